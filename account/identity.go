@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/goadesign/goa"
 	"github.com/jinzhu/gorm"
@@ -11,6 +12,14 @@ import (
 	"golang.org/x/net/context"
 )
 
+// TombstoneIdentityID is the ID of the identity that replaces anonymized
+// identities, it is created by the database migration
+var TombstoneIdentityID = uuid.FromStringOrNil("85592b03-e212-4be7-a564-f1cba7eec52f")
+
+// AnonymousIdentityID is the ID of the identity that creates the anonymous
+// contributions to public projects, it is created by the database migration
+var AnonymousIdentityID = uuid.FromStringOrNil("0fd02721-9ecc-4a65-b292-758a9e3f02e8")
+
 // Identity ddenDescribes a unique Person with the ALM
 type Identity struct {
 	gormsupport.Lifecycle
@@ -18,6 +27,9 @@ type Identity struct {
 	Emails   []User    // has many Users
 	FullName string    // The fullname of the Identity
 	ImageURL string    // The image URL for this Identity
+	// Timezone is the IANA name of the timezone the identity sees dates in,
+	// empty for UTC
+	Timezone string
 }
 
 // TableName overrides the table name settings in Gorm to force a specific table name
@@ -27,6 +39,33 @@ func (m Identity) TableName() string {
 
 }
 
+// Location returns the timezone of the identity, UTC if it has none or an
+// unknown one
+func (m Identity) Location() *time.Location {
+	if m.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(m.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// ValidateTimezone returns BadParameterError if the timezone isn't the IANA
+// name of a timezone or empty
+func ValidateTimezone(timezone string) error {
+	if timezone == "" {
+		return nil
+	}
+	// LoadLocation accepts "Local" and "UTC" as well, the former means
+	// the zone of the server
+	if _, err := time.LoadLocation(timezone); err != nil || timezone == "Local" {
+		return errors.NewBadParameterError("timezone", timezone).Expected("an IANA timezone like Europe/Berlin")
+	}
+	return nil
+}
+
 // TODO: Remove. Data layer should not know about the REST layer. Moved to /users.go
 // ConvertIdentityFromModel convert identity from model to app representation
 func (m Identity) ConvertIdentityFromModel() *app.Identity {
@@ -112,6 +151,10 @@ func (m *GormIdentityRepository) Save(ctx context.Context, model *Identity) erro
 		return err
 	}
 	err = m.db.Model(obj).Updates(model).Error
+	if err == nil {
+		// Updates skips blank fields, an empty timezone resets it to UTC
+		err = m.db.Model(obj).UpdateColumn("timezone", model.Timezone).Error
+	}
 
 	return err
 }
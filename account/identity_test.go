@@ -0,0 +1,24 @@
+package account_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentityTimezone(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	assert.Nil(t, account.ValidateTimezone(""))
+	assert.Nil(t, account.ValidateTimezone("Europe/Berlin"))
+	assert.IsType(t, errors.BadParameterError{}, account.ValidateTimezone("Mars/Olympus"))
+	assert.IsType(t, errors.BadParameterError{}, account.ValidateTimezone("Local"))
+
+	assert.Equal(t, time.UTC, account.Identity{}.Location())
+	assert.Equal(t, time.UTC, account.Identity{Timezone: "Mars/Olympus"}.Location())
+	assert.Equal(t, "Europe/Berlin", account.Identity{Timezone: "Europe/Berlin"}.Location().String())
+}
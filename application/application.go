@@ -2,9 +2,51 @@ package application
 
 import (
 	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/approval"
+	"github.com/almighty/almighty-core/assignment"
+	"github.com/almighty/almighty-core/attachment"
+	"github.com/almighty/almighty-core/automation"
+	"github.com/almighty/almighty-core/backup"
+	"github.com/almighty/almighty-core/branding"
+	"github.com/almighty/almighty-core/calendar"
+	"github.com/almighty/almighty-core/chat"
+	"github.com/almighty/almighty-core/codebase"
+	"github.com/almighty/almighty-core/codechange"
 	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/dashboard"
+	"github.com/almighty/almighty-core/deployment"
+	"github.com/almighty/almighty-core/escalation"
+	"github.com/almighty/almighty-core/favorite"
+	"github.com/almighty/almighty-core/fieldvalues"
+	"github.com/almighty/almighty-core/flow"
+	"github.com/almighty/almighty-core/hierarchy"
+	"github.com/almighty/almighty-core/impersonation"
+	"github.com/almighty/almighty-core/instancestats"
+	"github.com/almighty/almighty-core/intake"
 	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/job"
+	"github.com/almighty/almighty-core/moderation"
+	"github.com/almighty/almighty-core/notification"
+	"github.com/almighty/almighty-core/outbox"
+	"github.com/almighty/almighty-core/personaldata"
+	"github.com/almighty/almighty-core/portfolio"
+	"github.com/almighty/almighty-core/processconfig"
+	"github.com/almighty/almighty-core/processtemplate"
 	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/projectsettings"
+	"github.com/almighty/almighty-core/push"
+	"github.com/almighty/almighty-core/reaction"
+	"github.com/almighty/almighty-core/redirect"
+	"github.com/almighty/almighty-core/release"
+	"github.com/almighty/almighty-core/remotelink"
+	"github.com/almighty/almighty-core/report"
+	"github.com/almighty/almighty-core/retention"
+	"github.com/almighty/almighty-core/session"
+	"github.com/almighty/almighty-core/settings"
+	"github.com/almighty/almighty-core/share"
+	"github.com/almighty/almighty-core/stale"
+	"github.com/almighty/almighty-core/translation"
+	"github.com/almighty/almighty-core/vote"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/almighty/almighty-core/workitem/link"
 )
@@ -24,6 +66,63 @@ type Application interface {
 	Projects() project.Repository
 	Iterations() iteration.Repository
 	Users() account.IdentityRepository
+	RemoteLinks() remotelink.Repository
+	CodeChanges() codechange.Repository
+	Codebases() codebase.Repository
+	Deployments() deployment.Repository
+	Releases() release.Repository
+	FieldValues() fieldvalues.Repository
+	Votes() vote.Repository
+	Reactions() reaction.Repository
+	PersonalData() personaldata.Repository
+	Moderation() moderation.Repository
+	Settings() settings.Repository
+	Jobs() job.Repository
+	StalePolicies() stale.Repository
+	WorkItemEvents() workitem.EventRepository
+	Outbox() outbox.Repository
+	Favorites() favorite.Repository
+	Dashboards() dashboard.Repository
+	Reports() report.Repository
+	Flows() flow.Repository
+	Trash() workitem.TrashRepository
+	WorkItemArchive() workitem.ArchiveRepository
+	WorkItemChecklists() workitem.ChecklistRepository
+	EventPartitions() workitem.EventPartitionRepository
+	RetentionPolicies() retention.Repository
+	Backups() backup.Repository
+	Dumps() backup.Dumper
+	WorkItemTypeMigrations() workitem.TypeMigrationRepository
+	WorkItemTypeDeletions() workitem.TypeDeletionRepository
+	Translations() translation.Repository
+	CalendarFeeds() calendar.Repository
+	ChatIntegrations() chat.Repository
+	AutomationRules() automation.Repository
+	AssignmentRules() assignment.Repository
+	EscalationPolicies() escalation.Repository
+	Approvals() approval.Repository
+	TypeHierarchies() hierarchy.Repository
+	ProcessConfigs() processconfig.Repository
+	ProcessTemplates() processtemplate.Repository
+	ProjectSettings() projectsettings.Repository
+	InstanceStats() instancestats.Repository
+	Impersonations() impersonation.Repository
+	Sessions() session.Repository
+	Portfolios() portfolio.Repository
+	WorkItemMerge() workitem.MergeRepository
+	Redirects() redirect.Repository
+	ShareLinks() share.Repository
+	WorkItemDrafts() workitem.DraftRepository
+	WorkItemAutosaves() workitem.AutosaveRepository
+	Attachments() attachment.Repository
+	WorkItemChanges() workitem.ChangeRepository
+	PushDevices() push.Repository
+	NotificationPreferences() notification.PreferenceRepository
+	NotificationInbox() notification.InboxRepository
+	NotificationMutes() notification.MuteRepository
+	EmailTemplates() notification.TemplateRepository
+	Branding() branding.Repository
+	IntakePortals() intake.Repository
 }
 
 // A Transaction abstracts a database transaction. The repositories created for the transaction object make changes inside the the transaction
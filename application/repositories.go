@@ -1,8 +1,10 @@
 package application
 
 import (
+	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/search"
 	uuid "github.com/satori/go.uuid"
 	"golang.org/x/net/context"
 )
@@ -28,10 +30,14 @@ type TrackerQueryRepository interface {
 // SearchRepository encapsulates searching of woritems,users,etc
 type SearchRepository interface {
 	SearchFullText(ctx context.Context, searchStr string, start *int, length *int) ([]*app.WorkItem, uint64, error)
+	SearchFaceted(ctx context.Context, searchStr string, start *int, length *int, opts search.Options) (*search.Result, error)
+	Typeahead(ctx context.Context, text string, kinds []string, limit int) ([]search.Suggestion, error)
+	Duplicates(ctx context.Context, title string, description string, workItemType string, limit int) ([]search.Duplicate, error)
 }
 
 // IdentityRepository encapsulates identity
 type IdentityRepository interface {
+	Load(ctx context.Context, id uuid.UUID) (*account.Identity, error)
 	List(ctx context.Context) (*app.IdentityArray, error)
 	ValidIdentity(context.Context, uuid.UUID) bool
 }
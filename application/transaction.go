@@ -1,12 +1,47 @@
 package application
 
+import "github.com/almighty/almighty-core/readonly"
+
+// readOnlyTransaction is implemented by the transactions that can refuse
+// to write
+type readOnlyTransaction interface {
+	SetReadOnly() error
+}
+
 // Transactional executes the given function in a transaction. If todo returns an error, the transaction is rolled back
+// While the instance is in read-only mode the transaction is read only.
 func Transactional(db DB, todo func(f Application) error) error {
+	on, _ := readonly.Enabled()
+	return transactional(db, on, todo)
+}
+
+// MaintenanceTransactional executes the given function in a transaction that can write during
+// maintenance, for the tasks that switch it off or that it is switched on for like restoring
+// backups. It is read only as long as this code can't write the schema of the database.
+func MaintenanceTransactional(db DB, todo func(f Application) error) error {
+	readOnly := false
+	for _, reason := range readonly.Reasons() {
+		if reason == readonly.Schema {
+			readOnly = true
+		}
+	}
+	return transactional(db, readOnly, todo)
+}
+
+func transactional(db DB, readOnly bool, todo func(f Application) error) error {
 	var tx Transaction
 	var err error
 	if tx, err = db.BeginTransaction(); err != nil {
 		return err
 	}
+	if readOnly {
+		if ro, ok := tx.(readOnlyTransaction); ok {
+			if err := ro.SetReadOnly(); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
 	if err := todo(tx); err != nil {
 		tx.Rollback()
 		return err
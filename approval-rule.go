@@ -0,0 +1,163 @@
+package main
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/approval"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ApprovalRuleController implements the approval-rule resource.
+type ApprovalRuleController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewApprovalRuleController creates an approval-rule controller.
+func NewApprovalRuleController(service *goa.Service, db application.DB) *ApprovalRuleController {
+	return &ApprovalRuleController{Controller: service.NewController("ApprovalRuleController"), db: db}
+}
+
+// Show runs the show action.
+func (c *ApprovalRuleController) Show(ctx *app.ShowApprovalRuleContext) error {
+	return c.administrate(ctx, ctx.ID, func(appl application.Application, r *approval.Rule) error {
+		return ctx.OK(&app.ApprovalRuleSingle{Data: ConvertApprovalRule(ctx.RequestData, r)})
+	})
+}
+
+// Update runs the update action.
+func (c *ApprovalRuleController) Update(ctx *app.UpdateApprovalRuleContext) error {
+	changes, err := approvalRuleFromPayload(ctx.Payload.Data)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return c.administrate(ctx, ctx.ID, func(appl application.Application, r *approval.Rule) error {
+		attrs := ctx.Payload.Data.Attributes
+		if attrs.Version == nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.version", nil).Expected("not nil"))
+		}
+		r.Version = *attrs.Version
+		if attrs.Workitemtype != nil {
+			r.Type = changes.Type
+		}
+		if attrs.FromState != nil {
+			r.FromState = changes.FromState
+		}
+		if attrs.ToState != nil {
+			r.ToState = changes.ToState
+		}
+		if attrs.Roles != nil {
+			r.Roles = changes.Roles
+		}
+		if attrs.Approvals != nil {
+			r.Approvals = changes.Approvals
+		}
+		if err := appl.Approvals().SaveRule(ctx, r); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.ApprovalRuleSingle{Data: ConvertApprovalRule(ctx.RequestData, r)})
+	})
+}
+
+// Delete runs the delete action.
+func (c *ApprovalRuleController) Delete(ctx *app.DeleteApprovalRuleContext) error {
+	return c.administrate(ctx, ctx.ID, func(appl application.Application, r *approval.Rule) error {
+		if err := appl.Approvals().DeleteRule(ctx, r.ID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.NoContent()
+	})
+}
+
+// approvalContext is implemented by the contexts of the approval rule and
+// approval actions
+type approvalContext interface {
+	context.Context
+	jsonapi.InternalServerError
+}
+
+// administrate runs the given function in a transaction if the current
+// identity administrates the project of the rule
+func (c *ApprovalRuleController) administrate(ctx approvalContext, id string, f func(appl application.Application, r *approval.Rule) error) error {
+	ruleID, err := uuid.FromString(id)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		r, err := appl.Approvals().LoadRule(ctx, ruleID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if _, err := appl.Projects().Load(ctx, r.ProjectID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewNotFoundError("approval rule", id))
+		}
+		if err := checkProjectAdmin(ctx, appl, r.ProjectID, "manage the approval rules of the project"); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return f(appl, r)
+	})
+}
+
+// approvalRuleFromPayload returns the rule described by the payload
+// returns BadParameterError
+func approvalRuleFromPayload(data *app.ApprovalRule) (*approval.Rule, error) {
+	if data == nil || data.Attributes == nil {
+		return nil, errors.NewBadParameterError("data.attributes", nil).Expected("not nil")
+	}
+	attrs := data.Attributes
+	r := approval.Rule{Roles: approval.Roles{}, Approvals: 1}
+	if attrs.Workitemtype != nil {
+		r.Type = *attrs.Workitemtype
+	}
+	if attrs.FromState != nil {
+		r.FromState = *attrs.FromState
+	}
+	if attrs.ToState != nil {
+		r.ToState = *attrs.ToState
+	}
+	r.Roles = append(r.Roles, attrs.Roles...)
+	if attrs.Approvals != nil {
+		r.Approvals = *attrs.Approvals
+	}
+	return &r, nil
+}
+
+// ConvertApprovalRule converts between internal and external REST representation
+func ConvertApprovalRule(request *goa.RequestData, r *approval.Rule) *app.ApprovalRule {
+	selfURL := AbsoluteURL(request, app.ApprovalRuleHref(r.ID))
+	attrs := &app.ApprovalRuleAttributes{
+		ToState:   &r.ToState,
+		Roles:     r.Roles,
+		Approvals: &r.Approvals,
+		Version:   &r.Version,
+		CreatedAt: &r.CreatedAt,
+	}
+	if r.Type != "" {
+		attrs.Workitemtype = &r.Type
+	}
+	if r.FromState != "" {
+		attrs.FromState = &r.FromState
+	}
+	projectType := "projects"
+	projectID := r.ProjectID.String()
+	projectSelfURL := AbsoluteURL(request, app.ProjectHref(projectID))
+	return &app.ApprovalRule{
+		Type:       "approvalrules",
+		ID:         &r.ID,
+		Attributes: attrs,
+		Relationships: &app.ApprovalRuleRelations{
+			Project: &app.RelationGeneric{
+				Data:  &app.GenericData{Type: &projectType, ID: &projectID},
+				Links: &app.GenericLinks{Self: &projectSelfURL},
+			},
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
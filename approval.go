@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/approval"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ApprovalController implements the approval resource.
+type ApprovalController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewApprovalController creates an approval controller.
+func NewApprovalController(service *goa.Service, db application.DB) *ApprovalController {
+	return &ApprovalController{Controller: service.NewController("ApprovalController"), db: db}
+}
+
+// Show runs the show action.
+func (c *ApprovalController) Show(ctx *app.ShowApprovalContext) error {
+	id, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		a, err := appl.Approvals().Load(ctx, id)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		// the approvals of work items the viewer can't see don't exist
+		if _, err := appl.WorkItems().Load(ctx, strconv.FormatUint(a.WorkItemID, 10)); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewNotFoundError("approval", ctx.ID))
+		}
+		return ctx.OK(&app.ApprovalSingle{Data: ConvertApproval(ctx.RequestData, a)})
+	})
+}
+
+// Approve runs the approve action.
+func (c *ApprovalController) Approve(ctx *app.ApproveApprovalContext) error {
+	var comment *string
+	if ctx.Payload != nil {
+		comment = ctx.Payload.Comment
+	}
+	return c.decide(ctx, ctx.ID, true, comment, func(a *approval.Approval) error {
+		return ctx.OK(&app.ApprovalSingle{Data: ConvertApproval(ctx.RequestData, a)})
+	})
+}
+
+// Reject runs the reject action.
+func (c *ApprovalController) Reject(ctx *app.RejectApprovalContext) error {
+	var comment *string
+	if ctx.Payload != nil {
+		comment = ctx.Payload.Comment
+	}
+	return c.decide(ctx, ctx.ID, false, comment, func(a *approval.Approval) error {
+		return ctx.OK(&app.ApprovalSingle{Data: ConvertApproval(ctx.RequestData, a)})
+	})
+}
+
+// decide adds the decision of the current identity to the approval with the
+// given ID if it has one of the roles of the rule of the approval
+func (c *ApprovalController) decide(ctx approvalContext, id string, approved bool, comment *string, f func(a *approval.Approval) error) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	approvalID, err := uuid.FromString(id)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		a, err := appl.Approvals().Load(ctx, approvalID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		wi, err := appl.WorkItems().Load(ctx, strconv.FormatUint(a.WorkItemID, 10))
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewNotFoundError("approval", id))
+		}
+		r, err := appl.Approvals().LoadRule(ctx, a.RuleID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		role, err := approverRole(ctx, appl, r, *identityID, wi)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if role == "" {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(fmt.Sprintf("only the %s of the work item can decide on the approval", strings.Join(r.Roles, ", "))))
+		}
+		d := approval.Decision{IdentityID: *identityID, Role: role, Approved: approved, At: time.Now()}
+		if comment != nil {
+			d.Comment = *comment
+		}
+		a, err = appl.Approvals().Decide(ctx, approvalID, d)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return f(a)
+	})
+}
+
+// approverRole returns the first role of the rule the identity has for the
+// work item, empty if it has none of them
+func approverRole(ctx context.Context, appl application.Application, r *approval.Rule, identityID uuid.UUID, wi *app.WorkItem) (string, error) {
+	for _, role := range r.Roles {
+		switch role {
+		case approval.RoleProjectAdmin:
+			admin, err := isProjectAdmin(ctx, appl, r.ProjectID, identityID)
+			if err != nil {
+				return "", err
+			}
+			if admin {
+				return role, nil
+			}
+		case approval.RoleAssignee:
+			for _, assignee := range stringList(wi.Fields[workitem.SystemAssignees]) {
+				if assignee == identityID.String() {
+					return role, nil
+				}
+			}
+		case approval.RoleCreator:
+			if contributionText(wi.Fields[workitem.SystemCreator]) == identityID.String() {
+				return role, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// checkTransition enforces the approval rules of the project of the work
+// item moving from the state of before to the one of after, the transition
+// uses its approval
+// returns BadParameterError if the transition needs an approval it doesn't have
+func checkTransition(ctx context.Context, appl application.Application, request *goa.RequestData, before *app.WorkItem, after *app.WorkItem) error {
+	from, to := contributionText(before.Fields[workitem.SystemState]), contributionText(after.Fields[workitem.SystemState])
+	if from == to {
+		return nil
+	}
+	projectID, err := uuid.FromString(contributionText(after.Fields[workitem.SystemProject]))
+	if err != nil {
+		return nil
+	}
+	r, err := appl.Approvals().Rule(ctx, projectID, after.Type, from, to)
+	if err != nil || r == nil {
+		return err
+	}
+	wiID, err := workitem.ParseWorkItemIDToUint64(after.ID)
+	if err != nil {
+		return err
+	}
+	approved, err := appl.Approvals().Apply(ctx, wiID, from, to)
+	if err != nil {
+		return err
+	}
+	if !approved {
+		return errors.NewBadParameterError(workitem.SystemState, to).Expected(fmt.Sprintf("an approval by %d of the %s, request it at %s",
+			r.Approvals, strings.Join(r.Roles, ", "), AbsoluteURL(request, app.WorkitemHref(after.ID)+"/approvals")))
+	}
+	return nil
+}
+
+// ConvertApproval converts between internal and external REST representation
+func ConvertApproval(request *goa.RequestData, a *approval.Approval) *app.Approval {
+	selfURL := AbsoluteURL(request, app.ApprovalHref(a.ID))
+	decisions := make([]*app.ApprovalDecision, 0, len(a.Decisions))
+	for i := range a.Decisions {
+		d := a.Decisions[i]
+		decision := &app.ApprovalDecision{
+			Identity:  d.IdentityID,
+			Role:      d.Role,
+			Approved:  d.Approved,
+			DecidedAt: d.At,
+		}
+		if d.Comment != "" {
+			decision.Comment = &d.Comment
+		}
+		decisions = append(decisions, decision)
+	}
+	workItemType := "workitems"
+	workItemID := strconv.FormatUint(a.WorkItemID, 10)
+	workItemSelfURL := AbsoluteURL(request, app.WorkitemHref(workItemID))
+	ruleType := "approvalrules"
+	ruleID := a.RuleID.String()
+	identityType := "identities"
+	requesterID := a.RequestedBy.String()
+	return &app.Approval{
+		Type: "approvals",
+		ID:   &a.ID,
+		Attributes: &app.ApprovalAttributes{
+			FromState: &a.FromState,
+			ToState:   a.ToState,
+			Status:    &a.Status,
+			Decisions: decisions,
+			CreatedAt: &a.CreatedAt,
+		},
+		Relationships: &app.ApprovalRelations{
+			Workitem: &app.RelationGeneric{
+				Data:  &app.GenericData{Type: &workItemType, ID: &workItemID},
+				Links: &app.GenericLinks{Self: &workItemSelfURL},
+			},
+			Rule: &app.RelationGeneric{
+				Data: &app.GenericData{Type: &ruleType, ID: &ruleID},
+			},
+			Requester: &app.RelationGeneric{
+				Data: &app.GenericData{Type: &identityType, ID: &requesterID},
+			},
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
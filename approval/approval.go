@@ -0,0 +1,427 @@
+// Package approval stores the rules requiring approvals for the state
+// transitions of the work items of a project and the approvals requested for
+// them. A work item can only move to the state of a rule once the approvals
+// of the transition were given by identities that have one of the roles of
+// the rule, each approval allows the transition once.
+package approval
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Roles of the identities that can approve a transition
+const (
+	// RoleProjectAdmin are the admins of the project of the work item
+	RoleProjectAdmin = "project-admin"
+	// RoleAssignee are the assignees of the work item
+	RoleAssignee = "assignee"
+	// RoleCreator is the creator of the work item
+	RoleCreator = "creator"
+)
+
+// Statuses of an approval
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+	// StatusApplied approvals were used by the transition they approved
+	StatusApplied = "applied"
+)
+
+// MaxApprovals is the number of approvals a rule can require
+const MaxApprovals = 10
+
+// Roles are the roles of the identities that can approve a transition
+type Roles []string
+
+// Value implements the driver.Valuer interface
+func (rs Roles) Value() (driver.Value, error) {
+	if rs == nil {
+		rs = Roles{}
+	}
+	return json.Marshal(rs)
+}
+
+// Scan implements the sql.Scanner interface
+func (rs *Roles) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("scan source was not []byte")
+	}
+	return json.Unmarshal(b, rs)
+}
+
+// Rule requires approvals for the work items of a project that have the type
+// of the rule to move from its from state to its to state, an empty type or
+// from state matches all work items
+type Rule struct {
+	gormsupport.Lifecycle
+	ID        uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	ProjectID uuid.UUID `sql:"type:uuid"`
+	Type      string
+	FromState string
+	ToState   string
+	Roles     Roles `sql:"type:jsonb"`
+	// Approvals is the number of identities that must approve
+	Approvals int
+	Version   int
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m Rule) TableName() string {
+	return "approval_rules"
+}
+
+// Validate checks the transition, the roles and the approvals of the rule
+// returns BadParameterError
+func (m Rule) Validate() error {
+	if m.ToState == "" || m.ToState == m.FromState {
+		return errors.NewBadParameterError("to-state", m.ToState).Expected("a state other than the from state")
+	}
+	if len(m.Roles) == 0 {
+		return errors.NewBadParameterError("roles", len(m.Roles)).Expected("at least one role")
+	}
+	seen := map[string]bool{}
+	for _, r := range m.Roles {
+		if (r != RoleProjectAdmin && r != RoleAssignee && r != RoleCreator) || seen[r] {
+			return errors.NewBadParameterError("roles", r).Expected(fmt.Sprintf("unique roles of %s, %s and %s", RoleProjectAdmin, RoleAssignee, RoleCreator))
+		}
+		seen[r] = true
+	}
+	if m.Approvals < 1 || m.Approvals > MaxApprovals {
+		return errors.NewBadParameterError("approvals", m.Approvals).Expected(fmt.Sprintf("between 1 and %d", MaxApprovals))
+	}
+	return nil
+}
+
+// Matches returns true if the rule applies to work items of the given type
+// moving from one state to the other
+func (m Rule) Matches(typeName string, from string, to string) bool {
+	return from != to && to == m.ToState && (m.FromState == "" || m.FromState == from) && (m.Type == "" || m.Type == typeName)
+}
+
+// Decision is the approval or the rejection of an identity
+type Decision struct {
+	IdentityID uuid.UUID `json:"identity"`
+	// Role is the role of the rule the identity decided as
+	Role     string    `json:"role"`
+	Approved bool      `json:"approved"`
+	Comment  string    `json:"comment,omitempty"`
+	At       time.Time `json:"at"`
+}
+
+// Decisions are the decisions on an approval in the order they were made
+type Decisions []Decision
+
+// Value implements the driver.Valuer interface
+func (ds Decisions) Value() (driver.Value, error) {
+	if ds == nil {
+		ds = Decisions{}
+	}
+	return json.Marshal(ds)
+}
+
+// Scan implements the sql.Scanner interface
+func (ds *Decisions) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("scan source was not []byte")
+	}
+	return json.Unmarshal(b, ds)
+}
+
+// Approval is requested for a work item to move from one state to another
+// by the rule requiring it
+type Approval struct {
+	gormsupport.Lifecycle
+	ID          uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	WorkItemID  uint64
+	RuleID      uuid.UUID `sql:"type:uuid"`
+	FromState   string
+	ToState     string
+	RequestedBy uuid.UUID `sql:"type:uuid"`
+	Status      string
+	Decisions   Decisions `sql:"type:jsonb"`
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m Approval) TableName() string {
+	return "approvals"
+}
+
+// Decide adds the decision to the pending approval, one rejection rejects
+// it and the given number of approvals approve it. Identities decide once
+// and can't decide on the approvals they requested.
+// returns BadParameterError
+func (m *Approval) Decide(d Decision, required int) error {
+	if m.Status != StatusPending {
+		return errors.NewBadParameterError("status", m.Status).Expected(StatusPending)
+	}
+	if uuid.Equal(d.IdentityID, m.RequestedBy) {
+		return errors.NewBadParameterError("identity", d.IdentityID.String()).Expected("somebody else than the requester")
+	}
+	approvals := 0
+	for _, other := range m.Decisions {
+		if uuid.Equal(other.IdentityID, d.IdentityID) {
+			return errors.NewBadParameterError("identity", d.IdentityID.String()).Expected("an identity that didn't decide yet")
+		}
+		if other.Approved {
+			approvals++
+		}
+	}
+	m.Decisions = append(m.Decisions, d)
+	if !d.Approved {
+		m.Status = StatusRejected
+	} else if approvals+1 >= required {
+		m.Status = StatusApproved
+	}
+	return nil
+}
+
+// Repository describes interactions with approval rules and approvals
+type Repository interface {
+	CreateRule(ctx context.Context, r *Rule) error
+	LoadRule(ctx context.Context, id uuid.UUID) (*Rule, error)
+	SaveRule(ctx context.Context, r *Rule) error
+	DeleteRule(ctx context.Context, id uuid.UUID) error
+	ListRules(ctx context.Context, projectID uuid.UUID) ([]*Rule, error)
+	Rule(ctx context.Context, projectID uuid.UUID, typeName string, from string, to string) (*Rule, error)
+	Request(ctx context.Context, a *Approval) error
+	Load(ctx context.Context, id uuid.UUID) (*Approval, error)
+	List(ctx context.Context, workItemID uint64) ([]*Approval, error)
+	Decide(ctx context.Context, id uuid.UUID, d Decision) (*Approval, error)
+	Apply(ctx context.Context, workItemID uint64, from string, to string) (bool, error)
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for
+// approval rules and approvals.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// CreateRule stores a new rule, it is tried after the existing ones
+// returns BadParameterError or InternalError
+func (m *GormRepository) CreateRule(ctx context.Context, r *Rule) error {
+	defer goa.MeasureSince([]string{"goa", "db", "approvalrule", "create"}, time.Now())
+
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	r.ID = uuid.NewV4()
+	r.Version = 0
+	if err := m.db.Create(r).Error; err != nil {
+		goa.LogError(ctx, "error adding Rule", "error", err.Error())
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// LoadRule returns the rule with the given ID
+// returns NotFoundError or InternalError
+func (m *GormRepository) LoadRule(ctx context.Context, id uuid.UUID) (*Rule, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "approvalrule", "load"}, time.Now())
+
+	var obj Rule
+	tx := m.db.Where("id = ?", id).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("approval rule", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
+
+// SaveRule updates the transition, the roles and the approvals of the rule,
+// the version of r must match the stored one. Pending approvals keep the
+// rule, they need the approvals it requires when they are decided.
+// returns NotFoundError, BadParameterError, VersionConflictError or InternalError
+func (m *GormRepository) SaveRule(ctx context.Context, r *Rule) error {
+	defer goa.MeasureSince([]string{"goa", "db", "approvalrule", "save"}, time.Now())
+
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	if _, err := m.LoadRule(ctx, r.ID); err != nil {
+		return err
+	}
+	tx := m.db.Model(&Rule{}).Where("id = ? AND version = ?", r.ID, r.Version).Updates(map[string]interface{}{
+		"type":       r.Type,
+		"from_state": r.FromState,
+		"to_state":   r.ToState,
+		"roles":      r.Roles,
+		"approvals":  r.Approvals,
+		"version":    r.Version + 1,
+	})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewVersionConflictError("version conflict")
+	}
+	r.Version++
+	return nil
+}
+
+// DeleteRule removes the rule with the given ID and its approvals
+// returns NotFoundError or InternalError
+func (m *GormRepository) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "approvalrule", "delete"}, time.Now())
+
+	tx := m.db.Where("id = ?", id).Delete(&Rule{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("approval rule", id.String())
+	}
+	if err := m.db.Where("rule_id = ?", id).Delete(&Approval{}).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// ListRules returns the rules of the project in the order they are tried
+// returns InternalError
+func (m *GormRepository) ListRules(ctx context.Context, projectID uuid.UUID) ([]*Rule, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "approvalrule", "list"}, time.Now())
+
+	var rows []*Rule
+	if err := m.db.Where("project_id = ?", projectID).Order("created_at, id").Find(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return rows, nil
+}
+
+// Rule returns the first rule of the project requiring approvals for work
+// items of the given type to move from one state to the other, nil if the
+// transition needs no approvals
+// returns InternalError
+func (m *GormRepository) Rule(ctx context.Context, projectID uuid.UUID, typeName string, from string, to string) (*Rule, error) {
+	rules, err := m.ListRules(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rules {
+		if r.Matches(typeName, from, to) {
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+// Request stores a new pending approval, there can be one pending or
+// approved approval per transition of a work item
+// returns BadParameterError or InternalError
+func (m *GormRepository) Request(ctx context.Context, a *Approval) error {
+	defer goa.MeasureSince([]string{"goa", "db", "approval", "request"}, time.Now())
+
+	var open int
+	err := m.db.Model(&Approval{}).Where("work_item_id = ? AND from_state = ? AND to_state = ? AND status IN (?)",
+		a.WorkItemID, a.FromState, a.ToState, []string{StatusPending, StatusApproved}).Count(&open).Error
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if open > 0 {
+		return errors.NewBadParameterError("state", a.ToState).Expected("a transition without pending or approved approvals")
+	}
+	a.ID = uuid.NewV4()
+	a.Status = StatusPending
+	a.Decisions = Decisions{}
+	if err := m.db.Create(a).Error; err != nil {
+		goa.LogError(ctx, "error adding Approval", "error", err.Error())
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// Load returns the approval with the given ID
+// returns NotFoundError or InternalError
+func (m *GormRepository) Load(ctx context.Context, id uuid.UUID) (*Approval, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "approval", "load"}, time.Now())
+
+	var obj Approval
+	tx := m.db.Where("id = ?", id).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("approval", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
+
+// List returns the approvals requested for the work item, newest first
+// returns InternalError
+func (m *GormRepository) List(ctx context.Context, workItemID uint64) ([]*Approval, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "approval", "list"}, time.Now())
+
+	var rows []*Approval
+	if err := m.db.Where("work_item_id = ?", workItemID).Order("created_at DESC, id").Find(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return rows, nil
+}
+
+// Decide adds the decision to the pending approval with the given ID, the
+// approval is locked until the transaction ends
+// returns NotFoundError, BadParameterError or InternalError
+func (m *GormRepository) Decide(ctx context.Context, id uuid.UUID, d Decision) (*Approval, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "approval", "decide"}, time.Now())
+
+	var a Approval
+	tx := m.db.Set("gorm:query_option", "FOR UPDATE").Where("id = ?", id).First(&a)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("approval", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	r, err := m.LoadRule(ctx, a.RuleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.Decide(d, r.Approvals); err != nil {
+		return nil, err
+	}
+	err = m.db.Model(&Approval{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":    a.Status,
+		"decisions": a.Decisions,
+	}).Error
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return &a, nil
+}
+
+// Apply uses the approval of the work item moving from one state to the
+// other, returns false if the transition wasn't approved
+// returns InternalError
+func (m *GormRepository) Apply(ctx context.Context, workItemID uint64, from string, to string) (bool, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "approval", "apply"}, time.Now())
+
+	tx := m.db.Model(&Approval{}).Where("work_item_id = ? AND from_state = ? AND to_state = ? AND status = ?", workItemID, from, to, StatusApproved).
+		Update("status", StatusApplied)
+	if tx.Error != nil {
+		return false, errors.NewInternalError(tx.Error.Error())
+	}
+	return tx.RowsAffected > 0, nil
+}
@@ -0,0 +1,66 @@
+package approval_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/approval"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/resource"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	valid := approval.Rule{ToState: "ready for release", Roles: approval.Roles{approval.RoleProjectAdmin}, Approvals: 2}
+	assert.Nil(t, valid.Validate())
+	for _, change := range []func(r *approval.Rule){
+		func(r *approval.Rule) { r.ToState = "" },
+		func(r *approval.Rule) { r.FromState = r.ToState },
+		func(r *approval.Rule) { r.Roles = nil },
+		func(r *approval.Rule) { r.Roles = approval.Roles{"reviewer"} },
+		func(r *approval.Rule) { r.Roles = approval.Roles{approval.RoleAssignee, approval.RoleAssignee} },
+		func(r *approval.Rule) { r.Approvals = 0 },
+		func(r *approval.Rule) { r.Approvals = approval.MaxApprovals + 1 },
+	} {
+		r := valid
+		change(&r)
+		assert.IsType(t, errors.BadParameterError{}, r.Validate(), "%+v", r)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	r := approval.Rule{ToState: "closed"}
+	assert.True(t, r.Matches("system.bug", "open", "closed"))
+	assert.False(t, r.Matches("system.bug", "closed", "closed"))
+	assert.False(t, r.Matches("system.bug", "open", "resolved"))
+	r.FromState = "resolved"
+	r.Type = "system.bug"
+	assert.True(t, r.Matches("system.bug", "resolved", "closed"))
+	assert.False(t, r.Matches("system.bug", "open", "closed"))
+	assert.False(t, r.Matches("system.feature", "resolved", "closed"))
+}
+
+func TestDecide(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	requester, alice, bob := uuid.NewV4(), uuid.NewV4(), uuid.NewV4()
+	a := approval.Approval{RequestedBy: requester, Status: approval.StatusPending}
+	assert.IsType(t, errors.BadParameterError{}, a.Decide(approval.Decision{IdentityID: requester, Approved: true}, 2))
+	require.Nil(t, a.Decide(approval.Decision{IdentityID: alice, Approved: true}, 2))
+	assert.Equal(t, approval.StatusPending, a.Status)
+	assert.IsType(t, errors.BadParameterError{}, a.Decide(approval.Decision{IdentityID: alice, Approved: true}, 2))
+	require.Nil(t, a.Decide(approval.Decision{IdentityID: bob, Approved: true}, 2))
+	assert.Equal(t, approval.StatusApproved, a.Status)
+	assert.Len(t, a.Decisions, 2)
+	assert.IsType(t, errors.BadParameterError{}, a.Decide(approval.Decision{IdentityID: uuid.NewV4(), Approved: true}, 2))
+
+	a = approval.Approval{RequestedBy: requester, Status: approval.StatusPending}
+	require.Nil(t, a.Decide(approval.Decision{IdentityID: alice, Approved: true}, 2))
+	require.Nil(t, a.Decide(approval.Decision{IdentityID: bob, Approved: false}, 2))
+	assert.Equal(t, approval.StatusRejected, a.Status)
+}
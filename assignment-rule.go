@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/assignment"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// AssignmentRuleController implements the assignment-rule resource.
+type AssignmentRuleController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewAssignmentRuleController creates an assignment-rule controller.
+func NewAssignmentRuleController(service *goa.Service, db application.DB) *AssignmentRuleController {
+	return &AssignmentRuleController{Controller: service.NewController("AssignmentRuleController"), db: db}
+}
+
+// Show runs the show action.
+func (c *AssignmentRuleController) Show(ctx *app.ShowAssignmentRuleContext) error {
+	return c.administrate(ctx, ctx.ID, func(appl application.Application, r *assignment.Rule) error {
+		return ctx.OK(&app.AssignmentRuleSingle{Data: ConvertAssignmentRule(ctx.RequestData, r)})
+	})
+}
+
+// Update runs the update action.
+func (c *AssignmentRuleController) Update(ctx *app.UpdateAssignmentRuleContext) error {
+	changes, err := assignmentRuleFromPayload(ctx.Payload.Data)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return c.administrate(ctx, ctx.ID, func(appl application.Application, r *assignment.Rule) error {
+		attrs := ctx.Payload.Data.Attributes
+		if attrs.Version == nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.version", nil).Expected("not nil"))
+		}
+		r.Version = *attrs.Version
+		if attrs.Workitemtype != nil {
+			r.Type = changes.Type
+		}
+		if attrs.Label != nil {
+			r.Label = changes.Label
+		}
+		if attrs.Assignees != nil {
+			r.Assignees = changes.Assignees
+		}
+		if attrs.RoundRobin != nil {
+			r.RoundRobin = changes.RoundRobin
+		}
+		if err := validateAssignees(ctx, appl, r.Assignees); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if err := appl.AssignmentRules().Save(ctx, r); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.AssignmentRuleSingle{Data: ConvertAssignmentRule(ctx.RequestData, r)})
+	})
+}
+
+// Delete runs the delete action.
+func (c *AssignmentRuleController) Delete(ctx *app.DeleteAssignmentRuleContext) error {
+	return c.administrate(ctx, ctx.ID, func(appl application.Application, r *assignment.Rule) error {
+		if err := appl.AssignmentRules().Delete(ctx, r.ID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.NoContent()
+	})
+}
+
+// assignmentRuleContext is implemented by the contexts of the assignment
+// rule actions
+type assignmentRuleContext interface {
+	context.Context
+	jsonapi.InternalServerError
+}
+
+// administrate runs the given function in a transaction if the current
+// identity administrates the project of the rule
+func (c *AssignmentRuleController) administrate(ctx assignmentRuleContext, id string, f func(appl application.Application, r *assignment.Rule) error) error {
+	ruleID, err := uuid.FromString(id)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		r, err := appl.AssignmentRules().Load(ctx, ruleID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if _, err := appl.Projects().Load(ctx, r.ProjectID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewNotFoundError("assignment rule", id))
+		}
+		if err := checkProjectAdmin(ctx, appl, r.ProjectID, "manage the assignment rules of the project"); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return f(appl, r)
+	})
+}
+
+// assignmentRuleFromPayload returns the rule described by the payload
+// returns BadParameterError
+func assignmentRuleFromPayload(data *app.AssignmentRule) (*assignment.Rule, error) {
+	if data == nil || data.Attributes == nil {
+		return nil, errors.NewBadParameterError("data.attributes", nil).Expected("not nil")
+	}
+	attrs := data.Attributes
+	r := assignment.Rule{Assignees: assignment.Assignees{}}
+	if attrs.Workitemtype != nil {
+		r.Type = *attrs.Workitemtype
+	}
+	if attrs.Label != nil {
+		r.Label = *attrs.Label
+	}
+	for _, id := range attrs.Assignees {
+		r.Assignees = append(r.Assignees, id.String())
+	}
+	if attrs.RoundRobin != nil {
+		r.RoundRobin = *attrs.RoundRobin
+	}
+	return &r, nil
+}
+
+// validateAssignees returns BadParameterError if one of the assignees of a
+// rule isn't a known identity
+func validateAssignees(ctx context.Context, appl application.Application, assignees assignment.Assignees) error {
+	for _, a := range assignees {
+		id, err := uuid.FromString(a)
+		if err != nil || !appl.Identities().ValidIdentity(ctx, id) {
+			return errors.NewBadParameterError("data.attributes.assignees", a).Expected("the IDs of identities")
+		}
+	}
+	return nil
+}
+
+// ConvertAssignmentRule converts between internal and external REST representation
+func ConvertAssignmentRule(request *goa.RequestData, r *assignment.Rule) *app.AssignmentRule {
+	selfURL := AbsoluteURL(request, app.AssignmentRuleHref(r.ID))
+	assignees := make([]uuid.UUID, 0, len(r.Assignees))
+	for _, a := range r.Assignees {
+		if id, err := uuid.FromString(a); err == nil {
+			assignees = append(assignees, id)
+		}
+	}
+	attrs := &app.AssignmentRuleAttributes{
+		Assignees:  assignees,
+		RoundRobin: &r.RoundRobin,
+		Version:    &r.Version,
+		CreatedAt:  &r.CreatedAt,
+	}
+	if r.Type != "" {
+		attrs.Workitemtype = &r.Type
+	}
+	if r.Label != "" {
+		attrs.Label = &r.Label
+	}
+	projectType := "projects"
+	projectID := r.ProjectID.String()
+	projectSelfURL := AbsoluteURL(request, app.ProjectHref(projectID))
+	return &app.AssignmentRule{
+		Type:       "assignmentrules",
+		ID:         &r.ID,
+		Attributes: attrs,
+		Relationships: &app.AssignmentRuleRelations{
+			Project: &app.RelationGeneric{
+				Data:  &app.GenericData{Type: &projectType, ID: &projectID},
+				Links: &app.GenericLinks{Self: &projectSelfURL},
+			},
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
+
+// assignNewWorkItem assigns a new work item of the given type with the
+// given fields by the assignment rules of its project, unless it already
+// has assignees
+func assignNewWorkItem(ctx context.Context, appl application.Application, typeName string, fields map[string]interface{}) error {
+	if len(stringList(fields[workitem.SystemAssignees])) > 0 {
+		return nil
+	}
+	projectID, err := uuid.FromString(fmt.Sprint(fields[workitem.SystemProject]))
+	if err != nil {
+		return nil
+	}
+	assignees, err := appl.AssignmentRules().Assign(ctx, projectID, typeName, stringList(fields[workitem.SystemLabels]))
+	if err != nil || assignees == nil {
+		return err
+	}
+	fields[workitem.SystemAssignees] = assignees
+	return nil
+}
@@ -0,0 +1,245 @@
+// Package assignment stores the rules assigning the new work items of a
+// project. The first rule matching the type and the labels of a new work
+// item without assignees assigns it, either to all identities of the rule
+// or to one of them in turn.
+package assignment
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// MaxAssignees is the number of identities a rule can assign to
+const MaxAssignees = 50
+
+// Assignees are the IDs of the identities a rule assigns to
+type Assignees []string
+
+// Value implements the driver.Valuer interface
+func (as Assignees) Value() (driver.Value, error) {
+	if as == nil {
+		as = Assignees{}
+	}
+	return json.Marshal(as)
+}
+
+// Scan implements the sql.Scanner interface
+func (as *Assignees) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("scan source was not []byte")
+	}
+	return json.Unmarshal(b, as)
+}
+
+// Rule assigns the new work items of a project that have the type and the
+// label of the rule, an empty type or label matches all work items
+type Rule struct {
+	gormsupport.Lifecycle
+	ID        uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	ProjectID uuid.UUID `sql:"type:uuid"`
+	Type      string
+	Label     string
+	Assignees Assignees `sql:"type:jsonb"`
+	// RoundRobin assigns one assignee per work item in turn instead of all
+	RoundRobin bool
+	// Turns counts the work items assigned in round robin
+	Turns   int
+	Version int
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m Rule) TableName() string {
+	return "assignment_rules"
+}
+
+// Validate checks the assignees of the rule
+// returns BadParameterError
+func (m Rule) Validate() error {
+	if len(m.Assignees) == 0 || len(m.Assignees) > MaxAssignees {
+		return errors.NewBadParameterError("assignees", len(m.Assignees)).Expected(fmt.Sprintf("between 1 and %d assignees", MaxAssignees))
+	}
+	seen := map[string]bool{}
+	for _, a := range m.Assignees {
+		id, err := uuid.FromString(a)
+		if err != nil || seen[id.String()] {
+			return errors.NewBadParameterError("assignees", a).Expected("the unique IDs of identities")
+		}
+		seen[id.String()] = true
+	}
+	return nil
+}
+
+// Matches returns true if the rule applies to work items of the given type
+// with the given labels
+func (m Rule) Matches(typeName string, labels []string) bool {
+	if m.Type != "" && m.Type != typeName {
+		return false
+	}
+	if m.Label == "" {
+		return true
+	}
+	for _, l := range labels {
+		if l == m.Label {
+			return true
+		}
+	}
+	return false
+}
+
+// Repository describes interactions with assignment rules
+type Repository interface {
+	Create(ctx context.Context, r *Rule) error
+	Load(ctx context.Context, id uuid.UUID) (*Rule, error)
+	Save(ctx context.Context, r *Rule) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, projectID uuid.UUID) ([]*Rule, error)
+	Assign(ctx context.Context, projectID uuid.UUID, typeName string, labels []string) ([]string, error)
+}
+
+// NewRuleRepository creates a new storage type.
+func NewRuleRepository(db *gorm.DB) Repository {
+	return &GormRuleRepository{db: db}
+}
+
+// GormRuleRepository is the implementation of the storage interface for
+// assignment rules.
+type GormRuleRepository struct {
+	db *gorm.DB
+}
+
+// Create stores a new rule, it is tried after the existing ones
+// returns BadParameterError or InternalError
+func (m *GormRuleRepository) Create(ctx context.Context, r *Rule) error {
+	defer goa.MeasureSince([]string{"goa", "db", "assignmentrule", "create"}, time.Now())
+
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	r.ID = uuid.NewV4()
+	r.Turns = 0
+	r.Version = 0
+	if err := m.db.Create(r).Error; err != nil {
+		goa.LogError(ctx, "error adding Rule", "error", err.Error())
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// Load returns the rule with the given ID
+// returns NotFoundError or InternalError
+func (m *GormRuleRepository) Load(ctx context.Context, id uuid.UUID) (*Rule, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "assignmentrule", "load"}, time.Now())
+
+	var obj Rule
+	tx := m.db.Where("id = ?", id).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("assignment rule", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
+
+// Save updates the matched type and label and the assignees of the rule,
+// the version of r must match the stored one. Changing the assignees
+// restarts the round robin.
+// returns NotFoundError, BadParameterError, VersionConflictError or InternalError
+func (m *GormRuleRepository) Save(ctx context.Context, r *Rule) error {
+	defer goa.MeasureSince([]string{"goa", "db", "assignmentrule", "save"}, time.Now())
+
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	old, err := m.Load(ctx, r.ID)
+	if err != nil {
+		return err
+	}
+	if fmt.Sprint(old.Assignees) != fmt.Sprint(r.Assignees) {
+		r.Turns = 0
+	}
+	tx := m.db.Model(&Rule{}).Where("id = ? AND version = ?", r.ID, r.Version).Updates(map[string]interface{}{
+		"type":        r.Type,
+		"label":       r.Label,
+		"assignees":   r.Assignees,
+		"round_robin": r.RoundRobin,
+		"turns":       r.Turns,
+		"version":     r.Version + 1,
+	})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewVersionConflictError("version conflict")
+	}
+	r.Version++
+	return nil
+}
+
+// Delete removes the rule with the given ID
+// returns NotFoundError or InternalError
+func (m *GormRuleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "assignmentrule", "delete"}, time.Now())
+
+	tx := m.db.Where("id = ?", id).Delete(&Rule{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("assignment rule", id.String())
+	}
+	return nil
+}
+
+// List returns the rules of the project in the order they are tried
+// returns InternalError
+func (m *GormRuleRepository) List(ctx context.Context, projectID uuid.UUID) ([]*Rule, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "assignmentrule", "list"}, time.Now())
+
+	var rows []*Rule
+	if err := m.db.Where("project_id = ?", projectID).Order("created_at, id").Find(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return rows, nil
+}
+
+// Assign returns the assignees of a new work item of the project with the
+// given type and labels, nil if no rule matches. A round robin rule returns
+// its next assignee and moves on, concurrent work items get different ones.
+// returns InternalError
+func (m *GormRuleRepository) Assign(ctx context.Context, projectID uuid.UUID, typeName string, labels []string) ([]string, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "assignmentrule", "assign"}, time.Now())
+
+	rules, err := m.List(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rules {
+		if !r.Matches(typeName, labels) {
+			continue
+		}
+		if !r.RoundRobin {
+			return r.Assignees, nil
+		}
+		// the update locks the rule until the transaction ends
+		var turn int
+		err := m.db.Raw(`UPDATE assignment_rules SET turns = turns + 1 WHERE id = ? RETURNING turns - 1`, r.ID).Row().Scan(&turn)
+		if err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		return []string{r.Assignees[turn%len(r.Assignees)]}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,30 @@
+package assignment_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/assignment"
+	"github.com/almighty/almighty-core/resource"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	id := uuid.NewV4().String()
+	assert.Nil(t, assignment.Rule{Assignees: assignment.Assignees{id}}.Validate())
+	assert.NotNil(t, assignment.Rule{}.Validate())
+	assert.NotNil(t, assignment.Rule{Assignees: assignment.Assignees{"joe"}}.Validate())
+	assert.NotNil(t, assignment.Rule{Assignees: assignment.Assignees{id, id}}.Validate())
+}
+
+func TestMatches(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	assert.True(t, assignment.Rule{}.Matches("system.bug", nil))
+	assert.True(t, assignment.Rule{Type: "system.bug"}.Matches("system.bug", []string{"ui"}))
+	assert.False(t, assignment.Rule{Type: "system.bug"}.Matches("system.feature", []string{"ui"}))
+	assert.True(t, assignment.Rule{Type: "system.bug", Label: "ui"}.Matches("system.bug", []string{"backend", "ui"}))
+	assert.False(t, assignment.Rule{Label: "ui"}.Matches("system.bug", []string{"backend"}))
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/attachment"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// AttachmentController implements the attachment resource.
+type AttachmentController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewAttachmentController creates an attachment controller.
+func NewAttachmentController(service *goa.Service, db application.DB) *AttachmentController {
+	return &AttachmentController{Controller: service.NewController("AttachmentController"), db: db}
+}
+
+// Show runs the show action.
+func (c *AttachmentController) Show(ctx *app.ShowAttachmentContext) error {
+	id, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		a, err := appl.Attachments().Load(ctx, id)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if a.IsQuarantined() {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewNotFoundError("attachment", id.String()))
+		}
+		// the content of an attachment never changes, its hash is its version
+		header := ctx.ResponseData.Header()
+		header.Set("Content-Type", a.ContentType)
+		header.Set("Cache-Control", "private, max-age=31536000, immutable")
+		header.Set("ETag", `"`+a.Hash+`"`)
+		header.Set("X-Content-Type-Options", "nosniff")
+		if ctx.RequestData.Header.Get("If-None-Match") == `"`+a.Hash+`"` {
+			ctx.ResponseData.WriteHeader(http.StatusNotModified)
+			return nil
+		}
+		ctx.ResponseData.WriteHeader(http.StatusOK)
+		_, err = ctx.ResponseData.Write(a.Content)
+		return err
+	})
+}
+
+// ConvertAttachment converts from internal to external REST representation
+func ConvertAttachment(request *goa.RequestData, a *attachment.Attachment) *app.Attachment {
+	projectType := "projects"
+	projectID := a.ProjectID.String()
+	identityType := "identities"
+	creatorID := a.CreatedBy.String()
+
+	selfURL := AbsoluteURL(request, app.AttachmentHref(a.ID))
+	projectSelfURL := AbsoluteURL(request, app.ProjectHref(projectID))
+	markdown := "![image](" + selfURL + ")"
+	size := int(a.Size)
+
+	return &app.Attachment{
+		Type: "attachments",
+		ID:   &a.ID,
+		Attributes: &app.AttachmentAttributes{
+			URL:         selfURL,
+			Markdown:    markdown,
+			Hash:        a.Hash,
+			ContentType: a.ContentType,
+			Size:        size,
+			CreatedAt:   a.CreatedAt,
+		},
+		Relationships: &app.AttachmentRelations{
+			Project: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: &projectType,
+					ID:   &projectID,
+				},
+				Links: &app.GenericLinks{
+					Self: &projectSelfURL,
+				},
+			},
+			Creator: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: &identityType,
+					ID:   &creatorID,
+				},
+			},
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
@@ -0,0 +1,375 @@
+// Package attachment stores files uploaded to projects, e.g. screenshots
+// pasted into descriptions and comments. Attachments are addressed by the
+// SHA-256 of their content, the same file is stored once per project. The
+// attachments of a project must fit into its storage quota. While encryption
+// keys are configured the content is stored encrypted by the default keyring.
+package attachment
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/bodylimit"
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/encryption"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ImageTypes are the content types of images that can be pasted
+var ImageTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp"}
+
+// Attachment is a file uploaded to a project
+type Attachment struct {
+	gormsupport.Lifecycle
+	ID          uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	ProjectID   uuid.UUID `sql:"type:uuid"`
+	Hash        string
+	ContentType string
+	Size        int64
+	Content     []byte
+	CreatedBy   uuid.UUID `sql:"type:uuid"`
+	// Malware is the signature of the malware found in the content, the
+	// attachment is quarantined and never served if it is set
+	Malware *string
+	// Encrypted is set if the content is stored encrypted, the repository
+	// always returns the plaintext content
+	Encrypted bool
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m Attachment) TableName() string {
+	return "attachments"
+}
+
+// IsQuarantined returns true if malware was found in the attachment
+func (m Attachment) IsQuarantined() bool {
+	return m.Malware != nil
+}
+
+// Usage is the storage used by the attachments of a project
+type Usage struct {
+	ProjectID uuid.UUID
+	Count     int
+	// Size is the total size of the attachments in bytes
+	Size int64
+}
+
+// Hash returns the hex encoded SHA-256 of the content
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// DecodeImage returns the image and its content type sent with the given
+// content type, either as binary image, as data URL like
+// "data:image/png;base64,..." or as plain base64. The content type is sniffed
+// from the image, it must be one of ImageTypes.
+// returns BadParameterError
+func DecodeImage(contentType string, body []byte) ([]byte, string, error) {
+	image := body
+	if !strings.HasPrefix(contentType, "image/") {
+		text := string(bytes.TrimSpace(body))
+		if strings.HasPrefix(text, "data:") {
+			i := strings.Index(text, ",")
+			if i < 0 || !strings.HasSuffix(text[:i], ";base64") {
+				return nil, "", errors.NewBadParameterError("image", "data URL").Expected("base64 encoded data URL")
+			}
+			text = text[i+1:]
+		}
+		var err error
+		if image, err = base64.StdEncoding.DecodeString(text); err != nil {
+			return nil, "", errors.NewBadParameterError("image", err.Error()).Expected("binary or base64 encoded image")
+		}
+	}
+	return sniffImage(image)
+}
+
+// sniffImage returns the image and its content type sniffed from it
+// returns BadParameterError if it isn't one of ImageTypes
+func sniffImage(image []byte) ([]byte, string, error) {
+	if len(image) == 0 {
+		return nil, "", errors.NewBadParameterError("image", "empty").Expected("binary or base64 encoded image")
+	}
+	sniffed := http.DetectContentType(image)
+	for _, t := range ImageTypes {
+		if sniffed == t {
+			return image, sniffed, nil
+		}
+	}
+	return nil, "", errors.NewBadParameterError("image", sniffed).Expected(ImageTypes)
+}
+
+// ReadImage reads the image uploaded with the given content type from the
+// body, either a body DecodeImage accepts or a multipart/form-data body with
+// the binary image in its "image" part. Multipart bodies are parsed while they
+// are read, the other parts are skipped without keeping them, so at most the
+// image is held in memory.
+// returns BadParameterError or PayloadTooLargeError if the image exceeds
+// maxSize bytes
+func ReadImage(contentType string, body io.Reader, maxSize int64) ([]byte, string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err == nil && mediaType == "multipart/form-data" {
+		return readImagePart(multipart.NewReader(body, params["boundary"]), maxSize)
+	}
+	// base64 needs 4 bytes for every 3 bytes of the image
+	data, err := readAtMost(body, maxSize*4/3+1024)
+	if err != nil {
+		return nil, "", err
+	}
+	image, sniffed, err := DecodeImage(contentType, data)
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(image)) > maxSize {
+		return nil, "", tooLarge(maxSize)
+	}
+	return image, sniffed, nil
+}
+
+// readImagePart returns the image in the "image" part of the form
+func readImagePart(form *multipart.Reader, maxSize int64) ([]byte, string, error) {
+	for {
+		part, err := form.NextPart()
+		if err == io.EOF {
+			return nil, "", errors.NewBadParameterError("image", "missing").Expected("an image part")
+		}
+		if err != nil {
+			return nil, "", bodylimit.ReadError("image", err)
+		}
+		// NextPart discards the rest of the skipped parts
+		if part.FormName() != "image" {
+			continue
+		}
+		image, err := readAtMost(part, maxSize)
+		if err != nil {
+			return nil, "", err
+		}
+		return sniffImage(image)
+	}
+}
+
+// readAtMost reads the reader up to the limit
+// returns BadParameterError or PayloadTooLargeError if there is more to read
+func readAtMost(r io.Reader, limit int64) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, bodylimit.ReadError("image", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, tooLarge(limit)
+	}
+	return data, nil
+}
+
+func tooLarge(maxSize int64) error {
+	return errors.NewPayloadTooLargeError(fmt.Sprintf("the image exceeds %d bytes", maxSize))
+}
+
+// Repository encapsulates storage & retrieval of attachments
+type Repository interface {
+	Create(ctx context.Context, a *Attachment) (bool, error)
+	Load(ctx context.Context, id uuid.UUID) (*Attachment, error)
+	Usage(ctx context.Context, projectID *uuid.UUID) ([]*Usage, error)
+	Largest(ctx context.Context, projectID *uuid.UUID, limit int) ([]*Attachment, error)
+}
+
+// NewAttachmentRepository creates a new storage type.
+func NewAttachmentRepository(db *gorm.DB) Repository {
+	return &GormAttachmentRepository{db: db}
+}
+
+// GormAttachmentRepository is the implementation of the storage interface for attachments.
+type GormAttachmentRepository struct {
+	db *gorm.DB
+}
+
+// Create stores the attachment unless the project already has one with the
+// same content, then the given attachment is filled with the stored one.
+// Returns true if the attachment was created.
+// returns BadParameterError if the attachment exceeds the quota of the project,
+// DataConflictError if the same content is stored concurrently or InternalError
+func (m *GormAttachmentRepository) Create(ctx context.Context, a *Attachment) (bool, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "attachment", "create"}, time.Now())
+
+	a.Hash = Hash(a.Content)
+	a.Size = int64(len(a.Content))
+
+	var existing Attachment
+	tx := m.db.Where("project_id = ? AND hash = ?", a.ProjectID, a.Hash).First(&existing)
+	if tx.Error == nil {
+		if err := decrypt(&existing); err != nil {
+			return false, err
+		}
+		*a = existing
+		return false, nil
+	}
+	if !tx.RecordNotFound() {
+		return false, errors.NewInternalError(tx.Error.Error())
+	}
+
+	if quota := configuration.GetAttachmentQuota(); quota > 0 {
+		// uploads to the project wait for each other to check the quota
+		if err := m.db.Exec("SELECT 1 FROM projects WHERE id = ? FOR UPDATE", a.ProjectID).Error; err != nil {
+			return false, errors.NewInternalError(err.Error())
+		}
+		used, err := m.used(a.ProjectID)
+		if err != nil {
+			return false, err
+		}
+		if used+a.Size > quota {
+			available := quota - used
+			if available < 0 {
+				available = 0
+			}
+			return false, errors.NewBadParameterError("size", a.Size).Expected(fmt.Sprintf(
+				"at most %d bytes, the project uses %d bytes of its storage quota of %d bytes", available, used, quota))
+		}
+	}
+
+	a.ID = uuid.NewV4()
+	stored, err := encrypt(*a)
+	if err != nil {
+		return false, err
+	}
+	if err := m.db.Create(&stored).Error; err != nil {
+		if gormsupport.IsUniqueViolation(err, "attachments_project_id_hash_idx") {
+			return false, errors.NewDataConflictError("the attachment is being uploaded concurrently")
+		}
+		goa.LogError(ctx, "error adding Attachment", "error", err.Error())
+		return false, errors.NewInternalError(err.Error())
+	}
+	stored.Content = a.Content
+	*a = stored
+	return true, nil
+}
+
+// encrypt returns a copy of the attachment with the content encrypted by the
+// default keyring, or the attachment itself if no keys are configured
+// returns InternalError
+func encrypt(a Attachment) (Attachment, error) {
+	keyring, err := encryption.DefaultKeyring()
+	if err != nil {
+		return a, nil
+	}
+	if a.Content, err = keyring.Encrypt(a.Content); err != nil {
+		return a, err
+	}
+	a.Encrypted = true
+	return a, nil
+}
+
+// decrypt replaces the encrypted content of an attachment loaded from the
+// database with its plaintext
+// returns InternalError if the content can't be decrypted
+func decrypt(a *Attachment) error {
+	if !a.Encrypted {
+		return nil
+	}
+	keyring, err := encryption.DefaultKeyring()
+	if err != nil {
+		return err
+	}
+	content, err := keyring.Decrypt(a.Content)
+	if err != nil {
+		return errors.NewInternalError(fmt.Sprintf("attachment %s: %s", a.ID, err.Error()))
+	}
+	a.Content = content
+	return nil
+}
+
+// Load returns the attachment with its content
+// returns NotFoundError or InternalError
+func (m *GormAttachmentRepository) Load(ctx context.Context, id uuid.UUID) (*Attachment, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "attachment", "get"}, time.Now())
+	var obj Attachment
+
+	tx := m.db.Where("id = ?", id).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("attachment", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	if !workitem.ContextViewer(ctx).CanReadProject(obj.ProjectID) {
+		return nil, errors.NewNotFoundError("attachment", id.String())
+	}
+	if err := decrypt(&obj); err != nil {
+		return nil, err
+	}
+	return &obj, nil
+}
+
+// Usage returns the storage used by the attachments of the given project or
+// of all projects having attachments, most used first. Quarantined attachments
+// count as well.
+// returns InternalError
+func (m *GormAttachmentRepository) Usage(ctx context.Context, projectID *uuid.UUID) ([]*Usage, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "attachment", "usage"}, time.Now())
+
+	db := m.db.Model(&Attachment{}).Select("project_id, count(*) AS count, sum(size) AS size").Group("project_id")
+	if projectID != nil {
+		db = db.Where("project_id = ?", *projectID)
+	}
+	rows, err := db.Order("size DESC, project_id").Rows()
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	defer rows.Close()
+	usages := []*Usage{}
+	for rows.Next() {
+		var u Usage
+		if err := rows.Scan(&u.ProjectID, &u.Count, &u.Size); err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		usages = append(usages, &u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return usages, nil
+}
+
+// Largest returns the given number of largest attachments of the given
+// project or of all projects, without their content
+// returns InternalError
+func (m *GormAttachmentRepository) Largest(ctx context.Context, projectID *uuid.UUID, limit int) ([]*Attachment, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "attachment", "largest"}, time.Now())
+
+	db := m.db.Select("created_at, updated_at, id, project_id, hash, content_type, size, encrypted, created_by, malware")
+	if projectID != nil {
+		db = db.Where("project_id = ?", *projectID)
+	}
+	var objs []*Attachment
+	if err := db.Order("size DESC, id").Limit(limit).Find(&objs).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return objs, nil
+}
+
+// used returns the total size of the attachments of the project
+func (m *GormAttachmentRepository) used(projectID uuid.UUID) (int64, error) {
+	var used int64
+	err := m.db.Model(&Attachment{}).Where("project_id = ?", projectID).Select("coalesce(sum(size), 0)").Row().Scan(&used)
+	if err != nil {
+		return 0, errors.NewInternalError(err.Error())
+	}
+	return used, nil
+}
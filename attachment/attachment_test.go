@@ -0,0 +1,232 @@
+package attachment_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"mime/multipart"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/attachment"
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/encryption"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// png is the start of a PNG image, enough to be recognized
+var png = []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+
+func TestDecodeImage(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	encoded := base64.StdEncoding.EncodeToString(png)
+	for _, body := range []struct {
+		contentType string
+		body        string
+	}{
+		{"image/png", string(png)},
+		{"text/plain", encoded + "\n"},
+		{"text/plain", "data:image/png;base64," + encoded},
+	} {
+		image, contentType, err := attachment.DecodeImage(body.contentType, []byte(body.body))
+		require.Nil(t, err, body.body)
+		assert.Equal(t, png, image)
+		assert.Equal(t, "image/png", contentType)
+	}
+
+	for _, body := range []struct {
+		contentType string
+		body        string
+	}{
+		{"image/png", ""},
+		{"image/png", "<svg></svg>"},
+		{"text/plain", "not base64!"},
+		{"text/plain", "data:image/png," + encoded},
+		{"text/plain", base64.StdEncoding.EncodeToString([]byte("plain text"))},
+	} {
+		_, _, err := attachment.DecodeImage(body.contentType, []byte(body.body))
+		assert.IsType(t, errors.BadParameterError{}, err, body.body)
+	}
+}
+
+func TestReadImage(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	var form bytes.Buffer
+	w := multipart.NewWriter(&form)
+	require.Nil(t, w.WriteField("comment", "a screenshot"))
+	part, err := w.CreateFormFile("image", "screenshot.png")
+	require.Nil(t, err)
+	_, err = part.Write(png)
+	require.Nil(t, err)
+	require.Nil(t, w.Close())
+
+	image, contentType, err := attachment.ReadImage(w.FormDataContentType(), &form, 1024)
+	require.Nil(t, err)
+	assert.Equal(t, png, image)
+	assert.Equal(t, "image/png", contentType)
+
+	image, _, err = attachment.ReadImage("image/png", bytes.NewReader(png), 1024)
+	require.Nil(t, err)
+	assert.Equal(t, png, image)
+
+	// larger images are refused, multipart ones without reading past the limit
+	_, _, err = attachment.ReadImage("image/png", bytes.NewReader(png), int64(len(png)-1))
+	assert.IsType(t, errors.PayloadTooLargeError{}, err)
+	form.Reset()
+	w = multipart.NewWriter(&form)
+	part, err = w.CreateFormFile("image", "screenshot.png")
+	require.Nil(t, err)
+	_, err = part.Write(append(png, make([]byte, 2048)...))
+	require.Nil(t, err)
+	require.Nil(t, w.Close())
+	_, _, err = attachment.ReadImage(w.FormDataContentType(), &form, 1024)
+	assert.IsType(t, errors.PayloadTooLargeError{}, err)
+
+	// forms without an image part are refused
+	form.Reset()
+	w = multipart.NewWriter(&form)
+	require.Nil(t, w.WriteField("comment", "no screenshot"))
+	require.Nil(t, w.Close())
+	_, _, err = attachment.ReadImage(w.FormDataContentType(), &form, 1024)
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
+
+func TestHash(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", attachment.Hash(nil))
+}
+
+type TestAttachmentRepository struct {
+	gormsupport.DBTestSuite
+
+	clean      func()
+	projectID  uuid.UUID
+	identityID uuid.UUID
+}
+
+func TestRunAttachmentRepository(t *testing.T) {
+	suite.Run(t, &TestAttachmentRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestAttachmentRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+
+	p, err := project.NewRepository(test.DB).Create(context.Background(), "attachment-test-"+uuid.NewV4().String())
+	require.Nil(test.T(), err)
+	test.projectID = p.ID
+	identity := account.Identity{FullName: "Attachment Tester"}
+	require.Nil(test.T(), account.NewIdentityRepository(test.DB).Create(context.Background(), &identity))
+	test.identityID = identity.ID
+}
+
+func (test *TestAttachmentRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestAttachmentRepository) TestCreateDeduplicates() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := attachment.NewAttachmentRepository(test.DB)
+	a := attachment.Attachment{ProjectID: test.projectID, ContentType: "image/png", Content: png, CreatedBy: test.identityID}
+	created, err := repo.Create(context.Background(), &a)
+	require.Nil(t, err)
+	assert.True(t, created)
+	assert.Equal(t, attachment.Hash(png), a.Hash)
+	assert.Equal(t, int64(len(png)), a.Size)
+
+	// the same content is stored once per project
+	again := attachment.Attachment{ProjectID: test.projectID, ContentType: "image/png", Content: png, CreatedBy: test.identityID}
+	created, err = repo.Create(context.Background(), &again)
+	require.Nil(t, err)
+	assert.False(t, created)
+	assert.Equal(t, a.ID, again.ID)
+
+	loaded, err := repo.Load(context.Background(), a.ID)
+	require.Nil(t, err)
+	assert.Equal(t, png, loaded.Content)
+	assert.False(t, loaded.IsQuarantined())
+
+	_, err = repo.Load(context.Background(), uuid.NewV4())
+	assert.IsType(t, errors.NotFoundError{}, err)
+}
+
+func (test *TestAttachmentRepository) TestCreateEncrypts() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+	require.Nil(t, encryption.SetupDefaultKeyring())
+	defer encryption.SetDefaultKeyring(nil)
+
+	repo := attachment.NewAttachmentRepository(test.DB)
+	a := attachment.Attachment{ProjectID: test.projectID, ContentType: "image/png", Content: png, CreatedBy: test.identityID}
+	created, err := repo.Create(context.Background(), &a)
+	require.Nil(t, err)
+	assert.True(t, created)
+	assert.True(t, a.Encrypted)
+	assert.Equal(t, png, a.Content)
+	assert.Equal(t, int64(len(png)), a.Size)
+
+	var stored attachment.Attachment
+	require.Nil(t, test.DB.Where("id = ?", a.ID).First(&stored).Error)
+	assert.NotContains(t, string(stored.Content), string(png))
+
+	loaded, err := repo.Load(context.Background(), a.ID)
+	require.Nil(t, err)
+	assert.Equal(t, png, loaded.Content)
+	again := attachment.Attachment{ProjectID: test.projectID, ContentType: "image/png", Content: png, CreatedBy: test.identityID}
+	created, err = repo.Create(context.Background(), &again)
+	require.Nil(t, err)
+	assert.False(t, created)
+	assert.Equal(t, png, again.Content)
+
+	// the content can't be read without the keys
+	encryption.SetDefaultKeyring(nil)
+	_, err = repo.Load(context.Background(), a.ID)
+	assert.IsType(t, errors.InternalError{}, err)
+}
+
+func (test *TestAttachmentRepository) TestQuotaAndUsage() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+	require.Nil(t, configuration.Reload(map[string]string{"attachment.quota": "20"}))
+	defer configuration.Reload(nil)
+
+	repo := attachment.NewAttachmentRepository(test.DB)
+	a := attachment.Attachment{ProjectID: test.projectID, ContentType: "image/png", Content: png, CreatedBy: test.identityID}
+	_, err := repo.Create(context.Background(), &a)
+	require.Nil(t, err)
+
+	// the project uses 16 of its 20 bytes
+	big := attachment.Attachment{ProjectID: test.projectID, ContentType: "image/gif", Content: []byte("GIF89a"), CreatedBy: test.identityID}
+	_, err = repo.Create(context.Background(), &big)
+	assert.IsType(t, errors.BadParameterError{}, err)
+	small := attachment.Attachment{ProjectID: test.projectID, ContentType: "image/gif", Content: []byte("GIF8"), CreatedBy: test.identityID}
+	_, err = repo.Create(context.Background(), &small)
+	require.Nil(t, err)
+	// known content doesn't use more storage
+	again := attachment.Attachment{ProjectID: test.projectID, ContentType: "image/png", Content: png, CreatedBy: test.identityID}
+	_, err = repo.Create(context.Background(), &again)
+	require.Nil(t, err)
+
+	usages, err := repo.Usage(context.Background(), &test.projectID)
+	require.Nil(t, err)
+	require.Len(t, usages, 1)
+	assert.Equal(t, attachment.Usage{ProjectID: test.projectID, Count: 2, Size: 20}, *usages[0])
+
+	largest, err := repo.Largest(context.Background(), &test.projectID, 1)
+	require.Nil(t, err)
+	require.Len(t, largest, 1)
+	assert.Equal(t, a.ID, largest[0].ID)
+	assert.Nil(t, largest[0].Content)
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/automation"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// AutomationRuleController implements the automation-rule resource.
+type AutomationRuleController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewAutomationRuleController creates an automation-rule controller.
+func NewAutomationRuleController(service *goa.Service, db application.DB) *AutomationRuleController {
+	return &AutomationRuleController{Controller: service.NewController("AutomationRuleController"), db: db}
+}
+
+// Show runs the show action.
+func (c *AutomationRuleController) Show(ctx *app.ShowAutomationRuleContext) error {
+	return c.administrate(ctx, ctx.ID, func(appl application.Application, r *automation.Rule) error {
+		return ctx.OK(&app.AutomationRuleSingle{Data: ConvertAutomationRule(ctx.RequestData, r)})
+	})
+}
+
+// Update runs the update action.
+func (c *AutomationRuleController) Update(ctx *app.UpdateAutomationRuleContext) error {
+	changes, err := automationRuleFromPayload(ctx.Payload.Data)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return c.administrate(ctx, ctx.ID, func(appl application.Application, r *automation.Rule) error {
+		attrs := ctx.Payload.Data.Attributes
+		if attrs.Version == nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.version", nil).Expected("not nil"))
+		}
+		r.Version = *attrs.Version
+		if attrs.Name != nil {
+			r.Name = changes.Name
+		}
+		if attrs.Enabled != nil {
+			r.Enabled = changes.Enabled
+		}
+		if attrs.Trigger != nil {
+			r.TriggerKind = changes.TriggerKind
+			r.TriggerValue = changes.TriggerValue
+		}
+		if attrs.Conditions != nil {
+			r.Conditions = changes.Conditions
+		}
+		if attrs.Actions != nil {
+			r.Actions = changes.Actions
+		}
+		if err := appl.AutomationRules().Save(ctx, r); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.AutomationRuleSingle{Data: ConvertAutomationRule(ctx.RequestData, r)})
+	})
+}
+
+// Delete runs the delete action.
+func (c *AutomationRuleController) Delete(ctx *app.DeleteAutomationRuleContext) error {
+	return c.administrate(ctx, ctx.ID, func(appl application.Application, r *automation.Rule) error {
+		if err := appl.AutomationRules().Delete(ctx, r.ID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.NoContent()
+	})
+}
+
+// automationRuleContext is implemented by the contexts of the automation
+// rule actions
+type automationRuleContext interface {
+	context.Context
+	jsonapi.InternalServerError
+}
+
+// administrate runs the given function in a transaction if the current
+// identity administrates the project of the rule, the webhooks of the rules
+// are secrets of the admins
+func (c *AutomationRuleController) administrate(ctx automationRuleContext, id string, f func(appl application.Application, r *automation.Rule) error) error {
+	ruleID, err := uuid.FromString(id)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		r, err := appl.AutomationRules().Load(ctx, ruleID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if _, err := appl.Projects().Load(ctx, r.ProjectID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewNotFoundError("automation rule", id))
+		}
+		if err := checkProjectAdmin(ctx, appl, r.ProjectID, "manage the automation rules of the project"); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return f(appl, r)
+	})
+}
+
+// automationRuleFromPayload returns the rule described by the payload,
+// rules are enabled unless the payload says otherwise
+// returns BadParameterError
+func automationRuleFromPayload(data *app.AutomationRule) (*automation.Rule, error) {
+	if data == nil || data.Attributes == nil {
+		return nil, errors.NewBadParameterError("data.attributes", nil).Expected("not nil")
+	}
+	attrs := data.Attributes
+	r := automation.Rule{
+		Enabled:    attrs.Enabled == nil || *attrs.Enabled,
+		Conditions: automation.Conditions{},
+		Actions:    automation.Actions{},
+	}
+	if attrs.Name != nil {
+		r.Name = *attrs.Name
+	}
+	if attrs.Trigger != nil {
+		r.TriggerKind = attrs.Trigger.Kind
+		if attrs.Trigger.Value != nil {
+			r.TriggerValue = *attrs.Trigger.Value
+		}
+	}
+	for _, c := range attrs.Conditions {
+		condition := automation.Condition{Field: c.Field, Operator: c.Operator}
+		if c.Value != nil {
+			condition.Value = *c.Value
+		}
+		r.Conditions = append(r.Conditions, condition)
+	}
+	for _, a := range attrs.Actions {
+		action := automation.Action{Kind: a.Kind, Value: a.Value}
+		if a.Field != nil {
+			action.Field = *a.Field
+		}
+		r.Actions = append(r.Actions, action)
+	}
+	return &r, nil
+}
+
+// ConvertAutomationRule converts between internal and external REST representation
+func ConvertAutomationRule(request *goa.RequestData, r *automation.Rule) *app.AutomationRule {
+	selfURL := AbsoluteURL(request, app.AutomationRuleHref(r.ID))
+	trigger := &app.AutomationTrigger{Kind: r.TriggerKind}
+	if r.TriggerValue != "" {
+		value := r.TriggerValue
+		trigger.Value = &value
+	}
+	conditions := make([]*app.AutomationCondition, 0, len(r.Conditions))
+	for _, c := range r.Conditions {
+		condition := &app.AutomationCondition{Field: c.Field, Operator: c.Operator}
+		if c.Value != "" {
+			value := c.Value
+			condition.Value = &value
+		}
+		conditions = append(conditions, condition)
+	}
+	actions := make([]*app.AutomationAction, 0, len(r.Actions))
+	for _, a := range r.Actions {
+		action := &app.AutomationAction{Kind: a.Kind, Value: a.Value}
+		if a.Field != "" {
+			field := a.Field
+			action.Field = &field
+		}
+		actions = append(actions, action)
+	}
+	projectType := "projects"
+	projectID := r.ProjectID.String()
+	projectSelfURL := AbsoluteURL(request, app.ProjectHref(projectID))
+	identityType := "identities"
+	creatorID := r.CreatedBy.String()
+	return &app.AutomationRule{
+		Type: "automationrules",
+		ID:   &r.ID,
+		Attributes: &app.AutomationRuleAttributes{
+			Name:       &r.Name,
+			Enabled:    &r.Enabled,
+			Trigger:    trigger,
+			Conditions: conditions,
+			Actions:    actions,
+			Version:    &r.Version,
+			CreatedAt:  &r.CreatedAt,
+		},
+		Relationships: &app.AutomationRuleRelations{
+			Project: &app.RelationGeneric{
+				Data:  &app.GenericData{Type: &projectType, ID: &projectID},
+				Links: &app.GenericLinks{Self: &projectSelfURL},
+			},
+			Creator: &app.RelationGeneric{
+				Data: &app.GenericData{Type: &identityType, ID: &creatorID},
+			},
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
+
+// automate runs the enabled automation rules of the project of the work item
+// on its change from before to wi, before is nil for new work items and
+// added is the text of a comment added to the work item. Rules act with
+// the rights of the admins who configure them, the returned work item is
+// the one changed by the rules as the viewer of ctx sees it.
+func automate(ctx context.Context, appl application.Application, request *goa.RequestData, before *app.WorkItem, wi *app.WorkItem, added string) (*app.WorkItem, error) {
+	p, _ := wi.Fields[workitem.SystemProject].(string)
+	projectID, err := uuid.FromString(p)
+	if err != nil || wi.Fields[workitem.SystemPendingReview] == true || wi.Fields[workitem.SystemDraft] == true {
+		return wi, nil
+	}
+	rules, err := appl.AutomationRules().ListEnabled(ctx, projectID)
+	if err != nil || len(rules) == 0 {
+		return wi, err
+	}
+
+	system := workitem.WithViewer(ctx, nil)
+	current, err := appl.WorkItems().Load(system, wi.ID)
+	if err != nil {
+		return nil, err
+	}
+	e := automation.Event{After: copyFields(current.Fields), Comment: added}
+	if before != nil {
+		e.Before = before.Fields
+	}
+	fired := map[uuid.UUID]bool{}
+	changed := false
+	for round := 0; round < automation.MaxRounds; round++ {
+		previous := copyFields(current.Fields)
+		saved, comments, err := runAutomationRules(system, appl, request, rules, fired, e, current)
+		if err != nil {
+			return nil, err
+		}
+		if saved {
+			current, err = appl.WorkItems().Save(system, *current)
+			if err != nil {
+				return nil, err
+			}
+			changed = true
+		}
+		if !saved && len(comments) == 0 {
+			break
+		}
+		e = automation.Event{Before: previous, After: copyFields(current.Fields), Comment: strings.Join(comments, "\n")}
+	}
+	if !changed {
+		return wi, nil
+	}
+	res, err := appl.WorkItems().Load(ctx, wi.ID)
+	if _, ok := err.(errors.NotFoundError); ok {
+		// the rules hid the work item from the viewer
+		return wi, nil
+	}
+	return res, err
+}
+
+// runAutomationRules does the actions of the rules that didn't fire yet
+// and fire for the event, the fields of wi are changed in place. It returns
+// if wi must be saved and the added comments.
+func runAutomationRules(ctx context.Context, appl application.Application, request *goa.RequestData, rules []*automation.Rule, fired map[uuid.UUID]bool, e automation.Event, wi *app.WorkItem) (bool, []string, error) {
+	save := false
+	var comments []string
+	for _, r := range rules {
+		if fired[r.ID] || !r.Fires(e) {
+			continue
+		}
+		fired[r.ID] = true
+		for _, a := range r.Actions {
+			if a.Apply(wi.Fields) {
+				save = true
+				continue
+			}
+			switch a.Kind {
+			case automation.ActionAddComment:
+				c := comment.Comment{ParentID: wi.ID, Body: a.Value, CreatedBy: r.CreatedBy}
+				if err := appl.Comments().Create(ctx, &c); err != nil {
+					return false, nil, err
+				}
+				comments = append(comments, a.Value)
+			case automation.ActionCallWebhook:
+				err := automation.CallWebhook(ctx, appl.Jobs(), automation.Webhook{
+					URL:      a.Value,
+					RuleID:   r.ID,
+					RuleName: r.Name,
+					Type:     wi.Type,
+					ID:       wi.ID,
+					Link:     AbsoluteURL(request, app.WorkitemHref(wi.ID)),
+					Fields:   copyFields(wi.Fields),
+				})
+				if err != nil {
+					return false, nil, err
+				}
+			}
+		}
+	}
+	return save, comments, nil
+}
+
+// copyFields returns a shallow copy of the fields of a work item
+func copyFields(fields map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		res[k] = v
+	}
+	return res
+}
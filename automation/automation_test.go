@@ -0,0 +1,89 @@
+package automation_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/automation"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	valid := automation.Rule{
+		Name:        "Reopen regressions",
+		TriggerKind: automation.TriggerCommentContains,
+		Conditions:  automation.Conditions{{Field: workitem.SystemState, Operator: automation.OperatorEquals, Value: "closed"}},
+		Actions: automation.Actions{
+			{Kind: automation.ActionSetField, Field: workitem.SystemState, Value: "open"},
+			{Kind: automation.ActionCallWebhook, Value: "https://example.com/hook"},
+		},
+	}
+	// comment-contains needs a text
+	assert.NotNil(t, valid.Validate())
+	valid.TriggerValue = "regression"
+	assert.Nil(t, valid.Validate())
+
+	for _, change := range []func(r *automation.Rule){
+		func(r *automation.Rule) { r.Name = "" },
+		func(r *automation.Rule) { r.TriggerKind = "created" },
+		func(r *automation.Rule) { r.Conditions[0].Operator = "like" },
+		func(r *automation.Rule) { r.Actions = nil },
+		func(r *automation.Rule) { r.Actions[0].Field = workitem.SystemProject },
+		func(r *automation.Rule) {
+			r.Actions[0] = automation.Action{Kind: automation.ActionAssign, Value: "joe"}
+		},
+		func(r *automation.Rule) { r.Actions[1].Value = "ftp://example.com" },
+	} {
+		r := valid
+		r.Conditions = append(automation.Conditions{}, valid.Conditions...)
+		r.Actions = append(automation.Actions{}, valid.Actions...)
+		change(&r)
+		assert.NotNil(t, r.Validate(), "%+v", r)
+	}
+}
+
+func TestFires(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	before := map[string]interface{}{workitem.SystemState: "open", workitem.SystemLabels: []interface{}{"ui"}}
+	after := map[string]interface{}{workitem.SystemState: "resolved", workitem.SystemLabels: []interface{}{"ui", "urgent"}, workitem.SystemTitle: "Login fails"}
+
+	r := automation.Rule{TriggerKind: automation.TriggerStateChanged, TriggerValue: "resolved"}
+	assert.True(t, r.Fires(automation.Event{Before: before, After: after}))
+	assert.False(t, r.Fires(automation.Event{After: after}), "created work items don't change their state")
+	assert.False(t, r.Fires(automation.Event{Before: after, After: after}))
+	r.TriggerValue = "closed"
+	assert.False(t, r.Fires(automation.Event{Before: before, After: after}))
+
+	r = automation.Rule{TriggerKind: automation.TriggerLabelAdded, TriggerValue: "urgent"}
+	assert.True(t, r.Fires(automation.Event{Before: before, After: after}))
+	r.TriggerValue = "ui"
+	assert.False(t, r.Fires(automation.Event{Before: before, After: after}))
+	assert.True(t, r.Fires(automation.Event{After: after}))
+
+	r = automation.Rule{TriggerKind: automation.TriggerCommentContains, TriggerValue: "Regression"}
+	assert.True(t, r.Fires(automation.Event{Before: after, After: after, Comment: "a regression of #12"}))
+	assert.False(t, r.Fires(automation.Event{Before: before, After: after}))
+
+	r.Conditions = automation.Conditions{
+		{Field: workitem.SystemLabels, Operator: automation.OperatorContains, Value: "urgent"},
+		{Field: workitem.SystemTitle, Operator: automation.OperatorContains, Value: "login"},
+		{Field: workitem.SystemAssignees, Operator: automation.OperatorEmpty},
+	}
+	assert.True(t, r.Fires(automation.Event{Before: after, After: after, Comment: "regression"}))
+	r.Conditions = append(r.Conditions, automation.Condition{Field: workitem.SystemState, Operator: automation.OperatorNotEquals, Value: "resolved"})
+	assert.False(t, r.Fires(automation.Event{Before: after, After: after, Comment: "regression"}))
+}
+
+func TestApply(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	fields := map[string]interface{}{workitem.SystemState: "open"}
+	assert.True(t, automation.Action{Kind: automation.ActionSetField, Field: workitem.SystemState, Value: "closed"}.Apply(fields))
+	assert.True(t, automation.Action{Kind: automation.ActionAssign, Value: "joe"}.Apply(fields))
+	assert.False(t, automation.Action{Kind: automation.ActionAddComment, Value: "Closed"}.Apply(fields))
+	assert.Equal(t, map[string]interface{}{workitem.SystemState: "closed", workitem.SystemAssignees: []string{"joe"}}, fields)
+}
@@ -0,0 +1,200 @@
+package automation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/breaker"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/job"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+)
+
+// MaxRounds bounds the chain of rules fired by the changes made by other
+// rules. Rules run at most once per change, the bound also protects against
+// rules that keep changing each other's fields.
+const MaxRounds = 10
+
+// WebhookJobKind is the kind of the jobs calling the webhooks of rules
+const WebhookJobKind = "automation.webhook"
+
+// Event is a change of a work item rules can fire on
+type Event struct {
+	// Before holds the fields before the change, nil for created work items
+	Before map[string]interface{}
+	After  map[string]interface{}
+	// Comment is the text of the comments added by the change
+	Comment string
+}
+
+// Fires returns true if the trigger of the rule fires for the event and
+// all its conditions hold for the fields after it
+func (m Rule) Fires(e Event) bool {
+	switch m.TriggerKind {
+	case TriggerStateChanged:
+		if e.Before == nil {
+			return false
+		}
+		before, after := text(e.Before[workitem.SystemState]), text(e.After[workitem.SystemState])
+		if before == after || (m.TriggerValue != "" && after != m.TriggerValue) {
+			return false
+		}
+	case TriggerLabelAdded:
+		added := false
+		old := list(e.Before[workitem.SystemLabels])
+		for _, label := range list(e.After[workitem.SystemLabels]) {
+			if !contains(old, label) && (m.TriggerValue == "" || label == m.TriggerValue) {
+				added = true
+			}
+		}
+		if !added {
+			return false
+		}
+	case TriggerCommentContains:
+		if e.Comment == "" || !strings.Contains(strings.ToLower(e.Comment), strings.ToLower(m.TriggerValue)) {
+			return false
+		}
+	default:
+		return false
+	}
+	for _, c := range m.Conditions {
+		if !c.Holds(e.After) {
+			return false
+		}
+	}
+	return true
+}
+
+// Holds returns true if the condition holds for the given fields
+func (c Condition) Holds(fields map[string]interface{}) bool {
+	v := fields[c.Field]
+	switch c.Operator {
+	case OperatorEquals:
+		return text(v) == c.Value
+	case OperatorNotEquals:
+		return text(v) != c.Value
+	case OperatorContains:
+		if _, ok := v.(string); ok {
+			return strings.Contains(strings.ToLower(text(v)), strings.ToLower(c.Value))
+		}
+		return contains(list(v), c.Value)
+	case OperatorEmpty:
+		return text(v) == "" && len(list(v)) == 0
+	case OperatorNotEmpty:
+		return text(v) != "" || len(list(v)) > 0
+	}
+	return false
+}
+
+// Apply sets the fields changed by the action, returns false for actions
+// that don't change fields
+func (a Action) Apply(fields map[string]interface{}) bool {
+	switch a.Kind {
+	case ActionSetField:
+		fields[a.Field] = a.Value
+	case ActionAssign:
+		fields[workitem.SystemAssignees] = []string{a.Value}
+	default:
+		return false
+	}
+	return true
+}
+
+// Webhook is the payload of the jobs calling webhooks, it is posted as is
+type Webhook struct {
+	URL      string                 `json:"-"`
+	RuleID   uuid.UUID              `json:"rule"`
+	RuleName string                 `json:"rule-name"`
+	Type     string                 `json:"type"`
+	ID       string                 `json:"id"`
+	Link     string                 `json:"link"`
+	Fields   map[string]interface{} `json:"fields"`
+}
+
+// webhookJob carries the URL the job payload is posted to
+type webhookJob struct {
+	URL     string  `json:"url"`
+	Webhook Webhook `json:"webhook"`
+}
+
+// CallWebhook enqueues posting the webhook, within a transaction it is only
+// posted if the transaction commits
+// returns BadParameterError or InternalError
+func CallWebhook(ctx context.Context, jobs job.Repository, w Webhook) error {
+	_, err := jobs.Enqueue(ctx, WebhookJobKind, webhookJob{URL: w.URL, Webhook: w})
+	return err
+}
+
+// WebhookJob returns the handler of the jobs calling webhooks, the requests
+// time out after the given duration
+func WebhookJob(timeout time.Duration) job.Handler {
+	client := &http.Client{Timeout: timeout}
+	return func(ctx context.Context, payload []byte) error {
+		var j webhookJob
+		if err := json.Unmarshal(payload, &j); err != nil {
+			return errors.NewConversionError(err.Error())
+		}
+		body, err := json.Marshal(j.Webhook)
+		if err != nil {
+			return errors.NewConversionError(err.Error())
+		}
+		req, err := http.NewRequest("POST", j.URL, bytes.NewReader(body))
+		if err != nil {
+			return errors.NewBadParameterError("url", j.URL)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		res, err := breaker.For("webhook "+req.URL.Host).Request(client, req)
+		if err != nil {
+			return err
+		}
+		res.Body.Close()
+		if res.StatusCode < 200 || res.StatusCode >= 300 {
+			return fmt.Errorf("the webhook of rule %s responded %s", j.Webhook.RuleID, res.Status)
+		}
+		return nil
+	}
+}
+
+// text returns the value of a single valued field as string, empty for nil
+func text(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []string, []interface{}:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// list returns the values of a list field as strings
+func list(v interface{}) []string {
+	switch l := v.(type) {
+	case []string:
+		return l
+	case []interface{}:
+		res := make([]string, 0, len(l))
+		for _, s := range l {
+			res = append(res, text(s))
+		}
+		return res
+	}
+	return nil
+}
+
+func contains(l []string, s string) bool {
+	for _, v := range l {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,334 @@
+// Package automation runs the rules of the projects on the changes of their
+// work items: when the trigger of an enabled rule fires and its conditions
+// hold, its actions set fields, assign, comment or call a webhook. Rules run
+// in the transaction of the change, the changes made by their actions can
+// fire further rules but every rule runs at most once per change.
+package automation
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Kinds of triggers
+const (
+	// TriggerStateChanged fires when the state of a work item changes to the
+	// value of the trigger, or to any state if it has none
+	TriggerStateChanged = "state-changed"
+	// TriggerLabelAdded fires when the label of the trigger is added to a
+	// work item, or any label if it has none
+	TriggerLabelAdded = "label-added"
+	// TriggerCommentContains fires when a comment containing the value of the
+	// trigger is added to a work item, ignoring case
+	TriggerCommentContains = "comment-contains"
+)
+
+// Operators of conditions
+const (
+	OperatorEquals    = "equals"
+	OperatorNotEquals = "not-equals"
+	// OperatorContains matches text fields containing the value and list
+	// fields holding it
+	OperatorContains = "contains"
+	OperatorEmpty    = "empty"
+	OperatorNotEmpty = "not-empty"
+)
+
+// Kinds of actions
+const (
+	// ActionSetField sets the field of the action to its value
+	ActionSetField = "set-field"
+	// ActionAssign assigns the work item to the identity with the ID given as
+	// value, replacing the assignees
+	ActionAssign = "assign"
+	// ActionAddComment comments the value on behalf of the creator of the rule
+	ActionAddComment = "add-comment"
+	// ActionCallWebhook posts the work item to the URL given as value
+	ActionCallWebhook = "call-webhook"
+)
+
+// MaxConditions is the number of conditions a rule can have
+const MaxConditions = 10
+
+// MaxActions is the number of actions a rule can have
+const MaxActions = 10
+
+// protectedFields can't be set by actions
+var protectedFields = map[string]bool{
+	workitem.SystemProject:   true,
+	workitem.SystemCreator:   true,
+	workitem.SystemCreatedAt: true,
+}
+
+// Condition compares a field of the work item with a value, empty and
+// not-empty take no value
+type Condition struct {
+	Field    string `json:"field"`
+	Operator string `json:"operator"`
+	Value    string `json:"value,omitempty"`
+}
+
+// Conditions must all hold for a rule to run
+type Conditions []Condition
+
+// Value implements the driver.Valuer interface
+func (cs Conditions) Value() (driver.Value, error) {
+	if cs == nil {
+		cs = Conditions{}
+	}
+	return json.Marshal(cs)
+}
+
+// Scan implements the sql.Scanner interface
+func (cs *Conditions) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("scan source was not []byte")
+	}
+	return json.Unmarshal(b, cs)
+}
+
+// Action is done when a rule runs, Field is only used by set-field
+type Action struct {
+	Kind  string `json:"kind"`
+	Field string `json:"field,omitempty"`
+	Value string `json:"value"`
+}
+
+// Actions are done in their order
+type Actions []Action
+
+// Value implements the driver.Valuer interface
+func (as Actions) Value() (driver.Value, error) {
+	if as == nil {
+		as = Actions{}
+	}
+	return json.Marshal(as)
+}
+
+// Scan implements the sql.Scanner interface
+func (as *Actions) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("scan source was not []byte")
+	}
+	return json.Unmarshal(b, as)
+}
+
+// Rule does its actions on the work items of a project when its trigger
+// fires and its conditions hold
+type Rule struct {
+	gormsupport.Lifecycle
+	ID           uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	ProjectID    uuid.UUID `sql:"type:uuid"`
+	Name         string
+	Enabled      bool
+	TriggerKind  string
+	TriggerValue string
+	Conditions   Conditions `sql:"type:jsonb"`
+	Actions      Actions    `sql:"type:jsonb"`
+	// CreatedBy is the author of the comments added by the rule
+	CreatedBy uuid.UUID `sql:"type:uuid"`
+	Version   int
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m Rule) TableName() string {
+	return "automation_rules"
+}
+
+// Validate checks the name, the trigger, the conditions and the actions of
+// the rule
+// returns BadParameterError
+func (m Rule) Validate() error {
+	if m.Name == "" {
+		return errors.NewBadParameterError("name", m.Name).Expected("not empty")
+	}
+	switch m.TriggerKind {
+	case TriggerStateChanged, TriggerLabelAdded:
+	case TriggerCommentContains:
+		if m.TriggerValue == "" {
+			return errors.NewBadParameterError("trigger.value", m.TriggerValue).Expected("the text comments must contain")
+		}
+	default:
+		return errors.NewBadParameterError("trigger.kind", m.TriggerKind).Expected([]string{TriggerStateChanged, TriggerLabelAdded, TriggerCommentContains})
+	}
+	if len(m.Conditions) > MaxConditions {
+		return errors.NewBadParameterError("conditions", len(m.Conditions)).Expected(fmt.Sprintf("at most %d conditions", MaxConditions))
+	}
+	for _, c := range m.Conditions {
+		if c.Field == "" {
+			return errors.NewBadParameterError("conditions.field", c.Field).Expected("not empty")
+		}
+		switch c.Operator {
+		case OperatorEquals, OperatorNotEquals, OperatorContains, OperatorEmpty, OperatorNotEmpty:
+		default:
+			return errors.NewBadParameterError("conditions.operator", c.Operator).Expected([]string{OperatorEquals, OperatorNotEquals, OperatorContains, OperatorEmpty, OperatorNotEmpty})
+		}
+	}
+	if len(m.Actions) == 0 || len(m.Actions) > MaxActions {
+		return errors.NewBadParameterError("actions", len(m.Actions)).Expected(fmt.Sprintf("between 1 and %d actions", MaxActions))
+	}
+	for _, a := range m.Actions {
+		switch a.Kind {
+		case ActionSetField:
+			if a.Field == "" || protectedFields[a.Field] || a.Field == workitem.SystemAssignees {
+				return errors.NewBadParameterError("actions.field", a.Field).Expected("a field that isn't the project, the creator or the assignees")
+			}
+		case ActionAssign:
+			if _, err := uuid.FromString(a.Value); err != nil {
+				return errors.NewBadParameterError("actions.value", a.Value).Expected("the ID of an identity")
+			}
+		case ActionAddComment:
+			if a.Value == "" {
+				return errors.NewBadParameterError("actions.value", a.Value).Expected("the text of the comment")
+			}
+		case ActionCallWebhook:
+			u, err := url.Parse(a.Value)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return errors.NewBadParameterError("actions.value", a.Value).Expected("an http or https URL")
+			}
+		default:
+			return errors.NewBadParameterError("actions.kind", a.Kind).Expected([]string{ActionSetField, ActionAssign, ActionAddComment, ActionCallWebhook})
+		}
+	}
+	return nil
+}
+
+// Repository describes interactions with automation rules
+type Repository interface {
+	Create(ctx context.Context, r *Rule) error
+	Load(ctx context.Context, id uuid.UUID) (*Rule, error)
+	Save(ctx context.Context, r *Rule) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, projectID uuid.UUID) ([]*Rule, error)
+	ListEnabled(ctx context.Context, projectID uuid.UUID) ([]*Rule, error)
+}
+
+// NewRuleRepository creates a new storage type.
+func NewRuleRepository(db *gorm.DB) Repository {
+	return &GormRuleRepository{db: db}
+}
+
+// GormRuleRepository is the implementation of the storage interface for
+// automation rules.
+type GormRuleRepository struct {
+	db *gorm.DB
+}
+
+// Create stores a new rule
+// returns BadParameterError or InternalError
+func (m *GormRuleRepository) Create(ctx context.Context, r *Rule) error {
+	defer goa.MeasureSince([]string{"goa", "db", "automationrule", "create"}, time.Now())
+
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	r.ID = uuid.NewV4()
+	r.Version = 0
+	if err := m.db.Create(r).Error; err != nil {
+		goa.LogError(ctx, "error adding Rule", "error", err.Error())
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// Load returns the rule with the given ID
+// returns NotFoundError or InternalError
+func (m *GormRuleRepository) Load(ctx context.Context, id uuid.UUID) (*Rule, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "automationrule", "load"}, time.Now())
+
+	var obj Rule
+	tx := m.db.Where("id = ?", id).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("automation rule", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
+
+// Save updates the rule, the version of r must match the stored one. The
+// project and the creator can't change.
+// returns NotFoundError, BadParameterError, VersionConflictError or InternalError
+func (m *GormRuleRepository) Save(ctx context.Context, r *Rule) error {
+	defer goa.MeasureSince([]string{"goa", "db", "automationrule", "save"}, time.Now())
+
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	if _, err := m.Load(ctx, r.ID); err != nil {
+		return err
+	}
+	tx := m.db.Model(&Rule{}).Where("id = ? AND version = ?", r.ID, r.Version).Updates(map[string]interface{}{
+		"name":          r.Name,
+		"enabled":       r.Enabled,
+		"trigger_kind":  r.TriggerKind,
+		"trigger_value": r.TriggerValue,
+		"conditions":    r.Conditions,
+		"actions":       r.Actions,
+		"version":       r.Version + 1,
+	})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewVersionConflictError("version conflict")
+	}
+	r.Version++
+	return nil
+}
+
+// Delete removes the rule with the given ID
+// returns NotFoundError or InternalError
+func (m *GormRuleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "automationrule", "delete"}, time.Now())
+
+	tx := m.db.Where("id = ?", id).Delete(&Rule{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("automation rule", id.String())
+	}
+	return nil
+}
+
+// List returns the rules of the project in the order they were created
+// returns InternalError
+func (m *GormRuleRepository) List(ctx context.Context, projectID uuid.UUID) ([]*Rule, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "automationrule", "list"}, time.Now())
+
+	var rows []*Rule
+	if err := m.db.Where("project_id = ?", projectID).Order("created_at, id").Find(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return rows, nil
+}
+
+// ListEnabled returns the enabled rules of the project in the order they
+// run
+// returns InternalError
+func (m *GormRuleRepository) ListEnabled(ctx context.Context, projectID uuid.UUID) ([]*Rule, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "automationrule", "listenabled"}, time.Now())
+
+	var rows []*Rule
+	if err := m.db.Where("project_id = ? AND enabled", projectID).Order("created_at, id").Find(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return rows, nil
+}
@@ -0,0 +1,202 @@
+// Package backup takes logical backups of a project or of the whole instance
+// and restores them. A backup is a consistent snapshot of the rows of the
+// backed up tables written as gzipped JSON lines to an object store. Backups
+// and restores are runs of background jobs that record their progress.
+package backup
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Operations of runs
+const (
+	OperationBackup  = "backup"
+	OperationRestore = "restore"
+)
+
+// Status of a run
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Run is a backup, or a restore of one
+type Run struct {
+	gormsupport.Lifecycle
+	ID        uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	Operation string
+	// ProjectID is the backed up project, nil for the whole instance
+	ProjectID *uuid.UUID `sql:"type:uuid"`
+	// BackupID is the backup a restore reads
+	BackupID *uuid.UUID `sql:"type:uuid"`
+	// Object is the name of the backup in the object store
+	Object     string
+	Status     string
+	Tables     int
+	TablesDone int
+	Rows       int64
+	Error      string
+	CreatorID  *uuid.UUID `sql:"type:uuid"`
+	FinishedAt *time.Time
+}
+
+// TableName implements gorm.tabler
+func (r Run) TableName() string {
+	return "backup_runs"
+}
+
+// Repository encapsulates storage & retrieval of backup runs
+type Repository interface {
+	Create(ctx context.Context, r *Run) error
+	Load(ctx context.Context, id uuid.UUID) (*Run, error)
+	List(ctx context.Context, projectID *uuid.UUID) ([]*Run, error)
+	Start(ctx context.Context, id uuid.UUID, tables int) error
+	Progress(ctx context.Context, id uuid.UUID, tablesDone int, rows int64) error
+	Finish(ctx context.Context, id uuid.UUID, cause error) error
+}
+
+// NewBackupRepository creates a new storage type.
+func NewBackupRepository(db *gorm.DB) Repository {
+	return &GormBackupRepository{db: db}
+}
+
+// GormBackupRepository is the implementation of the storage interface for
+// backup runs.
+type GormBackupRepository struct {
+	db *gorm.DB
+}
+
+// Create stores a new pending run. Backups get the name of their object,
+// restores must refer to a succeeded backup and restore its scope.
+// returns BadParameterError, NotFoundError or InternalError
+func (m *GormBackupRepository) Create(ctx context.Context, r *Run) error {
+	defer goa.MeasureSince([]string{"goa", "db", "backup", "create"}, time.Now())
+
+	r.ID = uuid.NewV4()
+	switch r.Operation {
+	case OperationBackup:
+		r.BackupID = nil
+		r.Object = objectName(r.ID, r.ProjectID)
+	case OperationRestore:
+		if r.BackupID == nil {
+			return errors.NewBadParameterError("backup", nil).Expected("not nil")
+		}
+		b, err := m.Load(ctx, *r.BackupID)
+		if err != nil {
+			return err
+		}
+		if b.Operation != OperationBackup || b.Status != StatusSucceeded {
+			return errors.NewBadParameterError("backup", b.ID.String()).Expected("a succeeded backup")
+		}
+		r.ProjectID = b.ProjectID
+		r.Object = b.Object
+	default:
+		return errors.NewBadParameterError("operation", r.Operation).Expected([]string{OperationBackup, OperationRestore})
+	}
+	r.Status = StatusPending
+	r.Tables, r.TablesDone, r.Rows, r.Error, r.FinishedAt = 0, 0, 0, "", nil
+	if err := m.db.Create(r).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// Load returns the run with the given ID
+// returns NotFoundError or InternalError
+func (m *GormBackupRepository) Load(ctx context.Context, id uuid.UUID) (*Run, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "backup", "load"}, time.Now())
+
+	var obj Run
+	tx := m.db.Where("id = ?", id).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("backup", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
+
+// List returns the runs of the project, or of all projects and the instance
+// if projectID is nil, most recent first
+// returns InternalError
+func (m *GormBackupRepository) List(ctx context.Context, projectID *uuid.UUID) ([]*Run, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "backup", "list"}, time.Now())
+
+	db := m.db.Order("created_at desc")
+	if projectID != nil {
+		db = db.Where("project_id = ?", *projectID)
+	}
+	var objs []*Run
+	if err := db.Find(&objs).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return objs, nil
+}
+
+// Start marks the run as running through the given number of tables, a run
+// started again after a failure starts over
+// returns NotFoundError or InternalError
+func (m *GormBackupRepository) Start(ctx context.Context, id uuid.UUID, tables int) error {
+	defer goa.MeasureSince([]string{"goa", "db", "backup", "start"}, time.Now())
+
+	return m.update(id, map[string]interface{}{
+		"status":      StatusRunning,
+		"tables":      tables,
+		"tables_done": 0,
+		"rows":        0,
+		"error":       "",
+		"finished_at": nil,
+	})
+}
+
+// Progress records the number of tables and rows the run went through
+// returns NotFoundError or InternalError
+func (m *GormBackupRepository) Progress(ctx context.Context, id uuid.UUID, tablesDone int, rows int64) error {
+	defer goa.MeasureSince([]string{"goa", "db", "backup", "progress"}, time.Now())
+
+	return m.update(id, map[string]interface{}{"tables_done": tablesDone, "rows": rows})
+}
+
+// Finish marks the run as succeeded, or as failed with the given cause
+// returns NotFoundError or InternalError
+func (m *GormBackupRepository) Finish(ctx context.Context, id uuid.UUID, cause error) error {
+	defer goa.MeasureSince([]string{"goa", "db", "backup", "finish"}, time.Now())
+
+	values := map[string]interface{}{"status": StatusSucceeded, "finished_at": time.Now()}
+	if cause != nil {
+		values["status"] = StatusFailed
+		values["error"] = cause.Error()
+	}
+	return m.update(id, values)
+}
+
+func (m *GormBackupRepository) update(id uuid.UUID, values map[string]interface{}) error {
+	tx := m.db.Model(&Run{}).Where("id = ?", id).UpdateColumns(values)
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("backup", id.String())
+	}
+	return nil
+}
+
+// objectName returns the name of the object of a backup
+func objectName(id uuid.UUID, projectID *uuid.UUID) string {
+	scope := "instance"
+	if projectID != nil {
+		scope = "project-" + projectID.String()
+	}
+	return scope + "/" + id.String() + ".jsonl.gz"
+}
@@ -0,0 +1,160 @@
+package backup_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/backup"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestFileStore(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	dir, err := ioutil.TempDir("", "backups")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	store, err := backup.NewStore("file://"+dir, "")
+	require.Nil(t, err)
+	_, err = store.Get(ctx, "instance/missing.jsonl.gz")
+	assert.IsType(t, errors.NotFoundError{}, err)
+	require.Nil(t, store.Put(ctx, "instance/backup.jsonl.gz", bytes.NewBufferString("data")))
+	obj, err := store.Get(ctx, "instance/backup.jsonl.gz")
+	require.Nil(t, err)
+	defer obj.Close()
+	data, err := ioutil.ReadAll(obj)
+	require.Nil(t, err)
+	assert.Equal(t, "data", string(data))
+
+	_, err = backup.NewStore("", "")
+	assert.IsType(t, errors.BadParameterError{}, err)
+	_, err = backup.NewStore("ftp://example.com", "")
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
+
+type TestBackupRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunBackupRepository(t *testing.T) {
+	suite.Run(t, &TestBackupRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestBackupRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestBackupRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestBackupRepository) TestRuns() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := backup.NewBackupRepository(test.DB)
+	b := backup.Run{Operation: backup.OperationBackup}
+	require.Nil(t, repo.Create(ctx, &b))
+	assert.Equal(t, backup.StatusPending, b.Status)
+	assert.Equal(t, "instance/"+b.ID.String()+".jsonl.gz", b.Object)
+
+	// only succeeded backups can be restored
+	r := backup.Run{Operation: backup.OperationRestore, BackupID: &b.ID}
+	assert.IsType(t, errors.BadParameterError{}, repo.Create(ctx, &r))
+
+	require.Nil(t, repo.Start(ctx, b.ID, 3))
+	require.Nil(t, repo.Progress(ctx, b.ID, 2, 42))
+	loaded, err := repo.Load(ctx, b.ID)
+	require.Nil(t, err)
+	assert.Equal(t, backup.StatusRunning, loaded.Status)
+	assert.Equal(t, 3, loaded.Tables)
+	assert.Equal(t, 2, loaded.TablesDone)
+	assert.Equal(t, int64(42), loaded.Rows)
+	require.Nil(t, repo.Finish(ctx, b.ID, nil))
+
+	require.Nil(t, repo.Create(ctx, &r))
+	assert.Equal(t, b.Object, r.Object)
+	require.Nil(t, repo.Finish(ctx, r.ID, errors.NewInternalError("boom")))
+	loaded, err = repo.Load(ctx, r.ID)
+	require.Nil(t, err)
+	assert.Equal(t, backup.StatusFailed, loaded.Status)
+	assert.NotEmpty(t, loaded.Error)
+	assert.NotNil(t, loaded.FinishedAt)
+}
+
+func (test *TestBackupRepository) TestDumpAndRestoreProject() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	p, err := project.NewRepository(test.DB).Create(ctx, "backup-"+uuid.NewV4().String())
+	require.Nil(t, err)
+	wi, err := workitem.NewWorkItemRepository(test.DB).Create(ctx, workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle:   "backed up",
+		workitem.SystemState:   workitem.SystemStateNew,
+		workitem.SystemProject: p.ID.String(),
+	}, "xx")
+	require.Nil(t, err)
+	require.Nil(t, comment.NewCommentRepository(test.DB).Create(ctx, &comment.Comment{ParentID: wi.ID, Body: "kept", CreatedBy: uuid.NewV4()}))
+
+	var buf bytes.Buffer
+	tx := test.DB.Begin()
+	tables := 0
+	err = backup.NewDumper(tx).Dump(ctx, &p.ID, &buf, func(done int, rows int64) error {
+		tables = done
+		return nil
+	})
+	tx.Rollback()
+	require.Nil(t, err)
+	assert.Equal(t, backup.NewDumper(test.DB).Tables(&p.ID), tables)
+
+	// the rows exist, restoring them fails
+	tx = test.DB.Begin()
+	_, err = backup.NewDumper(tx).Restore(ctx, bytes.NewReader(buf.Bytes()), func(int, int64) error { return nil })
+	tx.Rollback()
+	assert.IsType(t, errors.DataConflictError{}, err)
+
+	require.Nil(t, test.DB.Exec("DELETE FROM comments WHERE parent_id = ?", wi.ID).Error)
+	require.Nil(t, test.DB.Exec("DELETE FROM work_items WHERE id = ?", wi.ID).Error)
+	require.Nil(t, test.DB.Exec("DELETE FROM projects WHERE id = ?", p.ID).Error)
+	tx = test.DB.Begin()
+	var rows int64
+	h, err := backup.NewDumper(tx).Restore(ctx, bytes.NewReader(buf.Bytes()), func(done int, n int64) error {
+		rows = n
+		return nil
+	})
+	require.Nil(t, err)
+	require.Nil(t, tx.Commit().Error)
+	require.NotNil(t, h.ProjectID)
+	assert.Equal(t, p.ID, *h.ProjectID)
+	// project, work item, its create event and the comment
+	assert.Equal(t, int64(4), rows)
+
+	restored, err := workitem.NewWorkItemRepository(test.DB).Load(ctx, wi.ID)
+	require.Nil(t, err)
+	assert.Equal(t, "backed up", restored.Fields[workitem.SystemTitle])
+	comments, err := comment.NewCommentRepository(test.DB).List(ctx, wi.ID)
+	require.Nil(t, err)
+	assert.Len(t, comments, 1)
+
+	test.DB.Exec("DELETE FROM comments WHERE parent_id = ?", wi.ID)
+	test.DB.Exec("DELETE FROM work_items WHERE id = ?", wi.ID)
+	test.DB.Exec("DELETE FROM projects WHERE id = ?", p.ID)
+}
@@ -0,0 +1,296 @@
+package backup
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// FormatVersion is the version of the format of the backups
+const FormatVersion = 1
+
+// progressRows is the number of rows after which a restore reports its
+// progress within a table
+const progressRows = 1000
+
+// table is a backed up table. Project backups include the rows matching
+// projectFilter, every ? of it is the ID of the project. Tables without
+// filter are only included in instance backups.
+type table struct {
+	name          string
+	projectFilter string
+}
+
+// projectItems are the IDs of the work items of the project
+var projectItems = fmt.Sprintf("SELECT id FROM work_items WHERE fields->>'%s' = ?", workitem.SystemProject)
+
+// tables are the backed up tables, every table comes after the tables it
+// refers to. Operational tables (jobs, outbox, backup runs) aren't backed up.
+var tables = []table{
+	{"identities", ""},
+	{"users", ""},
+	{"work_item_types", ""},
+	{"work_item_link_categories", ""},
+	{"work_item_link_types", ""},
+	{"trackers", ""},
+	{"tracker_queries", ""},
+	{"tracker_items", ""},
+	{"settings", ""},
+	{"deployments", ""},
+	{"projects", "id = ?"},
+	{"project_admins", "project_id = ?"},
+	{"iterations", "project_id = ?"},
+	{"codebases", "project_id = ?"},
+	{"codebase_builds", "codebase_id IN (SELECT id FROM codebases WHERE project_id = ?)"},
+	{"releases", "project_id = ?"},
+	{"field_values", "project_id = ?"},
+	{"stale_policies", "project_id = ?"},
+	{"retention_policies", "project_id = ?"},
+	{"dashboards", "project_id = ?"},
+	{"work_item_state_snapshots", "project_id = ?"},
+	{"work_items", fmt.Sprintf("fields->>'%s' = ?", workitem.SystemProject)},
+	{"work_item_events", "work_item_id IN (" + projectItems + ")"},
+	{"work_item_links", "source_id IN (" + projectItems + ") AND target_id IN (" + projectItems + ")"},
+	{"comments", "parent_id IN (SELECT id::text FROM work_items WHERE fields->>'" + workitem.SystemProject + "' = ?)"},
+	{"comment_reactions", "comment_id IN (SELECT id FROM comments WHERE parent_id IN (SELECT id::text FROM work_items WHERE fields->>'" + workitem.SystemProject + "' = ?))"},
+	{"remote_links", "work_item_id IN (" + projectItems + ")"},
+	{"code_changes", "work_item_id IN (" + projectItems + ")"},
+	{"work_item_codebases", "work_item_id IN (" + projectItems + ")"},
+	{"deployment_work_items", ""},
+	{"work_item_votes", "work_item_id IN (" + projectItems + ")"},
+	{"work_item_views", "work_item_id IN (" + projectItems + ")"},
+	{"work_item_pins", "work_item_id IN (" + projectItems + ")"},
+	{"stale_work_items", "project_id = ?"},
+}
+
+// Header is the first line of a backup
+type Header struct {
+	Version int `json:"version"`
+	// ProjectID is the backed up project, nil for the whole instance
+	ProjectID *uuid.UUID `json:"project,omitempty"`
+	CreatedAt time.Time  `json:"created-at"`
+}
+
+// line is a row of a backup
+type line struct {
+	Table string          `json:"table"`
+	Row   json.RawMessage `json:"row"`
+}
+
+// Progress is called with the number of tables and rows a dump or a restore
+// went through
+type Progress func(tablesDone int, rows int64) error
+
+// Dumper writes and reads backups
+type Dumper interface {
+	Tables(projectID *uuid.UUID) int
+	Dump(ctx context.Context, projectID *uuid.UUID, w io.Writer, progress Progress) error
+	Restore(ctx context.Context, r io.Reader, progress Progress) (*Header, error)
+}
+
+// NewDumper creates a dumper working in the given transaction, it must not
+// have run any statement yet
+func NewDumper(db *gorm.DB) Dumper {
+	return &GormDumper{db: db}
+}
+
+// GormDumper is the implementation of Dumper with Postgres
+type GormDumper struct {
+	db *gorm.DB
+}
+
+// scope returns the tables of an instance or project backup
+func scope(projectID *uuid.UUID) []table {
+	if projectID == nil {
+		return tables
+	}
+	res := []table{}
+	for _, t := range tables {
+		if t.projectFilter != "" {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// Tables returns the number of tables a backup of the project, or the
+// instance if projectID is nil, goes through
+func (m *GormDumper) Tables(projectID *uuid.UUID) int {
+	return len(scope(projectID))
+}
+
+// Dump writes the backup of the project, or the instance if projectID is
+// nil, to w. The transaction becomes a read only snapshot so the backup is
+// consistent while the tables change.
+// returns NotFoundError or InternalError
+func (m *GormDumper) Dump(ctx context.Context, projectID *uuid.UUID, w io.Writer, progress Progress) error {
+	defer goa.MeasureSince([]string{"goa", "db", "backup", "dump"}, time.Now())
+
+	if err := m.db.Exec("SET TRANSACTION ISOLATION LEVEL REPEATABLE READ, READ ONLY").Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if projectID != nil {
+		var n int
+		if err := m.db.Raw("SELECT count(*) FROM projects WHERE id = ?", *projectID).Row().Scan(&n); err != nil {
+			return errors.NewInternalError(err.Error())
+		}
+		if n == 0 {
+			return errors.NewNotFoundError("project", projectID.String())
+		}
+	}
+	gz := gzip.NewWriter(w)
+	enc := json.NewEncoder(gz)
+	if err := enc.Encode(Header{Version: FormatVersion, ProjectID: projectID, CreatedAt: time.Now()}); err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	var count int64
+	for i, t := range scope(projectID) {
+		query := "SELECT to_jsonb(t) FROM " + t.name + " t"
+		var args []interface{}
+		if projectID != nil {
+			query += " WHERE " + t.projectFilter
+			for j := strings.Count(t.projectFilter, "?"); j > 0; j-- {
+				args = append(args, projectID.String())
+			}
+		}
+		rows, err := m.db.Raw(query, args...).Rows()
+		if err != nil {
+			return errors.NewInternalError(err.Error())
+		}
+		for rows.Next() {
+			var row []byte
+			if err := rows.Scan(&row); err != nil {
+				rows.Close()
+				return errors.NewInternalError(err.Error())
+			}
+			if err := enc.Encode(line{Table: t.name, Row: row}); err != nil {
+				rows.Close()
+				return errors.NewInternalError(err.Error())
+			}
+			count++
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return errors.NewInternalError(err.Error())
+		}
+		if err := progress(i+1, count); err != nil {
+			return err
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// Restore inserts the rows of the backup read from r. Rows that exist
+// already fail the restore, the transaction should be rolled back then.
+// returns BadParameterError, DataConflictError or InternalError
+func (m *GormDumper) Restore(ctx context.Context, r io.Reader, progress Progress) (*Header, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "backup", "restore"}, time.Now())
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.NewBadParameterError("backup", err.Error()).Expected("gzipped JSON lines")
+	}
+	defer gz.Close()
+	scanner := bufio.NewScanner(gz)
+	// rows hold whole work items and comments
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	if !scanner.Scan() {
+		return nil, errors.NewBadParameterError("backup", "empty").Expected("a header")
+	}
+	var h Header
+	if err := json.Unmarshal(scanner.Bytes(), &h); err != nil || h.Version != FormatVersion {
+		return nil, errors.NewBadParameterError("backup.version", h.Version).Expected(FormatVersion)
+	}
+	known := map[string]bool{}
+	for _, t := range scope(h.ProjectID) {
+		known[t.name] = true
+	}
+	restored := []string{}
+	var count int64
+	for scanner.Scan() {
+		var l line
+		if err := json.Unmarshal(scanner.Bytes(), &l); err != nil {
+			return nil, errors.NewBadParameterError("backup", err.Error()).Expected("JSON lines")
+		}
+		if !known[l.Table] {
+			return nil, errors.NewBadParameterError("backup.table", l.Table).Expected("a backed up table")
+		}
+		if len(restored) == 0 || restored[len(restored)-1] != l.Table {
+			if len(restored) > 0 {
+				if err := progress(len(restored), count); err != nil {
+					return nil, err
+				}
+			}
+			restored = append(restored, l.Table)
+		}
+		err := m.db.Exec(fmt.Sprintf("INSERT INTO %s SELECT * FROM jsonb_populate_record(NULL::%s, ?::jsonb)", l.Table, l.Table), string(l.Row)).Error
+		if err != nil {
+			if gormsupport.IsAnyUniqueViolation(err) {
+				return nil, errors.NewDataConflictError(fmt.Sprintf("%s of the backup exists already: %s", l.Table, err.Error()))
+			}
+			return nil, errors.NewInternalError(err.Error())
+		}
+		count++
+		if count%progressRows == 0 {
+			if err := progress(len(restored)-1, count); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.NewBadParameterError("backup", err.Error()).Expected("gzipped JSON lines")
+	}
+	if err := m.resetSequences(restored); err != nil {
+		return nil, err
+	}
+	if err := progress(len(restored), count); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// resetSequences moves the sequences of the serial columns of the tables
+// past the restored values, they never move back
+func (m *GormDumper) resetSequences(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	var columns []struct {
+		TableName  string
+		ColumnName string
+	}
+	err := m.db.Raw(`SELECT table_name, column_name FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name IN (?) AND column_default LIKE 'nextval(%'`, names).Scan(&columns).Error
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	for _, c := range columns {
+		var sequence string
+		if err := m.db.Raw("SELECT pg_get_serial_sequence(?, ?)", c.TableName, c.ColumnName).Row().Scan(&sequence); err != nil {
+			return errors.NewInternalError(err.Error())
+		}
+		err := m.db.Exec(fmt.Sprintf("SELECT setval('%s', GREATEST((SELECT last_value FROM %s), (SELECT coalesce(max(%s), 0) FROM %s)))",
+			sequence, sequence, c.ColumnName, c.TableName)).Error
+		if err != nil {
+			return errors.NewInternalError(err.Error())
+		}
+	}
+	return nil
+}
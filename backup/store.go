@@ -0,0 +1,126 @@
+package backup
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+)
+
+// Store is the object store the backups are kept in
+type Store interface {
+	Put(ctx context.Context, name string, r io.Reader) error
+	Get(ctx context.Context, name string) (io.ReadCloser, error)
+}
+
+// NewStore returns the store at the given location: a file:// URL of a
+// directory or the http(s):// URL of a bucket accepting PUT and GET of its
+// objects, with the token as bearer token if it is not empty
+// returns BadParameterError
+func NewStore(location string, token string) (Store, error) {
+	u, err := url.Parse(location)
+	if err != nil || location == "" {
+		return nil, errors.NewBadParameterError("backup.location", location).Expected("a file:// or http(s):// URL")
+	}
+	switch u.Scheme {
+	case "file":
+		return &FileStore{dir: u.Path}, nil
+	case "http", "https":
+		return &HTTPStore{url: strings.TrimSuffix(location, "/"), token: token, client: &http.Client{Timeout: time.Hour}}, nil
+	}
+	return nil, errors.NewBadParameterError("backup.location", location).Expected("a file:// or http(s):// URL")
+}
+
+// FileStore keeps the objects as files below a directory
+type FileStore struct {
+	dir string
+}
+
+// Put implements Store, the object is written to a temporary file first so
+// a failed backup never replaces a good one
+func (s *FileStore) Put(ctx context.Context, name string, r io.Reader) error {
+	path := filepath.Join(s.dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Get implements Store
+func (s *FileStore) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(s.dir, filepath.FromSlash(name)))
+	if os.IsNotExist(err) {
+		return nil, errors.NewNotFoundError("backup object", name)
+	}
+	return f, err
+}
+
+// HTTPStore keeps the objects below the URL of a bucket
+type HTTPStore struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// Put implements Store, any response but 2xx fails it
+func (s *HTTPStore) Put(ctx context.Context, name string, r io.Reader) error {
+	res, err := s.do("PUT", name, r)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+// Get implements Store
+func (s *HTTPStore) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	res, err := s.do("GET", name, nil)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+func (s *HTTPStore) do(method string, name string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, s.url+"/"+name, body)
+	if err != nil {
+		return nil, err
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil, errors.NewNotFoundError("backup object", name)
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		res.Body.Close()
+		return nil, fmt.Errorf("%s %s responded %s", method, s.url+"/"+name, res.Status)
+	}
+	return res, nil
+}
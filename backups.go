@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/backup"
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/job"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/readonly"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// backupJobKind is the kind of the jobs running backups and restores
+const backupJobKind = "backup.run"
+
+// backupJobPayload is the payload of the backup jobs
+type backupJobPayload struct {
+	Run uuid.UUID `json:"run"`
+}
+
+// BackupsController implements the backups resource.
+type BackupsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewBackupsController creates a backups controller.
+func NewBackupsController(service *goa.Service, db application.DB) *BackupsController {
+	return &BackupsController{Controller: service.NewController("BackupsController"), db: db}
+}
+
+// List runs the list action.
+func (c *BackupsController) List(ctx *app.ListBackupsContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can manage backups"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		runs, err := appl.Backups().List(ctx, ctx.FilterProject)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.BackupList{Data: []*app.Backup{}}
+		for _, r := range runs {
+			res.Data = append(res.Data, ConvertBackup(r))
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Show runs the show action.
+func (c *BackupsController) Show(ctx *app.ShowBackupsContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can manage backups"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		r, err := appl.Backups().Load(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.BackupSingle{Data: ConvertBackup(r)})
+	})
+}
+
+// Create runs the create action.
+func (c *BackupsController) Create(ctx *app.CreateBackupsContext) error {
+	r := backup.Run{Operation: backup.OperationBackup}
+	if ctx.Payload.Data != nil && ctx.Payload.Data.Attributes != nil {
+		r.ProjectID = ctx.Payload.Data.Attributes.Project
+	}
+	return c.enqueue(ctx, &r, func(appl application.Application) error {
+		if r.ProjectID != nil {
+			_, err := appl.Projects().Load(ctx, *r.ProjectID)
+			return err
+		}
+		return nil
+	}, func() error {
+		return ctx.Accepted(&app.BackupSingle{Data: ConvertBackup(&r)})
+	})
+}
+
+// Restore runs the restore action.
+func (c *BackupsController) Restore(ctx *app.RestoreBackupsContext) error {
+	r := backup.Run{Operation: backup.OperationRestore, BackupID: &ctx.ID}
+	return c.enqueue(ctx, &r, nil, func() error {
+		return ctx.Accepted(&app.BackupSingle{Data: ConvertBackup(&r)})
+	})
+}
+
+// backupContext is implemented by the contexts of the actions starting runs
+type backupContext interface {
+	context.Context
+	jsonapi.InternalServerError
+}
+
+// enqueue stores the run and enqueues its job if the current identity is an
+// instance admin and the object store is configured, check runs first
+func (c *BackupsController) enqueue(ctx backupContext, r *backup.Run, check func(appl application.Application) error, accepted func() error) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can manage backups"))
+	}
+	if _, err := backup.NewStore(configuration.GetBackupLocation(), configuration.GetBackupToken()); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	r.CreatorID = currentIdentityID(ctx)
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if check != nil {
+			if err := check(appl); err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+		}
+		if err := appl.Backups().Create(ctx, r); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if _, err := appl.Jobs().Enqueue(ctx, backupJobKind, backupJobPayload{Run: r.ID}); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return accepted()
+	})
+}
+
+// ConvertBackup converts between internal and external REST representation
+func ConvertBackup(r *backup.Run) *app.Backup {
+	res := &app.Backup{
+		Type: "backups",
+		ID:   &r.ID,
+		Attributes: &app.BackupAttributes{
+			Operation:  &r.Operation,
+			Project:    r.ProjectID,
+			Backup:     r.BackupID,
+			Object:     &r.Object,
+			Status:     &r.Status,
+			Tables:     &r.Tables,
+			TablesDone: &r.TablesDone,
+			CreatedAt:  &r.CreatedAt,
+			FinishedAt: r.FinishedAt,
+		},
+	}
+	rows := int(r.Rows)
+	res.Attributes.Rows = &rows
+	if r.Error != "" {
+		res.Attributes.Error = &r.Error
+	}
+	return res
+}
+
+// runBackupJob returns the handler of the jobs running backups and restores.
+// The run records its progress in transactions of its own so it can be
+// followed while the backup or restore transaction is still open.
+func runBackupJob(db application.DB) job.Handler {
+	return func(ctx context.Context, payload []byte) error {
+		var p backupJobPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return errors.NewConversionError(err.Error())
+		}
+		// the runs are recorded during maintenance as well, backups are
+		// what it is for
+		var r *backup.Run
+		err := application.MaintenanceTransactional(db, func(appl application.Application) error {
+			var err error
+			if r, err = appl.Backups().Load(ctx, p.Run); err != nil {
+				return err
+			}
+			return appl.Backups().Start(ctx, r.ID, appl.Dumps().Tables(r.ProjectID))
+		})
+		if err != nil {
+			return err
+		}
+		progress := func(tablesDone int, rows int64) error {
+			return application.MaintenanceTransactional(db, func(appl application.Application) error {
+				return appl.Backups().Progress(ctx, r.ID, tablesDone, rows)
+			})
+		}
+		cause := runBackup(ctx, db, r, progress)
+		err = application.MaintenanceTransactional(db, func(appl application.Application) error {
+			return appl.Backups().Finish(ctx, r.ID, cause)
+		})
+		if cause != nil {
+			return cause
+		}
+		return err
+	}
+}
+
+// runBackup writes the backup of the run to the object store, or restores it
+func runBackup(ctx context.Context, db application.DB, r *backup.Run, progress backup.Progress) error {
+	store, err := backup.NewStore(configuration.GetBackupLocation(), configuration.GetBackupToken())
+	if err != nil {
+		return err
+	}
+	if r.Operation == backup.OperationRestore {
+		obj, err := store.Get(ctx, r.Object)
+		if err != nil {
+			return err
+		}
+		defer obj.Close()
+		// nothing else writes while the backup is restored
+		readonly.Enable(readonly.Restore, "The service is read-only while a backup is restored, try again later.")
+		defer readonly.Disable(readonly.Restore)
+		return application.MaintenanceTransactional(db, func(appl application.Application) error {
+			_, err := appl.Dumps().Restore(ctx, obj, progress)
+			return err
+		})
+	}
+	pr, pw := io.Pipe()
+	dumped := make(chan error, 1)
+	go func() {
+		err := application.Transactional(db, func(appl application.Application) error {
+			return appl.Dumps().Dump(ctx, r.ProjectID, pw, progress)
+		})
+		pw.CloseWithError(err)
+		dumped <- err
+	}()
+	err = store.Put(ctx, r.Object, pr)
+	// unblocks the dump if the store gave up reading
+	pr.CloseWithError(err)
+	if err := <-dumped; err != nil {
+		return err
+	}
+	return err
+}
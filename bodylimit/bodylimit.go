@@ -0,0 +1,128 @@
+// Package bodylimit limits the size of request bodies so a single oversized
+// request can't exhaust the memory of the server. Every endpoint belongs to a
+// class with its own limit: uploads of attachments, webhooks and the JSON API.
+// Requests declaring a larger body are refused before it is read, bodies
+// growing larger while they are read fail with PayloadTooLargeError.
+package bodylimit
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+)
+
+// Class is a class of endpoints sharing a body size limit, loadshed caps the
+// requests served at once by class as well
+type Class string
+
+// The endpoint classes
+const (
+	API     Class = "api"
+	Upload  Class = "upload"
+	Webhook Class = "webhook"
+)
+
+// uploadPath matches the endpoints uploading attachments to a project
+var uploadPath = regexp.MustCompile(`^/api/projects/[^/]+/attachments(/.*)?$`)
+
+// ClassOf returns the class of the endpoint at the path
+func ClassOf(path string) Class {
+	switch {
+	case uploadPath.MatchString(path):
+		return Upload
+	case strings.HasPrefix(path, "/api/webhooks/"):
+		return Webhook
+	}
+	return API
+}
+
+// Limit returns the maximum body size of the class in bytes, 0 if there is no
+// limit
+func Limit(c Class) int64 {
+	switch c {
+	case Upload:
+		return configuration.GetRequestMaxSizeUpload()
+	case Webhook:
+		return configuration.GetRequestMaxSizeWebhook()
+	}
+	return configuration.GetRequestMaxSizeAPI()
+}
+
+// TooLarge returns the error of a body exceeding the limit
+func TooLarge(limit int64) errors.PayloadTooLargeError {
+	return errors.NewPayloadTooLargeError(fmt.Sprintf("the request body exceeds %d bytes", limit))
+}
+
+// ReadError returns the error of reading the parameter from a request body,
+// the PayloadTooLargeError of a body exceeding its limit or a
+// BadParameterError
+func ReadError(param string, err error) error {
+	if _, ok := err.(errors.PayloadTooLargeError); ok {
+		return err
+	}
+	return errors.NewBadParameterError(param, err.Error())
+}
+
+// Middleware limits the bodies of the requests to the limit of the class of
+// their endpoint. Actions whose payload failed to decode because the body was
+// too large fail with PayloadTooLargeError instead of the decoding error.
+func Middleware() goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			limit := Limit(ClassOf(req.URL.Path))
+			if limit <= 0 || req.Body == nil {
+				return h(ctx, rw, req)
+			}
+			if req.ContentLength > limit {
+				// don't keep the connection to read the rest of the body
+				rw.Header().Set("Connection", "close")
+				return TooLarge(limit)
+			}
+			body := &limitedBody{ReadCloser: req.Body, remaining: limit, limit: limit}
+			req.Body = body
+			err := h(ctx, rw, req)
+			if err != nil && body.exceeded {
+				rw.Header().Set("Connection", "close")
+				return TooLarge(limit)
+			}
+			return err
+		}
+	}
+}
+
+// limitedBody fails reading past the limit with PayloadTooLargeError
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	limit     int64
+	exceeded  bool
+}
+
+// Read implements io.Reader
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.exceeded {
+		return 0, TooLarge(b.limit)
+	}
+	// read one byte more than allowed to tell a body of exactly the limit
+	// from a larger one
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		b.remaining = 0
+		b.exceeded = true
+		return n, TooLarge(b.limit)
+	}
+	b.remaining -= int64(n)
+	return n, err
+}
@@ -0,0 +1,81 @@
+package bodylimit_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/bodylimit"
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	if err := configuration.Setup(""); err != nil {
+		panic(fmt.Errorf("Failed to setup the configuration: %s", err.Error()))
+	}
+	os.Exit(m.Run())
+}
+
+func TestClassOf(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	assert.Equal(t, bodylimit.Upload, bodylimit.ClassOf("/api/projects/1/attachments/paste"))
+	assert.Equal(t, bodylimit.Webhook, bodylimit.ClassOf("/api/webhooks/github"))
+	assert.Equal(t, bodylimit.API, bodylimit.ClassOf("/api/workitems"))
+	assert.Equal(t, bodylimit.API, bodylimit.ClassOf("/api/projects/1/intake"))
+	assert.Equal(t, configuration.GetRequestMaxSizeUpload(), bodylimit.Limit(bodylimit.Upload))
+}
+
+func TestMiddleware(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	limit := bodylimit.Limit(bodylimit.API)
+	var read int
+	h := bodylimit.Middleware()(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		body, err := ioutil.ReadAll(req.Body)
+		read = len(body)
+		if err != nil {
+			// like the decoding errors of goa
+			return fmt.Errorf("failed to decode request body: %s", err.Error())
+		}
+		return nil
+	})
+	call := func(body io.Reader) error {
+		req, err := http.NewRequest("POST", "/api/workitems", body)
+		require.Nil(t, err)
+		return h(context.Background(), httptest.NewRecorder(), req)
+	}
+
+	// bodies of exactly the limit are read
+	assert.Nil(t, call(bytes.NewReader(make([]byte, limit))))
+	assert.Equal(t, int(limit), read)
+
+	// bodies declaring a larger length are refused before they are read
+	read = -1
+	err := call(bytes.NewReader(make([]byte, limit+1)))
+	assert.IsType(t, errors.PayloadTooLargeError{}, err)
+	assert.Equal(t, -1, read)
+
+	// bodies of unknown length stop being read at the limit
+	err = call(io.MultiReader(bytes.NewReader(make([]byte, limit)), bytes.NewReader(make([]byte, 10))))
+	assert.IsType(t, errors.PayloadTooLargeError{}, err)
+	assert.Equal(t, int(limit), read)
+}
+
+func TestReadError(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	assert.IsType(t, errors.PayloadTooLargeError{}, bodylimit.ReadError("payload", bodylimit.TooLarge(10)))
+	assert.IsType(t, errors.BadParameterError{}, bodylimit.ReadError("payload", io.ErrUnexpectedEOF))
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/branding"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// BrandingController implements the branding resource.
+type BrandingController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewBrandingController creates a branding controller.
+func NewBrandingController(service *goa.Service, db application.DB) *BrandingController {
+	return &BrandingController{Controller: service.NewController("BrandingController"), db: db}
+}
+
+// Show runs the show action.
+func (c *BrandingController) Show(ctx *app.ShowBrandingContext) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if ctx.Project != nil {
+			if _, err := appl.Projects().Load(ctx, *ctx.Project); err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+		}
+		b, err := appl.Branding().Effective(ctx, ctx.Project)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(ConvertBranding(b))
+	})
+}
+
+// Update runs the update action.
+func (c *BrandingController) Update(ctx *app.UpdateBrandingContext) error {
+	b := branding.Branding{
+		ProjectID:    ctx.Project,
+		ProductName:  stringValue(ctx.Payload.ProductName),
+		LogoURL:      stringValue(ctx.Payload.LogoURL),
+		AccentColor:  stringValue(ctx.Payload.AccentColor),
+		SupportURL:   stringValue(ctx.Payload.SupportURL),
+		DocsURL:      stringValue(ctx.Payload.DocsURL),
+		SupportEmail: stringValue(ctx.Payload.SupportEmail),
+	}
+	return c.administrate(ctx, ctx.Project, func(appl application.Application) error {
+		if err := appl.Branding().Save(ctx, b); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res, err := appl.Branding().Effective(ctx, ctx.Project)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(ConvertBranding(res))
+	})
+}
+
+// Reset runs the reset action.
+func (c *BrandingController) Reset(ctx *app.ResetBrandingContext) error {
+	return c.administrate(ctx, &ctx.Project, func(appl application.Application) error {
+		if err := appl.Branding().Delete(ctx, ctx.Project); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.NoContent()
+	})
+}
+
+// brandingContext is implemented by the contexts of the branding actions
+// changing settings
+type brandingContext interface {
+	context.Context
+	jsonapi.InternalServerError
+}
+
+// administrate runs f in a transaction if the current identity is an instance
+// admin or, for the settings of a project, an admin of the project
+func (c *BrandingController) administrate(ctx brandingContext, projectID *uuid.UUID, f func(appl application.Application) error) error {
+	if currentIdentityID(ctx) == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if projectID != nil {
+			if _, err := appl.Projects().Load(ctx, *projectID); err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+		}
+		if projectID == nil {
+			if !isInstanceAdmin(ctx) {
+				return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can brand the deployment"))
+			}
+		} else if err := checkProjectAdmin(ctx, appl, *projectID, "brand the project"); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return f(appl)
+	})
+}
+
+// ConvertBranding converts from internal to external REST representation
+func ConvertBranding(b *branding.Branding) *app.Branding {
+	res := &app.Branding{ProductName: b.ProductName, AccentColor: b.AccentColor}
+	if b.LogoURL != "" {
+		res.LogoURL = &b.LogoURL
+	}
+	if b.SupportURL != "" {
+		res.SupportURL = &b.SupportURL
+	}
+	if b.DocsURL != "" {
+		res.DocsURL = &b.DocsURL
+	}
+	if b.SupportEmail != "" {
+		res.SupportEmail = &b.SupportEmail
+	}
+	return res
+}
+
+// stringValue returns the string s points to, empty if s is nil
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
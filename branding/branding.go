@@ -0,0 +1,196 @@
+// Package branding holds the product name, logo, accent color and support
+// links shown by the clients and in emails. They are set for the deployment
+// and optionally overridden by project, empty settings inherit.
+package branding
+
+import (
+	"net/url"
+	"regexp"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Defaults of the deployment settings
+const (
+	DefaultProductName = "ALMighty"
+	DefaultAccentColor = "#0088ce"
+)
+
+// accentColorPattern matches hex colors like #0088ce
+var accentColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Branding are the branding settings of the deployment or of a project
+type Branding struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// ProjectID is nil for the settings of the deployment
+	ProjectID   *uuid.UUID `sql:"type:uuid"`
+	ProductName string
+	LogoURL     string
+	// AccentColor is a hex color like #0088ce
+	AccentColor string
+	SupportURL  string
+	DocsURL     string
+	// SupportEmail is the address users write to for help
+	SupportEmail string
+}
+
+// TableName implements gorm.tabler
+func (b Branding) TableName() string {
+	return "branding"
+}
+
+// Validate checks the color and that the links are absolute http(s) URLs
+// returns BadParameterError
+func (b Branding) Validate() error {
+	if b.AccentColor != "" && !accentColorPattern.MatchString(b.AccentColor) {
+		return errors.NewBadParameterError("accent-color", b.AccentColor).Expected("a hex color like #0088ce")
+	}
+	links := []struct {
+		name  string
+		value string
+	}{
+		{"logo-url", b.LogoURL},
+		{"support-url", b.SupportURL},
+		{"docs-url", b.DocsURL},
+	}
+	for _, l := range links {
+		if l.value == "" {
+			continue
+		}
+		u, err := url.Parse(l.value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.NewBadParameterError(l.name, l.value).Expected("an absolute http(s) URL")
+		}
+	}
+	return nil
+}
+
+// inherit fills the empty settings with the ones of the parent
+func (b *Branding) inherit(parent Branding) {
+	if b.ProductName == "" {
+		b.ProductName = parent.ProductName
+	}
+	if b.LogoURL == "" {
+		b.LogoURL = parent.LogoURL
+	}
+	if b.AccentColor == "" {
+		b.AccentColor = parent.AccentColor
+	}
+	if b.SupportURL == "" {
+		b.SupportURL = parent.SupportURL
+	}
+	if b.DocsURL == "" {
+		b.DocsURL = parent.DocsURL
+	}
+	if b.SupportEmail == "" {
+		b.SupportEmail = parent.SupportEmail
+	}
+}
+
+// Repository encapsulates storage & retrieval of branding settings
+type Repository interface {
+	Load(ctx context.Context, projectID *uuid.UUID) (*Branding, error)
+	Effective(ctx context.Context, projectID *uuid.UUID) (*Branding, error)
+	Save(ctx context.Context, b Branding) error
+	Delete(ctx context.Context, projectID uuid.UUID) error
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for branding
+// settings.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Load returns the stored settings of the project or, if projectID is nil, of
+// the deployment; all settings are empty if none were stored
+// returns InternalError
+func (m *GormRepository) Load(ctx context.Context, projectID *uuid.UUID) (*Branding, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "branding", "load"}, time.Now())
+
+	db := m.db
+	if projectID == nil {
+		db = db.Where("project_id IS NULL")
+	} else {
+		db = db.Where("project_id = ?", *projectID)
+	}
+	var obj Branding
+	tx := db.First(&obj)
+	if tx.RecordNotFound() {
+		return &Branding{ProjectID: projectID}, nil
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
+
+// Effective returns the settings of the project, if any, inheriting the empty
+// ones from the deployment and then from the defaults
+// returns InternalError
+func (m *GormRepository) Effective(ctx context.Context, projectID *uuid.UUID) (*Branding, error) {
+	deployment, err := m.Load(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	deployment.inherit(Branding{ProductName: DefaultProductName, AccentColor: DefaultAccentColor})
+	if projectID == nil {
+		return deployment, nil
+	}
+	res, err := m.Load(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	res.inherit(*deployment)
+	return res, nil
+}
+
+// Save replaces the settings of the deployment or of the project
+// returns BadParameterError or InternalError
+func (m *GormRepository) Save(ctx context.Context, b Branding) error {
+	defer goa.MeasureSince([]string{"goa", "db", "branding", "save"}, time.Now())
+
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	db := m.db
+	if b.ProjectID == nil {
+		db = db.Where("project_id IS NULL")
+	} else {
+		db = db.Where("project_id = ?", *b.ProjectID)
+	}
+	if err := db.Delete(Branding{}).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if err := m.db.Create(&b).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// Delete removes the overrides of the project, the settings of the deployment
+// apply again
+// returns NotFoundError or InternalError
+func (m *GormRepository) Delete(ctx context.Context, projectID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "branding", "delete"}, time.Now())
+
+	tx := m.db.Where("project_id = ?", projectID).Delete(Branding{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("branding", projectID.String())
+	}
+	return nil
+}
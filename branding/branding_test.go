@@ -0,0 +1,81 @@
+package branding_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/branding"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestValidate(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	assert.Nil(t, branding.Branding{}.Validate())
+	assert.Nil(t, branding.Branding{AccentColor: "#0088CE", LogoURL: "https://example.com/logo.svg"}.Validate())
+	assert.IsType(t, errors.BadParameterError{}, branding.Branding{AccentColor: "blue"}.Validate())
+	assert.IsType(t, errors.BadParameterError{}, branding.Branding{LogoURL: "/logo.svg"}.Validate())
+	assert.IsType(t, errors.BadParameterError{}, branding.Branding{SupportURL: "javascript:alert(1)"}.Validate())
+}
+
+type TestBrandingRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunBrandingRepository(t *testing.T) {
+	suite.Run(t, &TestBrandingRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestBrandingRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestBrandingRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestBrandingRepository) TestEffective() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	p, err := project.NewRepository(test.DB).Create(ctx, "branding-"+uuid.NewV4().String())
+	require.Nil(t, err)
+	repo := branding.NewRepository(test.DB)
+
+	b, err := repo.Effective(ctx, &p.ID)
+	require.Nil(t, err)
+	assert.Equal(t, branding.DefaultProductName, b.ProductName)
+	assert.Equal(t, branding.DefaultAccentColor, b.AccentColor)
+
+	require.Nil(t, repo.Save(ctx, branding.Branding{ProductName: "Tracker", SupportURL: "https://help.example.com"}))
+	require.Nil(t, repo.Save(ctx, branding.Branding{ProjectID: &p.ID, AccentColor: "#ff0000"}))
+	// saving again replaces the overrides
+	require.Nil(t, repo.Save(ctx, branding.Branding{ProjectID: &p.ID, AccentColor: "#00ff00"}))
+	b, err = repo.Effective(ctx, &p.ID)
+	require.Nil(t, err)
+	assert.Equal(t, "Tracker", b.ProductName)
+	assert.Equal(t, "#00ff00", b.AccentColor)
+	assert.Equal(t, "https://help.example.com", b.SupportURL)
+
+	require.Nil(t, repo.Delete(ctx, p.ID))
+	assert.IsType(t, errors.NotFoundError{}, repo.Delete(ctx, p.ID))
+	b, err = repo.Effective(ctx, &p.ID)
+	require.Nil(t, err)
+	assert.Equal(t, branding.DefaultAccentColor, b.AccentColor)
+
+	b, err = repo.Load(ctx, nil)
+	require.Nil(t, err)
+	assert.Equal(t, "", b.AccentColor)
+	require.Nil(t, repo.Save(ctx, branding.Branding{}))
+}
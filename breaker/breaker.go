@@ -0,0 +1,208 @@
+// Package breaker protects the service from slow or failing external
+// dependencies, like the search index, webhook targets and GitHub. After a
+// number of consecutive failures the circuit of a dependency opens and calls
+// fail right away instead of waiting for timeouts; after a cooldown one call
+// is let through to try again, its success closes the circuit. The health of
+// the circuits is reported by the status.
+package breaker
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/errors"
+)
+
+// State is the state of a circuit
+type State string
+
+// The states of a circuit
+const (
+	// Closed circuits call the dependency
+	Closed State = "closed"
+	// Open circuits fail without calling the dependency
+	Open State = "open"
+	// HalfOpen circuits let one call through to try the dependency again
+	HalfOpen State = "half-open"
+)
+
+// Health is the state of the circuit of a dependency
+type Health struct {
+	Name  string
+	State State
+	// Failures is the number of consecutive failures
+	Failures int
+	// LastError is the error of the last failure, empty if there was none
+	LastError string
+	// Since is when the circuit changed to its state
+	Since time.Time
+}
+
+// Breaker is the circuit of an external dependency
+type Breaker struct {
+	mu        sync.Mutex
+	name      string
+	threshold int
+	cooldown  time.Duration
+	state     State
+	failures  int
+	lastError string
+	since     time.Time
+	// trying is true while the call of a half-open circuit runs
+	trying bool
+}
+
+// New returns the closed circuit of the dependency opening after threshold
+// consecutive failures for the cooldown
+func New(name string, threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{name: name, threshold: threshold, cooldown: cooldown, state: Closed, since: time.Now()}
+}
+
+var (
+	registryLock sync.Mutex
+	registry     = map[string]*Breaker{}
+)
+
+// For returns the circuit of the named dependency with the configured
+// threshold and cooldown, creating it on first use
+func For(name string) *Breaker {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	b, ok := registry[name]
+	if !ok {
+		b = New(name, configuration.GetBreakerThreshold(), configuration.GetBreakerCooldown())
+		registry[name] = b
+	}
+	return b
+}
+
+// All returns the health of the circuits of the dependencies called so far
+// ordered by name
+func All() []Health {
+	registryLock.Lock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	breakers := make([]*Breaker, len(names))
+	for i, name := range names {
+		breakers[i] = registry[name]
+	}
+	registryLock.Unlock()
+
+	res := make([]Health, len(breakers))
+	for i, b := range breakers {
+		res[i] = b.Health()
+	}
+	return res
+}
+
+// Health returns the state of the circuit
+func (b *Breaker) Health() Health {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return Health{Name: b.name, State: b.current(), Failures: b.failures, LastError: b.lastError, Since: b.since}
+}
+
+// Do calls f unless the circuit is open, an error returned by f or a panic
+// counts as a failure of the dependency
+// returns ServiceUnavailableError if the circuit is open
+func (b *Breaker) Do(f func() error) error {
+	trial, err := b.allow()
+	if err != nil {
+		return err
+	}
+	returned := false
+	defer func() {
+		// a panic must not leave the trial of a half-open circuit pending,
+		// which would keep the circuit from ever closing again
+		if !returned {
+			b.done(trial, fmt.Errorf("%s panicked", b.name))
+		}
+	}()
+	err = f()
+	returned = true
+	b.done(trial, err)
+	return err
+}
+
+// Request sends the request with the client unless the circuit is open.
+// Errors and 5xx responses count as failures of the dependency, responses
+// are returned whatever their status. Errors leave out the URL, which may
+// be a secret like the URLs of chat webhooks.
+// returns ServiceUnavailableError if the circuit is open
+func (b *Breaker) Request(client *http.Client, req *http.Request) (*http.Response, error) {
+	var res *http.Response
+	var failed error
+	err := b.Do(func() error {
+		var err error
+		res, err = client.Do(req)
+		if uerr, ok := err.(*url.Error); ok {
+			return fmt.Errorf("%s %s: %s", uerr.Op, b.name, uerr.Err)
+		}
+		if err != nil {
+			return err
+		}
+		if res.StatusCode >= 500 {
+			failed = fmt.Errorf("%s responded %s", b.name, res.Status)
+			return failed
+		}
+		return nil
+	})
+	if err != nil && err != failed {
+		return nil, err
+	}
+	return res, nil
+}
+
+// current returns the state, open circuits are half-open after the cooldown
+func (b *Breaker) current() State {
+	if b.state == Open && time.Since(b.since) >= b.cooldown {
+		return HalfOpen
+	}
+	return b.state
+}
+
+// allow returns an error unless the dependency may be called, and true if
+// the call tries a half-open circuit
+func (b *Breaker) allow() (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.current() {
+	case Closed:
+		return false, nil
+	case HalfOpen:
+		if !b.trying {
+			b.trying = true
+			return true, nil
+		}
+	}
+	return false, errors.NewServiceUnavailableError(fmt.Sprintf("%s is unavailable: %s", b.name, b.lastError))
+}
+
+// done records the result of a call
+func (b *Breaker) done(trial bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if trial {
+		b.trying = false
+	}
+	if err == nil {
+		if b.state != Closed {
+			b.state, b.since = Closed, time.Now()
+		}
+		b.failures = 0
+		return
+	}
+	b.failures++
+	b.lastError = err.Error()
+	if trial || (b.state == Closed && b.failures >= b.threshold) {
+		b.state, b.since = Open, time.Now()
+	}
+}
@@ -0,0 +1,122 @@
+package breaker_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/breaker"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreaker(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	b := breaker.New("test", 2, 50*time.Millisecond)
+	calls := 0
+	fail := func() error {
+		calls++
+		return fmt.Errorf("down")
+	}
+	succeed := func() error {
+		calls++
+		return nil
+	}
+
+	// the circuit opens after the threshold of consecutive failures
+	assert.NotNil(t, b.Do(fail))
+	assert.Nil(t, b.Do(succeed))
+	assert.NotNil(t, b.Do(fail))
+	assert.Equal(t, breaker.Closed, b.Health().State)
+	assert.NotNil(t, b.Do(fail))
+	assert.Equal(t, breaker.Open, b.Health().State)
+	assert.Equal(t, 2, b.Health().Failures)
+	assert.Equal(t, "down", b.Health().LastError)
+
+	// open circuits don't call
+	err := b.Do(succeed)
+	assert.IsType(t, errors.ServiceUnavailableError{}, err)
+	assert.Equal(t, 4, calls)
+
+	// a failing try after the cooldown opens the circuit again
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, breaker.HalfOpen, b.Health().State)
+	assert.NotNil(t, b.Do(fail))
+	assert.Equal(t, breaker.Open, b.Health().State)
+
+	// a successful one closes it
+	time.Sleep(60 * time.Millisecond)
+	assert.Nil(t, b.Do(succeed))
+	assert.Equal(t, breaker.Closed, b.Health().State)
+	assert.Equal(t, 0, b.Health().Failures)
+}
+
+func TestPanic(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	b := breaker.New("test", 1, 0)
+	panics := func() error {
+		panic("bug")
+	}
+	assert.Panics(t, func() { b.Do(panics) })
+	assert.Equal(t, breaker.HalfOpen, b.Health().State)
+	assert.Equal(t, "test panicked", b.Health().LastError)
+
+	// the panicking trial of the half-open circuit doesn't block the next one
+	assert.Panics(t, func() { b.Do(panics) })
+	assert.Nil(t, b.Do(func() error { return nil }))
+	assert.Equal(t, breaker.Closed, b.Health().State)
+}
+
+func TestRequest(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	status := http.StatusNotFound
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	b := breaker.New("test", 1, time.Minute)
+	request := func() (*http.Response, error) {
+		req, err := http.NewRequest("GET", server.URL+"/secret", nil)
+		require.Nil(t, err)
+		return b.Request(http.DefaultClient, req)
+	}
+
+	// client errors are no failures of the dependency
+	res, err := request()
+	require.Nil(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	assert.Equal(t, breaker.Closed, b.Health().State)
+
+	// server errors are returned and open the circuit
+	status = http.StatusBadGateway
+	res, err = request()
+	require.Nil(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusBadGateway, res.StatusCode)
+	assert.Equal(t, breaker.Open, b.Health().State)
+	_, err = request()
+	assert.IsType(t, errors.ServiceUnavailableError{}, err)
+	assert.NotContains(t, err.Error(), "secret")
+}
+
+func TestFor(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	b := breaker.For("test for")
+	assert.True(t, b == breaker.For("test for"))
+	found := false
+	for _, h := range breaker.All() {
+		if h.Name == "test for" {
+			found = true
+		}
+	}
+	assert.True(t, found)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"time"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/calendar"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// CalendarController implements the calendar resource.
+type CalendarController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewCalendarController creates a calendar controller.
+func NewCalendarController(service *goa.Service, db application.DB) *CalendarController {
+	return &CalendarController{Controller: service.NewController("CalendarController"), db: db}
+}
+
+// Show runs the show action.
+func (c *CalendarController) Show(ctx *app.ShowCalendarContext) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		feed, err := appl.CalendarFeeds().Load(ctx, ctx.Token)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		identity, err := appl.Identities().Load(ctx, feed.IdentityID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		// the request is anonymous, the feed shows what its owner can see
+		viewer, err := loadViewer(ctx, appl, &feed.IdentityID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		vctx := workitem.WithViewer(ctx, viewer)
+
+		name := identity.FullName
+		var projectIDs []uuid.UUID
+		if feed.ProjectID != nil {
+			projectIDs = []uuid.UUID{*feed.ProjectID}
+		} else {
+			projectIDs, err = appl.CalendarFeeds().ProjectIDs(ctx, feed.IdentityID)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+		}
+		loc := identity.Location()
+		var events []calendar.Event
+		for _, projectID := range projectIDs {
+			p, err := appl.Projects().Load(vctx, projectID)
+			if _, ok := err.(errors.NotFoundError); ok && feed.ProjectID == nil {
+				// the project was deleted or its owner lost access to it
+				continue
+			}
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			if feed.ProjectID != nil {
+				name = p.Name
+			}
+			active, err := appl.Iterations().List(vctx, projectID)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			archived, err := appl.Iterations().ListArchived(vctx, projectID)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			for _, i := range append(active, archived...) {
+				if e, ok := calendar.IterationEvent(*i, AbsoluteURL(ctx.RequestData, app.IterationHref(i.ID)), loc); ok {
+					events = append(events, e)
+				}
+			}
+			releases, err := appl.Releases().List(vctx, projectID)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			for _, r := range releases {
+				if e, ok := calendar.ReleaseEvent(*r, AbsoluteURL(ctx.RequestData, app.ReleaseHref(r.ID)), loc); ok {
+					events = append(events, e)
+				}
+			}
+			due, err := appl.CalendarFeeds().DueWorkItems(vctx, projectID)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			for _, wi := range due {
+				events = append(events, calendar.WorkItemEvent(wi, AbsoluteURL(ctx.RequestData, app.WorkitemHref(wi.ID)), loc))
+			}
+		}
+		return ctx.OK(calendar.Render(name, events, time.Now()))
+	})
+}
+
+// Create runs the create action.
+func (c *CalendarController) Create(ctx *app.CreateCalendarContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing identity"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if ctx.Project != nil {
+			if _, err := appl.Projects().Load(ctx, *ctx.Project); err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+		}
+		feed, err := appl.CalendarFeeds().Rotate(ctx, *identityID, ctx.Project)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(ConvertCalendarFeed(ctx.RequestData, feed))
+	})
+}
+
+// Delete runs the delete action.
+func (c *CalendarController) Delete(ctx *app.DeleteCalendarContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing identity"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if err := appl.CalendarFeeds().Revoke(ctx, *identityID, ctx.Project); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.NoContent()
+	})
+}
+
+// ConvertCalendarFeed converts a calendar feed to its secret URL
+func ConvertCalendarFeed(request *goa.RequestData, feed *calendar.Feed) *app.CalendarFeed {
+	return &app.CalendarFeed{
+		URL:       AbsoluteURL(request, app.CalendarHref(feed.Token)),
+		Project:   feed.ProjectID,
+		CreatedAt: feed.CreatedAt,
+	}
+}
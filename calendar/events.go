@@ -0,0 +1,65 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/release"
+)
+
+// IterationEvent returns the all day event spanning the iteration in the
+// given location. An iteration with only one of its dates lasts a day,
+// ok is false for iterations without dates.
+func IterationEvent(i iteration.Iteration, url string, loc *time.Location) (e Event, ok bool) {
+	start, end := i.StartAt, i.EndAt
+	switch {
+	case start == nil && end == nil:
+		return Event{}, false
+	case start == nil:
+		start = end
+	case end == nil:
+		end = start
+	}
+	return Event{
+		UID:     fmt.Sprintf("iteration-%s@almighty-core", i.ID),
+		Summary: i.Name,
+		URL:     url,
+		Start:   start.In(loc),
+		End:     end.In(loc),
+		AllDay:  true,
+	}, true
+}
+
+// ReleaseEvent returns the all day event of the target date of the release
+// in the given location, ok is false for releases without target date
+func ReleaseEvent(r release.Release, url string, loc *time.Location) (e Event, ok bool) {
+	if r.TargetDate == nil {
+		return Event{}, false
+	}
+	e = Event{
+		UID:     fmt.Sprintf("release-%s@almighty-core", r.ID),
+		Summary: fmt.Sprintf("Release %s due", r.Version),
+		URL:     url,
+		Start:   r.TargetDate.In(loc),
+		End:     r.TargetDate.In(loc),
+		AllDay:  true,
+	}
+	if r.IsPublished() {
+		e.Summary = fmt.Sprintf("Release %s", r.Version)
+	}
+	return e, true
+}
+
+// WorkItemEvent returns the all day event of the due date of the work item in
+// the given location
+func WorkItemEvent(wi DueWorkItem, url string, loc *time.Location) Event {
+	return Event{
+		UID:     fmt.Sprintf("workitem-%d@almighty-core", wi.ID),
+		Summary: fmt.Sprintf("%s due", wi.Title),
+		URL:     url,
+		Start:   wi.DueDate.In(loc),
+		End:     wi.DueDate.In(loc),
+		AllDay:  true,
+	}
+}
@@ -0,0 +1,184 @@
+// Package calendar stores the secret tokens of the ICS feeds of the
+// identities and renders iterations, release dates and the due dates of work
+// items as iCalendar documents, so calendar apps can subscribe to them.
+package calendar
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// tokenBytes is the number of random bytes of a feed token
+const tokenBytes = 20
+
+// Feed grants access to the calendar of an identity, or of one of its
+// projects if ProjectID is set, to whoever knows the token
+type Feed struct {
+	CreatedAt  time.Time
+	Token      string     `gorm:"primary_key"`
+	IdentityID uuid.UUID  `sql:"type:uuid"`
+	ProjectID  *uuid.UUID `sql:"type:uuid"`
+}
+
+// TableName implements gorm.tabler
+func (f Feed) TableName() string {
+	return "calendar_feeds"
+}
+
+// Repository encapsulates storage & retrieval of calendar feeds
+type Repository interface {
+	Load(ctx context.Context, token string) (*Feed, error)
+	Rotate(ctx context.Context, identityID uuid.UUID, projectID *uuid.UUID) (*Feed, error)
+	Revoke(ctx context.Context, identityID uuid.UUID, projectID *uuid.UUID) error
+	ProjectIDs(ctx context.Context, identityID uuid.UUID) ([]uuid.UUID, error)
+	DueWorkItems(ctx context.Context, projectID uuid.UUID) ([]DueWorkItem, error)
+}
+
+// DueWorkItem is a work item with a due date
+type DueWorkItem struct {
+	ID      uint64
+	Title   string
+	DueDate time.Time
+}
+
+// NewFeedRepository creates a new storage type.
+func NewFeedRepository(db *gorm.DB) Repository {
+	return &GormFeedRepository{db: db}
+}
+
+// GormFeedRepository is the implementation of the storage interface for
+// calendar feeds.
+type GormFeedRepository struct {
+	db *gorm.DB
+}
+
+// Load returns the feed with the given token
+// returns NotFoundError or InternalError
+func (m *GormFeedRepository) Load(ctx context.Context, token string) (*Feed, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "calendarfeed", "load"}, time.Now())
+
+	var res Feed
+	tx := m.db.Where("token = ?", token).First(&res)
+	if tx.RecordNotFound() {
+		// the token is a secret, don't echo it
+		return nil, errors.NewNotFoundError("calendar feed", "")
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &res, nil
+}
+
+// Rotate gives the feed of the identity, or of the identity for the project
+// if projectID isn't nil, a new token. The previous token stops working.
+// returns InternalError
+func (m *GormFeedRepository) Rotate(ctx context.Context, identityID uuid.UUID, projectID *uuid.UUID) (*Feed, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "calendarfeed", "rotate"}, time.Now())
+
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	res := Feed{Token: hex.EncodeToString(b), IdentityID: identityID, ProjectID: projectID}
+	if err := m.feed(identityID, projectID).Delete(&Feed{}).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	if err := m.db.Create(&res).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return &res, nil
+}
+
+// Revoke deletes the feed of the identity, or of the identity for the
+// project if projectID isn't nil
+// returns NotFoundError or InternalError
+func (m *GormFeedRepository) Revoke(ctx context.Context, identityID uuid.UUID, projectID *uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "calendarfeed", "revoke"}, time.Now())
+
+	tx := m.feed(identityID, projectID).Delete(&Feed{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		id := identityID.String()
+		if projectID != nil {
+			id = fmt.Sprintf("%s/%s", identityID, projectID)
+		}
+		return errors.NewNotFoundError("calendar feed", id)
+	}
+	return nil
+}
+
+// ProjectIDs returns the projects shown in the feed of the identity: the
+// ones it administrates and the ones with work items assigned to it
+// returns InternalError
+func (m *GormFeedRepository) ProjectIDs(ctx context.Context, identityID uuid.UUID) ([]uuid.UUID, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "calendarfeed", "projectids"}, time.Now())
+
+	assigned, _ := json.Marshal(map[string]interface{}{workitem.SystemAssignees: []string{identityID.String()}})
+	var ids []string
+	err := m.db.Raw(fmt.Sprintf(`SELECT project_id::text AS id FROM project_admins WHERE identity_id = ? AND deleted_at IS NULL
+		UNION SELECT fields->>'%[1]s' AS id FROM work_items WHERE deleted_at IS NULL AND fields->>'%[1]s' IS NOT NULL AND fields @> ?
+		ORDER BY id`, workitem.SystemProject), identityID, string(assigned)).Pluck("id", &ids).Error
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	res := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		projectID, err := uuid.FromString(id)
+		if err != nil {
+			continue
+		}
+		res = append(res, projectID)
+	}
+	return res, nil
+}
+
+// DueWorkItems returns the work items of the project with a due date which
+// the viewer of ctx can see, archived work items are left out
+// returns InternalError
+func (m *GormFeedRepository) DueWorkItems(ctx context.Context, projectID uuid.UUID) ([]DueWorkItem, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "calendarfeed", "dueworkitems"}, time.Now())
+
+	table := workitem.WorkItem{}.TableName()
+	db := m.db.Table(table).
+		Select(fmt.Sprintf("id, fields->>'%s' AS title, (fields->>'%s')::bigint AS due", workitem.SystemTitle, workitem.SystemDueDate)).
+		Where(fmt.Sprintf("deleted_at IS NULL AND NOT archived AND fields->>'%s' = ? AND fields->>'%s' IS NOT NULL", workitem.SystemProject, workitem.SystemDueDate), projectID.String())
+	if clause, params := workitem.VisibilityClause(ctx, table); clause != "" {
+		db = db.Where(clause, params...)
+	}
+	var rows []struct {
+		ID    uint64
+		Title string
+		Due   int64
+	}
+	if err := db.Order("due").Scan(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	res := make([]DueWorkItem, len(rows))
+	for i, r := range rows {
+		res[i] = DueWorkItem{ID: r.ID, Title: r.Title, DueDate: time.Unix(0, r.Due)}
+	}
+	return res, nil
+}
+
+// feed selects the feed of the identity for the project, or its own feed if
+// projectID is nil
+func (m *GormFeedRepository) feed(identityID uuid.UUID, projectID *uuid.UUID) *gorm.DB {
+	db := m.db.Where("identity_id = ?", identityID)
+	if projectID == nil {
+		return db.Where("project_id IS NULL")
+	}
+	return db.Where("project_id = ?", *projectID)
+}
@@ -0,0 +1,91 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLineOctets is the length lines are folded at, see RFC 5545 3.1
+const maxLineOctets = 75
+
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405Z"
+)
+
+// Event is an entry of a feed
+type Event struct {
+	// UID identifies the event across updates of the feed
+	UID         string
+	Summary     string
+	Description string
+	URL         string
+	Start       time.Time
+	End         time.Time
+	// AllDay events last from the day of Start to the day of End, both
+	// included, in the location of Start and End
+	AllDay bool
+}
+
+// textEscaper escapes the characters that have a meaning in TEXT values
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// Render returns the iCalendar document of the feed with the given name
+// and events, stamped with the given time
+func Render(name string, events []Event, stamp time.Time) []byte {
+	var buf bytes.Buffer
+	line := func(prop, value string) {
+		writeLine(&buf, prop+":"+value)
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//almighty//almighty-core//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", textEscaper.Replace(name))
+	for _, e := range events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", stamp.UTC().Format(dateTimeLayout))
+		if e.AllDay {
+			line("DTSTART;VALUE=DATE", e.Start.Format(dateLayout))
+			// the end of all day events is exclusive
+			line("DTEND;VALUE=DATE", e.End.AddDate(0, 0, 1).Format(dateLayout))
+		} else {
+			line("DTSTART", e.Start.UTC().Format(dateTimeLayout))
+			line("DTEND", e.End.UTC().Format(dateTimeLayout))
+		}
+		line("SUMMARY", textEscaper.Replace(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", textEscaper.Replace(e.Description))
+		}
+		if e.URL != "" {
+			line("URL", e.URL)
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return buf.Bytes()
+}
+
+// writeLine writes the content line terminated by CRLF, folding it into
+// continuation lines starting with a space so no line is longer than
+// maxLineOctets. Lines are only folded between characters.
+func writeLine(buf *bytes.Buffer, s string) {
+	limit := maxLineOctets
+	for len(s) > limit {
+		n := limit
+		for n > 0 && !utf8.RuneStart(s[n]) {
+			n--
+		}
+		buf.WriteString(s[:n])
+		buf.WriteString("\r\n ")
+		s = s[n:]
+		// the leading space counts
+		limit = maxLineOctets - 1
+	}
+	buf.WriteString(s)
+	buf.WriteString("\r\n")
+}
@@ -0,0 +1,112 @@
+package calendar_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/calendar"
+	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/release"
+	"github.com/almighty/almighty-core/resource"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	stamp := time.Date(2017, 3, 1, 12, 30, 0, 0, time.UTC)
+	events := []calendar.Event{
+		{
+			UID:     "sprint@test",
+			Summary: "Sprint 1, the first; really",
+			Start:   time.Date(2017, 3, 6, 0, 0, 0, 0, time.UTC),
+			End:     time.Date(2017, 3, 17, 0, 0, 0, 0, time.UTC),
+			AllDay:  true,
+		},
+		{
+			UID:         "demo@test",
+			Summary:     "Demo",
+			Description: strings.Repeat("é", 60) + "\nsecond line",
+			Start:       time.Date(2017, 3, 17, 14, 0, 0, 0, time.FixedZone("CET", 3600)),
+			End:         time.Date(2017, 3, 17, 15, 0, 0, 0, time.FixedZone("CET", 3600)),
+		},
+	}
+	doc := string(calendar.Render("Team", events, stamp))
+
+	require.True(t, strings.HasSuffix(doc, "END:VCALENDAR\r\n"))
+	lines := strings.Split(strings.TrimSuffix(doc, "\r\n"), "\r\n")
+	assert.Equal(t, "BEGIN:VCALENDAR", lines[0])
+	for _, l := range lines {
+		assert.True(t, len(l) <= 75, "line too long: %q", l)
+		assert.NotContains(t, l, "\n")
+	}
+	assert.Contains(t, lines, "SUMMARY:Sprint 1\\, the first\\; really")
+	assert.Contains(t, lines, "DTSTAMP:20170301T123000Z")
+	// the end of all day events is exclusive
+	assert.Contains(t, lines, "DTSTART;VALUE=DATE:20170306")
+	assert.Contains(t, lines, "DTEND;VALUE=DATE:20170318")
+	assert.Contains(t, lines, "DTSTART:20170317T130000Z")
+	assert.Contains(t, lines, "DTEND:20170317T140000Z")
+
+	// unfolding restores the description without splitting characters
+	unfolded := strings.Replace(doc, "\r\n ", "", -1)
+	assert.Contains(t, unfolded, "DESCRIPTION:"+strings.Repeat("é", 60)+"\\nsecond line\r\n")
+}
+
+func TestIterationEvent(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	berlin := time.FixedZone("CET", 3600)
+	start := time.Date(2017, 3, 5, 23, 30, 0, 0, time.UTC)
+	end := time.Date(2017, 3, 17, 12, 0, 0, 0, time.UTC)
+	i := iteration.Iteration{ID: uuid.NewV4(), Name: "Sprint 1", StartAt: &start, EndAt: &end}
+
+	e, ok := calendar.IterationEvent(i, "http://test/iterations/1", berlin)
+	require.True(t, ok)
+	assert.Equal(t, "Sprint 1", e.Summary)
+	assert.True(t, e.AllDay)
+	// the dates are the ones in the location
+	assert.Equal(t, 6, e.Start.Day())
+	assert.Equal(t, 17, e.End.Day())
+
+	i.StartAt = nil
+	e, ok = calendar.IterationEvent(i, "", berlin)
+	require.True(t, ok)
+	assert.Equal(t, e.End, e.Start)
+
+	i.EndAt = nil
+	_, ok = calendar.IterationEvent(i, "", berlin)
+	assert.False(t, ok)
+}
+
+func TestReleaseEvent(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	r := release.Release{ID: uuid.NewV4(), Version: "1.2.0"}
+	_, ok := calendar.ReleaseEvent(r, "", time.UTC)
+	assert.False(t, ok)
+
+	target := time.Date(2017, 4, 1, 0, 0, 0, 0, time.UTC)
+	r.TargetDate = &target
+	e, ok := calendar.ReleaseEvent(r, "", time.UTC)
+	require.True(t, ok)
+	assert.Equal(t, "Release 1.2.0 due", e.Summary)
+	assert.Equal(t, target, e.Start)
+}
+
+func TestWorkItemEvent(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	berlin := time.FixedZone("CET", 3600)
+	due := time.Date(2017, 4, 1, 23, 30, 0, 0, time.UTC)
+	e := calendar.WorkItemEvent(calendar.DueWorkItem{ID: 42, Title: "Ship it", DueDate: due}, "http://test/workitems/42", berlin)
+	assert.Equal(t, "workitem-42@almighty-core", e.UID)
+	assert.Equal(t, "Ship it due", e.Summary)
+	assert.True(t, e.AllDay)
+	// the date is the one in the location
+	assert.Equal(t, 2, e.Start.Day())
+	assert.Equal(t, e.Start, e.End)
+}
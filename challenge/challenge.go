@@ -0,0 +1,108 @@
+// Package challenge verifies the CAPTCHA challenges anonymous contributors
+// to public projects solve to prove they are human.
+package challenge
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/errors"
+)
+
+// Supported challenge providers
+const (
+	ProviderRecaptcha = "recaptcha"
+	ProviderHCaptcha  = "hcaptcha"
+)
+
+// Verifier verifies the response token of a solved challenge
+type Verifier interface {
+	// Verify returns BadParameterError if the token is not a valid solution
+	// or InternalError if the provider can't be reached
+	Verify(ctx context.Context, token string, remoteIP string) error
+}
+
+// ValidateProvider returns BadParameterError if the projects can't require
+// the challenge of the given provider, an empty provider requires none
+func ValidateProvider(provider string) error {
+	if provider == "" {
+		return nil
+	}
+	if _, err := NewVerifier(provider); err != nil {
+		return err
+	}
+	return nil
+}
+
+// NewVerifier returns the verifier of the given provider configured with
+// the secret from the configuration
+// returns BadParameterError for unknown or unconfigured providers
+func NewVerifier(provider string) (Verifier, error) {
+	var secret, verifyURL string
+	switch provider {
+	case ProviderRecaptcha:
+		secret, verifyURL = configuration.GetChallengeRecaptchaSecret(), configuration.GetChallengeRecaptchaURL()
+	case ProviderHCaptcha:
+		secret, verifyURL = configuration.GetChallengeHCaptchaSecret(), configuration.GetChallengeHCaptchaURL()
+	default:
+		return nil, errors.NewBadParameterError("challenge", provider).Expected([]string{ProviderRecaptcha, ProviderHCaptcha})
+	}
+	if secret == "" {
+		return nil, errors.NewBadParameterError("challenge", provider).Expected("a provider with a configured secret")
+	}
+	return NewSiteVerifier(verifyURL, secret), nil
+}
+
+// NewSiteVerifier returns a verifier for providers implementing the
+// siteverify protocol shared by reCAPTCHA and hCaptcha
+func NewSiteVerifier(verifyURL string, secret string) Verifier {
+	return &siteVerifier{
+		url:    verifyURL,
+		secret: secret,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type siteVerifier struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// siteVerifyResponse is the response of the siteverify endpoint
+type siteVerifyResponse struct {
+	Success    bool     `json:"success"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// Verify implements Verifier
+func (v *siteVerifier) Verify(ctx context.Context, token string, remoteIP string) error {
+	if token == "" {
+		return errors.NewBadParameterError("challenge token", token).Expected("not empty")
+	}
+	form := url.Values{"secret": {v.secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	res, err := v.client.PostForm(v.url, form)
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return errors.NewInternalError("challenge verification failed with status " + res.Status)
+	}
+	var result siteVerifyResponse
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if !result.Success {
+		return errors.NewBadParameterError("challenge token", result.ErrorCodes).Expected("a solved challenge")
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package challenge_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/challenge"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSiteVerifier(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Nil(t, r.ParseForm())
+		assert.Equal(t, "s3cret", r.PostForm.Get("secret"))
+		assert.Equal(t, "10.0.0.1", r.PostForm.Get("remoteip"))
+		if r.PostForm.Get("response") == "solved" {
+			w.Write([]byte(`{"success": true}`))
+			return
+		}
+		w.Write([]byte(`{"success": false, "error-codes": ["invalid-input-response"]}`))
+	}))
+	defer server.Close()
+
+	v := challenge.NewSiteVerifier(server.URL, "s3cret")
+	assert.Nil(t, v.Verify(context.Background(), "solved", "10.0.0.1"))
+	assert.IsType(t, errors.BadParameterError{}, v.Verify(context.Background(), "guessed", "10.0.0.1"))
+	assert.IsType(t, errors.BadParameterError{}, v.Verify(context.Background(), "", "10.0.0.1"))
+}
+
+func TestSiteVerifierUnavailable(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	err := challenge.NewSiteVerifier(server.URL, "s3cret").Verify(context.Background(), "solved", "")
+	assert.IsType(t, errors.InternalError{}, err)
+}
+
+func TestValidateProvider(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	assert.Nil(t, challenge.ValidateProvider(""))
+	assert.IsType(t, errors.BadParameterError{}, challenge.ValidateProvider("turing-test"))
+}
@@ -0,0 +1,74 @@
+// Package changefeed publishes the changes of work items, links and comments
+// to a message bus. Database triggers write each change to the outbox in the
+// transaction making the change, so a change is published exactly if it was
+// committed, and the outbox relay delivers it through the bus sink. A change
+// may be published again if the relay stops between publishing and marking
+// it, consumers drop duplicates by the message ID.
+package changefeed
+
+import (
+	"encoding/json"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/outbox"
+	uuid "github.com/satori/go.uuid"
+)
+
+// MessageVersion is the version of the message format, it changes when
+// messages change in a way consumers have to know about
+const MessageVersion = 1
+
+// Entities changes are recorded for, the triggers use them as the topic of
+// the outbox records
+const (
+	EntityWorkItem = "workitem"
+	EntityLink     = "link"
+	EntityComment  = "comment"
+)
+
+// Message is the JSON representation of a change on the message bus, the
+// triggers write it as the payload of the outbox record
+type Message struct {
+	Version  int             `json:"version"`
+	ID       uuid.UUID       `json:"id"`
+	Sequence uint64          `json:"sequence"`
+	Entity   string          `json:"entity"`
+	Op       string          `json:"op"`
+	EntityID string          `json:"entity_id"`
+	Time     time.Time       `json:"time"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// Topic returns the topic the records of the outbox topic are published to
+func Topic(prefix string, topic string) string {
+	return prefix + "." + topic
+}
+
+// BusSink is the outbox sink publishing the records to the message bus,
+// records are keyed by their key so the changes of an entity stay in order
+type BusSink struct {
+	publisher Publisher
+	prefix    string
+}
+
+// NewBusSink creates a sink publishing to topics with the given prefix
+func NewBusSink(publisher Publisher, prefix string) *BusSink {
+	return &BusSink{publisher: publisher, prefix: prefix}
+}
+
+// Name implements outbox.Sink
+func (s *BusSink) Name() string {
+	return "bus"
+}
+
+// Deliver implements outbox.Sink
+func (s *BusSink) Deliver(ctx context.Context, r outbox.Record) error {
+	return s.publisher.Publish(Topic(s.prefix, r.Topic), r.Key, []byte(r.Payload))
+}
+
+// Close implements outbox.Sink
+func (s *BusSink) Close() error {
+	return s.publisher.Close()
+}
@@ -0,0 +1,167 @@
+package changefeed_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/changefeed"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/outbox"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestNewPublisher(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	p, err := changefeed.NewPublisher("", nil)
+	require.Nil(t, err)
+	assert.Nil(t, p)
+	_, err = changefeed.NewPublisher("carrier-pigeon", []string{"localhost"})
+	assert.IsType(t, errors.BadParameterError{}, err)
+	_, err = changefeed.NewPublisher(changefeed.PublisherKafka, nil)
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
+
+type published struct {
+	topic string
+	key   string
+	value []byte
+}
+
+// fakePublisher records the published messages, it fails once failAfter
+// messages were published
+type fakePublisher struct {
+	messages  []published
+	failAfter int
+}
+
+func (p *fakePublisher) Publish(topic string, key string, value []byte) error {
+	if p.failAfter >= 0 && len(p.messages) >= p.failAfter {
+		return fmt.Errorf("bus is down")
+	}
+	p.messages = append(p.messages, published{topic: topic, key: key, value: value})
+	return nil
+}
+
+func (p *fakePublisher) Close() error {
+	return nil
+}
+
+// messagesOf returns the messages about the given entity
+func (p *fakePublisher) messagesOf(t *testing.T, entityID string) []changefeed.Message {
+	var res []changefeed.Message
+	for _, m := range p.messages {
+		if m.key != entityID {
+			continue
+		}
+		var msg changefeed.Message
+		require.Nil(t, json.Unmarshal(m.value, &msg))
+		res = append(res, msg)
+	}
+	return res
+}
+
+type TestChangeFeed struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunChangeFeed(t *testing.T) {
+	suite.Run(t, &TestChangeFeed{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestChangeFeed) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestChangeFeed) TearDownTest() {
+	test.clean()
+}
+
+// drain publishes all pending events
+func (test *TestChangeFeed) drain(relay *outbox.Relay) {
+	for {
+		n, err := relay.RunOnce(context.Background())
+		require.Nil(test.T(), err)
+		if n == 0 {
+			return
+		}
+	}
+}
+
+func (test *TestChangeFeed) TestPublishChanges() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	publisher := &fakePublisher{failAfter: -1}
+	relay := outbox.NewRelay(test.DB, []outbox.Sink{changefeed.NewBusSink(publisher, "test")}, time.Hour, 10)
+	test.drain(relay)
+
+	repo := workitem.NewWorkItemRepository(test.DB)
+	wi, err := repo.Create(ctx, workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle: "title",
+		workitem.SystemState: workitem.SystemStateNew,
+	}, "xx")
+	require.Nil(t, err)
+	wi.Fields[workitem.SystemTitle] = "changed"
+	_, err = repo.Save(ctx, *wi)
+	require.Nil(t, err)
+	require.Nil(t, repo.Delete(ctx, wi.ID))
+	test.drain(relay)
+
+	msgs := publisher.messagesOf(t, wi.ID)
+	require.Len(t, msgs, 3)
+	assert.Equal(t, changefeed.MessageVersion, msgs[0].Version)
+	assert.Equal(t, changefeed.EntityWorkItem, msgs[0].Entity)
+	assert.Equal(t, "create", msgs[0].Op)
+	assert.Equal(t, "update", msgs[1].Op)
+	assert.Equal(t, "delete", msgs[2].Op)
+	assert.True(t, msgs[0].Sequence < msgs[1].Sequence)
+	assert.Equal(t, "test.workitem", publisher.messages[len(publisher.messages)-1].topic)
+
+	// published events are not published again
+	test.drain(relay)
+	assert.Len(t, publisher.messagesOf(t, wi.ID), 3)
+}
+
+func (test *TestChangeFeed) TestFailedEventsStayPending() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	publisher := &fakePublisher{failAfter: -1}
+	relay := outbox.NewRelay(test.DB, []outbox.Sink{changefeed.NewBusSink(publisher, "test")}, time.Hour, 10)
+	test.drain(relay)
+
+	repo := workitem.NewWorkItemRepository(test.DB)
+	wi, err := repo.Create(ctx, workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle: "title",
+		workitem.SystemState: workitem.SystemStateNew,
+	}, "xx")
+	require.Nil(t, err)
+
+	down := &fakePublisher{failAfter: 0}
+	n, err := outbox.NewRelay(test.DB, []outbox.Sink{changefeed.NewBusSink(down, "test")}, time.Hour, 10).RunOnce(ctx)
+	assert.Equal(t, 0, n)
+	assert.IsType(t, errors.InternalError{}, err)
+
+	test.drain(relay)
+	msgs := publisher.messagesOf(t, wi.ID)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, "create", msgs[0].Op)
+	var data map[string]interface{}
+	require.Nil(t, json.Unmarshal(msgs[0].Data, &data))
+	assert.Equal(t, workitem.SystemBug, data["type"])
+}
@@ -0,0 +1,115 @@
+package changefeed
+
+import (
+	"github.com/Shopify/sarama"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/nats-io/go-nats"
+)
+
+// Kinds of publishers
+const (
+	PublisherKafka = "kafka"
+	PublisherNATS  = "nats"
+)
+
+// Publisher sends messages to a message bus. Publish returns once the bus
+// accepted the message.
+type Publisher interface {
+	Publish(topic string, key string, value []byte) error
+	Close() error
+}
+
+// NewPublisher connects to the brokers of the message bus of the given kind,
+// an empty kind disables the change feed and returns a nil publisher
+// returns BadParameterError or InternalError
+func NewPublisher(kind string, brokers []string) (Publisher, error) {
+	switch kind {
+	case "":
+		return nil, nil
+	case PublisherKafka, PublisherNATS:
+	default:
+		return nil, errors.NewBadParameterError("changefeed.publisher", kind).Expected([]string{PublisherKafka, PublisherNATS})
+	}
+	if len(brokers) == 0 {
+		return nil, errors.NewBadParameterError("changefeed.brokers", brokers).Expected("at least one broker")
+	}
+	if kind == PublisherKafka {
+		p, err := NewKafkaPublisher(brokers)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+	p, err := NewNATSPublisher(brokers)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// KafkaPublisher publishes to Kafka topics, messages are keyed by the ID of
+// the changed entity so the changes of an entity stay in order
+type KafkaPublisher struct {
+	producer sarama.SyncProducer
+}
+
+// NewKafkaPublisher connects to the given Kafka brokers
+// returns InternalError
+func NewKafkaPublisher(brokers []string) (*KafkaPublisher, error) {
+	config := sarama.NewConfig()
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Retry.Max = 5
+	config.Producer.Return.Successes = true
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return &KafkaPublisher{producer: producer}, nil
+}
+
+// Publish implements Publisher
+func (p *KafkaPublisher) Publish(topic string, key string, value []byte) error {
+	_, _, err := p.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: topic,
+		Key:   sarama.StringEncoder(key),
+		Value: sarama.ByteEncoder(value),
+	})
+	return err
+}
+
+// Close implements Publisher
+func (p *KafkaPublisher) Close() error {
+	return p.producer.Close()
+}
+
+// NATSPublisher publishes to NATS subjects
+type NATSPublisher struct {
+	conn *nats.Conn
+}
+
+// NewNATSPublisher connects to the given NATS servers
+// returns InternalError
+func NewNATSPublisher(servers []string) (*NATSPublisher, error) {
+	opts := nats.DefaultOptions
+	opts.Servers = servers
+	conn, err := opts.Connect()
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return &NATSPublisher{conn: conn}, nil
+}
+
+// Publish implements Publisher, NATS has no keys. The connection is flushed
+// so the message reached the server when Publish returns.
+func (p *NATSPublisher) Publish(topic string, key string, value []byte) error {
+	if err := p.conn.Publish(topic, value); err != nil {
+		return err
+	}
+	return p.conn.Flush()
+}
+
+// Close implements Publisher
+func (p *NATSPublisher) Close() error {
+	p.conn.Close()
+	return nil
+}
@@ -0,0 +1,124 @@
+package chat
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// LinkCodeTTL is how long a link code can be used
+const LinkCodeTTL = 10 * time.Minute
+
+// linkCodeAlphabet has no characters easily confused when typed
+const linkCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// linkCodeLength is the number of characters of a link code
+const linkCodeLength = 8
+
+// account maps a user of the chat of a project to an identity
+type account struct {
+	CreatedAt  time.Time
+	ProjectID  uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	ChatUserID string    `gorm:"primary_key"`
+	IdentityID uuid.UUID `sql:"type:uuid"`
+}
+
+// TableName implements gorm.tabler
+func (a account) TableName() string {
+	return "chat_accounts"
+}
+
+// LinkCode is typed in the chat of a project to link the chat user to the
+// identity that requested the code
+type LinkCode struct {
+	CreatedAt  time.Time
+	Code       string    `gorm:"primary_key"`
+	ProjectID  uuid.UUID `sql:"type:uuid"`
+	IdentityID uuid.UUID `sql:"type:uuid"`
+	ExpiresAt  time.Time
+}
+
+// TableName implements gorm.tabler
+func (c LinkCode) TableName() string {
+	return "chat_link_codes"
+}
+
+// Account returns the identity the chat user of the project is linked to
+// returns NotFoundError or InternalError
+func (m *GormIntegrationRepository) Account(ctx context.Context, projectID uuid.UUID, chatUserID string) (uuid.UUID, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "chatintegration", "account"}, time.Now())
+
+	var a account
+	tx := m.db.Where("project_id = ? AND chat_user_id = ?", projectID, chatUserID).First(&a)
+	if tx.RecordNotFound() {
+		return uuid.Nil, errors.NewNotFoundError("chat account", chatUserID)
+	}
+	if tx.Error != nil {
+		return uuid.Nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return a.IdentityID, nil
+}
+
+// CreateLinkCode returns a new code linking the chat user typing it in the
+// chat of the project to the identity, the previous code of the identity
+// stops working
+// returns InternalError
+func (m *GormIntegrationRepository) CreateLinkCode(ctx context.Context, projectID uuid.UUID, identityID uuid.UUID) (*LinkCode, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "chatintegration", "createlinkcode"}, time.Now())
+
+	// rand.Int picks the characters uniformly whatever the size of the
+	// alphabet, unlike the remainder of random bytes
+	size := big.NewInt(int64(len(linkCodeAlphabet)))
+	b := make([]byte, linkCodeLength)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, size)
+		if err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		b[i] = linkCodeAlphabet[n.Int64()]
+	}
+	now := time.Now()
+	c := LinkCode{Code: string(b), ProjectID: projectID, IdentityID: identityID, CreatedAt: now, ExpiresAt: now.Add(LinkCodeTTL)}
+	if err := m.db.Where("project_id = ? AND identity_id = ?", projectID, identityID).Delete(LinkCode{}).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	if err := m.db.Create(&c).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return &c, nil
+}
+
+// LinkAccount links the chat user of the project to the identity that
+// requested the code and deletes the code. A chat user that was linked to
+// another identity is linked to the new one.
+// returns NotFoundError if the code is unknown or expired, or InternalError
+func (m *GormIntegrationRepository) LinkAccount(ctx context.Context, projectID uuid.UUID, code string, chatUserID string) (uuid.UUID, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "chatintegration", "linkaccount"}, time.Now())
+
+	var c LinkCode
+	tx := m.db.Where("code = ? AND project_id = ? AND expires_at > now()", strings.ToUpper(code), projectID).First(&c)
+	if tx.RecordNotFound() {
+		// the code is a secret, don't echo it
+		return uuid.Nil, errors.NewNotFoundError("link code", "")
+	}
+	if tx.Error != nil {
+		return uuid.Nil, errors.NewInternalError(tx.Error.Error())
+	}
+	if err := m.db.Delete(&c).Error; err != nil {
+		return uuid.Nil, errors.NewInternalError(err.Error())
+	}
+	err := m.db.Exec(`INSERT INTO chat_accounts (project_id, chat_user_id, identity_id, created_at) VALUES (?, ?, ?, now())
+		ON CONFLICT (project_id, chat_user_id) DO UPDATE SET identity_id = excluded.identity_id, created_at = now()`,
+		projectID, chatUserID, c.IdentityID).Error
+	if err != nil {
+		return uuid.Nil, errors.NewInternalError(err.Error())
+	}
+	return c.IdentityID, nil
+}
@@ -0,0 +1,122 @@
+package chat_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/chat"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	m := chat.Message{
+		Event:     chat.EventAssigned,
+		Title:     "Fix <login> & [signup]",
+		URL:       "http://test/workitems/1",
+		Actor:     "Jane",
+		Assignees: []string{"Joe", "Ann"},
+	}
+	text, err := chat.Render(chat.Integration{Kind: chat.KindSlack}, m)
+	require.Nil(t, err)
+	assert.Equal(t, "Jane assigned <http://test/workitems/1|Fix &lt;login&gt; &amp; [signup]> to Joe, Ann", text)
+
+	text, err = chat.Render(chat.Integration{Kind: chat.KindMattermost}, m)
+	require.Nil(t, err)
+	assert.Equal(t, `Jane assigned [Fix <login> & \[signup\]](http://test/workitems/1) to Joe, Ann`, text)
+
+	m.Event = chat.EventStateChanged
+	m.PreviousState = "new"
+	m.State = "closed"
+	text, err = chat.Render(chat.Integration{Kind: chat.KindMattermost, StateChangedTemplate: "{{.Title}}: {{.State}}"}, m)
+	require.Nil(t, err)
+	assert.Equal(t, "Fix <login> & [signup]: closed", text)
+
+	m.Event = "workitem.unknown"
+	_, err = chat.Render(chat.Integration{Kind: chat.KindSlack}, m)
+	assert.NotNil(t, err)
+}
+
+func TestPost(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	var received []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&payload))
+		received = append(received, payload)
+		if payload["text"] == "fail" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"ok": true, "ts": "1503435956.000247"}`))
+	}))
+	defer server.Close()
+
+	poster := chat.NewPoster(time.Second)
+	i := chat.Integration{Kind: chat.KindSlack, WebhookURL: server.URL, Channel: "#planning"}
+	id, err := poster.Post(i, "first", "")
+	require.Nil(t, err)
+	assert.Equal(t, "1503435956.000247", id)
+	_, err = poster.Post(i, "reply", id)
+	require.Nil(t, err)
+	require.Len(t, received, 2)
+	assert.Equal(t, map[string]string{"text": "first", "channel": "#planning"}, received[0])
+	assert.Equal(t, id, received[1]["thread_ts"])
+
+	i.Kind = chat.KindMattermost
+	_, err = poster.Post(i, "reply", id)
+	require.Nil(t, err)
+	assert.Equal(t, id, received[2]["root_id"])
+
+	_, err = poster.Post(i, "fail", "")
+	require.NotNil(t, err)
+	assert.NotContains(t, err.Error(), server.URL)
+}
+
+func TestParseCommand(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	c, err := chat.ParseCommand(`create "Fix the login" type:bug assignee:me`)
+	require.Nil(t, err)
+	assert.Equal(t, chat.VerbCreate, c.Verb)
+	assert.Equal(t, "Fix the login", c.Title)
+	assert.Equal(t, map[string]string{"type": "bug", "assignee": "me"}, c.Options)
+
+	c, err = chat.ParseCommand(`create Fix “the” login`)
+	require.Nil(t, err)
+	assert.Equal(t, "Fix the login", c.Title)
+
+	c, err = chat.ParseCommand(`update #42 state:"in progress" title:"Fix: the login"`)
+	require.Nil(t, err)
+	assert.Equal(t, "42", c.WorkItemID)
+	assert.Equal(t, map[string]string{"state": "in progress", "title": "Fix: the login"}, c.Options)
+
+	c, err = chat.ParseCommand(" link K7M2QX9P ")
+	require.Nil(t, err)
+	assert.Equal(t, "K7M2QX9P", c.Code)
+
+	c, err = chat.ParseCommand("")
+	require.Nil(t, err)
+	assert.Equal(t, chat.VerbHelp, c.Verb)
+
+	for _, text := range []string{
+		`create`,
+		`create "Fix the login`,
+		`update 42`,
+		`update 42 43 state:closed`,
+		`update 42 type:bug`,
+		`update 42 assignee:joe`,
+		`link`,
+		`delete 42`,
+	} {
+		_, err := chat.ParseCommand(text)
+		assert.NotNil(t, err, text)
+	}
+}
@@ -0,0 +1,159 @@
+package chat
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/almighty/almighty-core/errors"
+)
+
+// Verbs of the slash commands
+const (
+	VerbCreate = "create"
+	VerbUpdate = "update"
+	VerbLink   = "link"
+	VerbHelp   = "help"
+)
+
+// Options of the slash commands, given as option:value
+const (
+	OptionType     = "type"
+	OptionState    = "state"
+	OptionTitle    = "title"
+	OptionAssignee = "assignee"
+)
+
+// Usage explains the slash commands
+const Usage = "Usage:\n" +
+	"`create \"title\" [type:bug] [state:open] [assignee:me]` creates a work item\n" +
+	"`update 42 [title:\"title\"] [state:closed] [assignee:me|none]` updates a work item\n" +
+	"`link CODE` links your chat account to the code of your profile\n" +
+	"`help` shows this message"
+
+// Command is a slash command typed in the chat, e.g.
+// /almighty create "Fix the login" type:bug
+type Command struct {
+	Verb string
+	// WorkItemID is the work item to update
+	WorkItemID string
+	// Title is the title of the work item to create
+	Title string
+	// Code is the link code
+	Code    string
+	Options map[string]string
+}
+
+// ParseCommand parses the text following the slash command. Words that
+// aren't options make up the title of created work items, double quotes
+// keep words together.
+// returns BadParameterError
+func ParseCommand(text string) (*Command, error) {
+	words, err := split(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return &Command{Verb: VerbHelp}, nil
+	}
+	c := Command{Verb: strings.ToLower(words[0]), Options: map[string]string{}}
+	var args []string
+	for _, w := range words[1:] {
+		if i := strings.Index(w, ":"); i > 0 {
+			switch option := strings.ToLower(w[:i]); option {
+			case OptionType, OptionState, OptionTitle, OptionAssignee:
+				c.Options[option] = w[i+1:]
+				continue
+			}
+		}
+		args = append(args, w)
+	}
+	switch c.Verb {
+	case VerbCreate:
+		c.Title = strings.Join(args, " ")
+		if t, ok := c.Options[OptionTitle]; ok {
+			c.Title = t
+			delete(c.Options, OptionTitle)
+		}
+		if strings.TrimSpace(c.Title) == "" {
+			return nil, errors.NewBadParameterError("title", c.Title).Expected("not empty")
+		}
+	case VerbUpdate:
+		if len(args) != 1 {
+			return nil, errors.NewBadParameterError("arguments", strings.Join(args, " ")).Expected("the ID of the work item")
+		}
+		c.WorkItemID = strings.TrimPrefix(args[0], "#")
+		if len(c.Options) == 0 {
+			return nil, errors.NewBadParameterError("options", "").Expected("one of title, state or assignee")
+		}
+		if _, ok := c.Options[OptionType]; ok {
+			return nil, errors.NewBadParameterError(OptionType, c.Options[OptionType]).Expected("not set, the type can't be changed")
+		}
+	case VerbLink:
+		if len(args) != 1 || len(c.Options) > 0 {
+			return nil, errors.NewBadParameterError("arguments", strings.Join(args, " ")).Expected("the link code")
+		}
+		c.Code = args[0]
+	case VerbHelp:
+	default:
+		return nil, errors.NewBadParameterError("command", c.Verb).Expected([]string{VerbCreate, VerbUpdate, VerbLink, VerbHelp})
+	}
+	if a, ok := c.Options[OptionAssignee]; ok && a != "me" && a != "none" {
+		return nil, errors.NewBadParameterError(OptionAssignee, a).Expected([]string{"me", "none"})
+	}
+	return &c, nil
+}
+
+// split splits the text into words at spaces outside of double quotes, the
+// quotes are removed
+func split(text string) ([]string, error) {
+	var words []string
+	var word []rune
+	quoted, inWord := false, false
+	// chats replace typed quotes with typographic ones
+	text = strings.NewReplacer("“", `"`, "”", `"`).Replace(text)
+	for _, r := range text {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			inWord = true
+		case unicode.IsSpace(r) && !quoted:
+			if inWord {
+				words = append(words, string(word))
+			}
+			word, inWord = nil, false
+		default:
+			word = append(word, r)
+			inWord = true
+		}
+	}
+	if quoted {
+		return nil, errors.NewBadParameterError("text", text).Expected("closed double quotes")
+	}
+	if inWord {
+		words = append(words, string(word))
+	}
+	return words, nil
+}
+
+// Response types of the replies to slash commands
+const (
+	// ResponseEphemeral is only shown to the user who typed the command
+	ResponseEphemeral = "ephemeral"
+	// ResponseInChannel is shown to everybody in the channel
+	ResponseInChannel = "in_channel"
+)
+
+// Reply is the response to a slash command, Slack and Mattermost
+// understand the same format
+type Reply struct {
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+}
+
+// Link formats a link in the markup of the chat of the given kind
+func Link(kind string, url string, text string) string {
+	if kind == KindSlack {
+		return "<" + url + "|" + slackEscaper.Replace(text) + ">"
+	}
+	return "[" + markdownEscaper.Replace(text) + "](" + url + ")"
+}
@@ -0,0 +1,222 @@
+// Package chat posts the changes of the work items of a project to a Slack
+// or Mattermost channel through an incoming webhook. Messages are rendered
+// from per project templates and delivered by background jobs, the messages
+// about a work item are grouped in a thread when the chat tells the ID of
+// the first one. Users of the chat whose account is linked to an identity
+// create and update work items with slash commands.
+package chat
+
+import (
+	"io/ioutil"
+	"net/url"
+	"text/template"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Kinds of chats
+const (
+	KindSlack      = "slack"
+	KindMattermost = "mattermost"
+)
+
+// Integration posts the changes of the work items of a project to a chat
+type Integration struct {
+	gormsupport.Lifecycle
+	ProjectID uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	Kind      string
+	// WebhookURL is the incoming webhook of the chat, it is a secret
+	WebhookURL string
+	// Channel overrides the channel of the webhook if not empty
+	Channel string
+	// The templates of the messages, empty uses the default template of the
+	// event, see Message
+	CreatedTemplate      string
+	AssignedTemplate     string
+	StateChangedTemplate string
+	// Threads groups the messages about a work item under the first one
+	Threads bool
+	// CommandToken is sent by the chat with the slash commands of the
+	// workspace, slash commands are refused if it is empty
+	CommandToken string
+}
+
+// TableName implements gorm.tabler
+func (i Integration) TableName() string {
+	return "chat_integrations"
+}
+
+// thread is the message starting the thread of a work item in the chat of
+// a project
+type thread struct {
+	CreatedAt  time.Time
+	ProjectID  uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	WorkItemID uint64    `gorm:"primary_key"`
+	ThreadID   string
+}
+
+// TableName implements gorm.tabler
+func (t thread) TableName() string {
+	return "chat_threads"
+}
+
+// Repository encapsulates storage & retrieval of chat integrations
+type Repository interface {
+	Load(ctx context.Context, projectID uuid.UUID) (*Integration, error)
+	Save(ctx context.Context, i Integration) (*Integration, error)
+	Delete(ctx context.Context, projectID uuid.UUID) error
+	Thread(ctx context.Context, projectID uuid.UUID, workItemID uint64) (string, error)
+	SaveThread(ctx context.Context, projectID uuid.UUID, workItemID uint64, threadID string) error
+	Account(ctx context.Context, projectID uuid.UUID, chatUserID string) (uuid.UUID, error)
+	CreateLinkCode(ctx context.Context, projectID uuid.UUID, identityID uuid.UUID) (*LinkCode, error)
+	LinkAccount(ctx context.Context, projectID uuid.UUID, code string, chatUserID string) (uuid.UUID, error)
+}
+
+// NewIntegrationRepository creates a new storage type.
+func NewIntegrationRepository(db *gorm.DB) Repository {
+	return &GormIntegrationRepository{db: db}
+}
+
+// GormIntegrationRepository is the implementation of the storage interface
+// for chat integrations.
+type GormIntegrationRepository struct {
+	db *gorm.DB
+}
+
+// Load returns the integration of the project
+// returns NotFoundError or InternalError
+func (m *GormIntegrationRepository) Load(ctx context.Context, projectID uuid.UUID) (*Integration, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "chatintegration", "get"}, time.Now())
+
+	var obj Integration
+	tx := m.db.Where("project_id = ?", projectID).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("chat integration", projectID.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
+
+// Save creates or replaces the integration of the project
+// returns BadParameterError or InternalError
+func (m *GormIntegrationRepository) Save(ctx context.Context, i Integration) (*Integration, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "chatintegration", "save"}, time.Now())
+
+	if err := i.validate(); err != nil {
+		return nil, err
+	}
+	var old Integration
+	moved := false
+	tx := m.db.Where("project_id = ?", i.ProjectID).First(&old)
+	if tx.Error != nil && !tx.RecordNotFound() {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	if !tx.RecordNotFound() {
+		moved = old.Kind != i.Kind || old.WebhookURL != i.WebhookURL || old.Channel != i.Channel
+	}
+	tx = m.db.Exec(`INSERT INTO chat_integrations (project_id, kind, webhook_url, channel, created_template, assigned_template,
+			state_changed_template, threads, command_token, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, now(), now())
+		ON CONFLICT (project_id) DO UPDATE SET kind = excluded.kind, webhook_url = excluded.webhook_url, channel = excluded.channel,
+			created_template = excluded.created_template, assigned_template = excluded.assigned_template,
+			state_changed_template = excluded.state_changed_template, threads = excluded.threads, command_token = excluded.command_token,
+			updated_at = now(), deleted_at = NULL`,
+		i.ProjectID, i.Kind, i.WebhookURL, i.Channel, i.CreatedTemplate, i.AssignedTemplate, i.StateChangedTemplate, i.Threads, i.CommandToken)
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	if moved {
+		// threads started in another channel can't be continued
+		if err := m.db.Where("project_id = ?", i.ProjectID).Delete(thread{}).Error; err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+	}
+	return m.Load(ctx, i.ProjectID)
+}
+
+// Delete removes the integration of the project, its changes aren't posted
+// from then on
+// returns NotFoundError or InternalError
+func (m *GormIntegrationRepository) Delete(ctx context.Context, projectID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "chatintegration", "delete"}, time.Now())
+
+	tx := m.db.Where("project_id = ?", projectID).Delete(&Integration{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("chat integration", projectID.String())
+	}
+	for _, obj := range []interface{}{thread{}, account{}, LinkCode{}} {
+		if err := m.db.Where("project_id = ?", projectID).Delete(obj).Error; err != nil {
+			return errors.NewInternalError(err.Error())
+		}
+	}
+	return nil
+}
+
+// Thread returns the ID of the message starting the thread of the work item
+// in the chat of the project, empty if there is none
+// returns InternalError
+func (m *GormIntegrationRepository) Thread(ctx context.Context, projectID uuid.UUID, workItemID uint64) (string, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "chatintegration", "thread"}, time.Now())
+
+	var t thread
+	tx := m.db.Where("project_id = ? AND work_item_id = ?", projectID, workItemID).First(&t)
+	if tx.RecordNotFound() {
+		return "", nil
+	}
+	if tx.Error != nil {
+		return "", errors.NewInternalError(tx.Error.Error())
+	}
+	return t.ThreadID, nil
+}
+
+// SaveThread records the message starting the thread of the work item in
+// the chat of the project
+// returns InternalError
+func (m *GormIntegrationRepository) SaveThread(ctx context.Context, projectID uuid.UUID, workItemID uint64, threadID string) error {
+	defer goa.MeasureSince([]string{"goa", "db", "chatintegration", "savethread"}, time.Now())
+
+	err := m.db.Exec(`INSERT INTO chat_threads (project_id, work_item_id, thread_id, created_at) VALUES (?, ?, ?, now())
+		ON CONFLICT (project_id, work_item_id) DO NOTHING`, projectID, workItemID, threadID).Error
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// validate returns BadParameterError if the kind, the webhook URL or one of
+// the templates is invalid
+func (i Integration) validate() error {
+	if i.Kind != KindSlack && i.Kind != KindMattermost {
+		return errors.NewBadParameterError("kind", i.Kind).Expected([]string{KindSlack, KindMattermost})
+	}
+	u, err := url.Parse(i.WebhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.NewBadParameterError("webhook_url", "").Expected("an http or https URL")
+	}
+	for name, text := range map[string]string{
+		"created_template":       i.CreatedTemplate,
+		"assigned_template":      i.AssignedTemplate,
+		"state_changed_template": i.StateChangedTemplate,
+	} {
+		tmpl, err := template.New(name).Funcs(templateFuncs(i.Kind)).Parse(text)
+		if err != nil {
+			return errors.NewBadParameterError(name, text).Expected("a valid template: " + err.Error())
+		}
+		// catches the fields messages don't have
+		if err := tmpl.Execute(ioutil.Discard, Message{}); err != nil {
+			return errors.NewBadParameterError(name, text).Expected("a template of the message: " + err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package chat
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/job"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Events messages are posted for
+const (
+	EventCreated      = "workitem.created"
+	EventAssigned     = "workitem.assigned"
+	EventStateChanged = "workitem.state-changed"
+)
+
+// JobKind is the kind of the jobs posting messages
+const JobKind = "chat.post"
+
+// Default templates of the messages
+const (
+	DefaultCreatedTemplate      = `{{.Actor}} created {{.Type}} {{link .URL .Title}}`
+	DefaultAssignedTemplate     = `{{.Actor}} assigned {{link .URL .Title}} to {{join .Assignees ", "}}`
+	DefaultStateChangedTemplate = `{{.Actor}} moved {{link .URL .Title}} from {{.PreviousState}} to {{.State}}`
+)
+
+// Message is what the templates render, it is also the payload of the jobs
+// posting it
+type Message struct {
+	Event      string
+	ProjectID  uuid.UUID
+	WorkItemID uint64
+	Type       string
+	Title      string
+	URL        string
+	// Actor is the name of the identity that made the change
+	Actor         string
+	State         string
+	PreviousState string
+	// Assignees are the names of the assignees of the work item
+	Assignees []string
+}
+
+// Notify enqueues posting the message to the chat of its project, within a
+// transaction the message is only posted if the transaction commits
+// returns BadParameterError or InternalError
+func Notify(ctx context.Context, jobs job.Repository, m Message) error {
+	_, err := jobs.Enqueue(ctx, JobKind, m)
+	return err
+}
+
+// Render returns the text of the message for the chat of the integration
+// returns BadParameterError if the event is unknown or the template fails
+func Render(i Integration, m Message) (string, error) {
+	var text string
+	switch m.Event {
+	case EventCreated:
+		text = orDefault(i.CreatedTemplate, DefaultCreatedTemplate)
+	case EventAssigned:
+		text = orDefault(i.AssignedTemplate, DefaultAssignedTemplate)
+	case EventStateChanged:
+		text = orDefault(i.StateChangedTemplate, DefaultStateChangedTemplate)
+	default:
+		return "", errors.NewBadParameterError("event", m.Event).Expected([]string{EventCreated, EventAssigned, EventStateChanged})
+	}
+	tmpl, err := template.New(m.Event).Funcs(templateFuncs(i.Kind)).Parse(text)
+	if err != nil {
+		return "", errors.NewBadParameterError("template", text).Expected("a valid template: " + err.Error())
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, m); err != nil {
+		return "", errors.NewBadParameterError("template", text).Expected("a template of the message: " + err.Error())
+	}
+	return buf.String(), nil
+}
+
+// templateFuncs returns the functions of the templates: link formats a link
+// in the markup of the chat and join joins strings
+func templateFuncs(kind string) template.FuncMap {
+	return template.FuncMap{
+		"link": func(url, text string) string {
+			return Link(kind, url, text)
+		},
+		"join": strings.Join,
+	}
+}
+
+// slackEscaper escapes the control characters of Slack messages
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// markdownEscaper escapes the characters ending the text of markdown links
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`)
+
+func orDefault(s string, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
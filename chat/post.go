@@ -0,0 +1,84 @@
+package chat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/almighty/almighty-core/breaker"
+)
+
+// maxResponse is the number of bytes of the responses of the chat read to
+// find the ID of the posted message
+const maxResponse = 64 * 1024
+
+// Poster posts messages to the webhooks of chats
+type Poster struct {
+	client *http.Client
+}
+
+// NewPoster creates a poster whose requests time out after the given
+// duration
+func NewPoster(timeout time.Duration) *Poster {
+	return &Poster{client: &http.Client{Timeout: timeout}}
+}
+
+// Post sends the text to the webhook of the integration, as a reply to the
+// given thread if it isn't empty, and returns the ID of the posted message
+// if the chat responds with it. Slack webhooks only respond "ok", messages
+// posted through them start no thread.
+func (p *Poster) Post(i Integration, text string, thread string) (string, error) {
+	payload := map[string]string{"text": text}
+	if i.Channel != "" {
+		payload["channel"] = i.Channel
+	}
+	if thread != "" {
+		if i.Kind == KindSlack {
+			payload["thread_ts"] = thread
+		} else {
+			payload["root_id"] = thread
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest("POST", i.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := breaker.For("chat "+req.URL.Host).Request(p.client, req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(res.Body, maxResponse))
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		// the URL of the webhook is a secret, keep it out of the error
+		return "", fmt.Errorf("the %s webhook responded %s", i.Kind, res.Status)
+	}
+	var posted struct {
+		OK    *bool  `json:"ok"`
+		Error string `json:"error"`
+		TS    string `json:"ts"`
+		ID    string `json:"id"`
+	}
+	if json.Unmarshal(data, &posted) != nil {
+		return "", nil
+	}
+	if posted.OK != nil && !*posted.OK {
+		return "", fmt.Errorf("the %s webhook responded %s", i.Kind, posted.Error)
+	}
+	if posted.TS != "" {
+		return posted.TS, nil
+	}
+	return posted.ID, nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/codebase"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// CodebaseController implements the codebase resource.
+type CodebaseController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewCodebaseController creates a codebase controller.
+func NewCodebaseController(service *goa.Service, db application.DB) *CodebaseController {
+	return &CodebaseController{Controller: service.NewController("CodebaseController"), db: db}
+}
+
+// Show runs the show action.
+func (c *CodebaseController) Show(ctx *app.ShowCodebaseContext) error {
+	id, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		cb, err := appl.Codebases().Load(ctx, id)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		b, err := appl.Codebases().LatestBuild(ctx, cb.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		res := &app.CodebaseSingle{
+			Data: ConvertCodebase(ctx.RequestData, cb, b),
+		}
+		return ctx.OK(res)
+	})
+}
+
+// CreateBuild runs the create-build action.
+func (c *CodebaseController) CreateBuild(ctx *app.CreateBuildCodebaseContext) error {
+	if currentIdentityID(ctx) == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	id, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		cb, err := appl.Codebases().Load(ctx, id)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		// the builds show up on the work items, so only the project admins
+		// (or the CI acting with their token) may report them
+		if err := checkProjectAdmin(ctx, appl, cb.ProjectID, "report the builds of the codebase"); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		attrs := ctx.Payload.Data.Attributes
+		b := codebase.Build{
+			CodebaseID: cb.ID,
+			Status:     attrs.Status,
+		}
+		if attrs.Revision != nil {
+			b.Revision = *attrs.Revision
+		}
+		if attrs.URL != nil {
+			b.URL = *attrs.URL
+		}
+		err = appl.Codebases().AddBuild(ctx, &b)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		res := &app.CodebaseSingle{
+			Data: ConvertCodebase(ctx.RequestData, cb, &b),
+		}
+		return ctx.OK(res)
+	})
+}
+
+// ConvertCodebases converts between internal and external REST representation.
+// The latest build of each codebase is looked up with the given function.
+func ConvertCodebases(request *goa.RequestData, codebases []*codebase.Codebase, latestBuild func(uuid.UUID) (*codebase.Build, error)) ([]*app.Codebase, error) {
+	var cs = []*app.Codebase{}
+	for _, cb := range codebases {
+		b, err := latestBuild(cb.ID)
+		if err != nil {
+			return nil, err
+		}
+		cs = append(cs, ConvertCodebase(request, cb, b))
+	}
+	return cs, nil
+}
+
+// ConvertCodebase converts between internal and external REST representation.
+// The build may be nil if no build was reported for the codebase yet.
+func ConvertCodebase(request *goa.RequestData, cb *codebase.Codebase, b *codebase.Build) *app.Codebase {
+	projectType := "projects"
+	projectID := cb.ProjectID.String()
+
+	selfURL := AbsoluteURL(request, app.CodebaseHref(cb.ID))
+	projectSelfURL := AbsoluteURL(request, app.ProjectHref(projectID))
+
+	c := &app.Codebase{
+		Type: "codebases",
+		ID:   &cb.ID,
+		Attributes: &app.CodebaseAttributes{
+			URL:       &cb.URL,
+			Branch:    &cb.Branch,
+			CreatedAt: &cb.CreatedAt,
+		},
+		Relationships: &app.CodebaseRelations{
+			Project: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: &projectType,
+					ID:   &projectID,
+				},
+				Links: &app.GenericLinks{
+					Self: &projectSelfURL,
+				},
+			},
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+	shipped := false
+	if b != nil {
+		shipped = b.Shipped()
+		c.Attributes.BuildStatus = &b.Status
+		c.Attributes.BuildRevision = &b.Revision
+		c.Attributes.BuildURL = &b.URL
+	}
+	c.Attributes.Shipped = &shipped
+	return c
+}
@@ -0,0 +1,233 @@
+package codebase
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/asaskevich/govalidator"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Build status values as reported by CI
+const (
+	BuildPending  = "pending"
+	BuildRunning  = "running"
+	BuildSuccess  = "success"
+	BuildFailure  = "failure"
+	BuildDeployed = "deployed"
+)
+
+// BuildStatuses contains all the known build status values
+var BuildStatuses = []string{BuildPending, BuildRunning, BuildSuccess, BuildFailure, BuildDeployed}
+
+// Codebase describes a source repository branch a project is built from
+type Codebase struct {
+	gormsupport.Lifecycle
+	ID        uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	ProjectID uuid.UUID `sql:"type:uuid"`
+	URL       string
+	Branch    string
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m Codebase) TableName() string {
+	return "codebases"
+}
+
+// WorkItemCodebase associates a work item with a codebase
+type WorkItemCodebase struct {
+	CreatedAt  time.Time
+	WorkItemID uint64    `gorm:"primary_key"`
+	CodebaseID uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m WorkItemCodebase) TableName() string {
+	return "work_item_codebases"
+}
+
+// Build describes the build and deploy status of a codebase revision
+type Build struct {
+	gormsupport.Lifecycle
+	ID         uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	CodebaseID uuid.UUID `sql:"type:uuid"`
+	Revision   string
+	Status     string
+	URL        string
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m Build) TableName() string {
+	return "codebase_builds"
+}
+
+// Shipped returns true if the build has been deployed
+func (m Build) Shipped() bool {
+	return m.Status == BuildDeployed
+}
+
+// Repository describes interactions with codebases
+type Repository interface {
+	Create(ctx context.Context, c *Codebase) error
+	Load(ctx context.Context, id uuid.UUID) (*Codebase, error)
+	List(ctx context.Context, projectID uuid.UUID) ([]*Codebase, error)
+	AddWorkItem(ctx context.Context, codebaseID uuid.UUID, workItemID uint64) error
+	ListByWorkItem(ctx context.Context, workItemID uint64) ([]*Codebase, error)
+	AddBuild(ctx context.Context, b *Build) error
+	LatestBuild(ctx context.Context, codebaseID uuid.UUID) (*Build, error)
+}
+
+// NewCodebaseRepository creates a new storage type.
+func NewCodebaseRepository(db *gorm.DB) Repository {
+	return &GormCodebaseRepository{db: db}
+}
+
+// GormCodebaseRepository is the implementation of the storage interface for codebases.
+type GormCodebaseRepository struct {
+	db *gorm.DB
+}
+
+// Create creates a new record.
+// returns BadParameterError or InternalError
+func (m *GormCodebaseRepository) Create(ctx context.Context, c *Codebase) error {
+	defer goa.MeasureSince([]string{"goa", "db", "codebase", "create"}, time.Now())
+
+	if !govalidator.IsURL(c.URL) {
+		return errors.NewBadParameterError("url", c.URL).Expected("valid URL")
+	}
+	if c.Branch == "" {
+		c.Branch = "master"
+	}
+	c.ID = uuid.NewV4()
+
+	err := m.db.Create(c).Error
+	if err != nil {
+		if gormsupport.IsUniqueViolation(err, "codebases_project_id_url_branch_idx") {
+			return errors.NewBadParameterError("url", c.URL).Expected("unique repository and branch within the project")
+		}
+		goa.LogError(ctx, "error adding Codebase", "error", err.Error())
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// Load a single codebase regardless of parent
+// returns NotFoundError or InternalError
+func (m *GormCodebaseRepository) Load(ctx context.Context, id uuid.UUID) (*Codebase, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "codebase", "get"}, time.Now())
+	var obj Codebase
+
+	tx := m.db.Where("id = ?", id).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("codebase", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	if !workitem.ContextViewer(ctx).CanReadProject(obj.ProjectID) {
+		return nil, errors.NewNotFoundError("codebase", id.String())
+	}
+	return &obj, nil
+}
+
+// List all codebases of a single project
+func (m *GormCodebaseRepository) List(ctx context.Context, projectID uuid.UUID) ([]*Codebase, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "codebase", "query"}, time.Now())
+	var objs []*Codebase
+
+	err := m.db.Where("project_id = ?", projectID).Order("created_at").Find(&objs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return objs, nil
+}
+
+// AddWorkItem associates the work item with the codebase. Associating the
+// same work item twice is not an error.
+// returns InternalError
+func (m *GormCodebaseRepository) AddWorkItem(ctx context.Context, codebaseID uuid.UUID, workItemID uint64) error {
+	defer goa.MeasureSince([]string{"goa", "db", "codebase", "addworkitem"}, time.Now())
+
+	var count int
+	err := m.db.Model(&WorkItemCodebase{}).Where("work_item_id = ? AND codebase_id = ?", workItemID, codebaseID).Count(&count).Error
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if count > 0 {
+		return nil
+	}
+	err = m.db.Create(&WorkItemCodebase{WorkItemID: workItemID, CodebaseID: codebaseID}).Error
+	if err != nil {
+		goa.LogError(ctx, "error adding WorkItemCodebase", "error", err.Error())
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// ListByWorkItem lists all codebases the work item is associated with
+func (m *GormCodebaseRepository) ListByWorkItem(ctx context.Context, workItemID uint64) ([]*Codebase, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "codebase", "querybyworkitem"}, time.Now())
+	var objs []*Codebase
+
+	err := m.db.Joins("JOIN work_item_codebases ON work_item_codebases.codebase_id = codebases.id").
+		Where("work_item_codebases.work_item_id = ?", workItemID).
+		Order("work_item_codebases.created_at").
+		Find(&objs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return objs, nil
+}
+
+// AddBuild records the status of a build of the codebase.
+// returns BadParameterError or InternalError
+func (m *GormCodebaseRepository) AddBuild(ctx context.Context, b *Build) error {
+	defer goa.MeasureSince([]string{"goa", "db", "codebase", "addbuild"}, time.Now())
+
+	if !isValidBuildStatus(b.Status) {
+		return errors.NewBadParameterError("status", b.Status).Expected(BuildStatuses)
+	}
+	b.ID = uuid.NewV4()
+
+	err := m.db.Create(b).Error
+	if err != nil {
+		goa.LogError(ctx, "error adding Build", "error", err.Error())
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// LatestBuild returns the most recently reported build of the codebase or
+// nil if no build was reported yet.
+// returns InternalError
+func (m *GormCodebaseRepository) LatestBuild(ctx context.Context, codebaseID uuid.UUID) (*Build, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "codebase", "latestbuild"}, time.Now())
+	var obj Build
+
+	tx := m.db.Where("codebase_id = ?", codebaseID).Order("created_at desc").First(&obj)
+	if tx.RecordNotFound() {
+		return nil, nil
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
+
+func isValidBuildStatus(status string) bool {
+	for _, s := range BuildStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
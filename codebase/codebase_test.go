@@ -0,0 +1,101 @@
+package codebase_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/codebase"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestCodebaseRepository struct {
+	gormsupport.DBTestSuite
+
+	clean      func()
+	projectID  uuid.UUID
+	workItemID uint64
+}
+
+func TestRunCodebaseRepository(t *testing.T) {
+	suite.Run(t, &TestCodebaseRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestCodebaseRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+
+	p, err := project.NewRepository(test.DB).Create(context.Background(), "codebase-test-"+uuid.NewV4().String())
+	require.Nil(test.T(), err)
+	test.projectID = p.ID
+
+	wi, err := workitem.NewWorkItemRepository(test.DB).Create(
+		context.Background(), workitem.SystemBug,
+		map[string]interface{}{
+			workitem.SystemTitle: "Title",
+			workitem.SystemState: workitem.SystemStateNew,
+		}, "xx")
+	require.Nil(test.T(), err)
+	test.workItemID, err = workitem.ParseWorkItemIDToUint64(wi.ID)
+	require.Nil(test.T(), err)
+}
+
+func (test *TestCodebaseRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestCodebaseRepository) TestCreateAndListCodebases() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := codebase.NewCodebaseRepository(test.DB)
+	c := codebase.Codebase{ProjectID: test.projectID, URL: "https://github.com/almighty/almighty-core"}
+	require.Nil(t, repo.Create(context.Background(), &c))
+	// missing branch falls back to master
+	assert.Equal(t, "master", c.Branch)
+
+	// the same repository branch can only be added once
+	dup := codebase.Codebase{ProjectID: test.projectID, URL: c.URL}
+	assert.IsType(t, errors.BadParameterError{}, repo.Create(context.Background(), &dup))
+
+	cs, err := repo.List(context.Background(), test.projectID)
+	require.Nil(t, err)
+	require.Len(t, cs, 1)
+	assert.Equal(t, c.ID, cs[0].ID)
+}
+
+func (test *TestCodebaseRepository) TestWorkItemBuildStatus() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := codebase.NewCodebaseRepository(test.DB)
+	c := codebase.Codebase{ProjectID: test.projectID, URL: "https://github.com/almighty/almighty-core", Branch: "devel"}
+	require.Nil(t, repo.Create(context.Background(), &c))
+
+	require.Nil(t, repo.AddWorkItem(context.Background(), c.ID, test.workItemID))
+	// associating twice is fine
+	require.Nil(t, repo.AddWorkItem(context.Background(), c.ID, test.workItemID))
+	cs, err := repo.ListByWorkItem(context.Background(), test.workItemID)
+	require.Nil(t, err)
+	require.Len(t, cs, 1)
+
+	b, err := repo.LatestBuild(context.Background(), c.ID)
+	require.Nil(t, err)
+	assert.Nil(t, b)
+
+	assert.IsType(t, errors.BadParameterError{}, repo.AddBuild(context.Background(), &codebase.Build{CodebaseID: c.ID, Status: "exploded"}))
+	require.Nil(t, repo.AddBuild(context.Background(), &codebase.Build{CodebaseID: c.ID, Revision: "abc", Status: codebase.BuildSuccess}))
+	require.Nil(t, repo.AddBuild(context.Background(), &codebase.Build{CodebaseID: c.ID, Revision: "abc", Status: codebase.BuildDeployed}))
+
+	b, err = repo.LatestBuild(context.Background(), c.ID)
+	require.Nil(t, err)
+	require.NotNil(t, b)
+	assert.True(t, b.Shipped())
+}
@@ -0,0 +1,120 @@
+package codechange
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Kinds of code changes
+const (
+	KindCommit      = "commit"
+	KindPullRequest = "pull-request"
+)
+
+// Providers that can report code changes
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+// Status of a code change
+const (
+	StatusOpen   = "open"
+	StatusMerged = "merged"
+	StatusClosed = "closed"
+)
+
+// CodeChange associates a commit or a pull request with a work item
+type CodeChange struct {
+	gormsupport.Lifecycle
+	ID         uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	WorkItemID uint64
+	Kind       string
+	Provider   string
+	// Repository is the web URL of the repository the change was made in
+	Repository string
+	// Ref is the commit SHA or the pull request number
+	Ref    string
+	Title  string
+	URL    string
+	Status string
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m CodeChange) TableName() string {
+	return "code_changes"
+}
+
+// Repository describes interactions with code changes
+type Repository interface {
+	Associate(ctx context.Context, c *CodeChange) error
+	List(ctx context.Context, workItemID uint64) ([]*CodeChange, error)
+}
+
+// NewCodeChangeRepository creates a new storage type.
+func NewCodeChangeRepository(db *gorm.DB) Repository {
+	return &GormCodeChangeRepository{db: db}
+}
+
+// GormCodeChangeRepository is the implementation of the storage interface for code changes.
+type GormCodeChangeRepository struct {
+	db *gorm.DB
+}
+
+// Associate records the given code change for its work item. If the change
+// was already associated with the work item (same URL), its title and status
+// are updated instead.
+// returns BadParameterError or InternalError
+func (m *GormCodeChangeRepository) Associate(ctx context.Context, c *CodeChange) error {
+	defer goa.MeasureSince([]string{"goa", "db", "codechange", "associate"}, time.Now())
+
+	if c.WorkItemID == 0 {
+		return errors.NewBadParameterError("work_item_id", c.WorkItemID)
+	}
+	if c.URL == "" {
+		return errors.NewBadParameterError("url", c.URL).Expected("not empty")
+	}
+
+	var existing CodeChange
+	tx := m.db.Where("work_item_id = ? AND url = ?", c.WorkItemID, c.URL).First(&existing)
+	if tx.Error != nil && !tx.RecordNotFound() {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RecordNotFound() {
+		c.ID = uuid.NewV4()
+		if err := m.db.Create(c).Error; err != nil {
+			goa.LogError(ctx, "error adding CodeChange", "error", err.Error())
+			return errors.NewInternalError(err.Error())
+		}
+		return nil
+	}
+
+	existing.Title = c.Title
+	existing.Status = c.Status
+	if err := m.db.Save(&existing).Error; err != nil {
+		goa.LogError(ctx, "error updating CodeChange", "error", err.Error())
+		return errors.NewInternalError(err.Error())
+	}
+	*c = existing
+	return nil
+}
+
+// List all code changes associated with a single work item
+func (m *GormCodeChangeRepository) List(ctx context.Context, workItemID uint64) ([]*CodeChange, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "codechange", "query"}, time.Now())
+	var objs []*CodeChange
+
+	err := m.db.Where("work_item_id = ?", workItemID).Order("created_at").Find(&objs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return objs, nil
+}
@@ -0,0 +1,72 @@
+package codechange_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/codechange"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestCodeChangeRepository struct {
+	gormsupport.DBTestSuite
+
+	clean      func()
+	workItemID uint64
+}
+
+func TestRunCodeChangeRepository(t *testing.T) {
+	suite.Run(t, &TestCodeChangeRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestCodeChangeRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+
+	wi, err := workitem.NewWorkItemRepository(test.DB).Create(
+		context.Background(), workitem.SystemBug,
+		map[string]interface{}{
+			workitem.SystemTitle: "Title",
+			workitem.SystemState: workitem.SystemStateNew,
+		}, "xx")
+	require.Nil(test.T(), err)
+	test.workItemID, err = workitem.ParseWorkItemIDToUint64(wi.ID)
+	require.Nil(test.T(), err)
+}
+
+func (test *TestCodeChangeRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestCodeChangeRepository) TestAssociateUpdatesStatus() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := codechange.NewCodeChangeRepository(test.DB)
+	c := codechange.CodeChange{
+		WorkItemID: test.workItemID,
+		Kind:       codechange.KindPullRequest,
+		Provider:   codechange.ProviderGitHub,
+		Ref:        "5",
+		Title:      "Login fix",
+		URL:        "https://github.com/almighty/almighty-core/pull/5",
+		Status:     codechange.StatusOpen,
+	}
+	require.Nil(t, repo.Associate(context.Background(), &c))
+
+	// the same pull request reported again only updates the existing association
+	merged := c
+	merged.Status = codechange.StatusMerged
+	require.Nil(t, repo.Associate(context.Background(), &merged))
+	assert.Equal(t, c.ID, merged.ID)
+
+	changes, err := repo.List(context.Background(), test.workItemID)
+	require.Nil(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, codechange.StatusMerged, changes[0].Status)
+}
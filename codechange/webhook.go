@@ -0,0 +1,219 @@
+package codechange
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/almighty/almighty-core/errors"
+)
+
+// Change is a code change reported by a webhook together with the text
+// that can reference work items
+type Change struct {
+	CodeChange
+	// Message is the commit message or the pull request description
+	Message string
+}
+
+// References returns the IDs of the work items the change refers to
+func (c Change) References() []string {
+	return ParseWorkItemReferences(c.Title + "\n" + c.Message)
+}
+
+var referencePattern = regexp.MustCompile(`(?:^|[^\w/&])#(\d+|[A-Z][A-Z0-9]{1,9}-[1-9]\d*)\b`)
+
+// ParseWorkItemReferences returns the distinct work item IDs and keys
+// referenced as "#<id>" or "#<key>" in the given text, in order of appearance.
+func ParseWorkItemReferences(text string) []string {
+	var ids []string
+	seen := map[string]bool{}
+	for _, m := range referencePattern.FindAllStringSubmatch(text, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			ids = append(ids, m[1])
+		}
+	}
+	return ids
+}
+
+// VerifyGitHubSignature checks the X-Hub-Signature header sent by GitHub
+// ("sha1=<hex hmac of the body>") against the configured secret
+func VerifyGitHubSignature(secret, signature string, body []byte) bool {
+	if !strings.HasPrefix(signature, "sha1=") {
+		return false
+	}
+	actual, err := hex.DecodeString(strings.TrimPrefix(signature, "sha1="))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(actual, mac.Sum(nil))
+}
+
+type gitHubPush struct {
+	Ref        string `json:"ref"`
+	Repository struct {
+		HTMLURL       string `json:"html_url"`
+		DefaultBranch string `json:"default_branch"`
+	} `json:"repository"`
+	Commits []struct {
+		ID      string `json:"id"`
+		Message string `json:"message"`
+		URL     string `json:"url"`
+	} `json:"commits"`
+}
+
+type gitHubPullRequest struct {
+	Number      int `json:"number"`
+	PullRequest struct {
+		HTMLURL string `json:"html_url"`
+		Title   string `json:"title"`
+		Body    string `json:"body"`
+		State   string `json:"state"`
+		Merged  bool   `json:"merged"`
+	} `json:"pull_request"`
+	Repository struct {
+		HTMLURL string `json:"html_url"`
+	} `json:"repository"`
+}
+
+// ParseGitHubEvent extracts the code changes from a GitHub webhook payload.
+// Only "push" and "pull_request" events carry code changes; other events
+// (e.g. "ping") yield no changes.
+func ParseGitHubEvent(event string, body []byte) ([]Change, error) {
+	switch event {
+	case "push":
+		var p gitHubPush
+		if err := json.Unmarshal(body, &p); err != nil {
+			return nil, errors.NewBadParameterError("payload", err.Error())
+		}
+		status := StatusOpen
+		if p.Ref == "refs/heads/"+p.Repository.DefaultBranch {
+			status = StatusMerged
+		}
+		var changes []Change
+		for _, c := range p.Commits {
+			changes = append(changes, newCommit(ProviderGitHub, p.Repository.HTMLURL, c.ID, c.URL, c.Message, status))
+		}
+		return changes, nil
+	case "pull_request":
+		var p gitHubPullRequest
+		if err := json.Unmarshal(body, &p); err != nil {
+			return nil, errors.NewBadParameterError("payload", err.Error())
+		}
+		status := StatusOpen
+		if p.PullRequest.Merged {
+			status = StatusMerged
+		} else if p.PullRequest.State == "closed" {
+			status = StatusClosed
+		}
+		return []Change{{
+			CodeChange: CodeChange{
+				Kind:       KindPullRequest,
+				Provider:   ProviderGitHub,
+				Repository: p.Repository.HTMLURL,
+				Ref:        strconv.Itoa(p.Number),
+				Title:      p.PullRequest.Title,
+				URL:        p.PullRequest.HTMLURL,
+				Status:     status,
+			},
+			Message: p.PullRequest.Body,
+		}}, nil
+	}
+	return nil, nil
+}
+
+type gitLabPush struct {
+	Ref     string `json:"ref"`
+	Project struct {
+		WebURL        string `json:"web_url"`
+		DefaultBranch string `json:"default_branch"`
+	} `json:"project"`
+	Commits []struct {
+		ID      string `json:"id"`
+		Message string `json:"message"`
+		URL     string `json:"url"`
+	} `json:"commits"`
+}
+
+type gitLabMergeRequest struct {
+	Project struct {
+		WebURL string `json:"web_url"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		IID         int    `json:"iid"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		URL         string `json:"url"`
+		State       string `json:"state"`
+	} `json:"object_attributes"`
+}
+
+// ParseGitLabEvent extracts the code changes from a GitLab webhook payload.
+// Only "Push Hook" and "Merge Request Hook" events carry code changes.
+func ParseGitLabEvent(event string, body []byte) ([]Change, error) {
+	switch event {
+	case "Push Hook":
+		var p gitLabPush
+		if err := json.Unmarshal(body, &p); err != nil {
+			return nil, errors.NewBadParameterError("payload", err.Error())
+		}
+		status := StatusOpen
+		if p.Ref == "refs/heads/"+p.Project.DefaultBranch {
+			status = StatusMerged
+		}
+		var changes []Change
+		for _, c := range p.Commits {
+			changes = append(changes, newCommit(ProviderGitLab, p.Project.WebURL, c.ID, c.URL, c.Message, status))
+		}
+		return changes, nil
+	case "Merge Request Hook":
+		var p gitLabMergeRequest
+		if err := json.Unmarshal(body, &p); err != nil {
+			return nil, errors.NewBadParameterError("payload", err.Error())
+		}
+		status := StatusOpen
+		switch p.ObjectAttributes.State {
+		case "merged":
+			status = StatusMerged
+		case "closed":
+			status = StatusClosed
+		}
+		return []Change{{
+			CodeChange: CodeChange{
+				Kind:       KindPullRequest,
+				Provider:   ProviderGitLab,
+				Repository: p.Project.WebURL,
+				Ref:        strconv.Itoa(p.ObjectAttributes.IID),
+				Title:      p.ObjectAttributes.Title,
+				URL:        p.ObjectAttributes.URL,
+				Status:     status,
+			},
+			Message: p.ObjectAttributes.Description,
+		}}, nil
+	}
+	return nil, nil
+}
+
+func newCommit(provider, repository, sha, url, message, status string) Change {
+	// the first line of a commit message is its title
+	title := strings.SplitN(message, "\n", 2)[0]
+	return Change{
+		CodeChange: CodeChange{
+			Kind:       KindCommit,
+			Provider:   provider,
+			Repository: repository,
+			Ref:        sha,
+			Title:      title,
+			URL:        url,
+			Status:     status,
+		},
+		Message: message,
+	}
+}
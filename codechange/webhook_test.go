@@ -0,0 +1,99 @@
+package codechange_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"testing"
+
+	"github.com/almighty/almighty-core/codechange"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWorkItemReferences(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	assert.Equal(t, []string{"12", "7"}, codechange.ParseWorkItemReferences("Fixes #12 and #7, see also #12"))
+	assert.Equal(t, []string{"3"}, codechange.ParseWorkItemReferences("#3 at the start"))
+	assert.Equal(t, []string{"ALM-482", "12"}, codechange.ParseWorkItemReferences("Fixes #ALM-482 and #12, not #ALM- or #alm-3"))
+	// URL fragments and HTML entities are not references
+	assert.Empty(t, codechange.ParseWorkItemReferences("see http://example.com/page#12 and &#39;"))
+	assert.Empty(t, codechange.ParseWorkItemReferences("no references here"))
+}
+
+func TestVerifyGitHubSignature(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	body := []byte(`{"zen":"Keep it logically awesome."}`)
+	mac := hmac.New(sha1.New, []byte("secret"))
+	mac.Write(body)
+	signature := "sha1=" + hex.EncodeToString(mac.Sum(nil))
+
+	assert.True(t, codechange.VerifyGitHubSignature("secret", signature, body))
+	assert.False(t, codechange.VerifyGitHubSignature("other", signature, body))
+	assert.False(t, codechange.VerifyGitHubSignature("secret", "md5=abc", body))
+}
+
+func TestParseGitHubPushEvent(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	body := []byte(`{
+		"ref": "refs/heads/master",
+		"repository": {"html_url": "https://github.com/almighty/almighty-core", "default_branch": "master"},
+		"commits": [{"id": "abc123", "message": "Fix login #42\n\nlonger description", "url": "https://github.com/almighty/almighty-core/commit/abc123"}]
+	}`)
+	changes, err := codechange.ParseGitHubEvent("push", body)
+	require.Nil(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, codechange.KindCommit, changes[0].Kind)
+	assert.Equal(t, codechange.StatusMerged, changes[0].Status)
+	assert.Equal(t, "Fix login #42", changes[0].Title)
+	assert.Equal(t, []string{"42"}, changes[0].References())
+}
+
+func TestParseGitHubPullRequestEvent(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	body := []byte(`{
+		"number": 5,
+		"pull_request": {"html_url": "https://github.com/almighty/almighty-core/pull/5", "title": "Login fix", "body": "Closes #42", "state": "closed", "merged": true},
+		"repository": {"html_url": "https://github.com/almighty/almighty-core"}
+	}`)
+	changes, err := codechange.ParseGitHubEvent("pull_request", body)
+	require.Nil(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, codechange.KindPullRequest, changes[0].Kind)
+	assert.Equal(t, codechange.StatusMerged, changes[0].Status)
+	assert.Equal(t, "5", changes[0].Ref)
+	assert.Equal(t, []string{"42"}, changes[0].References())
+
+	changes, err = codechange.ParseGitHubEvent("ping", []byte(`{}`))
+	require.Nil(t, err)
+	assert.Empty(t, changes)
+
+	_, err = codechange.ParseGitHubEvent("push", []byte(`not json`))
+	assert.NotNil(t, err)
+}
+
+func TestParseGitLabMergeRequestEvent(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	body := []byte(`{
+		"object_kind": "merge_request",
+		"project": {"web_url": "https://gitlab.com/almighty/almighty-core"},
+		"object_attributes": {"iid": 9, "title": "Work on #1", "description": "", "url": "https://gitlab.com/almighty/almighty-core/merge_requests/9", "state": "opened"}
+	}`)
+	changes, err := codechange.ParseGitLabEvent("Merge Request Hook", body)
+	require.Nil(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, codechange.ProviderGitLab, changes[0].Provider)
+	assert.Equal(t, codechange.StatusOpen, changes[0].Status)
+	assert.Equal(t, []string{"1"}, changes[0].References())
+}
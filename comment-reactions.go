@@ -0,0 +1,150 @@
+package main
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/reaction"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// CommentReactionsController implements the comment-reactions resource.
+type CommentReactionsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewCommentReactionsController creates a comment-reactions controller.
+func NewCommentReactionsController(service *goa.Service, db application.DB) *CommentReactionsController {
+	return &CommentReactionsController{Controller: service.NewController("CommentReactionsController"), db: db}
+}
+
+// Create runs the create action.
+func (c *CommentReactionsController) Create(ctx *app.CreateCommentReactionsContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	id, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		cm, err := loadVisibleComment(ctx, appl, id)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		err = appl.Reactions().Create(ctx, id, *identityID, ctx.Payload.Data.Attributes.Emoji)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res, err := convertCommentWithReactions(ctx, appl, ctx.RequestData, cm)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Delete runs the delete action.
+func (c *CommentReactionsController) Delete(ctx *app.DeleteCommentReactionsContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	id, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		cm, err := loadVisibleComment(ctx, appl, id)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		err = appl.Reactions().Delete(ctx, id, *identityID, ctx.Emoji)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res, err := convertCommentWithReactions(ctx, appl, ctx.RequestData, cm)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// loadVisibleComment returns the comment with the given ID if the viewer of
+// ctx can see it: comments are only visible together with their work item,
+// comments pending review only to their creator and the project admins
+// returns NotFoundError
+func loadVisibleComment(ctx context.Context, appl application.Application, id uuid.UUID) (*comment.Comment, error) {
+	cm, err := appl.Comments().Load(ctx, id)
+	if err != nil {
+		return nil, errors.NewNotFoundError("comment", id.String())
+	}
+	wi, err := appl.WorkItems().Load(ctx, cm.ParentID)
+	if err != nil || len(visibleComments(ctx, wi, []*comment.Comment{cm})) == 0 {
+		return nil, errors.NewNotFoundError("comment", id.String())
+	}
+	return cm, nil
+}
+
+func convertCommentWithReactions(ctx context.Context, appl application.Application, request *goa.RequestData, cm *comment.Comment) (*app.CommentSingle, error) {
+	reactions, err := loadReactions(ctx, appl, []*comment.Comment{cm})
+	if err != nil {
+		return nil, err
+	}
+	return &app.CommentSingle{
+		Data: ConvertComment(request, cm, CommentIncludeParentWorkItem(), reactions),
+	}, nil
+}
+
+// loadReactions loads the reactions to the given comments and returns a
+// CommentConvertFunc that includes them, flagging the current user's ones
+func loadReactions(ctx context.Context, appl application.Application, comments []*comment.Comment) (CommentConvertFunc, error) {
+	ids := make([]uuid.UUID, 0, len(comments))
+	for _, cm := range comments {
+		ids = append(ids, cm.ID)
+	}
+	summaries, err := appl.Reactions().Summarize(ctx, ids, currentIdentityID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return CommentIncludeReactions(summaries), nil
+}
+
+// CommentIncludeReactions adds the aggregated reactions to the comment
+func CommentIncludeReactions(summaries map[uuid.UUID][]reaction.Summary) CommentConvertFunc {
+	return func(request *goa.RequestData, cm *comment.Comment, data *app.Comment) {
+		reactions := []*app.ReactionSummary{}
+		for _, s := range summaries[cm.ID] {
+			reactions = append(reactions, &app.ReactionSummary{
+				Emoji:   s.Emoji,
+				Count:   s.Count,
+				Reacted: s.Reacted,
+			})
+		}
+		data.Attributes.Reactions = reactions
+	}
+}
+
+// currentIdentityID returns the ID of the current user or nil for anonymous requests
+func currentIdentityID(ctx context.Context) *uuid.UUID {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return nil
+	}
+	id, err := uuid.FromString(currentUser)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
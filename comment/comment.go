@@ -19,6 +19,8 @@ type Comment struct {
 	ParentID  string
 	CreatedBy uuid.UUID `sql:"type:uuid"` // Belongs To Identity
 	Body      string
+	// PendingReview is set for comments of first-time contributors to public projects
+	PendingReview bool
 }
 
 // Repository describes interactions with comments
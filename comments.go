@@ -30,18 +30,24 @@ func (c *CommentsController) Show(ctx *app.ShowCommentsContext) error {
 		return ctx.BadRequest(jerrors)
 	}
 
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 		c, err := appl.Comments().Load(ctx, id)
 		if err != nil {
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
 			return ctx.NotFound(jerrors)
 		}
+		// comments are only visible together with their work item
+		wi, err := appl.WorkItems().Load(ctx, c.ParentID)
+		if err != nil || len(visibleComments(ctx, wi, []*comment.Comment{c})) == 0 {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrNotFound(fmt.Sprintf("comment %s not found", ctx.ID)))
+			return ctx.NotFound(jerrors)
+		}
 
-		res := &app.CommentSingle{}
-		res.Data = ConvertComment(
-			ctx.RequestData,
-			c,
-			CommentIncludeParentWorkItem())
+		res, err := convertCommentWithReactions(ctx, appl, ctx.RequestData, c)
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.InternalServerError(jerrors)
+		}
 
 		return ctx.OK(res)
 	})
@@ -88,8 +94,9 @@ func ConvertComment(request *goa.RequestData, comment *comment.Comment, addition
 		Type: "comments",
 		ID:   &comment.ID,
 		Attributes: &app.CommentAttributes{
-			Body:      &comment.Body,
-			CreatedAt: &comment.CreatedAt,
+			Body:          &comment.Body,
+			CreatedAt:     &comment.CreatedAt,
+			PendingReview: &comment.PendingReview,
 		},
 		Relationships: &app.CommentRelations{
 			CreatedBy: &app.CommentCreatedBy{
@@ -26,6 +26,7 @@ func String() string {
 // variables.
 func Setup(configFilePath string) error {
 	viper.Reset()
+	resetTunables(configFilePath)
 
 	// Expect environment variables to be prefix with "ALMIGHTY_".
 	viper.SetEnvPrefix("ALMIGHTY")
@@ -69,12 +70,88 @@ const (
 	varPostgresConnectionRetrySleep = "postgres.connection.retrysleep"
 	varPopulateCommonTypes          = "populate.commontypes"
 	varHTTPAddress                  = "http.address"
+	varHTTPReadTimeout              = "http.timeout.read"
+	varHTTPReadHeaderTimeout        = "http.timeout.readheader"
+	varHTTPWriteTimeout             = "http.timeout.write"
+	varHTTPIdleTimeout              = "http.timeout.idle"
+	varHTTPMaxHeaderBytes           = "http.maxheaderbytes"
+	varHTTP2Enabled                 = "http.http2.enabled"
+	varHTTP2MaxConcurrentStreams    = "http.http2.maxconcurrentstreams"
+	varHTTPTLSCert                  = "http.tls.cert"
+	varHTTPTLSKey                   = "http.tls.key"
 	varDeveloperModeEnabled         = "developer.mode.enabled"
 	varGithubSecret                 = "github.secret"
 	varGithubClientID               = "github.client.id"
 	varGithubAuthToken              = "github.auth.token"
+	varGithubWebhookSecret          = "github.webhook.secret"
+	varGitlabWebhookToken           = "gitlab.webhook.token"
 	varTokenPublicKey               = "token.publickey"
 	varTokenPrivateKey              = "token.privatekey"
+	varEncryptionKeys               = "encryption.keys"
+	varEncryptionPrimaryKey         = "encryption.primarykey"
+	varModerationNewUserPeriod      = "moderation.newuser.period"
+	varModerationNewUserRateLimit   = "moderation.newuser.ratelimit"
+	varModerationBlockedWords       = "moderation.blockedwords"
+	varChallengeRecaptchaSecret     = "challenge.recaptcha.secret"
+	varChallengeRecaptchaURL        = "challenge.recaptcha.url"
+	varChallengeHCaptchaSecret      = "challenge.hcaptcha.secret"
+	varChallengeHCaptchaURL         = "challenge.hcaptcha.url"
+	varPageSizeDefault              = "paging.size.default"
+	varPageSizeMax                  = "paging.size.max"
+	varAdminIdentities              = "admin.identities"
+	varJobsWorkers                  = "jobs.workers"
+	varJobsPoll                     = "jobs.poll"
+	varJobsLockTimeout              = "jobs.locktimeout"
+	varStaleSchedule                = "stale.schedule"
+	varEscalationSchedule           = "escalation.schedule"
+	varRollupPointsField            = "rollup.points"
+	varReparentMaxWorkItems         = "reparent.maxworkitems"
+	varFlowSchedule                 = "flow.schedule"
+	varTrashRetention               = "trash.retention"
+	varTrashSchedule                = "trash.schedule"
+	varDraftMaxAge                  = "draft.maxage"
+	varDraftSchedule                = "draft.schedule"
+	varHistoryRetention             = "history.retention"
+	varHistorySchedule              = "history.schedule"
+	varRetentionSchedule            = "retention.schedule"
+	varChangeFeedPublisher          = "changefeed.publisher"
+	varChangeFeedBrokers            = "changefeed.brokers"
+	varChangeFeedTopicPrefix        = "changefeed.topicprefix"
+	varOutboxWebhooks               = "outbox.webhooks"
+	varOutboxWebhookSecret          = "outbox.webhook.secret"
+	varOutboxRetention              = "outbox.retention"
+	varOutboxMaxAttempts            = "outbox.maxattempts"
+	varSearchElasticURL             = "search.elastic.url"
+	varSearchElasticIndex           = "search.elastic.index"
+	varBackupLocation               = "backup.location"
+	varBackupToken                  = "backup.token"
+	varMigrationOnMismatch          = "migration.onmismatch"
+	varMaintenanceEnabled           = "maintenance.enabled"
+	varMaintenanceMessage           = "maintenance.message"
+	varShareSecret                  = "share.secret"
+	varShareMaxDays                 = "share.maxdays"
+	varScanClamd                    = "scan.clamd"
+	varScanTimeout                  = "scan.timeout"
+	varAttachmentMaxSize            = "attachment.maxsize"
+	varAttachmentQuota              = "attachment.quota"
+	varRequestMaxSizeAPI            = "request.maxsize.api"
+	varRequestMaxSizeUpload         = "request.maxsize.upload"
+	varRequestMaxSizeWebhook        = "request.maxsize.webhook"
+	varLoadMaxInFlightAPI           = "load.maxinflight.api"
+	varLoadMaxInFlightUpload        = "load.maxinflight.upload"
+	varLoadMaxInFlightWebhook       = "load.maxinflight.webhook"
+	varLoadQueueSize                = "load.queue.size"
+	varLoadQueueTimeout             = "load.queue.timeout"
+	varLoadRetryAfter               = "load.retryafter"
+	varBreakerThreshold             = "breaker.threshold"
+	varBreakerCooldown              = "breaker.cooldown"
+	varPushFCMKey                   = "push.fcm.key"
+	varPushAPNsKey                  = "push.apns.key"
+	varPushAPNsKeyID                = "push.apns.keyid"
+	varPushAPNsTeamID               = "push.apns.teamid"
+	varPushAPNsTopic                = "push.apns.topic"
+	varPushAPNsSandbox              = "push.apns.sandbox"
+	varImpersonationTTL             = "impersonation.ttl"
 )
 
 func setConfigDefaults() {
@@ -97,6 +174,22 @@ func setConfigDefaults() {
 	// HTTP
 	//-----
 	viper.SetDefault(varHTTPAddress, "0.0.0.0:8080")
+	// Slow clients are disconnected: the headers must arrive within the
+	// header timeout and the whole request within the read timeout, the
+	// response must be written within the write timeout (exports included)
+	// and idle keep-alive connections are closed after the idle timeout; 0
+	// disables a timeout
+	viper.SetDefault(varHTTPReadTimeout, time.Duration(time.Minute))
+	viper.SetDefault(varHTTPReadHeaderTimeout, time.Duration(10*time.Second))
+	viper.SetDefault(varHTTPWriteTimeout, time.Duration(5*time.Minute))
+	viper.SetDefault(varHTTPIdleTimeout, time.Duration(2*time.Minute))
+	viper.SetDefault(varHTTPMaxHeaderBytes, 1024*1024)
+	// HTTP/2 is negotiated on TLS connections, which are served if a PEM
+	// encoded certificate and key are given
+	viper.SetDefault(varHTTP2Enabled, true)
+	viper.SetDefault(varHTTP2MaxConcurrentStreams, 250)
+	viper.SetDefault(varHTTPTLSCert, "")
+	viper.SetDefault(varHTTPTLSKey, "")
 
 	//-----
 	// Misc
@@ -113,6 +206,162 @@ func setConfigDefaults() {
 	viper.SetDefault(varGithubClientID, defaultGithubClientID)
 	viper.SetDefault(varGithubSecret, defaultGithubSecret)
 	viper.SetDefault(varGithubAuthToken, defaultActualToken)
+
+	// Webhook secrets, when empty the webhook payloads are refused
+	viper.SetDefault(varGithubWebhookSecret, "")
+	viper.SetDefault(varGitlabWebhookToken, "")
+
+	// Encryption keys, when empty sensitive fields can't be written
+	viper.SetDefault(varEncryptionKeys, "")
+	viper.SetDefault(varEncryptionPrimaryKey, "")
+
+	// Moderation of public projects: identities younger than the period can
+	// contribute at most ratelimit work items and comments per hour
+	viper.SetDefault(varModerationNewUserPeriod, time.Duration(24*time.Hour))
+	viper.SetDefault(varModerationNewUserRateLimit, 10)
+	viper.SetDefault(varModerationBlockedWords, "")
+
+	// Challenges anonymous contributors to public projects must solve, a
+	// project can only require a challenge whose secret is configured
+	viper.SetDefault(varChallengeRecaptchaSecret, "")
+	viper.SetDefault(varChallengeRecaptchaURL, "https://www.google.com/recaptcha/api/siteverify")
+	viper.SetDefault(varChallengeHCaptchaSecret, "")
+	viper.SetDefault(varChallengeHCaptchaURL, "https://hcaptcha.com/siteverify")
+
+	// Page sizes of list endpoints
+	viper.SetDefault(varPageSizeDefault, 20)
+	viper.SetDefault(varPageSizeMax, 100)
+
+	// Instance admins can change the runtime settings, see Reload
+	viper.SetDefault(varAdminIdentities, "")
+
+	// Background jobs: the number of workers, how often idle workers look for
+	// due jobs and after how long a running job is assumed to be abandoned
+	viper.SetDefault(varJobsWorkers, 4)
+	viper.SetDefault(varJobsPoll, time.Duration(time.Second))
+	viper.SetDefault(varJobsLockTimeout, time.Duration(10*time.Minute))
+
+	// Cron spec (with seconds) of the sweep applying the stale policies of the projects
+	viper.SetDefault(varStaleSchedule, "0 0 * * * *")
+
+	// Cron spec (with seconds) of the sweep applying the escalation policies of the projects
+	viper.SetDefault(varEscalationSchedule, "0 */10 * * * *")
+
+	// Numeric work item field whose values of the children are summed up on
+	// the parent work item
+	viper.SetDefault(varRollupPointsField, "storypoints")
+
+	// Number of work items that can be moved to a new parent or iteration at once
+	viper.SetDefault(varReparentMaxWorkItems, 500)
+
+	// Cron spec (with seconds) of the daily snapshot of the work item states
+	// per project, it should run shortly before midnight UTC
+	viper.SetDefault(varFlowSchedule, "0 55 23 * * *")
+
+	// Deleted work items are purged from the trash after the retention
+	// period by a job running on the given cron spec (with seconds)
+	viper.SetDefault(varTrashRetention, time.Duration(30*24*time.Hour))
+	viper.SetDefault(varTrashSchedule, "0 30 3 * * *")
+
+	// Drafts not changed for the given age are deleted by a job running on
+	// the given cron spec (with seconds), 0 keeps them
+	viper.SetDefault(varDraftMaxAge, time.Duration(30*24*time.Hour))
+	viper.SetDefault(varDraftSchedule, "0 15 3 * * *")
+
+	// The work item events are partitioned by month, a job running on the
+	// given cron spec (with seconds) creates the partitions of the coming
+	// months and prunes the events older than the retention (0 keeps them)
+	viper.SetDefault(varHistoryRetention, time.Duration(0))
+	viper.SetDefault(varHistorySchedule, "0 15 2 * * *")
+
+	// Cron spec (with seconds) of the job enforcing the retention policies
+	// of the projects
+	viper.SetDefault(varRetentionSchedule, "0 45 2 * * *")
+
+	// Change feed: the message bus ("kafka" or "nats", disabled if empty)
+	// changes are published to
+	viper.SetDefault(varChangeFeedPublisher, "")
+	viper.SetDefault(varChangeFeedBrokers, "")
+	viper.SetDefault(varChangeFeedTopicPrefix, "almighty")
+
+	// Outbox: the webhooks records are posted to, the secret their requests
+	// are signed with, how long records are kept after delivery and how many
+	// times a sink fails a record before it is dead
+	viper.SetDefault(varOutboxWebhooks, "")
+	viper.SetDefault(varOutboxWebhookSecret, "")
+	viper.SetDefault(varOutboxRetention, time.Duration(7*24*time.Hour))
+	viper.SetDefault(varOutboxMaxAttempts, 10)
+
+	// Elasticsearch server and index work items are searched in, searches
+	// run in Postgres if no server is set
+	viper.SetDefault(varSearchElasticURL, "")
+	viper.SetDefault(varSearchElasticIndex, "workitems")
+
+	// Object store the backups are kept in (a file:// or http(s):// URL,
+	// backups are disabled if empty) and the bearer token of its requests
+	viper.SetDefault(varBackupLocation, "")
+	viper.SetDefault(varBackupToken, "")
+
+	// What to do when the database was migrated to a schema this code can't
+	// run on: "refuse" to start or serve it in "readonly" mode
+	viper.SetDefault(varMigrationOnMismatch, "refuse")
+
+	// Maintenance: whether the API is read-only and the message its
+	// rejected requests get, can be switched at runtime
+	viper.SetDefault(varMaintenanceEnabled, false)
+	viper.SetDefault(varMaintenanceMessage, "The service is read-only for maintenance, try again later.")
+
+	// Share links: the secret their tokens are signed with (share links are
+	// disabled if empty) and how many days they can be valid at most
+	viper.SetDefault(varShareSecret, "")
+	viper.SetDefault(varShareMaxDays, 90)
+
+	// Uploads are scanned for malware by the ClamAV daemon at the given
+	// address (host:port or the path of its socket), empty disables scanning
+	viper.SetDefault(varScanClamd, "")
+	viper.SetDefault(varScanTimeout, time.Duration(30*time.Second))
+
+	// Larger uploads to projects are refused (in bytes)
+	viper.SetDefault(varAttachmentMaxSize, 10*1024*1024)
+	// Uploads exceeding the storage quota of a project are refused (in bytes),
+	// 0 disables the quota
+	viper.SetDefault(varAttachmentQuota, 1024*1024*1024)
+	// Larger request bodies are refused (in bytes) by endpoint class: uploads
+	// of attachments leave room for base64 and multipart overhead, webhooks
+	// for the largest payloads GitHub sends, everything else is JSON; 0
+	// disables the limit of a class
+	viper.SetDefault(varRequestMaxSizeAPI, 1024*1024)
+	viper.SetDefault(varRequestMaxSizeUpload, 16*1024*1024)
+	viper.SetDefault(varRequestMaxSizeWebhook, 25*1024*1024)
+	// At most the given number of requests are served at once by endpoint
+	// class (0 disables the cap of a class), up to the queue size more wait
+	// for their turn until the queue timeout; the others are refused with
+	// Service Unavailable and told to retry after the given time
+	viper.SetDefault(varLoadMaxInFlightAPI, 100)
+	viper.SetDefault(varLoadMaxInFlightUpload, 10)
+	viper.SetDefault(varLoadMaxInFlightWebhook, 20)
+	viper.SetDefault(varLoadQueueSize, 100)
+	viper.SetDefault(varLoadQueueTimeout, time.Duration(time.Second))
+	viper.SetDefault(varLoadRetryAfter, time.Duration(5*time.Second))
+	// External dependencies failing the given number of times in a row aren't
+	// called for the cooldown
+	viper.SetDefault(varBreakerThreshold, 5)
+	viper.SetDefault(varBreakerCooldown, time.Duration(30*time.Second))
+
+	// Notifications are pushed to Android devices through FCM with the server
+	// key of the Firebase project and to iOS devices through APNs with the
+	// PEM encoded key of the Apple developer team, a platform is disabled if
+	// its key is empty
+	viper.SetDefault(varPushFCMKey, "")
+	viper.SetDefault(varPushAPNsKey, "")
+	viper.SetDefault(varPushAPNsKeyID, "")
+	viper.SetDefault(varPushAPNsTeamID, "")
+	viper.SetDefault(varPushAPNsTopic, "io.almighty.app")
+	viper.SetDefault(varPushAPNsSandbox, false)
+
+	// Tokens instance admins are issued to act as another identity expire
+	// after the given time
+	viper.SetDefault(varImpersonationTTL, time.Duration(30*time.Minute))
 }
 
 // GetPostgresHost returns the postgres host as set via default, config file, or environment variable
@@ -181,6 +430,60 @@ func GetHTTPAddress() string {
 	return viper.GetString(varHTTPAddress)
 }
 
+// GetHTTPReadTimeout returns how long reading a request may take (as set via default, config file,
+// or environment variable), 0 if there is no limit.
+func GetHTTPReadTimeout() time.Duration {
+	return viper.GetDuration(varHTTPReadTimeout)
+}
+
+// GetHTTPReadHeaderTimeout returns how long reading the headers of a request may take (as set via
+// default, config file, or environment variable), 0 if there is no limit.
+func GetHTTPReadHeaderTimeout() time.Duration {
+	return viper.GetDuration(varHTTPReadHeaderTimeout)
+}
+
+// GetHTTPWriteTimeout returns how long writing a response may take (as set via default, config file,
+// or environment variable), 0 if there is no limit.
+func GetHTTPWriteTimeout() time.Duration {
+	return viper.GetDuration(varHTTPWriteTimeout)
+}
+
+// GetHTTPIdleTimeout returns how long a keep-alive connection may wait for the next request (as set
+// via default, config file, or environment variable), 0 if there is no limit.
+func GetHTTPIdleTimeout() time.Duration {
+	return viper.GetDuration(varHTTPIdleTimeout)
+}
+
+// GetHTTPMaxHeaderBytes returns the maximum size in bytes of the headers of a request (as set via
+// default, config file, or environment variable).
+func GetHTTPMaxHeaderBytes() int {
+	return viper.GetInt(varHTTPMaxHeaderBytes)
+}
+
+// IsHTTP2Enabled returns true if HTTP/2 is negotiated on TLS connections (as set via default, config
+// file, or environment variable).
+func IsHTTP2Enabled() bool {
+	return viper.GetBool(varHTTP2Enabled)
+}
+
+// GetHTTP2MaxConcurrentStreams returns how many requests an HTTP/2 connection may have in flight
+// (as set via default, config file, or environment variable).
+func GetHTTP2MaxConcurrentStreams() int {
+	return viper.GetInt(varHTTP2MaxConcurrentStreams)
+}
+
+// GetHTTPTLSCert returns the path of the PEM encoded certificate (as set via config file or
+// environment variable) the server uses for TLS, empty if it serves plain HTTP.
+func GetHTTPTLSCert() string {
+	return viper.GetString(varHTTPTLSCert)
+}
+
+// GetHTTPTLSKey returns the path of the PEM encoded private key of the TLS certificate (as set via
+// config file or environment variable).
+func GetHTTPTLSKey() string {
+	return viper.GetString(varHTTPTLSKey)
+}
+
 // IsPostgresDeveloperModeEnabled returns if development related features (as set via default, config file, or environment variable),
 // e.g. token generation endpoint are enabled
 func IsPostgresDeveloperModeEnabled() bool {
@@ -216,6 +519,54 @@ func GetGithubAuthToken() string {
 	return viper.GetString(varGithubAuthToken)
 }
 
+// GetGithubWebhookSecret returns the secret (as set via config file or environment variable)
+// that is used to verify the signature of GitHub webhook payloads.
+func GetGithubWebhookSecret() string {
+	return viper.GetString(varGithubWebhookSecret)
+}
+
+// GetGitlabWebhookToken returns the token (as set via config file or environment variable)
+// that GitLab sends along with its webhook payloads.
+func GetGitlabWebhookToken() string {
+	return viper.GetString(varGitlabWebhookToken)
+}
+
+// GetEncryptionKeys returns the keys (as set via config file or environment variable)
+// that sensitive values are encrypted with in the form "id1:base64key1,id2:base64key2".
+func GetEncryptionKeys() string {
+	return viper.GetString(varEncryptionKeys)
+}
+
+// GetEncryptionPrimaryKey returns the ID of the key (as set via config file or environment variable)
+// that new values are encrypted with. Values encrypted with the other keys can still be decrypted.
+func GetEncryptionPrimaryKey() string {
+	return viper.GetString(varEncryptionPrimaryKey)
+}
+
+// GetModerationNewUserPeriod returns for how long (as set via default, config file, environment variable or runtime setting)
+// identities are considered new users whose contributions to public projects are rate limited.
+func GetModerationNewUserPeriod() time.Duration {
+	return tunableDuration(varModerationNewUserPeriod)
+}
+
+// GetModerationNewUserRateLimit returns the number of work items and comments (as set via default, config file,
+// environment variable or runtime setting) new users can contribute to public projects per hour.
+func GetModerationNewUserRateLimit() int {
+	return tunableInt(varModerationNewUserRateLimit)
+}
+
+// GetModerationBlockedWords returns the comma separated words (as set via config file, environment variable or runtime setting)
+// that contributions to public projects must not contain.
+func GetModerationBlockedWords() []string {
+	var words []string
+	for _, w := range strings.Split(tunableString(varModerationBlockedWords), ",") {
+		if w = strings.TrimSpace(w); w != "" {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
 // Auth-related defaults
 
 // RSAPrivateKey for signing JWT Tokens
@@ -268,3 +619,379 @@ var camouflagedAccessToken = "751e16a8b39c0985066-AccessToken-4871777f2c13b32be8
 
 // ActualToken is actual OAuth access token of github
 var defaultActualToken = strings.Split(camouflagedAccessToken, "-AccessToken-")[0] + strings.Split(camouflagedAccessToken, "-AccessToken-")[1]
+
+// GetChallengeRecaptchaSecret returns the reCAPTCHA secret key (as set via config file or environment variable)
+// used to verify the challenges solved by anonymous contributors.
+func GetChallengeRecaptchaSecret() string {
+	return viper.GetString(varChallengeRecaptchaSecret)
+}
+
+// GetChallengeRecaptchaURL returns the reCAPTCHA verification URL as set via default, config file, or environment variable
+func GetChallengeRecaptchaURL() string {
+	return viper.GetString(varChallengeRecaptchaURL)
+}
+
+// GetChallengeHCaptchaSecret returns the hCaptcha secret key (as set via config file or environment variable)
+// used to verify the challenges solved by anonymous contributors.
+func GetChallengeHCaptchaSecret() string {
+	return viper.GetString(varChallengeHCaptchaSecret)
+}
+
+// GetChallengeHCaptchaURL returns the hCaptcha verification URL as set via default, config file, or environment variable
+func GetChallengeHCaptchaURL() string {
+	return viper.GetString(varChallengeHCaptchaURL)
+}
+
+// GetPageSizeDefault returns the number of items list endpoints return when no page limit is given (as set via
+// default, config file, environment variable or runtime setting).
+func GetPageSizeDefault() int {
+	return tunableInt(varPageSizeDefault)
+}
+
+// GetPageSizeMax returns the maximum number of items list endpoints return (as set via default, config file,
+// environment variable or runtime setting).
+func GetPageSizeMax() int {
+	return tunableInt(varPageSizeMax)
+}
+
+// GetAdminIdentities returns the comma separated IDs of the identities (as set via config file or environment
+// variable) that administrate the instance.
+func GetAdminIdentities() []string {
+	var ids []string
+	for _, id := range strings.Split(viper.GetString(varAdminIdentities), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// GetJobsWorkers returns the number of workers (as set via config file or environment variable)
+// that run background jobs.
+func GetJobsWorkers() int {
+	return viper.GetInt(varJobsWorkers)
+}
+
+// GetJobsPoll returns how often idle workers (as set via config file or environment variable)
+// look for due background jobs.
+func GetJobsPoll() time.Duration {
+	return viper.GetDuration(varJobsPoll)
+}
+
+// GetJobsLockTimeout returns after how long (as set via config file or environment variable)
+// a running background job is considered abandoned and run again.
+func GetJobsLockTimeout() time.Duration {
+	return viper.GetDuration(varJobsLockTimeout)
+}
+
+// GetStaleSchedule returns the cron spec (as set via config file or environment variable)
+// of the sweep that flags stale work items.
+func GetStaleSchedule() string {
+	return viper.GetString(varStaleSchedule)
+}
+
+// GetEscalationSchedule returns the cron spec (as set via config file or environment variable)
+// of the sweep that escalates unassigned or untouched work items.
+func GetEscalationSchedule() string {
+	return viper.GetString(varEscalationSchedule)
+}
+
+// GetRollupPointsField returns the work item field (as set via config file or environment variable)
+// whose values of the children are summed up as points of the parent work item.
+func GetRollupPointsField() string {
+	return viper.GetString(varRollupPointsField)
+}
+
+// GetReparentMaxWorkItems returns the number of work items (as set via config file or environment
+// variable) that can be moved to a new parent or iteration at once.
+func GetReparentMaxWorkItems() int {
+	return tunableInt(varReparentMaxWorkItems)
+}
+
+// GetFlowSchedule returns the cron spec (as set via config file or environment variable)
+// of the daily snapshot of the work item states used by the cumulative flow diagrams.
+func GetFlowSchedule() string {
+	return viper.GetString(varFlowSchedule)
+}
+
+// GetTrashRetention returns how long deleted work items (as set via config file or environment
+// variable) stay in the trash before they are purged.
+func GetTrashRetention() time.Duration {
+	return viper.GetDuration(varTrashRetention)
+}
+
+// GetTrashSchedule returns the cron spec (as set via config file or environment variable)
+// of the job purging the trash.
+func GetTrashSchedule() string {
+	return viper.GetString(varTrashSchedule)
+}
+
+// GetHistoryRetention returns how long the superseded work item events (as set via config file
+// or environment variable) are kept, 0 keeps them forever.
+func GetHistoryRetention() time.Duration {
+	return viper.GetDuration(varHistoryRetention)
+}
+
+// GetHistorySchedule returns the cron spec (as set via config file or environment variable)
+// of the job maintaining the partitions of the work item events.
+func GetHistorySchedule() string {
+	return viper.GetString(varHistorySchedule)
+}
+
+// GetRetentionSchedule returns the cron spec (as set via config file or environment variable)
+// of the job enforcing the retention policies of the projects.
+func GetRetentionSchedule() string {
+	return viper.GetString(varRetentionSchedule)
+}
+
+// GetChangeFeedPublisher returns the kind of message bus (as set via config file or environment variable)
+// the changes of work items, links and comments are published to, empty if the change feed is disabled.
+func GetChangeFeedPublisher() string {
+	return viper.GetString(varChangeFeedPublisher)
+}
+
+// GetChangeFeedBrokers returns the comma separated addresses of the message bus brokers
+// (as set via config file or environment variable) of the change feed.
+func GetChangeFeedBrokers() []string {
+	var brokers []string
+	for _, b := range strings.Split(viper.GetString(varChangeFeedBrokers), ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			brokers = append(brokers, b)
+		}
+	}
+	return brokers
+}
+
+// GetChangeFeedTopicPrefix returns the prefix (as set via config file or environment variable)
+// of the topics the change feed publishes to.
+func GetChangeFeedTopicPrefix() string {
+	return viper.GetString(varChangeFeedTopicPrefix)
+}
+
+// GetOutboxWebhooks returns the comma separated URLs (as set via config file or environment variable)
+// the outbox records are posted to.
+func GetOutboxWebhooks() []string {
+	var urls []string
+	for _, u := range strings.Split(viper.GetString(varOutboxWebhooks), ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// GetOutboxWebhookSecret returns the secret (as set via config file or environment variable)
+// the requests posting outbox records are signed with.
+func GetOutboxWebhookSecret() string {
+	return viper.GetString(varOutboxWebhookSecret)
+}
+
+// GetOutboxRetention returns how long delivered outbox records (as set via config file or
+// environment variable) are kept.
+func GetOutboxRetention() time.Duration {
+	return viper.GetDuration(varOutboxRetention)
+}
+
+// GetOutboxMaxAttempts returns how many times (as set via config file or environment variable)
+// a sink may fail to accept an outbox record before the record is dead.
+func GetOutboxMaxAttempts() int {
+	return viper.GetInt(varOutboxMaxAttempts)
+}
+
+// GetSearchElasticURL returns the URL of the Elasticsearch server (as set via config file or
+// environment variable) work items are indexed in, empty if searches run in Postgres.
+func GetSearchElasticURL() string {
+	return viper.GetString(varSearchElasticURL)
+}
+
+// GetSearchElasticIndex returns the name of the Elasticsearch index (as set via config file or
+// environment variable) work items are indexed in.
+func GetSearchElasticIndex() string {
+	return viper.GetString(varSearchElasticIndex)
+}
+
+// GetBackupLocation returns the URL of the object store (as set via config file or environment
+// variable) the backups are kept in, empty if backups are disabled.
+func GetBackupLocation() string {
+	return viper.GetString(varBackupLocation)
+}
+
+// GetBackupToken returns the bearer token (as set via config file or environment variable) of
+// the requests to an http(s) object store.
+func GetBackupToken() string {
+	return viper.GetString(varBackupToken)
+}
+
+// GetMigrationOnMismatch returns what to do (as set via config file or environment variable)
+// when the schema of the database is incompatible with the code: "refuse" to start or run in
+// "readonly" mode.
+func GetMigrationOnMismatch() string {
+	return viper.GetString(varMigrationOnMismatch)
+}
+
+// IsMaintenanceEnabled returns true if the API is read-only for maintenance (as set via config
+// file, environment variable or runtime setting).
+func IsMaintenanceEnabled() bool {
+	return tunableBool(varMaintenanceEnabled)
+}
+
+// GetMaintenanceMessage returns the message (as set via config file, environment variable or
+// runtime setting) the requests rejected during maintenance get.
+func GetMaintenanceMessage() string {
+	return tunableString(varMaintenanceMessage)
+}
+
+// GetShareSecret returns the secret (as set via config file or environment variable) the tokens
+// of share links are signed with, empty if share links are disabled.
+func GetShareSecret() string {
+	return viper.GetString(varShareSecret)
+}
+
+// GetShareMaxDays returns the number of days (as set via config file or environment variable)
+// share links can be valid at most.
+func GetShareMaxDays() int {
+	return viper.GetInt(varShareMaxDays)
+}
+
+// GetDraftMaxAge returns how long drafts (as set via config file or environment variable) can
+// stay unchanged before they are deleted as abandoned, 0 keeps them.
+func GetDraftMaxAge() time.Duration {
+	return viper.GetDuration(varDraftMaxAge)
+}
+
+// GetDraftSchedule returns the cron spec (as set via config file or environment variable)
+// of the job deleting abandoned drafts.
+func GetDraftSchedule() string {
+	return viper.GetString(varDraftSchedule)
+}
+
+// GetScanClamd returns the address of the ClamAV daemon (as set via config file or environment
+// variable) uploads are scanned with, empty if uploads aren't scanned.
+func GetScanClamd() string {
+	return viper.GetString(varScanClamd)
+}
+
+// GetScanTimeout returns how long scanning an upload (as set via config file or environment
+// variable) may take before it fails.
+func GetScanTimeout() time.Duration {
+	return viper.GetDuration(varScanTimeout)
+}
+
+// GetAttachmentMaxSize returns the maximum size in bytes of a file uploaded to a project (as set
+// via config file or environment variable).
+func GetAttachmentMaxSize() int64 {
+	return viper.GetInt64(varAttachmentMaxSize)
+}
+
+// GetAttachmentQuota returns the storage quota in bytes of the attachments of a project (as set via
+// default, config file, environment variable or runtime setting), 0 if there is no quota.
+func GetAttachmentQuota() int64 {
+	return int64(tunableInt(varAttachmentQuota))
+}
+
+// GetRequestMaxSizeAPI returns the maximum size in bytes of the body of a request to the JSON API
+// (as set via config file or environment variable).
+func GetRequestMaxSizeAPI() int64 {
+	return viper.GetInt64(varRequestMaxSizeAPI)
+}
+
+// GetRequestMaxSizeUpload returns the maximum size in bytes of the body of a request uploading an
+// attachment (as set via config file or environment variable).
+func GetRequestMaxSizeUpload() int64 {
+	return viper.GetInt64(varRequestMaxSizeUpload)
+}
+
+// GetRequestMaxSizeWebhook returns the maximum size in bytes of the body of a webhook request (as
+// set via config file or environment variable).
+func GetRequestMaxSizeWebhook() int64 {
+	return viper.GetInt64(varRequestMaxSizeWebhook)
+}
+
+// GetLoadMaxInFlightAPI returns how many requests to the JSON API are served at once (as set via
+// config file or environment variable), 0 if there is no cap.
+func GetLoadMaxInFlightAPI() int {
+	return viper.GetInt(varLoadMaxInFlightAPI)
+}
+
+// GetLoadMaxInFlightUpload returns how many requests uploading attachments are served at once (as
+// set via config file or environment variable), 0 if there is no cap.
+func GetLoadMaxInFlightUpload() int {
+	return viper.GetInt(varLoadMaxInFlightUpload)
+}
+
+// GetLoadMaxInFlightWebhook returns how many webhook requests are served at once (as set via config
+// file or environment variable), 0 if there is no cap.
+func GetLoadMaxInFlightWebhook() int {
+	return viper.GetInt(varLoadMaxInFlightWebhook)
+}
+
+// GetLoadQueueSize returns how many requests of an endpoint class may wait to be served (as set via
+// config file or environment variable).
+func GetLoadQueueSize() int {
+	return viper.GetInt(varLoadQueueSize)
+}
+
+// GetLoadQueueTimeout returns how long a request may wait to be served (as set via config file or
+// environment variable).
+func GetLoadQueueTimeout() time.Duration {
+	return viper.GetDuration(varLoadQueueTimeout)
+}
+
+// GetLoadRetryAfter returns when clients of refused requests should retry (as set via config file or
+// environment variable).
+func GetLoadRetryAfter() time.Duration {
+	return viper.GetDuration(varLoadRetryAfter)
+}
+
+// GetBreakerThreshold returns after how many consecutive failures an external dependency isn't
+// called anymore (as set via config file or environment variable).
+func GetBreakerThreshold() int {
+	return viper.GetInt(varBreakerThreshold)
+}
+
+// GetBreakerCooldown returns how long a failing external dependency isn't called (as set via config
+// file or environment variable).
+func GetBreakerCooldown() time.Duration {
+	return viper.GetDuration(varBreakerCooldown)
+}
+
+// GetPushFCMKey returns the server key of the Firebase project (as set via config file or environment
+// variable) notifications are pushed to Android devices with, empty if they aren't pushed.
+func GetPushFCMKey() string {
+	return viper.GetString(varPushFCMKey)
+}
+
+// GetPushAPNsKey returns the PEM encoded key of the Apple developer team (as set via config file or
+// environment variable) notifications are pushed to iOS devices with, empty if they aren't pushed.
+func GetPushAPNsKey() string {
+	return viper.GetString(varPushAPNsKey)
+}
+
+// GetPushAPNsKeyID returns the ID of the APNs key (as set via config file or environment variable).
+func GetPushAPNsKeyID() string {
+	return viper.GetString(varPushAPNsKeyID)
+}
+
+// GetPushAPNsTeamID returns the ID of the Apple developer team (as set via config file or environment
+// variable) the APNs key belongs to.
+func GetPushAPNsTeamID() string {
+	return viper.GetString(varPushAPNsTeamID)
+}
+
+// GetPushAPNsTopic returns the bundle ID of the iOS app (as set via default, config file or environment
+// variable) notifications are pushed to.
+func GetPushAPNsTopic() string {
+	return viper.GetString(varPushAPNsTopic)
+}
+
+// IsPushAPNsSandbox returns true if notifications are pushed through the APNs sandbox (as set via
+// config file or environment variable) to development builds of the iOS app.
+func IsPushAPNsSandbox() bool {
+	return viper.GetBool(varPushAPNsSandbox)
+}
+
+// GetImpersonationTTL returns how long the tokens instance admins are issued to act as another
+// identity are valid (as set via config file or environment variable).
+func GetImpersonationTTL() time.Duration {
+	return viper.GetDuration(varImpersonationTTL)
+}
@@ -0,0 +1,168 @@
+package configuration
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Kinds of tunable values
+const (
+	tunableKindInt      = "int"
+	tunableKindDuration = "duration"
+	tunableKindString   = "string"
+	tunableKindBool     = "bool"
+)
+
+// tunables are the variables that can be changed at runtime, see Reload
+var tunables = map[string]string{
+	varPageSizeDefault:            tunableKindInt,
+	varPageSizeMax:                tunableKindInt,
+	varModerationNewUserPeriod:    tunableKindDuration,
+	varModerationNewUserRateLimit: tunableKindInt,
+	varModerationBlockedWords:     tunableKindString,
+	varMaintenanceEnabled:         tunableKindBool,
+	varMaintenanceMessage:         tunableKindString,
+	varAttachmentQuota:            tunableKindInt,
+	varReparentMaxWorkItems:       tunableKindInt,
+}
+
+var (
+	tunablesLock sync.RWMutex
+	// reloaded holds the tunables read by the last call to Reload, they
+	// take precedence over the values viper read during Setup
+	reloaded map[string]string
+	// configFile is the file given to Setup, it is read again on Reload
+	configFile string
+)
+
+func resetTunables(configFilePath string) {
+	tunablesLock.Lock()
+	defer tunablesLock.Unlock()
+	reloaded = nil
+	configFile = configFilePath
+}
+
+// Tunables returns the names of the variables that can be changed at runtime
+// in alphabetical order
+func Tunables() []string {
+	names := make([]string, 0, len(tunables))
+	for name := range tunables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TunableValue returns the current value of the given tunable variable
+func TunableValue(name string) string {
+	switch tunables[name] {
+	case tunableKindInt:
+		return strconv.Itoa(tunableInt(name))
+	case tunableKindDuration:
+		return tunableDuration(name).String()
+	case tunableKindBool:
+		return strconv.FormatBool(tunableBool(name))
+	}
+	return tunableString(name)
+}
+
+// ValidateTunable returns an error if the variable can't be changed at
+// runtime or the value doesn't match its kind
+func ValidateTunable(name string, value string) error {
+	kind, ok := tunables[name]
+	if !ok {
+		return fmt.Errorf("%s can't be changed at runtime", name)
+	}
+	var err error
+	switch kind {
+	case tunableKindInt:
+		_, err = strconv.Atoi(value)
+	case tunableKindDuration:
+		_, err = time.ParseDuration(value)
+	case tunableKindBool:
+		_, err = strconv.ParseBool(value)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid value of %s: %s", name, err.Error())
+	}
+	return nil
+}
+
+// Reload reads the tunable variables from the config file given to Setup
+// again and applies the given runtime settings on top of them. Environment
+// variables still take precedence over the config file but not over the
+// runtime settings. Other variables are not reloaded, changing them requires
+// a restart.
+func Reload(settings map[string]string) error {
+	values := map[string]string{}
+	if configFile != "" {
+		v := viper.New()
+		v.SetConfigFile(configFile)
+		v.SetConfigType("yaml")
+		if err := v.ReadInConfig(); err != nil {
+			return fmt.Errorf("Fatal error config file: %s \n", err)
+		}
+		for name := range tunables {
+			if v.IsSet(name) && !isSetInEnv(name) {
+				values[name] = v.GetString(name)
+			}
+		}
+	}
+	for name, value := range settings {
+		if err := ValidateTunable(name, value); err != nil {
+			return err
+		}
+		values[name] = value
+	}
+
+	tunablesLock.Lock()
+	defer tunablesLock.Unlock()
+	reloaded = values
+	return nil
+}
+
+// isSetInEnv returns true if the variable is overridden with an environment
+// variable, see Setup
+func isSetInEnv(name string) bool {
+	_, ok := os.LookupEnv("ALMIGHTY_" + strings.ToUpper(strings.Replace(name, ".", "_", -1)))
+	return ok
+}
+
+func tunableString(name string) string {
+	tunablesLock.RLock()
+	defer tunablesLock.RUnlock()
+	if value, ok := reloaded[name]; ok {
+		return value
+	}
+	return viper.GetString(name)
+}
+
+// tunableInt returns the integer value of the tunable, reloaded values are
+// validated so the conversion only fails for invalid startup values
+func tunableInt(name string) int {
+	if i, err := strconv.Atoi(tunableString(name)); err == nil {
+		return i
+	}
+	return viper.GetInt(name)
+}
+
+func tunableDuration(name string) time.Duration {
+	if d, err := time.ParseDuration(tunableString(name)); err == nil {
+		return d
+	}
+	return viper.GetDuration(name)
+}
+
+func tunableBool(name string) bool {
+	if b, err := strconv.ParseBool(tunableString(name)); err == nil {
+		return b
+	}
+	return viper.GetBool(name)
+}
@@ -0,0 +1,54 @@
+package configuration_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloadTunables(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	require.Nil(t, configuration.Setup(""))
+	defer configuration.Setup("")
+
+	assert.Equal(t, 20, configuration.GetPageSizeDefault())
+	assert.Equal(t, "24h0m0s", configuration.TunableValue("moderation.newuser.period"))
+
+	err := configuration.Reload(map[string]string{
+		"paging.size.default":       "50",
+		"moderation.newuser.period": "1h",
+	})
+	require.Nil(t, err)
+	assert.Equal(t, 50, configuration.GetPageSizeDefault())
+	assert.Equal(t, time.Hour, configuration.GetModerationNewUserPeriod())
+	assert.Equal(t, 100, configuration.GetPageSizeMax())
+
+	// settings that are removed fall back to the startup configuration
+	require.Nil(t, configuration.Reload(nil))
+	assert.Equal(t, 20, configuration.GetPageSizeDefault())
+
+	assert.NotNil(t, configuration.Reload(map[string]string{"paging.size.default": "many"}))
+	assert.NotNil(t, configuration.Reload(map[string]string{"postgres.host": "elsewhere"}))
+	assert.Equal(t, 20, configuration.GetPageSizeDefault())
+}
+
+func TestReloadMaintenance(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	require.Nil(t, configuration.Setup(""))
+	defer configuration.Setup("")
+
+	assert.False(t, configuration.IsMaintenanceEnabled())
+	require.Nil(t, configuration.Reload(map[string]string{
+		"maintenance.enabled": "true",
+		"maintenance.message": "back at noon",
+	}))
+	assert.True(t, configuration.IsMaintenanceEnabled())
+	assert.Equal(t, "true", configuration.TunableValue("maintenance.enabled"))
+	assert.Equal(t, "back at noon", configuration.GetMaintenanceMessage())
+
+	assert.NotNil(t, configuration.Reload(map[string]string{"maintenance.enabled": "sometimes"}))
+}
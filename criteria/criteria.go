@@ -43,6 +43,9 @@ type ExpressionVisitor interface {
 	And(a *AndExpression) interface{}
 	Or(a *OrExpression) interface{}
 	Equals(e *EqualsExpression) interface{}
+	LessThan(e *LessThanExpression) interface{}
+	GreaterOrEqual(e *GreaterOrEqualExpression) interface{}
+	In(e *InExpression) interface{}
 	Parameter(v *ParameterExpression) interface{}
 	Literal(c *LiteralExpression) interface{}
 }
@@ -200,3 +203,55 @@ func (t *EqualsExpression) Accept(visitor ExpressionVisitor) interface{} {
 func Equals(left Expression, right Expression) Expression {
 	return reparent(&EqualsExpression{binaryExpression{expression{}, left, right}})
 }
+
+// <
+
+// LessThanExpression represents the less than operator
+type LessThanExpression struct {
+	binaryExpression
+}
+
+// Accept implements ExpressionVisitor
+func (t *LessThanExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.LessThan(t)
+}
+
+// LessThan constructs a LessThanExpression
+func LessThan(left Expression, right Expression) Expression {
+	return reparent(&LessThanExpression{binaryExpression{expression{}, left, right}})
+}
+
+// >=
+
+// GreaterOrEqualExpression represents the greater than or equal operator
+type GreaterOrEqualExpression struct {
+	binaryExpression
+}
+
+// Accept implements ExpressionVisitor
+func (t *GreaterOrEqualExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.GreaterOrEqual(t)
+}
+
+// GreaterOrEqual constructs a GreaterOrEqualExpression
+func GreaterOrEqual(left Expression, right Expression) Expression {
+	return reparent(&GreaterOrEqualExpression{binaryExpression{expression{}, left, right}})
+}
+
+// IN
+
+// InExpression represents the membership of the left value in the list of
+// values on the right
+type InExpression struct {
+	binaryExpression
+}
+
+// Accept implements ExpressionVisitor
+func (t *InExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.In(t)
+}
+
+// In constructs an InExpression
+func In(left Expression, right Expression) Expression {
+	return reparent(&InExpression{binaryExpression{expression{}, left, right}})
+}
@@ -27,6 +27,18 @@ func (i *postOrderIterator) Equals(exp *EqualsExpression) interface{} {
 	return i.binary(exp)
 }
 
+func (i *postOrderIterator) LessThan(exp *LessThanExpression) interface{} {
+	return i.binary(exp)
+}
+
+func (i *postOrderIterator) GreaterOrEqual(exp *GreaterOrEqualExpression) interface{} {
+	return i.binary(exp)
+}
+
+func (i *postOrderIterator) In(exp *InExpression) interface{} {
+	return i.binary(exp)
+}
+
 func (i *postOrderIterator) Parameter(exp *ParameterExpression) interface{} {
 	return i.visit(exp)
 }
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/dashboard"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// DashboardController implements the dashboard resource.
+type DashboardController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewDashboardController creates a dashboard controller.
+func NewDashboardController(service *goa.Service, db application.DB) *DashboardController {
+	return &DashboardController{Controller: service.NewController("DashboardController"), db: db}
+}
+
+// List runs the list action.
+func (c *DashboardController) List(ctx *app.ListDashboardContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		dashboards, err := appl.Dashboards().ListOwned(ctx, *identityID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(convertDashboardList(ctx.RequestData, dashboards))
+	})
+}
+
+// Create runs the create action.
+func (c *DashboardController) Create(ctx *app.CreateDashboardContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	d, err := dashboardFromPayload(ctx.Payload.Data)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	d.OwnerID = identityID
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if err := appl.Dashboards().Create(ctx, d); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.DashboardSingle{Data: ConvertDashboard(ctx.RequestData, d)}
+		ctx.ResponseData.Header().Set("Location", AbsoluteURL(ctx.RequestData, app.DashboardHref(d.ID)))
+		return ctx.Created(res)
+	})
+}
+
+// Show runs the show action.
+func (c *DashboardController) Show(ctx *app.ShowDashboardContext) error {
+	return c.read(ctx, ctx.ID, func(appl application.Application, d *dashboard.Dashboard) error {
+		return ctx.OK(&app.DashboardSingle{Data: ConvertDashboard(ctx.RequestData, d)})
+	})
+}
+
+// Data runs the data action.
+func (c *DashboardController) Data(ctx *app.DataDashboardContext) error {
+	return c.read(ctx, ctx.ID, func(appl application.Application, d *dashboard.Dashboard) error {
+		data, err := appl.Dashboards().Data(ctx, *d)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.DashboardData{Data: make(map[string]*app.DashboardWidgetData, len(data))}
+		for id, wd := range data {
+			converted := &app.DashboardWidgetData{}
+			for _, w := range d.Widgets {
+				if w.ID != id {
+					continue
+				}
+				switch w.Kind {
+				case dashboard.WidgetCount:
+					count := wd.Count
+					converted.Count = &count
+				case dashboard.WidgetChart:
+					converted.Groups = wd.Groups
+				case dashboard.WidgetActivity:
+					converted.Items = ConvertWorkItems(ctx.RequestData, wd.Items)
+				}
+			}
+			res.Data[id] = converted
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Update runs the update action.
+func (c *DashboardController) Update(ctx *app.UpdateDashboardContext) error {
+	changes, err := dashboardFromPayload(ctx.Payload.Data)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return c.write(ctx, ctx.ID, func(appl application.Application, d *dashboard.Dashboard) error {
+		attrs := ctx.Payload.Data.Attributes
+		if attrs.Version == nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.version", nil).Expected("not nil"))
+		}
+		d.Version = *attrs.Version
+		if attrs.Name != nil {
+			d.Name = changes.Name
+		}
+		if attrs.Widgets != nil {
+			d.Widgets = changes.Widgets
+		}
+		if err := appl.Dashboards().Save(ctx, d); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.DashboardSingle{Data: ConvertDashboard(ctx.RequestData, d)})
+	})
+}
+
+// Delete runs the delete action.
+func (c *DashboardController) Delete(ctx *app.DeleteDashboardContext) error {
+	return c.write(ctx, ctx.ID, func(appl application.Application, d *dashboard.Dashboard) error {
+		if err := appl.Dashboards().Delete(ctx, d.ID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.NoContent()
+	})
+}
+
+// dashboardContext is implemented by the contexts of the dashboard actions
+type dashboardContext interface {
+	context.Context
+	jsonapi.InternalServerError
+}
+
+// read runs the given function in a transaction if the dashboard is visible
+// to the viewer, personal dashboards are only visible to their owner and
+// project dashboards to everyone who can read the project
+func (c *DashboardController) read(ctx dashboardContext, id string, f func(appl application.Application, d *dashboard.Dashboard) error) error {
+	dashboardID, err := uuid.FromString(id)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		d, err := loadVisibleDashboard(ctx, appl, dashboardID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return f(appl, d)
+	})
+}
+
+// write runs the given function in a transaction if the current identity
+// owns the dashboard or administrates its project
+func (c *DashboardController) write(ctx dashboardContext, id string, f func(appl application.Application, d *dashboard.Dashboard) error) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	return c.read(ctx, id, func(appl application.Application, d *dashboard.Dashboard) error {
+		if d.ProjectID != nil && !isInstanceAdmin(ctx) {
+			admin, err := isProjectAdmin(ctx, appl, *d.ProjectID, *identityID)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			if !admin {
+				return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only project admins can change the dashboards of the project"))
+			}
+		}
+		return f(appl, d)
+	})
+}
+
+// loadVisibleDashboard returns the dashboard if the viewer of ctx can see it
+// returns NotFoundError or InternalError
+func loadVisibleDashboard(ctx context.Context, appl application.Application, id uuid.UUID) (*dashboard.Dashboard, error) {
+	d, err := appl.Dashboards().Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if d.OwnerID != nil {
+		viewer := workitem.ContextViewer(ctx)
+		if viewer == nil || viewer.IdentityID == nil || !uuid.Equal(*viewer.IdentityID, *d.OwnerID) {
+			return nil, errors.NewNotFoundError("dashboard", id.String())
+		}
+	}
+	if d.ProjectID != nil {
+		if _, err := appl.Projects().Load(ctx, *d.ProjectID); err != nil {
+			return nil, errors.NewNotFoundError("dashboard", id.String())
+		}
+	}
+	return d, nil
+}
+
+// dashboardFromPayload returns the name and the widgets of the payload
+// returns BadParameterError
+func dashboardFromPayload(data *app.Dashboard) (*dashboard.Dashboard, error) {
+	if data == nil || data.Attributes == nil {
+		return nil, errors.NewBadParameterError("data.attributes", nil).Expected("not nil")
+	}
+	attrs := data.Attributes
+	d := dashboard.Dashboard{Widgets: dashboard.Widgets{}}
+	if attrs.Name != nil {
+		d.Name = *attrs.Name
+	}
+	for _, w := range attrs.Widgets {
+		widget := dashboard.Widget{ID: w.ID, Kind: w.Kind}
+		if w.Title != nil {
+			widget.Title = *w.Title
+		}
+		if w.Filter != nil {
+			widget.Filter = *w.Filter
+		}
+		if w.GroupBy != nil {
+			widget.GroupBy = *w.GroupBy
+		}
+		if w.Limit != nil {
+			widget.Limit = *w.Limit
+		}
+		d.Widgets = append(d.Widgets, widget)
+	}
+	return &d, nil
+}
+
+func convertDashboardList(request *goa.RequestData, dashboards []*dashboard.Dashboard) *app.DashboardList {
+	data := make([]*app.Dashboard, 0, len(dashboards))
+	for _, d := range dashboards {
+		data = append(data, ConvertDashboard(request, d))
+	}
+	return &app.DashboardList{
+		Data: data,
+		Meta: &app.WorkItemListResponseMeta{TotalCount: len(data)},
+	}
+}
+
+// ConvertDashboard converts between internal and external REST representation
+func ConvertDashboard(request *goa.RequestData, d *dashboard.Dashboard) *app.Dashboard {
+	selfURL := AbsoluteURL(request, app.DashboardHref(d.ID))
+	widgets := make([]*app.DashboardWidget, 0, len(d.Widgets))
+	for _, w := range d.Widgets {
+		widget := &app.DashboardWidget{ID: w.ID, Kind: w.Kind}
+		if w.Title != "" {
+			title := w.Title
+			widget.Title = &title
+		}
+		if w.Filter != "" {
+			filter := w.Filter
+			widget.Filter = &filter
+		}
+		if w.GroupBy != "" {
+			groupBy := w.GroupBy
+			widget.GroupBy = &groupBy
+		}
+		if w.Limit != 0 {
+			limit := w.Limit
+			widget.Limit = &limit
+		}
+		widgets = append(widgets, widget)
+	}
+	converted := &app.Dashboard{
+		Type: "dashboards",
+		ID:   &d.ID,
+		Attributes: &app.DashboardAttributes{
+			Name:    &d.Name,
+			Widgets: widgets,
+			Version: &d.Version,
+		},
+		Relationships: &app.DashboardRelations{},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+	if d.OwnerID != nil {
+		identityType := "identities"
+		ownerID := d.OwnerID.String()
+		converted.Relationships.Owner = &app.RelationGeneric{
+			Data: &app.GenericData{Type: &identityType, ID: &ownerID},
+		}
+	}
+	if d.ProjectID != nil {
+		projectType := "projects"
+		projectID := d.ProjectID.String()
+		projectSelfURL := AbsoluteURL(request, app.ProjectHref(projectID))
+		converted.Relationships.Project = &app.RelationGeneric{
+			Data:  &app.GenericData{Type: &projectType, ID: &projectID},
+			Links: &app.GenericLinks{Self: &projectSelfURL},
+		}
+	}
+	return converted
+}
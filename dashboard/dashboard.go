@@ -0,0 +1,248 @@
+// Package dashboard stores the dashboards of identities and projects and
+// resolves the data of their widgets.
+package dashboard
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	query "github.com/almighty/almighty-core/query/simple"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Kinds of widgets
+const (
+	// WidgetCount shows the number of work items matching its filter
+	WidgetCount = "count"
+	// WidgetChart shows the number of matching work items per value of a field
+	WidgetChart = "chart"
+	// WidgetActivity lists the latest changed work items matching its filter
+	WidgetActivity = "activity"
+)
+
+// MaxWidgets is the number of widgets a dashboard can hold
+const MaxWidgets = 20
+
+// MaxActivity is the number of work items an activity widget can list
+const MaxActivity = 50
+
+// defaultActivity is the number of work items an activity widget lists if
+// it has no limit
+const defaultActivity = 10
+
+// Widget is a single tile of a dashboard
+type Widget struct {
+	// ID identifies the widget within its dashboard
+	ID    string `json:"id"`
+	Kind  string `json:"kind"`
+	Title string `json:"title,omitempty"`
+	// Filter is a query expression restricting the work items of the widget
+	Filter string `json:"filter,omitempty"`
+	// GroupBy is the field the chart counts the work items by
+	GroupBy string `json:"group-by,omitempty"`
+	// Limit is the number of work items of an activity widget
+	Limit int `json:"limit,omitempty"`
+}
+
+// Widgets is the list of widgets of a dashboard in their display order
+type Widgets []Widget
+
+// Value implements the driver.Valuer interface
+func (ws Widgets) Value() (driver.Value, error) {
+	if ws == nil {
+		ws = Widgets{}
+	}
+	return json.Marshal(ws)
+}
+
+// Scan implements the sql.Scanner interface
+func (ws *Widgets) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("scan source was not []byte")
+	}
+	return json.Unmarshal(b, ws)
+}
+
+// Dashboard is a named set of widgets owned by an identity or shared in a
+// project
+type Dashboard struct {
+	gormsupport.Lifecycle
+	ID        uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	Name      string
+	OwnerID   *uuid.UUID `sql:"type:uuid"`
+	ProjectID *uuid.UUID `sql:"type:uuid"`
+	Widgets   Widgets    `sql:"type:jsonb"`
+	Version   int
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m Dashboard) TableName() string {
+	return "dashboards"
+}
+
+// Validate checks the name and the widgets of the dashboard
+// returns BadParameterError
+func (m Dashboard) Validate() error {
+	if m.Name == "" {
+		return errors.NewBadParameterError("name", m.Name).Expected("not empty")
+	}
+	if len(m.Widgets) > MaxWidgets {
+		return errors.NewBadParameterError("widgets", len(m.Widgets)).Expected(fmt.Sprintf("at most %d widgets", MaxWidgets))
+	}
+	ids := map[string]bool{}
+	for _, w := range m.Widgets {
+		if w.ID == "" || ids[w.ID] {
+			return errors.NewBadParameterError("widgets.id", w.ID).Expected("unique and not empty")
+		}
+		ids[w.ID] = true
+		if _, err := query.Parse(&w.Filter); err != nil {
+			return errors.NewBadParameterError("widgets.filter", w.Filter).Expected(err.Error())
+		}
+		switch w.Kind {
+		case WidgetCount:
+		case WidgetChart:
+			if _, ok := chartFields[w.GroupBy]; !ok {
+				return errors.NewBadParameterError("widgets.group-by", w.GroupBy).Expected(chartFieldNames())
+			}
+		case WidgetActivity:
+			if w.Limit < 0 || w.Limit > MaxActivity {
+				return errors.NewBadParameterError("widgets.limit", w.Limit).Expected(fmt.Sprintf("at most %d, 0 for the default", MaxActivity))
+			}
+		default:
+			return errors.NewBadParameterError("widgets.kind", w.Kind).Expected([]string{WidgetCount, WidgetChart, WidgetActivity})
+		}
+	}
+	return nil
+}
+
+// Repository describes interactions with dashboards
+type Repository interface {
+	Create(ctx context.Context, d *Dashboard) error
+	Load(ctx context.Context, id uuid.UUID) (*Dashboard, error)
+	Save(ctx context.Context, d *Dashboard) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	ListOwned(ctx context.Context, ownerID uuid.UUID) ([]*Dashboard, error)
+	ListProject(ctx context.Context, projectID uuid.UUID) ([]*Dashboard, error)
+	Data(ctx context.Context, d Dashboard) (map[string]WidgetData, error)
+}
+
+// NewDashboardRepository creates a new storage type.
+func NewDashboardRepository(db *gorm.DB) Repository {
+	return &GormDashboardRepository{db: db}
+}
+
+// GormDashboardRepository is the implementation of the storage interface for
+// dashboards.
+type GormDashboardRepository struct {
+	db *gorm.DB
+}
+
+// Create stores a new dashboard, it must either have an owner or a project
+// returns BadParameterError or InternalError
+func (m *GormDashboardRepository) Create(ctx context.Context, d *Dashboard) error {
+	defer goa.MeasureSince([]string{"goa", "db", "dashboard", "create"}, time.Now())
+
+	if (d.OwnerID == nil) == (d.ProjectID == nil) {
+		return errors.NewBadParameterError("owner, project", nil).Expected("exactly one of them")
+	}
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	d.ID = uuid.NewV4()
+	d.Version = 0
+	if err := m.db.Create(d).Error; err != nil {
+		goa.LogError(ctx, "error adding Dashboard", "error", err.Error())
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// Load returns the dashboard with the given ID
+// returns NotFoundError or InternalError
+func (m *GormDashboardRepository) Load(ctx context.Context, id uuid.UUID) (*Dashboard, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "dashboard", "load"}, time.Now())
+
+	var obj Dashboard
+	tx := m.db.Where("id = ?", id).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("dashboard", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
+
+// Save updates the name and the widgets of the dashboard, the version of d
+// must match the stored one. The owner and the project can't change.
+// returns NotFoundError, BadParameterError, VersionConflictError or InternalError
+func (m *GormDashboardRepository) Save(ctx context.Context, d *Dashboard) error {
+	defer goa.MeasureSince([]string{"goa", "db", "dashboard", "save"}, time.Now())
+
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	if _, err := m.Load(ctx, d.ID); err != nil {
+		return err
+	}
+	tx := m.db.Model(&Dashboard{}).Where("id = ? AND version = ?", d.ID, d.Version).Updates(map[string]interface{}{
+		"name":    d.Name,
+		"widgets": d.Widgets,
+		"version": d.Version + 1,
+	})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewVersionConflictError("version conflict")
+	}
+	d.Version++
+	return nil
+}
+
+// Delete removes the dashboard with the given ID
+// returns NotFoundError or InternalError
+func (m *GormDashboardRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "dashboard", "delete"}, time.Now())
+
+	tx := m.db.Where("id = ?", id).Delete(&Dashboard{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("dashboard", id.String())
+	}
+	return nil
+}
+
+// ListOwned returns the dashboards of the identity ordered by name
+// returns InternalError
+func (m *GormDashboardRepository) ListOwned(ctx context.Context, ownerID uuid.UUID) ([]*Dashboard, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "dashboard", "listowned"}, time.Now())
+	return m.list(m.db.Where("owner_id = ?", ownerID))
+}
+
+// ListProject returns the dashboards of the project ordered by name
+// returns InternalError
+func (m *GormDashboardRepository) ListProject(ctx context.Context, projectID uuid.UUID) ([]*Dashboard, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "dashboard", "listproject"}, time.Now())
+	return m.list(m.db.Where("project_id = ?", projectID))
+}
+
+func (m *GormDashboardRepository) list(db *gorm.DB) ([]*Dashboard, error) {
+	var objs []*Dashboard
+	if err := db.Order("name, id").Find(&objs).Error; err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return objs, nil
+}
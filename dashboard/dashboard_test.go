@@ -0,0 +1,147 @@
+package dashboard_test
+
+import (
+	"fmt"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/dashboard"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/gormsupport/testfixture"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestDashboardRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunDashboardRepository(t *testing.T) {
+	suite.Run(t, &TestDashboardRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestDashboardRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestDashboardRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestDashboardRepository) createWorkItem(title string, state string) {
+	_, err := workitem.NewWorkItemRepository(test.DB).Create(
+		context.Background(), workitem.SystemFeature,
+		map[string]interface{}{
+			workitem.SystemTitle: title,
+			workitem.SystemState: state,
+		}, "xx")
+	require.Nil(test.T(), err)
+}
+
+func (test *TestDashboardRepository) TestCreateAndSave() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := dashboard.NewDashboardRepository(test.DB)
+	owner := testfixture.CreateIdentity(t, test.DB, "owner")
+
+	d := dashboard.Dashboard{
+		Name:    "Mine",
+		OwnerID: &owner,
+		Widgets: dashboard.Widgets{{ID: "open", Kind: dashboard.WidgetCount, Filter: `{"system.state":"open"}`}},
+	}
+	require.Nil(t, repo.Create(ctx, &d))
+
+	loaded, err := repo.Load(ctx, d.ID)
+	require.Nil(t, err)
+	assert.Equal(t, "Mine", loaded.Name)
+	require.Len(t, loaded.Widgets, 1)
+	assert.Equal(t, dashboard.WidgetCount, loaded.Widgets[0].Kind)
+
+	loaded.Name = "Renamed"
+	require.Nil(t, repo.Save(ctx, loaded))
+	assert.Equal(t, 1, loaded.Version)
+
+	// saving the stale version fails
+	d.Name = "Stale"
+	err = repo.Save(ctx, &d)
+	require.NotNil(t, err)
+	assert.IsType(t, errors.VersionConflictError{}, err)
+
+	owned, err := repo.ListOwned(ctx, owner)
+	require.Nil(t, err)
+	require.Len(t, owned, 1)
+	assert.Equal(t, "Renamed", owned[0].Name)
+
+	require.Nil(t, repo.Delete(ctx, d.ID))
+	_, err = repo.Load(ctx, d.ID)
+	assert.IsType(t, errors.NotFoundError{}, err)
+}
+
+func (test *TestDashboardRepository) TestCreateInvalid() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := dashboard.NewDashboardRepository(test.DB)
+	owner := testfixture.CreateIdentity(t, test.DB, "owner")
+
+	invalid := []dashboard.Dashboard{
+		{Name: "", OwnerID: &owner},
+		{Name: "no scope"},
+		{Name: "kind", OwnerID: &owner, Widgets: dashboard.Widgets{{ID: "a", Kind: "pie"}}},
+		{Name: "group", OwnerID: &owner, Widgets: dashboard.Widgets{{ID: "a", Kind: dashboard.WidgetChart, GroupBy: "system.title"}}},
+		{Name: "filter", OwnerID: &owner, Widgets: dashboard.Widgets{{ID: "a", Kind: dashboard.WidgetCount, Filter: "{"}}},
+		{Name: "ids", OwnerID: &owner, Widgets: dashboard.Widgets{{ID: "a", Kind: dashboard.WidgetCount}, {ID: "a", Kind: dashboard.WidgetCount}}},
+		{Name: "limit", OwnerID: &owner, Widgets: dashboard.Widgets{{ID: "a", Kind: dashboard.WidgetActivity, Limit: dashboard.MaxActivity + 1}}},
+	}
+	for _, d := range invalid {
+		err := repo.Create(ctx, &d)
+		require.NotNil(t, err, d.Name)
+		assert.IsType(t, errors.BadParameterError{}, err, d.Name)
+	}
+}
+
+func (test *TestDashboardRepository) TestData() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := dashboard.NewDashboardRepository(test.DB)
+	owner := testfixture.CreateIdentity(t, test.DB, "owner")
+	title := uuid.NewV4().String()
+	test.createWorkItem(title, workitem.SystemStateOpen)
+	test.createWorkItem(title, workitem.SystemStateOpen)
+	test.createWorkItem(title, workitem.SystemStateClosed)
+
+	filter := fmt.Sprintf(`{"system.title":"%s"}`, title)
+	d := dashboard.Dashboard{
+		Name:    "Data",
+		OwnerID: &owner,
+		Widgets: dashboard.Widgets{
+			{ID: "all", Kind: dashboard.WidgetCount, Filter: filter},
+			{ID: "states", Kind: dashboard.WidgetChart, Filter: filter, GroupBy: workitem.SystemState},
+			{ID: "types", Kind: dashboard.WidgetChart, Filter: filter, GroupBy: dashboard.GroupByType},
+			{ID: "latest", Kind: dashboard.WidgetActivity, Filter: filter, Limit: 2},
+		},
+	}
+	require.Nil(t, repo.Create(ctx, &d))
+
+	data, err := repo.Data(ctx, d)
+	require.Nil(t, err)
+	require.Len(t, data, 4)
+	assert.Equal(t, 3, data["all"].Count)
+	assert.Equal(t, map[string]int{workitem.SystemStateOpen: 2, workitem.SystemStateClosed: 1}, data["states"].Groups)
+	assert.Equal(t, map[string]int{workitem.SystemFeature: 3}, data["types"].Groups)
+	require.Len(t, data["latest"].Items, 2)
+	assert.Equal(t, workitem.SystemStateClosed, data["latest"].Items[0].Fields[workitem.SystemState])
+}
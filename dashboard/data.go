@@ -0,0 +1,151 @@
+package dashboard
+
+import (
+	"sort"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
+	query "github.com/almighty/almighty-core/query/simple"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+)
+
+// GroupByType charts the work items by their type
+const GroupByType = "type"
+
+// chartFields are the fields charts can group by. The work items are counted
+// once per value of the array fields.
+var chartFields = map[string]bool{
+	GroupByType:              false,
+	workitem.SystemState:     false,
+	workitem.SystemPriority:  false,
+	workitem.SystemSeverity:  false,
+	workitem.SystemIteration: false,
+	workitem.SystemRelease:   false,
+	workitem.SystemLabels:    true,
+	workitem.SystemAssignees: true,
+}
+
+func chartFieldNames() []string {
+	names := make([]string, 0, len(chartFields))
+	for name := range chartFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WidgetData is the resolved content of a widget, only the part for the kind
+// of the widget is set
+type WidgetData struct {
+	Count int
+	// Groups are the counts by value of a chart, work items without a
+	// value are counted under the empty value
+	Groups map[string]int
+	Items  []*app.WorkItem
+}
+
+// Data resolves all widgets of the dashboard by their ID. The work items of
+// project dashboards are restricted to the project and only the ones visible
+// to the viewer of ctx are counted.
+// returns BadParameterError or InternalError
+func (m *GormDashboardRepository) Data(ctx context.Context, d Dashboard) (map[string]WidgetData, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "dashboard", "data"}, time.Now())
+
+	res := make(map[string]WidgetData, len(d.Widgets))
+	for _, w := range d.Widgets {
+		exp, err := query.Parse(&w.Filter)
+		if err != nil {
+			return nil, errors.NewBadParameterError("widgets.filter", w.Filter).Expected(err.Error())
+		}
+		if d.ProjectID != nil {
+			exp = criteria.And(exp, criteria.Equals(criteria.Field(workitem.SystemProject), criteria.Literal(d.ProjectID.String())))
+		}
+		var data WidgetData
+		switch w.Kind {
+		case WidgetCount:
+			data.Count, err = m.count(ctx, exp)
+		case WidgetChart:
+			data.Groups, err = m.groups(ctx, exp, w.GroupBy)
+		case WidgetActivity:
+			data.Items, err = m.activity(ctx, exp, w.Limit)
+		default:
+			err = errors.NewBadParameterError("widgets.kind", w.Kind).Expected([]string{WidgetCount, WidgetChart, WidgetActivity})
+		}
+		if err != nil {
+			return nil, err
+		}
+		res[w.ID] = data
+	}
+	return res, nil
+}
+
+func (m *GormDashboardRepository) where(ctx context.Context, exp criteria.Expression) (*gorm.DB, error) {
+	where, params, compileErrors := workitem.Compile(exp)
+	if compileErrors != nil {
+		return nil, errors.NewBadParameterError("filter", exp)
+	}
+	db := m.db.Model(&workitem.WorkItem{}).Where(where, params...)
+	if clause, params := workitem.VisibilityClause(ctx, workitem.WorkItem{}.TableName()); clause != "" {
+		db = db.Where(clause, params...)
+	}
+	return db, nil
+}
+
+func (m *GormDashboardRepository) count(ctx context.Context, exp criteria.Expression) (int, error) {
+	db, err := m.where(ctx, exp)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	if err := db.Count(&count).Error; err != nil {
+		return 0, errors.NewInternalError(err.Error())
+	}
+	return count, nil
+}
+
+func (m *GormDashboardRepository) groups(ctx context.Context, exp criteria.Expression, field string) (map[string]int, error) {
+	db, err := m.where(ctx, exp)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case field == GroupByType:
+		db = db.Select("type AS value, count(*) AS count").Group("type")
+	case chartFields[field]:
+		db = db.Select("value, count(*) AS count").
+			Joins("CROSS JOIN LATERAL jsonb_array_elements_text(coalesce(fields->?, '[]')) AS value", field).
+			Group("value")
+	default:
+		db = db.Select("coalesce(fields->>?, '') AS value, count(*) AS count", field).Group("1")
+	}
+	var rows []struct {
+		Value string
+		Count int
+	}
+	if err := db.Scan(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	groups := make(map[string]int, len(rows))
+	for _, row := range rows {
+		groups[row.Value] = row.Count
+	}
+	return groups, nil
+}
+
+func (m *GormDashboardRepository) activity(ctx context.Context, exp criteria.Expression, limit int) ([]*app.WorkItem, error) {
+	if limit == 0 {
+		limit = defaultActivity
+	}
+	start := 0
+	items, _, err := workitem.NewWorkItemRepository(m.db).List(ctx, exp, &start, &limit, workitem.SortKey{Field: workitem.SortByUpdated, Descending: true})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/sqldebug"
+	"github.com/almighty/almighty-core/token"
+	"github.com/goadesign/goa"
+)
+
+// InjectSQLDebug is a middleware recording the SQL statements of the
+// requests of instance admins that set the X-Debug-SQL header. The
+// statements and their timings are added to the "meta" member of the JSON
+// response. It belongs above the middlewares running statements of their
+// own, like InjectViewer, and below the gzip middleware.
+func InjectSQLDebug(tm token.Manager) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if req.Header.Get(sqldebug.Header) == "" || !isBearerInstanceAdmin(tm, req) {
+				return h(ctx, rw, req)
+			}
+			resp := goa.ContextResponse(ctx)
+			if resp == nil {
+				return h(ctx, rw, req)
+			}
+			recorder := &sqldebug.Recorder{}
+			buf := &bufferedResponseWriter{status: http.StatusOK}
+			buf.ResponseWriter = resp.SwitchWriter(buf)
+			err := h(sqldebug.WithRecorder(ctx, recorder), rw, req)
+			resp.SwitchWriter(buf.ResponseWriter)
+			if err != nil {
+				// the error handler writes the response
+				return err
+			}
+			return buf.flush(recorder)
+		}
+	}
+}
+
+// isBearerInstanceAdmin returns true if the bearer token of the request
+// belongs to one of the configured instance admins, the security middleware
+// hasn't looked at it yet
+func isBearerInstanceAdmin(tm token.Manager, req *http.Request) bool {
+	auth := req.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return false
+	}
+	identity, err := tm.Extract(strings.TrimPrefix(auth, "Bearer "))
+	if err != nil {
+		return false
+	}
+	for _, id := range configuration.GetAdminIdentities() {
+		if id == identity.ID.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// requestDB returns the database the request should use, one recording its
+// statements if the request asked for them
+func requestDB(ctx context.Context, db application.DB) application.DB {
+	recorder := sqldebug.ContextRecorder(ctx)
+	if recorder == nil {
+		return db
+	}
+	if g, ok := db.(*gormapplication.GormDB); ok {
+		return g.WithLogger(recorder)
+	}
+	return db
+}
+
+// bufferedResponseWriter holds back the response until the statements can
+// be added to it
+type bufferedResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader implements http.ResponseWriter
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+// Write implements http.ResponseWriter
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+// flush writes the response with the statements added to the "meta" member
+// of its JSON object, responses that aren't JSON objects are written as
+// they are
+func (w *bufferedResponseWriter) flush(recorder *sqldebug.Recorder) error {
+	body := w.body.Bytes()
+	var doc map[string]json.RawMessage
+	if strings.Contains(w.Header().Get("Content-Type"), "json") && json.Unmarshal(body, &doc) == nil && doc != nil {
+		meta := map[string]interface{}{}
+		if raw, ok := doc["meta"]; ok {
+			json.Unmarshal(raw, &meta)
+		}
+		meta["sql"] = recorder.Statements()
+		meta["sql-duration-ms"] = float64(recorder.Total()) / float64(time.Millisecond)
+		if raw, err := json.Marshal(meta); err == nil {
+			doc["meta"] = raw
+			if b, err := json.Marshal(doc); err == nil {
+				body = b
+			}
+		}
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.ResponseWriter.WriteHeader(w.status)
+	_, err := w.ResponseWriter.Write(body)
+	return err
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/deployment"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// DeploymentController implements the deployment resource.
+type DeploymentController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewDeploymentController creates a deployment controller.
+func NewDeploymentController(service *goa.Service, db application.DB) *DeploymentController {
+	return &DeploymentController{Controller: service.NewController("DeploymentController"), db: db}
+}
+
+// Show runs the show action.
+func (c *DeploymentController) Show(ctx *app.ShowDeploymentContext) error {
+	id, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		d, err := appl.Deployments().Load(ctx, id)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		res := &app.DeploymentSingle{
+			Data: ConvertDeployment(ctx.RequestData, d),
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Create runs the create action.
+func (c *DeploymentController) Create(ctx *app.CreateDeploymentContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	if ctx.Payload.Data == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data", nil).Expected("not nil"))
+	}
+	attrs := ctx.Payload.Data.Attributes
+	if attrs.Environment == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.environment", nil).Expected("not nil"))
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		d := deployment.Deployment{
+			Environment: *attrs.Environment,
+		}
+		if attrs.Revision != nil {
+			d.Revision = *attrs.Revision
+		}
+		if attrs.URL != nil {
+			d.URL = *attrs.URL
+		}
+		rel := ctx.Payload.Data.Relationships
+		if rel != nil && rel.Workitems != nil {
+			for _, data := range rel.Workitems.Data {
+				if data.ID == nil {
+					return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.relationships.workitems.data.id", nil).Expected("not nil"))
+				}
+				// make sure the work item exists
+				_, err := appl.WorkItems().Load(ctx, *data.ID)
+				if err != nil {
+					return jsonapi.JSONErrorResponse(ctx, err)
+				}
+				wiID, err := workitem.ParseWorkItemIDToUint64(*data.ID)
+				if err != nil {
+					return jsonapi.JSONErrorResponse(ctx, err)
+				}
+				d.WorkItemIDs = append(d.WorkItemIDs, wiID)
+			}
+		}
+
+		err := appl.Deployments().Create(ctx, &d)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		res := &app.DeploymentSingle{
+			Data: ConvertDeployment(ctx.RequestData, &d),
+		}
+		ctx.ResponseData.Header().Set("Location", AbsoluteURL(ctx.RequestData, app.DeploymentHref(res.Data.ID)))
+		return ctx.Created(res)
+	})
+}
+
+// ConvertDeployment converts between internal and external REST representation
+func ConvertDeployment(request *goa.RequestData, d *deployment.Deployment) *app.Deployment {
+	workItemType := APIStringTypeWorkItem
+	selfURL := AbsoluteURL(request, app.DeploymentHref(d.ID))
+
+	workItems := []*app.GenericData{}
+	for _, wiID := range d.WorkItemIDs {
+		id := strconv.FormatUint(wiID, 10)
+		workItemSelfURL := AbsoluteURL(request, app.WorkitemHref(id))
+		workItems = append(workItems, &app.GenericData{
+			Type: &workItemType,
+			ID:   &id,
+			Links: &app.GenericLinks{
+				Self: &workItemSelfURL,
+			},
+		})
+	}
+
+	return &app.Deployment{
+		Type: "deployments",
+		ID:   &d.ID,
+		Attributes: &app.DeploymentAttributes{
+			Environment: &d.Environment,
+			Revision:    &d.Revision,
+			URL:         &d.URL,
+			CreatedAt:   &d.CreatedAt,
+		},
+		Relationships: &app.DeploymentRelations{
+			Workitems: &app.RelationGenericList{
+				Data: workItems,
+			},
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
@@ -0,0 +1,130 @@
+package deployment
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Deployment describes a single deployment to an environment
+type Deployment struct {
+	gormsupport.Lifecycle
+	ID          uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	Environment string
+	Revision    string
+	URL         string
+	// WorkItemIDs are the work items included in the deployment
+	WorkItemIDs []uint64 `gorm:"-"`
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m Deployment) TableName() string {
+	return "deployments"
+}
+
+// deploymentWorkItem associates a deployment with a work item it included
+type deploymentWorkItem struct {
+	DeploymentID uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	WorkItemID   uint64    `gorm:"primary_key"`
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m deploymentWorkItem) TableName() string {
+	return "deployment_work_items"
+}
+
+// Repository describes interactions with deployments
+type Repository interface {
+	Create(ctx context.Context, d *Deployment) error
+	Load(ctx context.Context, id uuid.UUID) (*Deployment, error)
+	DeployedWorkItemIDs(ctx context.Context, environment string) ([]uint64, error)
+}
+
+// NewDeploymentRepository creates a new storage type.
+func NewDeploymentRepository(db *gorm.DB) Repository {
+	return &GormDeploymentRepository{db: db}
+}
+
+// GormDeploymentRepository is the implementation of the storage interface for deployments.
+type GormDeploymentRepository struct {
+	db *gorm.DB
+}
+
+// Create records a new deployment together with the work items it included.
+// returns BadParameterError or InternalError
+func (m *GormDeploymentRepository) Create(ctx context.Context, d *Deployment) error {
+	defer goa.MeasureSince([]string{"goa", "db", "deployment", "create"}, time.Now())
+
+	if d.Environment == "" {
+		return errors.NewBadParameterError("environment", d.Environment).Expected("not empty")
+	}
+	d.ID = uuid.NewV4()
+
+	err := m.db.Create(d).Error
+	if err != nil {
+		goa.LogError(ctx, "error adding Deployment", "error", err.Error())
+		return errors.NewInternalError(err.Error())
+	}
+	seen := map[uint64]bool{}
+	for _, wiID := range d.WorkItemIDs {
+		if seen[wiID] {
+			continue
+		}
+		seen[wiID] = true
+		err = m.db.Create(&deploymentWorkItem{DeploymentID: d.ID, WorkItemID: wiID}).Error
+		if err != nil {
+			goa.LogError(ctx, "error adding deployed work item", "error", err.Error())
+			return errors.NewInternalError(err.Error())
+		}
+	}
+	return nil
+}
+
+// Load a single deployment including the IDs of its work items
+// returns NotFoundError or InternalError
+func (m *GormDeploymentRepository) Load(ctx context.Context, id uuid.UUID) (*Deployment, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "deployment", "get"}, time.Now())
+	var obj Deployment
+
+	tx := m.db.Where("id = ?", id).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("deployment", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	var items []deploymentWorkItem
+	err := m.db.Where("deployment_id = ?", id).Order("work_item_id").Find(&items).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	for _, item := range items {
+		obj.WorkItemIDs = append(obj.WorkItemIDs, item.WorkItemID)
+	}
+	return &obj, nil
+}
+
+// DeployedWorkItemIDs returns the IDs of all work items that were included in
+// any deployment to the given environment
+func (m *GormDeploymentRepository) DeployedWorkItemIDs(ctx context.Context, environment string) ([]uint64, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "deployment", "deployedworkitems"}, time.Now())
+	var ids []uint64
+
+	err := m.db.Table("deployment_work_items").
+		Joins("JOIN deployments ON deployments.id = deployment_work_items.deployment_id").
+		Where("deployments.environment = ? AND deployments.deleted_at IS NULL", environment).
+		Order("deployment_work_items.work_item_id").
+		Pluck("DISTINCT deployment_work_items.work_item_id", &ids).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return ids, nil
+}
@@ -0,0 +1,91 @@
+package deployment_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/deployment"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestDeploymentRepository struct {
+	gormsupport.DBTestSuite
+
+	clean       func()
+	workItemIDs []uint64
+}
+
+func TestRunDeploymentRepository(t *testing.T) {
+	suite.Run(t, &TestDeploymentRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestDeploymentRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+
+	test.workItemIDs = nil
+	for i := 0; i < 2; i++ {
+		wi, err := workitem.NewWorkItemRepository(test.DB).Create(
+			context.Background(), workitem.SystemBug,
+			map[string]interface{}{
+				workitem.SystemTitle: "Title",
+				workitem.SystemState: workitem.SystemStateNew,
+			}, "xx")
+		require.Nil(test.T(), err)
+		id, err := workitem.ParseWorkItemIDToUint64(wi.ID)
+		require.Nil(test.T(), err)
+		test.workItemIDs = append(test.workItemIDs, id)
+	}
+}
+
+func (test *TestDeploymentRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestDeploymentRepository) TestCreateAndLoadDeployment() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := deployment.NewDeploymentRepository(test.DB)
+	assert.IsType(t, errors.BadParameterError{}, repo.Create(context.Background(), &deployment.Deployment{}))
+
+	d := deployment.Deployment{
+		Environment: "staging",
+		Revision:    "abc123",
+		WorkItemIDs: []uint64{test.workItemIDs[0], test.workItemIDs[0]},
+	}
+	require.Nil(t, repo.Create(context.Background(), &d))
+
+	loaded, err := repo.Load(context.Background(), d.ID)
+	require.Nil(t, err)
+	assert.Equal(t, "staging", loaded.Environment)
+	assert.Equal(t, []uint64{test.workItemIDs[0]}, loaded.WorkItemIDs)
+}
+
+func (test *TestDeploymentRepository) TestDeployedWorkItemIDs() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := deployment.NewDeploymentRepository(test.DB)
+	require.Nil(t, repo.Create(context.Background(), &deployment.Deployment{Environment: "staging", WorkItemIDs: test.workItemIDs}))
+	require.Nil(t, repo.Create(context.Background(), &deployment.Deployment{Environment: "production", WorkItemIDs: test.workItemIDs[:1]}))
+	require.Nil(t, repo.Create(context.Background(), &deployment.Deployment{Environment: "staging", WorkItemIDs: test.workItemIDs[:1]}))
+
+	ids, err := repo.DeployedWorkItemIDs(context.Background(), "staging")
+	require.Nil(t, err)
+	assert.Equal(t, test.workItemIDs, ids)
+
+	ids, err = repo.DeployedWorkItemIDs(context.Background(), "production")
+	require.Nil(t, err)
+	assert.Equal(t, test.workItemIDs[:1], ids)
+
+	ids, err = repo.DeployedWorkItemIDs(context.Background(), "qa")
+	require.Nil(t, err)
+	assert.Empty(t, ids)
+}
@@ -0,0 +1,309 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var approvalRule = a.Type("ApprovalRule", func() {
+	a.Description(`JSONAPI store for the data of an approval rule.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("approvalrules")
+	})
+	a.Attribute("id", d.UUID, "ID of the approval rule", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", approvalRuleAttributes)
+	a.Attribute("relationships", approvalRuleRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var approvalRuleAttributes = a.Type("ApprovalRuleAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of an approval rule. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("workitemtype", d.String, "The type of the work items the rule applies to, all types if not set", func() {
+		a.Example("system.feature")
+	})
+	a.Attribute("from-state", d.String, "The state the work items move from, all states if not set", func() {
+		a.Example("resolved")
+	})
+	a.Attribute("to-state", d.String, "The state the work items move to", func() {
+		a.Example("ready for release")
+	})
+	a.Attribute("roles", a.ArrayOf(d.String), `The roles of the identities that can approve: project-admin, assignee
+(of the work item) or creator (of the work item)`, func() {
+		a.Example([]string{"project-admin"})
+	})
+	a.Attribute("approvals", d.Integer, "The number of identities that must approve", func() {
+		a.Minimum(1)
+		a.Example(2)
+	})
+	a.Attribute("version", d.Integer, "Version for optimistic concurrency control")
+	a.Attribute("created-at", d.DateTime, "When the rule was created")
+})
+
+var approvalRuleRelationships = a.Type("ApprovalRuleRelations", func() {
+	a.Attribute("project", relationGeneric, "The project the rule applies to the work items of")
+})
+
+var approvalRuleList = JSONList(
+	"ApprovalRule", "Holds the list of approval rules",
+	approvalRule,
+	nil,
+	meta)
+
+var approvalRuleSingle = JSONSingle(
+	"ApprovalRule", "Holds a single approval rule",
+	approvalRule,
+	nil)
+
+var approval = a.Type("Approval", func() {
+	a.Description(`JSONAPI store for the data of an approval of a state transition.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("approvals")
+	})
+	a.Attribute("id", d.UUID, "ID of the approval", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", approvalAttributes)
+	a.Attribute("relationships", approvalRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var approvalAttributes = a.Type("ApprovalAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of an approval. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("from-state", d.String, "The state of the work item when the approval was requested")
+	a.Attribute("to-state", d.String, "The state the work item moves to once approved", func() {
+		a.Example("ready for release")
+	})
+	a.Attribute("status", d.String, "The status of the approval, applied approvals were used by the transition", func() {
+		a.Enum("pending", "approved", "rejected", "applied")
+	})
+	a.Attribute("decisions", a.ArrayOf(approvalDecision), "The approvals and rejections in the order they were given")
+	a.Attribute("created-at", d.DateTime, "When the approval was requested")
+	a.Required("to-state")
+})
+
+var approvalDecision = a.Type("ApprovalDecision", func() {
+	a.Attribute("identity", d.UUID, "The identity that decided")
+	a.Attribute("role", d.String, "The role of the rule the identity decided as", func() {
+		a.Example("project-admin")
+	})
+	a.Attribute("approved", d.Boolean, "True if the identity approved, false if it rejected")
+	a.Attribute("comment", d.String, "Why the identity decided so")
+	a.Attribute("decided-at", d.DateTime, "When the identity decided")
+	a.Required("identity", "role", "approved", "decided-at")
+})
+
+var approvalDecisionPayload = a.Type("ApprovalDecisionPayload", func() {
+	a.Attribute("comment", d.String, "Why the current identity approves or rejects", func() {
+		a.Example("QA passed on staging")
+	})
+})
+
+var approvalRelationships = a.Type("ApprovalRelations", func() {
+	a.Attribute("workitem", relationGeneric, "The work item the approval was requested for")
+	a.Attribute("rule", relationGeneric, "The rule requiring the approval")
+	a.Attribute("requester", relationGeneric, "The identity that requested the approval")
+})
+
+var approvalList = JSONList(
+	"Approval", "Holds the list of approvals",
+	approval,
+	nil,
+	meta)
+
+var approvalSingle = JSONSingle(
+	"Approval", "Holds a single approval",
+	approval,
+	nil)
+
+var _ = a.Resource("approval-rule", func() {
+	a.BasePath("/approval-rules")
+
+	a.Action("show", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Retrieve the approval rule with the given id (project admins only).")
+		a.Response(d.OK, func() {
+			a.Media(approvalRuleSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Update the transition, the roles and the approvals of the approval rule (project admins only).")
+		a.Payload(approvalRuleSingle)
+		a.Response(d.OK, func() {
+			a.Media(approvalRuleSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Delete the approval rule and its approvals (project admins only).")
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("project-approval-rules", func() {
+	a.Parent("project")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("approval-rules"),
+		)
+		a.Description("List the approval rules of the given project in the order they are tried (project admins only).")
+		a.Response(d.OK, func() {
+			a.Media(approvalRuleList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("approval-rules"),
+		)
+		a.Description(`Create an approval rule of the given project (project admins only). The first rule
+matching the type and the transition of a work item moving to another state requires its approvals, the
+new rule is tried after the existing ones. Work items moved by automation rules or stale policies don't need
+approvals.`)
+		a.Payload(approvalRuleSingle)
+		a.Response(d.Created, "/approval-rules/.*", func() {
+			a.Media(approvalRuleSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("work-item-approvals", func() {
+	a.Parent("workitem")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("approvals"),
+		)
+		a.Description("List the approvals requested for the given work item, newest first.")
+		a.Response(d.OK, func() {
+			a.Media(approvalList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("approvals"),
+		)
+		a.Description(`Request the approval of moving the given work item from its current state to the given
+to-state, which must require approvals by the rules of its project.`)
+		a.Payload(approvalSingle)
+		a.Response(d.Created, "/approvals/.*", func() {
+			a.Media(approvalSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("approval", func() {
+	a.BasePath("/approvals")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Retrieve the approval with the given id.")
+		a.Response(d.OK, func() {
+			a.Media(approvalSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("approve", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:id/approve"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description(`Approve the pending approval, the current identity must have one of the roles of the rule
+and can't approve its own requests. The approval is approved once the rule's number of identities approved.`)
+		a.Payload(approvalDecisionPayload)
+		a.Response(d.OK, func() {
+			a.Media(approvalSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("reject", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:id/reject"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description(`Reject the pending approval, the current identity must have one of the roles of the rule.
+A rejected approval stays rejected, the transition needs a new request.`)
+		a.Payload(approvalDecisionPayload)
+		a.Response(d.OK, func() {
+			a.Media(approvalSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
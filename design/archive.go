@@ -0,0 +1,73 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var _ = a.Resource("work-item-archive", func() {
+	a.Parent("workitem")
+
+	a.Action("archive", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("archive"),
+		)
+		a.Description(`Archive the given work item, archived work items are only listed with filter[archived]=true.`)
+		a.Response(d.OK, func() {
+			a.Media(workItemSingle)
+		})
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("unarchive", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("archive"),
+		)
+		a.Description(`Make the given archived work item active again.`)
+		a.Response(d.OK, func() {
+			a.Media(workItemSingle)
+		})
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("iteration-archive", func() {
+	a.Parent("iteration")
+
+	a.Action("archive", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("archive"),
+		)
+		a.Description(`Archive the given iteration, archived iterations are only listed with filter[archived]=true.`)
+		a.Params(func() {
+			a.Param("workitems", d.Boolean, "Archive the closed work items of the iteration as well")
+		})
+		a.Response(d.OK, func() {
+			a.Media(iterationSingle)
+		})
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("unarchive", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("archive"),
+		)
+		a.Description(`Make the given archived iteration active again, its work items stay archived.`)
+		a.Response(d.OK, func() {
+			a.Media(iterationSingle)
+		})
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
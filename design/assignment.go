@@ -0,0 +1,143 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var assignmentRule = a.Type("AssignmentRule", func() {
+	a.Description(`JSONAPI store for the data of an assignment rule.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("assignmentrules")
+	})
+	a.Attribute("id", d.UUID, "ID of the assignment rule", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", assignmentRuleAttributes)
+	a.Attribute("relationships", assignmentRuleRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var assignmentRuleAttributes = a.Type("AssignmentRuleAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of an assignment rule. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("workitemtype", d.String, "The type of the work items the rule assigns, all types if not set", func() {
+		a.Example("system.bug")
+	})
+	a.Attribute("label", d.String, "The label of the work items the rule assigns, all work items if not set", func() {
+		a.Example("ui")
+	})
+	a.Attribute("assignees", a.ArrayOf(d.UUID), "The identities the work items are assigned to")
+	a.Attribute("round-robin", d.Boolean, "Assign each work item to one of the assignees in turn instead of all of them")
+	a.Attribute("version", d.Integer, "Version for optimistic concurrency control")
+	a.Attribute("created-at", d.DateTime, "When the rule was created")
+})
+
+var assignmentRuleRelationships = a.Type("AssignmentRuleRelations", func() {
+	a.Attribute("project", relationGeneric, "The project the rule assigns the work items of")
+})
+
+var assignmentRuleList = JSONList(
+	"AssignmentRule", "Holds the list of assignment rules",
+	assignmentRule,
+	nil,
+	meta)
+
+var assignmentRuleSingle = JSONSingle(
+	"AssignmentRule", "Holds a single assignment rule",
+	assignmentRule,
+	nil)
+
+var _ = a.Resource("assignment-rule", func() {
+	a.BasePath("/assignment-rules")
+
+	a.Action("show", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Retrieve the assignment rule with the given id (project admins only).")
+		a.Response(d.OK, func() {
+			a.Media(assignmentRuleSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Update the matched type and label and the assignees of the assignment rule (project admins only).")
+		a.Payload(assignmentRuleSingle)
+		a.Response(d.OK, func() {
+			a.Media(assignmentRuleSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Delete the assignment rule (project admins only).")
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("project-assignment-rules", func() {
+	a.Parent("project")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("assignment-rules"),
+		)
+		a.Description("List the assignment rules of the given project in the order they are tried (project admins only).")
+		a.Response(d.OK, func() {
+			a.Media(assignmentRuleList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("assignment-rules"),
+		)
+		a.Description(`Create an assignment rule of the given project (project admins only). The first rule
+matching the type and the labels of a new work item created without assignees assigns it, the new rule is
+tried after the existing ones.`)
+		a.Payload(assignmentRuleSingle)
+		a.Response(d.Created, "/assignment-rules/.*", func() {
+			a.Media(assignmentRuleSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
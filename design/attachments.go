@@ -0,0 +1,147 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var attachment = a.Type("Attachment", func() {
+	a.Description(`JSONAPI store for the data of an attachment.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("attachments")
+	})
+	a.Attribute("id", d.UUID, "ID of the attachment", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", attachmentAttributes)
+	a.Attribute("relationships", attachmentRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var attachmentAttributes = a.Type("AttachmentAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of an attachment. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("url", d.String, "Where the attachment is served", func() {
+		a.Example("https://api.almighty.io/api/attachments/40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("markdown", d.String, "Markdown embedding the attachment, e.g. in a description", func() {
+		a.Example("![image](https://api.almighty.io/api/attachments/40bbdd3d-8b5d-4fd6-ac90-7236b669af04)")
+	})
+	a.Attribute("hash", d.String, "The hex encoded SHA-256 of the content")
+	a.Attribute("content-type", d.String, "The content type of the attachment", func() {
+		a.Example("image/png")
+	})
+	a.Attribute("size", d.Integer, "The size of the content in bytes")
+	a.Attribute("created-at", d.DateTime, "When the content was first uploaded to the project", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Required("url", "markdown", "hash", "content-type", "size", "created-at")
+})
+
+var attachmentRelationships = a.Type("AttachmentRelations", func() {
+	a.Attribute("project", relationGeneric, "This defines the owning project")
+	a.Attribute("creator", relationGeneric, "This defines the identity that first uploaded the content")
+})
+
+var attachmentSingle = JSONSingle(
+	"Attachment", "Holds a single attachment",
+	attachment,
+	nil)
+
+var _ = a.Resource("attachment", func() {
+	a.BasePath("/attachments")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description(`Download the content of the attachment with given id. The content never changes, it can be cached
+forever. Quarantined attachments are not served.`)
+		a.Response(d.OK, "application/octet-stream")
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("project-attachments", func() {
+	a.Parent("project")
+
+	a.Action("paste", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("attachments/paste"),
+		)
+		a.Description(`Upload an image pasted into an editor of the given project, e.g. a screenshot. The body is the binary
+image sent with its image content type, a base64 encoded image or data URL like 'data:image/png;base64,...' sent
+as text, or a multipart/form-data form with the binary image in its 'image' part. Images larger than the
+'attachment.maxsize' setting are refused with Request Entity Too Large. Images are stored once per project:
+uploading the same image again returns the stored attachment with OK instead of Created. The markdown of the response embeds the image. Images containing malware are quarantined,
+images exceeding the storage quota of the project are refused.`)
+		a.Response(d.Created, "/attachments/.*", func() {
+			a.Media(attachmentSingle)
+		})
+		a.Response(d.OK, func() {
+			a.Media(attachmentSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.RequestEntityTooLarge, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.ServiceUnavailable, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
+
+var projectStorageUsage = a.Type("ProjectStorageUsage", func() {
+	a.Attribute("project-id", d.UUID, "ID of the project", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attachments", d.Integer, "The number of attachments of the project, including quarantined ones")
+	a.Attribute("size", d.Integer, "The total size of the attachments in bytes")
+	a.Attribute("available", d.Integer, "The bytes left in the quota of the project, not set if there is no quota")
+	a.Required("project-id", "attachments", "size")
+})
+
+var storageUsage = a.MediaType("application/vnd.storageusage+json", func() {
+	a.TypeName("StorageUsage")
+	a.Description("The storage used by the attachments of the projects")
+	a.Attributes(func() {
+		a.Attribute("quota", d.Integer, "The storage quota of every project in bytes, not set if there is no quota")
+		a.Attribute("projects", a.ArrayOf(projectStorageUsage), "The projects having attachments, most used first")
+		a.Attribute("largest", a.ArrayOf(attachment), "The largest attachments, largest first")
+		a.Required("projects", "largest")
+	})
+	a.View("default", func() {
+		a.Attribute("quota")
+		a.Attribute("projects")
+		a.Attribute("largest")
+	})
+})
+
+var _ = a.Resource("storage", func() {
+	a.BasePath("/storage")
+
+	a.Action("show", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description(`Report the storage used by the attachments of every project against the quota set with the
+'attachment.quota' setting, and the largest attachments (instance admins only).`)
+		a.Params(func() {
+			a.Param("filter[project]", d.UUID, "Only report the usage and the attachments of the given project")
+			a.Param("page[limit]", d.Integer, "Number of largest attachments (1 to 100, defaults to 10)", func() {
+				a.Minimum(1)
+				a.Maximum(100)
+			})
+		})
+		a.Response(d.OK, storageUsage)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,182 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var automationRule = a.Type("AutomationRule", func() {
+	a.Description(`JSONAPI store for the data of an automation rule.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("automationrules")
+	})
+	a.Attribute("id", d.UUID, "ID of the automation rule", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", automationRuleAttributes)
+	a.Attribute("relationships", automationRuleRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var automationRuleAttributes = a.Type("AutomationRuleAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of an automation rule. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("name", d.String, "The name of the rule", func() {
+		a.Example("Reopen bugs commented as regressions")
+	})
+	a.Attribute("enabled", d.Boolean, "Only enabled rules run, true if not set")
+	a.Attribute("trigger", automationTrigger, "The change of a work item the rule runs on")
+	a.Attribute("conditions", a.ArrayOf(automationCondition), "The conditions that must all hold after the change")
+	a.Attribute("actions", a.ArrayOf(automationAction), "The actions done in their order")
+	a.Attribute("version", d.Integer, "Version for optimistic concurrency control")
+	a.Attribute("created-at", d.DateTime, "When the rule was created")
+})
+
+var automationTrigger = a.Type("AutomationTrigger", func() {
+	a.Attribute("kind", d.String, "The kind of the change", func() {
+		a.Enum("state-changed", "label-added", "comment-contains")
+	})
+	a.Attribute("value", d.String, `The state a work item moves to or the label added to it, any if not set. The text
+a comment must contain, ignoring case.`, func() {
+		a.Example("resolved")
+	})
+	a.Required("kind")
+})
+
+var automationCondition = a.Type("AutomationCondition", func() {
+	a.Attribute("field", d.String, "The field of the work item", func() {
+		a.Example("system.priority")
+	})
+	a.Attribute("operator", d.String, "How the field is compared, contains matches text fields containing the value and list fields holding it", func() {
+		a.Enum("equals", "not-equals", "contains", "empty", "not-empty")
+	})
+	a.Attribute("value", d.String, "The value the field is compared with", func() {
+		a.Example("high")
+	})
+	a.Required("field", "operator")
+})
+
+var automationAction = a.Type("AutomationAction", func() {
+	a.Attribute("kind", d.String, `What the action does: set-field sets the field to the value, assign replaces the assignees
+with the identity whose ID is the value, add-comment comments the value on behalf of the creator of the rule and
+call-webhook posts the work item to the URL given as value`, func() {
+		a.Enum("set-field", "assign", "add-comment", "call-webhook")
+	})
+	a.Attribute("field", d.String, "The field set by set-field", func() {
+		a.Example("system.state")
+	})
+	a.Attribute("value", d.String, "The value of the action", func() {
+		a.Example("open")
+	})
+	a.Required("kind", "value")
+})
+
+var automationRuleRelationships = a.Type("AutomationRuleRelations", func() {
+	a.Attribute("project", relationGeneric, "The project the rule runs in")
+	a.Attribute("creator", relationGeneric, "The identity that created the rule")
+})
+
+var automationRuleList = JSONList(
+	"AutomationRule", "Holds the list of automation rules",
+	automationRule,
+	nil,
+	meta)
+
+var automationRuleSingle = JSONSingle(
+	"AutomationRule", "Holds a single automation rule",
+	automationRule,
+	nil)
+
+var _ = a.Resource("automation-rule", func() {
+	a.BasePath("/automation-rules")
+
+	a.Action("show", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Retrieve the automation rule with the given id (project admins only).")
+		a.Response(d.OK, func() {
+			a.Media(automationRuleSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Update the automation rule, e.g. to disable it (project admins only).")
+		a.Payload(automationRuleSingle)
+		a.Response(d.OK, func() {
+			a.Media(automationRuleSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Delete the automation rule (project admins only).")
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("project-automation-rules", func() {
+	a.Parent("project")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("automation-rules"),
+		)
+		a.Description("List the automation rules of the given project in the order they run (project admins only).")
+		a.Response(d.OK, func() {
+			a.Media(automationRuleList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("automation-rules"),
+		)
+		a.Description(`Create an automation rule of the given project (project admins only). Rules run in the
+transaction of the change of a work item, a rule that fails fails the change. The changes made by the
+actions of rules run the rules again, but every rule runs at most once per change.`)
+		a.Payload(automationRuleSingle)
+		a.Response(d.Created, "/automation-rules/.*", func() {
+			a.Media(automationRuleSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
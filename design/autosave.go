@@ -0,0 +1,93 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var autosave = a.Type("Autosave", func() {
+	a.Attribute("fields", a.HashOf(d.String, d.Any), "The autosaved values of the changed fields", func() {
+		a.Example(map[string]interface{}{"system.description": "Steps to reproduce: ..."})
+	})
+	a.Attribute("version", d.Integer, "The version of the work item the changes are based on")
+	a.Attribute("updated-at", d.DateTime, "When changes were last autosaved")
+	a.Required("fields", "version", "updated-at")
+})
+
+var autosavePayload = a.Type("AutosavePayload", func() {
+	a.Attribute("fields", a.HashOf(d.String, d.Any), "The values of the fields changed since the last autosave")
+	a.Required("fields")
+})
+
+var autosaveSingle = a.MediaType("application/vnd.autosave+json", func() {
+	a.TypeName("AutosaveSingle")
+	a.Description("The autosaved changes of a work item")
+	a.Attributes(func() {
+		a.Attribute("data", autosave)
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+	})
+})
+
+var _ = a.Resource("work-item-autosave", func() {
+	a.Parent("workitem")
+
+	a.Action("show", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("autosave"),
+		)
+		a.Description("Show the changes of the given work item the current user autosaved.")
+		a.Response(d.OK, autosaveSingle)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("save", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PUT("autosave"),
+		)
+		a.Description(`Autosave changes of the title and description of the given work item, e.g. while the current user is
+typing. Autosaving doesn't change the work item, doesn't create revisions and doesn't notify anybody, so it can be
+called often with the fields changed since the last call. The changes are applied as one revision by the apply action
+or when the draft is published, changes based on an older version of the work item are replaced.`)
+		a.Payload(autosavePayload)
+		a.Response(d.OK, autosaveSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("apply", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("autosave/apply"),
+		)
+		a.Description(`Save the given work item with the autosaved changes of the current user as one revision and discard
+them. Fails with a version conflict if the work item was changed since the changes were started.`)
+		a.Response(d.OK, func() {
+			a.Media(workItemSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("discard", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("autosave"),
+		)
+		a.Description("Discard the autosaved changes of the given work item of the current user.")
+		a.Response(d.NoContent)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
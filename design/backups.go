@@ -0,0 +1,123 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var backupRun = a.Type("Backup", func() {
+	a.Description(`JSONAPI store for the data of a backup, or of the restore of one.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("backups")
+	})
+	a.Attribute("id", d.UUID, "ID of the backup", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", backupRunAttributes)
+	a.Required("type", "attributes")
+})
+
+var backupRunAttributes = a.Type("BackupAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a backup. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("operation", d.String, "Whether the run backs up or restores", func() {
+		a.Enum("backup", "restore")
+	})
+	a.Attribute("project", d.UUID, "The backed up project, not set for the whole instance")
+	a.Attribute("backup", d.UUID, "The backup a restore reads")
+	a.Attribute("object", d.String, "The name of the backup in the object store", func() {
+		a.Example("instance/40bbdd3d-8b5d-4fd6-ac90-7236b669af04.jsonl.gz")
+	})
+	a.Attribute("status", d.String, "The status of the run", func() {
+		a.Enum("pending", "running", "succeeded", "failed")
+	})
+	a.Attribute("tables", d.Integer, "The number of tables the run goes through")
+	a.Attribute("tables-done", d.Integer, "The number of tables the run went through")
+	a.Attribute("rows", d.Integer, "The number of rows the run went through")
+	a.Attribute("error", d.String, "Why the run failed")
+	a.Attribute("created-at", d.DateTime, "When the run was requested")
+	a.Attribute("finished-at", d.DateTime, "When the run succeeded or failed")
+})
+
+var backupRunList = JSONList(
+	"Backup", "Holds the list of backups and restores",
+	backupRun,
+	nil,
+	nil)
+
+var backupRunSingle = JSONSingle(
+	"Backup", "Holds a single backup or restore",
+	backupRun,
+	nil)
+
+var _ = a.Resource("backups", func() {
+	a.BasePath("/backups")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the backups and restores, most recent first (instance admins only).")
+		a.Params(func() {
+			a.Param("filter[project]", d.UUID, "Only list the runs of the given project")
+		})
+		a.Response(d.OK, func() {
+			a.Media(backupRunList)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("show", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.UUID, "id")
+		})
+		a.Description("Retrieve the backup or restore with its progress (instance admins only).")
+		a.Response(d.OK, func() {
+			a.Media(backupRunSingle)
+		})
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description(`Take a consistent backup of the project given in the attributes, or of the whole
+instance, in a background job (instance admins only).`)
+		a.Payload(backupRunSingle)
+		a.Response(d.Accepted, func() {
+			a.Media(backupRunSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("restore", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:id/restore"),
+		)
+		a.Params(func() {
+			a.Param("id", d.UUID, "id")
+		})
+		a.Description(`Restore the backup in a background job (instance admins only). The restore fails if
+any restored row exists already, a project is restored into an instance it doesn't exist in.`)
+		a.Response(d.Accepted, func() {
+			a.Media(backupRunSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,98 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var brandingPayload = a.Type("BrandingPayload", func() {
+	a.Attribute("product-name", d.String, "The name of the product", func() {
+		a.Example("ALMighty")
+	})
+	a.Attribute("logo-url", d.String, "URL of the logo", func() {
+		a.Example("https://example.com/logo.svg")
+	})
+	a.Attribute("accent-color", d.String, "Hex color of the accents", func() {
+		a.Example("#0088ce")
+	})
+	a.Attribute("support-url", d.String, "URL of the support pages")
+	a.Attribute("docs-url", d.String, "URL of the documentation")
+	a.Attribute("support-email", d.String, "Address users write to for help")
+})
+
+var branding = a.MediaType("application/vnd.branding+json", func() {
+	a.TypeName("Branding")
+	a.Description("The branding settings of the deployment or of a project, missing ones aren't set")
+	a.Reference(brandingPayload)
+	a.Attributes(func() {
+		a.Attribute("product-name")
+		a.Attribute("logo-url")
+		a.Attribute("accent-color")
+		a.Attribute("support-url")
+		a.Attribute("docs-url")
+		a.Attribute("support-email")
+		a.Required("product-name", "accent-color")
+	})
+	a.View("default", func() {
+		a.Attribute("product-name")
+		a.Attribute("logo-url")
+		a.Attribute("accent-color")
+		a.Attribute("support-url")
+		a.Attribute("docs-url")
+		a.Attribute("support-email")
+	})
+})
+
+var _ = a.Resource("branding", func() {
+	a.BasePath("/branding")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET(""),
+		)
+		a.Params(func() {
+			a.Param("project", d.UUID, "ID of the project, the settings of the deployment if missing")
+		})
+		a.Description(`Show the branding settings of the project or of the deployment, the settings a project doesn't
+override are the ones of the deployment.`)
+		a.Response(d.OK, branding)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PUT(""),
+		)
+		a.Params(func() {
+			a.Param("project", d.UUID, "ID of the project, the settings of the deployment if missing")
+		})
+		a.Description(`Replace the branding settings of the deployment (instance admins only) or the overrides of the
+project (project admins only), missing settings are inherited.`)
+		a.Payload(brandingPayload)
+		a.Response(d.OK, branding)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("reset", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE(""),
+		)
+		a.Params(func() {
+			a.Param("project", d.UUID, "ID of the project")
+			a.Required("project")
+		})
+		a.Description("Remove the branding overrides of the project (project admins only).")
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,75 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var calendarFeed = a.MediaType("application/vnd.calendarfeed+json", func() {
+	a.TypeName("CalendarFeed")
+	a.Description("The secret URL of an ICS calendar feed")
+	a.Attributes(func() {
+		a.Attribute("url", d.String, "The URL calendar apps subscribe to, anyone knowing it can read the feed", func() {
+			a.Example("https://api.almighty.io/api/calendar/2f8b1d0c6e9a4b7f8e3d5c1a0b9f8e7d6c5b4a39")
+		})
+		a.Attribute("project", d.UUID, "The project of the feed, not set for the feed of the user")
+		a.Attribute("createdAt", d.DateTime, "When the token of the feed was generated")
+		a.Required("url", "createdAt")
+	})
+	a.View("default", func() {
+		a.Attribute("url")
+		a.Attribute("project")
+		a.Attribute("createdAt")
+	})
+})
+
+var _ = a.Resource("calendar", func() {
+	a.BasePath("/calendar")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("/:token"),
+		)
+		a.Description(`Get the ICS calendar feed with the given token. The feed of a user lists the iterations and
+the release target dates of the projects the user administrates or has work items assigned in,
+the feed of a project the ones of the project. The dates are all day events in the timezone of
+the user. The token authenticates the request, calendar apps can't send a bearer token.`)
+		a.Params(func() {
+			a.Param("token", d.String, "The secret token of the feed")
+		})
+		a.Response(d.OK, "text/calendar")
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description(`Generate the secret URL of the calendar feed of the current user, or of the user for the
+given project. The previous URL of the feed stops working.`)
+		a.Params(func() {
+			a.Param("project", d.UUID, "Generate the feed of the given project")
+		})
+		a.Response(d.OK, calendarFeed)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE(""),
+		)
+		a.Description("Revoke the calendar feed of the current user, or of the user for the given project.")
+		a.Params(func() {
+			a.Param("project", d.UUID, "Revoke the feed of the given project")
+		})
+		a.Response(d.NoContent)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
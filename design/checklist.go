@@ -0,0 +1,39 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var checklistItemPayload = a.Type("ChecklistItemPayload", func() {
+	a.Attribute("done", d.Boolean, "Whether the item is done", func() {
+		a.Example(true)
+	})
+	a.Required("done")
+})
+
+var _ = a.Resource("work-item-checklist", func() {
+	a.Parent("workitem")
+
+	a.Action("check", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("checklists/:field/items/:item"),
+		)
+		a.Description(`Mark an item of a checklist field of the given work item as done or not.
+The work item version is not needed and increased, the completion of the checklist is returned
+in the "<field>.completion" attribute.`)
+		a.Params(func() {
+			a.Param("field", d.String, "Name of the checklist field")
+			a.Param("item", d.String, "ID of the checklist item")
+		})
+		a.Payload(checklistItemPayload)
+		a.Response(d.OK, func() {
+			a.Media(workItemSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
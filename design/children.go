@@ -0,0 +1,31 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var _ = a.Resource("work-item-children", func() {
+	a.Parent("workitem")
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("children"),
+		)
+		a.Description(`Create a child of the given work item and link it to the work item with a tree link type in one go.
+The child is created in the project, iteration and release of the parent unless they are given. Parents show the
+number of children per state and the sum of their points in the "children" attribute.`)
+		a.Params(func() {
+			a.Param("link_type", d.String, "ID of the tree link type to link the child with, needed when several tree link types link the work item types")
+		})
+		a.Payload(workItemSingle)
+		a.Response(d.Created, "/workitems/.*", func() {
+			a.Media(workItemSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
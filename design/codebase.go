@@ -0,0 +1,104 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var codeChange = a.Type("CodeChange", func() {
+	a.Description(`JSONAPI store for the data of a code change (commit or pull request) associated with a work item.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("codechanges")
+	})
+	a.Attribute("id", d.UUID, "ID of code change", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", codeChangeAttributes)
+	a.Attribute("relationships", codeChangeRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var codeChangeAttributes = a.Type("CodeChangeAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a code change. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("kind", d.String, "Whether the change is a commit or a pull request", func() {
+		a.Enum("commit", "pull-request")
+	})
+	a.Attribute("provider", d.String, "The service that reported the change", func() {
+		a.Enum("github", "gitlab")
+	})
+	a.Attribute("repository", d.String, "The URL of the repository the change was made in", func() {
+		a.Example("https://github.com/almighty/almighty-core")
+	})
+	a.Attribute("ref", d.String, "The commit SHA or the pull request number", func() {
+		a.Example("42")
+	})
+	a.Attribute("title", d.String, "The commit message summary or the pull request title", func() {
+		a.Example("Fix for the login bug")
+	})
+	a.Attribute("url", d.String, "The URL of the change", func() {
+		a.Example("https://github.com/almighty/almighty-core/pull/42")
+	})
+	a.Attribute("status", d.String, "The status of the change", func() {
+		a.Enum("open", "merged", "closed")
+	})
+	a.Attribute("created-at", d.DateTime, "When the change was first associated", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("updated-at", d.DateTime, "When the status of the change was last updated", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+})
+
+var codeChangeRelationships = a.Type("CodeChangeRelations", func() {
+	a.Attribute("workitem", relationGeneric, "This defines the work item the change is associated with")
+})
+
+var codeChangeList = JSONList(
+	"CodeChange", "Holds the list of code changes",
+	codeChange,
+	nil,
+	meta)
+
+var _ = a.Resource("webhooks", func() {
+	a.BasePath("/webhooks")
+
+	a.Action("github", func() {
+		a.Routing(
+			a.POST("/github"),
+		)
+		a.Description(`Receive a GitHub "push" or "pull_request" webhook and associate the changes with the referenced work items.`)
+		a.Response(d.OK)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("gitlab", func() {
+		a.Routing(
+			a.POST("/gitlab"),
+		)
+		a.Description(`Receive a GitLab "Push Hook" or "Merge Request Hook" webhook and associate the changes with the referenced work items.`)
+		a.Response(d.OK)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("work-item-codebase", func() {
+	a.Parent("workitem")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("codebase"),
+		)
+		a.Description("List the commits and pull requests associated with the given work item")
+		a.Response(d.OK, func() {
+			a.Media(codeChangeList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,193 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var codebase = a.Type("Codebase", func() {
+	a.Description(`JSONAPI store for the data of a codebase.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("codebases")
+	})
+	a.Attribute("id", d.UUID, "ID of codebase", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", codebaseAttributes)
+	a.Attribute("relationships", codebaseRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var codebaseAttributes = a.Type("CodebaseAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a codebase. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("url", d.String, "The URL of the repository", func() {
+		a.Example("https://github.com/almighty/almighty-core")
+	})
+	a.Attribute("branch", d.String, "The branch that is built", func() {
+		a.Example("master")
+	})
+	a.Attribute("build-status", d.String, "The status of the latest build (read-only)", func() {
+		a.Enum("pending", "running", "success", "failure", "deployed")
+	})
+	a.Attribute("build-revision", d.String, "The revision of the latest build (read-only)", func() {
+		a.Example("6f2f7ac4e4c50ec7a1bc0d3f4f9b8a3e1c6d0b5a")
+	})
+	a.Attribute("build-url", d.String, "The URL of the latest build in CI (read-only)", func() {
+		a.Example("https://ci.centos.org/job/almighty-core/42")
+	})
+	a.Attribute("shipped", d.Boolean, "Whether the latest build has been deployed (read-only)")
+	a.Attribute("created-at", d.DateTime, "When the codebase was created", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+})
+
+var codebaseRelationships = a.Type("CodebaseRelations", func() {
+	a.Attribute("project", relationGeneric, "This defines the owning project")
+})
+
+var codebaseBuild = a.Type("CodebaseBuild", func() {
+	a.Description(`JSONAPI store for the data of a build reported by CI.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("builds")
+	})
+	a.Attribute("attributes", codebaseBuildAttributes)
+	a.Required("type", "attributes")
+})
+
+var codebaseBuildAttributes = a.Type("CodebaseBuildAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a build. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("status", d.String, "The status of the build", func() {
+		a.Enum("pending", "running", "success", "failure", "deployed")
+	})
+	a.Attribute("revision", d.String, "The revision that was built", func() {
+		a.Example("6f2f7ac4e4c50ec7a1bc0d3f4f9b8a3e1c6d0b5a")
+	})
+	a.Attribute("url", d.String, "The URL of the build in CI", func() {
+		a.Example("https://ci.centos.org/job/almighty-core/42")
+	})
+	a.Required("status")
+})
+
+var codebaseList = JSONList(
+	"Codebase", "Holds the list of codebases",
+	codebase,
+	nil,
+	meta)
+
+var codebaseSingle = JSONSingle(
+	"Codebase", "Holds a single codebase",
+	codebase,
+	nil)
+
+var codebaseBuildSingle = JSONSingle(
+	"CodebaseBuild", "Holds a single build",
+	codebaseBuild,
+	nil)
+
+var _ = a.Resource("codebase", func() {
+	a.BasePath("/codebases")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Retrieve codebase with given id.")
+		a.Response(d.OK, func() {
+			a.Media(codebaseSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("create-build", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:id/builds"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Report the build or deploy status of the codebase with given id (used by CI with the token of a project admin).")
+		a.Payload(codebaseBuildSingle)
+		a.Response(d.OK, func() {
+			a.Media(codebaseSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("project-codebases", func() {
+	a.Parent("project")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("codebases"),
+		)
+		a.Description("List the codebases of the given project.")
+		a.Response(d.OK, func() {
+			a.Media(codebaseList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("codebases"),
+		)
+		a.Description("Add a codebase to the given project.")
+		a.Payload(codebaseSingle)
+		a.Response(d.Created, "/codebases/.*", func() {
+			a.Media(codebaseSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("work-item-codebases", func() {
+	a.Parent("workitem")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("codebases"),
+		)
+		a.Description("List the codebases the given work item is associated with, including their latest build status.")
+		a.Response(d.OK, func() {
+			a.Media(codebaseList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("codebases/:codebaseID"),
+		)
+		a.Params(func() {
+			a.Param("codebaseID", d.String, "codebaseID")
+		})
+		a.Description("Associate the given work item with a codebase.")
+		a.Response(d.OK, func() {
+			a.Media(codebaseSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
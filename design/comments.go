@@ -36,6 +36,35 @@ var commentAttributes = a.Type("CommentAttributes", func() {
 	a.Attribute("body", d.String, "The comment body", func() {
 		a.Example("This is really interesting")
 	})
+	a.Attribute("reactions", a.ArrayOf(reactionSummary), "The emoji reactions to the comment (read-only)")
+	a.Attribute("pending-review", d.Boolean, "True if the comment is held for review by the project admins (read-only)")
+})
+
+var reactionSummary = a.Type("ReactionSummary", func() {
+	a.Description("The reactions to a comment with a single emoji")
+	a.Attribute("emoji", d.String, "The emoji", func() {
+		a.Example("+1")
+	})
+	a.Attribute("count", d.Integer, "The number of identities that reacted with the emoji")
+	a.Attribute("reacted", d.Boolean, "True if the current user reacted with the emoji")
+	a.Required("emoji", "count", "reacted")
+})
+
+var createReaction = a.Type("CreateReaction", func() {
+	a.Description(`JSONAPI store for the data of a reaction to a comment.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("reactions")
+	})
+	a.Attribute("attributes", createReactionAttributes)
+	a.Required("type", "attributes")
+})
+
+var createReactionAttributes = a.Type("CreateReactionAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" for creating a reaction. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("emoji", d.String, "The emoji to react with", func() {
+		a.Enum("+1", "-1", "laugh", "hooray", "confused", "heart")
+	})
+	a.Required("emoji")
 })
 
 var createCommentAttributes = a.Type("CreateCommentAttributes", func() {
@@ -105,6 +134,18 @@ var createSingleComment = a.MediaType("application/vnd.comments-create+json", fu
 	})
 })
 
+var createSingleReaction = a.MediaType("application/vnd.reactions-create+json", func() {
+	a.TypeName("CreateSingleReaction")
+	a.Description("Holds the create data for a reaction")
+	a.Attribute("data", createReaction)
+
+	a.Required("data")
+
+	a.View("default", func() {
+		a.Attribute("data")
+	})
+})
+
 var _ = a.Resource("comments", func() {
 	a.BasePath("/comments")
 
@@ -170,3 +211,41 @@ var _ = a.Resource("work-item-comments", func() {
 		a.Response(d.NotFound, JSONAPIErrors)
 	})
 })
+
+var _ = a.Resource("comment-reactions", func() {
+	a.Parent("comments")
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("reactions"),
+		)
+		a.Description("React to the given comment with an emoji, reacting again with the same emoji has no effect")
+		a.Payload(createSingleReaction)
+		a.Response(d.OK, func() {
+			a.Media(commentSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("reactions/:emoji"),
+		)
+		a.Params(func() {
+			a.Param("emoji", d.String, "The emoji of the reaction to remove")
+		})
+		a.Description("Remove the reaction of the current user with the given emoji from the comment")
+		a.Response(d.OK, func() {
+			a.Media(commentSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,212 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var dashboard = a.Type("Dashboard", func() {
+	a.Description(`JSONAPI store for the data of a dashboard.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("dashboards")
+	})
+	a.Attribute("id", d.UUID, "ID of the dashboard", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", dashboardAttributes)
+	a.Attribute("relationships", dashboardRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var dashboardAttributes = a.Type("DashboardAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a dashboard. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("name", d.String, "The name of the dashboard", func() {
+		a.Example("Sprint overview")
+	})
+	a.Attribute("widgets", a.ArrayOf(dashboardWidget), "The widgets in their display order")
+	a.Attribute("version", d.Integer, "Version for optimistic concurrency control")
+})
+
+var dashboardWidget = a.Type("DashboardWidget", func() {
+	a.Attribute("id", d.String, "Identifies the widget within the dashboard", func() {
+		a.Example("open-bugs")
+	})
+	a.Attribute("kind", d.String, "What the widget shows", func() {
+		a.Enum("count", "chart", "activity")
+	})
+	a.Attribute("title", d.String, "The title of the widget")
+	a.Attribute("filter", d.String, "A query language expression restricting the work items of the widget", func() {
+		a.Example(`{"system.state":"open"}`)
+	})
+	a.Attribute("group-by", d.String, `The field a chart counts the work items by: "type", "system.state", "system.priority",
+"system.severity", "system.iteration", "system.release", "system.labels" or "system.assignees"`)
+	a.Attribute("limit", d.Integer, "The number of work items an activity widget lists (at most 50, defaults to 10)")
+	a.Required("id", "kind")
+})
+
+var dashboardRelationships = a.Type("DashboardRelations", func() {
+	a.Attribute("owner", relationGeneric, "The identity owning a personal dashboard")
+	a.Attribute("project", relationGeneric, "The project sharing the dashboard")
+})
+
+var dashboardList = JSONList(
+	"Dashboard", "Holds the list of dashboards",
+	dashboard,
+	nil,
+	meta)
+
+var dashboardSingle = JSONSingle(
+	"Dashboard", "Holds a single dashboard",
+	dashboard,
+	nil)
+
+var dashboardWidgetData = a.Type("DashboardWidgetData", func() {
+	a.Attribute("count", d.Integer, "The number of matching work items of a count widget")
+	a.Attribute("groups", a.HashOf(d.String, d.Integer), "The number of matching work items per value of a chart")
+	a.Attribute("items", a.ArrayOf(workItem2), "The latest changed matching work items of an activity widget")
+})
+
+var dashboardData = a.MediaType("application/vnd.dashboarddata+json", func() {
+	a.TypeName("DashboardData")
+	a.Description("The data of all widgets of a dashboard")
+	a.Attributes(func() {
+		a.Attribute("data", a.HashOf(d.String, dashboardWidgetData), "The data of every widget by its ID")
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+	})
+})
+
+var _ = a.Resource("dashboard", func() {
+	a.BasePath("/dashboards")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the personal dashboards of the authenticated user.")
+		a.Response(d.OK, func() {
+			a.Media(dashboardList)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description("Create a personal dashboard of the authenticated user.")
+		a.Payload(dashboardSingle)
+		a.Response(d.Created, "/dashboards/.*", func() {
+			a.Media(dashboardSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Retrieve the dashboard with the given id, personal dashboards are only visible to their owner.")
+		a.Response(d.OK, func() {
+			a.Media(dashboardSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("data", func() {
+		a.Routing(
+			a.GET("/:id/data"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Resolve all widgets of the dashboard with the given id in one request.")
+		a.Response(d.OK, func() {
+			a.Media(dashboardData)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Update the name and the widgets of the dashboard (its owner or the project admins only).")
+		a.Payload(dashboardSingle)
+		a.Response(d.OK, func() {
+			a.Media(dashboardSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Delete the dashboard (its owner or the project admins only).")
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("project-dashboards", func() {
+	a.Parent("project")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("dashboards"),
+		)
+		a.Description("List the dashboards shared in the given project.")
+		a.Response(d.OK, func() {
+			a.Media(dashboardList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("dashboards"),
+		)
+		a.Description("Create a dashboard shared in the given project (project admins only).")
+		a.Payload(dashboardSingle)
+		a.Response(d.Created, "/dashboards/.*", func() {
+			a.Media(dashboardSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,81 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var deployment = a.Type("Deployment", func() {
+	a.Description(`JSONAPI store for the data of a deployment.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("deployments")
+	})
+	a.Attribute("id", d.UUID, "ID of deployment", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", deploymentAttributes)
+	a.Attribute("relationships", deploymentRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var deploymentAttributes = a.Type("DeploymentAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a deployment. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("environment", d.String, "The environment that was deployed to", func() {
+		a.Example("staging")
+	})
+	a.Attribute("revision", d.String, "The revision that was deployed", func() {
+		a.Example("6f2f7ac4e4c50ec7a1bc0d3f4f9b8a3e1c6d0b5a")
+	})
+	a.Attribute("url", d.String, "The URL of the deployment in the CD pipeline", func() {
+		a.Example("https://ci.centos.org/job/almighty-core-deploy/42")
+	})
+	a.Attribute("created-at", d.DateTime, "When the deployment was recorded", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+})
+
+var deploymentRelationships = a.Type("DeploymentRelations", func() {
+	a.Attribute("workitems", relationGenericList, "This defines the work items included in the deployment")
+})
+
+var deploymentSingle = JSONSingle(
+	"Deployment", "Holds a single deployment",
+	deployment,
+	nil)
+
+var _ = a.Resource("deployment", func() {
+	a.BasePath("/deployments")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Retrieve deployment with given id.")
+		a.Response(d.OK, func() {
+			a.Media(deploymentSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description("Record a deployment and the work items it included (used by CD pipelines).")
+		a.Payload(deploymentSingle)
+		a.Response(d.Created, "/deployments/.*", func() {
+			a.Media(deploymentSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
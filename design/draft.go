@@ -0,0 +1,27 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var _ = a.Resource("work-item-draft", func() {
+	a.Parent("workitem")
+
+	a.Action("publish", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("publish"),
+		)
+		a.Description(`Publish the given draft. Work items created with system.draft set to true are drafts, they are only
+visible to their creator, only listed with filter[drafts]=true and don't trigger notifications or automation rules
+until they are published. Drafts not changed for a while are deleted.`)
+		a.Response(d.OK, func() {
+			a.Media(workItemSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
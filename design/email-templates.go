@@ -0,0 +1,130 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var emailTemplate = a.Type("EmailTemplate", func() {
+	a.Attribute("event", d.String, "The event the emails are sent for", func() {
+		a.Example("workitem.stale")
+	})
+	a.Attribute("locale", d.String, "The locale of the recipients, missing for the template used for all other locales", func() {
+		a.Example("de")
+	})
+	a.Attribute("subject", d.String, "Go text/template of the subject", func() {
+		a.Example("[ALM] {{.Subject}}")
+	})
+	a.Attribute("body", d.String, "Go text/template of the body", func() {
+		a.Example("Hello {{.Recipient}}, see {{.URL}}")
+	})
+	a.Attribute("custom", d.Boolean, "False for the built-in default")
+	a.Required("event", "subject", "body", "custom")
+})
+
+var emailTemplatePayload = a.Type("EmailTemplatePayload", func() {
+	a.Attribute("locale", d.String, "The locale of the recipients, missing for the template used for all other locales")
+	a.Attribute("subject", d.String, "Go text/template of the subject")
+	a.Attribute("body", d.String, "Go text/template of the body")
+	a.Required("subject", "body")
+})
+
+var emailTemplatePreviewPayload = a.Type("EmailTemplatePreviewPayload", func() {
+	a.Attribute("locale", d.String, "The locale of the stored template to preview")
+	a.Attribute("subject", d.String, "Go text/template of the subject to preview instead of the stored one")
+	a.Attribute("body", d.String, "Go text/template of the body to preview instead of the stored one")
+})
+
+var emailTemplateList = a.MediaType("application/vnd.emailtemplates+json", func() {
+	a.TypeName("EmailTemplateList")
+	a.Description("The email templates of the events")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(emailTemplate))
+		a.Attribute("variables", a.ArrayOf(d.String), "The variables of the templates")
+		a.Required("data", "variables")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Attribute("variables")
+	})
+})
+
+var emailPreview = a.MediaType("application/vnd.emailpreview+json", func() {
+	a.TypeName("EmailPreview")
+	a.Description("An email rendered with sample data")
+	a.Attributes(func() {
+		a.Attribute("subject", d.String)
+		a.Attribute("body", d.String)
+		a.Required("subject", "body")
+	})
+	a.View("default", func() {
+		a.Attribute("subject")
+		a.Attribute("body")
+	})
+})
+
+var _ = a.Resource("email-templates", func() {
+	a.BasePath("/email-templates")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description(`List the default email template of every event followed by the customized ones, with the
+variables of the templates (instance admins only).`)
+		a.Response(d.OK, emailTemplateList)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PUT("/:event"),
+		)
+		a.Params(func() {
+			a.Param("event", d.String, "The event the emails are sent for")
+		})
+		a.Description("Replace the email template of the event for the locale (instance admins only).")
+		a.Payload(emailTemplatePayload)
+		a.Response(d.OK, emailTemplateList)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("reset", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:event"),
+		)
+		a.Params(func() {
+			a.Param("event", d.String, "The event the emails are sent for")
+			a.Param("locale", d.String, "The locale of the template, the one for all other locales if missing")
+		})
+		a.Description("Remove the customized email template of the event for the locale (instance admins only).")
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("preview", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:event/preview"),
+		)
+		a.Params(func() {
+			a.Param("event", d.String, "The event the emails are sent for")
+		})
+		a.Description(`Render the email template of the event with sample data and the branding of the deployment, the
+given subject and body replace the stored ones (instance admins only).`)
+		a.Payload(emailTemplatePreviewPayload)
+		a.Response(d.OK, emailPreview)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
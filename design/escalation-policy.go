@@ -0,0 +1,94 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var escalationPolicy = a.Type("EscalationPolicy", func() {
+	a.Description(`JSONAPI store for the data of the escalation policy of a project.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("escalationpolicies")
+	})
+	a.Attribute("id", d.UUID, "ID of the project", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", escalationPolicyAttributes)
+	a.Attribute("relationships", escalationPolicyRelationships)
+	a.Required("type", "attributes")
+})
+
+var escalationPolicyAttributes = a.Type("EscalationPolicyAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of an escalation policy. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("severity", d.String, "The severity of the work items that are escalated", func() {
+		a.MinLength(1)
+		a.Example("1")
+	})
+	a.Attribute("unassigned-hours", d.Integer, "Hours after their creation open work items without assignees are escalated, not set doesn't escalate unassigned work items", func() {
+		a.Minimum(1)
+		a.Example(4)
+	})
+	a.Attribute("untouched-hours", d.Integer, "Hours without updates or comments after which open work items are escalated, not set doesn't escalate untouched work items", func() {
+		a.Minimum(1)
+		a.Example(24)
+	})
+	a.Attribute("raise-priority", d.String, "The priority escalated work items are set to, not set leaves their priority", func() {
+		a.Example("critical")
+	})
+	a.Required("severity")
+})
+
+var escalationPolicyRelationships = a.Type("EscalationPolicyRelations", func() {
+	a.Attribute("contact", relationGeneric, "The secondary contact notified of escalated work items")
+})
+
+var escalationPolicySingle = JSONSingle(
+	"EscalationPolicy", "Holds the escalation policy of a project",
+	escalationPolicy,
+	nil)
+
+var _ = a.Resource("project-escalation-policy", func() {
+	a.Parent("project")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("escalation-policy"),
+		)
+		a.Description("Retrieve the policy escalating the high-severity work items of the project.")
+		a.Response(d.OK, func() {
+			a.Media(escalationPolicySingle)
+		})
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PUT("escalation-policy"),
+		)
+		a.Description(`Set the policy escalating the high-severity work items of the project (project admins only).
+Escalated work items are raised to the given priority, and the contact and the assignees are notified. A
+work item is escalated once, until it is assigned or touched again.`)
+		a.Payload(escalationPolicySingle)
+		a.Response(d.OK, func() {
+			a.Media(escalationPolicySingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("escalation-policy"),
+		)
+		a.Description("Stop escalating the work items of the project (project admins only).")
+		a.Response(d.OK)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
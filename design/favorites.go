@@ -0,0 +1,72 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var _ = a.Resource("favorites", func() {
+	a.BasePath("/user")
+
+	a.Action("recent", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("recent"),
+		)
+		a.Description("List the work items the authenticated user viewed recently, the latest first.")
+		a.Params(func() {
+			a.Param("page[limit]", d.Integer, "Number of work items (1 to 50, defaults to 10)")
+		})
+		a.Response(d.OK, func() {
+			a.Media(workItemList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("pinned", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("pins"),
+		)
+		a.Description("List the work items the authenticated user pinned, the latest pin first.")
+		a.Response(d.OK, func() {
+			a.Media(workItemList)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("pin", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PUT("pins/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "ID of the work item")
+		})
+		a.Description("Pin the work item for the authenticated user, pinning it again has no effect.")
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("unpin", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("pins/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "ID of the work item")
+		})
+		a.Description("Remove the work item from the pins of the authenticated user.")
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
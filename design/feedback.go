@@ -0,0 +1,30 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var _ = a.Resource("project-feedback", func() {
+	a.Parent("project")
+
+	a.Action("create", func() {
+		a.Routing(
+			a.POST("feedback"),
+		)
+		a.Description(`Create a work item in the given public project without logging in. If the project requires a
+challenge the response token of the solved challenge must be sent in the X-Challenge-Token header.
+Anonymous work items are always held for review by the project admins.`)
+		a.Headers(func() {
+			a.Header("X-Challenge-Token", d.String, "Response token of the challenge solved by the contributor")
+		})
+		a.Payload(workItemSingle)
+		a.Response(d.Created, "/workitems/.*", func() {
+			a.Media(workItemSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
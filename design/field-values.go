@@ -0,0 +1,76 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var fieldValues = a.Type("FieldValues", func() {
+	a.Description(`JSONAPI store for the allowed values of an ordered work item field in a project.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("fieldvalues")
+	})
+	a.Attribute("id", d.String, "Name of the work item field", func() {
+		a.Example("system.priority")
+	})
+	a.Attribute("attributes", fieldValuesAttributes)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var fieldValuesAttributes = a.Type("FieldValuesAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of field values. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("values", a.ArrayOf(d.String), "The allowed values ordered from the most to the least important one", func() {
+		a.Example([]string{"critical", "high", "medium", "low"})
+	})
+	a.Attribute("version", d.Integer, "Version for optimistic concurrency control, 0 if the project uses the default values")
+})
+
+var fieldValuesSingle = JSONSingle(
+	"FieldValues", "Holds the allowed values of a field",
+	fieldValues,
+	nil)
+
+var _ = a.Resource("project-field-values", func() {
+	a.Parent("project")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("fields/:field/values"),
+		)
+		a.Params(func() {
+			a.Param("field", d.String, "Name of the work item field", func() {
+				a.Enum("system.priority", "system.severity")
+			})
+		})
+		a.Description("Retrieve the allowed values of an ordered work item field in the given project.")
+		a.Response(d.OK, func() {
+			a.Media(fieldValuesSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("fields/:field/values"),
+		)
+		a.Params(func() {
+			a.Param("field", d.String, "Name of the work item field", func() {
+				a.Enum("system.priority", "system.severity")
+			})
+		})
+		a.Description(`Customize the allowed values of an ordered work item field in the given project. The order of
+the values defines how work items are sorted by the field. Values still used by work items of the project can not be removed.`)
+		a.Payload(fieldValuesSingle)
+		a.Response(d.OK, func() {
+			a.Media(fieldValuesSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
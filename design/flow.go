@@ -0,0 +1,52 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var cumulativeFlowDay = a.Type("CumulativeFlowDay", func() {
+	a.Attribute("day", d.DateTime, "The start of the day (UTC)")
+	a.Attribute("counts", a.ArrayOf(d.Integer), "The number of work items at the end of the day in the order of the states", func() {
+		a.Example([]int{4, 7, 2, 30})
+	})
+	a.Required("day", "counts")
+})
+
+var cumulativeFlow = a.MediaType("application/vnd.cumulativeflow+json", func() {
+	a.TypeName("CumulativeFlow")
+	a.Description("The number of work items per state of a project for every day of a range")
+	a.Attributes(func() {
+		a.Attribute("states", a.ArrayOf(d.String), "The states in workflow order", func() {
+			a.Example([]string{"new", "open", "in progress", "closed"})
+		})
+		a.Attribute("days", a.ArrayOf(cumulativeFlowDay), "The days with a snapshot, oldest first")
+		a.Required("states", "days")
+	})
+	a.View("default", func() {
+		a.Attribute("states")
+		a.Attribute("days")
+	})
+})
+
+var _ = a.Resource("project-flow", func() {
+	a.Parent("project")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("flow"),
+		)
+		a.Description(`Retrieve the cumulative flow diagram of the project, built from the daily snapshots
+of the number of work items per state. Days before the first snapshot are not included.`)
+		a.Params(func() {
+			a.Param("from", d.DateTime, "The first day of the diagram (defaults to 30 days before to)")
+			a.Param("to", d.DateTime, "The last day of the diagram (defaults to today)")
+		})
+		a.Response(d.OK, func() {
+			a.Media(cumulativeFlow)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,46 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+// graphParams defines the parameters selecting the work items and links of a
+// graph
+func graphParams() {
+	a.Params(func() {
+		a.Param("filter", d.String, "a query language expression restricting the set of work items")
+		a.Param("filter[project]", d.UUID, "Work Items belonging to the given project")
+		a.Param("filter[link-type]", d.UUID, "Only draw the links of the given link type")
+	})
+}
+
+var _ = a.Resource("work-item-graph", func() {
+	a.BasePath("/workitems/graph")
+
+	a.Action("dot", func() {
+		a.Routing(
+			a.GET("/dot"),
+		)
+		a.Description(`Render the work items matching the filters and the links between them as directed graph in the
+DOT language of Graphviz, e.g. to visualize the structure of epics or webs of dependencies. Links to work items
+not matching the filters are left out. At most 1000 work items can be rendered.`)
+		graphParams()
+		a.Response(d.OK, "text/vnd.graphviz")
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("graphml", func() {
+		a.Routing(
+			a.GET("/graphml"),
+		)
+		a.Description(`Render the work items matching the filters and the links between them as directed GraphML graph
+for tools like yEd or Gephi. Links to work items not matching the filters are left out. At most 1000 work items can
+be rendered.`)
+		graphParams()
+		a.Response(d.OK, "application/graphml+xml")
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+})
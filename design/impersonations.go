@@ -0,0 +1,94 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var impersonation = a.MediaType("application/vnd.impersonation+json", func() {
+	a.TypeName("Impersonation")
+	a.Description("A token an instance admin was issued to act as another identity")
+	a.Attributes(func() {
+		a.Attribute("id", d.UUID, "ID of the impersonation")
+		a.Attribute("impersonator", d.UUID, "ID of the instance admin")
+		a.Attribute("identity", d.UUID, "ID of the identity the admin acts as")
+		a.Attribute("reason", d.String, "Why the admin acts as the identity, e.g. a support ticket")
+		a.Attribute("created-at", d.DateTime, "When the token was issued")
+		a.Attribute("expires-at", d.DateTime, "When the token expires")
+		a.Attribute("token", d.String, "The JWT acting as the identity, only returned when it is issued")
+		a.Required("id", "impersonator", "identity", "reason", "created-at", "expires-at")
+	})
+	a.View("default", func() {
+		a.Attribute("id")
+		a.Attribute("impersonator")
+		a.Attribute("identity")
+		a.Attribute("reason")
+		a.Attribute("created-at")
+		a.Attribute("expires-at")
+		a.Attribute("token")
+	})
+})
+
+var impersonationList = a.MediaType("application/vnd.impersonationlist+json", func() {
+	a.TypeName("ImpersonationList")
+	a.Description("A page of impersonations, the latest first")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(impersonation))
+		a.Attribute("links", pagingLinks)
+		a.Attribute("meta", projectListMeta)
+		a.Required("data", "links", "meta")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Attribute("links")
+		a.Attribute("meta")
+	})
+})
+
+var impersonationPayload = a.Type("ImpersonationPayload", func() {
+	a.Attribute("identity", d.UUID, "ID of the identity to act as")
+	a.Attribute("reason", d.String, "Why the admin acts as the identity, e.g. a support ticket", func() {
+		a.MinLength(1)
+		a.MaxLength(500)
+	})
+	a.Required("identity", "reason")
+})
+
+var _ = a.Resource("impersonations", func() {
+	a.BasePath("/admin/impersonations")
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description(`Issue a short-lived token acting as the given identity, so support can reproduce the
+permission problems of a user (instance admins only). The token carries the "impersonator" and "banner" claims,
+clients show the banner as long as they use it. Every token is recorded with the reason, requests made with it
+are logged with the admin. The token is a session of the identity, it can be revoked before it expires.
+Impersonation tokens can't issue other ones and instance admins can't be impersonated.`)
+		a.Payload(impersonationPayload)
+		a.Response(d.OK, impersonation)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the tokens issued to act as other identities, the latest first (instance admins only).")
+		a.Params(func() {
+			a.Param("filter[identity]", d.UUID, "Only list the impersonations of the identity")
+			a.Param("page[offset]", d.String, "Paging start position")
+			a.Param("page[limit]", d.Integer, "Paging size")
+		})
+		a.Response(d.OK, impersonationList)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,113 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var instanceGrowthWeek = a.Type("InstanceGrowthWeek", func() {
+	a.Attribute("start", d.DateTime, "The Monday 00:00 UTC the week starts on")
+	a.Attribute("projects", d.Integer, "The projects created in the week")
+	a.Attribute("work-items", d.Integer, "The work items created in the week")
+	a.Attribute("identities", d.Integer, "The identities created in the week")
+	a.Required("start", "projects", "work-items", "identities")
+})
+
+var instanceOverview = a.MediaType("application/vnd.instanceoverview+json", func() {
+	a.TypeName("InstanceOverview")
+	a.Description("The totals, active users and growth of the instance")
+	a.Attributes(func() {
+		a.Attribute("projects", d.Integer, "The number of projects")
+		a.Attribute("work-items", d.Integer, "The number of work items")
+		a.Attribute("identities", d.Integer, "The number of identities")
+		a.Attribute("storage", d.Integer, "The size of the attachments and work item fields in bytes")
+		a.Attribute("database-size", d.Integer, "The size of the whole database in bytes")
+		a.Attribute("active-day", d.Integer, "The users who created or changed a work item or commented in the last day")
+		a.Attribute("active-week", d.Integer, "The users active in the last 7 days")
+		a.Attribute("active-month", d.Integer, "The users active in the last 30 days")
+		a.Attribute("growth", a.ArrayOf(instanceGrowthWeek), "What was created by week, the oldest week first")
+		a.Required("projects", "work-items", "identities", "storage", "database-size", "active-day", "active-week", "active-month", "growth")
+	})
+	a.View("default", func() {
+		a.Attribute("projects")
+		a.Attribute("work-items")
+		a.Attribute("identities")
+		a.Attribute("storage")
+		a.Attribute("database-size")
+		a.Attribute("active-day")
+		a.Attribute("active-week")
+		a.Attribute("active-month")
+		a.Attribute("growth")
+	})
+})
+
+var instanceProjectStats = a.Type("InstanceProjectStats", func() {
+	a.Attribute("id", d.UUID, "ID of the project")
+	a.Attribute("name", d.String, "Name of the project")
+	a.Attribute("work-items", d.Integer, "The number of work items of the project")
+	a.Attribute("users", d.Integer, "The users who created or changed a work item or commented in the project")
+	a.Attribute("storage", d.Integer, "The size of the attachments and work item fields of the project in bytes")
+	a.Attribute("last-activity", d.DateTime, "When a work item of the project last changed, missing if it has none")
+	a.Required("id", "name", "work-items", "users", "storage")
+})
+
+var instanceProjectStatsList = a.MediaType("application/vnd.instanceprojectstats+json", func() {
+	a.TypeName("InstanceProjectStatsList")
+	a.Description("A page of the statistics of the projects")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(instanceProjectStats))
+		a.Attribute("links", pagingLinks)
+		a.Attribute("meta", projectListMeta)
+		a.Required("data", "links", "meta")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Attribute("links")
+		a.Attribute("meta")
+	})
+})
+
+var _ = a.Resource("instance-stats", func() {
+	a.BasePath("/admin/stats")
+
+	a.Action("overview", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description(`Report the totals of the instance, its active users and what was created in each of the last
+weeks, for capacity planning (instance admins only).`)
+		a.Params(func() {
+			a.Param("weeks", d.Integer, "The number of weeks of the growth, the current one included", func() {
+				a.Minimum(1)
+				a.Maximum(104)
+				a.Default(12)
+			})
+		})
+		a.Response(d.OK, instanceOverview)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("projects", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/projects"),
+		)
+		a.Description(`List the work items, users and storage of every project, the largest first (instance admins
+only).`)
+		a.Params(func() {
+			a.Param("sort", d.String, "What the projects are ordered by", func() {
+				a.Enum("work-items", "users", "storage", "activity")
+				a.Default("work-items")
+			})
+			a.Param("page[offset]", d.String, "Paging start position")
+			a.Param("page[limit]", d.Integer, "Paging size")
+		})
+		a.Response(d.OK, instanceProjectStatsList)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
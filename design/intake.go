@@ -0,0 +1,228 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var intakePortal = a.Type("IntakePortal", func() {
+	a.Description(`JSONAPI store for the data of the feedback portal of a project.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("intakeportals")
+	})
+	a.Attribute("id", d.UUID, "ID of the project", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", intakePortalAttributes)
+	a.Required("type", "attributes")
+})
+
+var intakePortalAttributes = a.Type("IntakePortalAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a feedback portal. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("enabled", d.Boolean, "Whether the portal accepts submissions")
+	a.Attribute("work-item-type", d.String, "The type of the work items created for submissions", func() {
+		a.MinLength(1)
+		a.Example("system.bug")
+	})
+	a.Attribute("label", d.String, "The label of the work items created for submissions, triage if not set", func() {
+		a.Example("triage")
+	})
+	a.Attribute("rate-limit", d.Integer, "The number of submissions per hour a client can send, 5 if not set", func() {
+		a.Minimum(1)
+		a.Example(5)
+	})
+	a.Attribute("origins", a.ArrayOf(d.String), "The websites the form can be embedded on", func() {
+		a.Example([]string{"https://example.com"})
+	})
+	a.Attribute("require-token", d.Boolean, "Whether submissions must carry a token of the portal")
+	a.Required("enabled", "work-item-type")
+})
+
+var intakePortalSingle = JSONSingle(
+	"IntakePortal", "Holds the feedback portal of a project",
+	intakePortal,
+	nil)
+
+var intakeToken = a.Type("IntakeToken", func() {
+	a.Description(`JSONAPI store for the data of a token of a feedback portal.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("intaketokens")
+	})
+	a.Attribute("id", d.UUID, "ID of the token", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", intakeTokenAttributes)
+	a.Required("type", "attributes")
+})
+
+var intakeTokenAttributes = a.Type("IntakeTokenAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a token of a feedback portal. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("name", d.String, "What the token is used for", func() {
+		a.MinLength(1)
+		a.Example("Product website")
+	})
+	a.Attribute("origin", d.String, "The only website the token can be used on, any allowed origin if not set", func() {
+		a.Example("https://example.com")
+	})
+	a.Attribute("created-at", d.DateTime, "When the token was created")
+	a.Attribute("last-used-at", d.DateTime, "When the token was last used")
+	a.Attribute("token", d.String, "The secret of the token, only returned when it is created")
+	a.Required("name")
+})
+
+var intakeTokenSingle = JSONSingle(
+	"IntakeToken", "Holds a token of a feedback portal",
+	intakeToken,
+	nil)
+
+var intakeTokenList = JSONList(
+	"IntakeToken", "Holds the tokens of a feedback portal",
+	intakeToken,
+	nil,
+	nil)
+
+var intakeSubmission = a.Type("IntakeSubmission", func() {
+	a.Description("Feedback sent by an end user through a widget")
+	a.Attribute("title", d.String, "What the feedback is about", func() {
+		a.MinLength(1)
+		a.MaxLength(256)
+		a.Example("The export button does nothing")
+	})
+	a.Attribute("description", d.String, "The feedback", func() {
+		a.MaxLength(10000)
+	})
+	a.Attribute("page-url", d.String, "The page the feedback was sent from", func() {
+		a.Example("https://example.com/reports")
+	})
+	a.Attribute("website", d.String, "Must be left empty, widgets hide the field from end users to catch bots")
+	a.Required("title")
+})
+
+var intakeReceipt = a.MediaType("application/vnd.intakereceipt+json", func() {
+	a.TypeName("IntakeReceipt")
+	a.Description("Confirms that a submission was received")
+	a.Attributes(func() {
+		a.Attribute("id", d.String, "ID of the created work item")
+		a.Attribute("pending-review", d.Boolean, "True if the work item is held for review by the project admins")
+		a.Required("id", "pending-review")
+	})
+	a.View("default", func() {
+		a.Attribute("id")
+		a.Attribute("pending-review")
+	})
+})
+
+var _ = a.Resource("project-intake", func() {
+	a.Parent("project")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("intake"),
+		)
+		a.Description("Retrieve the feedback portal of the project.")
+		a.Response(d.OK, func() {
+			a.Media(intakePortalSingle)
+		})
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PUT("intake"),
+		)
+		a.Description(`Set up the feedback portal of the project (project admins only). Submissions create work items of
+the given type in the new state with the given label.`)
+		a.Payload(intakePortalSingle)
+		a.Response(d.OK, func() {
+			a.Media(intakePortalSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("intake"),
+		)
+		a.Description("Remove the feedback portal of the project (project admins only).")
+		a.Response(d.OK)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("submit", func() {
+		a.Routing(
+			a.POST("intake/submissions"),
+		)
+		a.Description(`Send feedback to the enabled portal of the project without logging in. If the project requires a
+challenge the response token of the solved challenge must be sent in the X-Challenge-Token header. Forms embedded
+on other websites are accepted from the allowed origins of the portal; a token of the portal is sent in the
+X-Intake-Token header. Clients are limited to the rate limit of the portal, submissions caught by the content
+filters are rejected or held for review.`)
+		a.Headers(func() {
+			a.Header("X-Challenge-Token", d.String, "Response token of the challenge solved by the end user")
+			a.Header("X-Intake-Token", d.String, "Token of the portal")
+		})
+		a.Payload(intakeSubmission)
+		a.Response(d.Created, "/workitems/.*", func() {
+			a.Media(intakeReceipt)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("list-tokens", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("intake/tokens"),
+		)
+		a.Description("List the tokens of the feedback portal of the project, without their secrets (project admins only).")
+		a.Response(d.OK, func() {
+			a.Media(intakeTokenList)
+		})
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("create-token", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("intake/tokens"),
+		)
+		a.Description(`Create a token for the forms embedded on a website (project admins only). The secret of the token
+is only returned in the response.`)
+		a.Payload(intakeTokenSingle)
+		a.Response(d.Created, func() {
+			a.Media(intakeTokenSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("revoke-token", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("intake/tokens/:tokenID"),
+		)
+		a.Params(func() {
+			a.Param("tokenID", d.UUID, "ID of the token")
+		})
+		a.Description("Revoke a token of the feedback portal of the project (project admins only).")
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -30,6 +30,9 @@ var iterationAttributes = a.Type("IterationAttributes", func() {
 	a.Attribute("endAt", d.DateTime, "When the iteration starts", func() {
 		a.Example("2016-11-29T23:18:14Z")
 	})
+	a.Attribute("archivedAt", d.DateTime, "When the iteration was archived, not set for active iterations", func() {
+		a.Example("2016-12-29T23:18:14Z")
+	})
 })
 
 var iterationRelationships = a.Type("IterationRelations", func() {
@@ -96,6 +99,9 @@ var _ = a.Resource("project-iterations", func() {
 			a.GET("iterations"),
 		)
 		a.Description("List iterations.")
+		a.Params(func() {
+			a.Param("filter[archived]", d.Boolean, "List the archived instead of the active iterations")
+		})
 		/*
 			a.Params(func() {
 				a.Param("filter", d.String, "a query language expression restricting the set of found work items")
@@ -0,0 +1,105 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var backgroundJob = a.Type("Job", func() {
+	a.Description(`JSONAPI store for the data of a background job.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("jobs")
+	})
+	a.Attribute("id", d.UUID, "ID of the job", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", backgroundJobAttributes)
+	a.Required("type", "id", "attributes")
+})
+
+var backgroundJobAttributes = a.Type("JobAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a background job. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("kind", d.String, "What the job does", func() {
+		a.Example("codechange.associate")
+	})
+	a.Attribute("status", d.String, "The status of the job", func() {
+		a.Enum("pending", "running", "succeeded", "dead")
+	})
+	a.Attribute("attempts", d.Integer, "How often the job was run")
+	a.Attribute("max-attempts", d.Integer, "How often the job is run before it is dead")
+	a.Attribute("run-at", d.DateTime, "When the job is run next")
+	a.Attribute("last-error", d.String, "Why the last attempt failed")
+	a.Attribute("created-at", d.DateTime, "When the job was enqueued")
+	a.Required("kind", "status", "attempts", "max-attempts", "run-at", "created-at")
+})
+
+var backgroundJobList = JSONList(
+	"Job", "Holds the list of background jobs",
+	backgroundJob,
+	nil,
+	nil)
+
+var backgroundJobSingle = JSONSingle(
+	"Job", "Holds a single background job",
+	backgroundJob,
+	nil)
+
+var _ = a.Resource("jobs", func() {
+	a.BasePath("/jobs")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the background jobs, most recent first (instance admins only).")
+		a.Params(func() {
+			a.Param("status", d.String, "Only list the jobs with the given status", func() {
+				a.Enum("pending", "running", "succeeded", "dead")
+			})
+			a.Param("page[offset]", d.String, "Paging start position")
+			a.Param("page[limit]", d.Integer, "Paging size")
+		})
+		a.Response(d.OK, func() {
+			a.Media(backgroundJobList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("show", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.UUID, "id")
+		})
+		a.Description("Retrieve the background job with the given id (instance admins only).")
+		a.Response(d.OK, func() {
+			a.Media(backgroundJobSingle)
+		})
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("retry", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:id/retry"),
+		)
+		a.Params(func() {
+			a.Param("id", d.UUID, "id")
+		})
+		a.Description("Run a dead background job again with a fresh set of attempts (instance admins only).")
+		a.Response(d.OK, func() {
+			a.Media(backgroundJobSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
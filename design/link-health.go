@@ -0,0 +1,97 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var linkHealthOrphans = a.Type("LinkHealthOrphans", func() {
+	a.Attribute("link-type-id", d.UUID, "The tree link type")
+	a.Attribute("link-type-name", d.String, "The name of the link type")
+	a.Attribute("count", d.Integer, "The number of work items of the target type without a parent or children")
+	a.Attribute("work-item-ids", a.ArrayOf(d.String), "The IDs of the first 100 orphans")
+	a.Required("link-type-id", "link-type-name", "count", "work-item-ids")
+})
+
+var linkHealthDangling = a.Type("LinkHealthDangling", func() {
+	a.Attribute("id", d.UUID, "The link")
+	a.Attribute("source-id", d.String, "The source work item")
+	a.Attribute("target-id", d.String, "The target work item")
+	a.Attribute("link-type-id", d.UUID, "The link type")
+	a.Required("id", "source-id", "target-id", "link-type-id")
+})
+
+var linkHealthBucket = a.Type("LinkHealthBucket", func() {
+	a.Attribute("links", d.Integer, "A number of links")
+	a.Attribute("work-items", d.Integer, "The number of work items with that many links")
+	a.Required("links", "work-items")
+})
+
+var linkHealthDistribution = a.Type("LinkHealthDistribution", func() {
+	a.Attribute("link-type-id", d.UUID, "The link type")
+	a.Attribute("link-type-name", d.String, "The name of the link type")
+	a.Attribute("links", d.Integer, "The number of links of the type")
+	a.Attribute("buckets", a.ArrayOf(linkHealthBucket), "The number of work items by their number of links of the type")
+	a.Required("link-type-id", "link-type-name", "links", "buckets")
+})
+
+var linkHealth = a.MediaType("application/vnd.linkhealth+json", func() {
+	a.TypeName("LinkHealth")
+	a.Description("The anomalies and statistics of the work item links")
+	a.Attributes(func() {
+		a.Attribute("orphans", a.ArrayOf(linkHealthOrphans), "The orphans of every tree link type")
+		a.Attribute("dangling", a.ArrayOf(linkHealthDangling), "The first 100 links referencing a deleted work item or link type")
+		a.Attribute("dangling-count", d.Integer, "The number of dangling links")
+		a.Attribute("distributions", a.ArrayOf(linkHealthDistribution), "How many links of each type the work items have")
+		a.Required("orphans", "dangling", "dangling-count", "distributions")
+	})
+	a.View("default", func() {
+		a.Attribute("orphans")
+		a.Attribute("dangling")
+		a.Attribute("dangling-count")
+		a.Attribute("distributions")
+	})
+})
+
+var linkRepair = a.MediaType("application/vnd.linkrepair+json", func() {
+	a.TypeName("LinkRepair")
+	a.Description("The outcome of a repair of the work item links")
+	a.Attributes(func() {
+		a.Attribute("deleted", d.Integer, "The number of deleted dangling links")
+		a.Required("deleted")
+	})
+	a.View("default", func() {
+		a.Attribute("deleted")
+	})
+})
+
+var _ = a.Resource("link-health", func() {
+	a.BasePath("/linkhealth")
+
+	a.Action("show", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description(`Check the work item links for orphans in tree link types and dangling links and
+report how many links the work items have (instance admins only).`)
+		a.Response(d.OK, func() {
+			a.Media(linkHealth)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("repair", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/repair"),
+		)
+		a.Description("Delete the links referencing a deleted work item or link type (instance admins only).")
+		a.Response(d.OK, func() {
+			a.Media(linkRepair)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,57 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var linkSuggestion = a.Type("LinkSuggestion", func() {
+	a.Attribute("work-item-id", d.String, "The suggested work item")
+	a.Attribute("title", d.String, "The title of the suggested work item")
+	a.Attribute("type", d.String, "The type of the suggested work item")
+	a.Attribute("kind", d.String, "Why the work item is suggested", func() {
+		a.Enum("duplicate", "parent", "related")
+	})
+	a.Attribute("link-type-id", d.UUID, "The tree link type to link a suggested parent with")
+	a.Attribute("score", d.Number, "The score between 0 and 1 ordering the suggestions")
+	a.Attribute("reasons", a.ArrayOf(d.String), "The reasons of the score, like shared labels")
+	a.Required("work-item-id", "title", "type", "kind", "score", "reasons")
+})
+
+var linkSuggestions = a.MediaType("application/vnd.linksuggestions+json", func() {
+	a.TypeName("LinkSuggestions")
+	a.Description("The work items suggested to link a work item to, best first")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(linkSuggestion))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+	})
+})
+
+var _ = a.Resource("work-item-link-suggestions", func() {
+	a.Parent("workitem")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("relationships/suggestions"),
+		)
+		a.Description(`Suggest work items the given work item is not linked to yet but likely belongs to: duplicates
+of the same type with a very similar title, parents in a tree link type and related work items. The
+suggestions are scored by the similarity of the titles, shared labels and how often the work items
+were linked recently.`)
+		a.Params(func() {
+			a.Param("page[limit]", d.Integer, "Number of suggestions (1 to 50, defaults to 10)", func() {
+				a.Minimum(1)
+				a.Maximum(50)
+			})
+		})
+		a.Response(d.OK, func() {
+			a.Media(linkSuggestions)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+})
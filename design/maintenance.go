@@ -0,0 +1,63 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var maintenance = a.Type("Maintenance", func() {
+	a.Description(`JSONAPI store for the data of the maintenance mode.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("maintenance")
+	})
+	a.Attribute("attributes", maintenanceAttributes)
+	a.Required("type", "attributes")
+})
+
+var maintenanceAttributes = a.Type("MaintenanceAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of the maintenance mode. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("enabled", d.Boolean, "Whether the API is read-only for maintenance")
+	a.Attribute("message", d.String, "The message the rejected requests get", func() {
+		a.Example("The service is read-only for maintenance, try again later.")
+	})
+	a.Attribute("read-only", d.Boolean, "Whether the API is read-only for maintenance or any other reason (read-only)")
+	a.Attribute("reasons", a.ArrayOf(d.String), "Why the API is read-only: schema, maintenance or restore (read-only)")
+	a.Required("enabled")
+})
+
+var maintenanceSingle = JSONSingle(
+	"Maintenance", "Holds the maintenance mode",
+	maintenance,
+	nil)
+
+var _ = a.Resource("maintenance", func() {
+	a.BasePath("/maintenance")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("Retrieve whether the API is read-only and why.")
+		a.Response(d.OK, func() {
+			a.Media(maintenanceSingle)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PUT(""),
+		)
+		a.Description(`Switch the read-only maintenance mode on or off (instance admins only). While it is on
+all requests that could change data get a 503 with the message. The switch is a runtime setting,
+other servers apply it when they reload their settings.`)
+		a.Payload(maintenanceSingle)
+		a.Response(d.OK, func() {
+			a.Media(maintenanceSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -13,6 +13,9 @@ var ALMStatus = a.MediaType("application/vnd.status+json", func() {
 		a.Attribute("buildTime", d.String, "The time when built")
 		a.Attribute("startTime", d.String, "The time when started")
 		a.Attribute("error", d.String, "The error if any")
+		a.Attribute("activeConnections", d.Integer, "The HTTP connections reading or serving a request")
+		a.Attribute("idleConnections", d.Integer, "The HTTP connections kept alive between requests")
+		a.Attribute("dependencies", a.ArrayOf(dependencyHealth), "The circuits of the external dependencies called so far")
 		a.Required("commit", "buildTime", "startTime")
 	})
 	a.View("default", func() {
@@ -20,9 +23,27 @@ var ALMStatus = a.MediaType("application/vnd.status+json", func() {
 		a.Attribute("buildTime")
 		a.Attribute("startTime")
 		a.Attribute("error")
+		a.Attribute("activeConnections")
+		a.Attribute("idleConnections")
+		a.Attribute("dependencies")
 	})
 })
 
+var dependencyHealth = a.Type("DependencyHealth", func() {
+	a.Description(`The circuit of an external dependency: open circuits fail without calling the dependency until
+they are half-open again after a cooldown`)
+	a.Attribute("name", d.String, "The dependency", func() {
+		a.Example("webhook example.com")
+	})
+	a.Attribute("state", d.String, "The state of the circuit", func() {
+		a.Enum("closed", "open", "half-open")
+	})
+	a.Attribute("failures", d.Integer, "The number of consecutive failures")
+	a.Attribute("error", d.String, "The error of the last failure")
+	a.Attribute("since", d.DateTime, "When the circuit changed to its state")
+	a.Required("name", "state", "failures", "since")
+})
+
 // AuthToken represents an authentication JWT Token
 var AuthToken = a.MediaType("application/vnd.authtoken+json", func() {
 	a.TypeName("AuthToken")
@@ -77,6 +98,10 @@ var fieldDefinition = a.Type("fieldDefinition", func() {
 	a.Description("A fieldDescription aggregates a fieldType and additional field metadata")
 	a.Attribute("required", d.Boolean)
 	a.Attribute("type", fieldType)
+	a.Attribute("roles", a.ArrayOf(d.String), "Restricts reading and writing the field to the given roles: 'creator', 'assignee' or 'project-admin'. Everybody can read and write the field if not set")
+	a.Attribute("encrypted", d.Boolean, "Whether values of the field are encrypted at rest. Work items can't be filtered or sorted by encrypted fields")
+	a.Attribute("deprecated", d.Boolean, "Deprecated fields can't be set on new work items, the values of existing work items are still returned")
+	a.Attribute("replacedBy", d.String, "The field the values of the deprecated field are moved to")
 
 	a.Required("required")
 	a.Required("type")
@@ -120,6 +145,48 @@ var workItemType = a.MediaType("application/vnd.workitemtype+json", func() {
 
 })
 
+var workItemTypeMigration = a.MediaType("application/vnd.workitemtypemigration+json", func() {
+	a.TypeName("WorkItemTypeMigration")
+	a.Description("The outcome of migrating work items to the current version of their type")
+	a.Attributes(func() {
+		a.Attribute("version", d.Integer, "The version the work items were migrated to")
+		a.Attribute("migrated", d.Integer, "Number of migrated work items")
+		a.Attribute("failures", a.HashOf(d.String, d.String), "Why work items couldn't be migrated by their ID, they stay at their version")
+		a.Required("version", "migrated", "failures")
+	})
+	a.View("default", func() {
+		a.Attribute("version")
+		a.Attribute("migrated")
+		a.Attribute("failures")
+	})
+})
+
+var workItemTypeImpact = a.MediaType("application/vnd.workitemtypeimpact+json", func() {
+	a.TypeName("WorkItemTypeImpact")
+	a.Description("What deleting a work item type affects")
+	a.Attributes(func() {
+		a.Attribute("work-items", d.Integer, "Number of work items of the type, including trashed and archived ones")
+		a.Attribute("subtypes", a.ArrayOf(d.String), "The types extending the type, a type with subtypes can't be deleted")
+		a.Attribute("link-types", a.ArrayOf(d.String), "The link types from or to the type, they are deleted with it")
+		a.Attribute("links", d.Integer, "Number of links of these link types, they are deleted with them")
+		a.Attribute("assignment-rules", d.Integer, "Number of assignment rules matching the type")
+		a.Attribute("approval-rules", d.Integer, "Number of approval rules matching the type")
+		a.Attribute("hierarchies", d.Integer, "Number of projects whose type hierarchy has a level of the type")
+		a.Attribute("in-use", d.Boolean, "Whether deleting the type requires a target type to migrate the work items, rules and hierarchy levels to")
+		a.Required("work-items", "subtypes", "link-types", "links", "assignment-rules", "approval-rules", "hierarchies", "in-use")
+	})
+	a.View("default", func() {
+		a.Attribute("work-items")
+		a.Attribute("subtypes")
+		a.Attribute("link-types")
+		a.Attribute("links")
+		a.Attribute("assignment-rules")
+		a.Attribute("approval-rules")
+		a.Attribute("hierarchies")
+		a.Attribute("in-use")
+	})
+})
+
 // Tracker configuration
 var Tracker = a.MediaType("application/vnd.tracker+json", func() {
 	a.TypeName("Tracker")
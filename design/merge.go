@@ -0,0 +1,31 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var _ = a.Resource("work-item-merge", func() {
+	a.Parent("workitem")
+
+	a.Action("merge", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("merge"),
+		)
+		a.Description(`Merge the given work item into another one, e.g. a duplicate into the original. Its comments move to the
+other work item and it is deleted, requests for its ID or key are redirected to the other work item until it is
+restored from the trash. Responds with the work item it was merged into.`)
+		a.Params(func() {
+			a.Param("into", d.String, "ID or key of the work item to merge into")
+			a.Required("into")
+		})
+		a.Response(d.OK, func() {
+			a.Media(workItemSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,78 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var _ = a.Resource("moderation", func() {
+	a.Parent("project")
+
+	a.Action("workitems", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("moderation/workitems"),
+		)
+		a.Description(`List the work items of the given public project that are held for review (project admins only).`)
+		a.Response(d.OK, func() {
+			a.Media(workItemList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("comments", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("moderation/comments"),
+		)
+		a.Description(`List the comments on work items of the given public project that are held for review (project admins only).`)
+		a.Response(d.OK, func() {
+			a.Media(commentArray)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("approve", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("moderation/:kind/:itemID/approve"),
+		)
+		a.Params(func() {
+			a.Param("kind", d.String, "kind of the contribution", func() {
+				a.Enum("workitems", "comments")
+			})
+			a.Param("itemID", d.String, "ID of the work item or comment")
+		})
+		a.Description(`Publish a work item or comment held for review (project admins only).`)
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("reject", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("moderation/:kind/:itemID/reject"),
+		)
+		a.Params(func() {
+			a.Param("kind", d.String, "kind of the contribution", func() {
+				a.Enum("workitems", "comments")
+			})
+			a.Param("itemID", d.String, "ID of the work item or comment")
+		})
+		a.Description(`Delete a work item or comment held for review (project admins only).`)
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
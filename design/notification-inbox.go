@@ -0,0 +1,119 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var inboxNotification = a.Type("InboxNotification", func() {
+	a.Description(`JSONAPI store for the data of a notification in the inbox of a user.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("notifications")
+	})
+	a.Attribute("id", d.UUID, "ID of the notification", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", inboxNotificationAttributes)
+	a.Attribute("relationships", inboxNotificationRelationships)
+	a.Required("type", "id", "attributes")
+})
+
+var inboxNotificationAttributes = a.Type("InboxNotificationAttributes", func() {
+	a.Attribute("event", d.String, "What happened", func() {
+		a.Example("workitem.stale")
+	})
+	a.Attribute("subject", d.String, "The text of the notification", func() {
+		a.Example("No activity on Login fails")
+	})
+	a.Attribute("at", d.DateTime, "When it happened")
+	a.Attribute("read-at", d.DateTime, "When the user read the notification, missing if unread")
+	a.Attribute("snoozed-until", d.DateTime, "When the snooze of the notification ended, missing if never snoozed")
+	a.Required("event", "subject", "at")
+})
+
+var inboxNotificationRelationships = a.Type("InboxNotificationRelations", func() {
+	a.Attribute("workitem", relationGeneric, "The work item it happened to")
+	a.Attribute("project", relationGeneric, "The project of the work item")
+})
+
+var inboxMeta = a.Type("InboxMeta", func() {
+	a.Attribute("totalCount", d.Integer, "The number of listed notifications")
+	a.Attribute("unreadCount", d.Integer, "The number of unread notifications")
+	a.Required("totalCount", "unreadCount")
+})
+
+var inboxNotificationList = JSONList(
+	"InboxNotification", "Holds the paginated notifications of a user",
+	inboxNotification,
+	pagingLinks,
+	inboxMeta)
+
+var _ = a.Resource("notification-inbox", func() {
+	a.BasePath("/user")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("notifications"),
+		)
+		a.Description(`List the notifications in the inbox of the authenticated user, the latest first, with the number of
+unread ones. Snoozed notifications are left out until their snooze ends. Read notifications are removed after 90
+days.`)
+		a.Params(func() {
+			a.Param("page[offset]", d.String, "Paging start position")
+			a.Param("page[limit]", d.Integer, "Paging size")
+			a.Param("filter[unread]", d.Boolean, "List the unread notifications only")
+		})
+		a.Response(d.OK, func() {
+			a.Media(inboxNotificationList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("read", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("notifications/:id/read"),
+		)
+		a.Params(func() {
+			a.Param("id", d.UUID, "ID of the notification")
+		})
+		a.Description("Mark a notification in the inbox of the authenticated user as read.")
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("snooze", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("notifications/:id/snooze"),
+		)
+		a.Params(func() {
+			a.Param("id", d.UUID, "ID of the notification")
+			a.Param("until", d.DateTime, "When the notification shows up again")
+			a.Required("until")
+		})
+		a.Description("Hide a notification in the inbox of the authenticated user until the given time.")
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("read-all", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("notifications/read"),
+		)
+		a.Description("Mark all notifications in the inbox of the authenticated user as read.")
+		a.Response(d.NoContent)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
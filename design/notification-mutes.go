@@ -0,0 +1,85 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+// notificationMutePayload mutes a work item or a project until a time or for
+// a duration
+var notificationMutePayload = a.Type("NotificationMutePayload", func() {
+	a.Attribute("workitem", d.String, "ID of the work item to mute, either a work item or a project must be given", func() {
+		a.Example("42")
+	})
+	a.Attribute("project", d.UUID, "ID of the project to mute all work items of")
+	a.Attribute("until", d.DateTime, "When the mute ends, either the end or a duration must be given")
+	a.Attribute("duration", d.String, "How long the mute lasts", func() {
+		a.Example("8h")
+	})
+})
+
+var notificationMute = a.Type("NotificationMute", func() {
+	a.Attribute("id", d.UUID, "ID of the mute")
+	a.Attribute("workitem", d.String, "ID of the muted work item")
+	a.Attribute("project", d.UUID, "ID of the muted project")
+	a.Attribute("until", d.DateTime, "When the mute ends")
+	a.Required("id", "until")
+})
+
+var notificationMuteList = a.MediaType("application/vnd.notificationmutes+json", func() {
+	a.TypeName("NotificationMuteList")
+	a.Description("The mutes of a user, the ones ending first first")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(notificationMute))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+	})
+})
+
+var _ = a.Resource("notification-mutes", func() {
+	a.BasePath("/user")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("mutes"),
+		)
+		a.Description("List the work items and projects the authenticated user muted.")
+		a.Response(d.OK, notificationMuteList)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("mute", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("mutes"),
+		)
+		a.Description(`Stop notifying the authenticated user about a work item or about all work items of a project for a
+while, an earlier mute of the work item or project is replaced.`)
+		a.Payload(notificationMutePayload)
+		a.Response(d.OK, notificationMuteList)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("unmute", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("mutes/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.UUID, "ID of the mute")
+		})
+		a.Description("End a mute of the authenticated user before its time.")
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
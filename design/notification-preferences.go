@@ -0,0 +1,79 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+// notificationPreferences are the defaults of a user or the overrides for a
+// project
+var notificationPreferences = a.Type("NotificationPreferences", func() {
+	a.Attribute("project", d.UUID, "The project the preferences override the defaults for, the defaults if missing")
+	a.Attribute("matrix", a.HashOf(d.String, a.HashOf(d.String, d.Boolean)), `Whether the user is notified by event and
+channel, events and channels left out follow the defaults, notifications are sent if there is no default`, func() {
+		a.Example(map[string]interface{}{"workitem.stale": map[string]interface{}{"email": false, "push": true}})
+	})
+	a.Required("matrix")
+})
+
+var notificationPreferenceList = a.MediaType("application/vnd.notificationpreferences+json", func() {
+	a.TypeName("NotificationPreferenceList")
+	a.Description("The defaults of a user followed by the overrides for projects")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(notificationPreferences))
+		a.Attribute("events", a.ArrayOf(d.String), "The events users are notified of")
+		a.Attribute("channels", a.ArrayOf(d.String), "The channels users are notified through")
+		a.Required("data", "events", "channels")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Attribute("events")
+		a.Attribute("channels")
+	})
+})
+
+var _ = a.Resource("notification-preferences", func() {
+	a.BasePath("/user")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("notification-preferences"),
+		)
+		a.Description("List the notification preferences of the authenticated user.")
+		a.Response(d.OK, notificationPreferenceList)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PUT("notification-preferences"),
+		)
+		a.Description(`Replace the defaults of the authenticated user or, if a project is given, the overrides of the
+defaults for the project.`)
+		a.Payload(notificationPreferences)
+		a.Response(d.OK, notificationPreferenceList)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("reset", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("notification-preferences/:projectID"),
+		)
+		a.Params(func() {
+			a.Param("projectID", d.UUID, "ID of the project")
+		})
+		a.Description("Remove the overrides of the authenticated user for the project, the defaults apply again.")
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
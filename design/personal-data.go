@@ -0,0 +1,41 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var _ = a.Resource("personal-data", func() {
+	a.BasePath("/users/:id")
+	a.Params(func() {
+		a.Param("id", d.String, "ID of the identity")
+	})
+
+	a.Action("export", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/export"),
+		)
+		a.Description(`Export all personal data stored for the identity as a JSON archive: the profile, comments,
+created work items, votes, reactions and project admin memberships. Identities can only export their own data.`)
+		a.Response(d.OK, "application/json")
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("anonymize", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/anonymize"),
+		)
+		a.Description(`Erase the personal data of the identity. References from work items and comments are replaced
+by a "Deleted user" tombstone identity, everything else is deleted. Identities can only anonymize themselves.`)
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,169 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var portfolio = a.Type("Portfolio", func() {
+	a.Description(`JSONAPI store for the data of a portfolio.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("portfolios")
+	})
+	a.Attribute("id", d.UUID, "ID of the portfolio", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", portfolioAttributes)
+	a.Attribute("relationships", portfolioRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var portfolioAttributes = a.Type("PortfolioAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a portfolio. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("name", d.String, "The name of the portfolio", func() {
+		a.Example("Platform")
+	})
+	a.Attribute("description", d.String, "What the projects of the portfolio have in common")
+	a.Attribute("version", d.Integer, "Version for optimistic concurrency control")
+})
+
+var portfolioRelationships = a.Type("PortfolioRelations", func() {
+	a.Attribute("owner", relationGeneric, "The identity owning the portfolio")
+	a.Attribute("projects", relationGenericList, "The projects grouped by the portfolio")
+	a.Attribute("link-types", relationGenericList, "The tree link types work items of different projects of the portfolio can be linked with")
+})
+
+var portfolioList = JSONList(
+	"Portfolio", "Holds the list of portfolios",
+	portfolio,
+	nil,
+	meta)
+
+var portfolioSingle = JSONSingle(
+	"Portfolio", "Holds a single portfolio",
+	portfolio,
+	nil)
+
+var portfolioCount = a.Type("PortfolioCount", func() {
+	a.Attribute("type", d.String, "The work item type", func() {
+		a.Example("system.bug")
+	})
+	a.Attribute("state", d.String, "The state of the work items", func() {
+		a.Example("open")
+	})
+	a.Attribute("count", d.Integer, "The number of open work items of the type in the state")
+	a.Required("type", "state", "count")
+})
+
+var portfolioReport = a.MediaType("application/vnd.portfolioreport+json", func() {
+	a.TypeName("PortfolioReport")
+	a.Description("The open work items of the projects of a portfolio")
+	a.Attributes(func() {
+		a.Attribute("counts", a.ArrayOf(portfolioCount), "The open work items of all projects by type and state")
+		a.Attribute("projects", a.HashOf(d.String, d.Integer), "The number of open work items per project ID")
+		a.Required("counts", "projects")
+	})
+	a.View("default", func() {
+		a.Attribute("counts")
+		a.Attribute("projects")
+	})
+})
+
+var _ = a.Resource("portfolio", func() {
+	a.BasePath("/portfolios")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List all portfolios.")
+		a.Response(d.OK, func() {
+			a.Media(portfolioList)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description("Create a portfolio owned by the authenticated user, who must administrate the projects added to it.")
+		a.Payload(portfolioSingle)
+		a.Response(d.Created, "/portfolios/.*", func() {
+			a.Media(portfolioSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Retrieve the portfolio with the given id.")
+		a.Response(d.OK, func() {
+			a.Media(portfolioSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("report", func() {
+		a.Routing(
+			a.GET("/:id/report"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Count the open work items of the projects of the portfolio by type and state, and per project.")
+		a.Response(d.OK, func() {
+			a.Media(portfolioReport)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description(`Update the portfolio (its owner only), projects can only be added by their admins. Tree links
+between work items of different projects are only allowed with the link types of a portfolio containing both projects.`)
+		a.Payload(portfolioSingle)
+		a.Response(d.OK, func() {
+			a.Media(portfolioSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Delete the portfolio (its owner only), existing links between its projects are kept.")
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
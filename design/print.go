@@ -0,0 +1,32 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var _ = a.Resource("work-item-print", func() {
+	a.Parent("workitem")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("print"),
+		)
+		a.Description(`Render the given work item with its fields, description, links and comments as a printable
+HTML page.`)
+		a.Response(d.OK, "text/html")
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("download", func() {
+		a.Routing(
+			a.GET("print/pdf"),
+		)
+		a.Description(`Render the given work item with its fields, description, links and comments as a PDF document,
+e.g. for audits and offline reviews.`)
+		a.Response(d.OK, "application/pdf")
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+})
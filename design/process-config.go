@@ -0,0 +1,81 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var processConfigApplied = a.MediaType("application/vnd.processconfigapplied+json", func() {
+	a.TypeName("ProcessConfigApplied")
+	a.Description("The changes applying a process config made, or would make in a dry run")
+	a.Attributes(func() {
+		a.Attribute("changes", a.ArrayOf(d.String), `The changes in the order they are made, e.g. "create link type user/blocks"`)
+		a.Attribute("dry-run", d.Boolean, "True if the changes were not made")
+		a.Required("changes", "dry-run")
+	})
+	a.View("default", func() {
+		a.Attribute("changes")
+		a.Attribute("dry-run")
+	})
+})
+
+var _ = a.Resource("project-process-config", func() {
+	a.Parent("project")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("process-config"),
+		)
+		a.Description(`Dump the process customization of the project as YAML config: the link categories and link
+types shared by all projects, the workflows (the approval rules of state transitions in their order) and the work
+item type hierarchy of the project. Work item types and boards are left out.`)
+		a.Response(d.OK, "application/x-yaml")
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("apply", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PUT("process-config"),
+		)
+		a.Description(`Apply a YAML process config as returned by the show action to the project (project admins only),
+the request body is the config. Applying a config again changes nothing. Link categories and link types missing in
+the config are kept since other projects may use them, changing them or creating work item types needs an instance
+admin. Workflows missing in the config are deleted, an empty type hierarchy deletes the one of the project. Work item
+types are created if missing, boards if the project has no dashboard of their name; neither are ever changed.`)
+		a.Params(func() {
+			a.Param("dry-run", d.Boolean, "List the changes without making them")
+		})
+		a.Response(d.OK, func() {
+			a.Media(processConfigApplied)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("apply-template", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("process-config/templates/:name"),
+		)
+		a.Description(`Apply the process template of the name to the project like its config (project admins only).
+Templates are registered by instance admins, so their work item types and link types are created for project admins
+as well.`)
+		a.Params(func() {
+			a.Param("name", d.String, "The name of the template")
+			a.Param("dry-run", d.Boolean, "List the changes without making them")
+		})
+		a.Response(d.OK, func() {
+			a.Media(processConfigApplied)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,104 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var processTemplate = a.MediaType("application/vnd.processtemplate+json", func() {
+	a.TypeName("ProcessTemplate")
+	a.Description("A process template projects can be created with")
+	a.Attributes(func() {
+		a.Attribute("name", d.String, "The name of the template", func() {
+			a.Example("scrum")
+		})
+		a.Attribute("description", d.String, "What the process of the template is like")
+		a.Attribute("config", d.String, `The YAML process config of the template: the work item types, link categories, link
+types, workflows, type hierarchy and boards, as applied by the apply action of the project process config`)
+		a.Attribute("custom", d.Boolean, "False for the built-in templates")
+		a.Required("name", "description", "config", "custom")
+	})
+	a.View("default", func() {
+		a.Attribute("name")
+		a.Attribute("description")
+		a.Attribute("config")
+		a.Attribute("custom")
+	})
+})
+
+var processTemplateList = a.MediaType("application/vnd.processtemplates+json", func() {
+	a.TypeName("ProcessTemplateList")
+	a.Description("The built-in process templates followed by the custom ones")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(processTemplate))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+	})
+})
+
+var processTemplatePayload = a.Type("ProcessTemplatePayload", func() {
+	a.Attribute("description", d.String, "What the process of the template is like")
+	a.Attribute("config", d.String, "The YAML process config of the template")
+	a.Required("config")
+})
+
+var _ = a.Resource("process-templates", func() {
+	a.BasePath("/process-templates")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the built-in process templates (scrum, kanban and issue-tracking) followed by the custom ones.")
+		a.Response(d.OK, processTemplateList)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("/:name"),
+		)
+		a.Params(func() {
+			a.Param("name", d.String, "The name of the template")
+		})
+		a.Description("Retrieve the process template of the name.")
+		a.Response(d.OK, processTemplate)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PUT("/:name"),
+		)
+		a.Params(func() {
+			a.Param("name", d.String, "The name of the template")
+		})
+		a.Description(`Register the custom process template of the name or replace it (instance admins only). Projects
+created with the template keep their process.`)
+		a.Payload(processTemplatePayload)
+		a.Response(d.OK, processTemplate)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:name"),
+		)
+		a.Params(func() {
+			a.Param("name", d.String, "The name of the template")
+		})
+		a.Description("Remove the custom process template of the name (instance admins only).")
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
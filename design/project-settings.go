@@ -0,0 +1,79 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var projectSettings = a.Type("ProjectSettings", func() {
+	a.Description(`JSONAPI store for the data of the settings of a project.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("projectsettings")
+	})
+	a.Attribute("id", d.UUID, "ID of the project", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", projectSettingsAttributes)
+	a.Required("type", "attributes")
+})
+
+var projectSettingsAttributes = a.Type("ProjectSettingsAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of the settings of a project. Attributes that are not set are
+left unchanged by updates. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("default-work-item-type", d.String, "The type of the work items created without one, empty if the type must be given", func() {
+		a.Example("system.userstory")
+	})
+	a.Attribute("required-fields", a.ArrayOf(d.String), "The fields work items must have a value for if their type has them", func() {
+		a.Example([]string{"system.assignees"})
+	})
+	a.Attribute("link-type-creators", d.String, `Who creates work item types and link types by applying a process config to
+the project`, func() {
+		a.Enum("instance-admins", "project-admins")
+	})
+	a.Attribute("visibility", d.String, "Who can read the project, see the attribute of the project", func() {
+		a.Enum("private", "internal", "public")
+	})
+	a.Attribute("public", d.Boolean, "Whether everybody can contribute work items and comments to the project")
+	a.Attribute("intake-enabled", d.Boolean, "Whether the feedback portal of the project accepts submissions")
+	a.Attribute("intake-require-token", d.Boolean, "Whether the feedback portal rejects submissions without a token")
+})
+
+var projectSettingsSingle = JSONSingle(
+	"ProjectSettings", "Holds the settings of a project",
+	projectSettings,
+	nil)
+
+var _ = a.Resource("project-settings", func() {
+	a.Parent("project")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("settings"),
+		)
+		a.Description(`Retrieve the settings of the project in one place: the process customization toggles, the visibility
+and the toggles of the feedback portal.`)
+		a.Response(d.OK, func() {
+			a.Media(projectSettingsSingle)
+		})
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("settings"),
+		)
+		a.Description(`Change the given settings of the project (project admins only). All values are checked before any
+is changed. Turning on the feedback portal of a project without one creates it for the default work item type of the
+project, or bugs.`)
+		a.Payload(projectSettingsSingle)
+		a.Response(d.OK, func() {
+			a.Media(projectSettingsSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
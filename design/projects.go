@@ -22,6 +22,27 @@ var projectAttributes = a.Type("ProjectAttributes", func() {
 	a.Attribute("name", d.String, "Name of the project", func() {
 		a.Example("foobar")
 	})
+	a.Attribute("public", d.Boolean, `Whether everybody can contribute work items and comments to the project. The contributions
+of first-time contributors are held for review by the project admins`)
+	a.Attribute("challenge", d.String, `The challenge anonymous contributors to the public project must solve, empty if none is
+required. The provider's secret must be configured on the server`, func() {
+		a.Enum("", "recaptcha", "hcaptcha")
+	})
+	a.Attribute("visibility", d.String, `Who can read the project and its work items, comments, links, iterations, releases and
+codebases: only the project admins (private), all logged in users (internal) or everybody (public). Only project
+admins can change the visibility`, func() {
+		a.Enum("private", "internal", "public")
+		a.Example("public")
+	})
+	a.Attribute("key", d.String, `Prefix of the human-friendly keys of the work items of the project, e.g. ALM for ALM-482.
+Work items get keys once it is set, it can only be set once and only by project admins`, func() {
+		a.Pattern("^[A-Z][A-Z0-9]{1,9}$")
+		a.Example("ALM")
+	})
+	a.Attribute("process-template", d.String, `The process template the project is created with, see /process-templates.
+Only read when the project is created`, func() {
+		a.Example("scrum")
+	})
 	a.Attribute("version", d.Integer, "Version for optimistic concurrency control (optional during creating)", func() {
 		a.Example(23)
 	})
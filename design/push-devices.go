@@ -0,0 +1,113 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var pushDevice = a.Type("PushDevice", func() {
+	a.Description(`JSONAPI store for the data of a mobile device notifications are pushed to.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("devices")
+	})
+	a.Attribute("id", d.UUID, "ID of the device", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", pushDeviceAttributes)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var pushDeviceAttributes = a.Type("PushDeviceAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a device. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("platform", d.String, "The push service of the device", func() {
+		a.Enum("fcm", "apns")
+	})
+	a.Attribute("token", d.String, "The token the push service gave the app, it is never returned")
+	a.Attribute("name", d.String, "The name telling the devices of the user apart", func() {
+		a.Example("work phone")
+	})
+	a.Attribute("muted", a.ArrayOf(d.String), `The events the device isn't notified of: "workitem.stale", "workitem.inactive"
+or "workitem.escalated"`)
+	a.Attribute("badge", d.Boolean, "Whether the badge of the app counts the open work items assigned to the user")
+	a.Attribute("created-at", d.DateTime, "When the device was registered")
+})
+
+var pushDeviceList = JSONList(
+	"PushDevice", "Holds the list of devices",
+	pushDevice,
+	nil,
+	meta)
+
+var pushDeviceSingle = JSONSingle(
+	"PushDevice", "Holds a single device",
+	pushDevice,
+	nil)
+
+var _ = a.Resource("push-devices", func() {
+	a.BasePath("/user")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("devices"),
+		)
+		a.Description("List the mobile devices notifications are pushed to for the authenticated user.")
+		a.Response(d.OK, func() {
+			a.Media(pushDeviceList)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("register", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("devices"),
+		)
+		a.Description(`Register a mobile device to push notifications to for the authenticated user. A device registered
+before, also by another user, is registered again with the given preferences.`)
+		a.Payload(pushDeviceSingle)
+		a.Response(d.Created, "/user/devices/.*", func() {
+			a.Media(pushDeviceSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("devices/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.UUID, "ID of the device")
+		})
+		a.Description("Change the name and the preferences of a device of the authenticated user.")
+		a.Payload(pushDeviceSingle)
+		a.Response(d.OK, func() {
+			a.Media(pushDeviceSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("devices/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.UUID, "ID of the device")
+		})
+		a.Description("Stop pushing notifications to a device of the authenticated user, e.g. when signing out of the app.")
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
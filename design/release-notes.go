@@ -0,0 +1,34 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var _ = a.Resource("release-notes", func() {
+	a.BasePath("/releasenotes")
+
+	a.Action("generate", func() {
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description(`Generate markdown release notes from the closed work items of a release, an iteration or
+the iterations of a project within a date range.`)
+		a.Params(func() {
+			a.Param("release", d.UUID, "Generate the notes of the given release")
+			a.Param("iteration", d.UUID, "Generate the notes of the given iteration")
+			a.Param("project", d.UUID, "Generate the notes of the iterations of the given project between 'from' and 'to'")
+			a.Param("from", d.DateTime, "Start of the date range")
+			a.Param("to", d.DateTime, "End of the date range")
+			a.Param("group-by", d.String, "Group the work items by type or by label", func() {
+				a.Enum("type", "label")
+				a.Default("type")
+			})
+			a.Param("template", d.String, "A Go text/template used to render the notes instead of the default markdown template")
+		})
+		a.Response(d.OK, "text/markdown")
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,143 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var release = a.Type("Release", func() {
+	a.Description(`JSONAPI store for the data of a release.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("releases")
+	})
+	a.Attribute("id", d.UUID, "ID of release", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", releaseAttributes)
+	a.Attribute("relationships", releaseRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var releaseAttributes = a.Type("ReleaseAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a release. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("version", d.String, "The version of the release", func() {
+		a.Example("1.2.0")
+	})
+	a.Attribute("target-date", d.DateTime, "When the release is planned to ship", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("status", d.String, "The status of the release (read-only)", func() {
+		a.Enum("planned", "published")
+	})
+	a.Attribute("published-at", d.DateTime, "When the release was published (read-only)", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("total-count", d.Integer, "The number of work items in the release (read-only)")
+	a.Attribute("closed-count", d.Integer, "The number of closed work items in the release (read-only)")
+	a.Attribute("completion", d.Integer, "The percentage of closed work items in the release (read-only)", func() {
+		a.Minimum(0)
+		a.Maximum(100)
+	})
+})
+
+var releaseRelationships = a.Type("ReleaseRelations", func() {
+	a.Attribute("project", relationGeneric, "This defines the owning project")
+	a.Attribute("workitems", relationGeneric, "This defines the work items planned for the release")
+})
+
+var releaseList = JSONList(
+	"Release", "Holds the list of releases",
+	release,
+	nil,
+	meta)
+
+var releaseSingle = JSONSingle(
+	"Release", "Holds a single release",
+	release,
+	nil)
+
+var _ = a.Resource("release", func() {
+	a.BasePath("/releases")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Retrieve release with given id including its completion.")
+		a.Response(d.OK, func() {
+			a.Media(releaseSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("notes", func() {
+		a.Routing(
+			a.GET("/:id/notes"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Retrieve the markdown release notes generated from the closed work items of the release.")
+		a.Response(d.OK, "text/markdown")
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("publish", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:id/publish"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Publish the release with given id, which freezes its notes and completion.")
+		a.Response(d.OK, func() {
+			a.Media(releaseSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("project-releases", func() {
+	a.Parent("project")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("releases"),
+		)
+		a.Description("List the releases of the given project.")
+		a.Response(d.OK, func() {
+			a.Media(releaseList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("releases"),
+		)
+		a.Description("Create a release in the given project.")
+		a.Payload(releaseSingle)
+		a.Response(d.Created, "/releases/.*", func() {
+			a.Media(releaseSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,146 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var remoteLink = a.Type("RemoteLink", func() {
+	a.Description(`JSONAPI store for the data of a remote link.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("remotelinks")
+	})
+	a.Attribute("id", d.UUID, "ID of remote link", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", remoteLinkAttributes)
+	a.Attribute("relationships", remoteLinkRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var remoteLinkAttributes = a.Type("RemoteLinkAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a remote link. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("url", d.String, "The URL of the external resource", func() {
+		a.Example("https://github.com/almighty/almighty-core/pull/42")
+	})
+	a.Attribute("title", d.String, "A human readable title of the external resource", func() {
+		a.Example("Fix for the login bug")
+	})
+	a.Attribute("icon", d.String, "URL to an icon representing the external resource", func() {
+		a.Example("https://github.com/favicon.ico")
+	})
+	a.Attribute("relationship", d.String, "How the work item relates to the external resource", func() {
+		a.Enum("relates to", "implemented by", "documented by", "built by", "blocked by")
+		a.Example("implemented by")
+	})
+	a.Attribute("version", d.Integer, "Version for optimistic concurrency control (optional during creating)", func() {
+		a.Example(0)
+	})
+	a.Attribute("created-at", d.DateTime, "When the remote link was created", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+})
+
+var remoteLinkRelationships = a.Type("RemoteLinkRelations", func() {
+	a.Attribute("workitem", relationGeneric, "This defines the owning work item")
+})
+
+var remoteLinkList = JSONList(
+	"RemoteLink", "Holds the list of remote links",
+	remoteLink,
+	nil,
+	meta)
+
+var remoteLinkSingle = JSONSingle(
+	"RemoteLink", "Holds a single remote link",
+	remoteLink,
+	nil)
+
+var _ = a.Resource("remote-link", func() {
+	a.BasePath("/remotelinks")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Retrieve remote link with given id.")
+		a.Response(d.OK, func() {
+			a.Media(remoteLinkSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Update the remote link with given id.")
+		a.Payload(remoteLinkSingle)
+		a.Response(d.OK, func() {
+			a.Media(remoteLinkSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:id"),
+		)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Description("Delete the remote link with given id.")
+		a.Response(d.OK)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("work-item-remote-links", func() {
+	a.Parent("workitem")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("remotelinks"),
+		)
+		a.Description("List remote links associated with the given work item")
+		a.Response(d.OK, func() {
+			a.Media(remoteLinkList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("remotelinks"),
+		)
+		a.Description("Create a remote link for the given work item")
+		a.Payload(remoteLinkSingle)
+		a.Response(d.Created, "/remotelinks/.*", func() {
+			a.Media(remoteLinkSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,56 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var reparentPayload = a.Type("ReparentPayload", func() {
+	a.Attribute("workitems", a.ArrayOf(d.String), "IDs or keys of the work items to move", func() {
+		a.MinLength(1)
+		a.MaxLength(1000)
+	})
+	a.Attribute("parent", d.String, "ID or key of the new parent of the work items")
+	a.Attribute("link-type", d.UUID, "ID of the tree link type to link the work items with, needed when several tree link types link the work item types")
+	a.Attribute("iteration", d.UUID, "ID of the new iteration of the work items")
+	a.Required("workitems")
+})
+
+var reparenting = a.MediaType("application/vnd.reparenting+json", func() {
+	a.TypeName("Reparenting")
+	a.Description("The work items moved to another parent or iteration")
+	a.Attributes(func() {
+		a.Attribute("workitems", a.ArrayOf(d.String), "IDs of the moved work items")
+		a.Attribute("parent", d.String, "ID or key of the new parent of the work items")
+		a.Attribute("iteration", d.UUID, "ID of the new iteration of the work items")
+		a.Attribute("former-parents", a.HashOf(d.String, a.ArrayOf(d.String)), "IDs of the parents the work items were unlinked from by their IDs")
+		a.Required("workitems", "former-parents")
+	})
+	a.View("default", func() {
+		a.Attribute("workitems")
+		a.Attribute("parent")
+		a.Attribute("iteration")
+		a.Attribute("former-parents")
+	})
+})
+
+var _ = a.Resource("work-item-reparent", func() {
+	a.BasePath("/workitems")
+
+	a.Action("reparent", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("reparent"),
+		)
+		a.Description(`Move the given work items to another parent and/or iteration in one transaction, e.g. to reorganize a
+backlog. The work items are unlinked from their current parents and linked to the new parent with a tree link type,
+like children created for it. Either all work items move or none does. A single "workitem.reparent" event is
+published for all of them.`)
+		a.Payload(reparentPayload)
+		a.Response(d.OK, reparenting)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
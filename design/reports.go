@@ -0,0 +1,65 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var reportRow = a.Type("ReportRow", func() {
+	a.Attribute("dimensions", a.ArrayOf(d.String), "The values of the group in the order of the requested dimensions", func() {
+		a.Example([]string{"open", "2017-03-27"})
+	})
+	a.Attribute("measures", a.ArrayOf(d.Number), "The measures of the group in the order of the requested measures", func() {
+		a.Example([]float64{12, 31})
+	})
+	a.Required("dimensions", "measures")
+})
+
+var report = a.MediaType("application/vnd.report+json", func() {
+	a.TypeName("Report")
+	a.Description("Work items aggregated for a chart")
+	a.Attributes(func() {
+		a.Attribute("dimensions", a.ArrayOf(d.String), "The requested dimensions")
+		a.Attribute("measures", a.ArrayOf(d.String), "The requested measures")
+		a.Attribute("rows", a.ArrayOf(reportRow), "One row per group ordered by the dimensions")
+		a.Attribute("truncated", d.Boolean, "Set if there were more groups than returned")
+		a.Required("dimensions", "measures", "rows", "truncated")
+	})
+	a.View("default", func() {
+		a.Attribute("dimensions")
+		a.Attribute("measures")
+		a.Attribute("rows")
+		a.Attribute("truncated")
+	})
+})
+
+var _ = a.Resource("report", func() {
+	a.BasePath("/reports")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description(`Aggregate the work items matching the filter for a chart. Every combination of the
+values of the dimensions is one row with the measures of its work items.`)
+		a.Params(func() {
+			a.Param("dimensions", d.String, `Comma separated list of at most 3 dimensions to group by: "type", "system.state",
+"system.priority", "system.severity", "system.iteration", "system.release", "system.project", "system.creator",
+"system.assignees", "system.labels" or "created_at" and "updated_at" truncated to the ":day", ":week" or ":month"`, func() {
+				a.Example("system.state,created_at:week")
+			})
+			a.Param("measures", d.String, `Comma separated list of at most 5 measures (defaults to count): "count", "sum:<field>"
+for numeric fields or statistics of the lead time (creation to closing) and the cycle time (first in progress to
+closing) in days of the closed work items, "lead_time" or "cycle_time" followed by ":avg", ":min", ":max", ":count"
+or a percentile like ":p85". The statistics are 0 for groups without closed work items.`, func() {
+				a.Example("count,sum:storypoints,lead_time:p50,cycle_time:p85")
+			})
+			a.Param("filter", d.String, "a query language expression restricting the set of aggregated work items")
+		})
+		a.Response(d.OK, func() {
+			a.Media(report)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,58 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+// workItemConflict is a field changed differently by a sync client and
+// someone else
+var workItemConflict = a.Type("WorkItemConflict", func() {
+	a.Attribute("field", d.String, "Name of the field", func() {
+		a.Example("system.title")
+	})
+	a.Attribute("base", d.Any, "The value of the version the changes are based on")
+	a.Attribute("ours", d.Any, "The value of the changes")
+	a.Attribute("theirs", d.Any, "The current value")
+	a.Required("field")
+})
+
+// workItemConflicts holds the conflicts of changes that can't be merged
+var workItemConflicts = a.MediaType("application/vnd.workitemconflicts+json", func() {
+	a.TypeName("WorkItemConflicts")
+	a.Description("The fields changed both by the changes and since the version the changes are based on")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(workItemConflict))
+		a.Attribute("version", d.Integer, "The current version of the work item to base the resolved changes on")
+		a.Required("data", "version")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Attribute("version")
+	})
+})
+
+var _ = a.Resource("work-item-resolve", func() {
+	a.Parent("workitem")
+
+	a.Action("resolve", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("resolve"),
+		)
+		a.Description(`Apply changes made offline by a sync client. The version attribute is the version the changes are based
+on, they are merged field by field with the changes made since: fields changed only on one side take the changed value,
+text changed on both sides is merged line by line. Responds with the saved work item if all changes could be merged,
+otherwise nothing is saved and the fields changed differently on both sides are listed with the current version to
+base the resolved changes on.`)
+		a.Payload(workItemSingle)
+		a.Response(d.OK, func() {
+			a.Media(workItemSingle)
+		})
+		a.Response(d.Conflict, workItemConflicts)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
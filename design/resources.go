@@ -39,6 +39,87 @@ var _ = a.Resource("workitemtype", func() {
 		a.Response(d.Unauthorized, JSONAPIErrors)
 	})
 
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PUT("/:name"),
+		)
+		a.Description(`Replace the fields of the work item type (instance admins only). Changed fields make
+a new version of the type, existing work items are read leniently until they are migrated.`)
+		a.Params(func() {
+			a.Param("name", d.String, "name")
+		})
+		a.Payload(UpdateWorkItemTypePayload)
+		a.Response(d.OK, func() {
+			a.Media(workItemType)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("migrate", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:name/migrate"),
+		)
+		a.Description(`Transform the work items of older versions of the type to its current version with
+the given rules (instance admins only). Work items that still don't fit stay at their version.`)
+		a.Params(func() {
+			a.Param("name", d.String, "name")
+		})
+		a.Payload(MigrateWorkItemTypePayload)
+		a.Response(d.OK, func() {
+			a.Media(workItemTypeMigration)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("impact", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:name/impact"),
+		)
+		a.Description(`Report what deleting the work item type would affect without changing anything (instance
+admins only).`)
+		a.Params(func() {
+			a.Param("name", d.String, "name")
+		})
+		a.Response(d.OK, func() {
+			a.Media(workItemTypeImpact)
+		})
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:name"),
+		)
+		a.Description(`Delete the work item type with its link types and their links (instance admins only). A type used
+by work items, rules or type hierarchies is only deleted with a target type: the work items are migrated to it like
+the migrate action without rules would, the rules and hierarchy levels are moved to it. Built-in types and types
+with subtypes can't be deleted. The response reports what was affected, see the impact action for a dry run.`)
+		a.Params(func() {
+			a.Param("name", d.String, "name")
+			a.Param("target", d.String, "The type to migrate the work items, rules and hierarchy levels to")
+		})
+		a.Response(d.OK, func() {
+			a.Media(workItemTypeImpact)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
 	a.Action("list", func() {
 		a.Routing(
 			a.GET(""),
@@ -72,6 +153,22 @@ var _ = a.Resource("user", func() {
 		a.Response(d.Unauthorized, JSONAPIErrors)
 	})
 
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH(""),
+		)
+		a.Description(`Change the preferences of the authenticated user. The timezone is used for the dates of
+the notifications and for relative date ranges like filter[updated]=this-week.`)
+		a.Payload(identity)
+		a.Response(d.OK, func() {
+			a.Media(identity)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
 })
 
 var _ = a.Resource("identity", func() {
@@ -0,0 +1,117 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var retentionPolicy = a.Type("RetentionPolicy", func() {
+	a.Description(`JSONAPI store for the data of the retention policy of a project.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("retentionpolicies")
+	})
+	a.Attribute("id", d.UUID, "ID of the project", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", retentionPolicyAttributes)
+	a.Required("type", "attributes")
+})
+
+var retentionPolicyAttributes = a.Type("RetentionPolicyAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a retention policy, days that are not set keep the history forever. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("revision-days", d.Integer, "Days after which superseded revisions of work items are removed, the latest revision always stays", func() {
+		a.Minimum(1)
+		a.Example(730)
+	})
+	a.Attribute("snapshot-days", d.Integer, "Days after which the daily state snapshots of the cumulative flow diagram are removed", func() {
+		a.Minimum(1)
+		a.Example(365)
+	})
+	a.Attribute("trash-days", d.Integer, "Days after which deleted work items are purged from the trash, not set uses the retention of the instance", func() {
+		a.Minimum(1)
+		a.Example(30)
+	})
+})
+
+var retentionPolicySingle = JSONSingle(
+	"RetentionPolicy", "Holds the retention policy of a project",
+	retentionPolicy,
+	nil)
+
+var retentionPreview = a.MediaType("application/vnd.retentionpreview+json", func() {
+	a.TypeName("RetentionPreview")
+	a.Description("What enforcing a retention policy would remove now")
+	a.Attributes(func() {
+		a.Attribute("revisions", d.Integer, "Number of removed revisions")
+		a.Attribute("snapshots", d.Integer, "Number of removed state snapshots")
+		a.Attribute("trashed", d.Integer, "Number of work items purged from the trash")
+		a.Required("revisions", "snapshots", "trashed")
+	})
+	a.View("default", func() {
+		a.Attribute("revisions")
+		a.Attribute("snapshots")
+		a.Attribute("trashed")
+	})
+})
+
+var _ = a.Resource("project-retention-policy", func() {
+	a.Parent("project")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("retention-policy"),
+		)
+		a.Description("Retrieve the policy limiting how long the history of the project is kept.")
+		a.Response(d.OK, func() {
+			a.Media(retentionPolicySingle)
+		})
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PUT("retention-policy"),
+		)
+		a.Description(`Set the policy limiting how long the history of the project is kept (project admins
+only). A scheduled job removes what is older than the policy allows.`)
+		a.Payload(retentionPolicySingle)
+		a.Response(d.OK, func() {
+			a.Media(retentionPolicySingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("retention-policy"),
+		)
+		a.Description("Keep the history of the project forever (project admins only).")
+		a.Response(d.OK)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("preview", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("retention-policy/preview"),
+		)
+		a.Description(`Count what the given policy would remove from the project if it was enforced now,
+without removing anything or storing the policy (project admins only).`)
+		a.Payload(retentionPolicySingle)
+		a.Response(d.OK, func() {
+			a.Media(retentionPreview)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,77 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var schedule = a.Type("Schedule", func() {
+	a.Description(`JSONAPI store for the data of a job schedule.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("schedules")
+	})
+	a.Attribute("id", d.String, "Name of the schedule", func() {
+		a.Example("stale-items")
+	})
+	a.Attribute("attributes", scheduleAttributes)
+	a.Required("type", "id", "attributes")
+})
+
+var scheduleAttributes = a.Type("ScheduleAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a job schedule. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("spec", d.String, "Cron spec with seconds of the times the job is enqueued", func() {
+		a.Example("0 0 * * * *")
+	})
+	a.Attribute("kind", d.String, "The kind of job the schedule enqueues", func() {
+		a.Example("workitem.stale")
+	})
+	a.Attribute("next-run-at", d.DateTime, "When the job is enqueued next")
+	a.Attribute("last-job-id", d.UUID, "ID of the job of the last run")
+	a.Attribute("last-run-at", d.DateTime, "When the last run was due")
+	a.Attribute("last-run-status", d.String, "The status of the job of the last run", func() {
+		a.Enum("pending", "running", "succeeded", "dead")
+	})
+	a.Attribute("last-run-error", d.String, "Why the last attempt of the last run failed")
+	a.Required("spec", "kind", "next-run-at")
+})
+
+var scheduleList = JSONList(
+	"Schedule", "Holds the list of job schedules",
+	schedule,
+	nil,
+	nil)
+
+var _ = a.Resource("schedules", func() {
+	a.BasePath("/schedules")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the schedules of background jobs with the status of their last run (instance admins only).")
+		a.Response(d.OK, func() {
+			a.Media(scheduleList)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("run", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:name/run"),
+		)
+		a.Params(func() {
+			a.Param("name", d.String, "name")
+		})
+		a.Description("Enqueue a run of the schedule now (instance admins only).")
+		a.Response(d.Created, func() {
+			a.Media(backgroundJobSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,71 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+// schemaFieldKind describes a kind of field type
+var schemaFieldKind = a.Type("SchemaFieldKind", func() {
+	a.Attribute("name", d.String, "The constant indicating the kind of type", func() {
+		a.Example("enum")
+	})
+	a.Attribute("simple", d.Boolean, "Whether the kind can be the component type of lists and the base type of enums")
+	a.Required("name", "simple")
+})
+
+var schema = a.MediaType("application/vnd.schema+json", func() {
+	a.TypeName("Schema")
+	a.Description("Describes the work item types, their fields and the link types of the instance")
+	a.Attributes(func() {
+		a.Attribute("kinds", a.ArrayOf(schemaFieldKind), "The kinds of field types")
+		a.Attribute("workItemTypes", a.ArrayOf(workItemType), "The work item types with their fields, the possible values of enum fields included")
+		a.Attribute("linkTypes", a.ArrayOf(workItemLinkTypeData), "The work item link types")
+		a.Attribute("transitions", a.HashOf(d.String, a.HashOf(d.String, a.ArrayOf(d.String))),
+			"The states a work item of the type can move to from each state, by the name of the work item type", func() {
+				a.Example(map[string]interface{}{"system.bug": map[string]interface{}{"new": []string{"open", "closed"}}})
+			})
+		a.Attribute("locale", d.String, "The locale of the labels, not set if none suits the request", func() {
+			a.Example("de")
+		})
+		a.Attribute("labels", a.HashOf(d.String, a.HashOf(d.String, d.String)),
+			"The labels of the type names ('type') and of the states and priorities in the locale by subject and value", func() {
+				a.Example(map[string]interface{}{"system.state": map[string]interface{}{"in progress": "In Bearbeitung"}})
+			})
+		a.Required("kinds", "workItemTypes", "linkTypes", "transitions")
+	})
+	a.View("default", func() {
+		a.Attribute("kinds")
+		a.Attribute("workItemTypes")
+		a.Attribute("linkTypes")
+		a.Attribute("transitions")
+		a.Attribute("locale")
+		a.Attribute("labels")
+	})
+})
+
+var _ = a.Resource("schema", func() {
+	a.BasePath("/schema")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description(`Describe the work item types, the field kinds, the link types and the state transitions
+in one document for clients rendering forms, with the labels of the locale that suits the
+Accept-Language header best. The response has an ETag, a request with the ETag in If-None-Match
+gets a 304 until something changes.`)
+		a.Headers(func() {
+			a.Header("If-None-Match", d.String, "The ETag of the document the client has")
+			a.Header("Accept-Language", d.String, "The languages of the client")
+		})
+		a.Response(d.OK, func() {
+			a.Media(schema)
+			a.Headers(func() {
+				a.Header("ETag", d.String, "The version of the document")
+			})
+		})
+		a.Response(d.NotModified)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+})
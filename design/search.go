@@ -5,11 +5,57 @@ import (
 	a "github.com/goadesign/goa/design/apidsl"
 )
 
+var searchMeta = a.Type("searchResponseMeta", func() {
+	a.Attribute("totalCount", d.Integer)
+	a.Attribute("facets", a.HashOf(d.String, a.HashOf(d.String, d.Integer)),
+		"Number of matching work items per type, state, labels and project, only set when the search index is enabled")
+	a.Attribute("highlights", a.HashOf(d.String, a.HashOf(d.String, a.ArrayOf(d.String))),
+		"Snippets of the matching fields per work item ID with the matches wrapped in <em>, only set if requested")
+
+	a.Required("totalCount")
+})
+
 var searchWorkItemList = JSONList(
 	"SearchWorkItem", "Holds the paginated response to a search request",
 	workItem2,
 	pagingLinks,
-	meta)
+	searchMeta)
+
+var typeaheadSuggestion = a.Type("TypeaheadSuggestion", func() {
+	a.Description("A work item or user matching the typed text")
+	a.Attribute("type", d.String, func() {
+		a.Enum("workitems", "identities")
+	})
+	a.Attribute("id", d.String, "ID of the work item or the identity", func() {
+		a.Example("42")
+	})
+	a.Attribute("attributes", typeaheadSuggestionAttributes)
+	a.Required("type", "id", "attributes")
+})
+
+var typeaheadSuggestionAttributes = a.Type("TypeaheadSuggestionAttributes", func() {
+	a.Attribute("title", d.String, "The title of the work item or the full name of the user")
+	a.Attribute("image-url", d.String, "The avatar of the user")
+	a.Required("title")
+})
+
+var typeaheadList = JSONList(
+	"TypeaheadSuggestion", "Holds the suggestions for the typed text",
+	typeaheadSuggestion,
+	nil,
+	nil)
+
+var duplicatesMeta = a.Type("duplicatesResponseMeta", func() {
+	a.Attribute("scores", a.HashOf(d.String, d.Number),
+		"How similar each work item is by its ID, only comparable within one response")
+	a.Required("scores")
+})
+
+var duplicateWorkItemList = JSONList(
+	"DuplicateWorkItem", "Holds the work items that may be duplicates, most similar first",
+	workItem2,
+	nil,
+	duplicatesMeta)
 
 var _ = a.Resource("search", func() {
 	a.BasePath("/search")
@@ -25,9 +71,16 @@ var _ = a.Resource("search", func() {
 				1) "id:100" :- Look for work item hainvg id 100
 				2) "url:http://demo.almighty.io/details/500" :- Search on WI having id 500 and check 
 					if this URL is mentioned in searchable columns of work item
-				3) "simple keywords seperated by space" :- Search in Work Items based on these keywords.`)
+				3) "simple keywords seperated by space" :- Search in Work Items based on these keywords.
+				4) "type:system.bug" :- Search for work items of the type or its subtypes.
+				With the search index enabled, "state:", "label:", "project:" and "assignee:" also
+				filter by the given value.`)
 			a.Param("page[offset]", d.String, "Paging start position") // #428
 			a.Param("page[limit]", d.Integer, "Paging size")
+			a.Param("rank[title]", d.Number, "How much more a match in the title counts than one in the description (0 to 5, defaults to 2)")
+			a.Param("rank[recency]", d.Number, "Boost of recently updated work items, a work item updated just now ranks up to 1 + boost times higher (0 to 10, defaults to 0)")
+			a.Param("highlight", d.Boolean, "Return snippets of the matching fields in the meta data")
+			a.Param("filter[archived]", d.Boolean, "Search the archived instead of the active Work Items")
 			a.Required("q")
 		})
 		a.Response(d.OK, func() {
@@ -36,4 +89,49 @@ var _ = a.Resource("search", func() {
 		a.Response(d.BadRequest, JSONAPIErrors)
 		a.Response(d.InternalServerError, JSONAPIErrors)
 	})
+
+	a.Action("typeahead", func() {
+		a.Routing(
+			a.GET("/typeahead"),
+		)
+		a.Description(`Suggest work items by the start of their ID or by a part of their title and users by a
+part of their name or the start of their email, used by the pickers for links and mentions.`)
+		a.Params(func() {
+			a.Param("q", d.String, "The text typed so far, a number with or without a leading # matches work item IDs", func() {
+				a.MinLength(1)
+			})
+			a.Param("type", d.String, "Only suggest work items or users", func() {
+				a.Enum("workitem", "user")
+			})
+			a.Param("page[limit]", d.Integer, "Number of suggestions per type (1 to 25, defaults to 10)")
+			a.Required("q")
+		})
+		a.Response(d.OK, func() {
+			a.Media(typeaheadList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("duplicates", func() {
+		a.Routing(
+			a.GET("/duplicates"),
+		)
+		a.Description(`Suggest existing work items with a similar title and description, used to find
+duplicates before filing a new work item.`)
+		a.Params(func() {
+			a.Param("title", d.String, "The title of the new work item", func() {
+				a.MinLength(1)
+			})
+			a.Param("description", d.String, "The description of the new work item")
+			a.Param("type", d.String, "Only suggest work items of this type or its subtypes")
+			a.Param("page[limit]", d.Integer, "Number of suggestions (1 to 25, defaults to 5)")
+			a.Required("title")
+		})
+		a.Response(d.OK, func() {
+			a.Media(duplicateWorkItemList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
 })
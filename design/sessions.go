@@ -0,0 +1,104 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var session = a.MediaType("application/vnd.session+json", func() {
+	a.TypeName("Session")
+	a.Description("A token issued to an identity")
+	a.Attributes(func() {
+		a.Attribute("id", d.UUID, "ID of the session, the jti claim of its token")
+		a.Attribute("identity", d.UUID, "ID of the identity the token was issued to")
+		a.Attribute("impersonator", d.UUID, "ID of the instance admin acting as the identity, missing unless the token is an impersonation")
+		a.Attribute("user-agent", d.String, "The client the token was issued to")
+		a.Attribute("created-at", d.DateTime, "When the token was issued")
+		a.Attribute("expires-at", d.DateTime, "When the token expires, missing if it doesn't")
+		a.Attribute("last-used-at", d.DateTime, "When the token was last used, to the minute")
+		a.Attribute("current", d.Boolean, "True if the request was made with the token of the session")
+		a.Required("id", "identity", "user-agent", "created-at", "current")
+	})
+	a.View("default", func() {
+		a.Attribute("id")
+		a.Attribute("identity")
+		a.Attribute("impersonator")
+		a.Attribute("user-agent")
+		a.Attribute("created-at")
+		a.Attribute("expires-at")
+		a.Attribute("last-used-at")
+		a.Attribute("current")
+	})
+})
+
+var sessionList = a.MediaType("application/vnd.sessionlist+json", func() {
+	a.TypeName("SessionList")
+	a.Description("A page of the active sessions of an identity, the latest first")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(session))
+		a.Attribute("links", pagingLinks)
+		a.Attribute("meta", projectListMeta)
+		a.Required("data", "links", "meta")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Attribute("links")
+		a.Attribute("meta")
+	})
+})
+
+var _ = a.Resource("sessions", func() {
+	a.BasePath("/sessions")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description(`List the tokens of the current identity that are neither revoked nor expired, the latest first.
+Instance admins can list the ones of any identity.`)
+		a.Params(func() {
+			a.Param("filter[identity]", d.UUID, "List the sessions of the identity instead of the current one (instance admins only)")
+			a.Param("page[offset]", d.String, "Paging start position")
+			a.Param("page[limit]", d.Integer, "Paging size")
+		})
+		a.Response(d.OK, sessionList)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("revoke", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:sessionID"),
+		)
+		a.Description(`Revoke a token of the current identity, requests made with it are refused right away.
+Instance admins can revoke the tokens of any identity, impersonation tokens can't revoke any.`)
+		a.Params(func() {
+			a.Param("sessionID", d.UUID, "ID of the session")
+		})
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("revoke-all", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE(""),
+		)
+		a.Description(`Revoke all the tokens of the current identity but the one the request is made with. Tokens
+issued before sessions were tracked are all revoked, the one the request is made with included. Instance admins
+can revoke all the tokens of any identity, impersonation tokens can't revoke any.`)
+		a.Params(func() {
+			a.Param("filter[identity]", d.UUID, "Revoke the sessions of the identity instead of the current one (instance admins only)")
+		})
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,101 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var setting = a.Type("Setting", func() {
+	a.Description(`JSONAPI store for the data of a runtime setting.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("settings")
+	})
+	a.Attribute("id", d.String, "Name of the tunable configuration variable", func() {
+		a.Example("paging.size.default")
+	})
+	a.Attribute("attributes", settingAttributes)
+	a.Required("type", "attributes")
+})
+
+var settingAttributes = a.Type("SettingAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a setting. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("value", d.String, "The current value of the variable", func() {
+		a.Example("50")
+	})
+	a.Attribute("overridden", d.Boolean, "Whether the value is set at runtime instead of by the configuration (read-only)")
+	a.Required("value")
+})
+
+var settingList = JSONList(
+	"Setting", "Holds the list of tunable configuration variables",
+	setting,
+	nil,
+	nil)
+
+var settingSingle = JSONSingle(
+	"Setting", "Holds a single setting",
+	setting,
+	nil)
+
+var _ = a.Resource("settings", func() {
+	a.BasePath("/settings")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the configuration variables that can be changed at runtime with their current values (instance admins only).")
+		a.Response(d.OK, func() {
+			a.Media(settingList)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PUT("/:name"),
+		)
+		a.Params(func() {
+			a.Param("name", d.String, "name")
+		})
+		a.Description("Override a configuration variable at runtime, the change applies immediately (instance admins only).")
+		a.Payload(settingSingle)
+		a.Response(d.OK, func() {
+			a.Media(settingSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:name"),
+		)
+		a.Params(func() {
+			a.Param("name", d.String, "name")
+		})
+		a.Description("Remove the runtime override of a configuration variable (instance admins only).")
+		a.Response(d.OK)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("reload", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/reload"),
+		)
+		a.Description(`Read the tunable configuration variables from the config file and the runtime settings again
+(instance admins only). Sending SIGHUP to the server does the same.`)
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
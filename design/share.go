@@ -0,0 +1,127 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var shareLink = a.Type("ShareLink", func() {
+	a.Attribute("id", d.UUID, "ID of the share link")
+	a.Attribute("url", d.String, "The URL of the work item, anyone knowing it can read the work item and its comments", func() {
+		a.Example("https://api.almighty.io/api/shared/40bbdd3d-8b5d-4fd6-ac90-7236b669af04.1483225200.o2f1Qz0jVg")
+	})
+	a.Attribute("expires-at", d.DateTime, "When the link stops working")
+	a.Attribute("created-at", d.DateTime, "When the link was created")
+	a.Required("id", "url", "expires-at", "created-at")
+})
+
+var shareLinkSingle = a.MediaType("application/vnd.sharelink+json", func() {
+	a.TypeName("ShareLinkSingle")
+	a.Description("A share link of a work item")
+	a.Attributes(func() {
+		a.Attribute("data", shareLink)
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+	})
+})
+
+var shareLinkList = a.MediaType("application/vnd.sharelinks+json", func() {
+	a.TypeName("ShareLinkList")
+	a.Description("The share links of a work item")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(shareLink))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+	})
+})
+
+var sharedWorkItem = a.MediaType("application/vnd.sharedworkitem+json", func() {
+	a.TypeName("SharedWorkItem")
+	a.Description("A work item and its comments read with a share link")
+	a.Attributes(func() {
+		a.Attribute("work-item", workItem2, "The shared work item")
+		a.Attribute("comments", a.ArrayOf(comment), "The comments of the work item, the oldest first")
+		a.Attribute("expires-at", d.DateTime, "When the link stops working")
+		a.Required("work-item", "comments", "expires-at")
+	})
+	a.View("default", func() {
+		a.Attribute("work-item")
+		a.Attribute("comments")
+		a.Attribute("expires-at")
+	})
+})
+
+var _ = a.Resource("work-item-shares", func() {
+	a.Parent("workitem")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("shares"),
+		)
+		a.Description("List the share links of the given work item the current user created that didn't expire yet.")
+		a.Response(d.OK, shareLinkList)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("shares"),
+		)
+		a.Description(`Create a link that grants read access to the given work item and its comments without logging
+in, e.g. for external stakeholders. Readers see what the current user can see, the link stops working when it
+expires, when it is revoked or when the current user loses access to the work item.`)
+		a.Params(func() {
+			a.Param("days", d.Integer, "Days the link is valid", func() {
+				a.Minimum(1)
+				a.Default(7)
+			})
+		})
+		a.Response(d.OK, shareLinkSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.ServiceUnavailable, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("revoke", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("shares/:shareID"),
+		)
+		a.Description("Revoke a share link of the given work item, only the user that created it can revoke it.")
+		a.Params(func() {
+			a.Param("shareID", d.UUID, "ID of the share link")
+		})
+		a.Response(d.NoContent)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("shared", func() {
+	a.BasePath("/shared")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("/:token"),
+		)
+		a.Description(`Read the work item and the comments of a share link. The token authenticates the request,
+no bearer token is needed.`)
+		a.Params(func() {
+			a.Param("token", d.String, "The signed token of the share link")
+		})
+		a.Response(d.OK, sharedWorkItem)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,81 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var stalePolicy = a.Type("StalePolicy", func() {
+	a.Description(`JSONAPI store for the data of the stale policy of a project.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("stalepolicies")
+	})
+	a.Attribute("id", d.UUID, "ID of the project", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", stalePolicyAttributes)
+	a.Required("type", "attributes")
+})
+
+var stalePolicyAttributes = a.Type("StalePolicyAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a stale policy. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("inactive-days", d.Integer, "Days without updates or comments after which a work item is flagged as stale", func() {
+		a.Minimum(1)
+		a.Example(30)
+	})
+	a.Attribute("grace-days", d.Integer, "Days after which a stale work item is moved to the inactive state, not set leaves stale work items in their state", func() {
+		a.Minimum(0)
+		a.Example(7)
+	})
+	a.Required("inactive-days")
+})
+
+var stalePolicySingle = JSONSingle(
+	"StalePolicy", "Holds the stale policy of a project",
+	stalePolicy,
+	nil)
+
+var _ = a.Resource("project-stale-policy", func() {
+	a.Parent("project")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("stale-policy"),
+		)
+		a.Description("Retrieve the policy flagging the stale work items of the project.")
+		a.Response(d.OK, func() {
+			a.Media(stalePolicySingle)
+		})
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PUT("stale-policy"),
+		)
+		a.Description(`Set the policy flagging the stale work items of the project (project admins only). The
+assignees of stale work items are notified.`)
+		a.Payload(stalePolicySingle)
+		a.Response(d.OK, func() {
+			a.Media(stalePolicySingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("stale-policy"),
+		)
+		a.Description("Stop flagging the stale work items of the project (project admins only).")
+		a.Response(d.OK)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,98 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var translation = a.Type("Translation", func() {
+	a.Description(`JSONAPI store for the data of a translation.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("translations")
+	})
+	a.Attribute("attributes", translationAttributes)
+	a.Required("type", "attributes")
+})
+
+var translationAttributes = a.Type("TranslationAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a translation. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("subject", d.String, "What is translated: 'type' for the names of the work item types, or the field whose values are translated", func() {
+		a.Enum("type", "system.state", "system.priority")
+	})
+	a.Attribute("value", d.String, "The translated type name or field value", func() {
+		a.Example("in progress")
+	})
+	a.Attribute("label", d.String, "The label of the value in the locale", func() {
+		a.Example("In Bearbeitung")
+	})
+	a.Required("subject", "value", "label")
+})
+
+var translationListMeta = a.Type("TranslationListMeta", func() {
+	a.Attribute("locale", d.String, "The locale of the translations, empty if none suits the request", func() {
+		a.Example("de")
+	})
+	a.Attribute("locales", a.ArrayOf(d.String), "The locales with translations")
+	a.Required("locale", "locales")
+})
+
+var translationList = JSONList(
+	"Translation", "Holds the translations of a locale",
+	translation,
+	nil,
+	translationListMeta)
+
+var _ = a.Resource("translations", func() {
+	a.BasePath("/translations")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description(`List the translations of the locale that suits the Accept-Language header best, or of the
+given locale. The list is empty if there is no translation for any of the accepted languages.`)
+		a.Headers(func() {
+			a.Header("Accept-Language", d.String, "The languages of the client")
+		})
+		a.Params(func() {
+			a.Param("locale", d.String, "The locale of the translations, overrides Accept-Language")
+		})
+		a.Response(d.OK, func() {
+			a.Media(translationList)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PUT("/:locale"),
+		)
+		a.Params(func() {
+			a.Param("locale", d.String, "A language tag like 'de' or 'pt-BR'")
+		})
+		a.Description("Replace the translations of a locale (instance admins only).")
+		a.Payload(translationList)
+		a.Response(d.OK, func() {
+			a.Media(translationList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:locale"),
+		)
+		a.Params(func() {
+			a.Param("locale", d.String, "A language tag like 'de' or 'pt-BR'")
+		})
+		a.Description("Remove the translations of a locale (instance admins only).")
+		a.Response(d.OK)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,80 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var trashedWorkItem = a.Type("TrashedWorkItem", func() {
+	a.Attribute("work-item", workItem2, "The deleted work item")
+	a.Attribute("deleted-at", d.DateTime, "When the work item was deleted")
+	a.Attribute("deleted-by", d.UUID, "The identity that deleted the work item, not set for system tasks")
+	a.Attribute("purge-at", d.DateTime, "When the work item is permanently deleted unless it is restored before")
+	a.Required("work-item", "deleted-at", "purge-at")
+})
+
+var trash = a.MediaType("application/vnd.trash+json", func() {
+	a.TypeName("Trash")
+	a.Description("The deleted work items of a project")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(trashedWorkItem), "The deleted work items, the latest deleted first")
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+	})
+})
+
+var _ = a.Resource("project-trash", func() {
+	a.Parent("project")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("trash"),
+		)
+		a.Description(`List the deleted work items of the project that are not purged yet.`)
+		a.Response(d.OK, func() {
+			a.Media(trash)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("restore", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("trash/:workItemID/restore"),
+		)
+		a.Description(`Take a deleted work item out of the trash, the links its deletion removed are restored
+if their other end still exists.`)
+		a.Params(func() {
+			a.Param("workItemID", d.String, "ID of the deleted work item")
+		})
+		a.Response(d.OK, func() {
+			a.Media(workItemSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("purge", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("trash/:workItemID"),
+		)
+		a.Description(`Permanently delete a deleted work item with its history, links and comments (project admins only).`)
+		a.Params(func() {
+			a.Param("workItemID", d.String, "ID of the deleted work item")
+		})
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
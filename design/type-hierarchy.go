@@ -0,0 +1,78 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var typeHierarchy = a.Type("TypeHierarchy", func() {
+	a.Description(`JSONAPI store for the data of the work item type hierarchy of a project.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("typehierarchies")
+	})
+	a.Attribute("id", d.UUID, "ID of the project", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", typeHierarchyAttributes)
+	a.Required("type", "attributes")
+})
+
+var typeHierarchyAttributes = a.Type("TypeHierarchyAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a type hierarchy. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("levels", a.ArrayOf(d.String), "The work item types of the hierarchy, the top level first", func() {
+		a.MinLength(2)
+		a.Example([]string{"epic", "feature", "story", "task"})
+	})
+	a.Required("levels")
+})
+
+var typeHierarchySingle = JSONSingle(
+	"TypeHierarchy", "Holds the work item type hierarchy of a project",
+	typeHierarchy,
+	nil)
+
+var _ = a.Resource("project-type-hierarchy", func() {
+	a.Parent("project")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("type-hierarchy"),
+		)
+		a.Description("Retrieve the work item type hierarchy of the project.")
+		a.Response(d.OK, func() {
+			a.Media(typeHierarchySingle)
+		})
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PUT("type-hierarchy"),
+		)
+		a.Description(`Set the work item type hierarchy of the project (project admins only). Work items of the
+types of the hierarchy can only be linked with tree link types to children of the type one level below, work items
+of other types are not restricted. Existing links are kept.`)
+		a.Payload(typeHierarchySingle)
+		a.Response(d.OK, func() {
+			a.Media(typeHierarchySingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("type-hierarchy"),
+		)
+		a.Description("Stop restricting the parents and children of the work items of the project (project admins only).")
+		a.Response(d.OK)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
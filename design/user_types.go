@@ -64,6 +64,31 @@ var CreateWorkItemTypePayload = a.Type("CreateWorkItemTypePayload", func() {
 	a.Required("name", "fields")
 })
 
+// UpdateWorkItemTypePayload defines the structure of the payload replacing the fields of a work item type
+var UpdateWorkItemTypePayload = a.Type("UpdateWorkItemTypePayload", func() {
+	a.Attribute("version", d.Integer, "Version of the work item type the fields are based on")
+	a.Attribute("fields", a.HashOf(d.String, fieldDefinition), "All fields of the next version of the type, including the inherited ones", func() {
+		a.MinLength(1)
+	})
+	a.Required("version", "fields")
+})
+
+// fieldMigrationRule tells how a field gets its value from work items of an older version of their type
+var fieldMigrationRule = a.Type("FieldMigrationRule", func() {
+	a.Attribute("from", d.String, "The field of the older version the value is taken from, the field itself if not set", func() {
+		a.Example("system.severity")
+	})
+	a.Attribute("values", a.HashOf(d.String, d.Any), "Maps old values to new ones, other values are kept", func() {
+		a.Example(map[string]interface{}{"blocker": "urgent"})
+	})
+	a.Attribute("default", d.Any, "The value of work items without a value")
+})
+
+// MigrateWorkItemTypePayload defines the structure of the payload migrating work items to the current version of their type
+var MigrateWorkItemTypePayload = a.Type("MigrateWorkItemTypePayload", func() {
+	a.Attribute("rules", a.HashOf(d.String, fieldMigrationRule), "The rules of the fields of the current version by their name")
+})
+
 // CreateTrackerAlternatePayload defines the structure of tracker payload for create
 var CreateTrackerAlternatePayload = a.Type("CreateTrackerAlternatePayload", func() {
 	a.Attribute("url", d.String, "URL of the tracker", func() {
@@ -134,6 +159,9 @@ var UpdateTrackerQueryAlternatePayload = a.Type("UpdateTrackerQueryAlternatePayl
 var identityDataAttributes = a.Type("IdentityDataAttributes", func() {
 	a.Attribute("fullName", d.String, "The users full name")
 	a.Attribute("imageURL", d.String, "The avatar image for the user")
+	a.Attribute("timezone", d.String, "The IANA timezone the user sees dates in, empty for UTC. Only returned for the authenticated user", func() {
+		a.Example("Europe/Berlin")
+	})
 })
 
 // identityData represents an identified user object
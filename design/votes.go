@@ -0,0 +1,77 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var votes = a.Type("Votes", func() {
+	a.Description(`JSONAPI store for the votes of a work item.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("votes")
+	})
+	a.Attribute("id", d.String, "ID of the work item", func() {
+		a.Example("42")
+	})
+	a.Attribute("attributes", votesAttributes)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var votesAttributes = a.Type("VotesAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of votes. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("count", d.Integer, "The number of identities that voted for the work item")
+	a.Attribute("voted", d.Boolean, "True if the current user voted for the work item")
+})
+
+var votesSingle = JSONSingle(
+	"Votes", "Holds the votes of a work item",
+	votes,
+	nil)
+
+var _ = a.Resource("work-item-votes", func() {
+	a.Parent("workitem")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("votes"),
+		)
+		a.Description("Retrieve the number of votes of the given work item.")
+		a.Response(d.OK, func() {
+			a.Media(votesSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("votes"),
+		)
+		a.Description("Vote for the given work item, voting again has no effect.")
+		a.Response(d.OK, func() {
+			a.Media(votesSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("votes"),
+		)
+		a.Description("Remove the vote of the current user for the given work item.")
+		a.Response(d.OK, func() {
+			a.Media(votesSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,87 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var workItemEvent = a.Type("WorkItemEvent", func() {
+	a.Description(`JSONAPI store for the data of a work item event.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("workitemevents")
+	})
+	a.Attribute("id", d.UUID, "ID of the event", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", workItemEventAttributes)
+	a.Required("type", "id", "attributes")
+})
+
+var workItemEventAttributes = a.Type("WorkItemEventAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a work item event. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("sequence", d.Integer, "Position of the event in the feed")
+	a.Attribute("kind", d.String, "What happened to the work item", func() {
+		a.Enum("create", "update", "delete", "restore")
+	})
+	a.Attribute("workitem", d.String, "ID of the work item", func() {
+		a.Example("42")
+	})
+	a.Attribute("version", d.Integer, "The version of the work item after the change")
+	a.Attribute("created-at", d.DateTime, "When the change was made")
+	a.Attribute("modifier", d.UUID, "The identity that made the change, not set for system tasks")
+	a.Required("sequence", "kind", "workitem", "version", "created-at")
+})
+
+var workItemEventList = JSONList(
+	"WorkItemEvent", "Holds the list of work item events",
+	workItemEvent,
+	nil,
+	nil)
+
+var workItemRebuild = a.MediaType("application/vnd.workitemrebuild+json", func() {
+	a.TypeName("WorkItemRebuild")
+	a.Description("The result of rebuilding the work items from their events")
+	a.Attributes(func() {
+		a.Attribute("rebuilt", d.Integer, "Number of work items that were out of date")
+		a.Required("rebuilt")
+	})
+	a.View("default", func() {
+		a.Attribute("rebuilt")
+	})
+})
+
+var _ = a.Resource("work-item-events", func() {
+	a.BasePath("/events")
+
+	a.Action("feed", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description(`List the events of all work items in the order they happened (instance admins only).
+Pass the sequence of the last event received as 'after' to read the feed from there.`)
+		a.Params(func() {
+			a.Param("after", d.Integer, "Only list the events after the given sequence")
+			a.Param("page[limit]", d.Integer, "Paging size")
+		})
+		a.Response(d.OK, func() {
+			a.Media(workItemEventList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("rebuild", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/rebuild"),
+		)
+		a.Description("Set all work items to the state of their latest event (instance admins only).")
+		a.Response(d.OK, func() {
+			a.Media(workItemRebuild)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -168,12 +168,15 @@ var _ = a.Resource("work-item-link-category", func() {
 		a.Routing(
 			a.DELETE("/:id"),
 		)
-		a.Description("Delete work item link category with given id.")
+		a.Description(`Delete work item link category with given id. If the category still has link types the request fails
+with a conflict unless "reassignTo" names the category to move them to.`)
 		a.Params(func() {
 			a.Param("id", d.String, "id")
+			a.Param("reassignTo", d.String, "ID of the work item link category that takes over the link types")
 		})
 		a.Response(d.OK)
 		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
 		a.Response(d.InternalServerError, JSONAPIErrors)
 		a.Response(d.NotFound, JSONAPIErrors)
 		a.Response(d.Unauthorized, JSONAPIErrors)
@@ -65,6 +65,11 @@ For example, if a bug blocks a user story, the reverse name name is "blocked by"
 	a.Attribute("topology", d.String, `The topology determines the restrictions placed on the usage of each work item link type.`, func() {
 		a.Enum("network")
 	})
+	a.Attribute("on_delete", d.String, `What happens to the links of this type when a work item is deleted: "detach" deletes the links,
+"cascade" also deletes the targets of the links the work item is the source of and "block" refuses to delete work items
+with links of this type (defaults to "detach").`, func() {
+		a.Enum("detach", "cascade", "block")
+	})
 
 	// IMPORTANT: We cannot require any field here because these "attributes" will be used
 	// during the creation as well as the update of a work item link type.
@@ -202,12 +207,15 @@ var _ = a.Resource("work-item-link-type", func() {
 		a.Routing(
 			a.DELETE("/:id"),
 		)
-		a.Description("Delete work item link type with given id.")
+		a.Description(`Delete work item link type with given id. If links of the type exist the request fails with a conflict
+unless "force" is set, then the links are deleted together with the link type.`)
 		a.Params(func() {
 			a.Param("id", d.String, "id")
+			a.Param("force", d.Boolean, "Delete the links of the type as well")
 		})
 		a.Response(d.OK)
 		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
 		a.Response(d.InternalServerError, JSONAPIErrors)
 		a.Response(d.NotFound, JSONAPIErrors)
 		a.Response(d.Unauthorized, JSONAPIErrors)
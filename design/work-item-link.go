@@ -56,6 +56,13 @@ See also see http://jsonapi.org/format/#document-resource-object-attributes`)
 	a.Attribute("version", d.Integer, "Version for optimistic concurrency control (optional during creating)", func() {
 		a.Example(0)
 	})
+	a.Attribute("note", d.String, "A short comment on the link", func() {
+		a.Example("blocks until API freeze")
+		a.MaxLength(500)
+	})
+	a.Attribute("metadata", a.HashOf(d.String, d.String), "Key/value pairs annotating the link, replaced as a whole on update", func() {
+		a.Example(map[string]string{"milestone": "API freeze"})
+	})
 	// IMPORTANT: We cannot require any field here because these "attributes" will be used
 	// during the creation as well as the update of a work item link type.
 	// During creation, the "name" field is required but not during update.
@@ -124,6 +131,40 @@ var _ = a.Resource("work-item-link", func() {
 	a.Action("create", createWorkItemLink)
 	a.Action("delete", deleteWorkItemLink)
 	a.Action("update", updateWorkItemLink)
+	a.Action("bulk-delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE(""),
+		)
+		a.Description(`Delete all links matching the filters in one transaction, e.g. before retiring a link type
+(instance admins only). At least one filter is required. With dry-run the matching links are only counted.`)
+		a.Params(func() {
+			a.Param("filter[link-type]", d.UUID, "Only delete the links of the given link type")
+			a.Param("filter[source]", d.String, "Only delete the links from the given work item")
+			a.Param("filter[created-before]", d.DateTime, "Only delete the links created before the given time")
+			a.Param("dry-run", d.Boolean, "Count the matching links without deleting them")
+		})
+		a.Response(d.OK, func() {
+			a.Media(linkDeletion)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
+
+var linkDeletion = a.MediaType("application/vnd.linkdeletion+json", func() {
+	a.TypeName("LinkDeletion")
+	a.Description("The outcome of a bulk deletion of work item links")
+	a.Attributes(func() {
+		a.Attribute("count", d.Integer, "The number of deleted links, or of matching links in a dry run")
+		a.Attribute("dry-run", d.Boolean, "True if the links were only counted")
+		a.Required("count", "dry-run")
+	})
+	a.View("default", func() {
+		a.Attribute("count")
+		a.Attribute("dry-run")
+	})
 })
 
 var _ = a.Resource("work-item-relationships-links", func() {
@@ -135,6 +176,13 @@ var _ = a.Resource("work-item-relationships-links", func() {
 	a.Action("list", func() {
 		listWorkItemLinks()
 		a.Description("List work item links associated with the given work item (either as source or as target work item).")
+		a.Params(func() {
+			a.Param("filter[direction]", d.String, "Only list the links having the work item as source (outgoing) or as target (incoming)", func() {
+				a.Enum("outgoing", "incoming")
+			})
+			a.Param("filter[link-type]", d.UUID, "Only list the links of the given link type")
+			a.Param("filter[target-type]", d.String, "Only list the links whose other work item is of the given type")
+		})
 		a.Response(d.NotFound, JSONAPIErrors, func() {
 			a.Description("This error arises when the given work item does not exist.")
 		})
@@ -0,0 +1,86 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var workItemRevision = a.Type("WorkItemRevision", func() {
+	a.Description(`JSONAPI store for the data of a work item revision.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("workitemrevisions")
+	})
+	a.Attribute("id", d.UUID, "ID of the revision", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", workItemRevisionAttributes)
+	a.Required("type", "id", "attributes")
+})
+
+var workItemRevisionAttributes = a.Type("WorkItemRevisionAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a work item revision. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("kind", d.String, "What happened to the work item", func() {
+		a.Enum("create", "update", "delete", "restore")
+	})
+	a.Attribute("version", d.Integer, "The version of the work item after the change")
+	a.Attribute("created-at", d.DateTime, "When the change was made")
+	a.Attribute("modifier", d.UUID, "The identity that made the change, not set for system tasks")
+	a.Required("kind", "version", "created-at")
+})
+
+var workItemRevisionList = JSONList(
+	"WorkItemRevision", "Holds the list of revisions of a work item",
+	workItemRevision,
+	nil,
+	nil)
+
+var _ = a.Resource("work-item-revisions", func() {
+	a.Parent("workitem")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("revisions"),
+		)
+		a.Description("List the revisions of the given work item, oldest first.")
+		a.Response(d.OK, func() {
+			a.Media(workItemRevisionList)
+		})
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("snapshot", func() {
+		a.Routing(
+			a.GET("snapshot"),
+		)
+		a.Description("Retrieve the given work item as it was at the given time.")
+		a.Params(func() {
+			a.Param("at", d.DateTime, "The point in time")
+			a.Required("at")
+		})
+		a.Response(d.OK, func() {
+			a.Media(workItemSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("restore", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("revisions/:version/restore"),
+		)
+		a.Description("Set the fields of the given work item to the ones of an earlier version, creating a new version.")
+		a.Params(func() {
+			a.Param("version", d.Integer, "The version to restore")
+		})
+		a.Response(d.OK, func() {
+			a.Media(workItemSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
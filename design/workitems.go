@@ -28,6 +28,10 @@ var workItemRelationships = a.Type("WorkItemRelationships", func() {
 	a.Attribute("baseType", relationBaseType, "This defines type of Work Item")
 	a.Attribute("comments", relationGeneric, "This defines comments on the Work Item")
 	a.Attribute("iteration", relationGeneric, "This defines the iteration this work item belong to")
+	a.Attribute("release", relationGeneric, "This defines the release this work item is planned for")
+	a.Attribute("project", relationGeneric, "This defines the project this work item belongs to")
+	a.Attribute("votes", relationGeneric, "This defines the votes for the Work Item, the meta holds the vote count")
+	a.Attribute("remote-links", relationGeneric, "This defines the links to external resources of the Work Item")
 })
 
 // relationBaseType is top level block for WorkItemType relationship
@@ -68,6 +72,41 @@ var workItemSingle = JSONSingle(
 	workItem2,
 	workItemLinks)
 
+// workItemChange is the version of a changed work item
+var workItemChange = a.Type("WorkItemChange", func() {
+	a.Attribute("id", d.String, "ID of the work item", func() {
+		a.Example("42")
+	})
+	a.Attribute("version", d.Integer, "The current version of the work item")
+	a.Attribute("updated-at", d.DateTime, "When the work item was last changed", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("created", d.Boolean, "Whether the work item was created since the checkpoint or the token")
+	a.Attribute("archived", d.Boolean, "Whether the work item is archived")
+	a.Attribute("deleted", d.Boolean, "Whether the work item was moved to the trash")
+	a.Required("id", "version", "updated-at", "created", "archived", "deleted")
+})
+
+// workItemChanges holds the changed work items and the checkpoint and the
+// token to ask for the next changes
+var workItemChanges = a.MediaType("application/vnd.workitemchanges+json", func() {
+	a.TypeName("WorkItemChanges")
+	a.Description("The work items changed since a checkpoint or a change token")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(workItemChange))
+		a.Attribute("included", a.ArrayOf(workItem2), "The changed work items that are not deleted if they were asked for")
+		a.Attribute("checkpoint", d.DateTime, "The since parameter of the next request")
+		a.Attribute("token", d.String, "The opaque token parameter of the next request")
+		a.Required("data", "checkpoint", "token")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Attribute("included")
+		a.Attribute("checkpoint")
+		a.Attribute("token")
+	})
+})
+
 // new version of "list" for migration
 var _ = a.Resource("workitem", func() {
 	a.BasePath("/workitems")
@@ -96,6 +135,16 @@ var _ = a.Resource("workitem", func() {
 			a.Param("page[offset]", d.String, "Paging start position")
 			a.Param("page[limit]", d.Integer, "Paging size")
 			a.Param("filter[assignee]", d.String, "Work Items assigned to the given user")
+			a.Param("filter[deployed-to]", d.String, "Work Items included in a deployment to the given environment")
+			a.Param("filter[project]", d.UUID, "Work Items belonging to the given project")
+			a.Param("filter[archived]", d.Boolean, "List the archived instead of the active Work Items")
+			a.Param("filter[drafts]", d.Boolean, "List the drafts of the authenticated user instead of the published Work Items")
+			a.Param("filter[updated]", d.String, "Work Items changed last in the given range, in the timezone of the authenticated user", func() {
+				a.Enum("today", "yesterday", "this-week", "last-week", "this-month", "last-month")
+			})
+			a.Param("sort", d.String, `Comma separated list of fields to sort by, a leading "-" sorts descending.
+Priority and severity are sorted by the order of their values in the project given by filter[project],
+"votes" sorts by the number of votes and "updated" by the time of the last change.`)
 		})
 		a.Response(d.OK, func() {
 			a.Media(workItemList)
@@ -103,12 +152,55 @@ var _ = a.Resource("workitem", func() {
 		a.Response(d.BadRequest, JSONAPIErrors)
 		a.Response(d.InternalServerError, JSONAPIErrors)
 	})
+	a.Action("changes", func() {
+		a.Routing(
+			a.GET("/changes"),
+		)
+		a.Description(`List the ID, version and time of the last change of all work items matching the filters that were
+created, changed or deleted since the given checkpoint or change token, for sync clients to find out which work items
+to fetch again. Work items moved to the trash since are listed as deleted, archived work items are always listed and
+flagged. Without checkpoint and token all work items are listed. The response holds the checkpoint and the token of
+the next request, they lag a bit behind so work items may be listed again. The token is based on the recorded
+changes of the work items, unlike the checkpoint it doesn't depend on clocks.`)
+		a.Params(func() {
+			a.Param("filter", d.String, "a query language expression restricting the set of found work items")
+			a.Param("filter[project]", d.UUID, "Work Items belonging to the given project")
+			a.Param("since", d.DateTime, "The checkpoint returned by the previous request")
+			a.Param("token", d.String, "The change token returned by the previous request")
+			a.Param("include", d.String, "Include the changed work items that are not deleted", func() {
+				a.Enum("workitems")
+			})
+		})
+		a.Response(d.OK, workItemChanges)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+	a.Action("export", func() {
+		a.Routing(
+			a.GET("/export"),
+		)
+		a.Description(`Export all work items matching the filters as JSON Lines (application/x-ndjson), one work item per
+line in the representation of the show action, without paging. The work items are written while they are read, so
+the export starts right away and a slow client slows down reading instead of the server buffering the work items.`)
+		a.Params(func() {
+			a.Param("filter", d.String, "a query language expression restricting the set of found work items")
+			a.Param("filter[assignee]", d.String, "Work Items assigned to the given user")
+			a.Param("filter[project]", d.UUID, "Work Items belonging to the given project")
+			a.Param("filter[archived]", d.Boolean, "Export the archived instead of the active Work Items")
+			a.Param("sort", d.String, `Comma separated list of fields to sort by like in the list action, work items are
+sorted by ID by default.`)
+		})
+		a.Response(d.OK, "application/x-ndjson")
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
 	a.Action("create", func() {
 		a.Security("jwt")
 		a.Routing(
 			a.POST(""),
 		)
-		a.Description("create work item with type and id.")
+		a.Description(`create work item with type and id. The type may be left out if the project of the work item has a
+default work item type, see the project settings.`)
 		a.Payload(workItemSingle)
 		a.Response(d.Created, "/workitems/.*", func() {
 			a.Media(workItemSingle)
@@ -137,7 +229,9 @@ var _ = a.Resource("workitem", func() {
 		a.Routing(
 			a.PATCH("/:id"),
 		)
-		a.Description("update the work item with the given id.")
+		a.Description(`update the work item with the given id. The version attribute is the revision the update is based on,
+if the work item was changed since, changes of the description are merged with the concurrent ones as long as they
+don't touch the same lines and no other field of the update was changed concurrently, otherwise the update fails.`)
 		a.Params(func() {
 			a.Param("id", d.String, "id")
 		})
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/notification"
+	"github.com/goadesign/goa"
+	"golang.org/x/net/context"
+)
+
+// EmailTemplatesController implements the email-templates resource.
+type EmailTemplatesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewEmailTemplatesController creates an email-templates controller.
+func NewEmailTemplatesController(service *goa.Service, db application.DB) *EmailTemplatesController {
+	return &EmailTemplatesController{Controller: service.NewController("EmailTemplatesController"), db: db}
+}
+
+// List runs the list action.
+func (c *EmailTemplatesController) List(ctx *app.ListEmailTemplatesContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can manage email templates"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		res, err := listEmailTemplates(ctx, appl)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Update runs the update action.
+func (c *EmailTemplatesController) Update(ctx *app.UpdateEmailTemplatesContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can manage email templates"))
+	}
+	t := notification.EmailTemplate{Event: ctx.Event, Subject: ctx.Payload.Subject, Body: ctx.Payload.Body}
+	if ctx.Payload.Locale != nil {
+		t.Locale = *ctx.Payload.Locale
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if err := appl.EmailTemplates().Save(ctx, &t); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res, err := listEmailTemplates(ctx, appl)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Reset runs the reset action.
+func (c *EmailTemplatesController) Reset(ctx *app.ResetEmailTemplatesContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can manage email templates"))
+	}
+	var locale string
+	if ctx.Locale != nil {
+		locale = *ctx.Locale
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if err := appl.EmailTemplates().Delete(ctx, ctx.Event, locale); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.NoContent()
+	})
+}
+
+// Preview runs the preview action.
+func (c *EmailTemplatesController) Preview(ctx *app.PreviewEmailTemplatesContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can manage email templates"))
+	}
+	var locale string
+	if ctx.Payload.Locale != nil {
+		locale = *ctx.Payload.Locale
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		t, err := appl.EmailTemplates().Load(ctx, ctx.Event, locale)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if ctx.Payload.Subject != nil {
+			t.Subject = *ctx.Payload.Subject
+		}
+		if ctx.Payload.Body != nil {
+			t.Body = *ctx.Payload.Body
+		}
+		brand, err := appl.Branding().Effective(ctx, nil)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		subject, body, err := t.Render(notification.SampleEmail(ctx.Event, *brand))
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.EmailPreview{Subject: subject, Body: body})
+	})
+}
+
+// listEmailTemplates returns the default template of every event followed by
+// the customized ones
+func listEmailTemplates(ctx context.Context, appl application.Application) (*app.EmailTemplateList, error) {
+	templates, err := appl.EmailTemplates().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := &app.EmailTemplateList{Variables: notification.EmailVariables}
+	for _, event := range notification.Events {
+		res.Data = append(res.Data, &app.EmailTemplate{
+			Event:   event,
+			Subject: notification.DefaultEmailSubject,
+			Body:    notification.DefaultEmailBody,
+			Custom:  false,
+		})
+	}
+	for _, t := range templates {
+		et := &app.EmailTemplate{Event: t.Event, Subject: t.Subject, Body: t.Body, Custom: true}
+		if t.Locale != "" {
+			locale := t.Locale
+			et.Locale = &locale
+		}
+		res.Data = append(res.Data, et)
+	}
+	return res, nil
+}
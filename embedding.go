@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// intakePath matches the paths of the feedback portals embedded forms use
+var intakePath = regexp.MustCompile(`^/api/projects/[^/]+/intake(/submissions)?$`)
+
+// AllowEmbeddedIntake answers the CORS requests of the feedback forms embedded
+// on the allowed origins of the enabled portal of a project, other requests
+// are left to the CORS policy of the API
+func AllowEmbeddedIntake(db application.DB) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			origin := req.Header.Get("Origin")
+			if origin == "" || !intakePath.MatchString(req.URL.Path) {
+				return h(ctx, rw, req)
+			}
+			projectID, err := uuid.FromString(goa.ContextRequest(ctx).Params.Get("id"))
+			if err != nil {
+				return h(ctx, rw, req)
+			}
+			allowed := false
+			err = application.Transactional(requestDB(ctx, db), func(appl application.Application) error {
+				p, err := appl.IntakePortals().Load(ctx, projectID)
+				if err != nil {
+					if _, ok := err.(errors.NotFoundError); ok {
+						return nil
+					}
+					return err
+				}
+				allowed = p.Enabled && p.Origins.Allows(origin)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if !allowed {
+				return h(ctx, rw, req)
+			}
+			rw.Header().Set("Access-Control-Allow-Origin", origin)
+			rw.Header().Add("Vary", "Origin")
+			if req.Method == "OPTIONS" && req.Header.Get("Access-Control-Request-Method") != "" {
+				rw.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+				rw.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-Challenge-Token, X-Intake-Token")
+				rw.Header().Set("Access-Control-Max-Age", "600")
+				rw.WriteHeader(http.StatusOK)
+				return nil
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
+
+// isSameOrigin returns true if the origin is the host the request was sent to
+func isSameOrigin(origin string, req *http.Request) bool {
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, req.Host)
+}
@@ -0,0 +1,213 @@
+// Package encryption implements the application level encryption of
+// sensitive values at rest. Values are encrypted with AES-GCM using the
+// primary key of a Keyring. The ID of the key is stored along with every
+// encrypted value so that the keyring can still decrypt values encrypted
+// with older keys after the primary key has been rotated.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"strings"
+
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/errors"
+)
+
+// Prefix marks encrypted string values
+const Prefix = "enc:"
+
+// keySeparator separates the key ID from the encrypted value
+const keySeparator = ":"
+
+// Keyring holds the keys used to encrypt and decrypt values
+type Keyring struct {
+	primary string
+	aeads   map[string]cipher.AEAD
+}
+
+// NewKeyring creates a keyring from the given AES keys of 16, 24 or 32 bytes
+// by their ID. New values are encrypted with the primary key.
+// returns BadParameterError if a key is invalid or the primary key is missing
+func NewKeyring(primary string, keys map[string][]byte) (*Keyring, error) {
+	k := &Keyring{primary: primary, aeads: map[string]cipher.AEAD{}}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, keySeparator) {
+			return nil, errors.NewBadParameterError("key id", id).Expected("not empty, without " + keySeparator)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, errors.NewBadParameterError("key "+id, err.Error()).Expected("16, 24 or 32 bytes")
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		k.aeads[id] = aead
+	}
+	if _, ok := k.aeads[primary]; !ok {
+		return nil, errors.NewBadParameterError("primary key", primary).Expected("one of the configured keys")
+	}
+	return k, nil
+}
+
+// ParseKeys parses keys in the form "id1:base64key1,id2:base64key2"
+// returns BadParameterError if the keys are malformed
+func ParseKeys(s string) (map[string][]byte, error) {
+	keys := map[string][]byte{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, keySeparator, 2)
+		if len(parts) != 2 {
+			return nil, errors.NewBadParameterError("encryption key", parts[0]).Expected("id:base64key")
+		}
+		key, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, errors.NewBadParameterError("encryption key "+parts[0], err.Error()).Expected("base64 encoded")
+		}
+		keys[parts[0]] = key
+	}
+	return keys, nil
+}
+
+// PrimaryKeyID returns the ID of the key new values are encrypted with
+func (k *Keyring) PrimaryKeyID() string {
+	return k.primary
+}
+
+// Encrypt encrypts a blob with the primary key
+// returns InternalError
+func (k *Keyring) Encrypt(plaintext []byte) ([]byte, error) {
+	aead := k.aeads[k.primary]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	out := append([]byte(k.primary+keySeparator), nonce...)
+	return aead.Seal(out, nonce, plaintext, []byte(k.primary)), nil
+}
+
+// Decrypt decrypts a blob encrypted with any of the keys of the keyring
+// returns BadParameterError if the blob can't be decrypted
+func (k *Keyring) Decrypt(ciphertext []byte) ([]byte, error) {
+	id, aead, rest, err := k.split(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < aead.NonceSize() {
+		return nil, errors.NewBadParameterError("ciphertext", len(ciphertext)).Expected("longer than the nonce")
+	}
+	plaintext, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], []byte(id))
+	if err != nil {
+		return nil, errors.NewBadParameterError("ciphertext", err.Error())
+	}
+	return plaintext, nil
+}
+
+// KeyID returns the ID of the key the given blob was encrypted with
+// returns BadParameterError if the key is unknown
+func (k *Keyring) KeyID(ciphertext []byte) (string, error) {
+	id, _, _, err := k.split(ciphertext)
+	return id, err
+}
+
+// Rotate re-encrypts a blob with the primary key unless it is already
+// encrypted with it
+func (k *Keyring) Rotate(ciphertext []byte) ([]byte, error) {
+	if id, err := k.KeyID(ciphertext); err == nil && id == k.primary {
+		return ciphertext, nil
+	}
+	plaintext, err := k.Decrypt(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return k.Encrypt(plaintext)
+}
+
+// EncryptString encrypts a string with the primary key, the result is
+// marked with Prefix
+func (k *Keyring) EncryptString(plaintext string) (string, error) {
+	ciphertext, err := k.Encrypt([]byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return Prefix + base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// DecryptString decrypts a string encrypted with EncryptString
+// returns BadParameterError if the string can't be decrypted
+func (k *Keyring) DecryptString(s string) (string, error) {
+	if !IsEncrypted(s) {
+		return "", errors.NewBadParameterError("ciphertext", s).Expected(Prefix + "...")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(s, Prefix))
+	if err != nil {
+		return "", errors.NewBadParameterError("ciphertext", err.Error())
+	}
+	plaintext, err := k.Decrypt(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// IsEncrypted returns true if s was encrypted with EncryptString
+func IsEncrypted(s string) bool {
+	return strings.HasPrefix(s, Prefix)
+}
+
+func (k *Keyring) split(ciphertext []byte) (string, cipher.AEAD, []byte, error) {
+	s := string(ciphertext)
+	i := strings.Index(s, keySeparator)
+	if i < 0 {
+		return "", nil, nil, errors.NewBadParameterError("ciphertext", len(ciphertext)).Expected("a key id")
+	}
+	id := s[:i]
+	aead, ok := k.aeads[id]
+	if !ok {
+		return "", nil, nil, errors.NewBadParameterError("key id", id).Expected("a configured key")
+	}
+	return id, aead, ciphertext[i+1:], nil
+}
+
+var defaultKeyring *Keyring
+
+// SetDefaultKeyring sets the keyring sensitive values are encrypted with
+func SetDefaultKeyring(k *Keyring) {
+	defaultKeyring = k
+}
+
+// DefaultKeyring returns the keyring sensitive values are encrypted with
+// returns InternalError if no keys are configured
+func DefaultKeyring() (*Keyring, error) {
+	if defaultKeyring == nil {
+		return nil, errors.NewInternalError("no encryption keys configured")
+	}
+	return defaultKeyring, nil
+}
+
+// SetupDefaultKeyring sets the default keyring to the keys of the
+// configuration, there is no default keyring if no keys are configured
+// returns BadParameterError if the configured keys are invalid
+func SetupDefaultKeyring() error {
+	if configuration.GetEncryptionKeys() == "" {
+		SetDefaultKeyring(nil)
+		return nil
+	}
+	keys, err := ParseKeys(configuration.GetEncryptionKeys())
+	if err != nil {
+		return err
+	}
+	k, err := NewKeyring(configuration.GetEncryptionPrimaryKey(), keys)
+	if err != nil {
+		return err
+	}
+	SetDefaultKeyring(k)
+	return nil
+}
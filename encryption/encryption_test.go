@@ -0,0 +1,88 @@
+package encryption_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/almighty/almighty-core/encryption"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	oldKey = bytes.Repeat([]byte{1}, 32)
+	newKey = bytes.Repeat([]byte{2}, 16)
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	k, err := encryption.NewKeyring("old", map[string][]byte{"old": oldKey})
+	require.Nil(t, err)
+
+	s, err := k.EncryptString("ACME Corp.")
+	require.Nil(t, err)
+	assert.True(t, encryption.IsEncrypted(s))
+	assert.NotContains(t, s, "ACME")
+	plaintext, err := k.DecryptString(s)
+	require.Nil(t, err)
+	assert.Equal(t, "ACME Corp.", plaintext)
+
+	blob, err := k.Encrypt([]byte{0, 1, 2})
+	require.Nil(t, err)
+	decrypted, err := k.Decrypt(blob)
+	require.Nil(t, err)
+	assert.Equal(t, []byte{0, 1, 2}, decrypted)
+
+	// tampered values don't decrypt
+	blob[len(blob)-1] ^= 1
+	_, err = k.Decrypt(blob)
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
+
+func TestKeyRotation(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	old, err := encryption.NewKeyring("old", map[string][]byte{"old": oldKey})
+	require.Nil(t, err)
+	blob, err := old.Encrypt([]byte("secret"))
+	require.Nil(t, err)
+
+	rotated, err := encryption.NewKeyring("new", map[string][]byte{"old": oldKey, "new": newKey})
+	require.Nil(t, err)
+	decrypted, err := rotated.Decrypt(blob)
+	require.Nil(t, err)
+	assert.Equal(t, "secret", string(decrypted))
+
+	blob, err = rotated.Rotate(blob)
+	require.Nil(t, err)
+	id, err := rotated.KeyID(blob)
+	require.Nil(t, err)
+	assert.Equal(t, "new", id)
+
+	// the old keyring doesn't know the new key
+	_, err = old.Decrypt(blob)
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
+
+func TestInvalidKeys(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	_, err := encryption.NewKeyring("k", map[string][]byte{"k": []byte("short")})
+	assert.IsType(t, errors.BadParameterError{}, err)
+	_, err = encryption.NewKeyring("missing", map[string][]byte{"k": oldKey})
+	assert.IsType(t, errors.BadParameterError{}, err)
+
+	keys, err := encryption.ParseKeys("a:" + base64.StdEncoding.EncodeToString(oldKey) + ", b:" + base64.StdEncoding.EncodeToString(newKey))
+	require.Nil(t, err)
+	assert.Equal(t, oldKey, keys["a"])
+	assert.Equal(t, newKey, keys["b"])
+	_, err = encryption.ParseKeys("nokey")
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
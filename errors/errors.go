@@ -36,6 +36,39 @@ func NewVersionConflictError(msg string) VersionConflictError {
 	return VersionConflictError{simpleError{msg}}
 }
 
+// DataConflictError means that the operation would leave the stored data
+// inconsistent, e.g. because other entities still refer to the one to delete
+type DataConflictError struct {
+	simpleError
+}
+
+// NewDataConflictError returns the custom defined error of type DataConflictError.
+func NewDataConflictError(msg string) DataConflictError {
+	return DataConflictError{simpleError{msg}}
+}
+
+// ServiceUnavailableError means that the operation can't be performed at the
+// moment, e.g. because the instance is in read-only mode
+type ServiceUnavailableError struct {
+	simpleError
+}
+
+// NewServiceUnavailableError returns the custom defined error of type ServiceUnavailableError.
+func NewServiceUnavailableError(msg string) ServiceUnavailableError {
+	return ServiceUnavailableError{simpleError{msg}}
+}
+
+// PayloadTooLargeError means that the body of the request exceeds the size
+// allowed for it
+type PayloadTooLargeError struct {
+	simpleError
+}
+
+// NewPayloadTooLargeError returns the custom defined error of type PayloadTooLargeError.
+func NewPayloadTooLargeError(msg string) PayloadTooLargeError {
+	return PayloadTooLargeError{simpleError{msg}}
+}
+
 // BadParameterError means that a parameter was not as required
 type BadParameterError struct {
 	parameter        string
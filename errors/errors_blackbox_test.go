@@ -27,6 +27,27 @@ func TestNewConversionError(t *testing.T) {
 	t.Log(err)
 }
 
+func TestNewDataConflictError(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	err := errors.NewDataConflictError("work item link type has links")
+	assert.Equal(t, "work item link type has links", err.Error())
+}
+
+func TestNewServiceUnavailableError(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	err := errors.NewServiceUnavailableError("the instance is read-only")
+	assert.Equal(t, "the instance is read-only", err.Error())
+}
+
+func TestNewPayloadTooLargeError(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	err := errors.NewPayloadTooLargeError("the request body exceeds 1024 bytes")
+	assert.Equal(t, "the request body exceeds 1024 bytes", err.Error())
+}
+
 func TestNewBadParameterError(t *testing.T) {
 	t.Parallel()
 	resource.Require(t, resource.UnitTest)
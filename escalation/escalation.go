@@ -0,0 +1,292 @@
+// Package escalation escalates the open work items of a project with a given
+// severity that stay unassigned or untouched for a number of hours, by
+// notifying a secondary contact and/or raising their priority. A work item is
+// escalated once, until it is assigned or touched again.
+package escalation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Reasons work items are escalated for
+const (
+	ReasonUnassigned = "unassigned"
+	ReasonUntouched  = "untouched"
+)
+
+// Policy decides when the work items of a project are escalated and how
+type Policy struct {
+	gormsupport.Lifecycle
+	ProjectID uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	// Severity of the work items the policy applies to
+	Severity string
+	// UnassignedHours after their creation work items without assignees are
+	// escalated, nil doesn't escalate unassigned work items
+	UnassignedHours *int
+	// UntouchedHours without updates or comments work items are escalated,
+	// nil doesn't escalate untouched work items
+	UntouchedHours *int
+	// ContactID is the identity notified of escalated work items
+	ContactID *uuid.UUID `sql:"type:uuid"`
+	// RaisePriority is the priority escalated work items are set to, empty
+	// leaves the priority
+	RaisePriority string
+}
+
+// TableName implements gorm.tabler
+func (p Policy) TableName() string {
+	return "escalation_policies"
+}
+
+// Validate checks that the policy escalates work items and does something
+// about them
+// returns BadParameterError
+func (p Policy) Validate() error {
+	if p.Severity == "" {
+		return errors.NewBadParameterError("severity", p.Severity).Expected("not empty")
+	}
+	if p.UnassignedHours == nil && p.UntouchedHours == nil {
+		return errors.NewBadParameterError("unassigned-hours", nil).Expected("unassigned-hours or untouched-hours")
+	}
+	if p.UnassignedHours != nil && *p.UnassignedHours <= 0 {
+		return errors.NewBadParameterError("unassigned-hours", *p.UnassignedHours).Expected("greater than 0")
+	}
+	if p.UntouchedHours != nil && *p.UntouchedHours <= 0 {
+		return errors.NewBadParameterError("untouched-hours", *p.UntouchedHours).Expected("greater than 0")
+	}
+	if p.ContactID == nil && p.RaisePriority == "" {
+		return errors.NewBadParameterError("contact", nil).Expected("a contact or a priority to raise to")
+	}
+	return nil
+}
+
+// Escalation is a work item that was escalated by a sweep
+type Escalation struct {
+	WorkItemID string
+	ProjectID  uuid.UUID
+	Title      string
+	Assignees  []string
+	Reason     string
+	ContactID  *uuid.UUID
+}
+
+// Repository encapsulates storage & retrieval of escalation policies
+type Repository interface {
+	Load(ctx context.Context, projectID uuid.UUID) (*Policy, error)
+	Save(ctx context.Context, p Policy) (*Policy, error)
+	Delete(ctx context.Context, projectID uuid.UUID) error
+	Sweep(ctx context.Context, now time.Time) ([]Escalation, error)
+}
+
+// NewEscalationPolicyRepository creates a new storage type.
+func NewEscalationPolicyRepository(db *gorm.DB) Repository {
+	return &GormEscalationPolicyRepository{db: db}
+}
+
+// GormEscalationPolicyRepository is the implementation of the storage
+// interface for escalation policies.
+type GormEscalationPolicyRepository struct {
+	db *gorm.DB
+}
+
+// Load returns the policy of the project
+// returns NotFoundError or InternalError
+func (m *GormEscalationPolicyRepository) Load(ctx context.Context, projectID uuid.UUID) (*Policy, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "escalationpolicy", "get"}, time.Now())
+
+	var obj Policy
+	tx := m.db.Where("project_id = ?", projectID).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("escalation policy", projectID.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
+
+// Save creates or replaces the policy of the project
+// returns BadParameterError or InternalError
+func (m *GormEscalationPolicyRepository) Save(ctx context.Context, p Policy) (*Policy, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "escalationpolicy", "save"}, time.Now())
+
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	tx := m.db.Exec(`INSERT INTO escalation_policies (project_id, severity, unassigned_hours, untouched_hours, contact_id, raise_priority, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, now(), now())
+		ON CONFLICT (project_id) DO UPDATE SET severity = excluded.severity, unassigned_hours = excluded.unassigned_hours,
+			untouched_hours = excluded.untouched_hours, contact_id = excluded.contact_id, raise_priority = excluded.raise_priority,
+			updated_at = now(), deleted_at = NULL`,
+		p.ProjectID, p.Severity, p.UnassignedHours, p.UntouchedHours, p.ContactID, p.RaisePriority)
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return m.Load(ctx, p.ProjectID)
+}
+
+// Delete removes the policy of the project and the escalations of its work
+// items
+// returns NotFoundError or InternalError
+func (m *GormEscalationPolicyRepository) Delete(ctx context.Context, projectID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "escalationpolicy", "delete"}, time.Now())
+
+	tx := m.db.Where("project_id = ?", projectID).Delete(&Policy{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("escalation policy", projectID.String())
+	}
+	if err := m.db.Exec("DELETE FROM escalated_work_items WHERE project_id = ?", projectID).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// unassigned matches the work items w without assignees
+var unassigned = fmt.Sprintf(`(w.fields->'%[1]s' IS NULL OR w.fields->'%[1]s' IN ('null', '[]'))`, workitem.SystemAssignees)
+
+// resolved matches the escalations of escalated_work_items e that no longer
+// apply: unassigned work items that were assigned and untouched ones that
+// were updated or commented on since
+var resolved = `(e.reason = '` + ReasonUnassigned + `' AND EXISTS (SELECT 1 FROM work_items w WHERE w.id = e.work_item_id AND NOT ` + unassigned + `))
+	OR (e.reason = '` + ReasonUntouched + `' AND (EXISTS (SELECT 1 FROM work_items w WHERE w.id = e.work_item_id AND w.updated_at > e.escalated_at)
+		OR EXISTS (SELECT 1 FROM comments c WHERE c.parent_id = e.work_item_id::text AND c.created_at > e.escalated_at AND c.deleted_at IS NULL)))`
+
+// Sweep applies the policies of all projects: escalations that no longer
+// apply are removed, then the open work items unassigned or untouched for
+// longer than allowed are escalated and their priority is raised.
+// returns InternalError
+func (m *GormEscalationPolicyRepository) Sweep(ctx context.Context, now time.Time) ([]Escalation, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "escalationpolicy", "sweep"}, time.Now())
+
+	var policies []*Policy
+	if err := m.db.Find(&policies).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	var escalations []Escalation
+	for _, p := range policies {
+		if err := m.db.Exec("DELETE FROM escalated_work_items e WHERE e.project_id = ? AND ("+resolved+")", p.ProjectID).Error; err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		open := fmt.Sprintf(`w.deleted_at IS NULL AND w.fields->>'%s' = ? AND w.fields->>'%s' = ? AND COALESCE(w.fields->>'%s', '') NOT IN (?)`,
+			workitem.SystemProject, workitem.SystemSeverity, workitem.SystemState)
+		closed := []string{workitem.SystemStateClosed, workitem.SystemStateInactive}
+		var escalated []Escalation
+		var escalatedIDs []uint64
+		if p.UnassignedHours != nil {
+			ids, err := m.ids(`INSERT INTO escalated_work_items (work_item_id, project_id, reason, escalated_at)
+				SELECT w.id, ?, ?, ? FROM work_items w
+				WHERE `+open+` AND `+unassigned+` AND w.created_at < ?
+				ON CONFLICT (work_item_id) DO NOTHING
+				RETURNING work_item_id`,
+				p.ProjectID, ReasonUnassigned, now, p.ProjectID.String(), p.Severity, closed, now.Add(-time.Duration(*p.UnassignedHours)*time.Hour))
+			if err != nil {
+				return nil, err
+			}
+			escalatedIDs = append(escalatedIDs, ids...)
+			es, err := m.escalations(p, ids, ReasonUnassigned)
+			if err != nil {
+				return nil, err
+			}
+			escalated = append(escalated, es...)
+		}
+		if p.UntouchedHours != nil {
+			cutoff := now.Add(-time.Duration(*p.UntouchedHours) * time.Hour)
+			ids, err := m.ids(`INSERT INTO escalated_work_items (work_item_id, project_id, reason, escalated_at)
+				SELECT w.id, ?, ?, ? FROM work_items w
+				WHERE `+open+` AND w.updated_at < ?
+					AND NOT EXISTS (SELECT 1 FROM comments c WHERE c.parent_id = w.id::text AND c.created_at >= ? AND c.deleted_at IS NULL)
+				ON CONFLICT (work_item_id) DO NOTHING
+				RETURNING work_item_id`,
+				p.ProjectID, ReasonUntouched, now, p.ProjectID.String(), p.Severity, closed, cutoff, cutoff)
+			if err != nil {
+				return nil, err
+			}
+			escalatedIDs = append(escalatedIDs, ids...)
+			es, err := m.escalations(p, ids, ReasonUntouched)
+			if err != nil {
+				return nil, err
+			}
+			escalated = append(escalated, es...)
+		}
+		if p.RaisePriority != "" && len(escalatedIDs) > 0 {
+			// the work items are updated at the time they are escalated, which
+			// doesn't count as activity
+			raised, err := m.ids(fmt.Sprintf(`UPDATE work_items SET fields = jsonb_set(fields, '{%s}', to_jsonb(?::text)), version = version + 1, updated_at = ?
+				WHERE id IN (?) AND fields->>'%[1]s' IS DISTINCT FROM ?
+				RETURNING id`, workitem.SystemPriority),
+				p.RaisePriority, now, escalatedIDs, p.RaisePriority)
+			if err != nil {
+				return nil, err
+			}
+			if err := workitem.RecordEvents(ctx, m.db, workitem.EventUpdate, raised...); err != nil {
+				return nil, err
+			}
+		}
+		escalations = append(escalations, escalated...)
+	}
+	return escalations, nil
+}
+
+// ids runs the statement returning work item IDs
+func (m *GormEscalationPolicyRepository) ids(sql string, values ...interface{}) ([]uint64, error) {
+	rows, err := m.db.Raw(sql, values...).Rows()
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	defer rows.Close()
+	var ids []uint64
+	for rows.Next() {
+		var id uint64
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// escalations loads the titles and assignees of the given work items
+func (m *GormEscalationPolicyRepository) escalations(p *Policy, ids []uint64, reason string) ([]Escalation, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := m.db.Raw(fmt.Sprintf("SELECT id, fields->>'%s', COALESCE(fields->'%s', '[]') FROM work_items WHERE id IN (?) ORDER BY id",
+		workitem.SystemTitle, workitem.SystemAssignees), ids).Rows()
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	defer rows.Close()
+	var escalations []Escalation
+	for rows.Next() {
+		var id uint64
+		var title *string
+		var assignees []byte
+		if err := rows.Scan(&id, &title, &assignees); err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		e := Escalation{WorkItemID: strconv.FormatUint(id, 10), ProjectID: p.ProjectID, Reason: reason, ContactID: p.ContactID}
+		if title != nil {
+			e.Title = *title
+		}
+		if err := json.Unmarshal(assignees, &e.Assignees); err != nil {
+			return nil, errors.NewConversionError(err.Error())
+		}
+		escalations = append(escalations, e)
+	}
+	return escalations, nil
+}
@@ -0,0 +1,162 @@
+package escalation_test
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/escalation"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestValidate(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	hours, zero := 4, 0
+	contact := uuid.NewV4()
+	assert.Nil(t, escalation.Policy{Severity: "1", UnassignedHours: &hours, ContactID: &contact}.Validate())
+	assert.Nil(t, escalation.Policy{Severity: "1", UntouchedHours: &hours, RaisePriority: "critical"}.Validate())
+	for _, p := range []escalation.Policy{
+		{UnassignedHours: &hours, ContactID: &contact},
+		{Severity: "1", ContactID: &contact},
+		{Severity: "1", UnassignedHours: &zero, ContactID: &contact},
+		{Severity: "1", UntouchedHours: &zero, ContactID: &contact},
+		{Severity: "1", UnassignedHours: &hours},
+	} {
+		assert.IsType(t, errors.BadParameterError{}, p.Validate(), "%+v", p)
+	}
+}
+
+type TestEscalationPolicyRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunEscalationPolicyRepository(t *testing.T) {
+	suite.Run(t, &TestEscalationPolicyRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestEscalationPolicyRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestEscalationPolicyRepository) TearDownTest() {
+	test.clean()
+}
+
+// createWorkItem creates a work item in the project that was created and
+// last updated the given number of hours ago
+func (test *TestEscalationPolicyRepository) createWorkItem(p *project.Project, title string, severity string, assignees []interface{}, hours int) string {
+	wi, err := workitem.NewWorkItemRepository(test.DB).Create(context.Background(), workitem.SystemBug,
+		map[string]interface{}{
+			workitem.SystemTitle:     title,
+			workitem.SystemState:     workitem.SystemStateOpen,
+			workitem.SystemProject:   p.ID.String(),
+			workitem.SystemSeverity:  severity,
+			workitem.SystemPriority:  "medium",
+			workitem.SystemAssignees: assignees,
+		}, uuid.NewV4().String())
+	require.Nil(test.T(), err)
+	at := time.Now().Add(-time.Duration(hours) * time.Hour)
+	require.Nil(test.T(), test.DB.Exec("UPDATE work_items SET created_at = ?, updated_at = ? WHERE id = ?", at, at, wi.ID).Error)
+	return wi.ID
+}
+
+func (test *TestEscalationPolicyRepository) TestSavePolicy() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	p, err := project.NewRepository(test.DB).Create(ctx, "escalation-"+uuid.NewV4().String())
+	require.Nil(t, err)
+	repo := escalation.NewEscalationPolicyRepository(test.DB)
+	_, err = repo.Load(ctx, p.ID)
+	assert.IsType(t, errors.NotFoundError{}, err)
+	_, err = repo.Save(ctx, escalation.Policy{ProjectID: p.ID, Severity: "1", RaisePriority: "critical"})
+	assert.IsType(t, errors.BadParameterError{}, err)
+
+	hours := 4
+	policy, err := repo.Save(ctx, escalation.Policy{ProjectID: p.ID, Severity: "1", UnassignedHours: &hours, RaisePriority: "critical"})
+	require.Nil(t, err)
+	assert.Equal(t, "1", policy.Severity)
+	require.NotNil(t, policy.UnassignedHours)
+	assert.Equal(t, 4, *policy.UnassignedHours)
+	assert.Nil(t, policy.UntouchedHours)
+	policy, err = repo.Save(ctx, escalation.Policy{ProjectID: p.ID, Severity: "2", UntouchedHours: &hours, RaisePriority: "high"})
+	require.Nil(t, err)
+	assert.Equal(t, "2", policy.Severity)
+	assert.Nil(t, policy.UnassignedHours)
+	assert.Equal(t, "high", policy.RaisePriority)
+
+	require.Nil(t, repo.Delete(ctx, p.ID))
+	assert.IsType(t, errors.NotFoundError{}, repo.Delete(ctx, p.ID))
+}
+
+func (test *TestEscalationPolicyRepository) TestSweep() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	p, err := project.NewRepository(test.DB).Create(ctx, "escalation-"+uuid.NewV4().String())
+	require.Nil(t, err)
+	repo := escalation.NewEscalationPolicyRepository(test.DB)
+	unassignedHours, untouchedHours := 4, 24
+	_, err = repo.Save(ctx, escalation.Policy{ProjectID: p.ID, Severity: "1", UnassignedHours: &unassignedHours, UntouchedHours: &untouchedHours, RaisePriority: "critical"})
+	require.Nil(t, err)
+	assignee := uuid.NewV4().String()
+	unassigned := test.createWorkItem(p, "unassigned", "1", nil, 5)
+	untouched := test.createWorkItem(p, "untouched", "1", []interface{}{assignee}, 30)
+	recent := test.createWorkItem(p, "recent", "1", nil, 1)
+	test.createWorkItem(p, "minor", "3", nil, 30)
+
+	now := time.Now()
+	escalations, err := repo.Sweep(ctx, now)
+	require.Nil(t, err)
+	reasons := map[string]string{}
+	for _, e := range escalations {
+		if uuid.Equal(e.ProjectID, p.ID) {
+			reasons[e.WorkItemID] = e.Reason
+		}
+	}
+	assert.Equal(t, map[string]string{unassigned: escalation.ReasonUnassigned, untouched: escalation.ReasonUntouched}, reasons)
+	wi, err := workitem.NewWorkItemRepository(test.DB).Load(ctx, untouched)
+	require.Nil(t, err)
+	assert.Equal(t, "critical", wi.Fields[workitem.SystemPriority])
+
+	// escalated work items are only reported once, raising the priority
+	// doesn't count as activity
+	escalations, err = repo.Sweep(ctx, now.Add(time.Hour))
+	require.Nil(t, err)
+	for _, e := range escalations {
+		assert.False(t, uuid.Equal(e.ProjectID, p.ID))
+	}
+
+	// a comment resolves the escalation, which happens again once the work
+	// item is untouched for long enough, by then the recent one is escalated too
+	require.Nil(t, comment.NewCommentRepository(test.DB).Create(ctx, &comment.Comment{ParentID: untouched, Body: "looking into it", CreatedBy: uuid.NewV4()}))
+	escalations, err = repo.Sweep(ctx, now.Add(2*time.Hour))
+	require.Nil(t, err)
+	for _, e := range escalations {
+		assert.False(t, uuid.Equal(e.ProjectID, p.ID))
+	}
+	escalations, err = repo.Sweep(ctx, now.Add(time.Duration(untouchedHours+1)*time.Hour))
+	require.Nil(t, err)
+	var escalated []string
+	for _, e := range escalations {
+		if uuid.Equal(e.ProjectID, p.ID) {
+			escalated = append(escalated, e.WorkItemID)
+		}
+	}
+	assert.Equal(t, []string{recent, untouched}, escalated)
+}
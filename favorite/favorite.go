@@ -0,0 +1,161 @@
+// Package favorite stores the work items every identity viewed recently and
+// the ones it pinned, so both lists follow the user across devices.
+package favorite
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// MaxRecent is the number of recently viewed work items kept per identity
+const MaxRecent = 50
+
+// MaxPins is the number of work items an identity can pin
+const MaxPins = 100
+
+// View records when an identity last viewed a work item and how often
+type View struct {
+	IdentityID uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	WorkItemID uint64    `gorm:"primary_key"`
+	ViewedAt   time.Time
+	Views      int
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m View) TableName() string {
+	return "work_item_views"
+}
+
+// Pin records that an identity pinned a work item
+type Pin struct {
+	IdentityID uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	WorkItemID uint64    `gorm:"primary_key"`
+	CreatedAt  time.Time
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m Pin) TableName() string {
+	return "work_item_pins"
+}
+
+// Repository describes interactions with the recently viewed and pinned
+// work items
+type Repository interface {
+	RecordView(ctx context.Context, identityID uuid.UUID, workItemID uint64) error
+	Recent(ctx context.Context, identityID uuid.UUID, limit int) ([]View, error)
+	Pin(ctx context.Context, identityID uuid.UUID, workItemID uint64) error
+	Unpin(ctx context.Context, identityID uuid.UUID, workItemID uint64) error
+	Pinned(ctx context.Context, identityID uuid.UUID) ([]Pin, error)
+}
+
+// NewFavoriteRepository creates a new storage type.
+func NewFavoriteRepository(db *gorm.DB) Repository {
+	return &GormFavoriteRepository{db: db}
+}
+
+// GormFavoriteRepository is the implementation of the storage interface for
+// views and pins.
+type GormFavoriteRepository struct {
+	db *gorm.DB
+}
+
+// RecordView records that the identity viewed the work item just now and
+// forgets the views beyond the latest MaxRecent ones
+// returns BadParameterError or InternalError
+func (m *GormFavoriteRepository) RecordView(ctx context.Context, identityID uuid.UUID, workItemID uint64) error {
+	defer goa.MeasureSince([]string{"goa", "db", "favorite", "recordview"}, time.Now())
+
+	if workItemID == 0 {
+		return errors.NewBadParameterError("work_item_id", workItemID)
+	}
+	tx := m.db.Exec(`INSERT INTO work_item_views (identity_id, work_item_id, viewed_at) VALUES (?, ?, now())
+		ON CONFLICT (identity_id, work_item_id) DO UPDATE SET viewed_at = excluded.viewed_at, views = work_item_views.views + 1`,
+		identityID, workItemID)
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	tx = m.db.Exec(`DELETE FROM work_item_views WHERE identity_id = ? AND work_item_id NOT IN (
+		SELECT work_item_id FROM work_item_views WHERE identity_id = ? ORDER BY viewed_at DESC LIMIT ?)`,
+		identityID, identityID, MaxRecent)
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	return nil
+}
+
+// Recent returns the latest views of the identity, the most recent first
+// returns BadParameterError or InternalError
+func (m *GormFavoriteRepository) Recent(ctx context.Context, identityID uuid.UUID, limit int) ([]View, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "favorite", "recent"}, time.Now())
+
+	if limit <= 0 || limit > MaxRecent {
+		return nil, errors.NewBadParameterError("limit", limit).Expected("between 1 and 50")
+	}
+	var views []View
+	err := m.db.Where("identity_id = ?", identityID).Order("viewed_at DESC").Limit(limit).Find(&views).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return views, nil
+}
+
+// Pin adds the work item to the pinned ones of the identity, pinning it again
+// has no effect
+// returns BadParameterError or InternalError
+func (m *GormFavoriteRepository) Pin(ctx context.Context, identityID uuid.UUID, workItemID uint64) error {
+	defer goa.MeasureSince([]string{"goa", "db", "favorite", "pin"}, time.Now())
+
+	if workItemID == 0 {
+		return errors.NewBadParameterError("work_item_id", workItemID)
+	}
+	var count int
+	err := m.db.Model(&Pin{}).Where("identity_id = ? AND work_item_id <> ?", identityID, workItemID).Count(&count).Error
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if count >= MaxPins {
+		return errors.NewBadParameterError("pins", count).Expected("at most 100 pinned work items")
+	}
+	tx := m.db.Exec(`INSERT INTO work_item_pins (identity_id, work_item_id, created_at) VALUES (?, ?, now())
+		ON CONFLICT (identity_id, work_item_id) DO NOTHING`, identityID, workItemID)
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	return nil
+}
+
+// Unpin removes the work item from the pinned ones of the identity
+// returns NotFoundError or InternalError
+func (m *GormFavoriteRepository) Unpin(ctx context.Context, identityID uuid.UUID, workItemID uint64) error {
+	defer goa.MeasureSince([]string{"goa", "db", "favorite", "unpin"}, time.Now())
+
+	tx := m.db.Where("identity_id = ? AND work_item_id = ?", identityID, workItemID).Delete(&Pin{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("pin", identityID.String())
+	}
+	return nil
+}
+
+// Pinned returns the pinned work items of the identity, the latest pin first
+// returns InternalError
+func (m *GormFavoriteRepository) Pinned(ctx context.Context, identityID uuid.UUID) ([]Pin, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "favorite", "pinned"}, time.Now())
+
+	var pins []Pin
+	err := m.db.Where("identity_id = ?", identityID).Order("created_at DESC").Find(&pins).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return pins, nil
+}
@@ -0,0 +1,105 @@
+package favorite_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/favorite"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/gormsupport/testfixture"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestFavoriteRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunFavoriteRepository(t *testing.T) {
+	suite.Run(t, &TestFavoriteRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestFavoriteRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestFavoriteRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestFavoriteRepository) createWorkItem() uint64 {
+	wi, err := workitem.NewWorkItemRepository(test.DB).Create(
+		context.Background(), workitem.SystemFeature,
+		map[string]interface{}{
+			workitem.SystemTitle: "Title",
+			workitem.SystemState: workitem.SystemStateNew,
+		}, "xx")
+	require.Nil(test.T(), err)
+	id, err := workitem.ParseWorkItemIDToUint64(wi.ID)
+	require.Nil(test.T(), err)
+	return id
+}
+
+func (test *TestFavoriteRepository) TestRecentViews() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := favorite.NewFavoriteRepository(test.DB)
+	alice := testfixture.CreateIdentity(t, test.DB, "alice")
+	bob := testfixture.CreateIdentity(t, test.DB, "bob")
+	first := test.createWorkItem()
+	second := test.createWorkItem()
+
+	require.Nil(t, repo.RecordView(ctx, alice, first))
+	require.Nil(t, repo.RecordView(ctx, alice, second))
+	require.Nil(t, repo.RecordView(ctx, alice, first))
+	require.Nil(t, repo.RecordView(ctx, bob, second))
+
+	views, err := repo.Recent(ctx, alice, 10)
+	require.Nil(t, err)
+	require.Len(t, views, 2)
+	assert.Equal(t, first, views[0].WorkItemID)
+	assert.Equal(t, 2, views[0].Views)
+	assert.Equal(t, second, views[1].WorkItemID)
+
+	views, err = repo.Recent(ctx, alice, 1)
+	require.Nil(t, err)
+	require.Len(t, views, 1)
+
+	_, err = repo.Recent(ctx, alice, favorite.MaxRecent+1)
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
+
+func (test *TestFavoriteRepository) TestPinAndUnpin() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := favorite.NewFavoriteRepository(test.DB)
+	alice := testfixture.CreateIdentity(t, test.DB, "alice")
+	wiID := test.createWorkItem()
+
+	require.Nil(t, repo.Pin(ctx, alice, wiID))
+	// pinning again has no effect
+	require.Nil(t, repo.Pin(ctx, alice, wiID))
+	pins, err := repo.Pinned(ctx, alice)
+	require.Nil(t, err)
+	require.Len(t, pins, 1)
+	assert.Equal(t, wiID, pins[0].WorkItemID)
+
+	require.Nil(t, repo.Unpin(ctx, alice, wiID))
+	pins, err = repo.Pinned(ctx, alice)
+	require.Nil(t, err)
+	assert.Empty(t, pins)
+
+	err = repo.Unpin(ctx, alice, wiID)
+	assert.IsType(t, errors.NotFoundError{}, err)
+}
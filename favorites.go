@@ -0,0 +1,146 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"golang.org/x/net/context"
+)
+
+// defaultRecent is the number of recently viewed work items listed if no
+// limit is given
+const defaultRecent = 10
+
+// FavoritesController implements the favorites resource.
+type FavoritesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewFavoritesController creates a favorites controller.
+func NewFavoritesController(service *goa.Service, db application.DB) *FavoritesController {
+	return &FavoritesController{Controller: service.NewController("FavoritesController"), db: db}
+}
+
+// Recent runs the recent action.
+func (c *FavoritesController) Recent(ctx *app.RecentFavoritesContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	limit := defaultRecent
+	if ctx.PageLimit != nil {
+		limit = *ctx.PageLimit
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		views, err := appl.Favorites().Recent(ctx, *identityID, limit)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		ids := make([]uint64, len(views))
+		for i, v := range views {
+			ids[i] = v.WorkItemID
+		}
+		res, err := listWorkItemsInOrder(ctx, appl, ctx.RequestData, ids)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Pinned runs the pinned action.
+func (c *FavoritesController) Pinned(ctx *app.PinnedFavoritesContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		pins, err := appl.Favorites().Pinned(ctx, *identityID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		ids := make([]uint64, len(pins))
+		for i, p := range pins {
+			ids[i] = p.WorkItemID
+		}
+		res, err := listWorkItemsInOrder(ctx, appl, ctx.RequestData, ids)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Pin runs the pin action.
+func (c *FavoritesController) Pin(ctx *app.PinFavoritesContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	wiID, err := workitem.ParseWorkItemIDToUint64(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err := appl.WorkItems().Load(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if err := appl.Favorites().Pin(ctx, *identityID, wiID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.NoContent()
+	})
+}
+
+// Unpin runs the unpin action.
+func (c *FavoritesController) Unpin(ctx *app.UnpinFavoritesContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	wiID, err := workitem.ParseWorkItemIDToUint64(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if err := appl.Favorites().Unpin(ctx, *identityID, wiID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.NoContent()
+	})
+}
+
+// listWorkItemsInOrder returns the visible work items with the given IDs in
+// their order, the ones deleted or hidden in the meantime are left out
+func listWorkItemsInOrder(ctx context.Context, appl application.Application, request *goa.RequestData, ids []uint64) (*app.WorkItem2List, error) {
+	items, _, err := appl.WorkItems().List(ctx, deployedWorkItemsCriteria(ids), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*app.WorkItem, len(items))
+	for _, wi := range items {
+		byID[wi.ID] = wi
+	}
+	ordered := make([]*app.WorkItem, 0, len(items))
+	visible := make([]uint64, 0, len(items))
+	for _, id := range ids {
+		if wi, ok := byID[strconv.FormatUint(id, 10)]; ok {
+			ordered = append(ordered, wi)
+			visible = append(visible, id)
+		}
+	}
+	counts, voted, err := loadVotes(ctx, appl, visible)
+	if err != nil {
+		return nil, err
+	}
+	return &app.WorkItem2List{
+		Data: ConvertWorkItems(request, ordered, WorkItemIncludeVotes(counts, voted)),
+		Meta: &app.WorkItemListResponseMeta{TotalCount: len(ordered)},
+	}, nil
+}
@@ -0,0 +1,185 @@
+package fieldvalues
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Defaults holds the allowed values of the ordered work item fields for
+// projects that did not customize them. Values are listed from the most to
+// the least important one.
+var Defaults = map[string]Values{
+	workitem.SystemPriority: {"critical", "high", "medium", "low"},
+	workitem.SystemSeverity: {"blocker", "critical", "major", "normal", "minor"},
+}
+
+// IsOrdered returns true if the values of the given work item field are
+// ordered and can be customized per project
+func IsOrdered(field string) bool {
+	_, ok := Defaults[field]
+	return ok
+}
+
+// Values is an ordered list of field values
+type Values []string
+
+// Index returns the position of v in the list or -1 if it is not allowed
+func (vs Values) Index(v string) int {
+	for i, e := range vs {
+		if e == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// Value implements the driver.Valuer interface
+func (vs Values) Value() (driver.Value, error) {
+	return json.Marshal(vs)
+}
+
+// Scan implements the sql.Scanner interface
+func (vs *Values) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("scan source was not []byte")
+	}
+	return json.Unmarshal(b, vs)
+}
+
+// FieldValues describes the allowed values of a work item field in a project
+type FieldValues struct {
+	gormsupport.Lifecycle
+	ID        uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	ProjectID uuid.UUID `sql:"type:uuid"`
+	FieldName string
+	Values    Values `sql:"type:jsonb" gorm:"column:field_values"`
+	Version   int
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m FieldValues) TableName() string {
+	return "field_values"
+}
+
+// Validate checks that value is one of the allowed values, an empty value
+// is always allowed.
+// returns BadParameterError
+func (m FieldValues) Validate(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	s, ok := value.(string)
+	if !ok || (s != "" && m.Values.Index(s) < 0) {
+		return errors.NewBadParameterError(m.FieldName, value).Expected([]string(m.Values))
+	}
+	return nil
+}
+
+// Repository describes interactions with the field values of projects
+type Repository interface {
+	Load(ctx context.Context, projectID uuid.UUID, field string) (*FieldValues, error)
+	Save(ctx context.Context, fv *FieldValues) error
+}
+
+// NewFieldValuesRepository creates a new storage type.
+func NewFieldValuesRepository(db *gorm.DB) Repository {
+	return &GormFieldValuesRepository{db: db}
+}
+
+// GormFieldValuesRepository is the implementation of the storage interface for field values.
+type GormFieldValuesRepository struct {
+	db *gorm.DB
+}
+
+// Load returns the values of the field in the given project, falling back
+// to the defaults if the project did not customize them.
+// returns BadParameterError or InternalError
+func (m *GormFieldValuesRepository) Load(ctx context.Context, projectID uuid.UUID, field string) (*FieldValues, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "fieldvalues", "get"}, time.Now())
+
+	defaults, ok := Defaults[field]
+	if !ok {
+		return nil, errors.NewBadParameterError("field", field).Expected("an ordered field")
+	}
+	var obj FieldValues
+	tx := m.db.Where("project_id = ? AND field_name = ?", projectID, field).First(&obj)
+	if tx.RecordNotFound() {
+		return &FieldValues{ProjectID: projectID, FieldName: field, Values: defaults}, nil
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
+
+// Save stores the customized values of a field in a project. The version of
+// fv must match the stored one, fields that were never customized have
+// version 0.
+// returns BadParameterError, VersionConflictError or InternalError
+func (m *GormFieldValuesRepository) Save(ctx context.Context, fv *FieldValues) error {
+	defer goa.MeasureSince([]string{"goa", "db", "fieldvalues", "save"}, time.Now())
+
+	if !IsOrdered(fv.FieldName) {
+		return errors.NewBadParameterError("field", fv.FieldName).Expected("an ordered field")
+	}
+	if len(fv.Values) == 0 {
+		return errors.NewBadParameterError("values", fv.Values).Expected("not empty")
+	}
+	for i, v := range fv.Values {
+		if v == "" {
+			return errors.NewBadParameterError("values", fv.Values).Expected("no empty values")
+		}
+		if fv.Values.Index(v) != i {
+			return errors.NewBadParameterError("values", fv.Values).Expected("unique values")
+		}
+	}
+
+	var existing FieldValues
+	tx := m.db.Where("project_id = ? AND field_name = ?", fv.ProjectID, fv.FieldName).First(&existing)
+	if tx.Error != nil && !tx.RecordNotFound() {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if existing.Version != fv.Version {
+		return errors.NewVersionConflictError("version conflict")
+	}
+	if tx.RecordNotFound() {
+		fv.ID = uuid.NewV4()
+		fv.Version = 1
+		if err := m.db.Create(fv).Error; err != nil {
+			if gormsupport.IsUniqueViolation(err, "field_values_project_id_field_name_idx") {
+				return errors.NewVersionConflictError("version conflict")
+			}
+			goa.LogError(ctx, "error adding FieldValues", "error", err.Error())
+			return errors.NewInternalError(err.Error())
+		}
+		return nil
+	}
+
+	fv.ID = existing.ID
+	fv.CreatedAt = existing.CreatedAt
+	fv.Version = existing.Version + 1
+	tx = m.db.Model(&existing).Where("version = ?", existing.Version).Updates(map[string]interface{}{
+		"field_values": fv.Values,
+		"version":      fv.Version,
+	})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewVersionConflictError("version conflict")
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package fieldvalues_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/fieldvalues"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestFieldValuesRepository struct {
+	gormsupport.DBTestSuite
+
+	clean     func()
+	projectID uuid.UUID
+}
+
+func TestRunFieldValuesRepository(t *testing.T) {
+	suite.Run(t, &TestFieldValuesRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestFieldValuesRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+
+	p, err := project.NewRepository(test.DB).Create(context.Background(), "fieldvalues-test-"+uuid.NewV4().String())
+	require.Nil(test.T(), err)
+	test.projectID = p.ID
+}
+
+func (test *TestFieldValuesRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestFieldValuesRepository) TestLoadDefaults() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := fieldvalues.NewFieldValuesRepository(test.DB)
+	fv, err := repo.Load(context.Background(), test.projectID, workitem.SystemPriority)
+	require.Nil(t, err)
+	assert.Equal(t, fieldvalues.Defaults[workitem.SystemPriority], fv.Values)
+	assert.Equal(t, 0, fv.Version)
+
+	_, err = repo.Load(context.Background(), test.projectID, workitem.SystemTitle)
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
+
+func (test *TestFieldValuesRepository) TestSaveCustomValues() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := fieldvalues.NewFieldValuesRepository(test.DB)
+	fv := fieldvalues.FieldValues{ProjectID: test.projectID, FieldName: workitem.SystemSeverity, Values: fieldvalues.Values{"s1", "s2"}}
+	require.Nil(t, repo.Save(context.Background(), &fv))
+	assert.Equal(t, 1, fv.Version)
+
+	fv.Values = fieldvalues.Values{"s1", "s2", "s3"}
+	require.Nil(t, repo.Save(context.Background(), &fv))
+
+	loaded, err := repo.Load(context.Background(), test.projectID, workitem.SystemSeverity)
+	require.Nil(t, err)
+	assert.Equal(t, fieldvalues.Values{"s1", "s2", "s3"}, loaded.Values)
+	assert.Equal(t, 2, loaded.Version)
+
+	stale := fieldvalues.FieldValues{ProjectID: test.projectID, FieldName: workitem.SystemSeverity, Values: fieldvalues.Values{"x"}, Version: 1}
+	assert.IsType(t, errors.VersionConflictError{}, repo.Save(context.Background(), &stale))
+}
+
+func (test *TestFieldValuesRepository) TestSaveInvalidValues() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := fieldvalues.NewFieldValuesRepository(test.DB)
+	for _, values := range []fieldvalues.Values{{}, {"a", "a"}, {"a", ""}} {
+		fv := fieldvalues.FieldValues{ProjectID: test.projectID, FieldName: workitem.SystemPriority, Values: values}
+		assert.IsType(t, errors.BadParameterError{}, repo.Save(context.Background(), &fv), "%v", values)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	fv := fieldvalues.FieldValues{FieldName: workitem.SystemPriority, Values: fieldvalues.Defaults[workitem.SystemPriority]}
+	assert.Nil(t, fv.Validate("high"))
+	assert.Nil(t, fv.Validate(nil))
+	assert.IsType(t, errors.BadParameterError{}, fv.Validate("urgent"))
+	assert.IsType(t, errors.BadParameterError{}, fv.Validate(1))
+}
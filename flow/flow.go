@@ -0,0 +1,158 @@
+// Package flow keeps a daily snapshot of the number of work items per state
+// of every project, the data of the cumulative flow diagrams.
+package flow
+
+import (
+	"sort"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// MaxDays is the longest range of a cumulative flow diagram
+const MaxDays = 366
+
+// stateOrder is the order of the known states in the diagrams, from the
+// start of the workflow to its end. Other states follow by name.
+var stateOrder = []string{
+	workitem.SystemStateNew,
+	workitem.SystemStateOpen,
+	workitem.SystemStateInProgress,
+	workitem.SystemStateResolved,
+	workitem.SystemStateClosed,
+	workitem.SystemStateInactive,
+}
+
+// Snapshot is the number of work items of a project in a state at the end of
+// a day
+type Snapshot struct {
+	ProjectID uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	Day       time.Time `gorm:"primary_key"`
+	State     string    `gorm:"primary_key"`
+	Count     int
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m Snapshot) TableName() string {
+	return "work_item_state_snapshots"
+}
+
+// Day holds the counts of a day in the order of the states of the diagram
+type Day struct {
+	Day    time.Time
+	Counts []int
+}
+
+// Diagram is the cumulative flow of a project, only the days with a snapshot
+// are included
+type Diagram struct {
+	States []string
+	Days   []Day
+}
+
+// Repository describes interactions with the state snapshots
+type Repository interface {
+	Snapshot(ctx context.Context, now time.Time) (int, error)
+	Diagram(ctx context.Context, projectID uuid.UUID, from time.Time, to time.Time) (*Diagram, error)
+}
+
+// NewFlowRepository creates a new storage type.
+func NewFlowRepository(db *gorm.DB) Repository {
+	return &GormFlowRepository{db: db}
+}
+
+// GormFlowRepository is the implementation of the storage interface for
+// state snapshots.
+type GormFlowRepository struct {
+	db *gorm.DB
+}
+
+// Snapshot stores the current number of work items per state of all projects
+// as the counts of the UTC day of now, running it again on the same day
+// replaces them. It returns the number of stored counts.
+// returns InternalError
+func (m *GormFlowRepository) Snapshot(ctx context.Context, now time.Time) (int, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "flow", "snapshot"}, time.Now())
+
+	day := truncateDay(now)
+	tx := m.db.Exec("DELETE FROM work_item_state_snapshots WHERE day = ?", day)
+	if tx.Error != nil {
+		return 0, errors.NewInternalError(tx.Error.Error())
+	}
+	tx = m.db.Exec(`INSERT INTO work_item_state_snapshots (project_id, day, state, count)
+		SELECT projects.id, ?, coalesce(work_items.fields->>?, ''), count(*)
+		FROM work_items JOIN projects ON projects.id::text = work_items.fields->>?
+		WHERE work_items.deleted_at IS NULL AND projects.deleted_at IS NULL
+		GROUP BY 1, 2, 3`, day, workitem.SystemState, workitem.SystemProject)
+	if tx.Error != nil {
+		return 0, errors.NewInternalError(tx.Error.Error())
+	}
+	return int(tx.RowsAffected), nil
+}
+
+// Diagram returns the snapshots of the project between the UTC days of from
+// and to, both included
+// returns BadParameterError or InternalError
+func (m *GormFlowRepository) Diagram(ctx context.Context, projectID uuid.UUID, from time.Time, to time.Time) (*Diagram, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "flow", "diagram"}, time.Now())
+
+	from, to = truncateDay(from), truncateDay(to)
+	if to.Before(from) {
+		return nil, errors.NewBadParameterError("to", to.Format("2006-01-02")).Expected("not before " + from.Format("2006-01-02"))
+	}
+	if to.Sub(from) >= MaxDays*24*time.Hour {
+		return nil, errors.NewBadParameterError("from", from.Format("2006-01-02")).Expected("a range of at most 366 days")
+	}
+	var snapshots []Snapshot
+	err := m.db.Where("project_id = ? AND day BETWEEN ? AND ?", projectID, from, to).Order("day").Find(&snapshots).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(err.Error())
+	}
+
+	found := map[string]bool{}
+	for _, s := range snapshots {
+		found[s.State] = true
+	}
+	res := Diagram{States: orderStates(found), Days: []Day{}}
+	index := make(map[string]int, len(res.States))
+	for i, state := range res.States {
+		index[state] = i
+	}
+	for _, s := range snapshots {
+		day := truncateDay(s.Day)
+		if len(res.Days) == 0 || !res.Days[len(res.Days)-1].Day.Equal(day) {
+			res.Days = append(res.Days, Day{Day: day, Counts: make([]int, len(res.States))})
+		}
+		res.Days[len(res.Days)-1].Counts[index[s.State]] = s.Count
+	}
+	return &res, nil
+}
+
+// orderStates returns the found states, the known ones in workflow order
+func orderStates(found map[string]bool) []string {
+	states := []string{}
+	for _, state := range stateOrder {
+		if found[state] {
+			states = append(states, state)
+			delete(found, state)
+		}
+	}
+	others := []string{}
+	for state := range found {
+		others = append(others, state)
+	}
+	sort.Strings(others)
+	return append(states, others...)
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
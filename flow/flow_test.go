@@ -0,0 +1,96 @@
+package flow_test
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/flow"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestFlowRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunFlowRepository(t *testing.T) {
+	suite.Run(t, &TestFlowRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestFlowRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestFlowRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestFlowRepository) createWorkItem(p *project.Project, state string) string {
+	wi, err := workitem.NewWorkItemRepository(test.DB).Create(context.Background(), workitem.SystemBug,
+		map[string]interface{}{
+			workitem.SystemTitle:   "flow",
+			workitem.SystemState:   state,
+			workitem.SystemProject: p.ID.String(),
+		}, uuid.NewV4().String())
+	require.Nil(test.T(), err)
+	return wi.ID
+}
+
+func (test *TestFlowRepository) TestDiagram() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	p, err := project.NewRepository(test.DB).Create(ctx, "flow-"+uuid.NewV4().String())
+	require.Nil(t, err)
+	repo := flow.NewFlowRepository(test.DB)
+	yesterday := time.Now().AddDate(0, 0, -1)
+
+	test.createWorkItem(p, workitem.SystemStateNew)
+	test.createWorkItem(p, "triaged")
+	_, err = repo.Snapshot(ctx, yesterday)
+	require.Nil(t, err)
+
+	id := test.createWorkItem(p, workitem.SystemStateClosed)
+	test.createWorkItem(p, workitem.SystemStateNew)
+	_, err = repo.Snapshot(ctx, time.Now())
+	require.Nil(t, err)
+	// a second snapshot on the same day replaces the first one
+	require.Nil(t, workitem.NewWorkItemRepository(test.DB).Delete(ctx, id))
+	_, err = repo.Snapshot(ctx, time.Now())
+	require.Nil(t, err)
+
+	diagram, err := repo.Diagram(ctx, p.ID, yesterday.AddDate(0, 0, -7), time.Now())
+	require.Nil(t, err)
+	assert.Equal(t, []string{workitem.SystemStateNew, "triaged"}, diagram.States)
+	require.Len(t, diagram.Days, 2)
+	assert.Equal(t, []int{1, 1}, diagram.Days[0].Counts)
+	assert.Equal(t, []int{2, 1}, diagram.Days[1].Counts)
+	assert.True(t, diagram.Days[0].Day.Before(diagram.Days[1].Day))
+
+	diagram, err = repo.Diagram(ctx, p.ID, time.Now(), time.Now())
+	require.Nil(t, err)
+	require.Len(t, diagram.Days, 1)
+}
+
+func (test *TestFlowRepository) TestDiagramInvalidRange() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := flow.NewFlowRepository(test.DB)
+	_, err := repo.Diagram(context.Background(), uuid.NewV4(), time.Now(), time.Now().AddDate(0, 0, -1))
+	assert.IsType(t, errors.BadParameterError{}, err)
+	_, err = repo.Diagram(context.Background(), uuid.NewV4(), time.Now().AddDate(-2, 0, 0), time.Now())
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
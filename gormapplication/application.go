@@ -6,11 +6,53 @@ import (
 
 	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/approval"
+	"github.com/almighty/almighty-core/assignment"
+	"github.com/almighty/almighty-core/attachment"
+	"github.com/almighty/almighty-core/automation"
+	"github.com/almighty/almighty-core/backup"
+	"github.com/almighty/almighty-core/branding"
+	"github.com/almighty/almighty-core/calendar"
+	"github.com/almighty/almighty-core/chat"
+	"github.com/almighty/almighty-core/codebase"
+	"github.com/almighty/almighty-core/codechange"
 	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/dashboard"
+	"github.com/almighty/almighty-core/deployment"
+	"github.com/almighty/almighty-core/escalation"
+	"github.com/almighty/almighty-core/favorite"
+	"github.com/almighty/almighty-core/fieldvalues"
+	"github.com/almighty/almighty-core/flow"
+	"github.com/almighty/almighty-core/hierarchy"
+	"github.com/almighty/almighty-core/impersonation"
+	"github.com/almighty/almighty-core/instancestats"
+	"github.com/almighty/almighty-core/intake"
 	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/job"
+	"github.com/almighty/almighty-core/moderation"
+	"github.com/almighty/almighty-core/notification"
+	"github.com/almighty/almighty-core/outbox"
+	"github.com/almighty/almighty-core/personaldata"
+	"github.com/almighty/almighty-core/portfolio"
+	"github.com/almighty/almighty-core/processconfig"
+	"github.com/almighty/almighty-core/processtemplate"
 	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/projectsettings"
+	"github.com/almighty/almighty-core/push"
+	"github.com/almighty/almighty-core/reaction"
+	"github.com/almighty/almighty-core/redirect"
+	"github.com/almighty/almighty-core/release"
+	"github.com/almighty/almighty-core/remotelink"
 	"github.com/almighty/almighty-core/remoteworkitem"
+	"github.com/almighty/almighty-core/report"
+	"github.com/almighty/almighty-core/retention"
 	"github.com/almighty/almighty-core/search"
+	"github.com/almighty/almighty-core/session"
+	"github.com/almighty/almighty-core/settings"
+	"github.com/almighty/almighty-core/share"
+	"github.com/almighty/almighty-core/stale"
+	"github.com/almighty/almighty-core/translation"
+	"github.com/almighty/almighty-core/vote"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/almighty/almighty-core/workitem/link"
 	"github.com/jinzhu/gorm"
@@ -118,10 +160,305 @@ func (g *GormBase) Iterations() iteration.Repository {
 	return iteration.NewIterationRepository(g.db)
 }
 
+// RemoteLinks returns a remote link repository
+func (g *GormBase) RemoteLinks() remotelink.Repository {
+	return remotelink.NewRemoteLinkRepository(g.db)
+}
+
+// CodeChanges returns a code change repository
+func (g *GormBase) CodeChanges() codechange.Repository {
+	return codechange.NewCodeChangeRepository(g.db)
+}
+
+// Codebases returns a codebase repository
+func (g *GormBase) Codebases() codebase.Repository {
+	return codebase.NewCodebaseRepository(g.db)
+}
+
+// Deployments returns a deployment repository
+func (g *GormBase) Deployments() deployment.Repository {
+	return deployment.NewDeploymentRepository(g.db)
+}
+
+// Releases returns a release repository
+func (g *GormBase) Releases() release.Repository {
+	return release.NewReleaseRepository(g.db)
+}
+
+// FieldValues returns a field values repository
+func (g *GormBase) FieldValues() fieldvalues.Repository {
+	return fieldvalues.NewFieldValuesRepository(g.db)
+}
+
+// Votes returns a vote repository
+func (g *GormBase) Votes() vote.Repository {
+	return vote.NewVoteRepository(g.db)
+}
+
+// Reactions returns a reaction repository
+func (g *GormBase) Reactions() reaction.Repository {
+	return reaction.NewReactionRepository(g.db)
+}
+
+// PersonalData returns a personal data repository
+func (g *GormBase) PersonalData() personaldata.Repository {
+	return personaldata.NewPersonalDataRepository(g.db)
+}
+
+// Moderation returns a moderation repository
+func (g *GormBase) Moderation() moderation.Repository {
+	return moderation.NewModerationRepository(g.db)
+}
+
+// Settings returns a setting repository
+func (g *GormBase) Settings() settings.Repository {
+	return settings.NewSettingRepository(g.db)
+}
+
+// Jobs returns a job repository
+func (g *GormBase) Jobs() job.Repository {
+	return job.NewJobRepository(g.db)
+}
+
+// StalePolicies returns a stale policy repository
+func (g *GormBase) StalePolicies() stale.Repository {
+	return stale.NewStalePolicyRepository(g.db)
+}
+
+// WorkItemEvents returns a work item event repository
+func (g *GormBase) WorkItemEvents() workitem.EventRepository {
+	return workitem.NewEventRepository(g.db)
+}
+
+// Outbox returns an outbox repository
+func (g *GormBase) Outbox() outbox.Repository {
+	return outbox.NewOutboxRepository(g.db)
+}
+
+// Favorites returns a favorite repository
+func (g *GormBase) Favorites() favorite.Repository {
+	return favorite.NewFavoriteRepository(g.db)
+}
+
+// Dashboards returns a dashboard repository
+func (g *GormBase) Dashboards() dashboard.Repository {
+	return dashboard.NewDashboardRepository(g.db)
+}
+
+// Reports returns a report repository
+func (g *GormBase) Reports() report.Repository {
+	return report.NewReportRepository(g.db)
+}
+
+// Flows returns a flow repository
+func (g *GormBase) Flows() flow.Repository {
+	return flow.NewFlowRepository(g.db)
+}
+
+// Trash returns a trash repository
+func (g *GormBase) Trash() workitem.TrashRepository {
+	return workitem.NewTrashRepository(g.db)
+}
+
+// WorkItemArchive returns a work item archive repository
+func (g *GormBase) WorkItemArchive() workitem.ArchiveRepository {
+	return workitem.NewArchiveRepository(g.db)
+}
+
+// WorkItemChecklists returns a work item checklist repository
+func (g *GormBase) WorkItemChecklists() workitem.ChecklistRepository {
+	return workitem.NewChecklistRepository(g.db)
+}
+
+// EventPartitions returns a event partition repository
+func (g *GormBase) EventPartitions() workitem.EventPartitionRepository {
+	return workitem.NewEventPartitionRepository(g.db)
+}
+
+// RetentionPolicies returns a retention policy repository
+func (g *GormBase) RetentionPolicies() retention.Repository {
+	return retention.NewRetentionPolicyRepository(g.db)
+}
+
+// Backups returns a backup repository
+func (g *GormBase) Backups() backup.Repository {
+	return backup.NewBackupRepository(g.db)
+}
+
+// Dumps returns a dumper of backups
+func (g *GormBase) Dumps() backup.Dumper {
+	return backup.NewDumper(g.db)
+}
+
+// WorkItemTypeMigrations returns a work item type migration repository
+func (g *GormBase) WorkItemTypeMigrations() workitem.TypeMigrationRepository {
+	return workitem.NewTypeMigrationRepository(g.db)
+}
+
+// WorkItemTypeDeletions returns a work item type deletion repository
+func (g *GormBase) WorkItemTypeDeletions() workitem.TypeDeletionRepository {
+	return workitem.NewTypeDeletionRepository(g.db)
+}
+
+// Translations returns a translation repository
+func (g *GormBase) Translations() translation.Repository {
+	return translation.NewTranslationRepository(g.db)
+}
+
+// CalendarFeeds returns a calendar feed repository
+func (g *GormBase) CalendarFeeds() calendar.Repository {
+	return calendar.NewFeedRepository(g.db)
+}
+
+// ChatIntegrations returns a chat integration repository
+func (g *GormBase) ChatIntegrations() chat.Repository {
+	return chat.NewIntegrationRepository(g.db)
+}
+
+// AutomationRules returns an automation rule repository
+func (g *GormBase) AutomationRules() automation.Repository {
+	return automation.NewRuleRepository(g.db)
+}
+
+// AssignmentRules returns an assignment rule repository
+func (g *GormBase) AssignmentRules() assignment.Repository {
+	return assignment.NewRuleRepository(g.db)
+}
+
+// EscalationPolicies returns an escalation policy repository
+func (g *GormBase) EscalationPolicies() escalation.Repository {
+	return escalation.NewEscalationPolicyRepository(g.db)
+}
+
+// Approvals returns an approval repository
+func (g *GormBase) Approvals() approval.Repository {
+	return approval.NewRepository(g.db)
+}
+
+// TypeHierarchies returns a type hierarchy repository
+func (g *GormBase) TypeHierarchies() hierarchy.Repository {
+	return hierarchy.NewTypeHierarchyRepository(g.db)
+}
+
+// ProcessConfigs returns a process config repository
+func (g *GormBase) ProcessConfigs() processconfig.Repository {
+	return processconfig.NewRepository(g.db)
+}
+
+// ProcessTemplates returns a process template repository
+func (g *GormBase) ProcessTemplates() processtemplate.Repository {
+	return processtemplate.NewRepository(g.db)
+}
+
+// ProjectSettings returns a project settings repository
+func (g *GormBase) ProjectSettings() projectsettings.Repository {
+	return projectsettings.NewRepository(g.db)
+}
+
+// InstanceStats returns an instance statistics repository
+func (g *GormBase) InstanceStats() instancestats.Repository {
+	return instancestats.NewRepository(g.db)
+}
+
+// Impersonations returns an impersonation repository
+func (g *GormBase) Impersonations() impersonation.Repository {
+	return impersonation.NewRepository(g.db)
+}
+
+// Sessions returns a session repository
+func (g *GormBase) Sessions() session.Repository {
+	return session.NewRepository(g.db)
+}
+
+// Portfolios returns a portfolio repository
+func (g *GormBase) Portfolios() portfolio.Repository {
+	return portfolio.NewPortfolioRepository(g.db)
+}
+
+// WorkItemMerge returns a work item merge repository
+func (g *GormBase) WorkItemMerge() workitem.MergeRepository {
+	return workitem.NewMergeRepository(g.db)
+}
+
+// Redirects returns a redirect repository
+func (g *GormBase) Redirects() redirect.Repository {
+	return redirect.NewRedirectRepository(g.db)
+}
+
+// ShareLinks returns a share link repository
+func (g *GormBase) ShareLinks() share.Repository {
+	return share.NewShareLinkRepository(g.db)
+}
+
+// WorkItemDrafts returns a work item draft repository
+func (g *GormBase) WorkItemDrafts() workitem.DraftRepository {
+	return workitem.NewDraftRepository(g.db)
+}
+
+// WorkItemAutosaves returns a work item autosave repository
+func (g *GormBase) WorkItemAutosaves() workitem.AutosaveRepository {
+	return workitem.NewAutosaveRepository(g.db)
+}
+
+// Attachments returns an attachment repository
+func (g *GormBase) Attachments() attachment.Repository {
+	return attachment.NewAttachmentRepository(g.db)
+}
+
+// WorkItemChanges returns a work item change repository
+func (g *GormBase) WorkItemChanges() workitem.ChangeRepository {
+	return workitem.NewChangeRepository(g.db)
+}
+
+// PushDevices returns a push device repository
+func (g *GormBase) PushDevices() push.Repository {
+	return push.NewDeviceRepository(g.db)
+}
+
+// NotificationPreferences returns a notification preference repository
+func (g *GormBase) NotificationPreferences() notification.PreferenceRepository {
+	return notification.NewPreferenceRepository(g.db)
+}
+
+// NotificationInbox returns a notification inbox repository
+func (g *GormBase) NotificationInbox() notification.InboxRepository {
+	return notification.NewInboxRepository(g.db)
+}
+
+// NotificationMutes returns a notification mute repository
+func (g *GormBase) NotificationMutes() notification.MuteRepository {
+	return notification.NewMuteRepository(g.db)
+}
+
+// EmailTemplates returns an email template repository
+func (g *GormBase) EmailTemplates() notification.TemplateRepository {
+	return notification.NewTemplateRepository(g.db)
+}
+
+// Branding returns a branding repository
+func (g *GormBase) Branding() branding.Repository {
+	return branding.NewRepository(g.db)
+}
+
+// IntakePortals returns a feedback portal repository
+func (g *GormBase) IntakePortals() intake.Repository {
+	return intake.NewRepository(g.db)
+}
+
 func (g *GormBase) DB() *gorm.DB {
 	return g.db
 }
 
+// WithLogger returns a copy of the database that logs its statements to the
+// given logger (see gorm.DB.SetLogger), e.g. a sqldebug.Recorder
+func (g *GormDB) WithLogger(logger interface {
+	Print(v ...interface{})
+}) *GormDB {
+	db := g.db.LogMode(true)
+	db.SetLogger(logger)
+	return &GormDB{GormBase{db}, g.txIsoLevel}
+}
+
 // SetTransactionIsolationLevel sets the isolation level for
 // See also https://www.postgresql.org/docs/9.3/static/sql-set-transaction.html
 func (g *GormDB) SetTransactionIsolationLevel(level TXIsoLevel) error {
@@ -156,6 +493,12 @@ func (g *GormDB) BeginTransaction() (application.Transaction, error) {
 	return &GormTransaction{GormBase{tx}}, nil
 }
 
+// SetReadOnly makes the transaction refuse to write, it must be called
+// before the first statement
+func (g *GormTransaction) SetReadOnly() error {
+	return g.db.Exec("SET TRANSACTION READ ONLY").Error
+}
+
 // Commit implements TransactionSupport
 func (g *GormTransaction) Commit() error {
 	err := g.db.Commit().Error
@@ -24,3 +24,12 @@ func IsUniqueViolation(err error, indexName string) bool {
 	}
 	return pqError.Code == errUniqueViolation && pqError.Constraint == indexName
 }
+
+// IsAnyUniqueViolation returns true if the error is a violation of a unique index
+func IsAnyUniqueViolation(err error) bool {
+	pqError, ok := err.(*pq.Error)
+	if !ok {
+		return false
+	}
+	return pqError.Code == errUniqueViolation
+}
@@ -0,0 +1,22 @@
+// Package testfixture creates the entities the tests against the database
+// need as a starting point.
+package testfixture
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// CreateIdentity creates an identity with the given name and returns its ID,
+// the test fails if the identity can't be created
+func CreateIdentity(t *testing.T, db *gorm.DB, fullName string) uuid.UUID {
+	identity := account.Identity{FullName: fullName}
+	require.Nil(t, account.NewIdentityRepository(db).Create(context.Background(), &identity))
+	return identity.ID
+}
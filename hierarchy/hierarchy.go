@@ -0,0 +1,194 @@
+// Package hierarchy stores the work item type hierarchies of the projects,
+// e.g. epic, feature, story and task. Work items of the types of a hierarchy
+// can only be linked with tree link types to children of the type one level
+// below, work items of other types are not restricted.
+package hierarchy
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// MaxLevels is the number of levels a hierarchy can have
+const MaxLevels = 10
+
+// Levels are the names of the work item types of a hierarchy, the top level
+// first
+type Levels []string
+
+// Value implements the driver.Valuer interface
+func (ls Levels) Value() (driver.Value, error) {
+	if ls == nil {
+		ls = Levels{}
+	}
+	return json.Marshal(ls)
+}
+
+// Scan implements the sql.Scanner interface
+func (ls *Levels) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("scan source was not []byte")
+	}
+	return json.Unmarshal(b, ls)
+}
+
+// Hierarchy is the work item type hierarchy of a project
+type Hierarchy struct {
+	gormsupport.Lifecycle
+	ProjectID uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	Levels    Levels    `sql:"type:jsonb"`
+}
+
+// TableName implements gorm.tabler
+func (h Hierarchy) TableName() string {
+	return "type_hierarchies"
+}
+
+// Validate checks that the hierarchy has at least two levels of distinct
+// types
+// returns BadParameterError
+func (h Hierarchy) Validate() error {
+	if len(h.Levels) < 2 || len(h.Levels) > MaxLevels {
+		return errors.NewBadParameterError("levels", len(h.Levels)).Expected(fmt.Sprintf("between 2 and %d levels", MaxLevels))
+	}
+	seen := map[string]bool{}
+	for _, l := range h.Levels {
+		if l == "" || seen[l] {
+			return errors.NewBadParameterError("levels", l).Expected("distinct work item types")
+		}
+		seen[l] = true
+	}
+	return nil
+}
+
+// level returns the level of the type in the hierarchy, -1 if it isn't part
+// of it
+func (h Hierarchy) level(typeName string) int {
+	for i, l := range h.Levels {
+		if l == typeName {
+			return i
+		}
+	}
+	return -1
+}
+
+// Allows checks that a work item of the parent type can have a child of the
+// child type
+// returns BadParameterError
+func (h Hierarchy) Allows(parentType, childType string) error {
+	parent, child := h.level(parentType), h.level(childType)
+	if (parent == -1 && child == -1) || (parent != -1 && child == parent+1) {
+		return nil
+	}
+	forbidden := errors.NewBadParameterError("link", parentType+" -> "+childType)
+	switch {
+	case parent == len(h.Levels)-1:
+		return forbidden.Expected(fmt.Sprintf("no children of %s, the lowest level of the hierarchy %s", parentType, h))
+	case parent != -1:
+		return forbidden.Expected(fmt.Sprintf("a child of type %s as in the hierarchy %s", h.Levels[parent+1], h))
+	case child == 0:
+		return forbidden.Expected(fmt.Sprintf("no parent of %s, the top level of the hierarchy %s", childType, h))
+	default:
+		return forbidden.Expected(fmt.Sprintf("a parent of type %s as in the hierarchy %s", h.Levels[child-1], h))
+	}
+}
+
+// String returns the levels of the hierarchy, e.g. "epic > feature > story"
+func (h Hierarchy) String() string {
+	return strings.Join(h.Levels, " > ")
+}
+
+// Repository encapsulates storage & retrieval of type hierarchies
+type Repository interface {
+	Load(ctx context.Context, projectID uuid.UUID) (*Hierarchy, error)
+	Save(ctx context.Context, h Hierarchy) (*Hierarchy, error)
+	Delete(ctx context.Context, projectID uuid.UUID) error
+	Check(ctx context.Context, projectID uuid.UUID, parentType, childType string) error
+}
+
+// NewTypeHierarchyRepository creates a new storage type.
+func NewTypeHierarchyRepository(db *gorm.DB) Repository {
+	return &GormTypeHierarchyRepository{db: db}
+}
+
+// GormTypeHierarchyRepository is the implementation of the storage interface
+// for type hierarchies.
+type GormTypeHierarchyRepository struct {
+	db *gorm.DB
+}
+
+// Load returns the hierarchy of the project
+// returns NotFoundError or InternalError
+func (m *GormTypeHierarchyRepository) Load(ctx context.Context, projectID uuid.UUID) (*Hierarchy, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "typehierarchy", "get"}, time.Now())
+
+	var obj Hierarchy
+	tx := m.db.Where("project_id = ?", projectID).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("type hierarchy", projectID.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
+
+// Save creates or replaces the hierarchy of the project, existing links are
+// not checked against it
+// returns BadParameterError or InternalError
+func (m *GormTypeHierarchyRepository) Save(ctx context.Context, h Hierarchy) (*Hierarchy, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "typehierarchy", "save"}, time.Now())
+
+	if err := h.Validate(); err != nil {
+		return nil, err
+	}
+	tx := m.db.Exec(`INSERT INTO type_hierarchies (project_id, levels, created_at, updated_at)
+		VALUES (?, ?, now(), now())
+		ON CONFLICT (project_id) DO UPDATE SET levels = excluded.levels, updated_at = now(), deleted_at = NULL`,
+		h.ProjectID, h.Levels)
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return m.Load(ctx, h.ProjectID)
+}
+
+// Delete removes the hierarchy of the project
+// returns NotFoundError or InternalError
+func (m *GormTypeHierarchyRepository) Delete(ctx context.Context, projectID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "typehierarchy", "delete"}, time.Now())
+
+	tx := m.db.Where("project_id = ?", projectID).Delete(&Hierarchy{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("type hierarchy", projectID.String())
+	}
+	return nil
+}
+
+// Check checks that the hierarchy of the project, if it has one, allows a
+// work item of the parent type to have a child of the child type
+// returns BadParameterError or InternalError
+func (m *GormTypeHierarchyRepository) Check(ctx context.Context, projectID uuid.UUID, parentType, childType string) error {
+	h, err := m.Load(ctx, projectID)
+	if err != nil {
+		if _, ok := err.(errors.NotFoundError); ok {
+			return nil
+		}
+		return err
+	}
+	return h.Allows(parentType, childType)
+}
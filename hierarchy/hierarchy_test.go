@@ -0,0 +1,48 @@
+package hierarchy_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/hierarchy"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	assert.Nil(t, hierarchy.Hierarchy{Levels: hierarchy.Levels{"epic", "feature"}}.Validate())
+	for _, levels := range []hierarchy.Levels{
+		nil,
+		{"epic"},
+		{"epic", ""},
+		{"epic", "feature", "epic"},
+		make(hierarchy.Levels, hierarchy.MaxLevels+1),
+	} {
+		assert.IsType(t, errors.BadParameterError{}, hierarchy.Hierarchy{Levels: levels}.Validate(), "%v", levels)
+	}
+}
+
+func TestAllows(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	h := hierarchy.Hierarchy{Levels: hierarchy.Levels{"epic", "feature", "story", "task"}}
+	assert.Nil(t, h.Allows("epic", "feature"))
+	assert.Nil(t, h.Allows("story", "task"))
+	// types outside of the hierarchy are not restricted
+	assert.Nil(t, h.Allows("bug", "bug"))
+	for _, pair := range [][2]string{
+		{"epic", "story"},
+		{"feature", "epic"},
+		{"task", "task"},
+		{"epic", "bug"},
+		{"bug", "epic"},
+		{"bug", "feature"},
+	} {
+		err := h.Allows(pair[0], pair[1])
+		assert.IsType(t, errors.BadParameterError{}, err, "%v", pair)
+	}
+	assert.Contains(t, h.Allows("epic", "story").Error(), "a child of type feature")
+	assert.Contains(t, h.Allows("bug", "feature").Error(), "a parent of type epic")
+}
@@ -0,0 +1,135 @@
+// Package httpserver sets up the HTTP server of the service: the timeouts that
+// keep slow clients from holding on to connections, HTTP/2 on TLS connections
+// and the metrics of the connections.
+package httpserver
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/goadesign/goa"
+	"golang.org/x/net/http2"
+)
+
+// New returns the server of the handler on the configured address with the
+// configured timeouts, its connections are tracked by conns
+func New(handler http.Handler, conns *Connections) (*http.Server, error) {
+	srv := &http.Server{
+		Addr:              configuration.GetHTTPAddress(),
+		Handler:           handler,
+		ReadTimeout:       configuration.GetHTTPReadTimeout(),
+		ReadHeaderTimeout: configuration.GetHTTPReadHeaderTimeout(),
+		WriteTimeout:      configuration.GetHTTPWriteTimeout(),
+		IdleTimeout:       configuration.GetHTTPIdleTimeout(),
+		MaxHeaderBytes:    configuration.GetHTTPMaxHeaderBytes(),
+		ConnState:         conns.Track,
+	}
+	if !configuration.IsHTTP2Enabled() {
+		// a non-nil map without "h2" turns off the built-in HTTP/2
+		srv.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+		return srv, nil
+	}
+	err := http2.ConfigureServer(srv, &http2.Server{
+		MaxConcurrentStreams: uint32(configuration.GetHTTP2MaxConcurrentStreams()),
+		IdleTimeout:          configuration.GetHTTPIdleTimeout(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return srv, nil
+}
+
+// ListenAndServe serves TLS if a certificate is configured, plain HTTP
+// otherwise
+func ListenAndServe(srv *http.Server) error {
+	if cert := configuration.GetHTTPTLSCert(); cert != "" {
+		return srv.ListenAndServeTLS(cert, configuration.GetHTTPTLSKey())
+	}
+	return srv.ListenAndServe()
+}
+
+// Stats are the numbers of connections of a server
+type Stats struct {
+	// Active connections are reading or serving a request
+	Active int
+	// Idle connections are kept alive between requests
+	Idle int
+	// Opened and Closed count the connections since the server started,
+	// hijacked connections count as closed
+	Opened uint64
+	Closed uint64
+}
+
+// Connections tracks the state of the connections of a server
+type Connections struct {
+	mu     sync.Mutex
+	states map[net.Conn]http.ConnState
+	stats  Stats
+}
+
+// NewConnections returns a tracker without connections
+func NewConnections() *Connections {
+	return &Connections{states: map[net.Conn]http.ConnState{}}
+}
+
+// Track records the state of the connection and emits the metrics of the
+// connections, it is the ConnState hook of the server
+func (c *Connections) Track(conn net.Conn, state http.ConnState) {
+	c.mu.Lock()
+	if previous, ok := c.states[conn]; ok {
+		c.count(previous, -1)
+	} else if state == http.StateNew {
+		c.stats.Opened++
+	}
+	switch state {
+	case http.StateClosed, http.StateHijacked:
+		if _, ok := c.states[conn]; ok {
+			c.stats.Closed++
+			delete(c.states, conn)
+		}
+	default:
+		c.states[conn] = state
+		c.count(state, 1)
+	}
+	stats := c.stats
+	c.mu.Unlock()
+
+	goa.IncrCounter([]string{"goa", "http", "connections", state.String()}, 1)
+	goa.SetGauge([]string{"goa", "http", "connections", "active"}, float32(stats.Active))
+	goa.SetGauge([]string{"goa", "http", "connections", "idle"}, float32(stats.Idle))
+}
+
+// ClearWriteDeadline lifts the write timeout of the connection of the request
+// for responses streamed for longer than the timeout, like exports. It returns
+// false if the connection isn't tracked. HTTP/2 streams keep their timeout,
+// as the deadline of their shared connection isn't what enforces it.
+func (c *Connections) ClearWriteDeadline(req *http.Request) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for conn := range c.states {
+		if conn.RemoteAddr().String() == req.RemoteAddr {
+			return conn.SetWriteDeadline(time.Time{}) == nil
+		}
+	}
+	return false
+}
+
+// count adds n to the number of connections in the state
+func (c *Connections) count(state http.ConnState, n int) {
+	if state == http.StateIdle {
+		c.stats.Idle += n
+	} else {
+		c.stats.Active += n
+	}
+}
+
+// Stats returns the current numbers of connections
+func (c *Connections) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
@@ -0,0 +1,92 @@
+package httpserver_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/httpserver"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	if err := configuration.Setup(""); err != nil {
+		panic(fmt.Errorf("Failed to setup the configuration: %s", err.Error()))
+	}
+	os.Exit(m.Run())
+}
+
+func TestNew(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	srv, err := httpserver.New(http.NotFoundHandler(), httpserver.NewConnections())
+	require.Nil(t, err)
+	assert.Equal(t, configuration.GetHTTPReadHeaderTimeout(), srv.ReadHeaderTimeout)
+	assert.Equal(t, configuration.GetHTTPWriteTimeout(), srv.WriteTimeout)
+	assert.Equal(t, configuration.GetHTTPIdleTimeout(), srv.IdleTimeout)
+	// HTTP/2 is negotiated on TLS connections
+	assert.Contains(t, srv.TLSConfig.NextProtos, "h2")
+	assert.NotNil(t, srv.TLSNextProto["h2"])
+}
+
+func TestConnections(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	conns := httpserver.NewConnections()
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+
+	conns.Track(a, http.StateNew)
+	conns.Track(b, http.StateNew)
+	assert.Equal(t, httpserver.Stats{Active: 2, Opened: 2}, conns.Stats())
+
+	conns.Track(a, http.StateActive)
+	conns.Track(a, http.StateIdle)
+	assert.Equal(t, httpserver.Stats{Active: 1, Idle: 1, Opened: 2}, conns.Stats())
+
+	conns.Track(a, http.StateClosed)
+	conns.Track(b, http.StateHijacked)
+	assert.Equal(t, httpserver.Stats{Opened: 2, Closed: 2}, conns.Stats())
+
+	// connections are closed once
+	conns.Track(a, http.StateClosed)
+	assert.Equal(t, httpserver.Stats{Opened: 2, Closed: 2}, conns.Stats())
+}
+
+func TestClearWriteDeadline(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	conns := httpserver.NewConnections()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/stream" {
+			assert.True(t, conns.ClearWriteDeadline(r))
+		}
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("done"))
+	}))
+	server.Config.WriteTimeout = 50 * time.Millisecond
+	server.Config.ConnState = conns.Track
+	server.Start()
+	defer server.Close()
+
+	// the timeout cuts off the responses
+	_, err := http.Get(server.URL + "/list")
+	assert.NotNil(t, err)
+
+	// unless the handler clears it for its connection
+	res, err := http.Get(server.URL + "/stream")
+	require.Nil(t, err)
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	require.Nil(t, err)
+	assert.Equal(t, "done", string(body))
+}
@@ -22,7 +22,7 @@ func NewIdentityController(service *goa.Service, db application.DB) *IdentityCon
 
 // List runs the list action.
 func (c *IdentityController) List(ctx *app.ListIdentityContext) error {
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 		result, err := appl.Identities().List(ctx.Context)
 		if err != nil {
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(fmt.Sprintf("Error listing identities: %s", err.Error())))
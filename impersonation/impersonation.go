@@ -0,0 +1,94 @@
+// Package impersonation audits the tokens instance admins are issued to act
+// as another identity, so support can reproduce the permission problems of a
+// user. Every token is recorded with the admin, the identity, the reason and
+// its expiry before it is handed out.
+package impersonation
+
+import (
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Impersonation records a token issued to an instance admin to act as
+// another identity
+type Impersonation struct {
+	ID             uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	CreatedAt      time.Time
+	ImpersonatorID uuid.UUID `sql:"type:uuid"`
+	IdentityID     uuid.UUID `sql:"type:uuid"`
+	Reason         string
+	ExpiresAt      time.Time
+}
+
+// TableName implements gorm.tabler
+func (i Impersonation) TableName() string {
+	return "impersonations"
+}
+
+// Repository encapsulates storage & retrieval of impersonations
+type Repository interface {
+	Create(ctx context.Context, i *Impersonation) error
+	List(ctx context.Context, identityID *uuid.UUID, offset int, limit int) ([]Impersonation, int, error)
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for
+// impersonations.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Create records the impersonation, it needs a reason, an impersonator
+// other than the identity and an expiry in the future
+// returns BadParameterError or InternalError
+func (m *GormRepository) Create(ctx context.Context, i *Impersonation) error {
+	defer goa.MeasureSince([]string{"goa", "db", "impersonation", "create"}, time.Now())
+
+	i.Reason = strings.TrimSpace(i.Reason)
+	if i.Reason == "" {
+		return errors.NewBadParameterError("reason", i.Reason).Expected("not empty")
+	}
+	if uuid.Equal(i.ImpersonatorID, i.IdentityID) {
+		return errors.NewBadParameterError("identity", i.IdentityID).Expected("not the impersonator")
+	}
+	if !i.ExpiresAt.After(time.Now()) {
+		return errors.NewBadParameterError("expires-at", i.ExpiresAt).Expected("in the future")
+	}
+	i.ID = uuid.NewV4()
+	if err := m.db.Create(i).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// List returns a page of the impersonations, of the identity if not nil, the
+// latest first with the total number of them
+// returns InternalError
+func (m *GormRepository) List(ctx context.Context, identityID *uuid.UUID, offset int, limit int) ([]Impersonation, int, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "impersonation", "list"}, time.Now())
+
+	db := m.db.Model(&Impersonation{})
+	if identityID != nil {
+		db = db.Where("identity_id = ?", *identityID)
+	}
+	var total int
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, errors.NewInternalError(err.Error())
+	}
+	res := []Impersonation{}
+	if err := db.Order("created_at DESC, id").Offset(offset).Limit(limit).Find(&res).Error; err != nil {
+		return nil, 0, errors.NewInternalError(err.Error())
+	}
+	return res, total, nil
+}
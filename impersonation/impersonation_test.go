@@ -0,0 +1,90 @@
+package impersonation_test
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/impersonation"
+	"github.com/almighty/almighty-core/resource"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestImpersonationRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunImpersonationRepository(t *testing.T) {
+	suite.Run(t, &TestImpersonationRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestImpersonationRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestImpersonationRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestImpersonationRepository) TestCreateAndList() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := impersonation.NewRepository(test.DB)
+	admin := uuid.NewV4()
+	user := uuid.NewV4()
+	expires := time.Now().Add(time.Hour)
+
+	first := &impersonation.Impersonation{ImpersonatorID: admin, IdentityID: user, Reason: " ticket 1 ", ExpiresAt: expires}
+	require.Nil(t, repo.Create(ctx, first))
+	assert.Equal(t, "ticket 1", first.Reason)
+	other := &impersonation.Impersonation{ImpersonatorID: admin, IdentityID: uuid.NewV4(), Reason: "ticket 2", ExpiresAt: expires}
+	require.Nil(t, repo.Create(ctx, other))
+	second := &impersonation.Impersonation{ImpersonatorID: admin, IdentityID: user, Reason: "ticket 3", ExpiresAt: expires}
+	require.Nil(t, repo.Create(ctx, second))
+
+	res, total, err := repo.List(ctx, &user, 0, 10)
+	require.Nil(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, res, 2)
+	assert.Equal(t, second.ID, res[0].ID)
+	assert.Equal(t, first.ID, res[1].ID)
+	assert.Equal(t, admin, res[1].ImpersonatorID)
+
+	res, total, err = repo.List(ctx, &user, 1, 10)
+	require.Nil(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, res, 1)
+	assert.Equal(t, first.ID, res[0].ID)
+
+	res, total, err = repo.List(ctx, nil, 0, 10)
+	require.Nil(t, err)
+	assert.True(t, total >= 3)
+}
+
+func (test *TestImpersonationRepository) TestCreateInvalid() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := impersonation.NewRepository(test.DB)
+	admin := uuid.NewV4()
+
+	for _, i := range []impersonation.Impersonation{
+		{ImpersonatorID: admin, IdentityID: uuid.NewV4(), Reason: "  ", ExpiresAt: time.Now().Add(time.Hour)},
+		{ImpersonatorID: admin, IdentityID: admin, Reason: "ticket", ExpiresAt: time.Now().Add(time.Hour)},
+		{ImpersonatorID: admin, IdentityID: uuid.NewV4(), Reason: "ticket", ExpiresAt: time.Now().Add(-time.Hour)},
+	} {
+		err := repo.Create(ctx, &i)
+		assert.IsType(t, errors.BadParameterError{}, err, i.Reason)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/impersonation"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/session"
+	"github.com/almighty/almighty-core/token"
+	"github.com/goadesign/goa"
+)
+
+// ImpersonationsController implements the impersonations resource.
+type ImpersonationsController struct {
+	*goa.Controller
+	db           application.DB
+	tokenManager token.Manager
+}
+
+// NewImpersonationsController creates an impersonations controller.
+func NewImpersonationsController(service *goa.Service, db application.DB, tokenManager token.Manager) *ImpersonationsController {
+	return &ImpersonationsController{Controller: service.NewController("ImpersonationsController"), db: db, tokenManager: tokenManager}
+}
+
+// Create runs the create action.
+func (c *ImpersonationsController) Create(ctx *app.CreateImpersonationsContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can impersonate identities"))
+	}
+	if impersonator, err := c.tokenManager.LocateImpersonator(ctx); err != nil || impersonator != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("impersonation tokens can't impersonate identities"))
+	}
+	identityID := ctx.Payload.Identity
+	for _, id := range configuration.GetAdminIdentities() {
+		if id == identityID.String() {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("identity", identityID).Expected("not an instance admin"))
+		}
+	}
+	impersonatorID := currentIdentityID(ctx)
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		impersonator, err := appl.Identities().Load(ctx, *impersonatorID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewNotFoundError("identity", impersonatorID.String()))
+		}
+		identity, err := appl.Identities().Load(ctx, identityID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewNotFoundError("identity", identityID.String()))
+		}
+		// tokens carry the expiry in seconds
+		i := &impersonation.Impersonation{
+			ImpersonatorID: impersonator.ID,
+			IdentityID:     identity.ID,
+			Reason:         ctx.Payload.Reason,
+			ExpiresAt:      time.Now().Add(configuration.GetImpersonationTTL()).Truncate(time.Second),
+		}
+		if err := appl.Impersonations().Create(ctx, i); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		tokenStr, err := c.tokenManager.Impersonate(*identity, *impersonator, i.ExpiresAt)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewInternalError(err.Error()))
+		}
+		s := &session.Session{
+			IdentityID:     identity.ID,
+			ImpersonatorID: &i.ImpersonatorID,
+			UserAgent:      ctx.RequestData.Header.Get("User-Agent"),
+			ExpiresAt:      &i.ExpiresAt,
+		}
+		if err := session.Record(ctx, appl.Sessions(), c.tokenManager, tokenStr, s); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		goa.LogInfo(ctx, "impersonation issued", "impersonator", i.ImpersonatorID.String(), "identity", i.IdentityID.String(), "reason", i.Reason)
+		res := convertImpersonation(*i)
+		res.Token = &tokenStr
+		return ctx.OK(res)
+	})
+}
+
+// List runs the list action.
+func (c *ImpersonationsController) List(ctx *app.ListImpersonationsContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can see the impersonations"))
+	}
+	offset, limit := computePagingLimts(ctx.PageOffset, ctx.PageLimit)
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		impersonations, total, err := appl.Impersonations().List(ctx, ctx.FilterIdentity, offset, limit)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.ImpersonationList{
+			Data:  make([]*app.Impersonation, len(impersonations)),
+			Links: &app.PagingLinks{},
+			Meta:  &app.ProjectListMeta{TotalCount: total},
+		}
+		for i, imp := range impersonations {
+			res.Data[i] = convertImpersonation(imp)
+		}
+		var extra []string
+		if ctx.FilterIdentity != nil {
+			extra = append(extra, "filter[identity]="+ctx.FilterIdentity.String())
+		}
+		setPagingLinks(res.Links, buildAbsoluteURL(ctx.RequestData), len(impersonations), offset, limit, total, extra...)
+		return ctx.OK(res)
+	})
+}
+
+func convertImpersonation(i impersonation.Impersonation) *app.Impersonation {
+	return &app.Impersonation{
+		ID:           i.ID,
+		Impersonator: i.ImpersonatorID,
+		Identity:     i.IdentityID,
+		Reason:       i.Reason,
+		CreatedAt:    i.CreatedAt,
+		ExpiresAt:    i.ExpiresAt,
+	}
+}
+
+// AuditImpersonation is a middleware logging the requests made with the
+// tokens instance admins were issued to act as another identity, with the
+// admin and the identity
+func AuditImpersonation(tm token.Manager) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			auth := req.Header.Get("Authorization")
+			if !strings.HasPrefix(auth, "Bearer ") {
+				return h(ctx, rw, req)
+			}
+			tokenStr := strings.TrimPrefix(auth, "Bearer ")
+			impersonator, err := tm.ExtractImpersonator(tokenStr)
+			if err != nil || impersonator == nil {
+				return h(ctx, rw, req)
+			}
+			if identity, err := tm.Extract(tokenStr); err == nil {
+				goa.LogInfo(ctx, "impersonated request", "impersonator", impersonator.String(), "identity", identity.ID.String(), "method", req.Method, "path", req.URL.Path)
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"time"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/instancestats"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/goadesign/goa"
+)
+
+// InstanceStatsController implements the instance-stats resource.
+type InstanceStatsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewInstanceStatsController creates an instance-stats controller.
+func NewInstanceStatsController(service *goa.Service, db application.DB) *InstanceStatsController {
+	return &InstanceStatsController{Controller: service.NewController("InstanceStatsController"), db: db}
+}
+
+// Overview runs the overview action.
+func (c *InstanceStatsController) Overview(ctx *app.OverviewInstanceStatsContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can see the statistics"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		o, err := appl.InstanceStats().Overview(ctx, time.Now(), ctx.Weeks)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.InstanceOverview{
+			Projects:     o.Projects,
+			WorkItems:    o.WorkItems,
+			Identities:   o.Identities,
+			Storage:      int(o.Storage),
+			DatabaseSize: int(o.DatabaseSize),
+			ActiveDay:    o.ActiveDay,
+			ActiveWeek:   o.ActiveWeek,
+			ActiveMonth:  o.ActiveMonth,
+			Growth:       make([]*app.InstanceGrowthWeek, len(o.Growth)),
+		}
+		for i, w := range o.Growth {
+			res.Growth[i] = &app.InstanceGrowthWeek{Start: w.Start, Projects: w.Projects, WorkItems: w.WorkItems, Identities: w.Identities}
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Projects runs the projects action.
+func (c *InstanceStatsController) Projects(ctx *app.ProjectsInstanceStatsContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can see the statistics"))
+	}
+	offset, limit := computePagingLimts(ctx.PageOffset, ctx.PageLimit)
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		stats, total, err := appl.InstanceStats().Projects(ctx, ctx.Sort, offset, limit)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.InstanceProjectStatsList{
+			Data:  make([]*app.InstanceProjectStats, len(stats)),
+			Links: &app.PagingLinks{},
+			Meta:  &app.ProjectListMeta{TotalCount: total},
+		}
+		for i, s := range stats {
+			res.Data[i] = convertInstanceProjectStats(s)
+		}
+		setPagingLinks(res.Links, buildAbsoluteURL(ctx.RequestData), len(stats), offset, limit, total, "sort="+ctx.Sort)
+		return ctx.OK(res)
+	})
+}
+
+func convertInstanceProjectStats(s instancestats.ProjectStats) *app.InstanceProjectStats {
+	return &app.InstanceProjectStats{
+		ID:           s.ProjectID,
+		Name:         s.Name,
+		WorkItems:    s.WorkItems,
+		Users:        s.Users,
+		Storage:      int(s.Storage),
+		LastActivity: s.LastActivity,
+	}
+}
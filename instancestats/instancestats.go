@@ -0,0 +1,250 @@
+// Package instancestats reports the usage of a deployment to its operators
+// for capacity planning: the totals and active users of the instance, its
+// growth by week and the work items, users and storage of every project.
+// Users are the identities who created or changed a work item or commented.
+package instancestats
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Limits of the reports
+const (
+	DefaultWeeks = 12
+	MaxWeeks     = 104
+)
+
+// The orders of the project statistics, the largest first
+const (
+	SortWorkItems = "work-items"
+	SortUsers     = "users"
+	SortStorage   = "storage"
+	SortActivity  = "activity"
+)
+
+// sortExpressions are the ORDER BY expressions of the orders
+var sortExpressions = map[string]string{
+	SortWorkItems: "work_items DESC",
+	SortUsers:     "users DESC",
+	SortStorage:   "storage DESC",
+	SortActivity:  "last_activity DESC NULLS LAST",
+}
+
+// Week counts what was created in the week starting on the Monday in UTC
+type Week struct {
+	Start      time.Time
+	Projects   int
+	WorkItems  int
+	Identities int
+}
+
+// Overview are the totals of the instance
+type Overview struct {
+	Projects   int
+	WorkItems  int
+	Identities int
+	// Storage is the size of the attachments and work item fields in bytes
+	Storage int64
+	// DatabaseSize is the size of the whole database in bytes
+	DatabaseSize int64
+	// ActiveDay, ActiveWeek and ActiveMonth are the users active in the
+	// last 1, 7 and 30 days
+	ActiveDay   int
+	ActiveWeek  int
+	ActiveMonth int
+	// Growth are the weeks up to the current one, the oldest first
+	Growth []Week
+}
+
+// ProjectStats are the statistics of a project
+type ProjectStats struct {
+	ProjectID uuid.UUID
+	Name      string
+	WorkItems int
+	Users     int
+	// Storage is the size of the attachments and work item fields of the
+	// project in bytes
+	Storage int64
+	// LastActivity is when a work item of the project last changed, nil if
+	// it has none
+	LastActivity *time.Time
+}
+
+// WeekStart returns the start of the week of t, Monday 00:00 UTC
+func WeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	// Sunday is the last day of the week
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// Repository reports the statistics of the instance
+type Repository interface {
+	Overview(ctx context.Context, now time.Time, weeks int) (*Overview, error)
+	Projects(ctx context.Context, sort string, offset int, limit int) ([]ProjectStats, int, error)
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for
+// instance statistics.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Overview returns the totals of the instance with the growth of the given
+// number of weeks up to the one of now
+// returns BadParameterError or InternalError
+func (m *GormRepository) Overview(ctx context.Context, now time.Time, weeks int) (*Overview, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "instancestats", "overview"}, time.Now())
+
+	if weeks < 1 || weeks > MaxWeeks {
+		return nil, errors.NewBadParameterError("weeks", weeks).Expected(fmt.Sprintf("between 1 and %d", MaxWeeks))
+	}
+	var totals struct {
+		Projects     int
+		WorkItems    int
+		Identities   int
+		Storage      int64
+		DatabaseSize int64
+	}
+	err := m.db.Raw(`SELECT
+		(SELECT count(*) FROM projects WHERE deleted_at IS NULL) AS projects,
+		(SELECT count(*) FROM work_items WHERE deleted_at IS NULL) AS work_items,
+		(SELECT count(*) FROM identities WHERE deleted_at IS NULL) AS identities,
+		coalesce((SELECT sum(size) FROM attachments WHERE deleted_at IS NULL), 0)
+			+ coalesce((SELECT sum(pg_column_size(fields)) FROM work_items WHERE deleted_at IS NULL), 0) AS storage,
+		pg_database_size(current_database()) AS database_size`).Scan(&totals).Error
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	res := &Overview{
+		Projects:     totals.Projects,
+		WorkItems:    totals.WorkItems,
+		Identities:   totals.Identities,
+		Storage:      totals.Storage,
+		DatabaseSize: totals.DatabaseSize,
+	}
+	for _, active := range []struct {
+		days  int
+		count *int
+	}{{1, &res.ActiveDay}, {7, &res.ActiveWeek}, {30, &res.ActiveMonth}} {
+		n, err := m.activeUsers(now.AddDate(0, 0, -active.days))
+		if err != nil {
+			return nil, err
+		}
+		*active.count = n
+	}
+	growth, err := m.growth(now, weeks)
+	if err != nil {
+		return nil, err
+	}
+	res.Growth = growth
+	return res, nil
+}
+
+// activeUsers returns the number of users active since the time
+func (m *GormRepository) activeUsers(since time.Time) (int, error) {
+	var res struct {
+		Count int
+	}
+	err := m.db.Raw(`SELECT count(DISTINCT identity) AS count FROM (
+			SELECT modifier_id AS identity FROM work_item_events WHERE created_at >= ?
+			UNION ALL
+			SELECT created_by FROM comments WHERE created_at >= ? AND deleted_at IS NULL
+		) AS activity WHERE identity IS NOT NULL`, since, since).Scan(&res).Error
+	if err != nil {
+		return 0, errors.NewInternalError(err.Error())
+	}
+	return res.Count, nil
+}
+
+// growth returns what was created in each of the weeks up to the one of now
+func (m *GormRepository) growth(now time.Time, weeks int) ([]Week, error) {
+	first := WeekStart(now).AddDate(0, 0, -7*(weeks-1))
+	res := make([]Week, weeks)
+	index := map[time.Time]int{}
+	for i := range res {
+		res[i].Start = first.AddDate(0, 0, 7*i)
+		index[res[i].Start] = i
+	}
+	for _, t := range []struct {
+		table string
+		count func(w *Week) *int
+	}{
+		{"projects", func(w *Week) *int { return &w.Projects }},
+		{"work_items", func(w *Week) *int { return &w.WorkItems }},
+		{"identities", func(w *Week) *int { return &w.Identities }},
+	} {
+		var rows []struct {
+			Week  time.Time
+			Count int
+		}
+		// the table names are constants
+		err := m.db.Raw(`SELECT date_trunc('week', created_at AT TIME ZONE 'UTC') AS week, count(*) AS count
+			FROM `+t.table+` WHERE created_at >= ? AND deleted_at IS NULL GROUP BY 1`, first).Scan(&rows).Error
+		if err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		for _, row := range rows {
+			start := time.Date(row.Week.Year(), row.Week.Month(), row.Week.Day(), 0, 0, 0, 0, time.UTC)
+			if i, ok := index[start]; ok {
+				*t.count(&res[i]) = row.Count
+			}
+		}
+	}
+	return res, nil
+}
+
+// Projects returns a page of the statistics of the projects in the order
+// with the total number of projects, ties are ordered by name
+// returns BadParameterError or InternalError
+func (m *GormRepository) Projects(ctx context.Context, sort string, offset int, limit int) ([]ProjectStats, int, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "instancestats", "projects"}, time.Now())
+
+	order, ok := sortExpressions[sort]
+	if !ok {
+		return nil, 0, errors.NewBadParameterError("sort", sort).Expected([]string{SortWorkItems, SortUsers, SortStorage, SortActivity})
+	}
+	var total int
+	if err := m.db.Table("projects").Where("deleted_at IS NULL").Count(&total).Error; err != nil {
+		return nil, 0, errors.NewInternalError(err.Error())
+	}
+	var rows []ProjectStats
+	err := m.db.Raw(`SELECT p.id AS project_id, p.name, coalesce(w.work_items, 0) AS work_items,
+			(SELECT count(DISTINCT u.identity) FROM (
+				SELECT r.modifier_id AS identity FROM work_item_events r
+				JOIN work_items rw ON rw.id = r.work_item_id WHERE rw.fields->>? = p.id::text
+				UNION ALL
+				SELECT c.created_by FROM comments c
+				JOIN work_items cw ON c.parent_id = cw.id::text WHERE cw.fields->>? = p.id::text AND c.deleted_at IS NULL
+			) AS u WHERE u.identity IS NOT NULL) AS users,
+			coalesce(w.fields_size, 0) + coalesce((SELECT sum(a.size) FROM attachments a WHERE a.project_id = p.id AND a.deleted_at IS NULL), 0) AS storage,
+			w.last_activity
+		FROM projects p
+		LEFT JOIN (SELECT fields->>? AS project_id, count(*) AS work_items, sum(pg_column_size(fields)) AS fields_size, max(updated_at) AS last_activity
+			FROM work_items WHERE deleted_at IS NULL GROUP BY 1) AS w ON w.project_id = p.id::text
+		WHERE p.deleted_at IS NULL
+		ORDER BY `+order+`, p.name, p.id
+		OFFSET ? LIMIT ?`, workitem.SystemProject, workitem.SystemProject, workitem.SystemProject, offset, limit).Scan(&rows).Error
+	if err != nil {
+		return nil, 0, errors.NewInternalError(err.Error())
+	}
+	if rows == nil {
+		rows = []ProjectStats{}
+	}
+	return rows, total, nil
+}
@@ -0,0 +1,112 @@
+package instancestats_test
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/instancestats"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestWeekStart(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	monday := time.Date(2017, 1, 2, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, monday, instancestats.WeekStart(monday))
+	assert.Equal(t, monday, instancestats.WeekStart(time.Date(2017, 1, 4, 13, 30, 0, 0, time.UTC)))
+	assert.Equal(t, monday, instancestats.WeekStart(time.Date(2017, 1, 8, 23, 59, 0, 0, time.UTC)))
+	assert.Equal(t, monday.AddDate(0, 0, 7), instancestats.WeekStart(time.Date(2017, 1, 9, 0, 0, 0, 0, time.UTC)))
+	// Monday 01:00 in Berlin is still Sunday in UTC
+	berlin := time.FixedZone("CET", 3600)
+	assert.Equal(t, monday, instancestats.WeekStart(time.Date(2017, 1, 9, 0, 30, 0, 0, berlin)))
+}
+
+type TestInstanceStatsRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunInstanceStatsRepository(t *testing.T) {
+	suite.Run(t, &TestInstanceStatsRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestInstanceStatsRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestInstanceStatsRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestInstanceStatsRepository) TestStats() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	p, err := project.NewRepository(test.DB).Create(ctx, "stats-"+uuid.NewV4().String())
+	require.Nil(t, err)
+	creator := uuid.NewV4().String()
+	for i := 0; i < 2; i++ {
+		_, err := workitem.NewWorkItemRepository(test.DB).Create(ctx, workitem.SystemBug, map[string]interface{}{
+			workitem.SystemTitle:   "stats",
+			workitem.SystemState:   workitem.SystemStateNew,
+			workitem.SystemProject: p.ID.String(),
+		}, creator)
+		require.Nil(t, err)
+	}
+	repo := instancestats.NewRepository(test.DB)
+
+	o, err := repo.Overview(ctx, time.Now(), 4)
+	require.Nil(t, err)
+	assert.True(t, o.Projects >= 1)
+	assert.True(t, o.WorkItems >= 2)
+	assert.True(t, o.Storage > 0)
+	assert.True(t, o.DatabaseSize >= o.Storage)
+	assert.True(t, o.ActiveMonth >= o.ActiveWeek && o.ActiveWeek >= o.ActiveDay)
+	require.Len(t, o.Growth, 4)
+	current := o.Growth[3]
+	assert.Equal(t, instancestats.WeekStart(time.Now()), current.Start)
+	assert.Equal(t, current.Start.AddDate(0, 0, -21), o.Growth[0].Start)
+	assert.True(t, current.Projects >= 1)
+	assert.True(t, current.WorkItems >= 2)
+
+	stats, total, err := repo.Projects(ctx, instancestats.SortWorkItems, 0, total(test))
+	require.Nil(t, err)
+	require.Len(t, stats, total)
+	var found bool
+	for i, s := range stats {
+		if i > 0 {
+			assert.True(t, stats[i-1].WorkItems >= s.WorkItems)
+		}
+		if uuid.Equal(s.ProjectID, p.ID) {
+			found = true
+			assert.Equal(t, 2, s.WorkItems)
+			assert.True(t, s.Storage > 0)
+			assert.NotNil(t, s.LastActivity)
+		}
+	}
+	assert.True(t, found)
+
+	_, err = repo.Overview(ctx, time.Now(), instancestats.MaxWeeks+1)
+	assert.IsType(t, errors.BadParameterError{}, err)
+	_, _, err = repo.Projects(ctx, "name", 0, 10)
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
+
+// total returns the number of projects
+func total(test *TestInstanceStatsRepository) int {
+	var n int
+	require.Nil(test.T(), test.DB.Table("projects").Where("deleted_at IS NULL").Count(&n).Error)
+	return n
+}
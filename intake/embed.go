@@ -0,0 +1,170 @@
+package intake
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Origins are the websites allowed to embed the feedback form of a project,
+// like https://example.com
+type Origins []string
+
+// Value implements the driver.Valuer interface
+func (o Origins) Value() (driver.Value, error) {
+	if o == nil {
+		o = Origins{}
+	}
+	return json.Marshal(o)
+}
+
+// Scan implements the sql.Scanner interface
+func (o *Origins) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("scan source was not []byte")
+	}
+	return json.Unmarshal(b, o)
+}
+
+// Allows returns true if the origin is one of the origins
+func (o Origins) Allows(origin string) bool {
+	for _, allowed := range o {
+		if strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateOrigin checks that the origin is a scheme and a host without a path
+// returns BadParameterError
+func ValidateOrigin(origin string) error {
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+		return errors.NewBadParameterError("origin", origin).Expected("an origin like https://example.com")
+	}
+	return nil
+}
+
+// Token lets the forms embedded on a website submit to the portal of a
+// project, only the hash of its secret is stored
+type Token struct {
+	CreatedAt time.Time
+	ID        uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	ProjectID uuid.UUID `sql:"type:uuid"`
+	Name      string
+	// Origin restricts the token to the forms on the website, empty for
+	// all allowed origins
+	Origin     string
+	Hash       string
+	LastUsedAt *time.Time
+}
+
+// TableName implements gorm.tabler
+func (t Token) TableName() string {
+	return "intake_tokens"
+}
+
+// hash returns the hash the secret of a token is stored as
+func hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateToken creates a token for the portal of the project and returns it
+// with its secret, the secret can't be retrieved later
+// returns BadParameterError or InternalError
+func (m *GormRepository) CreateToken(ctx context.Context, projectID uuid.UUID, name string, origin string) (*Token, string, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "intaketoken", "create"}, time.Now())
+
+	if name == "" {
+		return nil, "", errors.NewBadParameterError("name", name).Expected("not empty")
+	}
+	if origin != "" {
+		if err := ValidateOrigin(origin); err != nil {
+			return nil, "", err
+		}
+		origin = strings.TrimSuffix(origin, "/")
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", errors.NewInternalError(err.Error())
+	}
+	secret := base64.RawURLEncoding.EncodeToString(b)
+	t := Token{ProjectID: projectID, Name: name, Origin: origin, Hash: hash(secret)}
+	if err := m.db.Create(&t).Error; err != nil {
+		return nil, "", errors.NewInternalError(err.Error())
+	}
+	return &t, secret, nil
+}
+
+// ListTokens returns the tokens of the portal of the project, the latest
+// first
+// returns InternalError
+func (m *GormRepository) ListTokens(ctx context.Context, projectID uuid.UUID) ([]*Token, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "intaketoken", "list"}, time.Now())
+
+	var objs []*Token
+	if err := m.db.Where("project_id = ?", projectID).Order("created_at DESC, id").Find(&objs).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return objs, nil
+}
+
+// RevokeToken deletes the token of the portal of the project
+// returns NotFoundError or InternalError
+func (m *GormRepository) RevokeToken(ctx context.Context, projectID uuid.UUID, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "intaketoken", "revoke"}, time.Now())
+
+	tx := m.db.Where("id = ? AND project_id = ?", id, projectID).Delete(Token{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("intake token", id.String())
+	}
+	return nil
+}
+
+// VerifyToken checks that the secret is the one of a token of the portal of
+// the project that is valid for the origin, empty if the request has none,
+// and records its use
+// returns NotFoundError or InternalError
+func (m *GormRepository) VerifyToken(ctx context.Context, projectID uuid.UUID, secret string, origin string) (*Token, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "intaketoken", "verify"}, time.Now())
+
+	// the token is a secret, don't echo it
+	notFound := errors.NewNotFoundError("intake token", "")
+	if secret == "" {
+		return nil, notFound
+	}
+	var obj Token
+	tx := m.db.Where("project_id = ? AND hash = ?", projectID, hash(secret)).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, notFound
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	if obj.Origin != "" && !strings.EqualFold(obj.Origin, origin) {
+		return nil, notFound
+	}
+	if err := m.db.Model(&obj).UpdateColumn("last_used_at", time.Now()).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return &obj, nil
+}
@@ -0,0 +1,66 @@
+package intake_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/intake"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrigins(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	assert.Nil(t, intake.ValidateOrigin("https://example.com"))
+	assert.Nil(t, intake.ValidateOrigin("http://localhost:8080/"))
+	assert.IsType(t, errors.BadParameterError{}, intake.ValidateOrigin("example.com"))
+	assert.IsType(t, errors.BadParameterError{}, intake.ValidateOrigin("https://example.com/feedback"))
+
+	origins := intake.Origins{"https://example.com"}
+	assert.True(t, origins.Allows("https://EXAMPLE.com"))
+	assert.False(t, origins.Allows("https://evil.example.com"))
+	assert.False(t, intake.Origins{}.Allows("https://example.com"))
+}
+
+func (test *TestIntakeRepository) TestTokens() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	prj, err := project.NewRepository(test.DB).Create(ctx, "intake-"+uuid.NewV4().String())
+	require.Nil(t, err)
+	repo := intake.NewRepository(test.DB)
+
+	_, _, err = repo.CreateToken(ctx, prj.ID, "", "")
+	assert.IsType(t, errors.BadParameterError{}, err)
+	any, anySecret, err := repo.CreateToken(ctx, prj.ID, "any website", "")
+	require.Nil(t, err)
+	website, websiteSecret, err := repo.CreateToken(ctx, prj.ID, "website", "https://example.com/")
+	require.Nil(t, err)
+	assert.Equal(t, "https://example.com", website.Origin)
+
+	verified, err := repo.VerifyToken(ctx, prj.ID, anySecret, "https://other.example.com")
+	require.Nil(t, err)
+	assert.Equal(t, any.ID, verified.ID)
+	_, err = repo.VerifyToken(ctx, prj.ID, websiteSecret, "https://example.com")
+	require.Nil(t, err)
+	_, err = repo.VerifyToken(ctx, prj.ID, websiteSecret, "https://other.example.com")
+	assert.IsType(t, errors.NotFoundError{}, err)
+	_, err = repo.VerifyToken(ctx, uuid.NewV4(), anySecret, "")
+	assert.IsType(t, errors.NotFoundError{}, err)
+
+	tokens, err := repo.ListTokens(ctx, prj.ID)
+	require.Nil(t, err)
+	assert.Len(t, tokens, 2)
+
+	require.Nil(t, repo.RevokeToken(ctx, prj.ID, any.ID))
+	assert.IsType(t, errors.NotFoundError{}, repo.RevokeToken(ctx, prj.ID, any.ID))
+	_, err = repo.VerifyToken(ctx, prj.ID, anySecret, "")
+	assert.IsType(t, errors.NotFoundError{}, err)
+}
@@ -0,0 +1,243 @@
+// Package intake lets end-user feedback widgets post into a project without
+// logging in. Submissions are limited to a title, a description and the page
+// they were sent from; they create work items of the type chosen by the
+// project in the new state with the triage label. The portal of a project is
+// off until its admins turn it on, submissions are rate limited by client.
+// The form can be embedded on the websites of the allowed origins of the
+// portal, optionally only with the tokens of the portal.
+package intake
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Defaults and limits of the portals
+const (
+	// DefaultLabel marks the work items created by the portal
+	DefaultLabel = "triage"
+	// DefaultRateLimit is the number of submissions per hour a client can
+	// send to a project
+	DefaultRateLimit     = 5
+	MaxTitleLength       = 256
+	MaxDescriptionLength = 10000
+)
+
+// Portal are the settings of the feedback portal of a project
+type Portal struct {
+	gormsupport.Lifecycle
+	ProjectID uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	Enabled   bool
+	// WorkItemType is the name of the type of the created work items
+	WorkItemType string
+	// Label is added to the created work items
+	Label string
+	// RateLimit is the number of submissions per hour a client can send
+	RateLimit int
+	// Origins are the websites the form can be embedded on
+	Origins Origins `sql:"type:jsonb"`
+	// RequireToken rejects submissions without a token of the portal
+	RequireToken bool
+}
+
+// TableName implements gorm.tabler
+func (p Portal) TableName() string {
+	return "intake_portals"
+}
+
+// Validate checks that the portal creates work items of a type and allows
+// submissions
+// returns BadParameterError
+func (p Portal) Validate() error {
+	if p.WorkItemType == "" {
+		return errors.NewBadParameterError("work-item-type", p.WorkItemType).Expected("not empty")
+	}
+	if p.Label == "" {
+		return errors.NewBadParameterError("label", p.Label).Expected("not empty")
+	}
+	if p.RateLimit <= 0 {
+		return errors.NewBadParameterError("rate-limit", p.RateLimit).Expected("greater than 0")
+	}
+	for _, o := range p.Origins {
+		if err := ValidateOrigin(o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Submission is the feedback sent by an end user
+type Submission struct {
+	Title       string
+	Description string
+	// PageURL is the page the feedback was sent from
+	PageURL string
+	// Website is a field hidden from end users, bots filling it in are
+	// rejected
+	Website string
+}
+
+// Validate checks the lengths of the texts, the page and that the hidden
+// field is empty
+// returns BadParameterError
+func (s Submission) Validate() error {
+	if s.Website != "" {
+		return errors.NewBadParameterError("website", s.Website).Expected("empty")
+	}
+	if s.Title == "" || len(s.Title) > MaxTitleLength {
+		return errors.NewBadParameterError("title", len(s.Title)).Expected(fmt.Sprintf("between 1 and %d characters", MaxTitleLength))
+	}
+	if len(s.Description) > MaxDescriptionLength {
+		return errors.NewBadParameterError("description", len(s.Description)).Expected(fmt.Sprintf("at most %d characters", MaxDescriptionLength))
+	}
+	if s.PageURL != "" {
+		u, err := url.Parse(s.PageURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.NewBadParameterError("page-url", s.PageURL).Expected("an absolute http(s) URL")
+		}
+	}
+	return nil
+}
+
+// Fields returns the fields of the work item created for the submission to
+// the portal
+func (s Submission) Fields(p Portal) map[string]interface{} {
+	description := s.Description
+	if s.PageURL != "" {
+		description += "\n\nSent from " + s.PageURL
+	}
+	return map[string]interface{}{
+		workitem.SystemTitle:       s.Title,
+		workitem.SystemDescription: description,
+		workitem.SystemState:       workitem.SystemStateNew,
+		workitem.SystemLabels:      []interface{}{p.Label},
+		workitem.SystemProject:     p.ProjectID.String(),
+	}
+}
+
+// submission records when a client sent a submission to a project, the
+// client is hashed so no addresses are kept
+type submission struct {
+	CreatedAt time.Time
+	ProjectID uuid.UUID `sql:"type:uuid"`
+	Client    string
+}
+
+// TableName implements gorm.tabler
+func (s submission) TableName() string {
+	return "intake_submissions"
+}
+
+// Repository encapsulates storage & retrieval of the feedback portals
+type Repository interface {
+	Load(ctx context.Context, projectID uuid.UUID) (*Portal, error)
+	Save(ctx context.Context, p Portal) (*Portal, error)
+	Delete(ctx context.Context, projectID uuid.UUID) error
+	Admit(ctx context.Context, p Portal, client string) error
+	CreateToken(ctx context.Context, projectID uuid.UUID, name string, origin string) (*Token, string, error)
+	ListTokens(ctx context.Context, projectID uuid.UUID) ([]*Token, error)
+	RevokeToken(ctx context.Context, projectID uuid.UUID, id uuid.UUID) error
+	VerifyToken(ctx context.Context, projectID uuid.UUID, secret string, origin string) (*Token, error)
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for feedback
+// portals.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Load returns the portal of the project
+// returns NotFoundError or InternalError
+func (m *GormRepository) Load(ctx context.Context, projectID uuid.UUID) (*Portal, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "intakeportal", "get"}, time.Now())
+
+	var obj Portal
+	tx := m.db.Where("project_id = ?", projectID).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("intake portal", projectID.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
+
+// Save creates or replaces the portal of the project
+// returns BadParameterError or InternalError
+func (m *GormRepository) Save(ctx context.Context, p Portal) (*Portal, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "intakeportal", "save"}, time.Now())
+
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	origins := make(Origins, len(p.Origins))
+	for i, o := range p.Origins {
+		origins[i] = strings.TrimSuffix(o, "/")
+	}
+	tx := m.db.Exec(`INSERT INTO intake_portals (project_id, enabled, work_item_type, label, rate_limit, origins, require_token, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, now(), now())
+		ON CONFLICT (project_id) DO UPDATE SET enabled = excluded.enabled, work_item_type = excluded.work_item_type,
+			label = excluded.label, rate_limit = excluded.rate_limit, origins = excluded.origins,
+			require_token = excluded.require_token, updated_at = now(), deleted_at = NULL`,
+		p.ProjectID, p.Enabled, p.WorkItemType, p.Label, p.RateLimit, origins, p.RequireToken)
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return m.Load(ctx, p.ProjectID)
+}
+
+// Delete removes the portal of the project
+// returns NotFoundError or InternalError
+func (m *GormRepository) Delete(ctx context.Context, projectID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "intakeportal", "delete"}, time.Now())
+
+	tx := m.db.Where("project_id = ?", projectID).Delete(&Portal{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("intake portal", projectID.String())
+	}
+	return nil
+}
+
+// Admit records a submission of the client, like its IP address, to the
+// portal unless the client sent as many as the rate limit in the last hour
+// returns BadParameterError or InternalError
+func (m *GormRepository) Admit(ctx context.Context, p Portal, client string) error {
+	defer goa.MeasureSince([]string{"goa", "db", "intakeportal", "admit"}, time.Now())
+
+	since := time.Now().Add(-time.Hour)
+	if err := m.db.Where("created_at < ?", since).Delete(submission{}).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	hashed := hash(client)
+	var count int
+	err := m.db.Model(&submission{}).Where("project_id = ? AND client = ? AND created_at >= ?", p.ProjectID, hashed, since).Count(&count).Error
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if count >= p.RateLimit {
+		return errors.NewBadParameterError("submissions", count).Expected(fmt.Sprintf("at most %d per hour", p.RateLimit))
+	}
+	if err := m.db.Create(&submission{ProjectID: p.ProjectID, Client: hashed}).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package intake_test
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/intake"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSubmission(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	assert.Nil(t, intake.Submission{Title: "Broken export", PageURL: "https://example.com/reports"}.Validate())
+	assert.IsType(t, errors.BadParameterError{}, intake.Submission{}.Validate())
+	assert.IsType(t, errors.BadParameterError{}, intake.Submission{Title: strings.Repeat("x", intake.MaxTitleLength+1)}.Validate())
+	assert.IsType(t, errors.BadParameterError{}, intake.Submission{Title: "spam", Website: "http://spam.example.com"}.Validate())
+	assert.IsType(t, errors.BadParameterError{}, intake.Submission{Title: "page", PageURL: "reports"}.Validate())
+
+	p := intake.Portal{ProjectID: uuid.NewV4(), WorkItemType: workitem.SystemBug, Label: intake.DefaultLabel}
+	fields := intake.Submission{Title: "Broken export", Description: "Nothing happens", PageURL: "https://example.com/reports"}.Fields(p)
+	assert.Equal(t, workitem.SystemStateNew, fields[workitem.SystemState])
+	assert.Equal(t, []interface{}{intake.DefaultLabel}, fields[workitem.SystemLabels])
+	assert.Equal(t, "Nothing happens\n\nSent from https://example.com/reports", fields[workitem.SystemDescription])
+	assert.Equal(t, p.ProjectID.String(), fields[workitem.SystemProject])
+}
+
+type TestIntakeRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunIntakeRepository(t *testing.T) {
+	suite.Run(t, &TestIntakeRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestIntakeRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestIntakeRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestIntakeRepository) TestPortal() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	prj, err := project.NewRepository(test.DB).Create(ctx, "intake-"+uuid.NewV4().String())
+	require.Nil(t, err)
+	repo := intake.NewRepository(test.DB)
+
+	_, err = repo.Load(ctx, prj.ID)
+	assert.IsType(t, errors.NotFoundError{}, err)
+	_, err = repo.Save(ctx, intake.Portal{ProjectID: prj.ID, WorkItemType: workitem.SystemBug, Label: intake.DefaultLabel})
+	assert.IsType(t, errors.BadParameterError{}, err)
+
+	_, err = repo.Save(ctx, intake.Portal{ProjectID: prj.ID, WorkItemType: workitem.SystemBug, Label: intake.DefaultLabel, RateLimit: 2, Origins: intake.Origins{"example.com"}})
+	assert.IsType(t, errors.BadParameterError{}, err)
+
+	p, err := repo.Save(ctx, intake.Portal{ProjectID: prj.ID, Enabled: true, WorkItemType: workitem.SystemBug, Label: intake.DefaultLabel, RateLimit: 2, Origins: intake.Origins{"https://example.com/"}})
+	require.Nil(t, err)
+	assert.True(t, p.Enabled)
+	assert.Equal(t, 2, p.RateLimit)
+	assert.Equal(t, intake.Origins{"https://example.com"}, p.Origins)
+
+	require.Nil(t, repo.Admit(ctx, *p, "10.0.0.1"))
+	require.Nil(t, repo.Admit(ctx, *p, "10.0.0.1"))
+	assert.IsType(t, errors.BadParameterError{}, repo.Admit(ctx, *p, "10.0.0.1"))
+	require.Nil(t, repo.Admit(ctx, *p, "10.0.0.2"))
+
+	require.Nil(t, repo.Delete(ctx, prj.ID))
+	assert.IsType(t, errors.NotFoundError{}, repo.Delete(ctx, prj.ID))
+}
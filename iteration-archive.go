@@ -0,0 +1,61 @@
+package main
+
+import (
+	"log"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// IterationArchiveController implements the iteration-archive resource.
+type IterationArchiveController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewIterationArchiveController creates a iteration-archive controller.
+func NewIterationArchiveController(service *goa.Service, db application.DB) *IterationArchiveController {
+	return &IterationArchiveController{Controller: service.NewController("IterationArchiveController"), db: db}
+}
+
+// Archive runs the archive action.
+func (c *IterationArchiveController) Archive(ctx *app.ArchiveIterationArchiveContext) error {
+	workItems := ctx.Workitems != nil && *ctx.Workitems
+	return c.archive(ctx, ctx.ID, true, workItems, ctx.RequestData, ctx.OK)
+}
+
+// Unarchive runs the unarchive action.
+func (c *IterationArchiveController) Unarchive(ctx *app.UnarchiveIterationArchiveContext) error {
+	return c.archive(ctx, ctx.ID, false, false, ctx.RequestData, ctx.OK)
+}
+
+// archive sets whether the iteration is archived, archiving its closed work
+// items as well if workItems is set, and responds with the iteration
+func (c *IterationArchiveController) archive(ctx archiveContext, id string, archived bool, workItems bool, request *goa.RequestData, ok func(*app.IterationSingle) error) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	iterationID, err := uuid.FromString(id)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		i, err := appl.Iterations().Archive(ctx, iterationID, archived)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if workItems {
+			n, err := appl.WorkItemArchive().ArchiveClosed(ctx, i.ID.String())
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			log.Printf("Archived %d work items of iteration %s\n", n, i.ID)
+		}
+		return ok(&app.IterationSingle{Data: ConvertIteration(request, i)})
+	})
+}
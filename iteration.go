@@ -35,7 +35,7 @@ func (c *IterationController) CreateChild(ctx *app.CreateChildIterationContext)
 		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
 	}
 
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 
 		parent, err := appl.Iterations().Load(ctx, parentID)
 		if err != nil {
@@ -75,7 +75,7 @@ func (c *IterationController) Show(ctx *app.ShowIterationContext) error {
 		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
 	}
 
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 		c, err := appl.Iterations().Load(ctx, id)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, err)
@@ -117,9 +117,10 @@ func ConvertIteration(request *goa.RequestData, iteration *iteration.Iteration,
 		Type: iterationType,
 		ID:   &iteration.ID,
 		Attributes: &app.IterationAttributes{
-			Name:    &iteration.Name,
-			StartAt: iteration.StartAt,
-			EndAt:   iteration.EndAt,
+			Name:       &iteration.Name,
+			StartAt:    iteration.StartAt,
+			EndAt:      iteration.EndAt,
+			ArchivedAt: iteration.ArchivedAt,
 		},
 		Relationships: &app.IterationRelations{
 			Project: &app.RelationGeneric{
@@ -5,6 +5,7 @@ import (
 
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/workitem"
 	"github.com/goadesign/goa"
 	"github.com/jinzhu/gorm"
 	uuid "github.com/satori/go.uuid"
@@ -20,6 +21,8 @@ type Iteration struct {
 	StartAt   *time.Time
 	EndAt     *time.Time
 	Name      string
+	// ArchivedAt is set for archived iterations, they are not listed with the active ones
+	ArchivedAt *time.Time
 }
 
 // TableName overrides the table name settings in Gorm to force a specific table name
@@ -32,7 +35,9 @@ func (m *Iteration) TableName() string {
 type Repository interface {
 	Create(ctx context.Context, u *Iteration) error
 	List(ctx context.Context, projectID uuid.UUID) ([]*Iteration, error)
+	ListArchived(ctx context.Context, projectID uuid.UUID) ([]*Iteration, error)
 	Load(ctx context.Context, id uuid.UUID) (*Iteration, error)
+	Archive(ctx context.Context, id uuid.UUID, archived bool) (*Iteration, error)
 }
 
 // NewIterationRepository creates a new storage type.
@@ -65,7 +70,20 @@ func (m *GormIterationRepository) List(ctx context.Context, projectID uuid.UUID)
 	defer goa.MeasureSince([]string{"goa", "db", "iteration", "query"}, time.Now())
 	var objs []*Iteration
 
-	err := m.db.Where("project_id = ?", projectID).Find(&objs).Error
+	err := m.db.Where("project_id = ? AND archived_at IS NULL", projectID).Find(&objs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	return objs, nil
+}
+
+// ListArchived returns the archived iterations of the project, the latest
+// archived first
+func (m *GormIterationRepository) ListArchived(ctx context.Context, projectID uuid.UUID) ([]*Iteration, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "iteration", "listarchived"}, time.Now())
+	var objs []*Iteration
+
+	err := m.db.Where("project_id = ? AND archived_at IS NOT NULL", projectID).Order("archived_at DESC").Find(&objs).Error
 	if err != nil && err != gorm.ErrRecordNotFound {
 		return nil, err
 	}
@@ -84,5 +102,33 @@ func (m *GormIterationRepository) Load(ctx context.Context, id uuid.UUID) (*Iter
 	if tx.Error != nil {
 		return nil, errors.NewInternalError(tx.Error.Error())
 	}
+	// the iterations of hidden projects don't exist for the viewer
+	if !workitem.ContextViewer(ctx).CanReadProject(obj.ProjectID) {
+		return nil, errors.NewNotFoundError("Iteration", id.String())
+	}
 	return &obj, nil
 }
+
+// Archive archives or unarchives the iteration with the given ID
+// returns NotFoundError or InternalError
+func (m *GormIterationRepository) Archive(ctx context.Context, id uuid.UUID, archived bool) (*Iteration, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "iteration", "archive"}, time.Now())
+
+	obj, err := m.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if archived == (obj.ArchivedAt != nil) {
+		return obj, nil
+	}
+	var archivedAt *time.Time
+	if archived {
+		now := time.Now()
+		archivedAt = &now
+	}
+	if err := m.db.Model(obj).UpdateColumn("archived_at", archivedAt).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	obj.ArchivedAt = archivedAt
+	return obj, nil
+}
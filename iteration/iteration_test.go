@@ -128,3 +128,37 @@ func (test *TestIterationRepository) TestListIterationByProject() {
 	assert.Nil(t, err)
 	assert.Len(t, its, 3)
 }
+
+func (test *TestIterationRepository) TestArchiveIteration() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := iteration.NewIterationRepository(test.DB)
+	ctx := context.Background()
+	projectID := uuid.NewV4()
+	active := iteration.Iteration{Name: "Sprint #1", ProjectID: projectID}
+	repo.Create(ctx, &active)
+	done := iteration.Iteration{Name: "Sprint #0", ProjectID: projectID}
+	repo.Create(ctx, &done)
+
+	archived, err := repo.Archive(ctx, done.ID, true)
+	assert.Nil(t, err)
+	assert.NotNil(t, archived.ArchivedAt)
+	its, err := repo.List(ctx, projectID)
+	assert.Nil(t, err)
+	if assert.Len(t, its, 1) {
+		assert.Equal(t, active.ID, its[0].ID)
+	}
+	its, err = repo.ListArchived(ctx, projectID)
+	assert.Nil(t, err)
+	if assert.Len(t, its, 1) {
+		assert.Equal(t, done.ID, its[0].ID)
+	}
+
+	archived, err = repo.Archive(ctx, done.ID, false)
+	assert.Nil(t, err)
+	assert.Nil(t, archived.ArchivedAt)
+	its, err = repo.List(ctx, projectID)
+	assert.Nil(t, err)
+	assert.Len(t, its, 2)
+}
@@ -0,0 +1,334 @@
+// Package job implements a persistent queue of background work. Jobs are
+// stored in Postgres, claimed by the workers of a Pool and retried with a
+// backoff until they succeed or run out of attempts.
+package job
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Status of a job
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	// StatusDead jobs failed all their attempts, they can be retried manually
+	StatusDead = "dead"
+)
+
+// DefaultMaxAttempts is the number of times a job is run before it is dead
+const DefaultMaxAttempts = 5
+
+// maxRetryDelay caps the backoff between the attempts of a job
+const maxRetryDelay = time.Hour
+
+// Job is a unit of background work of a given kind
+type Job struct {
+	gormsupport.Lifecycle
+	ID          uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	Kind        string
+	Payload     string `sql:"type:jsonb"`
+	Status      string
+	Attempts    int
+	MaxAttempts int
+	RunAt       time.Time
+	LockedAt    *time.Time
+	// LockedBy identifies the claim that holds the lock of a running job
+	LockedBy  *uuid.UUID `sql:"type:uuid"`
+	LastError string
+	// Schedule is the name of the schedule that enqueued the job, if any
+	Schedule *string
+}
+
+// TableName implements gorm.tabler
+func (j Job) TableName() string {
+	return "jobs"
+}
+
+// Decode unmarshals the payload of the job
+// returns ConversionError
+func (j Job) Decode(v interface{}) error {
+	if err := json.Unmarshal([]byte(j.Payload), v); err != nil {
+		return errors.NewConversionError(err.Error())
+	}
+	return nil
+}
+
+// RetryDelay returns how long to wait before the next attempt after the
+// given number of failed attempts
+func RetryDelay(attempts int) time.Duration {
+	delay := time.Duration(1<<uint(attempts)) * time.Second
+	if attempts > 12 || delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
+
+// Repository encapsulates storage & retrieval of jobs
+type Repository interface {
+	Enqueue(ctx context.Context, kind string, payload interface{}) (*Job, error)
+	Load(ctx context.Context, id uuid.UUID) (*Job, error)
+	List(ctx context.Context, status string, start *int, limit *int) ([]*Job, error)
+	Claim(ctx context.Context, lockTimeout time.Duration) (*Job, error)
+	Complete(ctx context.Context, j *Job) error
+	Fail(ctx context.Context, j *Job, cause error) error
+	Retry(ctx context.Context, id uuid.UUID) (*Job, error)
+	EnqueueScheduled(ctx context.Context, s *Schedule, runAt time.Time) (*Job, error)
+	LastRun(ctx context.Context, schedule string) (*Job, error)
+}
+
+// NewJobRepository creates a new storage type.
+func NewJobRepository(db *gorm.DB) Repository {
+	return &GormJobRepository{db: db}
+}
+
+// GormJobRepository is the implementation of the storage interface for jobs.
+type GormJobRepository struct {
+	db *gorm.DB
+}
+
+// Enqueue stores a new pending job with the JSON encoded payload
+// returns BadParameterError or InternalError
+func (m *GormJobRepository) Enqueue(ctx context.Context, kind string, payload interface{}) (*Job, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "job", "enqueue"}, time.Now())
+
+	j, err := newJob(kind, payload, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := m.db.Create(j).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	log.Printf("enqueued %s job %s\n", j.Kind, j.ID)
+	return j, nil
+}
+
+// EnqueueScheduled stores the job of the given run of a schedule. It returns
+// nil if the run was already enqueued, e.g. by another server.
+// returns BadParameterError or InternalError
+func (m *GormJobRepository) EnqueueScheduled(ctx context.Context, s *Schedule, runAt time.Time) (*Job, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "job", "enqueuescheduled"}, time.Now())
+
+	j, err := newJob(s.Kind, s.Payload, runAt)
+	if err != nil {
+		return nil, err
+	}
+	j.Schedule = &s.Name
+	if err := m.db.Create(j).Error; err != nil {
+		if gormsupport.IsUniqueViolation(err, "jobs_schedule_run_at_idx") {
+			return nil, nil
+		}
+		return nil, errors.NewInternalError(err.Error())
+	}
+	log.Printf("enqueued %s job %s of schedule %s\n", j.Kind, j.ID, s.Name)
+	return j, nil
+}
+
+// LastRun returns the most recent job of the given schedule, or nil if the
+// schedule never ran
+// returns InternalError
+func (m *GormJobRepository) LastRun(ctx context.Context, schedule string) (*Job, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "job", "lastrun"}, time.Now())
+
+	var obj Job
+	tx := m.db.Where("schedule = ?", schedule).Order("run_at desc").First(&obj)
+	if tx.RecordNotFound() {
+		return nil, nil
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
+
+// newJob returns a pending job with the JSON encoded payload
+// returns BadParameterError
+func newJob(kind string, payload interface{}, runAt time.Time) (*Job, error) {
+	if kind == "" {
+		return nil, errors.NewBadParameterError("kind", kind).Expected("not empty")
+	}
+	p, err := json.Marshal(payload)
+	if err != nil {
+		return nil, errors.NewBadParameterError("payload", err.Error())
+	}
+	return &Job{
+		Kind:        kind,
+		Payload:     string(p),
+		Status:      StatusPending,
+		MaxAttempts: DefaultMaxAttempts,
+		RunAt:       runAt,
+	}, nil
+}
+
+// Load returns the job for the given id
+// returns NotFoundError or InternalError
+func (m *GormJobRepository) Load(ctx context.Context, id uuid.UUID) (*Job, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "job", "get"}, time.Now())
+
+	var obj Job
+	tx := m.db.Where("id = ?", id).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("job", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
+
+// List returns the jobs with the given status, or all jobs if the status is
+// empty, most recent first
+// returns BadParameterError or InternalError
+func (m *GormJobRepository) List(ctx context.Context, status string, start *int, limit *int) ([]*Job, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "job", "list"}, time.Now())
+
+	db := m.db.Order("created_at desc")
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	if start != nil {
+		if *start < 0 {
+			return nil, errors.NewBadParameterError("start", *start)
+		}
+		db = db.Offset(*start)
+	}
+	if limit != nil {
+		if *limit <= 0 {
+			return nil, errors.NewBadParameterError("limit", *limit)
+		}
+		db = db.Limit(*limit)
+	}
+	var objs []*Job
+	if err := db.Find(&objs).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return objs, nil
+}
+
+// Claim marks the next due job as running and returns it, or nil if no job
+// is due. Running jobs whose lock is older than the timeout are claimed
+// again, their worker is assumed to have crashed, unless they ran out of
+// attempts in which case they are dead. Concurrent claims never return the
+// same job.
+// returns InternalError
+func (m *GormJobRepository) Claim(ctx context.Context, lockTimeout time.Duration) (*Job, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "job", "claim"}, time.Now())
+
+	expired := time.Now().Add(-lockTimeout)
+	tx := m.db.Model(&Job{}).Where("status = ? AND locked_at < ? AND attempts >= max_attempts", StatusRunning, expired).Updates(map[string]interface{}{
+		"status":     StatusDead,
+		"locked_at":  nil,
+		"locked_by":  nil,
+		"last_error": "lock timed out on the last attempt",
+	})
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected > 0 {
+		log.Printf("%d jobs are dead after their last attempt timed out\n", tx.RowsAffected)
+	}
+
+	var id uuid.UUID
+	err := m.db.Raw(`UPDATE jobs SET status = ?, attempts = attempts + 1, locked_at = now(), locked_by = ?, updated_at = now()
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE deleted_at IS NULL AND run_at <= now() AND (status = ? OR (status = ? AND locked_at < ? AND attempts < max_attempts))
+			ORDER BY run_at LIMIT 1 FOR UPDATE SKIP LOCKED)
+		RETURNING id`, StatusRunning, uuid.NewV4(), StatusPending, StatusRunning, expired).Row().Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return m.Load(ctx, id)
+}
+
+// locked restricts the query to the job as long as it is still held by the
+// claim it was returned by
+func locked(db *gorm.DB, j *Job) *gorm.DB {
+	return db.Model(&Job{}).Where("id = ? AND locked_by = ? AND status = ?", j.ID, j.LockedBy, StatusRunning)
+}
+
+// Complete marks the claimed job as succeeded
+// returns NotFoundError if the job is no longer held by its claim, or
+// InternalError
+func (m *GormJobRepository) Complete(ctx context.Context, j *Job) error {
+	defer goa.MeasureSince([]string{"goa", "db", "job", "complete"}, time.Now())
+
+	tx := locked(m.db, j).Updates(map[string]interface{}{
+		"status":     StatusSucceeded,
+		"locked_at":  nil,
+		"locked_by":  nil,
+		"last_error": "",
+	})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("running job", j.ID.String())
+	}
+	return nil
+}
+
+// Fail records the cause of a failed attempt of the claimed job and
+// schedules the next attempt, a job that ran out of attempts is dead
+// returns NotFoundError if the job is no longer held by its claim, or
+// InternalError
+func (m *GormJobRepository) Fail(ctx context.Context, j *Job, cause error) error {
+	defer goa.MeasureSince([]string{"goa", "db", "job", "fail"}, time.Now())
+
+	updates := map[string]interface{}{
+		"status":     StatusPending,
+		"locked_at":  nil,
+		"locked_by":  nil,
+		"last_error": cause.Error(),
+		"run_at":     time.Now().Add(RetryDelay(j.Attempts)),
+	}
+	if j.Attempts >= j.MaxAttempts {
+		updates["status"] = StatusDead
+		log.Printf("%s job %s is dead after %d attempts: %s\n", j.Kind, j.ID, j.Attempts, cause.Error())
+	}
+	tx := locked(m.db, j).Updates(updates)
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("running job", j.ID.String())
+	}
+	return nil
+}
+
+// Retry schedules a dead job to run again with a fresh set of attempts
+// returns NotFoundError, BadParameterError or InternalError
+func (m *GormJobRepository) Retry(ctx context.Context, id uuid.UUID) (*Job, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "job", "retry"}, time.Now())
+
+	j, err := m.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if j.Status != StatusDead {
+		return nil, errors.NewBadParameterError("status", j.Status).Expected(StatusDead)
+	}
+	tx := m.db.Model(j).Where("status = ?", StatusDead).Updates(map[string]interface{}{
+		"status":   StatusPending,
+		"attempts": 0,
+		"run_at":   time.Now(),
+	})
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return m.Load(ctx, id)
+}
@@ -0,0 +1,172 @@
+package job_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/job"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestRetryDelay(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	assert.Equal(t, 2*time.Second, job.RetryDelay(1))
+	assert.Equal(t, 32*time.Second, job.RetryDelay(5))
+	assert.Equal(t, time.Hour, job.RetryDelay(12))
+	assert.Equal(t, time.Hour, job.RetryDelay(100))
+}
+
+type TestJobRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunJobRepository(t *testing.T) {
+	suite.Run(t, &TestJobRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestJobRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestJobRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestJobRepository) TestClaimFailAndRetry() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := job.NewJobRepository(test.DB)
+	j, err := repo.Enqueue(ctx, "test.fail", map[string]string{"name": "a"})
+	require.Nil(t, err)
+	assert.Equal(t, job.StatusPending, j.Status)
+	var payload map[string]string
+	require.Nil(t, j.Decode(&payload))
+	assert.Equal(t, "a", payload["name"])
+
+	claimed, err := repo.Claim(ctx, time.Minute)
+	require.Nil(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, j.ID, claimed.ID)
+	assert.Equal(t, job.StatusRunning, claimed.Status)
+	assert.Equal(t, 1, claimed.Attempts)
+
+	// running jobs are not claimed again until their lock times out
+	other, err := repo.Claim(ctx, time.Minute)
+	require.Nil(t, err)
+	assert.Nil(t, other)
+
+	require.Nil(t, repo.Fail(ctx, claimed, fmt.Errorf("boom")))
+	failed, err := repo.Load(ctx, j.ID)
+	require.Nil(t, err)
+	assert.Equal(t, job.StatusPending, failed.Status)
+	assert.Equal(t, "boom", failed.LastError)
+	assert.True(t, failed.RunAt.After(time.Now()))
+	_, err = repo.Retry(ctx, j.ID)
+	assert.IsType(t, errors.BadParameterError{}, err)
+
+	// a stale claim can no longer fail the job
+	assert.IsType(t, errors.NotFoundError{}, repo.Fail(ctx, claimed, fmt.Errorf("boom")))
+
+	require.Nil(t, test.DB.Model(failed).Updates(map[string]interface{}{"run_at": time.Now(), "max_attempts": 2}).Error)
+	claimed, err = repo.Claim(ctx, time.Minute)
+	require.Nil(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, 2, claimed.Attempts)
+	require.Nil(t, repo.Fail(ctx, claimed, fmt.Errorf("boom")))
+	dead, err := repo.List(ctx, job.StatusDead, nil, nil)
+	require.Nil(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, j.ID, dead[0].ID)
+
+	retried, err := repo.Retry(ctx, j.ID)
+	require.Nil(t, err)
+	assert.Equal(t, job.StatusPending, retried.Status)
+	assert.Equal(t, 0, retried.Attempts)
+}
+
+func (test *TestJobRepository) TestClaimExpiredLock() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := job.NewJobRepository(test.DB)
+	j, err := repo.Enqueue(ctx, "test.expire", nil)
+	require.Nil(t, err)
+	require.Nil(t, test.DB.Model(j).Update("max_attempts", 2).Error)
+
+	crashed, err := repo.Claim(ctx, time.Minute)
+	require.Nil(t, err)
+	require.NotNil(t, crashed)
+
+	// a negative timeout expires the lock taken just now
+	reclaimed, err := repo.Claim(ctx, -time.Minute)
+	require.Nil(t, err)
+	require.NotNil(t, reclaimed)
+	assert.Equal(t, j.ID, reclaimed.ID)
+	assert.Equal(t, 2, reclaimed.Attempts)
+	assert.NotEqual(t, *crashed.LockedBy, *reclaimed.LockedBy)
+	assert.IsType(t, errors.NotFoundError{}, repo.Complete(ctx, crashed))
+
+	// the last attempt timed out as well, the job is dead
+	none, err := repo.Claim(ctx, -time.Minute)
+	require.Nil(t, err)
+	assert.Nil(t, none)
+	dead, err := repo.Load(ctx, j.ID)
+	require.Nil(t, err)
+	assert.Equal(t, job.StatusDead, dead.Status)
+	assert.Nil(t, dead.LockedBy)
+	assert.IsType(t, errors.NotFoundError{}, repo.Complete(ctx, reclaimed))
+}
+
+func (test *TestJobRepository) TestPoolRunsJobs() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	var names []string
+	job.Register("test.record", func(ctx context.Context, payload []byte) error {
+		names = append(names, string(payload))
+		return nil
+	})
+	job.Register("test.panic", func(ctx context.Context, payload []byte) error {
+		panic("boom")
+	})
+	repo := job.NewJobRepository(test.DB)
+	ok, err := repo.Enqueue(ctx, "test.record", "a")
+	require.Nil(t, err)
+	panicking, err := repo.Enqueue(ctx, "test.panic", nil)
+	require.Nil(t, err)
+
+	pool := job.NewPool(test.DB, 1, time.Second, time.Minute)
+	for i := 0; i < 2; i++ {
+		ran, err := pool.RunOnce(ctx)
+		require.Nil(t, err)
+		assert.True(t, ran)
+	}
+	ran, err := pool.RunOnce(ctx)
+	require.Nil(t, err)
+	assert.False(t, ran)
+
+	assert.Equal(t, []string{`"a"`}, names)
+	j, err := repo.Load(ctx, ok.ID)
+	require.Nil(t, err)
+	assert.Equal(t, job.StatusSucceeded, j.Status)
+	j, err = repo.Load(ctx, panicking.ID)
+	require.Nil(t, err)
+	assert.Equal(t, job.StatusPending, j.Status)
+	assert.Contains(t, j.LastError, "boom")
+}
@@ -0,0 +1,113 @@
+package job
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/jinzhu/gorm"
+)
+
+// Handler runs a job with the JSON encoded payload it was enqueued with. A
+// returned error fails the attempt, the job is retried later.
+type Handler func(ctx context.Context, payload []byte) error
+
+var handlers = map[string]Handler{}
+
+// Register sets the handler of the jobs of the given kind. Handlers must be
+// registered before the pool is started.
+func Register(kind string, h Handler) {
+	handlers[kind] = h
+}
+
+// Pool runs pending jobs in a number of workers
+type Pool struct {
+	repo        Repository
+	workers     int
+	poll        time.Duration
+	lockTimeout time.Duration
+	stop        chan struct{}
+	wg          sync.WaitGroup
+}
+
+// NewPool creates a pool of workers running the jobs stored in the database.
+// Idle workers look for due jobs every poll interval.
+func NewPool(db *gorm.DB, workers int, poll time.Duration, lockTimeout time.Duration) *Pool {
+	return &Pool{
+		repo:        NewJobRepository(db),
+		workers:     workers,
+		poll:        poll,
+		lockTimeout: lockTimeout,
+		stop:        make(chan struct{}),
+	}
+}
+
+// Start starts the workers
+func (p *Pool) Start() {
+	for i := 0; i < p.workers; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+}
+
+// Stop stops the workers and waits for the running jobs to finish
+func (p *Pool) Stop() {
+	close(p.stop)
+	p.wg.Wait()
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for {
+		ran, err := p.RunOnce(context.Background())
+		if err != nil {
+			log.Printf("Failed to run job: %s\n", err.Error())
+		}
+		if ran {
+			select {
+			case <-p.stop:
+				return
+			default:
+				continue
+			}
+		}
+		select {
+		case <-p.stop:
+			return
+		case <-time.After(p.poll):
+		}
+	}
+}
+
+// RunOnce claims the next due job and runs it, it returns false if no job was
+// due. Jobs without a registered handler fail.
+// returns InternalError
+func (p *Pool) RunOnce(ctx context.Context) (bool, error) {
+	j, err := p.repo.Claim(ctx, p.lockTimeout)
+	if err != nil || j == nil {
+		return false, err
+	}
+	if err := run(ctx, j); err != nil {
+		log.Printf("%s job %s failed attempt %d: %s\n", j.Kind, j.ID, j.Attempts, err.Error())
+		return true, p.repo.Fail(ctx, j, err)
+	}
+	return true, p.repo.Complete(ctx, j)
+}
+
+// run calls the handler of the job, a panicking handler fails the attempt
+func run(ctx context.Context, j *Job) (err error) {
+	h, ok := handlers[j.Kind]
+	if !ok {
+		return errors.NewInternalError(fmt.Sprintf("no handler for %s jobs", j.Kind))
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.NewInternalError(fmt.Sprintf("handler panicked: %v", r))
+		}
+	}()
+	return h(ctx, []byte(j.Payload))
+}
@@ -0,0 +1,136 @@
+package job
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/jinzhu/gorm"
+	"github.com/robfig/cron"
+)
+
+// Schedule enqueues a job of the given kind at the times of a cron spec
+type Schedule struct {
+	Name string
+	// Spec is a cron spec with seconds, e.g. "0 0 * * * *" enqueues hourly
+	Spec    string
+	Kind    string
+	Payload interface{}
+
+	times cron.Schedule
+}
+
+// Next returns the first run of the schedule after the given time
+func (s Schedule) Next(t time.Time) time.Time {
+	return s.times.Next(t)
+}
+
+var schedules = map[string]*Schedule{}
+
+// RegisterSchedule adds a schedule that enqueues a job of the given kind with
+// the payload at the times of the cron spec. Schedules must be registered
+// before the scheduler is started.
+// returns BadParameterError if the spec is invalid
+func RegisterSchedule(name string, spec string, kind string, payload interface{}) error {
+	times, err := cron.Parse(spec)
+	if err != nil {
+		return errors.NewBadParameterError("spec", spec).Expected(err.Error())
+	}
+	schedules[name] = &Schedule{Name: name, Spec: spec, Kind: kind, Payload: payload, times: times}
+	return nil
+}
+
+// Schedules returns the registered schedules ordered by name
+func Schedules() []*Schedule {
+	res := make([]*Schedule, 0, len(schedules))
+	for _, s := range schedules {
+		res = append(res, s)
+	}
+	sort.Sort(byName(res))
+	return res
+}
+
+// LookupSchedule returns the registered schedule with the given name
+// returns NotFoundError
+func LookupSchedule(name string) (*Schedule, error) {
+	s, ok := schedules[name]
+	if !ok {
+		return nil, errors.NewNotFoundError("schedule", name)
+	}
+	return s, nil
+}
+
+// Scheduler enqueues the runs of the registered schedules as they become due.
+// Every server runs a scheduler, a run is only enqueued once.
+type Scheduler struct {
+	repo Repository
+	// next holds the time of the next run of each schedule
+	next map[string]time.Time
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewScheduler creates a scheduler enqueuing the jobs in the database
+func NewScheduler(db *gorm.DB) *Scheduler {
+	return &Scheduler{
+		repo: NewJobRepository(db),
+		next: map[string]time.Time{},
+		stop: make(chan struct{}),
+	}
+}
+
+// Start checks for due runs every interval
+func (s *Scheduler) Start(interval time.Duration) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			if err := s.Tick(context.Background(), time.Now()); err != nil {
+				log.Printf("Failed to enqueue scheduled jobs: %s\n", err.Error())
+			}
+			select {
+			case <-s.stop:
+				return
+			case <-time.After(interval):
+			}
+		}
+	}()
+}
+
+// Stop stops checking for due runs
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// Tick enqueues the runs of the schedules that are due at the given time.
+// Runs missed while no scheduler was running are skipped.
+// returns InternalError
+func (s *Scheduler) Tick(ctx context.Context, now time.Time) error {
+	for _, sched := range Schedules() {
+		next, ok := s.next[sched.Name]
+		if !ok {
+			s.next[sched.Name] = sched.Next(now)
+			continue
+		}
+		if now.Before(next) {
+			continue
+		}
+		if _, err := s.repo.EnqueueScheduled(ctx, sched, next); err != nil {
+			return err
+		}
+		s.next[sched.Name] = sched.Next(now)
+	}
+	return nil
+}
+
+// byName sorts schedules by their name
+type byName []*Schedule
+
+func (s byName) Len() int           { return len(s) }
+func (s byName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byName) Less(i, j int) bool { return s[i].Name < s[j].Name }
@@ -0,0 +1,83 @@
+package job_test
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/job"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestRegisterSchedule(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	err := job.RegisterSchedule("test.invalid", "every now and then", "test.record", nil)
+	assert.IsType(t, errors.BadParameterError{}, err)
+	_, err = job.LookupSchedule("test.invalid")
+	assert.IsType(t, errors.NotFoundError{}, err)
+
+	require.Nil(t, job.RegisterSchedule("test.hourly", "0 0 * * * *", "test.record", nil))
+	s, err := job.LookupSchedule("test.hourly")
+	require.Nil(t, err)
+	now := time.Date(2017, 1, 1, 10, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2017, 1, 1, 11, 0, 0, 0, time.UTC), s.Next(now))
+}
+
+type TestScheduler struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunScheduler(t *testing.T) {
+	suite.Run(t, &TestScheduler{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestScheduler) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestScheduler) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestScheduler) TestTickEnqueuesEachRunOnce() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	require.Nil(t, job.RegisterSchedule("test.minutely", "0 * * * * *", "test.record", "tick"))
+	start := time.Now().Truncate(time.Minute).Add(-10 * time.Minute)
+
+	// two servers see the same run
+	s1 := job.NewScheduler(test.DB)
+	s2 := job.NewScheduler(test.DB)
+	for _, s := range []*job.Scheduler{s1, s2} {
+		require.Nil(t, s.Tick(ctx, start))
+		require.Nil(t, s.Tick(ctx, start.Add(30*time.Second)))
+		require.Nil(t, s.Tick(ctx, start.Add(90*time.Second)))
+	}
+
+	repo := job.NewJobRepository(test.DB)
+	last, err := repo.LastRun(ctx, "test.minutely")
+	require.Nil(t, err)
+	require.NotNil(t, last)
+	assert.Equal(t, start.Add(time.Minute).Unix(), last.RunAt.Unix())
+	assert.Equal(t, "test.record", last.Kind)
+	assert.Equal(t, `"tick"`, last.Payload)
+	var count int
+	require.Nil(t, test.DB.Model(&job.Job{}).Where("schedule = ?", "test.minutely").Count(&count).Error)
+	assert.Equal(t, 1, count)
+
+	none, err := repo.LastRun(ctx, "test.unknown")
+	require.Nil(t, err)
+	assert.Nil(t, none)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/job"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/goadesign/goa"
+)
+
+// JobsController implements the jobs resource.
+type JobsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewJobsController creates a jobs controller.
+func NewJobsController(service *goa.Service, db application.DB) *JobsController {
+	return &JobsController{Controller: service.NewController("JobsController"), db: db}
+}
+
+// List runs the list action.
+func (c *JobsController) List(ctx *app.ListJobsContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can manage jobs"))
+	}
+	status := ""
+	if ctx.Status != nil {
+		status = *ctx.Status
+	}
+	offset, limit := computePagingLimts(ctx.PageOffset, ctx.PageLimit)
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		jobs, err := appl.Jobs().List(ctx, status, &offset, &limit)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.JobList{Data: []*app.Job{}}
+		for _, j := range jobs {
+			res.Data = append(res.Data, ConvertJob(j))
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Show runs the show action.
+func (c *JobsController) Show(ctx *app.ShowJobsContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can manage jobs"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		j, err := appl.Jobs().Load(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.JobSingle{Data: ConvertJob(j)})
+	})
+}
+
+// Retry runs the retry action.
+func (c *JobsController) Retry(ctx *app.RetryJobsContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can manage jobs"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		j, err := appl.Jobs().Retry(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.JobSingle{Data: ConvertJob(j)})
+	})
+}
+
+// ConvertJob converts between internal and external REST representation
+func ConvertJob(j *job.Job) *app.Job {
+	res := &app.Job{
+		Type: "jobs",
+		ID:   j.ID,
+		Attributes: &app.JobAttributes{
+			Kind:        j.Kind,
+			Status:      j.Status,
+			Attempts:    j.Attempts,
+			MaxAttempts: j.MaxAttempts,
+			RunAt:       j.RunAt,
+			CreatedAt:   j.CreatedAt,
+		},
+	}
+	if j.LastError != "" {
+		res.Attributes.LastError = &j.LastError
+	}
+	return res
+}
@@ -51,7 +51,7 @@ func ErrorHandler(service *goa.Service, verbose bool) goa.Middleware {
 				//respBody = e.Error()
 				//rw.Header().Set("Content-Type", "text/plain")
 			}
-			if status >= 500 && status < 600 {
+			if status >= 500 && status < 600 && status != http.StatusServiceUnavailable {
 				//reqID := ctx.Value(reqIDKey)
 				reqID := ctx.Value(1) // TODO remove this hack
 				if reqID == nil {
@@ -6,19 +6,24 @@ import (
 
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/readonly"
 	"github.com/goadesign/goa"
 	pkgerrors "github.com/pkg/errors"
+	"golang.org/x/net/context"
 )
 
 const (
-	ErrorCodeNotFound          = "not_found"
-	ErrorCodeBadParameter      = "bad_parameter"
-	ErrorCodeVersionConflict   = "version_conflict"
-	ErrorCodeUnknownError      = "unknown_error"
-	ErrorCodeConversionError   = "conversion_error"
-	ErrorCodeInternalError     = "internal_error"
-	ErrorCodeUnauthorizedError = "unauthorized_error"
-	ErrorCodeJWTSecurityError  = "jwt_security_error"
+	ErrorCodeNotFound           = "not_found"
+	ErrorCodeBadParameter       = "bad_parameter"
+	ErrorCodeVersionConflict    = "version_conflict"
+	ErrorCodeDataConflict       = "data_conflict"
+	ErrorCodeUnknownError       = "unknown_error"
+	ErrorCodeConversionError    = "conversion_error"
+	ErrorCodeInternalError      = "internal_error"
+	ErrorCodeUnauthorizedError  = "unauthorized_error"
+	ErrorCodeJWTSecurityError   = "jwt_security_error"
+	ErrorCodeServiceUnavailable = "service_unavailable"
+	ErrorCodePayloadTooLarge    = "payload_too_large"
 )
 
 // ErrorToJSONAPIError returns the JSONAPI representation
@@ -47,10 +52,29 @@ func ErrorToJSONAPIError(err error) (app.JSONAPIError, int) {
 		code = ErrorCodeVersionConflict
 		title = "Version conflict error"
 		statusCode = http.StatusBadRequest
+	case errors.DataConflictError:
+		code = ErrorCodeDataConflict
+		title = "Data conflict error"
+		statusCode = http.StatusConflict
+	case errors.ServiceUnavailableError:
+		code = ErrorCodeServiceUnavailable
+		title = "Service unavailable"
+		statusCode = http.StatusServiceUnavailable
+	case errors.PayloadTooLargeError:
+		code = ErrorCodePayloadTooLarge
+		title = "Payload too large"
+		statusCode = http.StatusRequestEntityTooLarge
 	case errors.InternalError:
 		code = ErrorCodeInternalError
 		title = "Internal error"
 		statusCode = http.StatusInternalServerError
+		// writes rejected by the database while in read-only mode
+		if on, msg := readonly.Enabled(); on && readonly.IsViolation(err) {
+			code = ErrorCodeServiceUnavailable
+			title = "Service unavailable"
+			statusCode = http.StatusServiceUnavailable
+			detail = msg
+		}
 	default:
 		code = ErrorCodeUnknownError
 		title = "Unknown error"
@@ -109,6 +133,11 @@ type Unauthorized interface {
 	Unauthorized(*app.JSONAPIErrors) error
 }
 
+// RequestEntityTooLarge represent a Context that can return a RequestEntityTooLarge HTTP status
+type RequestEntityTooLarge interface {
+	RequestEntityTooLarge(*app.JSONAPIErrors) error
+}
+
 // JSONErrorResponse auto maps the provided error to the correct response type
 // If all else fails, InternalServerError is returned
 func JSONErrorResponse(x InternalServerError, err error) error {
@@ -126,8 +155,27 @@ func JSONErrorResponse(x InternalServerError, err error) error {
 		if ctx, ok := x.(Unauthorized); ok {
 			return ctx.Unauthorized(jsonErr)
 		}
+	case http.StatusRequestEntityTooLarge:
+		if ctx, ok := x.(RequestEntityTooLarge); ok {
+			return ctx.RequestEntityTooLarge(jsonErr)
+		}
+		return sendDirectly(x, status, jsonErr)
+	case http.StatusServiceUnavailable:
+		// no action declares this response, send it directly
+		return sendDirectly(x, status, jsonErr)
 	default:
 		return x.InternalServerError(jsonErr)
 	}
 	return nil
 }
+
+// sendDirectly sends the errors with the status even if the action doesn't
+// declare the response
+func sendDirectly(x InternalServerError, status int, jsonErr *app.JSONAPIErrors) error {
+	if ctx, ok := x.(context.Context); ok {
+		if resp := goa.ContextResponse(ctx); resp != nil && resp.Service != nil {
+			return resp.Service.Send(ctx, status, jsonErr)
+		}
+	}
+	return x.InternalServerError(jsonErr)
+}
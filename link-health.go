@@ -0,0 +1,93 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/workitem/link"
+	"github.com/goadesign/goa"
+)
+
+// LinkHealthController implements the link-health resource.
+type LinkHealthController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewLinkHealthController creates a link-health controller.
+func NewLinkHealthController(service *goa.Service, db application.DB) *LinkHealthController {
+	return &LinkHealthController{Controller: service.NewController("LinkHealthController"), db: db}
+}
+
+// Show runs the show action.
+func (c *LinkHealthController) Show(ctx *app.ShowLinkHealthContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can check the links"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		health, err := appl.WorkItemLinks().Health(ctx)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(ConvertLinkHealth(health))
+	})
+}
+
+// Repair runs the repair action.
+func (c *LinkHealthController) Repair(ctx *app.RepairLinkHealthContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can repair the links"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		deleted, err := appl.WorkItemLinks().RepairDangling(ctx)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.LinkRepair{Deleted: deleted})
+	})
+}
+
+// ConvertLinkHealth converts between internal and external REST representation
+func ConvertLinkHealth(h *link.Health) *app.LinkHealth {
+	res := &app.LinkHealth{
+		Orphans:       make([]*app.LinkHealthOrphans, 0, len(h.Orphans)),
+		Dangling:      make([]*app.LinkHealthDangling, 0, len(h.Dangling)),
+		DanglingCount: h.DanglingCount,
+		Distributions: make([]*app.LinkHealthDistribution, 0, len(h.Distributions)),
+	}
+	for _, o := range h.Orphans {
+		ids := make([]string, 0, len(o.WorkItemIDs))
+		for _, id := range o.WorkItemIDs {
+			ids = append(ids, strconv.FormatUint(id, 10))
+		}
+		res.Orphans = append(res.Orphans, &app.LinkHealthOrphans{
+			LinkTypeID:   o.LinkTypeID,
+			LinkTypeName: o.LinkTypeName,
+			Count:        o.Count,
+			WorkItemIds:  ids,
+		})
+	}
+	for _, l := range h.Dangling {
+		res.Dangling = append(res.Dangling, &app.LinkHealthDangling{
+			ID:         l.ID,
+			SourceID:   strconv.FormatUint(l.SourceID, 10),
+			TargetID:   strconv.FormatUint(l.TargetID, 10),
+			LinkTypeID: l.LinkTypeID,
+		})
+	}
+	for _, d := range h.Distributions {
+		buckets := make([]*app.LinkHealthBucket, 0, len(d.Buckets))
+		for _, b := range d.Buckets {
+			buckets = append(buckets, &app.LinkHealthBucket{Links: b.Links, WorkItems: b.WorkItems})
+		}
+		res.Distributions = append(res.Distributions, &app.LinkHealthDistribution{
+			LinkTypeID:   d.LinkTypeID,
+			LinkTypeName: d.LinkTypeName,
+			Links:        d.Links,
+			Buckets:      buckets,
+		})
+	}
+	return res
+}
@@ -0,0 +1,147 @@
+// Package linkgraph renders work items and the links between them as graphs
+// for standard graph tooling: DOT for Graphviz and GraphML for yEd, Gephi and
+// the like, e.g. to visualize the structure of epics or webs of dependencies.
+package linkgraph
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/workitem"
+)
+
+// MaxNodes is the maximum number of work items of a graph, larger graphs
+// can't be read in graph tooling anyway
+const MaxNodes = 1000
+
+// Node is a work item of the graph
+type Node struct {
+	ID    string
+	Title string
+	Type  string
+	State string
+}
+
+// Edge is a link between two work items of the graph, Relation is the
+// forward name of its type
+type Edge struct {
+	Source   string
+	Target   string
+	Relation string
+}
+
+// Graph holds work items and the links between them
+type Graph struct {
+	Nodes []Node
+	Edges []Edge
+}
+
+// NewNode returns the node of the work item
+func NewNode(wi *app.WorkItem) Node {
+	n := Node{ID: wi.ID, Type: wi.Type}
+	n.Title, _ = wi.Fields[workitem.SystemTitle].(string)
+	n.State, _ = wi.Fields[workitem.SystemState].(string)
+	return n
+}
+
+// dotEscaper escapes text in DOT quoted strings
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", `\n`)
+
+// dotString returns the text as DOT quoted string
+func dotString(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+// RenderDOT renders the graph as directed graph in the DOT language of
+// Graphviz, nodes are labeled with the ID and title of their work item
+func RenderDOT(g *Graph) []byte {
+	var b bytes.Buffer
+	b.WriteString("digraph workitems {\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "  %s [label=%s, type=%s, state=%s];\n", dotString(n.ID), dotString("#"+n.ID+" "+n.Title), dotString(n.Type), dotString(n.State))
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", dotString(e.Source), dotString(e.Target), dotString(e.Relation))
+	}
+	b.WriteString("}\n")
+	return b.Bytes()
+}
+
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// RenderGraphML renders the graph as directed GraphML graph, the title, type
+// and state of the work items and the relation of the links are data of the
+// nodes and edges
+func RenderGraphML(g *Graph) ([]byte, error) {
+	doc := graphMLDocument{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "title", For: "node", Name: "title", Type: "string"},
+			{ID: "type", For: "node", Name: "type", Type: "string"},
+			{ID: "state", For: "node", Name: "state", Type: "string"},
+			{ID: "relation", For: "edge", Name: "relation", Type: "string"},
+		},
+		Graph: graphMLGraph{
+			ID:          "workitems",
+			EdgeDefault: "directed",
+			Nodes:       make([]graphMLNode, 0, len(g.Nodes)),
+			Edges:       make([]graphMLEdge, 0, len(g.Edges)),
+		},
+	}
+	for _, n := range g.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{ID: n.ID, Data: []graphMLData{
+			{Key: "title", Value: n.Title},
+			{Key: "type", Value: n.Type},
+			{Key: "state", Value: n.State},
+		}})
+	}
+	for _, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{Source: e.Source, Target: e.Target, Data: []graphMLData{
+			{Key: "relation", Value: e.Relation},
+		}})
+	}
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
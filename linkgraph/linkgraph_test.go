@@ -0,0 +1,71 @@
+package linkgraph_test
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/linkgraph"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testGraph() *linkgraph.Graph {
+	return &linkgraph.Graph{
+		Nodes: []linkgraph.Node{
+			linkgraph.NewNode(&app.WorkItem{ID: "12", Type: workitem.SystemFeature, Fields: map[string]interface{}{
+				workitem.SystemTitle: `Epic "login"`,
+				workitem.SystemState: "open",
+			}}),
+			{ID: "13", Title: "Crash on <save>", Type: workitem.SystemBug, State: "new"},
+		},
+		Edges: []linkgraph.Edge{{Source: "12", Target: "13", Relation: "parent of"}},
+	}
+}
+
+func TestRenderDOT(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	assert.Equal(t, `digraph workitems {
+  node [shape=box];
+  "12" [label="#12 Epic \"login\"", type="system.feature", state="open"];
+  "13" [label="#13 Crash on <save>", type="system.bug", state="new"];
+  "12" -> "13" [label="parent of"];
+}
+`, string(linkgraph.RenderDOT(testGraph())))
+}
+
+func TestRenderGraphML(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	out, err := linkgraph.RenderGraphML(testGraph())
+	require.Nil(t, err)
+	var doc struct {
+		Graph struct {
+			EdgeDefault string `xml:"edgedefault,attr"`
+			Nodes       []struct {
+				ID   string `xml:"id,attr"`
+				Data []struct {
+					Key   string `xml:"key,attr"`
+					Value string `xml:",chardata"`
+				} `xml:"data"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Target string `xml:"target,attr"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	require.Nil(t, xml.Unmarshal(out, &doc))
+	assert.Equal(t, "directed", doc.Graph.EdgeDefault)
+	require.Len(t, doc.Graph.Nodes, 2)
+	assert.Equal(t, "12", doc.Graph.Nodes[0].ID)
+	assert.Equal(t, "title", doc.Graph.Nodes[0].Data[0].Key)
+	assert.Equal(t, `Epic "login"`, doc.Graph.Nodes[0].Data[0].Value)
+	assert.Equal(t, "Crash on <save>", doc.Graph.Nodes[1].Data[0].Value)
+	require.Len(t, doc.Graph.Edges, 1)
+	assert.Equal(t, "12", doc.Graph.Edges[0].Source)
+	assert.Equal(t, "13", doc.Graph.Edges[0].Target)
+}
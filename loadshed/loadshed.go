@@ -0,0 +1,125 @@
+// Package loadshed caps the requests served at once by endpoint class, the
+// classes of bodylimit. Requests over the cap of their class wait in a short
+// queue for their turn; when the queue is full or the wait too long they are
+// refused with Service Unavailable and a Retry-After header, so traffic
+// spikes don't pile up waiting for database connections.
+package loadshed
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/bodylimit"
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+)
+
+// Shedder admits the requests of the endpoint classes up to their caps
+type Shedder struct {
+	slots      map[bodylimit.Class]chan struct{}
+	waiting    map[bodylimit.Class]*int32
+	queueSize  int32
+	timeout    time.Duration
+	retryAfter time.Duration
+}
+
+// New returns a shedder serving at most caps requests of each class at once,
+// classes without a cap are not limited. Up to queueSize requests of a class
+// wait at most timeout for their turn, refused clients are told to retry
+// after retryAfter.
+func New(caps map[bodylimit.Class]int, queueSize int, timeout time.Duration, retryAfter time.Duration) *Shedder {
+	s := &Shedder{
+		slots:      map[bodylimit.Class]chan struct{}{},
+		waiting:    map[bodylimit.Class]*int32{},
+		queueSize:  int32(queueSize),
+		timeout:    timeout,
+		retryAfter: retryAfter,
+	}
+	for class, n := range caps {
+		if n > 0 {
+			s.slots[class] = make(chan struct{}, n)
+			s.waiting[class] = new(int32)
+		}
+	}
+	return s
+}
+
+// Middleware returns the middleware capping the requests with the configured
+// caps, requests to paths with the given prefixes, like health checks, are
+// never refused
+func Middleware(except ...string) goa.Middleware {
+	caps := map[bodylimit.Class]int{
+		bodylimit.API:     configuration.GetLoadMaxInFlightAPI(),
+		bodylimit.Upload:  configuration.GetLoadMaxInFlightUpload(),
+		bodylimit.Webhook: configuration.GetLoadMaxInFlightWebhook(),
+	}
+	return New(caps, configuration.GetLoadQueueSize(), configuration.GetLoadQueueTimeout(), configuration.GetLoadRetryAfter()).Middleware(except...)
+}
+
+// Middleware returns the middleware capping the requests, requests to paths
+// with the given prefixes are never refused
+func (s *Shedder) Middleware(except ...string) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			for _, prefix := range except {
+				if strings.HasPrefix(req.URL.Path, prefix) {
+					return h(ctx, rw, req)
+				}
+			}
+			class := bodylimit.ClassOf(req.URL.Path)
+			if !s.acquire(ctx, class) {
+				goa.IncrCounter([]string{"goa", "load", "shed", string(class)}, 1)
+				rw.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(s.retryAfter.Seconds()))))
+				return errors.NewServiceUnavailableError("the server is busy, retry later")
+			}
+			defer s.release(class)
+			return h(ctx, rw, req)
+		}
+	}
+}
+
+// acquire returns true once the request may be served, false if it has to be
+// refused
+func (s *Shedder) acquire(ctx context.Context, class bodylimit.Class) bool {
+	slots, ok := s.slots[class]
+	if !ok {
+		return true
+	}
+	select {
+	case slots <- struct{}{}:
+		return true
+	default:
+	}
+	waiting := s.waiting[class]
+	if atomic.AddInt32(waiting, 1) > s.queueSize {
+		atomic.AddInt32(waiting, -1)
+		return false
+	}
+	defer atomic.AddInt32(waiting, -1)
+	defer goa.MeasureSince([]string{"goa", "load", "queued", string(class)}, time.Now())
+
+	timer := time.NewTimer(s.timeout)
+	defer timer.Stop()
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// release frees the slot of a served request
+func (s *Shedder) release(class bodylimit.Class) {
+	if slots, ok := s.slots[class]; ok {
+		<-slots
+	}
+}
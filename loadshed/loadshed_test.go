@@ -0,0 +1,70 @@
+package loadshed_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/bodylimit"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/loadshed"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	shedder := loadshed.New(map[bodylimit.Class]int{bodylimit.API: 1}, 1, 50*time.Millisecond, 2500*time.Millisecond)
+	started := make(chan struct{}, 10)
+	unblock := make(chan struct{})
+	h := shedder.Middleware("/api/status")(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		started <- struct{}{}
+		<-unblock
+		return nil
+	})
+	call := func(path string) (*httptest.ResponseRecorder, error) {
+		req, err := http.NewRequest("GET", path, nil)
+		require.Nil(t, err)
+		rw := httptest.NewRecorder()
+		return rw, h(context.Background(), rw, req)
+	}
+
+	// the first request is served, the second waits in the queue
+	first := make(chan error)
+	go func() {
+		_, err := call("/api/workitems")
+		first <- err
+	}()
+	<-started
+	second := make(chan error)
+	go func() {
+		_, err := call("/api/workitems")
+		second <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	// the queue is full
+	rw, err := call("/api/workitems")
+	assert.IsType(t, errors.ServiceUnavailableError{}, err)
+	assert.Equal(t, "3", rw.Header().Get("Retry-After"))
+
+	// the second request waits too long
+	assert.IsType(t, errors.ServiceUnavailableError{}, <-second)
+
+	// other classes and excepted paths are served
+	close(unblock)
+	_, err = call("/api/projects/1/attachments/paste")
+	assert.Nil(t, err)
+	_, err = call("/api/status")
+	assert.Nil(t, err)
+	assert.Nil(t, <-first)
+
+	// the slot of the first request was released
+	_, err = call("/api/workitems")
+	assert.Nil(t, err)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/almighty/almighty-core/configuration"
 	"github.com/almighty/almighty-core/jsonapi"
 	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/session"
 	"github.com/almighty/almighty-core/token"
 	"github.com/goadesign/goa"
 	uuid "github.com/satori/go.uuid"
@@ -17,12 +18,13 @@ import (
 type LoginController struct {
 	*goa.Controller
 	auth         login.Service
+	sessions     session.Repository
 	tokenManager token.Manager
 }
 
 // NewLoginController creates a login controller.
-func NewLoginController(service *goa.Service, auth login.Service, tokenManager token.Manager) *LoginController {
-	return &LoginController{Controller: service.NewController("login"), auth: auth, tokenManager: tokenManager}
+func NewLoginController(service *goa.Service, auth login.Service, sessions session.Repository, tokenManager token.Manager) *LoginController {
+	return &LoginController{Controller: service.NewController("login"), auth: auth, sessions: sessions, tokenManager: tokenManager}
 }
 
 // Authorize runs the authorize action.
@@ -55,6 +57,11 @@ func (c *LoginController) Generate(ctx *app.GenerateLoginContext) error {
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(fmt.Sprintf("Failed to generate token: %s", err.Error())))
 			return ctx.Unauthorized(jerrors)
 		}
+		err = session.Record(ctx, c.sessions, c.tokenManager, tokenStr, &session.Session{IdentityID: user.ID, UserAgent: ctx.RequestData.Header.Get("User-Agent")})
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(fmt.Sprintf("Failed to record session: %s", err.Error())))
+			return ctx.Unauthorized(jerrors)
+		}
 		tokens = append(tokens, &app.AuthToken{Token: tokenStr})
 	}
 	return ctx.OK(tokens)
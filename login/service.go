@@ -9,7 +9,9 @@ import (
 
 	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/breaker"
 	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/session"
 	"github.com/almighty/almighty-core/token"
 	"github.com/goadesign/goa"
 	uuid "github.com/satori/go.uuid"
@@ -30,11 +32,12 @@ type Service interface {
 }
 
 // NewGitHubOAuth creates a new login.Service capable of using GitHub for authorization
-func NewGitHubOAuth(config *oauth2.Config, identities account.IdentityRepository, users account.UserRepository, tokenManager token.Manager) Service {
+func NewGitHubOAuth(config *oauth2.Config, identities account.IdentityRepository, users account.UserRepository, sessions session.Repository, tokenManager token.Manager) Service {
 	return &gitHubOAuth{
 		config:       config,
 		identities:   identities,
 		users:        users,
+		sessions:     sessions,
 		tokenManager: tokenManager,
 	}
 }
@@ -43,6 +46,7 @@ type gitHubOAuth struct {
 	config       *oauth2.Config
 	identities   account.IdentityRepository
 	users        account.UserRepository
+	sessions     session.Repository
 	tokenManager token.Manager
 }
 
@@ -131,6 +135,12 @@ func (gh *gitHubOAuth) Perform(ctx *app.AuthorizeLoginContext) error {
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
 			return ctx.Unauthorized(jerrors)
 		}
+		err = session.Record(ctx, gh.sessions, gh.tokenManager, almtoken, &session.Session{IdentityID: identity.ID, UserAgent: ctx.RequestData.Header.Get("User-Agent")})
+		if err != nil {
+			fmt.Println("Failed to record session", err)
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+			return ctx.Unauthorized(jerrors)
+		}
 
 		ctx.ResponseData.Header().Set("Location", knownReferer+"?token="+almtoken)
 		return ctx.TemporaryRedirect()
@@ -154,10 +164,15 @@ func (gh *gitHubOAuth) Perform(ctx *app.AuthorizeLoginContext) error {
 
 func (gh gitHubOAuth) getUserEmails(ctx context.Context, token *oauth2.Token) ([]ghEmail, error) {
 	client := gh.config.Client(ctx, token)
-	resp, err := client.Get("https://api.github.com/user/emails")
+	req, err := http.NewRequest("GET", "https://api.github.com/user/emails", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := breaker.For("github").Request(client, req)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
 	var emails []ghEmail
 	json.NewDecoder(resp.Body).Decode(&emails)
@@ -166,10 +181,15 @@ func (gh gitHubOAuth) getUserEmails(ctx context.Context, token *oauth2.Token) ([
 
 func (gh gitHubOAuth) getUser(ctx context.Context, token *oauth2.Token) (*ghUser, error) {
 	client := gh.config.Client(ctx, token)
-	resp, err := client.Get("https://api.github.com/user")
+	req, err := http.NewRequest("GET", "https://api.github.com/user", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := breaker.For("github").Request(client, req)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
 	var user ghUser
 	json.NewDecoder(resp.Body).Decode(&user)
@@ -18,6 +18,7 @@ import (
 	. "github.com/almighty/almighty-core/login"
 	"github.com/almighty/almighty-core/migration"
 	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/session"
 	"github.com/almighty/almighty-core/token"
 	"github.com/goadesign/goa"
 	"github.com/jinzhu/gorm"
@@ -69,7 +70,8 @@ func TestMain(m *testing.M) {
 	tokenManager := token.NewManager(publicKey, privateKey)
 	userRepository := account.NewUserRepository(db)
 	identityRepository := account.NewIdentityRepository(db)
-	loginService = NewGitHubOAuth(oauth, identityRepository, userRepository, tokenManager)
+	sessionRepository := session.NewRepository(db)
+	loginService = NewGitHubOAuth(oauth, identityRepository, userRepository, sessionRepository, tokenManager)
 
 	os.Exit(m.Run())
 }
@@ -6,7 +6,9 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"os/user"
+	"syscall"
 	"time"
 
 	"golang.org/x/net/context"
@@ -19,13 +21,28 @@ import (
 
 	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/automation"
+	"github.com/almighty/almighty-core/bodylimit"
+	"github.com/almighty/almighty-core/changefeed"
+	"github.com/almighty/almighty-core/chat"
 	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/encryption"
 	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/httpserver"
+	"github.com/almighty/almighty-core/job"
 	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/loadshed"
 	"github.com/almighty/almighty-core/login"
 	"github.com/almighty/almighty-core/migration"
 	"github.com/almighty/almighty-core/models"
+	"github.com/almighty/almighty-core/notification"
+	"github.com/almighty/almighty-core/outbox"
+	"github.com/almighty/almighty-core/push"
+	"github.com/almighty/almighty-core/readonly"
 	"github.com/almighty/almighty-core/remoteworkitem"
+	"github.com/almighty/almighty-core/scan"
+	"github.com/almighty/almighty-core/search"
+	"github.com/almighty/almighty-core/session"
 	"github.com/almighty/almighty-core/token"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/almighty/almighty-core/workitem/link"
@@ -42,6 +59,8 @@ var (
 	BuildTime = "0"
 	// StartTime in ISO 8601 (UTC) format
 	StartTime = time.Now().UTC().Format("2006-01-02T15:04:05Z")
+	// connections of the HTTP server, reported by the status
+	connections = httpserver.NewConnections()
 )
 
 func main() {
@@ -114,8 +133,20 @@ func main() {
 		os.Exit(0)
 	}
 
+	// A newer version may have migrated the schema during a rolling upgrade,
+	// serve it read-only if this code can't write it
+	var degraded bool
+	if err := migration.CheckCompatibility(db.DB()); err != nil {
+		if _, ok := err.(migration.IncompatibleSchemaError); !ok || configuration.GetMigrationOnMismatch() != "readonly" {
+			panic(err.Error())
+		}
+		log.Printf("Running in read-only mode: %s\n", err.Error())
+		readonly.Enable(readonly.Schema, "The service is read-only while it's being upgraded, try again later.")
+		degraded = true
+	}
+
 	// Make sure the database is populated with the correct types (e.g. system.bug etc.)
-	if configuration.GetPopulateCommonTypes() {
+	if configuration.GetPopulateCommonTypes() && !degraded {
 		if err := models.Transactional(db, func(tx *gorm.DB) error {
 			return migration.PopulateCommonTypes(context.Background(), tx, workitem.NewWorkItemTypeRepository(tx))
 		}); err != nil {
@@ -128,10 +159,17 @@ func main() {
 		}
 	}
 
+	// Setup the keys sensitive work item fields are encrypted with
+	if err := encryption.SetupDefaultKeyring(); err != nil {
+		panic(err.Error())
+	}
+
 	// Scheduler to fetch and import remote tracker items
 	scheduler = remoteworkitem.NewScheduler(db)
 	defer scheduler.Stop()
-	scheduler.ScheduleAllQueries()
+	if !degraded {
+		scheduler.ScheduleAllQueries()
+	}
 
 	// Create service
 	service := goa.New("alm")
@@ -141,6 +179,9 @@ func main() {
 	service.Use(middleware.LogRequest(true))
 	service.Use(gzip.Middleware(9))
 	service.Use(jsonapi.ErrorHandler(service, true))
+	service.Use(bodylimit.Middleware())
+	service.Use(loadshed.Middleware("/api/status"))
+	service.Use(readonly.Middleware("/api/maintenance"))
 	service.Use(middleware.Recover())
 
 	privateKey, err := token.ParsePrivateKey(configuration.GetTokenPrivateKey())
@@ -155,10 +196,133 @@ func main() {
 	// Setup Account/Login/Security
 	identityRepository := account.NewIdentityRepository(db)
 	userRepository := account.NewUserRepository(db)
+	sessionRepository := session.NewRepository(db)
 
 	tokenManager := token.NewManager(publicKey, privateKey)
 	app.UseJWTMiddleware(service, jwt.New(publicKey, nil, app.NewJWTSecurity()))
 	service.Use(login.InjectTokenManager(tokenManager))
+	service.Use(AuditImpersonation(tokenManager))
+
+	appDB := gormapplication.NewGormDB(db)
+	service.Use(CheckSession(appDB, tokenManager))
+	service.Use(InjectSQLDebug(tokenManager))
+	service.Use(InjectViewer(appDB, tokenManager))
+	service.Use(RedirectMovedResources(appDB))
+	service.Use(ResolveWorkItemKeys(appDB))
+	service.Use(AllowEmbeddedIntake(appDB))
+
+	// Apply the runtime settings and reload them on SIGHUP
+	if err := reloadConfiguration(context.Background(), appDB); err != nil {
+		panic(err.Error())
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			if err := reloadConfiguration(context.Background(), appDB); err != nil {
+				log.Printf("Failed to reload the configuration: %s", err.Error())
+			}
+		}
+	}()
+
+	// Relay delivering the outbox to the webhooks, the change feed bus and the search index
+	var sinks []outbox.Sink
+	if urls := configuration.GetOutboxWebhooks(); len(urls) > 0 {
+		sinks = append(sinks, outbox.NewWebhookSink(urls, configuration.GetOutboxWebhookSecret()))
+	}
+	publisher, err := changefeed.NewPublisher(configuration.GetChangeFeedPublisher(), configuration.GetChangeFeedBrokers())
+	if err != nil {
+		panic(err.Error())
+	}
+	if publisher != nil {
+		sinks = append(sinks, changefeed.NewBusSink(publisher, configuration.GetChangeFeedTopicPrefix()))
+	}
+	if esURL := configuration.GetSearchElasticURL(); esURL != "" {
+		index := search.NewElasticIndex(esURL, configuration.GetSearchElasticIndex())
+		created, err := index.Create(context.Background())
+		if err != nil {
+			panic(err.Error())
+		}
+		indexSink := search.NewIndexSink(db, index)
+		job.Register(search.ReindexJobKind, indexSink.ReindexJob())
+		if created && !degraded {
+			if _, err := appDB.Jobs().Enqueue(context.Background(), search.ReindexJobKind, nil); err != nil {
+				panic(err.Error())
+			}
+		}
+		search.RegisterIndex(index)
+		sinks = append(sinks, indexSink)
+	}
+	outboxRelay := outbox.NewRelay(db, sinks, configuration.GetOutboxRetention(), configuration.GetOutboxMaxAttempts())
+	if !degraded {
+		outboxRelay.Start(configuration.GetJobsPoll())
+		defer outboxRelay.Stop()
+	}
+
+	// Notifications are pushed to the registered mobile devices of the platforms with a key
+	senders := map[string]push.Sender{}
+	if key := configuration.GetPushFCMKey(); key != "" {
+		senders[push.PlatformFCM] = push.NewFCMSender(key, 10*time.Second)
+	}
+	if key := configuration.GetPushAPNsKey(); key != "" {
+		sender, err := push.NewAPNsSender([]byte(key), configuration.GetPushAPNsKeyID(), configuration.GetPushAPNsTeamID(),
+			configuration.GetPushAPNsTopic(), configuration.IsPushAPNsSandbox(), 10*time.Second)
+		if err != nil {
+			panic(err.Error())
+		}
+		senders[push.PlatformAPNs] = sender
+	}
+	if len(senders) > 0 {
+		notification.RegisterChannel(push.NewChannel(db, senders))
+	}
+	// Notifications are kept in the inboxes of their recipients, each channel only if the
+	// preferences of the recipient allow it
+	notification.RegisterChannel(notification.NewInboxChannel(db))
+	notification.UsePreferences(db)
+
+	// Workers running the background jobs
+	job.Register(associateCodeChangesJobKind, associateCodeChangesJob(appDB))
+	job.Register(staleJobKind, sweepStaleWorkItemsJob(appDB))
+	if err := job.RegisterSchedule("stale-work-items", configuration.GetStaleSchedule(), staleJobKind, nil); err != nil {
+		panic(err.Error())
+	}
+	job.Register(escalationJobKind, sweepEscalationsJob(appDB))
+	if err := job.RegisterSchedule("escalations", configuration.GetEscalationSchedule(), escalationJobKind, nil); err != nil {
+		panic(err.Error())
+	}
+	job.Register(flowJobKind, snapshotFlowJob(appDB))
+	if err := job.RegisterSchedule("state-snapshots", configuration.GetFlowSchedule(), flowJobKind, nil); err != nil {
+		panic(err.Error())
+	}
+	job.Register(trashJobKind, purgeTrashJob(appDB))
+	if err := job.RegisterSchedule("purge-trash", configuration.GetTrashSchedule(), trashJobKind, nil); err != nil {
+		panic(err.Error())
+	}
+	job.Register(draftJobKind, deleteAbandonedDraftsJob(appDB))
+	if err := job.RegisterSchedule("abandoned-drafts", configuration.GetDraftSchedule(), draftJobKind, nil); err != nil {
+		panic(err.Error())
+	}
+	job.Register(eventPartitionsJobKind, maintainEventPartitionsJob(appDB))
+	if err := job.RegisterSchedule("event-partitions", configuration.GetHistorySchedule(), eventPartitionsJobKind, nil); err != nil {
+		panic(err.Error())
+	}
+	job.Register(retentionJobKind, enforceRetentionJob(appDB))
+	if err := job.RegisterSchedule("retention-policies", configuration.GetRetentionSchedule(), retentionJobKind, nil); err != nil {
+		panic(err.Error())
+	}
+	job.Register(backupJobKind, runBackupJob(appDB))
+	job.Register(replaceDeprecatedJobKind, replaceDeprecatedJob(appDB))
+	job.Register(chat.JobKind, postChatMessageJob(appDB, chat.NewPoster(10*time.Second)))
+	job.Register(automation.WebhookJobKind, automation.WebhookJob(10*time.Second))
+	// the instances that can write the schema run the jobs
+	if !degraded {
+		jobPool := job.NewPool(db, configuration.GetJobsWorkers(), configuration.GetJobsPoll(), configuration.GetJobsLockTimeout())
+		jobPool.Start()
+		defer jobPool.Stop()
+		jobScheduler := job.NewScheduler(db)
+		jobScheduler.Start(configuration.GetJobsPoll())
+		defer jobScheduler.Stop()
+	}
 
 	// Mount "login" controller
 	oauth := &oauth2.Config{
@@ -168,20 +332,62 @@ func main() {
 		Endpoint:     github.Endpoint,
 	}
 
-	loginService := login.NewGitHubOAuth(oauth, identityRepository, userRepository, tokenManager)
-	loginCtrl := NewLoginController(service, loginService, tokenManager)
+	loginService := login.NewGitHubOAuth(oauth, identityRepository, userRepository, sessionRepository, tokenManager)
+	loginCtrl := NewLoginController(service, loginService, sessionRepository, tokenManager)
 	app.MountLoginController(service, loginCtrl)
 
 	// Mount "status" controller
 	statusCtrl := NewStatusController(service, db)
 	app.MountStatusController(service, statusCtrl)
 
-	appDB := gormapplication.NewGormDB(db)
-
 	// Mount "workitem" controller
 	workitemCtrl := NewWorkitemController(service, appDB)
 	app.MountWorkitemController(service, workitemCtrl)
 
+	// Mount "work item archive" controller
+	workItemArchiveCtrl := NewWorkItemArchiveController(service, appDB)
+	app.MountWorkItemArchiveController(service, workItemArchiveCtrl)
+
+	// Mount "work item autosave" controller
+	workItemAutosaveCtrl := NewWorkItemAutosaveController(service, appDB)
+	app.MountWorkItemAutosaveController(service, workItemAutosaveCtrl)
+
+	// Mount "work item checklist" controller
+	workItemChecklistCtrl := NewWorkItemChecklistController(service, appDB)
+	app.MountWorkItemChecklistController(service, workItemChecklistCtrl)
+
+	// Mount "work item children" controller
+	workItemChildrenCtrl := NewWorkItemChildrenController(service, appDB)
+	app.MountWorkItemChildrenController(service, workItemChildrenCtrl)
+
+	// Mount "work item draft" controller
+	workItemDraftCtrl := NewWorkItemDraftController(service, appDB)
+	app.MountWorkItemDraftController(service, workItemDraftCtrl)
+
+	// Mount "work item merge" controller
+	workItemMergeCtrl := NewWorkItemMergeController(service, appDB)
+	app.MountWorkItemMergeController(service, workItemMergeCtrl)
+
+	// Mount "work item print" controller
+	workItemPrintCtrl := NewWorkItemPrintController(service, appDB)
+	app.MountWorkItemPrintController(service, workItemPrintCtrl)
+
+	// Mount "work item reparent" controller
+	workItemReparentCtrl := NewWorkItemReparentController(service, appDB)
+	app.MountWorkItemReparentController(service, workItemReparentCtrl)
+
+	// Mount "work item resolve" controller
+	workItemResolveCtrl := NewWorkItemResolveController(service, appDB)
+	app.MountWorkItemResolveController(service, workItemResolveCtrl)
+
+	// Mount "work item shares" controller
+	workItemSharesCtrl := NewWorkItemSharesController(service, appDB)
+	app.MountWorkItemSharesController(service, workItemSharesCtrl)
+
+	// Mount "shared" controller
+	sharedCtrl := NewSharedController(service, appDB)
+	app.MountSharedController(service, sharedCtrl)
+
 	// Mount "workitemtype" controller
 	workitemtypeCtrl := NewWorkitemtypeController(service, appDB)
 	app.MountWorkitemtypeController(service, workitemtypeCtrl)
@@ -198,10 +404,275 @@ func main() {
 	workItemLinkCtrl := NewWorkItemLinkController(service, appDB)
 	app.MountWorkItemLinkController(service, workItemLinkCtrl)
 
+	// Mount "work item link suggestions" controller
+	workItemLinkSuggestionsCtrl := NewWorkItemLinkSuggestionsController(service, appDB)
+	app.MountWorkItemLinkSuggestionsController(service, workItemLinkSuggestionsCtrl)
+
+	// Mount "work item graph" controller
+	workItemGraphCtrl := NewWorkItemGraphController(service, appDB)
+	app.MountWorkItemGraphController(service, workItemGraphCtrl)
+
+	// Mount "link health" controller
+	linkHealthCtrl := NewLinkHealthController(service, appDB)
+	app.MountLinkHealthController(service, linkHealthCtrl)
+
 	// Mount "work item comments" controller
 	workItemCommentsCtrl := NewWorkItemCommentsController(service, appDB)
 	app.MountWorkItemCommentsController(service, workItemCommentsCtrl)
 
+	// Mount "work item remote links" controller
+	workItemRemoteLinksCtrl := NewWorkItemRemoteLinksController(service, appDB)
+	app.MountWorkItemRemoteLinksController(service, workItemRemoteLinksCtrl)
+
+	// Mount "remote link" controller
+	remoteLinkCtrl := NewRemoteLinkController(service, appDB)
+	app.MountRemoteLinkController(service, remoteLinkCtrl)
+
+	// Mount "work item codebase" controller
+	workItemCodebaseCtrl := NewWorkItemCodebaseController(service, appDB)
+	app.MountWorkItemCodebaseController(service, workItemCodebaseCtrl)
+
+	// Mount "webhooks" controller
+	webhooksCtrl := NewWebhooksController(service, appDB)
+	app.MountWebhooksController(service, webhooksCtrl)
+
+	// Mount "codebase" controller
+	codebaseCtrl := NewCodebaseController(service, appDB)
+	app.MountCodebaseController(service, codebaseCtrl)
+
+	// Mount "project codebases" controller
+	projectCodebasesCtrl := NewProjectCodebasesController(service, appDB)
+	app.MountProjectCodebasesController(service, projectCodebasesCtrl)
+
+	// Mount "work item codebases" controller
+	workItemCodebasesCtrl := NewWorkItemCodebasesController(service, appDB)
+	app.MountWorkItemCodebasesController(service, workItemCodebasesCtrl)
+
+	// Mount "deployment" controller
+	deploymentCtrl := NewDeploymentController(service, appDB)
+	app.MountDeploymentController(service, deploymentCtrl)
+
+	// Mount "release" controller
+	releaseCtrl := NewReleaseController(service, appDB)
+	app.MountReleaseController(service, releaseCtrl)
+
+	// Mount "project releases" controller
+	projectReleasesCtrl := NewProjectReleasesController(service, appDB)
+	app.MountProjectReleasesController(service, projectReleasesCtrl)
+
+	// Mount "release notes" controller
+	releaseNotesCtrl := NewReleaseNotesController(service, appDB)
+	app.MountReleaseNotesController(service, releaseNotesCtrl)
+
+	// Mount "project field values" controller
+	projectFieldValuesCtrl := NewProjectFieldValuesController(service, appDB)
+	app.MountProjectFieldValuesController(service, projectFieldValuesCtrl)
+
+	// Mount "work item votes" controller
+	workItemVotesCtrl := NewWorkItemVotesController(service, appDB)
+	app.MountWorkItemVotesController(service, workItemVotesCtrl)
+
+	// Mount "favorites" controller
+	favoritesCtrl := NewFavoritesController(service, appDB)
+	app.MountFavoritesController(service, favoritesCtrl)
+
+	// Mount "push devices" controller
+	pushDevicesCtrl := NewPushDevicesController(service, appDB)
+	app.MountPushDevicesController(service, pushDevicesCtrl)
+
+	// Mount "notification preferences" controller
+	notificationPreferencesCtrl := NewNotificationPreferencesController(service, appDB)
+	app.MountNotificationPreferencesController(service, notificationPreferencesCtrl)
+
+	// Mount "notification inbox" controller
+	notificationInboxCtrl := NewNotificationInboxController(service, appDB)
+	app.MountNotificationInboxController(service, notificationInboxCtrl)
+
+	// Mount "notification mutes" controller
+	notificationMutesCtrl := NewNotificationMutesController(service, appDB)
+	app.MountNotificationMutesController(service, notificationMutesCtrl)
+
+	// Mount "email templates" controller
+	emailTemplatesCtrl := NewEmailTemplatesController(service, appDB)
+	app.MountEmailTemplatesController(service, emailTemplatesCtrl)
+
+	// Mount "branding" controller
+	brandingCtrl := NewBrandingController(service, appDB)
+	app.MountBrandingController(service, brandingCtrl)
+
+	// Mount "dashboard" controller
+	dashboardCtrl := NewDashboardController(service, appDB)
+	app.MountDashboardController(service, dashboardCtrl)
+
+	// Mount "portfolio" controller
+	portfolioCtrl := NewPortfolioController(service, appDB)
+	app.MountPortfolioController(service, portfolioCtrl)
+
+	// Mount "project dashboards" controller
+	projectDashboardsCtrl := NewProjectDashboardsController(service, appDB)
+	app.MountProjectDashboardsController(service, projectDashboardsCtrl)
+
+	// Mount "comment reactions" controller
+	commentReactionsCtrl := NewCommentReactionsController(service, appDB)
+	app.MountCommentReactionsController(service, commentReactionsCtrl)
+
+	// Mount "personal data" controller
+	personalDataCtrl := NewPersonalDataController(service, appDB)
+	app.MountPersonalDataController(service, personalDataCtrl)
+
+	// Mount "moderation" controller
+	moderationCtrl := NewModerationController(service, appDB)
+	app.MountModerationController(service, moderationCtrl)
+
+	// Mount "project feedback" controller
+	projectFeedbackCtrl := NewProjectFeedbackController(service, appDB)
+	app.MountProjectFeedbackController(service, projectFeedbackCtrl)
+
+	// Mount "project intake" controller
+	projectIntakeCtrl := NewProjectIntakeController(service, appDB)
+	app.MountProjectIntakeController(service, projectIntakeCtrl)
+
+	// Mount "settings" controller
+	settingsCtrl := NewSettingsController(service, appDB)
+	app.MountSettingsController(service, settingsCtrl)
+
+	// Mount "maintenance" controller
+	maintenanceCtrl := NewMaintenanceController(service, appDB)
+	app.MountMaintenanceController(service, maintenanceCtrl)
+
+	// Mount "schema" controller
+	schemaCtrl := NewSchemaController(service, appDB)
+	app.MountSchemaController(service, schemaCtrl)
+
+	// Mount "translations" controller
+	translationsCtrl := NewTranslationsController(service, appDB)
+	app.MountTranslationsController(service, translationsCtrl)
+
+	// Mount "calendar" controller
+	calendarCtrl := NewCalendarController(service, appDB)
+	app.MountCalendarController(service, calendarCtrl)
+
+	// Mount "project-chat-integration" controller
+	projectChatIntegrationCtrl := NewProjectChatIntegrationController(service, appDB)
+	app.MountProjectChatIntegrationController(service, projectChatIntegrationCtrl)
+
+	// Mount "automation-rule" controller
+	automationRuleCtrl := NewAutomationRuleController(service, appDB)
+	app.MountAutomationRuleController(service, automationRuleCtrl)
+
+	// Mount "project-automation-rules" controller
+	projectAutomationRulesCtrl := NewProjectAutomationRulesController(service, appDB)
+	app.MountProjectAutomationRulesController(service, projectAutomationRulesCtrl)
+
+	// Mount "assignment-rule" controller
+	assignmentRuleCtrl := NewAssignmentRuleController(service, appDB)
+	app.MountAssignmentRuleController(service, assignmentRuleCtrl)
+
+	// Mount "project-assignment-rules" controller
+	projectAssignmentRulesCtrl := NewProjectAssignmentRulesController(service, appDB)
+	app.MountProjectAssignmentRulesController(service, projectAssignmentRulesCtrl)
+
+	// Mount "approval-rule" controller
+	approvalRuleCtrl := NewApprovalRuleController(service, appDB)
+	app.MountApprovalRuleController(service, approvalRuleCtrl)
+
+	// Mount "project-approval-rules" controller
+	projectApprovalRulesCtrl := NewProjectApprovalRulesController(service, appDB)
+	app.MountProjectApprovalRulesController(service, projectApprovalRulesCtrl)
+
+	// Mount "work-item-approvals" controller
+	workItemApprovalsCtrl := NewWorkItemApprovalsController(service, appDB)
+	app.MountWorkItemApprovalsController(service, workItemApprovalsCtrl)
+
+	// Mount "approval" controller
+	approvalCtrl := NewApprovalController(service, appDB)
+	app.MountApprovalController(service, approvalCtrl)
+
+	// Mount "jobs" controller
+	jobsCtrl := NewJobsController(service, appDB)
+	app.MountJobsController(service, jobsCtrl)
+
+	// Mount "schedules" controller
+	schedulesCtrl := NewSchedulesController(service, appDB)
+	app.MountSchedulesController(service, schedulesCtrl)
+
+	// Mount "backups" controller
+	backupsCtrl := NewBackupsController(service, appDB)
+	app.MountBackupsController(service, backupsCtrl)
+
+	// Mount "project stale policy" controller
+	projectStalePolicyCtrl := NewProjectStalePolicyController(service, appDB)
+	app.MountProjectStalePolicyController(service, projectStalePolicyCtrl)
+
+	// Mount "project escalation policy" controller
+	projectEscalationPolicyCtrl := NewProjectEscalationPolicyController(service, appDB)
+	app.MountProjectEscalationPolicyController(service, projectEscalationPolicyCtrl)
+
+	// Mount "project type hierarchy" controller
+	projectTypeHierarchyCtrl := NewProjectTypeHierarchyController(service, appDB)
+	app.MountProjectTypeHierarchyController(service, projectTypeHierarchyCtrl)
+
+	// Mount "project process config" controller
+	projectProcessConfigCtrl := NewProjectProcessConfigController(service, appDB)
+	app.MountProjectProcessConfigController(service, projectProcessConfigCtrl)
+
+	// Mount "process templates" controller
+	processTemplatesCtrl := NewProcessTemplatesController(service, appDB)
+	app.MountProcessTemplatesController(service, processTemplatesCtrl)
+
+	// Mount "project settings" controller
+	projectSettingsCtrl := NewProjectSettingsController(service, appDB)
+	app.MountProjectSettingsController(service, projectSettingsCtrl)
+
+	// Mount "instance stats" controller
+	instanceStatsCtrl := NewInstanceStatsController(service, appDB)
+	app.MountInstanceStatsController(service, instanceStatsCtrl)
+
+	// Mount "impersonations" controller
+	impersonationsCtrl := NewImpersonationsController(service, appDB, tokenManager)
+	app.MountImpersonationsController(service, impersonationsCtrl)
+
+	// Mount "sessions" controller
+	sessionsCtrl := NewSessionsController(service, appDB, tokenManager)
+	app.MountSessionsController(service, sessionsCtrl)
+
+	// Mount "project retention policy" controller
+	projectRetentionPolicyCtrl := NewProjectRetentionPolicyController(service, appDB)
+	app.MountProjectRetentionPolicyController(service, projectRetentionPolicyCtrl)
+
+	// Mount "project flow" controller
+	projectFlowCtrl := NewProjectFlowController(service, appDB)
+	app.MountProjectFlowController(service, projectFlowCtrl)
+
+	// Mount "project trash" controller
+	projectTrashCtrl := NewProjectTrashController(service, appDB)
+	app.MountProjectTrashController(service, projectTrashCtrl)
+
+	// Mount "attachment" controller
+	attachmentCtrl := NewAttachmentController(service, appDB)
+	app.MountAttachmentController(service, attachmentCtrl)
+
+	// Mount "project attachments" controller, uploads are scanned for malware
+	// if a ClamAV daemon is configured
+	var scanner scan.Scanner = scan.NopScanner{}
+	if address := configuration.GetScanClamd(); address != "" {
+		scanner = scan.NewClamdScanner(address, configuration.GetScanTimeout())
+	}
+	projectAttachmentsCtrl := NewProjectAttachmentsController(service, appDB, scanner)
+	app.MountProjectAttachmentsController(service, projectAttachmentsCtrl)
+
+	// Mount "storage" controller
+	storageCtrl := NewStorageController(service, appDB)
+	app.MountStorageController(service, storageCtrl)
+
+	// Mount "work item revisions" controller
+	workItemRevisionsCtrl := NewWorkItemRevisionsController(service, appDB)
+	app.MountWorkItemRevisionsController(service, workItemRevisionsCtrl)
+
+	// Mount "work item events" controller
+	workItemEventsCtrl := NewWorkItemEventsController(service, appDB)
+	app.MountWorkItemEventsController(service, workItemEventsCtrl)
+
 	// Mount "work item relationships links" controller
 	workItemRelationshipsLinksCtrl := NewWorkItemRelationshipsLinksController(service, appDB)
 	app.MountWorkItemRelationshipsLinksController(service, workItemRelationshipsLinksCtrl)
@@ -230,6 +701,10 @@ func main() {
 	searchCtrl := NewSearchController(service, appDB)
 	app.MountSearchController(service, searchCtrl)
 
+	// Mount "report" controller
+	reportCtrl := NewReportController(service, appDB)
+	app.MountReportController(service, reportCtrl)
+
 	// Mount "indentity" controller
 	identityCtrl := NewIdentityController(service, appDB)
 	app.MountIdentityController(service, identityCtrl)
@@ -242,6 +717,10 @@ func main() {
 	iterationCtrl := NewIterationController(service, appDB)
 	app.MountIterationController(service, iterationCtrl)
 
+	// Mount "iteration archive" controller
+	iterationArchiveCtrl := NewIterationArchiveController(service, appDB)
+	app.MountIterationArchiveController(service, iterationArchiveCtrl)
+
 	projectIterationCtrl := NewProjectIterationsController(service, appDB)
 	app.MountProjectIterationsController(service, projectIterationCtrl)
 
@@ -255,7 +734,11 @@ func main() {
 	http.Handle("/favicon.ico", http.NotFoundHandler())
 
 	// Start http
-	if err := http.ListenAndServe(configuration.GetHTTPAddress(), nil); err != nil {
+	srv, err := httpserver.New(http.DefaultServeMux, connections)
+	if err != nil {
+		panic(err.Error())
+	}
+	if err := httpserver.ListenAndServe(srv); err != nil {
 		service.LogError("startup", "err", err)
 	}
 
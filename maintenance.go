@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/readonly"
+	"github.com/goadesign/goa"
+)
+
+// MaintenanceController implements the maintenance resource.
+type MaintenanceController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewMaintenanceController creates a maintenance controller.
+func NewMaintenanceController(service *goa.Service, db application.DB) *MaintenanceController {
+	return &MaintenanceController{Controller: service.NewController("MaintenanceController"), db: db}
+}
+
+// Show runs the show action.
+func (c *MaintenanceController) Show(ctx *app.ShowMaintenanceContext) error {
+	return ctx.OK(&app.MaintenanceSingle{Data: ConvertMaintenance()})
+}
+
+// Update runs the update action.
+func (c *MaintenanceController) Update(ctx *app.UpdateMaintenanceContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can switch maintenance"))
+	}
+	if ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	attrs := ctx.Payload.Data.Attributes
+	// the settings have to be written while the API is read-only
+	err := application.MaintenanceTransactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if attrs.Message != nil {
+			if _, err := appl.Settings().Save(ctx, "maintenance.message", *attrs.Message); err != nil {
+				return err
+			}
+		}
+		_, err := appl.Settings().Save(ctx, "maintenance.enabled", strconv.FormatBool(attrs.Enabled))
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	if err := reloadConfiguration(ctx, c.db); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.MaintenanceSingle{Data: ConvertMaintenance()})
+}
+
+// applyMaintenance switches the read-only mode of the maintenance as
+// configured
+func applyMaintenance() {
+	if configuration.IsMaintenanceEnabled() {
+		readonly.Enable(readonly.Maintenance, configuration.GetMaintenanceMessage())
+	} else {
+		readonly.Disable(readonly.Maintenance)
+	}
+}
+
+// ConvertMaintenance converts the current maintenance mode to its REST
+// representation
+func ConvertMaintenance() *app.Maintenance {
+	enabled := configuration.IsMaintenanceEnabled()
+	message := configuration.GetMaintenanceMessage()
+	readOnly, _ := readonly.Enabled()
+	return &app.Maintenance{
+		Type: "maintenance",
+		Attributes: &app.MaintenanceAttributes{
+			Enabled:  enabled,
+			Message:  &message,
+			ReadOnly: &readOnly,
+			Reasons:  readonly.Reasons(),
+		},
+	}
+}
@@ -26,6 +26,38 @@ type steps []fn
 // migrations defines all a collection of all the steps
 type migrations []steps
 
+// compatibilityVersion is the first version recording which older versions
+// the schema is compatible with
+const compatibilityVersion = 46
+
+// backwardCompatible holds the versions whose migration only adds to the
+// schema (tables, nullable columns, indexes) so the code of the version
+// before keeps working on it. A migration renaming, dropping or changing
+// the meaning of anything the code of the version before uses must not be
+// listed, instances running that code refuse to start once it's applied.
+var backwardCompatible = map[int64]bool{
+	46: true,
+	47: true,
+	48: true,
+	49: true,
+	50: true,
+	51: true,
+	52: true,
+	53: true,
+	54: true,
+	55: true,
+	56: true,
+	57: true,
+	58: true,
+	59: true,
+	60: true,
+	61: true,
+	62: true,
+	63: true,
+	64: true,
+	65: true,
+}
+
 // Migrate executes the required migration of the database on startup.
 // For each successful migration, an entry will be written into the "version"
 // table, that states when a certain version was reached.
@@ -121,6 +153,210 @@ func getMigrations() migrations {
 	// Version 14
 	m = append(m, steps{executeSQLFile("014-wi-fields-index.sql")})
 
+	// Version 15
+	m = append(m, steps{executeSQLFile("015-remote-links.sql")})
+
+	// Version 16
+	m = append(m, steps{executeSQLFile("016-code-changes.sql")})
+
+	// Version 17
+	m = append(m, steps{executeSQLFile("017-codebases.sql")})
+
+	// Version 18
+	m = append(m, steps{executeSQLFile("018-deployments.sql")})
+
+	// Version 19
+	m = append(m, steps{executeSQLFile("019-releases.sql")})
+
+	// Version 20
+	m = append(m, steps{executeSQLFile("020-field-values.sql")})
+
+	// Version 21
+	m = append(m, steps{executeSQLFile("021-work-item-votes.sql")})
+
+	// Version 22
+	m = append(m, steps{executeSQLFile("022-comment-reactions.sql")})
+
+	// Version 23
+	m = append(m, steps{executeSQLFile("023-project-admins.sql")})
+
+	// Version 24
+	m = append(m, steps{executeSQLFile("024-tombstone-identity.sql")})
+
+	// Version 25
+	m = append(m, steps{executeSQLFile("025-moderation.sql")})
+
+	// Version 26
+	m = append(m, steps{executeSQLFile("026-anonymous-contributions.sql")})
+
+	// Version 27
+	m = append(m, steps{executeSQLFile("027-project-visibility.sql")})
+
+	// Version 28
+	m = append(m, steps{executeSQLFile("028-settings.sql")})
+
+	// Version 29
+	m = append(m, steps{executeSQLFile("029-jobs.sql")})
+
+	// Version 30
+	m = append(m, steps{executeSQLFile("030-job-schedules.sql")})
+
+	// Version 31
+	m = append(m, steps{executeSQLFile("031-stale-work-items.sql")})
+
+	// Version 32
+	m = append(m, steps{executeSQLFile("032-work-item-revisions.sql")})
+
+	// Version 33
+	m = append(m, steps{executeSQLFile("033-work-item-events.sql")})
+
+	// Version 34
+	m = append(m, steps{executeSQLFile("034-change-events.sql")})
+
+	// Version 35
+	m = append(m, steps{executeSQLFile("035-outbox.sql")})
+
+	// Version 36
+	m = append(m, steps{executeSQLFile("036-typeahead.sql")})
+
+	// Version 37
+	m = append(m, steps{executeSQLFile("037-favorites.sql")})
+
+	// Version 38
+	m = append(m, steps{executeSQLFile("038-dashboards.sql")})
+
+	// Version 39
+	m = append(m, steps{executeSQLFile("039-state-snapshots.sql")})
+
+	// Version 40
+	m = append(m, steps{executeSQLFile("040-link-type-on-delete.sql")})
+
+	// Version 41
+	m = append(m, steps{executeSQLFile("041-work-item-trash.sql")})
+
+	// Version 42
+	m = append(m, steps{executeSQLFile("042-archive.sql")})
+
+	// Version 43
+	m = append(m, steps{executeSQLFile("043-partition-work-item-events.sql")})
+
+	// Version 44
+	m = append(m, steps{executeSQLFile("044-retention-policies.sql")})
+
+	// Version 45
+	m = append(m, steps{executeSQLFile("045-backups.sql")})
+
+	// Version 46
+	m = append(m, steps{executeSQLFile("046-schema-compatibility.sql")})
+
+	// Version 47
+	m = append(m, steps{executeSQLFile("047-work-item-type-versions.sql")})
+
+	// Version 48
+	m = append(m, steps{executeSQLFile("048-translations.sql")})
+
+	// Version 49
+	m = append(m, steps{executeSQLFile("049-identity-timezones.sql")})
+
+	// Version 50
+	m = append(m, steps{executeSQLFile("050-calendar-feeds.sql")})
+
+	// Version 51
+	m = append(m, steps{executeSQLFile("051-chat-integrations.sql")})
+
+	// Version 52
+	m = append(m, steps{executeSQLFile("052-chat-commands.sql")})
+
+	// Version 53
+	m = append(m, steps{executeSQLFile("053-automation-rules.sql")})
+
+	// Version 54
+	m = append(m, steps{executeSQLFile("054-assignment-rules.sql")})
+
+	// Version 55
+	m = append(m, steps{executeSQLFile("055-escalation-policies.sql")})
+
+	// Version 56
+	m = append(m, steps{executeSQLFile("056-approvals.sql")})
+
+	// Version 57
+	m = append(m, steps{executeSQLFile("057-type-hierarchies.sql")})
+
+	// Version 58
+	m = append(m, steps{executeSQLFile("058-portfolios.sql")})
+
+	// Version 59
+	m = append(m, steps{executeSQLFile("059-work-item-keys.sql")})
+
+	// Version 60
+	m = append(m, steps{executeSQLFile("060-redirects.sql")})
+
+	// Version 61
+	m = append(m, steps{executeSQLFile("061-share-links.sql")})
+
+	// Version 62
+	m = append(m, steps{executeSQLFile("062-work-item-drafts.sql")})
+
+	// Version 63
+	m = append(m, steps{executeSQLFile("063-work-item-autosaves.sql")})
+
+	// Version 64
+	m = append(m, steps{executeSQLFile("064-attachments.sql")})
+
+	// Version 65
+	m = append(m, steps{executeSQLFile("065-attachment-usage.sql")})
+
+	// Version 66
+	m = append(m, steps{executeSQLFile("066-push-devices.sql")})
+
+	// Version 67
+	m = append(m, steps{executeSQLFile("067-notification-preferences.sql")})
+
+	// Version 68
+	m = append(m, steps{executeSQLFile("068-notification-inbox.sql")})
+
+	// Version 69
+	m = append(m, steps{executeSQLFile("069-notification-mutes.sql")})
+
+	// Version 70
+	m = append(m, steps{executeSQLFile("070-notification-email-templates.sql")})
+
+	// Version 71
+	m = append(m, steps{executeSQLFile("071-branding.sql")})
+
+	// Version 72
+	m = append(m, steps{executeSQLFile("072-intake.sql")})
+
+	// Version 73
+	m = append(m, steps{executeSQLFile("073-intake-embedding.sql")})
+
+	// Version 74
+	m = append(m, steps{executeSQLFile("074-work-item-link-annotations.sql")})
+
+	// Version 75
+	m = append(m, steps{executeSQLFile("075-process-templates.sql")})
+
+	// Version 76
+	m = append(m, steps{executeSQLFile("076-project-settings.sql")})
+
+	// Version 77
+	m = append(m, steps{executeSQLFile("077-impersonations.sql")})
+
+	// Version 78
+	m = append(m, steps{executeSQLFile("078-sessions.sql")})
+
+	// Version 79
+	m = append(m, steps{executeSQLFile("079-session-cutoffs.sql")})
+
+	// Version 80
+	m = append(m, steps{executeSQLFile("080-job-locks.sql")})
+
+	// Version 81
+	m = append(m, steps{executeSQLFile("081-outbox-deliveries.sql")})
+
+	// Version 82
+	m = append(m, steps{executeSQLFile("082-attachment-encryption.sql")})
+
 	// Version N
 	//
 	// In order to add an upgrade, simply append an array of MigrationFunc to the
@@ -194,8 +430,20 @@ func migrateToNextVersion(tx *sql.Tx, nextVersion *int64, m migrations) error {
 		}
 	}
 
-	if _, err := tx.Exec("INSERT INTO version(version) VALUES($1)", *nextVersion); err != nil {
-		return fmt.Errorf("Failed to update DB to version %d: %s\n", *nextVersion, err)
+	if *nextVersion < compatibilityVersion {
+		if _, err := tx.Exec("INSERT INTO version(version) VALUES($1)", *nextVersion); err != nil {
+			return fmt.Errorf("Failed to update DB to version %d: %s\n", *nextVersion, err)
+		}
+	} else {
+		compatibleFrom := *nextVersion
+		if backwardCompatible[*nextVersion] {
+			if err := tx.QueryRow("SELECT coalesce(max(compatible_from), $1) FROM version WHERE version = $1", currentVersion).Scan(&compatibleFrom); err != nil {
+				return fmt.Errorf("Failed to determine the compatibility of version %d: %s\n", currentVersion, err)
+			}
+		}
+		if _, err := tx.Exec("INSERT INTO version(version, compatible_from) VALUES($1, $2)", *nextVersion, compatibleFrom); err != nil {
+			return fmt.Errorf("Failed to update DB to version %d: %s\n", *nextVersion, err)
+		}
 	}
 
 	log.Printf("Successfully updated DB to version %d\n", *nextVersion)
@@ -231,6 +479,54 @@ func getCurrentVersion(db *sql.Tx) (int64, error) {
 	return current, nil
 }
 
+// IncompatibleSchemaError means that the database was migrated by a newer
+// version of the code to a schema this code can't run on
+type IncompatibleSchemaError struct {
+	// Current is the version of the database
+	Current int64
+	// CompatibleFrom is the oldest version whose code can run on it
+	CompatibleFrom int64
+	// Latest is the version this code migrates to
+	Latest int64
+}
+
+// Error implements the error interface
+func (err IncompatibleSchemaError) Error() string {
+	return fmt.Sprintf("the database is at schema version %d which requires the code of version %d or later, this code is at version %d", err.Current, err.CompatibleFrom, err.Latest)
+}
+
+// CheckCompatibility checks that this code can run on the schema of the
+// database, meant to be called after Migrate. During a rolling upgrade the
+// instances still running the old code find the database migrated by the
+// new one; that's fine as long as all migrations they don't know are
+// backward compatible. Returns an IncompatibleSchemaError if they aren't.
+func CheckCompatibility(db *sql.DB) error {
+	var current int64
+	var compatibleFrom sql.NullInt64
+	err := db.QueryRow("SELECT version, compatible_from FROM version ORDER BY version DESC LIMIT 1").Scan(&current, &compatibleFrom)
+	if err != nil {
+		return fmt.Errorf("Failed to scan the current version in table \"version\": %s\n", err)
+	}
+	return checkCompatibility(current, compatibleFrom, int64(len(getMigrations())-1))
+}
+
+// checkCompatibility returns an IncompatibleSchemaError if the code at
+// version latest can't run on the schema at version current, whose oldest
+// compatible code is at version compatibleFrom
+func checkCompatibility(current int64, compatibleFrom sql.NullInt64, latest int64) error {
+	if current <= latest {
+		return nil
+	}
+	if !compatibleFrom.Valid || compatibleFrom.Int64 > latest {
+		from := current
+		if compatibleFrom.Valid {
+			from = compatibleFrom.Int64
+		}
+		return IncompatibleSchemaError{Current: current, CompatibleFrom: from, Latest: latest}
+	}
+	return nil
+}
+
 // BootstrapWorkItemLinking makes sure the database is populated with the correct work item link stuff (e.g. category and some basic types)
 func BootstrapWorkItemLinking(ctx context.Context, linkCatRepo *link.GormWorkItemLinkCategoryRepository, linkTypeRepo *link.GormWorkItemLinkTypeRepository) error {
 	if err := createOrUpdateWorkItemLinkCategory(ctx, linkCatRepo, link.SystemWorkItemLinkCategorySystem, "The system category is reserved for link types that are to be manipulated by the system only."); err != nil {
@@ -286,7 +582,7 @@ func createOrUpdateWorkItemLinkType(ctx context.Context, linkCatRepo *link.GormW
 
 	switch err.(type) {
 	case errors.NotFoundError:
-		_, err := linkTypeRepo.Create(ctx, lt.Name, lt.Description, lt.SourceTypeName, lt.TargetTypeName, lt.ForwardName, lt.ReverseName, lt.Topology, lt.LinkCategoryID)
+		_, err := linkTypeRepo.Create(ctx, lt.Name, lt.Description, lt.SourceTypeName, lt.TargetTypeName, lt.ForwardName, lt.ReverseName, lt.Topology, lt.OnDelete, lt.LinkCategoryID)
 		if err != nil {
 			return err
 		}
@@ -294,6 +590,7 @@ func createOrUpdateWorkItemLinkType(ctx context.Context, linkCatRepo *link.GormW
 		log.Printf("Work item link type %v exists, will update/overwrite all fields", name)
 		lt.ID = linkType.ID
 		lt.Version = linkType.Version
+		lt.OnDelete = linkType.OnDelete
 		_, err = linkTypeRepo.Save(ctx, link.ConvertLinkTypeFromModel(lt))
 		return err
 	}
@@ -337,7 +634,18 @@ func createOrUpdateSystemPlannerItemType(ctx context.Context, witr *workitem.Gor
 		workitem.SystemCreator:      app.FieldDefinition{Type: &app.FieldType{Kind: "user"}, Required: true},
 		workitem.SystemRemoteItemID: app.FieldDefinition{Type: &app.FieldType{Kind: "string"}, Required: false},
 		workitem.SystemCreatedAt:    app.FieldDefinition{Type: &app.FieldType{Kind: "instant"}, Required: false},
+		workitem.SystemDueDate:      app.FieldDefinition{Type: &app.FieldType{Kind: "instant"}, Required: false},
 		workitem.SystemIteration:    app.FieldDefinition{Type: &app.FieldType{Kind: "iteration"}, Required: false},
+		workitem.SystemRelease:      app.FieldDefinition{Type: &app.FieldType{Kind: "release"}, Required: false},
+		workitem.SystemProject:      app.FieldDefinition{Type: &app.FieldType{Kind: "project"}, Required: false},
+		workitem.SystemConfidential: app.FieldDefinition{Type: &app.FieldType{Kind: "boolean"}, Required: false},
+		// set for the contributions of first-time contributors to public projects, see package moderation
+		workitem.SystemPendingReview: app.FieldDefinition{Type: &app.FieldType{Kind: "boolean"}, Required: false},
+		// set for work items saved before they are submitted, see workitem.DraftRepository
+		workitem.SystemDraft: app.FieldDefinition{Type: &app.FieldType{Kind: "boolean"}, Required: false},
+		// the allowed values of priority and severity can be customized per project, see package fieldvalues
+		workitem.SystemPriority: app.FieldDefinition{Type: &app.FieldType{Kind: "string"}, Required: false},
+		workitem.SystemSeverity: app.FieldDefinition{Type: &app.FieldType{Kind: "string"}, Required: false},
 		workitem.SystemAssignees: app.FieldDefinition{
 			Type: &app.FieldType{
 				ComponentType: &stUser,
@@ -345,6 +653,13 @@ func createOrUpdateSystemPlannerItemType(ctx context.Context, witr *workitem.Gor
 			},
 			Required: false,
 		},
+		workitem.SystemLabels: app.FieldDefinition{
+			Type: &app.FieldType{
+				ComponentType: &stString,
+				Kind:          "list",
+			},
+			Required: false,
+		},
 		workitem.SystemState: app.FieldDefinition{
 			Type: &app.FieldType{
 				BaseType: &stString,
@@ -355,6 +670,7 @@ func createOrUpdateSystemPlannerItemType(ctx context.Context, witr *workitem.Gor
 					workitem.SystemStateInProgress,
 					workitem.SystemStateResolved,
 					workitem.SystemStateClosed,
+					workitem.SystemStateInactive,
 				},
 			},
 			Required: true,
@@ -400,11 +716,9 @@ func createOrUpdateType(typeName string, extendedTypeName *string, fields map[st
 		if err != nil {
 			return err
 		}
-		wit.Fields = convertedFields
+		// changed definitions make a new version of the type
 		wit.Path = path
-
-		db = db.Save(wit)
-		return db.Error
+		return witr.Evolve(ctx, wit, convertedFields)
 	}
 	return nil
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/almighty/almighty-core/resource"
 	_ "github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestConcurrentMigrations(t *testing.T) {
@@ -38,3 +39,38 @@ func TestConcurrentMigrations(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestCheckCompatibility(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	// the database is at the version of the code or older
+	assert.Nil(t, checkCompatibility(45, sql.NullInt64{}, 45))
+	assert.Nil(t, checkCompatibility(46, sql.NullInt64{Int64: 45, Valid: true}, 47))
+	// newer but backward compatible
+	assert.Nil(t, checkCompatibility(48, sql.NullInt64{Int64: 46, Valid: true}, 47))
+	assert.Nil(t, checkCompatibility(48, sql.NullInt64{Int64: 47, Valid: true}, 47))
+	// newer and breaking
+	err := checkCompatibility(48, sql.NullInt64{Int64: 48, Valid: true}, 47)
+	assert.Equal(t, IncompatibleSchemaError{Current: 48, CompatibleFrom: 48, Latest: 47}, err)
+	// newer without compatibility recorded
+	err = checkCompatibility(46, sql.NullInt64{}, 45)
+	assert.Equal(t, IncompatibleSchemaError{Current: 46, CompatibleFrom: 46, Latest: 45}, err)
+}
+
+func TestCompatibleFrom(t *testing.T) {
+	resource.Require(t, resource.Database)
+
+	if err := configuration.Setup(""); err != nil {
+		panic(fmt.Errorf("Failed to setup the configuration: %s", err.Error()))
+	}
+	db, err := sql.Open("postgres", configuration.GetPostgresConfigString())
+	require.Nil(t, err)
+	defer db.Close()
+	require.Nil(t, Migrate(db))
+	require.Nil(t, CheckCompatibility(db))
+
+	var compatibleFrom sql.NullInt64
+	err = db.QueryRow("SELECT compatible_from FROM version WHERE version = $1", compatibilityVersion).Scan(&compatibleFrom)
+	require.Nil(t, err)
+	assert.Equal(t, sql.NullInt64{Int64: compatibilityVersion - 1, Valid: true}, compatibleFrom)
+}
@@ -0,0 +1,19 @@
+-- remote links point from a work item to an arbitrary external resource
+-- (e.g. a pull request, a document or a CI build)
+
+CREATE TABLE remote_links (
+    created_at      timestamp with time zone,
+    updated_at      timestamp with time zone,
+    deleted_at      timestamp with time zone DEFAULT NULL,
+
+    id              uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    version         integer DEFAULT 0 NOT NULL,
+
+    work_item_id    bigint REFERENCES work_items(id) ON DELETE CASCADE,
+    url             text NOT NULL CHECK(url <> ''),
+    title           text,
+    icon_url        text,
+    relationship    text NOT NULL
+);
+
+CREATE INDEX remote_links_work_item_id_idx ON remote_links USING btree (work_item_id);
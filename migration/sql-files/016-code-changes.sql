@@ -0,0 +1,22 @@
+-- code changes associate commits and pull/merge requests reported by a
+-- repository webhook with the work items referenced in their messages
+
+CREATE TABLE code_changes (
+    created_at      timestamp with time zone,
+    updated_at      timestamp with time zone,
+    deleted_at      timestamp with time zone DEFAULT NULL,
+
+    id              uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+
+    work_item_id    bigint REFERENCES work_items(id) ON DELETE CASCADE,
+    kind            text NOT NULL,
+    provider        text NOT NULL,
+    repository      text,
+    ref             text NOT NULL,
+    title           text,
+    url             text NOT NULL CHECK(url <> ''),
+    status          text NOT NULL
+);
+
+CREATE INDEX code_changes_work_item_id_idx ON code_changes USING btree (work_item_id);
+CREATE UNIQUE INDEX code_changes_work_item_id_url_idx ON code_changes (work_item_id, url) WHERE deleted_at IS NULL;
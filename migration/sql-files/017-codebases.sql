@@ -0,0 +1,43 @@
+-- codebases are the source repositories (and branches) a project is built from
+
+CREATE TABLE codebases (
+    created_at      timestamp with time zone,
+    updated_at      timestamp with time zone,
+    deleted_at      timestamp with time zone DEFAULT NULL,
+
+    id              uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+
+    project_id      uuid REFERENCES projects(id) ON DELETE CASCADE,
+    url             text NOT NULL CHECK(url <> ''),
+    branch          text NOT NULL CHECK(branch <> '')
+);
+
+CREATE INDEX codebases_project_id_idx ON codebases USING btree (project_id);
+CREATE UNIQUE INDEX codebases_project_id_url_branch_idx ON codebases (project_id, url, branch) WHERE deleted_at IS NULL;
+
+-- work items are associated with the codebases their fix goes into
+
+CREATE TABLE work_item_codebases (
+    created_at      timestamp with time zone,
+
+    work_item_id    bigint REFERENCES work_items(id) ON DELETE CASCADE,
+    codebase_id     uuid REFERENCES codebases(id) ON DELETE CASCADE,
+    PRIMARY KEY (work_item_id, codebase_id)
+);
+
+-- builds report the build and deploy status of a codebase as pushed from CI
+
+CREATE TABLE codebase_builds (
+    created_at      timestamp with time zone,
+    updated_at      timestamp with time zone,
+    deleted_at      timestamp with time zone DEFAULT NULL,
+
+    id              uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+
+    codebase_id     uuid REFERENCES codebases(id) ON DELETE CASCADE,
+    revision        text,
+    status          text NOT NULL,
+    url             text
+);
+
+CREATE INDEX codebase_builds_codebase_id_idx ON codebase_builds USING btree (codebase_id, created_at);
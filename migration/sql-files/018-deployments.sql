@@ -0,0 +1,24 @@
+-- deployments record which work items were shipped to an environment as
+-- reported by CD pipelines
+
+CREATE TABLE deployments (
+    created_at      timestamp with time zone,
+    updated_at      timestamp with time zone,
+    deleted_at      timestamp with time zone DEFAULT NULL,
+
+    id              uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+
+    environment     text NOT NULL CHECK(environment <> ''),
+    revision        text,
+    url             text
+);
+
+CREATE INDEX deployments_environment_idx ON deployments USING btree (environment);
+
+CREATE TABLE deployment_work_items (
+    deployment_id   uuid REFERENCES deployments(id) ON DELETE CASCADE,
+    work_item_id    bigint REFERENCES work_items(id) ON DELETE CASCADE,
+    PRIMARY KEY (deployment_id, work_item_id)
+);
+
+CREATE INDEX deployment_work_items_work_item_id_idx ON deployment_work_items USING btree (work_item_id);
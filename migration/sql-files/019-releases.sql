@@ -0,0 +1,23 @@
+-- releases group the work items of a project that ship together. Once a
+-- release is published its notes and completion are frozen.
+
+CREATE TABLE releases (
+    created_at      timestamp with time zone,
+    updated_at      timestamp with time zone,
+    deleted_at      timestamp with time zone DEFAULT NULL,
+
+    id              uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+
+    project_id      uuid REFERENCES projects(id) ON DELETE CASCADE,
+    version         text NOT NULL CHECK(version <> ''),
+    target_date     timestamp with time zone,
+    status          text NOT NULL,
+
+    published_at    timestamp with time zone,
+    notes           text,
+    total_count     integer DEFAULT 0 NOT NULL,
+    closed_count    integer DEFAULT 0 NOT NULL
+);
+
+CREATE INDEX releases_project_id_idx ON releases USING btree (project_id);
+CREATE UNIQUE INDEX releases_project_id_version_idx ON releases (project_id, version) WHERE deleted_at IS NULL;
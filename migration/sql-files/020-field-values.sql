@@ -0,0 +1,18 @@
+-- field_values holds the ordered list of allowed values of an enum-like work
+-- item field (e.g. priority or severity) customized for a project. The order
+-- of the values defines how work items are sorted by that field.
+
+CREATE TABLE field_values (
+    created_at      timestamp with time zone,
+    updated_at      timestamp with time zone,
+    deleted_at      timestamp with time zone DEFAULT NULL,
+
+    id              uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+
+    project_id      uuid REFERENCES projects(id) ON DELETE CASCADE,
+    field_name      text NOT NULL,
+    field_values    jsonb NOT NULL,
+    version         integer DEFAULT 0 NOT NULL
+);
+
+CREATE UNIQUE INDEX field_values_project_id_field_name_idx ON field_values (project_id, field_name) WHERE deleted_at IS NULL;
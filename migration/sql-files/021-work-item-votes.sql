@@ -0,0 +1,16 @@
+-- work_item_votes records which identities upvoted a work item, every
+-- identity can vote for a work item once
+
+CREATE TABLE work_item_votes (
+    created_at      timestamp with time zone,
+    updated_at      timestamp with time zone,
+    deleted_at      timestamp with time zone DEFAULT NULL,
+
+    id              uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+
+    work_item_id    bigint REFERENCES work_items(id) ON DELETE CASCADE,
+    identity_id     uuid REFERENCES identities(id) ON DELETE CASCADE
+);
+
+CREATE INDEX work_item_votes_work_item_id_idx ON work_item_votes USING btree (work_item_id);
+CREATE UNIQUE INDEX work_item_votes_work_item_id_identity_id_idx ON work_item_votes (work_item_id, identity_id) WHERE deleted_at IS NULL;
@@ -0,0 +1,17 @@
+-- comment_reactions records the emoji reactions of identities to comments,
+-- every identity can react to a comment once per emoji
+
+CREATE TABLE comment_reactions (
+    created_at      timestamp with time zone,
+    updated_at      timestamp with time zone,
+    deleted_at      timestamp with time zone DEFAULT NULL,
+
+    id              uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+
+    comment_id      uuid REFERENCES comments(id) ON DELETE CASCADE,
+    identity_id     uuid REFERENCES identities(id) ON DELETE CASCADE,
+    emoji           text NOT NULL
+);
+
+CREATE INDEX comment_reactions_comment_id_idx ON comment_reactions USING btree (comment_id);
+CREATE UNIQUE INDEX comment_reactions_comment_id_identity_id_emoji_idx ON comment_reactions (comment_id, identity_id, emoji) WHERE deleted_at IS NULL;
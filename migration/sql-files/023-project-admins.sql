@@ -0,0 +1,16 @@
+-- project_admins records the identities administrating a project, project
+-- admins can see all confidential work items of the project
+
+CREATE TABLE project_admins (
+    created_at      timestamp with time zone,
+    updated_at      timestamp with time zone,
+    deleted_at      timestamp with time zone DEFAULT NULL,
+
+    id              uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+
+    project_id      uuid REFERENCES projects(id) ON DELETE CASCADE,
+    identity_id     uuid REFERENCES identities(id) ON DELETE CASCADE
+);
+
+CREATE INDEX project_admins_identity_id_idx ON project_admins USING btree (identity_id);
+CREATE UNIQUE INDEX project_admins_project_id_identity_id_idx ON project_admins (project_id, identity_id) WHERE deleted_at IS NULL;
@@ -0,0 +1,5 @@
+-- anonymized identities are replaced by this tombstone identity in all
+-- references to keep work items and comments intact
+
+INSERT INTO identities (id, created_at, updated_at, full_name, image_url)
+    VALUES ('85592b03-e212-4be7-a564-f1cba7eec52f', now(), now(), 'Deleted user', '');
@@ -0,0 +1,6 @@
+-- everybody can contribute to public projects, the contributions of
+-- first-time contributors are held for review by the project admins
+
+ALTER TABLE projects ADD COLUMN public boolean DEFAULT false NOT NULL;
+
+ALTER TABLE comments ADD COLUMN pending_review boolean DEFAULT false NOT NULL;
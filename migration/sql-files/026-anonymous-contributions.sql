@@ -0,0 +1,7 @@
+-- anonymous contributions to public projects are created by this identity,
+-- projects can require anonymous contributors to solve a challenge
+
+INSERT INTO identities (id, created_at, updated_at, full_name, image_url)
+    VALUES ('0fd02721-9ecc-4a65-b292-758a9e3f02e8', now(), now(), 'Anonymous', '');
+
+ALTER TABLE projects ADD COLUMN challenge text DEFAULT '' NOT NULL;
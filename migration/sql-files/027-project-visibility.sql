@@ -0,0 +1,5 @@
+-- private projects can only be read by their admins, internal projects by
+-- all logged in users and public projects without logging in
+
+ALTER TABLE projects ADD COLUMN visibility text DEFAULT 'public' NOT NULL
+    CONSTRAINT projects_visibility_check CHECK (visibility IN ('private', 'internal', 'public'));
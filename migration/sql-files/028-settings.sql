@@ -0,0 +1,10 @@
+-- runtime settings override tunable configuration variables without a
+-- restart, see configuration.Reload
+
+CREATE TABLE settings (
+    name text PRIMARY KEY,
+    value text NOT NULL,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone
+);
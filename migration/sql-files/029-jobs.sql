@@ -0,0 +1,21 @@
+-- jobs is the persistent queue of background work, failed jobs are
+-- retried with a backoff until they run out of attempts and are dead
+
+CREATE TABLE jobs (
+    created_at      timestamp with time zone,
+    updated_at      timestamp with time zone,
+    deleted_at      timestamp with time zone DEFAULT NULL,
+
+    id              uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+
+    kind            text NOT NULL,
+    payload         jsonb NOT NULL DEFAULT 'null',
+    status          text NOT NULL CONSTRAINT jobs_status_check CHECK (status IN ('pending', 'running', 'succeeded', 'dead')),
+    attempts        integer NOT NULL DEFAULT 0,
+    max_attempts    integer NOT NULL,
+    run_at          timestamp with time zone NOT NULL DEFAULT now(),
+    locked_at       timestamp with time zone,
+    last_error      text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX jobs_status_run_at_idx ON jobs USING btree (status, run_at) WHERE deleted_at IS NULL;
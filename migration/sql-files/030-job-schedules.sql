@@ -0,0 +1,6 @@
+-- jobs enqueued by a schedule remember its name, every server enqueues the
+-- due runs of the schedules and the unique index keeps only one job per run
+
+ALTER TABLE jobs ADD COLUMN schedule text;
+
+CREATE UNIQUE INDEX jobs_schedule_run_at_idx ON jobs USING btree (schedule, run_at) WHERE schedule IS NOT NULL;
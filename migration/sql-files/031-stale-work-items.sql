@@ -0,0 +1,18 @@
+-- projects can flag work items without activity as stale and move them to
+-- the inactive state after a grace period, see package stale
+
+CREATE TABLE stale_policies (
+    created_at      timestamp with time zone,
+    updated_at      timestamp with time zone,
+    deleted_at      timestamp with time zone,
+
+    project_id      uuid PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+    inactive_days   integer NOT NULL CONSTRAINT stale_policies_inactive_days_check CHECK (inactive_days > 0),
+    grace_days      integer CONSTRAINT stale_policies_grace_days_check CHECK (grace_days >= 0)
+);
+
+CREATE TABLE stale_work_items (
+    work_item_id    bigint PRIMARY KEY REFERENCES work_items(id) ON DELETE CASCADE,
+    project_id      uuid NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    flagged_at      timestamp with time zone NOT NULL
+);
@@ -0,0 +1,19 @@
+-- work_item_revisions records the fields of every version of a work item,
+-- existing work items start with a revision of their current version
+
+CREATE TABLE work_item_revisions (
+    id              uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    created_at      timestamp with time zone NOT NULL,
+
+    kind            text NOT NULL CONSTRAINT work_item_revisions_kind_check CHECK (kind IN ('create', 'update', 'delete')),
+    work_item_id    bigint NOT NULL REFERENCES work_items(id) ON DELETE CASCADE,
+    version         integer NOT NULL,
+    type            text NOT NULL,
+    fields          jsonb NOT NULL,
+    modifier_id     uuid
+);
+
+CREATE INDEX work_item_revisions_work_item_id_created_at_idx ON work_item_revisions USING btree (work_item_id, created_at);
+
+INSERT INTO work_item_revisions (created_at, kind, work_item_id, version, type, fields)
+    SELECT COALESCE(updated_at, now()), 'create', id, version, type, fields FROM work_items WHERE deleted_at IS NULL;
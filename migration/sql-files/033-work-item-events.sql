@@ -0,0 +1,10 @@
+-- work item mutations are recorded as events, work_items is the projection
+-- of the latest event of each work item and can be rebuilt from the events
+
+ALTER TABLE work_item_revisions RENAME TO work_item_events;
+ALTER TABLE work_item_events RENAME CONSTRAINT work_item_revisions_kind_check TO work_item_events_kind_check;
+ALTER INDEX work_item_revisions_work_item_id_created_at_idx RENAME TO work_item_events_work_item_id_created_at_idx;
+
+-- sequence orders the events of all work items for change feeds
+ALTER TABLE work_item_events ADD COLUMN sequence bigserial NOT NULL;
+CREATE UNIQUE INDEX work_item_events_sequence_idx ON work_item_events USING btree (sequence);
@@ -0,0 +1,70 @@
+-- change_events is the outbox of the change data capture feed: triggers
+-- record every change of work items, links and comments in the transaction
+-- making the change, the relay publishes them after the commit
+
+CREATE TABLE change_events (
+    sequence bigserial PRIMARY KEY,
+    id uuid NOT NULL DEFAULT uuid_generate_v4(),
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    entity text NOT NULL,
+    op text NOT NULL CHECK (op IN ('create', 'update', 'delete')),
+    entity_id text NOT NULL,
+    data jsonb NOT NULL,
+    published_at timestamp with time zone
+);
+
+CREATE UNIQUE INDEX change_events_id_idx ON change_events USING btree (id);
+CREATE INDEX change_events_pending_idx ON change_events USING btree (sequence) WHERE published_at IS NULL;
+CREATE INDEX change_events_created_at_idx ON change_events USING btree (created_at);
+
+--##########################################################################
+-- Record the change of a row as an event of the entity given as argument.
+-- Soft deleting a row is a delete, resurrecting it a create.
+--##########################################################################
+
+CREATE FUNCTION record_change_event() RETURNS trigger AS $record_change_event$
+    DECLARE
+        change_op text;
+        change_data jsonb;
+    BEGIN
+        IF TG_OP = 'DELETE' THEN
+            IF OLD.deleted_at IS NOT NULL THEN
+                RETURN NULL;
+            END IF;
+            change_op := 'delete';
+            change_data := to_jsonb(OLD);
+        ELSE
+            change_data := to_jsonb(NEW);
+            IF NEW.deleted_at IS NOT NULL THEN
+                IF TG_OP = 'UPDATE' AND OLD.deleted_at IS NOT NULL THEN
+                    RETURN NULL;
+                END IF;
+                change_op := 'delete';
+            ELSIF TG_OP = 'INSERT' OR OLD.deleted_at IS NOT NULL THEN
+                change_op := 'create';
+            ELSE
+                change_op := 'update';
+            END IF;
+        END IF;
+        INSERT INTO change_events (entity, op, entity_id, data) VALUES (TG_ARGV[0], change_op, change_data->>'id', change_data);
+        RETURN NULL;
+    END;
+$record_change_event$ LANGUAGE plpgsql;
+
+CREATE TRIGGER record_change_event_work_items_trigger
+AFTER INSERT OR UPDATE OR DELETE
+ON work_items
+FOR EACH ROW
+EXECUTE PROCEDURE record_change_event('workitem');
+
+CREATE TRIGGER record_change_event_work_item_links_trigger
+AFTER INSERT OR UPDATE OR DELETE
+ON work_item_links
+FOR EACH ROW
+EXECUTE PROCEDURE record_change_event('link');
+
+CREATE TRIGGER record_change_event_comments_trigger
+AFTER INSERT OR UPDATE OR DELETE
+ON comments
+FOR EACH ROW
+EXECUTE PROCEDURE record_change_event('comment');
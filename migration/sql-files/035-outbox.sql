@@ -0,0 +1,64 @@
+-- outbox_records holds the records to deliver to the sinks (webhooks, message
+-- bus) once the transaction writing them commits. The change feed triggers
+-- write their events to the outbox too, its payload is the change message.
+
+CREATE TABLE outbox_records (
+    sequence bigserial PRIMARY KEY,
+    id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    topic text NOT NULL,
+    key text NOT NULL,
+    payload jsonb NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    last_error text,
+    published_at timestamp with time zone
+);
+
+CREATE UNIQUE INDEX outbox_records_id_idx ON outbox_records USING btree (id);
+CREATE INDEX outbox_records_pending_idx ON outbox_records USING btree (sequence) WHERE published_at IS NULL;
+CREATE INDEX outbox_records_created_at_idx ON outbox_records USING btree (created_at);
+
+CREATE OR REPLACE FUNCTION record_change_event() RETURNS trigger AS $record_change_event$
+    DECLARE
+        change_op text;
+        change_data jsonb;
+        record_id uuid := uuid_generate_v4();
+        record_sequence bigint := nextval('outbox_records_sequence_seq');
+    BEGIN
+        IF TG_OP = 'DELETE' THEN
+            IF OLD.deleted_at IS NOT NULL THEN
+                RETURN NULL;
+            END IF;
+            change_op := 'delete';
+            change_data := to_jsonb(OLD);
+        ELSE
+            change_data := to_jsonb(NEW);
+            IF NEW.deleted_at IS NOT NULL THEN
+                IF TG_OP = 'UPDATE' AND OLD.deleted_at IS NOT NULL THEN
+                    RETURN NULL;
+                END IF;
+                change_op := 'delete';
+            ELSIF TG_OP = 'INSERT' OR OLD.deleted_at IS NOT NULL THEN
+                change_op := 'create';
+            ELSE
+                change_op := 'update';
+            END IF;
+        END IF;
+        INSERT INTO outbox_records (sequence, id, topic, key, payload) VALUES (
+            record_sequence, record_id, TG_ARGV[0], change_data->>'id',
+            jsonb_build_object('version', 1, 'id', record_id, 'sequence', record_sequence, 'entity', TG_ARGV[0],
+                'op', change_op, 'entity_id', change_data->>'id', 'time', now(), 'data', change_data));
+        RETURN NULL;
+    END;
+$record_change_event$ LANGUAGE plpgsql;
+
+-- move the events not published yet, the sequence continues the one of the
+-- change events so consumers keep seeing increasing sequence numbers
+INSERT INTO outbox_records (sequence, id, created_at, topic, key, payload)
+    SELECT sequence, id, created_at, entity, entity_id,
+        jsonb_build_object('version', 1, 'id', id, 'sequence', sequence, 'entity', entity,
+            'op', op, 'entity_id', entity_id, 'time', created_at, 'data', data)
+    FROM change_events WHERE published_at IS NULL;
+SELECT setval('outbox_records_sequence_seq', (SELECT COALESCE(max(sequence), 0) + 1 FROM change_events), false);
+
+DROP TABLE change_events;
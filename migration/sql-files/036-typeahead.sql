@@ -0,0 +1,7 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- trigram indexes serve the substring and prefix matches of the typeahead
+CREATE INDEX work_items_title_trgm_idx ON work_items USING gin ((fields->>'system.title') gin_trgm_ops) WHERE deleted_at IS NULL;
+CREATE INDEX work_items_id_text_idx ON work_items ((id::text) text_pattern_ops) WHERE deleted_at IS NULL;
+CREATE INDEX identities_full_name_trgm_idx ON identities USING gin (full_name gin_trgm_ops) WHERE deleted_at IS NULL;
+CREATE INDEX users_email_trgm_idx ON users USING gin (email gin_trgm_ops) WHERE deleted_at IS NULL;
@@ -0,0 +1,21 @@
+-- work_item_views records when each identity last viewed a work item, only
+-- the latest views of every identity are kept
+
+CREATE TABLE work_item_views (
+    identity_id     uuid NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+    work_item_id    bigint NOT NULL REFERENCES work_items(id) ON DELETE CASCADE,
+    viewed_at       timestamp with time zone NOT NULL,
+    views           integer NOT NULL DEFAULT 1,
+    PRIMARY KEY (identity_id, work_item_id)
+);
+
+CREATE INDEX work_item_views_identity_id_viewed_at_idx ON work_item_views USING btree (identity_id, viewed_at DESC);
+
+-- work_item_pins records the work items identities pinned as their favorites
+
+CREATE TABLE work_item_pins (
+    identity_id     uuid NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+    work_item_id    bigint NOT NULL REFERENCES work_items(id) ON DELETE CASCADE,
+    created_at      timestamp with time zone NOT NULL,
+    PRIMARY KEY (identity_id, work_item_id)
+);
@@ -0,0 +1,21 @@
+-- dashboards are either owned by an identity or shared in a project, their
+-- widgets are stored as a JSON array
+
+CREATE TABLE dashboards (
+    created_at      timestamp with time zone,
+    updated_at      timestamp with time zone,
+    deleted_at      timestamp with time zone DEFAULT NULL,
+
+    id              uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+
+    name            text NOT NULL CONSTRAINT dashboards_name_check CHECK (name <> ''),
+    owner_id        uuid REFERENCES identities(id) ON DELETE CASCADE,
+    project_id      uuid REFERENCES projects(id) ON DELETE CASCADE,
+    widgets         jsonb NOT NULL DEFAULT '[]',
+    version         integer NOT NULL DEFAULT 0,
+
+    CONSTRAINT dashboards_scope_check CHECK ((owner_id IS NULL) <> (project_id IS NULL))
+);
+
+CREATE INDEX dashboards_owner_id_idx ON dashboards USING btree (owner_id) WHERE deleted_at IS NULL;
+CREATE INDEX dashboards_project_id_idx ON dashboards USING btree (project_id) WHERE deleted_at IS NULL;
@@ -0,0 +1,10 @@
+-- work_item_state_snapshots holds the number of work items per state of every
+-- project at the end of each day, the source of the cumulative flow diagrams
+
+CREATE TABLE work_item_state_snapshots (
+    project_id  uuid NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    day         date NOT NULL,
+    state       text NOT NULL,
+    count       integer NOT NULL,
+    PRIMARY KEY (project_id, day, state)
+);
@@ -0,0 +1,5 @@
+-- on_delete decides what happens to the links of a type when a work item is
+-- deleted, the links of the existing types keep being detached
+
+ALTER TABLE work_item_link_types ADD COLUMN on_delete text NOT NULL DEFAULT 'detach'
+    CONSTRAINT work_item_link_types_on_delete_check CHECK (on_delete IN ('detach', 'cascade', 'block'));
@@ -0,0 +1,32 @@
+-- deleted work items stay in the trash of their project until they are
+-- restored or purged, restoring one is recorded as its own kind of event
+
+ALTER TABLE work_item_events DROP CONSTRAINT work_item_events_kind_check;
+ALTER TABLE work_item_events ADD CONSTRAINT work_item_events_kind_check CHECK (kind IN ('create', 'update', 'delete', 'restore'));
+
+CREATE INDEX work_items_deleted_at_idx ON work_items USING btree (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- detached_by is the work item whose deletion soft deleted the link, only
+-- those links come back when a work item is restored
+ALTER TABLE work_item_links ADD COLUMN detached_by bigint;
+
+CREATE OR REPLACE FUNCTION update_WIL_after_WI() RETURNS trigger AS $update_WIL_after_WI$
+    BEGIN
+        IF NEW.deleted_at IS NOT NULL THEN
+            UPDATE work_item_links SET deleted_at = NEW.deleted_at, detached_by = NEW.id
+                WHERE NEW.id IN (source_id, target_id) AND deleted_at IS NULL;
+        ELSE
+            -- the other end and the link type must still exist and the link
+            -- must not have been created again in the meantime
+            UPDATE work_item_links l SET deleted_at = NULL, detached_by = NULL
+                WHERE NEW.id IN (l.source_id, l.target_id) AND l.deleted_at IS NOT NULL AND l.detached_by IS NOT NULL
+                    AND EXISTS (SELECT 1 FROM work_items w WHERE w.deleted_at IS NULL
+                        AND w.id = CASE WHEN l.source_id = NEW.id THEN l.target_id ELSE l.source_id END)
+                    AND EXISTS (SELECT 1 FROM work_item_link_types lt WHERE lt.id = l.link_type_id AND lt.deleted_at IS NULL)
+                    AND (l.detached_by = NEW.id OR EXISTS (SELECT 1 FROM work_items d WHERE d.id = l.detached_by AND d.deleted_at IS NULL))
+                    AND NOT EXISTS (SELECT 1 FROM work_item_links o WHERE o.deleted_at IS NULL
+                        AND o.source_id = l.source_id AND o.target_id = l.target_id AND o.link_type_id = l.link_type_id);
+        END IF;
+        RETURN NEW;
+    END;
+$update_WIL_after_WI$ LANGUAGE plpgsql;
@@ -0,0 +1,7 @@
+-- archived work items and iterations are kept but hidden from the default
+-- lists, the partial index keeps listing the active work items fast
+
+ALTER TABLE work_items ADD COLUMN archived boolean NOT NULL DEFAULT false;
+CREATE INDEX work_items_active_idx ON work_items USING btree (id) WHERE NOT archived AND deleted_at IS NULL;
+
+ALTER TABLE iterations ADD COLUMN archived_at timestamp with time zone;
@@ -0,0 +1,75 @@
+-- work_item_events is partitioned by month: every month has its own child
+-- table work_item_events_yYYYYmMM inheriting from work_item_events, the
+-- parent stays empty. Queries on the parent see all partitions and skip the
+-- ones whose check constraint excludes the range they ask for.
+
+--##########################################################################
+-- Create the partition of the month of the given time if it doesn't exist
+-- and return its name. Indexes and foreign keys aren't inherited, every
+-- partition needs its own.
+--##########################################################################
+
+CREATE FUNCTION create_work_item_events_partition(at timestamp with time zone) RETURNS text AS $create_work_item_events_partition$
+    DECLARE
+        month_start timestamp with time zone := date_trunc('month', at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC';
+        month_end timestamp with time zone := (date_trunc('month', at AT TIME ZONE 'UTC') + interval '1 month') AT TIME ZONE 'UTC';
+        partition text := 'work_item_events_' || to_char(at AT TIME ZONE 'UTC', '"y"YYYY"m"MM');
+    BEGIN
+        IF to_regclass(partition) IS NOT NULL THEN
+            RETURN partition;
+        END IF;
+        EXECUTE format('CREATE TABLE %I (CHECK (created_at >= %L AND created_at < %L)) INHERITS (work_item_events)', partition, month_start, month_end);
+        EXECUTE format('ALTER TABLE %I ADD PRIMARY KEY (id)', partition);
+        EXECUTE format('ALTER TABLE %I ADD FOREIGN KEY (work_item_id) REFERENCES work_items(id) ON DELETE CASCADE', partition);
+        EXECUTE format('CREATE UNIQUE INDEX %I ON %I USING btree (sequence)', partition || '_sequence_idx', partition);
+        EXECUTE format('CREATE INDEX %I ON %I USING btree (work_item_id, created_at)', partition || '_work_item_id_created_at_idx', partition);
+        RETURN partition;
+    END;
+$create_work_item_events_partition$ LANGUAGE plpgsql;
+
+--##########################################################################
+-- Route the events inserted into the parent to the partition of their month.
+-- The row is kept in the parent until the statement ends and then removed,
+-- a BEFORE trigger returning NULL would break INSERT ... RETURNING.
+--##########################################################################
+
+CREATE FUNCTION insert_work_item_event() RETURNS trigger AS $insert_work_item_event$
+    BEGIN
+        EXECUTE format('INSERT INTO %I SELECT ($1).*', create_work_item_events_partition(NEW.created_at)) USING NEW;
+        RETURN NEW;
+    END;
+$insert_work_item_event$ LANGUAGE plpgsql;
+
+CREATE FUNCTION remove_work_item_event_from_parent() RETURNS trigger AS $remove_work_item_event_from_parent$
+    BEGIN
+        DELETE FROM ONLY work_item_events WHERE id = NEW.id;
+        RETURN NULL;
+    END;
+$remove_work_item_event_from_parent$ LANGUAGE plpgsql;
+
+-- move the existing events to their partitions first
+DO $$
+    DECLARE
+        month timestamp with time zone;
+    BEGIN
+        FOR month IN SELECT DISTINCT date_trunc('month', created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' FROM ONLY work_item_events LOOP
+            EXECUTE format('INSERT INTO %I SELECT * FROM ONLY work_item_events WHERE date_trunc(''month'', created_at AT TIME ZONE ''UTC'') AT TIME ZONE ''UTC'' = $1',
+                create_work_item_events_partition(month)) USING month;
+        END LOOP;
+        DELETE FROM ONLY work_item_events;
+        PERFORM create_work_item_events_partition(now());
+        PERFORM create_work_item_events_partition(now() + interval '1 month');
+    END;
+$$;
+
+CREATE TRIGGER insert_work_item_event_trigger
+BEFORE INSERT
+ON work_item_events
+FOR EACH ROW
+EXECUTE PROCEDURE insert_work_item_event();
+
+CREATE TRIGGER remove_work_item_event_from_parent_trigger
+AFTER INSERT
+ON work_item_events
+FOR EACH ROW
+EXECUTE PROCEDURE remove_work_item_event_from_parent();
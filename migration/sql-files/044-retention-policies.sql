@@ -0,0 +1,12 @@
+-- projects can limit how long their history is kept, see package retention
+
+CREATE TABLE retention_policies (
+    created_at      timestamp with time zone,
+    updated_at      timestamp with time zone,
+    deleted_at      timestamp with time zone,
+
+    project_id      uuid PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+    revision_days   integer CONSTRAINT retention_policies_revision_days_check CHECK (revision_days > 0),
+    snapshot_days   integer CONSTRAINT retention_policies_snapshot_days_check CHECK (snapshot_days > 0),
+    trash_days      integer CONSTRAINT retention_policies_trash_days_check CHECK (trash_days > 0)
+);
@@ -0,0 +1,23 @@
+-- backup_runs records the logical backups of projects and of the instance,
+-- and their restores, see package backup
+
+CREATE TABLE backup_runs (
+    created_at      timestamp with time zone,
+    updated_at      timestamp with time zone,
+    deleted_at      timestamp with time zone,
+
+    id              uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    operation       text NOT NULL CONSTRAINT backup_runs_operation_check CHECK (operation IN ('backup', 'restore')),
+    project_id      uuid,
+    backup_id       uuid REFERENCES backup_runs(id) ON DELETE CASCADE,
+    object          text NOT NULL,
+    status          text NOT NULL CONSTRAINT backup_runs_status_check CHECK (status IN ('pending', 'running', 'succeeded', 'failed')),
+    tables          integer NOT NULL DEFAULT 0,
+    tables_done     integer NOT NULL DEFAULT 0,
+    rows            bigint NOT NULL DEFAULT 0,
+    error           text NOT NULL DEFAULT '',
+    creator_id      uuid,
+    finished_at     timestamp with time zone
+);
+
+CREATE INDEX backup_runs_project_id_idx ON backup_runs USING btree (project_id);
@@ -0,0 +1,6 @@
+-- compatible_from is the oldest schema version whose code can still run on
+-- the schema of the row's version: the version itself after a breaking
+-- migration, the compatible_from of the version before after a backward
+-- compatible one. NULL for the versions from before it was recorded.
+
+ALTER TABLE version ADD COLUMN compatible_from integer;
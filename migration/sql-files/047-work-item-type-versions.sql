@@ -0,0 +1,23 @@
+-- work_item_type_versions keeps the field definitions of every version of the
+-- work item types. Work items and their events record the version of their
+-- type their fields were validated against, items of older versions are read
+-- with the definitions of their version until they are migrated.
+
+CREATE TABLE work_item_type_versions (
+    created_at  timestamp with time zone,
+    type_name   text NOT NULL REFERENCES work_item_types(name) ON DELETE CASCADE,
+    version     integer NOT NULL,
+    fields      jsonb NOT NULL,
+    PRIMARY KEY (type_name, version)
+);
+
+INSERT INTO work_item_type_versions (created_at, type_name, version, fields)
+    SELECT now(), name, version, fields FROM work_item_types;
+
+ALTER TABLE work_items ADD COLUMN type_version integer NOT NULL DEFAULT 0;
+UPDATE work_items w SET type_version = t.version FROM work_item_types t WHERE t.name = w.type;
+CREATE INDEX work_items_type_type_version_idx ON work_items USING btree (type, type_version);
+
+-- the partitions inherit the column, earlier events keep version 0 and are
+-- read leniently
+ALTER TABLE work_item_events ADD COLUMN type_version integer NOT NULL DEFAULT 0;
@@ -0,0 +1,12 @@
+-- translations of the work item type names and of the values of the state
+-- and priority fields, by locale, see package translation
+
+CREATE TABLE translations (
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    locale text NOT NULL,
+    subject text NOT NULL,
+    value text NOT NULL,
+    label text NOT NULL,
+    PRIMARY KEY (locale, subject, value)
+);
@@ -0,0 +1,2 @@
+-- the IANA timezone identities see dates in, empty for UTC
+ALTER TABLE identities ADD COLUMN timezone text NOT NULL DEFAULT '';
@@ -0,0 +1,15 @@
+-- calendar_feeds holds the secret tokens of the ICS feeds of the identities.
+-- A feed without project lists the iterations and release dates of all
+-- projects of the identity, an identity has at most one feed per project.
+
+CREATE TABLE calendar_feeds (
+    created_at      timestamp with time zone,
+
+    token           text primary key,
+
+    identity_id     uuid NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+    project_id      uuid REFERENCES projects(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX calendar_feeds_identity_id_idx ON calendar_feeds (identity_id) WHERE project_id IS NULL;
+CREATE UNIQUE INDEX calendar_feeds_identity_id_project_id_idx ON calendar_feeds (identity_id, project_id) WHERE project_id IS NOT NULL;
@@ -0,0 +1,27 @@
+-- projects can post the changes of their work items to a Slack or Mattermost
+-- channel, see package chat
+
+CREATE TABLE chat_integrations (
+    created_at              timestamp with time zone,
+    updated_at              timestamp with time zone,
+    deleted_at              timestamp with time zone,
+
+    project_id              uuid PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+    kind                    text NOT NULL CHECK (kind IN ('slack', 'mattermost')),
+    webhook_url             text NOT NULL,
+    channel                 text NOT NULL DEFAULT '',
+    created_template        text NOT NULL DEFAULT '',
+    assigned_template       text NOT NULL DEFAULT '',
+    state_changed_template  text NOT NULL DEFAULT '',
+    threads                 boolean NOT NULL DEFAULT true
+);
+
+-- the message starting the thread of the messages about a work item
+CREATE TABLE chat_threads (
+    created_at      timestamp with time zone,
+
+    project_id      uuid REFERENCES chat_integrations(project_id) ON DELETE CASCADE,
+    work_item_id    bigint REFERENCES work_items(id) ON DELETE CASCADE,
+    thread_id       text NOT NULL,
+    PRIMARY KEY (project_id, work_item_id)
+);
@@ -0,0 +1,24 @@
+-- the slash commands of the chat of a project carry the token of the
+-- command, the users typing them are linked to identities with link codes
+
+ALTER TABLE chat_integrations ADD COLUMN command_token text NOT NULL DEFAULT '';
+
+CREATE TABLE chat_accounts (
+    created_at      timestamp with time zone,
+
+    project_id      uuid REFERENCES chat_integrations(project_id) ON DELETE CASCADE,
+    chat_user_id    text NOT NULL,
+    identity_id     uuid NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+    PRIMARY KEY (project_id, chat_user_id)
+);
+
+CREATE TABLE chat_link_codes (
+    created_at      timestamp with time zone,
+
+    code            text PRIMARY KEY,
+    project_id      uuid NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    identity_id     uuid NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+    expires_at      timestamp with time zone NOT NULL
+);
+
+CREATE INDEX chat_link_codes_project_id_identity_id_idx ON chat_link_codes (project_id, identity_id);
@@ -0,0 +1,21 @@
+-- automation rules run on the changes of the work items of their project,
+-- see package automation
+
+CREATE TABLE automation_rules (
+    created_at      timestamp with time zone,
+    updated_at      timestamp with time zone,
+    deleted_at      timestamp with time zone,
+
+    id              uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    project_id      uuid NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name            text NOT NULL,
+    enabled         boolean NOT NULL DEFAULT true,
+    trigger_kind    text NOT NULL,
+    trigger_value   text NOT NULL DEFAULT '',
+    conditions      jsonb NOT NULL DEFAULT '[]',
+    actions         jsonb NOT NULL DEFAULT '[]',
+    created_by      uuid NOT NULL,
+    version         integer NOT NULL DEFAULT 0
+);
+
+CREATE INDEX automation_rules_project_id_idx ON automation_rules (project_id) WHERE deleted_at IS NULL;
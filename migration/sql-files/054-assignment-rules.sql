@@ -0,0 +1,19 @@
+-- assignment rules assign the new work items of their project, see package
+-- assignment
+
+CREATE TABLE assignment_rules (
+    created_at      timestamp with time zone,
+    updated_at      timestamp with time zone,
+    deleted_at      timestamp with time zone,
+
+    id              uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    project_id      uuid NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    type            text NOT NULL DEFAULT '',
+    label           text NOT NULL DEFAULT '',
+    assignees       jsonb NOT NULL DEFAULT '[]',
+    round_robin     boolean NOT NULL DEFAULT false,
+    turns           integer NOT NULL DEFAULT 0,
+    version         integer NOT NULL DEFAULT 0
+);
+
+CREATE INDEX assignment_rules_project_id_idx ON assignment_rules (project_id) WHERE deleted_at IS NULL;
@@ -0,0 +1,22 @@
+-- projects can escalate open work items of a severity that stay unassigned or
+-- untouched for a number of hours, see package escalation
+
+CREATE TABLE escalation_policies (
+    created_at          timestamp with time zone,
+    updated_at          timestamp with time zone,
+    deleted_at          timestamp with time zone,
+
+    project_id          uuid PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+    severity            text NOT NULL,
+    unassigned_hours    integer CONSTRAINT escalation_policies_unassigned_hours_check CHECK (unassigned_hours > 0),
+    untouched_hours     integer CONSTRAINT escalation_policies_untouched_hours_check CHECK (untouched_hours > 0),
+    contact_id          uuid REFERENCES identities(id) ON DELETE SET NULL,
+    raise_priority      text NOT NULL DEFAULT ''
+);
+
+CREATE TABLE escalated_work_items (
+    work_item_id    bigint PRIMARY KEY REFERENCES work_items(id) ON DELETE CASCADE,
+    project_id      uuid NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    reason          text NOT NULL,
+    escalated_at    timestamp with time zone NOT NULL
+);
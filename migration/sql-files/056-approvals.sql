@@ -0,0 +1,36 @@
+-- approval rules require approvals for the state transitions of the work
+-- items of their project, see package approval
+
+CREATE TABLE approval_rules (
+    created_at      timestamp with time zone,
+    updated_at      timestamp with time zone,
+    deleted_at      timestamp with time zone,
+
+    id              uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    project_id      uuid NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    type            text NOT NULL DEFAULT '',
+    from_state      text NOT NULL DEFAULT '',
+    to_state        text NOT NULL,
+    roles           jsonb NOT NULL DEFAULT '[]',
+    approvals       integer NOT NULL CONSTRAINT approval_rules_approvals_check CHECK (approvals > 0),
+    version         integer NOT NULL DEFAULT 0
+);
+
+CREATE INDEX approval_rules_project_id_idx ON approval_rules (project_id) WHERE deleted_at IS NULL;
+
+CREATE TABLE approvals (
+    created_at      timestamp with time zone,
+    updated_at      timestamp with time zone,
+    deleted_at      timestamp with time zone,
+
+    id              uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    work_item_id    bigint NOT NULL REFERENCES work_items(id) ON DELETE CASCADE,
+    rule_id         uuid NOT NULL REFERENCES approval_rules(id) ON DELETE CASCADE,
+    from_state      text NOT NULL,
+    to_state        text NOT NULL,
+    requested_by    uuid NOT NULL,
+    status          text NOT NULL,
+    decisions       jsonb NOT NULL DEFAULT '[]'
+);
+
+CREATE INDEX approvals_work_item_id_idx ON approvals (work_item_id, to_state) WHERE deleted_at IS NULL;
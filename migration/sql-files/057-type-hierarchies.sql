@@ -0,0 +1,11 @@
+-- projects can restrict which work item types can be parent and child in
+-- tree links, see package hierarchy
+
+CREATE TABLE type_hierarchies (
+    created_at  timestamp with time zone,
+    updated_at  timestamp with time zone,
+    deleted_at  timestamp with time zone,
+
+    project_id  uuid PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+    levels      jsonb NOT NULL DEFAULT '[]'
+);
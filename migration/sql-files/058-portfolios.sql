@@ -0,0 +1,18 @@
+-- portfolios group projects, report on their open work items and allow tree
+-- links between them, see package portfolio
+
+CREATE TABLE portfolios (
+    created_at  timestamp with time zone,
+    updated_at  timestamp with time zone,
+    deleted_at  timestamp with time zone,
+
+    id          uuid PRIMARY KEY DEFAULT uuid_generate_v4() NOT NULL,
+    name        text NOT NULL CONSTRAINT portfolios_name_check CHECK (name <> ''),
+    description text NOT NULL DEFAULT '',
+    owner_id    uuid NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+    projects    jsonb NOT NULL DEFAULT '[]',
+    link_types  jsonb NOT NULL DEFAULT '[]',
+    version     integer NOT NULL DEFAULT 0
+);
+
+CREATE INDEX portfolios_projects_idx ON portfolios USING gin (projects);
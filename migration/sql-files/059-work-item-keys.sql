@@ -0,0 +1,12 @@
+-- work items of projects with a key get a human-friendly key of the project
+-- key and a per-project sequence number, e.g. ALM-482, see package workitem
+
+ALTER TABLE projects
+    ADD COLUMN key text CONSTRAINT projects_key_check CHECK (key ~ '^[A-Z][A-Z0-9]{1,9}$'),
+    ADD COLUMN key_sequence integer NOT NULL DEFAULT 0;
+
+CREATE UNIQUE INDEX projects_key_idx ON projects (key);
+
+ALTER TABLE work_items ADD COLUMN key text;
+
+CREATE UNIQUE INDEX work_items_key_idx ON work_items (key);
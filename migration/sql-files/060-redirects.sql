@@ -0,0 +1,11 @@
+-- redirects of merged and moved resources, see package redirect
+
+CREATE TABLE redirects (
+    kind       text NOT NULL,
+    source     text NOT NULL,
+    target     text NOT NULL,
+    created_at timestamp with time zone,
+    PRIMARY KEY (kind, source)
+);
+
+CREATE INDEX redirects_target_idx ON redirects (kind, target);
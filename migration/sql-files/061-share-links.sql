@@ -0,0 +1,15 @@
+-- share_links grant read access to single work items without logging in,
+-- see package share
+
+CREATE TABLE share_links (
+    created_at   timestamp with time zone,
+    updated_at   timestamp with time zone,
+    deleted_at   timestamp with time zone,
+
+    id           uuid PRIMARY KEY DEFAULT uuid_generate_v4() NOT NULL,
+    work_item_id bigint NOT NULL REFERENCES work_items(id) ON DELETE CASCADE,
+    created_by   uuid NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+    expires_at   timestamp with time zone NOT NULL
+);
+
+CREATE INDEX share_links_work_item_id_idx ON share_links (work_item_id, created_by);
@@ -0,0 +1,5 @@
+-- drafts are work items flagged with system.draft until they are published,
+-- the index serves the job deleting the abandoned ones, see
+-- workitem.DraftRepository
+
+CREATE INDEX work_items_draft_idx ON work_items (updated_at) WHERE fields @> '{"system.draft": true}' AND deleted_at IS NULL;
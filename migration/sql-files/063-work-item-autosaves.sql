@@ -0,0 +1,11 @@
+-- work_item_autosaves hold the changes of the identities editing a work item
+-- until they are applied as one revision, see workitem.AutosaveRepository
+
+CREATE TABLE work_item_autosaves (
+    work_item_id bigint NOT NULL REFERENCES work_items(id) ON DELETE CASCADE,
+    identity_id  uuid NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+    version      integer NOT NULL,
+    fields       jsonb NOT NULL,
+    updated_at   timestamp with time zone NOT NULL,
+    PRIMARY KEY (work_item_id, identity_id)
+);
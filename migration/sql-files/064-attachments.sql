@@ -0,0 +1,20 @@
+-- attachments are files uploaded to projects, e.g. screenshots pasted into
+-- descriptions, see package attachment
+
+CREATE TABLE attachments (
+    created_at   timestamp with time zone,
+    updated_at   timestamp with time zone,
+    deleted_at   timestamp with time zone,
+
+    id           uuid PRIMARY KEY DEFAULT uuid_generate_v4() NOT NULL,
+    project_id   uuid NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    hash         text NOT NULL,
+    content_type text NOT NULL,
+    size         bigint NOT NULL,
+    content      bytea NOT NULL,
+    created_by   uuid NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+    malware      text
+);
+
+-- the same content is stored once per project
+CREATE UNIQUE INDEX attachments_project_id_hash_idx ON attachments (project_id, hash) WHERE deleted_at IS NULL;
@@ -0,0 +1,3 @@
+-- the largest attachments are reported to the admins managing the storage
+-- quotas of the projects
+CREATE INDEX attachments_size_idx ON attachments (size DESC) WHERE deleted_at IS NULL;
@@ -0,0 +1,19 @@
+-- push_devices are the mobile devices identities are notified on through
+-- FCM or APNs, see package push
+
+CREATE TABLE push_devices (
+    created_at  timestamp with time zone,
+    updated_at  timestamp with time zone,
+
+    id          uuid PRIMARY KEY DEFAULT uuid_generate_v4() NOT NULL,
+    identity_id uuid NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+    platform    text NOT NULL,
+    token       text NOT NULL,
+    name        text NOT NULL DEFAULT '',
+    muted       jsonb NOT NULL DEFAULT '[]',
+    badge       boolean NOT NULL DEFAULT false
+);
+
+-- a device registered again by another identity moves to that identity
+CREATE UNIQUE INDEX push_devices_platform_token_idx ON push_devices (platform, token);
+CREATE INDEX push_devices_identity_id_idx ON push_devices (identity_id);
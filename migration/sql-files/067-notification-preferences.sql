@@ -0,0 +1,15 @@
+-- notification_preferences tell by event and channel whether identities are
+-- notified, by default or for a project, see package notification
+
+CREATE TABLE notification_preferences (
+    created_at  timestamp with time zone,
+
+    identity_id uuid NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+    project_id  uuid REFERENCES projects(id) ON DELETE CASCADE,
+    event       text NOT NULL,
+    channel     text NOT NULL,
+    enabled     boolean NOT NULL
+);
+
+CREATE UNIQUE INDEX notification_preferences_defaults_idx ON notification_preferences (identity_id, event, channel) WHERE project_id IS NULL;
+CREATE UNIQUE INDEX notification_preferences_projects_idx ON notification_preferences (identity_id, project_id, event, channel) WHERE project_id IS NOT NULL;
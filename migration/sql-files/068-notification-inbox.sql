@@ -0,0 +1,19 @@
+-- notification_inbox holds the notifications shown to identities by the web
+-- and mobile clients, see package notification
+
+CREATE TABLE notification_inbox (
+    created_at   timestamp with time zone,
+
+    id           uuid PRIMARY KEY DEFAULT uuid_generate_v4() NOT NULL,
+    recipient_id uuid NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+    event        text NOT NULL,
+    work_item_id text NOT NULL,
+    project_id   uuid REFERENCES projects(id) ON DELETE CASCADE,
+    subject      text NOT NULL,
+    at           timestamp with time zone NOT NULL,
+    read_at      timestamp with time zone
+);
+
+-- a notification delivered again is added once
+CREATE UNIQUE INDEX notification_inbox_delivery_idx ON notification_inbox (recipient_id, event, work_item_id, at);
+CREATE INDEX notification_inbox_unread_idx ON notification_inbox (recipient_id) WHERE read_at IS NULL;
@@ -0,0 +1,18 @@
+-- notification_mutes stops the notifications of identities about work items
+-- or projects for a while, see package notification
+
+CREATE TABLE notification_mutes (
+    created_at   timestamp with time zone,
+
+    id           uuid PRIMARY KEY DEFAULT uuid_generate_v4() NOT NULL,
+    identity_id  uuid NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+    work_item_id text,
+    project_id   uuid REFERENCES projects(id) ON DELETE CASCADE,
+    until        timestamp with time zone NOT NULL,
+    CHECK ((work_item_id IS NULL) <> (project_id IS NULL))
+);
+
+CREATE INDEX notification_mutes_identity_idx ON notification_mutes (identity_id, until);
+
+-- snoozed notifications are hidden from the inbox until then
+ALTER TABLE notification_inbox ADD COLUMN snoozed_until timestamp with time zone;
@@ -0,0 +1,13 @@
+-- notification_email_templates holds the email templates customized by the
+-- operators, see package notification
+
+CREATE TABLE notification_email_templates (
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+
+    event      text NOT NULL,
+    locale     text NOT NULL DEFAULT '',
+    subject    text NOT NULL,
+    body       text NOT NULL,
+    PRIMARY KEY (event, locale)
+);
@@ -0,0 +1,18 @@
+-- branding holds the branding settings of the deployment and the overrides
+-- of projects, see package branding
+
+CREATE TABLE branding (
+    created_at    timestamp with time zone,
+    updated_at    timestamp with time zone,
+
+    project_id    uuid REFERENCES projects(id) ON DELETE CASCADE,
+    product_name  text NOT NULL DEFAULT '',
+    logo_url      text NOT NULL DEFAULT '',
+    accent_color  text NOT NULL DEFAULT '',
+    support_url   text NOT NULL DEFAULT '',
+    docs_url      text NOT NULL DEFAULT '',
+    support_email text NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX branding_deployment_idx ON branding ((true)) WHERE project_id IS NULL;
+CREATE UNIQUE INDEX branding_projects_idx ON branding (project_id) WHERE project_id IS NOT NULL;
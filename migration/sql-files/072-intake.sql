@@ -0,0 +1,24 @@
+-- intake_portals holds the feedback portals of projects, intake_submissions
+-- the recent submissions by hashed client for the rate limit, see package
+-- intake
+
+CREATE TABLE intake_portals (
+    created_at     timestamp with time zone,
+    updated_at     timestamp with time zone,
+    deleted_at     timestamp with time zone,
+
+    project_id     uuid PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+    enabled        boolean NOT NULL DEFAULT false,
+    work_item_type text NOT NULL,
+    label          text NOT NULL,
+    rate_limit     integer NOT NULL
+);
+
+CREATE TABLE intake_submissions (
+    created_at timestamp with time zone NOT NULL,
+
+    project_id uuid NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    client     text NOT NULL
+);
+
+CREATE INDEX intake_submissions_client_idx ON intake_submissions (project_id, client, created_at);
@@ -0,0 +1,18 @@
+-- the origins allowed to embed the feedback forms of projects and the tokens
+-- of the forms, see package intake
+
+ALTER TABLE intake_portals ADD COLUMN origins jsonb NOT NULL DEFAULT '[]';
+ALTER TABLE intake_portals ADD COLUMN require_token boolean NOT NULL DEFAULT false;
+
+CREATE TABLE intake_tokens (
+    created_at   timestamp with time zone,
+
+    id           uuid PRIMARY KEY DEFAULT uuid_generate_v4() NOT NULL,
+    project_id   uuid NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name         text NOT NULL,
+    origin       text NOT NULL DEFAULT '',
+    hash         text NOT NULL UNIQUE,
+    last_used_at timestamp with time zone
+);
+
+CREATE INDEX intake_tokens_project_idx ON intake_tokens (project_id);
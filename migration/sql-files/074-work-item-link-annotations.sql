@@ -0,0 +1,3 @@
+-- a short note and key/value metadata annotating work item links
+ALTER TABLE work_item_links ADD COLUMN note text NOT NULL DEFAULT '';
+ALTER TABLE work_item_links ADD COLUMN metadata jsonb NOT NULL DEFAULT '{}';
@@ -0,0 +1,11 @@
+-- process_templates holds the process templates registered by the operators,
+-- see package processtemplate
+
+CREATE TABLE process_templates (
+    created_at  timestamp with time zone,
+    updated_at  timestamp with time zone,
+
+    name        text PRIMARY KEY,
+    description text NOT NULL DEFAULT '',
+    config      text NOT NULL
+);
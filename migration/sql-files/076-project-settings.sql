@@ -0,0 +1,13 @@
+-- project_settings holds the process customization toggles of projects, see
+-- package projectsettings
+
+CREATE TABLE project_settings (
+    created_at             timestamp with time zone,
+    updated_at             timestamp with time zone,
+    deleted_at             timestamp with time zone,
+
+    project_id             uuid PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+    default_work_item_type text NOT NULL DEFAULT '',
+    required_fields        jsonb NOT NULL DEFAULT '[]',
+    link_type_creators     text NOT NULL DEFAULT 'instance-admins'
+);
@@ -0,0 +1,16 @@
+-- impersonations audits the tokens instance admins were issued to act as
+-- another identity, see package impersonation. The records outlive the
+-- identities so they don't reference them.
+
+CREATE TABLE impersonations (
+    id              uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    created_at      timestamp with time zone NOT NULL,
+
+    impersonator_id uuid NOT NULL,
+    identity_id     uuid NOT NULL,
+    reason          text NOT NULL,
+    expires_at      timestamp with time zone NOT NULL
+);
+
+CREATE INDEX impersonations_created_at_idx ON impersonations USING btree (created_at);
+CREATE INDEX impersonations_identity_id_idx ON impersonations USING btree (identity_id);
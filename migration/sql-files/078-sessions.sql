@@ -0,0 +1,16 @@
+-- sessions tracks the tokens issued to identities so they can be revoked,
+-- see package session. The ID of a session is the "jti" claim of its token.
+
+CREATE TABLE sessions (
+    id              uuid primary key NOT NULL,
+    created_at      timestamp with time zone NOT NULL,
+
+    identity_id     uuid NOT NULL,
+    impersonator_id uuid,
+    user_agent      text NOT NULL DEFAULT '',
+    expires_at      timestamp with time zone,
+    last_used_at    timestamp with time zone,
+    revoked_at      timestamp with time zone
+);
+
+CREATE INDEX sessions_identity_id_created_at_idx ON sessions USING btree (identity_id, created_at);
@@ -0,0 +1,7 @@
+-- session_cutoffs holds when all the tokens of an identity were last revoked,
+-- tokens without a session issued before are refused, see package session
+
+CREATE TABLE session_cutoffs (
+    identity_id    uuid primary key NOT NULL,
+    revoked_before timestamp with time zone NOT NULL
+);
@@ -0,0 +1,4 @@
+-- locked_by identifies the claim of a running job, a worker whose lock timed
+-- out and was claimed again can no longer complete or fail the job
+
+ALTER TABLE jobs ADD COLUMN locked_by uuid;
@@ -0,0 +1,17 @@
+-- outbox_deliveries tracks the attempts of each sink to accept a record, a
+-- record is only delivered again to the sinks that did not accept it yet.
+-- Records a sink failed too many times are dead and no longer delivered.
+
+CREATE TABLE outbox_deliveries (
+    record_sequence bigint NOT NULL REFERENCES outbox_records (sequence) ON DELETE CASCADE,
+    sink            text NOT NULL,
+    attempts        integer NOT NULL DEFAULT 0,
+    last_error      text NOT NULL DEFAULT '',
+    delivered_at    timestamp with time zone,
+    PRIMARY KEY (record_sequence, sink)
+);
+
+ALTER TABLE outbox_records ADD COLUMN dead_at timestamp with time zone;
+
+DROP INDEX outbox_records_pending_idx;
+CREATE INDEX outbox_records_pending_idx ON outbox_records USING btree (sequence) WHERE published_at IS NULL AND dead_at IS NULL;
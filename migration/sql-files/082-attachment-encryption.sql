@@ -0,0 +1,4 @@
+-- the content of attachments uploaded while encryption keys are configured
+-- is stored encrypted, see package encryption
+
+ALTER TABLE attachments ADD COLUMN encrypted boolean NOT NULL DEFAULT false;
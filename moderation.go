@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/moderation"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ModerationController implements the moderation resource.
+type ModerationController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewModerationController creates a moderation controller.
+func NewModerationController(service *goa.Service, db application.DB) *ModerationController {
+	return &ModerationController{Controller: service.NewController("ModerationController"), db: db}
+}
+
+// Workitems runs the workitems action.
+func (c *ModerationController) Workitems(ctx *app.WorkitemsModerationContext) error {
+	return administrateProject(ctx, c.db, ctx.ID, "moderate", func(appl application.Application, projectID uuid.UUID) error {
+		exp := criteria.And(
+			criteria.Equals(criteria.Field(workitem.SystemProject), criteria.Literal(projectID.String())),
+			criteria.Equals(criteria.Field(workitem.SystemPendingReview), criteria.Literal(true)))
+		result, count, err := appl.WorkItems().List(ctx, exp, nil, nil)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.WorkItem2List{
+			Links: &app.PagingLinks{},
+			Meta:  &app.WorkItemListResponseMeta{TotalCount: int(count)},
+			Data:  ConvertWorkItems(ctx.RequestData, result),
+		})
+	})
+}
+
+// Comments runs the comments action.
+func (c *ModerationController) Comments(ctx *app.CommentsModerationContext) error {
+	return administrateProject(ctx, c.db, ctx.ID, "moderate", func(appl application.Application, projectID uuid.UUID) error {
+		comments, err := appl.Moderation().PendingComments(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.CommentArray{Data: ConvertComments(ctx.RequestData, comments)})
+	})
+}
+
+// Approve runs the approve action.
+func (c *ModerationController) Approve(ctx *app.ApproveModerationContext) error {
+	return administrateProject(ctx, c.db, ctx.ID, "moderate", func(appl application.Application, projectID uuid.UUID) error {
+		err := appl.Moderation().Approve(ctx, projectID, ctx.Kind, ctx.ItemID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.NoContent()
+	})
+}
+
+// Reject runs the reject action.
+func (c *ModerationController) Reject(ctx *app.RejectModerationContext) error {
+	return administrateProject(ctx, c.db, ctx.ID, "moderate", func(appl application.Application, projectID uuid.UUID) error {
+		err := appl.Moderation().Reject(ctx, projectID, ctx.Kind, ctx.ItemID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.NoContent()
+	})
+}
+
+// projectAdminContext is implemented by the contexts of the actions
+// restricted to the admins of a project
+type projectAdminContext interface {
+	context.Context
+	jsonapi.InternalServerError
+}
+
+// administrateProject runs the given function in a transaction if the current
+// identity is an admin of the project with the given ID or of the instance.
+// The action completes the error message for everybody else, e.g. "change the
+// stale policy".
+func administrateProject(ctx projectAdminContext, db application.DB, id string, action string, f func(appl application.Application, projectID uuid.UUID) error) error {
+	if currentIdentityID(ctx) == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing identity"))
+	}
+	projectID, err := uuid.FromString(id)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, db), func(appl application.Application) error {
+		if _, err := appl.Projects().Load(ctx, projectID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if err := checkProjectAdmin(ctx, appl, projectID, action); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return f(appl, projectID)
+	})
+}
+
+// checkProjectAdmin returns an error unless the current identity is an admin
+// of the project with the given ID or of the instance
+func checkProjectAdmin(ctx context.Context, appl application.Application, projectID uuid.UUID, action string) error {
+	if isInstanceAdmin(ctx) {
+		return nil
+	}
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return goa.ErrUnauthorized("missing identity")
+	}
+	admin, err := isProjectAdmin(ctx, appl, projectID, *identityID)
+	if err != nil {
+		return err
+	}
+	if !admin {
+		return goa.ErrUnauthorized("only project admins can " + action)
+	}
+	return nil
+}
+
+func isProjectAdmin(ctx context.Context, appl application.Application, projectID uuid.UUID, identityID uuid.UUID) (bool, error) {
+	ids, err := appl.Projects().AdminProjectIDs(ctx, identityID)
+	if err != nil {
+		return false, err
+	}
+	for _, id := range ids {
+		if uuid.Equal(id, projectID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// checkContribution moderates a new work item or comment by the given author
+// in the project with the given ID, a nil or empty project is not moderated.
+// Returns true if the contribution must be held for review.
+func checkContribution(ctx context.Context, appl application.Application, kind string, project interface{}, author string, text ...string) (bool, error) {
+	p, _ := project.(string)
+	if p == "" {
+		return false, nil
+	}
+	projectID, err := uuid.FromString(p)
+	if err != nil {
+		return false, nil
+	}
+	authorID, err := uuid.FromString(author)
+	if err != nil {
+		return false, goa.ErrUnauthorized(err.Error())
+	}
+	return appl.Moderation().Check(ctx, moderation.Content{
+		Kind:      kind,
+		ProjectID: projectID,
+		AuthorID:  authorID,
+		Text:      strings.Join(text, "\n"),
+	})
+}
+
+// visibleComments removes the comments held for review from the list unless
+// the current identity wrote them or administrates the project of the work item
+func visibleComments(ctx context.Context, wi *app.WorkItem, comments []*comment.Comment) []*comment.Comment {
+	viewer := workitem.ContextViewer(ctx)
+	if viewer.HasRole(wi.Fields, workitem.RoleProjectAdmin) {
+		return comments
+	}
+	visible := make([]*comment.Comment, 0, len(comments))
+	for _, cm := range comments {
+		if cm.PendingReview && (viewer.IdentityID == nil || !uuid.Equal(cm.CreatedBy, *viewer.IdentityID)) {
+			continue
+		}
+		visible = append(visible, cm)
+	}
+	return visible
+}
+
+// contributionText returns the text of a work item field for the content filters
+func contributionText(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
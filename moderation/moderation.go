@@ -0,0 +1,288 @@
+// Package moderation protects public projects from spam and abuse. The
+// contributions of first-time and anonymous contributors are held for review
+// by the project admins, new users are rate limited and all contributions
+// pass the registered content filters.
+package moderation
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Kinds of contributions
+const (
+	KindWorkItem = "workitems"
+	KindComment  = "comments"
+)
+
+// Verdict is the decision of a content filter
+type Verdict int
+
+// Verdicts ordered by severity
+const (
+	Accept Verdict = iota
+	Review
+	Reject
+)
+
+// Content is a contribution to a public project
+type Content struct {
+	Kind      string
+	ProjectID uuid.UUID
+	AuthorID  uuid.UUID
+	Text      string
+}
+
+// Filter inspects contributions to public projects before they are stored
+type Filter interface {
+	Check(ctx context.Context, c Content) (Verdict, error)
+}
+
+var filters = []Filter{BlockedWordsFilter{}}
+
+// RegisterFilter adds a filter that is applied to all contributions to
+// public projects. Filters must be registered during initialization.
+func RegisterFilter(f Filter) {
+	filters = append(filters, f)
+}
+
+// BlockedWordsFilter rejects contributions containing one of the configured
+// blocked words
+type BlockedWordsFilter struct{}
+
+// Check implements Filter
+func (BlockedWordsFilter) Check(ctx context.Context, c Content) (Verdict, error) {
+	text := strings.ToLower(c.Text)
+	for _, w := range configuration.GetModerationBlockedWords() {
+		if strings.Contains(text, strings.ToLower(w)) {
+			return Reject, nil
+		}
+	}
+	return Accept, nil
+}
+
+// Repository moderates contributions to public projects
+type Repository interface {
+	Check(ctx context.Context, c Content) (bool, error)
+	PendingComments(ctx context.Context, projectID uuid.UUID) ([]*comment.Comment, error)
+	Approve(ctx context.Context, projectID uuid.UUID, kind string, id string) error
+	Reject(ctx context.Context, projectID uuid.UUID, kind string, id string) error
+}
+
+// NewModerationRepository creates a new storage type.
+func NewModerationRepository(db *gorm.DB) Repository {
+	return &GormModerationRepository{db: db}
+}
+
+// GormModerationRepository is the implementation of the storage interface
+// for moderation.
+type GormModerationRepository struct {
+	db *gorm.DB
+}
+
+// Check runs the content filters and the new user rate limit on a
+// contribution to a public project and returns true if the contribution must
+// be held for review. Contributions to other projects are not moderated.
+// returns NotFoundError, BadParameterError if the contribution is rejected or InternalError
+func (m *GormModerationRepository) Check(ctx context.Context, c Content) (bool, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "moderation", "check"}, time.Now())
+
+	p, err := project.NewRepository(m.db).Load(ctx, c.ProjectID)
+	if err != nil {
+		return false, err
+	}
+	if !p.Public {
+		return false, nil
+	}
+
+	verdict, err := Screen(ctx, c)
+	if err != nil {
+		return false, err
+	}
+	if verdict == Reject {
+		return false, errors.NewBadParameterError("content", c.Kind).Expected("no blocked content")
+	}
+	// anonymous contributions are always reviewed, the challenge of the
+	// project takes the place of the rate limit
+	if uuid.Equal(c.AuthorID, account.AnonymousIdentityID) {
+		return true, nil
+	}
+
+	if err := m.checkRateLimit(ctx, c.AuthorID); err != nil {
+		return false, err
+	}
+	if verdict == Review {
+		return true, nil
+	}
+	firstTime, err := m.isFirstTimeContributor(c.ProjectID, c.AuthorID)
+	if err != nil {
+		return false, err
+	}
+	return firstTime, nil
+}
+
+// Screen runs the content filters on the contribution and returns the most
+// severe verdict
+// returns InternalError
+func Screen(ctx context.Context, c Content) (Verdict, error) {
+	verdict := Accept
+	for _, f := range filters {
+		v, err := f.Check(ctx, c)
+		if err != nil {
+			return Accept, errors.NewInternalError(err.Error())
+		}
+		if v > verdict {
+			verdict = v
+		}
+	}
+	return verdict, nil
+}
+
+// checkRateLimit returns BadParameterError if a new user exceeded the number
+// of contributions per hour
+func (m *GormModerationRepository) checkRateLimit(ctx context.Context, identityID uuid.UUID) error {
+	identity := account.Identity{}
+	tx := m.db.Where("id = ?", identityID).First(&identity)
+	if tx.RecordNotFound() {
+		return errors.NewNotFoundError("identity", identityID.String())
+	}
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if time.Since(identity.CreatedAt) > configuration.GetModerationNewUserPeriod() {
+		return nil
+	}
+	since := time.Now().Add(-time.Hour)
+	var items, comments int
+	err := m.db.Model(&workitem.WorkItem{}).Where("fields->>? = ? AND created_at > ?", workitem.SystemCreator, identityID.String(), since).Count(&items).Error
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	err = m.db.Model(&comment.Comment{}).Where("created_by = ? AND created_at > ?", identityID, since).Count(&comments).Error
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	limit := configuration.GetModerationNewUserRateLimit()
+	if items+comments >= limit {
+		return errors.NewBadParameterError("contributions", items+comments).Expected(fmt.Sprintf("at most %d per hour for new users", limit))
+	}
+	return nil
+}
+
+// isFirstTimeContributor returns true if none of the work items and comments
+// of the identity in the project were approved yet
+func (m *GormModerationRepository) isFirstTimeContributor(projectID uuid.UUID, identityID uuid.UUID) (bool, error) {
+	var items, comments int
+	err := m.db.Model(&workitem.WorkItem{}).Where(
+		fmt.Sprintf(`fields->>'%s' = ? AND fields->>'%s' = ? AND NOT (fields @> '{"%s": true}')`, workitem.SystemCreator, workitem.SystemProject, workitem.SystemPendingReview),
+		identityID.String(), projectID.String()).Count(&items).Error
+	if err != nil {
+		return false, errors.NewInternalError(err.Error())
+	}
+	if items > 0 {
+		return false, nil
+	}
+	err = m.db.Model(&comment.Comment{}).Where(
+		"created_by = ? AND NOT pending_review AND parent_id IN ("+projectWorkItemIDs+")", identityID, projectID.String()).Count(&comments).Error
+	if err != nil {
+		return false, errors.NewInternalError(err.Error())
+	}
+	return comments == 0, nil
+}
+
+// projectWorkItemIDs selects the IDs of the work items of a project as text
+var projectWorkItemIDs = fmt.Sprintf("SELECT id::text FROM work_items WHERE fields->>'%s' = ? AND deleted_at IS NULL", workitem.SystemProject)
+
+// pendingWorkItem matches the pending work item with a given ID in a project
+var pendingWorkItem = fmt.Sprintf(`id = ? AND fields->>'%s' = ? AND fields @> '{"%s": true}' AND deleted_at IS NULL`, workitem.SystemProject, workitem.SystemPendingReview)
+
+// PendingComments returns the comments on the work items of the project that
+// are held for review, oldest first
+// returns InternalError
+func (m *GormModerationRepository) PendingComments(ctx context.Context, projectID uuid.UUID) ([]*comment.Comment, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "moderation", "pendingcomments"}, time.Now())
+
+	var comments []*comment.Comment
+	err := m.db.Where("pending_review AND parent_id IN ("+projectWorkItemIDs+")", projectID.String()).Order("created_at").Find(&comments).Error
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return comments, nil
+}
+
+// Approve publishes a pending work item or comment of the project
+// returns NotFoundError, BadParameterError or InternalError
+func (m *GormModerationRepository) Approve(ctx context.Context, projectID uuid.UUID, kind string, id string) error {
+	defer goa.MeasureSince([]string{"goa", "db", "moderation", "approve"}, time.Now())
+
+	switch kind {
+	case KindWorkItem:
+		if err := m.exec(fmt.Sprintf("UPDATE work_items SET fields = jsonb_set(fields, '{%s}', 'false'), version = version + 1, updated_at = now() WHERE %s", workitem.SystemPendingReview, pendingWorkItem), kind, id, projectID); err != nil {
+			return err
+		}
+		return m.recordEvent(ctx, workitem.EventUpdate, id)
+	case KindComment:
+		return m.exec("UPDATE comments SET pending_review = false, updated_at = now() WHERE id = ? AND pending_review AND deleted_at IS NULL AND parent_id IN ("+projectWorkItemIDs+")", kind, id, projectID)
+	}
+	return errors.NewBadParameterError("kind", kind).Expected([]string{KindWorkItem, KindComment})
+}
+
+// Reject deletes a pending work item or comment of the project
+// returns NotFoundError, BadParameterError or InternalError
+func (m *GormModerationRepository) Reject(ctx context.Context, projectID uuid.UUID, kind string, id string) error {
+	defer goa.MeasureSince([]string{"goa", "db", "moderation", "reject"}, time.Now())
+
+	switch kind {
+	case KindWorkItem:
+		if err := m.exec("UPDATE work_items SET deleted_at = now() WHERE "+pendingWorkItem, kind, id, projectID); err != nil {
+			return err
+		}
+		return m.recordEvent(ctx, workitem.EventDelete, id)
+	case KindComment:
+		return m.exec("UPDATE comments SET deleted_at = now() WHERE id = ? AND pending_review AND deleted_at IS NULL AND parent_id IN ("+projectWorkItemIDs+")", kind, id, projectID)
+	}
+	return errors.NewBadParameterError("kind", kind).Expected([]string{KindWorkItem, KindComment})
+}
+
+func (m *GormModerationRepository) exec(sql string, kind string, id string, projectID uuid.UUID) error {
+	var err error
+	switch kind {
+	case KindWorkItem:
+		_, err = workitem.ParseWorkItemIDToUint64(id)
+	case KindComment:
+		_, err = uuid.FromString(id)
+	}
+	if err != nil {
+		return errors.NewNotFoundError("pending "+kind, id)
+	}
+	tx := m.db.Exec(sql, id, projectID.String())
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("pending "+kind, id)
+	}
+	return nil
+}
+
+// recordEvent records the event of a work item changed by exec
+func (m *GormModerationRepository) recordEvent(ctx context.Context, kind string, id string) error {
+	wiID, err := workitem.ParseWorkItemIDToUint64(id)
+	if err != nil {
+		return err
+	}
+	return workitem.RecordEvents(ctx, m.db, kind, wiID)
+}
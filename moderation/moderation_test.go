@@ -0,0 +1,187 @@
+package moderation_test
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/gormsupport/testfixture"
+	"github.com/almighty/almighty-core/moderation"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// spamFilter rejects contributions mentioning "buy now" and holds the ones
+// mentioning "discount" for review
+type spamFilter struct{}
+
+func (spamFilter) Check(ctx context.Context, c moderation.Content) (moderation.Verdict, error) {
+	switch {
+	case strings.Contains(c.Text, "buy now"):
+		return moderation.Reject, nil
+	case strings.Contains(c.Text, "discount"):
+		return moderation.Review, nil
+	}
+	return moderation.Accept, nil
+}
+
+func init() {
+	moderation.RegisterFilter(spamFilter{})
+}
+
+type TestModerationRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunModerationRepository(t *testing.T) {
+	suite.Run(t, &TestModerationRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestModerationRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestModerationRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestModerationRepository) createProject(public bool) *project.Project {
+	repo := project.NewRepository(test.DB)
+	p, err := repo.Create(context.Background(), "moderation-"+uuid.NewV4().String())
+	require.Nil(test.T(), err)
+	p.Public = public
+	p, err = repo.Save(context.Background(), *p)
+	require.Nil(test.T(), err)
+	return p
+}
+
+func (test *TestModerationRepository) TestPrivateProjectIsNotModerated() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	p := test.createProject(false)
+	pending, err := moderation.NewModerationRepository(test.DB).Check(context.Background(), moderation.Content{
+		Kind:      moderation.KindWorkItem,
+		ProjectID: p.ID,
+		AuthorID:  testfixture.CreateIdentity(t, test.DB, "contributor"),
+		Text:      "buy now",
+	})
+	require.Nil(t, err)
+	assert.False(t, pending)
+}
+
+func (test *TestModerationRepository) TestFilters() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := moderation.NewModerationRepository(test.DB)
+	content := moderation.Content{
+		Kind:      moderation.KindComment,
+		ProjectID: test.createProject(true).ID,
+		AuthorID:  testfixture.CreateIdentity(t, test.DB, "contributor"),
+		Text:      "buy now",
+	}
+	_, err := repo.Check(context.Background(), content)
+	assert.IsType(t, errors.BadParameterError{}, err)
+
+	content.Text = "ten percent discount"
+	pending, err := repo.Check(context.Background(), content)
+	require.Nil(t, err)
+	assert.True(t, pending)
+}
+
+func (test *TestModerationRepository) TestFirstTimeContributorIsReviewed() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := moderation.NewModerationRepository(test.DB)
+	p := test.createProject(true)
+	author := testfixture.CreateIdentity(t, test.DB, "author")
+	content := moderation.Content{Kind: moderation.KindWorkItem, ProjectID: p.ID, AuthorID: author, Text: "first"}
+
+	pending, err := repo.Check(ctx, content)
+	require.Nil(t, err)
+	require.True(t, pending)
+	wi, err := workitem.NewWorkItemRepository(test.DB).Create(ctx, workitem.SystemBug,
+		map[string]interface{}{
+			workitem.SystemTitle:         "first",
+			workitem.SystemState:         workitem.SystemStateNew,
+			workitem.SystemProject:       p.ID.String(),
+			workitem.SystemPendingReview: true,
+		}, author.String())
+	require.Nil(t, err)
+
+	c := comment.Comment{ParentID: wi.ID, Body: "first comment", CreatedBy: author, PendingReview: true}
+	require.Nil(t, comment.NewCommentRepository(test.DB).Create(ctx, &c))
+	comments, err := repo.PendingComments(ctx, p.ID)
+	require.Nil(t, err)
+	require.Len(t, comments, 1)
+	assert.Equal(t, c.ID, comments[0].ID)
+
+	// approving the work item makes the author a known contributor
+	require.Nil(t, repo.Approve(ctx, p.ID, moderation.KindWorkItem, wi.ID))
+	assert.IsType(t, errors.NotFoundError{}, repo.Approve(ctx, p.ID, moderation.KindWorkItem, wi.ID))
+	loaded, err := workitem.NewWorkItemRepository(test.DB).Load(ctx, wi.ID)
+	require.Nil(t, err)
+	assert.Equal(t, false, loaded.Fields[workitem.SystemPendingReview])
+	pending, err = repo.Check(ctx, content)
+	require.Nil(t, err)
+	assert.False(t, pending)
+
+	require.Nil(t, repo.Reject(ctx, p.ID, moderation.KindComment, c.ID.String()))
+	_, err = comment.NewCommentRepository(test.DB).Load(ctx, c.ID)
+	assert.IsType(t, errors.NotFoundError{}, err)
+	assert.IsType(t, errors.NotFoundError{}, repo.Reject(ctx, test.createProject(true).ID, moderation.KindComment, c.ID.String()))
+	assert.IsType(t, errors.BadParameterError{}, repo.Reject(ctx, p.ID, "projects", c.ID.String()))
+}
+
+func (test *TestModerationRepository) TestNewUserRateLimit() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	p := test.createProject(true)
+	author := testfixture.CreateIdentity(t, test.DB, "author")
+	for i := 0; i < 10; i++ {
+		_, err := workitem.NewWorkItemRepository(test.DB).Create(ctx, workitem.SystemBug,
+			map[string]interface{}{
+				workitem.SystemTitle: "contribution",
+				workitem.SystemState: workitem.SystemStateNew,
+			}, author.String())
+		require.Nil(t, err)
+	}
+	_, err := moderation.NewModerationRepository(test.DB).Check(ctx, moderation.Content{
+		Kind:      moderation.KindComment,
+		ProjectID: p.ID,
+		AuthorID:  author,
+		Text:      "one too many",
+	})
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
+
+func (test *TestModerationRepository) TestAnonymousContributionIsReviewed() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	pending, err := moderation.NewModerationRepository(test.DB).Check(context.Background(), moderation.Content{
+		Kind:      moderation.KindWorkItem,
+		ProjectID: test.createProject(true).ID,
+		AuthorID:  account.AnonymousIdentityID,
+		Text:      "feedback",
+	})
+	require.Nil(t, err)
+	assert.True(t, pending)
+}
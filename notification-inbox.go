@@ -0,0 +1,129 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/notification"
+	"github.com/goadesign/goa"
+)
+
+// NotificationInboxController implements the notification-inbox resource.
+type NotificationInboxController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewNotificationInboxController creates a notification-inbox controller.
+func NewNotificationInboxController(service *goa.Service, db application.DB) *NotificationInboxController {
+	return &NotificationInboxController{Controller: service.NewController("NotificationInboxController"), db: db}
+}
+
+// List runs the list action.
+func (c *NotificationInboxController) List(ctx *app.ListNotificationInboxContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	offset, limit := computePagingLimts(ctx.PageOffset, ctx.PageLimit)
+	unread := ctx.FilterUnread != nil && *ctx.FilterUnread
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		entries, total, err := appl.NotificationInbox().List(ctx, *identityID, unread, offset, limit)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		unreadCount, err := appl.NotificationInbox().Unread(ctx, *identityID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.InboxNotificationList{
+			Links: &app.PagingLinks{},
+			Meta:  &app.InboxMeta{TotalCount: int(total), UnreadCount: unreadCount},
+			Data:  make([]*app.InboxNotification, len(entries)),
+		}
+		for i, e := range entries {
+			res.Data[i] = ConvertInboxNotification(ctx.RequestData, e)
+		}
+		var additionalQuery []string
+		if unread {
+			additionalQuery = append(additionalQuery, "filter[unread]=true")
+		}
+		setPagingLinks(res.Links, buildAbsoluteURL(ctx.RequestData), len(entries), offset, limit, int(total), additionalQuery...)
+		return ctx.OK(res)
+	})
+}
+
+// Read runs the read action.
+func (c *NotificationInboxController) Read(ctx *app.ReadNotificationInboxContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if err := appl.NotificationInbox().MarkRead(ctx, *identityID, ctx.ID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.NoContent()
+	})
+}
+
+// Snooze runs the snooze action.
+func (c *NotificationInboxController) Snooze(ctx *app.SnoozeNotificationInboxContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if err := appl.NotificationInbox().Snooze(ctx, *identityID, ctx.ID, ctx.Until); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.NoContent()
+	})
+}
+
+// ReadAll runs the read-all action.
+func (c *NotificationInboxController) ReadAll(ctx *app.ReadAllNotificationInboxContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if _, err := appl.NotificationInbox().MarkAllRead(ctx, *identityID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.NoContent()
+	})
+}
+
+// ConvertInboxNotification converts from internal to external REST
+// representation
+func ConvertInboxNotification(request *goa.RequestData, e *notification.Entry) *app.InboxNotification {
+	workItemType := "workitems"
+	workItemID := e.WorkItemID
+	workItemSelfURL := AbsoluteURL(request, app.WorkitemHref(workItemID))
+	res := &app.InboxNotification{
+		Type: "notifications",
+		ID:   e.ID,
+		Attributes: &app.InboxNotificationAttributes{
+			Event:        e.Event,
+			Subject:      e.Subject,
+			At:           e.At,
+			ReadAt:       e.ReadAt,
+			SnoozedUntil: e.SnoozedUntil,
+		},
+		Relationships: &app.InboxNotificationRelations{
+			Workitem: &app.RelationGeneric{
+				Data:  &app.GenericData{Type: &workItemType, ID: &workItemID},
+				Links: &app.GenericLinks{Self: &workItemSelfURL},
+			},
+		},
+	}
+	if e.ProjectID != nil {
+		projectType := "projects"
+		projectID := e.ProjectID.String()
+		res.Relationships.Project = &app.RelationGeneric{
+			Data: &app.GenericData{Type: &projectType, ID: &projectID},
+		}
+	}
+	return res
+}
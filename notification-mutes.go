@@ -0,0 +1,108 @@
+package main
+
+import (
+	"time"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/notification"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// NotificationMutesController implements the notification-mutes resource.
+type NotificationMutesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewNotificationMutesController creates a notification-mutes controller.
+func NewNotificationMutesController(service *goa.Service, db application.DB) *NotificationMutesController {
+	return &NotificationMutesController{Controller: service.NewController("NotificationMutesController"), db: db}
+}
+
+// List runs the list action.
+func (c *NotificationMutesController) List(ctx *app.ListNotificationMutesContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		res, err := listNotificationMutes(ctx, appl, *identityID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Mute runs the mute action.
+func (c *NotificationMutesController) Mute(ctx *app.MuteNotificationMutesContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	m := notification.Mute{IdentityID: *identityID, WorkItemID: ctx.Payload.Workitem, ProjectID: ctx.Payload.Project}
+	switch {
+	case ctx.Payload.Until != nil && ctx.Payload.Duration == nil:
+		m.Until = *ctx.Payload.Until
+	case ctx.Payload.Until == nil && ctx.Payload.Duration != nil:
+		d, err := time.ParseDuration(*ctx.Payload.Duration)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("duration", *ctx.Payload.Duration).Expected("a duration like 8h"))
+		}
+		m.Until = time.Now().Add(d)
+	default:
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("until", ctx.Payload.Until).Expected("either an end or a duration"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if m.WorkItemID != nil {
+			if _, err := appl.WorkItems().Load(ctx, *m.WorkItemID); err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+		}
+		if m.ProjectID != nil {
+			if _, err := appl.Projects().Load(ctx, *m.ProjectID); err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+		}
+		if err := appl.NotificationMutes().Mute(ctx, &m); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res, err := listNotificationMutes(ctx, appl, *identityID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Unmute runs the unmute action.
+func (c *NotificationMutesController) Unmute(ctx *app.UnmuteNotificationMutesContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if err := appl.NotificationMutes().Unmute(ctx, *identityID, ctx.ID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.NoContent()
+	})
+}
+
+// listNotificationMutes returns the mutes of the identity that didn't end yet
+func listNotificationMutes(ctx context.Context, appl application.Application, identityID uuid.UUID) (*app.NotificationMuteList, error) {
+	mutes, err := appl.NotificationMutes().List(ctx, identityID)
+	if err != nil {
+		return nil, err
+	}
+	res := &app.NotificationMuteList{Data: make([]*app.NotificationMute, len(mutes))}
+	for i, m := range mutes {
+		res.Data[i] = &app.NotificationMute{ID: m.ID, Workitem: m.WorkItemID, Project: m.ProjectID, Until: m.Until}
+	}
+	return res, nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/notification"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// NotificationPreferencesController implements the notification-preferences resource.
+type NotificationPreferencesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewNotificationPreferencesController creates a notification-preferences controller.
+func NewNotificationPreferencesController(service *goa.Service, db application.DB) *NotificationPreferencesController {
+	return &NotificationPreferencesController{Controller: service.NewController("NotificationPreferencesController"), db: db}
+}
+
+// List runs the list action.
+func (c *NotificationPreferencesController) List(ctx *app.ListNotificationPreferencesContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		res, err := listNotificationPreferences(ctx, appl, *identityID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Update runs the update action.
+func (c *NotificationPreferencesController) Update(ctx *app.UpdateNotificationPreferencesContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	p := notification.Preferences{IdentityID: *identityID, ProjectID: ctx.Payload.Project, Matrix: notification.Matrix(ctx.Payload.Matrix)}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if p.ProjectID != nil {
+			if _, err := appl.Projects().Load(ctx, *p.ProjectID); err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+		}
+		if err := appl.NotificationPreferences().Save(ctx, p); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res, err := listNotificationPreferences(ctx, appl, *identityID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Reset runs the reset action.
+func (c *NotificationPreferencesController) Reset(ctx *app.ResetNotificationPreferencesContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if err := appl.NotificationPreferences().Delete(ctx, *identityID, ctx.ProjectID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.NoContent()
+	})
+}
+
+// listNotificationPreferences returns the preferences of the identity with
+// the events and the channels to choose from
+func listNotificationPreferences(ctx context.Context, appl application.Application, identityID uuid.UUID) (*app.NotificationPreferenceList, error) {
+	preferences, err := appl.NotificationPreferences().List(ctx, identityID)
+	if err != nil {
+		return nil, err
+	}
+	res := &app.NotificationPreferenceList{
+		Data:     make([]*app.NotificationPreferences, len(preferences)),
+		Events:   notification.Events,
+		Channels: notification.Channels,
+	}
+	for i, p := range preferences {
+		res.Data[i] = &app.NotificationPreferences{Project: p.ProjectID, Matrix: p.Matrix}
+	}
+	return res, nil
+}
@@ -0,0 +1,200 @@
+package notification
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// InboxRetention is how long read notifications stay in the inbox
+const InboxRetention = 90 * 24 * time.Hour
+
+// notSnoozed selects the notifications that aren't snoozed at the moment
+const notSnoozed = "(snoozed_until IS NULL OR snoozed_until <= now())"
+
+// Entry is a notification in the inbox of its recipient
+type Entry struct {
+	CreatedAt   time.Time
+	ID          uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	RecipientID uuid.UUID `sql:"type:uuid"`
+	Event       string
+	WorkItemID  string
+	ProjectID   *uuid.UUID `sql:"type:uuid"`
+	Subject     string
+	// At is when the event happened
+	At time.Time
+	// ReadAt is when the recipient read the notification, nil if unread
+	ReadAt *time.Time
+	// SnoozedUntil hides the notification from the inbox until then
+	SnoozedUntil *time.Time
+}
+
+// TableName implements gorm.tabler
+func (e Entry) TableName() string {
+	return "notification_inbox"
+}
+
+// InboxRepository encapsulates storage & retrieval of the notifications in
+// the inboxes of identities
+type InboxRepository interface {
+	Add(ctx context.Context, n Notification) error
+	List(ctx context.Context, recipientID uuid.UUID, unread bool, start int, limit int) ([]*Entry, uint64, error)
+	Unread(ctx context.Context, recipientID uuid.UUID) (int, error)
+	MarkRead(ctx context.Context, recipientID uuid.UUID, id uuid.UUID) error
+	MarkAllRead(ctx context.Context, recipientID uuid.UUID) (int64, error)
+	Snooze(ctx context.Context, recipientID uuid.UUID, id uuid.UUID, until time.Time) error
+}
+
+// NewInboxRepository creates a new storage type.
+func NewInboxRepository(db *gorm.DB) InboxRepository {
+	return &GormInboxRepository{db: db}
+}
+
+// GormInboxRepository is the implementation of the storage interface for
+// inboxes.
+type GormInboxRepository struct {
+	db *gorm.DB
+}
+
+// Add puts the notification into the inbox of its recipient, a notification
+// delivered again is only added once. Read notifications older than
+// InboxRetention are removed from the inbox.
+// returns InternalError
+func (m *GormInboxRepository) Add(ctx context.Context, n Notification) error {
+	defer goa.MeasureSince([]string{"goa", "db", "notificationinbox", "add"}, time.Now())
+
+	var projectID *uuid.UUID
+	if !uuid.Equal(n.ProjectID, uuid.Nil) {
+		projectID = &n.ProjectID
+	}
+	err := m.db.Exec(`INSERT INTO notification_inbox (recipient_id, event, work_item_id, project_id, subject, at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, now())
+		ON CONFLICT (recipient_id, event, work_item_id, at) DO NOTHING`,
+		n.RecipientID, n.Event, n.WorkItemID, projectID, n.Subject, n.At).Error
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	err = m.db.Where("recipient_id = ? AND read_at < ?", n.RecipientID, time.Now().Add(-InboxRetention)).Delete(Entry{}).Error
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// List returns the notifications in the inbox of the identity, the latest
+// first, and their total number; only the unread ones if unread is true.
+// Snoozed notifications are left out and count as of when their snooze ends.
+// returns BadParameterError or InternalError
+func (m *GormInboxRepository) List(ctx context.Context, recipientID uuid.UUID, unread bool, start int, limit int) ([]*Entry, uint64, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "notificationinbox", "list"}, time.Now())
+
+	if start < 0 {
+		return nil, 0, errors.NewBadParameterError("start", start).Expected("non-negative")
+	}
+	if limit <= 0 {
+		return nil, 0, errors.NewBadParameterError("limit", limit).Expected("greater than 0")
+	}
+	db := m.db.Model(&Entry{}).Where("recipient_id = ?", recipientID).Where(notSnoozed)
+	if unread {
+		db = db.Where("read_at IS NULL")
+	}
+	var count uint64
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, errors.NewInternalError(err.Error())
+	}
+	var objs []*Entry
+	if err := db.Order("coalesce(snoozed_until, at) DESC, id").Offset(start).Limit(limit).Find(&objs).Error; err != nil {
+		return nil, 0, errors.NewInternalError(err.Error())
+	}
+	return objs, count, nil
+}
+
+// Unread returns the number of unread notifications in the inbox of the
+// identity that aren't snoozed
+// returns InternalError
+func (m *GormInboxRepository) Unread(ctx context.Context, recipientID uuid.UUID) (int, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "notificationinbox", "unread"}, time.Now())
+
+	var count int
+	if err := m.db.Model(&Entry{}).Where("recipient_id = ? AND read_at IS NULL", recipientID).Where(notSnoozed).Count(&count).Error; err != nil {
+		return 0, errors.NewInternalError(err.Error())
+	}
+	return count, nil
+}
+
+// MarkRead marks the notification in the inbox of the identity as read,
+// marking it again has no effect
+// returns NotFoundError or InternalError
+func (m *GormInboxRepository) MarkRead(ctx context.Context, recipientID uuid.UUID, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "notificationinbox", "markread"}, time.Now())
+
+	tx := m.db.Model(&Entry{}).Where("id = ? AND recipient_id = ?", id, recipientID).
+		UpdateColumn("read_at", gorm.Expr("coalesce(read_at, now())"))
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("notification", id.String())
+	}
+	return nil
+}
+
+// MarkAllRead marks all notifications in the inbox of the identity as read
+// and returns how many were unread, snoozed notifications are left unread
+// returns InternalError
+func (m *GormInboxRepository) MarkAllRead(ctx context.Context, recipientID uuid.UUID) (int64, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "notificationinbox", "markallread"}, time.Now())
+
+	tx := m.db.Model(&Entry{}).Where("recipient_id = ? AND read_at IS NULL", recipientID).Where(notSnoozed).
+		UpdateColumn("read_at", gorm.Expr("now()"))
+	if tx.Error != nil {
+		return 0, errors.NewInternalError(tx.Error.Error())
+	}
+	return tx.RowsAffected, nil
+}
+
+// Snooze hides the notification in the inbox of the identity until the given
+// time, when it shows up again as the latest one
+// returns BadParameterError, NotFoundError or InternalError
+func (m *GormInboxRepository) Snooze(ctx context.Context, recipientID uuid.UUID, id uuid.UUID, until time.Time) error {
+	defer goa.MeasureSince([]string{"goa", "db", "notificationinbox", "snooze"}, time.Now())
+
+	if !until.After(time.Now()) {
+		return errors.NewBadParameterError("until", until).Expected("in the future")
+	}
+	tx := m.db.Model(&Entry{}).Where("id = ? AND recipient_id = ?", id, recipientID).UpdateColumn("snoozed_until", until)
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("notification", id.String())
+	}
+	return nil
+}
+
+// InboxChannel delivers notifications to the inboxes of their recipients
+// shown by the web and mobile clients
+type InboxChannel struct {
+	db *gorm.DB
+}
+
+// NewInboxChannel creates a channel storing notifications in the given
+// database
+func NewInboxChannel(db *gorm.DB) *InboxChannel {
+	return &InboxChannel{db: db}
+}
+
+// Name implements Channel
+func (c *InboxChannel) Name() string {
+	return ChannelWeb
+}
+
+// Deliver implements Channel
+func (c *InboxChannel) Deliver(ctx context.Context, n Notification) error {
+	return NewInboxRepository(c.db).Add(ctx, n)
+}
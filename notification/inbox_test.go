@@ -0,0 +1,87 @@
+package notification_test
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/notification"
+	"github.com/almighty/almighty-core/resource"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestInboxRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunInboxRepository(t *testing.T) {
+	suite.Run(t, &TestInboxRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestInboxRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestInboxRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestInboxRepository) TestInbox() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	identity := account.Identity{FullName: "inbox"}
+	require.Nil(t, account.NewIdentityRepository(test.DB).Create(ctx, &identity))
+	channel := notification.NewInboxChannel(test.DB)
+	repo := notification.NewInboxRepository(test.DB)
+
+	at := time.Now().Round(time.Second)
+	n := notification.Notification{Event: notification.EventStale, RecipientID: identity.ID, WorkItemID: "1", Subject: "No activity on one", At: at}
+	require.Nil(t, channel.Deliver(ctx, n))
+	// delivered again after another channel failed
+	require.Nil(t, channel.Deliver(ctx, n))
+	n.WorkItemID, n.Subject, n.At = "2", "No activity on two", at.Add(time.Minute)
+	require.Nil(t, channel.Deliver(ctx, n))
+
+	entries, total, err := repo.List(ctx, identity.ID, false, 0, 1)
+	require.Nil(t, err)
+	assert.Equal(t, uint64(2), total)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "2", entries[0].WorkItemID)
+	assert.Nil(t, entries[0].ProjectID)
+
+	require.Nil(t, repo.MarkRead(ctx, identity.ID, entries[0].ID))
+	require.Nil(t, repo.MarkRead(ctx, identity.ID, entries[0].ID))
+	unread, err := repo.Unread(ctx, identity.ID)
+	require.Nil(t, err)
+	assert.Equal(t, 1, unread)
+	entries, total, err = repo.List(ctx, identity.ID, true, 0, 10)
+	require.Nil(t, err)
+	assert.Equal(t, uint64(1), total)
+	assert.Equal(t, "1", entries[0].WorkItemID)
+
+	assert.IsType(t, errors.NotFoundError{}, repo.MarkRead(ctx, identity.ID, uuid.NewV4()))
+	assert.IsType(t, errors.BadParameterError{}, repo.Snooze(ctx, identity.ID, entries[0].ID, time.Now().Add(-time.Minute)))
+	assert.IsType(t, errors.NotFoundError{}, repo.Snooze(ctx, identity.ID, uuid.NewV4(), time.Now().Add(time.Hour)))
+	// snoozed notifications are hidden and stay unread
+	require.Nil(t, repo.Snooze(ctx, identity.ID, entries[0].ID, time.Now().Add(time.Hour)))
+	unread, err = repo.Unread(ctx, identity.ID)
+	require.Nil(t, err)
+	assert.Equal(t, 0, unread)
+	_, total, err = repo.List(ctx, identity.ID, false, 0, 10)
+	require.Nil(t, err)
+	assert.Equal(t, uint64(1), total)
+	read, err := repo.MarkAllRead(ctx, identity.ID)
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), read)
+}
@@ -0,0 +1,128 @@
+package notification
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Mute stops the notifications of an identity about a work item or about
+// all work items of a project until a given time
+type Mute struct {
+	CreatedAt  time.Time
+	ID         uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	IdentityID uuid.UUID `sql:"type:uuid"`
+	// Either the work item or the project is muted
+	WorkItemID *string
+	ProjectID  *uuid.UUID `sql:"type:uuid"`
+	Until      time.Time
+}
+
+// TableName implements gorm.tabler
+func (m Mute) TableName() string {
+	return "notification_mutes"
+}
+
+// Validate checks that the mute is for either a work item or a project and
+// ends in the future
+// returns BadParameterError
+func (m Mute) Validate() error {
+	if (m.WorkItemID == nil) == (m.ProjectID == nil) {
+		return errors.NewBadParameterError("workitem", m.WorkItemID).Expected("either a work item or a project")
+	}
+	if !m.Until.After(time.Now()) {
+		return errors.NewBadParameterError("until", m.Until).Expected("in the future")
+	}
+	return nil
+}
+
+// MuteRepository encapsulates storage & retrieval of mutes
+type MuteRepository interface {
+	Mute(ctx context.Context, m *Mute) error
+	List(ctx context.Context, identityID uuid.UUID) ([]*Mute, error)
+	Unmute(ctx context.Context, identityID uuid.UUID, id uuid.UUID) error
+	Muted(ctx context.Context, n Notification) (bool, error)
+}
+
+// NewMuteRepository creates a new storage type.
+func NewMuteRepository(db *gorm.DB) MuteRepository {
+	return &GormMuteRepository{db: db}
+}
+
+// GormMuteRepository is the implementation of the storage interface for
+// mutes.
+type GormMuteRepository struct {
+	db *gorm.DB
+}
+
+// Mute stores the mute, it replaces a mute of the identity for the same work
+// item or project. Expired mutes of the identity are removed.
+// returns BadParameterError or InternalError
+func (m *GormMuteRepository) Mute(ctx context.Context, mute *Mute) error {
+	defer goa.MeasureSince([]string{"goa", "db", "notificationmute", "mute"}, time.Now())
+
+	if err := mute.Validate(); err != nil {
+		return err
+	}
+	db := m.db.Where("identity_id = ?", mute.IdentityID)
+	if mute.WorkItemID != nil {
+		db = db.Where("until <= now() OR work_item_id = ?", *mute.WorkItemID)
+	} else {
+		db = db.Where("until <= now() OR project_id = ?", *mute.ProjectID)
+	}
+	if err := db.Delete(Mute{}).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if err := m.db.Create(mute).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// List returns the mutes of the identity that didn't end yet, the ones ending
+// first first
+// returns InternalError
+func (m *GormMuteRepository) List(ctx context.Context, identityID uuid.UUID) ([]*Mute, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "notificationmute", "list"}, time.Now())
+
+	var objs []*Mute
+	if err := m.db.Where("identity_id = ? AND until > now()", identityID).Order("until, id").Find(&objs).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return objs, nil
+}
+
+// Unmute ends the mute of the identity
+// returns NotFoundError or InternalError
+func (m *GormMuteRepository) Unmute(ctx context.Context, identityID uuid.UUID, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "notificationmute", "unmute"}, time.Now())
+
+	tx := m.db.Where("id = ? AND identity_id = ? AND until > now()", id, identityID).Delete(Mute{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("mute", id.String())
+	}
+	return nil
+}
+
+// Muted returns whether the recipient muted the work item or the project of
+// the notification
+// returns InternalError
+func (m *GormMuteRepository) Muted(ctx context.Context, n Notification) (bool, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "notificationmute", "muted"}, time.Now())
+
+	var count int
+	err := m.db.Model(&Mute{}).Where("identity_id = ? AND until > now() AND (work_item_id = ? OR project_id = ?)", n.RecipientID, n.WorkItemID, n.ProjectID).
+		Count(&count).Error
+	if err != nil {
+		return false, errors.NewInternalError(err.Error())
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,76 @@
+package notification_test
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/notification"
+	"github.com/almighty/almighty-core/resource"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestMuteRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunMuteRepository(t *testing.T) {
+	suite.Run(t, &TestMuteRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestMuteRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestMuteRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestMuteRepository) TestMute() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	identity := account.Identity{FullName: "mute"}
+	require.Nil(t, account.NewIdentityRepository(test.DB).Create(ctx, &identity))
+	repo := notification.NewMuteRepository(test.DB)
+	workItemID := "1"
+	n := notification.Notification{Event: notification.EventStale, RecipientID: identity.ID, WorkItemID: workItemID}
+
+	assert.IsType(t, errors.BadParameterError{}, repo.Mute(ctx, &notification.Mute{IdentityID: identity.ID, Until: time.Now().Add(time.Hour)}))
+	assert.IsType(t, errors.BadParameterError{}, repo.Mute(ctx, &notification.Mute{IdentityID: identity.ID, WorkItemID: &workItemID, Until: time.Now().Add(-time.Hour)}))
+
+	require.Nil(t, repo.Mute(ctx, &notification.Mute{IdentityID: identity.ID, WorkItemID: &workItemID, Until: time.Now().Add(time.Hour)}))
+	// muting again replaces the mute
+	m := notification.Mute{IdentityID: identity.ID, WorkItemID: &workItemID, Until: time.Now().Add(2 * time.Hour)}
+	require.Nil(t, repo.Mute(ctx, &m))
+	mutes, err := repo.List(ctx, identity.ID)
+	require.Nil(t, err)
+	require.Len(t, mutes, 1)
+	assert.Equal(t, m.ID, mutes[0].ID)
+
+	muted, err := repo.Muted(ctx, n)
+	require.Nil(t, err)
+	assert.True(t, muted)
+	n.WorkItemID = "2"
+	muted, err = repo.Muted(ctx, n)
+	require.Nil(t, err)
+	assert.False(t, muted)
+
+	require.Nil(t, repo.Unmute(ctx, identity.ID, m.ID))
+	assert.IsType(t, errors.NotFoundError{}, repo.Unmute(ctx, identity.ID, m.ID))
+	assert.IsType(t, errors.NotFoundError{}, repo.Unmute(ctx, identity.ID, uuid.NewV4()))
+	n.WorkItemID = workItemID
+	muted, err = repo.Muted(ctx, n)
+	require.Nil(t, err)
+	assert.False(t, muted)
+}
@@ -0,0 +1,159 @@
+// Package notification tells identities about events on work items through
+// the registered channels. Notifications are delivered by background jobs, a
+// notification is delivered again on all channels if one of them fails.
+package notification
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/job"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Events notifications are sent for
+const (
+	// EventStale is sent to the assignees of a work item without activity
+	EventStale = "workitem.stale"
+	// EventInactive is sent to the assignees of a stale work item that was
+	// moved to the inactive state
+	EventInactive = "workitem.inactive"
+	// EventEscalated is sent to the secondary contact and the assignees of a
+	// work item escalated by the escalation policy of its project
+	EventEscalated = "workitem.escalated"
+)
+
+// Events are all events notifications are sent for
+var Events = []string{EventStale, EventInactive, EventEscalated}
+
+// Channels identities choose to be notified through, see Preferences
+const (
+	ChannelEmail = "email"
+	ChannelWeb   = "web"
+	ChannelChat  = "chat"
+	ChannelPush  = "push"
+)
+
+// Channels are all channels identities choose to be notified through
+var Channels = []string{ChannelEmail, ChannelWeb, ChannelChat, ChannelPush}
+
+// JobKind is the kind of the jobs delivering notifications
+const JobKind = "notification.deliver"
+
+// TimeLayout is the layout of the times in notifications
+const TimeLayout = "Mon, 02 Jan 2006 15:04 MST"
+
+// Notification tells an identity about an event on a work item
+type Notification struct {
+	Event       string
+	RecipientID uuid.UUID
+	WorkItemID  string
+	// ProjectID is the project of the work item, the preferences of the
+	// recipient for the project override the defaults
+	ProjectID uuid.UUID
+	Subject   string
+	// At is when the event happened
+	At time.Time
+	// Timezone is the IANA timezone of the recipient, empty for UTC
+	Timezone string
+}
+
+// Time returns when the event happened in the timezone of the recipient
+func (n Notification) Time() string {
+	loc, err := time.LoadLocation(n.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return n.At.In(loc).Format(TimeLayout)
+}
+
+// Channel delivers notifications to their recipients, e.g. by email
+type Channel interface {
+	Name() string
+	Deliver(ctx context.Context, n Notification) error
+}
+
+var channels = []Channel{LogChannel{}}
+
+// preferencesDB stores the preferences and mutes deliveries follow, nil
+// delivers on all channels
+var preferencesDB *gorm.DB
+
+// RegisterChannel adds a channel notifications are delivered through.
+// Channels must be registered during initialization.
+func RegisterChannel(c Channel) {
+	channels = append(channels, c)
+}
+
+// UsePreferences makes deliveries skip the channels the recipients turned
+// off for the event, see Preferences, and the notifications about work items
+// and projects the recipients muted, see Mute. It must be called during
+// initialization.
+func UsePreferences(db *gorm.DB) {
+	preferencesDB = db
+}
+
+// LogChannel writes notifications to the server log
+type LogChannel struct{}
+
+// Name implements Channel
+func (LogChannel) Name() string {
+	return "log"
+}
+
+// Deliver implements Channel
+func (LogChannel) Deliver(ctx context.Context, n Notification) error {
+	log.Printf("notify %s of %s on work item %s at %s: %s\n", n.RecipientID, n.Event, n.WorkItemID, n.Time(), n.Subject)
+	return nil
+}
+
+// Notify enqueues the delivery of the notification, within a transaction the
+// notification is only delivered if the transaction commits
+// returns BadParameterError or InternalError
+func Notify(ctx context.Context, jobs job.Repository, n Notification) error {
+	_, err := jobs.Enqueue(ctx, JobKind, n)
+	return err
+}
+
+// deliver is the handler of the notification jobs
+func deliver(ctx context.Context, payload []byte) error {
+	var n Notification
+	if err := json.Unmarshal(payload, &n); err != nil {
+		return errors.NewConversionError(err.Error())
+	}
+	muted := false
+	if preferencesDB != nil {
+		var err error
+		if muted, err = NewMuteRepository(preferencesDB).Muted(ctx, n); err != nil {
+			return err
+		}
+	}
+	for _, c := range channels {
+		// the server log is no channel of the recipient
+		if _, ok := c.(LogChannel); !ok && preferencesDB != nil {
+			if muted {
+				continue
+			}
+			enabled, err := NewPreferenceRepository(preferencesDB).Enabled(ctx, n, c.Name())
+			if err != nil {
+				return err
+			}
+			if !enabled {
+				continue
+			}
+		}
+		if err := c.Deliver(ctx, n); err != nil {
+			return errors.NewInternalError(c.Name() + ": " + err.Error())
+		}
+	}
+	return nil
+}
+
+func init() {
+	job.Register(JobKind, deliver)
+}
@@ -0,0 +1,175 @@
+package notification
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Matrix tells by event and channel whether an identity is notified, events
+// and channels that aren't in the matrix are left to the defaults
+type Matrix map[string]map[string]bool
+
+// Preferences are the choices of an identity how to be notified, either the
+// defaults or the overrides of the defaults for a project
+type Preferences struct {
+	IdentityID uuid.UUID
+	// ProjectID is the project the preferences override the defaults for,
+	// nil for the defaults
+	ProjectID *uuid.UUID
+	Matrix    Matrix
+}
+
+// Validate checks the events and the channels of the matrix
+// returns BadParameterError
+func (p Preferences) Validate() error {
+	for event, channels := range p.Matrix {
+		if !contains(Events, event) {
+			return errors.NewBadParameterError("event", event).Expected(Events)
+		}
+		for channel := range channels {
+			if !contains(Channels, channel) {
+				return errors.NewBadParameterError("channel", channel).Expected(Channels)
+			}
+		}
+	}
+	return nil
+}
+
+// preference is a cell of the matrix of preferences
+type preference struct {
+	CreatedAt  time.Time
+	IdentityID uuid.UUID  `sql:"type:uuid"`
+	ProjectID  *uuid.UUID `sql:"type:uuid"`
+	Event      string
+	Channel    string
+	Enabled    bool
+}
+
+// TableName implements gorm.tabler
+func (p preference) TableName() string {
+	return "notification_preferences"
+}
+
+// PreferenceRepository encapsulates storage & retrieval of notification
+// preferences
+type PreferenceRepository interface {
+	List(ctx context.Context, identityID uuid.UUID) ([]*Preferences, error)
+	Save(ctx context.Context, p Preferences) error
+	Delete(ctx context.Context, identityID uuid.UUID, projectID uuid.UUID) error
+	Enabled(ctx context.Context, n Notification, channel string) (bool, error)
+}
+
+// NewPreferenceRepository creates a new storage type.
+func NewPreferenceRepository(db *gorm.DB) PreferenceRepository {
+	return &GormPreferenceRepository{db: db}
+}
+
+// GormPreferenceRepository is the implementation of the storage interface
+// for notification preferences.
+type GormPreferenceRepository struct {
+	db *gorm.DB
+}
+
+// List returns the defaults of the identity followed by its overrides by
+// project
+// returns InternalError
+func (m *GormPreferenceRepository) List(ctx context.Context, identityID uuid.UUID) ([]*Preferences, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "notificationpreference", "list"}, time.Now())
+
+	var rows []preference
+	if err := m.db.Where("identity_id = ?", identityID).Order("project_id NULLS FIRST, event, channel").Find(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	res := []*Preferences{{IdentityID: identityID, Matrix: Matrix{}}}
+	for _, row := range rows {
+		p := res[len(res)-1]
+		if row.ProjectID != nil && (p.ProjectID == nil || !uuid.Equal(*p.ProjectID, *row.ProjectID)) {
+			p = &Preferences{IdentityID: identityID, ProjectID: row.ProjectID, Matrix: Matrix{}}
+			res = append(res, p)
+		}
+		if p.Matrix[row.Event] == nil {
+			p.Matrix[row.Event] = map[string]bool{}
+		}
+		p.Matrix[row.Event][row.Channel] = row.Enabled
+	}
+	return res, nil
+}
+
+// Save replaces the defaults or the overrides for a project of the identity
+// returns BadParameterError or InternalError
+func (m *GormPreferenceRepository) Save(ctx context.Context, p Preferences) error {
+	defer goa.MeasureSince([]string{"goa", "db", "notificationpreference", "save"}, time.Now())
+
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	db := m.db.Where("identity_id = ?", p.IdentityID)
+	if p.ProjectID == nil {
+		db = db.Where("project_id IS NULL")
+	} else {
+		db = db.Where("project_id = ?", *p.ProjectID)
+	}
+	if err := db.Delete(preference{}).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	for event, channels := range p.Matrix {
+		for channel, enabled := range channels {
+			row := preference{IdentityID: p.IdentityID, ProjectID: p.ProjectID, Event: event, Channel: channel, Enabled: enabled}
+			if err := m.db.Create(&row).Error; err != nil {
+				return errors.NewInternalError(err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// Delete removes the overrides for the project, the defaults of the identity
+// apply again
+// returns NotFoundError or InternalError
+func (m *GormPreferenceRepository) Delete(ctx context.Context, identityID uuid.UUID, projectID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "notificationpreference", "delete"}, time.Now())
+
+	tx := m.db.Where("identity_id = ? AND project_id = ?", identityID, projectID).Delete(preference{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("notification preferences", projectID.String())
+	}
+	return nil
+}
+
+// Enabled returns whether the recipient of the notification is notified
+// through the channel: the override for the project of the work item if
+// there is one, otherwise the default of the recipient, otherwise true
+// returns InternalError
+func (m *GormPreferenceRepository) Enabled(ctx context.Context, n Notification, channel string) (bool, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "notificationpreference", "enabled"}, time.Now())
+
+	var row preference
+	tx := m.db.Where("identity_id = ? AND event = ? AND channel = ? AND (project_id IS NULL OR project_id = ?)", n.RecipientID, n.Event, channel, n.ProjectID).
+		Order("project_id NULLS LAST").First(&row)
+	if tx.RecordNotFound() {
+		return true, nil
+	}
+	if tx.Error != nil {
+		return false, errors.NewInternalError(tx.Error.Error())
+	}
+	return row.Enabled, nil
+}
+
+// contains returns true if the value is one of the values
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
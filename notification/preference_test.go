@@ -0,0 +1,80 @@
+package notification_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/notification"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestPreferenceRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunPreferenceRepository(t *testing.T) {
+	suite.Run(t, &TestPreferenceRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestPreferenceRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestPreferenceRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestPreferenceRepository) TestPreferences() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	identity := account.Identity{FullName: "notified"}
+	require.Nil(t, account.NewIdentityRepository(test.DB).Create(ctx, &identity))
+	p, err := project.NewRepository(test.DB).Create(ctx, "notification-"+uuid.NewV4().String())
+	require.Nil(t, err)
+	repo := notification.NewPreferenceRepository(test.DB)
+
+	require.Nil(t, repo.Save(ctx, notification.Preferences{IdentityID: identity.ID, Matrix: notification.Matrix{
+		notification.EventStale: {notification.ChannelPush: false, notification.ChannelEmail: true},
+	}}))
+	require.Nil(t, repo.Save(ctx, notification.Preferences{IdentityID: identity.ID, ProjectID: &p.ID, Matrix: notification.Matrix{
+		notification.EventStale: {notification.ChannelPush: true},
+	}}))
+	all, err := repo.List(ctx, identity.ID)
+	require.Nil(t, err)
+	require.Len(t, all, 2)
+	assert.Nil(t, all[0].ProjectID)
+	assert.Equal(t, notification.Matrix{notification.EventStale: {notification.ChannelPush: false, notification.ChannelEmail: true}}, all[0].Matrix)
+	assert.Equal(t, p.ID, *all[1].ProjectID)
+
+	n := notification.Notification{Event: notification.EventStale, RecipientID: identity.ID}
+	enabled, err := repo.Enabled(ctx, n, notification.ChannelPush)
+	require.Nil(t, err)
+	assert.False(t, enabled)
+	n.ProjectID = p.ID
+	enabled, err = repo.Enabled(ctx, n, notification.ChannelPush)
+	require.Nil(t, err)
+	assert.True(t, enabled)
+	n.Event = notification.EventEscalated
+	enabled, err = repo.Enabled(ctx, n, notification.ChannelPush)
+	require.Nil(t, err)
+	assert.True(t, enabled)
+
+	require.Nil(t, repo.Delete(ctx, identity.ID, p.ID))
+	assert.IsType(t, errors.NotFoundError{}, repo.Delete(ctx, identity.ID, p.ID))
+	assert.IsType(t, errors.BadParameterError{}, repo.Save(ctx, notification.Preferences{IdentityID: identity.ID, Matrix: notification.Matrix{
+		notification.EventStale: {"pigeon": true},
+	}}))
+}
@@ -0,0 +1,221 @@
+package notification
+
+import (
+	"bytes"
+	"regexp"
+	"text/template"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/branding"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+)
+
+// Default templates of the emails, used for the events and locales the
+// operators didn't customize
+const (
+	DefaultEmailSubject = `{{.Subject}}`
+	DefaultEmailBody    = `Hello {{.Recipient}},
+
+{{.Subject}} ({{.Time}}).
+
+See {{.URL}}
+
+-- 
+{{.Brand.ProductName}}
+`
+)
+
+// localePattern matches the locales of templates like de or pt-BR
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2})?$`)
+
+// EmailTemplate is the subject and the body of the emails of an event in a
+// locale, the text/template variables are the fields of Email
+type EmailTemplate struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Event     string `gorm:"primary_key"`
+	// Locale is empty for the template used when there is none for the
+	// locale of the recipient
+	Locale  string `gorm:"primary_key"`
+	Subject string
+	Body    string
+	// Custom is false for the built-in defaults
+	Custom bool `sql:"-"`
+}
+
+// TableName implements gorm.tabler
+func (t EmailTemplate) TableName() string {
+	return "notification_email_templates"
+}
+
+// Email is what email templates render
+type Email struct {
+	Event string
+	// Subject is the text of the notification
+	Subject string
+	// Recipient is the name of the recipient
+	Recipient  string
+	WorkItemID string
+	// URL links to the work item
+	URL string
+	// Time is when the event happened in the timezone of the recipient
+	Time string
+	// Brand are the branding settings of the project of the work item
+	Brand branding.Branding
+}
+
+// EmailVariables are the variables of the email templates
+var EmailVariables = []string{"Event", "Subject", "Recipient", "WorkItemID", "URL", "Time",
+	"Brand.ProductName", "Brand.LogoURL", "Brand.AccentColor", "Brand.SupportURL", "Brand.DocsURL", "Brand.SupportEmail"}
+
+// SampleEmail returns the email of the event templates are previewed with
+func SampleEmail(event string, brand branding.Branding) Email {
+	return Email{
+		Event:      event,
+		Subject:    "No activity on Login fails",
+		Recipient:  "Jane Doe",
+		WorkItemID: "42",
+		URL:        "https://almighty.io/work-item/list/detail/42",
+		Time:       time.Now().UTC().Format(TimeLayout),
+		Brand:      brand,
+	}
+}
+
+// Validate checks the event, the locale and that the templates parse
+// returns BadParameterError
+func (t EmailTemplate) Validate() error {
+	if !contains(Events, t.Event) {
+		return errors.NewBadParameterError("event", t.Event).Expected(Events)
+	}
+	if t.Locale != "" && !localePattern.MatchString(t.Locale) {
+		return errors.NewBadParameterError("locale", t.Locale).Expected("a locale like de or pt-BR")
+	}
+	if _, err := template.New("subject").Parse(t.Subject); err != nil {
+		return errors.NewBadParameterError("subject", t.Subject).Expected("a valid template: " + err.Error())
+	}
+	if _, err := template.New("body").Parse(t.Body); err != nil {
+		return errors.NewBadParameterError("body", t.Body).Expected("a valid template: " + err.Error())
+	}
+	return nil
+}
+
+// Render returns the subject and the body of the email
+// returns BadParameterError if a template fails
+func (t EmailTemplate) Render(e Email) (string, string, error) {
+	subject, err := render("subject", t.Subject, e)
+	if err != nil {
+		return "", "", err
+	}
+	body, err := render("body", t.Body, e)
+	if err != nil {
+		return "", "", err
+	}
+	return subject, body, nil
+}
+
+func render(name string, text string, e Email) (string, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", errors.NewBadParameterError(name, text).Expected("a valid template: " + err.Error())
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, e); err != nil {
+		return "", errors.NewBadParameterError(name, text).Expected("a template of the email: " + err.Error())
+	}
+	return buf.String(), nil
+}
+
+// TemplateRepository encapsulates storage & retrieval of the email templates
+// customized by the operators
+type TemplateRepository interface {
+	List(ctx context.Context) ([]*EmailTemplate, error)
+	Load(ctx context.Context, event string, locale string) (*EmailTemplate, error)
+	Save(ctx context.Context, t *EmailTemplate) error
+	Delete(ctx context.Context, event string, locale string) error
+}
+
+// NewTemplateRepository creates a new storage type.
+func NewTemplateRepository(db *gorm.DB) TemplateRepository {
+	return &GormTemplateRepository{db: db}
+}
+
+// GormTemplateRepository is the implementation of the storage interface for
+// email templates.
+type GormTemplateRepository struct {
+	db *gorm.DB
+}
+
+// List returns the customized templates ordered by event and locale
+// returns InternalError
+func (m *GormTemplateRepository) List(ctx context.Context) ([]*EmailTemplate, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "notificationtemplate", "list"}, time.Now())
+
+	var objs []*EmailTemplate
+	if err := m.db.Order("event, locale").Find(&objs).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	for _, t := range objs {
+		t.Custom = true
+	}
+	return objs, nil
+}
+
+// Load returns the template of the event for the locale, the one without a
+// locale if the locale wasn't customized and the default if the event wasn't
+// returns BadParameterError or InternalError
+func (m *GormTemplateRepository) Load(ctx context.Context, event string, locale string) (*EmailTemplate, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "notificationtemplate", "load"}, time.Now())
+
+	if !contains(Events, event) {
+		return nil, errors.NewBadParameterError("event", event).Expected(Events)
+	}
+	var objs []*EmailTemplate
+	// the template of the locale sorts before the one without
+	if err := m.db.Where("event = ? AND locale IN (?)", event, []string{locale, ""}).Order("locale DESC").Find(&objs).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	if len(objs) == 0 {
+		return &EmailTemplate{Event: event, Subject: DefaultEmailSubject, Body: DefaultEmailBody}, nil
+	}
+	objs[0].Custom = true
+	return objs[0], nil
+}
+
+// Save stores the template, replacing the one of the event and locale
+// returns BadParameterError or InternalError
+func (m *GormTemplateRepository) Save(ctx context.Context, t *EmailTemplate) error {
+	defer goa.MeasureSince([]string{"goa", "db", "notificationtemplate", "save"}, time.Now())
+
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	err := m.db.Exec(`INSERT INTO notification_email_templates (event, locale, subject, body, created_at, updated_at)
+		VALUES (?, ?, ?, ?, now(), now())
+		ON CONFLICT (event, locale) DO UPDATE SET subject = EXCLUDED.subject, body = EXCLUDED.body, updated_at = now()`,
+		t.Event, t.Locale, t.Subject, t.Body).Error
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	t.Custom = true
+	return nil
+}
+
+// Delete removes the template of the event and locale, the default applies
+// again
+// returns NotFoundError or InternalError
+func (m *GormTemplateRepository) Delete(ctx context.Context, event string, locale string) error {
+	defer goa.MeasureSince([]string{"goa", "db", "notificationtemplate", "delete"}, time.Now())
+
+	tx := m.db.Where("event = ? AND locale = ?", event, locale).Delete(EmailTemplate{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("email template", event)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package notification_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/branding"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/notification"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestRenderEmail(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	e := notification.SampleEmail(notification.EventStale, branding.Branding{ProductName: "Tracker"})
+	subject, body, err := notification.EmailTemplate{Subject: notification.DefaultEmailSubject, Body: notification.DefaultEmailBody}.Render(e)
+	require.Nil(t, err)
+	assert.Equal(t, e.Subject, subject)
+	assert.Contains(t, body, "Hello Jane Doe")
+	assert.Contains(t, body, e.URL)
+	assert.Contains(t, body, "Tracker")
+
+	_, _, err = notification.EmailTemplate{Subject: "{{.Unknown}}"}.Render(e)
+	assert.IsType(t, errors.BadParameterError{}, err)
+
+	assert.IsType(t, errors.BadParameterError{}, notification.EmailTemplate{Event: "unknown"}.Validate())
+	assert.IsType(t, errors.BadParameterError{}, notification.EmailTemplate{Event: notification.EventStale, Locale: "german"}.Validate())
+	assert.IsType(t, errors.BadParameterError{}, notification.EmailTemplate{Event: notification.EventStale, Body: "{{"}.Validate())
+	assert.Nil(t, notification.EmailTemplate{Event: notification.EventStale, Locale: "pt-BR", Subject: "{{.Subject}}"}.Validate())
+}
+
+type TestTemplateRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunTemplateRepository(t *testing.T) {
+	suite.Run(t, &TestTemplateRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestTemplateRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestTemplateRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestTemplateRepository) TestTemplates() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := notification.NewTemplateRepository(test.DB)
+
+	tmpl, err := repo.Load(ctx, notification.EventStale, "de")
+	require.Nil(t, err)
+	assert.False(t, tmpl.Custom)
+	assert.Equal(t, notification.DefaultEmailBody, tmpl.Body)
+
+	require.Nil(t, repo.Save(ctx, &notification.EmailTemplate{Event: notification.EventStale, Subject: "[ALM] {{.Subject}}", Body: "all"}))
+	tmpl, err = repo.Load(ctx, notification.EventStale, "de")
+	require.Nil(t, err)
+	assert.True(t, tmpl.Custom)
+	assert.Equal(t, "all", tmpl.Body)
+
+	require.Nil(t, repo.Save(ctx, &notification.EmailTemplate{Event: notification.EventStale, Locale: "de", Subject: "{{.Subject}}", Body: "de"}))
+	require.Nil(t, repo.Save(ctx, &notification.EmailTemplate{Event: notification.EventStale, Locale: "de", Subject: "{{.Subject}}", Body: "Hallo"}))
+	tmpl, err = repo.Load(ctx, notification.EventStale, "de")
+	require.Nil(t, err)
+	assert.Equal(t, "Hallo", tmpl.Body)
+
+	templates, err := repo.List(ctx)
+	require.Nil(t, err)
+	require.Len(t, templates, 2)
+	assert.Equal(t, "", templates[0].Locale)
+	assert.Equal(t, "de", templates[1].Locale)
+
+	require.Nil(t, repo.Delete(ctx, notification.EventStale, "de"))
+	assert.IsType(t, errors.NotFoundError{}, repo.Delete(ctx, notification.EventStale, "de"))
+	tmpl, err = repo.Load(ctx, notification.EventStale, "de")
+	require.Nil(t, err)
+	assert.Equal(t, "all", tmpl.Body)
+}
@@ -0,0 +1,117 @@
+// Package outbox delivers records to sinks such as webhooks or a message bus
+// consistently with the database: records are written in the transaction of
+// the change they are about, in application.Transactional through
+// Application.Outbox(), and the relay delivers them after the commit. A
+// record is delivered at least once, it may be delivered again if the relay
+// stops after delivering it, receivers drop duplicates by the record ID. A
+// record a sink fails to accept too many times is dead, it is no longer
+// delivered to any sink.
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Record is a message waiting to be delivered to the sinks
+type Record struct {
+	Sequence  uint64 `gorm:"primary_key" sql:"DEFAULT:nextval('outbox_records_sequence_seq')"`
+	ID        uuid.UUID
+	CreatedAt time.Time
+	// Topic tells sinks what the record is about, e.g. "workitem" for the
+	// changes recorded by the change feed triggers
+	Topic string
+	// Key identifies the entity the record is about, records with the same
+	// key are delivered in order
+	Key         string
+	Payload     string `sql:"type:jsonb"`
+	Attempts    int
+	LastError   string
+	PublishedAt *time.Time
+	DeadAt      *time.Time
+}
+
+// TableName implements gorm.tabler
+func (r Record) TableName() string {
+	return "outbox_records"
+}
+
+// Delivery tracks the attempts of a sink to accept a record
+type Delivery struct {
+	RecordSequence uint64 `gorm:"primary_key"`
+	Sink           string `gorm:"primary_key"`
+	Attempts       int
+	LastError      string
+	DeliveredAt    *time.Time
+}
+
+// TableName implements gorm.tabler
+func (d Delivery) TableName() string {
+	return "outbox_deliveries"
+}
+
+// Repository encapsulates storage & retrieval of outbox records
+type Repository interface {
+	Add(ctx context.Context, topic string, key string, payload interface{}) (*Record, error)
+	Load(ctx context.Context, id uuid.UUID) (*Record, error)
+}
+
+// NewOutboxRepository creates a new storage type.
+func NewOutboxRepository(db *gorm.DB) Repository {
+	return &GormOutboxRepository{db: db}
+}
+
+// GormOutboxRepository is the implementation of the storage interface for
+// outbox records.
+type GormOutboxRepository struct {
+	db *gorm.DB
+}
+
+// Add writes a record with the JSON encoded payload, within a transaction the
+// record is only delivered if the transaction commits
+// returns BadParameterError or InternalError
+func (m *GormOutboxRepository) Add(ctx context.Context, topic string, key string, payload interface{}) (*Record, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "outbox", "add"}, time.Now())
+
+	if topic == "" {
+		return nil, errors.NewBadParameterError("topic", topic).Expected("not empty")
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, errors.NewBadParameterError("payload", payload).Expected("JSON encodable")
+	}
+	r := Record{
+		ID:        uuid.NewV4(),
+		CreatedAt: time.Now(),
+		Topic:     topic,
+		Key:       key,
+		Payload:   string(data),
+	}
+	if err := m.db.Create(&r).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return &r, nil
+}
+
+// Load returns the record with the given ID
+// returns NotFoundError or InternalError
+func (m *GormOutboxRepository) Load(ctx context.Context, id uuid.UUID) (*Record, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "outbox", "load"}, time.Now())
+
+	var r Record
+	tx := m.db.Where("id = ?", id).First(&r)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("outbox record", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &r, nil
+}
@@ -0,0 +1,208 @@
+package outbox_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/outbox"
+	"github.com/almighty/almighty-core/resource"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestWebhookSink(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	var body string
+	var headers http.Header
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		headers = r.Header
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := outbox.NewWebhookSink([]string{server.URL}, "s3cret")
+	r := outbox.Record{ID: uuid.NewV4(), Topic: "project", Key: "1", Payload: `{"name":"x"}`}
+	require.Nil(t, sink.Deliver(context.Background(), r))
+	assert.Equal(t, `{"name":"x"}`, body)
+	assert.Equal(t, "project", headers.Get(outbox.HeaderTopic))
+	assert.Equal(t, r.ID.String(), headers.Get(outbox.HeaderDelivery))
+	assert.Equal(t, "sha256="+outbox.Sign("s3cret", []byte(body)), headers.Get(outbox.HeaderSignature))
+
+	status = http.StatusBadGateway
+	assert.NotNil(t, sink.Deliver(context.Background(), r))
+}
+
+// recordingSink records the keys of the delivered records of its topic, it
+// fails while down is set and for the records with the failing key
+type recordingSink struct {
+	name       string
+	topic      string
+	keys       []string
+	down       bool
+	failingKey string
+}
+
+func (s *recordingSink) Name() string {
+	if s.name != "" {
+		return s.name
+	}
+	return "recording"
+}
+
+func (s *recordingSink) Deliver(ctx context.Context, r outbox.Record) error {
+	if s.down || (r.Topic == s.topic && r.Key == s.failingKey) {
+		return fmt.Errorf("sink is down")
+	}
+	if r.Topic == s.topic {
+		s.keys = append(s.keys, r.Key)
+	}
+	return nil
+}
+
+func (s *recordingSink) Close() error {
+	return nil
+}
+
+type TestOutbox struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunOutbox(t *testing.T) {
+	suite.Run(t, &TestOutbox{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestOutbox) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestOutbox) TearDownTest() {
+	test.clean()
+}
+
+// drain delivers all pending records
+func (test *TestOutbox) drain(relay *outbox.Relay) {
+	for {
+		n, err := relay.RunOnce(context.Background())
+		require.Nil(test.T(), err)
+		if n == 0 {
+			return
+		}
+	}
+}
+
+func (test *TestOutbox) TestDeliverCommittedRecords() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	topic := "test." + uuid.NewV4().String()
+	sink := &recordingSink{topic: topic}
+	relay := outbox.NewRelay(test.DB, []outbox.Sink{sink}, time.Hour, 10)
+	test.drain(relay)
+
+	tx := test.DB.Begin()
+	_, err := outbox.NewOutboxRepository(tx).Add(ctx, topic, "rolled back", map[string]string{"a": "b"})
+	require.Nil(t, err)
+	tx.Rollback()
+	repo := outbox.NewOutboxRepository(test.DB)
+	first, err := repo.Add(ctx, topic, "1", map[string]string{"a": "b"})
+	require.Nil(t, err)
+	_, err = repo.Add(ctx, topic, "2", nil)
+	require.Nil(t, err)
+	_, err = repo.Add(ctx, "", "3", nil)
+	assert.IsType(t, errors.BadParameterError{}, err)
+
+	sink.down = true
+	_, err = relay.RunOnce(ctx)
+	assert.IsType(t, errors.InternalError{}, err)
+	failed, err := repo.Load(ctx, first.ID)
+	require.Nil(t, err)
+	assert.Equal(t, 1, failed.Attempts)
+	assert.Contains(t, failed.LastError, "sink is down")
+	assert.Nil(t, failed.PublishedAt)
+
+	sink.down = false
+	test.drain(relay)
+	assert.Equal(t, []string{"1", "2"}, sink.keys)
+	published, err := repo.Load(ctx, first.ID)
+	require.Nil(t, err)
+	assert.NotNil(t, published.PublishedAt)
+
+	// delivered records are not delivered again, they are pruned after the retention
+	test.drain(relay)
+	assert.Len(t, sink.keys, 2)
+	_, err = relay.Prune(ctx, time.Now().Add(2*time.Hour))
+	require.Nil(t, err)
+	_, err = repo.Load(ctx, first.ID)
+	assert.IsType(t, errors.NotFoundError{}, err)
+}
+
+func (test *TestOutbox) TestFailingRecordDoesNotStopDelivery() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	topic := "test." + uuid.NewV4().String()
+	healthy := &recordingSink{name: "healthy", topic: topic}
+	failing := &recordingSink{name: "failing", topic: topic, failingKey: "a"}
+	relay := outbox.NewRelay(test.DB, []outbox.Sink{healthy, failing}, time.Hour, 2)
+	test.drain(relay)
+
+	repo := outbox.NewOutboxRepository(test.DB)
+	first, err := repo.Add(ctx, topic, "a", nil)
+	require.Nil(t, err)
+	_, err = repo.Add(ctx, topic, "b", nil)
+	require.Nil(t, err)
+	last, err := repo.Add(ctx, topic, "a", nil)
+	require.Nil(t, err)
+
+	// the records after the failing one are delivered, except the one with the
+	// same key which is held back from the failing sink to keep the order
+	n, err := relay.RunOnce(ctx)
+	assert.IsType(t, errors.InternalError{}, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{"a", "b", "a"}, healthy.keys)
+	assert.Equal(t, []string{"b"}, failing.keys)
+
+	// the healthy sink does not get the records it accepted again, the
+	// failing record is dead after the second attempt
+	_, err = relay.RunOnce(ctx)
+	assert.IsType(t, errors.InternalError{}, err)
+	assert.Equal(t, []string{"a", "b", "a"}, healthy.keys)
+	dead, err := repo.Load(ctx, first.ID)
+	require.Nil(t, err)
+	assert.Equal(t, 2, dead.Attempts)
+	assert.Contains(t, dead.LastError, "failing: sink is down")
+	assert.NotNil(t, dead.DeadAt)
+	assert.Nil(t, dead.PublishedAt)
+
+	// the dead record no longer holds back the later ones
+	failing.failingKey = ""
+	test.drain(relay)
+	assert.Equal(t, []string{"a", "b", "a"}, healthy.keys)
+	assert.Equal(t, []string{"b", "a"}, failing.keys)
+	published, err := repo.Load(ctx, last.ID)
+	require.Nil(t, err)
+	assert.NotNil(t, published.PublishedAt)
+	_, err = relay.Prune(ctx, time.Now().Add(2*time.Hour))
+	require.Nil(t, err)
+	_, err = repo.Load(ctx, first.ID)
+	assert.IsType(t, errors.NotFoundError{}, err)
+}
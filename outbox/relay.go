@@ -0,0 +1,250 @@
+package outbox
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+)
+
+// relayLock is the advisory lock held by the relay delivering records, only
+// one relay of all servers delivers at a time to keep the order
+const relayLock = 7301
+
+// batchSize is the number of records delivered by a relay run
+const batchSize = 100
+
+// pruneInterval is how often the relay deletes old records
+const pruneInterval = time.Hour
+
+// Sink delivers outbox records, Deliver returns once the record was accepted
+type Sink interface {
+	Name() string
+	Deliver(ctx context.Context, r Record) error
+	Close() error
+}
+
+// Relay delivers the pending records to the sinks
+type Relay struct {
+	db        *gorm.DB
+	sinks     []Sink
+	retention time.Duration
+	// maxAttempts is how many times a sink may fail a record before it is dead
+	maxAttempts int
+	lastPrune   time.Time
+	stop        chan struct{}
+	wg          sync.WaitGroup
+}
+
+// NewRelay creates a relay delivering to the given sinks and deleting records
+// older than the retention. Records a sink fails to accept maxAttempts times
+// are dead. Without sinks the relay only deletes old records.
+func NewRelay(db *gorm.DB, sinks []Sink, retention time.Duration, maxAttempts int) *Relay {
+	return &Relay{
+		db:          db,
+		sinks:       sinks,
+		retention:   retention,
+		maxAttempts: maxAttempts,
+		stop:        make(chan struct{}),
+	}
+}
+
+// Start delivers the pending records every interval
+func (r *Relay) Start(interval time.Duration) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case now := <-ticker.C:
+				r.tick(context.Background(), now)
+			}
+		}
+	}()
+}
+
+// Stop stops delivering, waits for the running delivery to finish and closes
+// the sinks
+func (r *Relay) Stop() {
+	close(r.stop)
+	r.wg.Wait()
+	for _, s := range r.sinks {
+		if err := s.Close(); err != nil {
+			log.Printf("Failed to close the %s outbox sink: %s\n", s.Name(), err.Error())
+		}
+	}
+}
+
+func (r *Relay) tick(ctx context.Context, now time.Time) {
+	for {
+		n, err := r.RunOnce(ctx)
+		if err != nil {
+			log.Printf("Failed to deliver the outbox: %s\n", err.Error())
+			break
+		}
+		if n < batchSize {
+			break
+		}
+	}
+	if now.Sub(r.lastPrune) >= pruneInterval {
+		if _, err := r.Prune(ctx, now); err != nil {
+			log.Printf("Failed to prune the outbox: %s\n", err.Error())
+		}
+		r.lastPrune = now
+	}
+}
+
+// RunOnce delivers the next batch of pending records in order to the sinks
+// that did not accept them yet and returns how many were delivered to all
+// sinks. A record a sink fails to accept does not stop the batch: the attempt
+// is recorded, the later records with the same topic and key are held back
+// from that sink to keep their order and the record is delivered to it again
+// by the next run, unless the sink failed it maxAttempts times and it is dead.
+// returns InternalError
+func (r *Relay) RunOnce(ctx context.Context) (int, error) {
+	defer goa.MeasureSince([]string{"goa", "outbox", "deliver"}, time.Now())
+
+	if len(r.sinks) == 0 {
+		return 0, nil
+	}
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return 0, errors.NewInternalError(tx.Error.Error())
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", relayLock).Row().Scan(&locked); err != nil {
+		return 0, errors.NewInternalError(err.Error())
+	}
+	if !locked {
+		// another relay is delivering
+		return 0, nil
+	}
+	var records []Record
+	if err := tx.Where("published_at IS NULL AND dead_at IS NULL").Order("sequence").Limit(batchSize).Find(&records).Error; err != nil {
+		return 0, errors.NewInternalError(err.Error())
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+	sequences := make([]uint64, len(records))
+	for i, rec := range records {
+		sequences[i] = rec.Sequence
+	}
+	var ds []Delivery
+	if err := tx.Where("record_sequence IN (?)", sequences).Find(&ds).Error; err != nil {
+		return 0, errors.NewInternalError(err.Error())
+	}
+	deliveries := make(map[string]Delivery, len(ds))
+	for _, d := range ds {
+		deliveries[deliveryKey(d.RecordSequence, d.Sink)] = d
+	}
+
+	held := map[string]bool{}
+	var delivered []uint64
+	var cause error
+	for _, rec := range records {
+		var failure error
+		accepted, dead := true, false
+		for _, s := range r.sinks {
+			d := deliveries[deliveryKey(rec.Sequence, s.Name())]
+			if d.DeliveredAt != nil {
+				continue
+			}
+			order := s.Name() + "\x00" + rec.Topic + "\x00" + rec.Key
+			if held[order] {
+				accepted = false
+				continue
+			}
+			d.Attempts++
+			if err := s.Deliver(ctx, rec); err != nil {
+				failure = errors.NewInternalError(s.Name() + ": " + err.Error())
+				held[order] = true
+				accepted = false
+				dead = dead || d.Attempts >= r.maxAttempts
+				if err := saveDelivery(tx, rec.Sequence, s.Name(), d.Attempts, failure.Error(), nil); err != nil {
+					return 0, err
+				}
+				continue
+			}
+			now := time.Now()
+			if err := saveDelivery(tx, rec.Sequence, s.Name(), d.Attempts, "", &now); err != nil {
+				return 0, err
+			}
+		}
+		if accepted {
+			delivered = append(delivered, rec.Sequence)
+			continue
+		}
+		if failure == nil {
+			// held back behind an earlier record
+			continue
+		}
+		cause = failure
+		var deadAt *time.Time
+		if dead {
+			now := time.Now()
+			deadAt = &now
+			log.Printf("outbox record %s is dead: %s\n", rec.ID, failure.Error())
+		}
+		err := tx.Exec("UPDATE outbox_records SET attempts = attempts + 1, last_error = ?, dead_at = ? WHERE sequence = ?", failure.Error(), deadAt, rec.Sequence).Error
+		if err != nil {
+			return 0, errors.NewInternalError(err.Error())
+		}
+	}
+	if len(delivered) > 0 {
+		if err := tx.Exec("UPDATE outbox_records SET published_at = ? WHERE sequence IN (?)", time.Now(), delivered).Error; err != nil {
+			return 0, errors.NewInternalError(err.Error())
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return 0, errors.NewInternalError(err.Error())
+	}
+	return len(delivered), cause
+}
+
+// deliveryKey identifies the delivery of a record to a sink
+func deliveryKey(sequence uint64, sink string) string {
+	return fmt.Sprintf("%d\x00%s", sequence, sink)
+}
+
+// saveDelivery records an attempt of the sink to accept the record
+// returns InternalError
+func saveDelivery(tx *gorm.DB, sequence uint64, sink string, attempts int, lastError string, deliveredAt *time.Time) error {
+	err := tx.Exec(`INSERT INTO outbox_deliveries (record_sequence, sink, attempts, last_error, delivered_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (record_sequence, sink) DO UPDATE SET attempts = EXCLUDED.attempts, last_error = EXCLUDED.last_error, delivered_at = EXCLUDED.delivered_at`,
+		sequence, sink, attempts, lastError, deliveredAt).Error
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// Prune deletes the delivered and dead records older than the retention.
+// Without sinks nothing is ever delivered and old records are deleted
+// regardless.
+// returns InternalError
+func (r *Relay) Prune(ctx context.Context, now time.Time) (int64, error) {
+	defer goa.MeasureSince([]string{"goa", "outbox", "prune"}, time.Now())
+
+	tx := r.db.Where("created_at < ?", now.Add(-r.retention))
+	if len(r.sinks) > 0 {
+		tx = tx.Where("published_at IS NOT NULL OR dead_at IS NOT NULL")
+	}
+	tx = tx.Delete(Record{})
+	if tx.Error != nil {
+		return 0, errors.NewInternalError(tx.Error.Error())
+	}
+	return tx.RowsAffected, nil
+}
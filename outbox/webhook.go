@@ -0,0 +1,77 @@
+package outbox
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/breaker"
+)
+
+// Headers of the requests of the webhook sink
+const (
+	HeaderTopic     = "X-Outbox-Topic"
+	HeaderDelivery  = "X-Outbox-Delivery"
+	HeaderSignature = "X-Outbox-Signature"
+)
+
+// WebhookSink posts the payload of each record to the given URLs. With a
+// secret the requests carry the hex encoded HMAC-SHA256 of the payload.
+type WebhookSink struct {
+	urls   []string
+	secret string
+	client *http.Client
+}
+
+// NewWebhookSink creates a sink posting to the given URLs
+func NewWebhookSink(urls []string, secret string) *WebhookSink {
+	return &WebhookSink{urls: urls, secret: secret, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Name implements Sink
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+// Deliver implements Sink, any response but 2xx fails the delivery
+func (s *WebhookSink) Deliver(ctx context.Context, r Record) error {
+	for _, url := range s.urls {
+		req, err := http.NewRequest("POST", url, bytes.NewBufferString(r.Payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(HeaderTopic, r.Topic)
+		req.Header.Set(HeaderDelivery, r.ID.String())
+		if s.secret != "" {
+			req.Header.Set(HeaderSignature, "sha256="+Sign(s.secret, []byte(r.Payload)))
+		}
+		res, err := breaker.For("webhook "+req.URL.Host).Request(s.client, req)
+		if err != nil {
+			return err
+		}
+		res.Body.Close()
+		if res.StatusCode < 200 || res.StatusCode >= 300 {
+			return fmt.Errorf("%s responded %s", url, res.Status)
+		}
+	}
+	return nil
+}
+
+// Close implements Sink
+func (s *WebhookSink) Close() error {
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the payload
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
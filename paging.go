@@ -8,14 +8,10 @@ import (
 	"strings"
 
 	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/configuration"
 	"github.com/goadesign/goa"
 )
 
-const (
-	pageSizeDefault = 20
-	pageSizeMax     = 100
-)
-
 func computePagingLimts(offsetParam *string, limitParam *int) (offset int, limit int) {
 	if offsetParam == nil {
 		offset = 0
@@ -31,6 +27,8 @@ func computePagingLimts(offsetParam *string, limitParam *int) (offset int, limit
 		offset = 0
 	}
 
+	pageSizeDefault := configuration.GetPageSizeDefault()
+	pageSizeMax := configuration.GetPageSizeMax()
 	if limitParam == nil {
 		limit = pageSizeDefault
 	} else {
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// PersonalDataController implements the personal-data resource.
+type PersonalDataController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewPersonalDataController creates a personal-data controller.
+func NewPersonalDataController(service *goa.Service, db application.DB) *PersonalDataController {
+	return &PersonalDataController{Controller: service.NewController("PersonalDataController"), db: db}
+}
+
+// Export runs the export action.
+func (c *PersonalDataController) Export(ctx *app.ExportPersonalDataContext) error {
+	identityID, err := authorizePersonalData(ctx, ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		archive, err := appl.PersonalData().Export(ctx, identityID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		b, err := json.MarshalIndent(archive, "", "  ")
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewInternalError(err.Error()))
+		}
+		ctx.ResponseData.Header().Set("Content-Disposition", `attachment; filename="personal-data-`+identityID.String()+`.json"`)
+		return ctx.OK(b)
+	})
+}
+
+// Anonymize runs the anonymize action.
+func (c *PersonalDataController) Anonymize(ctx *app.AnonymizePersonalDataContext) error {
+	identityID, err := authorizePersonalData(ctx, ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		err := appl.PersonalData().Anonymize(ctx, identityID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.NoContent()
+	})
+}
+
+// authorizePersonalData returns the ID of the identity whose personal data is
+// requested, identities can only access their own personal data
+func authorizePersonalData(ctx context.Context, id string) (uuid.UUID, error) {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return uuid.Nil, goa.ErrUnauthorized(err.Error())
+	}
+	identityID, err := uuid.FromString(id)
+	if err != nil {
+		return uuid.Nil, errors.NewNotFoundError("identity", id)
+	}
+	if identityID.String() != currentUser {
+		return uuid.Nil, goa.ErrUnauthorized("personal data can only be accessed by its owner")
+	}
+	return identityID, nil
+}
@@ -0,0 +1,220 @@
+// Package personaldata exports and erases the personal data of identities.
+package personaldata
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/reaction"
+	"github.com/almighty/almighty-core/vote"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Archive holds all personal data stored for an identity
+type Archive struct {
+	ExportedAt time.Time  `json:"exportedAt"`
+	Profile    Profile    `json:"profile"`
+	Comments   []Comment  `json:"comments"`
+	WorkItems  []WorkItem `json:"workItems"`
+	Votes      []string   `json:"votes"`
+	Reactions  []Reaction `json:"reactions"`
+	AdminOf    []string   `json:"adminOf"`
+}
+
+// Profile is the identity with its email addresses
+type Profile struct {
+	ID        string    `json:"id"`
+	FullName  string    `json:"fullName"`
+	ImageURL  string    `json:"imageURL"`
+	Emails    []string  `json:"emails"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Comment is a comment written by the identity
+type Comment struct {
+	ID        string    `json:"id"`
+	ParentID  string    `json:"parentId"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// WorkItem is a work item created by the identity
+type WorkItem struct {
+	ID        string                 `json:"id"`
+	Type      string                 `json:"type"`
+	Fields    map[string]interface{} `json:"fields"`
+	CreatedAt time.Time              `json:"createdAt"`
+}
+
+// Reaction is a reaction of the identity to a comment
+type Reaction struct {
+	CommentID string `json:"commentId"`
+	Emoji     string `json:"emoji"`
+}
+
+// Repository exports and erases personal data
+type Repository interface {
+	Export(ctx context.Context, identityID uuid.UUID) (*Archive, error)
+	Anonymize(ctx context.Context, identityID uuid.UUID) error
+}
+
+// NewPersonalDataRepository creates a new storage type.
+func NewPersonalDataRepository(db *gorm.DB) Repository {
+	return &GormPersonalDataRepository{db: db}
+}
+
+// GormPersonalDataRepository is the implementation of the storage interface
+// for personal data.
+type GormPersonalDataRepository struct {
+	db *gorm.DB
+}
+
+// Export collects the profile, comments, created work items, votes,
+// reactions and project admin memberships of the identity
+// returns NotFoundError or InternalError
+func (m *GormPersonalDataRepository) Export(ctx context.Context, identityID uuid.UUID) (*Archive, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "personaldata", "export"}, time.Now())
+
+	identity, err := m.loadIdentity(identityID)
+	if err != nil {
+		return nil, err
+	}
+	archive := Archive{
+		ExportedAt: time.Now().UTC(),
+		Profile: Profile{
+			ID:        identity.ID.String(),
+			FullName:  identity.FullName,
+			ImageURL:  identity.ImageURL,
+			Emails:    []string{},
+			CreatedAt: identity.CreatedAt,
+		},
+		Comments:  []Comment{},
+		WorkItems: []WorkItem{},
+		Votes:     []string{},
+		Reactions: []Reaction{},
+		AdminOf:   []string{},
+	}
+
+	var users []account.User
+	if err := m.db.Where("identity_id = ?", identityID).Find(&users).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	for _, u := range users {
+		archive.Profile.Emails = append(archive.Profile.Emails, u.Email)
+	}
+
+	var comments []comment.Comment
+	if err := m.db.Where("created_by = ?", identityID).Order("created_at").Find(&comments).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	for _, c := range comments {
+		archive.Comments = append(archive.Comments, Comment{ID: c.ID.String(), ParentID: c.ParentID, Body: c.Body, CreatedAt: c.CreatedAt})
+	}
+
+	var items []workitem.WorkItem
+	if err := m.db.Where("fields->>? = ?", workitem.SystemCreator, identityID.String()).Order("id").Find(&items).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	witr := workitem.NewWorkItemTypeRepository(m.db)
+	for _, wi := range items {
+		wit, err := witr.LoadTypeFromDB(wi.Type)
+		if err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		fields, err := workitem.DecryptFields(*wit, wi.Fields)
+		if err != nil {
+			return nil, err
+		}
+		archive.WorkItems = append(archive.WorkItems, WorkItem{ID: strconv.FormatUint(wi.ID, 10), Type: wi.Type, Fields: fields, CreatedAt: wi.CreatedAt})
+	}
+
+	var votes []vote.Vote
+	if err := m.db.Where("identity_id = ?", identityID).Order("work_item_id").Find(&votes).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	for _, v := range votes {
+		archive.Votes = append(archive.Votes, strconv.FormatUint(v.WorkItemID, 10))
+	}
+
+	var reactions []reaction.Reaction
+	if err := m.db.Where("identity_id = ?", identityID).Order("created_at").Find(&reactions).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	for _, r := range reactions {
+		archive.Reactions = append(archive.Reactions, Reaction{CommentID: r.CommentID.String(), Emoji: r.Emoji})
+	}
+
+	var admins []project.Admin
+	if err := m.db.Where("identity_id = ?", identityID).Find(&admins).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	for _, a := range admins {
+		archive.AdminOf = append(archive.AdminOf, a.ProjectID.String())
+	}
+	return &archive, nil
+}
+
+// Anonymize erases the personal data of the identity. The creator and
+// assignees of work items and the authors of comments are replaced by the
+// tombstone identity, votes, reactions, project admin memberships, sessions,
+// email addresses and the identity itself are deleted, its tokens revoked.
+// returns NotFoundError, BadParameterError or InternalError
+func (m *GormPersonalDataRepository) Anonymize(ctx context.Context, identityID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "personaldata", "anonymize"}, time.Now())
+
+	if uuid.Equal(identityID, account.TombstoneIdentityID) {
+		return errors.NewBadParameterError("identity", identityID.String()).Expected("not the tombstone identity")
+	}
+	if _, err := m.loadIdentity(identityID); err != nil {
+		return err
+	}
+	me := identityID.String()
+	tombstone := account.TombstoneIdentityID.String()
+	assignee, _ := json.Marshal(map[string]interface{}{workitem.SystemAssignees: []string{me}})
+
+	statements := []struct {
+		sql    string
+		params []interface{}
+	}{
+		{fmt.Sprintf("UPDATE work_items SET fields = jsonb_set(fields, '{%[1]s}', to_jsonb(?::text)), version = version + 1 WHERE fields->>'%[1]s' = ?", workitem.SystemCreator), []interface{}{tombstone, me}},
+		{fmt.Sprintf("UPDATE work_items SET fields = jsonb_set(fields, '{%[1]s}', (SELECT jsonb_agg(CASE WHEN a = ? THEN ? ELSE a END) FROM jsonb_array_elements_text(fields->'%[1]s') a)), version = version + 1 WHERE fields @> ?", workitem.SystemAssignees), []interface{}{me, tombstone, string(assignee)}},
+		{"UPDATE comments SET created_by = ? WHERE created_by = ?", []interface{}{tombstone, me}},
+		{"DELETE FROM work_item_votes WHERE identity_id = ?", []interface{}{me}},
+		{"DELETE FROM comment_reactions WHERE identity_id = ?", []interface{}{me}},
+		{"DELETE FROM project_admins WHERE identity_id = ?", []interface{}{me}},
+		{"DELETE FROM sessions WHERE identity_id = ?", []interface{}{me}},
+		{"INSERT INTO session_cutoffs (identity_id, revoked_before) VALUES (?, now()) ON CONFLICT (identity_id) DO UPDATE SET revoked_before = excluded.revoked_before", []interface{}{me}},
+		{"DELETE FROM users WHERE identity_id = ?", []interface{}{me}},
+		{"DELETE FROM identities WHERE id = ?", []interface{}{me}},
+	}
+	for _, s := range statements {
+		if err := m.db.Exec(s.sql, s.params...).Error; err != nil {
+			goa.LogError(ctx, "error anonymizing Identity", "error", err.Error())
+			return errors.NewInternalError(err.Error())
+		}
+	}
+	return nil
+}
+
+func (m *GormPersonalDataRepository) loadIdentity(identityID uuid.UUID) (*account.Identity, error) {
+	var identity account.Identity
+	tx := m.db.Where("id = ?", identityID).First(&identity)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("identity", identityID.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &identity, nil
+}
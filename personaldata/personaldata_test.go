@@ -0,0 +1,117 @@
+package personaldata_test
+
+import (
+	"strconv"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/personaldata"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/vote"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestPersonalDataRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunPersonalDataRepository(t *testing.T) {
+	suite.Run(t, &TestPersonalDataRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestPersonalDataRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestPersonalDataRepository) TearDownTest() {
+	test.clean()
+}
+
+// createPersonalData creates an identity with an email address, a created
+// and an assigned work item, a comment and a vote
+func (test *TestPersonalDataRepository) createPersonalData() (uuid.UUID, *workitem.WorkItem, *comment.Comment) {
+	t := test.T()
+	ctx := context.Background()
+	identity := account.Identity{FullName: "Jane Doe", ImageURL: "http://example.com/jane.png"}
+	require.Nil(t, account.NewIdentityRepository(test.DB).Create(ctx, &identity))
+	user := account.User{Email: "jane-" + uuid.NewV4().String() + "@example.com", IdentityID: identity.ID}
+	require.Nil(t, account.NewUserRepository(test.DB).Create(ctx, &user))
+
+	wi, err := workitem.NewWorkItemRepository(test.DB).Create(ctx, workitem.SystemBug,
+		map[string]interface{}{
+			workitem.SystemTitle:     "Title",
+			workitem.SystemState:     workitem.SystemStateNew,
+			workitem.SystemAssignees: []interface{}{identity.ID.String(), "someone"},
+		}, identity.ID.String())
+	require.Nil(t, err)
+
+	c := comment.Comment{ParentID: wi.ID, Body: "my comment", CreatedBy: identity.ID}
+	require.Nil(t, comment.NewCommentRepository(test.DB).Create(ctx, &c))
+
+	wiID, err := workitem.ParseWorkItemIDToUint64(wi.ID)
+	require.Nil(t, err)
+	require.Nil(t, vote.NewVoteRepository(test.DB).Create(ctx, wiID, identity.ID))
+
+	stored, err := workitem.NewWorkItemRepository(test.DB).LoadFromDB(wi.ID)
+	require.Nil(t, err)
+	return identity.ID, stored, &c
+}
+
+func (test *TestPersonalDataRepository) TestExport() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	identityID, wi, c := test.createPersonalData()
+	repo := personaldata.NewPersonalDataRepository(test.DB)
+
+	archive, err := repo.Export(context.Background(), identityID)
+	require.Nil(t, err)
+	assert.Equal(t, "Jane Doe", archive.Profile.FullName)
+	require.Len(t, archive.Profile.Emails, 1)
+	require.Len(t, archive.Comments, 1)
+	assert.Equal(t, c.Body, archive.Comments[0].Body)
+	require.Len(t, archive.WorkItems, 1)
+	assert.Equal(t, strconv.FormatUint(wi.ID, 10), archive.WorkItems[0].ID)
+	assert.Equal(t, "Title", archive.WorkItems[0].Fields[workitem.SystemTitle])
+	assert.Len(t, archive.Votes, 1)
+
+	_, err = repo.Export(context.Background(), uuid.NewV4())
+	assert.IsType(t, errors.NotFoundError{}, err)
+}
+
+func (test *TestPersonalDataRepository) TestAnonymize() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	identityID, wi, c := test.createPersonalData()
+	repo := personaldata.NewPersonalDataRepository(test.DB)
+
+	require.Nil(t, repo.Anonymize(context.Background(), identityID))
+
+	tombstone := account.TombstoneIdentityID.String()
+	stored, err := workitem.NewWorkItemRepository(test.DB).LoadFromDB(strconv.FormatUint(wi.ID, 10))
+	require.Nil(t, err)
+	assert.Equal(t, tombstone, stored.Fields[workitem.SystemCreator])
+	assert.Equal(t, []interface{}{tombstone, "someone"}, stored.Fields[workitem.SystemAssignees])
+	assert.Equal(t, wi.Version+1, stored.Version)
+
+	anonymized, err := comment.NewCommentRepository(test.DB).Load(context.Background(), c.ID)
+	require.Nil(t, err)
+	assert.Equal(t, account.TombstoneIdentityID, anonymized.CreatedBy)
+	assert.Equal(t, c.Body, anonymized.Body)
+
+	_, err = repo.Export(context.Background(), identityID)
+	assert.IsType(t, errors.NotFoundError{}, err)
+	assert.IsType(t, errors.BadParameterError{}, repo.Anonymize(context.Background(), account.TombstoneIdentityID))
+}
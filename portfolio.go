@@ -0,0 +1,301 @@
+package main
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/portfolio"
+	"github.com/almighty/almighty-core/workitem/link"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// PortfolioController implements the portfolio resource.
+type PortfolioController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewPortfolioController creates a portfolio controller.
+func NewPortfolioController(service *goa.Service, db application.DB) *PortfolioController {
+	return &PortfolioController{Controller: service.NewController("PortfolioController"), db: db}
+}
+
+// List runs the list action.
+func (c *PortfolioController) List(ctx *app.ListPortfolioContext) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		portfolios, err := appl.Portfolios().List(ctx)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		data := make([]*app.Portfolio, 0, len(portfolios))
+		for _, p := range portfolios {
+			data = append(data, ConvertPortfolio(ctx.RequestData, p))
+		}
+		return ctx.OK(&app.PortfolioList{
+			Data: data,
+			Meta: &app.WorkItemListResponseMeta{TotalCount: len(data)},
+		})
+	})
+}
+
+// Create runs the create action.
+func (c *PortfolioController) Create(ctx *app.CreatePortfolioContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	p, err := portfolioFromPayload(ctx.Payload.Data)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	p.OwnerID = *identityID
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if err := checkPortfolioMembers(ctx, appl, *identityID, nil, p); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if err := appl.Portfolios().Create(ctx, p); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		ctx.ResponseData.Header().Set("Location", AbsoluteURL(ctx.RequestData, app.PortfolioHref(p.ID)))
+		return ctx.Created(&app.PortfolioSingle{Data: ConvertPortfolio(ctx.RequestData, p)})
+	})
+}
+
+// Show runs the show action.
+func (c *PortfolioController) Show(ctx *app.ShowPortfolioContext) error {
+	return c.read(ctx, ctx.ID, func(appl application.Application, p *portfolio.Portfolio) error {
+		return ctx.OK(&app.PortfolioSingle{Data: ConvertPortfolio(ctx.RequestData, p)})
+	})
+}
+
+// Report runs the report action.
+func (c *PortfolioController) Report(ctx *app.ReportPortfolioContext) error {
+	return c.read(ctx, ctx.ID, func(appl application.Application, p *portfolio.Portfolio) error {
+		report, err := appl.Portfolios().Report(ctx, *p)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.PortfolioReport{
+			Counts:   make([]*app.PortfolioCount, 0, len(report.Counts)),
+			Projects: make(map[string]int, len(report.Projects)),
+		}
+		for _, count := range report.Counts {
+			res.Counts = append(res.Counts, &app.PortfolioCount{Type: count.Type, State: count.State, Count: count.Count})
+		}
+		for id, count := range report.Projects {
+			res.Projects[id.String()] = count
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Update runs the update action.
+func (c *PortfolioController) Update(ctx *app.UpdatePortfolioContext) error {
+	changes, err := portfolioFromPayload(ctx.Payload.Data)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return c.write(ctx, ctx.ID, func(appl application.Application, identityID uuid.UUID, p *portfolio.Portfolio) error {
+		attrs := ctx.Payload.Data.Attributes
+		if attrs.Version == nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.version", nil).Expected("not nil"))
+		}
+		before := *p
+		p.Version = *attrs.Version
+		if attrs.Name != nil {
+			p.Name = changes.Name
+		}
+		if attrs.Description != nil {
+			p.Description = changes.Description
+		}
+		if rel := ctx.Payload.Data.Relationships; rel != nil {
+			if rel.Projects != nil {
+				p.Projects = changes.Projects
+			}
+			if rel.LinkTypes != nil {
+				p.LinkTypes = changes.LinkTypes
+			}
+		}
+		if err := checkPortfolioMembers(ctx, appl, identityID, &before, p); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if err := appl.Portfolios().Save(ctx, p); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.PortfolioSingle{Data: ConvertPortfolio(ctx.RequestData, p)})
+	})
+}
+
+// Delete runs the delete action.
+func (c *PortfolioController) Delete(ctx *app.DeletePortfolioContext) error {
+	return c.write(ctx, ctx.ID, func(appl application.Application, identityID uuid.UUID, p *portfolio.Portfolio) error {
+		if err := appl.Portfolios().Delete(ctx, p.ID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.NoContent()
+	})
+}
+
+// portfolioContext is implemented by the contexts of the portfolio actions
+type portfolioContext interface {
+	context.Context
+	jsonapi.InternalServerError
+}
+
+// read runs the given function in a transaction with the portfolio
+func (c *PortfolioController) read(ctx portfolioContext, id string, f func(appl application.Application, p *portfolio.Portfolio) error) error {
+	portfolioID, err := uuid.FromString(id)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		p, err := appl.Portfolios().Load(ctx, portfolioID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return f(appl, p)
+	})
+}
+
+// write runs the given function in a transaction if the current identity
+// owns the portfolio or is an instance admin
+func (c *PortfolioController) write(ctx portfolioContext, id string, f func(appl application.Application, identityID uuid.UUID, p *portfolio.Portfolio) error) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	return c.read(ctx, id, func(appl application.Application, p *portfolio.Portfolio) error {
+		if !uuid.Equal(p.OwnerID, *identityID) && !isInstanceAdmin(ctx) {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only the owner can change the portfolio"))
+		}
+		return f(appl, *identityID, p)
+	})
+}
+
+// checkPortfolioMembers checks that the projects added to the portfolio
+// since before exist and are administrated by the identity, and that the
+// link types are tree link types
+// returns BadParameterError or InternalError
+func checkPortfolioMembers(ctx context.Context, appl application.Application, identityID uuid.UUID, before *portfolio.Portfolio, p *portfolio.Portfolio) error {
+	for _, projectID := range p.Projects {
+		if before != nil && before.Projects.Contains(projectID) {
+			continue
+		}
+		if _, err := appl.Projects().Load(ctx, projectID); err != nil {
+			return errors.NewBadParameterError("data.relationships.projects.data.id", projectID.String()).Expected("the ID of a project")
+		}
+		if isInstanceAdmin(ctx) {
+			continue
+		}
+		admin, err := isProjectAdmin(ctx, appl, projectID, identityID)
+		if err != nil {
+			return err
+		}
+		if !admin {
+			return errors.NewBadParameterError("data.relationships.projects.data.id", projectID.String()).Expected("a project administrated by the current identity")
+		}
+	}
+	for _, linkTypeID := range p.LinkTypes {
+		lt, err := appl.WorkItemLinkTypes().Load(ctx, linkTypeID.String())
+		if err != nil || lt.Data.Attributes.Topology == nil || *lt.Data.Attributes.Topology != link.TopologyTree {
+			return errors.NewBadParameterError("data.relationships.link-types.data.id", linkTypeID.String()).Expected("the ID of a tree link type")
+		}
+	}
+	return nil
+}
+
+// portfolioFromPayload returns the name, the description, the projects and
+// the link types of the payload
+// returns BadParameterError
+func portfolioFromPayload(data *app.Portfolio) (*portfolio.Portfolio, error) {
+	if data == nil || data.Attributes == nil {
+		return nil, errors.NewBadParameterError("data.attributes", nil).Expected("not nil")
+	}
+	attrs := data.Attributes
+	p := portfolio.Portfolio{Projects: portfolio.IDs{}, LinkTypes: portfolio.IDs{}}
+	if attrs.Name != nil {
+		p.Name = *attrs.Name
+	}
+	if attrs.Description != nil {
+		p.Description = *attrs.Description
+	}
+	if rel := data.Relationships; rel != nil {
+		var err error
+		if p.Projects, err = portfolioIDs("data.relationships.projects.data.id", rel.Projects); err != nil {
+			return nil, err
+		}
+		if p.LinkTypes, err = portfolioIDs("data.relationships.link-types.data.id", rel.LinkTypes); err != nil {
+			return nil, err
+		}
+	}
+	return &p, nil
+}
+
+// portfolioIDs returns the IDs of the relationship list
+// returns BadParameterError
+func portfolioIDs(field string, rel *app.RelationGenericList) (portfolio.IDs, error) {
+	ids := portfolio.IDs{}
+	if rel == nil {
+		return ids, nil
+	}
+	for _, d := range rel.Data {
+		if d == nil || d.ID == nil {
+			return nil, errors.NewBadParameterError(field, nil).Expected("not nil")
+		}
+		id, err := uuid.FromString(*d.ID)
+		if err != nil {
+			return nil, errors.NewBadParameterError(field, *d.ID).Expected("a UUID")
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// ConvertPortfolio converts between internal and external REST representation
+func ConvertPortfolio(request *goa.RequestData, p *portfolio.Portfolio) *app.Portfolio {
+	selfURL := AbsoluteURL(request, app.PortfolioHref(p.ID))
+	identityType := "identities"
+	ownerID := p.OwnerID.String()
+	converted := &app.Portfolio{
+		Type: "portfolios",
+		ID:   &p.ID,
+		Attributes: &app.PortfolioAttributes{
+			Name:        &p.Name,
+			Description: &p.Description,
+			Version:     &p.Version,
+		},
+		Relationships: &app.PortfolioRelations{
+			Owner: &app.RelationGeneric{
+				Data: &app.GenericData{Type: &identityType, ID: &ownerID},
+			},
+			Projects:  &app.RelationGenericList{Data: []*app.GenericData{}},
+			LinkTypes: &app.RelationGenericList{Data: []*app.GenericData{}},
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+	for _, id := range p.Projects {
+		projectType := "projects"
+		projectID := id.String()
+		projectSelfURL := AbsoluteURL(request, app.ProjectHref(projectID))
+		converted.Relationships.Projects.Data = append(converted.Relationships.Projects.Data, &app.GenericData{
+			Type:  &projectType,
+			ID:    &projectID,
+			Links: &app.GenericLinks{Self: &projectSelfURL},
+		})
+	}
+	for _, id := range p.LinkTypes {
+		linkTypeType := link.EndpointWorkItemLinkTypes
+		linkTypeID := id.String()
+		converted.Relationships.LinkTypes.Data = append(converted.Relationships.LinkTypes.Data, &app.GenericData{
+			Type: &linkTypeType,
+			ID:   &linkTypeID,
+		})
+	}
+	return converted
+}
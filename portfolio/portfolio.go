@@ -0,0 +1,308 @@
+// Package portfolio stores portfolios grouping several projects. Portfolios
+// report on the open work items of their projects, and tree links between
+// work items of different projects are only allowed with the link types
+// designated by a portfolio containing both projects.
+package portfolio
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// MaxProjects is the number of projects a portfolio can group
+const MaxProjects = 50
+
+// IDs are the IDs of the projects or the link types of a portfolio
+type IDs []uuid.UUID
+
+// Value implements the driver.Valuer interface
+func (ids IDs) Value() (driver.Value, error) {
+	if ids == nil {
+		ids = IDs{}
+	}
+	return json.Marshal(ids)
+}
+
+// Scan implements the sql.Scanner interface
+func (ids *IDs) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("scan source was not []byte")
+	}
+	return json.Unmarshal(b, ids)
+}
+
+// Contains returns true if the ID is one of the IDs
+func (ids IDs) Contains(id uuid.UUID) bool {
+	for _, i := range ids {
+		if uuid.Equal(i, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// Portfolio groups projects owned by an identity
+type Portfolio struct {
+	gormsupport.Lifecycle
+	ID          uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	Name        string
+	Description string
+	OwnerID     uuid.UUID `sql:"type:uuid"`
+	Projects    IDs       `sql:"type:jsonb"`
+	// LinkTypes are the tree link types work items of different projects of
+	// the portfolio can be linked with
+	LinkTypes IDs `sql:"type:jsonb"`
+	Version   int
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m Portfolio) TableName() string {
+	return "portfolios"
+}
+
+// Validate checks the name, the projects and the link types of the portfolio
+// returns BadParameterError
+func (m Portfolio) Validate() error {
+	if m.Name == "" {
+		return errors.NewBadParameterError("name", m.Name).Expected("not empty")
+	}
+	if len(m.Projects) > MaxProjects {
+		return errors.NewBadParameterError("projects", len(m.Projects)).Expected(fmt.Sprintf("at most %d projects", MaxProjects))
+	}
+	for name, ids := range map[string]IDs{"projects": m.Projects, "link-types": m.LinkTypes} {
+		seen := map[uuid.UUID]bool{}
+		for _, id := range ids {
+			if seen[id] {
+				return errors.NewBadParameterError(name, id.String()).Expected("unique")
+			}
+			seen[id] = true
+		}
+	}
+	return nil
+}
+
+// Count is the number of open work items of a type in a state
+type Count struct {
+	Type  string
+	State string
+	Count int
+}
+
+// Report sums up the open work items of the projects of a portfolio
+type Report struct {
+	// Counts are the open work items of all projects by type and state
+	Counts []Count
+	// Projects are the number of open work items per project
+	Projects map[uuid.UUID]int
+}
+
+// Repository describes interactions with portfolios
+type Repository interface {
+	Create(ctx context.Context, p *Portfolio) error
+	Load(ctx context.Context, id uuid.UUID) (*Portfolio, error)
+	Save(ctx context.Context, p *Portfolio) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context) ([]*Portfolio, error)
+	Report(ctx context.Context, p Portfolio) (*Report, error)
+	CheckLink(ctx context.Context, sourceProjectID, targetProjectID, linkTypeID uuid.UUID) error
+}
+
+// NewPortfolioRepository creates a new storage type.
+func NewPortfolioRepository(db *gorm.DB) Repository {
+	return &GormPortfolioRepository{db: db}
+}
+
+// GormPortfolioRepository is the implementation of the storage interface for
+// portfolios.
+type GormPortfolioRepository struct {
+	db *gorm.DB
+}
+
+// Create stores a new portfolio
+// returns BadParameterError or InternalError
+func (m *GormPortfolioRepository) Create(ctx context.Context, p *Portfolio) error {
+	defer goa.MeasureSince([]string{"goa", "db", "portfolio", "create"}, time.Now())
+
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	p.ID = uuid.NewV4()
+	p.Version = 0
+	if err := m.db.Create(p).Error; err != nil {
+		goa.LogError(ctx, "error adding Portfolio", "error", err.Error())
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// Load returns the portfolio with the given ID
+// returns NotFoundError or InternalError
+func (m *GormPortfolioRepository) Load(ctx context.Context, id uuid.UUID) (*Portfolio, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "portfolio", "load"}, time.Now())
+
+	var obj Portfolio
+	tx := m.db.Where("id = ?", id).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("portfolio", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
+
+// Save updates the name, the description, the projects and the link types
+// of the portfolio, the version of p must match the stored one. The owner
+// can't change.
+// returns NotFoundError, BadParameterError, VersionConflictError or InternalError
+func (m *GormPortfolioRepository) Save(ctx context.Context, p *Portfolio) error {
+	defer goa.MeasureSince([]string{"goa", "db", "portfolio", "save"}, time.Now())
+
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if _, err := m.Load(ctx, p.ID); err != nil {
+		return err
+	}
+	tx := m.db.Model(&Portfolio{}).Where("id = ? AND version = ?", p.ID, p.Version).Updates(map[string]interface{}{
+		"name":        p.Name,
+		"description": p.Description,
+		"projects":    p.Projects,
+		"link_types":  p.LinkTypes,
+		"version":     p.Version + 1,
+	})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewVersionConflictError("version conflict")
+	}
+	p.Version++
+	return nil
+}
+
+// Delete removes the portfolio with the given ID
+// returns NotFoundError or InternalError
+func (m *GormPortfolioRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "portfolio", "delete"}, time.Now())
+
+	tx := m.db.Where("id = ?", id).Delete(&Portfolio{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("portfolio", id.String())
+	}
+	return nil
+}
+
+// List returns all portfolios ordered by name
+// returns InternalError
+func (m *GormPortfolioRepository) List(ctx context.Context) ([]*Portfolio, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "portfolio", "list"}, time.Now())
+
+	var objs []*Portfolio
+	if err := m.db.Order("name, id").Find(&objs).Error; err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return objs, nil
+}
+
+// Report counts the open work items of the projects of the portfolio that
+// are visible to the viewer of ctx, archived work items are left out
+// returns InternalError
+func (m *GormPortfolioRepository) Report(ctx context.Context, p Portfolio) (*Report, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "portfolio", "report"}, time.Now())
+
+	res := Report{Counts: []Count{}, Projects: make(map[uuid.UUID]int, len(p.Projects))}
+	if len(p.Projects) == 0 {
+		return &res, nil
+	}
+	projects := make([]string, len(p.Projects))
+	for i, id := range p.Projects {
+		projects[i] = id.String()
+		res.Projects[id] = 0
+	}
+	table := workitem.WorkItem{}.TableName()
+	db := m.db.Table(table).
+		Select(fmt.Sprintf("fields->>'%[1]s' AS project, type, coalesce(fields->>'%[2]s', '') AS state, count(*) AS count", workitem.SystemProject, workitem.SystemState)).
+		Where("deleted_at IS NULL AND NOT archived").
+		Where(fmt.Sprintf("fields->>'%s' IN (?)", workitem.SystemProject), projects).
+		Where(fmt.Sprintf("coalesce(fields->>'%s', '') NOT IN (?)", workitem.SystemState), []string{workitem.SystemStateClosed, workitem.SystemStateInactive})
+	if clause, params := workitem.VisibilityClause(ctx, table); clause != "" {
+		db = db.Where(clause, params...)
+	}
+	var rows []struct {
+		Project string
+		Type    string
+		State   string
+		Count   int
+	}
+	if err := db.Group("1, 2, 3").Scan(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	counts := map[Count]int{}
+	for _, row := range rows {
+		if id, err := uuid.FromString(row.Project); err == nil {
+			res.Projects[id] += row.Count
+		}
+		counts[Count{Type: row.Type, State: row.State}] += row.Count
+	}
+	for c, count := range counts {
+		c.Count = count
+		res.Counts = append(res.Counts, c)
+	}
+	sort.Sort(byTypeAndState(res.Counts))
+	return &res, nil
+}
+
+// CheckLink checks that work items of the source project can be linked to
+// ones of the target project with the tree link type, which is the case for
+// work items of the same project or if a portfolio containing both projects
+// designates the link type
+// returns BadParameterError or InternalError
+func (m *GormPortfolioRepository) CheckLink(ctx context.Context, sourceProjectID, targetProjectID, linkTypeID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "portfolio", "checklink"}, time.Now())
+
+	if uuid.Equal(sourceProjectID, targetProjectID) {
+		return nil
+	}
+	var count int
+	err := m.db.Model(&Portfolio{}).
+		Where("projects @> ? AND projects @> ? AND link_types @> ?",
+			fmt.Sprintf("[%q]", sourceProjectID.String()), fmt.Sprintf("[%q]", targetProjectID.String()), fmt.Sprintf("[%q]", linkTypeID.String())).
+		Count(&count).Error
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if count == 0 {
+		return errors.NewBadParameterError("link", sourceProjectID.String()+" -> "+targetProjectID.String()).Expected(
+			"work items of the same project, or of projects of a portfolio that designates the link type")
+	}
+	return nil
+}
+
+type byTypeAndState []Count
+
+func (s byTypeAndState) Len() int      { return len(s) }
+func (s byTypeAndState) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byTypeAndState) Less(i, j int) bool {
+	if s[i].Type != s[j].Type {
+		return s[i].Type < s[j].Type
+	}
+	return s[i].State < s[j].State
+}
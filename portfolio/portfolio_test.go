@@ -0,0 +1,109 @@
+package portfolio_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/portfolio"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestValidate(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	id := uuid.NewV4()
+	assert.Nil(t, portfolio.Portfolio{Name: "platform", Projects: portfolio.IDs{id}, LinkTypes: portfolio.IDs{id}}.Validate())
+	for _, p := range []portfolio.Portfolio{
+		{Projects: portfolio.IDs{id}},
+		{Name: "platform", Projects: portfolio.IDs{id, id}},
+		{Name: "platform", LinkTypes: portfolio.IDs{id, id}},
+		{Name: "platform", Projects: make(portfolio.IDs, portfolio.MaxProjects+1)},
+	} {
+		assert.IsType(t, errors.BadParameterError{}, p.Validate(), "%+v", p)
+	}
+}
+
+type TestPortfolioRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunPortfolioRepository(t *testing.T) {
+	suite.Run(t, &TestPortfolioRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestPortfolioRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestPortfolioRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestPortfolioRepository) createWorkItem(p *project.Project, typeName string, state string) {
+	_, err := workitem.NewWorkItemRepository(test.DB).Create(context.Background(), typeName,
+		map[string]interface{}{
+			workitem.SystemTitle:   "portfolio",
+			workitem.SystemState:   state,
+			workitem.SystemProject: p.ID.String(),
+		}, "xx")
+	require.Nil(test.T(), err)
+}
+
+func (test *TestPortfolioRepository) TestReportAndCheckLink() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	owner := account.Identity{FullName: "portfolio owner"}
+	require.Nil(t, account.NewIdentityRepository(test.DB).Create(ctx, &owner))
+	projects := project.NewRepository(test.DB)
+	first, err := projects.Create(ctx, "portfolio-"+uuid.NewV4().String())
+	require.Nil(t, err)
+	second, err := projects.Create(ctx, "portfolio-"+uuid.NewV4().String())
+	require.Nil(t, err)
+	outside, err := projects.Create(ctx, "portfolio-"+uuid.NewV4().String())
+	require.Nil(t, err)
+	test.createWorkItem(first, workitem.SystemBug, workitem.SystemStateOpen)
+	test.createWorkItem(first, workitem.SystemBug, workitem.SystemStateClosed)
+	test.createWorkItem(second, workitem.SystemBug, workitem.SystemStateOpen)
+	test.createWorkItem(second, workitem.SystemFeature, workitem.SystemStateInProgress)
+	test.createWorkItem(outside, workitem.SystemBug, workitem.SystemStateOpen)
+
+	linkTypeID := uuid.NewV4()
+	repo := portfolio.NewPortfolioRepository(test.DB)
+	p := portfolio.Portfolio{Name: "platform", OwnerID: owner.ID, Projects: portfolio.IDs{first.ID, second.ID}, LinkTypes: portfolio.IDs{linkTypeID}}
+	require.Nil(t, repo.Create(ctx, &p))
+
+	report, err := repo.Report(ctx, p)
+	require.Nil(t, err)
+	assert.Equal(t, []portfolio.Count{
+		{Type: workitem.SystemBug, State: workitem.SystemStateOpen, Count: 2},
+		{Type: workitem.SystemFeature, State: workitem.SystemStateInProgress, Count: 1},
+	}, report.Counts)
+	assert.Equal(t, map[uuid.UUID]int{first.ID: 1, second.ID: 2}, report.Projects)
+
+	assert.Nil(t, repo.CheckLink(ctx, first.ID, first.ID, uuid.NewV4()))
+	assert.Nil(t, repo.CheckLink(ctx, first.ID, second.ID, linkTypeID))
+	assert.IsType(t, errors.BadParameterError{}, repo.CheckLink(ctx, first.ID, second.ID, uuid.NewV4()))
+	assert.IsType(t, errors.BadParameterError{}, repo.CheckLink(ctx, first.ID, outside.ID, linkTypeID))
+
+	p.Projects = portfolio.IDs{first.ID}
+	require.Nil(t, repo.Save(ctx, &p))
+	assert.IsType(t, errors.BadParameterError{}, repo.CheckLink(ctx, first.ID, second.ID, linkTypeID))
+	p.Version = 0
+	assert.IsType(t, errors.VersionConflictError{}, repo.Save(ctx, &p))
+	require.Nil(t, repo.Delete(ctx, p.ID))
+	assert.IsType(t, errors.NotFoundError{}, repo.Delete(ctx, p.ID))
+}
@@ -0,0 +1,171 @@
+package printout
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Layout of the PDF pages: A4 in points with the standard Helvetica fonts,
+// which every PDF reader has, so no fonts are embedded
+const (
+	pageWidth  = 595
+	pageHeight = 842
+	margin     = 56
+	leading    = 14
+	// lineChars is about how many characters of 10pt Helvetica fit in a line
+	lineChars = 92
+	// pageLines is how many lines fit on a page
+	pageLines = (pageHeight - 2*margin) / leading
+)
+
+// pdfLine is a line of text of a PDF page
+type pdfLine struct {
+	text string
+	bold bool
+}
+
+// RenderPDF renders the document as a PDF with text only. Characters the
+// standard fonts can't show are replaced with question marks.
+func RenderPDF(doc *Document) []byte {
+	var lines []pdfLine
+	heading := func(text string) {
+		if len(lines) > 0 {
+			lines = append(lines, pdfLine{})
+		}
+		lines = append(lines, pdfLine{text: text, bold: true})
+	}
+	text := func(s string) {
+		for _, l := range wrap(s, lineChars) {
+			lines = append(lines, pdfLine{text: l})
+		}
+	}
+	heading(fmt.Sprintf("#%s %s", doc.ID, doc.Title))
+	for _, f := range doc.Fields {
+		text(f.Name + ": " + f.Value)
+	}
+	if doc.Description != "" {
+		heading("Description")
+		text(doc.Description)
+	}
+	if len(doc.Links) > 0 {
+		heading("Links")
+		for _, l := range doc.Links {
+			text(fmt.Sprintf("%s #%s %s", l.Relation, l.ID, l.Title))
+		}
+	}
+	if len(doc.Comments) > 0 {
+		heading("Comments")
+		for i, c := range doc.Comments {
+			if i > 0 {
+				lines = append(lines, pdfLine{})
+			}
+			lines = append(lines, pdfLine{text: fmt.Sprintf("%s, %s", c.Author, c.CreatedAt.UTC().Format("2006-01-02 15:04 MST")), bold: true})
+			text(c.Body)
+		}
+	}
+	lines = append(lines, pdfLine{}, pdfLine{text: "Printed " + doc.PrintedAt.UTC().Format("2006-01-02 15:04 MST")})
+
+	var pages [][]pdfLine
+	for len(lines) > pageLines {
+		pages = append(pages, lines[:pageLines])
+		lines = lines[pageLines:]
+	}
+	pages = append(pages, lines)
+	return writePDF(pages)
+}
+
+// writePDF writes the pages as a PDF document. The objects are the catalog
+// (1), the page tree (2), the fonts (3 and 4) and a page and its content
+// stream for every page.
+func writePDF(pages [][]pdfLine) []byte {
+	var b bytes.Buffer
+	var offsets []int
+	object := func(content string) {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", len(offsets), content)
+	}
+	b.WriteString("%PDF-1.4\n")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, page := range pages {
+		var s bytes.Buffer
+		fmt.Fprintf(&s, "BT\n%d TL\n%d %d Td\n", leading, margin, pageHeight-margin)
+		for _, l := range page {
+			font := "/F1"
+			if l.bold {
+				font = "/F2"
+			}
+			fmt.Fprintf(&s, "%s 10 Tf (%s) Tj T*\n", font, pdfText(l.text))
+		}
+		s.WriteString("ET")
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", s.Len(), s.String()))
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, o := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", o)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return b.Bytes()
+}
+
+// pdfText returns the text as the content of a PDF string in the Latin-1
+// range of WinAnsiEncoding
+func pdfText(s string) string {
+	var b bytes.Buffer
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\t':
+			b.WriteByte(' ')
+		case r < 0x20 || (r >= 0x7f && r < 0xa0) || r > 0xff:
+			b.WriteByte('?')
+		default:
+			b.WriteByte(byte(r))
+		}
+	}
+	return b.String()
+}
+
+// wrap breaks the text into lines of at most width characters at spaces,
+// longer words are broken as well
+func wrap(s string, width int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.Replace(s, "\r\n", "\n", -1), "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			for utf8.RuneCountInString(word) > width {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				runes := []rune(word)
+				lines = append(lines, string(runes[:width]))
+				word = string(runes[width:])
+			}
+			switch {
+			case line == "":
+				line = word
+			case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
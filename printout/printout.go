@@ -0,0 +1,143 @@
+// Package printout renders work items with their comments and links as
+// printable documents, HTML for browsers and PDF for archives, e.g. for
+// audits and offline reviews.
+package printout
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/workitem"
+)
+
+// Field is a field of the work item with its value formatted as text
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Comment is a comment on the work item
+type Comment struct {
+	Author    string
+	CreatedAt time.Time
+	Body      string
+}
+
+// Link is a link of the work item to another one, Relation is the forward
+// or reverse name of its type as seen from the work item
+type Link struct {
+	Relation string
+	ID       string
+	Title    string
+}
+
+// Document is a printable work item
+type Document struct {
+	ID          string
+	Title       string
+	Fields      []Field
+	Description string
+	Links       []Link
+	Comments    []Comment
+	PrintedAt   time.Time
+}
+
+// NewDocument returns the document of the work item with its fields sorted
+// by name, the comments and links are added by the caller
+func NewDocument(wi *app.WorkItem, printedAt time.Time) *Document {
+	doc := &Document{ID: wi.ID, PrintedAt: printedAt}
+	doc.Title, _ = wi.Fields[workitem.SystemTitle].(string)
+	doc.Description, _ = wi.Fields[workitem.SystemDescription].(string)
+	for name, value := range wi.Fields {
+		if name == workitem.SystemTitle || name == workitem.SystemDescription || value == nil {
+			continue
+		}
+		doc.Fields = append(doc.Fields, Field{Name: name, Value: FormatValue(value)})
+	}
+	sort.Sort(byName(doc.Fields))
+	return doc
+}
+
+// byName sorts fields by name
+type byName []Field
+
+func (fs byName) Len() int           { return len(fs) }
+func (fs byName) Swap(i, j int)      { fs[i], fs[j] = fs[j], fs[i] }
+func (fs byName) Less(i, j int) bool { return fs[i].Name < fs[j].Name }
+
+// FormatValue formats a field value as text: lists are comma separated,
+// times in RFC 3339 and objects as JSON
+func FormatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case []interface{}:
+		values := make([]string, len(v))
+		for i, item := range v {
+			values[i] = FormatValue(item)
+		}
+		return strings.Join(values, ", ")
+	case map[string]interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(b)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+var htmlTemplate = template.Must(template.New("printout").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>#{{.ID}} {{.Title}}</title>
+<style>
+body { font-family: sans-serif; font-size: 10pt; margin: 2cm; }
+h1 { font-size: 16pt; }
+h2 { font-size: 12pt; border-bottom: 1px solid #999; margin-top: 1.5em; }
+table { border-collapse: collapse; }
+th, td { text-align: left; vertical-align: top; padding: 2px 12px 2px 0; }
+.text { white-space: pre-wrap; }
+.comment { margin-bottom: 1em; page-break-inside: avoid; }
+.meta { color: #555; }
+</style>
+</head>
+<body>
+<h1>#{{.ID}} {{.Title}}</h1>
+<table>
+{{range .Fields}}<tr><th>{{.Name}}</th><td>{{.Value}}</td></tr>
+{{end}}</table>
+{{if .Description}}<h2>Description</h2>
+<div class="text">{{.Description}}</div>
+{{end}}{{if .Links}}<h2>Links</h2>
+<ul>
+{{range .Links}}<li>{{.Relation}} #{{.ID}} {{.Title}}</li>
+{{end}}</ul>
+{{end}}{{if .Comments}}<h2>Comments</h2>
+{{range .Comments}}<div class="comment"><div class="meta">{{.Author}}, {{.CreatedAt.UTC.Format "2006-01-02 15:04 MST"}}</div>
+<div class="text">{{.Body}}</div></div>
+{{end}}{{end}}<p class="meta">Printed {{.PrintedAt.UTC.Format "2006-01-02 15:04 MST"}}</p>
+</body>
+</html>
+`))
+
+// RenderHTML renders the document as a standalone HTML page
+func RenderHTML(doc *Document) ([]byte, error) {
+	var b bytes.Buffer
+	if err := htmlTemplate.Execute(&b, doc); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,66 @@
+package printout_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/printout"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDocument() *printout.Document {
+	doc := printout.NewDocument(&app.WorkItem{ID: "12", Fields: map[string]interface{}{
+		workitem.SystemTitle:       "Crash on <save>",
+		workitem.SystemDescription: "Steps:\n1. open\n2. save",
+		workitem.SystemState:       "open",
+		workitem.SystemAssignees:   []interface{}{"jane", "joe"},
+		workitem.SystemIteration:   nil,
+	}}, time.Date(2017, 3, 1, 12, 0, 0, 0, time.UTC))
+	doc.Links = []printout.Link{{Relation: "blocks", ID: "13", Title: "Release"}}
+	doc.Comments = []printout.Comment{{Author: "Jane Doe", CreatedAt: time.Date(2017, 2, 28, 9, 30, 0, 0, time.UTC), Body: "Happens (always)"}}
+	return doc
+}
+
+func TestNewDocument(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	doc := testDocument()
+	assert.Equal(t, "Crash on <save>", doc.Title)
+	assert.Equal(t, []printout.Field{
+		{Name: workitem.SystemAssignees, Value: "jane, joe"},
+		{Name: workitem.SystemState, Value: "open"},
+	}, doc.Fields)
+}
+
+func TestRenderHTML(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	page, err := printout.RenderHTML(testDocument())
+	require.Nil(t, err)
+	html := string(page)
+	assert.Contains(t, html, "<h1>#12 Crash on &lt;save&gt;</h1>")
+	assert.Contains(t, html, "<th>system.assignees</th><td>jane, joe</td>")
+	assert.Contains(t, html, "<li>blocks #13 Release</li>")
+	assert.Contains(t, html, "Jane Doe, 2017-02-28 09:30 UTC")
+}
+
+func TestRenderPDF(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	doc := testDocument()
+	pdf := printout.RenderPDF(doc)
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(pdf, []byte("%%EOF\n")))
+	assert.Contains(t, string(pdf), "(Happens \\(always\\)) Tj")
+	assert.Contains(t, string(pdf), "/Count 1")
+
+	// long descriptions continue on further pages
+	doc.Description = strings.Repeat("lorem ipsum dolor sit amet ", 200)
+	assert.Contains(t, string(printout.RenderPDF(doc)), "/Count 2")
+}
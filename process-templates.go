@@ -0,0 +1,80 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/processtemplate"
+	"github.com/goadesign/goa"
+)
+
+// ProcessTemplatesController implements the process-templates resource.
+type ProcessTemplatesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProcessTemplatesController creates a process-templates controller.
+func NewProcessTemplatesController(service *goa.Service, db application.DB) *ProcessTemplatesController {
+	return &ProcessTemplatesController{Controller: service.NewController("ProcessTemplatesController"), db: db}
+}
+
+// List runs the list action.
+func (c *ProcessTemplatesController) List(ctx *app.ListProcessTemplatesContext) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		templates, err := appl.ProcessTemplates().List(ctx)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.ProcessTemplateList{Data: make([]*app.ProcessTemplate, len(templates))}
+		for i, t := range templates {
+			res.Data[i] = convertProcessTemplate(t)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Show runs the show action.
+func (c *ProcessTemplatesController) Show(ctx *app.ShowProcessTemplatesContext) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		t, err := appl.ProcessTemplates().Load(ctx, ctx.Name)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(convertProcessTemplate(t))
+	})
+}
+
+// Update runs the update action.
+func (c *ProcessTemplatesController) Update(ctx *app.UpdateProcessTemplatesContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can manage process templates"))
+	}
+	t := processtemplate.Template{Name: ctx.Name, Config: ctx.Payload.Config}
+	if ctx.Payload.Description != nil {
+		t.Description = *ctx.Payload.Description
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if err := appl.ProcessTemplates().Save(ctx, &t); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(convertProcessTemplate(&t))
+	})
+}
+
+// Delete runs the delete action.
+func (c *ProcessTemplatesController) Delete(ctx *app.DeleteProcessTemplatesContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can manage process templates"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if err := appl.ProcessTemplates().Delete(ctx, ctx.Name); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.NoContent()
+	})
+}
+
+func convertProcessTemplate(t *processtemplate.Template) *app.ProcessTemplate {
+	return &app.ProcessTemplate{Name: t.Name, Description: t.Description, Config: t.Config, Custom: t.Custom}
+}
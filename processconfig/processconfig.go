@@ -0,0 +1,606 @@
+// Package processconfig dumps the process customization as YAML config and
+// applies such configs idempotently, so it can be managed as code: the work
+// item types, link categories and link types, which all projects share, and
+// the workflows, the work item type hierarchy and the boards of a project.
+// The workflows are the approval rules of the state transitions, tried in
+// their order. The boards are project dashboards.
+package processconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/approval"
+	"github.com/almighty/almighty-core/dashboard"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/hierarchy"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/almighty/almighty-core/workitem/link"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Config is the process customization of a project
+type Config struct {
+	// Types are created if missing but never changed, dumps leave them out
+	Types      []Type     `yaml:"types,omitempty"`
+	Categories []Category `yaml:"categories"`
+	LinkTypes  []LinkType `yaml:"link-types"`
+	Workflows  []Workflow `yaml:"workflows"`
+	// TypeHierarchy are the work item types of the hierarchy of the project,
+	// the top level first, empty if it has none
+	TypeHierarchy []string `yaml:"type-hierarchy"`
+	// Boards are created if the project has no dashboard of their name but
+	// never changed, dumps leave them out
+	Boards []Board `yaml:"boards,omitempty"`
+}
+
+// Type is a work item type, it extends a type created before it or an
+// existing one
+type Type struct {
+	Name    string           `yaml:"name"`
+	Extends string           `yaml:"extends,omitempty"`
+	Fields  map[string]Field `yaml:"fields,omitempty"`
+}
+
+// Field is a field of a work item type, enums of strings list their values
+type Field struct {
+	Kind     string   `yaml:"kind"`
+	Required bool     `yaml:"required,omitempty"`
+	Values   []string `yaml:"values,omitempty"`
+}
+
+// definitions returns the field definitions of the type
+func (t Type) definitions() map[string]app.FieldDefinition {
+	res := map[string]app.FieldDefinition{}
+	for name, f := range t.Fields {
+		fieldType := &app.FieldType{Kind: f.Kind}
+		if f.Kind == string(workitem.KindEnum) {
+			baseType := string(workitem.KindString)
+			fieldType.BaseType = &baseType
+			for _, v := range f.Values {
+				fieldType.Values = append(fieldType.Values, v)
+			}
+		}
+		res[name] = app.FieldDefinition{Required: f.Required, Type: fieldType}
+	}
+	return res
+}
+
+// Board is a project dashboard counting the work items in the state of each
+// column
+type Board struct {
+	Name    string   `yaml:"name"`
+	Columns []string `yaml:"columns"`
+}
+
+// dashboard returns the dashboard of the board in the project
+func (b Board) dashboard(projectID uuid.UUID) (*dashboard.Dashboard, error) {
+	d := &dashboard.Dashboard{Name: b.Name, ProjectID: &projectID, Widgets: dashboard.Widgets{}}
+	for i, state := range b.Columns {
+		filter, err := json.Marshal(map[string]string{workitem.SystemState: state})
+		if err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		d.Widgets = append(d.Widgets, dashboard.Widget{ID: fmt.Sprintf("column-%d", i), Kind: dashboard.WidgetCount, Title: state, Filter: string(filter)})
+	}
+	return d, d.Validate()
+}
+
+// Category is a link category
+type Category struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// LinkType is a link type, its name is unique within its category
+type LinkType struct {
+	Name        string `yaml:"name"`
+	Category    string `yaml:"category"`
+	Description string `yaml:"description,omitempty"`
+	Topology    string `yaml:"topology"`
+	// OnDelete defaults to detaching the links
+	OnDelete    string `yaml:"on-delete,omitempty"`
+	SourceType  string `yaml:"source-type"`
+	TargetType  string `yaml:"target-type"`
+	ForwardName string `yaml:"forward-name"`
+	ReverseName string `yaml:"reverse-name"`
+}
+
+// Workflow is an approval rule of a state transition, an empty type or from
+// state matches all
+type Workflow struct {
+	Type      string   `yaml:"type,omitempty"`
+	From      string   `yaml:"from,omitempty"`
+	To        string   `yaml:"to"`
+	Roles     []string `yaml:"roles"`
+	Approvals int      `yaml:"approvals"`
+}
+
+// name returns the transition of the workflow, e.g. "system.bug: * -> closed"
+func (w Workflow) name() string {
+	typeName, from := w.Type, w.From
+	if typeName == "" {
+		typeName = "*"
+	}
+	if from == "" {
+		from = "*"
+	}
+	return fmt.Sprintf("%s: %s -> %s", typeName, from, w.To)
+}
+
+// rule returns the approval rule of the workflow in the project
+func (w Workflow) rule(projectID uuid.UUID) approval.Rule {
+	return approval.Rule{ProjectID: projectID, Type: w.Type, FromState: w.From, ToState: w.To, Roles: approval.Roles(w.Roles), Approvals: w.Approvals}
+}
+
+// Parse reads a YAML config
+// returns BadParameterError
+func Parse(data []byte) (*Config, error) {
+	var c Config
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, errors.NewBadParameterError("config", err.Error()).Expected("a YAML process config")
+	}
+	return &c, nil
+}
+
+// Marshal returns the config as YAML
+func (c *Config) Marshal() ([]byte, error) {
+	return yaml.Marshal(c)
+}
+
+// The actions of changes
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Change is a change applying a config makes
+type Change struct {
+	Action string
+	Kind   string
+	Name   string
+	// Global changes affect all projects
+	Global bool
+	apply  func(ctx context.Context) error
+}
+
+// String returns the change as text, e.g. "create link type user/blocks"
+func (c Change) String() string {
+	return c.Action + " " + c.Kind + " " + c.Name
+}
+
+// Plan are the changes applying a config makes, in their order
+type Plan struct {
+	Changes []Change
+}
+
+// Global returns true if the plan changes the work item types, link
+// categories or link types shared by all projects
+func (p *Plan) Global() bool {
+	for _, c := range p.Changes {
+		if c.Global {
+			return true
+		}
+	}
+	return false
+}
+
+// Execute makes the changes of the plan
+// returns BadParameterError, VersionConflictError or InternalError
+func (p *Plan) Execute(ctx context.Context) error {
+	for _, c := range p.Changes {
+		if err := c.apply(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Repository encapsulates the process config of projects
+type Repository interface {
+	Dump(ctx context.Context, projectID uuid.UUID) (*Config, error)
+	Plan(ctx context.Context, projectID uuid.UUID, c *Config) (*Plan, error)
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{
+		db:          db,
+		categories:  link.NewWorkItemLinkCategoryRepository(db),
+		linkTypes:   link.NewWorkItemLinkTypeRepository(db),
+		types:       workitem.NewWorkItemTypeRepository(db),
+		approvals:   approval.NewRepository(db),
+		hierarchies: hierarchy.NewTypeHierarchyRepository(db),
+		dashboards:  dashboard.NewDashboardRepository(db),
+	}
+}
+
+// GormRepository is the implementation of the storage interface for process
+// configs.
+type GormRepository struct {
+	db          *gorm.DB
+	categories  *link.GormWorkItemLinkCategoryRepository
+	linkTypes   *link.GormWorkItemLinkTypeRepository
+	types       *workitem.GormWorkItemTypeRepository
+	approvals   approval.Repository
+	hierarchies hierarchy.Repository
+	dashboards  dashboard.Repository
+}
+
+// Dump returns the config of the project with all link categories and link
+// types ordered by name
+// returns InternalError
+func (m *GormRepository) Dump(ctx context.Context, projectID uuid.UUID) (*Config, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "processconfig", "dump"}, time.Now())
+
+	c := Config{Categories: []Category{}, LinkTypes: []LinkType{}, Workflows: []Workflow{}, TypeHierarchy: []string{}}
+	categories, err := m.loadCategories()
+	if err != nil {
+		return nil, err
+	}
+	names := map[uuid.UUID]string{}
+	for _, cat := range categories {
+		names[cat.ID] = cat.Name
+		c.Categories = append(c.Categories, Category{Name: cat.Name, Description: deref(cat.Description)})
+	}
+	linkTypes, err := m.loadLinkTypes()
+	if err != nil {
+		return nil, err
+	}
+	for _, lt := range linkTypes {
+		c.LinkTypes = append(c.LinkTypes, LinkType{
+			Name:        lt.Name,
+			Category:    names[lt.LinkCategoryID],
+			Description: deref(lt.Description),
+			Topology:    lt.Topology,
+			OnDelete:    lt.OnDelete,
+			SourceType:  lt.SourceTypeName,
+			TargetType:  lt.TargetTypeName,
+			ForwardName: lt.ForwardName,
+			ReverseName: lt.ReverseName,
+		})
+	}
+	rules, err := m.approvals.ListRules(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rules {
+		c.Workflows = append(c.Workflows, Workflow{Type: r.Type, From: r.FromState, To: r.ToState, Roles: []string(r.Roles), Approvals: r.Approvals})
+	}
+	h, err := m.hierarchies.Load(ctx, projectID)
+	if err == nil {
+		c.TypeHierarchy = []string(h.Levels)
+	} else if _, ok := err.(errors.NotFoundError); !ok {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Plan returns the changes making the stored config of the project match c.
+// Link categories and link types missing in c are kept since other projects
+// may use them, the source and target types of existing link types can't be
+// changed. Workflows are matched by their position.
+// returns BadParameterError or InternalError
+func (m *GormRepository) Plan(ctx context.Context, projectID uuid.UUID, c *Config) (*Plan, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "processconfig", "plan"}, time.Now())
+
+	p := &Plan{Changes: []Change{}}
+	if err := m.planTypes(p, c); err != nil {
+		return nil, err
+	}
+	if err := m.planCategories(p, c); err != nil {
+		return nil, err
+	}
+	if err := m.planLinkTypes(p, c); err != nil {
+		return nil, err
+	}
+	if err := m.planWorkflows(ctx, p, projectID, c); err != nil {
+		return nil, err
+	}
+	if err := m.planTypeHierarchy(ctx, p, projectID, c); err != nil {
+		return nil, err
+	}
+	if err := m.planBoards(ctx, p, projectID, c); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (m *GormRepository) planTypes(p *Plan, c *Config) error {
+	planned := map[string]bool{}
+	for i := range c.Types {
+		t := c.Types[i]
+		param := fmt.Sprintf("types[%d]", i)
+		if t.Name == "" || planned[t.Name] {
+			return errors.NewBadParameterError(param+".name", t.Name).Expected("a unique name")
+		}
+		planned[t.Name] = true
+		if _, err := m.types.LoadTypeFromDB(t.Name); err == nil {
+			continue
+		}
+		var extends *string
+		if t.Extends != "" {
+			if _, err := m.types.LoadTypeFromDB(t.Extends); err != nil && !planned[t.Extends] {
+				return errors.NewBadParameterError(param+".extends", t.Extends).Expected("the name of an existing work item type or of one created before")
+			}
+			extends = &t.Extends
+		}
+		p.Changes = append(p.Changes, Change{Action: ActionCreate, Kind: "work item type", Name: t.Name, Global: true, apply: func(ctx context.Context) error {
+			_, err := m.types.Create(ctx, extends, t.Name, t.definitions())
+			return err
+		}})
+	}
+	return nil
+}
+
+func (m *GormRepository) planCategories(p *Plan, c *Config) error {
+	existing, err := m.loadCategories()
+	if err != nil {
+		return err
+	}
+	byName := map[string]link.WorkItemLinkCategory{}
+	for _, cat := range existing {
+		byName[cat.Name] = cat
+	}
+	seen := map[string]bool{}
+	for i := range c.Categories {
+		cat := c.Categories[i]
+		if cat.Name == "" || seen[cat.Name] {
+			return errors.NewBadParameterError(fmt.Sprintf("categories[%d].name", i), cat.Name).Expected("a unique name")
+		}
+		seen[cat.Name] = true
+		description := optional(cat.Description)
+		old, ok := byName[cat.Name]
+		switch {
+		case !ok:
+			p.Changes = append(p.Changes, Change{Action: ActionCreate, Kind: "link category", Name: cat.Name, Global: true, apply: func(ctx context.Context) error {
+				_, err := m.categories.Create(ctx, &cat.Name, description)
+				return err
+			}})
+		case deref(old.Description) != cat.Description:
+			p.Changes = append(p.Changes, Change{Action: ActionUpdate, Kind: "link category", Name: cat.Name, Global: true, apply: func(ctx context.Context) error {
+				update := link.ConvertLinkCategoryFromModel(old)
+				update.Data.Attributes.Description = &cat.Description
+				_, err := m.categories.Save(ctx, update)
+				return err
+			}})
+		}
+	}
+	return nil
+}
+
+func (m *GormRepository) planLinkTypes(p *Plan, c *Config) error {
+	categories, err := m.loadCategories()
+	if err != nil {
+		return err
+	}
+	categoryNames := map[uuid.UUID]string{}
+	categoryExists := map[string]bool{}
+	for _, cat := range categories {
+		categoryNames[cat.ID] = cat.Name
+		categoryExists[cat.Name] = true
+	}
+	for _, cat := range c.Categories {
+		categoryExists[cat.Name] = true
+	}
+	existing, err := m.loadLinkTypes()
+	if err != nil {
+		return err
+	}
+	byName := map[string]link.WorkItemLinkType{}
+	for _, lt := range existing {
+		byName[categoryNames[lt.LinkCategoryID]+"/"+lt.Name] = lt
+	}
+	seen := map[string]bool{}
+	for i := range c.LinkTypes {
+		lt := c.LinkTypes[i]
+		param := fmt.Sprintf("link-types[%d]", i)
+		name := lt.Category + "/" + lt.Name
+		if lt.Name == "" || seen[name] {
+			return errors.NewBadParameterError(param+".name", lt.Name).Expected("a unique name within the category")
+		}
+		seen[name] = true
+		if !categoryExists[lt.Category] {
+			return errors.NewBadParameterError(param+".category", lt.Category).Expected("the name of a link category")
+		}
+		if lt.OnDelete == "" {
+			lt.OnDelete = link.OnDeleteDetach
+		}
+		for _, typeName := range []string{lt.SourceType, lt.TargetType} {
+			if !m.typeExists(c, typeName) {
+				return errors.NewBadParameterError(param, typeName).Expected("the name of a work item type")
+			}
+		}
+		description := optional(lt.Description)
+		old, ok := byName[name]
+		if !ok {
+			p.Changes = append(p.Changes, Change{Action: ActionCreate, Kind: "link type", Name: name, Global: true, apply: func(ctx context.Context) error {
+				category, err := m.categories.LoadCategoryFromDB(ctx, lt.Category)
+				if err != nil {
+					return err
+				}
+				_, err = m.linkTypes.Create(ctx, lt.Name, description, lt.SourceType, lt.TargetType, lt.ForwardName, lt.ReverseName, lt.Topology, lt.OnDelete, category.ID)
+				return err
+			}})
+			continue
+		}
+		if old.SourceTypeName != lt.SourceType || old.TargetTypeName != lt.TargetType {
+			return errors.NewBadParameterError(param, name).Expected(fmt.Sprintf("the source type %s and target type %s of the existing link type", old.SourceTypeName, old.TargetTypeName))
+		}
+		if deref(old.Description) != lt.Description || old.Topology != lt.Topology || old.OnDelete != lt.OnDelete ||
+			old.ForwardName != lt.ForwardName || old.ReverseName != lt.ReverseName {
+			p.Changes = append(p.Changes, Change{Action: ActionUpdate, Kind: "link type", Name: name, Global: true, apply: func(ctx context.Context) error {
+				update := link.ConvertLinkTypeFromModel(old)
+				attrs := update.Data.Attributes
+				attrs.Description, attrs.Topology, attrs.OnDelete = &lt.Description, &lt.Topology, &lt.OnDelete
+				attrs.ForwardName, attrs.ReverseName = &lt.ForwardName, &lt.ReverseName
+				_, err := m.linkTypes.Save(ctx, update)
+				return err
+			}})
+		}
+	}
+	return nil
+}
+
+func (m *GormRepository) planWorkflows(ctx context.Context, p *Plan, projectID uuid.UUID, c *Config) error {
+	existing, err := m.approvals.ListRules(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	for i, w := range c.Workflows {
+		r := w.rule(projectID)
+		if err := r.Validate(); err != nil {
+			return errors.NewBadParameterError(fmt.Sprintf("workflows[%d]", i), w.name()).Expected(err.Error())
+		}
+		if w.Type != "" {
+			if !m.typeExists(c, w.Type) {
+				return errors.NewBadParameterError(fmt.Sprintf("workflows[%d].type", i), w.Type).Expected("the name of a work item type")
+			}
+		}
+		if i >= len(existing) {
+			p.Changes = append(p.Changes, Change{Action: ActionCreate, Kind: "workflow", Name: w.name(), apply: func(ctx context.Context) error {
+				return m.approvals.CreateRule(ctx, &r)
+			}})
+			continue
+		}
+		old := existing[i]
+		if old.Type != r.Type || old.FromState != r.FromState || old.ToState != r.ToState || old.Approvals != r.Approvals ||
+			!reflect.DeepEqual([]string(old.Roles), []string(r.Roles)) {
+			r.ID, r.Version = old.ID, old.Version
+			p.Changes = append(p.Changes, Change{Action: ActionUpdate, Kind: "workflow", Name: w.name(), apply: func(ctx context.Context) error {
+				return m.approvals.SaveRule(ctx, &r)
+			}})
+		}
+	}
+	for i := len(c.Workflows); i < len(existing); i++ {
+		old := existing[i]
+		w := Workflow{Type: old.Type, From: old.FromState, To: old.ToState}
+		p.Changes = append(p.Changes, Change{Action: ActionDelete, Kind: "workflow", Name: w.name(), apply: func(ctx context.Context) error {
+			return m.approvals.DeleteRule(ctx, old.ID)
+		}})
+	}
+	return nil
+}
+
+func (m *GormRepository) planTypeHierarchy(ctx context.Context, p *Plan, projectID uuid.UUID, c *Config) error {
+	var levels []string
+	old, err := m.hierarchies.Load(ctx, projectID)
+	if err == nil {
+		levels = []string(old.Levels)
+	} else if _, ok := err.(errors.NotFoundError); !ok {
+		return err
+	}
+	name := hierarchy.Hierarchy{Levels: c.TypeHierarchy}.String()
+	switch {
+	case len(c.TypeHierarchy) == 0 && len(levels) == 0:
+	case len(c.TypeHierarchy) == 0:
+		p.Changes = append(p.Changes, Change{Action: ActionDelete, Kind: "type hierarchy", Name: old.String(), apply: func(ctx context.Context) error {
+			return m.hierarchies.Delete(ctx, projectID)
+		}})
+	case !reflect.DeepEqual(levels, c.TypeHierarchy):
+		h := hierarchy.Hierarchy{ProjectID: projectID, Levels: hierarchy.Levels(c.TypeHierarchy)}
+		if err := h.Validate(); err != nil {
+			return err
+		}
+		for _, typeName := range h.Levels {
+			if !m.typeExists(c, typeName) {
+				return errors.NewBadParameterError("type-hierarchy", typeName).Expected("the name of a work item type")
+			}
+		}
+		action := ActionUpdate
+		if len(levels) == 0 {
+			action = ActionCreate
+		}
+		p.Changes = append(p.Changes, Change{Action: action, Kind: "type hierarchy", Name: name, apply: func(ctx context.Context) error {
+			_, err := m.hierarchies.Save(ctx, h)
+			return err
+		}})
+	}
+	return nil
+}
+
+func (m *GormRepository) planBoards(ctx context.Context, p *Plan, projectID uuid.UUID, c *Config) error {
+	existing, err := m.dashboards.ListProject(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	names := map[string]bool{}
+	for _, d := range existing {
+		names[d.Name] = true
+	}
+	seen := map[string]bool{}
+	for i, b := range c.Boards {
+		param := fmt.Sprintf("boards[%d]", i)
+		if b.Name == "" || seen[b.Name] {
+			return errors.NewBadParameterError(param+".name", b.Name).Expected("a unique name")
+		}
+		seen[b.Name] = true
+		if len(b.Columns) == 0 {
+			return errors.NewBadParameterError(param+".columns", len(b.Columns)).Expected("at least one state")
+		}
+		d, err := b.dashboard(projectID)
+		if err != nil {
+			return err
+		}
+		if names[b.Name] {
+			continue
+		}
+		p.Changes = append(p.Changes, Change{Action: ActionCreate, Kind: "board", Name: b.Name, apply: func(ctx context.Context) error {
+			return m.dashboards.Create(ctx, d)
+		}})
+	}
+	return nil
+}
+
+// typeExists returns true if the work item type is stored or created by the
+// config
+func (m *GormRepository) typeExists(c *Config, name string) bool {
+	for _, t := range c.Types {
+		if t.Name == name {
+			return true
+		}
+	}
+	_, err := m.types.LoadTypeFromDB(name)
+	return err == nil
+}
+
+func (m *GormRepository) loadCategories() ([]link.WorkItemLinkCategory, error) {
+	var res []link.WorkItemLinkCategory
+	if err := m.db.Order("name").Find(&res).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return res, nil
+}
+
+func (m *GormRepository) loadLinkTypes() ([]link.WorkItemLinkType, error) {
+	var res []link.WorkItemLinkType
+	if err := m.db.Order("name, id").Find(&res).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return res, nil
+}
+
+// deref returns the string s points to, empty if s is nil
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// optional returns a pointer to s, nil if s is empty
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,153 @@
+package processconfig_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/approval"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/processconfig"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/almighty/almighty-core/workitem/link"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestParse(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	c, err := processconfig.Parse([]byte(`
+categories:
+- name: planning
+link-types:
+- name: blocks
+  category: planning
+  topology: dependency
+  source-type: system.bug
+  target-type: system.bug
+  forward-name: blocks
+  reverse-name: blocked by
+workflows:
+- type: system.bug
+  to: closed
+  roles: [project-admin]
+  approvals: 1
+type-hierarchy: [system.feature, system.bug]
+`))
+	require.Nil(t, err)
+	assert.Equal(t, []processconfig.Category{{Name: "planning"}}, c.Categories)
+	require.Len(t, c.LinkTypes, 1)
+	assert.Equal(t, "blocked by", c.LinkTypes[0].ReverseName)
+	assert.Equal(t, []processconfig.Workflow{{Type: workitem.SystemBug, To: "closed", Roles: []string{approval.RoleProjectAdmin}, Approvals: 1}}, c.Workflows)
+	assert.Equal(t, []string{workitem.SystemFeature, workitem.SystemBug}, c.TypeHierarchy)
+
+	out, err := c.Marshal()
+	require.Nil(t, err)
+	again, err := processconfig.Parse(out)
+	require.Nil(t, err)
+	assert.Equal(t, c, again)
+
+	_, err = processconfig.Parse([]byte("categories: {"))
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
+
+type TestProcessConfigRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunProcessConfigRepository(t *testing.T) {
+	suite.Run(t, &TestProcessConfigRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestProcessConfigRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestProcessConfigRepository) TearDownTest() {
+	test.clean()
+}
+
+// changes returns the changes of the plan as text
+func changes(p *processconfig.Plan) []string {
+	res := []string{}
+	for _, c := range p.Changes {
+		res = append(res, c.String())
+	}
+	return res
+}
+
+func (test *TestProcessConfigRepository) TestApply() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	p, err := project.NewRepository(test.DB).Create(ctx, "process-"+uuid.NewV4().String())
+	require.Nil(t, err)
+	repo := processconfig.NewRepository(test.DB)
+	category := "process-" + uuid.NewV4().String()
+	c := &processconfig.Config{
+		Categories: []processconfig.Category{{Name: category}},
+		LinkTypes: []processconfig.LinkType{{
+			Name: "blocks", Category: category, Topology: link.TopologyDependency,
+			SourceType: workitem.SystemBug, TargetType: workitem.SystemBug, ForwardName: "blocks", ReverseName: "blocked by",
+		}},
+		Workflows: []processconfig.Workflow{
+			{Type: workitem.SystemBug, To: "closed", Roles: []string{approval.RoleProjectAdmin}, Approvals: 1},
+			{To: "resolved", Roles: []string{approval.RoleAssignee}, Approvals: 1},
+		},
+		TypeHierarchy: []string{workitem.SystemFeature, workitem.SystemBug},
+	}
+
+	plan, err := repo.Plan(ctx, p.ID, c)
+	require.Nil(t, err)
+	assert.True(t, plan.Global())
+	assert.Equal(t, []string{
+		"create link category " + category,
+		"create link type " + category + "/blocks",
+		"create workflow system.bug: * -> closed",
+		"create workflow *: * -> resolved",
+		"create type hierarchy system.feature > system.bug",
+	}, changes(plan))
+	require.Nil(t, plan.Execute(ctx))
+
+	// applying again changes nothing
+	plan, err = repo.Plan(ctx, p.ID, c)
+	require.Nil(t, err)
+	assert.Empty(t, plan.Changes)
+	assert.False(t, plan.Global())
+
+	dump, err := repo.Dump(ctx, p.ID)
+	require.Nil(t, err)
+	assert.Contains(t, dump.Categories, c.Categories[0])
+	assert.Equal(t, c.Workflows, dump.Workflows)
+	assert.Equal(t, c.TypeHierarchy, dump.TypeHierarchy)
+
+	c.LinkTypes[0].ReverseName = "is blocked by"
+	c.Workflows = c.Workflows[1:]
+	c.TypeHierarchy = nil
+	plan, err = repo.Plan(ctx, p.ID, c)
+	require.Nil(t, err)
+	assert.Equal(t, []string{
+		"update link type " + category + "/blocks",
+		"update workflow *: * -> resolved",
+		"delete workflow *: * -> resolved",
+		"delete type hierarchy system.feature > system.bug",
+	}, changes(plan))
+	require.Nil(t, plan.Execute(ctx))
+	plan, err = repo.Plan(ctx, p.ID, c)
+	require.Nil(t, err)
+	assert.Empty(t, plan.Changes)
+
+	// the ends of existing link types can't change
+	c.LinkTypes[0].TargetType = workitem.SystemFeature
+	_, err = repo.Plan(ctx, p.ID, c)
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
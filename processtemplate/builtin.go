@@ -0,0 +1,115 @@
+package processtemplate
+
+// The names of the built-in templates
+const (
+	Scrum         = "scrum"
+	Kanban        = "kanban"
+	IssueTracking = "issue-tracking"
+)
+
+// builtins are the templates every deployment has, in the order they are
+// listed
+var builtins = []Template{
+	{
+		Name:        Scrum,
+		Description: "Epics broken down into stories and tasks, worked on in sprints. The project admins accept the stories.",
+		Config: `types:
+- name: scrum.epic
+  extends: system.planneritem
+- name: scrum.story
+  extends: system.planneritem
+  fields:
+    scrum.story-points:
+      kind: float
+- name: scrum.task
+  extends: system.planneritem
+  fields:
+    scrum.remaining-hours:
+      kind: float
+categories:
+- name: scrum
+  description: The link types of the Scrum template
+link-types:
+- name: breakdown
+  category: scrum
+  topology: tree
+  source-type: system.planneritem
+  target-type: system.planneritem
+  forward-name: breaks down into
+  reverse-name: is part of
+workflows:
+- type: scrum.story
+  to: closed
+  roles: [project-admin]
+  approvals: 1
+type-hierarchy: [scrum.epic, scrum.story, scrum.task]
+boards:
+- name: Sprint board
+  columns: [new, open, in progress, resolved, closed]
+`,
+	},
+	{
+		Name:        Kanban,
+		Description: "A continuous flow of work items across a board, blocked work items are linked to what blocks them.",
+		Config: `categories:
+- name: kanban
+  description: The link types of the Kanban template
+link-types:
+- name: blocks
+  category: kanban
+  topology: dependency
+  source-type: system.planneritem
+  target-type: system.planneritem
+  forward-name: blocks
+  reverse-name: is blocked by
+workflows: []
+type-hierarchy: []
+boards:
+- name: Kanban board
+  columns: [new, open, in progress, resolved, closed]
+`,
+	},
+	{
+		Name:        IssueTracking,
+		Description: "Bugs and incidents triaged on a board, their reporters confirm the fixes before they are closed.",
+		Config: `types:
+- name: issue.incident
+  extends: system.bug
+  fields:
+    issue.impact:
+      kind: enum
+      values: [low, medium, high]
+categories:
+- name: issue-tracking
+  description: The link types of the issue tracking template
+link-types:
+- name: duplicates
+  category: issue-tracking
+  topology: network
+  source-type: system.bug
+  target-type: system.bug
+  forward-name: duplicates
+  reverse-name: is duplicated by
+workflows:
+- type: system.bug
+  to: closed
+  roles: [creator]
+  approvals: 1
+type-hierarchy: []
+boards:
+- name: Triage
+  columns: [new, open, in progress, resolved]
+`,
+	},
+}
+
+// builtin returns the built-in template of the name, nil if there is none
+func builtin(name string) *Template {
+	for i := range builtins {
+		if builtins[i].Name == name {
+			t := builtins[i]
+			return &t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+// Package processtemplate holds the process templates projects start from:
+// the built-in Scrum, Kanban and issue tracking presets and the custom
+// templates registered by the operators. A template is a process config
+// bundling work item types, link types, workflows and boards, see package
+// processconfig; it is applied when a project is created or later.
+package processtemplate
+
+import (
+	"regexp"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/processconfig"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+)
+
+// namePattern matches the names of templates like scrum or issue-tracking
+var namePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Template is a named process config projects can be created with
+type Template struct {
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Name        string `gorm:"primary_key"`
+	Description string
+	// Config is the YAML process config of the template
+	Config string
+	// Custom is false for the built-in templates
+	Custom bool `sql:"-"`
+}
+
+// TableName implements gorm.tabler
+func (t Template) TableName() string {
+	return "process_templates"
+}
+
+// Parse returns the process config of the template
+// returns BadParameterError
+func (t Template) Parse() (*processconfig.Config, error) {
+	c, err := processconfig.Parse([]byte(t.Config))
+	if err != nil {
+		return nil, errors.NewBadParameterError("config", t.Name).Expected(err.Error())
+	}
+	return c, nil
+}
+
+// Validate checks the name and that the config parses, custom templates
+// can't take the name of a built-in one
+// returns BadParameterError
+func (t Template) Validate() error {
+	if !namePattern.MatchString(t.Name) {
+		return errors.NewBadParameterError("name", t.Name).Expected("lower case letters and digits separated by dashes")
+	}
+	if builtin(t.Name) != nil {
+		return errors.NewBadParameterError("name", t.Name).Expected("not the name of a built-in template")
+	}
+	_, err := t.Parse()
+	return err
+}
+
+// Repository encapsulates storage & retrieval of the process templates
+type Repository interface {
+	List(ctx context.Context) ([]*Template, error)
+	Load(ctx context.Context, name string) (*Template, error)
+	Save(ctx context.Context, t *Template) error
+	Delete(ctx context.Context, name string) error
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for process
+// templates.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// List returns the built-in templates followed by the custom ones ordered
+// by name
+// returns InternalError
+func (m *GormRepository) List(ctx context.Context) ([]*Template, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "processtemplate", "list"}, time.Now())
+
+	var objs []*Template
+	if err := m.db.Order("name").Find(&objs).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	res := make([]*Template, 0, len(builtins)+len(objs))
+	for _, t := range builtins {
+		t := t
+		res = append(res, &t)
+	}
+	for _, t := range objs {
+		t.Custom = true
+		res = append(res, t)
+	}
+	return res, nil
+}
+
+// Load returns the built-in or custom template of the name
+// returns NotFoundError or InternalError
+func (m *GormRepository) Load(ctx context.Context, name string) (*Template, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "processtemplate", "load"}, time.Now())
+
+	if t := builtin(name); t != nil {
+		return t, nil
+	}
+	var t Template
+	tx := m.db.Where("name = ?", name).First(&t)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("process template", name)
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	t.Custom = true
+	return &t, nil
+}
+
+// Save stores the custom template, replacing the one of the name
+// returns BadParameterError or InternalError
+func (m *GormRepository) Save(ctx context.Context, t *Template) error {
+	defer goa.MeasureSince([]string{"goa", "db", "processtemplate", "save"}, time.Now())
+
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	err := m.db.Exec(`INSERT INTO process_templates (name, description, config, created_at, updated_at)
+		VALUES (?, ?, ?, now(), now())
+		ON CONFLICT (name) DO UPDATE SET description = EXCLUDED.description, config = EXCLUDED.config, updated_at = now()`,
+		t.Name, t.Description, t.Config).Error
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	t.Custom = true
+	return nil
+}
+
+// Delete removes the custom template of the name, projects created with it
+// keep their process
+// returns BadParameterError, NotFoundError or InternalError
+func (m *GormRepository) Delete(ctx context.Context, name string) error {
+	defer goa.MeasureSince([]string{"goa", "db", "processtemplate", "delete"}, time.Now())
+
+	if builtin(name) != nil {
+		return errors.NewBadParameterError("name", name).Expected("the name of a custom template")
+	}
+	tx := m.db.Where("name = ?", name).Delete(Template{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("process template", name)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package processtemplate_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/dashboard"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/processconfig"
+	"github.com/almighty/almighty-core/processtemplate"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestValidate(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	valid := processtemplate.Template{Name: "my-process", Config: "boards:\n- name: Board\n  columns: [open]\n"}
+	assert.Nil(t, valid.Validate())
+	for _, invalid := range []processtemplate.Template{
+		{Name: "My Process", Config: valid.Config},
+		{Name: "my-process-", Config: valid.Config},
+		{Name: processtemplate.Scrum, Config: valid.Config},
+		{Name: "my-process", Config: "boards: {"},
+	} {
+		assert.IsType(t, errors.BadParameterError{}, invalid.Validate(), invalid.Name)
+	}
+}
+
+type TestProcessTemplateRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunProcessTemplateRepository(t *testing.T) {
+	suite.Run(t, &TestProcessTemplateRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestProcessTemplateRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestProcessTemplateRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestProcessTemplateRepository) TestCustom() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := processtemplate.NewRepository(test.DB)
+	name := "custom-" + uuid.NewV4().String()
+	custom := &processtemplate.Template{Name: name, Description: "first", Config: "type-hierarchy: []\n"}
+	require.Nil(t, repo.Save(ctx, custom))
+	custom.Description = "second"
+	require.Nil(t, repo.Save(ctx, custom))
+
+	loaded, err := repo.Load(ctx, name)
+	require.Nil(t, err)
+	assert.Equal(t, "second", loaded.Description)
+	assert.True(t, loaded.Custom)
+
+	templates, err := repo.List(ctx)
+	require.Nil(t, err)
+	require.True(t, len(templates) > 3)
+	assert.Equal(t, processtemplate.Scrum, templates[0].Name)
+	assert.False(t, templates[0].Custom)
+	var listed bool
+	for _, tmpl := range templates[3:] {
+		listed = listed || tmpl.Name == name
+	}
+	assert.True(t, listed)
+
+	require.Nil(t, repo.Delete(ctx, name))
+	_, err = repo.Load(ctx, name)
+	assert.IsType(t, errors.NotFoundError{}, err)
+	assert.IsType(t, errors.NotFoundError{}, repo.Delete(ctx, name))
+	assert.IsType(t, errors.BadParameterError{}, repo.Delete(ctx, processtemplate.Kanban))
+}
+
+func (test *TestProcessTemplateRepository) TestApplyBuiltins() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := processtemplate.NewRepository(test.DB)
+	configs := processconfig.NewRepository(test.DB)
+	for _, name := range []string{processtemplate.Scrum, processtemplate.Kanban, processtemplate.IssueTracking} {
+		p, err := project.NewRepository(test.DB).Create(ctx, name+"-"+uuid.NewV4().String())
+		require.Nil(t, err)
+		tmpl, err := repo.Load(ctx, name)
+		require.Nil(t, err)
+		c, err := tmpl.Parse()
+		require.Nil(t, err)
+
+		plan, err := configs.Plan(ctx, p.ID, c)
+		require.Nil(t, err, name)
+		require.Nil(t, plan.Execute(ctx), name)
+		plan, err = configs.Plan(ctx, p.ID, c)
+		require.Nil(t, err, name)
+		assert.Empty(t, plan.Changes, name)
+
+		boards, err := dashboard.NewDashboardRepository(test.DB).ListProject(ctx, p.ID)
+		require.Nil(t, err)
+		require.Len(t, boards, 1, name)
+		assert.Equal(t, c.Boards[0].Name, boards[0].Name)
+		assert.Len(t, boards[0].Widgets, len(c.Boards[0].Columns))
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ProjectApprovalRulesController implements the project-approval-rules resource.
+type ProjectApprovalRulesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectApprovalRulesController creates a project-approval-rules controller.
+func NewProjectApprovalRulesController(service *goa.Service, db application.DB) *ProjectApprovalRulesController {
+	return &ProjectApprovalRulesController{Controller: service.NewController("ProjectApprovalRulesController"), db: db}
+}
+
+// List runs the list action.
+func (c *ProjectApprovalRulesController) List(ctx *app.ListProjectApprovalRulesContext) error {
+	return administrateProject(ctx, c.db, ctx.ID, "manage the approval rules of the project", func(appl application.Application, projectID uuid.UUID) error {
+		rules, err := appl.Approvals().ListRules(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		data := make([]*app.ApprovalRule, 0, len(rules))
+		for _, r := range rules {
+			data = append(data, ConvertApprovalRule(ctx.RequestData, r))
+		}
+		return ctx.OK(&app.ApprovalRuleList{
+			Data: data,
+			Meta: &app.WorkItemListResponseMeta{TotalCount: len(data)},
+		})
+	})
+}
+
+// Create runs the create action.
+func (c *ProjectApprovalRulesController) Create(ctx *app.CreateProjectApprovalRulesContext) error {
+	r, err := approvalRuleFromPayload(ctx.Payload.Data)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return administrateProject(ctx, c.db, ctx.ID, "manage the approval rules of the project", func(appl application.Application, projectID uuid.UUID) error {
+		r.ProjectID = projectID
+		if err := appl.Approvals().CreateRule(ctx, r); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.ApprovalRuleSingle{Data: ConvertApprovalRule(ctx.RequestData, r)}
+		ctx.ResponseData.Header().Set("Location", AbsoluteURL(ctx.RequestData, app.ApprovalRuleHref(r.ID)))
+		return ctx.Created(res)
+	})
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ProjectAssignmentRulesController implements the project-assignment-rules resource.
+type ProjectAssignmentRulesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectAssignmentRulesController creates a project-assignment-rules controller.
+func NewProjectAssignmentRulesController(service *goa.Service, db application.DB) *ProjectAssignmentRulesController {
+	return &ProjectAssignmentRulesController{Controller: service.NewController("ProjectAssignmentRulesController"), db: db}
+}
+
+// List runs the list action.
+func (c *ProjectAssignmentRulesController) List(ctx *app.ListProjectAssignmentRulesContext) error {
+	return administrateProject(ctx, c.db, ctx.ID, "manage the assignment rules of the project", func(appl application.Application, projectID uuid.UUID) error {
+		rules, err := appl.AssignmentRules().List(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		data := make([]*app.AssignmentRule, 0, len(rules))
+		for _, r := range rules {
+			data = append(data, ConvertAssignmentRule(ctx.RequestData, r))
+		}
+		return ctx.OK(&app.AssignmentRuleList{
+			Data: data,
+			Meta: &app.WorkItemListResponseMeta{TotalCount: len(data)},
+		})
+	})
+}
+
+// Create runs the create action.
+func (c *ProjectAssignmentRulesController) Create(ctx *app.CreateProjectAssignmentRulesContext) error {
+	r, err := assignmentRuleFromPayload(ctx.Payload.Data)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return administrateProject(ctx, c.db, ctx.ID, "manage the assignment rules of the project", func(appl application.Application, projectID uuid.UUID) error {
+		r.ProjectID = projectID
+		if err := validateAssignees(ctx, appl, r.Assignees); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if err := appl.AssignmentRules().Create(ctx, r); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.AssignmentRuleSingle{Data: ConvertAssignmentRule(ctx.RequestData, r)}
+		ctx.ResponseData.Header().Set("Location", AbsoluteURL(ctx.RequestData, app.AssignmentRuleHref(r.ID)))
+		return ctx.Created(res)
+	})
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"log"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/attachment"
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/scan"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ProjectAttachmentsController implements the project-attachments resource.
+type ProjectAttachmentsController struct {
+	*goa.Controller
+	db      application.DB
+	scanner scan.Scanner
+}
+
+// NewProjectAttachmentsController creates a project-attachments controller.
+// Uploads are checked for malware with the given scanner.
+func NewProjectAttachmentsController(service *goa.Service, db application.DB, scanner scan.Scanner) *ProjectAttachmentsController {
+	return &ProjectAttachmentsController{Controller: service.NewController("ProjectAttachmentsController"), db: db, scanner: scanner}
+}
+
+// Paste runs the paste action.
+func (c *ProjectAttachmentsController) Paste(ctx *app.PasteProjectAttachmentsContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	identityID, err := uuid.FromString(currentUser)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	projectID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	content, contentType, err := attachment.ReadImage(ctx.Request.Header.Get("Content-Type"), ctx.Request.Body, configuration.GetAttachmentMaxSize())
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	result, err := c.scanner.Scan(ctx, bytes.NewReader(content))
+	if err != nil {
+		goa.LogError(ctx, "error scanning attachment", "error", err.Error())
+		return jsonapi.JSONErrorResponse(ctx, errors.NewServiceUnavailableError("the image can't be checked for malware, try again later"))
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err = appl.Projects().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+		}
+
+		a := attachment.Attachment{
+			ProjectID:   projectID,
+			ContentType: contentType,
+			Content:     content,
+			CreatedBy:   identityID,
+		}
+		// infected images are kept in quarantine for the admins
+		if result.Infected {
+			a.Malware = &result.Signature
+		}
+		created, err := appl.Attachments().Create(ctx, &a)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if a.IsQuarantined() {
+			log.Printf("Quarantined attachment %s: %s\n", a.ID, *a.Malware)
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("image", "malware").Expected("no malware, the image was quarantined"))
+		}
+
+		res := &app.AttachmentSingle{
+			Data: ConvertAttachment(ctx.RequestData, &a),
+		}
+		if !created {
+			return ctx.OK(res)
+		}
+		ctx.ResponseData.Header().Set("Location", AbsoluteURL(ctx.RequestData, app.AttachmentHref(a.ID)))
+		return ctx.Created(res)
+	})
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ProjectAutomationRulesController implements the project-automation-rules resource.
+type ProjectAutomationRulesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectAutomationRulesController creates a project-automation-rules controller.
+func NewProjectAutomationRulesController(service *goa.Service, db application.DB) *ProjectAutomationRulesController {
+	return &ProjectAutomationRulesController{Controller: service.NewController("ProjectAutomationRulesController"), db: db}
+}
+
+// List runs the list action.
+func (c *ProjectAutomationRulesController) List(ctx *app.ListProjectAutomationRulesContext) error {
+	return administrateProject(ctx, c.db, ctx.ID, "manage the automation rules of the project", func(appl application.Application, projectID uuid.UUID) error {
+		rules, err := appl.AutomationRules().List(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		data := make([]*app.AutomationRule, 0, len(rules))
+		for _, r := range rules {
+			data = append(data, ConvertAutomationRule(ctx.RequestData, r))
+		}
+		return ctx.OK(&app.AutomationRuleList{
+			Data: data,
+			Meta: &app.WorkItemListResponseMeta{TotalCount: len(data)},
+		})
+	})
+}
+
+// Create runs the create action.
+func (c *ProjectAutomationRulesController) Create(ctx *app.CreateProjectAutomationRulesContext) error {
+	r, err := automationRuleFromPayload(ctx.Payload.Data)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return administrateProject(ctx, c.db, ctx.ID, "manage the automation rules of the project", func(appl application.Application, projectID uuid.UUID) error {
+		r.ProjectID = projectID
+		r.CreatedBy = *currentIdentityID(ctx)
+		if err := appl.AutomationRules().Create(ctx, r); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.AutomationRuleSingle{Data: ConvertAutomationRule(ctx.RequestData, r)}
+		ctx.ResponseData.Header().Set("Location", AbsoluteURL(ctx.RequestData, app.AutomationRuleHref(r.ID)))
+		return ctx.Created(res)
+	})
+}
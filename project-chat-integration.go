@@ -0,0 +1,459 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"strconv"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/chat"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/job"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/moderation"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ProjectChatIntegrationController implements the project-chat-integration resource.
+type ProjectChatIntegrationController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectChatIntegrationController creates a project-chat-integration controller.
+func NewProjectChatIntegrationController(service *goa.Service, db application.DB) *ProjectChatIntegrationController {
+	return &ProjectChatIntegrationController{Controller: service.NewController("ProjectChatIntegrationController"), db: db}
+}
+
+// Show runs the show action.
+func (c *ProjectChatIntegrationController) Show(ctx *app.ShowProjectChatIntegrationContext) error {
+	// the webhook URL is a secret of the admins
+	return administrateProject(ctx, c.db, ctx.ID, "configure the chat integration", func(appl application.Application, projectID uuid.UUID) error {
+		i, err := appl.ChatIntegrations().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.ChatIntegrationSingle{Data: ConvertChatIntegration(i)})
+	})
+}
+
+// Update runs the update action.
+func (c *ProjectChatIntegrationController) Update(ctx *app.UpdateProjectChatIntegrationContext) error {
+	if ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	return administrateProject(ctx, c.db, ctx.ID, "configure the chat integration", func(appl application.Application, projectID uuid.UUID) error {
+		i, err := appl.ChatIntegrations().Save(ctx, convertChatIntegrationAttributes(projectID, ctx.Payload.Data.Attributes))
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.ChatIntegrationSingle{Data: ConvertChatIntegration(i)})
+	})
+}
+
+// Delete runs the delete action.
+func (c *ProjectChatIntegrationController) Delete(ctx *app.DeleteProjectChatIntegrationContext) error {
+	return administrateProject(ctx, c.db, ctx.ID, "configure the chat integration", func(appl application.Application, projectID uuid.UUID) error {
+		if err := appl.ChatIntegrations().Delete(ctx, projectID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// Command runs the command action.
+func (c *ProjectChatIntegrationController) Command(ctx *app.CommandProjectChatIntegrationContext) error {
+	projectID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(ctx.Request.Body, maxChatCommandBytes))
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("payload", err.Error()))
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("payload", err.Error()))
+	}
+	var i *chat.Integration
+	err = application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		var err error
+		i, err = appl.ChatIntegrations().Load(ctx, projectID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	// the token authenticates the workspace of the chat
+	if i.CommandToken == "" || subtle.ConstantTimeCompare([]byte(form.Get("token")), []byte(i.CommandToken)) != 1 {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("invalid command token"))
+	}
+
+	var text string
+	err = application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		var err error
+		text, err = runChatCommand(ctx, appl, ctx.RequestData, *i, form.Get("user_id"), form.Get("text"))
+		return err
+	})
+	if err != nil {
+		// mistakes of the user are replied to, the changes are rolled back
+		switch err.(type) {
+		case errors.BadParameterError, errors.NotFoundError, errors.VersionConflictError:
+			text = err.Error()
+		default:
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+	}
+	reply, err := json.Marshal(chat.Reply{ResponseType: chat.ResponseEphemeral, Text: text})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewInternalError(err.Error()))
+	}
+	ctx.ResponseData.Header().Set("Content-Type", "application/json")
+	return ctx.OK(reply)
+}
+
+// Link runs the link action.
+func (c *ProjectChatIntegrationController) Link(ctx *app.LinkProjectChatIntegrationContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing identity"))
+	}
+	projectID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if _, err := appl.ChatIntegrations().Load(ctx, projectID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		code, err := appl.ChatIntegrations().CreateLinkCode(ctx, projectID, *identityID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.ChatLinkCode{Code: code.Code, ExpiresAt: code.ExpiresAt})
+	})
+}
+
+// maxChatCommandBytes limits the size of the slash command requests
+const maxChatCommandBytes = 64 * 1024
+
+// defaultChatCommandType is the type of the work items created by slash
+// commands without type option
+const defaultChatCommandType = workitem.SystemUserStory
+
+// runChatCommand runs the slash command typed in the chat of the
+// integration by the given chat user and returns the reply
+func runChatCommand(ctx context.Context, appl application.Application, request *goa.RequestData, i chat.Integration, chatUserID string, text string) (string, error) {
+	cmd, err := chat.ParseCommand(text)
+	if err != nil {
+		return err.Error() + "\n" + chat.Usage, nil
+	}
+	switch cmd.Verb {
+	case chat.VerbHelp:
+		return chat.Usage, nil
+	case chat.VerbLink:
+		identityID, err := appl.ChatIntegrations().LinkAccount(ctx, i.ProjectID, cmd.Code, chatUserID)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Your chat account is linked to %s", identityName(ctx, appl, identityID.String())), nil
+	}
+
+	identityID, err := appl.ChatIntegrations().Account(ctx, i.ProjectID, chatUserID)
+	if _, ok := err.(errors.NotFoundError); ok {
+		return "Your chat account isn't linked yet, request a link code from your profile and type `link CODE`", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	// the command acts on behalf of the linked identity
+	viewer, err := loadViewer(ctx, appl, &identityID)
+	if err != nil {
+		return "", err
+	}
+	ctx = workitem.WithViewer(ctx, viewer)
+	if _, err := appl.Projects().Load(ctx, i.ProjectID); err != nil {
+		return "", err
+	}
+
+	if cmd.Verb == chat.VerbCreate {
+		typeName := defaultChatCommandType
+		if t, ok := cmd.Options[chat.OptionType]; ok {
+			typeName, err = chatCommandType(ctx, appl, t)
+			if err != nil {
+				return "", err
+			}
+		}
+		fields := map[string]interface{}{
+			workitem.SystemTitle:   cmd.Title,
+			workitem.SystemProject: i.ProjectID.String(),
+			workitem.SystemState:   workitem.SystemStateNew,
+		}
+		applyChatCommandOptions(cmd, identityID, fields)
+		pending, err := checkContribution(ctx, appl, moderation.KindWorkItem, i.ProjectID.String(), identityID.String(), cmd.Title)
+		if err != nil {
+			return "", err
+		}
+		if pending {
+			fields[workitem.SystemPendingReview] = true
+		}
+		if err := assignNewWorkItem(ctx, appl, typeName, fields); err != nil {
+			return "", err
+		}
+		wi, err := appl.WorkItems().Create(ctx, typeName, fields, identityID.String())
+		if err != nil {
+			return "", err
+		}
+		wi, err = automate(ctx, appl, request, nil, wi, "")
+		if err != nil {
+			return "", err
+		}
+		if err := notifyChat(ctx, appl, request, nil, wi); err != nil {
+			return "", err
+		}
+		reply := "Created " + chat.Link(i.Kind, AbsoluteURL(request, app.WorkitemHref(wi.ID)), cmd.Title)
+		if pending {
+			reply += ", it is held for review by the project admins"
+		}
+		return reply, nil
+	}
+
+	wi, err := appl.WorkItems().Load(ctx, cmd.WorkItemID)
+	if err != nil {
+		return "", err
+	}
+	before := app.WorkItem{ID: wi.ID, Type: wi.Type, Fields: make(map[string]interface{}, len(wi.Fields))}
+	for k, v := range wi.Fields {
+		before.Fields[k] = v
+	}
+	applyChatCommandOptions(cmd, identityID, wi.Fields)
+	if err := checkTransition(ctx, appl, request, &before, wi); err != nil {
+		return "", err
+	}
+	wi, err = appl.WorkItems().Save(ctx, *wi)
+	if err != nil {
+		return "", err
+	}
+	wi, err = automate(ctx, appl, request, &before, wi, "")
+	if err != nil {
+		return "", err
+	}
+	if err := notifyChat(ctx, appl, request, &before, wi); err != nil {
+		return "", err
+	}
+	return "Updated " + chat.Link(i.Kind, AbsoluteURL(request, app.WorkitemHref(wi.ID)), contributionText(wi.Fields[workitem.SystemTitle])), nil
+}
+
+// applyChatCommandOptions sets the fields given as options of the command,
+// the only assignee that can be given is the identity typing the command
+func applyChatCommandOptions(cmd *chat.Command, identityID uuid.UUID, fields map[string]interface{}) {
+	if title, ok := cmd.Options[chat.OptionTitle]; ok {
+		fields[workitem.SystemTitle] = title
+	}
+	if state, ok := cmd.Options[chat.OptionState]; ok {
+		fields[workitem.SystemState] = state
+	}
+	switch cmd.Options[chat.OptionAssignee] {
+	case "me":
+		fields[workitem.SystemAssignees] = []string{identityID.String()}
+	case "none":
+		delete(fields, workitem.SystemAssignees)
+	}
+}
+
+// chatCommandType returns the name of the work item type given in a slash
+// command, the "system." prefix of the system types can be left out
+// returns BadParameterError or InternalError
+func chatCommandType(ctx context.Context, appl application.Application, name string) (string, error) {
+	for _, candidate := range []string{"system." + name, name} {
+		_, err := appl.WorkItemTypes().Load(ctx, candidate)
+		if err == nil {
+			return candidate, nil
+		}
+		if _, ok := err.(errors.NotFoundError); !ok {
+			return "", err
+		}
+	}
+	return "", errors.NewBadParameterError(chat.OptionType, name).Expected("the name of a work item type")
+}
+
+func convertChatIntegrationAttributes(projectID uuid.UUID, attrs *app.ChatIntegrationAttributes) chat.Integration {
+	i := chat.Integration{
+		ProjectID:  projectID,
+		Kind:       attrs.Kind,
+		WebhookURL: attrs.WebhookURL,
+		Threads:    attrs.Threads == nil || *attrs.Threads,
+	}
+	if attrs.Channel != nil {
+		i.Channel = *attrs.Channel
+	}
+	if attrs.CreatedTemplate != nil {
+		i.CreatedTemplate = *attrs.CreatedTemplate
+	}
+	if attrs.AssignedTemplate != nil {
+		i.AssignedTemplate = *attrs.AssignedTemplate
+	}
+	if attrs.StateChangedTemplate != nil {
+		i.StateChangedTemplate = *attrs.StateChangedTemplate
+	}
+	if attrs.CommandToken != nil {
+		i.CommandToken = *attrs.CommandToken
+	}
+	return i
+}
+
+// ConvertChatIntegration converts between internal and external REST representation
+func ConvertChatIntegration(i *chat.Integration) *app.ChatIntegration {
+	return &app.ChatIntegration{
+		Type: "chatintegrations",
+		ID:   &i.ProjectID,
+		Attributes: &app.ChatIntegrationAttributes{
+			Kind:                 i.Kind,
+			WebhookURL:           i.WebhookURL,
+			Channel:              &i.Channel,
+			CreatedTemplate:      &i.CreatedTemplate,
+			AssignedTemplate:     &i.AssignedTemplate,
+			StateChangedTemplate: &i.StateChangedTemplate,
+			Threads:              &i.Threads,
+			CommandToken:         &i.CommandToken,
+		},
+	}
+}
+
+// notifyChat enqueues the messages about the change of the work item from
+// before to after for the chat integration of its project, before is nil
+// for new work items. Confidential work items, drafts and the ones held for
+// review aren't posted.
+func notifyChat(ctx context.Context, appl application.Application, request *goa.RequestData, before *app.WorkItem, after *app.WorkItem) error {
+	p, _ := after.Fields[workitem.SystemProject].(string)
+	projectID, err := uuid.FromString(p)
+	if err != nil {
+		return nil
+	}
+	if after.Fields[workitem.SystemConfidential] == true || after.Fields[workitem.SystemPendingReview] == true || after.Fields[workitem.SystemDraft] == true {
+		return nil
+	}
+	if _, err := appl.ChatIntegrations().Load(ctx, projectID); err != nil {
+		if _, ok := err.(errors.NotFoundError); ok {
+			return nil
+		}
+		return err
+	}
+	id, err := strconv.ParseUint(after.ID, 10, 64)
+	if err != nil {
+		return errors.NewConversionError(err.Error())
+	}
+	m := chat.Message{
+		ProjectID:  projectID,
+		WorkItemID: id,
+		Type:       after.Type,
+		Title:      contributionText(after.Fields[workitem.SystemTitle]),
+		URL:        AbsoluteURL(request, app.WorkitemHref(after.ID)),
+		Actor:      "Someone",
+		State:      contributionText(after.Fields[workitem.SystemState]),
+	}
+	if viewer := workitem.ContextViewer(ctx); viewer != nil && viewer.IdentityID != nil {
+		m.Actor = identityName(ctx, appl, viewer.IdentityID.String())
+	}
+
+	var events []string
+	if before == nil {
+		events = append(events, chat.EventCreated)
+	} else if previous := contributionText(before.Fields[workitem.SystemState]); previous != m.State {
+		m.PreviousState = previous
+		events = append(events, chat.EventStateChanged)
+	}
+	assignees := stringList(after.Fields[workitem.SystemAssignees])
+	if len(assignees) > 0 && (before == nil || fmt.Sprint(assignees) != fmt.Sprint(stringList(before.Fields[workitem.SystemAssignees]))) {
+		events = append(events, chat.EventAssigned)
+		for _, a := range assignees {
+			m.Assignees = append(m.Assignees, identityName(ctx, appl, a))
+		}
+	}
+	for _, event := range events {
+		m.Event = event
+		if err := chat.Notify(ctx, appl.Jobs(), m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// identityName returns the full name of the identity with the given ID, or
+// the ID if it can't be loaded
+func identityName(ctx context.Context, appl application.Application, id string) string {
+	identityID, err := uuid.FromString(id)
+	if err != nil {
+		return id
+	}
+	identity, err := appl.Identities().Load(ctx, identityID)
+	if err != nil || identity.FullName == "" {
+		return id
+	}
+	return identity.FullName
+}
+
+// stringList returns the strings of a list field value
+func stringList(v interface{}) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		res := make([]string, 0, len(list))
+		for _, s := range list {
+			res = append(res, contributionText(s))
+		}
+		return res
+	}
+	return nil
+}
+
+// postChatMessageJob returns the handler of the jobs posting messages to the
+// chat integrations of the projects
+func postChatMessageJob(db application.DB, poster *chat.Poster) job.Handler {
+	return func(ctx context.Context, payload []byte) error {
+		var m chat.Message
+		if err := json.Unmarshal(payload, &m); err != nil {
+			return errors.NewConversionError(err.Error())
+		}
+		return application.Transactional(db, func(appl application.Application) error {
+			i, err := appl.ChatIntegrations().Load(ctx, m.ProjectID)
+			if _, ok := err.(errors.NotFoundError); ok {
+				log.Printf("Dropped the %s message about work item %d, project %s has no chat integration\n", m.Event, m.WorkItemID, m.ProjectID)
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			text, err := chat.Render(*i, m)
+			if err != nil {
+				return err
+			}
+			var thread string
+			if i.Threads {
+				thread, err = appl.ChatIntegrations().Thread(ctx, m.ProjectID, m.WorkItemID)
+				if err != nil {
+					return err
+				}
+			}
+			posted, err := poster.Post(*i, text, thread)
+			if err != nil {
+				return errors.NewInternalError(err.Error())
+			}
+			if i.Threads && thread == "" && posted != "" {
+				return appl.ChatIntegrations().SaveThread(ctx, m.ProjectID, m.WorkItemID, posted)
+			}
+			return nil
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/codebase"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ProjectCodebasesController implements the project-codebases resource.
+type ProjectCodebasesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectCodebasesController creates a project-codebases controller.
+func NewProjectCodebasesController(service *goa.Service, db application.DB) *ProjectCodebasesController {
+	return &ProjectCodebasesController{Controller: service.NewController("ProjectCodebasesController"), db: db}
+}
+
+// Create runs the create action.
+func (c *ProjectCodebasesController) Create(ctx *app.CreateProjectCodebasesContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	projectID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	if ctx.Payload.Data == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data", nil).Expected("not nil"))
+	}
+	attrs := ctx.Payload.Data.Attributes
+	if attrs.URL == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.url", nil).Expected("not nil"))
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err = appl.Projects().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+		}
+
+		cb := codebase.Codebase{
+			ProjectID: projectID,
+			URL:       *attrs.URL,
+		}
+		if attrs.Branch != nil {
+			cb.Branch = *attrs.Branch
+		}
+		err = appl.Codebases().Create(ctx, &cb)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		res := &app.CodebaseSingle{
+			Data: ConvertCodebase(ctx.RequestData, &cb, nil),
+		}
+		ctx.ResponseData.Header().Set("Location", AbsoluteURL(ctx.RequestData, app.CodebaseHref(res.Data.ID)))
+		return ctx.Created(res)
+	})
+}
+
+// List runs the list action.
+func (c *ProjectCodebasesController) List(ctx *app.ListProjectCodebasesContext) error {
+	projectID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err = appl.Projects().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+		}
+
+		codebases, err := appl.Codebases().List(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		data, err := ConvertCodebases(ctx.RequestData, codebases, func(id uuid.UUID) (*codebase.Build, error) {
+			return appl.Codebases().LatestBuild(ctx, id)
+		})
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		res := &app.CodebaseList{
+			Data: data,
+			Meta: &app.WorkItemListResponseMeta{TotalCount: len(data)},
+		}
+		return ctx.OK(res)
+	})
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ProjectDashboardsController implements the project-dashboards resource.
+type ProjectDashboardsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectDashboardsController creates a project-dashboards controller.
+func NewProjectDashboardsController(service *goa.Service, db application.DB) *ProjectDashboardsController {
+	return &ProjectDashboardsController{Controller: service.NewController("ProjectDashboardsController"), db: db}
+}
+
+// Create runs the create action.
+func (c *ProjectDashboardsController) Create(ctx *app.CreateProjectDashboardsContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	projectID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	d, err := dashboardFromPayload(ctx.Payload.Data)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	d.ProjectID = &projectID
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err = appl.Projects().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+		}
+		if !isInstanceAdmin(ctx) {
+			admin, err := isProjectAdmin(ctx, appl, projectID, *identityID)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			if !admin {
+				return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only project admins can share dashboards in the project"))
+			}
+		}
+		if err := appl.Dashboards().Create(ctx, d); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.DashboardSingle{Data: ConvertDashboard(ctx.RequestData, d)}
+		ctx.ResponseData.Header().Set("Location", AbsoluteURL(ctx.RequestData, app.DashboardHref(d.ID)))
+		return ctx.Created(res)
+	})
+}
+
+// List runs the list action.
+func (c *ProjectDashboardsController) List(ctx *app.ListProjectDashboardsContext) error {
+	projectID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err = appl.Projects().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+		}
+		dashboards, err := appl.Dashboards().ListProject(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(convertDashboardList(ctx.RequestData, dashboards))
+	})
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/escalation"
+	"github.com/almighty/almighty-core/job"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/notification"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// escalationJobKind is the kind of the jobs applying the escalation policies
+const escalationJobKind = "workitem.escalate"
+
+// ProjectEscalationPolicyController implements the project-escalation-policy resource.
+type ProjectEscalationPolicyController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectEscalationPolicyController creates a project-escalation-policy controller.
+func NewProjectEscalationPolicyController(service *goa.Service, db application.DB) *ProjectEscalationPolicyController {
+	return &ProjectEscalationPolicyController{Controller: service.NewController("ProjectEscalationPolicyController"), db: db}
+}
+
+// Show runs the show action.
+func (c *ProjectEscalationPolicyController) Show(ctx *app.ShowProjectEscalationPolicyContext) error {
+	projectID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err := appl.Projects().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		p, err := appl.EscalationPolicies().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.EscalationPolicySingle{Data: ConvertEscalationPolicy(p)})
+	})
+}
+
+// Update runs the update action.
+func (c *ProjectEscalationPolicyController) Update(ctx *app.UpdateProjectEscalationPolicyContext) error {
+	if ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	attrs := ctx.Payload.Data.Attributes
+	policy := escalation.Policy{
+		Severity:        attrs.Severity,
+		UnassignedHours: attrs.UnassignedHours,
+		UntouchedHours:  attrs.UntouchedHours,
+	}
+	if attrs.RaisePriority != nil {
+		policy.RaisePriority = *attrs.RaisePriority
+	}
+	rel := ctx.Payload.Data.Relationships
+	if rel != nil && rel.Contact != nil && rel.Contact.Data != nil {
+		if rel.Contact.Data.ID == nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.relationships.contact.data.id", nil).Expected("not nil"))
+		}
+		contactID, err := uuid.FromString(*rel.Contact.Data.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.relationships.contact.data.id", *rel.Contact.Data.ID).Expected("the ID of an identity"))
+		}
+		policy.ContactID = &contactID
+	}
+	return administrateProject(ctx, c.db, ctx.ID, "change the escalation policy", func(appl application.Application, projectID uuid.UUID) error {
+		if policy.ContactID != nil {
+			// make sure the contact exists
+			if _, err := appl.Identities().Load(ctx, *policy.ContactID); err != nil {
+				return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.relationships.contact.data.id", policy.ContactID.String()).Expected("the ID of an identity"))
+			}
+		}
+		policy.ProjectID = projectID
+		p, err := appl.EscalationPolicies().Save(ctx, policy)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.EscalationPolicySingle{Data: ConvertEscalationPolicy(p)})
+	})
+}
+
+// Delete runs the delete action.
+func (c *ProjectEscalationPolicyController) Delete(ctx *app.DeleteProjectEscalationPolicyContext) error {
+	return administrateProject(ctx, c.db, ctx.ID, "change the escalation policy", func(appl application.Application, projectID uuid.UUID) error {
+		if err := appl.EscalationPolicies().Delete(ctx, projectID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// ConvertEscalationPolicy converts between internal and external REST representation
+func ConvertEscalationPolicy(p *escalation.Policy) *app.EscalationPolicy {
+	res := &app.EscalationPolicy{
+		Type: "escalationpolicies",
+		ID:   &p.ProjectID,
+		Attributes: &app.EscalationPolicyAttributes{
+			Severity:        p.Severity,
+			UnassignedHours: p.UnassignedHours,
+			UntouchedHours:  p.UntouchedHours,
+		},
+		Relationships: &app.EscalationPolicyRelations{},
+	}
+	if p.RaisePriority != "" {
+		res.Attributes.RaisePriority = &p.RaisePriority
+	}
+	if p.ContactID != nil {
+		identityType := "identities"
+		contactID := p.ContactID.String()
+		res.Relationships.Contact = &app.RelationGeneric{
+			Data: &app.GenericData{Type: &identityType, ID: &contactID},
+		}
+	}
+	return res
+}
+
+// sweepEscalationsJob returns the handler of the scheduled jobs applying the
+// escalation policies, the contacts and the assignees of the escalated work
+// items are notified once the escalations are committed
+func sweepEscalationsJob(db application.DB) job.Handler {
+	return func(ctx context.Context, payload []byte) error {
+		return application.Transactional(db, func(appl application.Application) error {
+			now := time.Now()
+			escalations, err := appl.EscalationPolicies().Sweep(ctx, now)
+			if err != nil {
+				return err
+			}
+			for _, e := range escalations {
+				n := notification.Notification{
+					Event:      notification.EventEscalated,
+					WorkItemID: e.WorkItemID,
+					ProjectID:  e.ProjectID,
+					Subject:    "Escalated " + e.Title + ", it is still " + e.Reason,
+					At:         now,
+				}
+				recipients := e.Assignees
+				if e.ContactID != nil {
+					recipients = append([]string{e.ContactID.String()}, recipients...)
+				}
+				seen := map[uuid.UUID]bool{}
+				for _, recipient := range recipients {
+					n.RecipientID, err = uuid.FromString(recipient)
+					if err != nil || seen[n.RecipientID] {
+						continue
+					}
+					seen[n.RecipientID] = true
+					n.Timezone = ""
+					if identity, err := appl.Identities().Load(ctx, n.RecipientID); err == nil {
+						n.Timezone = identity.Timezone
+					}
+					if err := notification.Notify(ctx, appl.Jobs(), n); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"net"
+	"net/http"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/challenge"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/moderation"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ProjectFeedbackController implements the project-feedback resource.
+type ProjectFeedbackController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectFeedbackController creates a project-feedback controller.
+func NewProjectFeedbackController(service *goa.Service, db application.DB) *ProjectFeedbackController {
+	return &ProjectFeedbackController{Controller: service.NewController("ProjectFeedbackController"), db: db}
+}
+
+// Create runs the create action.
+func (c *ProjectFeedbackController) Create(ctx *app.CreateProjectFeedbackContext) error {
+	projectID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	if ctx.Payload.Data == nil || ctx.Payload.Data.Relationships == nil || ctx.Payload.Data.Relationships.BaseType == nil || ctx.Payload.Data.Relationships.BaseType.Data == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.relationships.basetype.data.id", nil).Expected("not nil"))
+	}
+	wit := ctx.Payload.Data.Relationships.BaseType.Data.ID
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		p, err := appl.Projects().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if !p.Public {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("project does not accept anonymous contributions"))
+		}
+		if err := verifyChallenge(ctx, p, ctx.XChallengeToken, ctx.Request); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		wi := app.WorkItem{
+			Fields: make(map[string]interface{}),
+		}
+		err = ConvertJSONAPIToWorkItem(appl, *ctx.Payload.Data, &wi)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		// anonymous contributors can't plan the work item
+		for _, field := range []string{workitem.SystemAssignees, workitem.SystemIteration, workitem.SystemRelease} {
+			delete(wi.Fields, field)
+		}
+		wi.Fields[workitem.SystemProject] = projectID.String()
+
+		anonymous := account.AnonymousIdentityID.String()
+		pending, err := checkContribution(ctx, appl, moderation.KindWorkItem, wi.Fields[workitem.SystemProject], anonymous,
+			contributionText(wi.Fields[workitem.SystemTitle]), contributionText(wi.Fields[workitem.SystemDescription]))
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		wi.Fields[workitem.SystemPendingReview] = pending
+
+		created, err := appl.WorkItems().Create(ctx, wit, wi.Fields, anonymous)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		wi2 := ConvertWorkItem(ctx.RequestData, created)
+		ctx.ResponseData.Header().Set("Location", app.WorkitemHref(wi2.ID))
+		return ctx.Created(&app.WorkItem2Single{
+			Data: wi2,
+			Links: &app.WorkItemLinks{
+				Self: buildAbsoluteURL(ctx.RequestData),
+			},
+		})
+	})
+}
+
+// verifyChallenge checks the response token of the challenge the project
+// requires from anonymous contributors, if any
+// returns BadParameterError or InternalError
+func verifyChallenge(ctx context.Context, p *project.Project, token *string, req *http.Request) error {
+	if p.Challenge == "" {
+		return nil
+	}
+	verifier, err := challenge.NewVerifier(p.Challenge)
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	t := ""
+	if token != nil {
+		t = *token
+	}
+	return verifier.Verify(ctx, t, remoteIP(req))
+}
+
+// remoteIP returns the IP address of the client of the request
+func remoteIP(req *http.Request) string {
+	ip, _, _ := net.SplitHostPort(req.RemoteAddr)
+	return ip
+}
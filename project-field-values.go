@@ -0,0 +1,124 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/fieldvalues"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ProjectFieldValuesController implements the project-field-values resource.
+type ProjectFieldValuesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectFieldValuesController creates a project-field-values controller.
+func NewProjectFieldValuesController(service *goa.Service, db application.DB) *ProjectFieldValuesController {
+	return &ProjectFieldValuesController{Controller: service.NewController("ProjectFieldValuesController"), db: db}
+}
+
+// Show runs the show action.
+func (c *ProjectFieldValuesController) Show(ctx *app.ShowProjectFieldValuesContext) error {
+	projectID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err = appl.Projects().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+		}
+		fv, err := appl.FieldValues().Load(ctx, projectID, ctx.Field)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.FieldValuesSingle{
+			Data: ConvertFieldValues(ctx.RequestData, fv),
+		})
+	})
+}
+
+// Update runs the update action.
+func (c *ProjectFieldValuesController) Update(ctx *app.UpdateProjectFieldValuesContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	projectID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	if ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	attrs := ctx.Payload.Data.Attributes
+	if attrs.Version == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.version", nil).Expected("not nil"))
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err = appl.Projects().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+		}
+		fv, err := appl.FieldValues().Load(ctx, projectID, ctx.Field)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		// removing a value still in use would leave work items that can't
+		// be sorted or saved anymore
+		values := fieldvalues.Values(attrs.Values)
+		one := 1
+		for _, old := range fv.Values {
+			if values.Index(old) >= 0 {
+				continue
+			}
+			exp := criteria.And(
+				criteria.Equals(criteria.Field(workitem.SystemProject), criteria.Literal(projectID.String())),
+				criteria.Equals(criteria.Field(ctx.Field), criteria.Literal(old)))
+			_, count, err := appl.WorkItems().List(ctx, exp, nil, &one)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			if count > 0 {
+				return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.values", attrs.Values).Expected("to contain the used value "+old))
+			}
+		}
+
+		fv.Values = values
+		fv.Version = *attrs.Version
+		err = appl.FieldValues().Save(ctx, fv)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.FieldValuesSingle{
+			Data: ConvertFieldValues(ctx.RequestData, fv),
+		})
+	})
+}
+
+// ConvertFieldValues converts between internal and external REST representation
+func ConvertFieldValues(request *goa.RequestData, fv *fieldvalues.FieldValues) *app.FieldValues {
+	selfURL := AbsoluteURL(request, app.ProjectFieldValuesHref(fv.ProjectID, fv.FieldName))
+	field := fv.FieldName
+	return &app.FieldValues{
+		Type: "fieldvalues",
+		ID:   &field,
+		Attributes: &app.FieldValuesAttributes{
+			Values:  []string(fv.Values),
+			Version: &fv.Version,
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
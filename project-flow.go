@@ -0,0 +1,81 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/job"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// flowJobKind is the kind of the jobs taking the daily state snapshots
+const flowJobKind = "workitem.flow-snapshot"
+
+// defaultFlowDays is the range of a cumulative flow diagram without a start
+const defaultFlowDays = 30
+
+// ProjectFlowController implements the project-flow resource.
+type ProjectFlowController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectFlowController creates a project-flow controller.
+func NewProjectFlowController(service *goa.Service, db application.DB) *ProjectFlowController {
+	return &ProjectFlowController{Controller: service.NewController("ProjectFlowController"), db: db}
+}
+
+// Show runs the show action.
+func (c *ProjectFlowController) Show(ctx *app.ShowProjectFlowContext) error {
+	projectID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	to := time.Now()
+	if ctx.To != nil {
+		to = *ctx.To
+	}
+	from := to.AddDate(0, 0, -defaultFlowDays)
+	if ctx.From != nil {
+		from = *ctx.From
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err := appl.Projects().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+		}
+		diagram, err := appl.Flows().Diagram(ctx, projectID, from, to)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.CumulativeFlow{
+			States: diagram.States,
+			Days:   make([]*app.CumulativeFlowDay, 0, len(diagram.Days)),
+		}
+		for _, d := range diagram.Days {
+			res.Days = append(res.Days, &app.CumulativeFlowDay{Day: d.Day, Counts: d.Counts})
+		}
+		return ctx.OK(res)
+	})
+}
+
+// snapshotFlowJob returns the handler of the scheduled jobs storing the
+// number of work items per state of every project for today
+func snapshotFlowJob(db application.DB) job.Handler {
+	return func(ctx context.Context, payload []byte) error {
+		return application.Transactional(db, func(appl application.Application) error {
+			n, err := appl.Flows().Snapshot(ctx, time.Now())
+			if err != nil {
+				return err
+			}
+			log.Printf("Stored %d work item state counts\n", n)
+			return nil
+		})
+	}
+}
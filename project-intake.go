@@ -0,0 +1,249 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/intake"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/moderation"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ProjectIntakeController implements the project-intake resource.
+type ProjectIntakeController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectIntakeController creates a project-intake controller.
+func NewProjectIntakeController(service *goa.Service, db application.DB) *ProjectIntakeController {
+	return &ProjectIntakeController{Controller: service.NewController("ProjectIntakeController"), db: db}
+}
+
+// Show runs the show action.
+func (c *ProjectIntakeController) Show(ctx *app.ShowProjectIntakeContext) error {
+	projectID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err := appl.Projects().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		p, err := appl.IntakePortals().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.IntakePortalSingle{Data: ConvertIntakePortal(p)})
+	})
+}
+
+// Update runs the update action.
+func (c *ProjectIntakeController) Update(ctx *app.UpdateProjectIntakeContext) error {
+	if ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	attrs := ctx.Payload.Data.Attributes
+	portal := intake.Portal{
+		Enabled:      attrs.Enabled,
+		WorkItemType: attrs.WorkItemType,
+		Label:        intake.DefaultLabel,
+		RateLimit:    intake.DefaultRateLimit,
+	}
+	if attrs.Label != nil {
+		portal.Label = *attrs.Label
+	}
+	if attrs.RateLimit != nil {
+		portal.RateLimit = *attrs.RateLimit
+	}
+	if attrs.Origins != nil {
+		portal.Origins = attrs.Origins
+	}
+	if attrs.RequireToken != nil {
+		portal.RequireToken = *attrs.RequireToken
+	}
+	return administrateProject(ctx, c.db, ctx.ID, "change the feedback portal", func(appl application.Application, projectID uuid.UUID) error {
+		if _, err := appl.WorkItemTypes().Load(ctx, portal.WorkItemType); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.work-item-type", portal.WorkItemType).Expected("the name of a work item type"))
+		}
+		portal.ProjectID = projectID
+		p, err := appl.IntakePortals().Save(ctx, portal)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.IntakePortalSingle{Data: ConvertIntakePortal(p)})
+	})
+}
+
+// Delete runs the delete action.
+func (c *ProjectIntakeController) Delete(ctx *app.DeleteProjectIntakeContext) error {
+	return administrateProject(ctx, c.db, ctx.ID, "change the feedback portal", func(appl application.Application, projectID uuid.UUID) error {
+		if err := appl.IntakePortals().Delete(ctx, projectID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// Submit runs the submit action.
+func (c *ProjectIntakeController) Submit(ctx *app.SubmitProjectIntakeContext) error {
+	projectID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	s := intake.Submission{Title: ctx.Payload.Title}
+	if ctx.Payload.Description != nil {
+		s.Description = *ctx.Payload.Description
+	}
+	if ctx.Payload.PageURL != nil {
+		s.PageURL = *ctx.Payload.PageURL
+	}
+	if ctx.Payload.Website != nil {
+		s.Website = *ctx.Payload.Website
+	}
+	if err := s.Validate(); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		p, err := appl.Projects().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		portal, err := appl.IntakePortals().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if !portal.Enabled {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewNotFoundError("intake portal", projectID.String()))
+		}
+		// forms on other websites must be embedded on an allowed origin
+		origin := ctx.Request.Header.Get("Origin")
+		if origin != "" && !isSameOrigin(origin, ctx.Request) && !portal.Origins.Allows(origin) {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("the feedback form can't be embedded on "+origin))
+		}
+		if portal.RequireToken || ctx.XIntakeToken != nil {
+			secret := ""
+			if ctx.XIntakeToken != nil {
+				secret = *ctx.XIntakeToken
+			}
+			if _, err := appl.IntakePortals().VerifyToken(ctx, projectID, secret, origin); err != nil {
+				if _, ok := err.(errors.NotFoundError); ok {
+					return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid intake token"))
+				}
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+		}
+		if err := verifyChallenge(ctx, p, ctx.XChallengeToken, ctx.Request); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if err := appl.IntakePortals().Admit(ctx, *portal, remoteIP(ctx.Request)); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		verdict, err := moderation.Screen(ctx, moderation.Content{
+			Kind:      moderation.KindWorkItem,
+			ProjectID: projectID,
+			AuthorID:  account.AnonymousIdentityID,
+			Text:      s.Title + "\n" + s.Description,
+		})
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if verdict == moderation.Reject {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("content", moderation.KindWorkItem).Expected("no blocked content"))
+		}
+		fields := s.Fields(*portal)
+		pending := verdict == moderation.Review
+		fields[workitem.SystemPendingReview] = pending
+		created, err := appl.WorkItems().Create(ctx, portal.WorkItemType, fields, account.AnonymousIdentityID.String())
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		ctx.ResponseData.Header().Set("Location", app.WorkitemHref(created.ID))
+		return ctx.Created(&app.IntakeReceipt{ID: created.ID, PendingReview: pending})
+	})
+}
+
+// ListTokens runs the list-tokens action.
+func (c *ProjectIntakeController) ListTokens(ctx *app.ListTokensProjectIntakeContext) error {
+	return administrateProject(ctx, c.db, ctx.ID, "change the feedback portal", func(appl application.Application, projectID uuid.UUID) error {
+		tokens, err := appl.IntakePortals().ListTokens(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.IntakeTokenList{Data: make([]*app.IntakeToken, len(tokens))}
+		for i, t := range tokens {
+			res.Data[i] = ConvertIntakeToken(t)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// CreateToken runs the create-token action.
+func (c *ProjectIntakeController) CreateToken(ctx *app.CreateTokenProjectIntakeContext) error {
+	if ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	attrs := ctx.Payload.Data.Attributes
+	var origin string
+	if attrs.Origin != nil {
+		origin = *attrs.Origin
+	}
+	return administrateProject(ctx, c.db, ctx.ID, "change the feedback portal", func(appl application.Application, projectID uuid.UUID) error {
+		t, secret, err := appl.IntakePortals().CreateToken(ctx, projectID, attrs.Name, origin)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := ConvertIntakeToken(t)
+		res.Attributes.Token = &secret
+		return ctx.Created(&app.IntakeTokenSingle{Data: res})
+	})
+}
+
+// RevokeToken runs the revoke-token action.
+func (c *ProjectIntakeController) RevokeToken(ctx *app.RevokeTokenProjectIntakeContext) error {
+	return administrateProject(ctx, c.db, ctx.ID, "change the feedback portal", func(appl application.Application, projectID uuid.UUID) error {
+		if err := appl.IntakePortals().RevokeToken(ctx, projectID, ctx.TokenID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.NoContent()
+	})
+}
+
+// ConvertIntakePortal converts between internal and external REST representation
+func ConvertIntakePortal(p *intake.Portal) *app.IntakePortal {
+	return &app.IntakePortal{
+		Type: "intakeportals",
+		ID:   &p.ProjectID,
+		Attributes: &app.IntakePortalAttributes{
+			Enabled:      p.Enabled,
+			WorkItemType: p.WorkItemType,
+			Label:        &p.Label,
+			RateLimit:    &p.RateLimit,
+			Origins:      p.Origins,
+			RequireToken: &p.RequireToken,
+		},
+	}
+}
+
+// ConvertIntakeToken converts between internal and external REST
+// representation, without the secret of the token
+func ConvertIntakeToken(t *intake.Token) *app.IntakeToken {
+	res := &app.IntakeToken{
+		Type: "intaketokens",
+		ID:   &t.ID,
+		Attributes: &app.IntakeTokenAttributes{
+			Name:       t.Name,
+			CreatedAt:  &t.CreatedAt,
+			LastUsedAt: t.LastUsedAt,
+		},
+	}
+	if t.Origin != "" {
+		res.Attributes.Origin = &t.Origin
+	}
+	return res
+}
@@ -42,7 +42,7 @@ func (c *ProjectIterationsController) Create(ctx *app.CreateProjectIterationsCon
 		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.name", nil).Expected("not nil"))
 	}
 
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 		_, err = appl.Projects().Load(ctx, projectID)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
@@ -75,14 +75,18 @@ func (c *ProjectIterationsController) List(ctx *app.ListProjectIterationsContext
 		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
 	}
 
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 
 		_, err = appl.Projects().Load(ctx, projectID)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
 		}
 
-		iterations, err := appl.Iterations().List(ctx, projectID)
+		list := appl.Iterations().List
+		if ctx.FilterArchived != nil && *ctx.FilterArchived {
+			list = appl.Iterations().ListArchived
+		}
+		iterations, err := list(ctx, projectID)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, err)
 		}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"io/ioutil"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/bodylimit"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/processconfig"
+	"github.com/almighty/almighty-core/processtemplate"
+	"github.com/almighty/almighty-core/projectsettings"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ProjectProcessConfigController implements the project-process-config resource.
+type ProjectProcessConfigController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectProcessConfigController creates a project-process-config controller.
+func NewProjectProcessConfigController(service *goa.Service, db application.DB) *ProjectProcessConfigController {
+	return &ProjectProcessConfigController{Controller: service.NewController("ProjectProcessConfigController"), db: db}
+}
+
+// Show runs the show action.
+func (c *ProjectProcessConfigController) Show(ctx *app.ShowProjectProcessConfigContext) error {
+	projectID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if _, err := appl.Projects().Load(ctx, projectID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		config, err := appl.ProcessConfigs().Dump(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		out, err := config.Marshal()
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewInternalError(err.Error()))
+		}
+		return ctx.OK(out)
+	})
+}
+
+// Apply runs the apply action.
+func (c *ProjectProcessConfigController) Apply(ctx *app.ApplyProjectProcessConfigContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing identity"))
+	}
+	projectID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	body, err := ioutil.ReadAll(ctx.Request.Body)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, bodylimit.ReadError("config", err))
+	}
+	config, err := processconfig.Parse(body)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	dryRun := ctx.DryRun != nil && *ctx.DryRun
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if _, err := appl.Projects().Load(ctx, projectID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		admin, err := isProjectAdmin(ctx, appl, projectID, *identityID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if !admin {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only project admins can apply a process config"))
+		}
+		plan, err := appl.ProcessConfigs().Plan(ctx, projectID, config)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if plan.Global() && !isInstanceAdmin(ctx) {
+			// the project may let its admins create types
+			process, err := appl.ProjectSettings().Process(ctx, projectID)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			if process.LinkTypeCreators != projectsettings.CreatorsProjectAdmins {
+				return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can change work item types, link categories and link types"))
+			}
+		}
+		if !dryRun {
+			if err := plan.Execute(ctx); err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+		}
+		return ctx.OK(convertProcessConfigApplied(plan, dryRun))
+	})
+}
+
+// ApplyTemplate runs the apply-template action.
+func (c *ProjectProcessConfigController) ApplyTemplate(ctx *app.ApplyTemplateProjectProcessConfigContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing identity"))
+	}
+	projectID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	dryRun := ctx.DryRun != nil && *ctx.DryRun
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if _, err := appl.Projects().Load(ctx, projectID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		admin, err := isProjectAdmin(ctx, appl, projectID, *identityID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if !admin {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only project admins can apply a process template"))
+		}
+		t, err := appl.ProcessTemplates().Load(ctx, ctx.Name)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		plan, err := applyProcessTemplate(ctx, appl, projectID, t, dryRun)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(convertProcessConfigApplied(plan, dryRun))
+	})
+}
+
+// applyProcessTemplate applies the config of the template to the project
+// unless it is a dry run, global changes need no instance admin since only
+// instance admins register templates
+func applyProcessTemplate(ctx context.Context, appl application.Application, projectID uuid.UUID, t *processtemplate.Template, dryRun bool) (*processconfig.Plan, error) {
+	config, err := t.Parse()
+	if err != nil {
+		return nil, err
+	}
+	plan, err := appl.ProcessConfigs().Plan(ctx, projectID, config)
+	if err != nil {
+		return nil, err
+	}
+	if !dryRun {
+		if err := plan.Execute(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return plan, nil
+}
+
+func convertProcessConfigApplied(plan *processconfig.Plan, dryRun bool) *app.ProcessConfigApplied {
+	res := &app.ProcessConfigApplied{Changes: make([]string, 0, len(plan.Changes)), DryRun: dryRun}
+	for _, change := range plan.Changes {
+		res.Changes = append(res.Changes, change.String())
+	}
+	return res
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/release"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ProjectReleasesController implements the project-releases resource.
+type ProjectReleasesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectReleasesController creates a project-releases controller.
+func NewProjectReleasesController(service *goa.Service, db application.DB) *ProjectReleasesController {
+	return &ProjectReleasesController{Controller: service.NewController("ProjectReleasesController"), db: db}
+}
+
+// Create runs the create action.
+func (c *ProjectReleasesController) Create(ctx *app.CreateProjectReleasesContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	projectID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	if ctx.Payload.Data == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data", nil).Expected("not nil"))
+	}
+	attrs := ctx.Payload.Data.Attributes
+	if attrs.Version == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.version", nil).Expected("not nil"))
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err = appl.Projects().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+		}
+
+		r := release.Release{
+			ProjectID:  projectID,
+			Version:    *attrs.Version,
+			TargetDate: attrs.TargetDate,
+		}
+		err = appl.Releases().Create(ctx, &r)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		res := &app.ReleaseSingle{
+			Data: ConvertRelease(ctx.RequestData, &r, 0, 0),
+		}
+		ctx.ResponseData.Header().Set("Location", AbsoluteURL(ctx.RequestData, app.ReleaseHref(res.Data.ID)))
+		return ctx.Created(res)
+	})
+}
+
+// List runs the list action.
+func (c *ProjectReleasesController) List(ctx *app.ListProjectReleasesContext) error {
+	projectID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err = appl.Projects().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+		}
+
+		releases, err := appl.Releases().List(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		data := []*app.Release{}
+		for _, r := range releases {
+			converted, err := convertReleaseWithCompletion(ctx, appl, ctx.RequestData, r)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			data = append(data, converted)
+		}
+
+		res := &app.ReleaseList{
+			Data: data,
+			Meta: &app.WorkItemListResponseMeta{TotalCount: len(data)},
+		}
+		return ctx.OK(res)
+	})
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/job"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/retention"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// retentionJobKind is the kind of the jobs enforcing the retention policies
+const retentionJobKind = "project.retention"
+
+// ProjectRetentionPolicyController implements the project-retention-policy resource.
+type ProjectRetentionPolicyController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectRetentionPolicyController creates a project-retention-policy controller.
+func NewProjectRetentionPolicyController(service *goa.Service, db application.DB) *ProjectRetentionPolicyController {
+	return &ProjectRetentionPolicyController{Controller: service.NewController("ProjectRetentionPolicyController"), db: db}
+}
+
+// Show runs the show action.
+func (c *ProjectRetentionPolicyController) Show(ctx *app.ShowProjectRetentionPolicyContext) error {
+	projectID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err := appl.Projects().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		p, err := appl.RetentionPolicies().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.RetentionPolicySingle{Data: ConvertRetentionPolicy(p)})
+	})
+}
+
+// Update runs the update action.
+func (c *ProjectRetentionPolicyController) Update(ctx *app.UpdateProjectRetentionPolicyContext) error {
+	if ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	return administrateProject(ctx, c.db, ctx.ID, "change the retention policy", func(appl application.Application, projectID uuid.UUID) error {
+		p, err := appl.RetentionPolicies().Save(ctx, convertRetentionPolicyAttributes(projectID, ctx.Payload.Data.Attributes))
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.RetentionPolicySingle{Data: ConvertRetentionPolicy(p)})
+	})
+}
+
+// Delete runs the delete action.
+func (c *ProjectRetentionPolicyController) Delete(ctx *app.DeleteProjectRetentionPolicyContext) error {
+	return administrateProject(ctx, c.db, ctx.ID, "change the retention policy", func(appl application.Application, projectID uuid.UUID) error {
+		if err := appl.RetentionPolicies().Delete(ctx, projectID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// Preview runs the preview action.
+func (c *ProjectRetentionPolicyController) Preview(ctx *app.PreviewProjectRetentionPolicyContext) error {
+	if ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	return administrateProject(ctx, c.db, ctx.ID, "change the retention policy", func(appl application.Application, projectID uuid.UUID) error {
+		counts, err := appl.RetentionPolicies().Preview(ctx, convertRetentionPolicyAttributes(projectID, ctx.Payload.Data.Attributes), time.Now())
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.RetentionPreview{
+			Revisions: int(counts.Revisions),
+			Snapshots: int(counts.Snapshots),
+			Trashed:   int(counts.Trashed),
+		})
+	})
+}
+
+func convertRetentionPolicyAttributes(projectID uuid.UUID, attrs *app.RetentionPolicyAttributes) retention.Policy {
+	return retention.Policy{
+		ProjectID:    projectID,
+		RevisionDays: attrs.RevisionDays,
+		SnapshotDays: attrs.SnapshotDays,
+		TrashDays:    attrs.TrashDays,
+	}
+}
+
+// ConvertRetentionPolicy converts between internal and external REST representation
+func ConvertRetentionPolicy(p *retention.Policy) *app.RetentionPolicy {
+	return &app.RetentionPolicy{
+		Type: "retentionpolicies",
+		ID:   &p.ProjectID,
+		Attributes: &app.RetentionPolicyAttributes{
+			RevisionDays: p.RevisionDays,
+			SnapshotDays: p.SnapshotDays,
+			TrashDays:    p.TrashDays,
+		},
+	}
+}
+
+// enforceRetentionJob returns the handler of the scheduled jobs removing the
+// history the retention policies of the projects no longer keep
+func enforceRetentionJob(db application.DB) job.Handler {
+	return func(ctx context.Context, payload []byte) error {
+		return application.Transactional(db, func(appl application.Application) error {
+			c, err := appl.RetentionPolicies().Enforce(ctx, time.Now())
+			if err != nil {
+				return err
+			}
+			log.Printf("Removed %d revisions, %d state snapshots and %d deleted work items\n", c.Revisions, c.Snapshots, c.Trashed)
+			return nil
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/projectsettings"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ProjectSettingsController implements the project-settings resource.
+type ProjectSettingsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectSettingsController creates a project-settings controller.
+func NewProjectSettingsController(service *goa.Service, db application.DB) *ProjectSettingsController {
+	return &ProjectSettingsController{Controller: service.NewController("ProjectSettingsController"), db: db}
+}
+
+// Show runs the show action.
+func (c *ProjectSettingsController) Show(ctx *app.ShowProjectSettingsContext) error {
+	projectID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		s, err := appl.ProjectSettings().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.ProjectSettingsSingle{Data: ConvertProjectSettings(s)})
+	})
+}
+
+// Update runs the update action.
+func (c *ProjectSettingsController) Update(ctx *app.UpdateProjectSettingsContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing identity"))
+	}
+	projectID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	if ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	attrs := ctx.Payload.Data.Attributes
+	patch := projectsettings.Patch{
+		DefaultWorkItemType: attrs.DefaultWorkItemType,
+		RequiredFields:      attrs.RequiredFields,
+		LinkTypeCreators:    attrs.LinkTypeCreators,
+		Visibility:          attrs.Visibility,
+		Public:              attrs.Public,
+		IntakeEnabled:       attrs.IntakeEnabled,
+		IntakeRequireToken:  attrs.IntakeRequireToken,
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if _, err := appl.Projects().Load(ctx, projectID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		admin, err := isProjectAdmin(ctx, appl, projectID, *identityID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if !admin {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only project admins can change the settings"))
+		}
+		s, err := appl.ProjectSettings().Update(ctx, projectID, patch)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.ProjectSettingsSingle{Data: ConvertProjectSettings(s)})
+	})
+}
+
+// ConvertProjectSettings converts between internal and external REST representation
+func ConvertProjectSettings(s *projectsettings.Settings) *app.ProjectSettings {
+	return &app.ProjectSettings{
+		Type: "projectsettings",
+		ID:   &s.ProjectID,
+		Attributes: &app.ProjectSettingsAttributes{
+			DefaultWorkItemType: &s.DefaultWorkItemType,
+			RequiredFields:      []string(s.RequiredFields),
+			LinkTypeCreators:    &s.LinkTypeCreators,
+			Visibility:          &s.Visibility,
+			Public:              &s.Public,
+			IntakeEnabled:       &s.IntakeEnabled,
+			IntakeRequireToken:  &s.IntakeRequireToken,
+		},
+	}
+}
+
+// defaultWorkItemType returns the default work item type of the project of
+// the fields, nil if it has none
+func defaultWorkItemType(ctx context.Context, appl application.Application, fields map[string]interface{}) (*string, error) {
+	projectID, err := uuid.FromString(contributionText(fields[workitem.SystemProject]))
+	if err != nil {
+		return nil, nil
+	}
+	process, err := appl.ProjectSettings().Process(ctx, projectID)
+	if err != nil || process.DefaultWorkItemType == "" {
+		return nil, err
+	}
+	return &process.DefaultWorkItemType, nil
+}
+
+// checkRequiredFields returns a BadParameterError if a field required by the
+// project of the work item has no value, fields its type doesn't have are
+// not required
+func checkRequiredFields(ctx context.Context, appl application.Application, wi *app.WorkItem) error {
+	projectID, err := uuid.FromString(contributionText(wi.Fields[workitem.SystemProject]))
+	if err != nil {
+		return nil
+	}
+	process, err := appl.ProjectSettings().Process(ctx, projectID)
+	if err != nil || len(process.RequiredFields) == 0 {
+		return err
+	}
+	wit, err := appl.WorkItemTypes().Load(ctx, wi.Type)
+	if err != nil {
+		return err
+	}
+	for _, field := range process.RequiredFields {
+		if _, ok := wit.Fields[field]; ok && !hasValue(wi.Fields[field]) {
+			return errors.NewBadParameterError("data.attributes."+field, nil).Expected("a value, the project requires it")
+		}
+	}
+	return nil
+}
+
+// hasValue returns false for missing values, empty strings and empty lists
+func hasValue(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case string:
+		return v != ""
+	case []string:
+		return len(v) > 0
+	case []interface{}:
+		return len(v) > 0
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/job"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/notification"
+	"github.com/almighty/almighty-core/stale"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// staleJobKind is the kind of the jobs applying the stale policies
+const staleJobKind = "workitem.stale"
+
+// ProjectStalePolicyController implements the project-stale-policy resource.
+type ProjectStalePolicyController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectStalePolicyController creates a project-stale-policy controller.
+func NewProjectStalePolicyController(service *goa.Service, db application.DB) *ProjectStalePolicyController {
+	return &ProjectStalePolicyController{Controller: service.NewController("ProjectStalePolicyController"), db: db}
+}
+
+// Show runs the show action.
+func (c *ProjectStalePolicyController) Show(ctx *app.ShowProjectStalePolicyContext) error {
+	projectID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err := appl.Projects().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		p, err := appl.StalePolicies().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.StalePolicySingle{Data: ConvertStalePolicy(p)})
+	})
+}
+
+// Update runs the update action.
+func (c *ProjectStalePolicyController) Update(ctx *app.UpdateProjectStalePolicyContext) error {
+	if ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	attrs := ctx.Payload.Data.Attributes
+	return administrateProject(ctx, c.db, ctx.ID, "change the stale policy", func(appl application.Application, projectID uuid.UUID) error {
+		p, err := appl.StalePolicies().Save(ctx, stale.Policy{
+			ProjectID:    projectID,
+			InactiveDays: attrs.InactiveDays,
+			GraceDays:    attrs.GraceDays,
+		})
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.StalePolicySingle{Data: ConvertStalePolicy(p)})
+	})
+}
+
+// Delete runs the delete action.
+func (c *ProjectStalePolicyController) Delete(ctx *app.DeleteProjectStalePolicyContext) error {
+	return administrateProject(ctx, c.db, ctx.ID, "change the stale policy", func(appl application.Application, projectID uuid.UUID) error {
+		if err := appl.StalePolicies().Delete(ctx, projectID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// ConvertStalePolicy converts between internal and external REST representation
+func ConvertStalePolicy(p *stale.Policy) *app.StalePolicy {
+	return &app.StalePolicy{
+		Type: "stalepolicies",
+		ID:   &p.ProjectID,
+		Attributes: &app.StalePolicyAttributes{
+			InactiveDays: p.InactiveDays,
+			GraceDays:    p.GraceDays,
+		},
+	}
+}
+
+// sweepStaleWorkItemsJob returns the handler of the scheduled jobs applying
+// the stale policies, the assignees of the changed work items are notified
+// once the changes are committed
+func sweepStaleWorkItemsJob(db application.DB) job.Handler {
+	return func(ctx context.Context, payload []byte) error {
+		return application.Transactional(db, func(appl application.Application) error {
+			now := time.Now()
+			changes, err := appl.StalePolicies().Sweep(ctx, now)
+			if err != nil {
+				return err
+			}
+			for _, change := range changes {
+				n := notification.Notification{
+					Event:      notification.EventStale,
+					WorkItemID: change.WorkItemID,
+					ProjectID:  change.ProjectID,
+					Subject:    "No activity on " + change.Title,
+					At:         now,
+				}
+				if change.Inactive {
+					n.Event = notification.EventInactive
+					n.Subject = "Moved inactive " + change.Title
+				}
+				for _, assignee := range change.Assignees {
+					n.RecipientID, err = uuid.FromString(assignee)
+					if err != nil {
+						continue
+					}
+					n.Timezone = ""
+					if recipient, err := appl.Identities().Load(ctx, n.RecipientID); err == nil {
+						n.Timezone = recipient.Timezone
+					}
+					if err := notification.Notify(ctx, appl.Jobs(), n); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/job"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// trashJobKind is the kind of the jobs purging the work items deleted before
+// the retention period
+const trashJobKind = "workitem.purge-trash"
+
+// ProjectTrashController implements the project-trash resource.
+type ProjectTrashController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectTrashController creates a project-trash controller.
+func NewProjectTrashController(service *goa.Service, db application.DB) *ProjectTrashController {
+	return &ProjectTrashController{Controller: service.NewController("ProjectTrashController"), db: db}
+}
+
+// List runs the list action.
+func (c *ProjectTrashController) List(ctx *app.ListProjectTrashContext) error {
+	return c.trash(ctx, ctx.ID, false, func(appl application.Application, projectID uuid.UUID) error {
+		trashed, err := appl.Trash().List(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		retention := configuration.GetTrashRetention()
+		res := &app.Trash{Data: make([]*app.TrashedWorkItem, 0, len(trashed))}
+		for _, t := range trashed {
+			res.Data = append(res.Data, &app.TrashedWorkItem{
+				WorkItem:  ConvertWorkItem(ctx.RequestData, t.Item),
+				DeletedAt: t.DeletedAt,
+				DeletedBy: t.DeletedBy,
+				PurgeAt:   t.DeletedAt.Add(retention),
+			})
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Restore runs the restore action.
+func (c *ProjectTrashController) Restore(ctx *app.RestoreProjectTrashContext) error {
+	return c.trash(ctx, ctx.ID, false, func(appl application.Application, projectID uuid.UUID) error {
+		wi, err := appl.Trash().Restore(ctx, projectID, ctx.WorkItemID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.WorkItem2Single{
+			Data: ConvertWorkItem(ctx.RequestData, wi),
+			Links: &app.WorkItemLinks{
+				Self: AbsoluteURL(ctx.RequestData, app.WorkitemHref(wi.ID)),
+			},
+		})
+	})
+}
+
+// Purge runs the purge action.
+func (c *ProjectTrashController) Purge(ctx *app.PurgeProjectTrashContext) error {
+	return c.trash(ctx, ctx.ID, true, func(appl application.Application, projectID uuid.UUID) error {
+		if err := appl.Trash().Purge(ctx, projectID, ctx.WorkItemID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.NoContent()
+	})
+}
+
+// trashContext is implemented by the contexts of all trash actions
+type trashContext interface {
+	context.Context
+	jsonapi.InternalServerError
+}
+
+// trash runs the given function in a transaction if the current identity can
+// read the project, or is an admin of the project if admin is set
+func (c *ProjectTrashController) trash(ctx trashContext, id string, admin bool, f func(appl application.Application, projectID uuid.UUID) error) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing identity"))
+	}
+	projectID, err := uuid.FromString(id)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err := appl.Projects().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if admin {
+			ok, err := isProjectAdmin(ctx, appl, projectID, *identityID)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			if !ok && !isInstanceAdmin(ctx) {
+				return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only project admins can purge work items"))
+			}
+		}
+		return f(appl, projectID)
+	})
+}
+
+// purgeTrashJob returns the handler of the scheduled jobs permanently
+// deleting the work items that are in the trash for longer than the
+// configured retention period
+func purgeTrashJob(db application.DB) job.Handler {
+	return func(ctx context.Context, payload []byte) error {
+		return application.Transactional(db, func(appl application.Application) error {
+			// projects with a trash retention of their own are purged by the
+			// retention job
+			policies, err := appl.RetentionPolicies().List(ctx)
+			if err != nil {
+				return err
+			}
+			var except []uuid.UUID
+			for _, p := range policies {
+				if p.TrashDays != nil {
+					except = append(except, p.ProjectID)
+				}
+			}
+			n, err := appl.Trash().PurgeBefore(ctx, time.Now().Add(-configuration.GetTrashRetention()), except...)
+			if err != nil {
+				return err
+			}
+			log.Printf("Purged %d deleted work items\n", n)
+			return nil
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/hierarchy"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ProjectTypeHierarchyController implements the project-type-hierarchy resource.
+type ProjectTypeHierarchyController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectTypeHierarchyController creates a project-type-hierarchy controller.
+func NewProjectTypeHierarchyController(service *goa.Service, db application.DB) *ProjectTypeHierarchyController {
+	return &ProjectTypeHierarchyController{Controller: service.NewController("ProjectTypeHierarchyController"), db: db}
+}
+
+// Show runs the show action.
+func (c *ProjectTypeHierarchyController) Show(ctx *app.ShowProjectTypeHierarchyContext) error {
+	projectID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err := appl.Projects().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		h, err := appl.TypeHierarchies().Load(ctx, projectID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.TypeHierarchySingle{Data: ConvertTypeHierarchy(h)})
+	})
+}
+
+// Update runs the update action.
+func (c *ProjectTypeHierarchyController) Update(ctx *app.UpdateProjectTypeHierarchyContext) error {
+	if ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	h := hierarchy.Hierarchy{Levels: ctx.Payload.Data.Attributes.Levels}
+	return administrateProject(ctx, c.db, ctx.ID, "change the type hierarchy", func(appl application.Application, projectID uuid.UUID) error {
+		for _, typeName := range h.Levels {
+			if _, err := appl.WorkItemTypes().Load(ctx, typeName); err != nil {
+				return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.levels", typeName).Expected("the name of a work item type"))
+			}
+		}
+		h.ProjectID = projectID
+		saved, err := appl.TypeHierarchies().Save(ctx, h)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.TypeHierarchySingle{Data: ConvertTypeHierarchy(saved)})
+	})
+}
+
+// Delete runs the delete action.
+func (c *ProjectTypeHierarchyController) Delete(ctx *app.DeleteProjectTypeHierarchyContext) error {
+	return administrateProject(ctx, c.db, ctx.ID, "change the type hierarchy", func(appl application.Application, projectID uuid.UUID) error {
+		if err := appl.TypeHierarchies().Delete(ctx, projectID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// ConvertTypeHierarchy converts between internal and external REST representation
+func ConvertTypeHierarchy(h *hierarchy.Hierarchy) *app.TypeHierarchy {
+	levels := []string(h.Levels)
+	if levels == nil {
+		levels = []string{}
+	}
+	return &app.TypeHierarchy{
+		Type: "typehierarchies",
+		ID:   &h.ProjectID,
+		Attributes: &app.TypeHierarchyAttributes{
+			Levels: levels,
+		},
+	}
+}
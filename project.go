@@ -1,11 +1,15 @@
 package main
 
 import (
+	"fmt"
+
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/challenge"
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/jsonapi"
 	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/processtemplate"
 	"github.com/almighty/almighty-core/project"
 	"github.com/goadesign/goa"
 	satoriuuid "github.com/satori/go.uuid"
@@ -24,7 +28,11 @@ func NewProjectController(service *goa.Service, db application.DB) *ProjectContr
 
 // Create runs the create action.
 func (c *ProjectController) Create(ctx *app.CreateProjectContext) error {
-	_, err := login.ContextIdentity(ctx)
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	creatorID, err := satoriuuid.FromString(currentUser)
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
 	}
@@ -33,11 +41,38 @@ func (c *ProjectController) Create(ctx *app.CreateProjectContext) error {
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
 
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		var template *processtemplate.Template
+		if name := ctx.Payload.Data.Attributes.ProcessTemplate; name != nil {
+			template, err = appl.ProcessTemplates().Load(ctx, *name)
+			if _, ok := err.(errors.NotFoundError); ok {
+				return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.process-template", *name).Expected("the name of a process template"))
+			}
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+		}
 		project, err := appl.Projects().Create(ctx, *ctx.Payload.Data.Attributes.Name)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, err)
 		}
+		// the creator administrates the project
+		err = appl.Projects().AddAdmin(ctx, project.ID, creatorID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if v := ctx.Payload.Data.Attributes.Visibility; v != nil && *v != project.Visibility {
+			project.Visibility = *v
+			project, err = appl.Projects().Save(ctx, *project)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+		}
+		if template != nil {
+			if _, err := applyProcessTemplate(ctx, appl, project.ID, template, false); err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+		}
 		res := &app.ProjectSingle{
 			Data: ConvertProject(ctx.RequestData, project),
 		}
@@ -56,7 +91,7 @@ func (c *ProjectController) Delete(ctx *app.DeleteProjectContext) error {
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
 	}
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 		err = appl.Projects().Delete(ctx.Context, id)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, err)
@@ -70,7 +105,7 @@ func (c *ProjectController) Delete(ctx *app.DeleteProjectContext) error {
 func (c *ProjectController) List(ctx *app.ListProjectContext) error {
 	offset, limit := computePagingLimts(ctx.PageOffset, ctx.PageLimit)
 
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 		projects, c, err := appl.Projects().List(ctx.Context, &offset, &limit)
 		count := int(c)
 		if err != nil {
@@ -96,7 +131,7 @@ func (c *ProjectController) Show(ctx *app.ShowProjectContext) error {
 		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
 	}
 
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 		p, err := appl.Projects().Load(ctx.Context, id)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, err)
@@ -126,7 +161,7 @@ func (c *ProjectController) Update(ctx *app.UpdateProjectContext) error {
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
 
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 		p, err := appl.Projects().Load(ctx.Context, id)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, err)
@@ -135,11 +170,59 @@ func (c *ProjectController) Update(ctx *app.UpdateProjectContext) error {
 		if ctx.Payload.Data.Attributes.Name != nil {
 			p.Name = *ctx.Payload.Data.Attributes.Name
 		}
+		if ctx.Payload.Data.Attributes.Public != nil {
+			p.Public = *ctx.Payload.Data.Attributes.Public
+		}
+		if ctx.Payload.Data.Attributes.Challenge != nil {
+			err = challenge.ValidateProvider(*ctx.Payload.Data.Attributes.Challenge)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			p.Challenge = *ctx.Payload.Data.Attributes.Challenge
+		}
+		if v := ctx.Payload.Data.Attributes.Visibility; v != nil && *v != p.Visibility {
+			// only admins decide who can read the project
+			currentUser, _ := login.ContextIdentity(ctx)
+			identityID, err := satoriuuid.FromString(currentUser)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+			}
+			admin, err := isProjectAdmin(ctx, appl, p.ID, identityID)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			if !admin {
+				return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only project admins can change the visibility"))
+			}
+			p.Visibility = *v
+		}
+		key := ctx.Payload.Data.Attributes.Key
+		if key != nil && (p.Key == nil || *key != *p.Key) {
+			// only admins name the keys of the work items
+			currentUser, _ := login.ContextIdentity(ctx)
+			identityID, err := satoriuuid.FromString(currentUser)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+			}
+			admin, err := isProjectAdmin(ctx, appl, p.ID, identityID)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			if !admin {
+				return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only project admins can set the key"))
+			}
+		}
 
 		p, err = appl.Projects().Save(ctx.Context, *p)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, err)
 		}
+		if key != nil {
+			p, err = appl.Projects().SetKey(ctx.Context, p.ID, *key)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+		}
 
 		response := app.ProjectSingle{
 			Data: ConvertProject(ctx.RequestData, p),
@@ -198,13 +281,31 @@ func ConvertProject(request *goa.RequestData, p *project.Project, additional ...
 		ID:   p.ID,
 		Type: "projects",
 		Attributes: &app.ProjectAttributes{
-			Name:      &p.Name,
-			CreatedAt: &p.CreatedAt,
-			UpdatedAt: &p.UpdatedAt,
-			Version:   &p.Version,
+			Name:       &p.Name,
+			Public:     &p.Public,
+			Challenge:  &p.Challenge,
+			Visibility: &p.Visibility,
+			Key:        p.Key,
+			CreatedAt:  &p.CreatedAt,
+			UpdatedAt:  &p.UpdatedAt,
+			Version:    &p.Version,
 		},
 		Links: &app.GenericLinks{
 			Self: &selfURL,
 		},
 	}
 }
+
+// ConvertProjectSimple converts a simple project ID into a Generic Reletionship
+func ConvertProjectSimple(request *goa.RequestData, id interface{}) *app.GenericData {
+	t := "projects"
+	i := fmt.Sprint(id)
+	selfURL := AbsoluteURL(request, app.ProjectHref(i))
+	return &app.GenericData{
+		Type: &t,
+		ID:   &i,
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
@@ -1,22 +1,50 @@
 package project
 
 import (
+	"fmt"
 	"log"
+	"regexp"
 
 	"github.com/almighty/almighty-core/convert"
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/workitem"
 	"github.com/jinzhu/gorm"
 	satoriuuid "github.com/satori/go.uuid"
 	"golang.org/x/net/context"
 )
 
+// Visibilities of a project
+const (
+	// VisibilityPrivate projects can only be read by their admins
+	VisibilityPrivate = "private"
+	// VisibilityInternal projects can be read by all logged in users
+	VisibilityInternal = "internal"
+	// VisibilityPublic projects can be read without logging in
+	VisibilityPublic = "public"
+)
+
+// KeyPattern matches the keys of projects: an upper case letter followed by up
+// to 9 upper case letters or digits
+var KeyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9]{1,9}$`)
+
 // Project represents a project on the domain and db layer
 type Project struct {
 	gormsupport.Lifecycle
 	ID      satoriuuid.UUID
 	Version int
 	Name    string
+	// Public projects accept contributions from everybody, see package moderation
+	Public bool
+	// Challenge is the provider of the challenge anonymous contributors must
+	// solve, see package challenge. Empty if no challenge is required.
+	Challenge string
+	// Visibility decides who can read the project and its work items,
+	// comments, links, iterations, releases and codebases
+	Visibility string
+	// Key prefixes the human-friendly keys of the work items of the project,
+	// e.g. ALM for ALM-482. It can only be set once, see SetKey.
+	Key *string
 }
 
 // Ensure Fields implements the Equaler interface
@@ -39,6 +67,18 @@ func (p Project) Equal(u convert.Equaler) bool {
 	if p.Name != other.Name {
 		return false
 	}
+	if p.Public != other.Public {
+		return false
+	}
+	if p.Challenge != other.Challenge {
+		return false
+	}
+	if p.Visibility != other.Visibility {
+		return false
+	}
+	if (p.Key == nil) != (other.Key == nil) || (p.Key != nil && *p.Key != *other.Key) {
+		return false
+	}
 	return true
 }
 
@@ -49,6 +89,23 @@ type Repository interface {
 	Load(ctx context.Context, ID satoriuuid.UUID) (*Project, error)
 	Delete(ctx context.Context, ID satoriuuid.UUID) error
 	List(ctx context.Context, start *int, length *int) ([]*Project, uint64, error)
+	AddAdmin(ctx context.Context, projectID satoriuuid.UUID, identityID satoriuuid.UUID) error
+	AdminProjectIDs(ctx context.Context, identityID satoriuuid.UUID) ([]satoriuuid.UUID, error)
+	HiddenProjectIDs(ctx context.Context, identityID *satoriuuid.UUID) ([]satoriuuid.UUID, error)
+	SetKey(ctx context.Context, ID satoriuuid.UUID, key string) (*Project, error)
+}
+
+// Admin makes an identity an admin of a project
+type Admin struct {
+	gormsupport.Lifecycle
+	ID         satoriuuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	ProjectID  satoriuuid.UUID `sql:"type:uuid"`
+	IdentityID satoriuuid.UUID `sql:"type:uuid"`
+}
+
+// TableName implements gorm.tabler
+func (a Admin) TableName() string {
+	return "project_admins"
 }
 
 // NewRepository creates a new project repo
@@ -64,6 +121,9 @@ type GormRepository struct {
 // Load returns the project for the given id
 // returns NotFoundError or InternalError
 func (r *GormRepository) Load(ctx context.Context, ID satoriuuid.UUID) (*Project, error) {
+	if !workitem.ContextViewer(ctx).CanReadProject(ID) {
+		return nil, errors.NewNotFoundError("project", ID.String())
+	}
 	res := Project{}
 	tx := r.db.Where("id=?", ID).First(&res)
 	if tx.RecordNotFound() {
@@ -108,11 +168,15 @@ func (r *GormRepository) Save(ctx context.Context, p Project) (*Project, error)
 	if err := tx.Error; err != nil {
 		return nil, errors.NewInternalError(err.Error())
 	}
-	tx = tx.Where("Version = ?", oldVersion).Save(&p)
+	// the key is only set by SetKey
+	tx = tx.Where("Version = ?", oldVersion).Omit("key").Save(&p)
 	if err := tx.Error; err != nil {
 		if gormsupport.IsCheckViolation(tx.Error, "projects_name_check") {
 			return nil, errors.NewBadParameterError("Name", p.Name).Expected("not empty")
 		}
+		if gormsupport.IsCheckViolation(tx.Error, "projects_visibility_check") {
+			return nil, errors.NewBadParameterError("Visibility", p.Visibility).Expected([]string{VisibilityPrivate, VisibilityInternal, VisibilityPublic})
+		}
 		if gormsupport.IsUniqueViolation(tx.Error, "projects_name_idx") {
 			return nil, errors.NewBadParameterError("Name", p.Name).Expected("unique")
 		}
@@ -129,7 +193,8 @@ func (r *GormRepository) Save(ctx context.Context, p Project) (*Project, error)
 // returns BadParameterError or InternalError
 func (r *GormRepository) Create(ctx context.Context, name string) (*Project, error) {
 	newProject := Project{
-		Name: name,
+		Name:       name,
+		Visibility: VisibilityPublic,
 	}
 
 	tx := r.db.Create(&newProject)
@@ -151,6 +216,9 @@ func (r *GormRepository) Create(ctx context.Context, name string) (*Project, err
 func (r *GormRepository) listProjectFromDB(ctx context.Context, start *int, limit *int) ([]*Project, uint64, error) {
 
 	db := r.db.Model(&Project{})
+	if v := workitem.ContextViewer(ctx); v != nil && len(v.HiddenProjectIDs) > 0 {
+		db = db.Where("id NOT IN (?)", v.HiddenProjectIDs)
+	}
 	orgDB := db
 	if start != nil {
 		if *start < 0 {
@@ -225,3 +293,100 @@ func (r *GormRepository) List(ctx context.Context, start *int, limit *int) ([]*P
 
 	return result, count, nil
 }
+
+// AddAdmin makes the given identity an admin of the project, adding an
+// existing admin again is not an error
+// returns InternalError
+func (r *GormRepository) AddAdmin(ctx context.Context, projectID satoriuuid.UUID, identityID satoriuuid.UUID) error {
+	admin := Admin{ProjectID: projectID, IdentityID: identityID}
+	tx := r.db.Create(&admin)
+	if err := tx.Error; err != nil {
+		if gormsupport.IsUniqueViolation(err, "project_admins_project_id_identity_id_idx") {
+			return nil
+		}
+		return errors.NewInternalError(err.Error())
+	}
+	log.Printf("added admin %v\n", admin)
+	return nil
+}
+
+// AdminProjectIDs returns the IDs of the projects the given identity administrates
+// returns InternalError
+func (r *GormRepository) AdminProjectIDs(ctx context.Context, identityID satoriuuid.UUID) ([]satoriuuid.UUID, error) {
+	var admins []Admin
+	tx := r.db.Where("identity_id = ?", identityID).Find(&admins)
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	ids := make([]satoriuuid.UUID, len(admins))
+	for i, a := range admins {
+		ids[i] = a.ProjectID
+	}
+	return ids, nil
+}
+
+// HiddenProjectIDs returns the IDs of the projects the given identity can't
+// read: the private projects it doesn't administrate and, for anonymous
+// users, the internal ones
+// returns InternalError
+func (r *GormRepository) HiddenProjectIDs(ctx context.Context, identityID *satoriuuid.UUID) ([]satoriuuid.UUID, error) {
+	db := r.db.Model(&Project{})
+	if identityID == nil {
+		db = db.Where("visibility IN (?)", []string{VisibilityPrivate, VisibilityInternal})
+	} else {
+		db = db.Where("visibility = ? AND id NOT IN (SELECT project_id FROM project_admins WHERE identity_id = ? AND deleted_at IS NULL)", VisibilityPrivate, *identityID)
+	}
+	var ids []satoriuuid.UUID
+	if err := db.Pluck("id", &ids).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return ids, nil
+}
+
+// SetKey sets the key of the project with the given ID and gives its existing
+// work items keys in the order they were created. The key can't be changed
+// once it is set, references to the keys of the work items would break.
+// returns NotFoundError, BadParameterError or InternalError
+func (r *GormRepository) SetKey(ctx context.Context, ID satoriuuid.UUID, key string) (*Project, error) {
+	if !KeyPattern.MatchString(key) {
+		return nil, errors.NewBadParameterError("key", key).Expected(KeyPattern.String())
+	}
+	if !workitem.ContextViewer(ctx).CanReadProject(ID) {
+		return nil, errors.NewNotFoundError("project", ID.String())
+	}
+	p := Project{}
+	tx := r.db.Set("gorm:query_option", "FOR UPDATE").Where("id=?", ID).First(&p)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("project", ID.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	if p.Key != nil {
+		if *p.Key == key {
+			return &p, nil
+		}
+		return nil, errors.NewBadParameterError("key", key).Expected(fmt.Sprintf("unchanged, the key of the project is %s", *p.Key))
+	}
+	tx = r.db.Model(&p).UpdateColumn("key", key)
+	if err := tx.Error; err != nil {
+		if gormsupport.IsUniqueViolation(err, "projects_key_idx") {
+			return nil, errors.NewBadParameterError("key", key).Expected("unique")
+		}
+		return nil, errors.NewInternalError(err.Error())
+	}
+	// number the existing work items, the sequence continues after them
+	tx = r.db.Exec(fmt.Sprintf(`WITH n AS (
+			SELECT id, row_number() OVER (ORDER BY id) AS seq FROM work_items
+			WHERE fields->>'%s' = ? AND key IS NULL)
+		UPDATE work_items SET key = ? || '-' || n.seq FROM n WHERE work_items.id = n.id`, workitem.SystemProject), ID.String(), key)
+	if err := tx.Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	if err := r.db.Exec("UPDATE projects SET key_sequence = ? WHERE id = ?", tx.RowsAffected, ID).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	p.Key = &key
+	log.Printf("set key of project %s to %s\n", ID, key)
+	return &p, nil
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/workitem"
 	satoriuuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -98,6 +99,47 @@ func (test *repoBBTest) TestList() {
 	assert.Equal(test.T(), orgCount+1, newCount)
 }
 
+func (test *repoBBTest) TestVisibility() {
+	ctx := context.Background()
+	admin := satoriuuid.NewV4()
+	private, _ := expectProject(test.create(testProject), test.requireOk)
+	require.Nil(test.T(), test.repo.AddAdmin(ctx, private.ID, admin))
+	private.Visibility = project.VisibilityPrivate
+	private, _ = expectProject(test.save(*private), test.requireOk)
+	internal, _ := expectProject(test.create(testProject2), test.requireOk)
+	internal.Visibility = project.VisibilityInternal
+	internal, _ = expectProject(test.save(*internal), test.requireOk)
+
+	hidden, err := test.repo.HiddenProjectIDs(ctx, nil)
+	require.Nil(test.T(), err)
+	assert.Contains(test.T(), hidden, private.ID)
+	assert.Contains(test.T(), hidden, internal.ID)
+
+	someone := satoriuuid.NewV4()
+	hidden, err = test.repo.HiddenProjectIDs(ctx, &someone)
+	require.Nil(test.T(), err)
+	assert.Contains(test.T(), hidden, private.ID)
+	assert.NotContains(test.T(), hidden, internal.ID)
+
+	hidden, err = test.repo.HiddenProjectIDs(ctx, &admin)
+	require.Nil(test.T(), err)
+	assert.NotContains(test.T(), hidden, private.ID)
+
+	viewerCtx := workitem.WithViewer(ctx, &workitem.Viewer{IdentityID: &someone, HiddenProjectIDs: []satoriuuid.UUID{private.ID}})
+	_, err = test.repo.Load(viewerCtx, private.ID)
+	assert.IsType(test.T(), errors.NotFoundError{}, err)
+	_, err = test.repo.Load(viewerCtx, internal.ID)
+	assert.Nil(test.T(), err)
+	projects, _, err := test.repo.List(viewerCtx, nil, nil)
+	require.Nil(test.T(), err)
+	for _, p := range projects {
+		assert.NotEqual(test.T(), private.ID, p.ID)
+	}
+
+	private.Visibility = "secret"
+	expectProject(test.save(*private), test.assertBadParameter())
+}
+
 type projectExpectation func(p *project.Project, err error)
 
 func expectProject(f func() (*project.Project, error), e projectExpectation) (*project.Project, error) {
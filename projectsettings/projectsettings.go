@@ -0,0 +1,277 @@
+// Package projectsettings consolidates the settings of a project that are
+// otherwise spread over entities, so they can be read and changed at once:
+// the visibility of the project, the toggles of its intake portal and the
+// process customization toggles stored here. Those are the work item type
+// created when none is given, the fields work items must have a value for
+// and who creates work item types and link types with process configs.
+package projectsettings
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/intake"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Who creates work item types and link types with the process config of a
+// project
+const (
+	CreatorsInstanceAdmins = "instance-admins"
+	CreatorsProjectAdmins  = "project-admins"
+)
+
+// MaxRequiredFields is the number of fields a project can require
+const MaxRequiredFields = 20
+
+// Fields are the names of work item fields
+type Fields []string
+
+// Value implements the driver.Valuer interface
+func (f Fields) Value() (driver.Value, error) {
+	if f == nil {
+		f = Fields{}
+	}
+	return json.Marshal(f)
+}
+
+// Scan implements the sql.Scanner interface
+func (f *Fields) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("scan source was not []byte")
+	}
+	return json.Unmarshal(b, f)
+}
+
+// Contains returns true if the field is one of the fields
+func (f Fields) Contains(field string) bool {
+	for _, name := range f {
+		if name == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Process are the process customization toggles of a project
+type Process struct {
+	gormsupport.Lifecycle
+	ProjectID uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	// DefaultWorkItemType is the type of the work items created without one,
+	// empty if the type must be given
+	DefaultWorkItemType string
+	// RequiredFields must have a value in the work items of the project
+	// whose type has them
+	RequiredFields Fields `sql:"type:jsonb"`
+	// LinkTypeCreators are the ones who create work item types and link
+	// types with the process config of the project
+	LinkTypeCreators string
+}
+
+// TableName implements gorm.tabler
+func (p Process) TableName() string {
+	return "project_settings"
+}
+
+// Settings are all settings of a project
+type Settings struct {
+	Process
+	Visibility         string
+	Public             bool
+	IntakeEnabled      bool
+	IntakeRequireToken bool
+}
+
+// Patch changes the settings that are not nil
+type Patch struct {
+	DefaultWorkItemType *string
+	RequiredFields      []string
+	LinkTypeCreators    *string
+	Visibility          *string
+	Public              *bool
+	IntakeEnabled       *bool
+	IntakeRequireToken  *bool
+}
+
+// Validate checks the values of the patch that don't need the database
+// returns BadParameterError
+func (p Patch) Validate() error {
+	if p.RequiredFields != nil {
+		if len(p.RequiredFields) > MaxRequiredFields {
+			return errors.NewBadParameterError("required-fields", len(p.RequiredFields)).Expected(fmt.Sprintf("at most %d fields", MaxRequiredFields))
+		}
+		seen := map[string]bool{}
+		for _, f := range p.RequiredFields {
+			if f == "" || seen[f] {
+				return errors.NewBadParameterError("required-fields", f).Expected("distinct field names")
+			}
+			seen[f] = true
+		}
+	}
+	if c := p.LinkTypeCreators; c != nil && *c != CreatorsInstanceAdmins && *c != CreatorsProjectAdmins {
+		return errors.NewBadParameterError("link-type-creators", *c).Expected([]string{CreatorsInstanceAdmins, CreatorsProjectAdmins})
+	}
+	if v := p.Visibility; v != nil && *v != project.VisibilityPrivate && *v != project.VisibilityInternal && *v != project.VisibilityPublic {
+		return errors.NewBadParameterError("visibility", *v).Expected([]string{project.VisibilityPrivate, project.VisibilityInternal, project.VisibilityPublic})
+	}
+	return nil
+}
+
+// Repository encapsulates storage & retrieval of the settings of projects
+type Repository interface {
+	Load(ctx context.Context, projectID uuid.UUID) (*Settings, error)
+	Process(ctx context.Context, projectID uuid.UUID) (*Process, error)
+	Update(ctx context.Context, projectID uuid.UUID, p Patch) (*Settings, error)
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{
+		db:       db,
+		projects: project.NewRepository(db),
+		portals:  intake.NewRepository(db),
+		types:    workitem.NewWorkItemTypeRepository(db),
+	}
+}
+
+// GormRepository is the implementation of the storage interface for project
+// settings.
+type GormRepository struct {
+	db       *gorm.DB
+	projects project.Repository
+	portals  intake.Repository
+	types    *workitem.GormWorkItemTypeRepository
+}
+
+// Load returns the settings of the project
+// returns NotFoundError or InternalError
+func (m *GormRepository) Load(ctx context.Context, projectID uuid.UUID) (*Settings, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "projectsettings", "get"}, time.Now())
+
+	p, err := m.projects.Load(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	process, err := m.Process(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	s := &Settings{Process: *process, Visibility: p.Visibility, Public: p.Public}
+	portal, err := m.portals.Load(ctx, projectID)
+	if err == nil {
+		s.IntakeEnabled, s.IntakeRequireToken = portal.Enabled, portal.RequireToken
+	} else if _, ok := err.(errors.NotFoundError); !ok {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Process returns the process customization toggles of the project, the
+// defaults if they were never changed
+// returns InternalError
+func (m *GormRepository) Process(ctx context.Context, projectID uuid.UUID) (*Process, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "projectsettings", "process"}, time.Now())
+
+	var obj Process
+	tx := m.db.Where("project_id = ?", projectID).First(&obj)
+	if tx.RecordNotFound() {
+		return &Process{ProjectID: projectID, RequiredFields: Fields{}, LinkTypeCreators: CreatorsInstanceAdmins}, nil
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
+
+// Update changes the settings of the project. All values are checked before
+// any is stored. Turning on the intake portal of a project without one
+// creates it with the default work item type of the project, or bugs.
+// returns NotFoundError, BadParameterError or InternalError
+func (m *GormRepository) Update(ctx context.Context, projectID uuid.UUID, p Patch) (*Settings, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "projectsettings", "update"}, time.Now())
+
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	prj, err := m.projects.Load(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	process, err := m.Process(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if p.DefaultWorkItemType != nil {
+		if *p.DefaultWorkItemType != "" {
+			if _, err := m.types.LoadTypeFromDB(*p.DefaultWorkItemType); err != nil {
+				return nil, errors.NewBadParameterError("default-work-item-type", *p.DefaultWorkItemType).Expected("the name of a work item type")
+			}
+		}
+		process.DefaultWorkItemType = *p.DefaultWorkItemType
+	}
+	if p.RequiredFields != nil {
+		process.RequiredFields = Fields(p.RequiredFields)
+	}
+	if p.LinkTypeCreators != nil {
+		process.LinkTypeCreators = *p.LinkTypeCreators
+	}
+
+	var portal *intake.Portal
+	if p.IntakeEnabled != nil || p.IntakeRequireToken != nil {
+		portal, err = m.portals.Load(ctx, projectID)
+		if _, ok := err.(errors.NotFoundError); ok {
+			portal = &intake.Portal{ProjectID: projectID, WorkItemType: process.DefaultWorkItemType, Label: intake.DefaultLabel, RateLimit: intake.DefaultRateLimit}
+			if portal.WorkItemType == "" {
+				portal.WorkItemType = workitem.SystemBug
+			}
+		} else if err != nil {
+			return nil, err
+		}
+		if p.IntakeEnabled != nil {
+			portal.Enabled = *p.IntakeEnabled
+		}
+		if p.IntakeRequireToken != nil {
+			portal.RequireToken = *p.IntakeRequireToken
+		}
+		if err := portal.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.Visibility != nil || p.Public != nil {
+		if p.Visibility != nil {
+			prj.Visibility = *p.Visibility
+		}
+		if p.Public != nil {
+			prj.Public = *p.Public
+		}
+		if _, err := m.projects.Save(ctx, *prj); err != nil {
+			return nil, err
+		}
+	}
+	if portal != nil {
+		if _, err := m.portals.Save(ctx, *portal); err != nil {
+			return nil, err
+		}
+	}
+	tx := m.db.Exec(`INSERT INTO project_settings (project_id, default_work_item_type, required_fields, link_type_creators, created_at, updated_at)
+		VALUES (?, ?, ?, ?, now(), now())
+		ON CONFLICT (project_id) DO UPDATE SET default_work_item_type = excluded.default_work_item_type,
+			required_fields = excluded.required_fields, link_type_creators = excluded.link_type_creators, updated_at = now(), deleted_at = NULL`,
+		projectID, process.DefaultWorkItemType, process.RequiredFields, process.LinkTypeCreators)
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return m.Load(ctx, projectID)
+}
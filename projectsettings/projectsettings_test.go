@@ -0,0 +1,102 @@
+package projectsettings_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/intake"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/projectsettings"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestPatchValidate(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	s := func(v string) *string { return &v }
+	assert.Nil(t, projectsettings.Patch{}.Validate())
+	assert.Nil(t, projectsettings.Patch{RequiredFields: []string{workitem.SystemAssignees}, LinkTypeCreators: s(projectsettings.CreatorsProjectAdmins), Visibility: s(project.VisibilityPrivate)}.Validate())
+	for _, invalid := range []projectsettings.Patch{
+		{RequiredFields: []string{workitem.SystemAssignees, workitem.SystemAssignees}},
+		{RequiredFields: []string{""}},
+		{RequiredFields: make([]string, projectsettings.MaxRequiredFields+1)},
+		{LinkTypeCreators: s("everybody")},
+		{Visibility: s("secret")},
+	} {
+		assert.IsType(t, errors.BadParameterError{}, invalid.Validate())
+	}
+}
+
+type TestProjectSettingsRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunProjectSettingsRepository(t *testing.T) {
+	suite.Run(t, &TestProjectSettingsRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestProjectSettingsRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestProjectSettingsRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestProjectSettingsRepository) TestUpdate() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	p, err := project.NewRepository(test.DB).Create(ctx, "settings-"+uuid.NewV4().String())
+	require.Nil(t, err)
+	repo := projectsettings.NewRepository(test.DB)
+
+	s, err := repo.Load(ctx, p.ID)
+	require.Nil(t, err)
+	assert.Equal(t, "", s.DefaultWorkItemType)
+	assert.Empty(t, s.RequiredFields)
+	assert.Equal(t, projectsettings.CreatorsInstanceAdmins, s.LinkTypeCreators)
+	assert.Equal(t, project.VisibilityPublic, s.Visibility)
+	assert.False(t, s.IntakeEnabled)
+
+	defaultType, creators, visibility, enabled := workitem.SystemUserStory, projectsettings.CreatorsProjectAdmins, project.VisibilityInternal, true
+	s, err = repo.Update(ctx, p.ID, projectsettings.Patch{
+		DefaultWorkItemType: &defaultType,
+		RequiredFields:      []string{workitem.SystemAssignees},
+		LinkTypeCreators:    &creators,
+		Visibility:          &visibility,
+		IntakeEnabled:       &enabled,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, workitem.SystemUserStory, s.DefaultWorkItemType)
+	assert.Equal(t, projectsettings.Fields{workitem.SystemAssignees}, s.RequiredFields)
+	assert.Equal(t, projectsettings.CreatorsProjectAdmins, s.LinkTypeCreators)
+	assert.Equal(t, project.VisibilityInternal, s.Visibility)
+	assert.True(t, s.IntakeEnabled)
+	portal, err := intake.NewRepository(test.DB).Load(ctx, p.ID)
+	require.Nil(t, err)
+	assert.Equal(t, workitem.SystemUserStory, portal.WorkItemType)
+
+	// nothing changes if a value is invalid
+	unknown, private := "unknown.type", project.VisibilityPrivate
+	_, err = repo.Update(ctx, p.ID, projectsettings.Patch{Visibility: &private, DefaultWorkItemType: &unknown})
+	assert.IsType(t, errors.BadParameterError{}, err)
+	s, err = repo.Load(ctx, p.ID)
+	require.Nil(t, err)
+	assert.Equal(t, project.VisibilityInternal, s.Visibility)
+	assert.Equal(t, workitem.SystemUserStory, s.DefaultWorkItemType)
+
+	_, err = repo.Load(ctx, uuid.NewV4())
+	assert.IsType(t, errors.NotFoundError{}, err)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/push"
+	"github.com/goadesign/goa"
+)
+
+// PushDevicesController implements the push-devices resource.
+type PushDevicesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewPushDevicesController creates a push-devices controller.
+func NewPushDevicesController(service *goa.Service, db application.DB) *PushDevicesController {
+	return &PushDevicesController{Controller: service.NewController("PushDevicesController"), db: db}
+}
+
+// List runs the list action.
+func (c *PushDevicesController) List(ctx *app.ListPushDevicesContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		devices, err := appl.PushDevices().List(ctx, *identityID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.PushDeviceList{Data: make([]*app.PushDevice, len(devices))}
+		for i, d := range devices {
+			res.Data[i] = ConvertPushDevice(ctx.RequestData, d)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Register runs the register action.
+func (c *PushDevicesController) Register(ctx *app.RegisterPushDevicesContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	if ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	d := &push.Device{IdentityID: *identityID}
+	applyPushDeviceAttributes(d, ctx.Payload.Data.Attributes)
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if err := appl.PushDevices().Register(ctx, d); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.PushDeviceSingle{Data: ConvertPushDevice(ctx.RequestData, d)}
+		ctx.ResponseData.Header().Set("Location", pushDeviceURL(ctx.RequestData, d))
+		return ctx.Created(res)
+	})
+}
+
+// Update runs the update action.
+func (c *PushDevicesController) Update(ctx *app.UpdatePushDevicesContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	if ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	attrs := ctx.Payload.Data.Attributes
+	if attrs.Platform != nil || attrs.Token != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.token", attrs.Token).Expected("unchanged, register the device again"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		d, err := appl.PushDevices().Load(ctx, *identityID, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		applyPushDeviceAttributes(d, attrs)
+		if err := appl.PushDevices().Save(ctx, d); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.PushDeviceSingle{Data: ConvertPushDevice(ctx.RequestData, d)})
+	})
+}
+
+// Delete runs the delete action.
+func (c *PushDevicesController) Delete(ctx *app.DeletePushDevicesContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if err := appl.PushDevices().Delete(ctx, *identityID, ctx.ID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.NoContent()
+	})
+}
+
+// applyPushDeviceAttributes sets the given attributes of the device
+func applyPushDeviceAttributes(d *push.Device, attrs *app.PushDeviceAttributes) {
+	if attrs.Platform != nil {
+		d.Platform = *attrs.Platform
+	}
+	if attrs.Token != nil {
+		d.Token = *attrs.Token
+	}
+	if attrs.Name != nil {
+		d.Name = *attrs.Name
+	}
+	if attrs.Muted != nil {
+		d.Muted = push.Events(attrs.Muted)
+	}
+	if attrs.Badge != nil {
+		d.Badge = *attrs.Badge
+	}
+}
+
+// pushDeviceURL returns the URL of the device
+func pushDeviceURL(request *goa.RequestData, d *push.Device) string {
+	return AbsoluteURL(request, "/api/user/devices/"+d.ID.String())
+}
+
+// ConvertPushDevice converts from internal to external REST representation,
+// the token is left out
+func ConvertPushDevice(request *goa.RequestData, d *push.Device) *app.PushDevice {
+	selfURL := pushDeviceURL(request, d)
+	muted := []string(d.Muted)
+	if muted == nil {
+		muted = []string{}
+	}
+	return &app.PushDevice{
+		Type: "devices",
+		ID:   &d.ID,
+		Attributes: &app.PushDeviceAttributes{
+			Platform:  &d.Platform,
+			Name:      &d.Name,
+			Muted:     muted,
+			Badge:     &d.Badge,
+			CreatedAt: &d.CreatedAt,
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
@@ -0,0 +1,81 @@
+package push
+
+import (
+	"log"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/notification"
+	"github.com/jinzhu/gorm"
+)
+
+// titles are the titles of the messages by notification event
+var titles = map[string]string{
+	notification.EventStale:     "Stale work item",
+	notification.EventInactive:  "Inactive work item",
+	notification.EventEscalated: "Escalated work item",
+}
+
+// Channel delivers notifications to the devices of their recipients
+type Channel struct {
+	db      *gorm.DB
+	senders map[string]Sender
+}
+
+// NewChannel creates a channel sending through the given senders by
+// platform, devices of other platforms are skipped
+func NewChannel(db *gorm.DB, senders map[string]Sender) *Channel {
+	return &Channel{db: db, senders: senders}
+}
+
+// Name implements notification.Channel
+func (c *Channel) Name() string {
+	return notification.ChannelPush
+}
+
+// Deliver implements notification.Channel. Devices the push service no
+// longer knows are unregistered, the notification is delivered to the other
+// devices before a failure is returned.
+func (c *Channel) Deliver(ctx context.Context, n notification.Notification) error {
+	repo := NewDeviceRepository(c.db)
+	devices, err := repo.List(ctx, n.RecipientID)
+	if err != nil {
+		return err
+	}
+	title, ok := titles[n.Event]
+	if !ok {
+		title = "Work item " + n.WorkItemID
+	}
+	var badge *int
+	var failed error
+	for _, d := range devices {
+		sender, ok := c.senders[d.Platform]
+		if !ok || d.Muted.Contains(n.Event) {
+			continue
+		}
+		m := Message{Title: title, Body: n.Subject, Event: n.Event, WorkItemID: n.WorkItemID}
+		if d.Badge {
+			if badge == nil {
+				count, err := repo.Badge(ctx, n.RecipientID)
+				if err != nil {
+					return err
+				}
+				badge = &count
+			}
+			m.Badge = badge
+		}
+		err := sender.Send(d.Token, m)
+		if err == ErrUnregistered {
+			log.Printf("Unregistered the %s device %s of %s, the push service no longer knows it\n", d.Platform, d.ID, d.IdentityID)
+			if err := repo.Unregister(ctx, d.Platform, d.Token); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil && failed == nil {
+			failed = errors.NewInternalError(d.Platform + ": " + err.Error())
+		}
+	}
+	return failed
+}
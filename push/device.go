@@ -0,0 +1,257 @@
+// Package push delivers notifications to the mobile devices of identities
+// through Firebase Cloud Messaging (Android) and the Apple Push Notification
+// service (iOS). The apps register their devices with the token the push
+// service gave them, every device chooses which events it is notified of and
+// whether the badge of the app counts the open work items assigned to the
+// identity.
+package push
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/notification"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Platforms are the push services devices are registered with
+const (
+	PlatformFCM  = "fcm"
+	PlatformAPNs = "apns"
+)
+
+// MaxDevices is the number of devices an identity can register
+const MaxDevices = 20
+
+// Events are the notification events a device isn't notified of
+type Events []string
+
+// Value implements the driver.Valuer interface
+func (e Events) Value() (driver.Value, error) {
+	if e == nil {
+		e = Events{}
+	}
+	return json.Marshal(e)
+}
+
+// Scan implements the sql.Scanner interface
+func (e *Events) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("scan source was not []byte")
+	}
+	return json.Unmarshal(b, e)
+}
+
+// Contains returns true if the event is one of the events
+func (e Events) Contains(event string) bool {
+	for _, x := range e {
+		if x == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Device is a mobile device an identity is notified on
+type Device struct {
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	ID         uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	IdentityID uuid.UUID `sql:"type:uuid"`
+	Platform   string
+	// Token addresses the device at its push service, it is a secret
+	Token string
+	// Name tells the devices of an identity apart, e.g. "work phone"
+	Name string
+	// Muted are the notification events the device isn't notified of
+	Muted Events `sql:"type:jsonb"`
+	// Badge sets the badge of the app to the number of open work items
+	// assigned to the identity
+	Badge bool
+}
+
+// TableName implements gorm.tabler
+func (d Device) TableName() string {
+	return "push_devices"
+}
+
+// Validate checks the platform, the token and the muted events of the device
+// returns BadParameterError
+func (d Device) Validate() error {
+	if d.Platform != PlatformFCM && d.Platform != PlatformAPNs {
+		return errors.NewBadParameterError("platform", d.Platform).Expected(PlatformFCM + " or " + PlatformAPNs)
+	}
+	if d.Token == "" {
+		return errors.NewBadParameterError("token", d.Token).Expected("not empty")
+	}
+	for _, event := range d.Muted {
+		if !Events(notification.Events).Contains(event) {
+			return errors.NewBadParameterError("muted", event).Expected(notification.Events)
+		}
+	}
+	return nil
+}
+
+// Repository encapsulates storage & retrieval of devices
+type Repository interface {
+	Register(ctx context.Context, d *Device) error
+	Load(ctx context.Context, identityID uuid.UUID, id uuid.UUID) (*Device, error)
+	List(ctx context.Context, identityID uuid.UUID) ([]*Device, error)
+	Save(ctx context.Context, d *Device) error
+	Delete(ctx context.Context, identityID uuid.UUID, id uuid.UUID) error
+	Unregister(ctx context.Context, platform string, token string) error
+	Badge(ctx context.Context, identityID uuid.UUID) (int, error)
+}
+
+// NewDeviceRepository creates a new storage type.
+func NewDeviceRepository(db *gorm.DB) Repository {
+	return &GormDeviceRepository{db: db}
+}
+
+// GormDeviceRepository is the implementation of the storage interface for
+// devices.
+type GormDeviceRepository struct {
+	db *gorm.DB
+}
+
+// Register stores the device of its identity. A device registered before,
+// possibly by another identity that used it, is registered again with the
+// given identity and preferences and keeps its ID.
+// returns BadParameterError or InternalError
+func (m *GormDeviceRepository) Register(ctx context.Context, d *Device) error {
+	defer goa.MeasureSince([]string{"goa", "db", "pushdevice", "register"}, time.Now())
+
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	var count int
+	err := m.db.Model(&Device{}).Where("identity_id = ? AND NOT (platform = ? AND token = ?)", d.IdentityID, d.Platform, d.Token).Count(&count).Error
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if count >= MaxDevices {
+		return errors.NewBadParameterError("devices", count+1).Expected(fmt.Sprintf("at most %d devices", MaxDevices))
+	}
+	muted, err := d.Muted.Value()
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	row := m.db.Raw(`INSERT INTO push_devices (identity_id, platform, token, name, muted, badge, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, now(), now())
+		ON CONFLICT (platform, token) DO UPDATE SET identity_id = excluded.identity_id, name = excluded.name,
+			muted = excluded.muted, badge = excluded.badge, updated_at = now()
+		RETURNING id, created_at, updated_at`,
+		d.IdentityID, d.Platform, d.Token, d.Name, muted, d.Badge).Row()
+	if err := row.Scan(&d.ID, &d.CreatedAt, &d.UpdatedAt); err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// Load returns the device of the identity
+// returns NotFoundError or InternalError
+func (m *GormDeviceRepository) Load(ctx context.Context, identityID uuid.UUID, id uuid.UUID) (*Device, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "pushdevice", "get"}, time.Now())
+
+	var obj Device
+	tx := m.db.Where("id = ? AND identity_id = ?", id, identityID).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("device", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
+
+// List returns the devices of the identity, the first registered first
+// returns InternalError
+func (m *GormDeviceRepository) List(ctx context.Context, identityID uuid.UUID) ([]*Device, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "pushdevice", "list"}, time.Now())
+
+	var objs []*Device
+	if err := m.db.Where("identity_id = ?", identityID).Order("created_at, id").Find(&objs).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return objs, nil
+}
+
+// Save updates the name and the preferences of the device
+// returns NotFoundError, BadParameterError or InternalError
+func (m *GormDeviceRepository) Save(ctx context.Context, d *Device) error {
+	defer goa.MeasureSince([]string{"goa", "db", "pushdevice", "save"}, time.Now())
+
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	tx := m.db.Model(d).Where("identity_id = ?", d.IdentityID).Updates(map[string]interface{}{
+		"name":  d.Name,
+		"muted": d.Muted,
+		"badge": d.Badge,
+	})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("device", d.ID.String())
+	}
+	return nil
+}
+
+// Delete removes the device of the identity, it isn't notified anymore
+// returns NotFoundError or InternalError
+func (m *GormDeviceRepository) Delete(ctx context.Context, identityID uuid.UUID, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "pushdevice", "delete"}, time.Now())
+
+	tx := m.db.Where("id = ? AND identity_id = ?", id, identityID).Delete(&Device{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("device", id.String())
+	}
+	return nil
+}
+
+// Unregister removes the device with the given token, e.g. because the push
+// service no longer knows the token after the app was uninstalled
+// returns InternalError
+func (m *GormDeviceRepository) Unregister(ctx context.Context, platform string, token string) error {
+	defer goa.MeasureSince([]string{"goa", "db", "pushdevice", "unregister"}, time.Now())
+
+	if err := m.db.Where("platform = ? AND token = ?", platform, token).Delete(&Device{}).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// Badge returns the number of open work items assigned to the identity,
+// archived work items and drafts are left out
+// returns InternalError
+func (m *GormDeviceRepository) Badge(ctx context.Context, identityID uuid.UUID) (int, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "pushdevice", "badge"}, time.Now())
+
+	assigned, err := json.Marshal(map[string]interface{}{workitem.SystemAssignees: []string{identityID.String()}})
+	if err != nil {
+		return 0, errors.NewInternalError(err.Error())
+	}
+	var count int
+	err = m.db.Table(workitem.WorkItem{}.TableName()).
+		Where("deleted_at IS NULL AND NOT archived AND fields @> ?", string(assigned)).
+		Where(fmt.Sprintf("coalesce(fields->>'%s', '') NOT IN (?)", workitem.SystemState), []string{workitem.SystemStateClosed, workitem.SystemStateInactive}).
+		Where(fmt.Sprintf(`NOT (fields @> '{"%s": true}')`, workitem.SystemDraft)).
+		Count(&count).Error
+	if err != nil {
+		return 0, errors.NewInternalError(err.Error())
+	}
+	return count, nil
+}
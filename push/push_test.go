@@ -0,0 +1,159 @@
+package push_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/gormsupport/testfixture"
+	"github.com/almighty/almighty-core/notification"
+	"github.com/almighty/almighty-core/push"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestDeviceRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunDeviceRepository(t *testing.T) {
+	suite.Run(t, &TestDeviceRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestDeviceRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestDeviceRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestDeviceRepository) TestRegister() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := push.NewDeviceRepository(test.DB)
+	owner, buyer := testfixture.CreateIdentity(t, test.DB, "owner"), testfixture.CreateIdentity(t, test.DB, "buyer")
+	d := &push.Device{IdentityID: owner, Platform: push.PlatformFCM, Token: uuid.NewV4().String(), Name: "phone"}
+	require.Nil(t, repo.Register(ctx, d))
+
+	// the device changed hands
+	again := &push.Device{IdentityID: buyer, Platform: push.PlatformFCM, Token: d.Token, Badge: true}
+	require.Nil(t, repo.Register(ctx, again))
+	assert.Equal(t, d.ID, again.ID)
+	devices, err := repo.List(ctx, owner)
+	require.Nil(t, err)
+	assert.Empty(t, devices)
+	devices, err = repo.List(ctx, buyer)
+	require.Nil(t, err)
+	require.Len(t, devices, 1)
+	assert.True(t, devices[0].Badge)
+
+	devices[0].Muted = push.Events{notification.EventStale}
+	require.Nil(t, repo.Save(ctx, devices[0]))
+	loaded, err := repo.Load(ctx, buyer, d.ID)
+	require.Nil(t, err)
+	assert.Equal(t, push.Events{notification.EventStale}, loaded.Muted)
+
+	loaded.Muted = push.Events{"workitem.unknown"}
+	assert.IsType(t, errors.BadParameterError{}, repo.Save(ctx, loaded))
+	assert.IsType(t, errors.BadParameterError{}, repo.Register(ctx, &push.Device{IdentityID: owner, Platform: "sms", Token: "x"}))
+	_, err = repo.Load(ctx, owner, d.ID)
+	assert.IsType(t, errors.NotFoundError{}, err)
+	assert.IsType(t, errors.NotFoundError{}, repo.Delete(ctx, owner, d.ID))
+	require.Nil(t, repo.Delete(ctx, buyer, d.ID))
+}
+
+// sender records the messages and fails for unregistered tokens
+type sender struct {
+	sent         map[string]push.Message
+	unregistered string
+}
+
+func (s *sender) Send(token string, m push.Message) error {
+	if token == s.unregistered {
+		return push.ErrUnregistered
+	}
+	s.sent[token] = m
+	return nil
+}
+
+func (test *TestDeviceRepository) TestDeliver() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := push.NewDeviceRepository(test.DB)
+	identityID := testfixture.CreateIdentity(t, test.DB, "mobile")
+	_, err := workitem.NewWorkItemRepository(test.DB).Create(ctx, workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle:     "assigned",
+		workitem.SystemState:     workitem.SystemStateOpen,
+		workitem.SystemAssignees: []string{identityID.String()},
+	}, identityID.String())
+	require.Nil(t, err)
+
+	badge := &push.Device{IdentityID: identityID, Platform: push.PlatformFCM, Token: uuid.NewV4().String(), Badge: true}
+	muted := &push.Device{IdentityID: identityID, Platform: push.PlatformFCM, Token: uuid.NewV4().String(), Muted: push.Events{notification.EventEscalated}}
+	gone := &push.Device{IdentityID: identityID, Platform: push.PlatformFCM, Token: uuid.NewV4().String()}
+	other := &push.Device{IdentityID: identityID, Platform: push.PlatformAPNs, Token: uuid.NewV4().String()}
+	for _, d := range []*push.Device{badge, muted, gone, other} {
+		require.Nil(t, repo.Register(ctx, d))
+	}
+
+	s := &sender{sent: map[string]push.Message{}, unregistered: gone.Token}
+	channel := push.NewChannel(test.DB, map[string]push.Sender{push.PlatformFCM: s})
+	err = channel.Deliver(ctx, notification.Notification{
+		Event:       notification.EventEscalated,
+		RecipientID: identityID,
+		WorkItemID:  "42",
+		Subject:     "Escalated assigned",
+		At:          time.Now(),
+	})
+	require.Nil(t, err)
+	require.Len(t, s.sent, 1)
+	m := s.sent[badge.Token]
+	assert.Equal(t, "Escalated assigned", m.Body)
+	require.NotNil(t, m.Badge)
+	assert.Equal(t, 1, *m.Badge)
+
+	devices, err := repo.List(ctx, identityID)
+	require.Nil(t, err)
+	assert.Len(t, devices, 3)
+}
+
+func TestFCMSender(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "key=secret", r.Header.Get("Authorization"))
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&received))
+		if received["to"] == "gone" {
+			w.Write([]byte(`{"success":0,"failure":1,"results":[{"error":"NotRegistered"}]}`))
+			return
+		}
+		w.Write([]byte(`{"success":1,"failure":0,"results":[{"message_id":"1"}]}`))
+	}))
+	defer server.Close()
+	defer func(url string) { push.FCMURL = url }(push.FCMURL)
+	push.FCMURL = server.URL
+
+	s := push.NewFCMSender("secret", time.Second)
+	badge := 3
+	require.Nil(t, s.Send("token", push.Message{Title: "Stale work item", Body: "Stale bug", WorkItemID: "42", Badge: &badge}))
+	assert.Equal(t, map[string]interface{}{"title": "Stale work item", "body": "Stale bug", "badge": "3"}, received["notification"])
+	assert.Equal(t, push.ErrUnregistered, s.Send("gone", push.Message{}))
+}
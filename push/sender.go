@@ -0,0 +1,242 @@
+package push
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxResponse is the number of bytes of the responses of the push services
+// read to find out whether the notification was delivered
+const maxResponse = 64 * 1024
+
+// apnsTokenLifetime is how long a provider token is used, APNs refuses
+// tokens older than an hour
+const apnsTokenLifetime = 50 * time.Minute
+
+// The addresses of the push services
+var (
+	FCMURL         = "https://fcm.googleapis.com/fcm/send"
+	APNsURL        = "https://api.push.apple.com"
+	APNsSandboxURL = "https://api.sandbox.push.apple.com"
+)
+
+// ErrUnregistered is returned by senders if the push service doesn't know the
+// token of the device anymore, e.g. because the app was uninstalled
+var ErrUnregistered = fmt.Errorf("the device is no longer registered with the push service")
+
+// Message is a notification as shown on a device
+type Message struct {
+	Title      string
+	Body       string
+	Event      string
+	WorkItemID string
+	// Badge is the number shown on the app icon, nil leaves it as it is
+	Badge *int
+}
+
+// Sender sends messages to the devices of a push service
+type Sender interface {
+	Send(token string, m Message) error
+}
+
+// FCMSender sends messages through Firebase Cloud Messaging
+type FCMSender struct {
+	client *http.Client
+	key    string
+}
+
+// NewFCMSender creates a sender authorized by the server key of the Firebase
+// project whose requests time out after the given duration
+func NewFCMSender(key string, timeout time.Duration) *FCMSender {
+	return &FCMSender{client: &http.Client{Timeout: timeout}, key: key}
+}
+
+// Send implements Sender
+func (s *FCMSender) Send(token string, m Message) error {
+	n := map[string]interface{}{"title": m.Title, "body": m.Body}
+	if m.Badge != nil {
+		n["badge"] = fmt.Sprintf("%d", *m.Badge)
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"to":           token,
+		"notification": n,
+		"data":         map[string]string{"event": m.Event, "workitem": m.WorkItemID},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", FCMURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "key="+s.key)
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(res.Body, maxResponse))
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("FCM responded %s", res.Status)
+	}
+	var sent struct {
+		Results []struct {
+			Error string `json:"error"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(data, &sent); err != nil {
+		return err
+	}
+	for _, r := range sent.Results {
+		switch r.Error {
+		case "":
+		case "NotRegistered", "InvalidRegistration":
+			return ErrUnregistered
+		default:
+			return fmt.Errorf("FCM responded %s", r.Error)
+		}
+	}
+	return nil
+}
+
+// APNsSender sends messages through the Apple Push Notification service,
+// authorized by provider tokens signed with the key of the team
+type APNsSender struct {
+	client *http.Client
+	url    string
+	topic  string
+	keyID  string
+	teamID string
+	key    *ecdsa.PrivateKey
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+// NewAPNsSender creates a sender for the app with the given bundle ID
+// (topic) whose requests time out after the given duration. The key is the
+// PEM encoded .p8 file downloaded from the Apple developer account, the
+// sandbox is used by development builds of the app.
+func NewAPNsSender(key []byte, keyID, teamID, topic string, sandbox bool, timeout time.Duration) (*APNsSender, error) {
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return nil, fmt.Errorf("the APNs key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	ecKey, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("the APNs key is not an ECDSA key")
+	}
+	url := APNsURL
+	if sandbox {
+		url = APNsSandboxURL
+	}
+	return &APNsSender{
+		client: &http.Client{Timeout: timeout},
+		url:    url,
+		topic:  topic,
+		keyID:  keyID,
+		teamID: teamID,
+		key:    ecKey,
+	}, nil
+}
+
+// Send implements Sender
+func (s *APNsSender) Send(token string, m Message) error {
+	aps := map[string]interface{}{
+		"alert": map[string]string{"title": m.Title, "body": m.Body},
+		"sound": "default",
+	}
+	if m.Badge != nil {
+		aps["badge"] = *m.Badge
+	}
+	body, err := json.Marshal(map[string]interface{}{"aps": aps, "event": m.Event, "workitem": m.WorkItemID})
+	if err != nil {
+		return err
+	}
+	auth, err := s.providerToken()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", s.url+"/3/device/"+token, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "bearer "+auth)
+	req.Header.Set("apns-topic", s.topic)
+	req.Header.Set("apns-push-type", "alert")
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusOK {
+		return nil
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(res.Body, maxResponse))
+	if err != nil {
+		return err
+	}
+	var failed struct {
+		Reason string `json:"reason"`
+	}
+	json.Unmarshal(data, &failed)
+	if res.StatusCode == http.StatusGone || failed.Reason == "BadDeviceToken" || failed.Reason == "Unregistered" {
+		return ErrUnregistered
+	}
+	return fmt.Errorf("APNs responded %s %s", res.Status, failed.Reason)
+}
+
+// providerToken returns the JWT authorizing the requests, it is signed again
+// before it expires
+func (s *APNsSender) providerToken() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if s.token != "" && now.Sub(s.issuedAt) < apnsTokenLifetime {
+		return s.token, nil
+	}
+	header, err := json.Marshal(map[string]string{"alg": "ES256", "kid": s.keyID})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{"iss": s.teamID, "iat": now.Unix()})
+	if err != nil {
+		return "", err
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(signed))
+	r, ss, err := ecdsa.Sign(rand.Reader, s.key, hash[:])
+	if err != nil {
+		return "", err
+	}
+	// ES256 signatures are r and s as 32 byte big endian numbers
+	signature := make([]byte, 64)
+	rb, sb := r.Bytes(), ss.Bytes()
+	copy(signature[32-len(rb):32], rb)
+	copy(signature[64-len(sb):], sb)
+	s.token = signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+	s.issuedAt = now
+	return s.token, nil
+}
@@ -0,0 +1,155 @@
+package reaction
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Emojis lists the reactions that can be added to a comment in the order
+// they are presented
+var Emojis = []string{"+1", "-1", "laugh", "hooray", "confused", "heart"}
+
+// Reaction records the emoji reaction of an identity to a comment
+type Reaction struct {
+	gormsupport.Lifecycle
+	ID         uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	CommentID  uuid.UUID `sql:"type:uuid"`
+	IdentityID uuid.UUID `sql:"type:uuid"`
+	Emoji      string
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m Reaction) TableName() string {
+	return "comment_reactions"
+}
+
+// Summary aggregates the reactions with one emoji to a comment
+type Summary struct {
+	Emoji string
+	Count int
+	// Reacted is true if the current identity is one of the reactors
+	Reacted bool
+}
+
+// Repository describes interactions with reactions
+type Repository interface {
+	Create(ctx context.Context, commentID uuid.UUID, identityID uuid.UUID, emoji string) error
+	Delete(ctx context.Context, commentID uuid.UUID, identityID uuid.UUID, emoji string) error
+	Summarize(ctx context.Context, commentIDs []uuid.UUID, identityID *uuid.UUID) (map[uuid.UUID][]Summary, error)
+}
+
+// NewReactionRepository creates a new storage type.
+func NewReactionRepository(db *gorm.DB) Repository {
+	return &GormReactionRepository{db: db}
+}
+
+// GormReactionRepository is the implementation of the storage interface for reactions.
+type GormReactionRepository struct {
+	db *gorm.DB
+}
+
+func validEmoji(emoji string) bool {
+	for _, e := range Emojis {
+		if e == emoji {
+			return true
+		}
+	}
+	return false
+}
+
+// Create records the reaction of the identity to the comment. Reacting again
+// with the same emoji has no effect.
+// returns BadParameterError or InternalError
+func (m *GormReactionRepository) Create(ctx context.Context, commentID uuid.UUID, identityID uuid.UUID, emoji string) error {
+	defer goa.MeasureSince([]string{"goa", "db", "reaction", "create"}, time.Now())
+
+	if !validEmoji(emoji) {
+		return errors.NewBadParameterError("emoji", emoji).Expected(Emojis)
+	}
+	var count int
+	err := m.db.Model(&Reaction{}).Where("comment_id = ? AND identity_id = ? AND emoji = ?", commentID, identityID, emoji).Count(&count).Error
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if count > 0 {
+		return nil
+	}
+
+	r := Reaction{ID: uuid.NewV4(), CommentID: commentID, IdentityID: identityID, Emoji: emoji}
+	err = m.db.Create(&r).Error
+	if err != nil {
+		if gormsupport.IsUniqueViolation(err, "comment_reactions_comment_id_identity_id_emoji_idx") {
+			// a concurrent request added the reaction already
+			return nil
+		}
+		goa.LogError(ctx, "error adding Reaction", "error", err.Error())
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// Delete removes the reaction of the identity to the comment
+// returns NotFoundError or InternalError
+func (m *GormReactionRepository) Delete(ctx context.Context, commentID uuid.UUID, identityID uuid.UUID, emoji string) error {
+	defer goa.MeasureSince([]string{"goa", "db", "reaction", "delete"}, time.Now())
+
+	tx := m.db.Where("comment_id = ? AND identity_id = ? AND emoji = ?", commentID, identityID, emoji).Delete(&Reaction{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("reaction", emoji)
+	}
+	return nil
+}
+
+// Summarize returns the reactions to each of the given comments aggregated by
+// emoji in the order of Emojis. If identityID is given the reactions of that
+// identity are flagged. Comments without reactions are not contained in the
+// result.
+// returns InternalError
+func (m *GormReactionRepository) Summarize(ctx context.Context, commentIDs []uuid.UUID, identityID *uuid.UUID) (map[uuid.UUID][]Summary, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "reaction", "summarize"}, time.Now())
+
+	result := map[uuid.UUID][]Summary{}
+	if len(commentIDs) == 0 {
+		return result, nil
+	}
+	var reactions []Reaction
+	err := m.db.Where("comment_id IN (?)", commentIDs).Find(&reactions).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(err.Error())
+	}
+
+	type key struct {
+		comment uuid.UUID
+		emoji   string
+	}
+	counts := map[key]int{}
+	reacted := map[key]bool{}
+	for _, r := range reactions {
+		k := key{r.CommentID, r.Emoji}
+		counts[k]++
+		if identityID != nil && uuid.Equal(r.IdentityID, *identityID) {
+			reacted[k] = true
+		}
+	}
+	for _, id := range commentIDs {
+		for _, e := range Emojis {
+			k := key{id, e}
+			if counts[k] == 0 {
+				continue
+			}
+			result[id] = append(result[id], Summary{Emoji: e, Count: counts[k], Reacted: reacted[k]})
+		}
+	}
+	return result, nil
+}
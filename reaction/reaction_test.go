@@ -0,0 +1,85 @@
+package reaction_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/gormsupport/testfixture"
+	"github.com/almighty/almighty-core/reaction"
+	"github.com/almighty/almighty-core/resource"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestReactionRepository struct {
+	gormsupport.DBTestSuite
+
+	clean     func()
+	commentID uuid.UUID
+}
+
+func TestRunReactionRepository(t *testing.T) {
+	suite.Run(t, &TestReactionRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestReactionRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+
+	c := comment.Comment{ParentID: "1", Body: "Nice", CreatedBy: testfixture.CreateIdentity(test.T(), test.DB, "reactor")}
+	require.Nil(test.T(), comment.NewCommentRepository(test.DB).Create(context.Background(), &c))
+	test.commentID = c.ID
+}
+
+func (test *TestReactionRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestReactionRepository) TestSummarizeReactions() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := reaction.NewReactionRepository(test.DB)
+	alice := testfixture.CreateIdentity(t, test.DB, "alice")
+	bob := testfixture.CreateIdentity(t, test.DB, "bob")
+	require.Nil(t, repo.Create(context.Background(), test.commentID, alice, "heart"))
+	require.Nil(t, repo.Create(context.Background(), test.commentID, alice, "heart"))
+	require.Nil(t, repo.Create(context.Background(), test.commentID, alice, "+1"))
+	require.Nil(t, repo.Create(context.Background(), test.commentID, bob, "heart"))
+
+	summaries, err := repo.Summarize(context.Background(), []uuid.UUID{test.commentID}, &bob)
+	require.Nil(t, err)
+	assert.Equal(t, []reaction.Summary{
+		{Emoji: "+1", Count: 1, Reacted: false},
+		{Emoji: "heart", Count: 2, Reacted: true},
+	}, summaries[test.commentID])
+}
+
+func (test *TestReactionRepository) TestDeleteReaction() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := reaction.NewReactionRepository(test.DB)
+	alice := testfixture.CreateIdentity(t, test.DB, "alice")
+	require.Nil(t, repo.Create(context.Background(), test.commentID, alice, "laugh"))
+	require.Nil(t, repo.Delete(context.Background(), test.commentID, alice, "laugh"))
+	assert.IsType(t, errors.NotFoundError{}, repo.Delete(context.Background(), test.commentID, alice, "laugh"))
+
+	summaries, err := repo.Summarize(context.Background(), []uuid.UUID{test.commentID}, nil)
+	require.Nil(t, err)
+	assert.Empty(t, summaries[test.commentID])
+}
+
+func (test *TestReactionRepository) TestUnknownEmoji() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := reaction.NewReactionRepository(test.DB)
+	err := repo.Create(context.Background(), test.commentID, testfixture.CreateIdentity(t, test.DB, "reactor"), "rocket-ship")
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
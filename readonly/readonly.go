@@ -0,0 +1,117 @@
+// Package readonly holds whether the instance serves its data read-only and
+// why: the database was migrated to a schema this code can't write, an admin
+// switched on maintenance or a backup is being restored. While it does,
+// requests that could change data are rejected and the transactions of
+// application.Transactional are read only.
+package readonly
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+)
+
+// Reasons the instance is read-only for
+const (
+	Schema      = "schema"
+	Maintenance = "maintenance"
+	Restore     = "restore"
+)
+
+// reason is one of the reasons the instance is read-only for with the
+// message telling the clients about it
+type reason struct {
+	name    string
+	message string
+}
+
+var (
+	mu      sync.RWMutex
+	reasons []reason
+)
+
+// Enable puts the instance in read-only mode for the given reason, the
+// message tells the clients why. Enabling a reason again replaces its
+// message.
+func Enable(name string, msg string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for i := range reasons {
+		if reasons[i].name == name {
+			reasons[i].message = msg
+			return
+		}
+	}
+	reasons = append(reasons, reason{name: name, message: msg})
+}
+
+// Disable removes the given reason, the read-only mode ends with the last
+// one
+func Disable(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for i := range reasons {
+		if reasons[i].name == name {
+			reasons = append(reasons[:i], reasons[i+1:]...)
+			return
+		}
+	}
+}
+
+// Enabled returns whether the instance is in read-only mode and the message
+// of the reason enabled first
+func Enabled() (bool, string) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if len(reasons) == 0 {
+		return false, ""
+	}
+	return true, reasons[0].message
+}
+
+// Reasons returns the reasons the instance is read-only for in the order
+// they were enabled
+func Reasons() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, len(reasons))
+	for i, r := range reasons {
+		names[i] = r.name
+	}
+	return names
+}
+
+// IsViolation returns true if err is Postgres refusing to write in a read
+// only transaction
+func IsViolation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "read-only transaction")
+}
+
+// Middleware rejects the requests with a method that could change data with
+// a ServiceUnavailableError while the instance is in read-only mode, except
+// the ones whose path starts with one of the given prefixes. It belongs below
+// the error handler in the middleware chain.
+func Middleware(except ...string) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			switch req.Method {
+			case "GET", "HEAD", "OPTIONS":
+				return h(ctx, rw, req)
+			}
+			for _, prefix := range except {
+				if strings.HasPrefix(req.URL.Path, prefix) {
+					return h(ctx, rw, req)
+				}
+			}
+			if on, msg := Enabled(); on {
+				return errors.NewServiceUnavailableError(msg)
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
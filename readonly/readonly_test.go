@@ -0,0 +1,64 @@
+package readonly_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/readonly"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReasons(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	defer readonly.Disable(readonly.Maintenance)
+	defer readonly.Disable(readonly.Restore)
+
+	on, _ := readonly.Enabled()
+	assert.False(t, on)
+
+	readonly.Enable(readonly.Maintenance, "down for maintenance")
+	readonly.Enable(readonly.Restore, "restoring a backup")
+	on, msg := readonly.Enabled()
+	assert.True(t, on)
+	assert.Equal(t, "down for maintenance", msg)
+	assert.Equal(t, []string{readonly.Maintenance, readonly.Restore}, readonly.Reasons())
+
+	readonly.Disable(readonly.Maintenance)
+	on, msg = readonly.Enabled()
+	assert.True(t, on)
+	assert.Equal(t, "restoring a backup", msg)
+
+	readonly.Disable(readonly.Restore)
+	on, _ = readonly.Enabled()
+	assert.False(t, on)
+	assert.Empty(t, readonly.Reasons())
+}
+
+func TestMiddleware(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	defer readonly.Disable(readonly.Maintenance)
+
+	h := readonly.Middleware("/api/maintenance")(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		return nil
+	})
+	call := func(method, path string) error {
+		req, err := http.NewRequest(method, path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h(context.Background(), httptest.NewRecorder(), req)
+	}
+
+	assert.Nil(t, call("POST", "/api/workitems"))
+	readonly.Enable(readonly.Maintenance, "down for maintenance")
+	assert.Nil(t, call("GET", "/api/workitems"))
+	assert.Nil(t, call("PUT", "/api/maintenance"))
+	err := call("PATCH", "/api/workitems/1")
+	assert.IsType(t, errors.ServiceUnavailableError{}, err)
+	assert.Equal(t, "down for maintenance", err.Error())
+}
@@ -0,0 +1,109 @@
+// Package redirect stores where resources that were merged into others or
+// moved went, so that their old URLs keep resolving. The load paths of the
+// resources consult it when they don't find what was asked for.
+package redirect
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+)
+
+// Kinds of redirected resources
+const (
+	// KindWorkItem redirects the IDs and keys of work items to the IDs of
+	// the work items they were merged into or their own IDs when the keys
+	// changed on moves to other projects
+	KindWorkItem = "workitems"
+)
+
+// Redirect sends requests for a resource to another one of the same kind
+type Redirect struct {
+	Kind      string `gorm:"primary_key"`
+	Source    string `gorm:"primary_key"`
+	Target    string
+	CreatedAt time.Time
+}
+
+// TableName implements gorm.tabler
+func (r Redirect) TableName() string {
+	return "redirects"
+}
+
+// Repository encapsulates storage & retrieval of redirects
+type Repository interface {
+	Add(ctx context.Context, kind, source, target string) error
+	Resolve(ctx context.Context, kind, source string) (string, error)
+	Remove(ctx context.Context, kind string, sources ...string) error
+}
+
+// NewRedirectRepository creates a new storage type.
+func NewRedirectRepository(db *gorm.DB) Repository {
+	return &GormRedirectRepository{db: db}
+}
+
+// GormRedirectRepository is the implementation of the storage interface for
+// redirects.
+type GormRedirectRepository struct {
+	db *gorm.DB
+}
+
+// Add redirects the source to the target, replacing an earlier redirect of
+// the source. Redirects to the source are changed to point to the target, so
+// resolving never needs more than one step.
+// returns BadParameterError or InternalError
+func (m *GormRedirectRepository) Add(ctx context.Context, kind, source, target string) error {
+	defer goa.MeasureSince([]string{"goa", "db", "redirect", "add"}, time.Now())
+
+	if source == "" || source == target {
+		return errors.NewBadParameterError("source", source).Expected("not empty and different from the target")
+	}
+	// a target that was redirected before is a resource again
+	if err := m.db.Exec("DELETE FROM redirects WHERE kind = ? AND source = ?", kind, target).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if err := m.db.Exec("UPDATE redirects SET target = ? WHERE kind = ? AND target = ?", target, kind, source).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	tx := m.db.Exec(`INSERT INTO redirects (kind, source, target, created_at) VALUES (?, ?, ?, now())
+		ON CONFLICT (kind, source) DO UPDATE SET target = excluded.target, created_at = now()`, kind, source, target)
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	return nil
+}
+
+// Resolve returns the target the source of the given kind is redirected to
+// returns NotFoundError or InternalError
+func (m *GormRedirectRepository) Resolve(ctx context.Context, kind, source string) (string, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "redirect", "resolve"}, time.Now())
+
+	var r Redirect
+	tx := m.db.Where("kind = ? AND source = ?", kind, source).First(&r)
+	if tx.RecordNotFound() {
+		return "", errors.NewNotFoundError("redirect", source)
+	}
+	if tx.Error != nil {
+		return "", errors.NewInternalError(tx.Error.Error())
+	}
+	return r.Target, nil
+}
+
+// Remove removes the redirects of the given sources, e.g. when a merged work
+// item is restored. Sources without redirect are ignored.
+// returns InternalError
+func (m *GormRedirectRepository) Remove(ctx context.Context, kind string, sources ...string) error {
+	defer goa.MeasureSince([]string{"goa", "db", "redirect", "remove"}, time.Now())
+
+	if len(sources) == 0 {
+		return nil
+	}
+	if err := m.db.Exec("DELETE FROM redirects WHERE kind = ? AND source IN (?)", kind, sources).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package redirect_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/redirect"
+	"github.com/almighty/almighty-core/resource"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestRedirectRepository struct {
+	gormsupport.DBTestSuite
+}
+
+func TestRunRedirectRepository(t *testing.T) {
+	suite.Run(t, &TestRedirectRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestRedirectRepository) TestRedirects() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	tx := test.DB.Begin()
+	defer tx.Rollback()
+	repo := redirect.NewRedirectRepository(tx)
+	a, b, c := uuid.NewV4().String(), uuid.NewV4().String(), uuid.NewV4().String()
+
+	_, err := repo.Resolve(ctx, redirect.KindWorkItem, a)
+	assert.IsType(t, errors.NotFoundError{}, err)
+	assert.IsType(t, errors.BadParameterError{}, repo.Add(ctx, redirect.KindWorkItem, a, a))
+
+	// a is merged into b, which is merged into c: both resolve in one step
+	require.Nil(t, repo.Add(ctx, redirect.KindWorkItem, a, b))
+	require.Nil(t, repo.Add(ctx, redirect.KindWorkItem, b, c))
+	target, err := repo.Resolve(ctx, redirect.KindWorkItem, a)
+	require.Nil(t, err)
+	assert.Equal(t, c, target)
+	target, err = repo.Resolve(ctx, redirect.KindWorkItem, b)
+	require.Nil(t, err)
+	assert.Equal(t, c, target)
+	_, err = repo.Resolve(ctx, "projects", a)
+	assert.IsType(t, errors.NotFoundError{}, err)
+
+	// redirecting to a source makes it a resource again
+	require.Nil(t, repo.Add(ctx, redirect.KindWorkItem, c, a))
+	_, err = repo.Resolve(ctx, redirect.KindWorkItem, a)
+	assert.IsType(t, errors.NotFoundError{}, err)
+	target, err = repo.Resolve(ctx, redirect.KindWorkItem, b)
+	require.Nil(t, err)
+	assert.Equal(t, a, target)
+
+	require.Nil(t, repo.Remove(ctx, redirect.KindWorkItem, b, c))
+	_, err = repo.Resolve(ctx, redirect.KindWorkItem, b)
+	assert.IsType(t, errors.NotFoundError{}, err)
+}
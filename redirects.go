@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/redirect"
+	"github.com/goadesign/goa"
+)
+
+// RedirectMovedResources is a middleware that redirects requests for work
+// items that were merged into others, or for keys that changed when their work
+// items moved to other projects, to the URLs of the work items. Reads are
+// redirected permanently (301), other requests with 307 so that clients
+// repeat them with their method and body. It must run after InjectViewer,
+// redirects to work items the viewer can't see aren't followed.
+func RedirectMovedResources(db application.DB) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			request := goa.ContextRequest(ctx)
+			ref := request.Params.Get("id")
+			if ref == "" || !strings.Contains(req.URL.Path, "/workitems/"+ref) {
+				return h(ctx, rw, req)
+			}
+			var target string
+			err := application.Transactional(requestDB(ctx, db), func(appl application.Application) error {
+				id, err := appl.Redirects().Resolve(ctx, redirect.KindWorkItem, ref)
+				if err != nil {
+					if _, ok := err.(errors.NotFoundError); ok {
+						return nil
+					}
+					return err
+				}
+				wi, err := appl.WorkItems().Load(ctx, id)
+				if err != nil {
+					if _, ok := err.(errors.NotFoundError); ok {
+						return nil
+					}
+					return err
+				}
+				target = wi.ID
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if target == "" {
+				return h(ctx, rw, req)
+			}
+			location := AbsoluteURL(request, strings.Replace(req.URL.Path, "/workitems/"+ref, "/workitems/"+target, 1))
+			if req.URL.RawQuery != "" {
+				location += "?" + req.URL.RawQuery
+			}
+			status := http.StatusMovedPermanently
+			if req.Method != "GET" && req.Method != "HEAD" {
+				status = http.StatusTemporaryRedirect
+			}
+			rw.Header().Set("Location", location)
+			rw.WriteHeader(status)
+			return nil
+		}
+	}
+}
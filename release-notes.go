@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/release"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+)
+
+// ReleaseNotesController implements the release-notes resource.
+type ReleaseNotesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewReleaseNotesController creates a release-notes controller.
+func NewReleaseNotesController(service *goa.Service, db application.DB) *ReleaseNotesController {
+	return &ReleaseNotesController{Controller: service.NewController("ReleaseNotesController"), db: db}
+}
+
+// Generate runs the generate action.
+func (c *ReleaseNotesController) Generate(ctx *app.GenerateReleaseNotesContext) error {
+	sources := 0
+	for _, set := range []bool{ctx.Release != nil, ctx.Iteration != nil, ctx.Project != nil} {
+		if set {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("release, iteration or project", nil).Expected("exactly one of them"))
+	}
+	if ctx.Project != nil && (ctx.From == nil || ctx.To == nil) {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("from, to", nil).Expected("a date range for the project"))
+	}
+	tmpl := ""
+	if ctx.Template != nil {
+		tmpl = *ctx.Template
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		var title string
+		var exp criteria.Expression
+		switch {
+		case ctx.Release != nil:
+			r, err := appl.Releases().Load(ctx, *ctx.Release)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			title = "Release " + r.Version
+			exp = criteria.Equals(criteria.Field(workitem.SystemRelease), criteria.Literal(r.ID.String()))
+		case ctx.Iteration != nil:
+			i, err := appl.Iterations().Load(ctx, *ctx.Iteration)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			title = i.Name
+			exp = criteria.Equals(criteria.Field(workitem.SystemIteration), criteria.Literal(i.ID.String()))
+		default:
+			_, err := appl.Projects().Load(ctx, *ctx.Project)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			iterations, err := appl.Iterations().List(ctx, *ctx.Project)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			// past ranges are likely covered by archived iterations
+			archived, err := appl.Iterations().ListArchived(ctx, *ctx.Project)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			iterations = append(iterations, archived...)
+			title = fmt.Sprintf("Release notes %s - %s", ctx.From.Format("2006-01-02"), ctx.To.Format("2006-01-02"))
+			exp = iterationsInRangeCriteria(iterations, *ctx.From, *ctx.To)
+		}
+
+		items, _, err := appl.WorkItems().List(ctx, workitem.WithArchived(exp), nil, nil)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		notes, err := release.NewNotes(title, items, ctx.GroupBy)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		s, err := notes.Render(tmpl)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK([]byte(s))
+	})
+}
+
+// iterationsInRangeCriteria matches the work items of all iterations that
+// overlap with the given date range. Iterations without dates are skipped.
+func iterationsInRangeCriteria(iterations []*iteration.Iteration, from time.Time, to time.Time) criteria.Expression {
+	var exp criteria.Expression
+	for _, i := range iterations {
+		if i.StartAt == nil && i.EndAt == nil {
+			continue
+		}
+		if i.StartAt != nil && i.StartAt.After(to) {
+			continue
+		}
+		if i.EndAt != nil && i.EndAt.Before(from) {
+			continue
+		}
+		e := criteria.Equals(criteria.Field(workitem.SystemIteration), criteria.Literal(i.ID.String()))
+		if exp == nil {
+			exp = e
+		} else {
+			exp = criteria.Or(exp, e)
+		}
+	}
+	if exp == nil {
+		return criteria.Literal(false)
+	}
+	return exp
+}
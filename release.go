@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/release"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ReleaseController implements the release resource.
+type ReleaseController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewReleaseController creates a release controller.
+func NewReleaseController(service *goa.Service, db application.DB) *ReleaseController {
+	return &ReleaseController{Controller: service.NewController("ReleaseController"), db: db}
+}
+
+// Show runs the show action.
+func (c *ReleaseController) Show(ctx *app.ShowReleaseContext) error {
+	id, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		r, err := appl.Releases().Load(ctx, id)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		data, err := convertReleaseWithCompletion(ctx, appl, ctx.RequestData, r)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		res := &app.ReleaseSingle{
+			Data: data,
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Notes runs the notes action.
+func (c *ReleaseController) Notes(ctx *app.NotesReleaseContext) error {
+	id, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		r, err := appl.Releases().Load(ctx, id)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		notes := r.Notes
+		if !r.IsPublished() {
+			items, err := loadReleaseWorkItems(ctx, appl, r.ID)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			notes = release.GenerateNotes(*r, items)
+		}
+		return ctx.OK([]byte(notes))
+	})
+}
+
+// Publish runs the publish action.
+func (c *ReleaseController) Publish(ctx *app.PublishReleaseContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	id, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		r, err := appl.Releases().Load(ctx, id)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		items, err := loadReleaseWorkItems(ctx, appl, r.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		total, closed := release.Completion(items)
+		r, err = appl.Releases().Publish(ctx, r.ID, release.GenerateNotes(*r, items), total, closed)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		res := &app.ReleaseSingle{
+			Data: ConvertRelease(ctx.RequestData, r, r.TotalCount, r.ClosedCount),
+		}
+		return ctx.OK(res)
+	})
+}
+
+// loadReleaseWorkItems returns all work items planned for the given release
+func loadReleaseWorkItems(ctx context.Context, appl application.Application, releaseID uuid.UUID) ([]*app.WorkItem, error) {
+	exp := criteria.Equals(criteria.Field(workitem.SystemRelease), criteria.Literal(releaseID.String()))
+	items, _, err := appl.WorkItems().List(ctx, exp, nil, nil)
+	return items, err
+}
+
+// convertReleaseWithCompletion converts the release and computes its
+// completion from its work items unless the release is already published.
+func convertReleaseWithCompletion(ctx context.Context, appl application.Application, request *goa.RequestData, r *release.Release) (*app.Release, error) {
+	if r.IsPublished() {
+		return ConvertRelease(request, r, r.TotalCount, r.ClosedCount), nil
+	}
+	items, err := loadReleaseWorkItems(ctx, appl, r.ID)
+	if err != nil {
+		return nil, err
+	}
+	total, closed := release.Completion(items)
+	return ConvertRelease(request, r, total, closed), nil
+}
+
+// ConvertRelease converts between internal and external REST representation
+func ConvertRelease(request *goa.RequestData, r *release.Release, total int, closed int) *app.Release {
+	projectType := "projects"
+	projectID := r.ProjectID.String()
+	completion := release.Percentage(total, closed)
+
+	selfURL := AbsoluteURL(request, app.ReleaseHref(r.ID))
+	projectSelfURL := AbsoluteURL(request, app.ProjectHref(projectID))
+	filter := fmt.Sprintf(`{"%s":"%s"}`, workitem.SystemRelease, r.ID)
+	workItemsRelatedURL := AbsoluteURL(request, "/api/workitems") + "?filter=" + url.QueryEscape(filter)
+
+	return &app.Release{
+		Type: "releases",
+		ID:   &r.ID,
+		Attributes: &app.ReleaseAttributes{
+			Version:     &r.Version,
+			TargetDate:  r.TargetDate,
+			Status:      &r.Status,
+			PublishedAt: r.PublishedAt,
+			TotalCount:  &total,
+			ClosedCount: &closed,
+			Completion:  &completion,
+		},
+		Relationships: &app.ReleaseRelations{
+			Project: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: &projectType,
+					ID:   &projectID,
+				},
+				Links: &app.GenericLinks{
+					Self: &projectSelfURL,
+				},
+			},
+			Workitems: &app.RelationGeneric{
+				Links: &app.GenericLinks{
+					Related: &workItemsRelatedURL,
+				},
+			},
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
+
+// ConvertReleaseSimple converts a simple release ID into a Generic Reletionship
+func ConvertReleaseSimple(request *goa.RequestData, id interface{}) *app.GenericData {
+	t := "releases"
+	i := fmt.Sprint(id)
+	selfURL := AbsoluteURL(request, app.ReleaseHref(i))
+	return &app.GenericData{
+		Type: &t,
+		ID:   &i,
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
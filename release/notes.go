@@ -0,0 +1,175 @@
+package release
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"text/template"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/workitem"
+)
+
+// Grouping options for release notes
+const (
+	GroupByType  = "type"
+	GroupByLabel = "label"
+)
+
+// unlabeledGroup collects the work items without labels when grouping by label
+const unlabeledGroup = "unlabeled"
+
+// DefaultNotesTemplate is the markdown template used when no custom template is given
+const DefaultNotesTemplate = `# {{.Title}}
+{{range .Groups}}
+## {{.Name}}
+
+{{range .Items}}- #{{.ID}} {{.Title}}
+{{end}}{{end}}`
+
+// Notes holds the data release notes templates are rendered with
+type Notes struct {
+	Title  string
+	Groups []NotesGroup
+}
+
+// NotesGroup is a named group of work items in the release notes
+type NotesGroup struct {
+	Name  string
+	Items []NotesItem
+}
+
+// NotesItem is a single work item in the release notes
+type NotesItem struct {
+	ID     string
+	Type   string
+	Title  string
+	Labels []string
+}
+
+// Completion returns the number of work items and how many of them are closed
+func Completion(items []*app.WorkItem) (total int, closed int) {
+	for _, wi := range items {
+		if isClosed(wi) {
+			closed++
+		}
+	}
+	return len(items), closed
+}
+
+// Percentage returns the completion in percent, a release without work items
+// is not complete.
+func Percentage(total int, closed int) int {
+	if total == 0 {
+		return 0
+	}
+	return closed * 100 / total
+}
+
+// NewNotes collects the closed work items into groups by type or by label.
+// Groups are sorted by name, the items in each group by ID. A work item with
+// several labels is listed in each of its label groups.
+// returns BadParameterError for an unknown grouping
+func NewNotes(title string, items []*app.WorkItem, groupBy string) (*Notes, error) {
+	if groupBy == "" {
+		groupBy = GroupByType
+	}
+	if groupBy != GroupByType && groupBy != GroupByLabel {
+		return nil, errors.NewBadParameterError("group-by", groupBy).Expected([]string{GroupByType, GroupByLabel})
+	}
+	closed := []*app.WorkItem{}
+	for _, wi := range items {
+		if isClosed(wi) {
+			closed = append(closed, wi)
+		}
+	}
+	sort.Sort(byID(closed))
+
+	groups := map[string][]NotesItem{}
+	for _, wi := range closed {
+		item := NotesItem{
+			ID:     wi.ID,
+			Type:   wi.Type,
+			Title:  fmt.Sprint(wi.Fields[workitem.SystemTitle]),
+			Labels: labels(wi),
+		}
+		if groupBy == GroupByType {
+			groups[wi.Type] = append(groups[wi.Type], item)
+			continue
+		}
+		if len(item.Labels) == 0 {
+			groups[unlabeledGroup] = append(groups[unlabeledGroup], item)
+		}
+		for _, l := range item.Labels {
+			groups[l] = append(groups[l], item)
+		}
+	}
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	notes := Notes{Title: title}
+	for _, name := range names {
+		notes.Groups = append(notes.Groups, NotesGroup{Name: name, Items: groups[name]})
+	}
+	return &notes, nil
+}
+
+// Render renders the notes with the given text/template, an empty template
+// renders DefaultNotesTemplate.
+// returns BadParameterError if the template is invalid
+func (n Notes) Render(tmpl string) (string, error) {
+	if tmpl == "" {
+		tmpl = DefaultNotesTemplate
+	}
+	t, err := template.New("notes").Parse(tmpl)
+	if err != nil {
+		return "", errors.NewBadParameterError("template", err.Error())
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, n); err != nil {
+		return "", errors.NewBadParameterError("template", err.Error())
+	}
+	return buf.String(), nil
+}
+
+// GenerateNotes renders markdown release notes listing the closed work items
+// of the release grouped by their type
+func GenerateNotes(r Release, items []*app.WorkItem) string {
+	notes, _ := NewNotes("Release "+r.Version, items, GroupByType)
+	// the default template always renders
+	s, _ := notes.Render(DefaultNotesTemplate)
+	return s
+}
+
+func isClosed(wi *app.WorkItem) bool {
+	return wi.Fields[workitem.SystemState] == workitem.SystemStateClosed
+}
+
+func labels(wi *app.WorkItem) []string {
+	var ls []string
+	switch v := wi.Fields[workitem.SystemLabels].(type) {
+	case []interface{}:
+		for _, l := range v {
+			ls = append(ls, fmt.Sprint(l))
+		}
+	case []string:
+		ls = append(ls, v...)
+	}
+	return ls
+}
+
+// byID sorts work items by their numeric ID
+type byID []*app.WorkItem
+
+func (s byID) Len() int      { return len(s) }
+func (s byID) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byID) Less(i, j int) bool {
+	a, _ := strconv.ParseUint(s[i].ID, 10, 64)
+	b, _ := strconv.ParseUint(s[j].ID, 10, 64)
+	return a < b
+}
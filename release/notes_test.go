@@ -0,0 +1,94 @@
+package release_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/release"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newWorkItem(id, witype, title, state string) *app.WorkItem {
+	return &app.WorkItem{
+		ID:   id,
+		Type: witype,
+		Fields: map[string]interface{}{
+			workitem.SystemTitle: title,
+			workitem.SystemState: state,
+		},
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	items := []*app.WorkItem{
+		newWorkItem("1", workitem.SystemBug, "a", workitem.SystemStateClosed),
+		newWorkItem("2", workitem.SystemBug, "b", workitem.SystemStateOpen),
+		newWorkItem("3", workitem.SystemFeature, "c", workitem.SystemStateClosed),
+		newWorkItem("4", workitem.SystemFeature, "d", workitem.SystemStateInProgress),
+	}
+	total, closed := release.Completion(items)
+	assert.Equal(t, 4, total)
+	assert.Equal(t, 2, closed)
+	assert.Equal(t, 50, release.Percentage(total, closed))
+	assert.Equal(t, 0, release.Percentage(0, 0))
+}
+
+func TestGenerateNotes(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	items := []*app.WorkItem{
+		newWorkItem("10", workitem.SystemFeature, "Dark mode", workitem.SystemStateClosed),
+		newWorkItem("9", workitem.SystemBug, "Crash on login", workitem.SystemStateClosed),
+		newWorkItem("2", workitem.SystemBug, "Typo", workitem.SystemStateClosed),
+		newWorkItem("3", workitem.SystemBug, "Not done yet", workitem.SystemStateOpen),
+	}
+	notes := release.GenerateNotes(release.Release{Version: "1.0"}, items)
+	expected := "# Release 1.0\n" +
+		"\n## system.bug\n\n- #2 Typo\n- #9 Crash on login\n" +
+		"\n## system.feature\n\n- #10 Dark mode\n"
+	assert.Equal(t, expected, notes)
+}
+
+func TestNotesGroupedByLabel(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	ui := newWorkItem("1", workitem.SystemBug, "Button color", workitem.SystemStateClosed)
+	ui.Fields[workitem.SystemLabels] = []interface{}{"ui", "backend"}
+	other := newWorkItem("2", workitem.SystemBug, "Typo", workitem.SystemStateClosed)
+
+	notes, err := release.NewNotes("Sprint 1", []*app.WorkItem{ui, other}, release.GroupByLabel)
+	require.Nil(t, err)
+	require.Len(t, notes.Groups, 3)
+	assert.Equal(t, "backend", notes.Groups[0].Name)
+	assert.Equal(t, "ui", notes.Groups[1].Name)
+	assert.Equal(t, "unlabeled", notes.Groups[2].Name)
+	assert.Equal(t, "2", notes.Groups[2].Items[0].ID)
+
+	_, err = release.NewNotes("Sprint 1", nil, "assignee")
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
+
+func TestRenderNotesWithTemplate(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	items := []*app.WorkItem{newWorkItem("7", workitem.SystemBug, "Crash", workitem.SystemStateClosed)}
+	notes, err := release.NewNotes("1.0", items, release.GroupByType)
+	require.Nil(t, err)
+
+	s, err := notes.Render(`{{.Title}}:{{range .Groups}}{{range .Items}} {{.ID}}{{end}}{{end}}`)
+	require.Nil(t, err)
+	assert.Equal(t, "1.0: 7", s)
+
+	_, err = notes.Render(`{{.Unclosed`)
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
@@ -0,0 +1,146 @@
+package release
+
+import (
+	"log"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Release status values
+const (
+	StatusPlanned   = "planned"
+	StatusPublished = "published"
+)
+
+// Release describes a version of a project that ships a set of work items
+type Release struct {
+	gormsupport.Lifecycle
+	ID        uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	ProjectID uuid.UUID `sql:"type:uuid"`
+	// Version is the name of the release, e.g. "1.2.0"
+	Version    string
+	TargetDate *time.Time
+	Status     string
+	// The following fields are frozen when the release is published
+	PublishedAt *time.Time
+	Notes       string
+	TotalCount  int
+	ClosedCount int
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m Release) TableName() string {
+	return "releases"
+}
+
+// IsPublished returns true if the content of the release is frozen
+func (m Release) IsPublished() bool {
+	return m.Status == StatusPublished
+}
+
+// Repository describes interactions with releases
+type Repository interface {
+	Create(ctx context.Context, r *Release) error
+	Load(ctx context.Context, id uuid.UUID) (*Release, error)
+	List(ctx context.Context, projectID uuid.UUID) ([]*Release, error)
+	Publish(ctx context.Context, id uuid.UUID, notes string, totalCount int, closedCount int) (*Release, error)
+}
+
+// NewReleaseRepository creates a new storage type.
+func NewReleaseRepository(db *gorm.DB) Repository {
+	return &GormReleaseRepository{db: db}
+}
+
+// GormReleaseRepository is the implementation of the storage interface for releases.
+type GormReleaseRepository struct {
+	db *gorm.DB
+}
+
+// Create creates a new record.
+// returns BadParameterError or InternalError
+func (m *GormReleaseRepository) Create(ctx context.Context, r *Release) error {
+	defer goa.MeasureSince([]string{"goa", "db", "release", "create"}, time.Now())
+
+	if r.Version == "" {
+		return errors.NewBadParameterError("version", r.Version).Expected("not empty")
+	}
+	r.ID = uuid.NewV4()
+	r.Status = StatusPlanned
+
+	err := m.db.Create(r).Error
+	if err != nil {
+		if gormsupport.IsUniqueViolation(err, "releases_project_id_version_idx") {
+			return errors.NewBadParameterError("version", r.Version).Expected("unique within the project")
+		}
+		goa.LogError(ctx, "error adding Release", "error", err.Error())
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// Load a single release regardless of parent
+// returns NotFoundError or InternalError
+func (m *GormReleaseRepository) Load(ctx context.Context, id uuid.UUID) (*Release, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "release", "get"}, time.Now())
+	var obj Release
+
+	tx := m.db.Where("id = ?", id).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("release", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	if !workitem.ContextViewer(ctx).CanReadProject(obj.ProjectID) {
+		return nil, errors.NewNotFoundError("release", id.String())
+	}
+	return &obj, nil
+}
+
+// List all releases of a single project ordered by their target date
+func (m *GormReleaseRepository) List(ctx context.Context, projectID uuid.UUID) ([]*Release, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "release", "query"}, time.Now())
+	var objs []*Release
+
+	err := m.db.Where("project_id = ?", projectID).Order("target_date, created_at").Find(&objs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return objs, nil
+}
+
+// Publish freezes the notes and completion of the release. A release can only
+// be published once.
+// returns NotFoundError, BadParameterError or InternalError
+func (m *GormReleaseRepository) Publish(ctx context.Context, id uuid.UUID, notes string, totalCount int, closedCount int) (*Release, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "release", "publish"}, time.Now())
+
+	r, err := m.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.IsPublished() {
+		return nil, errors.NewBadParameterError("status", r.Status).Expected(StatusPlanned)
+	}
+	now := time.Now()
+	r.Status = StatusPublished
+	r.PublishedAt = &now
+	r.Notes = notes
+	r.TotalCount = totalCount
+	r.ClosedCount = closedCount
+
+	if err := m.db.Save(r).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	log.Printf("published release %v\n", r.ID)
+	return r, nil
+}
@@ -0,0 +1,82 @@
+package release_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/release"
+	"github.com/almighty/almighty-core/resource"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestReleaseRepository struct {
+	gormsupport.DBTestSuite
+
+	clean     func()
+	projectID uuid.UUID
+}
+
+func TestRunReleaseRepository(t *testing.T) {
+	suite.Run(t, &TestReleaseRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestReleaseRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+
+	p, err := project.NewRepository(test.DB).Create(context.Background(), "release-test-"+uuid.NewV4().String())
+	require.Nil(test.T(), err)
+	test.projectID = p.ID
+}
+
+func (test *TestReleaseRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestReleaseRepository) TestCreateAndList() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := release.NewReleaseRepository(test.DB)
+	assert.IsType(t, errors.BadParameterError{}, repo.Create(context.Background(), &release.Release{ProjectID: test.projectID}))
+
+	r := release.Release{ProjectID: test.projectID, Version: "1.0"}
+	require.Nil(t, repo.Create(context.Background(), &r))
+	assert.Equal(t, release.StatusPlanned, r.Status)
+
+	// versions are unique within a project
+	assert.IsType(t, errors.BadParameterError{}, repo.Create(context.Background(), &release.Release{ProjectID: test.projectID, Version: "1.0"}))
+
+	rs, err := repo.List(context.Background(), test.projectID)
+	require.Nil(t, err)
+	require.Len(t, rs, 1)
+	assert.Equal(t, r.ID, rs[0].ID)
+}
+
+func (test *TestReleaseRepository) TestPublishFreezesRelease() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := release.NewReleaseRepository(test.DB)
+	r := release.Release{ProjectID: test.projectID, Version: "2.0"}
+	require.Nil(t, repo.Create(context.Background(), &r))
+
+	published, err := repo.Publish(context.Background(), r.ID, "# Release 2.0\n", 4, 3)
+	require.Nil(t, err)
+	assert.True(t, published.IsPublished())
+	assert.NotNil(t, published.PublishedAt)
+
+	loaded, err := repo.Load(context.Background(), r.ID)
+	require.Nil(t, err)
+	assert.Equal(t, "# Release 2.0\n", loaded.Notes)
+	assert.Equal(t, 3, loaded.ClosedCount)
+
+	_, err = repo.Publish(context.Background(), r.ID, "changed", 1, 1)
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
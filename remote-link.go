@@ -0,0 +1,165 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/remotelink"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// RemoteLinkController implements the remote-link resource.
+type RemoteLinkController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewRemoteLinkController creates a remote-link controller.
+func NewRemoteLinkController(service *goa.Service, db application.DB) *RemoteLinkController {
+	return &RemoteLinkController{Controller: service.NewController("RemoteLinkController"), db: db}
+}
+
+// Show runs the show action.
+func (c *RemoteLinkController) Show(ctx *app.ShowRemoteLinkContext) error {
+	id, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		l, err := appl.RemoteLinks().Load(ctx, id)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		res := &app.RemoteLinkSingle{
+			Data: ConvertRemoteLink(ctx.RequestData, l),
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Update runs the update action.
+func (c *RemoteLinkController) Update(ctx *app.UpdateRemoteLinkContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	id, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	if ctx.Payload.Data.Attributes.Version == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.version", nil).Expected("not nil"))
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		l, err := appl.RemoteLinks().Load(ctx, id)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		attrs := ctx.Payload.Data.Attributes
+		l.Version = *attrs.Version
+		if attrs.URL != nil {
+			l.URL = *attrs.URL
+		}
+		if attrs.Title != nil {
+			l.Title = *attrs.Title
+		}
+		if attrs.Icon != nil {
+			l.IconURL = *attrs.Icon
+		}
+		if attrs.Relationship != nil {
+			l.Relationship = *attrs.Relationship
+		}
+
+		l, err = appl.RemoteLinks().Save(ctx, *l)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		res := &app.RemoteLinkSingle{
+			Data: ConvertRemoteLink(ctx.RequestData, l),
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Delete runs the delete action.
+func (c *RemoteLinkController) Delete(ctx *app.DeleteRemoteLinkContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	id, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		err := appl.RemoteLinks().Delete(ctx, id)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// RemoteLinkConvertFunc is a open ended function to add additional links/data/relations to a RemoteLink during
+// convertion from internal to API
+type RemoteLinkConvertFunc func(*goa.RequestData, *remotelink.RemoteLink, *app.RemoteLink)
+
+// ConvertRemoteLinks converts between internal and external REST representation
+func ConvertRemoteLinks(request *goa.RequestData, links []*remotelink.RemoteLink, additional ...RemoteLinkConvertFunc) []*app.RemoteLink {
+	var ls = []*app.RemoteLink{}
+	for _, l := range links {
+		ls = append(ls, ConvertRemoteLink(request, l, additional...))
+	}
+	return ls
+}
+
+// ConvertRemoteLink converts between internal and external REST representation
+func ConvertRemoteLink(request *goa.RequestData, l *remotelink.RemoteLink, additional ...RemoteLinkConvertFunc) *app.RemoteLink {
+	workItemType := APIStringTypeWorkItem
+	workItemID := strconv.FormatUint(l.WorkItemID, 10)
+
+	selfURL := AbsoluteURL(request, app.RemoteLinkHref(l.ID))
+	workItemSelfURL := AbsoluteURL(request, app.WorkitemHref(workItemID))
+
+	r := &app.RemoteLink{
+		Type: "remotelinks",
+		ID:   &l.ID,
+		Attributes: &app.RemoteLinkAttributes{
+			URL:          &l.URL,
+			Title:        &l.Title,
+			Icon:         &l.IconURL,
+			Relationship: &l.Relationship,
+			Version:      &l.Version,
+			CreatedAt:    &l.CreatedAt,
+		},
+		Relationships: &app.RemoteLinkRelations{
+			Workitem: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: &workItemType,
+					ID:   &workItemID,
+				},
+				Links: &app.GenericLinks{
+					Self: &workItemSelfURL,
+				},
+			},
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+	for _, add := range additional {
+		add(request, l, r)
+	}
+	return r
+}
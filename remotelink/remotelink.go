@@ -0,0 +1,234 @@
+package remotelink
+
+import (
+	"log"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/convert"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/asaskevich/govalidator"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Relationship kinds describing how a work item relates to the remote resource
+const (
+	RelationshipRelatesTo     = "relates to"
+	RelationshipImplementedBy = "implemented by"
+	RelationshipDocumentedBy  = "documented by"
+	RelationshipBuiltBy       = "built by"
+	RelationshipBlockedBy     = "blocked by"
+)
+
+// Relationships contains all the relationship kinds a remote link can have
+var Relationships = []string{
+	RelationshipRelatesTo,
+	RelationshipImplementedBy,
+	RelationshipDocumentedBy,
+	RelationshipBuiltBy,
+	RelationshipBlockedBy,
+}
+
+// RemoteLink describes a link from a work item to an external resource like a
+// pull request, a document or a CI build.
+type RemoteLink struct {
+	gormsupport.Lifecycle
+	ID uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	// Version for optimistic concurrency control
+	Version      int
+	WorkItemID   uint64
+	URL          string
+	Title        string
+	IconURL      string
+	Relationship string
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m RemoteLink) TableName() string {
+	return "remote_links"
+}
+
+// Ensure RemoteLink implements the Equaler interface
+var _ convert.Equaler = RemoteLink{}
+var _ convert.Equaler = (*RemoteLink)(nil)
+
+// Equal returns true if two RemoteLink objects are equal; otherwise false is returned.
+func (m RemoteLink) Equal(u convert.Equaler) bool {
+	other, ok := u.(RemoteLink)
+	if !ok {
+		return false
+	}
+	if !m.Lifecycle.Equal(other.Lifecycle) {
+		return false
+	}
+	if !uuid.Equal(m.ID, other.ID) {
+		return false
+	}
+	if m.Version != other.Version {
+		return false
+	}
+	if m.WorkItemID != other.WorkItemID {
+		return false
+	}
+	if m.URL != other.URL {
+		return false
+	}
+	if m.Title != other.Title {
+		return false
+	}
+	if m.IconURL != other.IconURL {
+		return false
+	}
+	if m.Relationship != other.Relationship {
+		return false
+	}
+	return true
+}
+
+// CheckValid returns an error if the remote link cannot be stored
+func (m RemoteLink) CheckValid() error {
+	if m.WorkItemID == 0 {
+		return errors.NewBadParameterError("work_item_id", m.WorkItemID)
+	}
+	if !govalidator.IsURL(m.URL) {
+		return errors.NewBadParameterError("url", m.URL).Expected("valid URL")
+	}
+	if m.IconURL != "" && !govalidator.IsURL(m.IconURL) {
+		return errors.NewBadParameterError("icon_url", m.IconURL).Expected("valid URL")
+	}
+	if !IsValidRelationship(m.Relationship) {
+		return errors.NewBadParameterError("relationship", m.Relationship).Expected(Relationships)
+	}
+	return nil
+}
+
+// IsValidRelationship returns true if the given kind is one of the known relationships
+func IsValidRelationship(kind string) bool {
+	for _, r := range Relationships {
+		if r == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Repository describes interactions with remote links
+type Repository interface {
+	Create(ctx context.Context, l *RemoteLink) error
+	Load(ctx context.Context, id uuid.UUID) (*RemoteLink, error)
+	List(ctx context.Context, workItemID uint64) ([]*RemoteLink, error)
+	Save(ctx context.Context, l RemoteLink) (*RemoteLink, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// NewRemoteLinkRepository creates a new storage type.
+func NewRemoteLinkRepository(db *gorm.DB) Repository {
+	return &GormRemoteLinkRepository{db: db}
+}
+
+// GormRemoteLinkRepository is the implementation of the storage interface for remote links.
+type GormRemoteLinkRepository struct {
+	db *gorm.DB
+}
+
+// Create creates a new record.
+// returns BadParameterError or InternalError
+func (m *GormRemoteLinkRepository) Create(ctx context.Context, l *RemoteLink) error {
+	defer goa.MeasureSince([]string{"goa", "db", "remotelink", "create"}, time.Now())
+
+	if l.Relationship == "" {
+		l.Relationship = RelationshipRelatesTo
+	}
+	if err := l.CheckValid(); err != nil {
+		return err
+	}
+	l.ID = uuid.NewV4()
+
+	err := m.db.Create(l).Error
+	if err != nil {
+		goa.LogError(ctx, "error adding RemoteLink", "error", err.Error())
+		return errors.NewInternalError(err.Error())
+	}
+
+	return nil
+}
+
+// Load a single remote link regardless of the work item it belongs to
+// returns NotFoundError or InternalError
+func (m *GormRemoteLinkRepository) Load(ctx context.Context, id uuid.UUID) (*RemoteLink, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "remotelink", "get"}, time.Now())
+	var obj RemoteLink
+
+	tx := m.db.Where("id=?", id).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("remote link", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
+
+// List all remote links related to a single work item
+func (m *GormRemoteLinkRepository) List(ctx context.Context, workItemID uint64) ([]*RemoteLink, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "remotelink", "query"}, time.Now())
+	var objs []*RemoteLink
+
+	err := m.db.Where("work_item_id = ?", workItemID).Order("created_at").Find(&objs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return objs, nil
+}
+
+// Save updates the given remote link in the db. Version must be the same as the one in the stored version
+// returns NotFoundError, BadParameterError, VersionConflictError or InternalError
+func (m *GormRemoteLinkRepository) Save(ctx context.Context, l RemoteLink) (*RemoteLink, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "remotelink", "save"}, time.Now())
+
+	if err := l.CheckValid(); err != nil {
+		return nil, err
+	}
+	res := RemoteLink{}
+	tx := m.db.Where("id=?", l.ID).First(&res)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("remote link", l.ID.String())
+	}
+	if err := tx.Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	oldVersion := l.Version
+	l.Version++
+	tx = tx.Where("Version = ?", oldVersion).Save(&l)
+	if err := tx.Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return nil, errors.NewVersionConflictError("version conflict")
+	}
+	log.Printf("updated remote link to %v\n", l)
+	return &l, nil
+}
+
+// Delete deletes the remote link with the given id
+// returns NotFoundError or InternalError
+func (m *GormRemoteLinkRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "remotelink", "delete"}, time.Now())
+
+	if id == uuid.Nil {
+		return errors.NewNotFoundError("remote link", id.String())
+	}
+	tx := m.db.Delete(RemoteLink{ID: id})
+	if err := tx.Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("remote link", id.String())
+	}
+	return nil
+}
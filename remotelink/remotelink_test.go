@@ -0,0 +1,122 @@
+package remotelink_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/remotelink"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestRemoteLinkRepository struct {
+	gormsupport.DBTestSuite
+
+	clean      func()
+	workItemID uint64
+}
+
+func TestRunRemoteLinkRepository(t *testing.T) {
+	suite.Run(t, &TestRemoteLinkRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestRemoteLinkRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+
+	wi, err := workitem.NewWorkItemRepository(test.DB).Create(
+		context.Background(), workitem.SystemBug,
+		map[string]interface{}{
+			workitem.SystemTitle: "Title",
+			workitem.SystemState: workitem.SystemStateNew,
+		}, "xx")
+	require.Nil(test.T(), err)
+	test.workItemID, err = workitem.ParseWorkItemIDToUint64(wi.ID)
+	require.Nil(test.T(), err)
+}
+
+func (test *TestRemoteLinkRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestRemoteLinkRepository) TestCreateAndLoadRemoteLink() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := remotelink.NewRemoteLinkRepository(test.DB)
+	l := remotelink.RemoteLink{
+		WorkItemID: test.workItemID,
+		URL:        "https://github.com/almighty/almighty-core/pull/1",
+		Title:      "Fix the bug",
+	}
+	err := repo.Create(context.Background(), &l)
+	require.Nil(t, err)
+	assert.NotEqual(t, uuid.Nil, l.ID)
+	// unset relationship falls back to the default one
+	assert.Equal(t, remotelink.RelationshipRelatesTo, l.Relationship)
+
+	loaded, err := repo.Load(context.Background(), l.ID)
+	require.Nil(t, err)
+	assert.Equal(t, l.URL, loaded.URL)
+	assert.Equal(t, l.Title, loaded.Title)
+}
+
+func (test *TestRemoteLinkRepository) TestCreateFailsForInvalidValues() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := remotelink.NewRemoteLinkRepository(test.DB)
+
+	err := repo.Create(context.Background(), &remotelink.RemoteLink{WorkItemID: test.workItemID, URL: "not a url"})
+	assert.IsType(t, errors.BadParameterError{}, err)
+
+	err = repo.Create(context.Background(), &remotelink.RemoteLink{WorkItemID: test.workItemID, URL: "http://example.com", Relationship: "owns"})
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
+
+func (test *TestRemoteLinkRepository) TestListRemoteLinksByWorkItem() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := remotelink.NewRemoteLinkRepository(test.DB)
+	for _, url := range []string{"http://example.com/a", "http://example.com/b"} {
+		err := repo.Create(context.Background(), &remotelink.RemoteLink{
+			WorkItemID:   test.workItemID,
+			URL:          url,
+			Relationship: remotelink.RelationshipDocumentedBy,
+		})
+		require.Nil(t, err)
+	}
+
+	ls, err := repo.List(context.Background(), test.workItemID)
+	require.Nil(t, err)
+	assert.Len(t, ls, 2)
+}
+
+func (test *TestRemoteLinkRepository) TestSaveAndDeleteRemoteLink() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := remotelink.NewRemoteLinkRepository(test.DB)
+	l := remotelink.RemoteLink{WorkItemID: test.workItemID, URL: "http://example.com/build/1"}
+	require.Nil(t, repo.Create(context.Background(), &l))
+
+	l.Relationship = remotelink.RelationshipBuiltBy
+	saved, err := repo.Save(context.Background(), l)
+	require.Nil(t, err)
+	assert.Equal(t, 1, saved.Version)
+
+	// saving the outdated version is a conflict
+	_, err = repo.Save(context.Background(), l)
+	assert.IsType(t, errors.VersionConflictError{}, err)
+
+	require.Nil(t, repo.Delete(context.Background(), l.ID))
+	_, err = repo.Load(context.Background(), l.ID)
+	assert.IsType(t, errors.NotFoundError{}, err)
+}
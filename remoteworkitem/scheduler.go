@@ -1,8 +1,12 @@
 package remoteworkitem
 
 import (
+	"encoding/json"
 	"log"
 
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/job"
 	"github.com/almighty/almighty-core/models"
 	"github.com/jinzhu/gorm"
 	"github.com/robfig/cron"
@@ -25,9 +29,13 @@ type Scheduler struct {
 
 var cr *cron.Cron
 
+// importJobKind is the kind of the jobs importing the items of a tracker query
+const importJobKind = "remoteworkitem.import"
+
 // NewScheduler creates a new Scheduler
 func NewScheduler(db *gorm.DB) *Scheduler {
 	s := Scheduler{db: db}
+	job.Register(importJobKind, s.importItems)
 	return &s
 }
 
@@ -48,25 +56,43 @@ func (s *Scheduler) ScheduleAllQueries() {
 
 	trackerQueries := fetchTrackerQueries(s.db)
 	for _, tq := range trackerQueries {
+		tq := tq
 		cr.AddFunc(tq.Schedule, func() {
-			tr := lookupProvider(tq)
-			for i := range tr.Fetch() {
-				models.Transactional(s.db, func(tx *gorm.DB) error {
-					// Save the remote items in a 'temporary' table.
-					err := upload(tx, tq.TrackerID, i)
-					if err != nil {
-						return err
-					}
-					// Convert the remote item into a local work item and persist in the DB.
-					_, err = convert(tx, tq.TrackerID, i, tq.TrackerType)
-					return err
-				})
+			if _, err := job.NewJobRepository(s.db).Enqueue(context.Background(), importJobKind, tq); err != nil {
+				log.Printf("Failed to enqueue import of tracker %d: %s\n", tq.TrackerID, err.Error())
 			}
 		})
 	}
 	cr.Start()
 }
 
+// importItems fetches the items of a tracker query and converts them into
+// local work items, it is run as a background job
+func (s *Scheduler) importItems(ctx context.Context, payload []byte) error {
+	var tq trackerSchedule
+	if err := json.Unmarshal(payload, &tq); err != nil {
+		return err
+	}
+	tr := lookupProvider(tq)
+	if tr == nil {
+		log.Printf("Unknown tracker type %s\n", tq.TrackerType)
+		return nil
+	}
+	for i := range tr.Fetch() {
+		models.Transactional(s.db, func(tx *gorm.DB) error {
+			// Save the remote items in a 'temporary' table.
+			err := upload(tx, tq.TrackerID, i)
+			if err != nil {
+				return err
+			}
+			// Convert the remote item into a local work item and persist in the DB.
+			_, err = convert(tx, tq.TrackerID, i, tq.TrackerType)
+			return err
+		})
+	}
+	return nil
+}
+
 func fetchTrackerQueries(db *gorm.DB) []trackerSchedule {
 	tsList := []trackerSchedule{}
 	err := db.Table("tracker_queries").Select("trackers.id as tracker_id, trackers.url, trackers.type as tracker_type, tracker_queries.query, tracker_queries.schedule").Joins("left join trackers on tracker_queries.tracker_id = trackers.id").Where("trackers.deleted_at is NULL AND tracker_queries.deleted_at is NULL").Scan(&tsList).Error
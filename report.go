@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	query "github.com/almighty/almighty-core/query/simple"
+	"github.com/almighty/almighty-core/report"
+	"github.com/goadesign/goa"
+)
+
+// ReportController implements the report resource.
+type ReportController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewReportController creates a report controller.
+func NewReportController(service *goa.Service, db application.DB) *ReportController {
+	return &ReportController{Controller: service.NewController("ReportController"), db: db}
+}
+
+// Show runs the show action.
+func (c *ReportController) Show(ctx *app.ShowReportContext) error {
+	exp, err := query.Parse(ctx.Filter)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(fmt.Sprintf("could not parse filter: %s", err.Error())))
+		return ctx.BadRequest(jerrors)
+	}
+	spec := report.Spec{
+		Dimensions: splitList(ctx.Dimensions),
+		Measures:   splitList(ctx.Measures),
+		Filter:     exp,
+	}
+	if len(spec.Measures) == 0 {
+		spec.Measures = []string{report.MeasureCount}
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		result, err := appl.Reports().Run(ctx, spec)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.Report{
+			Dimensions: spec.Dimensions,
+			Measures:   spec.Measures,
+			Rows:       make([]*app.ReportRow, 0, len(result.Rows)),
+			Truncated:  result.Truncated,
+		}
+		if res.Dimensions == nil {
+			res.Dimensions = []string{}
+		}
+		for _, row := range result.Rows {
+			res.Rows = append(res.Rows, &app.ReportRow{Dimensions: row.Dimensions, Measures: row.Measures})
+		}
+		return ctx.OK(res)
+	})
+}
+
+// splitList returns the trimmed non-empty elements of a comma separated
+// parameter
+func splitList(param *string) []string {
+	if param == nil {
+		return nil
+	}
+	var res []string
+	for _, s := range strings.Split(*param, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			res = append(res, s)
+		}
+	}
+	return res
+}
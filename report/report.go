@@ -0,0 +1,301 @@
+// Package report aggregates work items along client chosen dimensions for
+// charts. The dimensions and measures are validated against a fixed set of
+// SQL expressions, so no client input ends up in the generated GROUP BY.
+package report
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+)
+
+// Limits of a single report
+const (
+	MaxDimensions = 3
+	MaxMeasures   = 5
+	// MaxRows is the number of groups a report returns, the rest is cut off
+	MaxRows = 1000
+)
+
+// DimensionType groups the work items by their type
+const DimensionType = "type"
+
+// MeasureCount counts the work items of a group
+const MeasureCount = "count"
+
+// measureSum is the prefix of the measures summing up a numeric field, e.g.
+// "sum:storypoints"
+const measureSum = "sum:"
+
+// Durations in days the time measures compute statistics of, e.g.
+// "lead_time:p85". Only work items that are closed have them.
+const (
+	// LeadTime is the time from the creation to the latest closing
+	LeadTime = "lead_time"
+	// CycleTime is the time from the first start of the work to the latest
+	// closing
+	CycleTime = "cycle_time"
+)
+
+// timeStatistics are the aggregates of the time measures besides the
+// percentiles "p1" to "p99"
+var timeStatistics = map[string]string{
+	"avg":   "avg(%s)",
+	"min":   "min(%s)",
+	"max":   "max(%s)",
+	"count": "count(%s)",
+}
+
+var percentile = regexp.MustCompile(`^p([1-9][0-9]?)$`)
+
+// fieldDimensions are the work item fields reports can group by. The work
+// items are counted once per value of the array fields.
+var fieldDimensions = map[string]bool{
+	workitem.SystemState:     false,
+	workitem.SystemPriority:  false,
+	workitem.SystemSeverity:  false,
+	workitem.SystemIteration: false,
+	workitem.SystemRelease:   false,
+	workitem.SystemProject:   false,
+	workitem.SystemCreator:   false,
+	workitem.SystemAssignees: true,
+	workitem.SystemLabels:    true,
+}
+
+// timeDimensions are the columns reports can group by, combined with one of
+// the truncations as in "created_at:week"
+var timeDimensions = map[string]bool{"created_at": true, "updated_at": true}
+
+var truncations = map[string]bool{"day": true, "week": true, "month": true}
+
+var fieldName = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z0-9_]+)*$`)
+
+// Spec describes the report a client asks for
+type Spec struct {
+	// Dimensions are the names to group by, e.g. "system.state" or
+	// "created_at:week"
+	Dimensions []string
+	// Measures are computed per group, "count" or "sum:<field>"
+	Measures []string
+	// Filter restricts the work items, nil means all of them
+	Filter criteria.Expression
+}
+
+// Row is one group of a report
+type Row struct {
+	// Dimensions are the values of the group in the order of the spec, work
+	// items without a value are grouped under the empty value and the time
+	// dimensions are given as the start of their period like "2017-03-27"
+	Dimensions []string
+	Measures   []float64
+}
+
+// Result is the aggregated data of a report ordered by its dimensions
+type Result struct {
+	Rows []Row
+	// Truncated is set if there were more than MaxRows groups
+	Truncated bool
+}
+
+// Repository describes the computation of reports
+type Repository interface {
+	Run(ctx context.Context, spec Spec) (*Result, error)
+}
+
+// NewReportRepository creates a new storage type.
+func NewReportRepository(db *gorm.DB) Repository {
+	return &GormReportRepository{db: db}
+}
+
+// GormReportRepository is the implementation of the storage interface for
+// reports.
+type GormReportRepository struct {
+	db *gorm.DB
+}
+
+// Run aggregates the work items visible to the viewer of ctx as given by the
+// spec
+// returns BadParameterError or InternalError
+func (r *GormReportRepository) Run(ctx context.Context, spec Spec) (*Result, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "report", "run"}, time.Now())
+
+	if len(spec.Dimensions) > MaxDimensions {
+		return nil, errors.NewBadParameterError("dimensions", len(spec.Dimensions)).Expected(fmt.Sprintf("at most %d dimensions", MaxDimensions))
+	}
+	if len(spec.Measures) == 0 || len(spec.Measures) > MaxMeasures {
+		return nil, errors.NewBadParameterError("measures", len(spec.Measures)).Expected(fmt.Sprintf("between 1 and %d measures", MaxMeasures))
+	}
+	table := workitem.WorkItem{}.TableName()
+	var columns, groups []string
+	var joins []string
+	var params []interface{}
+	for i, dimension := range spec.Dimensions {
+		expr, join, err := dimensionSQL(table, dimension, i)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, expr)
+		groups = append(groups, fmt.Sprintf("%d", i+1))
+		if join != "" {
+			joins = append(joins, join)
+		}
+	}
+	timesJoined := false
+	for _, measure := range spec.Measures {
+		expr, measureParams, err := measureSQL(table, measure)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, expr)
+		params = append(params, measureParams...)
+		if isTimeMeasure(measure) && !timesJoined {
+			joins = append(joins, timesJoin(table))
+			timesJoined = true
+		}
+	}
+
+	db := r.db.Model(&workitem.WorkItem{}).Select(strings.Join(columns, ", "), params...)
+	for _, join := range joins {
+		db = db.Joins(join)
+	}
+	if spec.Filter != nil {
+		where, whereParams, compileErrors := workitem.Compile(spec.Filter)
+		if compileErrors != nil {
+			return nil, errors.NewBadParameterError("filter", spec.Filter)
+		}
+		db = db.Where(where, whereParams...)
+	}
+	if clause, visibilityParams := workitem.VisibilityClause(ctx, table); clause != "" {
+		db = db.Where(clause, visibilityParams...)
+	}
+	if len(groups) > 0 {
+		db = db.Group(strings.Join(groups, ", ")).Order(strings.Join(groups, ", "))
+	}
+	rows, err := db.Limit(MaxRows + 1).Rows()
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	defer rows.Close()
+
+	res := Result{Rows: []Row{}}
+	for rows.Next() {
+		if len(res.Rows) == MaxRows {
+			res.Truncated = true
+			break
+		}
+		dimensions := make([]sql.NullString, len(spec.Dimensions))
+		row := Row{Dimensions: make([]string, len(spec.Dimensions)), Measures: make([]float64, len(spec.Measures))}
+		dest := make([]interface{}, 0, len(columns))
+		for i := range dimensions {
+			dest = append(dest, &dimensions[i])
+		}
+		for i := range row.Measures {
+			dest = append(dest, &row.Measures[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		for i, d := range dimensions {
+			row.Dimensions[i] = d.String
+		}
+		res.Rows = append(res.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return &res, nil
+}
+
+// dimensionSQL returns the select expression of the dimension and the join it
+// needs. Only whitelisted names are written into the SQL.
+// returns BadParameterError
+func dimensionSQL(table string, dimension string, i int) (string, string, error) {
+	if dimension == DimensionType {
+		return table + ".type", "", nil
+	}
+	if array, ok := fieldDimensions[dimension]; ok {
+		if !array {
+			return fmt.Sprintf("coalesce(%s.fields->>'%s', '')", table, dimension), "", nil
+		}
+		alias := fmt.Sprintf("dimension%d", i)
+		join := fmt.Sprintf("LEFT JOIN LATERAL jsonb_array_elements_text(CASE WHEN jsonb_typeof(%[1]s.fields->'%[2]s') = 'array' THEN %[1]s.fields->'%[2]s' ELSE '[]' END) AS %[3]s(value) ON true",
+			table, dimension, alias)
+		return fmt.Sprintf("coalesce(%s.value, '')", alias), join, nil
+	}
+	parts := strings.SplitN(dimension, ":", 2)
+	if len(parts) == 2 && timeDimensions[parts[0]] && truncations[parts[1]] {
+		return fmt.Sprintf("to_char(date_trunc('%s', %s.%s), 'YYYY-MM-DD')", parts[1], table, parts[0]), "", nil
+	}
+	return "", "", errors.NewBadParameterError("dimensions", dimension).Expected(dimensionNames())
+}
+
+// measureSQL returns the select expression of the measure with its
+// parameters
+// returns BadParameterError
+func measureSQL(table string, measure string) (string, []interface{}, error) {
+	if measure == MeasureCount {
+		return "count(*)", nil, nil
+	}
+	if strings.HasPrefix(measure, measureSum) {
+		field := strings.TrimPrefix(measure, measureSum)
+		if fieldName.MatchString(field) {
+			// values that aren't numbers count as zero instead of failing the report
+			expr := fmt.Sprintf("coalesce(sum(CASE WHEN jsonb_typeof(%[1]s.fields->?) = 'number' THEN (%[1]s.fields->>?)::numeric ELSE 0 END), 0)", table)
+			return expr, []interface{}{field, field}, nil
+		}
+	}
+	parts := strings.SplitN(measure, ":", 2)
+	if len(parts) == 2 && (parts[0] == LeadTime || parts[0] == CycleTime) {
+		column := "times." + parts[0]
+		if aggregate, ok := timeStatistics[parts[1]]; ok {
+			return fmt.Sprintf("coalesce("+aggregate+", 0)", column), nil, nil
+		}
+		if m := percentile.FindStringSubmatch(parts[1]); m != nil {
+			return fmt.Sprintf("coalesce(percentile_cont(%s / 100.0) WITHIN GROUP (ORDER BY %s), 0)", m[1], column), nil, nil
+		}
+	}
+	return "", nil, errors.NewBadParameterError("measures", measure).Expected(`"count", "sum:<field>" or "lead_time" and "cycle_time" with ":avg", ":min", ":max", ":count" or a percentile like ":p85"`)
+}
+
+func isTimeMeasure(measure string) bool {
+	return strings.HasPrefix(measure, LeadTime+":") || strings.HasPrefix(measure, CycleTime+":")
+}
+
+// timesJoin returns the join computing the lead and the cycle time of every
+// work item from its events. A work item was closed by the first event of
+// its latest run of closed events and started by its first event in
+// progress before that.
+func timesJoin(table string) string {
+	return fmt.Sprintf(`LEFT JOIN LATERAL (
+		SELECT extract(epoch FROM closed.at - %[1]s.created_at) / 86400 AS lead_time,
+			extract(epoch FROM closed.at - (SELECT min(s.created_at) FROM work_item_events s
+				WHERE s.work_item_id = %[1]s.id AND s.fields->>'%[2]s' = '%[4]s' AND s.created_at <= closed.at)) / 86400 AS cycle_time
+		FROM (SELECT min(c.created_at) AS at FROM work_item_events c
+			WHERE c.work_item_id = %[1]s.id AND c.fields->>'%[2]s' = '%[3]s' AND %[1]s.fields->>'%[2]s' = '%[3]s'
+			AND c.created_at > coalesce((SELECT max(o.created_at) FROM work_item_events o
+				WHERE o.work_item_id = %[1]s.id AND coalesce(o.fields->>'%[2]s', '') <> '%[3]s'), '-infinity')) AS closed
+	) AS times ON true`, table, workitem.SystemState, workitem.SystemStateClosed, workitem.SystemStateInProgress)
+}
+
+func dimensionNames() string {
+	names := []string{DimensionType}
+	for name := range fieldDimensions {
+		names = append(names, name)
+	}
+	for name := range timeDimensions {
+		names = append(names, name+":day|week|month")
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,168 @@
+package report_test
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/report"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestReportRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunReportRepository(t *testing.T) {
+	suite.Run(t, &TestReportRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestReportRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestReportRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestReportRepository) createWorkItem(title string, state string, assignees []string) {
+	fields := map[string]interface{}{
+		workitem.SystemTitle: title,
+		workitem.SystemState: state,
+	}
+	if assignees != nil {
+		fields[workitem.SystemAssignees] = assignees
+	}
+	_, err := workitem.NewWorkItemRepository(test.DB).Create(context.Background(), workitem.SystemFeature, fields, "xx")
+	require.Nil(test.T(), err)
+}
+
+// createWorkItems creates three work items with the same new title and
+// returns the filter matching them
+func (test *TestReportRepository) createWorkItems() criteria.Expression {
+	title := uuid.NewV4().String()
+	test.createWorkItem(title, workitem.SystemStateOpen, []string{"ann", "bob"})
+	test.createWorkItem(title, workitem.SystemStateOpen, []string{"ann"})
+	test.createWorkItem(title, workitem.SystemStateClosed, nil)
+	return criteria.Equals(criteria.Field(workitem.SystemTitle), criteria.Literal(title))
+}
+
+func (test *TestReportRepository) TestRunWithoutDimensions() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	filter := test.createWorkItems()
+	res, err := report.NewReportRepository(test.DB).Run(context.Background(), report.Spec{Measures: []string{report.MeasureCount, "sum:storypoints"}, Filter: filter})
+	require.Nil(t, err)
+	require.Len(t, res.Rows, 1)
+	assert.Equal(t, []float64{3, 0}, res.Rows[0].Measures)
+	assert.False(t, res.Truncated)
+}
+
+func (test *TestReportRepository) TestRunByState() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	filter := test.createWorkItems()
+	res, err := report.NewReportRepository(test.DB).Run(context.Background(), report.Spec{Dimensions: []string{workitem.SystemState}, Measures: []string{report.MeasureCount}, Filter: filter})
+	require.Nil(t, err)
+	require.Len(t, res.Rows, 2)
+	assert.Equal(t, report.Row{Dimensions: []string{workitem.SystemStateClosed}, Measures: []float64{1}}, res.Rows[0])
+	assert.Equal(t, report.Row{Dimensions: []string{workitem.SystemStateOpen}, Measures: []float64{2}}, res.Rows[1])
+}
+
+func (test *TestReportRepository) TestRunByAssigneeAndWeek() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	filter := test.createWorkItems()
+	res, err := report.NewReportRepository(test.DB).Run(context.Background(), report.Spec{Dimensions: []string{workitem.SystemAssignees, "created_at:week"}, Measures: []string{report.MeasureCount}, Filter: filter})
+	require.Nil(t, err)
+	require.Len(t, res.Rows, 3)
+	// the unassigned work item is grouped under the empty value
+	assert.Equal(t, "", res.Rows[0].Dimensions[0])
+	assert.Equal(t, "ann", res.Rows[1].Dimensions[0])
+	assert.Equal(t, []float64{2}, res.Rows[1].Measures)
+	assert.Equal(t, "bob", res.Rows[2].Dimensions[0])
+	assert.Len(t, res.Rows[2].Dimensions[1], len("2017-03-27"))
+}
+
+func (test *TestReportRepository) TestRunTimes() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := workitem.NewWorkItemRepository(test.DB)
+	title := uuid.NewV4().String()
+	// one work item per type that took 10 days to close, 4 of them in progress
+	created := time.Now().AddDate(0, 0, -10)
+	for _, typ := range []string{workitem.SystemBug, workitem.SystemFeature} {
+		wi, err := repo.Create(ctx, typ, map[string]interface{}{
+			workitem.SystemTitle: title,
+			workitem.SystemState: workitem.SystemStateNew,
+		}, "xx")
+		require.Nil(t, err)
+		for _, state := range []string{workitem.SystemStateInProgress, workitem.SystemStateClosed} {
+			wi.Fields[workitem.SystemState] = state
+			wi, err = repo.Save(ctx, *wi)
+			require.Nil(t, err)
+		}
+		require.Nil(t, test.DB.Exec("UPDATE work_items SET created_at = ? WHERE id = ?", created, wi.ID).Error)
+		require.Nil(t, test.DB.Exec("UPDATE work_item_events SET created_at = ? WHERE work_item_id = ? AND fields->>? = ?",
+			created, wi.ID, workitem.SystemState, workitem.SystemStateNew).Error)
+		require.Nil(t, test.DB.Exec("UPDATE work_item_events SET created_at = ? WHERE work_item_id = ? AND fields->>? = ?",
+			created.AddDate(0, 0, 6), wi.ID, workitem.SystemState, workitem.SystemStateInProgress).Error)
+		require.Nil(t, test.DB.Exec("UPDATE work_item_events SET created_at = ? WHERE work_item_id = ? AND fields->>? = ?",
+			created.AddDate(0, 0, 10), wi.ID, workitem.SystemState, workitem.SystemStateClosed).Error)
+	}
+	// an open work item has no times
+	test.createWorkItem(title, workitem.SystemStateOpen, nil)
+
+	res, err := report.NewReportRepository(test.DB).Run(ctx, report.Spec{
+		Dimensions: []string{report.DimensionType},
+		Measures:   []string{report.MeasureCount, "lead_time:p50", "cycle_time:avg", "lead_time:count"},
+		Filter:     criteria.Equals(criteria.Field(workitem.SystemTitle), criteria.Literal(title)),
+	})
+	require.Nil(t, err)
+	require.Len(t, res.Rows, 2)
+	assert.Equal(t, []string{workitem.SystemBug}, res.Rows[0].Dimensions)
+	assert.InDelta(t, 1, res.Rows[0].Measures[0], 0.001)
+	assert.InDelta(t, 10, res.Rows[0].Measures[1], 0.001)
+	assert.InDelta(t, 4, res.Rows[0].Measures[2], 0.001)
+	assert.Equal(t, []string{workitem.SystemFeature}, res.Rows[1].Dimensions)
+	assert.InDelta(t, 2, res.Rows[1].Measures[0], 0.001)
+	assert.InDelta(t, 1, res.Rows[1].Measures[3], 0.001)
+}
+
+func (test *TestReportRepository) TestRunInvalid() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := report.NewReportRepository(test.DB)
+	specs := []report.Spec{
+		{Dimensions: []string{"fields"}, Measures: []string{report.MeasureCount}},
+		{Dimensions: []string{"created_at:year"}, Measures: []string{report.MeasureCount}},
+		{Dimensions: []string{workitem.SystemState, workitem.SystemLabels, workitem.SystemAssignees, report.DimensionType}, Measures: []string{report.MeasureCount}},
+		{Measures: []string{"avg:storypoints"}},
+		{Measures: []string{"lead_time:p100"}},
+		{Measures: []string{"cycle_time:median"}},
+		{Measures: []string{"sum:x'; drop table work_items; --"}},
+		{},
+	}
+	for _, spec := range specs {
+		_, err := repo.Run(context.Background(), spec)
+		require.NotNil(t, err)
+		assert.IsType(t, errors.BadParameterError{}, err)
+	}
+}
@@ -0,0 +1,223 @@
+// Package retention limits how long the history of a project is kept: the
+// superseded revisions of its work items, the daily state snapshots and the
+// deleted work items in its trash are removed once they are older than the
+// days of the retention policy of the project.
+package retention
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Policy decides how long the history of a project is kept, nil keeps it
+// forever or, for the trash, for the retention of the instance
+type Policy struct {
+	gormsupport.Lifecycle
+	ProjectID uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	// RevisionDays after they were superseded revisions are removed, the
+	// latest revision of a work item always stays
+	RevisionDays *int
+	// SnapshotDays after their day the state snapshots are removed
+	SnapshotDays *int
+	// TrashDays after their deletion work items are purged from the trash
+	TrashDays *int
+}
+
+// TableName implements gorm.tabler
+func (p Policy) TableName() string {
+	return "retention_policies"
+}
+
+// Counts is the number of removed, or to be removed, items of a project
+type Counts struct {
+	Revisions int64
+	Snapshots int64
+	Trashed   int64
+}
+
+// Repository encapsulates storage & retrieval of retention policies
+type Repository interface {
+	Load(ctx context.Context, projectID uuid.UUID) (*Policy, error)
+	List(ctx context.Context) ([]*Policy, error)
+	Save(ctx context.Context, p Policy) (*Policy, error)
+	Delete(ctx context.Context, projectID uuid.UUID) error
+	Preview(ctx context.Context, p Policy, now time.Time) (*Counts, error)
+	Enforce(ctx context.Context, now time.Time) (*Counts, error)
+}
+
+// NewRetentionPolicyRepository creates a new storage type.
+func NewRetentionPolicyRepository(db *gorm.DB) Repository {
+	return &GormRetentionPolicyRepository{db: db}
+}
+
+// GormRetentionPolicyRepository is the implementation of the storage
+// interface for retention policies.
+type GormRetentionPolicyRepository struct {
+	db *gorm.DB
+}
+
+// Load returns the policy of the project
+// returns NotFoundError or InternalError
+func (m *GormRetentionPolicyRepository) Load(ctx context.Context, projectID uuid.UUID) (*Policy, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "retentionpolicy", "get"}, time.Now())
+
+	var obj Policy
+	tx := m.db.Where("project_id = ?", projectID).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("retention policy", projectID.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
+
+// List returns the policies of all projects
+// returns InternalError
+func (m *GormRetentionPolicyRepository) List(ctx context.Context) ([]*Policy, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "retentionpolicy", "list"}, time.Now())
+
+	var objs []*Policy
+	if err := m.db.Order("project_id").Find(&objs).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return objs, nil
+}
+
+// Save creates or replaces the policy of the project
+// returns BadParameterError or InternalError
+func (m *GormRetentionPolicyRepository) Save(ctx context.Context, p Policy) (*Policy, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "retentionpolicy", "save"}, time.Now())
+
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	tx := m.db.Exec(`INSERT INTO retention_policies (project_id, revision_days, snapshot_days, trash_days, created_at, updated_at) VALUES (?, ?, ?, ?, now(), now())
+		ON CONFLICT (project_id) DO UPDATE SET revision_days = excluded.revision_days, snapshot_days = excluded.snapshot_days,
+			trash_days = excluded.trash_days, updated_at = now(), deleted_at = NULL`,
+		p.ProjectID, p.RevisionDays, p.SnapshotDays, p.TrashDays)
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return m.Load(ctx, p.ProjectID)
+}
+
+// Delete removes the policy of the project, its history is kept from then on
+// returns NotFoundError or InternalError
+func (m *GormRetentionPolicyRepository) Delete(ctx context.Context, projectID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "retentionpolicy", "delete"}, time.Now())
+
+	tx := m.db.Where("project_id = ?", projectID).Delete(&Policy{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("retention policy", projectID.String())
+	}
+	return nil
+}
+
+// Preview returns what enforcing the policy at the given time would remove,
+// the policy doesn't have to be stored
+// returns BadParameterError or InternalError
+func (m *GormRetentionPolicyRepository) Preview(ctx context.Context, p Policy, now time.Time) (*Counts, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "retentionpolicy", "preview"}, time.Now())
+
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	var c Counts
+	if p.RevisionDays != nil {
+		if err := m.count(&c.Revisions, "SELECT count(*) FROM work_item_events e, work_items w WHERE "+supersededRevisions,
+			p.ProjectID.String(), before(now, *p.RevisionDays)); err != nil {
+			return nil, err
+		}
+	}
+	if p.SnapshotDays != nil {
+		if err := m.count(&c.Snapshots, "SELECT count(*) FROM work_item_state_snapshots WHERE project_id = ? AND day < ?",
+			p.ProjectID, before(now, *p.SnapshotDays)); err != nil {
+			return nil, err
+		}
+	}
+	if p.TrashDays != nil {
+		if err := m.count(&c.Trashed, fmt.Sprintf("SELECT count(*) FROM work_items WHERE deleted_at < ? AND fields->>'%s' = ?", workitem.SystemProject),
+			before(now, *p.TrashDays), p.ProjectID.String()); err != nil {
+			return nil, err
+		}
+	}
+	return &c, nil
+}
+
+// Enforce applies the policies of all projects and returns how much was
+// removed in total
+// returns InternalError
+func (m *GormRetentionPolicyRepository) Enforce(ctx context.Context, now time.Time) (*Counts, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "retentionpolicy", "enforce"}, time.Now())
+
+	policies, err := m.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	trash := workitem.NewTrashRepository(m.db)
+	var c Counts
+	for _, p := range policies {
+		if p.RevisionDays != nil {
+			tx := m.db.Exec("DELETE FROM work_item_events e USING work_items w WHERE "+supersededRevisions,
+				p.ProjectID.String(), before(now, *p.RevisionDays))
+			if tx.Error != nil {
+				return nil, errors.NewInternalError(tx.Error.Error())
+			}
+			c.Revisions += tx.RowsAffected
+		}
+		if p.SnapshotDays != nil {
+			tx := m.db.Exec("DELETE FROM work_item_state_snapshots WHERE project_id = ? AND day < ?", p.ProjectID, before(now, *p.SnapshotDays))
+			if tx.Error != nil {
+				return nil, errors.NewInternalError(tx.Error.Error())
+			}
+			c.Snapshots += tx.RowsAffected
+		}
+		if p.TrashDays != nil {
+			n, err := trash.PurgeProjectBefore(ctx, p.ProjectID, before(now, *p.TrashDays))
+			if err != nil {
+				return nil, err
+			}
+			c.Trashed += n
+		}
+	}
+	return &c, nil
+}
+
+// supersededRevisions matches the events e of the work items w of a project
+// that were created before a time and aren't the latest of their work item
+var supersededRevisions = fmt.Sprintf(`w.id = e.work_item_id AND w.fields->>'%s' = ? AND e.created_at < ?
+	AND EXISTS (SELECT 1 FROM work_item_events l WHERE l.work_item_id = e.work_item_id AND l.sequence > e.sequence)`, workitem.SystemProject)
+
+func (m *GormRetentionPolicyRepository) count(n *int64, query string, values ...interface{}) error {
+	if err := m.db.Raw(query, values...).Row().Scan(n); err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// before returns the time the given number of days before now
+func before(now time.Time, days int) time.Time {
+	return now.AddDate(0, 0, -days)
+}
+
+func (p Policy) validate() error {
+	for name, days := range map[string]*int{"revision-days": p.RevisionDays, "snapshot-days": p.SnapshotDays, "trash-days": p.TrashDays} {
+		if days != nil && *days <= 0 {
+			return errors.NewBadParameterError(name, *days).Expected("greater than 0")
+		}
+	}
+	return nil
+}
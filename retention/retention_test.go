@@ -0,0 +1,112 @@
+package retention_test
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/retention"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestRetentionPolicyRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunRetentionPolicyRepository(t *testing.T) {
+	suite.Run(t, &TestRetentionPolicyRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestRetentionPolicyRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestRetentionPolicyRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestRetentionPolicyRepository) TestSavePolicy() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	p, err := project.NewRepository(test.DB).Create(ctx, "retention-"+uuid.NewV4().String())
+	require.Nil(t, err)
+	repo := retention.NewRetentionPolicyRepository(test.DB)
+	_, err = repo.Load(ctx, p.ID)
+	assert.IsType(t, errors.NotFoundError{}, err)
+	zero := 0
+	_, err = repo.Save(ctx, retention.Policy{ProjectID: p.ID, TrashDays: &zero})
+	assert.IsType(t, errors.BadParameterError{}, err)
+
+	days := 365
+	policy, err := repo.Save(ctx, retention.Policy{ProjectID: p.ID, RevisionDays: &days})
+	require.Nil(t, err)
+	require.NotNil(t, policy.RevisionDays)
+	assert.Equal(t, 365, *policy.RevisionDays)
+	assert.Nil(t, policy.SnapshotDays)
+	assert.Nil(t, policy.TrashDays)
+
+	require.Nil(t, repo.Delete(ctx, p.ID))
+	assert.IsType(t, errors.NotFoundError{}, repo.Delete(ctx, p.ID))
+}
+
+func (test *TestRetentionPolicyRepository) TestPreviewAndEnforce() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	p, err := project.NewRepository(test.DB).Create(ctx, "retention-"+uuid.NewV4().String())
+	require.Nil(t, err)
+	wir := workitem.NewWorkItemRepository(test.DB)
+	fields := map[string]interface{}{
+		workitem.SystemTitle:   "revised",
+		workitem.SystemState:   workitem.SystemStateNew,
+		workitem.SystemProject: p.ID.String(),
+	}
+	revised, err := wir.Create(ctx, workitem.SystemBug, fields, "xx")
+	require.Nil(t, err)
+	revised.Fields[workitem.SystemState] = workitem.SystemStateOpen
+	_, err = wir.Save(ctx, *revised)
+	require.Nil(t, err)
+	deleted, err := wir.Create(ctx, workitem.SystemBug, fields, "xx")
+	require.Nil(t, err)
+	require.Nil(t, wir.Delete(ctx, deleted.ID))
+	require.Nil(t, test.DB.Exec("INSERT INTO work_item_state_snapshots (project_id, day, state, count) VALUES (?, ?, 'new', 1)",
+		p.ID, time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)).Error)
+
+	// two days from now everything of today is older than a day
+	later := time.Now().AddDate(0, 0, 2)
+	day := 1
+	policy := retention.Policy{ProjectID: p.ID, RevisionDays: &day, SnapshotDays: &day, TrashDays: &day}
+	repo := retention.NewRetentionPolicyRepository(test.DB)
+	counts, err := repo.Preview(ctx, policy, later)
+	require.Nil(t, err)
+	assert.Equal(t, retention.Counts{Revisions: 1, Snapshots: 1, Trashed: 1}, *counts)
+	counts, err = repo.Preview(ctx, policy, time.Now())
+	require.Nil(t, err)
+	assert.Equal(t, retention.Counts{Snapshots: 1}, *counts)
+
+	_, err = repo.Save(ctx, policy)
+	require.Nil(t, err)
+	counts, err = repo.Enforce(ctx, later)
+	require.Nil(t, err)
+	assert.Equal(t, retention.Counts{Revisions: 1, Snapshots: 1, Trashed: 1}, *counts)
+	counts, err = repo.Preview(ctx, policy, later)
+	require.Nil(t, err)
+	assert.Equal(t, retention.Counts{}, *counts)
+	events, err := workitem.NewEventRepository(test.DB).List(ctx, revised.ID)
+	require.Nil(t, err)
+	assert.Len(t, events, 1)
+}
@@ -0,0 +1,123 @@
+// Package scan checks uploaded files for malware. Scanners are pluggable,
+// a client of the ClamAV daemon is included. Files a scanner flags are to be
+// quarantined: kept for the admins but never served.
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// Result is the verdict of a scanner on a file
+type Result struct {
+	// Infected is set if the file contains malware
+	Infected bool
+	// Signature is the name of the malware found
+	Signature string
+}
+
+// Scanner checks files for malware
+type Scanner interface {
+	// Scan reads the file and returns the verdict, an error means the file
+	// couldn't be checked
+	Scan(ctx context.Context, file io.Reader) (Result, error)
+}
+
+// NopScanner accepts all files, it is used when no scanner is configured
+type NopScanner struct{}
+
+// Scan implements Scanner
+func (NopScanner) Scan(ctx context.Context, file io.Reader) (Result, error) {
+	return Result{}, nil
+}
+
+// chunkSize is the size of the chunks files are streamed to clamd in, it
+// must be smaller than the StreamMaxLength of clamd
+const chunkSize = 64 * 1024
+
+// ClamdScanner scans files with the INSTREAM command of a ClamAV daemon
+type ClamdScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamdScanner creates a scanner using the clamd listening on the given
+// address, host:port for TCP or the path of its Unix socket. A scan fails
+// if it takes longer than the timeout.
+func NewClamdScanner(address string, timeout time.Duration) *ClamdScanner {
+	network := "tcp"
+	if strings.HasPrefix(address, "/") {
+		network = "unix"
+	}
+	return &ClamdScanner{network: network, address: address, timeout: timeout}
+}
+
+// Scan implements Scanner
+func (s *ClamdScanner) Scan(ctx context.Context, file io.Reader) (Result, error) {
+	deadline := time.Now().Add(s.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn, err := net.DialTimeout(s.network, s.address, s.timeout)
+	if err != nil {
+		return Result{}, err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return Result{}, err
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return Result{}, err
+	}
+	buf := make([]byte, chunkSize)
+	size := make([]byte, 4)
+	for {
+		n, err := file.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(append(size, buf[:n]...)); err != nil {
+				return Result{}, err
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Result{}, err
+		}
+	}
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err := conn.Write(size); err != nil {
+		return Result{}, err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadBytes(0)
+	if err != nil && err != io.EOF {
+		return Result{}, err
+	}
+	return parseReply(string(bytes.TrimRight(reply, "\x00\n")))
+}
+
+// parseReply returns the verdict of a reply of clamd like "stream: OK" or
+// "stream: Eicar-Signature FOUND"
+func parseReply(reply string) (Result, error) {
+	verdict := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case verdict == "OK":
+		return Result{}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return Result{Infected: true, Signature: strings.TrimSuffix(verdict, " FOUND")}, nil
+	default:
+		return Result{}, fmt.Errorf("clamd: %s", reply)
+	}
+}
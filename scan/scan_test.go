@@ -0,0 +1,69 @@
+package scan_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/scan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClamd answers INSTREAM commands, files containing "EICAR" are infected
+func fakeClamd(t *testing.T) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			cmd := make([]byte, len("zINSTREAM\x00"))
+			io.ReadFull(conn, cmd)
+			var file bytes.Buffer
+			for {
+				var size uint32
+				if binary.Read(conn, binary.BigEndian, &size) != nil || size == 0 {
+					break
+				}
+				io.CopyN(&file, conn, int64(size))
+			}
+			if strings.Contains(file.String(), "EICAR") {
+				conn.Write([]byte("stream: Eicar-Signature FOUND\x00"))
+			} else {
+				conn.Write([]byte("stream: OK\x00"))
+			}
+			conn.Close()
+		}
+	}()
+	return l
+}
+
+func TestClamdScanner(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	l := fakeClamd(t)
+	defer l.Close()
+	scanner := scan.NewClamdScanner(l.Addr().String(), time.Second)
+
+	res, err := scanner.Scan(context.Background(), strings.NewReader(strings.Repeat("clean ", 20000)))
+	require.Nil(t, err)
+	assert.False(t, res.Infected)
+
+	res, err = scanner.Scan(context.Background(), strings.NewReader(strings.Repeat("x", 100000)+"EICAR"))
+	require.Nil(t, err)
+	assert.True(t, res.Infected)
+	assert.Equal(t, "Eicar-Signature", res.Signature)
+
+	_, err = scan.NewClamdScanner("127.0.0.1:1", time.Second).Scan(context.Background(), strings.NewReader("file"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"time"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/job"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/goadesign/goa"
+)
+
+// SchedulesController implements the schedules resource.
+type SchedulesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewSchedulesController creates a schedules controller.
+func NewSchedulesController(service *goa.Service, db application.DB) *SchedulesController {
+	return &SchedulesController{Controller: service.NewController("SchedulesController"), db: db}
+}
+
+// List runs the list action.
+func (c *SchedulesController) List(ctx *app.ListSchedulesContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can manage jobs"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		res := &app.ScheduleList{Data: []*app.Schedule{}}
+		for _, s := range job.Schedules() {
+			last, err := appl.Jobs().LastRun(ctx, s.Name)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			res.Data = append(res.Data, ConvertSchedule(s, last))
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Run runs the run action.
+func (c *SchedulesController) Run(ctx *app.RunSchedulesContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can manage jobs"))
+	}
+	s, err := job.LookupSchedule(ctx.Name)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		j, err := appl.Jobs().EnqueueScheduled(ctx, s, time.Now())
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if j == nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("name", ctx.Name).Expected("a schedule that is not being enqueued"))
+		}
+		res := &app.JobSingle{Data: ConvertJob(j)}
+		ctx.ResponseData.Header().Set("Location", AbsoluteURL(ctx.RequestData, app.JobsHref(j.ID)))
+		return ctx.Created(res)
+	})
+}
+
+// ConvertSchedule converts between internal and external REST representation,
+// last is the job of the last run or nil
+func ConvertSchedule(s *job.Schedule, last *job.Job) *app.Schedule {
+	res := &app.Schedule{
+		Type: "schedules",
+		ID:   s.Name,
+		Attributes: &app.ScheduleAttributes{
+			Spec:      s.Spec,
+			Kind:      s.Kind,
+			NextRunAt: s.Next(time.Now()),
+		},
+	}
+	if last != nil {
+		res.Attributes.LastJobID = &last.ID
+		res.Attributes.LastRunAt = &last.RunAt
+		res.Attributes.LastRunStatus = &last.Status
+		if last.LastError != "" {
+			res.Attributes.LastRunError = &last.LastError
+		}
+	}
+	return res
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/translation"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+)
+
+// SchemaController implements the schema resource.
+type SchemaController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewSchemaController creates a schema controller.
+func NewSchemaController(service *goa.Service, db application.DB) *SchemaController {
+	return &SchemaController{Controller: service.NewController("SchemaController"), db: db}
+}
+
+// Show runs the show action.
+func (c *SchemaController) Show(ctx *app.ShowSchemaContext) error {
+	res := &app.Schema{
+		Kinds:       make([]*app.SchemaFieldKind, 0, len(workitem.Kinds)),
+		Transitions: map[string]map[string][]string{},
+	}
+	for _, k := range workitem.Kinds {
+		res.Kinds = append(res.Kinds, &app.SchemaFieldKind{Name: string(k), Simple: k.IsSimpleType()})
+	}
+	err := application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		types, err := appl.WorkItemTypes().List(ctx, nil, nil)
+		if err != nil {
+			return errors.NewInternalError(err.Error())
+		}
+		sort.Sort(typesByName(types))
+		res.WorkItemTypes = types
+		linkTypes, err := appl.WorkItemLinkTypes().List(ctx)
+		if err != nil {
+			return err
+		}
+		sort.Sort(linkTypesByID(linkTypes.Data))
+		res.LinkTypes = linkTypes.Data
+		if ctx.AcceptLanguage == nil {
+			return nil
+		}
+		locales, err := appl.Translations().Locales(ctx)
+		if err != nil {
+			return err
+		}
+		if locale := translation.Negotiate(*ctx.AcceptLanguage, locales); locale != "" {
+			labels, err := appl.Translations().Labels(ctx, locale)
+			if err != nil {
+				return err
+			}
+			res.Locale = &locale
+			res.Labels = labels
+		}
+		return nil
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	for _, wit := range res.WorkItemTypes {
+		if transitions := stateTransitions(wit); transitions != nil {
+			res.Transitions[wit.Name] = transitions
+		}
+	}
+	doc, err := json.Marshal(res)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewInternalError(err.Error()))
+	}
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256(doc))
+	ctx.ResponseData.Header().Set("ETag", etag)
+	ctx.ResponseData.Header().Set("Vary", "Accept-Language")
+	if res.Locale != nil {
+		ctx.ResponseData.Header().Set("Content-Language", *res.Locale)
+	}
+	if ctx.IfNoneMatch != nil && matchesETag(*ctx.IfNoneMatch, etag) {
+		return ctx.NotModified()
+	}
+	return ctx.OK(res)
+}
+
+// stateTransitions returns the states a work item of the type can move to
+// from each of the values of its state field, nil if it has no enum state
+// field. The states don't restrict each other, all other states can be
+// reached from every state.
+func stateTransitions(wit *app.WorkItemType) map[string][]string {
+	state, ok := wit.Fields[workitem.SystemState]
+	if !ok || state.Type == nil || state.Type.Kind != string(workitem.KindEnum) {
+		return nil
+	}
+	states := make([]string, 0, len(state.Type.Values))
+	for _, v := range state.Type.Values {
+		states = append(states, fmt.Sprintf("%v", v))
+	}
+	res := make(map[string][]string, len(states))
+	for _, from := range states {
+		to := make([]string, 0, len(states)-1)
+		for _, s := range states {
+			if s != from {
+				to = append(to, s)
+			}
+		}
+		res[from] = to
+	}
+	return res
+}
+
+// matchesETag returns true if the value of an If-None-Match header matches
+// the ETag
+func matchesETag(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// typesByName sorts work item types by name
+type typesByName []*app.WorkItemType
+
+func (t typesByName) Len() int           { return len(t) }
+func (t typesByName) Less(i, j int) bool { return t[i].Name < t[j].Name }
+func (t typesByName) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+
+// linkTypesByID sorts work item link types by ID
+type linkTypesByID []*app.WorkItemLinkTypeData
+
+func (t linkTypesByID) Len() int           { return len(t) }
+func (t linkTypesByID) Less(i, j int) bool { return *t[i].ID < *t[j].ID }
+func (t linkTypesByID) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateTransitions(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	wit := &app.WorkItemType{Name: "foo", Fields: map[string]*app.FieldDefinition{
+		workitem.SystemState: {Type: &app.FieldType{Kind: string(workitem.KindEnum), Values: []interface{}{"new", "open", "closed"}}},
+	}}
+	assert.Equal(t, map[string][]string{
+		"new":    {"open", "closed"},
+		"open":   {"new", "closed"},
+		"closed": {"new", "open"},
+	}, stateTransitions(wit))
+
+	wit.Fields[workitem.SystemState] = &app.FieldDefinition{Type: &app.FieldType{Kind: string(workitem.KindString)}}
+	assert.Nil(t, stateTransitions(wit))
+	delete(wit.Fields, workitem.SystemState)
+	assert.Nil(t, stateTransitions(wit))
+}
+
+func TestMatchesETag(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	assert.True(t, matchesETag(`"abc"`, `"abc"`))
+	assert.True(t, matchesETag(`"xyz", W/"abc"`, `"abc"`))
+	assert.True(t, matchesETag("*", `"abc"`))
+	assert.False(t, matchesETag(`"xyz"`, `"abc"`))
+	assert.False(t, matchesETag(`abc`, `"abc"`))
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/almighty/almighty-core/application"
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/search"
 	"github.com/goadesign/goa"
 )
 
@@ -53,10 +54,22 @@ func (c *SearchController) Show(ctx *app.ShowSearchContext) error {
 		return ctx.BadRequest(jerrors)
 	}
 
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 		//return transaction.Do(c.ts, func() error {
-		result, c, err := appl.SearchItems().SearchFullText(ctx.Context, ctx.Q, &offset, &limit)
-		count := int(c)
+		opts := search.DefaultOptions()
+		if ctx.RankTitle != nil {
+			opts.TitleWeight = *ctx.RankTitle
+		}
+		if ctx.RankRecency != nil {
+			opts.RecencyBoost = *ctx.RankRecency
+		}
+		if ctx.Highlight != nil {
+			opts.Highlight = *ctx.Highlight
+		}
+		if ctx.FilterArchived != nil {
+			opts.Archived = *ctx.FilterArchived
+		}
+		res, err := appl.SearchItems().SearchFaceted(ctx.Context, ctx.Q, &offset, &limit, opts)
 		if err != nil {
 			switch err := err.(type) {
 			case errors.BadParameterError:
@@ -69,9 +82,15 @@ func (c *SearchController) Show(ctx *app.ShowSearchContext) error {
 			}
 		}
 
+		result := res.Items
+		count := int(res.Count)
+		query := "q=" + ctx.Q
+		if opts.Archived {
+			query += "&filter[archived]=true"
+		}
 		response := app.SearchWorkItemList{
 			Links: &app.PagingLinks{},
-			Meta:  &app.WorkItemListResponseMeta{TotalCount: count},
+			Meta:  &app.SearchResponseMeta{TotalCount: count, Facets: res.Facets, Highlights: res.Highlights},
 			Data:  ConvertWorkItems(ctx.RequestData, result),
 		}
 
@@ -91,7 +110,7 @@ func (c *SearchController) Show(ctx *app.ShowSearchContext) error {
 				realLimit = limit + prevStart
 				prevStart = 0
 			}
-			prev := fmt.Sprintf("%s?q=%s&page[offset]=%d&page[limit]=%d", buildAbsoluteURL(ctx.RequestData), ctx.Q, prevStart, realLimit)
+			prev := fmt.Sprintf("%s?%s&page[offset]=%d&page[limit]=%d", buildAbsoluteURL(ctx.RequestData), query, prevStart, realLimit)
 			response.Links.Prev = &prev
 		}
 
@@ -99,7 +118,7 @@ func (c *SearchController) Show(ctx *app.ShowSearchContext) error {
 		nextStart := offset + len(result)
 		if nextStart < count {
 			// we have a next link
-			next := fmt.Sprintf("%s?q=%s&page[offset]=%d&page[limit]=%d", buildAbsoluteURL(ctx.RequestData), ctx.Q, nextStart, limit)
+			next := fmt.Sprintf("%s?%s&page[offset]=%d&page[limit]=%d", buildAbsoluteURL(ctx.RequestData), query, nextStart, limit)
 			response.Links.Next = &next
 		}
 
@@ -111,7 +130,7 @@ func (c *SearchController) Show(ctx *app.ShowSearchContext) error {
 			// offset == 0, first == current
 			firstEnd = limit
 		}
-		first := fmt.Sprintf("%s?q=%s&page[offset]=%d&page[limit]=%d", buildAbsoluteURL(ctx.RequestData), ctx.Q, 0, firstEnd)
+		first := fmt.Sprintf("%s?%s&page[offset]=%d&page[limit]=%d", buildAbsoluteURL(ctx.RequestData), query, 0, firstEnd)
 		response.Links.First = &first
 
 		// last link
@@ -129,9 +148,86 @@ func (c *SearchController) Show(ctx *app.ShowSearchContext) error {
 			realLimit = limit + lastStart
 			lastStart = 0
 		}
-		last := fmt.Sprintf("%s?q=%s&page[offset]=%d&page[limit]=%d", buildAbsoluteURL(ctx.RequestData), ctx.Q, lastStart, realLimit)
+		last := fmt.Sprintf("%s?%s&page[offset]=%d&page[limit]=%d", buildAbsoluteURL(ctx.RequestData), query, lastStart, realLimit)
 		response.Links.Last = &last
 
 		return ctx.OK(&response)
 	})
 }
+
+// defaultSuggestions is the number of typeahead suggestions per type if no
+// limit is given
+const defaultSuggestions = 10
+
+// Typeahead runs the typeahead action.
+func (c *SearchController) Typeahead(ctx *app.TypeaheadSearchContext) error {
+	limit := defaultSuggestions
+	if ctx.PageLimit != nil {
+		limit = *ctx.PageLimit
+	}
+	var kinds []string
+	if ctx.Type != nil {
+		kinds = []string{*ctx.Type}
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		suggestions, err := appl.SearchItems().Typeahead(ctx, ctx.Q, kinds, limit)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		data := make([]*app.TypeaheadSuggestion, 0, len(suggestions))
+		for _, s := range suggestions {
+			data = append(data, ConvertSuggestion(s))
+		}
+		return ctx.OK(&app.TypeaheadSuggestionList{Data: data})
+	})
+}
+
+// ConvertSuggestion converts between internal and external REST representation
+func ConvertSuggestion(s search.Suggestion) *app.TypeaheadSuggestion {
+	converted := &app.TypeaheadSuggestion{
+		Type:       "workitems",
+		ID:         s.ID,
+		Attributes: &app.TypeaheadSuggestionAttributes{Title: s.Title},
+	}
+	if s.Kind == search.SuggestUsers {
+		converted.Type = "identities"
+		if s.ImageURL != "" {
+			converted.Attributes.ImageURL = &s.ImageURL
+		}
+	}
+	return converted
+}
+
+// defaultDuplicates is the number of suggested duplicates if no limit is given
+const defaultDuplicates = 5
+
+// Duplicates runs the duplicates action.
+func (c *SearchController) Duplicates(ctx *app.DuplicatesSearchContext) error {
+	limit := defaultDuplicates
+	if ctx.PageLimit != nil {
+		limit = *ctx.PageLimit
+	}
+	var description, workItemType string
+	if ctx.Description != nil {
+		description = *ctx.Description
+	}
+	if ctx.Type != nil {
+		workItemType = *ctx.Type
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		duplicates, err := appl.SearchItems().Duplicates(ctx, ctx.Title, description, workItemType, limit)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		items := make([]*app.WorkItem, 0, len(duplicates))
+		scores := map[string]float64{}
+		for _, d := range duplicates {
+			items = append(items, d.Item)
+			scores[d.Item.ID] = d.Score
+		}
+		return ctx.OK(&app.DuplicateWorkItemList{
+			Data: ConvertWorkItems(ctx.RequestData, items),
+			Meta: &app.DuplicatesResponseMeta{Scores: scores},
+		})
+	})
+}
@@ -0,0 +1,192 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"golang.org/x/net/context"
+)
+
+// minDuplicateScore is the similarity below which work items are not
+// suggested as duplicates by the Postgres search
+const minDuplicateScore = 0.2
+
+// Duplicate is an existing work item that may describe the same thing as a
+// new one
+type Duplicate struct {
+	Item *app.WorkItem
+	// Score orders the duplicates, it is between 0 and 1 for the Postgres
+	// search but only comparable within one response of the index
+	Score float64
+}
+
+// Duplicates returns the work items with a title and description similar to
+// the given ones, most similar first. With a registered index the similarity
+// comes from there, otherwise or if the index fails from the trigrams of the
+// titles and descriptions in Postgres. If workItemType is set only work items
+// of that type and its subtypes are returned.
+// returns BadParameterError or InternalError
+func (r *GormSearchRepository) Duplicates(ctx context.Context, title string, description string, workItemType string, limit int) ([]Duplicate, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "search", "duplicates"}, time.Now())
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, errors.NewBadParameterError("title", title).Expected("not empty")
+	}
+	if limit <= 0 || limit > MaxSuggestions {
+		return nil, errors.NewBadParameterError("limit", limit).Expected(fmt.Sprintf("between 1 and %d", MaxSuggestions))
+	}
+	var types []string
+	if workItemType != "" {
+		types = []string{workItemType}
+	}
+	if index != nil {
+		hits, err := index.Similar(ctx, title, description, types, limit)
+		if err == nil {
+			items, err := r.loadAll(ctx, hits.IDs)
+			if err != nil {
+				return nil, err
+			}
+			return scored(items, hits.IDs, hits.Scores), nil
+		}
+		log.Printf("Search index failed, searching Postgres for duplicates: %s\n", err.Error())
+	}
+	return r.duplicatesPostgres(ctx, title, description, types, limit)
+}
+
+func (r *GormSearchRepository) duplicatesPostgres(ctx context.Context, title string, description string, types []string, limit int) ([]Duplicate, error) {
+	table := workitem.WorkItem{}.TableName()
+	titleField := "coalesce(fields->>'" + workitem.SystemTitle + "', '')"
+	score := "similarity(" + titleField + ", ?)"
+	params := []interface{}{title}
+	if strings.TrimSpace(description) != "" {
+		score = "(0.8 * " + score + " + 0.2 * similarity(coalesce(fields->>'" + workitem.SystemDescription + "', ''), ?))"
+		params = append(params, description)
+	}
+	// the candidates come from the trigram and the full text index, the
+	// score then decides which of them are similar enough
+	db := r.db.Table(table).
+		Select("id, "+score+" AS score", params...).
+		Where("deleted_at IS NULL").
+		Where(titleField+" % ? OR tsv @@ plainto_tsquery('english', ?)", title, title).
+		Where(score+" >= ?", append(params, minDuplicateScore)...)
+	if len(types) > 0 {
+		db = db.Where(subtypesClause(table), types)
+	}
+	if clause, params := workitem.VisibilityClause(ctx, table); clause != "" {
+		db = db.Where(clause, params...)
+	}
+	var rows []struct {
+		ID    uint64
+		Score float64
+	}
+	if err := db.Order("score DESC, id DESC").Limit(limit).Scan(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	ids := make([]uint64, 0, len(rows))
+	scores := make([]float64, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+		scores = append(scores, row.Score)
+	}
+	items, err := r.loadAll(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return scored(items, ids, scores), nil
+}
+
+// scored pairs the loaded work items with the scores of their IDs, the items
+// are a subset of the IDs in the same order
+func scored(items []*app.WorkItem, ids []uint64, scores []float64) []Duplicate {
+	res := make([]Duplicate, 0, len(items))
+	i := 0
+	for _, wi := range items {
+		for i < len(ids) && strconv.FormatUint(ids[i], 10) != wi.ID {
+			i++
+		}
+		if i == len(ids) {
+			break
+		}
+		res = append(res, Duplicate{Item: wi, Score: scores[i]})
+	}
+	return res
+}
+
+// subtypesClause restricts work items to the given types and their subtypes
+func subtypesClause(table string) string {
+	return fmt.Sprintf("%[1]s.type in ("+
+		"select distinct subtype.name from %[2]s subtype "+
+		"join %[2]s supertype on subtype.path like (supertype.path || '%%') "+
+		"where supertype.name in (?))", table, workitem.WorkItemType{}.TableName())
+}
+
+// Similar returns the work items visible to the viewer of ctx that are most
+// like the given title and description with their scores
+// returns InternalError
+func (idx *ElasticIndex) Similar(ctx context.Context, title string, description string, types []string, limit int) (*Hits, error) {
+	filter := visibilityFilter(ctx)
+	if len(types) > 0 {
+		filter = append(filter, map[string]interface{}{"terms": map[string]interface{}{"types": types}})
+	}
+	req := map[string]interface{}{
+		"size":    limit,
+		"_source": false,
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must": map[string]interface{}{
+					"more_like_this": map[string]interface{}{
+						"fields":          []string{"title", "description"},
+						"like":            []string{title, description},
+						"min_term_freq":   1,
+						"min_doc_freq":    1,
+						"max_query_terms": 25,
+					},
+				},
+				"filter": filter,
+			},
+		},
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	status, body, err := idx.do("POST", "/_search", bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, errors.NewInternalError(fmt.Sprintf("searching %s for duplicates: %d %s", idx.name, status, body))
+	}
+	var res struct {
+		Hits struct {
+			Hits []struct {
+				ID    string  `json:"_id"`
+				Score float64 `json:"_score"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	hits := Hits{}
+	for _, h := range res.Hits.Hits {
+		id, err := strconv.ParseUint(h.ID, 10, 64)
+		if err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		hits.IDs = append(hits.IDs, id)
+		hits.Scores = append(hits.Scores, h.Score)
+	}
+	hits.Total = uint64(len(hits.IDs))
+	return &hits, nil
+}
@@ -0,0 +1,402 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/breaker"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/asaskevich/govalidator"
+)
+
+// Facets of the work items returned by faceted searches, each facet maps the
+// values of the field to the number of matching work items with that value
+var Facets = []string{"type", "state", "labels", "project"}
+
+// facetSize is the maximum number of values returned per facet
+const facetSize = 20
+
+// Document is a work item in the search index. Encrypted fields are not
+// indexed, the visibility fields let the index filter by the viewer.
+type Document struct {
+	ID            uint64    `json:"id"`
+	Type          string    `json:"type"`
+	Types         []string  `json:"types"`
+	Title         string    `json:"title"`
+	Description   string    `json:"description"`
+	Text          []string  `json:"text"`
+	State         string    `json:"state"`
+	Labels        []string  `json:"labels"`
+	Project       string    `json:"project"`
+	Creator       string    `json:"creator"`
+	Assignees     []string  `json:"assignees"`
+	Confidential  bool      `json:"confidential"`
+	PendingReview bool      `json:"pending_review"`
+	Draft         bool      `json:"draft"`
+	Archived      bool      `json:"archived"`
+	Comments      []string  `json:"comments"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// indexMapping makes the facet and visibility fields exact values
+const indexMapping = `{
+	"mappings": {
+		"_doc": {
+			"properties": {
+				"id": {"type": "long"},
+				"type": {"type": "keyword"},
+				"types": {"type": "keyword"},
+				"title": {"type": "text"},
+				"description": {"type": "text"},
+				"text": {"type": "text"},
+				"state": {"type": "keyword"},
+				"labels": {"type": "keyword"},
+				"project": {"type": "keyword"},
+				"creator": {"type": "keyword"},
+				"assignees": {"type": "keyword"},
+				"confidential": {"type": "boolean"},
+				"pending_review": {"type": "boolean"},
+				"draft": {"type": "boolean"},
+				"archived": {"type": "boolean"},
+				"comments": {"type": "text"},
+				"updated_at": {"type": "date"}
+			}
+		}
+	}
+}`
+
+var index *ElasticIndex
+
+// RegisterIndex makes faceted searches use the given Elasticsearch index
+// instead of Postgres. It must be called during initialization.
+func RegisterIndex(idx *ElasticIndex) {
+	index = idx
+}
+
+// ElasticIndex stores work items in an Elasticsearch index
+type ElasticIndex struct {
+	url    string
+	name   string
+	client *http.Client
+}
+
+// NewElasticIndex creates an index with the given name on the Elasticsearch
+// server at url
+func NewElasticIndex(url string, name string) *ElasticIndex {
+	return &ElasticIndex{
+		url:    strings.TrimSuffix(url, "/"),
+		name:   name,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Create creates the index and returns true unless it already exists
+// returns InternalError
+func (idx *ElasticIndex) Create(ctx context.Context) (bool, error) {
+	status, body, err := idx.do("PUT", "", strings.NewReader(indexMapping))
+	if err != nil {
+		return false, err
+	}
+	if status == http.StatusBadRequest && strings.Contains(string(body), "already_exists") {
+		return false, nil
+	}
+	if status != http.StatusOK {
+		return false, errors.NewInternalError(fmt.Sprintf("creating search index %s: %d %s", idx.name, status, body))
+	}
+	return true, nil
+}
+
+// Put adds or replaces the document
+// returns InternalError
+func (idx *ElasticIndex) Put(ctx context.Context, doc Document) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	status, body, err := idx.do("PUT", "/_doc/"+strconv.FormatUint(doc.ID, 10), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK && status != http.StatusCreated {
+		return errors.NewInternalError(fmt.Sprintf("indexing work item %d: %d %s", doc.ID, status, body))
+	}
+	return nil
+}
+
+// Remove removes the document of the work item, a missing one is no error
+// returns InternalError
+func (idx *ElasticIndex) Remove(ctx context.Context, id uint64) error {
+	status, body, err := idx.do("DELETE", "/_doc/"+strconv.FormatUint(id, 10), nil)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK && status != http.StatusNotFound {
+		return errors.NewInternalError(fmt.Sprintf("removing work item %d: %d %s", id, status, body))
+	}
+	return nil
+}
+
+// Hits are the IDs of the matching work items, best match first, with their
+// total number, the facets of all matching work items and, if requested, the
+// highlighted snippets of the hits by work item ID
+type Hits struct {
+	IDs        []uint64
+	Total      uint64
+	Facets     map[string]map[string]int
+	Highlights map[string]map[string][]string
+	// Scores are only set for the IDs of similar work items
+	Scores []float64
+}
+
+// Search runs the query visible to the viewer of ctx
+// returns BadParameterError or InternalError
+func (idx *ElasticIndex) Search(ctx context.Context, q string, start int, limit int, opts Options) (*Hits, error) {
+	query, err := elasticQuery(ctx, q, opts)
+	if err != nil {
+		return nil, err
+	}
+	aggs := map[string]interface{}{}
+	for _, f := range Facets {
+		aggs[f] = map[string]interface{}{"terms": map[string]interface{}{"field": f, "size": facetSize}}
+	}
+	req := map[string]interface{}{
+		"from":    start,
+		"size":    limit,
+		"_source": false,
+		"query":   opts.elasticScore(query),
+		"sort":    []interface{}{"_score", map[string]interface{}{"updated_at": "desc"}},
+		"aggs":    aggs,
+	}
+	if opts.Highlight {
+		req["highlight"] = map[string]interface{}{
+			"pre_tags":  []string{highlightStart},
+			"post_tags": []string{highlightStop},
+			"fields": map[string]interface{}{
+				"title":       map[string]interface{}{"number_of_fragments": 0},
+				"description": map[string]interface{}{},
+				"comments":    map[string]interface{}{},
+			},
+		}
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	status, body, err := idx.do("POST", "/_search", bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, errors.NewInternalError(fmt.Sprintf("searching %s: %d %s", idx.name, status, body))
+	}
+	var res struct {
+		Hits struct {
+			// a number before Elasticsearch 7, an object since
+			Total json.RawMessage `json:"total"`
+			Hits  []struct {
+				ID        string              `json:"_id"`
+				Highlight map[string][]string `json:"highlight"`
+			} `json:"hits"`
+		} `json:"hits"`
+		Aggregations map[string]struct {
+			Buckets []struct {
+				Key      interface{} `json:"key"`
+				DocCount int         `json:"doc_count"`
+			} `json:"buckets"`
+		} `json:"aggregations"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	hits := Hits{Facets: map[string]map[string]int{}, Highlights: map[string]map[string][]string{}}
+	if err := json.Unmarshal(res.Hits.Total, &hits.Total); err != nil {
+		var total struct {
+			Value uint64 `json:"value"`
+		}
+		if err := json.Unmarshal(res.Hits.Total, &total); err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		hits.Total = total.Value
+	}
+	for _, h := range res.Hits.Hits {
+		id, err := strconv.ParseUint(h.ID, 10, 64)
+		if err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		hits.IDs = append(hits.IDs, id)
+		if len(h.Highlight) > 0 {
+			hits.Highlights[h.ID] = h.Highlight
+		}
+	}
+	for name, agg := range res.Aggregations {
+		values := map[string]int{}
+		for _, b := range agg.Buckets {
+			values[fmt.Sprint(b.Key)] = b.DocCount
+		}
+		hits.Facets[name] = values
+	}
+	return &hits, nil
+}
+
+func (idx *ElasticIndex) do(method string, path string, body io.Reader) (int, []byte, error) {
+	req, err := http.NewRequest(method, idx.url+"/"+idx.name+path, body)
+	if err != nil {
+		return 0, nil, errors.NewInternalError(err.Error())
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := breaker.For("search").Request(idx.client, req)
+	if err != nil {
+		return 0, nil, errors.NewInternalError(err.Error())
+	}
+	defer res.Body.Close()
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return 0, nil, errors.NewInternalError(err.Error())
+	}
+	return res.StatusCode, data, nil
+}
+
+// filterPrefixes map the search keywords filtering by a facet to the field
+var filterPrefixes = map[string]string{
+	"type:":     "types",
+	"state:":    "state",
+	"label:":    "labels",
+	"project:":  "project",
+	"assignee:": "assignees",
+}
+
+// elasticQuery translates the search string to an Elasticsearch query, the
+// keywords are the ones of parseSearchString plus the facet filters
+// returns BadParameterError
+func elasticQuery(ctx context.Context, q string, opts Options) (map[string]interface{}, error) {
+	var must, filter []interface{}
+	for _, part := range strings.Fields(strings.Trim(strings.Trim(q, "/"), "\"")) {
+		if p, err := url.QueryUnescape(part); err == nil {
+			part = p
+		}
+		if strings.HasPrefix(part, "id:") {
+			id, err := strconv.ParseUint(strings.TrimPrefix(part, "id:"), 10, 64)
+			if err != nil {
+				return nil, errors.NewBadParameterError("id", part).Expected("a work item number")
+			}
+			filter = append(filter, map[string]interface{}{"term": map[string]interface{}{"id": id}})
+			continue
+		}
+		matched := false
+		for prefix, field := range filterPrefixes {
+			if strings.HasPrefix(part, prefix) {
+				value := strings.TrimPrefix(part, prefix)
+				if value == "" {
+					return nil, errors.NewBadParameterError("search keyword must not be empty", part)
+				}
+				filter = append(filter, map[string]interface{}{"term": map[string]interface{}{field: value}})
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		must = append(must, map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":  strings.ToLower(part),
+				"type":   "phrase_prefix",
+				"fields": opts.elasticFields(),
+			},
+		})
+	}
+	if len(must) == 0 && len(filter) == 0 {
+		// like the full text search an empty query matches nothing
+		must = append(must, map[string]interface{}{"match_none": map[string]interface{}{}})
+	}
+	archived := map[string]interface{}{"term": map[string]interface{}{"archived": true}}
+	query := map[string]interface{}{"must": must, "filter": append(filter, visibilityFilter(ctx)...)}
+	if opts.Archived {
+		query["filter"] = append(query["filter"].([]interface{}), archived)
+	} else {
+		// documents indexed before the archived flag don't have it
+		query["must_not"] = []interface{}{archived}
+	}
+	return map[string]interface{}{"bool": query}, nil
+}
+
+// hasURL returns true if the search string contains a URL, those are only
+// understood by the Postgres search
+func hasURL(q string) bool {
+	for _, part := range strings.Fields(q) {
+		if p, err := url.QueryUnescape(part); err == nil {
+			part = p
+		}
+		if !strings.Contains(part, ":") || strings.HasPrefix(part, "id:") {
+			continue
+		}
+		if govalidator.IsURL(part) {
+			return true
+		}
+	}
+	return false
+}
+
+// visibilityFilter is the counterpart of workitem.VisibilityClause
+func visibilityFilter(ctx context.Context) []interface{} {
+	v := workitem.ContextViewer(ctx)
+	if v == nil {
+		return nil
+	}
+	filters := []interface{}{
+		flagFilter(v, "confidential", workitem.RoleCreator, workitem.RoleAssignee, workitem.RoleProjectAdmin),
+		flagFilter(v, "pending_review", workitem.RoleCreator, workitem.RoleProjectAdmin),
+		flagFilter(v, "draft", workitem.RoleCreator),
+	}
+	if len(v.HiddenProjectIDs) > 0 {
+		hidden := make([]string, len(v.HiddenProjectIDs))
+		for i, id := range v.HiddenProjectIDs {
+			hidden[i] = id.String()
+		}
+		filters = append(filters, map[string]interface{}{
+			"bool": map[string]interface{}{"must_not": map[string]interface{}{"terms": map[string]interface{}{"project": hidden}}},
+		})
+	}
+	return filters
+}
+
+// flagFilter matches the documents without the flag or on which the viewer
+// has one of the roles, the counterpart of the flag clauses of
+// workitem.VisibilityClause
+func flagFilter(v *workitem.Viewer, flag string, roles ...string) interface{} {
+	should := []interface{}{
+		map[string]interface{}{"bool": map[string]interface{}{"must_not": map[string]interface{}{"term": map[string]interface{}{flag: true}}}},
+	}
+	if v.IdentityID != nil {
+		me := v.IdentityID.String()
+		for _, role := range roles {
+			switch role {
+			case workitem.RoleCreator:
+				should = append(should, map[string]interface{}{"term": map[string]interface{}{"creator": me}})
+			case workitem.RoleAssignee:
+				should = append(should, map[string]interface{}{"term": map[string]interface{}{"assignees": me}})
+			case workitem.RoleProjectAdmin:
+				if len(v.AdminProjectIDs) == 0 {
+					continue
+				}
+				admin := make([]string, len(v.AdminProjectIDs))
+				for i, id := range v.AdminProjectIDs {
+					admin[i] = id.String()
+				}
+				should = append(should, map[string]interface{}{"terms": map[string]interface{}{"project": admin}})
+			}
+		}
+	}
+	return map[string]interface{}{"bool": map[string]interface{}{"should": should, "minimum_should_match": 1}}
+}
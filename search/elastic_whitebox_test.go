@@ -0,0 +1,141 @@
+package search
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestElasticQuery(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	me := uuid.NewV4()
+	ctx := workitem.WithViewer(context.Background(), &workitem.Viewer{IdentityID: &me})
+	q, err := elasticQuery(ctx, "crash state:open label:ui id:42", DefaultOptions())
+	require.Nil(t, err)
+	data, err := json.Marshal(q)
+	require.Nil(t, err)
+	s := string(data)
+	assert.Contains(t, s, `{"multi_match":{"fields":["title^2","description","text","comments"],"query":"crash","type":"phrase_prefix"}}`)
+	assert.Contains(t, s, `{"term":{"state":"open"}}`)
+	assert.Contains(t, s, `{"term":{"labels":"ui"}}`)
+	assert.Contains(t, s, `{"term":{"id":42}}`)
+	// confidential, pending and draft work items of others are filtered
+	assert.Contains(t, s, `{"term":{"creator":"`+me.String()+`"}}`)
+	assert.Contains(t, s, `{"term":{"assignees":"`+me.String()+`"}}`)
+	assert.Contains(t, s, `{"bool":{"minimum_should_match":1,"should":[{"bool":{"must_not":{"term":{"draft":true}}}},{"term":{"creator":"`+me.String()+`"}}]}}`)
+
+	_, err = elasticQuery(ctx, "state:", DefaultOptions())
+	assert.IsType(t, errors.BadParameterError{}, err)
+	_, err = elasticQuery(ctx, "id:x", DefaultOptions())
+	assert.IsType(t, errors.BadParameterError{}, err)
+
+	q, err = elasticQuery(context.Background(), "crash", DefaultOptions())
+	require.Nil(t, err)
+	data, _ = json.Marshal(q)
+	assert.NotContains(t, string(data), "creator")
+	// archived work items are only searched on request
+	assert.Contains(t, string(data), `"must_not":[{"term":{"archived":true}}]`)
+	opts := DefaultOptions()
+	opts.Archived = true
+	q, err = elasticQuery(context.Background(), "crash", opts)
+	require.Nil(t, err)
+	data, _ = json.Marshal(q)
+	assert.Contains(t, string(data), `"filter":[{"term":{"archived":true}}]`)
+	assert.NotContains(t, string(data), "must_not")
+
+	assert.True(t, hasURL("http://demo.almighty.io/work-item-list/detail/100"))
+	assert.False(t, hasURL("type:system.bug id:12 crash"))
+}
+
+func TestNewDocument(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	wit := workitem.WorkItemType{
+		Name: "bug",
+		Path: "/planneritem/bug",
+		Fields: map[string]workitem.FieldDefinition{
+			workitem.SystemTitle:   {},
+			"secret":               {Encrypted: true},
+			"notes":                {},
+			"salary":               {Roles: []string{workitem.RoleProjectAdmin}},
+			workitem.SystemCreator: {Roles: []string{workitem.RoleProjectAdmin}},
+		},
+	}
+	wi := workitem.WorkItem{ID: 12, Type: "bug", Fields: workitem.Fields{
+		workitem.SystemTitle:       "Crash",
+		workitem.SystemDescription: map[string]interface{}{"content": "on **login**", "markup": "Markdown"},
+		workitem.SystemLabels:      []interface{}{"ui"},
+		"secret":                   "ciphertext",
+		"notes":                    "see logs",
+		workitem.SystemDraft:       true,
+		"salary":                   "100k",
+		workitem.SystemCreator:     "me",
+	}}
+	doc := NewDocument(wit, wi, []comment.Comment{{Body: "me too"}, {Body: "buy now", PendingReview: true}})
+	assert.Equal(t, uint64(12), doc.ID)
+	assert.Equal(t, []string{"planneritem", "bug"}, doc.Types)
+	assert.Equal(t, "Crash", doc.Title)
+	assert.Equal(t, "on **login**", doc.Description)
+	assert.Equal(t, []string{"ui"}, doc.Labels)
+	assert.Equal(t, []string{"see logs"}, doc.Text)
+	assert.Equal(t, []string{"me too"}, doc.Comments)
+	// the creator is restricted but needed to filter the hits
+	assert.Equal(t, "me", doc.Creator)
+	assert.True(t, doc.Draft)
+}
+
+func TestElasticSearch(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	var path string
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		data, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(data, &body)
+		w.Write([]byte(`{"hits": {"total": {"value": 7}, "hits": [{"_id": "3", "highlight": {"title": ["<em>crash</em> on login"]}}, {"_id": "1"}]},
+			"aggregations": {"state": {"buckets": [{"key": "open", "doc_count": 5}, {"key": "closed", "doc_count": 2}]}}}`))
+	}))
+	defer server.Close()
+
+	hits, err := NewElasticIndex(server.URL+"/", "workitems").Search(context.Background(), "crash", 10, 2, Options{TitleWeight: 1, RecencyBoost: 2, Highlight: true})
+	require.Nil(t, err)
+	assert.Equal(t, "/workitems/_search", path)
+	assert.Equal(t, float64(10), body["from"])
+	assert.Equal(t, float64(2), body["size"])
+	assert.Equal(t, []uint64{3, 1}, hits.IDs)
+	assert.Equal(t, uint64(7), hits.Total)
+	assert.Equal(t, map[string]int{"open": 5, "closed": 2}, hits.Facets["state"])
+	assert.Equal(t, map[string][]string{"title": {"<em>crash</em> on login"}}, hits.Highlights["3"])
+	assert.Contains(t, body["query"], "function_score")
+	assert.Contains(t, body, "highlight")
+}
+
+func TestRankingOptions(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	assert.Nil(t, DefaultOptions().Validate())
+	assert.IsType(t, errors.BadParameterError{}, Options{TitleWeight: 6}.Validate())
+	assert.IsType(t, errors.BadParameterError{}, Options{TitleWeight: 1, RecencyBoost: -1}.Validate())
+
+	assert.Equal(t, "ts_rank('{0.1, 0.2, 0.4, 1.0}', work_items.tsv, query)", DefaultOptions().rankSQL("work_items"))
+	// weights are scaled down to stay within 1
+	assert.Equal(t, "ts_rank('{0.1, 0.2, 1, 1.0}', w.tsv, query)", Options{TitleWeight: 5}.rankSQL("w"))
+	assert.Contains(t, Options{TitleWeight: 2, RecencyBoost: 1.5}.rankSQL("w"), "* (1 + 1.5 * exp(-extract(epoch from now() - w.updated_at) / 2592000))")
+}
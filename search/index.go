@@ -0,0 +1,365 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/changefeed"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/outbox"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/jinzhu/gorm"
+)
+
+// ReindexJobKind is the kind of the job adding all work items to the index
+const ReindexJobKind = "search.reindex"
+
+// IndexSink is the outbox sink keeping the index up to date with the change
+// feed. Changes only tell which work item to index, the document is built
+// from the committed work item and its comments so redelivered or reordered
+// changes leave the index right.
+type IndexSink struct {
+	db  *gorm.DB
+	idx *ElasticIndex
+}
+
+// NewIndexSink creates a sink indexing the work items of db
+func NewIndexSink(db *gorm.DB, idx *ElasticIndex) *IndexSink {
+	return &IndexSink{db: db, idx: idx}
+}
+
+// Name implements outbox.Sink
+func (s *IndexSink) Name() string {
+	return "search"
+}
+
+// Deliver implements outbox.Sink
+func (s *IndexSink) Deliver(ctx context.Context, r outbox.Record) error {
+	var id string
+	switch r.Topic {
+	case changefeed.EntityWorkItem:
+		id = r.Key
+	case changefeed.EntityComment:
+		var msg changefeed.Message
+		if err := json.Unmarshal([]byte(r.Payload), &msg); err != nil {
+			return errors.NewConversionError(err.Error())
+		}
+		var c struct {
+			ParentID string `json:"parent_id"`
+		}
+		if err := json.Unmarshal(msg.Data, &c); err != nil {
+			return errors.NewConversionError(err.Error())
+		}
+		id = c.ParentID
+	default:
+		return nil
+	}
+	wiID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		// comments of other things than work items
+		return nil
+	}
+	return s.Index(ctx, wiID)
+}
+
+// Close implements outbox.Sink
+func (s *IndexSink) Close() error {
+	return nil
+}
+
+// Index adds the current state of the work item to the index or removes it
+// if the work item was deleted
+// returns InternalError
+func (s *IndexSink) Index(ctx context.Context, id uint64) error {
+	var wi workitem.WorkItem
+	tx := s.db.First(&wi, id)
+	if tx.RecordNotFound() {
+		return s.idx.Remove(ctx, id)
+	}
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	wit, err := workitem.NewWorkItemTypeRepository(s.db).LoadTypeFromDB(wi.Type)
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	var comments []comment.Comment
+	err = s.db.Where("parent_id = ? AND NOT pending_review", strconv.FormatUint(id, 10)).Order("created_at").Find(&comments).Error
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return s.idx.Put(ctx, NewDocument(*wit, wi, comments))
+}
+
+// Reindex adds all work items to the index
+// returns InternalError
+func (s *IndexSink) Reindex(ctx context.Context) error {
+	var ids []uint64
+	if err := s.db.Model(&workitem.WorkItem{}).Order("id").Pluck("id", &ids).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	for _, id := range ids {
+		if err := s.Index(ctx, id); err != nil {
+			return err
+		}
+	}
+	log.Printf("indexed %d work items\n", len(ids))
+	return nil
+}
+
+// ReindexJob returns the handler of the reindex jobs
+func (s *IndexSink) ReindexJob() func(ctx context.Context, payload []byte) error {
+	return func(ctx context.Context, payload []byte) error {
+		return s.Reindex(ctx)
+	}
+}
+
+// visibilityFields are the fields the hits are filtered by, they are indexed
+// whatever their roles
+var visibilityFields = map[string]bool{
+	workitem.SystemProject:       true,
+	workitem.SystemCreator:       true,
+	workitem.SystemAssignees:     true,
+	workitem.SystemConfidential:  true,
+	workitem.SystemPendingReview: true,
+	workitem.SystemDraft:         true,
+}
+
+// NewDocument returns the document of the work item. Values of encrypted and
+// role restricted fields and comments held for review are left out, queries
+// matching them would tell what they hold to viewers who can't read them.
+func NewDocument(wit workitem.WorkItemType, wi workitem.WorkItem, comments []comment.Comment) Document {
+	doc := Document{
+		ID:        wi.ID,
+		Type:      wi.Type,
+		Types:     []string{wit.Name},
+		Archived:  wi.Archived,
+		UpdatedAt: wi.UpdatedAt,
+	}
+	if path := strings.Trim(wit.Path, "/"); path != "" {
+		// the path ends with the type itself
+		doc.Types = strings.Split(path, "/")
+	}
+	for name, value := range wi.Fields {
+		def := wit.Fields[name]
+		if def.Encrypted || (len(def.Roles) > 0 && !visibilityFields[name]) {
+			continue
+		}
+		switch name {
+		case workitem.SystemTitle:
+			doc.Title = text(value)
+		case workitem.SystemDescription:
+			doc.Description = text(value)
+		case workitem.SystemState:
+			doc.State = text(value)
+		case workitem.SystemLabels:
+			doc.Labels = texts(value)
+		case workitem.SystemProject:
+			doc.Project = text(value)
+		case workitem.SystemCreator:
+			doc.Creator = text(value)
+		case workitem.SystemAssignees:
+			doc.Assignees = texts(value)
+		case workitem.SystemConfidential:
+			doc.Confidential, _ = value.(bool)
+		case workitem.SystemPendingReview:
+			doc.PendingReview, _ = value.(bool)
+		case workitem.SystemDraft:
+			doc.Draft, _ = value.(bool)
+		default:
+			if s, ok := value.(string); ok && s != "" {
+				doc.Text = append(doc.Text, s)
+			}
+		}
+	}
+	for _, c := range comments {
+		if c.PendingReview {
+			continue
+		}
+		doc.Comments = append(doc.Comments, c.Body)
+	}
+	return doc
+}
+
+// text returns the string value of a field, markup values are indexed by
+// their content
+func text(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]interface{}:
+		if content, ok := v["content"].(string); ok {
+			return content
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+func texts(value interface{}) []string {
+	var res []string
+	switch v := value.(type) {
+	case []interface{}:
+		for _, s := range v {
+			res = append(res, fmt.Sprint(s))
+		}
+	case []string:
+		res = append(res, v...)
+	}
+	return res
+}
+
+// Result is a page of work items matching a search
+type Result struct {
+	Items []*app.WorkItem
+	// Count is the number of all matching work items
+	Count uint64
+	// Facets is only set by searches in the index
+	Facets map[string]map[string]int
+	// Highlights are the snippets of the items by ID, if requested
+	Highlights map[string]map[string][]string
+}
+
+// SearchFaceted returns the work items for the given query with the facets of
+// all matching work items. With a registered index the query runs there,
+// otherwise or if the index fails it runs as a full text search in Postgres
+// which has no facets.
+// returns BadParameterError, ConversionError or InternalError
+func (r *GormSearchRepository) SearchFaceted(ctx context.Context, rawSearchString string, start *int, limit *int, opts Options) (*Result, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if index == nil || hasURL(rawSearchString) {
+		return r.searchPostgres(ctx, rawSearchString, start, limit, opts)
+	}
+	from, size := 0, 100
+	if start != nil {
+		if *start < 0 {
+			return nil, errors.NewBadParameterError("start", *start)
+		}
+		from = *start
+	}
+	if limit != nil {
+		if *limit <= 0 {
+			return nil, errors.NewBadParameterError("limit", *limit)
+		}
+		size = *limit
+	}
+	hits, err := index.Search(ctx, rawSearchString, from, size, opts)
+	if err != nil {
+		if _, ok := err.(errors.BadParameterError); ok {
+			return nil, err
+		}
+		log.Printf("Search index failed, searching Postgres: %s\n", err.Error())
+		return r.searchPostgres(ctx, rawSearchString, start, limit, opts)
+	}
+	items, err := r.loadAll(ctx, hits.IDs)
+	if err != nil {
+		return nil, err
+	}
+	res := &Result{Items: items, Count: hits.Total, Facets: hits.Facets}
+	if opts.Highlight {
+		res.Highlights = redactHighlights(items, hits.Highlights)
+	}
+	return res, nil
+}
+
+func (r *GormSearchRepository) searchPostgres(ctx context.Context, rawSearchString string, start *int, limit *int, opts Options) (*Result, error) {
+	items, count, err := r.searchFullText(ctx, rawSearchString, start, limit, opts)
+	if err != nil {
+		return nil, err
+	}
+	res := &Result{Items: items, Count: count}
+	if opts.Highlight {
+		keywords, err := parseSearchString(rawSearchString)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]uint64, 0, len(items))
+		for _, wi := range items {
+			id, err := strconv.ParseUint(wi.ID, 10, 64)
+			if err != nil {
+				return nil, errors.NewInternalError(err.Error())
+			}
+			ids = append(ids, id)
+		}
+		snippets, err := highlights(ctx, r.db, generateSQLSearchInfo(keywords), ids)
+		if err != nil {
+			return nil, err
+		}
+		res.Highlights = redactHighlights(items, snippets)
+	}
+	return res, nil
+}
+
+// highlightFields are the work item fields of the snippets
+var highlightFields = map[string]string{
+	"title":       workitem.SystemTitle,
+	"description": workitem.SystemDescription,
+}
+
+// redactHighlights drops the snippets of fields that were redacted from the
+// work items
+func redactHighlights(items []*app.WorkItem, snippets map[string]map[string][]string) map[string]map[string][]string {
+	res := map[string]map[string][]string{}
+	for _, wi := range items {
+		for name, s := range snippets[wi.ID] {
+			if field, ok := highlightFields[name]; ok {
+				if _, visible := wi.Fields[field]; !visible {
+					continue
+				}
+			}
+			if res[wi.ID] == nil {
+				res[wi.ID] = map[string][]string{}
+			}
+			res[wi.ID][name] = s
+		}
+	}
+	return res
+}
+
+// loadAll returns the visible work items with the given IDs in their order,
+// work items deleted since they were indexed are left out
+func (r *GormSearchRepository) loadAll(ctx context.Context, ids []uint64) ([]*app.WorkItem, error) {
+	result := []*app.WorkItem{}
+	if len(ids) == 0 {
+		return result, nil
+	}
+	db := r.db.Where("id IN (?)", ids)
+	if clause, params := workitem.VisibilityClause(ctx, workitem.WorkItem{}.TableName()); clause != "" {
+		db = db.Where(clause, params...)
+	}
+	var rows []workitem.WorkItem
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	byID := map[uint64]workitem.WorkItem{}
+	for _, wi := range rows {
+		byID[wi.ID] = wi
+	}
+	for _, id := range ids {
+		wi, ok := byID[id]
+		if !ok {
+			continue
+		}
+		wiType, err := r.wir.LoadTypeFromDB(wi.Type)
+		if err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		converted, err := convertFromModel(*wiType, wi)
+		if err != nil {
+			return nil, errors.NewConversionError(err.Error())
+		}
+		workitem.ContextViewer(ctx).Redact(*wiType, converted)
+		result = append(result, converted)
+	}
+	return result, nil
+}
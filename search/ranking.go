@@ -0,0 +1,141 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/jinzhu/gorm"
+)
+
+// Limits of the ranking options
+const (
+	MaxTitleWeight  = 5.0
+	MaxRecencyBoost = 10.0
+)
+
+// recencyScale is the age at which the recency boost of a work item has
+// decayed to 1/e, in seconds
+const recencyScale = 30 * 24 * 60 * 60
+
+// Highlighted terms in snippets are wrapped in these tags
+const (
+	highlightStart = "<em>"
+	highlightStop  = "</em>"
+)
+
+// Options tune the ranking of search results and whether the responses
+// carry highlighted snippets
+type Options struct {
+	// TitleWeight is how much more a match in the title counts than a
+	// match in the description
+	TitleWeight float64
+	// RecencyBoost multiplies the score of a work item updated just now by
+	// 1 + RecencyBoost, the boost decays with the age of the last update
+	RecencyBoost float64
+	// Highlight returns snippets of the matching fields
+	Highlight bool
+	// Archived searches the archived instead of the active work items
+	Archived bool
+}
+
+// DefaultOptions returns the options of searches that don't tune the ranking
+func DefaultOptions() Options {
+	return Options{TitleWeight: 2}
+}
+
+// Validate checks the options are within their limits
+// returns BadParameterError
+func (o Options) Validate() error {
+	if o.TitleWeight < 0 || o.TitleWeight > MaxTitleWeight {
+		return errors.NewBadParameterError("rank[title]", o.TitleWeight).Expected(fmt.Sprintf("between 0 and %v", MaxTitleWeight))
+	}
+	if o.RecencyBoost < 0 || o.RecencyBoost > MaxRecencyBoost {
+		return errors.NewBadParameterError("rank[recency]", o.RecencyBoost).Expected(fmt.Sprintf("between 0 and %v", MaxRecencyBoost))
+	}
+	return nil
+}
+
+// rankSQL returns the ranking expression of the full text search. The search
+// vector weighs IDs with A, titles with B and descriptions with C.
+func (o Options) rankSQL(table string) string {
+	description := 0.2
+	title := description * o.TitleWeight
+	if title > 1 {
+		// ts_rank weights can't exceed 1, scale both down instead
+		description, title = description/title, 1
+	}
+	rank := fmt.Sprintf("ts_rank('{0.1, %s, %s, 1.0}', %s.tsv, query)", formatFloat(description), formatFloat(title), table)
+	if o.RecencyBoost > 0 {
+		rank += fmt.Sprintf(" * (1 + %s * exp(-extract(epoch from now() - %s.updated_at) / %d))", formatFloat(o.RecencyBoost), table, recencyScale)
+	}
+	return rank
+}
+
+// elasticFields returns the fields matched by full text queries in the
+// index with their boosts
+func (o Options) elasticFields() []string {
+	return []string{"title^" + formatFloat(o.TitleWeight), "description", "text", "comments"}
+}
+
+// elasticScore wraps the query in the recency boost
+func (o Options) elasticScore(query interface{}) interface{} {
+	if o.RecencyBoost <= 0 {
+		return query
+	}
+	return map[string]interface{}{
+		"function_score": map[string]interface{}{
+			"query": query,
+			"functions": []interface{}{
+				map[string]interface{}{"weight": 1},
+				map[string]interface{}{
+					"exp":    map[string]interface{}{"updated_at": map[string]interface{}{"origin": "now", "scale": "30d"}},
+					"weight": o.RecencyBoost,
+				},
+			},
+			"score_mode": "sum",
+			"boost_mode": "multiply",
+		},
+	}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// highlights returns the snippets of the titles and descriptions of the work
+// items matching the search string, by work item ID
+// returns InternalError
+func highlights(ctx context.Context, db *gorm.DB, sqlSearchQueryParameter string, ids []uint64) (map[string]map[string][]string, error) {
+	res := map[string]map[string][]string{}
+	if len(ids) == 0 {
+		return res, nil
+	}
+	options := fmt.Sprintf("StartSel=%s, StopSel=%s, MaxFragments=2", highlightStart, highlightStop)
+	rows, err := db.Raw(fmt.Sprintf(`SELECT id, ts_headline('english', coalesce(fields->>'%[1]s', ''), query, '%[3]s'),
+			ts_headline('english', coalesce(fields->>'%[2]s', ''), query, '%[3]s'),
+			coalesce(fields->>'%[2]s', '') @@ query
+		FROM work_items, to_tsquery('english', ?) AS query WHERE id IN (?)`, workitem.SystemTitle, workitem.SystemDescription, options),
+		sqlSearchQueryParameter, ids).Rows()
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id uint64
+		var title, description string
+		var inDescription bool
+		if err := rows.Scan(&id, &title, &description, &inDescription); err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		snippets := map[string][]string{"title": {title}}
+		if inDescription {
+			snippets["description"] = []string{description}
+		}
+		res[strconv.FormatUint(id, 10)] = snippets
+	}
+	return res, nil
+}
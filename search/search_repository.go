@@ -48,6 +48,11 @@ func generateSearchQuery(q string) (string, error) {
 }
 
 func convertFromModel(wiType workitem.WorkItemType, workItem workitem.WorkItem) (*app.WorkItem, error) {
+	fields, err := workitem.DecryptFields(wiType, workItem.Fields)
+	if err != nil {
+		return nil, err
+	}
+	workItem.Fields = fields
 	result := app.WorkItem{
 		ID:      strconv.FormatUint(workItem.ID, 10),
 		Type:    workItem.Type,
@@ -264,8 +269,8 @@ func generateSQLSearchInfo(keywords searchKeyword) (sqlParameter string) {
 
 // extracted this function from List() in order to close the rows object with "defer" for more readability
 // workaround for https://github.com/lib/pq/issues/81
-func (r *GormSearchRepository) search(ctx context.Context, sqlSearchQueryParameter string, workItemTypes []string, start *int, limit *int) ([]workitem.WorkItem, uint64, error) {
-	db := r.db.Model(workitem.WorkItem{}).Where("tsv @@ query")
+func (r *GormSearchRepository) search(ctx context.Context, sqlSearchQueryParameter string, workItemTypes []string, start *int, limit *int, opts Options) ([]workitem.WorkItem, uint64, error) {
+	db := r.db.Model(workitem.WorkItem{}).Where("tsv @@ query AND archived = ?", opts.Archived)
 	if start != nil {
 		if *start < 0 {
 			return nil, 0, errors.NewBadParameterError("start", *start)
@@ -280,15 +285,14 @@ func (r *GormSearchRepository) search(ctx context.Context, sqlSearchQueryParamet
 	}
 	if len(workItemTypes) > 0 {
 		// restrict to all given types and their subtypes
-		query := fmt.Sprintf("%[1]s.type in ("+
-			"select distinct subtype.name from %[2]s subtype "+
-			"join %[2]s supertype on subtype.path like (supertype.path || '%%') "+
-			"where supertype.name in (?))", workitem.WorkItem{}.TableName(), workitem.WorkItemType{}.TableName())
-		db = db.Where(query, workItemTypes)
+		db = db.Where(subtypesClause(workitem.WorkItem{}.TableName()), workItemTypes)
+	}
+	if clause, params := workitem.VisibilityClause(ctx, workitem.WorkItem{}.TableName()); clause != "" {
+		db = db.Where(clause, params...)
 	}
 
-	db = db.Select("count(*) over () as cnt2 , *")
-	db = db.Joins(", to_tsquery('english', ?) as query, ts_rank(tsv, query) as rank", sqlSearchQueryParameter)
+	db = db.Select("count(*) over () as cnt2 , *, "+opts.rankSQL(workitem.WorkItem{}.TableName())+" as rank")
+	db = db.Joins(", to_tsquery('english', ?) as query", sqlSearchQueryParameter)
 	db = db.Order(fmt.Sprintf("rank desc,%s.updated_at desc", workitem.WorkItem{}.TableName()))
 
 	rows, err := db.Rows()
@@ -336,6 +340,11 @@ func (r *GormSearchRepository) search(ctx context.Context, sqlSearchQueryParamet
 
 // SearchFullText Search returns work items for the given query
 func (r *GormSearchRepository) SearchFullText(ctx context.Context, rawSearchString string, start *int, limit *int) ([]*app.WorkItem, uint64, error) {
+	return r.searchFullText(ctx, rawSearchString, start, limit, DefaultOptions())
+}
+
+// searchFullText runs the full text search in Postgres ranked by the given options
+func (r *GormSearchRepository) searchFullText(ctx context.Context, rawSearchString string, start *int, limit *int, opts Options) ([]*app.WorkItem, uint64, error) {
 	// parse
 	// generateSearchQuery
 	// ....
@@ -346,7 +355,7 @@ func (r *GormSearchRepository) SearchFullText(ctx context.Context, rawSearchStri
 
 	sqlSearchQueryParameter := generateSQLSearchInfo(parsedSearchDict)
 	var rows []workitem.WorkItem
-	rows, count, err := r.search(ctx, sqlSearchQueryParameter, parsedSearchDict.workItemTypes, start, limit)
+	rows, count, err := r.search(ctx, sqlSearchQueryParameter, parsedSearchDict.workItemTypes, start, limit, opts)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -363,6 +372,7 @@ func (r *GormSearchRepository) SearchFullText(ctx context.Context, rawSearchStri
 		if err != nil {
 			return nil, 0, errors.NewConversionError(err.Error())
 		}
+		workitem.ContextViewer(ctx).Redact(*wiType, result[index])
 	}
 
 	return result, count, nil
@@ -5,6 +5,7 @@ import (
 
 	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/resource"
 	"github.com/almighty/almighty-core/search"
@@ -105,3 +106,119 @@ func (s *searchRepositoryBlackboxTest) TestRestrictByType() {
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), uint64(0), count)
 }
+
+func (s *searchRepositoryBlackboxTest) TestTypeahead() {
+	resource.Require(s.T(), resource.Database)
+	defer gormsupport.DeleteCreatedEntities(s.DB)()
+	wiRepo := workitem.NewWorkItemRepository(s.DB)
+	searchRepo := search.NewGormSearchRepository(s.DB)
+	ctx := context.Background()
+
+	contains, err := wiRepo.Create(ctx, workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle: "Crash in typeaheadtest login",
+		workitem.SystemState: workitem.SystemStateNew,
+	}, account.TestIdentity.ID.String())
+	require.Nil(s.T(), err)
+	prefix, err := wiRepo.Create(ctx, workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle: "Typeaheadtest crash",
+		workitem.SystemState: workitem.SystemStateNew,
+	}, account.TestIdentity.ID.String())
+	require.Nil(s.T(), err)
+	identity := account.Identity{FullName: "Typeaheadtest User"}
+	require.Nil(s.T(), account.NewIdentityRepository(s.DB).Create(ctx, &identity))
+
+	res, err := searchRepo.Typeahead(ctx, "typeaheadtest", []string{search.SuggestWorkItems}, 10)
+	require.Nil(s.T(), err)
+	require.Len(s.T(), res, 2)
+	assert.Equal(s.T(), prefix.ID, res[0].ID)
+	assert.Equal(s.T(), contains.ID, res[1].ID)
+
+	res, err = searchRepo.Typeahead(ctx, "#"+prefix.ID, []string{search.SuggestWorkItems}, 10)
+	require.Nil(s.T(), err)
+	require.NotEmpty(s.T(), res)
+	assert.Equal(s.T(), prefix.ID, res[0].ID)
+
+	res, err = searchRepo.Typeahead(ctx, "typeaheadtest us", nil, 10)
+	require.Nil(s.T(), err)
+	require.Len(s.T(), res, 1)
+	assert.Equal(s.T(), search.SuggestUsers, res[0].Kind)
+	assert.Equal(s.T(), identity.ID.String(), res[0].ID)
+
+	// wildcards are matched literally
+	res, err = searchRepo.Typeahead(ctx, "typeahead%", nil, 10)
+	require.Nil(s.T(), err)
+	assert.Empty(s.T(), res)
+
+	_, err = searchRepo.Typeahead(ctx, "crash", nil, search.MaxSuggestions+1)
+	assert.IsType(s.T(), errors.BadParameterError{}, err)
+	_, err = searchRepo.Typeahead(ctx, "crash", []string{"project"}, 10)
+	assert.IsType(s.T(), errors.BadParameterError{}, err)
+}
+
+func (s *searchRepositoryBlackboxTest) TestDuplicates() {
+	resource.Require(s.T(), resource.Database)
+	defer gormsupport.DeleteCreatedEntities(s.DB)()
+	wiRepo := workitem.NewWorkItemRepository(s.DB)
+	searchRepo := search.NewGormSearchRepository(s.DB)
+	ctx := context.Background()
+
+	same, err := wiRepo.Create(ctx, workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle:       "Duplicatetest login page crashes on submit",
+		workitem.SystemDescription: "Pressing the submit button crashes the login page",
+		workitem.SystemState:       workitem.SystemStateNew,
+	}, account.TestIdentity.ID.String())
+	require.Nil(s.T(), err)
+	similar, err := wiRepo.Create(ctx, workitem.SystemFeature, map[string]interface{}{
+		workitem.SystemTitle: "Duplicatetest login page crash",
+		workitem.SystemState: workitem.SystemStateNew,
+	}, account.TestIdentity.ID.String())
+	require.Nil(s.T(), err)
+	_, err = wiRepo.Create(ctx, workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle: "Unrelated dashboard colors",
+		workitem.SystemState: workitem.SystemStateNew,
+	}, account.TestIdentity.ID.String())
+	require.Nil(s.T(), err)
+
+	res, err := searchRepo.Duplicates(ctx, "Duplicatetest login page crashes on submit", "the submit button crashes", "", 5)
+	require.Nil(s.T(), err)
+	require.Len(s.T(), res, 2)
+	assert.Equal(s.T(), same.ID, res[0].Item.ID)
+	assert.Equal(s.T(), similar.ID, res[1].Item.ID)
+	assert.True(s.T(), res[0].Score > res[1].Score)
+
+	res, err = searchRepo.Duplicates(ctx, "Duplicatetest login page crashes on submit", "", workitem.SystemFeature, 5)
+	require.Nil(s.T(), err)
+	require.Len(s.T(), res, 1)
+	assert.Equal(s.T(), similar.ID, res[0].Item.ID)
+
+	_, err = searchRepo.Duplicates(ctx, " ", "", "", 5)
+	assert.IsType(s.T(), errors.BadParameterError{}, err)
+}
+
+func (s *searchRepositoryBlackboxTest) TestSearchArchived() {
+	resource.Require(s.T(), resource.Database)
+	undoScript := &gormsupport.DBScript{}
+	defer undoScript.Run(s.DB)
+	wiRepo := workitem.NewUndoableWorkItemRepository(workitem.NewWorkItemRepository(s.DB), undoScript)
+	searchRepo := search.NewGormSearchRepository(s.DB)
+	ctx := context.Background()
+
+	wi, err := wiRepo.Create(ctx, workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle: "Test TestSearchArchived",
+		workitem.SystemState: "closed",
+	}, account.TestIdentity.ID.String())
+	require.Nil(s.T(), err)
+	_, err = workitem.NewArchiveRepository(s.DB).Archive(ctx, wi.ID, true)
+	require.Nil(s.T(), err)
+
+	res, err := searchRepo.SearchFaceted(ctx, "TestSearchArchived", nil, nil, search.DefaultOptions())
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), uint64(0), res.Count)
+
+	opts := search.DefaultOptions()
+	opts.Archived = true
+	res, err = searchRepo.SearchFaceted(ctx, "TestSearchArchived", nil, nil, opts)
+	require.Nil(s.T(), err)
+	require.Equal(s.T(), uint64(1), res.Count)
+	assert.Equal(s.T(), wi.ID, res.Items[0].ID)
+}
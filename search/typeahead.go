@@ -0,0 +1,147 @@
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// Kinds of typeahead suggestions
+const (
+	SuggestWorkItems = "workitem"
+	SuggestUsers     = "user"
+)
+
+// MaxSuggestions is the largest number of suggestions per kind
+const MaxSuggestions = 25
+
+// Suggestion is a single typeahead match
+type Suggestion struct {
+	Kind string
+	// ID is the work item ID or the identity ID
+	ID    string
+	Title string
+	// ImageURL is only set for users
+	ImageURL string
+}
+
+// Typeahead returns the work items whose ID starts with or whose title
+// contains the given text and the users whose name contains it or whose email
+// starts with it, prefix matches first. Only the given kinds are suggested,
+// no kinds means all of them.
+// returns BadParameterError or InternalError
+func (r *GormSearchRepository) Typeahead(ctx context.Context, text string, kinds []string, limit int) ([]Suggestion, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "search", "typeahead"}, time.Now())
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, errors.NewBadParameterError("q", text).Expected("not empty")
+	}
+	if limit <= 0 || limit > MaxSuggestions {
+		return nil, errors.NewBadParameterError("limit", limit).Expected(fmt.Sprintf("between 1 and %d", MaxSuggestions))
+	}
+	if len(kinds) == 0 {
+		kinds = []string{SuggestWorkItems, SuggestUsers}
+	}
+	res := []Suggestion{}
+	for _, kind := range kinds {
+		var found []Suggestion
+		var err error
+		switch kind {
+		case SuggestWorkItems:
+			found, err = r.suggestWorkItems(ctx, text, limit)
+		case SuggestUsers:
+			found, err = r.suggestUsers(ctx, text, limit)
+		default:
+			return nil, errors.NewBadParameterError("type", kind).Expected([]string{SuggestWorkItems, SuggestUsers})
+		}
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, found...)
+	}
+	return res, nil
+}
+
+// keyPrefixPattern matches the beginnings of work item keys typed with the
+// dash, e.g. ALM- or ALM-4
+var keyPrefixPattern = regexp.MustCompile(`^[A-Z][A-Z0-9]{1,9}-[0-9]*$`)
+
+func (r *GormSearchRepository) suggestWorkItems(ctx context.Context, text string, limit int) ([]Suggestion, error) {
+	table := workitem.WorkItem{}.TableName()
+	db := r.db.Table(table).Where("deleted_at IS NULL")
+	if clause, params := workitem.VisibilityClause(ctx, table); clause != "" {
+		db = db.Where(clause, params...)
+	}
+	number := strings.TrimPrefix(text, "#")
+	if n, err := strconv.ParseUint(number, 10, 64); err == nil {
+		db = db.Select("id").Where("id::text LIKE ?", number+"%").Order(fmt.Sprintf("id = %d DESC, id", n))
+	} else if key := strings.ToUpper(number); keyPrefixPattern.MatchString(key) {
+		db = db.Select("id").Where("key LIKE ?", key+"%").Order("length(key), key")
+	} else {
+		title := "fields->>'" + workitem.SystemTitle + "'"
+		db = db.Select("id, "+title+" ILIKE ? AS prefix, similarity("+title+", ?) AS score", escapeLike(text)+"%", text).
+			Where(title+" ILIKE ?", "%"+escapeLike(text)+"%").
+			Order("prefix DESC, score DESC, updated_at DESC")
+	}
+	var rows []struct{ ID uint64 }
+	if err := db.Limit(limit).Scan(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	ids := make([]uint64, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	// loading them again applies the field visibility to the titles
+	items, err := r.loadAll(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]Suggestion, 0, len(items))
+	for _, wi := range items {
+		title, ok := wi.Fields[workitem.SystemTitle].(string)
+		if !ok {
+			continue
+		}
+		res = append(res, Suggestion{Kind: SuggestWorkItems, ID: wi.ID, Title: title})
+	}
+	return res, nil
+}
+
+func (r *GormSearchRepository) suggestUsers(ctx context.Context, text string, limit int) ([]Suggestion, error) {
+	pattern := escapeLike(text)
+	var rows []struct {
+		ID       uuid.UUID
+		FullName string
+		ImageURL string
+	}
+	err := r.db.Table(account.Identity{}.TableName()).
+		Select("DISTINCT identities.id, identities.full_name, identities.image_url, identities.full_name ILIKE ? AS prefix", pattern+"%").
+		Joins("LEFT JOIN users ON users.identity_id = identities.id AND users.deleted_at IS NULL").
+		Where("identities.deleted_at IS NULL").
+		Where("identities.full_name ILIKE ? OR users.email ILIKE ?", "%"+pattern+"%", pattern+"%").
+		Order("prefix DESC, identities.full_name").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	res := make([]Suggestion, 0, len(rows))
+	for _, u := range rows {
+		res = append(res, Suggestion{Kind: SuggestUsers, ID: u.ID.String(), Title: u.FullName, ImageURL: u.ImageURL})
+	}
+	return res, nil
+}
+
+// escapeLike escapes the wildcards of LIKE patterns in s
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
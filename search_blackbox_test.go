@@ -14,6 +14,7 @@ import (
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/goadesign/goa"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
 )
 
@@ -44,7 +45,7 @@ func TestSearch(t *testing.T) {
 
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	q := "specialwordforsearch"
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, nil, q, nil, nil)
 	r := sr.Data[0]
 	assert.Equal(t, "specialwordforsearch", r.Attributes[workitem.SystemTitle])
 }
@@ -69,7 +70,7 @@ func TestSearchPagination(t *testing.T) {
 
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	q := "specialwordforsearch2"
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, nil, q, nil, nil)
 	assert.Equal(t, "http:///api/search?q=specialwordforsearch2&page[offset]=0&page[limit]=100", *sr.Links.First)
 	assert.Equal(t, "http:///api/search?q=specialwordforsearch2&page[offset]=0&page[limit]=100", *sr.Links.Last)
 	r := sr.Data[0]
@@ -95,7 +96,7 @@ func TestSearchWithEmptyValue(t *testing.T) {
 
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	q := ""
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, nil, q, nil, nil)
 	assert.Equal(t, 0, len(sr.Data))
 }
 
@@ -118,7 +119,7 @@ func TestSearchWithDomainPortCombination(t *testing.T) {
 
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	q := `"http://localhost:8080/detail/154687364529310"`
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, nil, q, nil, nil)
 	assert.NotEqual(t, 0, len(sr.Data))
 	r := sr.Data[0]
 	assert.Equal(t, expectedDescription, r.Attributes[workitem.SystemDescription])
@@ -144,7 +145,7 @@ func TestSearchURLWithoutPort(t *testing.T) {
 
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	q := `"http://localhost/detail/876394"`
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, nil, q, nil, nil)
 	assert.NotEqual(t, 0, len(sr.Data))
 	r := sr.Data[0]
 	assert.Equal(t, expectedDescription, r.Attributes[workitem.SystemDescription])
@@ -170,7 +171,7 @@ func TestUnregisteredURLWithPort(t *testing.T) {
 
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	q := `http://some-other-domain:8080/different-path/`
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, nil, q, nil, nil)
 	assert.NotEqual(t, 0, len(sr.Data))
 	r := sr.Data[0]
 	assert.Equal(t, expectedDescription, r.Attributes[workitem.SystemDescription])
@@ -197,6 +198,61 @@ func TestUnwantedCharactersRelatedToSearchLogic(t *testing.T) {
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	// add url: in the query, that is not expected by the code hence need to make sure it gives expected result.
 	q := `http://url:some-random-other-domain:8080/different-path/`
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, nil, q, nil, nil)
 	assert.Equal(t, 0, len(sr.Data))
 }
+
+func TestTypeahead(t *testing.T) {
+	resource.Require(t, resource.Database)
+	defer gormsupport.DeleteCreatedEntities(DB)()
+	service := getServiceAsUser()
+	wiRepo := workitem.NewWorkItemRepository(DB)
+
+	wi, err := wiRepo.Create(
+		context.Background(),
+		workitem.SystemBug,
+		map[string]interface{}{
+			workitem.SystemTitle:   "typeaheadcontrollertest title",
+			workitem.SystemCreator: "baijum",
+			workitem.SystemState:   workitem.SystemStateNew,
+		},
+		"")
+	require.Nil(t, err)
+
+	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
+	kind := "workitem"
+	_, res := test.TypeaheadSearchOK(t, nil, nil, controller, nil, "typeaheadcontrollertest", &kind)
+	require.Len(t, res.Data, 1)
+	assert.Equal(t, "workitems", res.Data[0].Type)
+	assert.Equal(t, wi.ID, res.Data[0].ID)
+	assert.Equal(t, "typeaheadcontrollertest title", res.Data[0].Attributes.Title)
+
+	limit := 100
+	test.TypeaheadSearchBadRequest(t, nil, nil, controller, &limit, "typeaheadcontrollertest", nil)
+}
+
+func TestDuplicates(t *testing.T) {
+	resource.Require(t, resource.Database)
+	defer gormsupport.DeleteCreatedEntities(DB)()
+	service := getServiceAsUser()
+	wiRepo := workitem.NewWorkItemRepository(DB)
+
+	wi, err := wiRepo.Create(
+		context.Background(),
+		workitem.SystemBug,
+		map[string]interface{}{
+			workitem.SystemTitle:   "duplicatecontrollertest export fails",
+			workitem.SystemCreator: "baijum",
+			workitem.SystemState:   workitem.SystemStateNew,
+		},
+		"")
+	require.Nil(t, err)
+
+	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
+	_, res := test.DuplicatesSearchOK(t, nil, nil, controller, nil, nil, "duplicatecontrollertest export fails", nil)
+	require.Len(t, res.Data, 1)
+	assert.Equal(t, wi.ID, *res.Data[0].ID)
+	assert.InDelta(t, 1.0, res.Meta.Scores[wi.ID], 0.01)
+
+	test.DuplicatesSearchBadRequest(t, nil, nil, controller, nil, nil, " ", nil)
+}
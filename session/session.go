@@ -0,0 +1,221 @@
+// Package session tracks the tokens issued to identities, so a leaked token
+// can be revoked right away. Every token carries the ID of its session, the
+// requests of tokens whose session was revoked, expired or is unknown are
+// refused. Tokens issued before sessions were tracked carry no ID, they can't
+// be revoked one by one: revoking all the sessions of an identity sets its
+// cutoff, the tokens without a session issued before it are refused.
+package session
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/token"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// TouchInterval is how often the last use of a session is recorded at most
+const TouchInterval = time.Minute
+
+// Session is a token issued to an identity
+type Session struct {
+	// ID is the "jti" claim of the token
+	ID         uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	CreatedAt  time.Time
+	IdentityID uuid.UUID `sql:"type:uuid"`
+	// ImpersonatorID is the instance admin acting as the identity, nil
+	// unless the token is an impersonation
+	ImpersonatorID *uuid.UUID `sql:"type:uuid"`
+	// UserAgent is the client the token was issued to
+	UserAgent string
+	// ExpiresAt is when the token expires, nil if it doesn't
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// TableName implements gorm.tabler
+func (s Session) TableName() string {
+	return "sessions"
+}
+
+// Active returns true if the session is neither revoked nor expired at the
+// given time
+func (s Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && (s.ExpiresAt == nil || now.Before(*s.ExpiresAt))
+}
+
+// activeClause selects the sessions that are neither revoked nor expired
+const activeClause = "revoked_at IS NULL AND (expires_at IS NULL OR expires_at > now())"
+
+// Record records the session of the token that was just issued, the ID of
+// the session is the one the token carries
+// returns BadParameterError or InternalError
+func Record(ctx context.Context, repo Repository, tm token.Manager, tokenString string, s *Session) error {
+	id, err := tm.ExtractSessionID(tokenString)
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if id == nil {
+		return errors.NewInternalError("the token carries no session ID")
+	}
+	s.ID = *id
+	return repo.Create(ctx, s)
+}
+
+// cutoff is when all the tokens of an identity were last revoked
+type cutoff struct {
+	IdentityID    uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	RevokedBefore time.Time
+}
+
+// TableName implements gorm.tabler
+func (c cutoff) TableName() string {
+	return "session_cutoffs"
+}
+
+// Repository encapsulates storage & retrieval of sessions
+type Repository interface {
+	Create(ctx context.Context, s *Session) error
+	Load(ctx context.Context, ID uuid.UUID) (*Session, error)
+	List(ctx context.Context, identityID uuid.UUID, offset int, limit int) ([]Session, int, error)
+	Revoke(ctx context.Context, ID uuid.UUID) error
+	RevokeAll(ctx context.Context, identityID uuid.UUID, except *uuid.UUID) (int, error)
+	RevokedBefore(ctx context.Context, identityID uuid.UUID) (*time.Time, error)
+	Touch(ctx context.Context, ID uuid.UUID) error
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for
+// sessions.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Create records the session of a token that was just issued
+// returns BadParameterError or InternalError
+func (m *GormRepository) Create(ctx context.Context, s *Session) error {
+	defer goa.MeasureSince([]string{"goa", "db", "session", "create"}, time.Now())
+
+	if uuid.Equal(s.ID, uuid.Nil) {
+		return errors.NewBadParameterError("id", s.ID).Expected("the jti claim of the token")
+	}
+	if err := m.db.Create(s).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// Load returns the session with the given ID, revoked and expired sessions
+// included
+// returns NotFoundError or InternalError
+func (m *GormRepository) Load(ctx context.Context, ID uuid.UUID) (*Session, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "session", "load"}, time.Now())
+
+	var res Session
+	tx := m.db.Where("id = ?", ID).First(&res)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("session", ID.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &res, nil
+}
+
+// List returns a page of the active sessions of the identity, the latest
+// first with the total number of them
+// returns InternalError
+func (m *GormRepository) List(ctx context.Context, identityID uuid.UUID, offset int, limit int) ([]Session, int, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "session", "list"}, time.Now())
+
+	db := m.db.Model(&Session{}).Where("identity_id = ? AND "+activeClause, identityID)
+	var total int
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, errors.NewInternalError(err.Error())
+	}
+	res := []Session{}
+	if err := db.Order("created_at DESC, id").Offset(offset).Limit(limit).Find(&res).Error; err != nil {
+		return nil, 0, errors.NewInternalError(err.Error())
+	}
+	return res, total, nil
+}
+
+// Revoke revokes the active session with the given ID
+// returns NotFoundError or InternalError
+func (m *GormRepository) Revoke(ctx context.Context, ID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "session", "revoke"}, time.Now())
+
+	tx := m.db.Model(&Session{}).Where("id = ? AND "+activeClause, ID).UpdateColumn("revoked_at", time.Now())
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("session", ID.String())
+	}
+	return nil
+}
+
+// RevokeAll revokes the active sessions of the identity but the excepted one
+// if not nil and returns how many were revoked. The tokens of the identity
+// without a session are revoked as well.
+// returns InternalError
+func (m *GormRepository) RevokeAll(ctx context.Context, identityID uuid.UUID, except *uuid.UUID) (int, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "session", "revokeall"}, time.Now())
+
+	now := time.Now()
+	db := m.db.Model(&Session{}).Where("identity_id = ? AND "+activeClause, identityID)
+	if except != nil {
+		db = db.Where("id <> ?", *except)
+	}
+	tx := db.UpdateColumn("revoked_at", now)
+	if tx.Error != nil {
+		return 0, errors.NewInternalError(tx.Error.Error())
+	}
+	err := m.db.Exec(`INSERT INTO session_cutoffs (identity_id, revoked_before) VALUES (?, ?)
+		ON CONFLICT (identity_id) DO UPDATE SET revoked_before = excluded.revoked_before`, identityID, now).Error
+	if err != nil {
+		return 0, errors.NewInternalError(err.Error())
+	}
+	return int(tx.RowsAffected), nil
+}
+
+// RevokedBefore returns the cutoff of the identity, the tokens without a
+// session issued before it are revoked, nil if there is none
+// returns InternalError
+func (m *GormRepository) RevokedBefore(ctx context.Context, identityID uuid.UUID) (*time.Time, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "session", "revokedbefore"}, time.Now())
+
+	var res cutoff
+	tx := m.db.Where("identity_id = ?", identityID).First(&res)
+	if tx.RecordNotFound() {
+		return nil, nil
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &res.RevokedBefore, nil
+}
+
+// Touch records the use of the session unless it was recorded within the
+// TouchInterval
+// returns InternalError
+func (m *GormRepository) Touch(ctx context.Context, ID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "session", "touch"}, time.Now())
+
+	now := time.Now()
+	err := m.db.Model(&Session{}).Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", ID, now.Add(-TouchInterval)).
+		UpdateColumn("last_used_at", now).Error
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package session_test
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/session"
+	"github.com/almighty/almighty-core/token"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestActive(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+	assert.True(t, session.Session{}.Active(now))
+	assert.True(t, session.Session{ExpiresAt: &future}.Active(now))
+	assert.False(t, session.Session{ExpiresAt: &past}.Active(now))
+	assert.False(t, session.Session{RevokedAt: &past}.Active(now))
+}
+
+type TestSessionRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunSessionRepository(t *testing.T) {
+	suite.Run(t, &TestSessionRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestSessionRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestSessionRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestSessionRepository) TestRecordAndRevoke() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := session.NewRepository(test.DB)
+	publicKey, err := token.ParsePublicKey([]byte(token.RSAPublicKey))
+	require.Nil(t, err)
+	privateKey, err := token.ParsePrivateKey([]byte(token.RSAPrivateKey))
+	require.Nil(t, err)
+	tm := token.NewManager(publicKey, privateKey)
+	identity := account.Identity{ID: uuid.NewV4(), FullName: "Mr Test Case"}
+
+	var ids []uuid.UUID
+	for i := 0; i < 3; i++ {
+		tokenStr, err := tm.Generate(identity)
+		require.Nil(t, err)
+		s := &session.Session{IdentityID: identity.ID, UserAgent: "test"}
+		require.Nil(t, session.Record(ctx, repo, tm, tokenStr, s))
+		id, err := tm.ExtractSessionID(tokenStr)
+		require.Nil(t, err)
+		assert.Equal(t, *id, s.ID)
+		ids = append(ids, s.ID)
+	}
+	expired := time.Now().Add(-time.Minute)
+	require.Nil(t, repo.Create(ctx, &session.Session{ID: uuid.NewV4(), IdentityID: identity.ID, ExpiresAt: &expired}))
+
+	res, total, err := repo.List(ctx, identity.ID, 0, 10)
+	require.Nil(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, res, 3)
+	assert.Equal(t, ids[2], res[0].ID)
+	assert.Equal(t, "test", res[0].UserAgent)
+
+	require.Nil(t, repo.Touch(ctx, ids[0]))
+	s, err := repo.Load(ctx, ids[0])
+	require.Nil(t, err)
+	require.NotNil(t, s.LastUsedAt)
+	used := *s.LastUsedAt
+	// the use is recorded once per interval
+	require.Nil(t, repo.Touch(ctx, ids[0]))
+	s, err = repo.Load(ctx, ids[0])
+	require.Nil(t, err)
+	assert.True(t, used.Equal(*s.LastUsedAt))
+
+	require.Nil(t, repo.Revoke(ctx, ids[0]))
+	s, err = repo.Load(ctx, ids[0])
+	require.Nil(t, err)
+	assert.False(t, s.Active(time.Now()))
+	assert.IsType(t, errors.NotFoundError{}, repo.Revoke(ctx, ids[0]))
+	assert.IsType(t, errors.NotFoundError{}, repo.Revoke(ctx, uuid.NewV4()))
+
+	cutoff, err := repo.RevokedBefore(ctx, identity.ID)
+	require.Nil(t, err)
+	assert.Nil(t, cutoff)
+	n, err := repo.RevokeAll(ctx, identity.ID, &ids[2])
+	require.Nil(t, err)
+	assert.Equal(t, 1, n)
+	res, total, err = repo.List(ctx, identity.ID, 0, 10)
+	require.Nil(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, res, 1)
+	assert.Equal(t, ids[2], res[0].ID)
+
+	n, err = repo.RevokeAll(ctx, identity.ID, nil)
+	require.Nil(t, err)
+	assert.Equal(t, 1, n)
+	cutoff, err = repo.RevokedBefore(ctx, identity.ID)
+	require.Nil(t, err)
+	require.NotNil(t, cutoff)
+	assert.True(t, cutoff.After(s.CreatedAt))
+
+	_, err = repo.Load(ctx, uuid.NewV4())
+	assert.IsType(t, errors.NotFoundError{}, err)
+	assert.IsType(t, errors.BadParameterError{}, repo.Create(ctx, &session.Session{IdentityID: identity.ID}))
+}
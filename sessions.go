@@ -0,0 +1,209 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/session"
+	"github.com/almighty/almighty-core/token"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// SessionsController implements the sessions resource.
+type SessionsController struct {
+	*goa.Controller
+	db           application.DB
+	tokenManager token.Manager
+}
+
+// NewSessionsController creates a sessions controller.
+func NewSessionsController(service *goa.Service, db application.DB, tokenManager token.Manager) *SessionsController {
+	return &SessionsController{Controller: service.NewController("SessionsController"), db: db, tokenManager: tokenManager}
+}
+
+// List runs the list action.
+func (c *SessionsController) List(ctx *app.ListSessionsContext) error {
+	identityID, err := sessionsOf(ctx, ctx.FilterIdentity)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	current, _ := c.tokenManager.LocateSessionID(ctx)
+	offset, limit := computePagingLimts(ctx.PageOffset, ctx.PageLimit)
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		sessions, total, err := appl.Sessions().List(ctx, identityID, offset, limit)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.SessionList{
+			Data:  make([]*app.Session, len(sessions)),
+			Links: &app.PagingLinks{},
+			Meta:  &app.ProjectListMeta{TotalCount: total},
+		}
+		for i, s := range sessions {
+			res.Data[i] = convertSession(s, current)
+		}
+		var extra []string
+		if ctx.FilterIdentity != nil {
+			extra = append(extra, "filter[identity]="+ctx.FilterIdentity.String())
+		}
+		setPagingLinks(res.Links, buildAbsoluteURL(ctx.RequestData), len(sessions), offset, limit, total, extra...)
+		return ctx.OK(res)
+	})
+}
+
+// Revoke runs the revoke action.
+func (c *SessionsController) Revoke(ctx *app.RevokeSessionsContext) error {
+	if impersonator, err := c.tokenManager.LocateImpersonator(ctx); err != nil || impersonator != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("impersonation tokens can't revoke sessions"))
+	}
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing identity"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		s, err := appl.Sessions().Load(ctx, ctx.SessionID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if !uuid.Equal(s.IdentityID, *identityID) && !isInstanceAdmin(ctx) {
+			// don't tell the sessions of others exist
+			return jsonapi.JSONErrorResponse(ctx, errors.NewNotFoundError("session", ctx.SessionID.String()))
+		}
+		if err := appl.Sessions().Revoke(ctx, s.ID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		goa.LogInfo(ctx, "session revoked", "session", s.ID.String(), "identity", s.IdentityID.String(), "by", identityID.String())
+		return ctx.NoContent()
+	})
+}
+
+// RevokeAll runs the revoke-all action.
+func (c *SessionsController) RevokeAll(ctx *app.RevokeAllSessionsContext) error {
+	if impersonator, err := c.tokenManager.LocateImpersonator(ctx); err != nil || impersonator != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("impersonation tokens can't revoke sessions"))
+	}
+	identityID, err := sessionsOf(ctx, ctx.FilterIdentity)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	// keep the session the request is made with
+	var except *uuid.UUID
+	if uuid.Equal(identityID, *currentIdentityID(ctx)) {
+		except, _ = c.tokenManager.LocateSessionID(ctx)
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		n, err := appl.Sessions().RevokeAll(ctx, identityID, except)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		goa.LogInfo(ctx, "sessions revoked", "count", n, "identity", identityID.String(), "by", currentIdentityID(ctx).String())
+		return ctx.NoContent()
+	})
+}
+
+// sessionsOf returns the identity whose sessions the request is about, the
+// current one unless an instance admin filtered for another one
+func sessionsOf(ctx context.Context, filter *uuid.UUID) (uuid.UUID, error) {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return uuid.Nil, goa.ErrUnauthorized("missing identity")
+	}
+	if filter == nil || uuid.Equal(*filter, *identityID) {
+		return *identityID, nil
+	}
+	if !isInstanceAdmin(ctx) {
+		return uuid.Nil, goa.ErrUnauthorized("only instance admins can manage the sessions of other identities")
+	}
+	return *filter, nil
+}
+
+func convertSession(s session.Session, current *uuid.UUID) *app.Session {
+	return &app.Session{
+		ID:           s.ID,
+		Identity:     s.IdentityID,
+		Impersonator: s.ImpersonatorID,
+		UserAgent:    s.UserAgent,
+		CreatedAt:    s.CreatedAt,
+		ExpiresAt:    s.ExpiresAt,
+		LastUsedAt:   s.LastUsedAt,
+		Current:      current != nil && uuid.Equal(*current, s.ID),
+	}
+}
+
+// CheckSession is a middleware refusing the requests whose bearer token
+// belongs to a revoked, expired or unknown session before anything else
+// trusts the token, and recording the use of the other sessions. Tokens
+// issued before sessions were tracked carry no session, they are refused if
+// they were issued before the cutoff of their identity.
+func CheckSession(db application.DB, tm token.Manager) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			auth := req.Header.Get("Authorization")
+			if !strings.HasPrefix(auth, "Bearer ") {
+				return h(ctx, rw, req)
+			}
+			tokenStr := strings.TrimPrefix(auth, "Bearer ")
+			identity, err := tm.Extract(tokenStr)
+			if err != nil {
+				// invalid tokens are refused by the security of the actions
+				return h(ctx, rw, req)
+			}
+			sessionID, err := tm.ExtractSessionID(tokenStr)
+			if err != nil {
+				return h(ctx, rw, req)
+			}
+			if sessionID == nil {
+				return checkLegacyToken(ctx, db, tm, tokenStr, identity.ID, func() error { return h(ctx, rw, req) })
+			}
+			var s *session.Session
+			err = application.Transactional(db, func(appl application.Application) error {
+				var err error
+				s, err = appl.Sessions().Load(ctx, *sessionID)
+				return err
+			})
+			if _, ok := err.(errors.NotFoundError); err != nil && !ok {
+				return err
+			}
+			if s == nil || !s.Active(time.Now()) {
+				return goa.ErrUnauthorized("the session of the token was revoked or expired")
+			}
+			if s.LastUsedAt == nil || time.Since(*s.LastUsedAt) >= session.TouchInterval {
+				// the use isn't recorded while the database is read only
+				application.Transactional(db, func(appl application.Application) error {
+					return appl.Sessions().Touch(ctx, s.ID)
+				})
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
+
+// checkLegacyToken calls next unless the token without a session was issued
+// before the cutoff of the identity, tokens that don't tell when they were
+// issued predate any cutoff
+func checkLegacyToken(ctx context.Context, db application.DB, tm token.Manager, tokenStr string, identityID uuid.UUID, next func() error) error {
+	var revokedBefore *time.Time
+	err := application.Transactional(db, func(appl application.Application) error {
+		var err error
+		revokedBefore, err = appl.Sessions().RevokedBefore(ctx, identityID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if revokedBefore != nil {
+		issuedAt, err := tm.ExtractIssuedAt(tokenStr)
+		if err != nil || issuedAt.Before(*revokedBefore) {
+			return goa.ErrUnauthorized("the token was revoked")
+		}
+	}
+	return next()
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/session"
+	"github.com/almighty/almighty-core/token"
+	jwt "github.com/dgrijalva/jwt-go"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSession(t *testing.T) {
+	resource.Require(t, resource.Database)
+	defer gormsupport.DeleteCreatedEntities(DB)()
+
+	publicKey, err := token.ParsePublicKey([]byte(token.RSAPublicKey))
+	require.Nil(t, err)
+	privateKey, err := token.ParsePrivateKey([]byte(token.RSAPrivateKey))
+	require.Nil(t, err)
+	tm := token.NewManager(publicKey, privateKey)
+	ctx := context.Background()
+	sessions := session.NewRepository(DB)
+	identity := account.Identity{ID: uuid.NewV4(), FullName: "Mr Test Case"}
+
+	h := CheckSession(gormapplication.NewGormDB(DB), tm)(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		return nil
+	})
+	status := func(tokenStr string) int {
+		req, err := http.NewRequest("GET", "/api/user", nil)
+		require.Nil(t, err)
+		req.Header.Set("Authorization", "Bearer "+tokenStr)
+		if err := h(ctx, httptest.NewRecorder(), req); err != nil {
+			_, status := jsonapi.ErrorToJSONAPIErrors(err)
+			return status
+		}
+		return http.StatusOK
+	}
+
+	// tokens issued before sessions were tracked carry neither a session
+	// nor when they were issued
+	legacy := jwt.New(jwt.SigningMethodRS256)
+	legacy.Claims.(jwt.MapClaims)["uuid"] = identity.ID.String()
+	legacy.Claims.(jwt.MapClaims)["fullName"] = identity.FullName
+	legacy.Claims.(jwt.MapClaims)["imageURL"] = identity.ImageURL
+	legacyStr, err := legacy.SignedString(privateKey)
+	require.Nil(t, err)
+
+	current, err := tm.Generate(identity)
+	require.Nil(t, err)
+	require.Nil(t, session.Record(ctx, sessions, tm, current, &session.Session{IdentityID: identity.ID}))
+	other, err := tm.Generate(identity)
+	require.Nil(t, err)
+	require.Nil(t, session.Record(ctx, sessions, tm, other, &session.Session{IdentityID: identity.ID}))
+	unknown, err := tm.Generate(identity)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, status(legacyStr))
+	assert.Equal(t, http.StatusOK, status(current))
+	assert.Equal(t, http.StatusOK, status(other))
+	assert.Equal(t, http.StatusUnauthorized, status(unknown))
+
+	currentID, err := tm.ExtractSessionID(current)
+	require.Nil(t, err)
+	_, err = sessions.RevokeAll(ctx, identity.ID, currentID)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, status(legacyStr))
+	assert.Equal(t, http.StatusUnauthorized, status(other))
+	assert.Equal(t, http.StatusOK, status(current))
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"log"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/goadesign/goa"
+)
+
+// SettingsController implements the settings resource.
+type SettingsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewSettingsController creates a settings controller.
+func NewSettingsController(service *goa.Service, db application.DB) *SettingsController {
+	return &SettingsController{Controller: service.NewController("SettingsController"), db: db}
+}
+
+// List runs the list action.
+func (c *SettingsController) List(ctx *app.ListSettingsContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can manage settings"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		overrides, err := appl.Settings().Values(ctx)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.SettingList{Data: []*app.Setting{}}
+		for _, name := range configuration.Tunables() {
+			_, overridden := overrides[name]
+			res.Data = append(res.Data, ConvertSetting(name, configuration.TunableValue(name), overridden))
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Update runs the update action.
+func (c *SettingsController) Update(ctx *app.UpdateSettingsContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can manage settings"))
+	}
+	if ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	err := application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err := appl.Settings().Save(ctx, ctx.Name, ctx.Payload.Data.Attributes.Value)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	if err := reloadConfiguration(ctx, c.db); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.SettingSingle{
+		Data: ConvertSetting(ctx.Name, configuration.TunableValue(ctx.Name), true),
+	})
+}
+
+// Delete runs the delete action.
+func (c *SettingsController) Delete(ctx *app.DeleteSettingsContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can manage settings"))
+	}
+	err := application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		return appl.Settings().Delete(ctx, ctx.Name)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	if err := reloadConfiguration(ctx, c.db); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK([]byte{})
+}
+
+// Reload runs the reload action.
+func (c *SettingsController) Reload(ctx *app.ReloadSettingsContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can manage settings"))
+	}
+	if err := reloadConfiguration(ctx, c.db); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.NoContent()
+}
+
+// ConvertSetting converts between internal and external REST representation
+func ConvertSetting(name string, value string, overridden bool) *app.Setting {
+	return &app.Setting{
+		Type: "settings",
+		ID:   &name,
+		Attributes: &app.SettingAttributes{
+			Value:      value,
+			Overridden: &overridden,
+		},
+	}
+}
+
+// reloadConfiguration applies the stored runtime settings and the tunable
+// variables of the config file. Every server reloads on its own, either
+// through the reload action or on SIGHUP.
+func reloadConfiguration(ctx context.Context, db application.DB) error {
+	var values map[string]string
+	err := application.Transactional(db, func(appl application.Application) error {
+		var err error
+		values, err = appl.Settings().Values(ctx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := configuration.Reload(values); err != nil {
+		return errors.NewBadParameterError("configuration", err.Error())
+	}
+	applyMaintenance()
+	log.Printf("reloaded %d runtime settings\n", len(values))
+	return nil
+}
+
+// isInstanceAdmin returns true if the current identity is one of the
+// configured instance admins
+func isInstanceAdmin(ctx context.Context) bool {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return false
+	}
+	for _, id := range configuration.GetAdminIdentities() {
+		if id == identityID.String() {
+			return true
+		}
+	}
+	return false
+}
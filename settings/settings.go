@@ -0,0 +1,107 @@
+// Package settings stores the runtime settings that override the tunable
+// configuration variables, see configuration.Reload.
+package settings
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+)
+
+// Setting overrides the value of a tunable configuration variable
+type Setting struct {
+	gormsupport.Lifecycle
+	Name  string `gorm:"primary_key"`
+	Value string
+}
+
+// TableName implements gorm.tabler
+func (s Setting) TableName() string {
+	return "settings"
+}
+
+// Repository encapsulates storage & retrieval of settings
+type Repository interface {
+	List(ctx context.Context) ([]*Setting, error)
+	Save(ctx context.Context, name string, value string) (*Setting, error)
+	Delete(ctx context.Context, name string) error
+	Values(ctx context.Context) (map[string]string, error)
+}
+
+// NewSettingRepository creates a new storage type.
+func NewSettingRepository(db *gorm.DB) Repository {
+	return &GormSettingRepository{db: db}
+}
+
+// GormSettingRepository is the implementation of the storage interface for
+// settings.
+type GormSettingRepository struct {
+	db *gorm.DB
+}
+
+// List returns all settings ordered by name
+// returns InternalError
+func (m *GormSettingRepository) List(ctx context.Context) ([]*Setting, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "setting", "list"}, time.Now())
+
+	var objs []*Setting
+	if err := m.db.Order("name").Find(&objs).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return objs, nil
+}
+
+// Save creates or replaces the setting of the given tunable variable
+// returns BadParameterError or InternalError
+func (m *GormSettingRepository) Save(ctx context.Context, name string, value string) (*Setting, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "setting", "save"}, time.Now())
+
+	if err := configuration.ValidateTunable(name, value); err != nil {
+		return nil, errors.NewBadParameterError(name, value).Expected(err.Error())
+	}
+	tx := m.db.Exec(`INSERT INTO settings (name, value, created_at, updated_at) VALUES (?, ?, now(), now())
+		ON CONFLICT (name) DO UPDATE SET value = excluded.value, updated_at = now(), deleted_at = NULL`, name, value)
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	var obj Setting
+	if err := m.db.Where("name = ?", name).First(&obj).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return &obj, nil
+}
+
+// Delete removes the setting so the variable falls back to the configuration
+// returns NotFoundError or InternalError
+func (m *GormSettingRepository) Delete(ctx context.Context, name string) error {
+	defer goa.MeasureSince([]string{"goa", "db", "setting", "delete"}, time.Now())
+
+	tx := m.db.Where("name = ?", name).Delete(&Setting{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("setting", name)
+	}
+	return nil
+}
+
+// Values returns the values of all settings by name
+// returns InternalError
+func (m *GormSettingRepository) Values(ctx context.Context) (map[string]string, error) {
+	objs, err := m.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(objs))
+	for _, s := range objs {
+		values[s.Name] = s.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,73 @@
+package settings_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestSettingRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunSettingRepository(t *testing.T) {
+	suite.Run(t, &TestSettingRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestSettingRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestSettingRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestSettingRepository) TestSaveAndDelete() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := settings.NewSettingRepository(test.DB)
+	s, err := repo.Save(ctx, "paging.size.default", "30")
+	require.Nil(t, err)
+	assert.Equal(t, "30", s.Value)
+	s, err = repo.Save(ctx, "paging.size.default", "40")
+	require.Nil(t, err)
+	assert.Equal(t, "40", s.Value)
+
+	values, err := repo.Values(ctx)
+	require.Nil(t, err)
+	assert.Equal(t, "40", values["paging.size.default"])
+
+	require.Nil(t, repo.Delete(ctx, "paging.size.default"))
+	assert.IsType(t, errors.NotFoundError{}, repo.Delete(ctx, "paging.size.default"))
+	values, err = repo.Values(ctx)
+	require.Nil(t, err)
+	assert.NotContains(t, values, "paging.size.default")
+
+	// deleted settings can be set again
+	_, err = repo.Save(ctx, "paging.size.default", "50")
+	require.Nil(t, err)
+	require.Nil(t, repo.Delete(ctx, "paging.size.default"))
+}
+
+func (test *TestSettingRepository) TestSaveInvalid() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := settings.NewSettingRepository(test.DB)
+	_, err := repo.Save(context.Background(), "paging.size.default", "many")
+	assert.IsType(t, errors.BadParameterError{}, err)
+	_, err = repo.Save(context.Background(), "postgres.host", "elsewhere")
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
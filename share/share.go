@@ -0,0 +1,158 @@
+// Package share stores the links that grant read access to a single work item
+// and its comments without logging in, e.g. for external stakeholders. The
+// tokens of the links are signed and carry their expiry, forged and expired
+// tokens are rejected before the link is loaded, revoked links are deleted.
+package share
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Link grants whoever knows its token read access to the work item, with
+// the visibility of the identity that created it
+type Link struct {
+	gormsupport.Lifecycle
+	ID         uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	WorkItemID uint64
+	CreatedBy  uuid.UUID `sql:"type:uuid"`
+	ExpiresAt  time.Time
+}
+
+// TableName implements gorm.tabler
+func (l Link) TableName() string {
+	return "share_links"
+}
+
+// Token returns the token of the link signed with the given secret
+func (l Link) Token(secret string) string {
+	payload := fmt.Sprintf("%s.%d", l.ID, l.ExpiresAt.Unix())
+	return payload + "." + sign(secret, payload)
+}
+
+// sign returns the signature of the payload of a token
+func sign(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and the expiry of the token and returns the ID
+// of its link. The link can still be revoked.
+// returns NotFoundError
+func Verify(secret, token string, now time.Time) (uuid.UUID, error) {
+	// the token is a secret, don't echo it
+	notFound := errors.NewNotFoundError("share link", "")
+	parts := strings.Split(token, ".")
+	if secret == "" || len(parts) != 3 {
+		return uuid.Nil, notFound
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(sign(secret, parts[0]+"."+parts[1]))) {
+		return uuid.Nil, notFound
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || !now.Before(time.Unix(expires, 0)) {
+		return uuid.Nil, notFound
+	}
+	id, err := uuid.FromString(parts[0])
+	if err != nil {
+		return uuid.Nil, notFound
+	}
+	return id, nil
+}
+
+// Repository encapsulates storage & retrieval of share links
+type Repository interface {
+	Create(ctx context.Context, workItemID uint64, createdBy uuid.UUID, expiresAt time.Time) (*Link, error)
+	Load(ctx context.Context, ID uuid.UUID) (*Link, error)
+	List(ctx context.Context, workItemID uint64, createdBy uuid.UUID) ([]Link, error)
+	Revoke(ctx context.Context, ID uuid.UUID, createdBy uuid.UUID) error
+}
+
+// NewShareLinkRepository creates a new storage type.
+func NewShareLinkRepository(db *gorm.DB) Repository {
+	return &GormShareLinkRepository{db: db}
+}
+
+// GormShareLinkRepository is the implementation of the storage interface for
+// share links.
+type GormShareLinkRepository struct {
+	db *gorm.DB
+}
+
+// Create creates a link to the work item that expires at the given time
+// returns BadParameterError or InternalError
+func (m *GormShareLinkRepository) Create(ctx context.Context, workItemID uint64, createdBy uuid.UUID, expiresAt time.Time) (*Link, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "sharelink", "create"}, time.Now())
+
+	if !expiresAt.After(time.Now()) {
+		return nil, errors.NewBadParameterError("expires-at", expiresAt).Expected("in the future")
+	}
+	// tokens carry the expiry in seconds
+	link := Link{ID: uuid.NewV4(), WorkItemID: workItemID, CreatedBy: createdBy, ExpiresAt: expiresAt.Truncate(time.Second)}
+	if err := m.db.Create(&link).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return &link, nil
+}
+
+// Load returns the link with the given ID, expired links are returned as
+// long as they are not revoked
+// returns NotFoundError or InternalError
+func (m *GormShareLinkRepository) Load(ctx context.Context, ID uuid.UUID) (*Link, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "sharelink", "load"}, time.Now())
+
+	var res Link
+	tx := m.db.Where("id = ?", ID).First(&res)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("share link", ID.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &res, nil
+}
+
+// List returns the links to the work item the identity created that didn't
+// expire yet, the latest first
+// returns InternalError
+func (m *GormShareLinkRepository) List(ctx context.Context, workItemID uint64, createdBy uuid.UUID) ([]Link, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "sharelink", "list"}, time.Now())
+
+	var res []Link
+	err := m.db.Where("work_item_id = ? AND created_by = ? AND expires_at > now()", workItemID, createdBy).
+		Order("created_at DESC").Find(&res).Error
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return res, nil
+}
+
+// Revoke deletes the link with the given ID, only the identity that created
+// it can revoke it
+// returns NotFoundError or InternalError
+func (m *GormShareLinkRepository) Revoke(ctx context.Context, ID uuid.UUID, createdBy uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "sharelink", "revoke"}, time.Now())
+
+	tx := m.db.Where("id = ? AND created_by = ?", ID, createdBy).Delete(&Link{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("share link", ID.String())
+	}
+	return nil
+}
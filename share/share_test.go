@@ -0,0 +1,97 @@
+package share_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/share"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestVerify(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	now := time.Now()
+	link := share.Link{ID: uuid.NewV4(), ExpiresAt: now.Add(time.Hour)}
+	token := link.Token("secret")
+	id, err := share.Verify("secret", token, now)
+	require.Nil(t, err)
+	assert.Equal(t, link.ID, id)
+
+	for _, tc := range []struct {
+		secret string
+		token  string
+		now    time.Time
+	}{
+		{"secret", token, now.Add(time.Hour)},
+		{"other", token, now},
+		{"", token, now},
+		{"secret", strings.Replace(token, strconv.FormatInt(link.ExpiresAt.Unix(), 10), strconv.FormatInt(now.Add(48*time.Hour).Unix(), 10), 1), now},
+		{"secret", link.ID.String(), now},
+	} {
+		_, err := share.Verify(tc.secret, tc.token, tc.now)
+		assert.IsType(t, errors.NotFoundError{}, err, tc.token)
+	}
+}
+
+type TestShareLinkRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunShareLinkRepository(t *testing.T) {
+	suite.Run(t, &TestShareLinkRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestShareLinkRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestShareLinkRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestShareLinkRepository) TestRevoke() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	identity := account.Identity{FullName: "Sharer"}
+	require.Nil(t, account.NewIdentityRepository(test.DB).Create(ctx, &identity))
+	wi, err := workitem.NewWorkItemRepository(test.DB).Create(ctx, workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle: "shared",
+		workitem.SystemState: workitem.SystemStateNew,
+	}, identity.ID.String())
+	require.Nil(t, err)
+	wiID, err := workitem.ParseWorkItemIDToUint64(wi.ID)
+	require.Nil(t, err)
+
+	repo := share.NewShareLinkRepository(test.DB)
+	_, err = repo.Create(ctx, wiID, identity.ID, time.Now().Add(-time.Minute))
+	assert.IsType(t, errors.BadParameterError{}, err)
+	link, err := repo.Create(ctx, wiID, identity.ID, time.Now().Add(24*time.Hour))
+	require.Nil(t, err)
+	links, err := repo.List(ctx, wiID, identity.ID)
+	require.Nil(t, err)
+	require.Len(t, links, 1)
+	assert.Equal(t, link.ID, links[0].ID)
+
+	// only the creator revokes the link
+	assert.IsType(t, errors.NotFoundError{}, repo.Revoke(ctx, link.ID, uuid.NewV4()))
+	require.Nil(t, repo.Revoke(ctx, link.ID, identity.ID))
+	_, err = repo.Load(ctx, link.ID)
+	assert.IsType(t, errors.NotFoundError{}, err)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/share"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+)
+
+// SharedController implements the shared resource.
+type SharedController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewSharedController creates a shared controller.
+func NewSharedController(service *goa.Service, db application.DB) *SharedController {
+	return &SharedController{Controller: service.NewController("SharedController"), db: db}
+}
+
+// Show runs the show action.
+func (c *SharedController) Show(ctx *app.ShowSharedContext) error {
+	id, err := share.Verify(configuration.GetShareSecret(), ctx.Token, time.Now())
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		link, err := appl.ShareLinks().Load(ctx, id)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		// the request is anonymous, the link shows what its creator can see
+		viewer, err := loadViewer(ctx, appl, &link.CreatedBy)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		vctx := workitem.WithViewer(ctx, viewer)
+		wiID := strconv.FormatUint(link.WorkItemID, 10)
+		wi, err := appl.WorkItems().Load(vctx, wiID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if confidential, _ := wi.Fields[workitem.SystemConfidential].(bool); confidential {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewNotFoundError("share link", id.String()))
+		}
+		comments, err := appl.Comments().List(vctx, wiID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		// comments held for review are never shared
+		shared := []*comment.Comment{}
+		for _, cm := range visibleComments(vctx, wi, comments) {
+			if !cm.PendingReview {
+				shared = append(shared, cm)
+			}
+		}
+		return ctx.OK(&app.SharedWorkItem{
+			WorkItem:  ConvertWorkItem(ctx.RequestData, wi),
+			Comments:  ConvertComments(ctx.RequestData, shared),
+			ExpiresAt: link.ExpiresAt,
+		})
+	})
+}
@@ -0,0 +1,89 @@
+// Package sqldebug records the SQL statements of single requests, see the
+// X-Debug-SQL header
+package sqldebug
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// Header is the request header asking for the statements of the request
+const Header = "X-Debug-SQL"
+
+// Statement is an executed SQL statement
+type Statement struct {
+	SQL      string        `json:"sql"`
+	Vars     []string      `json:"vars"`
+	Duration time.Duration `json:"-"`
+	// DurationMS is the duration in milliseconds
+	DurationMS float64 `json:"duration-ms"`
+	// Source is the file and line the statement was executed from
+	Source string `json:"source,omitempty"`
+}
+
+// Recorder collects the statements logged by a gorm.DB, it is the logger set
+// with SetLogger
+type Recorder struct {
+	mu         sync.Mutex
+	statements []Statement
+}
+
+// Print implements the logger interface of gorm, it keeps the statements and
+// ignores the other messages
+func (r *Recorder) Print(v ...interface{}) {
+	if len(v) < 4 || v[0] != "sql" {
+		return
+	}
+	s := Statement{SQL: fmt.Sprint(v[3])}
+	s.Source, _ = v[1].(string)
+	s.Duration, _ = v[2].(time.Duration)
+	s.DurationMS = float64(s.Duration) / float64(time.Millisecond)
+	if len(v) > 4 {
+		if vars, ok := v[4].([]interface{}); ok {
+			s.Vars = make([]string, len(vars))
+			for i, value := range vars {
+				s.Vars[i] = fmt.Sprintf("%v", value)
+			}
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = append(r.statements, s)
+}
+
+// Statements returns the recorded statements in the order they were executed
+func (r *Recorder) Statements() []Statement {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Statement{}, r.statements...)
+}
+
+// Total returns the summed up duration of the recorded statements
+func (r *Recorder) Total() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var total time.Duration
+	for _, s := range r.statements {
+		total += s.Duration
+	}
+	return total
+}
+
+type contextRecorderKeyType int
+
+const contextRecorderKey contextRecorderKeyType = iota + 1
+
+// WithRecorder returns a copy of ctx whose statements are recorded by r
+func WithRecorder(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, contextRecorderKey, r)
+}
+
+// ContextRecorder returns the recorder of ctx, nil if its statements aren't
+// recorded
+func ContextRecorder(ctx context.Context) *Recorder {
+	r, _ := ctx.Value(contextRecorderKey).(*Recorder)
+	return r
+}
@@ -0,0 +1,34 @@
+package sqldebug_test
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/sqldebug"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	assert.Nil(t, sqldebug.ContextRecorder(context.Background()))
+	r := &sqldebug.Recorder{}
+	ctx := sqldebug.WithRecorder(context.Background(), r)
+	require.Equal(t, r, sqldebug.ContextRecorder(ctx))
+
+	r.Print("sql", "workitem.go:12", 3*time.Millisecond, "SELECT * FROM work_items WHERE id = $1", []interface{}{42})
+	r.Print("log", "workitem.go:13", "not a statement")
+	r.Print("sql", "workitem.go:14", time.Millisecond, "SELECT 1", []interface{}{}, int64(1))
+
+	statements := r.Statements()
+	require.Len(t, statements, 2)
+	assert.Equal(t, "SELECT * FROM work_items WHERE id = $1", statements[0].SQL)
+	assert.Equal(t, []string{"42"}, statements[0].Vars)
+	assert.Equal(t, "workitem.go:12", statements[0].Source)
+	assert.Equal(t, 3.0, statements[0].DurationMS)
+	assert.Equal(t, 4*time.Millisecond, r.Total())
+}
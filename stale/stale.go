@@ -0,0 +1,232 @@
+// Package stale flags the work items of a project that had no activity for a
+// number of days. Stale work items can be moved to the inactive state after a
+// grace period, unless there is activity on them in the meantime.
+package stale
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Policy decides when the work items of a project are stale
+type Policy struct {
+	gormsupport.Lifecycle
+	ProjectID uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	// InactiveDays without updates or comments make a work item stale
+	InactiveDays int
+	// GraceDays after being flagged a stale work item is moved to the
+	// inactive state, nil leaves stale work items in their state
+	GraceDays *int
+}
+
+// TableName implements gorm.tabler
+func (p Policy) TableName() string {
+	return "stale_policies"
+}
+
+// Change is a work item that was flagged or moved to the inactive state by a
+// sweep
+type Change struct {
+	WorkItemID string
+	ProjectID  uuid.UUID
+	Title      string
+	Assignees  []string
+	// Inactive is true if the work item was moved to the inactive state
+	Inactive bool
+}
+
+// Repository encapsulates storage & retrieval of stale policies
+type Repository interface {
+	Load(ctx context.Context, projectID uuid.UUID) (*Policy, error)
+	Save(ctx context.Context, p Policy) (*Policy, error)
+	Delete(ctx context.Context, projectID uuid.UUID) error
+	Sweep(ctx context.Context, now time.Time) ([]Change, error)
+}
+
+// NewStalePolicyRepository creates a new storage type.
+func NewStalePolicyRepository(db *gorm.DB) Repository {
+	return &GormStalePolicyRepository{db: db}
+}
+
+// GormStalePolicyRepository is the implementation of the storage interface
+// for stale policies.
+type GormStalePolicyRepository struct {
+	db *gorm.DB
+}
+
+// Load returns the policy of the project
+// returns NotFoundError or InternalError
+func (m *GormStalePolicyRepository) Load(ctx context.Context, projectID uuid.UUID) (*Policy, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "stalepolicy", "get"}, time.Now())
+
+	var obj Policy
+	tx := m.db.Where("project_id = ?", projectID).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("stale policy", projectID.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
+
+// Save creates or replaces the policy of the project
+// returns BadParameterError or InternalError
+func (m *GormStalePolicyRepository) Save(ctx context.Context, p Policy) (*Policy, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "stalepolicy", "save"}, time.Now())
+
+	if p.InactiveDays <= 0 {
+		return nil, errors.NewBadParameterError("inactive-days", p.InactiveDays).Expected("greater than 0")
+	}
+	if p.GraceDays != nil && *p.GraceDays < 0 {
+		return nil, errors.NewBadParameterError("grace-days", *p.GraceDays).Expected("not negative")
+	}
+	tx := m.db.Exec(`INSERT INTO stale_policies (project_id, inactive_days, grace_days, created_at, updated_at) VALUES (?, ?, ?, now(), now())
+		ON CONFLICT (project_id) DO UPDATE SET inactive_days = excluded.inactive_days, grace_days = excluded.grace_days, updated_at = now(), deleted_at = NULL`,
+		p.ProjectID, p.InactiveDays, p.GraceDays)
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return m.Load(ctx, p.ProjectID)
+}
+
+// Delete removes the policy of the project and the flags of its work items
+// returns NotFoundError or InternalError
+func (m *GormStalePolicyRepository) Delete(ctx context.Context, projectID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "stalepolicy", "delete"}, time.Now())
+
+	tx := m.db.Where("project_id = ?", projectID).Delete(&Policy{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("stale policy", projectID.String())
+	}
+	if err := m.db.Exec("DELETE FROM stale_work_items WHERE project_id = ?", projectID).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// activity matches the work items of stale_work_items s that were updated or
+// commented on after they were flagged
+const activity = `EXISTS (SELECT 1 FROM work_items w WHERE w.id = s.work_item_id AND w.updated_at > s.flagged_at)
+	OR EXISTS (SELECT 1 FROM comments c WHERE c.parent_id = s.work_item_id::text AND c.created_at > s.flagged_at AND c.deleted_at IS NULL)`
+
+// Sweep applies the policies of all projects: flags with activity since they
+// were set are removed, work items stale for longer than the grace period are
+// moved to the inactive state and work items without activity are flagged.
+// returns InternalError
+func (m *GormStalePolicyRepository) Sweep(ctx context.Context, now time.Time) ([]Change, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "stalepolicy", "sweep"}, time.Now())
+
+	var policies []*Policy
+	if err := m.db.Find(&policies).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	var changes []Change
+	for _, p := range policies {
+		if err := m.db.Exec("DELETE FROM stale_work_items s WHERE s.project_id = ? AND ("+activity+")", p.ProjectID).Error; err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		if p.GraceDays != nil {
+			ids, err := m.ids(fmt.Sprintf(`UPDATE work_items SET fields = jsonb_set(fields, '{%s}', '"%s"'), version = version + 1, updated_at = ?
+				WHERE deleted_at IS NULL AND id IN (SELECT work_item_id FROM stale_work_items WHERE project_id = ? AND flagged_at <= ?)
+				RETURNING id`, workitem.SystemState, workitem.SystemStateInactive),
+				now, p.ProjectID, now.AddDate(0, 0, -*p.GraceDays))
+			if err != nil {
+				return nil, err
+			}
+			if len(ids) > 0 {
+				if err := m.db.Exec("DELETE FROM stale_work_items WHERE work_item_id IN (?)", ids).Error; err != nil {
+					return nil, errors.NewInternalError(err.Error())
+				}
+			}
+			if err := workitem.RecordEvents(ctx, m.db, workitem.EventUpdate, ids...); err != nil {
+				return nil, err
+			}
+			inactive, err := m.changes(p.ProjectID, ids, true)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, inactive...)
+		}
+		cutoff := now.AddDate(0, 0, -p.InactiveDays)
+		ids, err := m.ids(fmt.Sprintf(`INSERT INTO stale_work_items (work_item_id, project_id, flagged_at)
+			SELECT w.id, ?, ? FROM work_items w
+			WHERE w.deleted_at IS NULL AND w.fields->>'%s' = ? AND w.fields->>'%s' NOT IN (?) AND w.updated_at < ?
+				AND NOT EXISTS (SELECT 1 FROM comments c WHERE c.parent_id = w.id::text AND c.created_at >= ? AND c.deleted_at IS NULL)
+			ON CONFLICT (work_item_id) DO NOTHING
+			RETURNING work_item_id`, workitem.SystemProject, workitem.SystemState),
+			p.ProjectID, now, p.ProjectID.String(), []string{workitem.SystemStateClosed, workitem.SystemStateInactive}, cutoff, cutoff)
+		if err != nil {
+			return nil, err
+		}
+		flagged, err := m.changes(p.ProjectID, ids, false)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, flagged...)
+	}
+	return changes, nil
+}
+
+// ids runs the statement returning work item IDs
+func (m *GormStalePolicyRepository) ids(sql string, values ...interface{}) ([]uint64, error) {
+	rows, err := m.db.Raw(sql, values...).Rows()
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	defer rows.Close()
+	var ids []uint64
+	for rows.Next() {
+		var id uint64
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// changes loads the titles and assignees of the given work items
+func (m *GormStalePolicyRepository) changes(projectID uuid.UUID, ids []uint64, inactive bool) ([]Change, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := m.db.Raw(fmt.Sprintf("SELECT id, fields->>'%s', COALESCE(fields->'%s', '[]') FROM work_items WHERE id IN (?) ORDER BY id",
+		workitem.SystemTitle, workitem.SystemAssignees), ids).Rows()
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	defer rows.Close()
+	var changes []Change
+	for rows.Next() {
+		var id uint64
+		var title *string
+		var assignees []byte
+		if err := rows.Scan(&id, &title, &assignees); err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		c := Change{WorkItemID: strconv.FormatUint(id, 10), ProjectID: projectID, Inactive: inactive}
+		if title != nil {
+			c.Title = *title
+		}
+		if err := json.Unmarshal(assignees, &c.Assignees); err != nil {
+			return nil, errors.NewConversionError(err.Error())
+		}
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
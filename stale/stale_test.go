@@ -0,0 +1,137 @@
+package stale_test
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/stale"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestStalePolicyRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunStalePolicyRepository(t *testing.T) {
+	suite.Run(t, &TestStalePolicyRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestStalePolicyRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestStalePolicyRepository) TearDownTest() {
+	test.clean()
+}
+
+// createWorkItem creates a work item in the project that was last updated
+// the given number of days ago
+func (test *TestStalePolicyRepository) createWorkItem(p *project.Project, title string, assignee string, days int) string {
+	wi, err := workitem.NewWorkItemRepository(test.DB).Create(context.Background(), workitem.SystemBug,
+		map[string]interface{}{
+			workitem.SystemTitle:     title,
+			workitem.SystemState:     workitem.SystemStateOpen,
+			workitem.SystemProject:   p.ID.String(),
+			workitem.SystemAssignees: []interface{}{assignee},
+		}, uuid.NewV4().String())
+	require.Nil(test.T(), err)
+	require.Nil(test.T(), test.DB.Exec("UPDATE work_items SET updated_at = ? WHERE id = ?", time.Now().AddDate(0, 0, -days), wi.ID).Error)
+	return wi.ID
+}
+
+func (test *TestStalePolicyRepository) TestSavePolicy() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	p, err := project.NewRepository(test.DB).Create(ctx, "stale-"+uuid.NewV4().String())
+	require.Nil(t, err)
+	repo := stale.NewStalePolicyRepository(test.DB)
+	_, err = repo.Load(ctx, p.ID)
+	assert.IsType(t, errors.NotFoundError{}, err)
+	_, err = repo.Save(ctx, stale.Policy{ProjectID: p.ID, InactiveDays: 0})
+	assert.IsType(t, errors.BadParameterError{}, err)
+
+	policy, err := repo.Save(ctx, stale.Policy{ProjectID: p.ID, InactiveDays: 30})
+	require.Nil(t, err)
+	assert.Equal(t, 30, policy.InactiveDays)
+	assert.Nil(t, policy.GraceDays)
+	grace := 7
+	policy, err = repo.Save(ctx, stale.Policy{ProjectID: p.ID, InactiveDays: 14, GraceDays: &grace})
+	require.Nil(t, err)
+	assert.Equal(t, 14, policy.InactiveDays)
+	require.NotNil(t, policy.GraceDays)
+	assert.Equal(t, 7, *policy.GraceDays)
+
+	require.Nil(t, repo.Delete(ctx, p.ID))
+	assert.IsType(t, errors.NotFoundError{}, repo.Delete(ctx, p.ID))
+}
+
+func (test *TestStalePolicyRepository) TestSweep() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	p, err := project.NewRepository(test.DB).Create(ctx, "stale-"+uuid.NewV4().String())
+	require.Nil(t, err)
+	repo := stale.NewStalePolicyRepository(test.DB)
+	grace := 2
+	_, err = repo.Save(ctx, stale.Policy{ProjectID: p.ID, InactiveDays: 10, GraceDays: &grace})
+	require.Nil(t, err)
+	assignee := uuid.NewV4().String()
+	old := test.createWorkItem(p, "old", assignee, 20)
+	commented := test.createWorkItem(p, "commented", assignee, 20)
+	test.createWorkItem(p, "recent", assignee, 1)
+
+	now := time.Now()
+	changes, err := repo.Sweep(ctx, now)
+	require.Nil(t, err)
+	var flagged []string
+	for _, c := range changes {
+		if uuid.Equal(c.ProjectID, p.ID) {
+			flagged = append(flagged, c.WorkItemID)
+			assert.False(t, c.Inactive)
+			assert.Equal(t, []string{assignee}, c.Assignees)
+		}
+	}
+	assert.Equal(t, []string{old, commented}, flagged)
+
+	// flagged work items are only reported once
+	changes, err = repo.Sweep(ctx, now)
+	require.Nil(t, err)
+	for _, c := range changes {
+		assert.False(t, uuid.Equal(c.ProjectID, p.ID))
+	}
+
+	// activity removes the flag, the other one becomes inactive after the grace period
+	require.Nil(t, comment.NewCommentRepository(test.DB).Create(ctx, &comment.Comment{ParentID: commented, Body: "still relevant", CreatedBy: uuid.NewV4()}))
+	changes, err = repo.Sweep(ctx, now.AddDate(0, 0, 3))
+	require.Nil(t, err)
+	var inactive []string
+	for _, c := range changes {
+		if uuid.Equal(c.ProjectID, p.ID) {
+			assert.True(t, c.Inactive)
+			inactive = append(inactive, c.WorkItemID)
+		}
+	}
+	assert.Equal(t, []string{old}, inactive)
+	wi, err := workitem.NewWorkItemRepository(test.DB).Load(ctx, old)
+	require.Nil(t, err)
+	assert.Equal(t, workitem.SystemStateInactive, wi.Fields[workitem.SystemState])
+	wi, err = workitem.NewWorkItemRepository(test.DB).Load(ctx, commented)
+	require.Nil(t, err)
+	assert.Equal(t, workitem.SystemStateOpen, wi.Fields[workitem.SystemState])
+}
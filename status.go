@@ -2,6 +2,7 @@ package main
 
 import (
 	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/breaker"
 	"github.com/goadesign/goa"
 	"github.com/jinzhu/gorm"
 )
@@ -26,6 +27,22 @@ func (c *StatusController) Show(ctx *app.ShowStatusContext) error {
 	res.Commit = Commit
 	res.BuildTime = BuildTime
 	res.StartTime = StartTime
+	stats := connections.Stats()
+	res.ActiveConnections = &stats.Active
+	res.IdleConnections = &stats.Idle
+	for _, h := range breaker.All() {
+		dependency := &app.DependencyHealth{
+			Name:     h.Name,
+			State:    string(h.State),
+			Failures: h.Failures,
+			Since:    h.Since,
+		}
+		if h.LastError != "" {
+			lastError := h.LastError
+			dependency.Error = &lastError
+		}
+		res.Dependencies = append(res.Dependencies, dependency)
+	}
 
 	_, err := c.db.DB().Exec("select 1")
 	if err != nil {
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/goadesign/goa"
+)
+
+// defaultLargestAttachments is the number of largest attachments reported if
+// the request doesn't limit them
+const defaultLargestAttachments = 10
+
+// StorageController implements the storage resource.
+type StorageController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewStorageController creates a storage controller.
+func NewStorageController(service *goa.Service, db application.DB) *StorageController {
+	return &StorageController{Controller: service.NewController("StorageController"), db: db}
+}
+
+// Show runs the show action.
+func (c *StorageController) Show(ctx *app.ShowStorageContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can see the storage usage"))
+	}
+	limit := defaultLargestAttachments
+	if ctx.PageLimit != nil {
+		limit = *ctx.PageLimit
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		usages, err := appl.Attachments().Usage(ctx, ctx.FilterProject)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		largest, err := appl.Attachments().Largest(ctx, ctx.FilterProject, limit)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		res := &app.StorageUsage{
+			Projects: []*app.ProjectStorageUsage{},
+			Largest:  []*app.Attachment{},
+		}
+		quota := int(configuration.GetAttachmentQuota())
+		if quota > 0 {
+			res.Quota = &quota
+		}
+		for _, u := range usages {
+			usage := &app.ProjectStorageUsage{
+				ProjectID:   u.ProjectID,
+				Attachments: u.Count,
+				Size:        int(u.Size),
+			}
+			if res.Quota != nil {
+				available := quota - usage.Size
+				if available < 0 {
+					available = 0
+				}
+				usage.Available = &available
+			}
+			res.Projects = append(res.Projects, usage)
+		}
+		for _, a := range largest {
+			res.Largest = append(res.Largest, ConvertAttachment(ctx.RequestData, a))
+		}
+		return ctx.OK(res)
+	})
+}
@@ -3,9 +3,51 @@ package test
 import (
 	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/approval"
+	"github.com/almighty/almighty-core/assignment"
+	"github.com/almighty/almighty-core/attachment"
+	"github.com/almighty/almighty-core/automation"
+	"github.com/almighty/almighty-core/backup"
+	"github.com/almighty/almighty-core/branding"
+	"github.com/almighty/almighty-core/calendar"
+	"github.com/almighty/almighty-core/chat"
+	"github.com/almighty/almighty-core/codebase"
+	"github.com/almighty/almighty-core/codechange"
 	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/dashboard"
+	"github.com/almighty/almighty-core/deployment"
+	"github.com/almighty/almighty-core/escalation"
+	"github.com/almighty/almighty-core/favorite"
+	"github.com/almighty/almighty-core/fieldvalues"
+	"github.com/almighty/almighty-core/flow"
+	"github.com/almighty/almighty-core/hierarchy"
+	"github.com/almighty/almighty-core/impersonation"
+	"github.com/almighty/almighty-core/instancestats"
+	"github.com/almighty/almighty-core/intake"
 	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/job"
+	"github.com/almighty/almighty-core/moderation"
+	"github.com/almighty/almighty-core/notification"
+	"github.com/almighty/almighty-core/outbox"
+	"github.com/almighty/almighty-core/personaldata"
+	"github.com/almighty/almighty-core/portfolio"
+	"github.com/almighty/almighty-core/processconfig"
+	"github.com/almighty/almighty-core/processtemplate"
 	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/projectsettings"
+	"github.com/almighty/almighty-core/push"
+	"github.com/almighty/almighty-core/reaction"
+	"github.com/almighty/almighty-core/redirect"
+	"github.com/almighty/almighty-core/release"
+	"github.com/almighty/almighty-core/remotelink"
+	"github.com/almighty/almighty-core/report"
+	"github.com/almighty/almighty-core/retention"
+	"github.com/almighty/almighty-core/session"
+	"github.com/almighty/almighty-core/settings"
+	"github.com/almighty/almighty-core/share"
+	"github.com/almighty/almighty-core/stale"
+	"github.com/almighty/almighty-core/translation"
+	"github.com/almighty/almighty-core/vote"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/almighty/almighty-core/workitem/link"
 )
@@ -61,6 +103,234 @@ func (db *MockDB) Iterations() iteration.Repository {
 	return nil
 }
 
+func (db *MockDB) RemoteLinks() remotelink.Repository {
+	return nil
+}
+
+func (db *MockDB) CodeChanges() codechange.Repository {
+	return nil
+}
+
+func (db *MockDB) Codebases() codebase.Repository {
+	return nil
+}
+
+func (db *MockDB) Deployments() deployment.Repository {
+	return nil
+}
+
+func (db *MockDB) Releases() release.Repository {
+	return nil
+}
+
+func (db *MockDB) FieldValues() fieldvalues.Repository {
+	return nil
+}
+
+func (db *MockDB) Votes() vote.Repository {
+	return nil
+}
+
+func (db *MockDB) Reactions() reaction.Repository {
+	return nil
+}
+
+func (db *MockDB) PersonalData() personaldata.Repository {
+	return nil
+}
+
+func (db *MockDB) Moderation() moderation.Repository {
+	return nil
+}
+
+func (db *MockDB) Settings() settings.Repository {
+	return nil
+}
+
+func (db *MockDB) Jobs() job.Repository {
+	return nil
+}
+
+func (db *MockDB) StalePolicies() stale.Repository {
+	return nil
+}
+
+func (db *MockDB) WorkItemEvents() workitem.EventRepository {
+	return nil
+}
+
+func (db *MockDB) Outbox() outbox.Repository {
+	return nil
+}
+
+func (db *MockDB) Favorites() favorite.Repository {
+	return nil
+}
+
+func (db *MockDB) Dashboards() dashboard.Repository {
+	return nil
+}
+
+func (db *MockDB) Reports() report.Repository {
+	return nil
+}
+
+func (db *MockDB) Flows() flow.Repository {
+	return nil
+}
+
+func (db *MockDB) Trash() workitem.TrashRepository {
+	return nil
+}
+
+func (db *MockDB) WorkItemArchive() workitem.ArchiveRepository {
+	return nil
+}
+
+func (db *MockDB) WorkItemChecklists() workitem.ChecklistRepository {
+	return nil
+}
+
+func (db *MockDB) EventPartitions() workitem.EventPartitionRepository {
+	return nil
+}
+
+func (db *MockDB) RetentionPolicies() retention.Repository {
+	return nil
+}
+
+func (db *MockDB) Backups() backup.Repository {
+	return nil
+}
+
+func (db *MockDB) Dumps() backup.Dumper {
+	return nil
+}
+
+func (db *MockDB) WorkItemTypeMigrations() workitem.TypeMigrationRepository {
+	return nil
+}
+
+func (db *MockDB) WorkItemTypeDeletions() workitem.TypeDeletionRepository {
+	return nil
+}
+
+func (db *MockDB) Translations() translation.Repository {
+	return nil
+}
+
+func (db *MockDB) CalendarFeeds() calendar.Repository {
+	return nil
+}
+
+func (db *MockDB) ChatIntegrations() chat.Repository {
+	return nil
+}
+
+func (db *MockDB) AutomationRules() automation.Repository {
+	return nil
+}
+
+func (db *MockDB) AssignmentRules() assignment.Repository {
+	return nil
+}
+
+func (db *MockDB) EscalationPolicies() escalation.Repository {
+	return nil
+}
+
+func (db *MockDB) Approvals() approval.Repository {
+	return nil
+}
+
+func (db *MockDB) TypeHierarchies() hierarchy.Repository {
+	return nil
+}
+
+func (db *MockDB) ProcessConfigs() processconfig.Repository {
+	return nil
+}
+
+func (db *MockDB) ProcessTemplates() processtemplate.Repository {
+	return nil
+}
+
+func (db *MockDB) ProjectSettings() projectsettings.Repository {
+	return nil
+}
+
+func (db *MockDB) InstanceStats() instancestats.Repository {
+	return nil
+}
+
+func (db *MockDB) Impersonations() impersonation.Repository {
+	return nil
+}
+
+func (db *MockDB) Sessions() session.Repository {
+	return nil
+}
+
+func (db *MockDB) Portfolios() portfolio.Repository {
+	return nil
+}
+
+func (db *MockDB) WorkItemMerge() workitem.MergeRepository {
+	return nil
+}
+
+func (db *MockDB) Redirects() redirect.Repository {
+	return nil
+}
+
+func (db *MockDB) ShareLinks() share.Repository {
+	return nil
+}
+
+func (db *MockDB) WorkItemDrafts() workitem.DraftRepository {
+	return nil
+}
+
+func (db *MockDB) WorkItemAutosaves() workitem.AutosaveRepository {
+	return nil
+}
+
+func (db *MockDB) Attachments() attachment.Repository {
+	return nil
+}
+
+func (db *MockDB) WorkItemChanges() workitem.ChangeRepository {
+	return nil
+}
+
+func (db *MockDB) PushDevices() push.Repository {
+	return nil
+}
+
+func (db *MockDB) NotificationPreferences() notification.PreferenceRepository {
+	return nil
+}
+
+func (db *MockDB) NotificationInbox() notification.InboxRepository {
+	return nil
+}
+
+func (db *MockDB) NotificationMutes() notification.MuteRepository {
+	return nil
+}
+
+func (db *MockDB) EmailTemplates() notification.TemplateRepository {
+	return nil
+}
+
+func (db *MockDB) Branding() branding.Repository {
+	return nil
+}
+
+func (db *MockDB) IntakePortals() intake.Repository {
+	return nil
+}
+
 func (db *MockDB) Commit() error {
 	return nil
 }
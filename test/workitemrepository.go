@@ -51,19 +51,31 @@ type WorkItemRepository struct {
 		result1 *app.WorkItem
 		result2 error
 	}
-	ListStub        func(ctx context.Context, criteria criteria.Expression, start *int, length *int) ([]*app.WorkItem, uint64, error)
+	ListStub        func(ctx context.Context, criteria criteria.Expression, start *int, length *int, sort ...workitem.SortKey) ([]*app.WorkItem, uint64, error)
 	listMutex       sync.RWMutex
 	listArgsForCall []struct {
 		ctx      context.Context
 		criteria criteria.Expression
 		start    *int
 		length   *int
+		sort     []workitem.SortKey
 	}
 	listReturns struct {
 		result1 []*app.WorkItem
 		result2 uint64
 		result3 error
 	}
+	StreamStub        func(ctx context.Context, criteria criteria.Expression, sort []workitem.SortKey, fn func(*app.WorkItem) error) error
+	streamMutex       sync.RWMutex
+	streamArgsForCall []struct {
+		ctx      context.Context
+		criteria criteria.Expression
+		sort     []workitem.SortKey
+		fn       func(*app.WorkItem) error
+	}
+	streamReturns struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -208,18 +220,19 @@ func (fake *WorkItemRepository) CreateReturns(result1 *app.WorkItem, result2 err
 	}{result1, result2}
 }
 
-func (fake *WorkItemRepository) List(ctx context.Context, c criteria.Expression, start *int, length *int) ([]*app.WorkItem, uint64, error) {
+func (fake *WorkItemRepository) List(ctx context.Context, c criteria.Expression, start *int, length *int, sort ...workitem.SortKey) ([]*app.WorkItem, uint64, error) {
 	fake.listMutex.Lock()
 	fake.listArgsForCall = append(fake.listArgsForCall, struct {
 		ctx      context.Context
 		criteria criteria.Expression
 		start    *int
 		length   *int
-	}{ctx, c, start, length})
-	fake.recordInvocation("List", []interface{}{ctx, c, start, length})
+		sort     []workitem.SortKey
+	}{ctx, c, start, length, sort})
+	fake.recordInvocation("List", []interface{}{ctx, c, start, length, sort})
 	fake.listMutex.Unlock()
 	if fake.ListStub != nil {
-		return fake.ListStub(ctx, c, start, length)
+		return fake.ListStub(ctx, c, start, length, sort...)
 	} else {
 		return fake.listReturns.result1, fake.listReturns.result2, fake.listReturns.result3
 	}
@@ -231,10 +244,10 @@ func (fake *WorkItemRepository) ListCallCount() int {
 	return len(fake.listArgsForCall)
 }
 
-func (fake *WorkItemRepository) ListArgsForCall(i int) (context.Context, criteria.Expression, *int, *int) {
+func (fake *WorkItemRepository) ListArgsForCall(i int) (context.Context, criteria.Expression, *int, *int, []workitem.SortKey) {
 	fake.listMutex.RLock()
 	defer fake.listMutex.RUnlock()
-	return fake.listArgsForCall[i].ctx, fake.listArgsForCall[i].criteria, fake.listArgsForCall[i].start, fake.listArgsForCall[i].length
+	return fake.listArgsForCall[i].ctx, fake.listArgsForCall[i].criteria, fake.listArgsForCall[i].start, fake.listArgsForCall[i].length, fake.listArgsForCall[i].sort
 }
 
 func (fake *WorkItemRepository) ListReturns(result1 []*app.WorkItem, result2 uint64, result3 error) {
@@ -246,6 +259,42 @@ func (fake *WorkItemRepository) ListReturns(result1 []*app.WorkItem, result2 uin
 	}{result1, result2, result3}
 }
 
+func (fake *WorkItemRepository) Stream(ctx context.Context, c criteria.Expression, sort []workitem.SortKey, fn func(*app.WorkItem) error) error {
+	fake.streamMutex.Lock()
+	fake.streamArgsForCall = append(fake.streamArgsForCall, struct {
+		ctx      context.Context
+		criteria criteria.Expression
+		sort     []workitem.SortKey
+		fn       func(*app.WorkItem) error
+	}{ctx, c, sort, fn})
+	fake.recordInvocation("Stream", []interface{}{ctx, c, sort, fn})
+	fake.streamMutex.Unlock()
+	if fake.StreamStub != nil {
+		return fake.StreamStub(ctx, c, sort, fn)
+	} else {
+		return fake.streamReturns.result1
+	}
+}
+
+func (fake *WorkItemRepository) StreamCallCount() int {
+	fake.streamMutex.RLock()
+	defer fake.streamMutex.RUnlock()
+	return len(fake.streamArgsForCall)
+}
+
+func (fake *WorkItemRepository) StreamArgsForCall(i int) (context.Context, criteria.Expression, []workitem.SortKey, func(*app.WorkItem) error) {
+	fake.streamMutex.RLock()
+	defer fake.streamMutex.RUnlock()
+	return fake.streamArgsForCall[i].ctx, fake.streamArgsForCall[i].criteria, fake.streamArgsForCall[i].sort, fake.streamArgsForCall[i].fn
+}
+
+func (fake *WorkItemRepository) StreamReturns(result1 error) {
+	fake.StreamStub = nil
+	fake.streamReturns = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *WorkItemRepository) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -259,6 +308,8 @@ func (fake *WorkItemRepository) Invocations() map[string][][]interface{} {
 	defer fake.createMutex.RUnlock()
 	fake.listMutex.RLock()
 	defer fake.listMutex.RUnlock()
+	fake.streamMutex.RLock()
+	defer fake.streamMutex.RUnlock()
 	return fake.invocations
 }
 
@@ -0,0 +1,139 @@
+// Package textmerge merges concurrent edits of a text line by line, like
+// diff3 does, so that two people changing different parts of a work item
+// description don't overwrite each other.
+package textmerge
+
+import "strings"
+
+// maxCells limits the size of the tables used to match the changed lines of
+// two texts to 4MB, texts with more changes are not merged
+const maxCells = 1000000
+
+// Merge merges the changes from base to ours and from base to theirs and
+// reports whether that worked. It doesn't if both sides changed the same
+// lines differently or the texts are too large.
+func Merge(base, ours, theirs string) (string, bool) {
+	switch {
+	case ours == theirs || theirs == base:
+		return ours, true
+	case ours == base:
+		return theirs, true
+	}
+	b, o, t := lines(base), lines(ours), lines(theirs)
+	mo, ok := match(b, o)
+	if !ok {
+		return "", false
+	}
+	mt, ok := match(b, t)
+	if !ok {
+		return "", false
+	}
+
+	var merged []string
+	// chunk merges the unstable chunk of lines before the base line i,
+	// ours line j and theirs line k
+	i, j, k := 0, 0, 0
+	chunk := func(bi, oj, tk int) bool {
+		bc, oc, tc := b[i:bi], o[j:oj], t[k:tk]
+		switch {
+		case equal(oc, bc):
+			merged = append(merged, tc...)
+		case equal(tc, bc), equal(oc, tc):
+			merged = append(merged, oc...)
+		default:
+			return false
+		}
+		return true
+	}
+	for bi := range b {
+		if mo[bi] < 0 || mt[bi] < 0 {
+			continue
+		}
+		// the line is unchanged on both sides
+		if !chunk(bi, mo[bi], mt[bi]) {
+			return "", false
+		}
+		merged = append(merged, b[bi])
+		i, j, k = bi+1, mo[bi]+1, mt[bi]+1
+	}
+	if !chunk(len(b), len(o), len(t)) {
+		return "", false
+	}
+	return strings.Join(merged, ""), true
+}
+
+// lines splits the text into lines keeping the line breaks
+func lines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.SplitAfter(s, "\n")
+}
+
+// match returns for every line of a the index of the same line in b in a
+// longest common subsequence of both, or -1 if the line is not part of it
+func match(a, b []string) ([]int, bool) {
+	m := make([]int, len(a))
+	for i := range m {
+		m[i] = -1
+	}
+	// the common prefix and suffix are part of a longest common subsequence,
+	// only the changed lines between them need the table
+	p := 0
+	for p < len(a) && p < len(b) && a[p] == b[p] {
+		m[p] = p
+		p++
+	}
+	q := 0
+	for q < len(a)-p && q < len(b)-p && a[len(a)-1-q] == b[len(b)-1-q] {
+		m[len(a)-1-q] = len(b) - 1 - q
+		q++
+	}
+	a, b = a[p:len(a)-q], b[p:len(b)-q]
+	if (len(a)+1)*(len(b)+1) > maxCells {
+		return nil, false
+	}
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int32, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			m[p+i] = p + j
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return m, true
+}
+
+// equal returns whether both slices have the same lines
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
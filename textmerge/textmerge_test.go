@@ -0,0 +1,50 @@
+package textmerge_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/textmerge"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	base := "one\ntwo\nthree\nfour\n"
+	tests := []struct {
+		name, ours, theirs, merged string
+		ok                         bool
+	}{
+		{"unchanged", base, base, base, true},
+		{"only ours", "one\nTWO\nthree\nfour\n", base, "one\nTWO\nthree\nfour\n", true},
+		{"only theirs", base, "one\ntwo\nthree\n", "one\ntwo\nthree\n", true},
+		{"different lines", "ONE\ntwo\nthree\nfour\n", "one\ntwo\nthree\nFOUR\n", "ONE\ntwo\nthree\nFOUR\n", true},
+		{"added at both ends", "one\ntwo\nthree\nfour\nfive\n", "zero\none\ntwo\nthree\nfour\n", "zero\none\ntwo\nthree\nfour\nfive\n", true},
+		{"same change", "one\nTWO\nthree\nfour\n", "one\nTWO\nthree\nFOUR\n", "one\nTWO\nthree\nFOUR\n", true},
+		{"same line", "one\nTWO\nthree\nfour\n", "one\nZWEI\nthree\nfour\n", "", false},
+		{"changed and removed", "one\nTWO\nthree\nfour\n", "one\nthree\nfour\n", "", false},
+	}
+	for _, tt := range tests {
+		merged, ok := textmerge.Merge(base, tt.ours, tt.theirs)
+		assert.Equal(t, tt.ok, ok, tt.name)
+		assert.Equal(t, tt.merged, merged, tt.name)
+	}
+}
+
+func TestMergeLargeTexts(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	// only the changed lines count against the size limit
+	base := strings.Repeat("line\n", 5000)
+	ours := "first\n" + base
+	theirs := base + "last\n"
+	merged, ok := textmerge.Merge(base, ours, theirs)
+	assert.True(t, ok)
+	assert.Equal(t, "first\n"+base+"last\n", merged)
+
+	// texts with too many changes are not merged
+	_, ok = textmerge.Merge(base, strings.Repeat("ours\n", 1000)+base, strings.Repeat("theirs\n", 2000))
+	assert.False(t, ok)
+}
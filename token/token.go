@@ -3,6 +3,8 @@ package token
 import (
 	"crypto/rsa"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/almighty/almighty-core/account"
 	jwt "github.com/dgrijalva/jwt-go"
@@ -11,11 +13,41 @@ import (
 	"golang.org/x/net/context"
 )
 
+// ClaimSessionID is the claim of the ID of the session a token belongs to,
+// revoking the session revokes the token
+const ClaimSessionID = "jti"
+
+// The claims of the tokens instance admins are issued to act as another
+// identity, clients show the banner as long as they use such a token
+const (
+	ClaimImpersonator     = "impersonator"
+	ClaimImpersonatorName = "impersonatorName"
+	ClaimBanner           = "banner"
+)
+
 // Manager generate and find auth token information
 type Manager interface {
 	Generate(account.Identity) (string, error)
 	Extract(string) (*account.Identity, error)
 	Locate(ctx context.Context) (uuid.UUID, error)
+	// Impersonate generates a token of the identity acting on behalf of the
+	// impersonator, it expires at the given time
+	Impersonate(ident account.Identity, impersonator account.Identity, expiresAt time.Time) (string, error)
+	// ExtractImpersonator returns the ID of the impersonator of the token, nil
+	// if the token isn't an impersonation
+	ExtractImpersonator(string) (*uuid.UUID, error)
+	// LocateImpersonator returns the ID of the impersonator of the token of
+	// the context, nil if the token isn't an impersonation
+	LocateImpersonator(ctx context.Context) (*uuid.UUID, error)
+	// ExtractSessionID returns the ID of the session of the token, nil for
+	// tokens issued before sessions were tracked
+	ExtractSessionID(string) (*uuid.UUID, error)
+	// LocateSessionID returns the ID of the session of the token of the
+	// context, nil for tokens issued before sessions were tracked
+	LocateSessionID(ctx context.Context) (*uuid.UUID, error)
+	// ExtractIssuedAt returns when the token was issued, the zero time for
+	// tokens issued before it was recorded
+	ExtractIssuedAt(string) (time.Time, error)
 }
 
 type tokenManager struct {
@@ -36,6 +68,8 @@ func (mgm tokenManager) Generate(ident account.Identity) (string, error) {
 	token.Claims.(jwt.MapClaims)["uuid"] = ident.ID.String()
 	token.Claims.(jwt.MapClaims)["fullName"] = ident.FullName
 	token.Claims.(jwt.MapClaims)["imageURL"] = ident.ImageURL
+	token.Claims.(jwt.MapClaims)[ClaimSessionID] = uuid.NewV4().String()
+	token.Claims.(jwt.MapClaims)["iat"] = time.Now().Unix()
 
 	tokenStr, err := token.SignedString(mgm.privateKey)
 	if err != nil {
@@ -44,6 +78,98 @@ func (mgm tokenManager) Generate(ident account.Identity) (string, error) {
 	return tokenStr, nil
 }
 
+func (mgm tokenManager) Impersonate(ident account.Identity, impersonator account.Identity, expiresAt time.Time) (string, error) {
+	token := jwt.New(jwt.SigningMethodRS256)
+	claims := token.Claims.(jwt.MapClaims)
+	claims["uuid"] = ident.ID.String()
+	claims["fullName"] = ident.FullName
+	claims["imageURL"] = ident.ImageURL
+	claims["exp"] = expiresAt.Unix()
+	claims[ClaimSessionID] = uuid.NewV4().String()
+	claims["iat"] = time.Now().Unix()
+	claims[ClaimImpersonator] = impersonator.ID.String()
+	claims[ClaimImpersonatorName] = impersonator.FullName
+	claims[ClaimBanner] = fmt.Sprintf("%s is acting as %s until %s", impersonator.FullName, ident.FullName, expiresAt.UTC().Format("15:04 MST"))
+
+	return token.SignedString(mgm.privateKey)
+}
+
+func (mgm tokenManager) ExtractImpersonator(tokenString string) (*uuid.UUID, error) {
+	return mgm.extractClaim(tokenString, ClaimImpersonator)
+}
+
+func (mgm tokenManager) LocateImpersonator(ctx context.Context) (*uuid.UUID, error) {
+	return locateClaim(ctx, ClaimImpersonator)
+}
+
+func (mgm tokenManager) ExtractSessionID(tokenString string) (*uuid.UUID, error) {
+	return mgm.extractClaim(tokenString, ClaimSessionID)
+}
+
+func (mgm tokenManager) LocateSessionID(ctx context.Context) (*uuid.UUID, error) {
+	return locateClaim(ctx, ClaimSessionID)
+}
+
+func (mgm tokenManager) ExtractIssuedAt(tokenString string) (time.Time, error) {
+	token, err := mgm.parse(tokenString)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// numbers are parsed as float64
+	iat, ok := token.Claims.(jwt.MapClaims)["iat"].(float64)
+	if !ok {
+		return time.Time{}, nil
+	}
+	return time.Unix(int64(iat), 0), nil
+}
+
+// parse returns the valid token of the string
+func (mgm tokenManager) parse(tokenString string) (*jwt.Token, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return mgm.publicKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("Token not valid")
+	}
+	return token, nil
+}
+
+// extractClaim returns the UUID of the claim of the token, nil if there is
+// none
+func (mgm tokenManager) extractClaim(tokenString string, claim string) (*uuid.UUID, error) {
+	token, err := mgm.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	return uuidClaim(token.Claims.(jwt.MapClaims), claim)
+}
+
+// locateClaim returns the UUID of the claim of the token of the context, nil
+// if there is none
+func locateClaim(ctx context.Context, claim string) (*uuid.UUID, error) {
+	token := goajwt.ContextJWT(ctx)
+	if token == nil {
+		return nil, errors.New("Missing token")
+	}
+	return uuidClaim(token.Claims.(jwt.MapClaims), claim)
+}
+
+// uuidClaim returns the UUID of the claim, nil if there is none
+func uuidClaim(claims jwt.MapClaims, claim string) (*uuid.UUID, error) {
+	claimed, ok := claims[claim].(string)
+	if !ok {
+		return nil, nil
+	}
+	id, err := uuid.FromString(claimed)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
 func (mgm tokenManager) Extract(tokenString string) (*account.Identity, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		return mgm.publicKey, nil
@@ -134,6 +134,101 @@ func TestLocateInvalidUUIDInTokenInContext(t *testing.T) {
 	}
 }
 
+func TestImpersonate(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	manager := createManager(t)
+	admin := account.Identity{ID: uuid.NewV4(), FullName: "Support Admin"}
+	user := account.Identity{ID: uuid.NewV4(), FullName: "Mr Test Case"}
+
+	tokenString, err := manager.Impersonate(user, admin, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal("Could not generate impersonation token", err)
+	}
+	ident, err := manager.Extract(tokenString)
+	if err != nil {
+		t.Fatal("Could not extract Identity from impersonation token", err)
+	}
+	assert.Equal(t, user.ID, ident.ID)
+	impersonator, err := manager.ExtractImpersonator(tokenString)
+	if err != nil {
+		t.Fatal("Could not extract impersonator from impersonation token", err)
+	}
+	assert.Equal(t, admin.ID, *impersonator)
+
+	tk, err := jwt.Parse(tokenString, func(*jwt.Token) (interface{}, error) {
+		return token.ParsePublicKey([]byte(token.RSAPublicKey))
+	})
+	if err != nil {
+		t.Fatal("Could not parse impersonation token", err)
+	}
+	claims := tk.Claims.(jwt.MapClaims)
+	assert.Equal(t, "Support Admin", claims[token.ClaimImpersonatorName])
+	assert.Contains(t, claims[token.ClaimBanner], "Support Admin is acting as Mr Test Case")
+	impersonator, err = manager.LocateImpersonator(goajwt.WithJWT(context.Background(), tk))
+	if err != nil {
+		t.Fatal("Could not locate impersonator in context", err)
+	}
+	assert.Equal(t, admin.ID, *impersonator)
+
+	// regular tokens have no impersonator
+	tokenString, err = manager.Generate(user)
+	if err != nil {
+		t.Fatal("Could not generate token", err)
+	}
+	impersonator, err = manager.ExtractImpersonator(tokenString)
+	assert.Nil(t, err)
+	assert.Nil(t, impersonator)
+
+	// expired impersonation tokens are rejected
+	tokenString, err = manager.Impersonate(user, admin, time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatal("Could not generate impersonation token", err)
+	}
+	_, err = manager.Extract(tokenString)
+	assert.NotNil(t, err)
+	_, err = manager.ExtractImpersonator(tokenString)
+	assert.NotNil(t, err)
+}
+
+func TestSessionID(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	manager := createManager(t)
+	user := account.Identity{ID: uuid.NewV4(), FullName: "Mr Test Case"}
+
+	first, err := manager.Generate(user)
+	if err != nil {
+		t.Fatal("Could not generate token", err)
+	}
+	second, err := manager.Generate(user)
+	if err != nil {
+		t.Fatal("Could not generate token", err)
+	}
+	firstID, err := manager.ExtractSessionID(first)
+	if err != nil || firstID == nil {
+		t.Fatal("Could not extract session ID from generated token", err)
+	}
+	secondID, err := manager.ExtractSessionID(second)
+	if err != nil || secondID == nil {
+		t.Fatal("Could not extract session ID from generated token", err)
+	}
+	assert.NotEqual(t, *firstID, *secondID)
+
+	tk := jwt.New(jwt.SigningMethodRS256)
+	tk.Claims.(jwt.MapClaims)["uuid"] = user.ID.String()
+	tk.Claims.(jwt.MapClaims)[token.ClaimSessionID] = firstID.String()
+	located, err := manager.LocateSessionID(goajwt.WithJWT(context.Background(), tk))
+	assert.Nil(t, err)
+	assert.Equal(t, firstID, located)
+
+	// tokens issued before sessions were tracked have none
+	delete(tk.Claims.(jwt.MapClaims), token.ClaimSessionID)
+	located, err = manager.LocateSessionID(goajwt.WithJWT(context.Background(), tk))
+	assert.Nil(t, err)
+	assert.Nil(t, located)
+}
+
 func createManager(t *testing.T) token.Manager {
 	publicKey, err := token.ParsePublicKey([]byte(token.RSAPublicKey))
 	if err != nil {
@@ -26,7 +26,7 @@ func NewTrackerController(service *goa.Service, db application.DB, scheduler *re
 
 // Create runs the create action.
 func (c *TrackerController) Create(ctx *app.CreateTrackerContext) error {
-	result := application.Transactional(c.db, func(appl application.Application) error {
+	result := application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 		t, err := appl.Trackers().Create(ctx.Context, ctx.Payload.URL, ctx.Payload.Type)
 		if err != nil {
 			switch err := err.(type) {
@@ -47,7 +47,7 @@ func (c *TrackerController) Create(ctx *app.CreateTrackerContext) error {
 
 // Delete runs the delete action.
 func (c *TrackerController) Delete(ctx *app.DeleteTrackerContext) error {
-	result := application.Transactional(c.db, func(appl application.Application) error {
+	result := application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 		err := appl.Trackers().Delete(ctx.Context, ctx.ID)
 		if err != nil {
 			switch err.(type) {
@@ -67,7 +67,7 @@ func (c *TrackerController) Delete(ctx *app.DeleteTrackerContext) error {
 
 // Show runs the show action.
 func (c *TrackerController) Show(ctx *app.ShowTrackerContext) error {
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 		t, err := appl.Trackers().Load(ctx.Context, ctx.ID)
 		if err != nil {
 			switch err.(type) {
@@ -96,7 +96,7 @@ func (c *TrackerController) List(ctx *app.ListTrackerContext) error {
 		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(fmt.Sprintf("could not parse paging: %s", err.Error())))
 		return ctx.BadRequest(jerrors)
 	}
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 		result, err := appl.Trackers().List(ctx.Context, exp, start, &limit)
 		if err != nil {
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(fmt.Sprintf("Error listing trackers: %s", err.Error())))
@@ -109,7 +109,7 @@ func (c *TrackerController) List(ctx *app.ListTrackerContext) error {
 
 // Update runs the update action.
 func (c *TrackerController) Update(ctx *app.UpdateTrackerContext) error {
-	result := application.Transactional(c.db, func(appl application.Application) error {
+	result := application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 
 		toSave := app.Tracker{
 			ID:   ctx.ID,
@@ -0,0 +1,235 @@
+// Package translation stores the labels of the work item type names and of
+// the states and priorities in other languages than the one they are
+// defined in, and picks the locale of a request from its Accept-Language
+// header.
+package translation
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+)
+
+// SubjectType is the subject of the translations of the work item type
+// names, the other subjects are the names of the translated fields
+const SubjectType = "type"
+
+// Subjects lists what can be translated
+var Subjects = []string{SubjectType, workitem.SystemState, workitem.SystemPriority}
+
+// localePattern matches language tags like "de" or "pt-BR"
+var localePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// Translation is the label of a type name or a field value in a locale
+type Translation struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Locale    string `gorm:"primary_key"`
+	Subject   string `gorm:"primary_key"`
+	Value     string `gorm:"primary_key"`
+	Label     string
+}
+
+// TableName implements gorm.tabler
+func (t Translation) TableName() string {
+	return "translations"
+}
+
+// Labels holds the labels of a locale by subject and value
+type Labels map[string]map[string]string
+
+// Repository encapsulates storage & retrieval of translations
+type Repository interface {
+	Locales(ctx context.Context) ([]string, error)
+	List(ctx context.Context, locale string) ([]Translation, error)
+	Replace(ctx context.Context, locale string, translations []Translation) ([]Translation, error)
+	Delete(ctx context.Context, locale string) error
+	Labels(ctx context.Context, locale string) (Labels, error)
+}
+
+// NewTranslationRepository creates a new storage type.
+func NewTranslationRepository(db *gorm.DB) Repository {
+	return &GormTranslationRepository{db: db}
+}
+
+// GormTranslationRepository is the implementation of the storage interface
+// for translations.
+type GormTranslationRepository struct {
+	db *gorm.DB
+}
+
+// Locales returns the locales with translations in alphabetical order
+// returns InternalError
+func (m *GormTranslationRepository) Locales(ctx context.Context) ([]string, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "translation", "locales"}, time.Now())
+
+	var locales []string
+	if err := m.db.Raw("SELECT DISTINCT locale FROM translations ORDER BY locale").Pluck("locale", &locales).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return locales, nil
+}
+
+// List returns the translations of the locale ordered by subject and value
+// returns InternalError
+func (m *GormTranslationRepository) List(ctx context.Context, locale string) ([]Translation, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "translation", "list"}, time.Now())
+
+	var objs []Translation
+	if err := m.db.Where("locale = ?", locale).Order("subject, value").Find(&objs).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return objs, nil
+}
+
+// Replace sets the translations of the locale to the given ones and returns
+// them as stored
+// returns BadParameterError or InternalError
+func (m *GormTranslationRepository) Replace(ctx context.Context, locale string, translations []Translation) ([]Translation, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "translation", "replace"}, time.Now())
+
+	if !localePattern.MatchString(locale) {
+		return nil, errors.NewBadParameterError("locale", locale).Expected("a language tag like 'de' or 'pt-BR'")
+	}
+	for _, t := range translations {
+		if !knownSubject(t.Subject) {
+			return nil, errors.NewBadParameterError("subject", t.Subject).Expected(Subjects)
+		}
+		if t.Value == "" || strings.TrimSpace(t.Label) == "" {
+			return nil, errors.NewBadParameterError("label", t.Label).Expected("a value and a label")
+		}
+	}
+	if err := m.db.Where("locale = ?", locale).Delete(&Translation{}).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	for _, t := range translations {
+		t.Locale = locale
+		if err := m.db.Create(&t).Error; err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+	}
+	return m.List(ctx, locale)
+}
+
+// Delete removes all translations of the locale
+// returns NotFoundError or InternalError
+func (m *GormTranslationRepository) Delete(ctx context.Context, locale string) error {
+	defer goa.MeasureSince([]string{"goa", "db", "translation", "delete"}, time.Now())
+
+	tx := m.db.Where("locale = ?", locale).Delete(&Translation{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("translations", locale)
+	}
+	return nil
+}
+
+// Labels returns the labels of the locale by subject and value
+// returns InternalError
+func (m *GormTranslationRepository) Labels(ctx context.Context, locale string) (Labels, error) {
+	objs, err := m.List(ctx, locale)
+	if err != nil {
+		return nil, err
+	}
+	labels := Labels{}
+	for _, t := range objs {
+		if labels[t.Subject] == nil {
+			labels[t.Subject] = map[string]string{}
+		}
+		labels[t.Subject][t.Value] = t.Label
+	}
+	return labels, nil
+}
+
+func knownSubject(subject string) bool {
+	for _, s := range Subjects {
+		if s == subject {
+			return true
+		}
+	}
+	return false
+}
+
+// Negotiate returns the locale among the available ones that suits the
+// Accept-Language header best, "" if none does. A locale suits a language
+// when they are the same or share the primary language, "de-CH" accepts "de"
+// and "de" accepts "de-AT" if there is no better match.
+func Negotiate(acceptLanguage string, available []string) string {
+	ranges := parseAcceptLanguage(acceptLanguage)
+	for _, r := range ranges {
+		for _, locale := range available {
+			if strings.EqualFold(r, locale) {
+				return locale
+			}
+		}
+		for _, locale := range available {
+			if strings.EqualFold(primary(r), primary(locale)) {
+				return locale
+			}
+		}
+	}
+	return ""
+}
+
+// acceptedRange is a language range of an Accept-Language header
+type acceptedRange struct {
+	tag string
+	q   float64
+}
+
+// byQuality sorts language ranges by quality, the first one first for equal
+// qualities
+type byQuality []acceptedRange
+
+func (r byQuality) Len() int           { return len(r) }
+func (r byQuality) Less(i, j int) bool { return r[i].q > r[j].q }
+func (r byQuality) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+
+// parseAcceptLanguage returns the language ranges of the header by
+// decreasing preference, without the wildcard and the refused ones
+func parseAcceptLanguage(header string) []string {
+	var ranges []acceptedRange
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			ranges = append(ranges, acceptedRange{tag: tag, q: q})
+		}
+	}
+	sort.Stable(byQuality(ranges))
+	res := make([]string, len(ranges))
+	for i, r := range ranges {
+		res[i] = r.tag
+	}
+	return res
+}
+
+func primary(tag string) string {
+	if i := strings.Index(tag, "-"); i >= 0 {
+		return tag[:i]
+	}
+	return tag
+}
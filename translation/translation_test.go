@@ -0,0 +1,83 @@
+package translation_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/translation"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestNegotiate(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	available := []string{"de", "fr-CA", "pt-BR"}
+	assert.Equal(t, "de", translation.Negotiate("de", available))
+	assert.Equal(t, "de", translation.Negotiate("de-CH, en;q=0.8", available))
+	assert.Equal(t, "fr-CA", translation.Negotiate("fr", available))
+	assert.Equal(t, "pt-BR", translation.Negotiate("pt-br", available))
+	assert.Equal(t, "pt-BR", translation.Negotiate("de;q=0.5, pt-BR", available))
+	assert.Equal(t, "fr-CA", translation.Negotiate("de;q=0, fr;q=0.1", available))
+	assert.Equal(t, "", translation.Negotiate("en-US, *;q=0.5", available))
+	assert.Equal(t, "", translation.Negotiate("", available))
+}
+
+type TestTranslationRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunTranslationRepository(t *testing.T) {
+	suite.Run(t, &TestTranslationRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestTranslationRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestTranslationRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestTranslationRepository) TestReplaceAndDelete() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := translation.NewTranslationRepository(test.DB)
+	_, err := repo.Replace(ctx, "de_DE", nil)
+	assert.IsType(t, errors.BadParameterError{}, err)
+	_, err = repo.Replace(ctx, "de", []translation.Translation{{Subject: "system.title", Value: "x", Label: "y"}})
+	assert.IsType(t, errors.BadParameterError{}, err)
+
+	stored, err := repo.Replace(ctx, "de", []translation.Translation{
+		{Subject: workitem.SystemState, Value: workitem.SystemStateClosed, Label: "Geschlossen"},
+		{Subject: translation.SubjectType, Value: workitem.SystemBug, Label: "Fehler"},
+	})
+	require.Nil(t, err)
+	require.Len(t, stored, 2)
+	assert.Equal(t, "de", stored[0].Locale)
+	stored, err = repo.Replace(ctx, "de", []translation.Translation{
+		{Subject: workitem.SystemState, Value: workitem.SystemStateOpen, Label: "Offen"},
+	})
+	require.Nil(t, err)
+	require.Len(t, stored, 1)
+
+	labels, err := repo.Labels(ctx, "de")
+	require.Nil(t, err)
+	assert.Equal(t, translation.Labels{workitem.SystemState: {workitem.SystemStateOpen: "Offen"}}, labels)
+	locales, err := repo.Locales(ctx)
+	require.Nil(t, err)
+	assert.Contains(t, locales, "de")
+
+	require.Nil(t, repo.Delete(ctx, "de"))
+	assert.IsType(t, errors.NotFoundError{}, repo.Delete(ctx, "de"))
+}
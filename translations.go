@@ -0,0 +1,110 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/translation"
+	"github.com/goadesign/goa"
+)
+
+// TranslationsController implements the translations resource.
+type TranslationsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewTranslationsController creates a translations controller.
+func NewTranslationsController(service *goa.Service, db application.DB) *TranslationsController {
+	return &TranslationsController{Controller: service.NewController("TranslationsController"), db: db}
+}
+
+// List runs the list action.
+func (c *TranslationsController) List(ctx *app.ListTranslationsContext) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		locales, err := appl.Translations().Locales(ctx)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		var locale string
+		if ctx.Locale != nil {
+			locale = translation.Negotiate(*ctx.Locale, locales)
+		} else if ctx.AcceptLanguage != nil {
+			locale = translation.Negotiate(*ctx.AcceptLanguage, locales)
+		}
+		var translations []translation.Translation
+		if locale != "" {
+			if translations, err = appl.Translations().List(ctx, locale); err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			ctx.ResponseData.Header().Set("Content-Language", locale)
+		}
+		ctx.ResponseData.Header().Set("Vary", "Accept-Language")
+		return ctx.OK(ConvertTranslations(locale, locales, translations))
+	})
+}
+
+// Update runs the update action.
+func (c *TranslationsController) Update(ctx *app.UpdateTranslationsContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can manage translations"))
+	}
+	translations := make([]translation.Translation, 0, len(ctx.Payload.Data))
+	for _, t := range ctx.Payload.Data {
+		if t == nil || t.Attributes == nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+		}
+		translations = append(translations, translation.Translation{
+			Subject: t.Attributes.Subject,
+			Value:   t.Attributes.Value,
+			Label:   t.Attributes.Label,
+		})
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		stored, err := appl.Translations().Replace(ctx, ctx.Locale, translations)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		locales, err := appl.Translations().Locales(ctx)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(ConvertTranslations(ctx.Locale, locales, stored))
+	})
+}
+
+// Delete runs the delete action.
+func (c *TranslationsController) Delete(ctx *app.DeleteTranslationsContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can manage translations"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if err := appl.Translations().Delete(ctx, ctx.Locale); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// ConvertTranslations converts between internal and external REST representation
+func ConvertTranslations(locale string, locales []string, translations []translation.Translation) *app.TranslationList {
+	res := &app.TranslationList{
+		Data: make([]*app.Translation, 0, len(translations)),
+		Meta: &app.TranslationListMeta{Locale: locale, Locales: locales},
+	}
+	if res.Meta.Locales == nil {
+		res.Meta.Locales = []string{}
+	}
+	for _, t := range translations {
+		res.Data = append(res.Data, &app.Translation{
+			Type: "translations",
+			Attributes: &app.TranslationAttributes{
+				Subject: t.Subject,
+				Value:   t.Value,
+				Label:   t.Label,
+			},
+		})
+	}
+	return res
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/jsonapi"
 	"github.com/almighty/almighty-core/token"
 	"github.com/goadesign/goa"
@@ -36,5 +37,41 @@ func (c *UserController) Show(ctx *app.ShowUserContext) error {
 		return ctx.Unauthorized(jerrors)
 	}
 
-	return ctx.OK(ident.ConvertIdentityFromModel())
+	return ctx.OK(convertUser(ident))
+}
+
+// Update changes the preferences of the authorized user
+func (c *UserController) Update(ctx *app.UpdateUserContext) error {
+	identID, err := c.tokenManager.Locate(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(err.Error()))
+		return ctx.BadRequest(jerrors)
+	}
+	ident, err := c.identityRepository.Load(ctx, identID)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(fmt.Sprintf("Auth token contains id %s of unknown Identity\n", identID)))
+		return ctx.Unauthorized(jerrors)
+	}
+	if ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	if tz := ctx.Payload.Data.Attributes.Timezone; tz != nil {
+		if err := account.ValidateTimezone(*tz); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		ident.Timezone = *tz
+	}
+	if err := c.identityRepository.Save(ctx, ident); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(convertUser(ident))
+}
+
+// convertUser converts the authorized user, with the preferences only the
+// user sees
+func convertUser(ident *account.Identity) *app.Identity {
+	res := ident.ConvertIdentityFromModel()
+	timezone := ident.Timezone
+	res.Data.Attributes.Timezone = &timezone
+	return res
 }
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/token"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// InjectViewer is a middleware that restricts the projects and work items
+// every request can see to the ones visible to the identity of its bearer
+// token. Requests without a valid token are anonymous, they can only read
+// public projects and can't see confidential work items.
+func InjectViewer(db application.DB, tm token.Manager) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			var identityID *uuid.UUID
+			auth := req.Header.Get("Authorization")
+			if strings.HasPrefix(auth, "Bearer ") {
+				identity, err := tm.Extract(strings.TrimPrefix(auth, "Bearer "))
+				if err == nil {
+					identityID = &identity.ID
+				}
+			}
+			var viewer *workitem.Viewer
+			err := application.Transactional(requestDB(ctx, db), func(appl application.Application) error {
+				var err error
+				viewer, err = loadViewer(ctx, appl, identityID)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			return h(workitem.WithViewer(ctx, viewer), rw, req)
+		}
+	}
+}
+
+// loadViewer returns the viewer of the identity with the given ID, a nil ID
+// is an anonymous viewer
+func loadViewer(ctx context.Context, appl application.Application, identityID *uuid.UUID) (*workitem.Viewer, error) {
+	viewer := &workitem.Viewer{IdentityID: identityID}
+	var err error
+	if identityID != nil {
+		viewer.AdminProjectIDs, err = appl.Projects().AdminProjectIDs(ctx, *identityID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	viewer.HiddenProjectIDs, err = appl.Projects().HiddenProjectIDs(ctx, identityID)
+	if err != nil {
+		return nil, err
+	}
+	return viewer, nil
+}
+
+// viewerLocation returns the timezone of the viewer of ctx, UTC for
+// anonymous viewers
+func viewerLocation(ctx context.Context, appl application.Application) *time.Location {
+	viewer := workitem.ContextViewer(ctx)
+	if viewer == nil || viewer.IdentityID == nil {
+		return time.UTC
+	}
+	identity, err := appl.Identities().Load(ctx, *viewer.IdentityID)
+	if err != nil {
+		return time.UTC
+	}
+	return identity.Location()
+}
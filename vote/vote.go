@@ -0,0 +1,143 @@
+package vote
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Vote records that an identity upvoted a work item
+type Vote struct {
+	gormsupport.Lifecycle
+	ID         uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	WorkItemID uint64
+	IdentityID uuid.UUID `sql:"type:uuid"`
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m Vote) TableName() string {
+	return "work_item_votes"
+}
+
+// Repository describes interactions with votes
+type Repository interface {
+	Create(ctx context.Context, workItemID uint64, identityID uuid.UUID) error
+	Delete(ctx context.Context, workItemID uint64, identityID uuid.UUID) error
+	Count(ctx context.Context, workItemIDs []uint64) (map[uint64]int, error)
+	Voted(ctx context.Context, workItemIDs []uint64, identityID uuid.UUID) (map[uint64]bool, error)
+}
+
+// NewVoteRepository creates a new storage type.
+func NewVoteRepository(db *gorm.DB) Repository {
+	return &GormVoteRepository{db: db}
+}
+
+// GormVoteRepository is the implementation of the storage interface for votes.
+type GormVoteRepository struct {
+	db *gorm.DB
+}
+
+// Create records the vote of the identity for the work item. Voting again
+// for the same work item has no effect.
+// returns BadParameterError or InternalError
+func (m *GormVoteRepository) Create(ctx context.Context, workItemID uint64, identityID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "vote", "create"}, time.Now())
+
+	if workItemID == 0 {
+		return errors.NewBadParameterError("work_item_id", workItemID)
+	}
+	var count int
+	err := m.db.Model(&Vote{}).Where("work_item_id = ? AND identity_id = ?", workItemID, identityID).Count(&count).Error
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if count > 0 {
+		return nil
+	}
+
+	v := Vote{ID: uuid.NewV4(), WorkItemID: workItemID, IdentityID: identityID}
+	err = m.db.Create(&v).Error
+	if err != nil {
+		if gormsupport.IsUniqueViolation(err, "work_item_votes_work_item_id_identity_id_idx") {
+			// a concurrent request voted already
+			return nil
+		}
+		goa.LogError(ctx, "error adding Vote", "error", err.Error())
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// Delete removes the vote of the identity for the work item
+// returns NotFoundError or InternalError
+func (m *GormVoteRepository) Delete(ctx context.Context, workItemID uint64, identityID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "vote", "delete"}, time.Now())
+
+	tx := m.db.Where("work_item_id = ? AND identity_id = ?", workItemID, identityID).Delete(&Vote{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("vote", identityID.String())
+	}
+	return nil
+}
+
+// Count returns the number of votes of each of the given work items, work
+// items without votes are not contained in the result
+// returns InternalError
+func (m *GormVoteRepository) Count(ctx context.Context, workItemIDs []uint64) (map[uint64]int, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "vote", "count"}, time.Now())
+
+	counts := map[uint64]int{}
+	if len(workItemIDs) == 0 {
+		return counts, nil
+	}
+	rows, err := m.db.Model(&Vote{}).
+		Select("work_item_id, count(*)").
+		Where("work_item_id IN (?)", workItemIDs).
+		Group("work_item_id").
+		Rows()
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id uint64
+		var count int
+		if err := rows.Scan(&id, &count); err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		counts[id] = count
+	}
+	return counts, nil
+}
+
+// Voted returns which of the given work items the identity voted for
+// returns InternalError
+func (m *GormVoteRepository) Voted(ctx context.Context, workItemIDs []uint64, identityID uuid.UUID) (map[uint64]bool, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "vote", "voted"}, time.Now())
+
+	voted := map[uint64]bool{}
+	if len(workItemIDs) == 0 {
+		return voted, nil
+	}
+	var ids []uint64
+	err := m.db.Model(&Vote{}).
+		Where("work_item_id IN (?) AND identity_id = ?", workItemIDs, identityID).
+		Pluck("work_item_id", &ids).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	for _, id := range ids {
+		voted[id] = true
+	}
+	return voted, nil
+}
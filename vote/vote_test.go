@@ -0,0 +1,84 @@
+package vote_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/gormsupport/testfixture"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/vote"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestVoteRepository struct {
+	gormsupport.DBTestSuite
+
+	clean      func()
+	workItemID uint64
+}
+
+func TestRunVoteRepository(t *testing.T) {
+	suite.Run(t, &TestVoteRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestVoteRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+
+	wi, err := workitem.NewWorkItemRepository(test.DB).Create(
+		context.Background(), workitem.SystemFeature,
+		map[string]interface{}{
+			workitem.SystemTitle: "Title",
+			workitem.SystemState: workitem.SystemStateNew,
+		}, "xx")
+	require.Nil(test.T(), err)
+	test.workItemID, err = workitem.ParseWorkItemIDToUint64(wi.ID)
+	require.Nil(test.T(), err)
+}
+
+func (test *TestVoteRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestVoteRepository) TestVoteOncePerIdentity() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := vote.NewVoteRepository(test.DB)
+	alice := testfixture.CreateIdentity(t, test.DB, "alice")
+	bob := testfixture.CreateIdentity(t, test.DB, "bob")
+	require.Nil(t, repo.Create(context.Background(), test.workItemID, alice))
+	require.Nil(t, repo.Create(context.Background(), test.workItemID, alice))
+	require.Nil(t, repo.Create(context.Background(), test.workItemID, bob))
+
+	counts, err := repo.Count(context.Background(), []uint64{test.workItemID})
+	require.Nil(t, err)
+	assert.Equal(t, 2, counts[test.workItemID])
+
+	voted, err := repo.Voted(context.Background(), []uint64{test.workItemID}, alice)
+	require.Nil(t, err)
+	assert.True(t, voted[test.workItemID])
+}
+
+func (test *TestVoteRepository) TestUnvote() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := vote.NewVoteRepository(test.DB)
+	alice := testfixture.CreateIdentity(t, test.DB, "alice")
+	require.Nil(t, repo.Create(context.Background(), test.workItemID, alice))
+	require.Nil(t, repo.Delete(context.Background(), test.workItemID, alice))
+	assert.IsType(t, errors.NotFoundError{}, repo.Delete(context.Background(), test.workItemID, alice))
+
+	counts, err := repo.Count(context.Background(), []uint64{test.workItemID})
+	require.Nil(t, err)
+	assert.Equal(t, 0, counts[test.workItemID])
+
+	// voting again after removing the vote is allowed
+	require.Nil(t, repo.Create(context.Background(), test.workItemID, alice))
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io/ioutil"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/bodylimit"
+	"github.com/almighty/almighty-core/codechange"
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/job"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+)
+
+// WebhooksController implements the webhooks resource.
+type WebhooksController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWebhooksController creates a webhooks controller.
+func NewWebhooksController(service *goa.Service, db application.DB) *WebhooksController {
+	return &WebhooksController{Controller: service.NewController("WebhooksController"), db: db}
+}
+
+// Github runs the github action.
+func (c *WebhooksController) Github(ctx *app.GithubWebhooksContext) error {
+	body, err := ioutil.ReadAll(ctx.Request.Body)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, bodylimit.ReadError("payload", err))
+	}
+	// without a secret anybody could fake the payloads, so they are refused
+	secret := configuration.GetGithubWebhookSecret()
+	if secret == "" {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("webhook secret not configured"))
+	}
+	if !codechange.VerifyGitHubSignature(secret, ctx.Request.Header.Get("X-Hub-Signature"), body) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("invalid webhook signature"))
+	}
+	changes, err := codechange.ParseGitHubEvent(ctx.Request.Header.Get("X-GitHub-Event"), body)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if _, err := appl.Jobs().Enqueue(ctx, associateCodeChangesJobKind, changes); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// Gitlab runs the gitlab action.
+func (c *WebhooksController) Gitlab(ctx *app.GitlabWebhooksContext) error {
+	body, err := ioutil.ReadAll(ctx.Request.Body)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, bodylimit.ReadError("payload", err))
+	}
+	token := configuration.GetGitlabWebhookToken()
+	if token == "" {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("webhook token not configured"))
+	}
+	if subtle.ConstantTimeCompare([]byte(ctx.Request.Header.Get("X-Gitlab-Token")), []byte(token)) != 1 {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("invalid webhook token"))
+	}
+	changes, err := codechange.ParseGitLabEvent(ctx.Request.Header.Get("X-Gitlab-Event"), body)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if _, err := appl.Jobs().Enqueue(ctx, associateCodeChangesJobKind, changes); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// associateCodeChangesJobKind is the kind of the jobs associating the changes
+// received by the webhooks with their work items
+const associateCodeChangesJobKind = "codechange.associate"
+
+// associateCodeChangesJob returns the handler of the jobs enqueued by the
+// webhooks, so that the repository hosts don't wait for the association
+func associateCodeChangesJob(db application.DB) job.Handler {
+	return func(ctx context.Context, payload []byte) error {
+		var changes []codechange.Change
+		if err := json.Unmarshal(payload, &changes); err != nil {
+			return errors.NewConversionError(err.Error())
+		}
+		return application.Transactional(db, func(appl application.Application) error {
+			return associateCodeChanges(ctx, appl, changes)
+		})
+	}
+}
+
+// associateCodeChanges records each change for all the work items it references.
+// References to unknown work items are ignored.
+func associateCodeChanges(ctx context.Context, appl application.Application, changes []codechange.Change) error {
+	// the repository hosts have access to all work items, confidential or not
+	ctx = workitem.WithViewer(ctx, nil)
+	for _, change := range changes {
+		for _, ref := range change.References() {
+			wi, err := appl.WorkItems().Load(ctx, ref)
+			if err != nil {
+				if _, ok := err.(errors.NotFoundError); ok {
+					continue
+				}
+				return err
+			}
+			// references may be keys, the change is recorded for the ID
+			wiID, err := workitem.ParseWorkItemIDToUint64(wi.ID)
+			if err != nil {
+				return err
+			}
+			c := change.CodeChange
+			c.WorkItemID = wiID
+			if err := appl.CodeChanges().Associate(ctx, &c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/approval"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// WorkItemApprovalsController implements the work-item-approvals resource.
+type WorkItemApprovalsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemApprovalsController creates a work-item-approvals controller.
+func NewWorkItemApprovalsController(service *goa.Service, db application.DB) *WorkItemApprovalsController {
+	return &WorkItemApprovalsController{Controller: service.NewController("WorkItemApprovalsController"), db: db}
+}
+
+// List runs the list action.
+func (c *WorkItemApprovalsController) List(ctx *app.ListWorkItemApprovalsContext) error {
+	wiID, err := workitem.ParseWorkItemIDToUint64(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if _, err := appl.WorkItems().Load(ctx, ctx.ID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		approvals, err := appl.Approvals().List(ctx, wiID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		data := make([]*app.Approval, 0, len(approvals))
+		for _, a := range approvals {
+			data = append(data, ConvertApproval(ctx.RequestData, a))
+		}
+		return ctx.OK(&app.ApprovalList{
+			Data: data,
+			Meta: &app.WorkItemListResponseMeta{TotalCount: len(data)},
+		})
+	})
+}
+
+// Create runs the create action.
+func (c *WorkItemApprovalsController) Create(ctx *app.CreateWorkItemApprovalsContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing or invalid identity"))
+	}
+	wiID, err := workitem.ParseWorkItemIDToUint64(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	if ctx.Payload == nil || ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	to := ctx.Payload.Data.Attributes.ToState
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		wi, err := appl.WorkItems().Load(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		from := contributionText(wi.Fields[workitem.SystemState])
+		projectID, err := uuid.FromString(contributionText(wi.Fields[workitem.SystemProject]))
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.to-state", to).Expected("a state requiring approvals"))
+		}
+		r, err := appl.Approvals().Rule(ctx, projectID, wi.Type, from, to)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if r == nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.to-state", to).Expected("a state requiring approvals"))
+		}
+		a := approval.Approval{
+			WorkItemID:  wiID,
+			RuleID:      r.ID,
+			FromState:   from,
+			ToState:     to,
+			RequestedBy: *identityID,
+		}
+		if err := appl.Approvals().Request(ctx, &a); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.ApprovalSingle{Data: ConvertApproval(ctx.RequestData, &a)}
+		ctx.ResponseData.Header().Set("Location", AbsoluteURL(ctx.RequestData, app.ApprovalHref(a.ID)))
+		return ctx.Created(res)
+	})
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/goadesign/goa"
+)
+
+// WorkItemArchiveController implements the work-item-archive resource.
+type WorkItemArchiveController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemArchiveController creates a work-item-archive controller.
+func NewWorkItemArchiveController(service *goa.Service, db application.DB) *WorkItemArchiveController {
+	return &WorkItemArchiveController{Controller: service.NewController("WorkItemArchiveController"), db: db}
+}
+
+// Archive runs the archive action.
+func (c *WorkItemArchiveController) Archive(ctx *app.ArchiveWorkItemArchiveContext) error {
+	return c.archive(ctx, ctx.ID, true, ctx.RequestData, ctx.OK)
+}
+
+// Unarchive runs the unarchive action.
+func (c *WorkItemArchiveController) Unarchive(ctx *app.UnarchiveWorkItemArchiveContext) error {
+	return c.archive(ctx, ctx.ID, false, ctx.RequestData, ctx.OK)
+}
+
+// archiveContext is implemented by the contexts of the archive actions
+type archiveContext interface {
+	context.Context
+	jsonapi.InternalServerError
+}
+
+// archive sets whether the work item is archived and responds with it
+func (c *WorkItemArchiveController) archive(ctx archiveContext, id string, archived bool, request *goa.RequestData, ok func(*app.WorkItem2Single) error) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		wi, err := appl.WorkItemArchive().Archive(ctx, id, archived)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ok(&app.WorkItem2Single{
+			Data: ConvertWorkItem(request, wi),
+			Links: &app.WorkItemLinks{
+				Self: AbsoluteURL(request, app.WorkitemHref(wi.ID)),
+			},
+		})
+	})
+}
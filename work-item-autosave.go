@@ -0,0 +1,106 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+)
+
+// WorkItemAutosaveController implements the work-item-autosave resource.
+type WorkItemAutosaveController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemAutosaveController creates a work-item-autosave controller.
+func NewWorkItemAutosaveController(service *goa.Service, db application.DB) *WorkItemAutosaveController {
+	return &WorkItemAutosaveController{Controller: service.NewController("WorkItemAutosaveController"), db: db}
+}
+
+// Show runs the show action.
+func (c *WorkItemAutosaveController) Show(ctx *app.ShowWorkItemAutosaveContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		a, err := appl.WorkItemAutosaves().Load(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.AutosaveSingle{Data: ConvertAutosave(a)})
+	})
+}
+
+// Save runs the save action.
+func (c *WorkItemAutosaveController) Save(ctx *app.SaveWorkItemAutosaveContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		a, err := appl.WorkItemAutosaves().Save(ctx, ctx.ID, ctx.Payload.Fields)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.AutosaveSingle{Data: ConvertAutosave(a)})
+	})
+}
+
+// Apply runs the apply action.
+func (c *WorkItemAutosaveController) Apply(ctx *app.ApplyWorkItemAutosaveContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		before, err := appl.WorkItems().Load(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		wi, err := appl.WorkItemAutosaves().Apply(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		// the autosaved changes are one update of the work item
+		wi, err = automate(ctx, appl, ctx.RequestData, before, wi, "")
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if err := notifyChat(ctx, appl, ctx.RequestData, before, wi); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.WorkItem2Single{
+			Data: ConvertWorkItem(ctx.RequestData, wi),
+			Links: &app.WorkItemLinks{
+				Self: AbsoluteURL(ctx.RequestData, app.WorkitemHref(wi.ID)),
+			},
+		})
+	})
+}
+
+// Discard runs the discard action.
+func (c *WorkItemAutosaveController) Discard(ctx *app.DiscardWorkItemAutosaveContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		if err := appl.WorkItemAutosaves().Discard(ctx, ctx.ID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.NoContent()
+	})
+}
+
+// ConvertAutosave converts autosaved changes to the app representation
+func ConvertAutosave(a *workitem.Autosave) *app.Autosave {
+	return &app.Autosave{
+		Fields:    a.Fields,
+		Version:   a.Version,
+		UpdatedAt: a.UpdatedAt,
+	}
+}
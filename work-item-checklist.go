@@ -0,0 +1,44 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/goadesign/goa"
+)
+
+// WorkItemChecklistController implements the work-item-checklist resource.
+type WorkItemChecklistController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemChecklistController creates a work-item-checklist controller.
+func NewWorkItemChecklistController(service *goa.Service, db application.DB) *WorkItemChecklistController {
+	return &WorkItemChecklistController{Controller: service.NewController("WorkItemChecklistController"), db: db}
+}
+
+// Check runs the check action.
+func (c *WorkItemChecklistController) Check(ctx *app.CheckWorkItemChecklistContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	if ctx.Payload == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("done", nil).Expected("a boolean"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		wi, err := appl.WorkItemChecklists().Check(ctx, ctx.ID, ctx.Field, ctx.Item, ctx.Payload.Done)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.WorkItem2Single{
+			Data: ConvertWorkItem(ctx.RequestData, wi),
+			Links: &app.WorkItemLinks{
+				Self: AbsoluteURL(ctx.RequestData, app.WorkitemHref(wi.ID)),
+			},
+		})
+	})
+}
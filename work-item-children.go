@@ -0,0 +1,148 @@
+package main
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/moderation"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/almighty/almighty-core/workitem/link"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// childrenAttribute is the computed attribute of work items holding the
+// rollup of their children
+const childrenAttribute = "children"
+
+// inheritedFields are the fields children get from their parent unless they
+// are given
+var inheritedFields = []string{workitem.SystemProject, workitem.SystemIteration, workitem.SystemRelease}
+
+// WorkItemChildrenController implements the work-item-children resource.
+type WorkItemChildrenController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemChildrenController creates a work-item-children controller.
+func NewWorkItemChildrenController(service *goa.Service, db application.DB) *WorkItemChildrenController {
+	return &WorkItemChildrenController{Controller: service.NewController("WorkItemChildrenController"), db: db}
+}
+
+// Create runs the create action.
+func (c *WorkItemChildrenController) Create(ctx *app.CreateWorkItemChildrenContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	if ctx.Payload == nil || ctx.Payload.Data == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data", nil).Expected("not nil"))
+	}
+	rel := ctx.Payload.Data.Relationships
+	if rel == nil || rel.BaseType == nil || rel.BaseType.Data == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.relationships.basetype.data.id", nil))
+	}
+	wit := rel.BaseType.Data.ID
+	var linkTypeID *uuid.UUID
+	if ctx.LinkType != nil {
+		id, err := uuid.FromString(*ctx.LinkType)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("link_type", *ctx.LinkType).Expected("a link type ID"))
+		}
+		linkTypeID = &id
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		parent, err := appl.WorkItems().Load(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		parentID, err := workitem.ParseWorkItemIDToUint64(parent.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		lt, err := appl.WorkItemLinks().TreeLinkType(ctx, parent.Type, wit, linkTypeID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		child := app.WorkItem{
+			Fields: make(map[string]interface{}),
+		}
+		if err := ConvertJSONAPIToWorkItem(appl, *ctx.Payload.Data, &child); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		for _, field := range inheritedFields {
+			if _, ok := child.Fields[field]; !ok && parent.Fields[field] != nil {
+				child.Fields[field] = parent.Fields[field]
+			}
+		}
+		pending, err := checkContribution(ctx, appl, moderation.KindWorkItem, child.Fields[workitem.SystemProject], currentUser,
+			contributionText(child.Fields[workitem.SystemTitle]), contributionText(child.Fields[workitem.SystemDescription]))
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if pending {
+			child.Fields[workitem.SystemPendingReview] = true
+		}
+		if err := assignNewWorkItem(ctx, appl, wit, child.Fields); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		wi, err := appl.WorkItems().Create(ctx, wit, child.Fields, currentUser)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		childID, err := workitem.ParseWorkItemIDToUint64(wi.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if _, err := appl.WorkItemLinks().Create(ctx, parentID, childID, lt.ID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		wi, err = automate(ctx, appl, ctx.RequestData, nil, wi, "")
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if err := notifyChat(ctx, appl, ctx.RequestData, nil, wi); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		ctx.ResponseData.Header().Set("Location", app.WorkitemHref(wi.ID))
+		return ctx.Created(&app.WorkItem2Single{
+			Data: ConvertWorkItem(ctx.RequestData, wi),
+			Links: &app.WorkItemLinks{
+				Self: AbsoluteURL(ctx.RequestData, app.WorkitemHref(wi.ID)),
+			},
+		})
+	})
+}
+
+// loadRollups returns the rollups of the children of the given work items
+func loadRollups(ctx context.Context, appl application.Application, wiIDs []uint64) (map[uint64]link.Rollup, error) {
+	return appl.WorkItemLinks().Rollups(ctx, wiIDs, configuration.GetRollupPointsField())
+}
+
+// WorkItemIncludeRollups adds the rollup of the children to the attributes of
+// the work items that have children
+func WorkItemIncludeRollups(rollups map[uint64]link.Rollup) WorkItemConvertFunc {
+	return func(request *goa.RequestData, wi *app.WorkItem, wi2 *app.WorkItem2) {
+		id, err := workitem.ParseWorkItemIDToUint64(wi.ID)
+		if err != nil {
+			return
+		}
+		rollup, ok := rollups[id]
+		if !ok || rollup.Total == 0 {
+			return
+		}
+		wi2.Attributes[childrenAttribute] = map[string]interface{}{
+			"total":  rollup.Total,
+			"states": rollup.States,
+			"points": rollup.Points,
+		}
+	}
+}
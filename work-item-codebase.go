@@ -0,0 +1,92 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/codechange"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+)
+
+// WorkItemCodebaseController implements the work-item-codebase resource.
+type WorkItemCodebaseController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemCodebaseController creates a work-item-codebase controller.
+func NewWorkItemCodebaseController(service *goa.Service, db application.DB) *WorkItemCodebaseController {
+	return &WorkItemCodebaseController{Controller: service.NewController("WorkItemCodebaseController"), db: db}
+}
+
+// List runs the list action.
+func (c *WorkItemCodebaseController) List(ctx *app.ListWorkItemCodebaseContext) error {
+	wiID, err := workitem.ParseWorkItemIDToUint64(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err := appl.WorkItems().Load(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		changes, err := appl.CodeChanges().List(ctx, wiID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		res := &app.CodeChangeList{
+			Data: ConvertCodeChanges(ctx.RequestData, changes),
+			Meta: &app.WorkItemListResponseMeta{TotalCount: len(changes)},
+		}
+		return ctx.OK(res)
+	})
+}
+
+// ConvertCodeChanges converts between internal and external REST representation
+func ConvertCodeChanges(request *goa.RequestData, changes []*codechange.CodeChange) []*app.CodeChange {
+	var cs = []*app.CodeChange{}
+	for _, c := range changes {
+		cs = append(cs, ConvertCodeChange(request, c))
+	}
+	return cs
+}
+
+// ConvertCodeChange converts between internal and external REST representation
+func ConvertCodeChange(request *goa.RequestData, c *codechange.CodeChange) *app.CodeChange {
+	workItemType := APIStringTypeWorkItem
+	workItemID := strconv.FormatUint(c.WorkItemID, 10)
+	workItemSelfURL := AbsoluteURL(request, app.WorkitemHref(workItemID))
+
+	return &app.CodeChange{
+		Type: "codechanges",
+		ID:   &c.ID,
+		Attributes: &app.CodeChangeAttributes{
+			Kind:       &c.Kind,
+			Provider:   &c.Provider,
+			Repository: &c.Repository,
+			Ref:        &c.Ref,
+			Title:      &c.Title,
+			URL:        &c.URL,
+			Status:     &c.Status,
+			CreatedAt:  &c.CreatedAt,
+			UpdatedAt:  &c.UpdatedAt,
+		},
+		Relationships: &app.CodeChangeRelations{
+			Workitem: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: &workItemType,
+					ID:   &workItemID,
+				},
+				Links: &app.GenericLinks{
+					Self: &workItemSelfURL,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/codebase"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// WorkItemCodebasesController implements the work-item-codebases resource.
+type WorkItemCodebasesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemCodebasesController creates a work-item-codebases controller.
+func NewWorkItemCodebasesController(service *goa.Service, db application.DB) *WorkItemCodebasesController {
+	return &WorkItemCodebasesController{Controller: service.NewController("WorkItemCodebasesController"), db: db}
+}
+
+// Create runs the create action.
+func (c *WorkItemCodebasesController) Create(ctx *app.CreateWorkItemCodebasesContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	wiID, err := workitem.ParseWorkItemIDToUint64(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	codebaseID, err := uuid.FromString(ctx.CodebaseID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err := appl.WorkItems().Load(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		cb, err := appl.Codebases().Load(ctx, codebaseID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		err = appl.Codebases().AddWorkItem(ctx, cb.ID, wiID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		b, err := appl.Codebases().LatestBuild(ctx, cb.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		res := &app.CodebaseSingle{
+			Data: ConvertCodebase(ctx.RequestData, cb, b),
+		}
+		return ctx.OK(res)
+	})
+}
+
+// List runs the list action.
+func (c *WorkItemCodebasesController) List(ctx *app.ListWorkItemCodebasesContext) error {
+	wiID, err := workitem.ParseWorkItemIDToUint64(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err := appl.WorkItems().Load(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		codebases, err := appl.Codebases().ListByWorkItem(ctx, wiID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		data, err := ConvertCodebases(ctx.RequestData, codebases, func(id uuid.UUID) (*codebase.Build, error) {
+			return appl.Codebases().LatestBuild(ctx, id)
+		})
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		res := &app.CodebaseList{
+			Data: data,
+			Meta: &app.WorkItemListResponseMeta{TotalCount: len(data)},
+		}
+		return ctx.OK(res)
+	})
+}
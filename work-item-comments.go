@@ -6,6 +6,8 @@ import (
 	"github.com/almighty/almighty-core/comment"
 	"github.com/almighty/almighty-core/jsonapi"
 	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/moderation"
+	"github.com/almighty/almighty-core/workitem"
 	"github.com/goadesign/goa"
 	uuid "github.com/satori/go.uuid"
 	"golang.org/x/net/context"
@@ -24,8 +26,8 @@ func NewWorkItemCommentsController(service *goa.Service, db application.DB) *Wor
 
 // Create runs the create action.
 func (c *WorkItemCommentsController) Create(ctx *app.CreateWorkItemCommentsContext) error {
-	return application.Transactional(c.db, func(appl application.Application) error {
-		_, err := appl.WorkItems().Load(ctx, ctx.ID)
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		wi, err := appl.WorkItems().Load(ctx, ctx.ID)
 		if err != nil {
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
 			return ctx.NotFound(jerrors)
@@ -44,11 +46,16 @@ func (c *WorkItemCommentsController) Create(ctx *app.CreateWorkItemCommentsConte
 		}
 
 		reqComment := ctx.Payload.Data
+		pending, err := checkContribution(ctx, appl, moderation.KindComment, wi.Fields[workitem.SystemProject], currentUser, reqComment.Attributes.Body)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
 
 		newComment := comment.Comment{
-			ParentID:  ctx.ID,
-			Body:      reqComment.Attributes.Body,
-			CreatedBy: currentUserID,
+			ParentID:      ctx.ID,
+			Body:          reqComment.Attributes.Body,
+			PendingReview: pending,
+			CreatedBy:     currentUserID,
 		}
 
 		err = appl.Comments().Create(ctx, &newComment)
@@ -56,6 +63,11 @@ func (c *WorkItemCommentsController) Create(ctx *app.CreateWorkItemCommentsConte
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
 			return ctx.InternalServerError(jerrors)
 		}
+		if !pending {
+			if _, err := automate(ctx, appl, ctx.RequestData, wi, wi, newComment.Body); err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+		}
 
 		res := &app.CommentSingle{
 			Data: ConvertComment(ctx.RequestData, &newComment),
@@ -66,8 +78,8 @@ func (c *WorkItemCommentsController) Create(ctx *app.CreateWorkItemCommentsConte
 
 // List runs the list action.
 func (c *WorkItemCommentsController) List(ctx *app.ListWorkItemCommentsContext) error {
-	return application.Transactional(c.db, func(appl application.Application) error {
-		_, err := appl.WorkItems().Load(ctx, ctx.ID)
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		wi, err := appl.WorkItems().Load(ctx, ctx.ID)
 		if err != nil {
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
 			return ctx.NotFound(jerrors)
@@ -81,7 +93,13 @@ func (c *WorkItemCommentsController) List(ctx *app.ListWorkItemCommentsContext)
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
 			return ctx.InternalServerError(jerrors)
 		}
-		res.Data = ConvertComments(ctx.RequestData, comments)
+		comments = visibleComments(ctx, wi, comments)
+		reactions, err := loadReactions(ctx, appl, comments)
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.InternalServerError(jerrors)
+		}
+		res.Data = ConvertComments(ctx.RequestData, comments, reactions)
 
 		return ctx.OK(res)
 	})
@@ -90,7 +108,7 @@ func (c *WorkItemCommentsController) List(ctx *app.ListWorkItemCommentsContext)
 // Relations runs the relation action.
 // TODO: Should only return Resource Identifier Objects, not complete object (See List)
 func (c *WorkItemCommentsController) Relations(ctx *app.RelationsWorkItemCommentsContext) error {
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 		wi, err := appl.WorkItems().Load(ctx, ctx.ID)
 		if err != nil {
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
@@ -102,6 +120,7 @@ func (c *WorkItemCommentsController) Relations(ctx *app.RelationsWorkItemComment
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
 			return ctx.InternalServerError(jerrors)
 		}
+		comments = visibleComments(ctx, wi, comments)
 
 		res := &app.CommentArray{}
 		res.Data = []*app.Comment{}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/job"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/goadesign/goa"
+)
+
+// draftJobKind is the kind of the jobs deleting the drafts abandoned for
+// longer than the configured age
+const draftJobKind = "workitem.delete-abandoned-drafts"
+
+// WorkItemDraftController implements the work-item-draft resource.
+type WorkItemDraftController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemDraftController creates a work-item-draft controller.
+func NewWorkItemDraftController(service *goa.Service, db application.DB) *WorkItemDraftController {
+	return &WorkItemDraftController{Controller: service.NewController("WorkItemDraftController"), db: db}
+}
+
+// Publish runs the publish action.
+func (c *WorkItemDraftController) Publish(ctx *app.PublishWorkItemDraftContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		wi, err := appl.WorkItemDrafts().Publish(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		// a published draft is announced like a new work item
+		wi, err = automate(ctx, appl, ctx.RequestData, nil, wi, "")
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if err := notifyChat(ctx, appl, ctx.RequestData, nil, wi); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.WorkItem2Single{
+			Data: ConvertWorkItem(ctx.RequestData, wi),
+			Links: &app.WorkItemLinks{
+				Self: AbsoluteURL(ctx.RequestData, app.WorkitemHref(wi.ID)),
+			},
+		})
+	})
+}
+
+// deleteAbandonedDraftsJob returns the handler of the scheduled jobs
+// deleting the drafts that weren't changed for longer than the configured
+// age
+func deleteAbandonedDraftsJob(db application.DB) job.Handler {
+	return func(ctx context.Context, payload []byte) error {
+		maxAge := configuration.GetDraftMaxAge()
+		if maxAge <= 0 {
+			return nil
+		}
+		return application.Transactional(db, func(appl application.Application) error {
+			n, err := appl.WorkItemDrafts().DeleteAbandoned(ctx, time.Now().Add(-maxAge))
+			if err != nil {
+				return err
+			}
+			log.Printf("Deleted %d abandoned drafts\n", n)
+			return nil
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/job"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+)
+
+// eventPartitionsJobKind is the kind of the jobs maintaining the monthly
+// partitions of the work item events
+const eventPartitionsJobKind = "workitem.event-partitions"
+
+// WorkItemEventsController implements the work-item-events resource.
+type WorkItemEventsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemEventsController creates a work-item-events controller.
+func NewWorkItemEventsController(service *goa.Service, db application.DB) *WorkItemEventsController {
+	return &WorkItemEventsController{Controller: service.NewController("WorkItemEventsController"), db: db}
+}
+
+// Feed runs the feed action.
+func (c *WorkItemEventsController) Feed(ctx *app.FeedWorkItemEventsContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can read the event feed"))
+	}
+	var after uint64
+	if ctx.After != nil {
+		if *ctx.After < 0 {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("after", *ctx.After).Expected("not negative"))
+		}
+		after = uint64(*ctx.After)
+	}
+	_, limit := computePagingLimts(nil, ctx.PageLimit)
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		events, err := appl.WorkItemEvents().Feed(ctx, after, limit)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.WorkItemEventList{Data: []*app.WorkItemEvent{}}
+		for _, e := range events {
+			res.Data = append(res.Data, ConvertWorkItemEvent(e))
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Rebuild runs the rebuild action.
+func (c *WorkItemEventsController) Rebuild(ctx *app.RebuildWorkItemEventsContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can rebuild work items"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		n, err := appl.WorkItemEvents().Rebuild(ctx)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.WorkItemRebuild{Rebuilt: int(n)})
+	})
+}
+
+// ConvertWorkItemEvent converts between internal and external REST representation
+func ConvertWorkItemEvent(e *workitem.Event) *app.WorkItemEvent {
+	return &app.WorkItemEvent{
+		Type: "workitemevents",
+		ID:   e.ID,
+		Attributes: &app.WorkItemEventAttributes{
+			Sequence:  int(e.Sequence),
+			Kind:      e.Kind,
+			Workitem:  strconv.FormatUint(e.WorkItemID, 10),
+			Version:   e.Version,
+			CreatedAt: e.CreatedAt,
+			Modifier:  e.ModifierID,
+		},
+	}
+}
+
+// maintainEventPartitionsJob returns the handler of the scheduled jobs
+// creating the partitions of the coming months and, if a retention is
+// configured, pruning the events older than it
+func maintainEventPartitionsJob(db application.DB) job.Handler {
+	return func(ctx context.Context, payload []byte) error {
+		return application.Transactional(db, func(appl application.Application) error {
+			now := time.Now()
+			if err := appl.EventPartitions().Ensure(ctx, now, workitem.EventPartitionsAhead); err != nil {
+				return err
+			}
+			retention := configuration.GetHistoryRetention()
+			if retention <= 0 {
+				return nil
+			}
+			n, err := appl.EventPartitions().Prune(ctx, now.Add(-retention))
+			if err != nil {
+				return err
+			}
+			log.Printf("Pruned %d work item events\n", n)
+			return nil
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/linkgraph"
+	query "github.com/almighty/almighty-core/query/simple"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	satoriuuid "github.com/satori/go.uuid"
+)
+
+// WorkItemGraphController implements the work-item-graph resource.
+type WorkItemGraphController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemGraphController creates a work-item-graph controller.
+func NewWorkItemGraphController(service *goa.Service, db application.DB) *WorkItemGraphController {
+	return &WorkItemGraphController{Controller: service.NewController("WorkItemGraphController"), db: db}
+}
+
+// Dot runs the dot action.
+func (c *WorkItemGraphController) Dot(ctx *app.DotWorkItemGraphContext) error {
+	exp, err := graphCriteria(ctx.Filter, ctx.FilterProject)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		g, err := loadGraph(ctx, appl, exp, ctx.FilterLinkType)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(linkgraph.RenderDOT(g))
+	})
+}
+
+// Graphml runs the graphml action.
+func (c *WorkItemGraphController) Graphml(ctx *app.GraphmlWorkItemGraphContext) error {
+	exp, err := graphCriteria(ctx.Filter, ctx.FilterProject)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		g, err := loadGraph(ctx, appl, exp, ctx.FilterLinkType)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		out, err := linkgraph.RenderGraphML(g)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewInternalError(err.Error()))
+		}
+		return ctx.OK(out)
+	})
+}
+
+// graphCriteria returns the criteria of the work items of a graph
+// returns BadParameterError
+func graphCriteria(filter *string, project *satoriuuid.UUID) (criteria.Expression, error) {
+	exp, err := query.Parse(filter)
+	if err != nil {
+		return nil, errors.NewBadParameterError("filter", *filter).Expected(err.Error())
+	}
+	if project != nil {
+		exp = criteria.And(exp, criteria.Equals(criteria.Field(workitem.SystemProject), criteria.Literal(project.String())))
+	}
+	return exp, nil
+}
+
+// loadGraph returns the graph of the work items matching the criteria the
+// viewer of ctx can see and the links between them, of the link type if it
+// is given
+// returns BadParameterError if there are more than linkgraph.MaxNodes work
+// items or InternalError
+func loadGraph(ctx context.Context, appl application.Application, exp criteria.Expression, linkTypeID *satoriuuid.UUID) (*linkgraph.Graph, error) {
+	g := &linkgraph.Graph{Nodes: []linkgraph.Node{}, Edges: []linkgraph.Edge{}}
+	var ids []uint64
+	err := appl.WorkItems().Stream(ctx, exp, nil, func(wi *app.WorkItem) error {
+		if len(g.Nodes) == linkgraph.MaxNodes {
+			return errors.NewBadParameterError("filter", nil).Expected(fmt.Sprintf("at most %d matching work items", linkgraph.MaxNodes))
+		}
+		id, err := strconv.ParseUint(wi.ID, 10, 64)
+		if err != nil {
+			return errors.NewInternalError(err.Error())
+		}
+		ids = append(ids, id)
+		g.Nodes = append(g.Nodes, linkgraph.NewNode(wi))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	links, err := appl.WorkItemLinks().Between(ctx, ids, linkTypeID)
+	if err != nil {
+		return nil, err
+	}
+	relations := map[satoriuuid.UUID]string{}
+	for _, l := range links {
+		relation, ok := relations[l.LinkTypeID]
+		if !ok {
+			linkType, err := appl.WorkItemLinkTypes().Load(ctx, l.LinkTypeID.String())
+			if err != nil {
+				return nil, err
+			}
+			if linkType.Data.Attributes.ForwardName != nil {
+				relation = *linkType.Data.Attributes.ForwardName
+			}
+			relations[l.LinkTypeID] = relation
+		}
+		g.Edges = append(g.Edges, linkgraph.Edge{
+			Source:   strconv.FormatUint(l.SourceID, 10),
+			Target:   strconv.FormatUint(l.TargetID, 10),
+			Relation: relation,
+		})
+	}
+	return g, nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/workitem/link"
+	"github.com/goadesign/goa"
+)
+
+// defaultLinkSuggestions is the number of suggestions if no limit is given
+const defaultLinkSuggestions = 10
+
+// WorkItemLinkSuggestionsController implements the work-item-link-suggestions resource.
+type WorkItemLinkSuggestionsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemLinkSuggestionsController creates a work-item-link-suggestions controller.
+func NewWorkItemLinkSuggestionsController(service *goa.Service, db application.DB) *WorkItemLinkSuggestionsController {
+	return &WorkItemLinkSuggestionsController{Controller: service.NewController("WorkItemLinkSuggestionsController"), db: db}
+}
+
+// List runs the list action.
+func (c *WorkItemLinkSuggestionsController) List(ctx *app.ListWorkItemLinkSuggestionsContext) error {
+	limit := defaultLinkSuggestions
+	if ctx.PageLimit != nil {
+		limit = *ctx.PageLimit
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		suggestions, err := appl.WorkItemLinks().Suggest(ctx, ctx.ID, limit)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(ConvertLinkSuggestions(suggestions))
+	})
+}
+
+// ConvertLinkSuggestions converts between internal and external REST representation
+func ConvertLinkSuggestions(suggestions []link.Suggestion) *app.LinkSuggestions {
+	res := &app.LinkSuggestions{Data: make([]*app.LinkSuggestion, 0, len(suggestions))}
+	for _, s := range suggestions {
+		res.Data = append(res.Data, &app.LinkSuggestion{
+			WorkItemID: strconv.FormatUint(s.WorkItemID, 10),
+			Title:      s.Title,
+			Type:       s.Type,
+			Kind:       s.Kind,
+			LinkTypeID: s.LinkTypeID,
+			Score:      s.Score,
+			Reasons:    s.Reasons,
+		})
+	}
+	return res
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"strconv"
+
 	"golang.org/x/net/context"
 
 	"github.com/almighty/almighty-core/app"
@@ -283,7 +285,7 @@ func deleteWorkItemLink(ctx *workItemLinkContext, funcs deleteWorkItemLinkFuncs,
 
 // Delete runs the delete action
 func (c *WorkItemLinkController) Delete(ctx *app.DeleteWorkItemLinkContext) error {
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 		return deleteWorkItemLink(newWorkItemLinkContext(ctx.Context, appl, c.db, ctx.RequestData, ctx.ResponseData, app.WorkItemLinkHref), ctx, ctx.LinkID)
 	})
 }
@@ -292,11 +294,13 @@ type listWorkItemLinkFuncs interface {
 	OK(r *app.WorkItemLinkList) error
 }
 
-func listWorkItemLink(ctx *workItemLinkContext, funcs listWorkItemLinkFuncs, wiIDStr *string) error {
+// listWorkItemLink lists all links, or the links of the work item matching the
+// filter if wiIDStr is given
+func listWorkItemLink(ctx *workItemLinkContext, funcs listWorkItemLinkFuncs, wiIDStr *string, f link.ListFilter) error {
 	var linkArr *app.WorkItemLinkList
 	var err error
 	if wiIDStr != nil {
-		linkArr, err = ctx.Application.WorkItemLinks().ListByWorkItemID(ctx.Context, *wiIDStr)
+		linkArr, err = ctx.Application.WorkItemLinks().ListByWorkItemID(ctx.Context, *wiIDStr, f)
 	} else {
 		linkArr, err = ctx.Application.WorkItemLinks().List(ctx.Context)
 	}
@@ -314,8 +318,8 @@ func listWorkItemLink(ctx *workItemLinkContext, funcs listWorkItemLinkFuncs, wiI
 
 // List runs the list action.
 func (c *WorkItemLinkController) List(ctx *app.ListWorkItemLinkContext) error {
-	return application.Transactional(c.db, func(appl application.Application) error {
-		return listWorkItemLink(newWorkItemLinkContext(ctx.Context, appl, c.db, ctx.RequestData, ctx.ResponseData, app.WorkItemLinkHref), ctx, nil)
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		return listWorkItemLink(newWorkItemLinkContext(ctx.Context, appl, c.db, ctx.RequestData, ctx.ResponseData, app.WorkItemLinkHref), ctx, nil, link.ListFilter{})
 	})
 }
 
@@ -338,7 +342,7 @@ func showWorkItemLink(ctx *workItemLinkContext, funcs showWorkItemLinkFuncs, lin
 
 // Show runs the show action.
 func (c *WorkItemLinkController) Show(ctx *app.ShowWorkItemLinkContext) error {
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 		return showWorkItemLink(newWorkItemLinkContext(ctx.Context, appl, c.db, ctx.RequestData, ctx.ResponseData, app.WorkItemLinkHref), ctx, ctx.LinkID)
 	})
 }
@@ -365,7 +369,30 @@ func updateWorkItemLink(ctx *workItemLinkContext, funcs updateWorkItemLinkFuncs,
 
 // Update runs the update action.
 func (c *WorkItemLinkController) Update(ctx *app.UpdateWorkItemLinkContext) error {
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 		return updateWorkItemLink(newWorkItemLinkContext(ctx.Context, appl, c.db, ctx.RequestData, ctx.ResponseData, app.WorkItemLinkHref), ctx, ctx.Payload)
 	})
 }
+
+// BulkDelete runs the bulk-delete action.
+func (c *WorkItemLinkController) BulkDelete(ctx *app.BulkDeleteWorkItemLinkContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can delete links in bulk"))
+	}
+	f := link.Filter{LinkTypeID: ctx.FilterLinkType, CreatedBefore: ctx.FilterCreatedBefore}
+	if ctx.FilterSource != nil {
+		sourceID, err := strconv.ParseUint(*ctx.FilterSource, 10, 64)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("filter[source]", *ctx.FilterSource).Expected("a work item ID"))
+		}
+		f.SourceID = &sourceID
+	}
+	dryRun := ctx.DryRun != nil && *ctx.DryRun
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		count, err := appl.WorkItemLinks().DeleteMatching(ctx, f, dryRun)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.LinkDeletion{Count: count, DryRun: dryRun})
+	})
+}
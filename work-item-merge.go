@@ -0,0 +1,40 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/goadesign/goa"
+)
+
+// WorkItemMergeController implements the work-item-merge resource.
+type WorkItemMergeController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemMergeController creates a work-item-merge controller.
+func NewWorkItemMergeController(service *goa.Service, db application.DB) *WorkItemMergeController {
+	return &WorkItemMergeController{Controller: service.NewController("WorkItemMergeController"), db: db}
+}
+
+// Merge runs the merge action.
+func (c *WorkItemMergeController) Merge(ctx *app.MergeWorkItemMergeContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		wi, err := appl.WorkItemMerge().Merge(ctx, ctx.ID, ctx.Into)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.WorkItem2Single{
+			Data: ConvertWorkItem(ctx.RequestData, wi),
+			Links: &app.WorkItemLinks{
+				Self: AbsoluteURL(ctx.RequestData, app.WorkitemHref(wi.ID)),
+			},
+		})
+	})
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/printout"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/almighty/almighty-core/workitem/link"
+	"github.com/goadesign/goa"
+)
+
+// WorkItemPrintController implements the work-item-print resource.
+type WorkItemPrintController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemPrintController creates a work-item-print controller.
+func NewWorkItemPrintController(service *goa.Service, db application.DB) *WorkItemPrintController {
+	return &WorkItemPrintController{Controller: service.NewController("WorkItemPrintController"), db: db}
+}
+
+// Show runs the show action.
+func (c *WorkItemPrintController) Show(ctx *app.ShowWorkItemPrintContext) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		doc, err := loadPrintout(ctx, appl, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		page, err := printout.RenderHTML(doc)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewInternalError(err.Error()))
+		}
+		return ctx.OK(page)
+	})
+}
+
+// Download runs the download action.
+func (c *WorkItemPrintController) Download(ctx *app.DownloadWorkItemPrintContext) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		doc, err := loadPrintout(ctx, appl, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		ctx.ResponseData.Header().Set("Content-Disposition", `attachment; filename="workitem-`+doc.ID+`.pdf"`)
+		return ctx.OK(printout.RenderPDF(doc))
+	})
+}
+
+// loadPrintout returns the printable document of the work item with the
+// comments and links the viewer of ctx can see
+func loadPrintout(ctx context.Context, appl application.Application, id string) (*printout.Document, error) {
+	wi, err := appl.WorkItems().Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	doc := printout.NewDocument(wi, time.Now())
+
+	comments, err := appl.Comments().List(ctx, wi.ID)
+	if err != nil {
+		return nil, err
+	}
+	authors := map[string]string{}
+	for _, cm := range visibleComments(ctx, wi, comments) {
+		author, ok := authors[cm.CreatedBy.String()]
+		if !ok {
+			author = cm.CreatedBy.String()
+			if identity, err := appl.Identities().Load(ctx, cm.CreatedBy); err == nil && identity.FullName != "" {
+				author = identity.FullName
+			}
+			authors[cm.CreatedBy.String()] = author
+		}
+		doc.Comments = append(doc.Comments, printout.Comment{Author: author, CreatedAt: cm.CreatedAt, Body: cm.Body})
+	}
+
+	links, err := appl.WorkItemLinks().ListByWorkItemID(ctx, wi.ID, link.ListFilter{})
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range links.Data {
+		linkType, err := appl.WorkItemLinkTypes().Load(ctx, l.Relationships.LinkType.Data.ID)
+		if err != nil {
+			return nil, err
+		}
+		relation, other := linkType.Data.Attributes.ForwardName, l.Relationships.Target.Data.ID
+		if other == wi.ID {
+			relation, other = linkType.Data.Attributes.ReverseName, l.Relationships.Source.Data.ID
+		}
+		link := printout.Link{ID: other}
+		if relation != nil {
+			link.Relation = *relation
+		}
+		// the other end may be confidential, it is listed without title
+		if target, err := appl.WorkItems().Load(ctx, other); err == nil {
+			link.Title, _ = target.Fields[workitem.SystemTitle].(string)
+		}
+		doc.Links = append(doc.Links, link)
+	}
+	return doc, nil
+}
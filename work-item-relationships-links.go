@@ -46,7 +46,7 @@ func (c *WorkItemRelationshipsLinksController) getLinkFunc(id interface{}) hrefL
 
 // Create runs the create action.
 func (c *WorkItemRelationshipsLinksController) Create(ctx *app.CreateWorkItemRelationshipsLinksContext) error {
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 		// Check that current work item does indeed exist
 		if _, err := appl.WorkItems().Load(ctx.Context, ctx.ID); err != nil {
 			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
@@ -79,7 +79,7 @@ func (c *WorkItemRelationshipsLinksController) Create(ctx *app.CreateWorkItemRel
 }
 
 func (c *WorkItemRelationshipsLinksController) Delete(ctx *app.DeleteWorkItemRelationshipsLinksContext) error {
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 		// Check work item link exists
 		wil, err := appl.WorkItemLinks().Load(ctx.Context, ctx.LinkID)
 		if err != nil {
@@ -98,14 +98,18 @@ func (c *WorkItemRelationshipsLinksController) Delete(ctx *app.DeleteWorkItemRel
 
 // List runs the list action.
 func (c *WorkItemRelationshipsLinksController) List(ctx *app.ListWorkItemRelationshipsLinksContext) error {
-	return application.Transactional(c.db, func(appl application.Application) error {
-		return listWorkItemLink(newWorkItemLinkContext(ctx.Context, appl, c.db, ctx.RequestData, ctx.ResponseData, c.getLinkFunc(ctx.ID)), ctx, &ctx.ID)
+	f := link.ListFilter{LinkTypeID: ctx.FilterLinkType, TargetType: ctx.FilterTargetType}
+	if ctx.FilterDirection != nil {
+		f.Direction = *ctx.FilterDirection
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		return listWorkItemLink(newWorkItemLinkContext(ctx.Context, appl, c.db, ctx.RequestData, ctx.ResponseData, c.getLinkFunc(ctx.ID)), ctx, &ctx.ID, f)
 	})
 }
 
 // Show runs the show action.
 func (c *WorkItemRelationshipsLinksController) Show(ctx *app.ShowWorkItemRelationshipsLinksContext) error {
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 		// Check work item link exists
 		wil, err := appl.WorkItemLinks().Load(ctx.Context, ctx.LinkID)
 		if err != nil {
@@ -124,7 +128,7 @@ func (c *WorkItemRelationshipsLinksController) Show(ctx *app.ShowWorkItemRelatio
 
 // Update runs the update action.
 func (c *WorkItemRelationshipsLinksController) Update(ctx *app.UpdateWorkItemRelationshipsLinksContext) error {
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 		// Check work item link exists
 		wil, err := appl.WorkItemLinks().Load(ctx.Context, ctx.LinkID)
 		if err != nil {
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/remotelink"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+)
+
+// WorkItemRemoteLinksController implements the work-item-remote-links resource.
+type WorkItemRemoteLinksController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemRemoteLinksController creates a work-item-remote-links controller.
+func NewWorkItemRemoteLinksController(service *goa.Service, db application.DB) *WorkItemRemoteLinksController {
+	return &WorkItemRemoteLinksController{Controller: service.NewController("WorkItemRemoteLinksController"), db: db}
+}
+
+// Create runs the create action.
+func (c *WorkItemRemoteLinksController) Create(ctx *app.CreateWorkItemRemoteLinksContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	wiID, err := workitem.ParseWorkItemIDToUint64(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	attrs := ctx.Payload.Data.Attributes
+	if attrs.URL == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.url", nil).Expected("not nil"))
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err := appl.WorkItems().Load(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		newLink := remotelink.RemoteLink{
+			WorkItemID: wiID,
+			URL:        *attrs.URL,
+		}
+		if attrs.Title != nil {
+			newLink.Title = *attrs.Title
+		}
+		if attrs.Icon != nil {
+			newLink.IconURL = *attrs.Icon
+		}
+		if attrs.Relationship != nil {
+			newLink.Relationship = *attrs.Relationship
+		}
+
+		err = appl.RemoteLinks().Create(ctx, &newLink)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		res := &app.RemoteLinkSingle{
+			Data: ConvertRemoteLink(ctx.RequestData, &newLink),
+		}
+		ctx.ResponseData.Header().Set("Location", AbsoluteURL(ctx.RequestData, app.RemoteLinkHref(res.Data.ID)))
+		return ctx.Created(res)
+	})
+}
+
+// List runs the list action.
+func (c *WorkItemRemoteLinksController) List(ctx *app.ListWorkItemRemoteLinksContext) error {
+	wiID, err := workitem.ParseWorkItemIDToUint64(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err := appl.WorkItems().Load(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		links, err := appl.RemoteLinks().List(ctx, wiID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		res := &app.RemoteLinkList{
+			Data: ConvertRemoteLinks(ctx.RequestData, links),
+			Meta: &app.WorkItemListResponseMeta{TotalCount: len(links)},
+		}
+		return ctx.OK(res)
+	})
+}
+
+// WorkItemIncludeRemoteLinks adds relationship about remote links to workitem
+func WorkItemIncludeRemoteLinks(request *goa.RequestData, wi *app.WorkItem, wi2 *app.WorkItem2) {
+	wi2.Relationships.RemoteLinks = CreateRemoteLinksRelation(request, wi)
+}
+
+// CreateRemoteLinksRelation returns a RelationGeneric object representing the relation for a workitem to remote link relation
+func CreateRemoteLinksRelation(request *goa.RequestData, wi *app.WorkItem) *app.RelationGeneric {
+	related := AbsoluteURL(request, app.WorkitemHref(wi.ID)) + "/remotelinks"
+	return &app.RelationGeneric{
+		Links: &app.GenericLinks{
+			Related: &related,
+		},
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/workitem/link"
+	"github.com/goadesign/goa"
+)
+
+// WorkItemReparentController implements the work-item-reparent resource.
+type WorkItemReparentController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemReparentController creates a work-item-reparent controller.
+func NewWorkItemReparentController(service *goa.Service, db application.DB) *WorkItemReparentController {
+	return &WorkItemReparentController{Controller: service.NewController("WorkItemReparentController"), db: db}
+}
+
+// Reparent runs the reparent action.
+func (c *WorkItemReparentController) Reparent(ctx *app.ReparentWorkItemReparentContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	to := link.Reparenting{
+		Parent:     ctx.Payload.Parent,
+		LinkTypeID: ctx.Payload.LinkType,
+		Iteration:  ctx.Payload.Iteration,
+	}
+	var res *link.Reparenting
+	// the work items already moved must not be committed if one can't move
+	err = application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		var err error
+		res, err = appl.WorkItemLinks().Reparent(ctx, ctx.Payload.Workitems, to)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(ConvertReparenting(res))
+}
+
+// ConvertReparenting converts from internal to external REST representation
+func ConvertReparenting(r *link.Reparenting) *app.Reparenting {
+	return &app.Reparenting{
+		Workitems:     r.WorkItems,
+		Parent:        r.Parent,
+		Iteration:     r.Iteration,
+		FormerParents: r.FormerParents,
+	}
+}
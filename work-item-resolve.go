@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+)
+
+// WorkItemResolveController implements the work-item-resolve resource.
+type WorkItemResolveController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemResolveController creates a work-item-resolve controller.
+func NewWorkItemResolveController(service *goa.Service, db application.DB) *WorkItemResolveController {
+	return &WorkItemResolveController{Controller: service.NewController("WorkItemResolveController"), db: db}
+}
+
+// Resolve runs the resolve action.
+func (c *WorkItemResolveController) Resolve(ctx *app.ResolveWorkItemResolveContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	if ctx.Payload == nil || ctx.Payload.Data == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data", nil))
+	}
+	version, err := strconv.Atoi(fmt.Sprintf("%v", ctx.Payload.Data.Attributes["version"]))
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.version", ctx.Payload.Data.Attributes["version"]))
+	}
+	var wi *app.WorkItem
+	var conflicts *app.WorkItemConflicts
+	// automations run after saving must not leave the merged changes behind
+	// if they fail
+	err = application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		current, err := appl.WorkItems().Load(ctx, ctx.ID)
+		if err != nil {
+			return err
+		}
+		merged, conflicting, err := mergeOfflineEdit(ctx, appl, current, version, *ctx.Payload.Data)
+		if err != nil {
+			return err
+		}
+		if len(conflicting) > 0 {
+			conflicts = &app.WorkItemConflicts{Data: make([]*app.WorkItemConflict, len(conflicting)), Version: current.Version}
+			for i, c := range conflicting {
+				base, ours, theirs := c.Base, c.Ours, c.Theirs
+				conflicts.Data[i] = &app.WorkItemConflict{Field: c.Field, Base: &base, Ours: &ours, Theirs: &theirs}
+			}
+			return nil
+		}
+		// drafts are published with the publish action and published work
+		// items stay published
+		if draft := current.Fields[workitem.SystemDraft] == true; draft != (merged.Fields[workitem.SystemDraft] == true) {
+			return errors.NewBadParameterError("data.attributes."+workitem.SystemDraft, merged.Fields[workitem.SystemDraft]).Expected(draft)
+		}
+		if err := checkTransition(ctx, appl, ctx.RequestData, current, merged); err != nil {
+			return err
+		}
+		wi, err = appl.WorkItems().Save(ctx, *merged)
+		if err != nil {
+			return err
+		}
+		wi, err = automate(ctx, appl, ctx.RequestData, current, wi, "")
+		if err != nil {
+			return err
+		}
+		return notifyChat(ctx, appl, ctx.RequestData, current, wi)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	if conflicts != nil {
+		return ctx.Conflict(conflicts)
+	}
+	return ctx.OK(&app.WorkItem2Single{
+		Data: ConvertWorkItem(ctx.RequestData, wi),
+		Links: &app.WorkItemLinks{
+			Self: AbsoluteURL(ctx.RequestData, app.WorkitemHref(wi.ID)),
+		},
+	})
+}
+
+// mergeOfflineEdit returns the current work item with the changes of the
+// update based on the given version merged in, or the fields changed
+// differently on both sides. Unlike mergeConcurrentEdit it merges every
+// field, a sync client can't ask the user to redo the changes.
+func mergeOfflineEdit(ctx context.Context, appl application.Application, current *app.WorkItem, version int, source app.WorkItem2) (*app.WorkItem, []*workitem.FieldConflict, error) {
+	base := current
+	if version != current.Version {
+		var err error
+		base, err = appl.WorkItemEvents().LoadVersion(ctx, current.ID, version)
+		if err != nil {
+			if _, ok := err.(errors.NotFoundError); ok {
+				return nil, nil, errors.NewBadParameterError("data.attributes.version", version).Expected("a version of the work item")
+			}
+			return nil, nil, err
+		}
+	}
+	// the conversion changes the fields in place
+	ours := &app.WorkItem{ID: base.ID, Type: base.Type, Fields: make(map[string]interface{}, len(base.Fields))}
+	for k, v := range base.Fields {
+		ours.Fields[k] = v
+	}
+	if err := ConvertJSONAPIToWorkItem(appl, source, ours); err != nil {
+		return nil, nil, err
+	}
+	delete(ours.Fields, "version")
+
+	fields, conflicts := workitem.MergeFields(base.Fields, ours.Fields, current.Fields)
+	merged := &app.WorkItem{ID: current.ID, Type: current.Type, Version: current.Version, Fields: fields}
+	if ours.Type != base.Type && ours.Type != current.Type {
+		if current.Type != base.Type {
+			conflicts = append(conflicts, &workitem.FieldConflict{Field: "type", Base: base.Type, Ours: ours.Type, Theirs: current.Type})
+		}
+		merged.Type = ours.Type
+	}
+	return merged, conflicts, nil
+}
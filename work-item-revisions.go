@@ -0,0 +1,86 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+)
+
+// WorkItemRevisionsController implements the work-item-revisions resource.
+type WorkItemRevisionsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemRevisionsController creates a work-item-revisions controller.
+func NewWorkItemRevisionsController(service *goa.Service, db application.DB) *WorkItemRevisionsController {
+	return &WorkItemRevisionsController{Controller: service.NewController("WorkItemRevisionsController"), db: db}
+}
+
+// List runs the list action.
+func (c *WorkItemRevisionsController) List(ctx *app.ListWorkItemRevisionsContext) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		revisions, err := appl.WorkItemEvents().List(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.WorkItemRevisionList{Data: []*app.WorkItemRevision{}}
+		for _, r := range revisions {
+			res.Data = append(res.Data, ConvertWorkItemRevision(r))
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Snapshot runs the snapshot action.
+func (c *WorkItemRevisionsController) Snapshot(ctx *app.SnapshotWorkItemRevisionsContext) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		wi, err := appl.WorkItemEvents().LoadAt(ctx, ctx.ID, ctx.At)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.WorkItem2Single{
+			Data: ConvertWorkItem(ctx.RequestData, wi),
+			Links: &app.WorkItemLinks{
+				Self: buildAbsoluteURL(ctx.RequestData),
+			},
+		})
+	})
+}
+
+// Restore runs the restore action.
+func (c *WorkItemRevisionsController) Restore(ctx *app.RestoreWorkItemRevisionsContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		wi, err := appl.WorkItemEvents().Restore(ctx, ctx.ID, ctx.Version)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.WorkItem2Single{
+			Data: ConvertWorkItem(ctx.RequestData, wi),
+			Links: &app.WorkItemLinks{
+				Self: AbsoluteURL(ctx.RequestData, app.WorkitemHref(wi.ID)),
+			},
+		})
+	})
+}
+
+// ConvertWorkItemRevision converts between internal and external REST representation
+func ConvertWorkItemRevision(r *workitem.Event) *app.WorkItemRevision {
+	return &app.WorkItemRevision{
+		Type: "workitemrevisions",
+		ID:   r.ID,
+		Attributes: &app.WorkItemRevisionAttributes{
+			Kind:      r.Kind,
+			Version:   r.Version,
+			CreatedAt: r.CreatedAt,
+			Modifier:  r.ModifierID,
+		},
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"time"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/share"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+)
+
+// WorkItemSharesController implements the work-item-shares resource.
+type WorkItemSharesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemSharesController creates a work-item-shares controller.
+func NewWorkItemSharesController(service *goa.Service, db application.DB) *WorkItemSharesController {
+	return &WorkItemSharesController{Controller: service.NewController("WorkItemSharesController"), db: db}
+}
+
+// List runs the list action.
+func (c *WorkItemSharesController) List(ctx *app.ListWorkItemSharesContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing identity"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		wi, err := appl.WorkItems().Load(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		wiID, err := workitem.ParseWorkItemIDToUint64(wi.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		links, err := appl.ShareLinks().List(ctx, wiID, *identityID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.ShareLinkList{Data: make([]*app.ShareLink, len(links))}
+		for i := range links {
+			res.Data[i] = ConvertShareLink(ctx.RequestData, &links[i])
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Create runs the create action.
+func (c *WorkItemSharesController) Create(ctx *app.CreateWorkItemSharesContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing identity"))
+	}
+	if configuration.GetShareSecret() == "" {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewServiceUnavailableError("share links are disabled"))
+	}
+	if max := configuration.GetShareMaxDays(); ctx.Days > max {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("days", ctx.Days).Expected(max))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		wi, err := appl.WorkItems().Load(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if confidential, _ := wi.Fields[workitem.SystemConfidential].(bool); confidential {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("id", ctx.ID).Expected("a work item that isn't confidential"))
+		}
+		wiID, err := workitem.ParseWorkItemIDToUint64(wi.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		link, err := appl.ShareLinks().Create(ctx, wiID, *identityID, time.Now().AddDate(0, 0, ctx.Days))
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.ShareLinkSingle{Data: ConvertShareLink(ctx.RequestData, link)})
+	})
+}
+
+// Revoke runs the revoke action.
+func (c *WorkItemSharesController) Revoke(ctx *app.RevokeWorkItemSharesContext) error {
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("missing identity"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		link, err := appl.ShareLinks().Load(ctx, ctx.ShareID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		wiID, err := workitem.ParseWorkItemIDToUint64(ctx.ID)
+		if err != nil || link.WorkItemID != wiID {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewNotFoundError("share link", ctx.ShareID.String()))
+		}
+		if err := appl.ShareLinks().Revoke(ctx, ctx.ShareID, *identityID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.NoContent()
+	})
+}
+
+// ConvertShareLink converts a share link to its signed URL
+func ConvertShareLink(request *goa.RequestData, link *share.Link) *app.ShareLink {
+	return &app.ShareLink{
+		ID:        link.ID,
+		URL:       AbsoluteURL(request, app.SharedHref(link.Token(configuration.GetShareSecret()))),
+		ExpiresAt: link.ExpiresAt,
+		CreatedAt: link.CreatedAt,
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"strconv"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// WorkItemVotesController implements the work-item-votes resource.
+type WorkItemVotesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemVotesController creates a work-item-votes controller.
+func NewWorkItemVotesController(service *goa.Service, db application.DB) *WorkItemVotesController {
+	return &WorkItemVotesController{Controller: service.NewController("WorkItemVotesController"), db: db}
+}
+
+// Show runs the show action.
+func (c *WorkItemVotesController) Show(ctx *app.ShowWorkItemVotesContext) error {
+	wiID, err := workitem.ParseWorkItemIDToUint64(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err := appl.WorkItems().Load(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res, err := loadVotesSingle(ctx, appl, ctx.RequestData, wiID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Create runs the create action.
+func (c *WorkItemVotesController) Create(ctx *app.CreateWorkItemVotesContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	identityID, err := uuid.FromString(currentUser)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	wiID, err := workitem.ParseWorkItemIDToUint64(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		_, err := appl.WorkItems().Load(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		err = appl.Votes().Create(ctx, wiID, identityID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res, err := loadVotesSingle(ctx, appl, ctx.RequestData, wiID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Delete runs the delete action.
+func (c *WorkItemVotesController) Delete(ctx *app.DeleteWorkItemVotesContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	identityID, err := uuid.FromString(currentUser)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	wiID, err := workitem.ParseWorkItemIDToUint64(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		err := appl.Votes().Delete(ctx, wiID, identityID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res, err := loadVotesSingle(ctx, appl, ctx.RequestData, wiID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// loadVotesSingle loads the votes of a single work item
+func loadVotesSingle(ctx context.Context, appl application.Application, request *goa.RequestData, wiID uint64) (*app.VotesSingle, error) {
+	counts, voted, err := loadVotes(ctx, appl, []uint64{wiID})
+	if err != nil {
+		return nil, err
+	}
+	id := strconv.FormatUint(wiID, 10)
+	count := counts[wiID]
+	hasVoted := voted[wiID]
+	selfURL := AbsoluteURL(request, app.WorkItemVotesHref(id))
+	return &app.VotesSingle{
+		Data: &app.Votes{
+			Type: "votes",
+			ID:   &id,
+			Attributes: &app.VotesAttributes{
+				Count: &count,
+				Voted: &hasVoted,
+			},
+			Links: &app.GenericLinks{
+				Self: &selfURL,
+			},
+		},
+	}, nil
+}
+
+// loadVotes returns the vote counts of the given work items and, if there
+// is a current user, which of them the user voted for
+func loadVotes(ctx context.Context, appl application.Application, wiIDs []uint64) (map[uint64]int, map[uint64]bool, error) {
+	counts, err := appl.Votes().Count(ctx, wiIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	voted := map[uint64]bool{}
+	identityID := currentIdentityID(ctx)
+	if identityID == nil {
+		// anonymous users did not vote
+		return counts, voted, nil
+	}
+	voted, err = appl.Votes().Voted(ctx, wiIDs, *identityID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return counts, voted, nil
+}
+
+// WorkItemIncludeVotes adds the vote count and whether the current user voted
+// to the votes relationship of the work items
+func WorkItemIncludeVotes(counts map[uint64]int, voted map[uint64]bool) WorkItemConvertFunc {
+	return func(request *goa.RequestData, wi *app.WorkItem, wi2 *app.WorkItem2) {
+		id, err := workitem.ParseWorkItemIDToUint64(wi.ID)
+		if err != nil {
+			return
+		}
+		wi2.Relationships.Votes = CreateVotesRelation(request, wi)
+		wi2.Relationships.Votes.Meta = map[string]interface{}{
+			"totalCount": counts[id],
+			"voted":      voted[id],
+		}
+	}
+}
+
+// WorkItemIncludeVotesLink adds the votes relationship without counts to the work item
+func WorkItemIncludeVotesLink(request *goa.RequestData, wi *app.WorkItem, wi2 *app.WorkItem2) {
+	wi2.Relationships.Votes = CreateVotesRelation(request, wi)
+}
+
+// CreateVotesRelation returns a RelationGeneric object representing the relation for a workitem to votes relation
+func CreateVotesRelation(request *goa.RequestData, wi *app.WorkItem) *app.RelationGeneric {
+	related := AbsoluteURL(request, app.WorkitemHref(wi.ID)) + "/votes"
+	return &app.RelationGeneric{
+		Links: &app.GenericLinks{
+			Related: &related,
+		},
+	}
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
+	"reflect"
 	"strconv"
+	"time"
 
 	"golang.org/x/net/context"
 
@@ -11,9 +15,12 @@ import (
 	"github.com/almighty/almighty-core/application"
 	"github.com/almighty/almighty-core/criteria"
 	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/fieldvalues"
 	"github.com/almighty/almighty-core/jsonapi"
 	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/moderation"
 	query "github.com/almighty/almighty-core/query/simple"
+	"github.com/almighty/almighty-core/textmerge"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/goadesign/goa"
 	uuid "github.com/satori/go.uuid"
@@ -55,10 +62,61 @@ func (c *WorkitemController) List(ctx *app.ListWorkitemContext) error {
 		exp = criteria.And(exp, criteria.Equals(criteria.Field("system.assignees"), criteria.Literal([]string{*assignee})))
 		additionalQuery = append(additionalQuery, "filter[assignee]="+*assignee)
 	}
+	if ctx.FilterDeployedTo != nil {
+		additionalQuery = append(additionalQuery, "filter[deployed-to]="+*ctx.FilterDeployedTo)
+	}
+	if ctx.FilterProject != nil {
+		exp = criteria.And(exp, criteria.Equals(criteria.Field(workitem.SystemProject), criteria.Literal(ctx.FilterProject.String())))
+		additionalQuery = append(additionalQuery, "filter[project]="+ctx.FilterProject.String())
+	}
+	if ctx.FilterArchived != nil && *ctx.FilterArchived {
+		exp = criteria.And(exp, criteria.Equals(criteria.Field(workitem.ArchivedField), criteria.Literal(true)))
+		additionalQuery = append(additionalQuery, "filter[archived]=true")
+	}
+	if ctx.FilterDrafts != nil && *ctx.FilterDrafts {
+		exp = criteria.And(exp, criteria.Equals(criteria.Field(workitem.SystemDraft), criteria.Literal(true)))
+		additionalQuery = append(additionalQuery, "filter[drafts]=true")
+	}
+	if ctx.FilterUpdated != nil {
+		additionalQuery = append(additionalQuery, "filter[updated]="+*ctx.FilterUpdated)
+	}
+	var sort []workitem.SortKey
+	if ctx.Sort != nil {
+		sort, err = workitem.ParseSort(*ctx.Sort)
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(fmt.Sprintf("could not parse sort: %s", err.Error())))
+			return ctx.BadRequest(jerrors)
+		}
+		additionalQuery = append(additionalQuery, "sort="+*ctx.Sort)
+	}
 	offset, limit := computePagingLimts(ctx.PageOffset, ctx.PageLimit)
 
-	return application.Transactional(c.db, func(tx application.Application) error {
-		result, tc, err := tx.WorkItems().List(ctx.Context, exp, &offset, &limit)
+	return application.Transactional(requestDB(ctx, c.db), func(tx application.Application) error {
+		if ctx.FilterDeployedTo != nil {
+			ids, err := tx.Deployments().DeployedWorkItemIDs(ctx, *ctx.FilterDeployedTo)
+			if err != nil {
+				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(fmt.Sprintf("Error listing work items: %s", err.Error())))
+				return ctx.InternalServerError(jerrors)
+			}
+			exp = criteria.And(exp, deployedWorkItemsCriteria(ids))
+		}
+		if ctx.FilterUpdated != nil {
+			updated, err := workitem.UpdatedWithin(*ctx.FilterUpdated, time.Now().In(viewerLocation(ctx, tx)))
+			if err != nil {
+				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(err.Error()))
+				return ctx.BadRequest(jerrors)
+			}
+			exp = criteria.And(exp, updated)
+		}
+		for i, key := range sort {
+			values, err := orderedFieldValues(ctx, tx, ctx.FilterProject, key.Field)
+			if err != nil {
+				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(fmt.Sprintf("Error listing work items: %s", err.Error())))
+				return ctx.InternalServerError(jerrors)
+			}
+			sort[i].Values = values
+		}
+		result, tc, err := tx.WorkItems().List(ctx.Context, exp, &offset, &limit, sort...)
 		count := int(tc)
 		if err != nil {
 			switch err := err.(type) {
@@ -72,10 +130,28 @@ func (c *WorkitemController) List(ctx *app.ListWorkitemContext) error {
 			}
 		}
 
+		ids := make([]uint64, 0, len(result))
+		for _, wi := range result {
+			id, err := workitem.ParseWorkItemIDToUint64(wi.ID)
+			if err == nil {
+				ids = append(ids, id)
+			}
+		}
+		counts, voted, err := loadVotes(ctx, tx, ids)
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(fmt.Sprintf("Error listing work items: %s", err.Error())))
+			return ctx.InternalServerError(jerrors)
+		}
+		rollups, err := loadRollups(ctx, tx, ids)
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(fmt.Sprintf("Error listing work items: %s", err.Error())))
+			return ctx.InternalServerError(jerrors)
+		}
+
 		response := app.WorkItem2List{
 			Links: &app.PagingLinks{},
 			Meta:  &app.WorkItemListResponseMeta{TotalCount: count},
-			Data:  ConvertWorkItems(ctx.RequestData, result),
+			Data:  ConvertWorkItems(ctx.RequestData, result, WorkItemIncludeVotes(counts, voted), WorkItemIncludeRollups(rollups)),
 		}
 
 		setPagingLinks(response.Links, buildAbsoluteURL(ctx.RequestData), len(result), offset, limit, count, additionalQuery...)
@@ -85,9 +161,163 @@ func (c *WorkitemController) List(ctx *app.ListWorkitemContext) error {
 
 }
 
+// Changes runs the changes action.
+func (c *WorkitemController) Changes(ctx *app.ChangesWorkitemContext) error {
+	exp, err := query.Parse(ctx.Filter)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("filter", *ctx.Filter).Expected(err.Error()))
+	}
+	if ctx.FilterProject != nil {
+		exp = criteria.And(exp, criteria.Equals(criteria.Field(workitem.SystemProject), criteria.Literal(ctx.FilterProject.String())))
+	}
+	if ctx.Since != nil && ctx.Token != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("since", ctx.Since.String()).Expected("either since or token"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(tx application.Application) error {
+		var set *workitem.ChangeSet
+		if ctx.Token != nil {
+			set, err = tx.WorkItemChanges().ChangesAfter(ctx, exp, *ctx.Token)
+		} else {
+			set, err = tx.WorkItemChanges().Changes(ctx, exp, ctx.Since)
+		}
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.WorkItemChanges{
+			Data:       make([]*app.WorkItemChange, len(set.Changes)),
+			Checkpoint: set.Checkpoint,
+			Token:      set.Token,
+		}
+		for i, change := range set.Changes {
+			res.Data[i] = &app.WorkItemChange{
+				ID:        change.ID,
+				Version:   change.Version,
+				UpdatedAt: change.UpdatedAt,
+				Created:   change.Created,
+				Archived:  change.Archived,
+				Deleted:   change.Deleted,
+			}
+		}
+		if ctx.Include != nil {
+			res.Included = []*app.WorkItem2{}
+			for _, change := range set.Changes {
+				if change.Deleted {
+					continue
+				}
+				wi, err := tx.WorkItems().Load(ctx, change.ID)
+				if err != nil {
+					return jsonapi.JSONErrorResponse(ctx, err)
+				}
+				res.Included = append(res.Included, ConvertWorkItem(ctx.RequestData, wi))
+			}
+		}
+		return ctx.OK(res)
+	})
+}
+
+// exportFlushInterval is the number of exported work items after which the
+// response is flushed to the client
+const exportFlushInterval = 100
+
+// Export runs the export action.
+func (c *WorkitemController) Export(ctx *app.ExportWorkitemContext) error {
+	exp, err := query.Parse(ctx.Filter)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("filter", *ctx.Filter).Expected(err.Error()))
+	}
+	if ctx.FilterAssignee != nil {
+		exp = criteria.And(exp, criteria.Equals(criteria.Field("system.assignees"), criteria.Literal([]string{*ctx.FilterAssignee})))
+	}
+	if ctx.FilterProject != nil {
+		exp = criteria.And(exp, criteria.Equals(criteria.Field(workitem.SystemProject), criteria.Literal(ctx.FilterProject.String())))
+	}
+	if ctx.FilterArchived != nil && *ctx.FilterArchived {
+		exp = criteria.And(exp, criteria.Equals(criteria.Field(workitem.ArchivedField), criteria.Literal(true)))
+	}
+	var sort []workitem.SortKey
+	if ctx.Sort != nil {
+		sort, err = workitem.ParseSort(*ctx.Sort)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("sort", *ctx.Sort).Expected(err.Error()))
+		}
+	}
+
+	return application.Transactional(requestDB(ctx, c.db), func(tx application.Application) error {
+		for i, key := range sort {
+			values, err := orderedFieldValues(ctx, tx, ctx.FilterProject, key.Field)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			sort[i].Values = values
+		}
+
+		// the response starts with the first work item, errors before are
+		// responded as usual
+		started := false
+		start := func() {
+			if !started {
+				// the export takes as long as it takes, the client reads
+				// the work items while they are written
+				connections.ClearWriteDeadline(ctx.Request)
+				ctx.ResponseData.Header().Set("Content-Type", "application/x-ndjson")
+				ctx.ResponseData.WriteHeader(http.StatusOK)
+				started = true
+			}
+		}
+		flusher, _ := ctx.ResponseData.ResponseWriter.(http.Flusher)
+		encoder := json.NewEncoder(ctx.ResponseData)
+		count := 0
+		err := tx.WorkItems().Stream(ctx, exp, sort, func(wi *app.WorkItem) error {
+			start()
+			if err := encoder.Encode(ConvertWorkItem(ctx.RequestData, wi)); err != nil {
+				return err
+			}
+			count++
+			if flusher != nil && count%exportFlushInterval == 0 {
+				flusher.Flush()
+			}
+			return nil
+		})
+		if err != nil {
+			if !started {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			log.Printf("Error exporting work items after %d work items: %s\n", count, err.Error())
+			return err
+		}
+		start()
+		return nil
+	})
+}
+
+// deployedWorkItemsCriteria matches only the work items with the given IDs
+func deployedWorkItemsCriteria(ids []uint64) criteria.Expression {
+	if len(ids) == 0 {
+		return criteria.Literal(false)
+	}
+	return criteria.In(criteria.Field("ID"), criteria.Literal(ids))
+}
+
+// orderedFieldValues returns the values to sort an ordered field like the
+// priority by, customized by the given project. Other fields are not sorted
+// by value and no values are returned for them.
+func orderedFieldValues(ctx context.Context, appl application.Application, projectID *uuid.UUID, field string) ([]string, error) {
+	if !fieldvalues.IsOrdered(field) {
+		return nil, nil
+	}
+	if projectID == nil {
+		return fieldvalues.Defaults[field], nil
+	}
+	fv, err := appl.FieldValues().Load(ctx, *projectID, field)
+	if err != nil {
+		return nil, err
+	}
+	return fv.Values, nil
+}
+
 // Update does PATCH workitem
 func (c *WorkitemController) Update(ctx *app.UpdateWorkitemContext) error {
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 
 		if ctx.Payload == nil || ctx.Payload.Data == nil || ctx.Payload.Data.ID == nil {
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewBadParameterError("data.id", nil))
@@ -99,11 +329,31 @@ func (c *WorkitemController) Update(ctx *app.UpdateWorkitemContext) error {
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrNotFound(fmt.Sprintf("Error updating work item: %s", err.Error())))
 			return ctx.NotFound(jerrors)
 		}
-		err = ConvertJSONAPIToWorkItem(appl, *ctx.Payload.Data, wi)
+		data, err := mergeConcurrentEdit(ctx, appl, wi, *ctx.Payload.Data)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		// the conversion changes the fields in place
+		before := app.WorkItem{ID: wi.ID, Type: wi.Type, Fields: make(map[string]interface{}, len(wi.Fields))}
+		for k, v := range wi.Fields {
+			before.Fields[k] = v
+		}
+		err = ConvertJSONAPIToWorkItem(appl, data, wi)
 		if err != nil {
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(fmt.Sprintf("Error updating work item: %s", err.Error())))
 			return ctx.BadRequest(jerrors)
 		}
+		// drafts are published with the publish action and published work
+		// items stay published
+		if draft := before.Fields[workitem.SystemDraft] == true; draft != (wi.Fields[workitem.SystemDraft] == true) {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes."+workitem.SystemDraft, wi.Fields[workitem.SystemDraft]).Expected(draft))
+		}
+		if err := checkTransition(ctx, appl, ctx.RequestData, &before, wi); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if err := checkRequiredFields(ctx, appl, wi); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
 		wi, err = appl.WorkItems().Save(ctx, *wi)
 		if err != nil {
 			switch err := err.(type) {
@@ -122,6 +372,13 @@ func (c *WorkitemController) Update(ctx *app.UpdateWorkitemContext) error {
 				return ctx.InternalServerError(jerrors)
 			}
 		}
+		wi, err = automate(ctx, appl, ctx.RequestData, &before, wi, "")
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if err := notifyChat(ctx, appl, ctx.RequestData, &before, wi); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
 
 		wi2 := ConvertWorkItem(ctx.RequestData, wi)
 		resp := &app.WorkItem2Single{
@@ -135,6 +392,74 @@ func (c *WorkitemController) Update(ctx *app.UpdateWorkitemContext) error {
 	})
 }
 
+// mergeConcurrentEdit returns the update of the work item with the
+// description merged with the changes made since the version the update is
+// based on. The update is returned as it is if it is based on the current
+// version, doesn't change the description, changes other fields that were
+// changed since or the descriptions can't be merged, saving it then reports
+// the version conflict.
+func mergeConcurrentEdit(ctx context.Context, appl application.Application, current *app.WorkItem, source app.WorkItem2) (app.WorkItem2, error) {
+	version, err := strconv.Atoi(fmt.Sprintf("%v", source.Attributes["version"]))
+	if err != nil || version == current.Version {
+		return source, nil
+	}
+	ours, ok := source.Attributes[workitem.SystemDescription].(string)
+	if !ok {
+		return source, nil
+	}
+	base, err := appl.WorkItemEvents().LoadVersion(ctx, current.ID, version)
+	if err != nil {
+		if _, ok := err.(errors.NotFoundError); ok {
+			return source, nil
+		}
+		return source, err
+	}
+
+	var changed []string
+	for name := range source.Attributes {
+		if name != "version" && name != workitem.SystemDescription {
+			changed = append(changed, name)
+		}
+	}
+	if rel := source.Relationships; rel != nil {
+		if rel.BaseType != nil && base.Type != current.Type {
+			return source, nil
+		}
+		if rel.Assignees != nil {
+			changed = append(changed, workitem.SystemAssignees)
+		}
+		if rel.Iteration != nil {
+			changed = append(changed, workitem.SystemIteration)
+		}
+		if rel.Release != nil {
+			changed = append(changed, workitem.SystemRelease)
+		}
+		if rel.Project != nil {
+			changed = append(changed, workitem.SystemProject)
+		}
+	}
+	for _, name := range changed {
+		if !reflect.DeepEqual(base.Fields[name], current.Fields[name]) {
+			return source, nil
+		}
+	}
+
+	baseDescription, _ := base.Fields[workitem.SystemDescription].(string)
+	theirs, _ := current.Fields[workitem.SystemDescription].(string)
+	merged, ok := textmerge.Merge(baseDescription, ours, theirs)
+	if !ok {
+		return source, nil
+	}
+	attributes := make(map[string]interface{}, len(source.Attributes))
+	for name, value := range source.Attributes {
+		attributes[name] = value
+	}
+	attributes["version"] = current.Version
+	attributes[workitem.SystemDescription] = merged
+	source.Attributes = attributes
+	return source, nil
+}
+
 // Create does POST workitem
 func (c *WorkitemController) Create(ctx *app.CreateWorkitemContext) error {
 	currentUser, err := login.ContextIdentity(ctx)
@@ -149,18 +474,43 @@ func (c *WorkitemController) Create(ctx *app.CreateWorkitemContext) error {
 			wit = &ctx.Payload.Data.Relationships.BaseType.Data.ID
 		}
 	}
-	if wit == nil { // TODO Figure out path source etc. Should be a required relation
-		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewBadParameterError("data.relationships.basetype.data.id", nil))
-		return ctx.BadRequest(jerrors)
-
-	}
 
 	wi := app.WorkItem{
 		Fields: make(map[string]interface{}),
 	}
 
-	return application.Transactional(c.db, func(appl application.Application) error {
-		ConvertJSONAPIToWorkItem(appl, *ctx.Payload.Data, &wi)
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		err := ConvertJSONAPIToWorkItem(appl, *ctx.Payload.Data, &wi)
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(fmt.Sprintf("Error creating work item: %s", err.Error())))
+			return ctx.BadRequest(jerrors)
+		}
+		if wit == nil {
+			// the project may create work items of its default type
+			wit, err = defaultWorkItemType(ctx, appl, wi.Fields)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+		}
+		if wit == nil { // TODO Figure out path source etc. Should be a required relation
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewBadParameterError("data.relationships.basetype.data.id", nil))
+			return ctx.BadRequest(jerrors)
+		}
+		wi.Type = *wit
+		if err := checkRequiredFields(ctx, appl, &wi); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		pending, err := checkContribution(ctx, appl, moderation.KindWorkItem, wi.Fields[workitem.SystemProject], currentUser,
+			contributionText(wi.Fields[workitem.SystemTitle]), contributionText(wi.Fields[workitem.SystemDescription]))
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if pending {
+			wi.Fields[workitem.SystemPendingReview] = true
+		}
+		if err := assignNewWorkItem(ctx, appl, *wit, wi.Fields); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
 
 		wi, err := appl.WorkItems().Create(ctx, *wit, wi.Fields, currentUser)
 		if err != nil {
@@ -177,6 +527,13 @@ func (c *WorkitemController) Create(ctx *app.CreateWorkitemContext) error {
 				return ctx.InternalServerError(jerrors)
 			}
 		}
+		wi, err = automate(ctx, appl, ctx.RequestData, nil, wi, "")
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if err := notifyChat(ctx, appl, ctx.RequestData, nil, wi); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
 
 		wi2 := ConvertWorkItem(ctx.RequestData, wi)
 		resp := &app.WorkItem2Single{
@@ -192,7 +549,8 @@ func (c *WorkitemController) Create(ctx *app.CreateWorkitemContext) error {
 
 // Show does GET workitem
 func (c *WorkitemController) Show(ctx *app.ShowWorkitemContext) error {
-	return application.Transactional(c.db, func(appl application.Application) error {
+	var viewed uint64
+	err := application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 
 		comments := WorkItemIncludeCommentsAndTotal(ctx, c.db, ctx.ID)
 
@@ -215,17 +573,44 @@ func (c *WorkitemController) Show(ctx *app.ShowWorkitemContext) error {
 			}
 		}
 
-		wi2 := ConvertWorkItem(ctx.RequestData, wi, comments)
+		wiID, err := workitem.ParseWorkItemIDToUint64(wi.ID)
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrNotFound(err.Error()))
+			return ctx.NotFound(jerrors)
+		}
+		counts, voted, err := loadVotes(ctx, appl, []uint64{wiID})
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(err.Error()))
+			return ctx.InternalServerError(jerrors)
+		}
+		rollups, err := loadRollups(ctx, appl, []uint64{wiID})
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(err.Error()))
+			return ctx.InternalServerError(jerrors)
+		}
+
+		wi2 := ConvertWorkItem(ctx.RequestData, wi, comments, WorkItemIncludeVotes(counts, voted), WorkItemIncludeRollups(rollups))
 		resp := &app.WorkItem2Single{
 			Data: wi2,
 		}
+		viewed = wiID
 		return ctx.OK(resp)
 	})
+	if viewer := workitem.ContextViewer(ctx); err == nil && viewed != 0 && viewer != nil && viewer.IdentityID != nil {
+		// the view is recorded on its own so a failure doesn't fail showing the work item
+		err := application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+			return appl.Favorites().RecordView(ctx, *viewer.IdentityID, viewed)
+		})
+		if err != nil {
+			log.Printf("Error recording the view of work item %d: %s", viewed, err.Error())
+		}
+	}
+	return err
 }
 
 // Delete does DELETE workitem
 func (c *WorkitemController) Delete(ctx *app.DeleteWorkitemContext) error {
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 
 		err := appl.WorkItems().Delete(ctx, ctx.ID)
 		if err != nil {
@@ -263,6 +648,7 @@ func ConvertJSONAPIToWorkItem(appl application.Application, source app.WorkItem2
 		version = v
 	}
 	target.Version = version
+	formerRelease, _ := target.Fields[workitem.SystemRelease].(string)
 
 	if source.Relationships != nil && source.Relationships.Assignees != nil {
 		if source.Relationships.Assignees.Data == nil {
@@ -300,6 +686,34 @@ func ConvertJSONAPIToWorkItem(appl application.Application, source app.WorkItem2
 			target.Fields[workitem.SystemIteration] = iterationUUID.String()
 		}
 	}
+	if source.Relationships != nil && source.Relationships.Release != nil {
+		if source.Relationships.Release.Data == nil {
+			delete(target.Fields, workitem.SystemRelease)
+		} else {
+			d := source.Relationships.Release.Data
+			releaseUUID, err := uuid.FromString(*d.ID)
+			if err != nil {
+				return errors.NewBadParameterError("data.relationships.release.data.id", *d.ID)
+			}
+			target.Fields[workitem.SystemRelease] = releaseUUID.String()
+		}
+	}
+	if source.Relationships != nil && source.Relationships.Project != nil {
+		if source.Relationships.Project.Data == nil {
+			delete(target.Fields, workitem.SystemProject)
+		} else {
+			d := source.Relationships.Project.Data
+			projectUUID, err := uuid.FromString(*d.ID)
+			if err != nil {
+				return errors.NewBadParameterError("data.relationships.project.data.id", *d.ID)
+			}
+			_, err = appl.Projects().Load(context.Background(), projectUUID)
+			if err != nil {
+				return errors.NewBadParameterError("data.relationships.project.data.id", *d.ID)
+			}
+			target.Fields[workitem.SystemProject] = projectUUID.String()
+		}
+	}
 	if source.Relationships != nil && source.Relationships.BaseType != nil {
 		if source.Relationships.BaseType.Data != nil {
 			target.Type = source.Relationships.BaseType.Data.ID
@@ -308,6 +722,69 @@ func ConvertJSONAPIToWorkItem(appl application.Application, source app.WorkItem2
 	for key, val := range source.Attributes {
 		target.Fields[key] = val
 	}
+	if err := checkReleaseChange(appl, formerRelease, target); err != nil {
+		return err
+	}
+	for _, field := range []string{workitem.SystemPriority, workitem.SystemSeverity} {
+		val, ok := target.Fields[field]
+		if !ok {
+			continue
+		}
+		var projectID *uuid.UUID
+		if p, ok := target.Fields[workitem.SystemProject].(string); ok {
+			id, err := uuid.FromString(p)
+			if err == nil {
+				projectID = &id
+			}
+		}
+		values, err := orderedFieldValues(context.Background(), appl, projectID, field)
+		if err != nil {
+			return err
+		}
+		fv := fieldvalues.FieldValues{FieldName: field, Values: values}
+		if err := fv.Validate(val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkReleaseChange validates the release of the converted work item if it
+// changed: the content of a published release is frozen, so work items can
+// neither be added to nor removed from it, and the release must belong to the
+// project of the work item.
+func checkReleaseChange(appl application.Application, formerRelease string, wi *app.WorkItem) error {
+	release, _ := wi.Fields[workitem.SystemRelease].(string)
+	if release == formerRelease {
+		return nil
+	}
+	if formerRelease != "" {
+		id, err := uuid.FromString(formerRelease)
+		if err == nil {
+			r, err := appl.Releases().Load(context.Background(), id)
+			if err == nil && r.IsPublished() {
+				return errors.NewBadParameterError("data.relationships.release.data.id", release).Expected("no change of the published release " + formerRelease)
+			}
+		}
+	}
+	if release == "" {
+		return nil
+	}
+	id, err := uuid.FromString(release)
+	if err != nil {
+		return errors.NewBadParameterError("data.relationships.release.data.id", release)
+	}
+	r, err := appl.Releases().Load(context.Background(), id)
+	if err != nil {
+		return errors.NewBadParameterError("data.relationships.release.data.id", release)
+	}
+	if r.IsPublished() {
+		return errors.NewBadParameterError("data.relationships.release.data.id", release).Expected("unpublished release")
+	}
+	project, _ := wi.Fields[workitem.SystemProject].(string)
+	if project != r.ProjectID.String() {
+		return errors.NewBadParameterError("data.relationships.release.data.id", release).Expected("release of the project " + project)
+	}
 	return nil
 }
 
@@ -374,6 +851,20 @@ func ConvertWorkItem(request *goa.RequestData, wi *app.WorkItem, additional ...W
 					Data: ConvertIterationSimple(request, valStr),
 				}
 			}
+		case workitem.SystemRelease:
+			if val != nil {
+				valStr := val.(string)
+				op.Relationships.Release = &app.RelationGeneric{
+					Data: ConvertReleaseSimple(request, valStr),
+				}
+			}
+		case workitem.SystemProject:
+			if val != nil {
+				valStr := val.(string)
+				op.Relationships.Project = &app.RelationGeneric{
+					Data: ConvertProjectSimple(request, valStr),
+				}
+			}
 		default:
 			op.Attributes[name] = val
 		}
@@ -384,8 +875,16 @@ func ConvertWorkItem(request *goa.RequestData, wi *app.WorkItem, additional ...W
 	if op.Relationships.Iteration == nil {
 		op.Relationships.Iteration = &app.RelationGeneric{Data: nil}
 	}
+	if op.Relationships.Release == nil {
+		op.Relationships.Release = &app.RelationGeneric{Data: nil}
+	}
+	if op.Relationships.Project == nil {
+		op.Relationships.Project = &app.RelationGeneric{Data: nil}
+	}
 	// Always include Comments Link, but optionally use WorkItemIncludeCommentsAndTotal
 	WorkItemIncludeComments(request, wi, op)
+	WorkItemIncludeRemoteLinks(request, wi, op)
+	WorkItemIncludeVotesLink(request, wi, op)
 	for _, add := range additional {
 		add(request, wi, op)
 	}
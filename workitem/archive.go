@@ -0,0 +1,70 @@
+package workitem
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+)
+
+// ArchiveRepository encapsulates archiving work items
+type ArchiveRepository interface {
+	Archive(ctx context.Context, ID string, archived bool) (*app.WorkItem, error)
+	ArchiveClosed(ctx context.Context, iterationID string) (int64, error)
+}
+
+// NewArchiveRepository creates a new storage type.
+func NewArchiveRepository(db *gorm.DB) ArchiveRepository {
+	return &GormArchiveRepository{db: db, wir: NewWorkItemRepository(db)}
+}
+
+// GormArchiveRepository is the implementation of the storage interface for
+// archiving work items.
+type GormArchiveRepository struct {
+	db  *gorm.DB
+	wir *GormWorkItemRepository
+}
+
+// Archive archives or unarchives the work item with the given ID. Archiving
+// doesn't change the fields and the version of the work item.
+// returns NotFoundError, ConversionError or InternalError
+func (m *GormArchiveRepository) Archive(ctx context.Context, ID string, archived bool) (*app.WorkItem, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitemarchive", "archive"}, time.Now())
+
+	wi, err := m.wir.LoadFromDB(ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ContextViewer(ctx).CanSee(wi.Fields) {
+		return nil, errors.NewNotFoundError("work item", ID)
+	}
+	if err := m.db.Model(&WorkItem{}).Where("id = ?", wi.ID).UpdateColumn("archived", archived).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	wi.Archived = archived
+	wiType, err := m.wir.wir.LoadTypeFromDB(wi.Type)
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return convertWorkItemModelToApp(ctx, wiType, wi)
+}
+
+// ArchiveClosed archives the closed work items of the iteration and returns
+// how many there were
+// returns InternalError
+func (m *GormArchiveRepository) ArchiveClosed(ctx context.Context, iterationID string) (int64, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitemarchive", "archiveclosed"}, time.Now())
+
+	tx := m.db.Model(&WorkItem{}).
+		Where(fmt.Sprintf("NOT archived AND fields->>'%s' = ? AND fields->>'%s' = ?", SystemIteration, SystemState), iterationID, SystemStateClosed).
+		UpdateColumn("archived", true)
+	if tx.Error != nil {
+		return 0, errors.NewInternalError(tx.Error.Error())
+	}
+	return tx.RowsAffected, nil
+}
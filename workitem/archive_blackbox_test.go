@@ -0,0 +1,96 @@
+package workitem_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/context"
+)
+
+type archiveRepoBlackBoxTest struct {
+	gormsupport.DBTestSuite
+	clean func()
+}
+
+func TestRunArchiveRepoBlackBoxTest(t *testing.T) {
+	suite.Run(t, &archiveRepoBlackBoxTest{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *archiveRepoBlackBoxTest) SetupTest() {
+	s.clean = gormsupport.DeleteCreatedEntities(s.DB)
+}
+
+func (s *archiveRepoBlackBoxTest) TearDownTest() {
+	s.clean()
+}
+
+func (s *archiveRepoBlackBoxTest) create(iterationID string, state string) string {
+	wi, err := workitem.NewWorkItemRepository(s.DB).Create(context.Background(), workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle:     "archive",
+		workitem.SystemState:     state,
+		workitem.SystemIteration: iterationID,
+	}, "xx")
+	require.Nil(s.T(), err)
+	return wi.ID
+}
+
+func (s *archiveRepoBlackBoxTest) list(archived bool, iterationID string) []string {
+	exp := criteria.Equals(criteria.Field(workitem.SystemIteration), criteria.Literal(iterationID))
+	if archived {
+		exp = criteria.And(exp, criteria.Equals(criteria.Field(workitem.ArchivedField), criteria.Literal(true)))
+	}
+	items, _, err := workitem.NewWorkItemRepository(s.DB).List(context.Background(), exp, nil, nil)
+	require.Nil(s.T(), err)
+	ids := []string{}
+	for _, wi := range items {
+		ids = append(ids, wi.ID)
+	}
+	return ids
+}
+
+func (s *archiveRepoBlackBoxTest) TestArchive() {
+	t := s.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	iterationID := uuid.NewV4().String()
+	id := s.create(iterationID, workitem.SystemStateOpen)
+	repo := workitem.NewArchiveRepository(s.DB)
+
+	wi, err := repo.Archive(ctx, id, true)
+	require.Nil(t, err)
+	assert.Equal(t, true, wi.Fields[workitem.SystemArchived])
+	assert.Empty(t, s.list(false, iterationID))
+	assert.Equal(t, []string{id}, s.list(true, iterationID))
+	// archived work items can still be loaded by their ID
+	_, err = workitem.NewWorkItemRepository(s.DB).Load(ctx, id)
+	require.Nil(t, err)
+
+	wi, err = repo.Archive(ctx, id, false)
+	require.Nil(t, err)
+	assert.Nil(t, wi.Fields[workitem.SystemArchived])
+	assert.Equal(t, []string{id}, s.list(false, iterationID))
+}
+
+func (s *archiveRepoBlackBoxTest) TestArchiveClosed() {
+	t := s.T()
+	resource.Require(t, resource.Database)
+
+	iterationID := uuid.NewV4().String()
+	open := s.create(iterationID, workitem.SystemStateOpen)
+	closed := s.create(iterationID, workitem.SystemStateClosed)
+	s.create(uuid.NewV4().String(), workitem.SystemStateClosed)
+
+	n, err := workitem.NewArchiveRepository(s.DB).ArchiveClosed(context.Background(), iterationID)
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), n)
+	assert.Equal(t, []string{open}, s.list(false, iterationID))
+	assert.Equal(t, []string{closed}, s.list(true, iterationID))
+}
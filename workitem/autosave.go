@@ -0,0 +1,224 @@
+package workitem
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// AutosaveFields are the fields whose changes can be autosaved
+var AutosaveFields = []string{SystemTitle, SystemDescription}
+
+// Autosave holds the changes an identity made to the fields of a work item
+// while editing it. Autosaving doesn't change the work item, doesn't create
+// revisions and doesn't notify anybody, the changes are applied at once as a
+// single revision.
+type Autosave struct {
+	WorkItemID uint64    `gorm:"primary_key"`
+	IdentityID uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	// Version is the version of the work item the changes are based on
+	Version   int
+	Fields    Fields `sql:"type:jsonb"`
+	UpdatedAt time.Time
+}
+
+// TableName implements gorm.tabler
+func (a Autosave) TableName() string {
+	return "work_item_autosaves"
+}
+
+// AutosaveRepository encapsulates the autosaved changes of the identity of
+// the viewer of the context
+type AutosaveRepository interface {
+	Save(ctx context.Context, workItemID string, fields map[string]interface{}) (*Autosave, error)
+	Load(ctx context.Context, workItemID string) (*Autosave, error)
+	Apply(ctx context.Context, workItemID string) (*app.WorkItem, error)
+	Discard(ctx context.Context, workItemID string) error
+}
+
+// NewAutosaveRepository creates a new storage type.
+func NewAutosaveRepository(db *gorm.DB) AutosaveRepository {
+	return &GormAutosaveRepository{db: db, wir: NewWorkItemRepository(db)}
+}
+
+// GormAutosaveRepository is the implementation of the storage interface for
+// autosaved changes.
+type GormAutosaveRepository struct {
+	db  *gorm.DB
+	wir *GormWorkItemRepository
+}
+
+// Save adds the changes of the given fields to the autosave of the work
+// item. An autosave based on an older version of the work item is replaced.
+// returns NotFoundError, BadParameterError, ConversionError or InternalError
+func (m *GormAutosaveRepository) Save(ctx context.Context, workItemID string, fields map[string]interface{}) (*Autosave, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "autosave", "save"}, time.Now())
+
+	identityID, err := autosaveIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	wi, err := m.wir.Load(ctx, workItemID)
+	if err != nil {
+		return nil, err
+	}
+	wiType, err := m.wir.wir.LoadTypeFromDB(wi.Type)
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	changes := Fields{}
+	for name, value := range fields {
+		def, ok := wiType.Fields[name]
+		if !ok || !isAutosaveField(name) {
+			return nil, errors.NewBadParameterError("fields", name).Expected(AutosaveFields)
+		}
+		if changes[name], err = def.ConvertToModel(name, value); err != nil {
+			return nil, errors.NewBadParameterError(name, value)
+		}
+	}
+	if err := encryptFields(*wiType, changes); err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(changes)
+	if err != nil {
+		return nil, errors.NewConversionError(err.Error())
+	}
+	id, _ := strconv.ParseUint(wi.ID, 10, 64)
+	err = m.db.Exec(`INSERT INTO work_item_autosaves (work_item_id, identity_id, version, fields, updated_at) VALUES (?, ?, ?, ?, now())
+		ON CONFLICT (work_item_id, identity_id) DO UPDATE SET version = excluded.version, updated_at = excluded.updated_at,
+			fields = CASE WHEN work_item_autosaves.version = excluded.version THEN work_item_autosaves.fields || excluded.fields ELSE excluded.fields END`,
+		id, identityID, wi.Version, string(b)).Error
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return m.load(ctx, wi, identityID)
+}
+
+// Load returns the autosave of the work item
+// returns NotFoundError, ConversionError or InternalError
+func (m *GormAutosaveRepository) Load(ctx context.Context, workItemID string) (*Autosave, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "autosave", "load"}, time.Now())
+
+	identityID, err := autosaveIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	wi, err := m.wir.Load(ctx, workItemID)
+	if err != nil {
+		return nil, err
+	}
+	return m.load(ctx, wi, identityID)
+}
+
+// Apply saves the work item with the changes of its autosave as a new
+// version and discards the autosave
+// returns NotFoundError, BadParameterError, VersionConflictError if the work
+// item was changed since the autosave was started, ConversionError or InternalError
+func (m *GormAutosaveRepository) Apply(ctx context.Context, workItemID string) (*app.WorkItem, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "autosave", "apply"}, time.Now())
+
+	identityID, err := autosaveIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	wi, err := m.wir.Load(ctx, workItemID)
+	if err != nil {
+		return nil, err
+	}
+	a, err := m.load(ctx, wi, identityID)
+	if err != nil {
+		return nil, err
+	}
+	if a.Version != wi.Version {
+		return nil, errors.NewVersionConflictError("the work item was changed since the autosave was started")
+	}
+	for name, value := range a.Fields {
+		wi.Fields[name] = value
+	}
+	saved, err := m.wir.Save(ctx, *wi)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.discard(a.WorkItemID, identityID); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// Discard deletes the autosave of the work item
+// returns NotFoundError or InternalError
+func (m *GormAutosaveRepository) Discard(ctx context.Context, workItemID string) error {
+	defer goa.MeasureSince([]string{"goa", "db", "autosave", "discard"}, time.Now())
+
+	identityID, err := autosaveIdentity(ctx)
+	if err != nil {
+		return err
+	}
+	wi, err := m.wir.Load(ctx, workItemID)
+	if err != nil {
+		return err
+	}
+	id, _ := strconv.ParseUint(wi.ID, 10, 64)
+	return m.discard(id, identityID)
+}
+
+// load returns the autosave of the loaded work item with the fields
+// decrypted
+func (m *GormAutosaveRepository) load(ctx context.Context, wi *app.WorkItem, identityID uuid.UUID) (*Autosave, error) {
+	var a Autosave
+	tx := m.db.Where("work_item_id = ? AND identity_id = ?", wi.ID, identityID).First(&a)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("autosave", wi.ID)
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	wiType, err := m.wir.wir.LoadTypeFromDB(wi.Type)
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	if a.Fields, err = DecryptFields(*wiType, a.Fields); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// discard deletes the autosave of the work item with the given ID
+func (m *GormAutosaveRepository) discard(workItemID uint64, identityID uuid.UUID) error {
+	tx := m.db.Where("work_item_id = ? AND identity_id = ?", workItemID, identityID).Delete(&Autosave{})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("autosave", strconv.FormatUint(workItemID, 10))
+	}
+	return nil
+}
+
+// autosaveIdentity returns the identity of the viewer of the context,
+// autosaves belong to an identity
+func autosaveIdentity(ctx context.Context) (uuid.UUID, error) {
+	v := ContextViewer(ctx)
+	if v == nil || v.IdentityID == nil {
+		return uuid.Nil, errors.NewBadParameterError("identity", nil).Expected("an authenticated identity")
+	}
+	return *v.IdentityID, nil
+}
+
+// isAutosaveField returns true if changes of the field can be autosaved
+func isAutosaveField(name string) bool {
+	for _, f := range AutosaveFields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package workitem_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/context"
+)
+
+type autosaveRepoBlackBoxTest struct {
+	gormsupport.DBTestSuite
+	clean func()
+}
+
+func TestRunAutosaveRepoBlackBoxTest(t *testing.T) {
+	suite.Run(t, &autosaveRepoBlackBoxTest{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *autosaveRepoBlackBoxTest) SetupTest() {
+	s.clean = gormsupport.DeleteCreatedEntities(s.DB)
+}
+
+func (s *autosaveRepoBlackBoxTest) TearDownTest() {
+	s.clean()
+}
+
+func (s *autosaveRepoBlackBoxTest) TestSaveAndApply() {
+	t := s.T()
+	resource.Require(t, resource.Database)
+
+	identity := account.Identity{FullName: "Writer"}
+	require.Nil(t, account.NewIdentityRepository(s.DB).Create(context.Background(), &identity))
+	ctx := workitem.WithViewer(context.Background(), &workitem.Viewer{IdentityID: &identity.ID})
+	repo := workitem.NewWorkItemRepository(s.DB)
+	wi, err := repo.Create(ctx, workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle: "title",
+		workitem.SystemState: workitem.SystemStateNew,
+	}, identity.ID.String())
+	require.Nil(t, err)
+
+	autosaves := workitem.NewAutosaveRepository(s.DB)
+	_, err = autosaves.Save(ctx, wi.ID, map[string]interface{}{workitem.SystemState: workitem.SystemStateClosed})
+	assert.IsType(t, errors.BadParameterError{}, err)
+	_, err = autosaves.Save(ctx, wi.ID, map[string]interface{}{workitem.SystemDescription: "first"})
+	require.Nil(t, err)
+	a, err := autosaves.Save(ctx, wi.ID, map[string]interface{}{workitem.SystemTitle: "better title", workitem.SystemDescription: "first draft"})
+	require.Nil(t, err)
+	assert.Equal(t, wi.Version, a.Version)
+	assert.Equal(t, "first draft", a.Fields[workitem.SystemDescription])
+
+	// autosaving doesn't change the work item
+	loaded, err := repo.Load(ctx, wi.ID)
+	require.Nil(t, err)
+	assert.Equal(t, wi.Version, loaded.Version)
+	assert.Equal(t, "title", loaded.Fields[workitem.SystemTitle])
+
+	applied, err := autosaves.Apply(ctx, wi.ID)
+	require.Nil(t, err)
+	assert.Equal(t, wi.Version+1, applied.Version)
+	assert.Equal(t, "better title", applied.Fields[workitem.SystemTitle])
+	assert.Equal(t, "first draft", applied.Fields[workitem.SystemDescription])
+	_, err = autosaves.Load(ctx, wi.ID)
+	assert.IsType(t, errors.NotFoundError{}, err)
+
+	// changes based on an outdated version can't be applied
+	_, err = autosaves.Save(ctx, wi.ID, map[string]interface{}{workitem.SystemDescription: "second draft"})
+	require.Nil(t, err)
+	applied.Fields[workitem.SystemState] = workitem.SystemStateOpen
+	_, err = repo.Save(ctx, *applied)
+	require.Nil(t, err)
+	_, err = autosaves.Apply(ctx, wi.ID)
+	assert.IsType(t, errors.VersionConflictError{}, err)
+	require.Nil(t, autosaves.Discard(ctx, wi.ID))
+	assert.IsType(t, errors.NotFoundError{}, autosaves.Discard(ctx, wi.ID))
+}
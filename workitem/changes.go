@@ -0,0 +1,198 @@
+package workitem
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+)
+
+// ChangesCheckpointLag is how far the checkpoint and the token returned with
+// the changes lag behind the time they were read. Work items are stamped and
+// their events are numbered when they are changed, not when the change
+// commits, so changes committed shortly after the read may come before the
+// read; they are reported again instead of being missed.
+const ChangesCheckpointLag = time.Minute
+
+// changeTokenPrefix versions the format of change tokens
+const changeTokenPrefix = "e1:"
+
+// Change is the version of a work item a sync client compares with the
+// version it has to decide whether to fetch the work item again
+type Change struct {
+	ID        string
+	Version   int
+	UpdatedAt time.Time
+	// Created work items were created since the checkpoint or the token
+	Created bool
+	// Archived work items are reported, clients drop them unless they sync
+	// archived work items as well
+	Archived bool
+	// Deleted work items were moved to the trash, clients drop them
+	Deleted bool
+}
+
+// ChangeSet holds the changes of work items and where to continue from
+type ChangeSet struct {
+	Changes []*Change
+	// Checkpoint is the since of the next call to Changes
+	Checkpoint time.Time
+	// Token is the opaque token of the next call to ChangesAfter
+	Token string
+}
+
+// ChangeRepository encapsulates detecting the changes of work items for sync
+// clients
+type ChangeRepository interface {
+	Changes(ctx context.Context, criteria criteria.Expression, since *time.Time) (*ChangeSet, error)
+	ChangesAfter(ctx context.Context, criteria criteria.Expression, token string) (*ChangeSet, error)
+}
+
+// NewChangeRepository creates a new storage type.
+func NewChangeRepository(db *gorm.DB) ChangeRepository {
+	return &GormChangeRepository{db: db}
+}
+
+// GormChangeRepository is the implementation of the storage interface for
+// work item changes.
+type GormChangeRepository struct {
+	db *gorm.DB
+}
+
+// Changes returns the visible work items selected by the given
+// criteria.Expression that changed after since, including the ones deleted
+// since, ordered by ID. Without since all work items that are not deleted are
+// returned. Archived work items are returned whether or not the expression
+// asks for them, drafts only if it asks for them.
+// returns BadParameterError or InternalError
+func (m *GormChangeRepository) Changes(ctx context.Context, criteria criteria.Expression, since *time.Time) (*ChangeSet, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitemchange", "changes"}, time.Now())
+
+	set, err := m.next()
+	if err != nil {
+		return nil, err
+	}
+	db, err := m.query(ctx, criteria)
+	if err != nil {
+		return nil, err
+	}
+	if since != nil {
+		db = db.Where("(work_items.updated_at > ? OR work_items.deleted_at > ?)", *since, *since)
+		set.Changes, err = m.scan(db, "work_items.created_at > ?", *since)
+	} else {
+		db = db.Where("work_items.deleted_at IS NULL")
+		set.Changes, err = m.scan(db, "false")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// ChangesAfter returns the visible work items selected by the given
+// criteria.Expression that were created, changed or deleted after the token
+// was returned, ordered by ID. The work items are selected like by Changes.
+// Unlike the checkpoint of Changes the token is based on the events of the
+// work items, see Event.
+// returns BadParameterError or InternalError
+func (m *GormChangeRepository) ChangesAfter(ctx context.Context, criteria criteria.Expression, token string) (*ChangeSet, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitemchange", "changesafter"}, time.Now())
+
+	sequence, err := parseChangeToken(token)
+	if err != nil {
+		return nil, err
+	}
+	set, err := m.next()
+	if err != nil {
+		return nil, err
+	}
+	db, err := m.query(ctx, criteria)
+	if err != nil {
+		return nil, err
+	}
+	db = db.Joins(`JOIN (SELECT work_item_id, bool_or(kind = ?) AS created FROM work_item_events WHERE sequence > ? GROUP BY work_item_id) e
+		ON e.work_item_id = work_items.id`, EventCreate, sequence)
+	if set.Changes, err = m.scan(db, "e.created"); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// next returns the checkpoint and the token of the next call. The token is
+// the sequence of the last event recorded before the checkpoint.
+func (m *GormChangeRepository) next() (*ChangeSet, error) {
+	checkpoint := time.Now().Add(-ChangesCheckpointLag)
+	var sequence uint64
+	err := m.db.Raw("SELECT coalesce((SELECT sequence FROM work_item_events WHERE created_at <= ? ORDER BY sequence DESC LIMIT 1), 0)", checkpoint).
+		Row().Scan(&sequence)
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return &ChangeSet{
+		Changes:    []*Change{},
+		Checkpoint: checkpoint,
+		Token:      base64.RawURLEncoding.EncodeToString([]byte(changeTokenPrefix + strconv.FormatUint(sequence, 10))),
+	}, nil
+}
+
+// query returns the query of the visible work items selected by the given
+// criteria.Expression including the deleted ones
+func (m *GormChangeRepository) query(ctx context.Context, criteria criteria.Expression) (*gorm.DB, error) {
+	where, parameters, err := Compile(criteria)
+	if err != nil {
+		return nil, errors.NewBadParameterError("expression", criteria)
+	}
+	db := m.db.Unscoped().Model(&WorkItem{}).Where(where, parameters...)
+	if !referencesField(criteria, SystemDraft) {
+		db = db.Where(notDraft)
+	}
+	if clause, params := VisibilityClause(ctx, WorkItem{}.TableName()); clause != "" {
+		db = db.Where(clause, params...)
+	}
+	return db, nil
+}
+
+// scan returns the changes of the work items of the query, created is the
+// SQL expression with its parameters telling whether a work item was created
+func (m *GormChangeRepository) scan(db *gorm.DB, created string, params ...interface{}) ([]*Change, error) {
+	rows, err := db.Select("work_items.id, work_items.version, work_items.updated_at, work_items.archived, work_items.deleted_at IS NOT NULL, "+created, params...).
+		Order("work_items.id").Rows()
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	defer rows.Close()
+	changes := []*Change{}
+	for rows.Next() {
+		var id uint64
+		var c Change
+		if err := rows.Scan(&id, &c.Version, &c.UpdatedAt, &c.Archived, &c.Deleted, &c.Created); err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		c.ID = strconv.FormatUint(id, 10)
+		changes = append(changes, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return changes, nil
+}
+
+// parseChangeToken returns the event sequence of a token returned by
+// ChangesAfter
+// returns BadParameterError
+func parseChangeToken(token string) (uint64, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil && strings.HasPrefix(string(b), changeTokenPrefix) {
+		if sequence, err := strconv.ParseUint(strings.TrimPrefix(string(b), changeTokenPrefix), 10, 64); err == nil {
+			return sequence, nil
+		}
+	}
+	return 0, errors.NewBadParameterError("token", token).Expected("a token returned with earlier changes")
+}
@@ -0,0 +1,90 @@
+package workitem_test
+
+import (
+	"encoding/base64"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/context"
+)
+
+type changeRepoBlackBoxTest struct {
+	gormsupport.DBTestSuite
+	clean func()
+}
+
+func TestRunChangeRepoBlackBoxTest(t *testing.T) {
+	suite.Run(t, &changeRepoBlackBoxTest{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *changeRepoBlackBoxTest) SetupTest() {
+	s.clean = gormsupport.DeleteCreatedEntities(s.DB)
+}
+
+func (s *changeRepoBlackBoxTest) TearDownTest() {
+	s.clean()
+}
+
+func (s *changeRepoBlackBoxTest) TestChanges() {
+	t := s.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := workitem.NewWorkItemRepository(s.DB)
+	changes := workitem.NewChangeRepository(s.DB)
+	title := "changes-" + uuid.NewV4().String()
+	fields := map[string]interface{}{workitem.SystemTitle: title, workitem.SystemState: workitem.SystemStateNew}
+	unchanged, err := repo.Create(ctx, workitem.SystemBug, fields, "xx")
+	require.Nil(t, err)
+	updated, err := repo.Create(ctx, workitem.SystemBug, fields, "xx")
+	require.Nil(t, err)
+	deleted, err := repo.Create(ctx, workitem.SystemBug, fields, "xx")
+	require.Nil(t, err)
+
+	exp := criteria.Equals(criteria.Field(workitem.SystemTitle), criteria.Literal(title))
+	all, err := changes.Changes(ctx, exp, nil)
+	require.Nil(t, err)
+	require.Len(t, all.Changes, 3)
+	assert.Equal(t, unchanged.ID, all.Changes[0].ID)
+	assert.Equal(t, 0, all.Changes[0].Version)
+	assert.True(t, all.Checkpoint.Before(time.Now()))
+	assert.NotEmpty(t, all.Token)
+
+	// the work items were changed before the checkpoint
+	since := time.Now()
+	require.Nil(t, s.DB.Exec("UPDATE work_items SET updated_at = ? WHERE id IN (?, ?, ?)", since.Add(-time.Hour), unchanged.ID, updated.ID, deleted.ID).Error)
+	var sequence uint64
+	require.Nil(t, s.DB.Raw("SELECT max(sequence) FROM work_item_events").Row().Scan(&sequence))
+	_, err = repo.Save(ctx, *updated)
+	require.Nil(t, err)
+	require.Nil(t, repo.Delete(ctx, deleted.ID))
+
+	changed, err := changes.Changes(ctx, exp, &since)
+	require.Nil(t, err)
+	require.Len(t, changed.Changes, 2)
+	assert.Equal(t, workitem.Change{ID: updated.ID, Version: 1, UpdatedAt: changed.Changes[0].UpdatedAt}, *changed.Changes[0])
+	assert.Equal(t, deleted.ID, changed.Changes[1].ID)
+	assert.True(t, changed.Changes[1].Deleted)
+
+	// the token of the last event before the changes
+	token := base64.RawURLEncoding.EncodeToString([]byte("e1:" + strconv.FormatUint(sequence, 10)))
+	after, err := changes.ChangesAfter(ctx, exp, token)
+	require.Nil(t, err)
+	require.Len(t, after.Changes, 2)
+	assert.Equal(t, updated.ID, after.Changes[0].ID)
+	assert.False(t, after.Changes[0].Created)
+	assert.True(t, after.Changes[1].Deleted)
+
+	_, err = changes.ChangesAfter(ctx, exp, "not a token")
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
@@ -0,0 +1,207 @@
+package workitem
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// MaxChecklistItems is the number of items a checklist field can hold
+const MaxChecklistItems = 100
+
+// CompletionSuffix is appended to the name of a checklist field for the
+// computed attribute holding the percentage of its items that are done. It
+// is not a field and is ignored when work items are saved.
+const CompletionSuffix = ".completion"
+
+// ChecklistItem is an item of a checklist field
+type ChecklistItem struct {
+	// ID identifies the item within the checklist, it is generated when the
+	// item is added without one
+	ID   string
+	Text string
+	Done bool
+}
+
+// ParseChecklist returns the items of a checklist value as sent by clients
+// or stored, a list of objects with id, text and done members
+func ParseChecklist(value interface{}) ([]ChecklistItem, error) {
+	var values []interface{}
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		values = v
+	case []map[string]interface{}:
+		for _, item := range v {
+			values = append(values, item)
+		}
+	default:
+		return nil, fmt.Errorf("value %v should be a list of checklist items", value)
+	}
+	if len(values) > MaxChecklistItems {
+		return nil, fmt.Errorf("a checklist can hold at most %d items", MaxChecklistItems)
+	}
+	items := make([]ChecklistItem, 0, len(values))
+	seen := map[string]bool{}
+	for _, v := range values {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("checklist item %v should be an object", v)
+		}
+		var item ChecklistItem
+		if item.Text, ok = m["text"].(string); !ok || strings.TrimSpace(item.Text) == "" {
+			return nil, fmt.Errorf("checklist item %v should have a text", v)
+		}
+		if done, set := m["done"]; set {
+			if item.Done, ok = done.(bool); !ok {
+				return nil, fmt.Errorf("done of checklist item %v should be a boolean", v)
+			}
+		}
+		if id, set := m["id"]; set && id != nil {
+			if item.ID, ok = id.(string); !ok || item.ID == "" {
+				return nil, fmt.Errorf("id of checklist item %v should be a string", v)
+			}
+		} else {
+			item.ID = uuid.NewV4().String()
+		}
+		if seen[item.ID] {
+			return nil, fmt.Errorf("checklist item %s is listed twice", item.ID)
+		}
+		seen[item.ID] = true
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// checklistValue returns the value of a checklist field holding the items
+func checklistValue(items []ChecklistItem) []interface{} {
+	value := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		value = append(value, map[string]interface{}{"id": item.ID, "text": item.Text, "done": item.Done})
+	}
+	return value
+}
+
+// ChecklistCompletion returns the percentage of the items that are done,
+// rounded down, 0 for an empty checklist
+func ChecklistCompletion(items []ChecklistItem) int {
+	if len(items) == 0 {
+		return 0
+	}
+	done := 0
+	for _, item := range items {
+		if item.Done {
+			done++
+		}
+	}
+	return done * 100 / len(items)
+}
+
+// addChecklistCompletions adds the completion of the checklist fields of the
+// work item that are set as computed attributes
+func addChecklistCompletions(wit WorkItemType, wi *app.WorkItem) {
+	for name, def := range wit.Fields {
+		if def.Type.GetKind() != KindChecklist {
+			continue
+		}
+		value, ok := wi.Fields[name]
+		if !ok || value == nil {
+			continue
+		}
+		if items, err := ParseChecklist(value); err == nil {
+			wi.Fields[name+CompletionSuffix] = ChecklistCompletion(items)
+		}
+	}
+}
+
+// ChecklistRepository encapsulates checking the items of checklist fields
+type ChecklistRepository interface {
+	Check(ctx context.Context, ID string, field string, itemID string, done bool) (*app.WorkItem, error)
+}
+
+// NewChecklistRepository creates a new storage type.
+func NewChecklistRepository(db *gorm.DB) ChecklistRepository {
+	return &GormChecklistRepository{db: db, wir: NewWorkItemRepository(db)}
+}
+
+// GormChecklistRepository is the implementation of the storage interface for
+// checking the items of checklist fields.
+type GormChecklistRepository struct {
+	db  *gorm.DB
+	wir *GormWorkItemRepository
+}
+
+// Check marks the item of the checklist field of the work item with the
+// given ID as done or not. Unlike saving the work item it doesn't need its
+// version, concurrent changes of the work item wait for the transaction to
+// end and the version is increased.
+// returns NotFoundError, BadParameterError, VersionConflictError, ConversionError or InternalError
+func (m *GormChecklistRepository) Check(ctx context.Context, ID string, field string, itemID string, done bool) (*app.WorkItem, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "checklist", "check"}, time.Now())
+
+	id, err := strconv.ParseUint(ID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, errors.NewNotFoundError("work item", ID)
+	}
+	var wi WorkItem
+	tx := m.db.Set("gorm:query_option", "FOR UPDATE").First(&wi, id)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("work item", ID)
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	viewer := ContextViewer(ctx)
+	if !viewer.CanSee(wi.Fields) {
+		return nil, errors.NewNotFoundError("work item", ID)
+	}
+	wiType, err := m.wir.wir.LoadTypeFromDB(wi.Type)
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	def, ok := wiType.Fields[field]
+	if !ok || def.Type.GetKind() != KindChecklist {
+		return nil, errors.NewBadParameterError("field", field).Expected("a checklist field of the work item type")
+	}
+	fields, err := DecryptFields(*wiType, wi.Fields)
+	if err != nil {
+		return nil, err
+	}
+	if !viewer.HasRole(fields, def.Roles...) {
+		return nil, errors.NewBadParameterError("field", field).Expected(fmt.Sprintf("a field that isn't restricted to %v", def.Roles))
+	}
+	items, err := ParseChecklist(fields[field])
+	if err != nil {
+		return nil, errors.NewConversionError(err.Error())
+	}
+	found := false
+	for i := range items {
+		if items[i].ID == itemID {
+			found = true
+			items[i].Done = done
+		}
+	}
+	if !found {
+		return nil, errors.NewNotFoundError("checklist item", itemID)
+	}
+	fields[field] = checklistValue(items)
+	if err := encryptFields(*wiType, fields); err != nil {
+		return nil, err
+	}
+	wi.Version++
+	wi.Fields = fields
+	if err := apply(m.db, newEvent(ctx, EventUpdate, wi)); err != nil {
+		return nil, err
+	}
+	return convertWorkItemModelToApp(ctx, wiType, &wi)
+}
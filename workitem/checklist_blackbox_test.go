@@ -0,0 +1,72 @@
+package workitem_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/resource"
+	. "github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChecklist(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	items, err := ParseChecklist(nil)
+	require.Nil(t, err)
+	assert.Empty(t, items)
+
+	items, err = ParseChecklist([]interface{}{
+		map[string]interface{}{"id": "1", "text": "write tests", "done": true},
+		map[string]interface{}{"text": "update docs"},
+	})
+	require.Nil(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, ChecklistItem{ID: "1", Text: "write tests", Done: true}, items[0])
+	assert.NotEmpty(t, items[1].ID)
+	assert.Equal(t, "update docs", items[1].Text)
+	assert.False(t, items[1].Done)
+
+	for _, value := range []interface{}{
+		"write tests",
+		[]interface{}{"write tests"},
+		[]interface{}{map[string]interface{}{"text": " "}},
+		[]interface{}{map[string]interface{}{"text": "write tests", "done": "yes"}},
+		[]interface{}{map[string]interface{}{"id": 1, "text": "write tests"}},
+		[]interface{}{
+			map[string]interface{}{"id": "1", "text": "write tests"},
+			map[string]interface{}{"id": "1", "text": "update docs"},
+		},
+		make([]interface{}, MaxChecklistItems+1),
+	} {
+		_, err := ParseChecklist(value)
+		assert.NotNil(t, err, "%v", value)
+	}
+}
+
+func TestChecklistCompletion(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	assert.Equal(t, 0, ChecklistCompletion(nil))
+	assert.Equal(t, 33, ChecklistCompletion([]ChecklistItem{{Done: true}, {}, {}}))
+	assert.Equal(t, 100, ChecklistCompletion([]ChecklistItem{{Done: true}, {Done: true}}))
+}
+
+func TestConvertChecklist(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	checklist := SimpleType{Kind: KindChecklist}
+	assert.False(t, KindChecklist.IsSimpleType())
+	value, err := checklist.ConvertToModel([]interface{}{map[string]interface{}{"id": "1", "text": "write tests"}})
+	require.Nil(t, err)
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": "1", "text": "write tests", "done": false}}, value)
+	converted, err := checklist.ConvertFromModel(value)
+	require.Nil(t, err)
+	assert.Equal(t, value, converted)
+
+	_, err = checklist.ConvertToModel("write tests")
+	assert.NotNil(t, err)
+}
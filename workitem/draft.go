@@ -0,0 +1,103 @@
+package workitem
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+)
+
+// isDraft matches the work items that are not yet published, notDraft the
+// ones that are
+var (
+	isDraft  = fmt.Sprintf(`fields @> '{"%s": true}'`, SystemDraft)
+	notDraft = "NOT (" + isDraft + ")"
+)
+
+// DraftRepository encapsulates the work items saved before they are
+// submitted. Drafts are created with SystemDraft set, they are only visible
+// to their creator and only listed if the criteria ask for them until they
+// are published.
+type DraftRepository interface {
+	Publish(ctx context.Context, ID string) (*app.WorkItem, error)
+	DeleteAbandoned(ctx context.Context, before time.Time) (int64, error)
+}
+
+// NewDraftRepository creates a new storage type.
+func NewDraftRepository(db *gorm.DB) DraftRepository {
+	return &GormDraftRepository{db: db, wir: NewWorkItemRepository(db)}
+}
+
+// GormDraftRepository is the implementation of the storage interface for
+// draft work items.
+type GormDraftRepository struct {
+	db  *gorm.DB
+	wir *GormWorkItemRepository
+}
+
+// Publish publishes the draft with the given ID, creating a new version.
+// The changes the viewer of ctx autosaved for the current version of the
+// draft are published with it.
+// returns NotFoundError, BadParameterError, VersionConflictError, ConversionError or InternalError
+func (m *GormDraftRepository) Publish(ctx context.Context, ID string) (*app.WorkItem, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitemdraft", "publish"}, time.Now())
+
+	wi, err := m.wir.Load(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+	if draft, _ := wi.Fields[SystemDraft].(bool); !draft {
+		return nil, errors.NewBadParameterError("id", ID).Expected("a draft")
+	}
+	if identityID, err := autosaveIdentity(ctx); err == nil {
+		autosaves := &GormAutosaveRepository{db: m.db, wir: m.wir}
+		a, err := autosaves.load(ctx, wi, identityID)
+		switch err.(type) {
+		case nil:
+			if a.Version == wi.Version {
+				for name, value := range a.Fields {
+					wi.Fields[name] = value
+				}
+			}
+			if err := autosaves.discard(a.WorkItemID, identityID); err != nil {
+				return nil, err
+			}
+		case errors.NotFoundError:
+		default:
+			return nil, err
+		}
+	}
+	delete(wi.Fields, SystemDraft)
+	return m.wir.Save(ctx, *wi)
+}
+
+// DeleteAbandoned deletes the drafts not changed since the given time like
+// Delete does and returns how many there were. Drafts whose links block
+// their deletion are kept.
+// returns InternalError
+func (m *GormDraftRepository) DeleteAbandoned(ctx context.Context, before time.Time) (int64, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitemdraft", "deleteabandoned"}, time.Now())
+
+	var drafts []WorkItem
+	if err := m.db.Where(isDraft+" AND updated_at < ?", before).Order("id").Find(&drafts).Error; err != nil {
+		return 0, errors.NewInternalError(err.Error())
+	}
+	var n int64
+	for _, wi := range drafts {
+		if err := m.wir.deleteWithLinks(ctx, wi); err != nil {
+			if _, ok := err.(errors.BadParameterError); ok {
+				log.Printf("Keeping abandoned draft %d: %s\n", wi.ID, err.Error())
+				continue
+			}
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,101 @@
+package workitem_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/context"
+)
+
+type draftRepoBlackBoxTest struct {
+	gormsupport.DBTestSuite
+	clean func()
+}
+
+func TestRunDraftRepoBlackBoxTest(t *testing.T) {
+	suite.Run(t, &draftRepoBlackBoxTest{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *draftRepoBlackBoxTest) SetupTest() {
+	s.clean = gormsupport.DeleteCreatedEntities(s.DB)
+}
+
+func (s *draftRepoBlackBoxTest) TearDownTest() {
+	s.clean()
+}
+
+func (s *draftRepoBlackBoxTest) TestPublish() {
+	t := s.T()
+	resource.Require(t, resource.Database)
+
+	creator := uuid.NewV4()
+	ctx := workitem.WithViewer(context.Background(), &workitem.Viewer{IdentityID: &creator})
+	repo := workitem.NewWorkItemRepository(s.DB)
+	wi, err := repo.Create(ctx, workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle: "half-baked",
+		workitem.SystemState: workitem.SystemStateNew,
+		workitem.SystemDraft: true,
+	}, creator.String())
+	require.Nil(t, err)
+
+	someone := uuid.NewV4()
+	_, err = repo.Load(workitem.WithViewer(context.Background(), &workitem.Viewer{IdentityID: &someone}), wi.ID)
+	assert.IsType(t, errors.NotFoundError{}, err)
+	byID := criteria.Equals(criteria.Field("ID"), criteria.Literal(wi.ID))
+	listed, _, err := repo.List(ctx, byID, nil, nil)
+	require.Nil(t, err)
+	assert.Empty(t, listed)
+	listed, _, err = repo.List(ctx, criteria.And(byID, criteria.Equals(criteria.Field(workitem.SystemDraft), criteria.Literal(true))), nil, nil)
+	require.Nil(t, err)
+	assert.Len(t, listed, 1)
+
+	drafts := workitem.NewDraftRepository(s.DB)
+	published, err := drafts.Publish(ctx, wi.ID)
+	require.Nil(t, err)
+	assert.Nil(t, published.Fields[workitem.SystemDraft])
+	assert.Equal(t, wi.Version+1, published.Version)
+	listed, _, err = repo.List(ctx, byID, nil, nil)
+	require.Nil(t, err)
+	assert.Len(t, listed, 1)
+	_, err = drafts.Publish(ctx, wi.ID)
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
+
+func (s *draftRepoBlackBoxTest) TestDeleteAbandoned() {
+	t := s.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := workitem.NewWorkItemRepository(s.DB)
+	create := func(draft bool) string {
+		wi, err := repo.Create(ctx, workitem.SystemBug, map[string]interface{}{
+			workitem.SystemTitle: "title",
+			workitem.SystemState: workitem.SystemStateNew,
+			workitem.SystemDraft: draft,
+		}, "xx")
+		require.Nil(t, err)
+		return wi.ID
+	}
+	abandoned, published := create(true), create(false)
+	recent := create(true)
+	require.Nil(t, s.DB.Exec("UPDATE work_items SET updated_at = now() - interval '60 days' WHERE id IN (?, ?)", abandoned, published).Error)
+
+	n, err := workitem.NewDraftRepository(s.DB).DeleteAbandoned(ctx, time.Now().Add(-30*24*time.Hour))
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), n)
+	_, err = repo.Load(ctx, abandoned)
+	assert.IsType(t, errors.NotFoundError{}, err)
+	_, err = repo.Load(ctx, published)
+	assert.Nil(t, err)
+	_, err = repo.Load(ctx, recent)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,63 @@
+package workitem
+
+import (
+	"encoding/json"
+
+	"github.com/almighty/almighty-core/encryption"
+	"github.com/almighty/almighty-core/errors"
+)
+
+// encryptFields replaces the values of the encrypted fields of the work item
+// type with their json representation encrypted by the default keyring
+// returns InternalError if no keys are configured or ConversionError
+func encryptFields(wit WorkItemType, fields Fields) error {
+	for name, def := range wit.Fields {
+		value := fields[name]
+		if !def.Encrypted || value == nil {
+			continue
+		}
+		keyring, err := encryption.DefaultKeyring()
+		if err != nil {
+			return err
+		}
+		b, err := json.Marshal(value)
+		if err != nil {
+			return errors.NewConversionError(err.Error())
+		}
+		fields[name], err = keyring.EncryptString(string(b))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecryptFields returns a copy of the fields with the values of the encrypted
+// fields of the work item type decrypted by the default keyring
+// returns InternalError if no keys are configured or ConversionError
+func DecryptFields(wit WorkItemType, fields Fields) (Fields, error) {
+	result := Fields{}
+	for name, value := range fields {
+		result[name] = value
+	}
+	for name, def := range wit.Fields {
+		s, ok := fields[name].(string)
+		if !def.Encrypted || !ok || !encryption.IsEncrypted(s) {
+			continue
+		}
+		keyring, err := encryption.DefaultKeyring()
+		if err != nil {
+			return nil, err
+		}
+		plaintext, err := keyring.DecryptString(s)
+		if err != nil {
+			return nil, errors.NewConversionError(err.Error())
+		}
+		var value interface{}
+		if err := json.Unmarshal([]byte(plaintext), &value); err != nil {
+			return nil, errors.NewConversionError(err.Error())
+		}
+		result[name] = value
+	}
+	return result, nil
+}
@@ -0,0 +1,310 @@
+package workitem
+
+import (
+	"strconv"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Kinds of events
+const (
+	EventCreate = "create"
+	EventUpdate = "update"
+	EventDelete = "delete"
+	// EventRestore takes a deleted work item out of the trash
+	EventRestore = "restore"
+)
+
+// Event records a mutation of a work item with the fields of the work item
+// after the mutation, encrypted fields stay encrypted. The events of a work
+// item are its revisions, the work_items table is the projection of their
+// latest event.
+type Event struct {
+	ID uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	// Sequence orders the events of all work items
+	Sequence   uint64 `sql:"DEFAULT:nextval('work_item_events_sequence_seq')"`
+	CreatedAt  time.Time
+	Kind       string
+	WorkItemID uint64
+	Version    int
+	Type       string
+	// TypeVersion is the version of the work item type of the fields, 0
+	// for the events recorded before the types had versions
+	TypeVersion int
+	Fields      Fields `sql:"type:jsonb"`
+	// ModifierID is the identity that made the change, nil for system tasks
+	ModifierID *uuid.UUID `sql:"type:uuid"`
+}
+
+// TableName implements gorm.tabler
+func (e Event) TableName() string {
+	return "work_item_events"
+}
+
+// newEvent returns an event recording the given state of the work item,
+// made by the viewer of the context
+func newEvent(ctx context.Context, kind string, wi WorkItem) *Event {
+	e := Event{
+		CreatedAt:   time.Now(),
+		Kind:        kind,
+		WorkItemID:  wi.ID,
+		Version:     wi.Version,
+		Type:        wi.Type,
+		TypeVersion: wi.TypeVersion,
+		Fields:      wi.Fields,
+	}
+	if v := ContextViewer(ctx); v != nil {
+		e.ModifierID = v.IdentityID
+	}
+	return &e
+}
+
+// apply projects the event onto the work_items table and appends it. A create
+// event gets the ID of the new work item. An update event only applies to the
+// previous version of the work item, a restore event only to a deleted one.
+// returns NotFoundError, VersionConflictError or InternalError
+func apply(db *gorm.DB, e *Event) error {
+	switch e.Kind {
+	case EventCreate:
+		wi := WorkItem{Type: e.Type, Version: e.Version, TypeVersion: e.TypeVersion, Fields: e.Fields}
+		wi.CreatedAt = e.CreatedAt
+		wi.UpdatedAt = e.CreatedAt
+		if err := db.Create(&wi).Error; err != nil {
+			return errors.NewInternalError(err.Error())
+		}
+		e.WorkItemID = wi.ID
+	case EventUpdate:
+		tx := db.Model(&WorkItem{}).Where("id = ? AND version = ?", e.WorkItemID, e.Version-1).Updates(map[string]interface{}{
+			"type":         e.Type,
+			"version":      e.Version,
+			"type_version": e.TypeVersion,
+			"fields":       e.Fields,
+			"updated_at":   e.CreatedAt,
+		})
+		if tx.Error != nil {
+			return errors.NewInternalError(tx.Error.Error())
+		}
+		if tx.RowsAffected == 0 {
+			return errors.NewVersionConflictError("version conflict")
+		}
+	case EventDelete:
+		tx := db.Delete(WorkItem{ID: e.WorkItemID})
+		if tx.Error != nil {
+			return errors.NewInternalError(tx.Error.Error())
+		}
+		if tx.RowsAffected == 0 {
+			return errors.NewNotFoundError("work item", strconv.FormatUint(e.WorkItemID, 10))
+		}
+	case EventRestore:
+		// the restore is the latest change of the work item like on Rebuild
+		tx := db.Unscoped().Model(&WorkItem{}).Where("id = ? AND deleted_at IS NOT NULL", e.WorkItemID).UpdateColumns(map[string]interface{}{
+			"deleted_at": gorm.Expr("NULL"),
+			"updated_at": e.CreatedAt,
+		})
+		if tx.Error != nil {
+			return errors.NewInternalError(tx.Error.Error())
+		}
+		if tx.RowsAffected == 0 {
+			return errors.NewNotFoundError("deleted work item", strconv.FormatUint(e.WorkItemID, 10))
+		}
+	}
+	if err := db.Create(e).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// RecordEvents records the current state of the given work items as events
+// of the given kind. Repositories changing many work items at once with SQL
+// use it to record their changes in the same transaction.
+// returns InternalError
+func RecordEvents(ctx context.Context, db *gorm.DB, kind string, ids ...uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	var modifier interface{}
+	if v := ContextViewer(ctx); v != nil && v.IdentityID != nil {
+		modifier = v.IdentityID.String()
+	}
+	err := db.Exec(`INSERT INTO work_item_events (created_at, kind, work_item_id, version, type, type_version, fields, modifier_id)
+		SELECT ?, ?, id, version, type, type_version, fields, ? FROM work_items WHERE id IN (?)`, time.Now(), kind, modifier, ids).Error
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// EventRepository encapsulates retrieval of the events of work items
+type EventRepository interface {
+	List(ctx context.Context, workItemID string) ([]*Event, error)
+	LoadAt(ctx context.Context, workItemID string, at time.Time) (*app.WorkItem, error)
+	LoadVersion(ctx context.Context, workItemID string, version int) (*app.WorkItem, error)
+	Restore(ctx context.Context, workItemID string, version int) (*app.WorkItem, error)
+	Feed(ctx context.Context, after uint64, limit int) ([]*Event, error)
+	Rebuild(ctx context.Context) (int64, error)
+}
+
+// NewEventRepository creates a new storage type.
+func NewEventRepository(db *gorm.DB) EventRepository {
+	return &GormEventRepository{db: db, wir: NewWorkItemRepository(db)}
+}
+
+// GormEventRepository is the implementation of the storage interface for
+// work item events.
+type GormEventRepository struct {
+	db  *gorm.DB
+	wir *GormWorkItemRepository
+}
+
+// List returns the events of the work item, oldest first
+// returns NotFoundError or InternalError
+func (m *GormEventRepository) List(ctx context.Context, workItemID string) ([]*Event, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitemevent", "list"}, time.Now())
+
+	wi, err := m.load(ctx, workItemID)
+	if err != nil {
+		return nil, err
+	}
+	var objs []*Event
+	if err := m.db.Where("work_item_id = ?", wi.ID).Order("sequence").Find(&objs).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return objs, nil
+}
+
+// LoadAt returns the work item as it was at the given time
+// returns NotFoundError, ConversionError or InternalError
+func (m *GormEventRepository) LoadAt(ctx context.Context, workItemID string, at time.Time) (*app.WorkItem, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitemevent", "loadat"}, time.Now())
+
+	wi, err := m.load(ctx, workItemID)
+	if err != nil {
+		return nil, err
+	}
+	var e Event
+	tx := m.db.Where("work_item_id = ? AND created_at <= ?", wi.ID, at).Order("sequence desc").First(&e)
+	if tx.RecordNotFound() || e.Kind == EventDelete {
+		return nil, errors.NewNotFoundError("work item at "+at.Format(time.RFC3339), workItemID)
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return m.convert(ctx, wi, &e)
+}
+
+// LoadVersion returns the work item as it was at the given version
+// returns NotFoundError, ConversionError or InternalError
+func (m *GormEventRepository) LoadVersion(ctx context.Context, workItemID string, version int) (*app.WorkItem, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitemevent", "loadversion"}, time.Now())
+
+	wi, err := m.load(ctx, workItemID)
+	if err != nil {
+		return nil, err
+	}
+	return m.loadVersion(ctx, wi, version)
+}
+
+// Restore sets the fields of the work item to the ones of the given version,
+// creating a new version. Fields the current identity can't change and
+// fields the work item type no longer has are left as they are.
+// returns NotFoundError, BadParameterError, VersionConflictError, ConversionError or InternalError
+func (m *GormEventRepository) Restore(ctx context.Context, workItemID string, version int) (*app.WorkItem, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitemevent", "restore"}, time.Now())
+
+	wi, err := m.load(ctx, workItemID)
+	if err != nil {
+		return nil, err
+	}
+	restored, err := m.loadVersion(ctx, wi, version)
+	if err != nil {
+		return nil, err
+	}
+	restored.Version = wi.Version
+	return m.wir.Save(ctx, *restored)
+}
+
+// Feed returns the events of all work items in the order they happened,
+// starting after the given sequence number
+// returns BadParameterError or InternalError
+func (m *GormEventRepository) Feed(ctx context.Context, after uint64, limit int) ([]*Event, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitemevent", "feed"}, time.Now())
+
+	if limit <= 0 {
+		return nil, errors.NewBadParameterError("limit", limit).Expected("greater than 0")
+	}
+	var objs []*Event
+	if err := m.db.Where("sequence > ?", after).Order("sequence").Limit(limit).Find(&objs).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return objs, nil
+}
+
+// Rebuild sets all work items to the state of their latest event and
+// returns the number of work items that were out of date
+// returns InternalError
+func (m *GormEventRepository) Rebuild(ctx context.Context) (int64, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitemevent", "rebuild"}, time.Now())
+
+	tx := m.db.Exec(`UPDATE work_items w
+		SET type = e.type, version = e.version, type_version = e.type_version, fields = e.fields, updated_at = e.created_at,
+			deleted_at = CASE WHEN e.kind = ? THEN e.created_at END
+		FROM (SELECT DISTINCT ON (work_item_id) * FROM work_item_events ORDER BY work_item_id, sequence DESC) e
+		WHERE w.id = e.work_item_id
+			AND (w.version IS DISTINCT FROM e.version OR w.type IS DISTINCT FROM e.type OR w.fields IS DISTINCT FROM e.fields
+				OR (w.deleted_at IS NULL) <> (e.kind <> ?))`, EventDelete, EventDelete)
+	if tx.Error != nil {
+		return 0, errors.NewInternalError(tx.Error.Error())
+	}
+	return tx.RowsAffected, nil
+}
+
+// load returns the current work item if the viewer can see it
+func (m *GormEventRepository) load(ctx context.Context, workItemID string) (*WorkItem, error) {
+	wi, err := m.wir.LoadFromDB(workItemID)
+	if err != nil {
+		return nil, err
+	}
+	if !ContextViewer(ctx).CanSee(wi.Fields) {
+		return nil, errors.NewNotFoundError("work item", workItemID)
+	}
+	return wi, nil
+}
+
+// loadVersion returns the work item with the fields of the given version
+func (m *GormEventRepository) loadVersion(ctx context.Context, wi *WorkItem, version int) (*app.WorkItem, error) {
+	var e Event
+	tx := m.db.Where("work_item_id = ? AND version = ? AND kind <> ?", wi.ID, version, EventDelete).Order("sequence desc").First(&e)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("revision", strconv.Itoa(version))
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return m.convert(ctx, wi, &e)
+}
+
+// convert returns the work item with the fields of the event
+func (m *GormEventRepository) convert(ctx context.Context, wi *WorkItem, e *Event) (*app.WorkItem, error) {
+	if !ContextViewer(ctx).CanSee(e.Fields) {
+		return nil, errors.NewNotFoundError("work item", strconv.FormatUint(wi.ID, 10))
+	}
+	wiType, err := m.wir.wir.LoadTypeFromDB(e.Type)
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return convertWorkItemModelToApp(ctx, wiType, &WorkItem{
+		Lifecycle:   wi.Lifecycle,
+		ID:          wi.ID,
+		Type:        e.Type,
+		Version:     e.Version,
+		TypeVersion: e.TypeVersion,
+		Fields:      e.Fields,
+	})
+}
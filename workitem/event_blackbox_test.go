@@ -0,0 +1,129 @@
+package workitem_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/context"
+)
+
+type eventRepoBlackBoxTest struct {
+	gormsupport.DBTestSuite
+	clean func()
+}
+
+func TestRunEventRepoBlackBoxTest(t *testing.T) {
+	suite.Run(t, &eventRepoBlackBoxTest{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *eventRepoBlackBoxTest) SetupTest() {
+	s.clean = gormsupport.DeleteCreatedEntities(s.DB)
+}
+
+func (s *eventRepoBlackBoxTest) TearDownTest() {
+	s.clean()
+}
+
+func (s *eventRepoBlackBoxTest) TestSnapshotAndRestore() {
+	t := s.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := workitem.NewWorkItemRepository(s.DB)
+	events := workitem.NewEventRepository(s.DB)
+	wi, err := repo.Create(ctx, workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle: "original",
+		workitem.SystemState: workitem.SystemStateNew,
+	}, "xx")
+	require.Nil(t, err)
+	created := time.Now()
+
+	wi.Fields[workitem.SystemTitle] = "bad bulk edit"
+	wi, err = repo.Save(ctx, *wi)
+	require.Nil(t, err)
+
+	revs, err := events.List(ctx, wi.ID)
+	require.Nil(t, err)
+	require.Len(t, revs, 2)
+	assert.Equal(t, workitem.EventCreate, revs[0].Kind)
+	assert.Equal(t, 0, revs[0].Version)
+	assert.Equal(t, workitem.EventUpdate, revs[1].Kind)
+	assert.Equal(t, 1, revs[1].Version)
+
+	snapshot, err := events.LoadAt(ctx, wi.ID, created)
+	require.Nil(t, err)
+	assert.Equal(t, "original", snapshot.Fields[workitem.SystemTitle])
+	assert.Equal(t, 0, snapshot.Version)
+	_, err = events.LoadAt(ctx, wi.ID, created.Add(-time.Hour))
+	assert.IsType(t, errors.NotFoundError{}, err)
+
+	version, err := events.LoadVersion(ctx, wi.ID, 0)
+	require.Nil(t, err)
+	assert.Equal(t, "original", version.Fields[workitem.SystemTitle])
+	_, err = events.LoadVersion(ctx, wi.ID, 42)
+	assert.IsType(t, errors.NotFoundError{}, err)
+
+	restored, err := events.Restore(ctx, wi.ID, 0)
+	require.Nil(t, err)
+	assert.Equal(t, "original", restored.Fields[workitem.SystemTitle])
+	assert.Equal(t, 2, restored.Version)
+	revs, err = events.List(ctx, wi.ID)
+	require.Nil(t, err)
+	assert.Len(t, revs, 3)
+
+	_, err = events.Restore(ctx, wi.ID, 42)
+	assert.IsType(t, errors.NotFoundError{}, err)
+}
+
+func (s *eventRepoBlackBoxTest) TestFeedAndRebuild() {
+	t := s.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := workitem.NewWorkItemRepository(s.DB)
+	events := workitem.NewEventRepository(s.DB)
+	wi, err := repo.Create(ctx, workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle: "title",
+		workitem.SystemState: workitem.SystemStateNew,
+	}, "xx")
+	require.Nil(t, err)
+	wi.Fields[workitem.SystemTitle] = "changed"
+	_, err = repo.Save(ctx, *wi)
+	require.Nil(t, err)
+
+	revs, err := events.List(ctx, wi.ID)
+	require.Nil(t, err)
+	require.Len(t, revs, 2)
+	feed, err := events.Feed(ctx, revs[0].Sequence-1, 10)
+	require.Nil(t, err)
+	require.True(t, len(feed) >= 2)
+	assert.Equal(t, revs[0].ID, feed[0].ID)
+	assert.Equal(t, revs[1].ID, feed[1].ID)
+	assert.True(t, feed[0].Sequence < feed[1].Sequence)
+	_, err = events.Feed(ctx, 0, 0)
+	assert.IsType(t, errors.BadParameterError{}, err)
+
+	// a change bypassing the events is undone by rebuilding the projection
+	require.Nil(t, s.DB.Exec("UPDATE work_items SET version = 7, fields = '{}' WHERE id = ?", wi.ID).Error)
+	n, err := events.Rebuild(ctx)
+	require.Nil(t, err)
+	assert.True(t, n >= 1)
+	rebuilt, err := repo.Load(ctx, wi.ID)
+	require.Nil(t, err)
+	assert.Equal(t, 1, rebuilt.Version)
+	assert.Equal(t, "changed", rebuilt.Fields[workitem.SystemTitle])
+
+	require.Nil(t, repo.Delete(ctx, wi.ID))
+	require.Nil(t, s.DB.Exec("UPDATE work_items SET deleted_at = NULL WHERE id = ?", wi.ID).Error)
+	_, err = events.Rebuild(ctx)
+	require.Nil(t, err)
+	_, err = repo.Load(ctx, wi.ID)
+	assert.IsType(t, errors.NotFoundError{}, err)
+}
@@ -0,0 +1,122 @@
+package workitem
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+)
+
+// EventPartitionsAhead is the number of months after the current one whose
+// partitions exist before their first event arrives
+const EventPartitionsAhead = 2
+
+// eventPartitionLayout is the time layout of the names of the partitions
+const eventPartitionLayout = "work_item_events_y2006m01"
+
+// EventPartition is the table holding the work item events of one month
+type EventPartition struct {
+	Name string
+	// Month is the start of the month in UTC
+	Month time.Time
+}
+
+// EventPartitionRepository encapsulates the monthly partitions of the work
+// item events
+type EventPartitionRepository interface {
+	List(ctx context.Context) ([]EventPartition, error)
+	Ensure(ctx context.Context, from time.Time, months int) error
+	Prune(ctx context.Context, before time.Time) (int64, error)
+}
+
+// NewEventPartitionRepository creates a new storage type.
+func NewEventPartitionRepository(db *gorm.DB) EventPartitionRepository {
+	return &GormEventPartitionRepository{db: db}
+}
+
+// GormEventPartitionRepository is the implementation of the storage interface
+// for the partitions of the work item events.
+type GormEventPartitionRepository struct {
+	db *gorm.DB
+}
+
+// List returns the partitions of the work item events, oldest first
+// returns InternalError
+func (m *GormEventPartitionRepository) List(ctx context.Context) ([]EventPartition, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "eventpartition", "list"}, time.Now())
+
+	var names []string
+	err := m.db.Raw(`SELECT c.relname FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = ?::regclass ORDER BY c.relname`, Event{}.TableName()).Pluck("relname", &names).Error
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	res := make([]EventPartition, 0, len(names))
+	for _, name := range names {
+		month, err := time.Parse(eventPartitionLayout, name)
+		if err != nil {
+			// not one of ours
+			continue
+		}
+		res = append(res, EventPartition{Name: name, Month: month})
+	}
+	return res, nil
+}
+
+// Ensure creates the missing partitions of the month of from and of the
+// given number of months after it. Events of months without partition
+// create theirs when they are inserted, creating them in advance keeps the
+// DDL away from the inserting transactions.
+// returns InternalError
+func (m *GormEventPartitionRepository) Ensure(ctx context.Context, from time.Time, months int) error {
+	defer goa.MeasureSince([]string{"goa", "db", "eventpartition", "ensure"}, time.Now())
+
+	from = from.UTC()
+	month := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i <= months; i++ {
+		if err := m.db.Exec("SELECT create_work_item_events_partition(?)", month.AddDate(0, i, 0)).Error; err != nil {
+			return errors.NewInternalError(err.Error())
+		}
+	}
+	return nil
+}
+
+// Prune deletes the events of the partitions ending before the given time
+// that aren't the latest event of their work item, so the work items can
+// still be rebuilt from their events. Partitions left empty are dropped.
+// Returns the number of deleted events.
+// returns InternalError
+func (m *GormEventPartitionRepository) Prune(ctx context.Context, before time.Time) (int64, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "eventpartition", "prune"}, time.Now())
+
+	partitions, err := m.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	for _, p := range partitions {
+		if p.Month.AddDate(0, 1, 0).After(before) {
+			break
+		}
+		tx := m.db.Exec(fmt.Sprintf(`DELETE FROM %s e WHERE EXISTS (SELECT 1 FROM work_item_events l
+			WHERE l.work_item_id = e.work_item_id AND l.sequence > e.sequence)`, p.Name))
+		if tx.Error != nil {
+			return n, errors.NewInternalError(tx.Error.Error())
+		}
+		n += tx.RowsAffected
+		var left []uint64
+		if err := m.db.Raw(fmt.Sprintf("SELECT work_item_id FROM %s LIMIT 1", p.Name)).Pluck("work_item_id", &left).Error; err != nil {
+			return n, errors.NewInternalError(err.Error())
+		}
+		if len(left) == 0 {
+			if err := m.db.Exec(fmt.Sprintf("DROP TABLE %s", p.Name)).Error; err != nil {
+				return n, errors.NewInternalError(err.Error())
+			}
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,83 @@
+package workitem_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/context"
+)
+
+type eventPartitionRepoBlackBoxTest struct {
+	gormsupport.DBTestSuite
+	clean func()
+}
+
+func TestRunEventPartitionRepoBlackBoxTest(t *testing.T) {
+	suite.Run(t, &eventPartitionRepoBlackBoxTest{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *eventPartitionRepoBlackBoxTest) SetupTest() {
+	s.clean = gormsupport.DeleteCreatedEntities(s.DB)
+}
+
+func (s *eventPartitionRepoBlackBoxTest) TearDownTest() {
+	s.clean()
+}
+
+func (s *eventPartitionRepoBlackBoxTest) hasPartition(name string) bool {
+	partitions, err := workitem.NewEventPartitionRepository(s.DB).List(context.Background())
+	require.Nil(s.T(), err)
+	for _, p := range partitions {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *eventPartitionRepoBlackBoxTest) TestPartitionAndPrune() {
+	t := s.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := workitem.NewEventPartitionRepository(s.DB)
+	old := time.Date(2001, time.January, 15, 0, 0, 0, 0, time.UTC)
+	require.Nil(t, repo.Ensure(ctx, old, 0))
+	assert.True(t, s.hasPartition("work_item_events_y2001m01"))
+
+	wir := workitem.NewWorkItemRepository(s.DB)
+	fields := map[string]interface{}{workitem.SystemTitle: "history", workitem.SystemState: workitem.SystemStateNew}
+	superseded, err := wir.Create(ctx, workitem.SystemBug, fields, "xx")
+	require.Nil(t, err)
+	kept, err := wir.Create(ctx, workitem.SystemBug, fields, "xx")
+	require.Nil(t, err)
+	var inParent int
+	require.Nil(t, s.DB.Raw("SELECT count(*) FROM ONLY work_item_events").Row().Scan(&inParent))
+	assert.Equal(t, 0, inParent)
+
+	// an old event superseded by the create event and the only event of kept
+	insert := "INSERT INTO work_item_events (sequence, created_at, kind, work_item_id, version, type, fields) VALUES (?, ?, 'create', ?, 0, ?, '{}')"
+	require.Nil(t, s.DB.Exec(insert, 0, old, superseded.ID, workitem.SystemBug).Error)
+	require.Nil(t, s.DB.Exec("DELETE FROM work_item_events WHERE work_item_id = ?", kept.ID).Error)
+	require.Nil(t, s.DB.Exec(insert, 1, old, kept.ID, workitem.SystemBug).Error)
+
+	n, err := repo.Prune(ctx, old.AddDate(0, 1, 0))
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), n)
+	assert.True(t, s.hasPartition("work_item_events_y2001m01"))
+	events, err := workitem.NewEventRepository(s.DB).List(ctx, kept.ID)
+	require.Nil(t, err)
+	assert.Len(t, events, 1)
+
+	require.Nil(t, s.DB.Exec("DELETE FROM work_item_events WHERE work_item_id = ?", kept.ID).Error)
+	n, err = repo.Prune(ctx, old.AddDate(0, 1, 0))
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), n)
+	assert.False(t, s.hasPartition("work_item_events_y2001m01"))
+}
@@ -2,6 +2,7 @@ package workitem
 
 import (
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
 
@@ -12,6 +13,34 @@ const (
 	jsonAnnotation = "JSON"
 )
 
+// ArchivedField is the column criteria match archived work items by, work
+// items are listed regardless of it only if the criteria reference it
+const ArchivedField = "Archived"
+
+// UpdatedAtField is the column criteria compare the time of the last change
+// of work items with
+const UpdatedAtField = "updated_at"
+
+// WithArchived extends the expression to match archived work items as well
+func WithArchived(exp criteria.Expression) criteria.Expression {
+	return criteria.And(exp, criteria.Or(
+		criteria.Equals(criteria.Field(ArchivedField), criteria.Literal(true)),
+		criteria.Equals(criteria.Field(ArchivedField), criteria.Literal(false))))
+}
+
+// referencesField returns true if the expression matches on the given field
+func referencesField(exp criteria.Expression, fieldName string) bool {
+	found := false
+	criteria.IteratePostOrder(exp, func(exp criteria.Expression) bool {
+		if f, ok := exp.(*criteria.FieldExpression); ok && f.FieldName == fieldName {
+			found = true
+			return false
+		}
+		return true
+	})
+	return found
+}
+
 // Compile takes an expression and compiles it to a where clause for use with gorm.DB.Where()
 // Returns the number of expected parameters for the query and a slice of errors if something goes wrong
 func Compile(where criteria.Expression) (whereClause string, parameters []interface{}, err []error) {
@@ -19,7 +48,10 @@ func Compile(where criteria.Expression) (whereClause string, parameters []interf
 
 	compiler := newExpressionCompiler()
 	compiled := where.Accept(&compiler)
-
+	if compiled == nil {
+		// the expression could not be compiled, errors have been accumulated
+		return "", compiler.parameters, compiler.err
+	}
 	return compiled.(string), compiler.parameters, compiler.err
 }
 
@@ -34,6 +66,10 @@ func bubbleUpJSONContext(exp criteria.Expression) bool {
 		if t.Left().Annotation(jsonAnnotation) == true || t.Right().Annotation(jsonAnnotation) == true {
 			t.SetAnnotation(jsonAnnotation, true)
 		}
+	case *criteria.InExpression:
+		if t.Left().Annotation(jsonAnnotation) == true {
+			t.SetAnnotation(jsonAnnotation, true)
+		}
 	}
 	return true
 }
@@ -41,7 +77,7 @@ func bubbleUpJSONContext(exp criteria.Expression) bool {
 // does the field name reference a json field or a column?
 func isJSONField(fieldName string) bool {
 	switch fieldName {
-	case "ID", "Type", "Version":
+	case "ID", "Type", "Version", ArchivedField, UpdatedAtField:
 		return false
 	}
 	return true
@@ -98,6 +134,67 @@ func (c *expressionCompiler) Equals(e *criteria.EqualsExpression) interface{} {
 	return c.binary(e, "=")
 }
 
+func (c *expressionCompiler) LessThan(e *criteria.LessThanExpression) interface{} {
+	return c.comparison(e, "<")
+}
+
+func (c *expressionCompiler) GreaterOrEqual(e *criteria.GreaterOrEqualExpression) interface{} {
+	return c.comparison(e, ">=")
+}
+
+// comparison compiles an ordering operator, the values of JSON fields don't
+// have an order the database knows
+func (c *expressionCompiler) comparison(e criteria.BinaryExpression, op string) interface{} {
+	if e.Left().Annotation(jsonAnnotation) == true || e.Right().Annotation(jsonAnnotation) == true {
+		c.err = append(c.err, fmt.Errorf("%s is only supported on columns", op))
+		return nil
+	}
+	return c.binary(e, op)
+}
+
+// In compiles the membership in a list of values, JSON fields match if they
+// contain any of the values
+func (c *expressionCompiler) In(e *criteria.InExpression) interface{} {
+	if e.Annotation(jsonAnnotation) == true {
+		return c.jsonIn(e)
+	}
+	left := e.Left().Accept(c)
+	right := e.Right().Accept(c)
+	if left != nil && right != nil {
+		return "(" + left.(string) + " in (" + right.(string) + "))"
+	}
+	return nil
+}
+
+// jsonIn compiles the membership of a JSON field to the containment of any of
+// the values listed by the literal on the right
+func (c *expressionCompiler) jsonIn(e *criteria.InExpression) interface{} {
+	literal, ok := e.Right().(*criteria.LiteralExpression)
+	if !ok {
+		c.err = append(c.err, fmt.Errorf("in on JSON fields is only supported with a literal list of values"))
+		return nil
+	}
+	values := reflect.ValueOf(literal.Value)
+	if values.Kind() != reflect.Slice || values.Len() == 0 {
+		c.err = append(c.err, fmt.Errorf("in on JSON fields is only supported with a non-empty list of values, not %v", literal.Value))
+		return nil
+	}
+	left := e.Left().Accept(c)
+	if left == nil {
+		return nil
+	}
+	matches := make([]string, values.Len())
+	for i := range matches {
+		value, err := c.convertToString(values.Index(i).Interface())
+		if err != nil {
+			c.err = append(c.err, err)
+			return nil
+		}
+		matches[i] = "(" + left.(string) + " : " + value + "}')"
+	}
+	return "(" + strings.Join(matches, " or ") + ")"
+}
+
 func (c *expressionCompiler) Parameter(v *criteria.ParameterExpression) interface{} {
 	c.err = append(c.err, fmt.Errorf("Parameter expression not supported"))
 	return nil
@@ -27,6 +27,24 @@ func TestAndOr(t *testing.T) {
 	expect(t, Or(Equals(Field("foo"), Literal("abcd")), Equals(Literal(true), Literal(false))), "((Fields@>'{\"foo\" : \"abcd\"}') or (? = ?))", []interface{}{true, false})
 }
 
+func TestComparison(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expect(t, And(GreaterOrEqual(Field(UpdatedAtField), Literal(1)), LessThan(Field(UpdatedAtField), Literal(2))),
+		"((updated_at >= ?) and (updated_at < ?))", []interface{}{1, 2})
+	_, _, err := Compile(LessThan(Field("foo"), Literal(23)))
+	assert.NotEmpty(t, err)
+}
+
+func TestIn(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expect(t, In(Field("ID"), Literal([]uint64{1, 2})), "(ID in (?))", []interface{}{[]uint64{1, 2}})
+	expect(t, In(Field("foo"), Literal([]string{"a", "b"})), "((Fields@>'{\"foo\" : \"a\"}') or (Fields@>'{\"foo\" : \"b\"}'))", []interface{}{})
+	_, _, err := Compile(In(Field("foo"), Literal("a")))
+	assert.NotEmpty(t, err)
+}
+
 func expect(t *testing.T, expr Expression, expectedClause string, expectedParameters []interface{}) {
 	clause, parameters, err := Compile(expr)
 	if len(err) > 0 {
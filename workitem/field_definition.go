@@ -6,6 +6,7 @@ import (
 	"reflect"
 
 	"github.com/almighty/almighty-core/convert"
+	"github.com/almighty/almighty-core/errors"
 )
 
 // constants for describing possible field types
@@ -15,20 +16,32 @@ const (
 	KindFloat             Kind = "float"
 	KindInstant           Kind = "instant"
 	KindDuration          Kind = "duration"
+	KindBoolean           Kind = "boolean"
 	KindURL               Kind = "url"
 	KindIteration         Kind = "iteration"
+	KindRelease           Kind = "release"
+	KindProject           Kind = "project"
 	KindWorkitemReference Kind = "workitem"
 	KindUser              Kind = "user"
 	KindEnum              Kind = "enum"
 	KindList              Kind = "list"
+	// KindChecklist values are lists of items with a text that are done or
+	// not, see ChecklistItem
+	KindChecklist Kind = "checklist"
 )
 
+// Kinds lists the kinds of field types
+var Kinds = []Kind{
+	KindString, KindInteger, KindFloat, KindInstant, KindDuration, KindBoolean, KindURL,
+	KindIteration, KindRelease, KindProject, KindWorkitemReference, KindUser, KindEnum, KindList, KindChecklist,
+}
+
 // Kind is the kind of field type
 type Kind string
 
 // FieldType describes the possible values of a FieldDefinition
-func (k Kind) isSimpleType() bool {
-	return k != KindEnum && k != KindList
+func (k Kind) IsSimpleType() bool {
+	return k != KindEnum && k != KindList && k != KindChecklist
 }
 
 // FieldType describes the possible values of a FieldDefinition
@@ -46,6 +59,16 @@ type FieldType interface {
 type FieldDefinition struct {
 	Required bool
 	Type     FieldType
+	// Roles restricts reading and writing the field to viewers with one of
+	// the given roles, everybody can read and write the field if empty
+	Roles []string `json:",omitempty"`
+	// Encrypted fields are stored encrypted with the default keyring
+	Encrypted bool `json:",omitempty"`
+	// Deprecated fields can't be set on new work items, the values of
+	// existing ones are still read
+	Deprecated bool `json:",omitempty"`
+	// ReplacedBy is the field the values of the deprecated field move to
+	ReplacedBy string `json:",omitempty"`
 }
 
 // Ensure FieldDefinition implements the Equaler interface
@@ -61,6 +84,15 @@ func (self FieldDefinition) Equal(u convert.Equaler) bool {
 	if self.Required != other.Required {
 		return false
 	}
+	if !equalRoles(self.Roles, other.Roles) {
+		return false
+	}
+	if self.Encrypted != other.Encrypted {
+		return false
+	}
+	if self.Deprecated != other.Deprecated || self.ReplacedBy != other.ReplacedBy {
+		return false
+	}
 	return self.Type.Equal(other.Type)
 }
 
@@ -81,8 +113,12 @@ func (f FieldDefinition) ConvertFromModel(name string, value interface{}) (inter
 }
 
 type rawFieldDef struct {
-	Required bool
-	Type     *json.RawMessage
+	Required   bool
+	Type       *json.RawMessage
+	Roles      []string
+	Encrypted  bool
+	Deprecated bool   `json:",omitempty"`
+	ReplacedBy string `json:",omitempty"`
 }
 
 // Ensure rawFieldDef implements the Equaler interface
@@ -98,6 +134,15 @@ func (self rawFieldDef) Equal(u convert.Equaler) bool {
 	if self.Required != other.Required {
 		return false
 	}
+	if !equalRoles(self.Roles, other.Roles) {
+		return false
+	}
+	if self.Encrypted != other.Encrypted {
+		return false
+	}
+	if self.Deprecated != other.Deprecated || self.ReplacedBy != other.ReplacedBy {
+		return false
+	}
 	if self.Type == nil && other.Type == nil {
 		return true
 	}
@@ -130,21 +175,58 @@ func (f *FieldDefinition) UnmarshalJSON(bytes []byte) error {
 		if err != nil {
 			return err
 		}
-		*f = FieldDefinition{Type: theType, Required: temp.Required}
+		*f = FieldDefinition{Type: theType, Required: temp.Required, Roles: temp.Roles, Encrypted: temp.Encrypted, Deprecated: temp.Deprecated, ReplacedBy: temp.ReplacedBy}
 	case KindEnum:
 		theType := EnumType{}
 		err = json.Unmarshal(*temp.Type, &theType)
 		if err != nil {
 			return err
 		}
-		*f = FieldDefinition{Type: theType, Required: temp.Required}
+		*f = FieldDefinition{Type: theType, Required: temp.Required, Roles: temp.Roles, Encrypted: temp.Encrypted, Deprecated: temp.Deprecated, ReplacedBy: temp.ReplacedBy}
 	default:
 		theType := SimpleType{}
 		err = json.Unmarshal(*temp.Type, &theType)
 		if err != nil {
 			return err
 		}
-		*f = FieldDefinition{Type: theType, Required: temp.Required}
+		*f = FieldDefinition{Type: theType, Required: temp.Required, Roles: temp.Roles, Encrypted: temp.Encrypted, Deprecated: temp.Deprecated, ReplacedBy: temp.ReplacedBy}
 	}
 	return nil
 }
+
+// validateDeprecations returns BadParameterError if a deprecated field is
+// required or isn't replaced by another field of the given ones that isn't
+// deprecated itself
+func validateDeprecations(fields map[string]FieldDefinition) error {
+	for name, def := range fields {
+		if def.ReplacedBy != "" && !def.Deprecated {
+			return errors.NewBadParameterError(name, def.ReplacedBy).Expected("no replacement for a field that isn't deprecated")
+		}
+		if !def.Deprecated {
+			continue
+		}
+		if def.Required {
+			return errors.NewBadParameterError(name, "required").Expected("a deprecated field that isn't required")
+		}
+		if def.ReplacedBy == "" {
+			continue
+		}
+		replacement, ok := fields[def.ReplacedBy]
+		if !ok || def.ReplacedBy == name || replacement.Deprecated {
+			return errors.NewBadParameterError(name, def.ReplacedBy).Expected("a field of the type that isn't deprecated")
+		}
+	}
+	return nil
+}
+
+func equalRoles(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -34,3 +34,27 @@ func TestListFieldDefMarshalling(t *testing.T) {
 		t.Errorf("field should be %v, but is %v", def, unmarshalled)
 	}
 }
+
+func TestRestrictedFieldDefMarshalling(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	def := FieldDefinition{
+		Type:  SimpleType{Kind: KindString},
+		Roles: []string{RoleProjectAdmin},
+	}
+	bytes, err := json.Marshal(def)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+
+	unmarshalled := FieldDefinition{}
+	json.Unmarshal(bytes, &unmarshalled)
+
+	if !def.Equal(unmarshalled) {
+		t.Errorf("field should be %v, but is %v", def, unmarshalled)
+	}
+	if def.Equal(FieldDefinition{Type: SimpleType{Kind: KindString}}) {
+		t.Errorf("fields with different roles should not be equal")
+	}
+}
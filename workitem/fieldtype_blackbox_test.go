@@ -10,9 +10,12 @@ import (
 var (
 	stString    = SimpleType{Kind: KindString}
 	stIteration = SimpleType{Kind: KindIteration}
+	stRelease   = SimpleType{Kind: KindRelease}
+	stProject   = SimpleType{Kind: KindProject}
 	stInt       = SimpleType{Kind: KindInteger}
 	stFloat     = SimpleType{Kind: KindFloat}
 	stDuration  = SimpleType{Kind: KindDuration}
+	stBoolean   = SimpleType{Kind: KindBoolean}
 	stURL       = SimpleType{Kind: KindURL}
 	stList      = SimpleType{Kind: KindList}
 )
@@ -40,6 +43,12 @@ func TestSimpleTypeConversion(t *testing.T) {
 		{stIteration, 1.9, nil, true},
 		{stIteration, true, nil, true},
 
+		{stRelease, "6c5610be-30b2-4880-9fec-81e4f8e4fd76", "6c5610be-30b2-4880-9fec-81e4f8e4fd76", false},
+		{stRelease, 1, nil, true},
+
+		{stProject, "0d1f3a2e-6c4b-4c1c-9c0e-2f8a2e6f1b7d", "0d1f3a2e-6c4b-4c1c-9c0e-2f8a2e6f1b7d", false},
+		{stProject, 1, nil, true},
+
 		{stInt, 100.0, nil, true},
 		{stInt, 100, 100, false},
 		{stInt, "100", nil, true},
@@ -53,6 +62,10 @@ func TestSimpleTypeConversion(t *testing.T) {
 		{stDuration, 1.1, nil, true},
 		{stDuration, "duration", nil, true},
 
+		{stBoolean, true, true, false},
+		{stBoolean, "true", nil, true},
+		{stBoolean, 1, nil, true},
+
 		{stURL, "http://www.google.com", "http://www.google.com", false},
 		{stURL, "", nil, true},
 		{stURL, "google", nil, true},
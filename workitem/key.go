@@ -0,0 +1,90 @@
+package workitem
+
+import (
+	"database/sql"
+	"regexp"
+	"strconv"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/redirect"
+	"github.com/jinzhu/gorm"
+)
+
+// SystemKey is set on work items of projects with a key, it holds the
+// human-friendly key of the work item, e.g. ALM-482. It is not a field of the
+// work item types and can't be changed by updates, it only changes when the
+// work item moves to another project.
+const SystemKey = "system.key"
+
+// KeyPattern matches the keys of work items: the key of their project and a
+// sequence number within it
+var KeyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9]{1,9}-[1-9][0-9]*$`)
+
+// IsKey returns true if the given work item reference is a key and not a
+// numeric ID
+func IsKey(ref string) bool {
+	return KeyPattern.MatchString(ref)
+}
+
+// ResolveKey returns the numeric ID of the work item with the given key,
+// numeric IDs are returned as they are
+// returns NotFoundError or InternalError
+func ResolveKey(db *gorm.DB, ref string) (string, error) {
+	if !IsKey(ref) {
+		return ref, nil
+	}
+	var id uint64
+	err := db.Model(&WorkItem{}).Where("key = ?", ref).Select("id").Row().Scan(&id)
+	if err == sql.ErrNoRows {
+		return "", errors.NewNotFoundError("work item", ref)
+	}
+	if err != nil {
+		return "", errors.NewInternalError(err.Error())
+	}
+	return strconv.FormatUint(id, 10), nil
+}
+
+// assignKey gives the created work item with the given ID the next key of its
+// project. The sequence of the project is increased in the same statement, so
+// concurrent creations wait for each other and never share a key. Work items
+// of projects without a key don't get one.
+// returns InternalError
+func assignKey(db *gorm.DB, wi *WorkItem) error {
+	projectID, ok := wi.Fields[SystemProject].(string)
+	if !ok || projectID == "" {
+		return nil
+	}
+	var key string
+	err := db.Raw(`WITH p AS (
+			UPDATE projects SET key_sequence = key_sequence + 1
+			WHERE id::text = ? AND key IS NOT NULL AND deleted_at IS NULL
+			RETURNING key, key_sequence)
+		UPDATE work_items SET key = p.key || '-' || p.key_sequence FROM p
+		WHERE work_items.id = ?
+		RETURNING work_items.key`, projectID, wi.ID).Row().Scan(&key)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	wi.Key = &key
+	return nil
+}
+
+// moveKey gives the work item moved to another project the next key of that
+// project and redirects its previous key to it. Work items moved to projects
+// without a key keep theirs.
+// returns BadParameterError or InternalError
+func moveKey(ctx context.Context, db *gorm.DB, wi *WorkItem) error {
+	previous := wi.Key
+	if err := assignKey(db, wi); err != nil {
+		return err
+	}
+	if previous == nil || wi.Key == nil || *previous == *wi.Key {
+		return nil
+	}
+	return redirect.NewRedirectRepository(db).Add(ctx, redirect.KindWorkItem, *previous, strconv.FormatUint(wi.ID, 10))
+}
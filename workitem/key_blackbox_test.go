@@ -0,0 +1,103 @@
+package workitem_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/context"
+)
+
+func TestIsKey(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	for _, ref := range []string{"ALM-482", "A1-1", "ABCDEFGHIJ-10"} {
+		assert.True(t, workitem.IsKey(ref), ref)
+	}
+	for _, ref := range []string{"482", "alm-482", "A-1", "ALM-0", "ALM-", "ABCDEFGHIJK-1", "ALM-482x"} {
+		assert.False(t, workitem.IsKey(ref), ref)
+	}
+}
+
+type keyRepoBlackBoxTest struct {
+	gormsupport.DBTestSuite
+	clean func()
+}
+
+func TestRunKeyRepoBlackBoxTest(t *testing.T) {
+	suite.Run(t, &keyRepoBlackBoxTest{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *keyRepoBlackBoxTest) SetupTest() {
+	s.clean = gormsupport.DeleteCreatedEntities(s.DB)
+}
+
+func (s *keyRepoBlackBoxTest) TearDownTest() {
+	s.clean()
+}
+
+func (s *keyRepoBlackBoxTest) create(p *project.Project, title string) *workitem.WorkItem {
+	wi, err := workitem.NewWorkItemRepository(s.DB).Create(context.Background(), workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle:   title,
+		workitem.SystemState:   workitem.SystemStateNew,
+		workitem.SystemProject: p.ID.String(),
+	}, "xx")
+	require.Nil(s.T(), err)
+	res, err := workitem.NewWorkItemRepository(s.DB).LoadFromDB(wi.ID)
+	require.Nil(s.T(), err)
+	return res
+}
+
+func (s *keyRepoBlackBoxTest) TestKeys() {
+	t := s.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	projects := project.NewRepository(s.DB)
+	p, err := projects.Create(ctx, "keys-"+uuid.NewV4().String())
+	require.Nil(t, err)
+	before := s.create(p, "before")
+	assert.Nil(t, before.Key)
+
+	_, err = projects.SetKey(ctx, p.ID, "lower")
+	assert.IsType(t, errors.BadParameterError{}, err)
+	key := "K" + strings.ToUpper(uuid.NewV4().String()[:6])
+	p, err = projects.SetKey(ctx, p.ID, key)
+	require.Nil(t, err)
+	require.NotNil(t, p.Key)
+	assert.Equal(t, key, *p.Key)
+	_, err = projects.SetKey(ctx, p.ID, key)
+	assert.Nil(t, err)
+	_, err = projects.SetKey(ctx, p.ID, "OTHER")
+	assert.IsType(t, errors.BadParameterError{}, err)
+
+	// the existing work item is numbered first, new ones continue the sequence
+	before, err = workitem.NewWorkItemRepository(s.DB).LoadFromDB(strconv.FormatUint(before.ID, 10))
+	require.Nil(t, err)
+	require.NotNil(t, before.Key)
+	assert.Equal(t, key+"-1", *before.Key)
+	after := s.create(p, "after")
+	require.NotNil(t, after.Key)
+	assert.Equal(t, key+"-2", *after.Key)
+
+	wi, err := workitem.NewWorkItemRepository(s.DB).Load(ctx, key+"-2")
+	require.Nil(t, err)
+	assert.Equal(t, strconv.FormatUint(after.ID, 10), wi.ID)
+	assert.Equal(t, key+"-2", wi.Fields[workitem.SystemKey])
+	_, err = workitem.NewWorkItemRepository(s.DB).Load(ctx, key+"-3")
+	assert.IsType(t, errors.NotFoundError{}, err)
+
+	// work items of projects without key don't get one
+	other, err := projects.Create(ctx, "keys-"+uuid.NewV4().String())
+	require.Nil(t, err)
+	assert.Nil(t, s.create(other, "none").Key)
+}
@@ -0,0 +1,63 @@
+package link
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/almighty/almighty-core/errors"
+)
+
+// Limits of the annotations of links
+const (
+	MaxNoteLength        = 500
+	MaxMetadataEntries   = 20
+	MaxMetadataKeyLength = 64
+	MaxMetadataValueLen  = 256
+)
+
+// Metadata are key/value pairs annotating a link, like the milestone a
+// "blocks" link lasts until
+type Metadata map[string]string
+
+// Value implements the driver.Valuer interface
+func (m Metadata) Value() (driver.Value, error) {
+	if m == nil {
+		m = Metadata{}
+	}
+	return json.Marshal(m)
+}
+
+// Scan implements the sql.Scanner interface
+func (m *Metadata) Scan(src interface{}) error {
+	if src == nil {
+		*m = Metadata{}
+		return nil
+	}
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("scan source was not []byte")
+	}
+	return json.Unmarshal(b, m)
+}
+
+// ValidateAnnotation checks the lengths of the note and the metadata of the
+// link
+// returns BadParameterError
+func (l WorkItemLink) ValidateAnnotation() error {
+	if len(l.Note) > MaxNoteLength {
+		return errors.NewBadParameterError("data.attributes.note", len(l.Note)).Expected(fmt.Sprintf("at most %d characters", MaxNoteLength))
+	}
+	if len(l.Metadata) > MaxMetadataEntries {
+		return errors.NewBadParameterError("data.attributes.metadata", len(l.Metadata)).Expected(fmt.Sprintf("at most %d entries", MaxMetadataEntries))
+	}
+	for k, v := range l.Metadata {
+		if k == "" || len(k) > MaxMetadataKeyLength {
+			return errors.NewBadParameterError("data.attributes.metadata", k).Expected(fmt.Sprintf("keys of 1 to %d characters", MaxMetadataKeyLength))
+		}
+		if len(v) > MaxMetadataValueLen {
+			return errors.NewBadParameterError("data.attributes.metadata."+k, len(v)).Expected(fmt.Sprintf("at most %d characters", MaxMetadataValueLen))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package link_test
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem/link"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAnnotation(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	l := link.WorkItemLink{Note: "blocks until API freeze", Metadata: link.Metadata{"milestone": "API freeze"}}
+	assert.Nil(t, l.ValidateAnnotation())
+
+	l = link.WorkItemLink{Note: strings.Repeat("a", link.MaxNoteLength+1)}
+	assert.IsType(t, errors.BadParameterError{}, l.ValidateAnnotation())
+
+	l = link.WorkItemLink{Metadata: link.Metadata{"": "empty key"}}
+	assert.IsType(t, errors.BadParameterError{}, l.ValidateAnnotation())
+
+	l = link.WorkItemLink{Metadata: link.Metadata{"k": strings.Repeat("v", link.MaxMetadataValueLen+1)}}
+	assert.IsType(t, errors.BadParameterError{}, l.ValidateAnnotation())
+}
+
+func (test *TestLinkRepository) TestSaveAnnotation() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := link.NewWorkItemLinkRepository(test.DB)
+	l, err := repo.Create(ctx, test.createBug(), test.createBug(), test.createLinkType(""))
+	require.Nil(t, err)
+	assert.Equal(t, "", *l.Data.Attributes.Note)
+
+	note := "blocks until API freeze"
+	l.Data.Attributes.Note = &note
+	l.Data.Attributes.Metadata = map[string]string{"milestone": "API freeze"}
+	l, err = repo.Save(ctx, *l)
+	require.Nil(t, err)
+
+	// listings show the annotations
+	list, err := repo.List(ctx)
+	require.Nil(t, err)
+	found := false
+	for _, item := range list.Data {
+		if *item.ID == *l.Data.ID {
+			found = true
+			assert.Equal(t, note, *item.Attributes.Note)
+			assert.Equal(t, map[string]string{"milestone": "API freeze"}, item.Attributes.Metadata)
+		}
+	}
+	assert.True(t, found)
+
+	// the metadata are replaced as a whole
+	l.Data.Attributes.Metadata = map[string]string{"until": "2.0"}
+	l, err = repo.Save(ctx, *l)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{"until": "2.0"}, l.Data.Attributes.Metadata)
+	assert.Equal(t, note, *l.Data.Attributes.Note)
+
+	tooLong := strings.Repeat("a", link.MaxNoteLength+1)
+	l.Data.Attributes.Note = &tooLong
+	_, err = repo.Save(ctx, *l)
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
@@ -0,0 +1,46 @@
+package link_test
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem/link"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (test *TestLinkRepository) TestBetween() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := link.NewWorkItemLinkRepository(test.DB)
+	parentType, otherType := test.createLinkType(""), test.createLinkType("")
+	a, b, c := test.createBug(), test.createBug(), test.createBug()
+	_, err := repo.Create(ctx, a, b, parentType)
+	require.Nil(t, err)
+	_, err = repo.Create(ctx, b, c, parentType)
+	require.Nil(t, err)
+	_, err = repo.Create(ctx, a, c, otherType)
+	require.Nil(t, err)
+
+	links, err := repo.Between(ctx, []uint64{a, b, c}, nil)
+	require.Nil(t, err)
+	assert.Len(t, links, 3)
+
+	// links to work items outside are left out
+	links, err = repo.Between(ctx, []uint64{a, b}, nil)
+	require.Nil(t, err)
+	require.Len(t, links, 1)
+	assert.Equal(t, a, links[0].SourceID)
+	assert.Equal(t, b, links[0].TargetID)
+
+	links, err = repo.Between(ctx, []uint64{a, b, c}, &otherType)
+	require.Nil(t, err)
+	require.Len(t, links, 1)
+	assert.Equal(t, c, links[0].TargetID)
+
+	links, err = repo.Between(ctx, nil, nil)
+	require.Nil(t, err)
+	assert.Empty(t, links)
+}
@@ -0,0 +1,54 @@
+package link
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	satoriuuid "github.com/satori/go.uuid"
+)
+
+// Filter selects the links deleted in bulk, the links must match all fields
+// that are set
+type Filter struct {
+	LinkTypeID *satoriuuid.UUID
+	SourceID   *uint64
+	// CreatedBefore matches the links created before the time
+	CreatedBefore *time.Time
+}
+
+// DeleteMatching deletes the links matching the filter and returns how many
+// it deleted; a dry run only counts them. Like RepairDangling it skips the
+// checks of Delete, it is meant for admins e.g. retiring a link type.
+// returns BadParameterError if no field of the filter is set or InternalError
+func (r *GormWorkItemLinkRepository) DeleteMatching(ctx context.Context, f Filter, dryRun bool) (int, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitemlink", "deletematching"}, time.Now())
+
+	if f.LinkTypeID == nil && f.SourceID == nil && f.CreatedBefore == nil {
+		return 0, errors.NewBadParameterError("filter", nil).Expected("a link type, a source or a creation date")
+	}
+	db := r.db.Model(&WorkItemLink{})
+	if f.LinkTypeID != nil {
+		db = db.Where("link_type_id = ?", *f.LinkTypeID)
+	}
+	if f.SourceID != nil {
+		db = db.Where("source_id = ?", *f.SourceID)
+	}
+	if f.CreatedBefore != nil {
+		db = db.Where("created_at < ?", *f.CreatedBefore)
+	}
+	if dryRun {
+		var count int
+		if err := db.Count(&count).Error; err != nil {
+			return 0, errors.NewInternalError(err.Error())
+		}
+		return count, nil
+	}
+	tx := db.Delete(&WorkItemLink{})
+	if tx.Error != nil {
+		return 0, errors.NewInternalError(tx.Error.Error())
+	}
+	return int(tx.RowsAffected), nil
+}
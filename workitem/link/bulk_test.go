@@ -0,0 +1,54 @@
+package link_test
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem/link"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (test *TestLinkRepository) TestDeleteMatching() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := link.NewWorkItemLinkRepository(test.DB)
+	retired, kept := test.createLinkType(""), test.createLinkType("")
+	a, b, c := test.createBug(), test.createBug(), test.createBug()
+	_, err := repo.Create(ctx, a, b, retired)
+	require.Nil(t, err)
+	_, err = repo.Create(ctx, b, c, retired)
+	require.Nil(t, err)
+	other, err := repo.Create(ctx, a, c, kept)
+	require.Nil(t, err)
+
+	// an empty filter would delete everything
+	_, err = repo.DeleteMatching(ctx, link.Filter{}, false)
+	assert.IsType(t, errors.BadParameterError{}, err)
+
+	// dry runs only count
+	count, err := repo.DeleteMatching(ctx, link.Filter{LinkTypeID: &retired}, true)
+	require.Nil(t, err)
+	assert.Equal(t, 2, count)
+	count, err = repo.DeleteMatching(ctx, link.Filter{LinkTypeID: &retired, SourceID: &a}, true)
+	require.Nil(t, err)
+	assert.Equal(t, 1, count)
+	past := time.Now().Add(-time.Hour)
+	count, err = repo.DeleteMatching(ctx, link.Filter{CreatedBefore: &past}, true)
+	require.Nil(t, err)
+	assert.Equal(t, 0, count)
+
+	count, err = repo.DeleteMatching(ctx, link.Filter{LinkTypeID: &retired}, false)
+	require.Nil(t, err)
+	assert.Equal(t, 2, count)
+	count, err = repo.DeleteMatching(ctx, link.Filter{LinkTypeID: &retired}, true)
+	require.Nil(t, err)
+	assert.Equal(t, 0, count)
+	_, err = repo.Load(ctx, *other.Data.ID)
+	assert.Nil(t, err)
+}
@@ -1,7 +1,9 @@
 package link
 
 import (
+	"fmt"
 	"log"
+	"strings"
 
 	"golang.org/x/net/context"
 
@@ -16,7 +18,7 @@ type WorkItemLinkCategoryRepository interface {
 	Create(ctx context.Context, name *string, description *string) (*app.WorkItemLinkCategorySingle, error)
 	Load(ctx context.Context, ID string) (*app.WorkItemLinkCategorySingle, error)
 	List(ctx context.Context) (*app.WorkItemLinkCategoryList, error)
-	Delete(ctx context.Context, ID string) error
+	Delete(ctx context.Context, ID string, reassignTo string) error
 	Save(ctx context.Context, linkCat app.WorkItemLinkCategorySingle) (*app.WorkItemLinkCategorySingle, error)
 }
 
@@ -111,9 +113,11 @@ func (r *GormWorkItemLinkCategoryRepository) List(ctx context.Context) (*app.Wor
 	return &res, nil
 }
 
-// Delete deletes the work item link category with the given id
-// returns NotFoundError or InternalError
-func (r *GormWorkItemLinkCategoryRepository) Delete(ctx context.Context, ID string) error {
+// Delete deletes the work item link category with the given id. The link
+// types of the category are moved to the category reassignTo if it is not
+// empty, otherwise the category must not have any link types.
+// returns NotFoundError, BadParameterError, DataConflictError or InternalError
+func (r *GormWorkItemLinkCategoryRepository) Delete(ctx context.Context, ID string, reassignTo string) error {
 	id, err := satoriuuid.FromString(ID)
 	if err != nil {
 		// treat as not found: clients don't know it must be a UUID
@@ -123,10 +127,31 @@ func (r *GormWorkItemLinkCategoryRepository) Delete(ctx context.Context, ID stri
 	var cat = WorkItemLinkCategory{
 		ID: id,
 	}
+	db := r.db.Where("id = ?", id).First(&cat)
+	if db.RecordNotFound() {
+		return errors.NewNotFoundError("work item link category", id.String())
+	}
+	if db.Error != nil {
+		return errors.NewInternalError(db.Error.Error())
+	}
+
+	var names []string
+	db = r.db.Model(&WorkItemLinkType{}).Where("link_category_id = ?", id).Order("name").Pluck("name", &names)
+	if db.Error != nil {
+		return errors.NewInternalError(db.Error.Error())
+	}
+	if len(names) > 0 {
+		if reassignTo == "" {
+			return errors.NewDataConflictError(fmt.Sprintf("work item link category %s still has the link types %s, reassign them to another category first", id, strings.Join(names, ", ")))
+		}
+		if err := r.reassign(ctx, id, reassignTo); err != nil {
+			return err
+		}
+	}
 
 	log.Printf("work item link category to delete %v\n", cat)
 
-	db := r.db.Delete(&cat)
+	db = r.db.Delete(&cat)
 	if db.Error != nil {
 		return errors.NewInternalError(db.Error.Error())
 	}
@@ -137,6 +162,47 @@ func (r *GormWorkItemLinkCategoryRepository) Delete(ctx context.Context, ID stri
 	return nil
 }
 
+// reassign moves all link types of the category id to the category
+// reassignTo, the caller must run it within the transaction that deletes the
+// category
+func (r *GormWorkItemLinkCategoryRepository) reassign(ctx context.Context, id satoriuuid.UUID, reassignTo string) error {
+	targetID, err := satoriuuid.FromString(reassignTo)
+	if err != nil {
+		return errors.NewBadParameterError("reassignTo", reassignTo).Expected("the ID of a work item link category")
+	}
+	if satoriuuid.Equal(targetID, id) {
+		return errors.NewBadParameterError("reassignTo", reassignTo).Expected("another work item link category")
+	}
+	var target WorkItemLinkCategory
+	db := r.db.Where("id = ?", targetID).First(&target)
+	if db.RecordNotFound() {
+		return errors.NewBadParameterError("reassignTo", reassignTo).Expected("the ID of an existing work item link category")
+	}
+	if db.Error != nil {
+		return errors.NewInternalError(db.Error.Error())
+	}
+	// link type names are unique per category
+	var clashes []string
+	db = r.db.Model(&WorkItemLinkType{}).
+		Where("link_category_id = ? AND name IN (SELECT name FROM work_item_link_types WHERE link_category_id = ? AND deleted_at IS NULL)", targetID, id).
+		Order("name").Pluck("name", &clashes)
+	if db.Error != nil {
+		return errors.NewInternalError(db.Error.Error())
+	}
+	if len(clashes) > 0 {
+		return errors.NewDataConflictError(fmt.Sprintf("work item link category %s already has link types named %s", target.Name, strings.Join(clashes, ", ")))
+	}
+	db = r.db.Model(&WorkItemLinkType{}).Where("link_category_id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"link_category_id": targetID,
+			"version":          gorm.Expr("version + 1"),
+		})
+	if db.Error != nil {
+		return errors.NewInternalError(db.Error.Error())
+	}
+	return nil
+}
+
 // Save updates the given work item link category in storage. Version must be the same as the one int the stored version.
 // returns NotFoundError, VersionConflictError, ConversionError or InternalError
 func (r *GormWorkItemLinkCategoryRepository) Save(ctx context.Context, linkCat app.WorkItemLinkCategorySingle) (*app.WorkItemLinkCategorySingle, error) {
@@ -0,0 +1,170 @@
+package link_test
+
+import (
+	"strconv"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/almighty/almighty-core/workitem/link"
+	satoriuuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (test *TestLinkRepository) TestDeleteDetaches() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	linkTypeID := test.createLinkType("")
+	parent, child := test.createBug(), test.createBug()
+	l, err := link.NewWorkItemLinkRepository(test.DB).Create(ctx, parent, child, linkTypeID)
+	require.Nil(t, err)
+
+	require.Nil(t, workitem.NewWorkItemRepository(test.DB).Delete(ctx, strconv.FormatUint(parent, 10)))
+	_, err = workitem.NewWorkItemRepository(test.DB).Load(ctx, strconv.FormatUint(child, 10))
+	assert.Nil(t, err)
+	_, err = link.NewWorkItemLinkRepository(test.DB).Load(ctx, *l.Data.ID)
+	assert.IsType(t, errors.NotFoundError{}, err)
+}
+
+func (test *TestLinkRepository) TestDeleteCascades() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	linkTypeID := test.createLinkType(link.OnDeleteCascade)
+	repo := link.NewWorkItemLinkRepository(test.DB)
+	parent, child, grandchild := test.createBug(), test.createBug(), test.createBug()
+	_, err := repo.Create(ctx, parent, child, linkTypeID)
+	require.Nil(t, err)
+	_, err = repo.Create(ctx, child, grandchild, linkTypeID)
+	require.Nil(t, err)
+
+	// deleting a target leaves its source
+	require.Nil(t, workitem.NewWorkItemRepository(test.DB).Delete(ctx, strconv.FormatUint(grandchild, 10)))
+	_, err = workitem.NewWorkItemRepository(test.DB).Load(ctx, strconv.FormatUint(child, 10))
+	require.Nil(t, err)
+
+	require.Nil(t, workitem.NewWorkItemRepository(test.DB).Delete(ctx, strconv.FormatUint(parent, 10)))
+	_, err = workitem.NewWorkItemRepository(test.DB).Load(ctx, strconv.FormatUint(child, 10))
+	assert.IsType(t, errors.NotFoundError{}, err)
+}
+
+func (test *TestLinkRepository) TestDeleteBlocked() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	cascadeTypeID := test.createLinkType(link.OnDeleteCascade)
+	blockTypeID := test.createLinkType(link.OnDeleteBlock)
+	repo := link.NewWorkItemLinkRepository(test.DB)
+	parent, child, blocker := test.createBug(), test.createBug(), test.createBug()
+	_, err := repo.Create(ctx, parent, child, cascadeTypeID)
+	require.Nil(t, err)
+	_, err = repo.Create(ctx, blocker, child, blockTypeID)
+	require.Nil(t, err)
+
+	// the block of the child stops the whole cascade
+	err = workitem.NewWorkItemRepository(test.DB).Delete(ctx, strconv.FormatUint(parent, 10))
+	assert.IsType(t, errors.BadParameterError{}, err)
+	for _, id := range []uint64{parent, child, blocker} {
+		_, err = workitem.NewWorkItemRepository(test.DB).Load(ctx, strconv.FormatUint(id, 10))
+		assert.Nil(t, err)
+	}
+	err = workitem.NewWorkItemRepository(test.DB).Delete(ctx, strconv.FormatUint(blocker, 10))
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
+
+func (test *TestLinkRepository) TestDeleteLinkTypeWithLinks() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	linkTypeID := test.createLinkType("")
+	repo := link.NewWorkItemLinkRepository(test.DB)
+	l, err := repo.Create(ctx, test.createBug(), test.createBug(), linkTypeID)
+	require.Nil(t, err)
+
+	typeRepo := link.NewWorkItemLinkTypeRepository(test.DB)
+	err = typeRepo.Delete(ctx, linkTypeID.String(), false)
+	assert.IsType(t, errors.DataConflictError{}, err)
+	_, err = typeRepo.Load(ctx, linkTypeID.String())
+	require.Nil(t, err)
+	_, err = repo.Load(ctx, *l.Data.ID)
+	require.Nil(t, err)
+
+	require.Nil(t, typeRepo.Delete(ctx, linkTypeID.String(), true))
+	_, err = typeRepo.Load(ctx, linkTypeID.String())
+	assert.IsType(t, errors.NotFoundError{}, err)
+	_, err = repo.Load(ctx, *l.Data.ID)
+	assert.IsType(t, errors.NotFoundError{}, err)
+}
+
+func (test *TestLinkRepository) TestDeleteCategoryWithLinkTypes() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	linkTypeID := test.createLinkType("")
+	typeRepo := link.NewWorkItemLinkTypeRepository(test.DB)
+	linkType, err := typeRepo.Load(ctx, linkTypeID.String())
+	require.Nil(t, err)
+	categoryID := linkType.Data.Relationships.LinkCategory.Data.ID
+	categoryRepo := link.NewWorkItemLinkCategoryRepository(test.DB)
+
+	err = categoryRepo.Delete(ctx, categoryID, "")
+	assert.IsType(t, errors.DataConflictError{}, err)
+	err = categoryRepo.Delete(ctx, categoryID, categoryID)
+	assert.IsType(t, errors.BadParameterError{}, err)
+
+	name := "link-" + satoriuuid.NewV4().String()
+	other, err := categoryRepo.Create(ctx, &name, nil)
+	require.Nil(t, err)
+	require.Nil(t, categoryRepo.Delete(ctx, categoryID, *other.Data.ID))
+	_, err = categoryRepo.Load(ctx, categoryID)
+	assert.IsType(t, errors.NotFoundError{}, err)
+	linkType, err = typeRepo.Load(ctx, linkTypeID.String())
+	require.Nil(t, err)
+	assert.Equal(t, *other.Data.ID, linkType.Data.Relationships.LinkCategory.Data.ID)
+}
+
+func (test *TestLinkRepository) TestRestoreReattaches() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	projectID := satoriuuid.NewV4()
+	wir := workitem.NewWorkItemRepository(test.DB)
+	create := func() uint64 {
+		wi, err := wir.Create(ctx, workitem.SystemBug, map[string]interface{}{
+			workitem.SystemTitle:   "trash",
+			workitem.SystemState:   workitem.SystemStateOpen,
+			workitem.SystemProject: projectID.String(),
+		}, "xx")
+		require.Nil(t, err)
+		id, err := strconv.ParseUint(wi.ID, 10, 64)
+		require.Nil(t, err)
+		return id
+	}
+	linkTypeID := test.createLinkType("")
+	repo := link.NewWorkItemLinkRepository(test.DB)
+	parent, child, other := create(), create(), create()
+	detached, err := repo.Create(ctx, parent, child, linkTypeID)
+	require.Nil(t, err)
+	removed, err := repo.Create(ctx, other, child, test.createLinkType(""))
+	require.Nil(t, err)
+	// a link deleted on its own stays deleted
+	require.Nil(t, repo.Delete(ctx, *removed.Data.ID))
+
+	require.Nil(t, wir.Delete(ctx, strconv.FormatUint(child, 10)))
+	_, err = workitem.NewTrashRepository(test.DB).Restore(ctx, projectID, strconv.FormatUint(child, 10))
+	require.Nil(t, err)
+	_, err = repo.Load(ctx, *detached.Data.ID)
+	assert.Nil(t, err)
+	_, err = repo.Load(ctx, *removed.Data.ID)
+	assert.IsType(t, errors.NotFoundError{}, err)
+}
@@ -0,0 +1,158 @@
+package link
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	satoriuuid "github.com/satori/go.uuid"
+)
+
+// MaxListedAnomalies is the number of orphans per link type and of dangling
+// links the health check lists, the counts include all of them
+const MaxListedAnomalies = 100
+
+// Orphans are the work items without a parent in a tree link type
+type Orphans struct {
+	LinkTypeID   satoriuuid.UUID
+	LinkTypeName string
+	Count        int
+	WorkItemIDs  []uint64
+}
+
+// Bucket is the number of work items with the same number of links
+type Bucket struct {
+	Links     int
+	WorkItems int
+}
+
+// Distribution holds how many links of a link type the work items have, work
+// items without a link of the type are not counted
+type Distribution struct {
+	LinkTypeID   satoriuuid.UUID
+	LinkTypeName string
+	Links        int
+	Buckets      []Bucket
+}
+
+// Health is the result of the consistency checks of the work item links
+type Health struct {
+	// Orphans lists the work items of the target type of every tree link
+	// type that neither have a parent nor children. Roots of trees with
+	// children are not orphans.
+	Orphans []Orphans
+	// Dangling lists the links whose source, target or link type is deleted
+	Dangling      []WorkItemLink
+	DanglingCount int
+	Distributions []Distribution
+}
+
+// danglingClause matches the links of the table that reference a deleted
+// work item or link type
+const danglingClause = `work_item_links.deleted_at IS NULL AND (
+	NOT EXISTS (SELECT 1 FROM work_items s WHERE s.id = work_item_links.source_id AND s.deleted_at IS NULL) OR
+	NOT EXISTS (SELECT 1 FROM work_items t WHERE t.id = work_item_links.target_id AND t.deleted_at IS NULL) OR
+	NOT EXISTS (SELECT 1 FROM work_item_link_types lt WHERE lt.id = work_item_links.link_type_id AND lt.deleted_at IS NULL))`
+
+// Health checks all work item links for anomalies, it ignores the viewer
+// of ctx and is meant for instance admins
+// returns InternalError
+func (r *GormWorkItemLinkRepository) Health(ctx context.Context) (*Health, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitemlink", "health"}, time.Now())
+
+	res := Health{Orphans: []Orphans{}, Dangling: []WorkItemLink{}, Distributions: []Distribution{}}
+	var treeTypes []WorkItemLinkType
+	if err := r.db.Where("topology = ?", TopologyTree).Order("name").Find(&treeTypes).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	for _, lt := range treeTypes {
+		orphans, err := r.orphans(lt)
+		if err != nil {
+			return nil, err
+		}
+		res.Orphans = append(res.Orphans, *orphans)
+	}
+
+	dangling := r.db.Model(&WorkItemLink{}).Where(danglingClause)
+	if err := dangling.Count(&res.DanglingCount).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	if err := dangling.Order("created_at").Limit(MaxListedAnomalies).Find(&res.Dangling).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+
+	distributions, err := r.distributions()
+	if err != nil {
+		return nil, err
+	}
+	res.Distributions = distributions
+	return &res, nil
+}
+
+func (r *GormWorkItemLinkRepository) orphans(lt WorkItemLinkType) (*Orphans, error) {
+	db := r.db.Table(workitem.WorkItem{}.TableName()).
+		Where("deleted_at IS NULL").
+		Where(`type IN (SELECT subtype.name FROM work_item_types subtype
+			JOIN work_item_types supertype ON subtype.path LIKE (supertype.path || '%')
+			WHERE supertype.name = ? AND subtype.deleted_at IS NULL)`, lt.TargetTypeName).
+		Where(`NOT EXISTS (SELECT 1 FROM work_item_links l
+			WHERE l.deleted_at IS NULL AND l.link_type_id = ? AND (l.target_id = work_items.id OR l.source_id = work_items.id))`, lt.ID)
+	res := Orphans{LinkTypeID: lt.ID, LinkTypeName: lt.Name, WorkItemIDs: []uint64{}}
+	if err := db.Count(&res.Count).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	if err := db.Order("id").Limit(MaxListedAnomalies).Pluck("id", &res.WorkItemIDs).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return &res, nil
+}
+
+func (r *GormWorkItemLinkRepository) distributions() ([]Distribution, error) {
+	var rows []struct {
+		LinkTypeID satoriuuid.UUID
+		Name       string
+		Links      int
+		WorkItems  int
+	}
+	err := r.db.Raw(`SELECT lt.id AS link_type_id, lt.name, per.links, count(*) AS work_items
+		FROM (SELECT link_type_id, work_item_id, count(*) AS links FROM (
+				SELECT link_type_id, source_id AS work_item_id FROM work_item_links WHERE deleted_at IS NULL
+				UNION ALL
+				SELECT link_type_id, target_id AS work_item_id FROM work_item_links WHERE deleted_at IS NULL
+			) AS ends GROUP BY 1, 2) AS per
+		JOIN work_item_link_types lt ON lt.id = per.link_type_id AND lt.deleted_at IS NULL
+		GROUP BY 1, 2, 3 ORDER BY 2, 1, 3`).Scan(&rows).Error
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	res := []Distribution{}
+	for _, row := range rows {
+		if len(res) == 0 || !satoriuuid.Equal(res[len(res)-1].LinkTypeID, row.LinkTypeID) {
+			res = append(res, Distribution{LinkTypeID: row.LinkTypeID, LinkTypeName: row.Name, Buckets: []Bucket{}})
+		}
+		d := &res[len(res)-1]
+		d.Buckets = append(d.Buckets, Bucket{Links: row.Links, WorkItems: row.WorkItems})
+		// every link has two ends
+		d.Links += row.Links * row.WorkItems
+	}
+	for i := range res {
+		res[i].Links /= 2
+	}
+	return res, nil
+}
+
+// RepairDangling deletes the links whose source, target or link type is
+// deleted and returns how many it deleted
+// returns InternalError
+func (r *GormWorkItemLinkRepository) RepairDangling(ctx context.Context) (int, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitemlink", "repairdangling"}, time.Now())
+
+	tx := r.db.Where(danglingClause).Delete(&WorkItemLink{})
+	if tx.Error != nil {
+		return 0, errors.NewInternalError(tx.Error.Error())
+	}
+	return int(tx.RowsAffected), nil
+}
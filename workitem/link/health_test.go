@@ -0,0 +1,120 @@
+package link_test
+
+import (
+	"strconv"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/almighty/almighty-core/workitem/link"
+	satoriuuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestLinkRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunLinkRepository(t *testing.T) {
+	suite.Run(t, &TestLinkRepository{DBTestSuite: gormsupport.NewDBTestSuite("../../config.yaml")})
+}
+
+func (test *TestLinkRepository) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestLinkRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestLinkRepository) createBug() uint64 {
+	wi, err := workitem.NewWorkItemRepository(test.DB).Create(context.Background(), workitem.SystemBug,
+		map[string]interface{}{
+			workitem.SystemTitle: "health",
+			workitem.SystemState: workitem.SystemStateOpen,
+		}, "xx")
+	require.Nil(test.T(), err)
+	id, err := strconv.ParseUint(wi.ID, 10, 64)
+	require.Nil(test.T(), err)
+	return id
+}
+
+// createLinkType creates a tree link type between bugs
+func (test *TestLinkRepository) createLinkType(onDelete string) satoriuuid.UUID {
+	ctx := context.Background()
+	name := "link-" + satoriuuid.NewV4().String()
+	category, err := link.NewWorkItemLinkCategoryRepository(test.DB).Create(ctx, &name, nil)
+	require.Nil(test.T(), err)
+	categoryID, err := satoriuuid.FromString(*category.Data.ID)
+	require.Nil(test.T(), err)
+	linkType, err := link.NewWorkItemLinkTypeRepository(test.DB).Create(ctx, name, nil, workitem.SystemBug, workitem.SystemBug,
+		"parent of", "child of", link.TopologyTree, onDelete, categoryID)
+	require.Nil(test.T(), err)
+	id, err := satoriuuid.FromString(*linkType.Data.ID)
+	require.Nil(test.T(), err)
+	return id
+}
+
+func (test *TestLinkRepository) TestHealthAndRepair() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	linkTypeID := test.createLinkType(link.OnDeleteDetach)
+	repo := link.NewWorkItemLinkRepository(test.DB)
+	parent, child, orphan := test.createBug(), test.createBug(), test.createBug()
+	_, err := repo.Create(ctx, parent, child, linkTypeID)
+	require.Nil(t, err)
+	// links are deleted with their work items, only a link added afterwards
+	// can reference a deleted one
+	deleted := test.createBug()
+	require.Nil(t, workitem.NewWorkItemRepository(test.DB).Delete(ctx, strconv.FormatUint(deleted, 10)))
+	danglingID := satoriuuid.NewV4()
+	require.Nil(t, test.DB.Exec("INSERT INTO work_item_links (id, created_at, updated_at, version, source_id, target_id, link_type_id) VALUES (?, now(), now(), 0, ?, ?, ?)",
+		danglingID, parent, deleted, linkTypeID).Error)
+
+	health, err := repo.Health(ctx)
+	require.Nil(t, err)
+	var orphans *link.Orphans
+	for i, o := range health.Orphans {
+		if satoriuuid.Equal(o.LinkTypeID, linkTypeID) {
+			orphans = &health.Orphans[i]
+		}
+	}
+	require.NotNil(t, orphans)
+	assert.Contains(t, orphans.WorkItemIDs, orphan)
+	assert.NotContains(t, orphans.WorkItemIDs, parent)
+	assert.NotContains(t, orphans.WorkItemIDs, child)
+	require.True(t, health.DanglingCount >= 1)
+	found := false
+	for _, l := range health.Dangling {
+		found = found || satoriuuid.Equal(l.ID, danglingID)
+	}
+	assert.True(t, found)
+	var distribution *link.Distribution
+	for i, d := range health.Distributions {
+		if satoriuuid.Equal(d.LinkTypeID, linkTypeID) {
+			distribution = &health.Distributions[i]
+		}
+	}
+	require.NotNil(t, distribution)
+	assert.Equal(t, 2, distribution.Links)
+	// the parent has both links, the child and the deleted work item one each
+	assert.Equal(t, []link.Bucket{{Links: 1, WorkItems: 2}, {Links: 2, WorkItems: 1}}, distribution.Buckets)
+
+	repaired, err := repo.RepairDangling(ctx)
+	require.Nil(t, err)
+	assert.Equal(t, health.DanglingCount, repaired)
+	health, err = repo.Health(ctx)
+	require.Nil(t, err)
+	assert.Equal(t, 0, health.DanglingCount)
+	_, err = repo.Load(ctx, danglingID.String())
+	assert.NotNil(t, err)
+}
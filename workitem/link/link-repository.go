@@ -1,6 +1,7 @@
 package link
 
 import (
+	"fmt"
 	"log"
 	"strconv"
 
@@ -9,6 +10,8 @@ import (
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/hierarchy"
+	"github.com/almighty/almighty-core/portfolio"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/jinzhu/gorm"
 	satoriuuid "github.com/satori/go.uuid"
@@ -27,9 +30,17 @@ type WorkItemLinkRepository interface {
 	Create(ctx context.Context, sourceID, targetID uint64, linkTypeID satoriuuid.UUID) (*app.WorkItemLinkSingle, error)
 	Load(ctx context.Context, ID string) (*app.WorkItemLinkSingle, error)
 	List(ctx context.Context) (*app.WorkItemLinkList, error)
-	ListByWorkItemID(ctx context.Context, wiIDStr string) (*app.WorkItemLinkList, error)
+	ListByWorkItemID(ctx context.Context, wiIDStr string, f ListFilter) (*app.WorkItemLinkList, error)
 	Delete(ctx context.Context, ID string) error
 	Save(ctx context.Context, linkCat app.WorkItemLinkSingle) (*app.WorkItemLinkSingle, error)
+	Health(ctx context.Context) (*Health, error)
+	RepairDangling(ctx context.Context) (int, error)
+	DeleteMatching(ctx context.Context, f Filter, dryRun bool) (int, error)
+	TreeLinkType(ctx context.Context, parentType, childType string, linkTypeID *satoriuuid.UUID) (*WorkItemLinkType, error)
+	Rollups(ctx context.Context, parentIDs []uint64, pointsField string) (map[uint64]Rollup, error)
+	Reparent(ctx context.Context, ids []string, to Reparenting) (*Reparenting, error)
+	Suggest(ctx context.Context, wiIDStr string, limit int) ([]Suggestion, error)
+	Between(ctx context.Context, ids []uint64, linkTypeID *satoriuuid.UUID) ([]WorkItemLink, error)
 }
 
 // NewWorkItemLinkRepository creates a work item link repository based on gorm
@@ -39,6 +50,8 @@ func NewWorkItemLinkRepository(db *gorm.DB) *GormWorkItemLinkRepository {
 		workItemRepo:         workitem.NewWorkItemRepository(db),
 		workItemTypeRepo:     workitem.NewWorkItemTypeRepository(db),
 		workItemLinkTypeRepo: NewWorkItemLinkTypeRepository(db),
+		hierarchyRepo:        hierarchy.NewTypeHierarchyRepository(db),
+		portfolioRepo:        portfolio.NewPortfolioRepository(db),
 	}
 }
 
@@ -48,6 +61,8 @@ type GormWorkItemLinkRepository struct {
 	workItemRepo         *workitem.GormWorkItemRepository
 	workItemTypeRepo     *workitem.GormWorkItemTypeRepository
 	workItemLinkTypeRepo *GormWorkItemLinkTypeRepository
+	hierarchyRepo        hierarchy.Repository
+	portfolioRepo        portfolio.Repository
 }
 
 // ValidateCorrectSourceAndTargetType returns an error if the Path of
@@ -87,6 +102,70 @@ func (r *GormWorkItemLinkRepository) ValidateCorrectSourceAndTargetType(sourceID
 	return nil
 }
 
+// checkTree returns a BadParameterError if the link is of a tree link type
+// and either links work items of different projects without a portfolio
+// designating the link type for them, or the type hierarchy of the project
+// of the source forbids the types of the source and the target as parent and
+// child. Work items outside of projects are not restricted.
+func (r *GormWorkItemLinkRepository) checkTree(ctx context.Context, sourceID, targetID uint64, linkTypeID satoriuuid.UUID) error {
+	linkType, err := r.workItemLinkTypeRepo.LoadTypeFromDBByID(linkTypeID)
+	if err != nil {
+		return err
+	}
+	if linkType.Topology != TopologyTree {
+		return nil
+	}
+	source, err := r.workItemRepo.LoadFromDB(strconv.FormatUint(sourceID, 10))
+	if err != nil {
+		return err
+	}
+	target, err := r.workItemRepo.LoadFromDB(strconv.FormatUint(targetID, 10))
+	if err != nil {
+		return err
+	}
+	sourceProjectID, err := satoriuuid.FromString(fmt.Sprint(source.Fields[workitem.SystemProject]))
+	if err != nil {
+		return nil
+	}
+	if targetProjectID, err := satoriuuid.FromString(fmt.Sprint(target.Fields[workitem.SystemProject])); err == nil {
+		if err := r.portfolioRepo.CheckLink(ctx, sourceProjectID, targetProjectID, linkTypeID); err != nil {
+			return err
+		}
+	}
+	return r.hierarchyRepo.Check(ctx, sourceProjectID, source.Type, target.Type)
+}
+
+// checkEndpointsVisible returns NotFoundError if the source or the target of a
+// link is a confidential work item the viewer of ctx can not see.
+func (r *GormWorkItemLinkRepository) checkEndpointsVisible(ctx context.Context, sourceID, targetID uint64) error {
+	viewer := workitem.ContextViewer(ctx)
+	if viewer == nil {
+		return nil
+	}
+	for _, id := range []uint64{sourceID, targetID} {
+		wi, err := r.workItemRepo.LoadFromDB(strconv.FormatUint(id, 10))
+		if err != nil {
+			return err
+		}
+		if !viewer.CanSee(wi.Fields) {
+			return errors.NewNotFoundError("work item", strconv.FormatUint(id, 10))
+		}
+	}
+	return nil
+}
+
+// visibleLinks restricts db to the links of which both ends are visible to
+// the viewer of ctx
+func visibleLinks(ctx context.Context, db *gorm.DB) *gorm.DB {
+	wiTable := workitem.WorkItem{}.TableName()
+	clause, params := workitem.VisibilityClause(ctx, wiTable)
+	if clause == "" {
+		return db
+	}
+	return db.Where(fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %[1]s WHERE %[1]s.id IN (%[2]s.source_id, %[2]s.target_id) AND NOT %[3]s)",
+		wiTable, "work_item_links", clause), params...)
+}
+
 // Create creates a new work item link in the repository.
 // Returns BadParameterError, ConversionError or InternalError
 func (r *GormWorkItemLinkRepository) Create(ctx context.Context, sourceID, targetID uint64, linkTypeID satoriuuid.UUID) (*app.WorkItemLinkSingle, error) {
@@ -98,9 +177,15 @@ func (r *GormWorkItemLinkRepository) Create(ctx context.Context, sourceID, targe
 	if err := link.CheckValidForCreation(); err != nil {
 		return nil, err
 	}
+	if err := r.checkEndpointsVisible(ctx, sourceID, targetID); err != nil {
+		return nil, err
+	}
 	if err := r.ValidateCorrectSourceAndTargetType(sourceID, targetID, linkTypeID); err != nil {
 		return nil, err
 	}
+	if err := r.checkTree(ctx, sourceID, targetID, linkTypeID); err != nil {
+		return nil, err
+	}
 	db := r.db.Create(link)
 	if db.Error != nil {
 		if gormsupport.IsUniqueViolation(db.Error, "work_item_links_unique_idx") {
@@ -132,6 +217,10 @@ func (r *GormWorkItemLinkRepository) Load(ctx context.Context, ID string) (*app.
 	if db.Error != nil {
 		return nil, errors.NewInternalError(db.Error.Error())
 	}
+	if err := r.checkEndpointsVisible(ctx, res.SourceID, res.TargetID); err != nil {
+		// hide links from or to confidential work items
+		return nil, errors.NewNotFoundError("work item link", id.String())
+	}
 	// Convert the created link type entry into a JSONAPI response
 	result := ConvertLinkFromModel(res)
 	return &result, nil
@@ -158,19 +247,69 @@ func (r *GormWorkItemLinkRepository) list(ctx context.Context, fetchFunc fetchLi
 	return &res, nil
 }
 
-// ListByWorkItemID returns the work item links that have wiID as source or target.
+// Directions of the links of a work item
+const (
+	// DirectionOutgoing links have the work item as source
+	DirectionOutgoing = "outgoing"
+	// DirectionIncoming links have the work item as target
+	DirectionIncoming = "incoming"
+)
+
+// ListFilter selects the links of a work item listed by ListByWorkItemID, the
+// links must match all fields that are set
+type ListFilter struct {
+	// Direction is DirectionOutgoing or DirectionIncoming, both are listed
+	// if it is empty
+	Direction  string
+	LinkTypeID *satoriuuid.UUID
+	// TargetType matches the links whose other work item is of the type
+	TargetType *string
+}
+
+// ListByWorkItemID returns the work item links that have wiID as source or
+// target and match the filter.
+// returns BadParameterError for an unknown direction, NotFoundError or
+// InternalError
 // TODO: Handle pagination
-func (r *GormWorkItemLinkRepository) ListByWorkItemID(ctx context.Context, wiIDStr string) (*app.WorkItemLinkList, error) {
+func (r *GormWorkItemLinkRepository) ListByWorkItemID(ctx context.Context, wiIDStr string, f ListFilter) (*app.WorkItemLinkList, error) {
 	fetchFunc := func() ([]WorkItemLink, error) {
 		var rows []WorkItemLink
 		wi, err := r.workItemRepo.LoadFromDB(wiIDStr)
 		if err != nil {
 			return nil, err
 		}
-		// Now fetch all links for that work item
-		db := r.db.Model(&WorkItemLink{}).Where("? IN (source_id, target_id)", wi.ID).Find(&rows)
+		if !workitem.ContextViewer(ctx).CanSee(wi.Fields) {
+			return nil, errors.NewNotFoundError("work item", wiIDStr)
+		}
+		// the column of the other work item of the links
+		other := "CASE WHEN work_item_links.source_id = ? THEN work_item_links.target_id ELSE work_item_links.source_id END"
+		db := r.db.Model(&WorkItemLink{})
+		switch f.Direction {
+		case "":
+			db = db.Where("? IN (source_id, target_id)", wi.ID)
+		case DirectionOutgoing:
+			db = db.Where("source_id = ?", wi.ID)
+			other = "work_item_links.target_id"
+		case DirectionIncoming:
+			db = db.Where("target_id = ?", wi.ID)
+			other = "work_item_links.source_id"
+		default:
+			return nil, errors.NewBadParameterError("filter[direction]", f.Direction).Expected(DirectionOutgoing + " or " + DirectionIncoming)
+		}
+		if f.LinkTypeID != nil {
+			db = db.Where("link_type_id = ?", *f.LinkTypeID)
+		}
+		if f.TargetType != nil {
+			clause := fmt.Sprintf("EXISTS (SELECT 1 FROM %[1]s WHERE %[1]s.id = %[2]s AND %[1]s.type = ? AND %[1]s.deleted_at IS NULL)", workitem.WorkItem{}.TableName(), other)
+			if f.Direction == "" {
+				db = db.Where(clause, wi.ID, *f.TargetType)
+			} else {
+				db = db.Where(clause, *f.TargetType)
+			}
+		}
+		db = visibleLinks(ctx, db).Find(&rows)
 		if db.Error != nil {
-			return nil, db.Error
+			return nil, errors.NewInternalError(db.Error.Error())
 		}
 		return rows, nil
 	}
@@ -183,7 +322,7 @@ func (r *GormWorkItemLinkRepository) ListByWorkItemID(ctx context.Context, wiIDS
 func (r *GormWorkItemLinkRepository) List(ctx context.Context) (*app.WorkItemLinkList, error) {
 	fetchFunc := func() ([]WorkItemLink, error) {
 		var rows []WorkItemLink
-		db := r.db.Find(&rows)
+		db := visibleLinks(ctx, r.db.Model(&WorkItemLink{})).Find(&rows)
 		if db.Error != nil {
 			return nil, db.Error
 		}
@@ -192,6 +331,25 @@ func (r *GormWorkItemLinkRepository) List(ctx context.Context) (*app.WorkItemLin
 	return r.list(ctx, fetchFunc)
 }
 
+// Between returns the links whose source and target are both among the work
+// items, ordered by source and target. If linkTypeID is set only links of that
+// type are returned.
+// returns InternalError
+func (r *GormWorkItemLinkRepository) Between(ctx context.Context, ids []uint64, linkTypeID *satoriuuid.UUID) ([]WorkItemLink, error) {
+	rows := []WorkItemLink{}
+	if len(ids) == 0 {
+		return rows, nil
+	}
+	db := r.db.Model(&WorkItemLink{}).Where("source_id IN (?) AND target_id IN (?)", ids, ids)
+	if linkTypeID != nil {
+		db = db.Where("link_type_id = ?", *linkTypeID)
+	}
+	if err := visibleLinks(ctx, db).Order("source_id, target_id").Find(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return rows, nil
+}
+
 // Delete deletes the work item link with the given id
 // returns NotFoundError or InternalError
 func (r *GormWorkItemLinkRepository) Delete(ctx context.Context, ID string) error {
@@ -203,6 +361,11 @@ func (r *GormWorkItemLinkRepository) Delete(ctx context.Context, ID string) erro
 	var link = WorkItemLink{
 		ID: id,
 	}
+	if workitem.ContextViewer(ctx) != nil {
+		if _, err := r.Load(ctx, ID); err != nil {
+			return err
+		}
+	}
 	log.Printf("work item link to delete %v\n", link)
 	db := r.db.Delete(&link)
 	if db.Error != nil {
@@ -231,16 +394,28 @@ func (r *GormWorkItemLinkRepository) Save(ctx context.Context, lt app.WorkItemLi
 		log.Print(db.Error.Error())
 		return nil, errors.NewInternalError(db.Error.Error())
 	}
+	if err := r.checkEndpointsVisible(ctx, res.SourceID, res.TargetID); err != nil {
+		return nil, errors.NewNotFoundError("work item link", *lt.Data.ID)
+	}
 	if lt.Data.Attributes.Version == nil || res.Version != *lt.Data.Attributes.Version {
 		return nil, errors.NewVersionConflictError("version conflict")
 	}
 	if err := ConvertLinkToModel(lt, &res); err != nil {
 		return nil, err
 	}
+	if err := res.ValidateAnnotation(); err != nil {
+		return nil, err
+	}
 	res.Version = res.Version + 1
+	if err := r.checkEndpointsVisible(ctx, res.SourceID, res.TargetID); err != nil {
+		return nil, err
+	}
 	if err := r.ValidateCorrectSourceAndTargetType(res.SourceID, res.TargetID, res.LinkTypeID); err != nil {
 		return nil, err
 	}
+	if err := r.checkTree(ctx, res.SourceID, res.TargetID, res.LinkTypeID); err != nil {
+		return nil, err
+	}
 	db = r.db.Save(&res)
 	if db.Error != nil {
 		log.Print(db.Error.Error())
@@ -1,6 +1,7 @@
 package link
 
 import (
+	"reflect"
 	"strconv"
 
 	"github.com/almighty/almighty-core/app"
@@ -20,6 +21,9 @@ type WorkItemLink struct {
 	SourceID   uint64
 	TargetID   uint64
 	LinkTypeID satoriuuid.UUID `sql:"type:uuid default uuid_generate_v4()"`
+	// Note is a short comment on the link, like "blocks until API freeze"
+	Note     string
+	Metadata Metadata `sql:"type:jsonb"`
 }
 
 // Ensure Fields implements the Equaler interface
@@ -50,6 +54,12 @@ func (self WorkItemLink) Equal(u convert.Equaler) bool {
 	if self.LinkTypeID != other.LinkTypeID {
 		return false
 	}
+	if self.Note != other.Note {
+		return false
+	}
+	if !reflect.DeepEqual(self.Metadata, other.Metadata) {
+		return false
+	}
 	return true
 }
 
@@ -70,7 +80,9 @@ func ConvertLinkFromModel(t WorkItemLink) app.WorkItemLinkSingle {
 			Type: EndpointWorkItemLinks,
 			ID:   &id,
 			Attributes: &app.WorkItemLinkAttributes{
-				Version: &t.Version,
+				Version:  &t.Version,
+				Note:     &t.Note,
+				Metadata: map[string]string(t.Metadata),
 			},
 			Relationships: &app.WorkItemLinkRelationships{
 				LinkType: &app.RelationWorkItemLinkType{
@@ -124,6 +136,13 @@ func ConvertLinkToModel(in app.WorkItemLinkSingle, out *WorkItemLink) error {
 		if attrs.Version != nil {
 			out.Version = *attrs.Version
 		}
+		if attrs.Note != nil {
+			out.Note = *attrs.Note
+		}
+		// the metadata are replaced as a whole
+		if attrs.Metadata != nil {
+			out.Metadata = Metadata(attrs.Metadata)
+		}
 	}
 
 	if rel != nil && rel.LinkType != nil && rel.LinkType.Data != nil {
@@ -0,0 +1,52 @@
+package link_test
+
+import (
+	"strconv"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/almighty/almighty-core/workitem/link"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (test *TestLinkRepository) TestListByWorkItemIDFiltered() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := link.NewWorkItemLinkRepository(test.DB)
+	childType, parentType := test.createLinkType(""), test.createLinkType("")
+	a, b, c := test.createBug(), test.createBug(), test.createBug()
+	outgoing, err := repo.Create(ctx, a, b, childType)
+	require.Nil(t, err)
+	incoming, err := repo.Create(ctx, c, a, parentType)
+	require.Nil(t, err)
+	id := strconv.FormatUint(a, 10)
+
+	list := func(f link.ListFilter) []string {
+		res, err := repo.ListByWorkItemID(ctx, id, f)
+		require.Nil(t, err)
+		var ids []string
+		for _, l := range res.Data {
+			ids = append(ids, *l.ID)
+		}
+		return ids
+	}
+	assert.Len(t, list(link.ListFilter{}), 2)
+	assert.Equal(t, []string{*outgoing.Data.ID}, list(link.ListFilter{Direction: link.DirectionOutgoing}))
+	assert.Equal(t, []string{*incoming.Data.ID}, list(link.ListFilter{Direction: link.DirectionIncoming}))
+	assert.Equal(t, []string{*incoming.Data.ID}, list(link.ListFilter{LinkTypeID: &parentType}))
+	assert.Empty(t, list(link.ListFilter{Direction: link.DirectionOutgoing, LinkTypeID: &parentType}))
+
+	bug, feature := workitem.SystemBug, workitem.SystemFeature
+	assert.Len(t, list(link.ListFilter{TargetType: &bug}), 2)
+	assert.Equal(t, []string{*incoming.Data.ID}, list(link.ListFilter{Direction: link.DirectionIncoming, TargetType: &bug}))
+	assert.Empty(t, list(link.ListFilter{TargetType: &feature}))
+
+	_, err = repo.ListByWorkItemID(ctx, id, link.ListFilter{Direction: "sideways"})
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
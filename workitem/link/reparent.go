@@ -0,0 +1,179 @@
+package link
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/outbox"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	satoriuuid "github.com/satori/go.uuid"
+)
+
+// TopicReparent is the topic of the outbox record written for every batch of
+// reparented work items
+const TopicReparent = "workitem.reparent"
+
+// Reparenting tells where to move work items to and, once they are moved,
+// what changed. It is the payload of the TopicReparent outbox record.
+type Reparenting struct {
+	// WorkItems are the IDs of the moved work items
+	WorkItems []string `json:"workitems"`
+	// Parent is the ID or key of the new parent, the work items keep their
+	// parents if it is not set
+	Parent *string `json:"parent,omitempty"`
+	// LinkTypeID is the tree link type to link the work items to the parent
+	// with, needed when several tree link types link the work item types
+	LinkTypeID *satoriuuid.UUID `json:"link_type_id,omitempty"`
+	// Iteration is the new iteration of the work items, they keep their
+	// iterations if it is not set
+	Iteration *satoriuuid.UUID `json:"iteration,omitempty"`
+	// FormerParents are the IDs of the parents the work items were unlinked
+	// from by their IDs
+	FormerParents map[string][]string `json:"former_parents"`
+}
+
+// Reparent moves the given work items to the parent and/or the iteration of
+// the reparenting in one go: the links of tree link types to their current
+// parents are deleted like by Delete and they are linked to the new parent
+// like by Create, with the checks of the type hierarchy and the portfolios.
+// The iteration of all work items is changed by a single update. A single
+// TopicReparent record is added to the outbox for all work items. The
+// reparenting is returned with the IDs of the work items and their former
+// parents.
+// returns BadParameterError, NotFoundError or InternalError
+func (r *GormWorkItemLinkRepository) Reparent(ctx context.Context, ids []string, to Reparenting) (*Reparenting, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitemlink", "reparent"}, time.Now())
+
+	if len(ids) == 0 {
+		return nil, errors.NewBadParameterError("workitems", ids).Expected("at least one work item")
+	}
+	if max := configuration.GetReparentMaxWorkItems(); len(ids) > max {
+		return nil, errors.NewBadParameterError("workitems", len(ids)).Expected(fmt.Sprintf("at most %d work items", max))
+	}
+	if to.Parent == nil && to.Iteration == nil {
+		return nil, errors.NewBadParameterError("parent", nil).Expected("a parent or an iteration to move the work items to")
+	}
+	var parentID uint64
+	var parentType string
+	if to.Parent != nil {
+		parent, err := r.workItemRepo.Load(ctx, *to.Parent)
+		if err != nil {
+			return nil, errors.NewBadParameterError("parent", *to.Parent).Expected("an existing work item")
+		}
+		if parentID, err = workitem.ParseWorkItemIDToUint64(parent.ID); err != nil {
+			return nil, err
+		}
+		parentType = parent.Type
+	}
+	var itr *iteration.Iteration
+	if to.Iteration != nil {
+		var err error
+		if itr, err = iteration.NewIterationRepository(r.db).Load(ctx, *to.Iteration); err != nil {
+			return nil, errors.NewBadParameterError("iteration", to.Iteration.String()).Expected("an existing iteration")
+		}
+	}
+
+	res := to
+	res.WorkItems = []string{}
+	res.FormerParents = map[string][]string{}
+	moved := map[uint64]bool{}
+	var movedIDs []uint64
+	for _, id := range ids {
+		wi, err := r.workItemRepo.Load(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		childID, err := workitem.ParseWorkItemIDToUint64(wi.ID)
+		if err != nil {
+			return nil, err
+		}
+		if moved[childID] {
+			continue
+		}
+		moved[childID] = true
+		movedIDs = append(movedIDs, childID)
+		res.WorkItems = append(res.WorkItems, wi.ID)
+
+		if itr != nil {
+			if projectID, ok := wi.Fields[workitem.SystemProject]; ok && projectID != nil && fmt.Sprint(projectID) != itr.ProjectID.String() {
+				return nil, errors.NewBadParameterError("iteration", itr.ID.String()).Expected(fmt.Sprintf("an iteration of the project of work item %s", wi.ID))
+			}
+		}
+		if to.Parent == nil {
+			continue
+		}
+		lt, err := r.TreeLinkType(ctx, parentType, wi.Type, to.LinkTypeID)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.checkNotDescendant(parentID, childID); err != nil {
+			return nil, err
+		}
+		var links []WorkItemLink
+		err = r.db.Where("target_id = ? AND link_type_id IN (SELECT id FROM work_item_link_types WHERE topology = ? AND deleted_at IS NULL)",
+			childID, TopologyTree).Order("source_id").Find(&links).Error
+		if err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		for _, l := range links {
+			if err := r.Delete(ctx, l.ID.String()); err != nil {
+				return nil, err
+			}
+			res.FormerParents[wi.ID] = append(res.FormerParents[wi.ID], strconv.FormatUint(l.SourceID, 10))
+		}
+		if _, err := r.Create(ctx, parentID, childID, lt.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if itr != nil {
+		err := r.db.Exec(fmt.Sprintf(`UPDATE work_items SET fields = jsonb_set(fields, '{%s}', to_jsonb(CAST(? AS text))), version = version + 1, updated_at = ?
+			WHERE id IN (?)`, workitem.SystemIteration), itr.ID.String(), time.Now(), movedIDs).Error
+		if err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		if err := workitem.RecordEvents(ctx, r.db, workitem.EventUpdate, movedIDs...); err != nil {
+			return nil, err
+		}
+	}
+
+	var key string
+	if to.Parent != nil {
+		key = strconv.FormatUint(parentID, 10)
+	} else {
+		key = itr.ID.String()
+	}
+	if _, err := outbox.NewOutboxRepository(r.db).Add(ctx, TopicReparent, key, res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// checkNotDescendant returns a BadParameterError if the parent is the child
+// or one of its descendants, linking them would make a cycle
+func (r *GormWorkItemLinkRepository) checkNotDescendant(parentID, childID uint64) error {
+	var cycles int
+	err := r.db.Raw(`WITH RECURSIVE descendants(id) AS (
+			SELECT CAST(? AS bigint)
+			UNION
+			SELECT l.target_id FROM work_item_links l
+			JOIN work_item_link_types t ON t.id = l.link_type_id AND t.deleted_at IS NULL AND t.topology = ?
+			JOIN descendants d ON d.id = l.source_id
+			WHERE l.deleted_at IS NULL
+		)
+		SELECT count(*) FROM descendants WHERE id = ?`, childID, TopologyTree, parentID).Row().Scan(&cycles)
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if cycles > 0 {
+		return errors.NewBadParameterError("parent", parentID).Expected(fmt.Sprintf("a work item that is not work item %d or one of its descendants", childID))
+	}
+	return nil
+}
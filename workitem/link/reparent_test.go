@@ -0,0 +1,53 @@
+package link_test
+
+import (
+	"strconv"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem/link"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (test *TestLinkRepository) TestReparent() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	linkTypeID := test.createLinkType(link.OnDeleteDetach)
+	repo := link.NewWorkItemLinkRepository(test.DB)
+	from, to, child, orphan := test.createBug(), test.createBug(), test.createBug(), test.createBug()
+	_, err := repo.Create(ctx, from, child, linkTypeID)
+	require.Nil(t, err)
+
+	fromID, toID, childID, orphanID := strconv.FormatUint(from, 10), strconv.FormatUint(to, 10), strconv.FormatUint(child, 10), strconv.FormatUint(orphan, 10)
+	res, err := repo.Reparent(ctx, []string{childID, orphanID, childID}, link.Reparenting{Parent: &toID, LinkTypeID: &linkTypeID})
+	require.Nil(t, err)
+	assert.Equal(t, []string{childID, orphanID}, res.WorkItems)
+	assert.Equal(t, map[string][]string{childID: {fromID}}, res.FormerParents)
+
+	rollups, err := repo.Rollups(ctx, []uint64{from, to}, "storypoints")
+	require.Nil(t, err)
+	assert.Equal(t, 0, rollups[from].Total)
+	assert.Equal(t, 2, rollups[to].Total)
+
+	var records int
+	require.Nil(t, test.DB.Raw("SELECT count(*) FROM outbox_records WHERE topic = ? AND key = ?", link.TopicReparent, toID).Row().Scan(&records))
+	assert.Equal(t, 1, records)
+
+	// a work item can't move below itself
+	_, err = repo.Reparent(ctx, []string{toID}, link.Reparenting{Parent: &childID, LinkTypeID: &linkTypeID})
+	assert.IsType(t, errors.BadParameterError{}, err)
+	_, err = repo.Reparent(ctx, []string{childID}, link.Reparenting{})
+	assert.IsType(t, errors.BadParameterError{}, err)
+
+	// the number of work items moved at once is limited
+	require.Nil(t, configuration.Reload(map[string]string{"reparent.maxworkitems": "1"}))
+	defer configuration.Reload(nil)
+	_, err = repo.Reparent(ctx, []string{childID, orphanID}, link.Reparenting{Parent: &fromID, LinkTypeID: &linkTypeID})
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
@@ -0,0 +1,109 @@
+package link
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	satoriuuid "github.com/satori/go.uuid"
+)
+
+// Rollup sums up the children of a work item, the targets of its links of
+// tree link types the viewer can see
+type Rollup struct {
+	// Total is the number of children
+	Total int
+	// States counts the children per state
+	States map[string]int
+	// Points sums up the numeric points field of the children, children
+	// without points don't add to it
+	Points float64
+}
+
+// TreeLinkType returns the tree link type to link a child of the given type
+// to a parent of the given type. With a linkTypeID it checks that the link
+// type is such a tree link type, otherwise exactly one must exist.
+// returns NotFoundError, BadParameterError or InternalError
+func (r *GormWorkItemLinkRepository) TreeLinkType(ctx context.Context, parentType, childType string, linkTypeID *satoriuuid.UUID) (*WorkItemLinkType, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitemlink", "treelinktype"}, time.Now())
+
+	parent, err := r.workItemTypeRepo.LoadTypeFromDB(parentType)
+	if err != nil {
+		return nil, err
+	}
+	child, err := r.workItemTypeRepo.LoadTypeFromDB(childType)
+	if err != nil {
+		return nil, err
+	}
+	db := r.db.Where("topology = ?", TopologyTree)
+	if linkTypeID != nil {
+		db = db.Where("id = ?", *linkTypeID)
+	}
+	var candidates []WorkItemLinkType
+	if err := db.Order("name").Find(&candidates).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	var matching []WorkItemLinkType
+	for _, lt := range candidates {
+		if parent.IsTypeOrSubtypeOf(lt.SourceTypeName) && child.IsTypeOrSubtypeOf(lt.TargetTypeName) {
+			matching = append(matching, lt)
+		}
+	}
+	switch {
+	case linkTypeID != nil && len(matching) == 0:
+		return nil, errors.NewBadParameterError("link_type", linkTypeID.String()).Expected(fmt.Sprintf("a tree link type from %s to %s", parentType, childType))
+	case len(matching) == 0:
+		return nil, errors.NewNotFoundError("tree link type", parentType+" -> "+childType)
+	case len(matching) > 1:
+		return nil, errors.NewBadParameterError("link_type", nil).Expected(fmt.Sprintf("one of the %d tree link types from %s to %s", len(matching), parentType, childType))
+	}
+	return &matching[0], nil
+}
+
+// Rollups sums up the children of the given work items, pointsField names
+// the field whose numeric values are summed up as points. Work items
+// without children have an empty rollup.
+// returns InternalError
+func (r *GormWorkItemLinkRepository) Rollups(ctx context.Context, parentIDs []uint64, pointsField string) (map[uint64]Rollup, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitemlink", "rollups"}, time.Now())
+
+	res := make(map[uint64]Rollup, len(parentIDs))
+	if len(parentIDs) == 0 {
+		return res, nil
+	}
+	for _, id := range parentIDs {
+		res[id] = Rollup{States: map[string]int{}}
+	}
+	db := r.db.Table("work_item_links").
+		Select(`work_item_links.source_id AS parent_id, coalesce(work_items.fields->>?, '') AS state, count(*) AS children,
+			coalesce(sum(CASE WHEN jsonb_typeof(work_items.fields->?) = 'number' THEN (work_items.fields->>?)::numeric END), 0) AS points`,
+			workitem.SystemState, pointsField, pointsField).
+		Joins("JOIN work_item_link_types ON work_item_link_types.id = work_item_links.link_type_id AND work_item_link_types.deleted_at IS NULL").
+		Joins("JOIN work_items ON work_items.id = work_item_links.target_id AND work_items.deleted_at IS NULL").
+		Where("work_item_links.deleted_at IS NULL AND work_item_link_types.topology = ?", TopologyTree).
+		Where("work_item_links.source_id IN (?)", parentIDs)
+	if clause, params := workitem.VisibilityClause(ctx, workitem.WorkItem{}.TableName()); clause != "" {
+		db = db.Where(clause, params...)
+	}
+	var rows []struct {
+		ParentID uint64
+		State    string
+		Children int
+		Points   float64
+	}
+	if err := db.Group("1, 2").Scan(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	for _, row := range rows {
+		rollup := res[row.ParentID]
+		rollup.Total += row.Children
+		rollup.States[row.State] += row.Children
+		rollup.Points += row.Points
+		res[row.ParentID] = rollup
+	}
+	return res, nil
+}
@@ -0,0 +1,49 @@
+package link_test
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/almighty/almighty-core/workitem/link"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (test *TestLinkRepository) TestTreeLinkType() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	linkTypeID := test.createLinkType(link.OnDeleteDetach)
+	repo := link.NewWorkItemLinkRepository(test.DB)
+	lt, err := repo.TreeLinkType(ctx, workitem.SystemBug, workitem.SystemBug, &linkTypeID)
+	require.Nil(t, err)
+	assert.Equal(t, linkTypeID, lt.ID)
+	_, err = repo.TreeLinkType(ctx, workitem.SystemBug, workitem.SystemFeature, &linkTypeID)
+	assert.IsType(t, errors.BadParameterError{}, err)
+	_, err = repo.TreeLinkType(ctx, workitem.SystemFeature, workitem.SystemFeature, nil)
+	assert.IsType(t, errors.NotFoundError{}, err)
+}
+
+func (test *TestLinkRepository) TestRollups() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	linkTypeID := test.createLinkType(link.OnDeleteDetach)
+	repo := link.NewWorkItemLinkRepository(test.DB)
+	parent, open, closed, unpointed, childless := test.createBug(), test.createBug(), test.createBug(), test.createBug(), test.createBug()
+	for _, child := range []uint64{open, closed, unpointed} {
+		_, err := repo.Create(ctx, parent, child, linkTypeID)
+		require.Nil(t, err)
+	}
+	require.Nil(t, test.DB.Exec(`UPDATE work_items SET fields = fields || '{"storypoints": 3}' WHERE id = ?`, open).Error)
+	require.Nil(t, test.DB.Exec(`UPDATE work_items SET fields = fields || '{"storypoints": 5, "system.state": "closed"}' WHERE id = ?`, closed).Error)
+
+	rollups, err := repo.Rollups(ctx, []uint64{parent, childless}, "storypoints")
+	require.Nil(t, err)
+	assert.Equal(t, link.Rollup{Total: 3, States: map[string]int{workitem.SystemStateOpen: 2, workitem.SystemStateClosed: 1}, Points: 8}, rollups[parent])
+	assert.Equal(t, link.Rollup{States: map[string]int{}}, rollups[childless])
+}
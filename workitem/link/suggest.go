@@ -0,0 +1,249 @@
+package link
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	satoriuuid "github.com/satori/go.uuid"
+)
+
+// The kinds of link suggestions
+const (
+	// SuggestDuplicate work items are of the same type with a very similar
+	// title
+	SuggestDuplicate = "duplicate"
+	// SuggestParent work items can be linked as parent in a tree link type
+	SuggestParent = "parent"
+	// SuggestRelated work items are similar otherwise
+	SuggestRelated = "related"
+)
+
+// MaxSuggestions is the maximum number of suggested work items to link to
+const MaxSuggestions = 50
+
+const (
+	// suggestionCandidates is the number of work items with the most
+	// similar titles or shared labels that are scored
+	suggestionCandidates = 200
+	// duplicateTitleScore is the title similarity from which work items of
+	// the same type are likely duplicates
+	duplicateTitleScore = 0.5
+	// similarTitleScore is the title similarity from which it is a reason
+	similarTitleScore = 0.3
+	// recentLinking is how long links count as recent
+	recentLinking = 30 * 24 * time.Hour
+	// maxRecentLinks is the number of recent links from which the score no
+	// longer grows
+	maxRecentLinks = 5
+
+	// the weights of the title similarity, the shared labels and the recent
+	// links in the score
+	titleWeight  = 0.6
+	labelWeight  = 0.3
+	recentWeight = 0.1
+)
+
+// Suggestion is a work item the work item might be linked to
+type Suggestion struct {
+	WorkItemID uint64
+	Title      string
+	Type       string
+	Kind       string
+	// LinkTypeID is the tree link type linking a suggested parent
+	LinkTypeID *satoriuuid.UUID
+	// Score orders the suggestions, it is between 0 and 1
+	Score   float64
+	Reasons []string
+}
+
+// Suggest returns up to limit work items visible to the viewer of ctx that
+// are not linked to the work item yet, scored by the similarity of their
+// titles, their shared labels and how often they were linked recently, best
+// first
+// returns BadParameterError, NotFoundError or InternalError
+func (r *GormWorkItemLinkRepository) Suggest(ctx context.Context, wiIDStr string, limit int) ([]Suggestion, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitemlink", "suggest"}, time.Now())
+
+	if limit <= 0 || limit > MaxSuggestions {
+		return nil, errors.NewBadParameterError("limit", limit).Expected(fmt.Sprintf("between 1 and %d", MaxSuggestions))
+	}
+	wi, err := r.workItemRepo.LoadFromDB(wiIDStr)
+	if err != nil {
+		return nil, err
+	}
+	if !workitem.ContextViewer(ctx).CanSee(wi.Fields) {
+		return nil, errors.NewNotFoundError("work item", wiIDStr)
+	}
+	title, _ := wi.Fields[workitem.SystemTitle].(string)
+	labels := labelsOf(wi.Fields[workitem.SystemLabels])
+
+	table := workitem.WorkItem{}.TableName()
+	titleField := "coalesce(fields->>'" + workitem.SystemTitle + "', '')"
+	labelsField := "coalesce(fields->'" + workitem.SystemLabels + "', '[]')"
+	match := titleField + " % ?"
+	params := []interface{}{title}
+	if len(labels) > 0 {
+		match += " OR jsonb_exists_any(" + labelsField + ", ARRAY[?])"
+		params = append(params, labels)
+	}
+	var rows []struct {
+		ID          uint64
+		Type        string
+		Title       string
+		TitleScore  float64
+		Labels      string
+		RecentLinks int
+	}
+	db := r.db.Table(table).
+		Select("id, type, "+titleField+" AS title, similarity("+titleField+", ?) AS title_score, "+labelsField+" AS labels, "+
+			"(SELECT count(*) FROM work_item_links l WHERE l.deleted_at IS NULL AND "+table+".id IN (l.source_id, l.target_id) AND l.created_at > ?) AS recent_links",
+			title, time.Now().Add(-recentLinking)).
+		Where("deleted_at IS NULL AND id <> ?", wi.ID).
+		Where(match, params...).
+		Where("NOT EXISTS (SELECT 1 FROM work_item_links l WHERE l.deleted_at IS NULL AND "+
+			"((l.source_id = ? AND l.target_id = "+table+".id) OR (l.target_id = ? AND l.source_id = "+table+".id)))", wi.ID, wi.ID)
+	if clause, params := workitem.VisibilityClause(ctx, table); clause != "" {
+		db = db.Where(clause, params...)
+	}
+	if err := db.Order("title_score DESC, id DESC").Limit(suggestionCandidates).Scan(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+
+	parents, err := r.parentLinkTypes(wi.Type)
+	if err != nil {
+		return nil, err
+	}
+	types := map[string]*workitem.WorkItemType{}
+	res := []Suggestion{}
+	for _, row := range rows {
+		s := Suggestion{WorkItemID: row.ID, Title: row.Title, Type: row.Type, Kind: SuggestRelated, Reasons: []string{}}
+		if row.TitleScore >= similarTitleScore {
+			s.Score += titleWeight * row.TitleScore
+			s.Reasons = append(s.Reasons, fmt.Sprintf("similar title (%.0f%%)", row.TitleScore*100))
+		}
+		var candidateLabels []interface{}
+		// labels that aren't a list are ignored like missing ones
+		json.Unmarshal([]byte(row.Labels), &candidateLabels)
+		if shared, all := overlap(labels, labelsOf(candidateLabels)); len(shared) > 0 {
+			s.Score += labelWeight * float64(len(shared)) / float64(all)
+			s.Reasons = append(s.Reasons, "shares the labels "+strings.Join(shared, ", "))
+		}
+		if len(s.Reasons) == 0 {
+			// matched by the trigram index only
+			continue
+		}
+		if row.RecentLinks > 0 {
+			s.Score += recentWeight * float64(min(row.RecentLinks, maxRecentLinks)) / maxRecentLinks
+			s.Reasons = append(s.Reasons, fmt.Sprintf("linked %d times in the last %d days", row.RecentLinks, int(recentLinking.Hours()/24)))
+		}
+		if row.Type == wi.Type && row.TitleScore >= duplicateTitleScore {
+			s.Kind = SuggestDuplicate
+		} else if len(parents) > 0 {
+			wit, ok := types[row.Type]
+			if !ok {
+				if wit, err = r.workItemTypeRepo.LoadTypeFromDB(row.Type); err != nil {
+					return nil, err
+				}
+				types[row.Type] = wit
+			}
+			for _, lt := range parents {
+				if wit.IsTypeOrSubtypeOf(lt.SourceTypeName) {
+					id := lt.ID
+					s.Kind, s.LinkTypeID = SuggestParent, &id
+					s.Reasons = append(s.Reasons, fmt.Sprintf("can be linked as %q", lt.ForwardName))
+					break
+				}
+			}
+		}
+		res = append(res, s)
+	}
+	sort.Sort(byScore(res))
+	if len(res) > limit {
+		res = res[:limit]
+	}
+	return res, nil
+}
+
+// parentLinkTypes returns the tree link types that work items of the type can
+// be the child in, ordered by name
+func (r *GormWorkItemLinkRepository) parentLinkTypes(typeName string) ([]WorkItemLinkType, error) {
+	wit, err := r.workItemTypeRepo.LoadTypeFromDB(typeName)
+	if err != nil {
+		return nil, err
+	}
+	var treeTypes []WorkItemLinkType
+	if err := r.db.Where("topology = ?", TopologyTree).Order("name").Find(&treeTypes).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	var res []WorkItemLinkType
+	for _, lt := range treeTypes {
+		if wit.IsTypeOrSubtypeOf(lt.TargetTypeName) {
+			res = append(res, lt)
+		}
+	}
+	return res, nil
+}
+
+// labelsOf returns the labels of the value of a labels field
+func labelsOf(v interface{}) []string {
+	list, _ := v.([]interface{})
+	res := make([]string, 0, len(list))
+	for _, l := range list {
+		if s, ok := l.(string); ok {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// overlap returns the labels in both a and b and the number of distinct
+// labels in either
+func overlap(a []string, b []string) ([]string, int) {
+	inA := map[string]bool{}
+	for _, l := range a {
+		inA[l] = true
+	}
+	shared := []string{}
+	all := len(inA)
+	seen := map[string]bool{}
+	for _, l := range b {
+		if seen[l] {
+			continue
+		}
+		seen[l] = true
+		if inA[l] {
+			shared = append(shared, l)
+		} else {
+			all++
+		}
+	}
+	return shared, all
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// byScore orders suggestions by descending score, newer work items first on
+// equal scores
+type byScore []Suggestion
+
+func (s byScore) Len() int      { return len(s) }
+func (s byScore) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byScore) Less(i, j int) bool {
+	if s[i].Score != s[j].Score {
+		return s[i].Score > s[j].Score
+	}
+	return s[i].WorkItemID > s[j].WorkItemID
+}
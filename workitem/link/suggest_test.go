@@ -0,0 +1,67 @@
+package link_test
+
+import (
+	"strconv"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/almighty/almighty-core/workitem/link"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (test *TestLinkRepository) createLabeledBug(title string, labels ...interface{}) uint64 {
+	wi, err := workitem.NewWorkItemRepository(test.DB).Create(context.Background(), workitem.SystemBug,
+		map[string]interface{}{
+			workitem.SystemTitle:  title,
+			workitem.SystemState:  workitem.SystemStateOpen,
+			workitem.SystemLabels: labels,
+		}, "xx")
+	require.Nil(test.T(), err)
+	id, err := strconv.ParseUint(wi.ID, 10, 64)
+	require.Nil(test.T(), err)
+	return id
+}
+
+func (test *TestLinkRepository) TestSuggest() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	repo := link.NewWorkItemLinkRepository(test.DB)
+	linkTypeID := test.createLinkType("")
+	wi := test.createLabeledBug("Login page crashes on submit", "ui", "auth")
+	duplicate := test.createLabeledBug("Login page crashes on submit button")
+	labeled := test.createLabeledBug("Single sign-on rollout", "auth")
+	linked := test.createLabeledBug("Login page crashes on submit too", "ui")
+	unrelated := test.createLabeledBug("Quarterly report totals", "finance")
+	_, err := repo.Create(ctx, wi, linked, linkTypeID)
+	require.Nil(t, err)
+
+	suggestions, err := repo.Suggest(ctx, strconv.FormatUint(wi, 10), link.MaxSuggestions)
+	require.Nil(t, err)
+	byID := map[uint64]link.Suggestion{}
+	for _, s := range suggestions {
+		byID[s.WorkItemID] = s
+	}
+	require.Contains(t, byID, duplicate)
+	assert.Equal(t, link.SuggestDuplicate, byID[duplicate].Kind)
+	require.Contains(t, byID, labeled)
+	assert.Equal(t, link.SuggestParent, byID[labeled].Kind)
+	assert.Equal(t, linkTypeID, *byID[labeled].LinkTypeID)
+	assert.Contains(t, byID[labeled].Reasons, "shares the labels auth")
+	assert.True(t, byID[duplicate].Score > byID[labeled].Score)
+	assert.NotContains(t, byID, wi)
+	assert.NotContains(t, byID, linked)
+	assert.NotContains(t, byID, unrelated)
+
+	suggestions, err = repo.Suggest(ctx, strconv.FormatUint(wi, 10), 1)
+	require.Nil(t, err)
+	assert.Len(t, suggestions, 1)
+
+	_, err = repo.Suggest(ctx, strconv.FormatUint(wi, 10), link.MaxSuggestions+1)
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
@@ -72,6 +72,11 @@ func TestWorkItemLinkType_Equal(t *testing.T) {
 	b.Topology = "tree"
 	require.False(t, a.Equal(b))
 
+	// Test OnDelete
+	b = a
+	b.OnDelete = link.OnDeleteBlock
+	require.False(t, a.Equal(b))
+
 	// Test SourceTypeName
 	b = a
 	b.SourceTypeName = "foobar"
@@ -150,6 +155,11 @@ func TestWorkItemLinkTypeCheckValidForCreation(t *testing.T) {
 	b.Topology = ""
 	require.NotNil(t, b.CheckValidForCreation())
 
+	// Check invalid OnDelete
+	b = a
+	b.OnDelete = "ignore"
+	require.NotNil(t, b.CheckValidForCreation())
+
 	// Check empty LinkCategoryID
 	b = a
 	b.LinkCategoryID = satoriuuid.Nil
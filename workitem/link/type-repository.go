@@ -14,10 +14,10 @@ import (
 
 // WorkItemLinkTypeRepository encapsulates storage & retrieval of work item link types
 type WorkItemLinkTypeRepository interface {
-	Create(ctx context.Context, name string, description *string, sourceTypeName, targetTypeName, forwardName, reverseName, topology string, linkCategory satoriuuid.UUID) (*app.WorkItemLinkTypeSingle, error)
+	Create(ctx context.Context, name string, description *string, sourceTypeName, targetTypeName, forwardName, reverseName, topology, onDelete string, linkCategory satoriuuid.UUID) (*app.WorkItemLinkTypeSingle, error)
 	Load(ctx context.Context, ID string) (*app.WorkItemLinkTypeSingle, error)
 	List(ctx context.Context) (*app.WorkItemLinkTypeList, error)
-	Delete(ctx context.Context, ID string) error
+	Delete(ctx context.Context, ID string, force bool) error
 	Save(ctx context.Context, linkCat app.WorkItemLinkTypeSingle) (*app.WorkItemLinkTypeSingle, error)
 }
 
@@ -31,9 +31,13 @@ type GormWorkItemLinkTypeRepository struct {
 	db *gorm.DB
 }
 
-// Create creates a new work item link type in the repository.
+// Create creates a new work item link type in the repository. An empty
+// onDelete detaches the links.
 // Returns BadParameterError, ConversionError or InternalError
-func (r *GormWorkItemLinkTypeRepository) Create(ctx context.Context, name string, description *string, sourceTypeName, targetTypeName, forwardName, reverseName, topology string, linkCategoryID satoriuuid.UUID) (*app.WorkItemLinkTypeSingle, error) {
+func (r *GormWorkItemLinkTypeRepository) Create(ctx context.Context, name string, description *string, sourceTypeName, targetTypeName, forwardName, reverseName, topology, onDelete string, linkCategoryID satoriuuid.UUID) (*app.WorkItemLinkTypeSingle, error) {
+	if onDelete == "" {
+		onDelete = OnDeleteDetach
+	}
 	linkType := &WorkItemLinkType{
 		Name:           name,
 		Description:    description,
@@ -42,6 +46,7 @@ func (r *GormWorkItemLinkTypeRepository) Create(ctx context.Context, name string
 		ForwardName:    forwardName,
 		ReverseName:    reverseName,
 		Topology:       topology,
+		OnDelete:       onDelete,
 		LinkCategoryID: linkCategoryID,
 	}
 	if err := linkType.CheckValidForCreation(); err != nil {
@@ -144,9 +149,11 @@ func (r *GormWorkItemLinkTypeRepository) List(ctx context.Context) (*app.WorkIte
 	return &res, nil
 }
 
-// Delete deletes the work item link type with the given id
-// returns NotFoundError or InternalError
-func (r *GormWorkItemLinkTypeRepository) Delete(ctx context.Context, ID string) error {
+// Delete deletes the work item link type with the given id. If links of the
+// type exist they are deleted with it when force is set, otherwise the link
+// type is kept and a DataConflictError is returned.
+// returns NotFoundError, DataConflictError or InternalError
+func (r *GormWorkItemLinkTypeRepository) Delete(ctx context.Context, ID string, force bool) error {
 	id, err := satoriuuid.FromString(ID)
 	if err != nil {
 		// treat as not found: clients don't know it must be a UUID
@@ -155,8 +162,31 @@ func (r *GormWorkItemLinkTypeRepository) Delete(ctx context.Context, ID string)
 	var cat = WorkItemLinkType{
 		ID: id,
 	}
+	db := r.db.Where("id = ?", id).First(&cat)
+	if db.RecordNotFound() {
+		return errors.NewNotFoundError("work item link type", id.String())
+	}
+	if db.Error != nil {
+		return errors.NewInternalError(db.Error.Error())
+	}
+	var links int
+	db = r.db.Model(&WorkItemLink{}).Where("link_type_id = ?", id).Count(&links)
+	if db.Error != nil {
+		return errors.NewInternalError(db.Error.Error())
+	}
+	if links > 0 {
+		if !force {
+			return errors.NewDataConflictError(fmt.Sprintf("work item link type %s is used by %d links, delete them first or force the deletion", id, links))
+		}
+		// the links and the type go together or not at all, callers run this
+		// within a transaction
+		db = r.db.Where("link_type_id = ?", id).Delete(&WorkItemLink{})
+		if db.Error != nil {
+			return errors.NewInternalError(db.Error.Error())
+		}
+	}
 	log.Printf("work item link type to delete %v\n", cat)
-	db := r.db.Delete(&cat)
+	db = r.db.Delete(&cat)
 	if db.Error != nil {
 		return errors.NewInternalError(db.Error.Error())
 	}
@@ -5,6 +5,7 @@ import (
 	convert "github.com/almighty/almighty-core/convert"
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/workitem"
 	satoriuuid "github.com/satori/go.uuid"
 )
 
@@ -14,6 +15,12 @@ const (
 	TopologyDependency      = "dependency"
 	TopologyTree            = "tree"
 
+	// What happens to the links of a work item that gets deleted, see
+	// WorkItemLinkType.OnDelete
+	OnDeleteDetach  = workitem.LinkOnDeleteDetach
+	OnDeleteCascade = workitem.LinkOnDeleteCascade
+	OnDeleteBlock   = workitem.LinkOnDeleteBlock
+
 	// The names of a work item link type are basically the "system.title" field
 	// as in work items. The actual linking is done with UUIDs. Hence, the names
 	// hare are more human-readable.
@@ -49,6 +56,10 @@ type WorkItemLinkType struct {
 	// Version for optimistic concurrency control
 	Version  int
 	Topology string // Valid values: network, directed_network, dependency, tree
+	// OnDelete is what happens to links of this type when an end gets
+	// deleted: they are deleted ("detach"), the deletion of a source also
+	// deletes its targets ("cascade") or the deletion is refused ("block")
+	OnDelete string
 
 	SourceTypeName string
 	TargetTypeName string
@@ -87,6 +98,9 @@ func (self WorkItemLinkType) Equal(u convert.Equaler) bool {
 	if self.Topology != other.Topology {
 		return false
 	}
+	if self.OnDelete != other.OnDelete {
+		return false
+	}
 	if self.SourceTypeName != other.SourceTypeName {
 		return false
 	}
@@ -126,6 +140,12 @@ func (t *WorkItemLinkType) CheckValidForCreation() error {
 	if err := CheckValidTopology(t.Topology); err != nil {
 		return err
 	}
+	// an empty behavior on deletion defaults to detaching the links
+	if t.OnDelete != "" {
+		if err := CheckValidOnDelete(t.OnDelete); err != nil {
+			return err
+		}
+	}
 	if t.LinkCategoryID == satoriuuid.Nil {
 		return errors.NewBadParameterError("link_category_id", t.LinkCategoryID)
 	}
@@ -141,6 +161,15 @@ func CheckValidTopology(t string) error {
 	return nil
 }
 
+// CheckValidOnDelete returns nil if the given behavior on deletion is valid;
+// otherwise a BadParameterError is returned.
+func CheckValidOnDelete(onDelete string) error {
+	if onDelete != OnDeleteDetach && onDelete != OnDeleteCascade && onDelete != OnDeleteBlock {
+		return errors.NewBadParameterError("on_delete", onDelete).Expected(OnDeleteDetach + "|" + OnDeleteCascade + "|" + OnDeleteBlock)
+	}
+	return nil
+}
+
 // ConvertLinkTypeFromModel converts a work item link type from model to REST representation
 func ConvertLinkTypeFromModel(t WorkItemLinkType) app.WorkItemLinkTypeSingle {
 	id := t.ID.String()
@@ -178,6 +207,9 @@ func ConvertLinkTypeFromModel(t WorkItemLinkType) app.WorkItemLinkTypeSingle {
 			},
 		},
 	}
+	if t.OnDelete != "" {
+		converted.Data.Attributes.OnDelete = &t.OnDelete
+	}
 	return converted
 }
 
@@ -241,6 +273,13 @@ func ConvertLinkTypeToModel(in app.WorkItemLinkTypeSingle, out *WorkItemLinkType
 			}
 			out.Topology = *attrs.Topology
 		}
+
+		if attrs.OnDelete != nil {
+			if err := CheckValidOnDelete(*attrs.OnDelete); err != nil {
+				return err
+			}
+			out.OnDelete = *attrs.OnDelete
+		}
 	}
 
 	if rel != nil && rel.LinkCategory != nil && rel.LinkCategory.Data != nil {
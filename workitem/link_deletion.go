@@ -0,0 +1,87 @@
+package workitem
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+)
+
+// Behaviors of work item link types when an end of a link gets deleted. The
+// link package owns the link types, they are defined here since deleting
+// work items enforces them.
+const (
+	// LinkOnDeleteDetach deletes the links of the deleted work item
+	LinkOnDeleteDetach = "detach"
+	// LinkOnDeleteCascade deletes the targets of the links the deleted work
+	// item is the source of, with their own links
+	LinkOnDeleteCascade = "cascade"
+	// LinkOnDeleteBlock refuses to delete work items with links of the type
+	LinkOnDeleteBlock = "block"
+)
+
+// deleteWithLinks deletes the work item and enforces the behaviors of the
+// types of its links. Work items deleted by a cascade are deleted regardless
+// of the viewer of ctx. Nothing is deleted if any of them is blocked. The
+// links left are detached by the database.
+// returns BadParameterError, NotFoundError or InternalError
+func (r *GormWorkItemRepository) deleteWithLinks(ctx context.Context, wi WorkItem) error {
+	ids := []uint64{wi.ID}
+	found := map[uint64]bool{wi.ID: true}
+	for i := 0; i < len(ids); i++ {
+		if err := r.checkNotBlocked(ids[i]); err != nil {
+			return err
+		}
+		var targets []struct{ TargetID uint64 }
+		err := r.db.Raw(`SELECT DISTINCT l.target_id FROM work_item_links l JOIN work_item_link_types lt ON lt.id = l.link_type_id
+			WHERE l.deleted_at IS NULL AND lt.deleted_at IS NULL AND lt.on_delete = ? AND l.source_id = ?
+			ORDER BY l.target_id`, LinkOnDeleteCascade, ids[i]).Scan(&targets).Error
+		if err != nil {
+			return errors.NewInternalError(err.Error())
+		}
+		for _, t := range targets {
+			if !found[t.TargetID] {
+				found[t.TargetID] = true
+				ids = append(ids, t.TargetID)
+			}
+		}
+	}
+
+	if err := apply(r.db, newEvent(ctx, EventDelete, wi)); err != nil {
+		return err
+	}
+	for _, id := range ids[1:] {
+		target, err := r.LoadFromDB(strconv.FormatUint(id, 10))
+		if err != nil {
+			return err
+		}
+		if err := apply(r.db, newEvent(ctx, EventDelete, *target)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkNotBlocked returns an error if the work item has links of a type that
+// blocks its deletion
+// returns BadParameterError or InternalError
+func (r *GormWorkItemRepository) checkNotBlocked(id uint64) error {
+	var blocking []struct{ Name string }
+	err := r.db.Raw(`SELECT DISTINCT lt.name FROM work_item_links l JOIN work_item_link_types lt ON lt.id = l.link_type_id
+		WHERE l.deleted_at IS NULL AND lt.deleted_at IS NULL AND lt.on_delete = ? AND ? IN (l.source_id, l.target_id)
+		ORDER BY lt.name`, LinkOnDeleteBlock, id).Scan(&blocking).Error
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if len(blocking) == 0 {
+		return nil
+	}
+	names := make([]string, len(blocking))
+	for i, b := range blocking {
+		names[i] = b.Name
+	}
+	return errors.NewBadParameterError("id", id).Expected(fmt.Sprintf("a work item without %s links", strings.Join(names, ", ")))
+}
@@ -0,0 +1,78 @@
+package workitem
+
+import (
+	"strconv"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/redirect"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+)
+
+// MergeRepository encapsulates merging duplicate work items
+type MergeRepository interface {
+	Merge(ctx context.Context, ID string, targetID string) (*app.WorkItem, error)
+}
+
+// NewMergeRepository creates a new storage type.
+func NewMergeRepository(db *gorm.DB) MergeRepository {
+	return &GormMergeRepository{db: db, wir: NewWorkItemRepository(db)}
+}
+
+// GormMergeRepository is the implementation of the storage interface for
+// merging work items.
+type GormMergeRepository struct {
+	db  *gorm.DB
+	wir *GormWorkItemRepository
+}
+
+// Merge merges the work item with the given ID into the target and returns
+// the target. The comments of the work item move to the target, the work item
+// is deleted like by Delete and its ID and key are redirected to the target.
+// Restoring it from the trash removes the redirects, the comments stay.
+// returns BadParameterError, NotFoundError, ConversionError or InternalError
+func (m *GormMergeRepository) Merge(ctx context.Context, ID string, targetID string) (*app.WorkItem, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "merge", "merge"}, time.Now())
+
+	source, err := m.wir.LoadFromDB(ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ContextViewer(ctx).CanSee(source.Fields) {
+		return nil, errors.NewNotFoundError("work item", ID)
+	}
+	target, err := m.wir.Load(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	sourceID := strconv.FormatUint(source.ID, 10)
+	if sourceID == target.ID {
+		return nil, errors.NewBadParameterError("into", targetID).Expected("another work item")
+	}
+	if err := m.db.Exec("UPDATE comments SET parent_id = ? WHERE parent_id = ?", target.ID, sourceID).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	if err := m.wir.deleteWithLinks(ctx, *source); err != nil {
+		return nil, err
+	}
+	if _, err := m.wir.LoadFromDB(target.ID); err != nil {
+		if _, ok := err.(errors.NotFoundError); ok {
+			return nil, errors.NewBadParameterError("into", targetID).Expected("a work item that isn't deleted with the merged one")
+		}
+		return nil, err
+	}
+	redirects := redirect.NewRedirectRepository(m.db)
+	if err := redirects.Add(ctx, redirect.KindWorkItem, sourceID, target.ID); err != nil {
+		return nil, err
+	}
+	if source.Key != nil {
+		if err := redirects.Add(ctx, redirect.KindWorkItem, *source.Key, target.ID); err != nil {
+			return nil, err
+		}
+	}
+	return m.wir.Load(ctx, target.ID)
+}
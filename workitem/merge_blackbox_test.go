@@ -0,0 +1,70 @@
+package workitem_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/redirect"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/context"
+)
+
+type mergeRepoBlackBoxTest struct {
+	gormsupport.DBTestSuite
+	clean func()
+}
+
+func TestRunMergeRepoBlackBoxTest(t *testing.T) {
+	suite.Run(t, &mergeRepoBlackBoxTest{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *mergeRepoBlackBoxTest) SetupTest() {
+	s.clean = gormsupport.DeleteCreatedEntities(s.DB)
+}
+
+func (s *mergeRepoBlackBoxTest) TearDownTest() {
+	s.clean()
+}
+
+func (s *mergeRepoBlackBoxTest) create(title string) string {
+	wi, err := workitem.NewWorkItemRepository(s.DB).Create(context.Background(), workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle: title,
+		workitem.SystemState: workitem.SystemStateNew,
+	}, "xx")
+	require.Nil(s.T(), err)
+	return wi.ID
+}
+
+func (s *mergeRepoBlackBoxTest) TestMerge() {
+	t := s.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	duplicate, original := s.create("duplicate"), s.create("original")
+	comments := comment.NewCommentRepository(s.DB)
+	require.Nil(t, comments.Create(ctx, &comment.Comment{ParentID: duplicate, Body: "same here", CreatedBy: uuid.NewV4()}))
+
+	repo := workitem.NewMergeRepository(s.DB)
+	_, err := repo.Merge(ctx, original, original)
+	assert.IsType(t, errors.BadParameterError{}, err)
+	wi, err := repo.Merge(ctx, duplicate, original)
+	require.Nil(t, err)
+	assert.Equal(t, original, wi.ID)
+
+	_, err = workitem.NewWorkItemRepository(s.DB).Load(ctx, duplicate)
+	assert.IsType(t, errors.NotFoundError{}, err)
+	moved, err := comments.List(ctx, original)
+	require.Nil(t, err)
+	require.Len(t, moved, 1)
+	assert.Equal(t, "same here", moved[0].Body)
+	target, err := redirect.NewRedirectRepository(s.DB).Resolve(ctx, redirect.KindWorkItem, duplicate)
+	require.Nil(t, err)
+	assert.Equal(t, original, target)
+}
@@ -0,0 +1,51 @@
+package workitem
+
+import (
+	"time"
+
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
+)
+
+// RelativeDates lists the names of the date ranges relative to now
+var RelativeDates = []string{"today", "yesterday", "this-week", "last-week", "this-month", "last-month"}
+
+// RelativeRange returns the start and the end of the named date range
+// relative to now, in the location of now. Weeks start on Monday.
+// returns BadParameterError
+func RelativeRange(name string, now time.Time) (time.Time, time.Time, error) {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	// days since Monday
+	weekday := (int(day.Weekday()) + 6) % 7
+	week := day.AddDate(0, 0, -weekday)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	switch name {
+	case "today":
+		return day, day.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return day.AddDate(0, 0, -1), day, nil
+	case "this-week":
+		return week, week.AddDate(0, 0, 7), nil
+	case "last-week":
+		return week.AddDate(0, 0, -7), week, nil
+	case "this-month":
+		return month, month.AddDate(0, 1, 0), nil
+	case "last-month":
+		return month.AddDate(0, -1, 0), month, nil
+	}
+	return time.Time{}, time.Time{}, errors.NewBadParameterError("date range", name).Expected(RelativeDates)
+}
+
+// UpdatedWithin returns the criteria matching the work items changed last in
+// the named date range relative to now
+// returns BadParameterError
+func UpdatedWithin(name string, now time.Time) (criteria.Expression, error) {
+	from, to, err := RelativeRange(name, now)
+	if err != nil {
+		return nil, err
+	}
+	return criteria.And(
+		criteria.GreaterOrEqual(criteria.Field(UpdatedAtField), criteria.Literal(from)),
+		criteria.LessThan(criteria.Field(UpdatedAtField), criteria.Literal(to)),
+	), nil
+}
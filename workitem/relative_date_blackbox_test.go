@@ -0,0 +1,42 @@
+package workitem_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelativeRange(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.Nil(t, err)
+	// Monday just after midnight in Berlin, still Sunday in UTC
+	now := time.Date(2017, 1, 2, 0, 30, 0, 0, berlin)
+
+	from, to, err := workitem.RelativeRange("this-week", now)
+	require.Nil(t, err)
+	assert.Equal(t, time.Date(2017, 1, 2, 0, 0, 0, 0, berlin), from)
+	assert.Equal(t, time.Date(2017, 1, 9, 0, 0, 0, 0, berlin), to)
+	from, _, err = workitem.RelativeRange("this-week", now.UTC())
+	require.Nil(t, err)
+	assert.Equal(t, time.Date(2016, 12, 26, 0, 0, 0, 0, time.UTC), from)
+
+	from, to, err = workitem.RelativeRange("yesterday", now)
+	require.Nil(t, err)
+	assert.Equal(t, time.Date(2017, 1, 1, 0, 0, 0, 0, berlin), from)
+	assert.Equal(t, time.Date(2017, 1, 2, 0, 0, 0, 0, berlin), to)
+	from, to, err = workitem.RelativeRange("last-month", now)
+	require.Nil(t, err)
+	assert.Equal(t, time.Date(2016, 12, 1, 0, 0, 0, 0, berlin), from)
+	assert.Equal(t, time.Date(2017, 1, 1, 0, 0, 0, 0, berlin), to)
+
+	_, _, err = workitem.RelativeRange("next-year", now)
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
@@ -0,0 +1,67 @@
+package workitem
+
+import (
+	"sort"
+
+	"github.com/almighty/almighty-core/textmerge"
+)
+
+// FieldConflict is a field both a sync client and someone else changed
+// differently since the version the client's changes are based on
+type FieldConflict struct {
+	Field  string
+	Base   interface{}
+	Ours   interface{}
+	Theirs interface{}
+}
+
+// MergeFields merges the fields of a work item changed from base to ours by a
+// sync client with the ones changed from base to theirs since, field by field
+// like a three-way merge. A field changed only on one side takes the changed
+// value, text changed on both sides is merged line by line. The conflicts
+// are the fields changed on both sides that can't be merged, ordered by
+// name; the merged fields keep theirs for them. A missing field is the same
+// as a field without value.
+func MergeFields(base, ours, theirs map[string]interface{}) (map[string]interface{}, []*FieldConflict) {
+	merged := make(map[string]interface{}, len(theirs))
+	for name, value := range theirs {
+		merged[name] = value
+	}
+	var names []string
+	for name := range ours {
+		names = append(names, name)
+	}
+	for name := range base {
+		if _, ok := ours[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	conflicts := []*FieldConflict{}
+	for _, name := range names {
+		b, o, t := base[name], ours[name], theirs[name]
+		switch {
+		case sameValue(o, b), sameValue(o, t):
+			continue
+		case sameValue(t, b):
+			if o == nil {
+				delete(merged, name)
+			} else {
+				merged[name] = o
+			}
+			continue
+		}
+		bs, okB := b.(string)
+		os, okO := o.(string)
+		ts, okT := t.(string)
+		if okO && okT && (okB || b == nil) {
+			if text, ok := textmerge.Merge(bs, os, ts); ok {
+				merged[name] = text
+				continue
+			}
+		}
+		conflicts = append(conflicts, &FieldConflict{Field: name, Base: b, Ours: o, Theirs: t})
+	}
+	return merged, conflicts
+}
@@ -0,0 +1,50 @@
+package workitem_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeFields(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	base := map[string]interface{}{
+		workitem.SystemTitle:       "title",
+		workitem.SystemDescription: "one\ntwo\nthree\n",
+		workitem.SystemState:       workitem.SystemStateNew,
+		workitem.SystemAssignees:   []string{"a"},
+	}
+	ours := map[string]interface{}{
+		workitem.SystemTitle:       "our title",
+		workitem.SystemDescription: "ONE\ntwo\nthree\n",
+		workitem.SystemState:       workitem.SystemStateOpen,
+		"storypoints":              3.0,
+	}
+	theirs := map[string]interface{}{
+		workitem.SystemTitle:       "their title",
+		workitem.SystemDescription: "one\ntwo\nTHREE\n",
+		workitem.SystemState:       workitem.SystemStateOpen,
+		workitem.SystemAssignees:   []string{"a"},
+		"storypoints":              3,
+		"priority":                 "high",
+	}
+
+	merged, conflicts := workitem.MergeFields(base, ours, theirs)
+	assert.Equal(t, map[string]interface{}{
+		workitem.SystemTitle:       "their title",
+		workitem.SystemDescription: "ONE\ntwo\nTHREE\n",
+		workitem.SystemState:       workitem.SystemStateOpen,
+		"storypoints":              3,
+		"priority":                 "high",
+	}, merged)
+	assert.Equal(t, []*workitem.FieldConflict{
+		{Field: workitem.SystemTitle, Base: "title", Ours: "our title", Theirs: "their title"},
+	}, conflicts)
+
+	merged, conflicts = workitem.MergeFields(base, base, theirs)
+	assert.Equal(t, theirs, merged)
+	assert.Empty(t, conflicts)
+}
@@ -42,7 +42,7 @@ func (fieldType SimpleType) ConvertToModel(value interface{}) (interface{}, erro
 	}
 	valueType := reflect.TypeOf(value)
 	switch fieldType.GetKind() {
-	case KindString, KindUser, KindIteration:
+	case KindString, KindUser, KindIteration, KindRelease, KindProject:
 		if valueType.Kind() != reflect.String {
 			return nil, fmt.Errorf("value %v should be %s, but is %s", value, "string", valueType.Name())
 		}
@@ -62,6 +62,11 @@ func (fieldType SimpleType) ConvertToModel(value interface{}) (interface{}, erro
 			return nil, fmt.Errorf("value %v should be %s, but is %s", value, "int", valueType.Name())
 		}
 		return value, nil
+	case KindBoolean:
+		if valueType.Kind() != reflect.Bool {
+			return nil, fmt.Errorf("value %v should be %s, but is %s", value, "bool", valueType.Name())
+		}
+		return value, nil
 	case KindInstant:
 		// instant == milliseconds
 		if !valueType.Implements(timeType) {
@@ -82,6 +87,12 @@ func (fieldType SimpleType) ConvertToModel(value interface{}) (interface{}, erro
 	case KindEnum:
 		// to be done yet | not sure what to write here as of now.
 		return value, nil
+	case KindChecklist:
+		items, err := ParseChecklist(value)
+		if err != nil {
+			return nil, err
+		}
+		return checklistValue(items), nil
 	default:
 		return nil, fmt.Errorf("unexpected type constant: %d", fieldType.GetKind())
 	}
@@ -91,7 +102,7 @@ func (fieldType SimpleType) ConvertToModel(value interface{}) (interface{}, erro
 func (fieldType SimpleType) ConvertFromModel(value interface{}) (interface{}, error) {
 	valueType := reflect.TypeOf(value)
 	switch fieldType.GetKind() {
-	case KindString, KindURL, KindUser, KindInteger, KindFloat, KindDuration, KindIteration:
+	case KindString, KindURL, KindUser, KindInteger, KindFloat, KindDuration, KindBoolean, KindIteration, KindRelease, KindProject, KindChecklist:
 		return value, nil
 	case KindInstant:
 		return time.Unix(0, value.(int64)), nil
@@ -0,0 +1,79 @@
+package workitem
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/almighty/almighty-core/errors"
+)
+
+// SortByVotes sorts work items by the number of votes they received
+const SortByVotes = "votes"
+
+// SortByUpdated sorts work items by the time of their last change
+const SortByUpdated = "updated"
+
+// SortKey orders a list of work items by a column or a field
+type SortKey struct {
+	Field      string
+	Descending bool
+	// Values orders the field by the position of its value in the list
+	// instead of lexically. Unknown values come after all listed ones.
+	Values []string
+}
+
+// ParseSort parses a comma separated list of field names, a leading "-"
+// sorts the field in descending order, e.g. "-system.priority,id".
+// returns BadParameterError for an invalid field name
+func ParseSort(s string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		key := SortKey{}
+		if strings.HasPrefix(f, "-") {
+			key.Descending = true
+			f = f[1:]
+		}
+		if f == "" || strings.ContainsAny(f, "'\"") {
+			return nil, errors.NewBadParameterError("sort", s).Expected("comma separated field names")
+		}
+		key.Field = f
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// orderClause compiles the sort keys to an ORDER BY clause, the work item ID
+// is always used as the last key to get a stable order across pages.
+func orderClause(keys []SortKey) string {
+	var terms []string
+	hasID := false
+	for _, k := range keys {
+		var term string
+		switch {
+		case k.Field == "id" || k.Field == "ID":
+			hasID = true
+			term = "id"
+		case k.Field == SortByVotes:
+			term = "(SELECT count(*) FROM work_item_votes v WHERE v.work_item_id = work_items.id AND v.deleted_at IS NULL)"
+		case k.Field == SortByUpdated:
+			term = "updated_at"
+		case len(k.Values) > 0:
+			term = "CASE Fields->>'" + k.Field + "'"
+			for i, v := range k.Values {
+				term += fmt.Sprintf(" WHEN '%s' THEN %d", strings.Replace(v, "'", "''", -1), i)
+			}
+			term += fmt.Sprintf(" ELSE %d END", len(k.Values))
+		default:
+			term = "Fields->>'" + k.Field + "'"
+		}
+		if k.Descending {
+			term += " DESC"
+		}
+		terms = append(terms, term)
+	}
+	if !hasID {
+		terms = append(terms, "id")
+	}
+	return strings.Join(terms, ", ")
+}
@@ -0,0 +1,38 @@
+package workitem
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSort(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	keys, err := ParseSort("-system.priority, id")
+	require.Nil(t, err)
+	assert.Equal(t, []SortKey{{Field: SystemPriority, Descending: true}, {Field: "id"}}, keys)
+
+	_, err = ParseSort("system.title,")
+	assert.IsType(t, errors.BadParameterError{}, err)
+	_, err = ParseSort("x' or 1=1")
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
+
+func TestOrderClause(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	assert.Equal(t, "id", orderClause(nil))
+	assert.Equal(t, "(SELECT count(*) FROM work_item_votes v WHERE v.work_item_id = work_items.id AND v.deleted_at IS NULL) DESC, id",
+		orderClause([]SortKey{{Field: SortByVotes, Descending: true}}))
+	assert.Equal(t, "updated_at DESC, id", orderClause([]SortKey{{Field: SortByUpdated, Descending: true}}))
+	assert.Equal(t, "Fields->>'system.title', id DESC", orderClause([]SortKey{{Field: SystemTitle}, {Field: "id", Descending: true}}))
+	assert.Equal(t,
+		"CASE Fields->>'system.priority' WHEN 'high' THEN 0 WHEN 'won''t fix' THEN 1 ELSE 2 END DESC, id",
+		orderClause([]SortKey{{Field: SystemPriority, Descending: true, Values: []string{"high", "won't fix"}}}))
+}
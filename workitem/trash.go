@@ -0,0 +1,215 @@
+package workitem
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/redirect"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// MaxTrashed is the number of deleted work items the trash of a project lists
+const MaxTrashed = 500
+
+// Trashed is a deleted work item that can still be restored
+type Trashed struct {
+	Item      *app.WorkItem
+	DeletedAt time.Time
+	// DeletedBy is the identity that deleted the work item, nil for system tasks
+	DeletedBy *uuid.UUID
+}
+
+// TrashRepository encapsulates the deleted work items of projects
+type TrashRepository interface {
+	List(ctx context.Context, projectID uuid.UUID) ([]Trashed, error)
+	Restore(ctx context.Context, projectID uuid.UUID, workItemID string) (*app.WorkItem, error)
+	Purge(ctx context.Context, projectID uuid.UUID, workItemID string) error
+	PurgeBefore(ctx context.Context, before time.Time, except ...uuid.UUID) (int64, error)
+	PurgeProjectBefore(ctx context.Context, projectID uuid.UUID, before time.Time) (int64, error)
+}
+
+// NewTrashRepository creates a new storage type.
+func NewTrashRepository(db *gorm.DB) TrashRepository {
+	return &GormTrashRepository{db: db, wir: NewWorkItemTypeRepository(db)}
+}
+
+// GormTrashRepository is the implementation of the storage interface for
+// deleted work items.
+type GormTrashRepository struct {
+	db  *gorm.DB
+	wir *GormWorkItemTypeRepository
+}
+
+// trashClause matches the deleted work items of a project
+var trashClause = fmt.Sprintf("deleted_at IS NOT NULL AND fields->>'%s' = ?", SystemProject)
+
+// List returns the deleted work items of the project that are visible to the
+// viewer of ctx, the latest deleted first
+// returns InternalError
+func (m *GormTrashRepository) List(ctx context.Context, projectID uuid.UUID) ([]Trashed, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "trash", "list"}, time.Now())
+
+	db := m.db.Unscoped().Where(trashClause, projectID.String())
+	if clause, params := VisibilityClause(ctx, WorkItem{}.TableName()); clause != "" {
+		db = db.Where(clause, params...)
+	}
+	var rows []WorkItem
+	if err := db.Order("deleted_at DESC, id DESC").Limit(MaxTrashed).Find(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	if len(rows) == 0 {
+		return []Trashed{}, nil
+	}
+	ids := make([]uint64, len(rows))
+	for i, wi := range rows {
+		ids[i] = wi.ID
+	}
+	var deletions []struct {
+		WorkItemID uint64
+		ModifierID *uuid.UUID
+	}
+	err := m.db.Raw(`SELECT DISTINCT ON (work_item_id) work_item_id, modifier_id FROM work_item_events
+		WHERE work_item_id IN (?) AND kind = ? ORDER BY work_item_id, sequence DESC`, ids, EventDelete).Scan(&deletions).Error
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	deletedBy := make(map[uint64]*uuid.UUID, len(deletions))
+	for _, d := range deletions {
+		deletedBy[d.WorkItemID] = d.ModifierID
+	}
+	res := make([]Trashed, 0, len(rows))
+	for i := range rows {
+		wi := &rows[i]
+		wiType, err := m.wir.LoadTypeFromDB(wi.Type)
+		if err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		item, err := convertWorkItemModelToApp(ctx, wiType, wi)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, Trashed{Item: item, DeletedAt: *wi.DeletedAt, DeletedBy: deletedBy[wi.ID]})
+	}
+	return res, nil
+}
+
+// Restore takes the deleted work item out of the trash of the project. The
+// links its deletion detached come back if their other end still exists.
+// returns NotFoundError, ConversionError or InternalError
+func (m *GormTrashRepository) Restore(ctx context.Context, projectID uuid.UUID, workItemID string) (*app.WorkItem, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "trash", "restore"}, time.Now())
+
+	wi, err := m.load(ctx, projectID, workItemID)
+	if err != nil {
+		return nil, err
+	}
+	if err := apply(m.db, newEvent(ctx, EventRestore, *wi)); err != nil {
+		return nil, err
+	}
+	// a merged work item is a work item of its own again
+	sources := []string{strconv.FormatUint(wi.ID, 10)}
+	if wi.Key != nil {
+		sources = append(sources, *wi.Key)
+	}
+	if err := redirect.NewRedirectRepository(m.db).Remove(ctx, redirect.KindWorkItem, sources...); err != nil {
+		return nil, err
+	}
+	wi.DeletedAt = nil
+	wiType, err := m.wir.LoadTypeFromDB(wi.Type)
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return convertWorkItemModelToApp(ctx, wiType, wi)
+}
+
+// Purge permanently deletes the deleted work item of the project with its
+// events, links and comments
+// returns NotFoundError or InternalError
+func (m *GormTrashRepository) Purge(ctx context.Context, projectID uuid.UUID, workItemID string) error {
+	defer goa.MeasureSince([]string{"goa", "db", "trash", "purge"}, time.Now())
+
+	wi, err := m.load(ctx, projectID, workItemID)
+	if err != nil {
+		return err
+	}
+	_, err = m.purge(m.db.Unscoped().Model(&WorkItem{}).Where("id = ?", wi.ID))
+	return err
+}
+
+// PurgeBefore permanently deletes all work items deleted before the given
+// time, except the ones of the given projects, and returns how many there
+// were
+// returns InternalError
+func (m *GormTrashRepository) PurgeBefore(ctx context.Context, before time.Time, except ...uuid.UUID) (int64, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "trash", "purgebefore"}, time.Now())
+
+	db := m.db.Unscoped().Model(&WorkItem{}).Where("deleted_at IS NOT NULL AND deleted_at < ?", before)
+	if len(except) > 0 {
+		projects := make([]string, len(except))
+		for i, id := range except {
+			projects[i] = id.String()
+		}
+		db = db.Where(fmt.Sprintf("fields->>'%s' IS NULL OR fields->>'%s' NOT IN (?)", SystemProject, SystemProject), projects)
+	}
+	return m.purge(db)
+}
+
+// PurgeProjectBefore permanently deletes the work items of the project
+// deleted before the given time and returns how many there were
+// returns InternalError
+func (m *GormTrashRepository) PurgeProjectBefore(ctx context.Context, projectID uuid.UUID, before time.Time) (int64, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "trash", "purgeprojectbefore"}, time.Now())
+
+	return m.purge(m.db.Unscoped().Model(&WorkItem{}).Where(trashClause, projectID.String()).Where("deleted_at < ?", before))
+}
+
+// purge hard deletes the deleted work items selected by db, everything
+// referring to them by foreign key goes with them
+func (m *GormTrashRepository) purge(db *gorm.DB) (int64, error) {
+	var ids []uint64
+	if err := db.Where("deleted_at IS NOT NULL").Pluck("id", &ids).Error; err != nil {
+		return 0, errors.NewInternalError(err.Error())
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	parents := make([]string, len(ids))
+	for i, id := range ids {
+		parents[i] = strconv.FormatUint(id, 10)
+	}
+	if err := m.db.Exec("DELETE FROM comments WHERE parent_id IN (?)", parents).Error; err != nil {
+		return 0, errors.NewInternalError(err.Error())
+	}
+	tx := m.db.Exec("DELETE FROM work_items WHERE id IN (?) AND deleted_at IS NOT NULL", ids)
+	if tx.Error != nil {
+		return 0, errors.NewInternalError(tx.Error.Error())
+	}
+	return tx.RowsAffected, nil
+}
+
+// load returns the deleted work item of the project if the viewer can see it
+func (m *GormTrashRepository) load(ctx context.Context, projectID uuid.UUID, workItemID string) (*WorkItem, error) {
+	id, err := strconv.ParseUint(workItemID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, errors.NewNotFoundError("deleted work item", workItemID)
+	}
+	var wi WorkItem
+	tx := m.db.Unscoped().Where("id = ?", id).Where(trashClause, projectID.String()).First(&wi)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("deleted work item", workItemID)
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	if !ContextViewer(ctx).CanSee(wi.Fields) {
+		return nil, errors.NewNotFoundError("deleted work item", workItemID)
+	}
+	return &wi, nil
+}
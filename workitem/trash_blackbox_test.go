@@ -0,0 +1,106 @@
+package workitem_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/context"
+)
+
+type trashRepoBlackBoxTest struct {
+	gormsupport.DBTestSuite
+	clean func()
+}
+
+func TestRunTrashRepoBlackBoxTest(t *testing.T) {
+	suite.Run(t, &trashRepoBlackBoxTest{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *trashRepoBlackBoxTest) SetupTest() {
+	s.clean = gormsupport.DeleteCreatedEntities(s.DB)
+}
+
+func (s *trashRepoBlackBoxTest) TearDownTest() {
+	s.clean()
+}
+
+func (s *trashRepoBlackBoxTest) createDeleted(projectID uuid.UUID) string {
+	ctx := context.Background()
+	repo := workitem.NewWorkItemRepository(s.DB)
+	wi, err := repo.Create(ctx, workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle:   "trashed",
+		workitem.SystemState:   workitem.SystemStateNew,
+		workitem.SystemProject: projectID.String(),
+	}, "xx")
+	require.Nil(s.T(), err)
+	require.Nil(s.T(), repo.Delete(ctx, wi.ID))
+	return wi.ID
+}
+
+func (s *trashRepoBlackBoxTest) TestListAndRestore() {
+	t := s.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	projectID := uuid.NewV4()
+	id := s.createDeleted(projectID)
+	s.createDeleted(uuid.NewV4())
+	trash := workitem.NewTrashRepository(s.DB)
+
+	trashed, err := trash.List(ctx, projectID)
+	require.Nil(t, err)
+	require.Len(t, trashed, 1)
+	assert.Equal(t, id, trashed[0].Item.ID)
+	assert.Equal(t, "trashed", trashed[0].Item.Fields[workitem.SystemTitle])
+
+	_, err = trash.Restore(ctx, uuid.NewV4(), id)
+	assert.IsType(t, errors.NotFoundError{}, err)
+	restored, err := trash.Restore(ctx, projectID, id)
+	require.Nil(t, err)
+	assert.Equal(t, id, restored.ID)
+	_, err = workitem.NewWorkItemRepository(s.DB).Load(ctx, id)
+	require.Nil(t, err)
+	trashed, err = trash.List(ctx, projectID)
+	require.Nil(t, err)
+	assert.Len(t, trashed, 0)
+
+	revs, err := workitem.NewEventRepository(s.DB).List(ctx, id)
+	require.Nil(t, err)
+	require.Len(t, revs, 3)
+	assert.Equal(t, workitem.EventRestore, revs[2].Kind)
+	_, err = trash.Restore(ctx, projectID, id)
+	assert.IsType(t, errors.NotFoundError{}, err)
+}
+
+func (s *trashRepoBlackBoxTest) TestPurge() {
+	t := s.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	projectID := uuid.NewV4()
+	id := s.createDeleted(projectID)
+	old := s.createDeleted(projectID)
+	trash := workitem.NewTrashRepository(s.DB)
+
+	require.Nil(t, trash.Purge(ctx, projectID, id))
+	_, err := trash.Restore(ctx, projectID, id)
+	assert.IsType(t, errors.NotFoundError{}, err)
+
+	recent := s.createDeleted(projectID)
+	require.Nil(t, s.DB.Exec("UPDATE work_items SET deleted_at = ? WHERE id = ?", time.Now().AddDate(0, 0, -2), old).Error)
+	n, err := trash.PurgeBefore(ctx, time.Now().AddDate(0, 0, -1))
+	require.Nil(t, err)
+	assert.True(t, n >= 1)
+	_, err = trash.Restore(ctx, projectID, old)
+	assert.IsType(t, errors.NotFoundError{}, err)
+	_, err = trash.Restore(ctx, projectID, recent)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,265 @@
+package workitem
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+)
+
+// TypeImpact is what deleting a work item type affects
+type TypeImpact struct {
+	// WorkItems is the number of work items of the type, including trashed
+	// and archived ones
+	WorkItems int
+	// Subtypes are the names of the types extending the type, a type with
+	// subtypes can't be deleted
+	Subtypes []string
+	// LinkTypes are the names of the link types whose source or target is
+	// the type, they are deleted with the type
+	LinkTypes []string
+	// Links is the number of links of these link types
+	Links int
+	// AssignmentRules and ApprovalRules are the numbers of rules matching
+	// the type
+	AssignmentRules int
+	ApprovalRules   int
+	// Hierarchies is the number of projects whose type hierarchy has a level
+	// of the type
+	Hierarchies int
+}
+
+// InUse returns true if the type can only be deleted by migrating its work
+// items, rules and hierarchy levels to another type
+func (i TypeImpact) InUse() bool {
+	return i.WorkItems > 0 || i.AssignmentRules > 0 || i.ApprovalRules > 0 || i.Hierarchies > 0
+}
+
+// TypeDeletionRepository encapsulates deleting work item types
+type TypeDeletionRepository interface {
+	Impact(ctx context.Context, typeName string) (*TypeImpact, error)
+	Delete(ctx context.Context, typeName string, targetName string) (*TypeImpact, error)
+}
+
+// NewTypeDeletionRepository creates a new storage type.
+func NewTypeDeletionRepository(db *gorm.DB) TypeDeletionRepository {
+	return &GormTypeDeletionRepository{db: db, wir: NewWorkItemTypeRepository(db)}
+}
+
+// GormTypeDeletionRepository is the implementation of the storage interface
+// for deleting work item types.
+type GormTypeDeletionRepository struct {
+	db  *gorm.DB
+	wir *GormWorkItemTypeRepository
+}
+
+// Impact reports what deleting the type would affect without changing
+// anything
+// returns NotFoundError or InternalError
+func (m *GormTypeDeletionRepository) Impact(ctx context.Context, typeName string) (*TypeImpact, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "typedeletion", "impact"}, time.Now())
+
+	wit, err := m.wir.LoadTypeFromDB(typeName)
+	if err != nil {
+		return nil, err
+	}
+	return m.impact(wit)
+}
+
+// Delete deletes the type with its link types and their links. A type in use
+// can only be deleted with a target type: its work items are migrated to the
+// target like Migrate without rules would, the rules matching the type match
+// the target instead and the hierarchy levels of the type are replaced by the
+// target. Built-in types and types with subtypes can't be deleted. Returns
+// what was affected.
+// returns NotFoundError, BadParameterError, DataConflictError if the type is
+// in use and there is no target, or InternalError
+func (m *GormTypeDeletionRepository) Delete(ctx context.Context, typeName string, targetName string) (*TypeImpact, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "typedeletion", "delete"}, time.Now())
+
+	wit, err := m.wir.LoadTypeFromDB(typeName)
+	if err != nil {
+		return nil, err
+	}
+	// the built-in types are created again on startup
+	if strings.HasPrefix(wit.Name, "system.") {
+		return nil, errors.NewBadParameterError("name", wit.Name).Expected("a type that is not built in")
+	}
+	impact, err := m.impact(wit)
+	if err != nil {
+		return nil, err
+	}
+	if len(impact.Subtypes) > 0 {
+		return nil, errors.NewDataConflictError(fmt.Sprintf("the type is extended by %s", strings.Join(impact.Subtypes, ", ")))
+	}
+	if impact.InUse() {
+		if targetName == "" {
+			return nil, errors.NewDataConflictError(fmt.Sprintf(
+				"the type is used by %d work items, %d assignment rules, %d approval rules and %d type hierarchies, a target type to migrate them to is required",
+				impact.WorkItems, impact.AssignmentRules, impact.ApprovalRules, impact.Hierarchies))
+		}
+		if targetName == wit.Name {
+			return nil, errors.NewBadParameterError("target", targetName).Expected("another type")
+		}
+		target, err := m.wir.LoadTypeFromDB(targetName)
+		if err != nil {
+			return nil, errors.NewBadParameterError("target", targetName).Expected("an existing type")
+		}
+		if err := m.migrate(ctx, wit, target); err != nil {
+			return nil, err
+		}
+	}
+	// the link types and their links are deleted by a trigger
+	if err := m.db.Delete(&WorkItemType{Name: wit.Name}).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return impact, nil
+}
+
+// impact returns what deleting the loaded type would affect
+func (m *GormTypeDeletionRepository) impact(wit *WorkItemType) (*TypeImpact, error) {
+	impact := &TypeImpact{Subtypes: []string{}, LinkTypes: []string{}}
+	counts := []struct {
+		count *int
+		query string
+		param interface{}
+	}{
+		{&impact.WorkItems, "SELECT count(*) FROM work_items WHERE type = ?", wit.Name},
+		{&impact.Links, `SELECT count(*) FROM work_item_links l JOIN work_item_link_types t ON t.id = l.link_type_id
+			WHERE l.deleted_at IS NULL AND t.deleted_at IS NULL AND ? IN (t.source_type_name, t.target_type_name)`, wit.Name},
+		{&impact.AssignmentRules, "SELECT count(*) FROM assignment_rules WHERE type = ? AND deleted_at IS NULL", wit.Name},
+		{&impact.ApprovalRules, "SELECT count(*) FROM approval_rules WHERE type = ? AND deleted_at IS NULL", wit.Name},
+		{&impact.Hierarchies, "SELECT count(*) FROM type_hierarchies WHERE levels @> ?::jsonb AND deleted_at IS NULL", levelsOf(wit.Name)},
+	}
+	for _, c := range counts {
+		if err := m.db.Raw(c.query, c.param).Row().Scan(c.count); err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+	}
+	names := []struct {
+		names *[]string
+		query string
+		param interface{}
+	}{
+		{&impact.Subtypes, "SELECT name FROM work_item_types WHERE path LIKE ? AND deleted_at IS NULL ORDER BY name", wit.Path + pathSep + "%"},
+		{&impact.LinkTypes, "SELECT name FROM work_item_link_types WHERE ? IN (source_type_name, target_type_name) AND deleted_at IS NULL ORDER BY name", wit.Name},
+	}
+	for _, n := range names {
+		rows, err := m.db.Raw(n.query, n.param).Rows()
+		if err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				rows.Close()
+				return nil, errors.NewInternalError(err.Error())
+			}
+			*n.names = append(*n.names, name)
+		}
+		rows.Close()
+	}
+	return impact, nil
+}
+
+// migrate moves the work items, the rules and the hierarchy levels of the
+// type to the target type
+func (m *GormTypeDeletionRepository) migrate(ctx context.Context, wit *WorkItemType, target *WorkItemType) error {
+	// trashed work items are migrated as well so they can be restored
+	db := m.db.Unscoped()
+	var items []WorkItem
+	if err := db.Where("type = ?", wit.Name).Order("id").Find(&items).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	versions := &GormTypeMigrationRepository{db: m.db, wir: m.wir}
+	old := map[int]*WorkItemType{}
+	for _, wi := range items {
+		source, err := versions.version(wit, wi.TypeVersion, old)
+		if err != nil {
+			return err
+		}
+		migrated, err := migrateFields(*target, *source, wi, nil)
+		if err != nil {
+			return errors.NewBadParameterError("target", target.Name).Expected(fmt.Sprintf("a type work item %d fits: %s", wi.ID, err.Error()))
+		}
+		migrated.Type = target.Name
+		if err := encryptFields(*target, migrated.Fields); err != nil {
+			return err
+		}
+		if err := apply(db, newEvent(ctx, EventUpdate, *migrated)); err != nil {
+			return err
+		}
+	}
+
+	for _, table := range []string{"assignment_rules", "approval_rules"} {
+		err := m.db.Exec("UPDATE "+table+" SET type = ?, updated_at = now() WHERE type = ? AND deleted_at IS NULL", target.Name, wit.Name).Error
+		if err != nil {
+			return errors.NewInternalError(err.Error())
+		}
+	}
+
+	rows, err := m.db.Raw("SELECT project_id, levels FROM type_hierarchies WHERE levels @> ?::jsonb AND deleted_at IS NULL", levelsOf(wit.Name)).Rows()
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	hierarchies := map[string][]string{}
+	for rows.Next() {
+		var projectID string
+		var b []byte
+		var levels []string
+		if err := rows.Scan(&projectID, &b); err != nil {
+			rows.Close()
+			return errors.NewInternalError(err.Error())
+		}
+		if err := json.Unmarshal(b, &levels); err != nil {
+			rows.Close()
+			return errors.NewConversionError(err.Error())
+		}
+		hierarchies[projectID] = replaceLevel(levels, wit.Name, target.Name)
+	}
+	rows.Close()
+	for projectID, levels := range hierarchies {
+		b, err := json.Marshal(levels)
+		if err != nil {
+			return errors.NewConversionError(err.Error())
+		}
+		err = m.db.Exec("UPDATE type_hierarchies SET levels = ?, updated_at = now() WHERE project_id = ?", string(b), projectID).Error
+		if err != nil {
+			return errors.NewInternalError(err.Error())
+		}
+	}
+	return nil
+}
+
+// levelsOf returns the JSON of hierarchy levels containing the type
+func levelsOf(typeName string) string {
+	b, _ := json.Marshal([]string{typeName})
+	return string(b)
+}
+
+// replaceLevel replaces the level of the type by the target type, the level
+// is dropped if the hierarchy already has the target
+func replaceLevel(levels []string, typeName string, targetName string) []string {
+	hasTarget := false
+	for _, l := range levels {
+		if l == targetName {
+			hasTarget = true
+		}
+	}
+	replaced := []string{}
+	for _, l := range levels {
+		switch {
+		case l != typeName:
+			replaced = append(replaced, l)
+		case !hasTarget:
+			replaced = append(replaced, targetName)
+		}
+	}
+	return replaced
+}
@@ -0,0 +1,102 @@
+package workitem_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/context"
+)
+
+type typeDeletionRepoBlackBoxTest struct {
+	gormsupport.DBTestSuite
+	clean func()
+}
+
+func TestRunTypeDeletionRepoBlackBoxTest(t *testing.T) {
+	suite.Run(t, &typeDeletionRepoBlackBoxTest{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *typeDeletionRepoBlackBoxTest) SetupTest() {
+	for _, name := range []string{"foo.deleted", "foo.target"} {
+		require.Nil(s.T(), s.DB.Exec("DELETE FROM work_items WHERE type = ?", name).Error)
+		require.Nil(s.T(), s.DB.Unscoped().Delete(workitem.WorkItemType{Name: name}).Error)
+	}
+	s.clean = gormsupport.DeleteCreatedEntities(s.DB)
+}
+
+func (s *typeDeletionRepoBlackBoxTest) TearDownTest() {
+	s.clean()
+}
+
+func (s *typeDeletionRepoBlackBoxTest) TestDeleteMigratesToTarget() {
+	t := s.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	typeRepo := workitem.NewWorkItemTypeRepository(s.DB)
+	repo := workitem.NewWorkItemRepository(s.DB)
+	deletions := workitem.NewTypeDeletionRepository(s.DB)
+	fields := map[string]app.FieldDefinition{
+		"size": {Type: &app.FieldType{Kind: string(workitem.KindString)}},
+	}
+	_, err := typeRepo.Create(ctx, nil, "foo.deleted", fields)
+	require.Nil(t, err)
+	_, err = typeRepo.Create(ctx, nil, "foo.target", fields)
+	require.Nil(t, err)
+	wi, err := repo.Create(ctx, "foo.deleted", map[string]interface{}{"size": "big"}, "xx")
+	require.Nil(t, err)
+
+	impact, err := deletions.Impact(ctx, "foo.deleted")
+	require.Nil(t, err)
+	assert.Equal(t, 1, impact.WorkItems)
+	assert.Empty(t, impact.Subtypes)
+	assert.True(t, impact.InUse())
+
+	// the work item needs a target
+	_, err = deletions.Delete(ctx, "foo.deleted", "")
+	assert.IsType(t, errors.DataConflictError{}, err)
+	_, err = deletions.Delete(ctx, "foo.deleted", "foo.deleted")
+	assert.IsType(t, errors.BadParameterError{}, err)
+	_, err = deletions.Delete(ctx, "foo.deleted", "foo.unknown")
+	assert.IsType(t, errors.BadParameterError{}, err)
+
+	impact, err = deletions.Delete(ctx, "foo.deleted", "foo.target")
+	require.Nil(t, err)
+	assert.Equal(t, 1, impact.WorkItems)
+	_, err = typeRepo.Load(ctx, "foo.deleted")
+	assert.IsType(t, errors.NotFoundError{}, err)
+
+	migrated, err := repo.Load(ctx, wi.ID)
+	require.Nil(t, err)
+	assert.Equal(t, "foo.target", migrated.Type)
+	assert.Equal(t, "big", migrated.Fields["size"])
+	assert.Equal(t, wi.Version+1, migrated.Version)
+
+	// built-in types are created again on startup
+	_, err = deletions.Delete(ctx, workitem.SystemBug, "foo.target")
+	assert.IsType(t, errors.BadParameterError{}, err)
+}
+
+func (s *typeDeletionRepoBlackBoxTest) TestDeleteUnusedType() {
+	t := s.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	typeRepo := workitem.NewWorkItemTypeRepository(s.DB)
+	deletions := workitem.NewTypeDeletionRepository(s.DB)
+	_, err := typeRepo.Create(ctx, nil, "foo.deleted", map[string]app.FieldDefinition{})
+	require.Nil(t, err)
+
+	impact, err := deletions.Delete(ctx, "foo.deleted", "")
+	require.Nil(t, err)
+	assert.False(t, impact.InUse())
+	_, err = deletions.Impact(ctx, "foo.deleted")
+	assert.IsType(t, errors.NotFoundError{}, err)
+}
@@ -0,0 +1,250 @@
+package workitem
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+)
+
+// FieldRule tells how a field of the current version of a work item type
+// gets its value from a work item of an older version
+type FieldRule struct {
+	// From is the field of the older version the value is taken from, the
+	// field itself if empty
+	From string
+	// Values maps the old values, formatted with %v, to new ones. Values
+	// without mapping are kept.
+	Values map[string]interface{}
+	// Default is the value of work items without a value
+	Default interface{}
+}
+
+// TypeMigration is the outcome of migrating the work items of a type
+type TypeMigration struct {
+	// Version is the version of the type the work items were migrated to
+	Version int
+	// Migrated is the number of migrated work items
+	Migrated int
+	// Failures holds why work items couldn't be migrated by their ID, they
+	// stay at their version
+	Failures map[uint64]string
+}
+
+// TypeMigrationRepository encapsulates moving work items to the current
+// version of their type
+type TypeMigrationRepository interface {
+	Migrate(ctx context.Context, typeName string, rules map[string]FieldRule) (*TypeMigration, error)
+	ReplaceDeprecated(ctx context.Context, typeName string) (*TypeMigration, error)
+}
+
+// NewTypeMigrationRepository creates a new storage type.
+func NewTypeMigrationRepository(db *gorm.DB) TypeMigrationRepository {
+	return &GormTypeMigrationRepository{db: db, wir: NewWorkItemTypeRepository(db)}
+}
+
+// GormTypeMigrationRepository is the implementation of the storage interface
+// for migrating work items to the current version of their type.
+type GormTypeMigrationRepository struct {
+	db  *gorm.DB
+	wir *GormWorkItemTypeRepository
+}
+
+// Migrate transforms the work items of older versions of the type to the
+// current one. Fields without rule take the value of the field with the same
+// name, fields the current version doesn't have are dropped. Every migrated
+// work item gets a new version like an update of all its fields would.
+// returns NotFoundError or InternalError
+func (m *GormTypeMigrationRepository) Migrate(ctx context.Context, typeName string, rules map[string]FieldRule) (*TypeMigration, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "typemigration", "migrate"}, time.Now())
+
+	wit, err := m.wir.LoadTypeFromDB(typeName)
+	if err != nil {
+		return nil, err
+	}
+	var items []WorkItem
+	if err := m.db.Where("type = ? AND type_version < ?", wit.Name, wit.Version).Order("id").Find(&items).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	res := &TypeMigration{Version: wit.Version, Failures: map[uint64]string{}}
+	// the definitions of the older versions, keyed by version
+	versions := map[int]*WorkItemType{}
+	for _, wi := range items {
+		old, err := m.version(wit, wi.TypeVersion, versions)
+		if err != nil {
+			return nil, err
+		}
+		migrated, err := migrateFields(*wit, *old, wi, rules)
+		if err != nil {
+			res.Failures[wi.ID] = err.Error()
+			continue
+		}
+		if err := encryptFields(*wit, migrated.Fields); err != nil {
+			return nil, err
+		}
+		if err := apply(m.db, newEvent(ctx, EventUpdate, *migrated)); err != nil {
+			return nil, err
+		}
+		res.Migrated++
+	}
+	return res, nil
+}
+
+// ReplaceDeprecated moves the values of the deprecated fields of the work
+// items of the type to their replacements. Work items that already have a
+// value in the replacement only lose the deprecated value. Work items of
+// older versions are migrated to the current one on the way, like Migrate
+// without rules would.
+// returns NotFoundError or InternalError
+func (m *GormTypeMigrationRepository) ReplaceDeprecated(ctx context.Context, typeName string) (*TypeMigration, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "typemigration", "replacedeprecated"}, time.Now())
+
+	wit, err := m.wir.LoadTypeFromDB(typeName)
+	if err != nil {
+		return nil, err
+	}
+	res := &TypeMigration{Version: wit.Version, Failures: map[uint64]string{}}
+	var clauses []string
+	var params []interface{}
+	for name, def := range wit.Fields {
+		if def.Deprecated && def.ReplacedBy != "" {
+			clauses = append(clauses, "fields->>? IS NOT NULL")
+			params = append(params, name)
+		}
+	}
+	if len(clauses) == 0 {
+		return res, nil
+	}
+	var items []WorkItem
+	err = m.db.Where("type = ?", wit.Name).Where(strings.Join(clauses, " OR "), params...).Order("id").Find(&items).Error
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	versions := map[int]*WorkItemType{}
+	for _, wi := range items {
+		var replaced *WorkItem
+		if wi.TypeVersion < wit.Version {
+			old, err := m.version(wit, wi.TypeVersion, versions)
+			if err != nil {
+				return nil, err
+			}
+			replaced, err = migrateFields(*wit, *old, wi, nil)
+			if err != nil {
+				res.Failures[wi.ID] = err.Error()
+				continue
+			}
+		} else {
+			fields, err := DecryptFields(*wit, wi.Fields)
+			if err != nil {
+				res.Failures[wi.ID] = err.Error()
+				continue
+			}
+			replaced = &wi
+			replaced.Version = wi.Version + 1
+			replaced.Fields = fields
+		}
+		if err := replaceFields(*wit, replaced.Fields); err != nil {
+			res.Failures[wi.ID] = err.Error()
+			continue
+		}
+		if err := encryptFields(*wit, replaced.Fields); err != nil {
+			return nil, err
+		}
+		if err := apply(m.db, newEvent(ctx, EventUpdate, *replaced)); err != nil {
+			return nil, err
+		}
+		res.Migrated++
+	}
+	return res, nil
+}
+
+// version returns the type with the field definitions of the given older
+// version, caching them in versions. Versions whose definitions weren't
+// recorded get the current ones.
+func (m *GormTypeMigrationRepository) version(wit *WorkItemType, version int, versions map[int]*WorkItemType) (*WorkItemType, error) {
+	if old, ok := versions[version]; ok {
+		return old, nil
+	}
+	old := &WorkItemType{Name: wit.Name, Version: version, Fields: wit.Fields}
+	fields, err := m.wir.LoadVersion(wit.Name, version)
+	if err != nil {
+		return nil, err
+	}
+	if fields != nil {
+		old.Fields = fields
+	}
+	versions[version] = old
+	return old, nil
+}
+
+// replaceFields moves the values of the deprecated fields among the given
+// decrypted ones to their replacements
+func replaceFields(wit WorkItemType, fields Fields) error {
+	for name, def := range wit.Fields {
+		value := fields[name]
+		if !def.Deprecated || def.ReplacedBy == "" || value == nil {
+			continue
+		}
+		delete(fields, name)
+		if fields[def.ReplacedBy] != nil {
+			continue
+		}
+		converted, err := def.ConvertFromModel(name, value)
+		if err != nil {
+			return errors.NewConversionError(err.Error())
+		}
+		fields[def.ReplacedBy], err = wit.Fields[def.ReplacedBy].ConvertToModel(def.ReplacedBy, converted)
+		if err != nil {
+			return errors.NewBadParameterError(def.ReplacedBy, converted)
+		}
+	}
+	return nil
+}
+
+// migrateFields returns the work item of the older version at the current
+// version of the type with its fields transformed by the rules
+func migrateFields(wit WorkItemType, old WorkItemType, wi WorkItem, rules map[string]FieldRule) (*WorkItem, error) {
+	stored, err := DecryptFields(old, wi.Fields)
+	if err != nil {
+		return nil, err
+	}
+	migrated := WorkItem{
+		ID:          wi.ID,
+		Type:        wi.Type,
+		Version:     wi.Version + 1,
+		TypeVersion: wit.Version,
+		Fields:      Fields{},
+	}
+	for name, def := range wit.Fields {
+		if name == SystemCreatedAt {
+			continue
+		}
+		rule := rules[name]
+		from := name
+		if rule.From != "" {
+			from = rule.From
+		}
+		value := stored[from]
+		if oldDef, ok := old.Fields[from]; ok && value != nil {
+			if converted, err := oldDef.ConvertFromModel(from, value); err == nil {
+				value = converted
+			}
+		}
+		if mapped, ok := rule.Values[fmt.Sprintf("%v", value)]; ok && value != nil {
+			value = mapped
+		}
+		if value == nil {
+			value = rule.Default
+		}
+		migrated.Fields[name], err = def.ConvertToModel(name, value)
+		if err != nil {
+			return nil, errors.NewBadParameterError(name, value)
+		}
+	}
+	return &migrated, nil
+}
@@ -0,0 +1,133 @@
+package workitem_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/context"
+)
+
+type typeMigrationRepoBlackBoxTest struct {
+	gormsupport.DBTestSuite
+	clean func()
+}
+
+func TestRunTypeMigrationRepoBlackBoxTest(t *testing.T) {
+	suite.Run(t, &typeMigrationRepoBlackBoxTest{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *typeMigrationRepoBlackBoxTest) SetupTest() {
+	require.Nil(s.T(), s.DB.Exec("DELETE FROM work_items WHERE type = ?", "foo.migrated").Error)
+	require.Nil(s.T(), s.DB.Unscoped().Delete(workitem.WorkItemType{Name: "foo.migrated"}).Error)
+	s.clean = gormsupport.DeleteCreatedEntities(s.DB)
+}
+
+func (s *typeMigrationRepoBlackBoxTest) TearDownTest() {
+	s.clean()
+}
+
+func (s *typeMigrationRepoBlackBoxTest) TestUpdateAndMigrate() {
+	t := s.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	typeRepo := workitem.NewWorkItemTypeRepository(s.DB)
+	repo := workitem.NewWorkItemRepository(s.DB)
+	_, err := typeRepo.Create(ctx, nil, "foo.migrated", map[string]app.FieldDefinition{
+		"size": {Type: &app.FieldType{Kind: string(workitem.KindString)}},
+	})
+	require.Nil(t, err)
+	big, err := repo.Create(ctx, "foo.migrated", map[string]interface{}{"size": "big"}, "xx")
+	require.Nil(t, err)
+	huge, err := repo.Create(ctx, "foo.migrated", map[string]interface{}{"size": "huge"}, "xx")
+	require.Nil(t, err)
+
+	// the size becomes a required estimate
+	_, err = typeRepo.Update(ctx, "foo.migrated", 1, map[string]app.FieldDefinition{})
+	assert.IsType(t, errors.VersionConflictError{}, err)
+	wit, err := typeRepo.Update(ctx, "foo.migrated", 0, map[string]app.FieldDefinition{
+		workitem.SystemCreator: {Type: &app.FieldType{Kind: string(workitem.KindUser)}},
+		"estimate":             {Required: true, Type: &app.FieldType{Kind: string(workitem.KindInteger)}},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, 1, wit.Version)
+	fields, err := typeRepo.LoadVersion("foo.migrated", 0)
+	require.Nil(t, err)
+	assert.Contains(t, fields, "size")
+
+	// older work items are still readable
+	loaded, err := repo.Load(ctx, big.ID)
+	require.Nil(t, err)
+	assert.Nil(t, loaded.Fields["estimate"])
+
+	res, err := workitem.NewTypeMigrationRepository(s.DB).Migrate(ctx, "foo.migrated", map[string]workitem.FieldRule{
+		"estimate": {From: "size", Values: map[string]interface{}{"big": 8}},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, 1, res.Version)
+	assert.Equal(t, 1, res.Migrated)
+	require.Len(t, res.Failures, 1)
+
+	loaded, err = repo.Load(ctx, big.ID)
+	require.Nil(t, err)
+	assert.EqualValues(t, 8, loaded.Fields["estimate"])
+	assert.Equal(t, big.Version+1, loaded.Version)
+	assert.Nil(t, loaded.Fields["size"])
+	stored, err := repo.LoadFromDB(huge.ID)
+	require.Nil(t, err)
+	assert.Equal(t, 0, stored.TypeVersion)
+}
+
+func (s *typeMigrationRepoBlackBoxTest) TestReplaceDeprecated() {
+	t := s.T()
+	resource.Require(t, resource.Database)
+
+	ctx := context.Background()
+	typeRepo := workitem.NewWorkItemTypeRepository(s.DB)
+	repo := workitem.NewWorkItemRepository(s.DB)
+	_, err := typeRepo.Create(ctx, nil, "foo.migrated", map[string]app.FieldDefinition{
+		"size": {Type: &app.FieldType{Kind: string(workitem.KindString)}},
+	})
+	require.Nil(t, err)
+	moved, err := repo.Create(ctx, "foo.migrated", map[string]interface{}{"size": "big"}, "xx")
+	require.Nil(t, err)
+
+	deprecated := true
+	replacedBy := "estimate"
+	fields := map[string]app.FieldDefinition{
+		"size":     {Type: &app.FieldType{Kind: string(workitem.KindString)}, Deprecated: &deprecated, ReplacedBy: &replacedBy},
+		"estimate": {Type: &app.FieldType{Kind: string(workitem.KindString)}},
+	}
+	_, err = typeRepo.Update(ctx, "foo.migrated", 0, map[string]app.FieldDefinition{
+		"size": {Type: &app.FieldType{Kind: string(workitem.KindString)}, Deprecated: &deprecated, ReplacedBy: &replacedBy},
+	})
+	assert.IsType(t, errors.BadParameterError{}, err)
+	wit, err := typeRepo.Update(ctx, "foo.migrated", 0, fields)
+	require.Nil(t, err)
+	assert.Equal(t, &replacedBy, wit.Fields["size"].ReplacedBy)
+
+	_, err = repo.Create(ctx, "foo.migrated", map[string]interface{}{"size": "small"}, "xx")
+	assert.IsType(t, errors.BadParameterError{}, err)
+	kept, err := repo.Create(ctx, "foo.migrated", map[string]interface{}{"estimate": "small"}, "xx")
+	require.Nil(t, err)
+
+	res, err := workitem.NewTypeMigrationRepository(s.DB).ReplaceDeprecated(ctx, "foo.migrated")
+	require.Nil(t, err)
+	assert.Equal(t, 1, res.Migrated)
+	assert.Empty(t, res.Failures)
+
+	loaded, err := repo.Load(ctx, moved.ID)
+	require.Nil(t, err)
+	assert.Equal(t, "big", loaded.Fields["estimate"])
+	assert.Nil(t, loaded.Fields["size"])
+	loaded, err = repo.Load(ctx, kept.ID)
+	require.Nil(t, err)
+	assert.Equal(t, kept.Version, loaded.Version)
+}
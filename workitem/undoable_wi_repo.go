@@ -108,6 +108,11 @@ func (r *UndoableWorkItemRepository) Create(ctx context.Context, typeID string,
 }
 
 // List implements application.WorkItemRepository
-func (r *UndoableWorkItemRepository) List(ctx context.Context, criteria criteria.Expression, start *int, length *int) ([]*app.WorkItem, uint64, error) {
-	return r.wrapped.List(ctx, criteria, start, length)
+func (r *UndoableWorkItemRepository) List(ctx context.Context, criteria criteria.Expression, start *int, length *int, sort ...SortKey) ([]*app.WorkItem, uint64, error) {
+	return r.wrapped.List(ctx, criteria, start, length, sort...)
+}
+
+// Stream implements application.WorkItemRepository
+func (r *UndoableWorkItemRepository) Stream(ctx context.Context, criteria criteria.Expression, sort []SortKey, fn func(*app.WorkItem) error) error {
+	return r.wrapped.Stream(ctx, criteria, sort, fn)
 }
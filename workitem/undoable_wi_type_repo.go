@@ -43,3 +43,21 @@ func (r *UndoableWorkItemTypeRepository) Create(ctx context.Context, extendedTyp
 	}
 	return res, err
 }
+
+// Update implements application.WorkItemTypeRepository
+func (r *UndoableWorkItemTypeRepository) Update(ctx context.Context, name string, version int, fields map[string]app.FieldDefinition) (*app.WorkItemType, error) {
+	old, err := r.wrapped.LoadTypeFromDB(name)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.wrapped.Update(ctx, name, version, fields)
+	if err == nil {
+		r.undo.Append(func(db *gorm.DB) error {
+			if err := db.Where("type_name = ? AND version > ?", name, old.Version).Delete(&WorkItemTypeVersion{}).Error; err != nil {
+				return err
+			}
+			return db.Save(old).Error
+		})
+	}
+	return res, err
+}
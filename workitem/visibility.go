@@ -0,0 +1,214 @@
+package workitem
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Viewer describes who work items are loaded for. Confidential work items
+// are only visible to their creator, their assignees and the admins of
+// their project, drafts only to their creator.
+type Viewer struct {
+	// IdentityID is nil for anonymous users
+	IdentityID *uuid.UUID
+	// AdminProjectIDs are the projects administrated by the identity
+	AdminProjectIDs []uuid.UUID
+	// HiddenProjectIDs are the projects whose visibility doesn't allow the
+	// identity to read them, see package project
+	HiddenProjectIDs []uuid.UUID
+}
+
+type contextViewerKeyType int
+
+const contextViewerKey contextViewerKeyType = iota + 1
+
+// WithViewer returns a context that restricts the work items loaded with it
+// to the ones visible to the given viewer. A nil viewer lifts the
+// restriction, e.g. for system tasks acting on behalf of no user.
+func WithViewer(ctx context.Context, v *Viewer) context.Context {
+	return context.WithValue(ctx, contextViewerKey, v)
+}
+
+// ContextViewer returns the viewer of the given context, nil means all work
+// items are visible
+func ContextViewer(ctx context.Context) *Viewer {
+	v, _ := ctx.Value(contextViewerKey).(*Viewer)
+	return v
+}
+
+// Roles a viewer can have on a work item
+const (
+	RoleCreator      = "creator"
+	RoleAssignee     = "assignee"
+	RoleProjectAdmin = "project-admin"
+)
+
+// ValidateRoles returns BadParameterError if one of the roles is unknown
+func ValidateRoles(roles []string) error {
+	for _, role := range roles {
+		if role != RoleCreator && role != RoleAssignee && role != RoleProjectAdmin {
+			return errors.NewBadParameterError("roles", role).Expected([]string{RoleCreator, RoleAssignee, RoleProjectAdmin})
+		}
+	}
+	return nil
+}
+
+// HasRole returns true if the viewer has one of the roles on the work item
+// with the given fields. A nil viewer has all roles, an empty list of roles
+// is granted to everybody.
+func (v *Viewer) HasRole(fields map[string]interface{}, roles ...string) bool {
+	if v == nil || len(roles) == 0 {
+		return true
+	}
+	if v.IdentityID == nil {
+		return false
+	}
+	me := v.IdentityID.String()
+	for _, role := range roles {
+		switch role {
+		case RoleCreator:
+			if fields[SystemCreator] == me {
+				return true
+			}
+		case RoleAssignee:
+			switch assignees := fields[SystemAssignees].(type) {
+			case []interface{}:
+				for _, a := range assignees {
+					if a == me {
+						return true
+					}
+				}
+			case []string:
+				for _, a := range assignees {
+					if a == me {
+						return true
+					}
+				}
+			}
+		case RoleProjectAdmin:
+			if p, ok := fields[SystemProject].(string); ok {
+				for _, id := range v.AdminProjectIDs {
+					if id.String() == p {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// CanReadProject returns true if the project with the given ID isn't hidden
+// from the viewer, a nil viewer can read all projects
+func (v *Viewer) CanReadProject(id uuid.UUID) bool {
+	if v == nil {
+		return true
+	}
+	for _, hidden := range v.HiddenProjectIDs {
+		if uuid.Equal(hidden, id) {
+			return false
+		}
+	}
+	return true
+}
+
+// CanSee returns true if the work item with the given fields is visible to
+// the viewer, a nil viewer can see all work items. The work items of hidden
+// projects are not visible, confidential work items are visible to their
+// creator, assignees and project admins, work items pending review only to
+// their creator and project admins and drafts only to their creator.
+func (v *Viewer) CanSee(fields map[string]interface{}) bool {
+	if p, ok := fields[SystemProject].(string); ok {
+		if id, err := uuid.FromString(p); err == nil && !v.CanReadProject(id) {
+			return false
+		}
+	}
+	if confidential, _ := fields[SystemConfidential].(bool); confidential && !v.HasRole(fields, RoleCreator, RoleAssignee, RoleProjectAdmin) {
+		return false
+	}
+	if pending, _ := fields[SystemPendingReview].(bool); pending && !v.HasRole(fields, RoleCreator, RoleProjectAdmin) {
+		return false
+	}
+	if draft, _ := fields[SystemDraft].(bool); draft && !v.HasRole(fields, RoleCreator) {
+		return false
+	}
+	return true
+}
+
+// Redact removes the fields of the work item the viewer has none of the
+// required roles for
+func (v *Viewer) Redact(wit WorkItemType, wi *app.WorkItem) {
+	var restricted []string
+	for name, def := range wit.Fields {
+		if !v.HasRole(wi.Fields, def.Roles...) {
+			restricted = append(restricted, name)
+		}
+	}
+	for _, name := range restricted {
+		delete(wi.Fields, name)
+	}
+}
+
+// VisibilityClause returns a SQL condition that matches the work items of
+// the given table or alias visible to the viewer of ctx, or an empty string
+// if all work items are visible.
+func VisibilityClause(ctx context.Context, table string) (string, []interface{}) {
+	v := ContextViewer(ctx)
+	if v == nil {
+		return "", nil
+	}
+	confidential, confidentialParams := v.flagClause(table, SystemConfidential, RoleCreator, RoleAssignee, RoleProjectAdmin)
+	pending, pendingParams := v.flagClause(table, SystemPendingReview, RoleCreator, RoleProjectAdmin)
+	draft, draftParams := v.flagClause(table, SystemDraft, RoleCreator)
+	clause := confidential + " AND " + pending + " AND " + draft
+	params := append(append(confidentialParams, pendingParams...), draftParams...)
+	if len(v.HiddenProjectIDs) > 0 {
+		hidden := make([]string, len(v.HiddenProjectIDs))
+		for i, id := range v.HiddenProjectIDs {
+			hidden[i] = id.String()
+		}
+		clause += fmt.Sprintf(" AND (%[1]s.fields->>'%[2]s' IS NULL OR %[1]s.fields->>'%[2]s' NOT IN (?))", table, SystemProject)
+		params = append(params, hidden)
+	}
+	return clause, params
+}
+
+// flagClause returns a SQL condition that matches the work items which don't
+// have the given boolean field set or on which the viewer has one of the roles
+func (v *Viewer) flagClause(table string, field string, roles ...string) (string, []interface{}) {
+	clause := fmt.Sprintf(`(NOT (%[1]s.fields @> '{"%[2]s": true}')`, table, field)
+	if v.IdentityID == nil {
+		return clause + ")", nil
+	}
+	me := v.IdentityID.String()
+	var params []interface{}
+	for _, role := range roles {
+		switch role {
+		case RoleCreator:
+			creator, _ := json.Marshal(map[string]interface{}{SystemCreator: me})
+			clause += fmt.Sprintf(" OR %s.fields @> ?", table)
+			params = append(params, string(creator))
+		case RoleAssignee:
+			assignees, _ := json.Marshal(map[string]interface{}{SystemAssignees: []string{me}})
+			clause += fmt.Sprintf(" OR %s.fields @> ?", table)
+			params = append(params, string(assignees))
+		case RoleProjectAdmin:
+			if len(v.AdminProjectIDs) == 0 {
+				continue
+			}
+			projects := make([]string, len(v.AdminProjectIDs))
+			for i, id := range v.AdminProjectIDs {
+				projects[i] = id.String()
+			}
+			clause += fmt.Sprintf(" OR %s.fields->>'%s' IN (?)", table, SystemProject)
+			params = append(params, projects)
+		}
+	}
+	return clause + ")", params
+}
@@ -0,0 +1,133 @@
+package workitem_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestViewerCanSee(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	me := uuid.NewV4()
+	project := uuid.NewV4()
+	public := map[string]interface{}{workitem.SystemCreator: "someone"}
+	confidential := map[string]interface{}{workitem.SystemCreator: "someone", workitem.SystemConfidential: true}
+
+	var everything *workitem.Viewer
+	assert.True(t, everything.CanSee(confidential))
+
+	anonymous := &workitem.Viewer{}
+	assert.True(t, anonymous.CanSee(public))
+	assert.False(t, anonymous.CanSee(confidential))
+
+	viewer := &workitem.Viewer{IdentityID: &me}
+	assert.False(t, viewer.CanSee(confidential))
+	assert.True(t, viewer.CanSee(map[string]interface{}{workitem.SystemCreator: me.String(), workitem.SystemConfidential: true}))
+	assert.True(t, viewer.CanSee(map[string]interface{}{workitem.SystemAssignees: []interface{}{me.String()}, workitem.SystemConfidential: true}))
+
+	inProject := map[string]interface{}{workitem.SystemProject: project.String(), workitem.SystemConfidential: true}
+	assert.False(t, viewer.CanSee(inProject))
+	admin := &workitem.Viewer{IdentityID: &me, AdminProjectIDs: []uuid.UUID{project}}
+	assert.True(t, admin.CanSee(inProject))
+
+	pending := map[string]interface{}{workitem.SystemProject: project.String(), workitem.SystemPendingReview: true, workitem.SystemAssignees: []interface{}{me.String()}}
+	assert.False(t, anonymous.CanSee(pending))
+	assert.False(t, viewer.CanSee(pending))
+	assert.True(t, admin.CanSee(pending))
+
+	draft := map[string]interface{}{workitem.SystemProject: project.String(), workitem.SystemDraft: true, workitem.SystemCreator: "someone"}
+	assert.False(t, admin.CanSee(draft))
+	assert.True(t, viewer.CanSee(map[string]interface{}{workitem.SystemCreator: me.String(), workitem.SystemDraft: true}))
+
+	hidden := &workitem.Viewer{IdentityID: &me, HiddenProjectIDs: []uuid.UUID{project}}
+	assert.False(t, hidden.CanReadProject(project))
+	assert.True(t, hidden.CanReadProject(uuid.NewV4()))
+	assert.False(t, hidden.CanSee(map[string]interface{}{workitem.SystemProject: project.String()}))
+	assert.True(t, hidden.CanSee(public))
+	assert.True(t, everything.CanReadProject(project))
+}
+
+func TestVisibilityClause(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	clause, params := workitem.VisibilityClause(context.Background(), "work_items")
+	assert.Equal(t, "", clause)
+	assert.Nil(t, params)
+
+	ctx := workitem.WithViewer(context.Background(), &workitem.Viewer{})
+	clause, params = workitem.VisibilityClause(ctx, "work_items")
+	assert.Equal(t, `(NOT (work_items.fields @> '{"system.confidential": true}')) AND (NOT (work_items.fields @> '{"system.pending_review": true}'))`+
+		` AND (NOT (work_items.fields @> '{"system.draft": true}'))`, clause)
+	assert.Empty(t, params)
+
+	me := uuid.NewV4()
+	ctx = workitem.WithViewer(context.Background(), &workitem.Viewer{IdentityID: &me, AdminProjectIDs: []uuid.UUID{uuid.NewV4()}})
+	clause, params = workitem.VisibilityClause(ctx, "wi")
+	assert.Equal(t, `(NOT (wi.fields @> '{"system.confidential": true}') OR wi.fields @> ? OR wi.fields @> ? OR wi.fields->>'system.project' IN (?))`+
+		` AND (NOT (wi.fields @> '{"system.pending_review": true}') OR wi.fields @> ? OR wi.fields->>'system.project' IN (?))`+
+		` AND (NOT (wi.fields @> '{"system.draft": true}') OR wi.fields @> ?)`, clause)
+	assert.Len(t, params, 6)
+	assert.Equal(t, `{"system.creator":"`+me.String()+`"}`, params[0])
+
+	hidden := uuid.NewV4()
+	ctx = workitem.WithViewer(context.Background(), &workitem.Viewer{HiddenProjectIDs: []uuid.UUID{hidden}})
+	clause, params = workitem.VisibilityClause(ctx, "wi")
+	assert.Equal(t, `(NOT (wi.fields @> '{"system.confidential": true}')) AND (NOT (wi.fields @> '{"system.pending_review": true}'))`+
+		` AND (NOT (wi.fields @> '{"system.draft": true}'))`+
+		` AND (wi.fields->>'system.project' IS NULL OR wi.fields->>'system.project' NOT IN (?))`, clause)
+	assert.Equal(t, []interface{}{[]string{hidden.String()}}, params)
+}
+
+func TestRedactRestrictedFields(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	me := uuid.NewV4()
+	wit := workitem.WorkItemType{
+		Fields: map[string]workitem.FieldDefinition{
+			workitem.SystemTitle:   {Type: workitem.SimpleType{Kind: workitem.KindString}},
+			workitem.SystemCreator: {Type: workitem.SimpleType{Kind: workitem.KindUser}},
+			"customer":             {Type: workitem.SimpleType{Kind: workitem.KindString}, Roles: []string{workitem.RoleCreator, workitem.RoleProjectAdmin}},
+		},
+	}
+	newWorkItem := func(creator string) *app.WorkItem {
+		return &app.WorkItem{Fields: map[string]interface{}{
+			workitem.SystemTitle:   "title",
+			workitem.SystemCreator: creator,
+			"customer":             "ACME",
+		}}
+	}
+
+	wi := newWorkItem("someone")
+	(&workitem.Viewer{IdentityID: &me}).Redact(wit, wi)
+	assert.NotContains(t, wi.Fields, "customer")
+	assert.Equal(t, "title", wi.Fields[workitem.SystemTitle])
+
+	wi = newWorkItem(me.String())
+	(&workitem.Viewer{IdentityID: &me}).Redact(wit, wi)
+	assert.Equal(t, "ACME", wi.Fields["customer"])
+
+	var everything *workitem.Viewer
+	wi = newWorkItem("someone")
+	everything.Redact(wit, wi)
+	assert.Equal(t, "ACME", wi.Fields["customer"])
+}
+
+func TestValidateRoles(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	assert.Nil(t, workitem.ValidateRoles(nil))
+	assert.Nil(t, workitem.ValidateRoles([]string{workitem.RoleAssignee, workitem.RoleProjectAdmin}))
+	assert.IsType(t, errors.BadParameterError{}, workitem.ValidateRoles([]string{"owner"}))
+}
@@ -16,8 +16,16 @@ type WorkItem struct {
 	Type string
 	// Version for optimistic concurrency control
 	Version int
+	// TypeVersion is the version of the work item type the fields were
+	// validated against
+	TypeVersion int
 	// the field values
 	Fields Fields `sql:"type:jsonb"`
+	// Archived work items are only listed if the criteria ask for them
+	Archived bool
+	// Key is the human-friendly key of work items of projects with a key, it
+	// is assigned on creation and on moves to other projects, see SystemKey
+	Key *string
 }
 
 // TableName implements gorm.tabler
@@ -48,6 +56,15 @@ func (wi WorkItem) Equal(u convert.Equaler) bool {
 	if wi.Version != other.Version {
 		return false
 	}
+	if wi.TypeVersion != other.TypeVersion {
+		return false
+	}
+	if wi.Archived != other.Archived {
+		return false
+	}
+	if (wi.Key == nil) != (other.Key == nil) || (wi.Key != nil && *wi.Key != *other.Key) {
+		return false
+	}
 	return wi.Fields.Equal(other.Fields)
 }
 
@@ -1,6 +1,9 @@
 package workitem
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"log"
 	"strconv"
 
@@ -18,7 +21,8 @@ type WorkItemRepository interface {
 	Save(ctx context.Context, wi app.WorkItem) (*app.WorkItem, error)
 	Delete(ctx context.Context, ID string) error
 	Create(ctx context.Context, typeID string, fields map[string]interface{}, creator string) (*app.WorkItem, error)
-	List(ctx context.Context, criteria criteria.Expression, start *int, length *int) ([]*app.WorkItem, uint64, error)
+	List(ctx context.Context, criteria criteria.Expression, start *int, length *int, sort ...SortKey) ([]*app.WorkItem, uint64, error)
+	Stream(ctx context.Context, criteria criteria.Expression, sort []SortKey, fn func(*app.WorkItem) error) error
 }
 
 // GormWorkItemRepository implements WorkItemRepository using gorm
@@ -29,7 +33,11 @@ type GormWorkItemRepository struct {
 
 // LoadFromDB returns the work item with the given ID in model representation.
 func (r *GormWorkItemRepository) LoadFromDB(ID string) (*WorkItem, error) {
-	id, err := strconv.ParseUint(ID, 10, 64)
+	ref, err := ResolveKey(r.db, ID)
+	if err != nil {
+		return nil, err
+	}
+	id, err := strconv.ParseUint(ref, 10, 64)
 	if err != nil || id == 0 {
 		// treating this as a not found error: the fact that we're using number internal is implementation detail
 		return nil, errors.NewNotFoundError("work item", ID)
@@ -54,33 +62,33 @@ func (r *GormWorkItemRepository) Load(ctx context.Context, ID string) (*app.Work
 	if err != nil {
 		return nil, err
 	}
+	if !ContextViewer(ctx).CanSee(res.Fields) {
+		// confidential work items don't exist for those who can't see them
+		return nil, errors.NewNotFoundError("work item", ID)
+	}
 	wiType, err := r.wir.LoadTypeFromDB(res.Type)
 	if err != nil {
 		return nil, errors.NewInternalError(err.Error())
 	}
-	return convertWorkItemModelToApp(wiType, res)
+	return convertWorkItemModelToApp(ctx, wiType, res)
 }
 
-// Delete deletes the work item with the given id
-// returns NotFoundError or InternalError
+// Delete deletes the work item with the given id and enforces the behaviors
+// on deletion of the types of its links
+// returns BadParameterError, NotFoundError or InternalError
 func (r *GormWorkItemRepository) Delete(ctx context.Context, ID string) error {
-	var workItem = WorkItem{}
-	id, err := strconv.ParseUint(ID, 10, 64)
-	if err != nil || id == 0 {
+	if id, err := strconv.ParseUint(ID, 10, 64); err != nil || id == 0 {
 		// treat as not found: clients don't know it must be a number
 		return errors.NewNotFoundError("work item", ID)
 	}
-	workItem.ID = id
-	tx := r.db.Delete(workItem)
-
-	if err = tx.Error; err != nil {
-		return errors.NewInternalError(err.Error())
+	res, err := r.LoadFromDB(ID)
+	if err != nil {
+		return err
 	}
-	if tx.RowsAffected == 0 {
+	if !ContextViewer(ctx).CanSee(res.Fields) {
 		return errors.NewNotFoundError("work item", ID)
 	}
-
-	return nil
+	return r.deleteWithLinks(ctx, *res)
 }
 
 // Save updates the given work item in storage. Version must be the same as the one int the stored version
@@ -101,6 +109,9 @@ func (r *GormWorkItemRepository) Save(ctx context.Context, wi app.WorkItem) (*ap
 	if tx.Error != nil {
 		return nil, errors.NewInternalError(err.Error())
 	}
+	if !ContextViewer(ctx).CanSee(res.Fields) {
+		return nil, errors.NewNotFoundError("work item", wi.ID)
+	}
 	if res.Version != wi.Version {
 		return nil, errors.NewVersionConflictError("version conflict")
 	}
@@ -109,36 +120,56 @@ func (r *GormWorkItemRepository) Save(ctx context.Context, wi app.WorkItem) (*ap
 	if err != nil {
 		return nil, errors.NewBadParameterError("Type", wi.Type)
 	}
+	stored, err := DecryptFields(*wiType, res.Fields)
+	if err != nil {
+		return nil, err
+	}
 
 	newWi := WorkItem{
-		ID:      id,
-		Type:    wi.Type,
-		Version: wi.Version + 1,
-		Fields:  Fields{},
+		ID:          id,
+		Type:        wi.Type,
+		Version:     wi.Version + 1,
+		TypeVersion: wiType.Version,
+		Fields:      Fields{},
+		Key:         res.Key,
 	}
 
+	viewer := ContextViewer(ctx)
 	for fieldName, fieldDef := range wiType.Fields {
 		if fieldName == SystemCreatedAt {
 			continue
 		}
-		fieldValue := wi.Fields[fieldName]
+		fieldValue, set := wi.Fields[fieldName]
+		restricted := !viewer.HasRole(stored, fieldDef.Roles...)
+		if restricted && !set {
+			// the field was redacted when the work item was loaded, keep it
+			newWi.Fields[fieldName] = stored[fieldName]
+			continue
+		}
 		var err error
 		newWi.Fields[fieldName], err = fieldDef.ConvertToModel(fieldName, fieldValue)
 		if err != nil {
 			return nil, errors.NewBadParameterError(fieldName, fieldValue)
 		}
+		if restricted && !sameValue(newWi.Fields[fieldName], stored[fieldName]) {
+			return nil, errors.NewBadParameterError(fieldName, fieldValue).Expected(fmt.Sprintf("unchanged, the field is restricted to %v", fieldDef.Roles))
+		}
+	}
+	// saving re-encrypts values encrypted with older keys with the primary key
+	if err := encryptFields(*wiType, newWi.Fields); err != nil {
+		return nil, err
 	}
 
-	tx = tx.Where("Version = ?", wi.Version).Save(&newWi)
-	if err := tx.Error; err != nil {
-		log.Print(err.Error())
-		return nil, errors.NewInternalError(err.Error())
+	if err := apply(r.db, newEvent(ctx, EventUpdate, newWi)); err != nil {
+		return nil, err
 	}
-	if tx.RowsAffected == 0 {
-		return nil, errors.NewVersionConflictError("version conflict")
+	if !sameValue(stored[SystemProject], newWi.Fields[SystemProject]) {
+		if err := moveKey(ctx, r.db, &newWi); err != nil {
+			return nil, err
+		}
 	}
 	log.Printf("updated item to %v\n", newWi)
-	return convertWorkItemModelToApp(wiType, &newWi)
+	return convertWorkItemModelToApp(ctx, wiType, &newWi)
 }
 
 // Create creates a new work item in the repository
@@ -149,52 +180,107 @@ func (r *GormWorkItemRepository) Create(ctx context.Context, typeID string, fiel
 		return nil, errors.NewBadParameterError("type", typeID)
 	}
 	wi := WorkItem{
-		Type:   typeID,
-		Fields: Fields{},
+		Type:        typeID,
+		TypeVersion: wiType.Version,
+		Fields:      Fields{},
 	}
 	fields[SystemCreator] = creator
+	viewer := ContextViewer(ctx)
 	for fieldName, fieldDef := range wiType.Fields {
 		if fieldName == SystemCreatedAt {
 			continue
 		}
 		fieldValue := fields[fieldName]
+		if fieldValue != nil && !viewer.HasRole(fields, fieldDef.Roles...) {
+			return nil, errors.NewBadParameterError(fieldName, fieldValue).Expected(fmt.Sprintf("not set, the field is restricted to %v", fieldDef.Roles))
+		}
+		if fieldValue != nil && fieldDef.Deprecated {
+			expected := "not set, the field is deprecated"
+			if fieldDef.ReplacedBy != "" {
+				expected += ", use " + fieldDef.ReplacedBy
+			}
+			return nil, errors.NewBadParameterError(fieldName, fieldValue).Expected(expected)
+		}
 		var err error
 		wi.Fields[fieldName], err = fieldDef.ConvertToModel(fieldName, fieldValue)
 		if err != nil {
 			return nil, errors.NewBadParameterError(fieldName, fieldValue)
 		}
 	}
-	tx := r.db
-	if err = tx.Create(&wi).Error; err != nil {
-		return nil, errors.NewInternalError(err.Error())
+	if err := encryptFields(*wiType, wi.Fields); err != nil {
+		return nil, err
+	}
+	e := newEvent(ctx, EventCreate, wi)
+	if err := apply(r.db, e); err != nil {
+		return nil, err
+	}
+	wi.ID = e.WorkItemID
+	wi.CreatedAt = e.CreatedAt
+	wi.UpdatedAt = e.CreatedAt
+	if err := assignKey(r.db, &wi); err != nil {
+		return nil, err
 	}
 	log.Printf("created item %v\n", wi)
-	return convertWorkItemModelToApp(wiType, &wi)
+	return convertWorkItemModelToApp(ctx, wiType, &wi)
 }
 
-func convertWorkItemModelToApp(wiType *WorkItemType, wi *WorkItem) (*app.WorkItem, error) {
-	result, err := wiType.ConvertFromModel(*wi)
+func convertWorkItemModelToApp(ctx context.Context, wiType *WorkItemType, wi *WorkItem) (*app.WorkItem, error) {
+	fields, err := DecryptFields(*wiType, wi.Fields)
+	if err != nil {
+		return nil, err
+	}
+	decrypted := *wi
+	decrypted.Fields = fields
+	result, err := wiType.ConvertFromModel(decrypted)
 	if err != nil {
 		return nil, errors.NewConversionError(err.Error())
 	}
 	if _, ok := wiType.Fields[SystemCreatedAt]; ok {
 		result.Fields[SystemCreatedAt] = wi.CreatedAt
 	}
+	if wi.Archived {
+		result.Fields[SystemArchived] = true
+	}
+	if wi.Key != nil {
+		result.Fields[SystemKey] = *wi.Key
+	}
+	ContextViewer(ctx).Redact(*wiType, result)
+	addChecklistCompletions(*wiType, result)
 	return result, nil
 
 }
 
-// extracted this function from List() in order to close the rows object with "defer" for more readability
-// workaround for https://github.com/lib/pq/issues/81
-func (r *GormWorkItemRepository) listItemsFromDB(ctx context.Context, criteria criteria.Expression, start *int, limit *int) ([]WorkItem, uint64, error) {
+// listQuery returns the query of the visible work items selected by the
+// given criteria.Expression, archived work items and drafts are only selected
+// if the expression asks for them
+func (r *GormWorkItemRepository) listQuery(ctx context.Context, criteria criteria.Expression) (*gorm.DB, error) {
 	where, parameters, compileError := Compile(criteria)
 	if compileError != nil {
-		return nil, 0, errors.NewBadParameterError("expression", criteria)
+		return nil, errors.NewBadParameterError("expression", criteria)
 	}
 
 	log.Printf("executing query: '%s' with params %v", where, parameters)
 
 	db := r.db.Model(&WorkItem{}).Where(where, parameters...)
+	if !referencesField(criteria, ArchivedField) {
+		db = db.Where("NOT archived")
+	}
+	if !referencesField(criteria, SystemDraft) {
+		db = db.Where(notDraft)
+	}
+	if clause, params := VisibilityClause(ctx, WorkItem{}.TableName()); clause != "" {
+		db = db.Where(clause, params...)
+	}
+	return db, nil
+}
+
+// extracted this function from List() in order to close the rows object with "defer" for more readability
+// workaround for https://github.com/lib/pq/issues/81
+func (r *GormWorkItemRepository) listItemsFromDB(ctx context.Context, criteria criteria.Expression, start *int, limit *int, sort []SortKey) ([]WorkItem, uint64, error) {
+	db, err := r.listQuery(ctx, criteria)
+	if err != nil {
+		return nil, 0, err
+	}
 	orgDB := db
 	if start != nil {
 		if *start < 0 {
@@ -208,6 +294,9 @@ func (r *GormWorkItemRepository) listItemsFromDB(ctx context.Context, criteria c
 		}
 		db = db.Limit(*limit)
 	}
+	if len(sort) > 0 {
+		db = db.Order(orderClause(sort))
+	}
 	db = db.Select("count(*) over () as cnt2 , *")
 
 	rows, err := db.Rows()
@@ -261,8 +350,9 @@ func (r *GormWorkItemRepository) listItemsFromDB(ctx context.Context, criteria c
 }
 
 // List returns work item selected by the given criteria.Expression, starting with start (zero-based) and returning at most limit items
-func (r *GormWorkItemRepository) List(ctx context.Context, criteria criteria.Expression, start *int, limit *int) ([]*app.WorkItem, uint64, error) {
-	result, count, err := r.listItemsFromDB(ctx, criteria, start, limit)
+// ordered by the given sort keys
+func (r *GormWorkItemRepository) List(ctx context.Context, criteria criteria.Expression, start *int, limit *int, sort ...SortKey) ([]*app.WorkItem, uint64, error) {
+	result, count, err := r.listItemsFromDB(ctx, criteria, start, limit, sort)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -274,8 +364,71 @@ func (r *GormWorkItemRepository) List(ctx context.Context, criteria criteria.Exp
 		if err != nil {
 			return nil, 0, errors.NewInternalError(err.Error())
 		}
-		res[index], err = convertWorkItemModelToApp(wiType, &value)
+		res[index], err = convertWorkItemModelToApp(ctx, wiType, &value)
 	}
 
 	return res, count, nil
 }
+
+// Stream calls fn with the work items selected by the given
+// criteria.Expression ordered by the given sort keys or else by their ID. The
+// work items are converted one at a time while they are read from the
+// database, so they are never all held in memory and reading waits for fn,
+// e.g. for a slow client to receive them. fn must not use the database while
+// the rows are read, an error of fn stops the stream and is returned.
+// returns BadParameterError, ConversionError, InternalError or the error of fn
+func (r *GormWorkItemRepository) Stream(ctx context.Context, criteria criteria.Expression, sort []SortKey, fn func(*app.WorkItem) error) error {
+	db, err := r.listQuery(ctx, criteria)
+	if err != nil {
+		return err
+	}
+	// no other query can run while the rows are read, so all types are
+	// loaded first
+	var types []WorkItemType
+	if err := r.db.Find(&types).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	typesByName := make(map[string]*WorkItemType, len(types))
+	for i := range types {
+		typesByName[types[i].Name] = &types[i]
+	}
+
+	if len(sort) > 0 {
+		db = db.Order(orderClause(sort))
+	} else {
+		db = db.Order("id")
+	}
+	rows, err := db.Rows()
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	defer rows.Close()
+	for rows.Next() {
+		value := WorkItem{}
+		if err := db.ScanRows(rows, &value); err != nil {
+			return errors.NewInternalError(err.Error())
+		}
+		wiType, ok := typesByName[value.Type]
+		if !ok {
+			return errors.NewInternalError(fmt.Sprintf("unknown type %s of work item %d", value.Type, value.ID))
+		}
+		wi, err := convertWorkItemModelToApp(ctx, wiType, &value)
+		if err != nil {
+			return err
+		}
+		if err := fn(wi); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// sameValue compares two field values by their json representation
+func sameValue(a interface{}, b interface{}) bool {
+	x, errA := json.Marshal(a)
+	y, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(x, y)
+}
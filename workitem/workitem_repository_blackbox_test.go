@@ -3,9 +3,13 @@ package workitem_test
 import (
 	"testing"
 
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/encryption"
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -102,3 +106,128 @@ func (s *workItemRepoBlackBoxTest) TestSaveAssignees() {
 
 	assert.Equal(s.T(), "A", wi.Fields[workitem.SystemAssignees].([]interface{})[0])
 }
+
+func (s *workItemRepoBlackBoxTest) TestListSortedByValues() {
+	defer gormsupport.DeleteCreatedEntities(s.DB)()
+
+	title := "sort-" + uuid.NewV4().String()
+	for _, priority := range []string{"low", "critical", "medium"} {
+		_, err := s.repo.Create(
+			context.Background(), "system.bug",
+			map[string]interface{}{
+				workitem.SystemTitle:    title,
+				workitem.SystemState:    workitem.SystemStateNew,
+				workitem.SystemPriority: priority,
+			}, "xx")
+		require.Nil(s.T(), err)
+	}
+
+	exp := criteria.Equals(criteria.Field(workitem.SystemTitle), criteria.Literal(title))
+	sort := workitem.SortKey{Field: workitem.SystemPriority, Values: []string{"critical", "high", "medium", "low"}}
+	items, _, err := s.repo.List(context.Background(), exp, nil, nil, sort)
+	require.Nil(s.T(), err)
+	require.Len(s.T(), items, 3)
+	assert.Equal(s.T(), "critical", items[0].Fields[workitem.SystemPriority])
+	assert.Equal(s.T(), "medium", items[1].Fields[workitem.SystemPriority])
+	assert.Equal(s.T(), "low", items[2].Fields[workitem.SystemPriority])
+
+	sort.Descending = true
+	items, _, err = s.repo.List(context.Background(), exp, nil, nil, sort)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), "low", items[0].Fields[workitem.SystemPriority])
+}
+
+func (s *workItemRepoBlackBoxTest) TestStream() {
+	defer gormsupport.DeleteCreatedEntities(s.DB)()
+
+	title := "stream-" + uuid.NewV4().String()
+	var ids []string
+	for i := 0; i < 3; i++ {
+		wi, err := s.repo.Create(
+			context.Background(), "system.bug",
+			map[string]interface{}{
+				workitem.SystemTitle: title,
+				workitem.SystemState: workitem.SystemStateNew,
+			}, "xx")
+		require.Nil(s.T(), err)
+		ids = append(ids, wi.ID)
+	}
+
+	exp := criteria.Equals(criteria.Field(workitem.SystemTitle), criteria.Literal(title))
+	var streamed []string
+	err := s.repo.Stream(context.Background(), exp, nil, func(wi *app.WorkItem) error {
+		streamed = append(streamed, wi.ID)
+		return nil
+	})
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), ids, streamed)
+
+	// an error of the callback stops the stream
+	stop := errors.NewInternalError("stop")
+	streamed = nil
+	err = s.repo.Stream(context.Background(), exp, nil, func(wi *app.WorkItem) error {
+		streamed = append(streamed, wi.ID)
+		return stop
+	})
+	assert.Equal(s.T(), stop, err)
+	assert.Len(s.T(), streamed, 1)
+}
+
+func (s *workItemRepoBlackBoxTest) TestConfidentialVisibility() {
+	defer gormsupport.DeleteCreatedEntities(s.DB)()
+
+	creator := uuid.NewV4()
+	title := "confidential-" + uuid.NewV4().String()
+	wi, err := s.repo.Create(
+		context.Background(), "system.bug",
+		map[string]interface{}{
+			workitem.SystemTitle:        title,
+			workitem.SystemState:        workitem.SystemStateNew,
+			workitem.SystemConfidential: true,
+		}, creator.String())
+	require.Nil(s.T(), err)
+
+	exp := criteria.Equals(criteria.Field(workitem.SystemTitle), criteria.Literal(title))
+	anonymous := workitem.WithViewer(context.Background(), &workitem.Viewer{})
+	items, count, err := s.repo.List(anonymous, exp, nil, nil)
+	require.Nil(s.T(), err)
+	assert.Len(s.T(), items, 0)
+	assert.Equal(s.T(), uint64(0), count)
+	_, err = s.repo.Load(anonymous, wi.ID)
+	assert.IsType(s.T(), errors.NotFoundError{}, err)
+
+	owner := workitem.WithViewer(context.Background(), &workitem.Viewer{IdentityID: &creator})
+	items, _, err = s.repo.List(owner, exp, nil, nil)
+	require.Nil(s.T(), err)
+	assert.Len(s.T(), items, 1)
+	_, err = s.repo.Load(owner, wi.ID)
+	assert.Nil(s.T(), err)
+}
+
+func (s *workItemRepoBlackBoxTest) TestEncryptedFields() {
+	defer gormsupport.DeleteCreatedEntities(s.DB)()
+	require.Nil(s.T(), encryption.SetupDefaultKeyring())
+
+	encrypted := true
+	witName := "encrypted-" + uuid.NewV4().String()
+	_, err := workitem.NewWorkItemTypeRepository(s.DB).Create(context.Background(), nil, witName, map[string]app.FieldDefinition{
+		workitem.SystemTitle: {Type: &app.FieldType{Kind: "string"}, Required: true},
+		"customer":           {Type: &app.FieldType{Kind: "string"}, Encrypted: &encrypted},
+	})
+	require.Nil(s.T(), err)
+
+	wi, err := s.repo.Create(context.Background(), witName, map[string]interface{}{
+		workitem.SystemTitle: "Title",
+		"customer":           "ACME Corp.",
+	}, "xx")
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), "ACME Corp.", wi.Fields["customer"])
+
+	stored := workitem.WorkItem{}
+	require.Nil(s.T(), s.DB.First(&stored, wi.ID).Error)
+	assert.True(s.T(), encryption.IsEncrypted(stored.Fields["customer"].(string)))
+
+	loaded, err := s.repo.Load(context.Background(), wi.ID)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), "ACME Corp.", loaded.Fields["customer"])
+}
@@ -3,6 +3,7 @@ package workitem
 import (
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/convert"
@@ -14,14 +15,30 @@ const (
 	// pathSep specifies the symbol used to concatenate WIT names to form a so called "path"
 	pathSep = "/"
 
-	SystemRemoteItemID = "system.remote_item_id"
-	SystemTitle        = "system.title"
-	SystemDescription  = "system.description"
-	SystemState        = "system.state"
-	SystemAssignees    = "system.assignees"
-	SystemCreator      = "system.creator"
-	SystemCreatedAt    = "system.created_at"
-	SystemIteration    = "system.iteration"
+	SystemRemoteItemID  = "system.remote_item_id"
+	SystemTitle         = "system.title"
+	SystemDescription   = "system.description"
+	SystemState         = "system.state"
+	SystemAssignees     = "system.assignees"
+	SystemCreator       = "system.creator"
+	SystemCreatedAt     = "system.created_at"
+	SystemIteration     = "system.iteration"
+	SystemRelease       = "system.release"
+	SystemLabels        = "system.labels"
+	SystemProject       = "system.project"
+	SystemPriority      = "system.priority"
+	SystemSeverity      = "system.severity"
+	SystemConfidential  = "system.confidential"
+	SystemPendingReview = "system.pending_review"
+	// SystemDueDate is the instant a work item is due, it shows in the
+	// calendar feeds
+	SystemDueDate = "system.due_date"
+	// SystemDraft is set on work items that are not yet published, see
+	// DraftRepository
+	SystemDraft = "system.draft"
+	// SystemArchived is set on archived work items, it is not a field of
+	// the work item types and can't be changed by updates
+	SystemArchived = "system.archived"
 
 	// base item type with common fields for planner item types like userstory, experience, bug, feature, etc.
 	SystemPlannerItem = "system.planneritem"
@@ -38,6 +55,8 @@ const (
 	SystemStateInProgress = "in progress"
 	SystemStateResolved   = "resolved"
 	SystemStateClosed     = "closed"
+	// SystemStateInactive work items were stale for too long, see package stale
+	SystemStateInactive = "inactive"
 )
 
 // WorkItemType represents a work item type as it is stored in the db
@@ -58,6 +77,20 @@ func (wit WorkItemType) TableName() string {
 	return "work_item_types"
 }
 
+// WorkItemTypeVersion holds the field definitions of a version of a work item
+// type
+type WorkItemTypeVersion struct {
+	CreatedAt time.Time
+	TypeName  string           `gorm:"primary_key"`
+	Version   int              `gorm:"primary_key"`
+	Fields    FieldDefinitions `sql:"type:jsonb"`
+}
+
+// TableName implements gorm.tabler
+func (v WorkItemTypeVersion) TableName() string {
+	return "work_item_type_versions"
+}
+
 // Ensure Fields implements the Equaler interface
 var _ convert.Equaler = WorkItemType{}
 var _ convert.Equaler = (*WorkItemType)(nil)
@@ -95,7 +128,9 @@ func (wit WorkItemType) Equal(u convert.Equaler) bool {
 	return true
 }
 
-// ConvertFromModel serializes a database persisted workitem.
+// ConvertFromModel serializes a database persisted workitem. Values of work
+// items of an older version of the type that don't fit the current field
+// definitions are returned as they are stored.
 func (wit WorkItemType) ConvertFromModel(workItem WorkItem) (*app.WorkItem, error) {
 	result := app.WorkItem{
 		ID:      strconv.FormatUint(workItem.ID, 10),
@@ -110,6 +145,10 @@ func (wit WorkItemType) ConvertFromModel(workItem WorkItem) (*app.WorkItem, erro
 		}
 		result.Fields[name], err = field.ConvertFromModel(name, workItem.Fields[name])
 		if err != nil {
+			if workItem.TypeVersion < wit.Version {
+				result.Fields[name] = workItem.Fields[name]
+				continue
+			}
 			return nil, err
 		}
 	}
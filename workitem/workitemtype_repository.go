@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"reflect"
+	"time"
 
 	"golang.org/x/net/context"
 
@@ -16,6 +17,7 @@ import (
 type WorkItemTypeRepository interface {
 	Load(ctx context.Context, name string) (*app.WorkItemType, error)
 	Create(ctx context.Context, extendedTypeID *string, name string, fields map[string]app.FieldDefinition) (*app.WorkItemType, error)
+	Update(ctx context.Context, name string, version int, fields map[string]app.FieldDefinition) (*app.WorkItemType, error)
 	List(ctx context.Context, start *int, length *int) ([]*app.WorkItemType, error)
 }
 
@@ -97,15 +99,29 @@ func (r *GormWorkItemTypeRepository) Create(ctx context.Context, extendedTypeNam
 		if err != nil {
 			return nil, err
 		}
+		if err := ValidateRoles(definition.Roles); err != nil {
+			return nil, err
+		}
 		converted := FieldDefinition{
-			Required: definition.Required,
-			Type:     ct,
+			Required:  definition.Required,
+			Type:      ct,
+			Roles:     definition.Roles,
+			Encrypted: definition.Encrypted != nil && *definition.Encrypted,
+		}
+		if definition.Deprecated != nil {
+			converted.Deprecated = *definition.Deprecated
+		}
+		if definition.ReplacedBy != nil {
+			converted.ReplacedBy = *definition.ReplacedBy
 		}
 		if exists && !compatibleFields(existing, converted) {
 			return nil, fmt.Errorf("incompatible change for field %s", field)
 		}
 		allFields[field] = converted
 	}
+	if err := validateDeprecations(allFields); err != nil {
+		return nil, err
+	}
 
 	created := WorkItemType{
 		Version: 0,
@@ -117,11 +133,98 @@ func (r *GormWorkItemTypeRepository) Create(ctx context.Context, extendedTypeNam
 	if err := r.db.Save(&created).Error; err != nil {
 		return nil, errors.NewInternalError(err.Error())
 	}
+	if err := r.recordVersion(&created); err != nil {
+		return nil, err
+	}
 
 	result := convertTypeFromModels(&created)
 	return &result, nil
 }
 
+// Update replaces the field definitions of the work item type, the version
+// must be the one of the stored type. Changed definitions make a new version
+// of the type, the existing work items keep their fields until they are
+// migrated, see TypeMigrationRepository. Types extending it keep the fields
+// they copied when they were created.
+// returns NotFoundError, BadParameterError, VersionConflictError, ConversionError or InternalError
+func (r *GormWorkItemTypeRepository) Update(ctx context.Context, name string, version int, fields map[string]app.FieldDefinition) (*app.WorkItemType, error) {
+	wit, err := r.LoadTypeFromDB(name)
+	if err != nil {
+		return nil, err
+	}
+	if wit.Version != version {
+		return nil, errors.NewVersionConflictError("version conflict")
+	}
+	converted, err := TEMPConvertFieldTypesToModel(fields)
+	if err != nil {
+		return nil, errors.NewBadParameterError("fields", err.Error())
+	}
+	if err := r.Evolve(ctx, wit, converted); err != nil {
+		return nil, err
+	}
+	result := convertTypeFromModels(wit)
+	return &result, nil
+}
+
+// Evolve stores the work item type with the given field definitions. If
+// they differ from the stored ones the type gets a new version whose
+// definitions are kept with the ones of the earlier versions.
+// returns VersionConflictError or InternalError
+func (r *GormWorkItemTypeRepository) Evolve(ctx context.Context, wit *WorkItemType, fields FieldDefinitions) error {
+	version := wit.Version
+	if !equalFieldDefinitions(wit.Fields, fields) {
+		version++
+	}
+	tx := r.db.Model(&WorkItemType{}).Where("name = ? AND version = ?", wit.Name, wit.Version).Updates(map[string]interface{}{
+		"version": version,
+		"path":    wit.Path,
+		"fields":  fields,
+	})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewVersionConflictError("version conflict")
+	}
+	changed := version != wit.Version
+	wit.Version = version
+	wit.Fields = fields
+	if changed {
+		return r.recordVersion(wit)
+	}
+	return nil
+}
+
+// LoadVersion returns the field definitions of the given version of the work
+// item type, nil if they weren't recorded
+// returns InternalError
+func (r *GormWorkItemTypeRepository) LoadVersion(name string, version int) (FieldDefinitions, error) {
+	var v WorkItemTypeVersion
+	tx := r.db.Where("type_name = ? AND version = ?", name, version).First(&v)
+	if tx.RecordNotFound() {
+		return nil, nil
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return v.Fields, nil
+}
+
+// recordVersion keeps the field definitions of the current version of the
+// work item type
+func (r *GormWorkItemTypeRepository) recordVersion(wit *WorkItemType) error {
+	v := WorkItemTypeVersion{
+		CreatedAt: time.Now(),
+		TypeName:  wit.Name,
+		Version:   wit.Version,
+		Fields:    wit.Fields,
+	}
+	if err := r.db.Create(&v).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
 // List returns work item types selected by the given criteria.Expression, starting with start (zero-based) and returning at most "limit" item types
 func (r *GormWorkItemTypeRepository) List(ctx context.Context, start *int, limit *int) ([]*app.WorkItemType, error) {
 	// Currently we don't implement filtering here, so leave this empty
@@ -154,6 +257,21 @@ func compatibleFields(existing FieldDefinition, new FieldDefinition) bool {
 	return reflect.DeepEqual(existing, new)
 }
 
+// equalFieldDefinitions returns true if both have the same fields with equal
+// definitions
+func equalFieldDefinitions(a FieldDefinitions, b FieldDefinitions) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, def := range a {
+		other, ok := b[name]
+		if !ok || !def.Equal(other) {
+			return false
+		}
+	}
+	return true
+}
+
 // converts from models to app representation
 func convertTypeFromModels(t *WorkItemType) app.WorkItemType {
 	var converted = app.WorkItemType{
@@ -163,9 +281,20 @@ func convertTypeFromModels(t *WorkItemType) app.WorkItemType {
 	}
 	for name, def := range t.Fields {
 		ct := convertFieldTypeFromModels(def.Type)
+		encrypted := def.Encrypted
 		converted.Fields[name] = &app.FieldDefinition{
-			Required: def.Required,
-			Type:     &ct,
+			Required:  def.Required,
+			Type:      &ct,
+			Roles:     def.Roles,
+			Encrypted: &encrypted,
+		}
+		if def.Deprecated {
+			deprecated := true
+			converted.Fields[name].Deprecated = &deprecated
+		}
+		if def.ReplacedBy != "" {
+			replacedBy := def.ReplacedBy
+			converted.Fields[name].ReplacedBy = &replacedBy
 		}
 	}
 	return converted
@@ -200,7 +329,7 @@ func convertAnyToKind(any interface{}) (*Kind, error) {
 func convertStringToKind(k string) (*Kind, error) {
 	kind := Kind(k)
 	switch kind {
-	case KindString, KindInteger, KindFloat, KindInstant, KindDuration, KindURL, KindWorkitemReference, KindUser, KindEnum, KindList, KindIteration:
+	case KindString, KindInteger, KindFloat, KindInstant, KindDuration, KindBoolean, KindURL, KindWorkitemReference, KindUser, KindEnum, KindList, KindIteration, KindRelease, KindProject, KindChecklist:
 		return &kind, nil
 	}
 	return nil, fmt.Errorf("Not a simple type")
@@ -217,7 +346,7 @@ func convertFieldTypeToModels(t app.FieldType) (FieldType, error) {
 		if err != nil {
 			return nil, err
 		}
-		if !componentType.isSimpleType() {
+		if !componentType.IsSimpleType() {
 			return nil, fmt.Errorf("Component type is not list type: %s", componentType)
 		}
 		return ListType{SimpleType{*kind}, SimpleType{*componentType}}, nil
@@ -226,7 +355,7 @@ func convertFieldTypeToModels(t app.FieldType) (FieldType, error) {
 		if err != nil {
 			return nil, err
 		}
-		if !bt.isSimpleType() {
+		if !bt.IsSimpleType() {
 			return nil, fmt.Errorf("baseType type is not list type: %s", bt)
 		}
 		baseType := SimpleType{*bt}
@@ -252,11 +381,25 @@ func TEMPConvertFieldTypesToModel(fields map[string]app.FieldDefinition) (map[st
 		if err != nil {
 			return nil, err
 		}
+		if err := ValidateRoles(definition.Roles); err != nil {
+			return nil, err
+		}
 		converted := FieldDefinition{
-			Required: definition.Required,
-			Type:     ct,
+			Required:  definition.Required,
+			Type:      ct,
+			Roles:     definition.Roles,
+			Encrypted: definition.Encrypted != nil && *definition.Encrypted,
+		}
+		if definition.Deprecated != nil {
+			converted.Deprecated = *definition.Deprecated
+		}
+		if definition.ReplacedBy != nil {
+			converted.ReplacedBy = *definition.ReplacedBy
 		}
 		allFields[field] = converted
 	}
+	if err := validateDeprecations(allFields); err != nil {
+		return nil, err
+	}
 	return allFields, nil
 }
@@ -94,3 +94,31 @@ func (s *workItemTypeRepoBlackBoxTest) TestCreateLoadWITWithList() {
 	assert.Nil(s.T(), field.Type.BaseType)
 	assert.Nil(s.T(), field.Type.Values)
 }
+
+func (s *workItemTypeRepoBlackBoxTest) TestUpdateWIT() {
+	fields := map[string]app.FieldDefinition{
+		"foo": app.FieldDefinition{
+			Required: true,
+			Type:     &app.FieldType{Kind: string(workitem.KindFloat)},
+		},
+	}
+	_, err := s.repo.Create(context.Background(), nil, "foo.bar", fields)
+	require.Nil(s.T(), err)
+
+	// the same fields keep the version
+	wit, err := s.repo.Update(context.Background(), "foo.bar", 0, fields)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), 0, wit.Version)
+
+	fields["bar"] = app.FieldDefinition{Type: &app.FieldType{Kind: string(workitem.KindString)}}
+	wit, err = s.repo.Update(context.Background(), "foo.bar", 0, fields)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), 1, wit.Version)
+	assert.Contains(s.T(), wit.Fields, "bar")
+
+	_, err = s.repo.Update(context.Background(), "foo.bar", 0, fields)
+	assert.IsType(s.T(), errors.VersionConflictError{}, err)
+	fields["baz"] = app.FieldDefinition{Type: &app.FieldType{Kind: "unknown"}}
+	_, err = s.repo.Update(context.Background(), "foo.bar", 1, fields)
+	assert.IsType(s.T(), errors.BadParameterError{}, err)
+}
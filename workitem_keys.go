@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+)
+
+// ResolveWorkItemKeys is a middleware that lets clients use the keys of work
+// items, e.g. ALM-482, wherever the work item endpoints take their numeric
+// ID. It must run after InjectViewer, keys of work items the viewer can't see
+// aren't resolved and the endpoints report them as not found.
+func ResolveWorkItemKeys(db application.DB) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			params := goa.ContextRequest(ctx).Params
+			ref := params.Get("id")
+			if !strings.Contains(req.URL.Path, "/workitems/") || !workitem.IsKey(ref) {
+				return h(ctx, rw, req)
+			}
+			var id string
+			application.Transactional(requestDB(ctx, db), func(appl application.Application) error {
+				wi, err := appl.WorkItems().Load(ctx, ref)
+				if err != nil {
+					return err
+				}
+				id = wi.ID
+				return nil
+			})
+			if id != "" {
+				params.Set("id", id)
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
@@ -1,14 +1,31 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"log"
+	"strconv"
+
+	"golang.org/x/net/context"
 
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/job"
 	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/workitem"
 	"github.com/goadesign/goa"
 )
 
+// replaceDeprecatedJobKind is the kind of the jobs moving the values of the
+// deprecated fields of a work item type to their replacements
+const replaceDeprecatedJobKind = "workitemtype.replace-deprecated"
+
+// replaceDeprecatedJobPayload names the work item type of the job
+type replaceDeprecatedJobPayload struct {
+	Type string `json:"type"`
+}
+
 // WorkitemtypeController implements the workitemtype resource.
 type WorkitemtypeController struct {
 	*goa.Controller
@@ -25,7 +42,7 @@ func NewWorkitemtypeController(service *goa.Service, db application.DB) *Workite
 
 // Show runs the show action.
 func (c *WorkitemtypeController) Show(ctx *app.ShowWorkitemtypeContext) error {
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 		res, err := appl.WorkItemTypes().Load(ctx.Context, ctx.Name)
 		if err != nil {
 			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
@@ -37,7 +54,7 @@ func (c *WorkitemtypeController) Show(ctx *app.ShowWorkitemtypeContext) error {
 
 // Create runs the create action.
 func (c *WorkitemtypeController) Create(ctx *app.CreateWorkitemtypeContext) error {
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 		var fields = map[string]app.FieldDefinition{}
 
 		for key, fd := range ctx.Payload.Fields {
@@ -54,6 +71,112 @@ func (c *WorkitemtypeController) Create(ctx *app.CreateWorkitemtypeContext) erro
 	})
 }
 
+// Update runs the update action.
+func (c *WorkitemtypeController) Update(ctx *app.UpdateWorkitemtypeContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can change work item types"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		var fields = map[string]app.FieldDefinition{}
+		for key, fd := range ctx.Payload.Fields {
+			fields[key] = *fd
+		}
+		wit, err := appl.WorkItemTypes().Update(ctx, ctx.Name, ctx.Payload.Version, fields)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		for _, fd := range wit.Fields {
+			if fd.ReplacedBy != nil {
+				if _, err := appl.Jobs().Enqueue(ctx, replaceDeprecatedJobKind, replaceDeprecatedJobPayload{Type: wit.Name}); err != nil {
+					return jsonapi.JSONErrorResponse(ctx, err)
+				}
+				break
+			}
+		}
+		return ctx.OK(wit)
+	})
+}
+
+// Migrate runs the migrate action.
+func (c *WorkitemtypeController) Migrate(ctx *app.MigrateWorkitemtypeContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can migrate work items"))
+	}
+	rules := map[string]workitem.FieldRule{}
+	for name, r := range ctx.Payload.Rules {
+		rule := workitem.FieldRule{Values: r.Values, Default: r.Default}
+		if r.From != nil {
+			rule.From = *r.From
+		}
+		rules[name] = rule
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		res, err := appl.WorkItemTypeMigrations().Migrate(ctx, ctx.Name, rules)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		failures := make(map[string]string, len(res.Failures))
+		for id, reason := range res.Failures {
+			failures[strconv.FormatUint(id, 10)] = reason
+		}
+		return ctx.OK(&app.WorkItemTypeMigration{
+			Version:  res.Version,
+			Migrated: res.Migrated,
+			Failures: failures,
+		})
+	})
+}
+
+// Impact runs the impact action.
+func (c *WorkitemtypeController) Impact(ctx *app.ImpactWorkitemtypeContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can delete work item types"))
+	}
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		impact, err := appl.WorkItemTypeDeletions().Impact(ctx, ctx.Name)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(ConvertWorkItemTypeImpact(impact))
+	})
+}
+
+// Delete runs the delete action.
+func (c *WorkitemtypeController) Delete(ctx *app.DeleteWorkitemtypeContext) error {
+	if !isInstanceAdmin(ctx) {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized("only instance admins can delete work item types"))
+	}
+	target := ""
+	if ctx.Target != nil {
+		target = *ctx.Target
+	}
+	var impact *workitem.TypeImpact
+	// a failed migration of the work items must not be committed
+	err := application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
+		var err error
+		impact, err = appl.WorkItemTypeDeletions().Delete(ctx, ctx.Name, target)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(ConvertWorkItemTypeImpact(impact))
+}
+
+// ConvertWorkItemTypeImpact converts from internal to external REST representation
+func ConvertWorkItemTypeImpact(impact *workitem.TypeImpact) *app.WorkItemTypeImpact {
+	return &app.WorkItemTypeImpact{
+		WorkItems:       impact.WorkItems,
+		Subtypes:        impact.Subtypes,
+		LinkTypes:       impact.LinkTypes,
+		Links:           impact.Links,
+		AssignmentRules: impact.AssignmentRules,
+		ApprovalRules:   impact.ApprovalRules,
+		Hierarchies:     impact.Hierarchies,
+		InUse:           impact.InUse(),
+	}
+}
+
 // List runs the list action
 func (c *WorkitemtypeController) List(ctx *app.ListWorkitemtypeContext) error {
 	start, limit, err := parseLimit(ctx.Page)
@@ -61,7 +184,7 @@ func (c *WorkitemtypeController) List(ctx *app.ListWorkitemtypeContext) error {
 		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(fmt.Sprintf("could not parse paging: %s", err.Error())))
 		return ctx.BadRequest(jerrors)
 	}
-	return application.Transactional(c.db, func(appl application.Application) error {
+	return application.Transactional(requestDB(ctx, c.db), func(appl application.Application) error {
 		result, err := appl.WorkItemTypes().List(ctx.Context, start, &limit)
 		if err != nil {
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(fmt.Sprintf("Error listing work item types: %s", err.Error())))
@@ -70,3 +193,22 @@ func (c *WorkitemtypeController) List(ctx *app.ListWorkitemtypeContext) error {
 		return ctx.OK(result)
 	})
 }
+
+// replaceDeprecatedJob returns the handler of the jobs moving the values of
+// the deprecated fields of a work item type to their replacements
+func replaceDeprecatedJob(db application.DB) job.Handler {
+	return func(ctx context.Context, payload []byte) error {
+		var p replaceDeprecatedJobPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return errors.NewConversionError(err.Error())
+		}
+		return application.Transactional(db, func(appl application.Application) error {
+			res, err := appl.WorkItemTypeMigrations().ReplaceDeprecated(ctx, p.Type)
+			if err != nil {
+				return err
+			}
+			log.Printf("Moved the deprecated fields of %d work items of type %s, %d failed\n", res.Migrated, p.Type, len(res.Failures))
+			return nil
+		})
+	}
+}